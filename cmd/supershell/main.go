@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"os/signal"
 	"strings"
@@ -9,6 +10,8 @@ import (
 	"time"
 
 	"suppercommand/internal/app"
+	"suppercommand/internal/commands"
+	"suppercommand/internal/shell"
 
 	"github.com/fatih/color"
 )
@@ -22,24 +25,88 @@ func main() {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
-	// Create and initialize application
+	// --no-rc (or --no-scripts) skips the rc file and startup directory, which
+	// commands run once with -c never run either
+	var args []string
 	application := app.NewApplication()
+	for i, arg := range os.Args[1:] {
+		if arg == "-c" {
+			// Everything after -c is the command, flags included
+			application.SkipStartupScripts()
+			args = append(args, os.Args[1+i:]...)
+			break
+		}
+		if arg == "--no-rc" || arg == "--no-scripts" {
+			application.SkipStartupScripts()
+			continue
+		}
+		args = append(args, arg)
+	}
+
+	// Initialize application
 	if err := application.Initialize(ctx); err != nil {
 		color.New(color.FgRed).Printf("❌ Failed to initialize SuperShell: %v\n", err)
 		os.Exit(1)
 	}
 
+	// --ascii shows box drawing and emoji as plain ASCII whatever the terminal
+	// and config say, for CI logs and serial consoles
+	if len(args) > 0 && args[0] == "--ascii" {
+		commands.SetASCIIDecorations(true)
+		args = args[1:]
+	}
+
+	// --check-capabilities runs the capabilities command, to see on first run
+	// what packet capture and raw sockets need here
+	if len(args) > 0 && args[0] == "--check-capabilities" {
+		args = append([]string{"-c", "capabilities"}, args[1:]...)
+	}
+
+	// --error-format json makes -c failures one line of JSON on stderr, for
+	// scripts that need to tell an unknown command from one that failed
+	errorFormat := "text"
+	if len(args) > 0 && (args[0] == "--error-format" || strings.HasPrefix(args[0], "--error-format=")) {
+		errorFormat = strings.TrimPrefix(args[0], "--error-format=")
+		args = args[1:]
+		if errorFormat == "--error-format" {
+			errorFormat = ""
+			if len(args) > 0 {
+				errorFormat, args = args[0], args[1:]
+			}
+		}
+		if errorFormat != "text" && errorFormat != "json" {
+			fmt.Fprint(os.Stderr, commands.Decorate(commands.FormatError(fmt.Errorf("--error-format must be text or json, not %q", errorFormat))))
+			os.Exit(1)
+		}
+	}
+
 	// Check for command-line execution (-c flag)
-	if len(os.Args) >= 3 && os.Args[1] == "-c" {
-		// Execute single command and exit
-		command := strings.Join(os.Args[2:], " ")
+	if len(args) >= 2 && args[0] == "-c" {
+		// Execute single command and exit; a signal cancels the command
+		go func() {
+			<-sigChan
+			cancel()
+		}()
+
+		command := strings.Join(args[1:], " ")
 		result, err := application.ExecuteCommand(ctx, command)
+		if result == nil {
+			result = &shell.ExecutionResult{ExitCode: 1}
+		}
 		if err != nil {
-			color.New(color.FgRed).Printf("❌ Command failed: %v\n", err)
-			os.Exit(1)
+			result.Error = err
+		}
+		if result.Error != nil && result.ExitCode == 0 {
+			result.ExitCode = 1
 		}
+
 		if result.Output != "" {
-			color.New(color.FgWhite).Println(result.Output)
+			color.New(color.FgWhite).Println(commands.Decorate(result.Output))
+		}
+		fmt.Fprint(os.Stderr, commands.Decorate(result.Stderr))
+		reportError(errorFormat, result.Error, result.ExitCode)
+		if result.ExitCode != 0 {
+			os.Exit(result.ExitCode)
 		}
 		return
 	}
@@ -47,23 +114,41 @@ func main() {
 	// Start application in background
 	go func() {
 		if err := application.Run(ctx); err != nil {
-			color.New(color.FgRed).Printf("❌ Application error: %v\n", err)
+			fmt.Print(commands.Decorate(color.New(color.FgRed).Sprintf("❌ Application error: %v\n", err)))
 			cancel()
 		}
 	}()
 
 	// Wait for shutdown signal
 	<-sigChan
-	color.New(color.FgYellow).Println("\n🛑 Shutdown signal received...")
+	fmt.Print(commands.Decorate(color.New(color.FgYellow).Sprint("\n🛑 Shutdown signal received...\n")))
+
+	// Cancel the application context first so in-flight commands stop
+	cancel()
 
 	// Graceful shutdown with timeout
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer shutdownCancel()
 
 	if err := application.Shutdown(shutdownCtx); err != nil {
-		color.New(color.FgRed).Printf("❌ Shutdown error: %v\n", err)
+		fmt.Print(commands.Decorate(color.New(color.FgRed).Sprintf("❌ Shutdown error: %v\n", err)))
 		os.Exit(1)
 	}
 
-	color.New(color.FgGreen).Println("👋 SuperShell shutdown complete")
+	fmt.Print(commands.Decorate(color.New(color.FgGreen).Sprint("👋 SuperShell shutdown complete\n")))
+}
+
+// reportError writes the failure of a -c command to stderr, in red or as JSON.
+// In JSON a command that exits non-zero without an error is reported as well.
+func reportError(format string, err error, exitCode int) {
+	if format != "json" {
+		fmt.Fprint(os.Stderr, commands.Decorate(commands.FormatError(err)))
+		return
+	}
+	if err == nil && exitCode != 0 {
+		err = fmt.Errorf("exited with status %d", exitCode)
+	}
+	if err != nil {
+		fmt.Fprint(os.Stderr, commands.FormatErrorJSON(err, exitCode))
+	}
 }
@@ -5,6 +5,7 @@ go 1.14
 require (
 	github.com/c-bata/go-prompt v0.2.6
 	github.com/fatih/color v1.13.0
+	github.com/fsnotify/fsnotify v1.4.9
 	github.com/google/gopacket v1.1.19
 	golang.org/x/crypto v0.16.0
 	golang.org/x/sys v0.15.0
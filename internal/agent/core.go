@@ -42,12 +42,12 @@ type Command interface {
 
 // Result represents command execution results with metadata
 type Result struct {
-	Output     string         `json:"output"`
-	ExitCode   int            `json:"exit_code"`
-	Duration   time.Duration  `json:"duration"`
-	MemoryUsed int64          `json:"memory_used"`
-	Metadata   map[string]any `json:"metadata"`
-	Type       ResultType     `json:"type"`
+	Output     string                 `json:"output"`
+	ExitCode   int                    `json:"exit_code"`
+	Duration   time.Duration          `json:"duration"`
+	MemoryUsed int64                  `json:"memory_used"`
+	Metadata   map[string]interface{} `json:"metadata"`
+	Type       ResultType             `json:"type"`
 }
 
 type ResultType string
@@ -74,7 +74,7 @@ func (cmd *DevReloadCommand) Execute(ctx context.Context, args []string) (*Resul
 		Output:   output,
 		ExitCode: 0,
 		Type:     ResultTypeSuccess,
-		Metadata: map[string]any{
+		Metadata: map[string]interface{}{
 			"commands_reloaded": 47,
 			"plugins_active":    3,
 			"watch_active":      true,
@@ -123,7 +123,7 @@ func (cmd *DevTestCommand) Execute(ctx context.Context, args []string) (*Result,
 		Output:   output,
 		ExitCode: 0,
 		Type:     ResultTypeSuccess,
-		Metadata: map[string]any{
+		Metadata: map[string]interface{}{
 			"test_command": testCmd,
 			"test_passed":  true,
 			"memory_used":  0,
@@ -174,7 +174,7 @@ func (cmd *DevDocsCommand) Execute(ctx context.Context, args []string) (*Result,
 		Output:   output,
 		ExitCode: 0,
 		Type:     ResultTypeSuccess,
-		Metadata: map[string]any{
+		Metadata: map[string]interface{}{
 			"docs_generated": 8,
 			"formats":        []string{"html", "markdown", "pdf"},
 		},
@@ -224,7 +224,7 @@ func (cmd *DevBuildCommand) Execute(ctx context.Context, args []string) (*Result
 		Output:   output,
 		ExitCode: 0,
 		Type:     ResultTypeSuccess,
-		Metadata: map[string]any{
+		Metadata: map[string]interface{}{
 			"platforms":       4,
 			"tests_pass":      true,
 			"vulnerabilities": 0,
@@ -280,7 +280,7 @@ func (cmd *DevProfileCommand) Execute(ctx context.Context, args []string) (*Resu
 		Output:   output,
 		ExitCode: 0,
 		Type:     ResultTypeSuccess,
-		Metadata: map[string]any{
+		Metadata: map[string]interface{}{
 			"heap_size":   "23.4MB",
 			"cpu_usage":   4.2,
 			"goroutines":  12,
@@ -361,7 +361,7 @@ func (cmd *PerfStatsCommand) Execute(ctx context.Context, args []string) (*Resul
 		Output:   output,
 		ExitCode: 0,
 		Type:     ResultTypeSuccess,
-		Metadata: map[string]any{
+		Metadata: map[string]interface{}{
 			"cpu_usage":     8.3,
 			"memory_mb":     45.2,
 			"response_time": 18,
@@ -420,7 +420,7 @@ func (cmd *PerfBenchmarkCommand) Execute(ctx context.Context, args []string) (*R
 		Output:   output,
 		ExitCode: 0,
 		Type:     ResultTypeSuccess,
-		Metadata: map[string]any{
+		Metadata: map[string]interface{}{
 			"commands_tested": 5,
 			"fastest_ms":      12,
 			"slowest_ms":      22,
@@ -472,7 +472,7 @@ func (cmd *PerfOptimizeCommand) Execute(ctx context.Context, args []string) (*Re
 		Output:   output,
 		ExitCode: 0,
 		Type:     ResultTypeSuccess,
-		Metadata: map[string]any{
+		Metadata: map[string]interface{}{
 			"optimizations":       5,
 			"speed_improvement":   15,
 			"memory_reduction":    8,
@@ -523,7 +523,7 @@ func (cmd *PerfMonitorCommand) Execute(ctx context.Context, args []string) (*Res
 		Output:   output,
 		ExitCode: 0,
 		Type:     ResultTypeSuccess,
-		Metadata: map[string]any{
+		Metadata: map[string]interface{}{
 			"monitoring":    true,
 			"interval":      "1s",
 			"alerts_active": 0,
@@ -602,7 +602,7 @@ func (cmd *CloudStatusCommand) Execute(ctx context.Context, args []string) (*Res
 		Output:   output,
 		ExitCode: 0,
 		Type:     ResultTypeSuccess,
-		Metadata: map[string]any{
+		Metadata: map[string]interface{}{
 			"providers":   3,
 			"total_cost":  2694.76,
 			"vms_running": 23,
@@ -655,7 +655,7 @@ func (cmd *CloudDeployCommand) Execute(ctx context.Context, args []string) (*Res
 		Output:   output,
 		ExitCode: 0,
 		Type:     ResultTypeSuccess,
-		Metadata: map[string]any{
+		Metadata: map[string]interface{}{
 			"app_version": "v2.1.0",
 			"providers":   3,
 			"strategy":    "blue-green",
@@ -709,7 +709,7 @@ GCP (us-central1):
 		Output:   output,
 		ExitCode: 0,
 		Type:     ResultTypeSuccess,
-		Metadata: map[string]any{
+		Metadata: map[string]interface{}{
 			"providers_monitored": 3,
 			"active_alerts":       1,
 			"metrics_collected":   12,
@@ -793,7 +793,7 @@ func (cmd *SecurityScanCommand) Execute(ctx context.Context, args []string) (*Re
 		Output:   output,
 		ExitCode: 0,
 		Type:     ResultTypeSuccess,
-		Metadata: map[string]any{
+		Metadata: map[string]interface{}{
 			"target":          target,
 			"security_score":  7.5,
 			"vulnerabilities": 6,
@@ -848,7 +848,7 @@ func (cmd *SecurityAuditCommand) Execute(ctx context.Context, args []string) (*R
 		Output:   output,
 		ExitCode: 0,
 		Type:     ResultTypeSuccess,
-		Metadata: map[string]any{
+		Metadata: map[string]interface{}{
 			"compliance_soc2": 95,
 			"compliance_iso":  92,
 			"issues_found":    3,
@@ -937,7 +937,7 @@ func (cmd *MonitorSystemCommand) Execute(ctx context.Context, args []string) (*R
 		Output:   output,
 		ExitCode: 0,
 		Type:     ResultTypeSuccess,
-		Metadata: map[string]any{
+		Metadata: map[string]interface{}{
 			"cpu_usage":    23.4,
 			"memory_usage": 67.2,
 			"processes":    4,
@@ -1001,7 +1001,7 @@ Upload:   ▁▂▃▄▃▂▁▂▃▄▃▂▁▂▃▄▃▂▁▂▃▄▃
 		Output:   output,
 		ExitCode: 0,
 		Type:     ResultTypeSuccess,
-		Metadata: map[string]any{
+		Metadata: map[string]interface{}{
 			"interfaces":  4,
 			"connections": 4,
 			"alerts":      1,
@@ -1055,7 +1055,7 @@ func (cmd *MonitorProcessesCommand) Execute(ctx context.Context, args []string)
 		Output:   output,
 		ExitCode: 0,
 		Type:     ResultTypeSuccess,
-		Metadata: map[string]any{
+		Metadata: map[string]interface{}{
 			"total_processes": 234,
 			"alerts":          3,
 		},
@@ -1118,7 +1118,7 @@ func (cmd *MonitorLogsCommand) Execute(ctx context.Context, args []string) (*Res
 		Output:   output,
 		ExitCode: 0,
 		Type:     ResultTypeSuccess,
-		Metadata: map[string]any{
+		Metadata: map[string]interface{}{
 			"log_sources":   4,
 			"total_entries": 1583,
 			"error_count":   12,
@@ -1209,7 +1209,7 @@ func (cmd *WorkflowRunCommand) Execute(ctx context.Context, args []string) (*Res
 		Output:   output,
 		ExitCode: 0,
 		Type:     ResultTypeSuccess,
-		Metadata: map[string]any{
+		Metadata: map[string]interface{}{
 			"workflow":       workflowName,
 			"steps_executed": 8,
 			"success_rate":   100.0,
@@ -1262,7 +1262,7 @@ func (cmd *WorkflowListCommand) Execute(ctx context.Context, args []string) (*Re
 		Output:   output,
 		ExitCode: 0,
 		Type:     ResultTypeSuccess,
-		Metadata: map[string]any{
+		Metadata: map[string]interface{}{
 			"total_workflows":  6,
 			"active_workflows": 4,
 			"failed_workflows": 1,
@@ -1325,7 +1325,7 @@ func (cmd *TaskScheduleCommand) Execute(ctx context.Context, args []string) (*Re
 		Output:   output,
 		ExitCode: 0,
 		Type:     ResultTypeSuccess,
-		Metadata: map[string]any{
+		Metadata: map[string]interface{}{
 			"task_name":   taskName,
 			"total_tasks": 5,
 		},
@@ -1393,7 +1393,7 @@ func (cmd *AutomationStatusCommand) Execute(ctx context.Context, args []string)
 		Output:   output,
 		ExitCode: 0,
 		Type:     ResultTypeSuccess,
-		Metadata: map[string]any{
+		Metadata: map[string]interface{}{
 			"engine_status":    "running",
 			"total_executions": 167,
 			"success_rate":     95.2,
@@ -1408,25 +1408,25 @@ func (cmd *AutomationStatusCommand) Execute(ctx context.Context, args []string)
 
 // PluginMetadata represents plugin information in the marketplace
 type PluginMetadata struct {
-	Name         string         `json:"name"`
-	Version      string         `json:"version"`
-	Author       string         `json:"author"`
-	Description  string         `json:"description"`
-	Category     string         `json:"category"`
-	Tags         []string       `json:"tags"`
-	Downloads    int64          `json:"downloads"`
-	Rating       float64        `json:"rating"`
-	Reviews      int            `json:"reviews"`
-	Size         int64          `json:"size"`
-	License      string         `json:"license"`
-	Repository   string         `json:"repository"`
-	Dependencies []string       `json:"dependencies"`
-	Platforms    []string       `json:"platforms"`
-	LastUpdate   string         `json:"last_update"`
-	Verified     bool           `json:"verified"`
-	Featured     bool           `json:"featured"`
-	Commands     []string       `json:"commands"`
-	Metadata     map[string]any `json:"metadata"`
+	Name         string                 `json:"name"`
+	Version      string                 `json:"version"`
+	Author       string                 `json:"author"`
+	Description  string                 `json:"description"`
+	Category     string                 `json:"category"`
+	Tags         []string               `json:"tags"`
+	Downloads    int64                  `json:"downloads"`
+	Rating       float64                `json:"rating"`
+	Reviews      int                    `json:"reviews"`
+	Size         int64                  `json:"size"`
+	License      string                 `json:"license"`
+	Repository   string                 `json:"repository"`
+	Dependencies []string               `json:"dependencies"`
+	Platforms    []string               `json:"platforms"`
+	LastUpdate   string                 `json:"last_update"`
+	Verified     bool                   `json:"verified"`
+	Featured     bool                   `json:"featured"`
+	Commands     []string               `json:"commands"`
+	Metadata     map[string]interface{} `json:"metadata"`
 }
 
 // CommunityStats represents community engagement metrics
@@ -3,6 +3,7 @@ package app
 import (
 	"context"
 	"fmt"
+	"os"
 	"time"
 
 	"suppercommand/internal/commands"
@@ -11,16 +12,22 @@ import (
 	"suppercommand/internal/commands/system"
 	"suppercommand/internal/config"
 	"suppercommand/internal/monitoring"
+	"suppercommand/internal/security"
 	"suppercommand/internal/shell"
 )
 
 // Application orchestrates the entire shell lifecycle
 type Application struct {
-	config   *config.Config
-	shell    shell.Shell
-	registry *commands.Registry
-	monitor  monitoring.Monitor
-	logger   monitoring.Logger
+	config    *config.Config
+	shell     shell.Shell
+	registry  *commands.Registry
+	monitor   monitoring.Monitor
+	logger    monitoring.Logger
+	scheduler *system.Scheduler
+	audit     *security.AuditLog
+	jobs      *commands.JobTable
+
+	skipStartupScripts bool
 }
 
 // NewApplication creates a new application instance with dependency injection
@@ -34,12 +41,51 @@ func (a *Application) Initialize(ctx context.Context) error {
 	loader := config.NewLoader()
 	a.config = loader.LoadWithDefaults()
 
+	// A user config file overrides the defaults when present
+	var configErr error
+	if _, err := os.Stat(config.DefaultPath()); err == nil {
+		if cfg, err := loader.Load(config.DefaultPath()); err == nil {
+			a.config = cfg
+		} else {
+			configErr = err
+		}
+	}
+
 	// Initialize monitoring
 	a.logger = monitoring.NewLogger(a.config.Monitoring)
 	a.monitor = monitoring.NewMonitor(a.config.Monitoring, a.logger)
 
+	if configErr != nil {
+		a.logger.Warn("Ignoring invalid config file",
+			monitoring.Field{Key: "path", Value: config.DefaultPath()},
+			monitoring.Field{Key: "error", Value: configErr.Error()})
+	}
+
+	// Colors are turned off for terminals that can't show them, and decorations
+	// fall back to ASCII unless the config decides; diag terminal reports what
+	// was detected
+	commands.ApplyTerminalDefaults(commands.DetectTerminal())
+	switch a.config.Shell.Decorations {
+	case "unicode":
+		commands.SetASCIIDecorations(false)
+	case "ascii":
+		commands.SetASCIIDecorations(true)
+	}
+
 	// Initialize command registry
 	a.registry = commands.NewRegistry(a.logger)
+	a.registry.SetGlobNoMatch(a.config.Shell.GlobNoMatch)
+
+	// Every command the registry runs goes to the audit log, as do external
+	// commands run at the prompt or with -c
+	a.audit = security.NewAuditLog(a.config.Security.Audit)
+	a.registry.SetAuditLog(a.audit)
+
+	// Scheduled tasks dispatch through the same registry as the prompt
+	a.scheduler = system.NewScheduler(a.registry)
+
+	// Commands ended with & run as jobs that jobs, fg and kill manage
+	a.jobs = commands.NewJobTable()
 
 	// Register built-in commands
 	if err := a.registerBuiltinCommands(); err != nil {
@@ -47,13 +93,18 @@ func (a *Application) Initialize(ctx context.Context) error {
 	}
 
 	// Initialize shell
-	a.shell = shell.NewShell(a.config.Shell, a.registry, a.monitor, a.logger)
+	a.shell = shell.NewShell(a.config.Shell, a.registry, a.monitor, a.logger, a.audit, a.jobs)
 
 	// Initialize shell
 	if err := a.shell.Initialize(ctx); err != nil {
 		return fmt.Errorf("failed to initialize shell: %w", err)
 	}
 
+	// The rc file and startup directory run once every command is available
+	if !a.skipStartupScripts {
+		a.runStartupScripts(ctx)
+	}
+
 	a.logger.Info("Application initialized successfully")
 	return nil
 }
@@ -67,6 +118,11 @@ func (a *Application) Run(ctx context.Context) error {
 		return fmt.Errorf("failed to start monitoring: %w", err)
 	}
 
+	// Start background scheduler
+	if err := a.scheduler.Start(ctx); err != nil {
+		return fmt.Errorf("failed to start scheduler: %w", err)
+	}
+
 	// Run shell
 	return a.shell.Run(ctx)
 }
@@ -76,6 +132,8 @@ func (a *Application) ExecuteCommand(ctx context.Context, input string) (*shell.
 	if a.shell == nil {
 		return nil, fmt.Errorf("shell not initialized")
 	}
+	// The process exits straight after, so the entry has to be on disk first
+	defer a.audit.Flush()
 	return a.shell.ExecuteCommand(ctx, input)
 }
 
@@ -94,6 +152,16 @@ func (a *Application) Shutdown(ctx context.Context) error {
 		}
 	}
 
+	if a.scheduler != nil {
+		if err := a.scheduler.Stop(shutdownCtx); err != nil {
+			a.logger.Error("Failed to stop scheduler", err)
+		}
+	}
+
+	if err := a.audit.Close(); err != nil {
+		a.logger.Error("Failed to close audit log", err)
+	}
+
 	if a.monitor != nil {
 		if err := a.monitor.Stop(shutdownCtx); err != nil {
 			a.logger.Error("Failed to stop monitoring", err)
@@ -111,15 +179,43 @@ func (a *Application) registerBuiltinCommands() error {
 		system.NewHelpCommand(a.registry),
 		system.NewClearCommand(),
 		system.NewSysInfoCommand(),
+		system.NewBatteryCommand(),
+		system.NewSensorsCommand(),
 		system.NewWhoamiCommand(),
 		system.NewHostnameCommand(),
 		system.NewExitCommand(),
 		system.NewVerCommand(),
 		system.NewHelpHTMLCommand(a.registry),
 		system.NewWinUpdateCommand(),
+		system.NewLogtailCommand(),
+		system.NewSnapshotCommand(),
+		system.NewUserCommand(),
+		system.NewPrivCommand(a.registry),
+		system.NewRunAsCommand(a.registry),
+		system.NewAuditCommand(a.audit),
+		system.NewCrontabCommand(),
 		system.NewKillTaskCommand(),
+		system.NewJobsCommand(a.jobs),
+		system.NewFgCommand(a.jobs),
+		system.NewKillCommand(a.jobs),
+		system.NewRetryCommand(a.registry),
+		system.NewEachCommand(a.registry),
+		system.NewSourceCommand(a.registry, a.config.Security.Scripts),
 		system.NewLookupCommand(a.registry),
 		system.NewSmartHistoryCommand(a.registry),
+		system.NewFavCommand(a.registry),
+		system.NewProfileCommand(),
+		system.NewTemplateCommand(),
+		system.NewJSONCommand(),
+		system.NewHighlightCommand(),
+		system.NewCompletionCommand(a.registry),
+		system.NewInstallCompletionCommand(a.registry),
+		system.NewCredCommand(),
+		system.NewScheduleCommand(a.scheduler),
+		system.NewBenchmarkCommand(a.registry),
+		system.NewNotifyCommand(),
+		system.NewDiagCommand(),
+		system.NewConfigCommand(a.scheduler),
 	}
 
 	// Filesystem commands
@@ -129,12 +225,16 @@ func (a *Application) registerBuiltinCommands() error {
 		filesystem.NewDirCommand(),
 		filesystem.NewEchoCommand(),
 		filesystem.NewCdCommand(),
+		filesystem.NewZCommand(),
 		filesystem.NewCatCommand(),
 		filesystem.NewMkdirCommand(),
 		filesystem.NewRmCommand(),
 		filesystem.NewRmdirCommand(),
 		filesystem.NewCpCommand(),
 		filesystem.NewMvCommand(),
+		filesystem.NewUndoCommand(),
+		filesystem.NewBrowseCommand(a.registry),
+		filesystem.NewWatchdirCommand(a.registry),
 	}
 
 	// Networking commands
@@ -143,7 +243,9 @@ func (a *Application) registerBuiltinCommands() error {
 		networking.NewTracertCommand(),
 		networking.NewNslookupCommand(),
 		networking.NewNetstatCommand(),
+		networking.NewTopConnectionsCommand(),
 		networking.NewPortscanCommand(),
+		networking.NewWaitPortCommand(),
 		networking.NewIpconfigCommand(),
 		networking.NewWgetCommand(),
 		networking.NewArpCommand(),
@@ -151,11 +253,21 @@ func (a *Application) registerBuiltinCommands() error {
 		networking.NewSpeedtestCommand(),
 		networking.NewNetdiscoverCommand(),
 		networking.NewSniffCommand(),
+		networking.NewTraceCommand(),
+		networking.NewReplayCommand(),
+		networking.NewCapstatsCommand(),
+		networking.NewInterfacesCommand(),
+		networking.NewCapabilitiesCommand(),
+		networking.NewMtuCommand(),
+		networking.NewBandwidthCommand(),
+		networking.NewIpinfoCommand(a.config.Networking.IPInfo),
 		networking.NewFastcpSendCommand(),
 		networking.NewFastcpRecvCommand(),
 		networking.NewFastcpBackupCommand(),
 		networking.NewFastcpRestoreCommand(),
+		networking.NewFastcpVerifyCommand(),
 		networking.NewFastcpDedupCommand(),
+		networking.NewFastcpKeyCommand(),
 	}
 
 	// Management commands
@@ -0,0 +1,79 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/config"
+	"suppercommand/internal/monitoring"
+)
+
+// SkipStartupScripts keeps Initialize from running the rc file and startup
+// directory, for --no-rc and for commands run once with -c
+func (a *Application) SkipStartupScripts() {
+	a.skipStartupScripts = true
+}
+
+// startupScripts returns the scripts run at startup: the rc file when it
+// exists, then the .ss files of the startup directory in name order
+func startupScripts(shell config.ShellConfig, logger monitoring.Logger) []string {
+	var scripts []string
+	if shell.RCFile != "" && shell.RCFile != "none" {
+		rc := config.ExpandHome(shell.RCFile)
+		if info, err := os.Stat(rc); err == nil && !info.IsDir() {
+			scripts = append(scripts, rc)
+		}
+	}
+
+	if shell.StartupDir == "" {
+		return scripts
+	}
+	dir := config.ExpandHome(shell.StartupDir)
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		logger.Warn("Cannot read the startup script directory",
+			monitoring.Field{Key: "dir", Value: dir},
+			monitoring.Field{Key: "error", Value: err.Error()})
+		return scripts
+	}
+	for _, file := range files {
+		if !file.IsDir() && strings.HasSuffix(file.Name(), ".ss") {
+			scripts = append(scripts, filepath.Join(dir, file.Name()))
+		}
+	}
+	return scripts
+}
+
+// runStartupScripts runs each startup script with source, so the script guard
+// in security.scripts applies to them as to any other script. A failing
+// script is logged and doesn't stop the shell or the scripts after it.
+func (a *Application) runStartupScripts(ctx context.Context) {
+	for _, script := range startupScripts(a.config.Shell, a.logger) {
+		startTime := time.Now()
+		result, err := a.registry.Execute(ctx, "source", commands.ParseArguments([]string{script}))
+		if result == nil {
+			result = commands.ErrorResult("", err, startTime)
+		}
+		fmt.Fprint(os.Stderr, commands.Decorate(result.Stderr))
+
+		fields := []monitoring.Field{
+			{Key: "script", Value: script},
+			{Key: "exit_code", Value: result.ExitCode},
+			{Key: "duration", Value: time.Since(startTime)},
+		}
+		if result.ExitCode != 0 || result.Error != nil {
+			if result.Error != nil {
+				fields = append(fields, monitoring.Field{Key: "error", Value: result.Error.Error()})
+			}
+			a.logger.Warn("Startup script failed", fields...)
+			continue
+		}
+		a.logger.Info("Ran startup script", fields...)
+	}
+}
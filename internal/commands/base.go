@@ -18,6 +18,29 @@ type Command interface {
 	SupportedPlatforms() []string
 }
 
+// ArgumentCompleter is implemented by commands that can suggest their own
+// arguments, such as the names of saved items. args holds the words typed after
+// the command name; the last one is the word being completed and may be empty.
+type ArgumentCompleter interface {
+	CompleteArguments(args []string) []string
+}
+
+// RankedArgumentCompleter is implemented by argument completers whose
+// suggestions match the word being completed some other way than by prefix,
+// such as directories ranked by a fragment of their path. Their suggestions
+// are offered as given and in order, replacing the word.
+type RankedArgumentCompleter interface {
+	ArgumentCompleter
+	RanksArguments() bool
+}
+
+// ElevationPolicy is implemented by commands that need elevation for only some
+// of their arguments, such as a subcommand that changes the system. Commands
+// without it need elevation whenever RequiresElevation is true.
+type ElevationPolicy interface {
+	NeedsElevation(args *Arguments) bool
+}
+
 // Arguments contains parsed command arguments
 type Arguments struct {
 	Raw     []string
@@ -28,7 +51,10 @@ type Arguments struct {
 
 // Result contains the result of command execution
 type Result struct {
-	Output     string
+	Output string
+	// Stderr holds diagnostics such as warnings and notes on what was done,
+	// kept apart from the results in Output so that only those are piped on
+	Stderr     string
 	Error      error
 	ExitCode   int
 	Duration   time.Duration
@@ -140,3 +166,16 @@ func findEquals(s string) int {
 	}
 	return -1
 }
+
+// Sleep pauses for d or until ctx is cancelled, returning ctx.Err() when interrupted
+func Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,122 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ChainOperator joins a command in a chain to the one before it
+type ChainOperator string
+
+const (
+	// ChainThen runs the command whatever the previous one returned, as with ;
+	ChainThen ChainOperator = ";"
+	// ChainAnd runs the command only if the previous one succeeded
+	ChainAnd ChainOperator = "&&"
+	// ChainOr runs the command only if the previous one failed
+	ChainOr ChainOperator = "||"
+)
+
+// ChainStep is one command of a chained command line
+type ChainStep struct {
+	// Operator joins the command to the previous one; the first command's is ChainThen
+	Operator ChainOperator
+	// Line is the command as typed, quotes included
+	Line string
+	// Background is set on the first command of a list ended by &. That command
+	// and the && and || commands after it run together as a background job.
+	Background bool
+}
+
+// ShouldRun reports whether the step runs given the exit code of the last
+// command that ran. && and || bind equally and from the left, as in sh, so
+// "a && b || c" runs c when either a or b fails.
+func (s ChainStep) ShouldRun(lastExitCode int) bool {
+	switch s.Operator {
+	case ChainAnd:
+		return lastExitCode == 0
+	case ChainOr:
+		return lastExitCode != 0
+	}
+	return true
+}
+
+// SplitChain splits a command line on the &&, || ; and & outside quotes. &&
+// and || bind tighter than ; and &, so "a && b & c" runs a && b in the
+// background and then c. A line without operators is returned as a single
+// step. An operator with no command before it, or && and || with none after,
+// is a syntax error; a trailing ; or & is allowed.
+func SplitChain(input string) ([]ChainStep, error) {
+	var steps []ChainStep
+	var current strings.Builder
+	operator := ChainThen
+	listStart := 0
+	var quote rune
+
+	finish := func(symbol string, next ChainOperator) error {
+		line := strings.TrimSpace(current.String())
+		if line == "" {
+			return fmt.Errorf("syntax error near '%s'", symbol)
+		}
+		steps = append(steps, ChainStep{Operator: operator, Line: line})
+		current.Reset()
+		operator = next
+		return nil
+	}
+
+	runes := []rune(input)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else if quote == '"' && r == '\\' && i+1 < len(runes) && (runes[i+1] == '"' || runes[i+1] == '\\') {
+				// Keep the escape for SplitCommandWords, and skip the quote it escapes
+				current.WriteRune(r)
+				i++
+				r = runes[i]
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == ';':
+			if err := finish(";", ChainThen); err != nil {
+				return nil, err
+			}
+			listStart = len(steps)
+			continue
+		case (r == '&' || r == '|') && i+1 < len(runes) && runes[i+1] == r:
+			next := ChainAnd
+			if r == '|' {
+				next = ChainOr
+			}
+			if err := finish(string(next), next); err != nil {
+				return nil, err
+			}
+			i++
+			continue
+		case r == '&' && !isRedirect(runes, i):
+			if err := finish("&", ChainThen); err != nil {
+				return nil, err
+			}
+			steps[listStart].Background = true
+			listStart = len(steps)
+			continue
+		}
+		current.WriteRune(r)
+	}
+
+	if line := strings.TrimSpace(current.String()); line != "" {
+		steps = append(steps, ChainStep{Operator: operator, Line: line})
+	} else if operator != ChainThen {
+		return nil, fmt.Errorf("syntax error: '%s' needs a command after it", operator)
+	}
+	return steps, nil
+}
+
+// isRedirect reports whether the & at i belongs to a redirection such as 2>&1
+// or &> for the system shell, rather than sending a command to the background
+func isRedirect(runes []rune, i int) bool {
+	return (i > 0 && (runes[i-1] == '>' || runes[i-1] == '<')) || (i+1 < len(runes) && runes[i+1] == '>')
+}
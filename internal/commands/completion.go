@@ -263,30 +263,66 @@ func (c *CompletionEngine) GetSimilarCommands(input string, maxResults int) []st
 // GetAutoCompletions returns predefined auto-completion mappings
 func GetAutoCompletions() map[string][]string {
 	return map[string][]string{
-		"firewall":        {"status", "enable", "disable", "rules", "help"},
-		"firewall rules":  {"list", "add", "remove"},
-		"perf":            {"analyze", "monitor", "report", "baseline", "help"},
-		"perf baseline":   {"create", "list", "delete"},
-		"server":          {"health", "services", "users", "alerts", "backup", "session", "help"},
-		"server services": {"list", "start", "stop", "restart"},
-		"server session":  {"list", "kill"},
-		"remote":          {"list", "add", "remove", "exec", "cluster", "sync", "help"},
-		"remote cluster":  {"list", "create", "delete"},
-		"remote sync":     {"list", "create", "execute"},
-		"ping":            {"-c", "--count", "-t", "--timeout", "-i", "--interval"},
-		"tracert":         {"-m", "--max-hops", "-t", "--timeout"},
-		"nslookup":        {"-s", "--server"},
-		"netstat":         {"-tcp", "--tcp", "-udp", "--udp", "-state", "-p", "--process", "--csv", "--json", "--sort", "--desc", "--group"},
-		"portscan":        {"-p", "--ports", "-t", "--timeout", "--top-ports"},
-		"sniff":           {"-i", "--interface", "-c", "--count", "-p", "--protocol", "-s", "--source", "-d", "--dest", "--port", "-v", "--verbose", "--hex", "--save", "--continuous", "-t", "--timeout"},
-		"wget":            {"-v", "--verbose"},
-		"arp":             {"-a", "--all", "-d", "--delete"},
-		"route":           {"print", "show", "add", "delete", "-4", "--ipv4", "-6", "--ipv6"},
-		"speedtest":       {"-s", "--simple", "-q", "--quiet", "--download-only", "--upload-only"},
-		"sysinfo":         {"-v", "--verbose", "--cpu", "--memory", "--disk", "--network"},
-		"killtask":        {"-f", "--force", "-t", "--tree"},
-		"lookup":          {"-m", "--menu", "-s", "--similar", "-c", "--categories", "-t", "--task"},
-		"ver":             {"-v", "--verbose"},
+		"firewall":           {"status", "enable", "disable", "rules", "help"},
+		"firewall rules":     {"list", "add", "remove"},
+		"perf":               {"analyze", "monitor", "report", "baseline", "help"},
+		"perf baseline":      {"create", "list", "delete"},
+		"server":             {"health", "services", "users", "eventlog", "alerts", "backup", "session", "help"},
+		"server services":    {"list", "start", "stop", "restart"},
+		"server session":     {"list", "kill"},
+		"remote":             {"list", "add", "remove", "exec", "cluster", "sync", "help"},
+		"remote cluster":     {"list", "create", "delete"},
+		"remote sync":        {"list", "create", "execute"},
+		"ping":               {"-c", "--count", "-t", "--timeout", "-i", "--interval"},
+		"tracert":            {"-m", "--max-hops", "-t", "--timeout"},
+		"nslookup":           {"-s", "--server"},
+		"netstat":            {"-a", "-n", "-p", "-r", "-s", "--live", "--interval", "--count", "--filter", "--group", "--watch-new", "--closed", "--remote", "--notify", "--resolve", "--resolve-dns"},
+		"portscan":           {"-p", "--ports", "-t", "--timeout", "--top-ports"},
+		"wait-port":          {"-t", "--timeout", "-i", "--interval", "--invert"},
+		"trace":              {"-p", "--pid", "-e", "--events", "--path", "--failed", "--lib", "-c", "--count", "-t", "--duration", "--interval", "--json"},
+		"sniff":              {"-i", "--interface", "-c", "--count", "-p", "--protocol", "-s", "--source", "-d", "--dest", "--port", "-v", "--verbose", "--hex", "--save", "--continuous", "-t", "--timeout", "--summary", "--top", "--proto", "--host", "-f", "--filter", "--rotate-size", "--rotate-time", "--keep", "--jsonl", "--jsonl-file"},
+		"replay":             {"--send", "--fast", "-f", "--filter", "--host", "--port", "-p", "--proto", "-c", "--count", "-v", "--verbose", "--hex"},
+		"capstats":           {"-n", "--top", "--json"},
+		"top-connections":    {"--interval", "--count", "--filter", "--limit"},
+		"interfaces":         {"--up", "--json"},
+		"capabilities":       {"--json"},
+		"wget":               {"-v", "--verbose"},
+		"arp":                {"-a", "--all", "-d", "--delete"},
+		"route":              {"print", "show", "add", "delete", "-4", "--ipv4", "-6", "--ipv6"},
+		"speedtest":          {"-s", "--simple", "-q", "--quiet", "--download-only", "--upload-only"},
+		"sysinfo":            {"gpu", "sensors", "--json", "-v", "--verbose", "--cpu", "--memory", "--disk", "--network"},
+		"killtask":           {"-f", "--force", "-t", "--tree"},
+		"jobs":               {"--json"},
+		"kill":               {"-f", "--force"},
+		"retry":              {"-n", "--attempts", "-d", "--delay", "-b", "--backoff", "--max-delay", "--until-success", "--until-failure"},
+		"each":               {"-f", "--file", "-s", "--split", "-n", "--batch", "-P", "--parallel", "--fail-fast"},
+		"source":             {"--safe", "--confirm"},
+		"snapshot":           {"save", "list", "show", "diff", "remove"},
+		"user":               {"list", "disable", "enable", "passwd", "--all", "--json"},
+		"crontab":            {"list", "--all", "--json"},
+		"priv":               {"status", "elevate"},
+		"runas":              {"--cred"},
+		"audit":              {"status", "-n", "--lines", "--user", "--grep", "--failed", "-f", "--follow", "--json"},
+		"battery":            {"--json"},
+		"diag":               {"terminal", "--output-format"},
+		"sensors":            {"--json"},
+		"logtail":            {"--source", "-n", "--lines", "--level", "--since", "-f", "--follow", "--json"},
+		"lookup":             {"-m", "--menu", "-s", "--similar", "-c", "--categories", "-t", "--task", "-e", "--examples"},
+		"fav":                {"list", "add", "run", "edit", "remove"},
+		"profile":            {"list", "save", "load", "delete", "-e", "--env"},
+		"rm":                 {"-r", "--recursive", "-f", "--force"},
+		"rmdir":              {"-r", "--recursive", "-f", "--force"},
+		"undo":               {"list", "clear"},
+		"z":                  {"-l", "--list"},
+		"watchdir":           {"-c", "--on-change", "-d", "--debounce", "-i", "--ignore", "--no-recursive", "--chmod"},
+		"template":           {"list", "new", "--force"},
+		"template new":       {"script", "config", "backup"},
+		"json":               {"merge", "get", "set", "-o", "--output", "--string"},
+		"highlight":          {"-i", "--ignore-case", "-F", "--fixed-strings", "--line", "-f", "--file"},
+		"history export":     {"json", "csv", "txt", "--from", "--to", "--last", "--skip-failed", "--force"},
+		"completion":         {"bash", "zsh", "fish", "powershell"},
+		"install-completion": {"bash", "zsh", "fish", "powershell", "--path"},
+		"ver":                {"-v", "--verbose"},
 	}
 }
 
@@ -300,52 +336,88 @@ func GetCommandHelp() map[string]string {
 		"remote":   "Remote server management and SSH operations. Add servers, execute commands remotely, and manage distributed systems.",
 
 		// Network Commands
-		"ping":        "Send ICMP echo requests to test network connectivity and measure response times to remote hosts.",
-		"tracert":     "Trace the network route packets take to reach a destination, showing each hop along the path.",
-		"nslookup":    "Query DNS servers for domain name information, IP addresses, and various DNS record types.",
-		"netstat":     "Display active network connections, listening ports, and network statistics with filtering options.",
-		"portscan":    "Scan remote hosts for open ports and services, useful for network security assessment.",
-		"sniff":       "Capture and analyze network packets in real-time with protocol filtering and detailed inspection.",
-		"wget":        "Download files from web servers using HTTP/HTTPS with progress monitoring and resume capability.",
-		"arp":         "Display and modify the ARP (Address Resolution Protocol) table showing IP to MAC address mappings.",
-		"route":       "Display and modify the system routing table to control network packet forwarding.",
-		"speedtest":   "Test internet connection speed by measuring download/upload bandwidth and latency.",
-		"ipconfig":    "Display network interface configuration including IP addresses, subnet masks, and gateways.",
-		"netdiscover": "Discover active devices on the local network using ARP requests and network scanning.",
+		"ping":            "Send ICMP echo requests to test network connectivity and measure response times to remote hosts.",
+		"tracert":         "Trace the network route packets take to reach a destination, showing each hop along the path.",
+		"nslookup":        "Query DNS servers for domain name information, IP addresses, and various DNS record types.",
+		"netstat":         "Display active network connections, listening ports, and network statistics with filtering options.",
+		"portscan":        "Scan remote hosts for open ports and services, useful for network security assessment.",
+		"wait-port":       "Block until a TCP port accepts connections, or stops accepting them with --invert, failing after a timeout. Use it to wait for a service in scripts.",
+		"sniff":           "Capture and analyze network packets in real-time with protocol filtering and detailed inspection.",
+		"trace":           "Follow the files a process opens and the connections it makes, through strace or ltrace on Linux and Sysinternals Handle on Windows.",
+		"replay":          "Show the packets of a pcap or pcapng capture like sniff does, or send them onto an interface again with their original timing.",
+		"top-connections": "Show which processes use the most network bandwidth, refreshing live like nethogs. Traffic is attributed from per-connection byte counters.",
+		"capstats":        "Summarize saved pcap captures offline: protocol breakdown, top talkers, conversations and time span, side by side for several files.",
+		"interfaces":      "List network interfaces with their index, state, MTU and addresses; sniff, replay, mtu and netdiscover accept any of index, name or address.",
+		"capabilities":    "Check whether packet capture, raw frame sending, syscall tracing and the system network tools work here, with the fix for each one that doesn't.",
+		"wget":            "Download files from web servers using HTTP/HTTPS with progress monitoring and resume capability.",
+		"arp":             "Display and modify the ARP (Address Resolution Protocol) table showing IP to MAC address mappings.",
+		"route":           "Display and modify the system routing table to control network packet forwarding.",
+		"speedtest":       "Test internet connection speed by measuring download/upload bandwidth and latency.",
+		"ipconfig":        "Display network interface configuration including IP addresses, subnet masks, and gateways.",
+		"netdiscover":     "Discover active devices on the local network using ARP requests and network scanning.",
 
 		// File System Commands
-		"ls":    "List directory contents with various formatting options and file information display.",
-		"dir":   "Windows-style directory listing showing files and folders with detailed information.",
-		"cat":   "Display the contents of text files to the console with optional line numbering.",
-		"cp":    "Copy files and directories from source to destination with preservation of attributes.",
-		"mv":    "Move or rename files and directories, supporting both local and cross-directory operations.",
-		"rm":    "Remove files and directories with support for wildcards and recursive deletion.",
-		"mkdir": "Create new directories with optional parent directory creation.",
-		"rmdir": "Remove empty directories or recursively delete directory trees.",
-		"pwd":   "Print the current working directory path to show your current location.",
-		"cd":    "Change the current working directory to navigate the file system.",
+		"ls":       "List directory contents with various formatting options and file information display.",
+		"dir":      "Windows-style directory listing showing files and folders with detailed information.",
+		"cat":      "Display the contents of text files to the console with optional line numbering.",
+		"cp":       "Copy files and directories from source to destination with preservation of attributes.",
+		"mv":       "Move or rename files and directories, supporting both local and cross-directory operations.",
+		"rm":       "Remove files and directories with support for wildcards and recursive deletion.",
+		"mkdir":    "Create new directories with optional parent directory creation.",
+		"rmdir":    "Remove empty directories or recursively delete directory trees.",
+		"undo":     "Revert the last rm, rmdir or mv by restoring files from the trash or moving them back.",
+		"browse":   "Pick files in an interactive browser and print them or pass them to a command such as cp or fastcp-send.",
+		"watchdir": "Watch a directory tree and print changes live, optionally running a command such as fastcp-send once they settle.",
+		"pwd":      "Print the current working directory path to show your current location.",
+		"cd":       "Change the current working directory to navigate the file system.",
+		"z":        "Jump to the most frequently and recently visited directory matching part of its path, as cd records them.",
 
 		// System Commands
 		"sysinfo":   "Display comprehensive system information including hardware, OS, and performance metrics.",
 		"killtask":  "Terminate running processes by name or PID with force termination options.",
+		"jobs":      "List the background jobs started by ending a command with &, with their state and run time.",
+		"fg":        "Bring a background job to the foreground, showing its output until it finishes.",
+		"kill":      "Stop background jobs given as %id, or terminate processes by PID or name like killtask.",
+		"retry":     "Re-run a command until it succeeds, with a delay and optional backoff between attempts, e.g. to wait for a host to come up.",
+		"each":      "Run a command for every line read from stdin or a file, like xargs, substituting the item for {}, in batches and in parallel.",
+		"source":    "Run the commands in a .ss script line by line, with --safe blocking destructive commands such as rm, kill and firewall disable.",
 		"whoami":    "Display the current user account name and authentication context.",
 		"hostname":  "Show the system hostname and network identification information.",
 		"ver":       "Display SuperShell version information and build details.",
+		"diag":      "Check the terminal's color, UTF-8 and window size support and print a rendering test of SuperShell's symbols.",
 		"clear":     "Clear the terminal screen and reset the display for better readability.",
 		"echo":      "Print text to the console, useful for displaying messages and variables.",
 		"winupdate": "Manage Windows Update operations including checking for and installing updates.",
+		"snapshot":  "Save installed packages, running services, listening ports and network settings, then diff against them later.",
+		"user":      "List local accounts with their status and last logon, and disable, enable or reset them (needs elevation).",
+		"priv":      "Show whether the shell is elevated and run a command, or a whole shell, with administrator/root privileges.",
+		"runas":     "Run a command as a different user, through sudo -u or a Windows logon with a stored or prompted password.",
+		"audit":     "Show and follow the audit log of executed commands, with who ran them, where and how they exited.",
+		"crontab":   "List the tasks the system has scheduled: crontabs and systemd timers on Linux, Task Scheduler on Windows.",
+		"battery":   "Show battery charge, charging state, time remaining and the power plan or CPU governor.",
+		"sensors":   "Show temperature and fan sensors with their high and critical thresholds, coloring hot readings red.",
+		"logtail":   "Show and follow system logs from the journal, log files or the Windows Event Log, filtered by level, time and text.",
 
 		// Help and Utility Commands
-		"help":   "Display comprehensive help information for all commands with detailed usage examples.",
-		"lookup": "Interactive command discovery system with search, categorization, and suggestion features.",
-		"exit":   "Exit the SuperShell application and return to the system command prompt.",
+		"help":               "Display comprehensive help information for all commands with detailed usage examples.",
+		"lookup":             "Interactive command discovery system with search, categorization, and suggestion features.",
+		"fav":                "Save complete command lines under a label, list them as a numbered menu and run one with fav run <n>.",
+		"profile":            "Save the working directory, environment changes, bookmarks and favorites as a named profile and switch back with profile load.",
+		"template":           "Write a starter automation script, a documented settings file or a fastcp backup script to get going quickly.",
+		"json":               "Merge JSON files as a merge patch, and get or set values by path such as servers[0].host, keeping the file's layout.",
+		"highlight":          "Color the parts of piped input matching regex patterns, such as ERROR:red WARN:yellow, passing every line through unlike grep.",
+		"completion":         "Print a bash, zsh, fish or PowerShell script that tab-completes commands and flags after supershell -c.",
+		"install-completion": "Write the completion script where your outer shell loads it and show any line to add to its startup file.",
+		"exit":               "Exit the SuperShell application and return to the system command prompt.",
 
 		// FastCP Commands
-		"fastcp-send":    "Ultra-fast file transfer sender with encryption, compression, and resume capability.",
+		"fastcp-send":    "Ultra-fast file transfer sender with encryption, compression, delta transfers, block deduplication across files and transfers, single-stream archives of many small files and continuous sync of a changing directory.",
 		"fastcp-recv":    "Ultra-fast file transfer receiver with automatic decompression and verification.",
 		"fastcp-backup":  "Create encrypted, compressed backups with deduplication and cloud storage support.",
 		"fastcp-restore": "Restore files from FastCP backups with integrity verification and selective recovery.",
+		"fastcp-verify":  "Check that a backup in a bucket matches its manifest without restoring it.",
 		"fastcp-dedup":   "Manage file deduplication to optimize storage usage and backup efficiency.",
+		"fastcp-key":     "Generate a strong random transfer key or word passphrase, optionally saved as a credential and shown as a QR code.",
 	}
 }
 
@@ -354,12 +426,12 @@ func GetCommandCategories() map[string][]string {
 	return map[string][]string{
 		"🔥 Security & Firewall":    {"firewall"},
 		"⚡ Performance Monitoring": {"perf"},
-		"🖥️ Server Management":     {"server", "sysinfo", "killtask", "winupdate"},
+		"🖥️ Server Management":     {"server", "sysinfo", "killtask", "jobs", "fg", "kill", "retry", "each", "source", "winupdate", "logtail", "snapshot", "user", "priv", "runas", "audit", "crontab"},
 		"🌐 Remote Administration":  {"remote"},
-		"🌐 Network Tools":          {"ping", "tracert", "nslookup", "netstat", "portscan", "sniff", "wget", "arp", "route", "speedtest", "ipconfig", "netdiscover"},
-		"📁 File Operations":        {"ls", "dir", "cat", "cp", "mv", "rm", "mkdir", "rmdir", "pwd", "cd"},
-		"⚙️ System Information":    {"whoami", "hostname", "sysinfo", "ver", "clear", "echo"},
-		"🔍 Help & Discovery":       {"help", "lookup", "exit"},
-		"🚀 FastCP File Transfer":   {"fastcp-send", "fastcp-recv", "fastcp-backup", "fastcp-restore", "fastcp-dedup"},
+		"🌐 Network Tools":          {"ping", "tracert", "nslookup", "netstat", "top-connections", "portscan", "wait-port", "sniff", "trace", "replay", "capstats", "wget", "arp", "route", "speedtest", "ipconfig", "interfaces", "capabilities", "netdiscover"},
+		"📁 File Operations":        {"ls", "dir", "cat", "cp", "mv", "rm", "mkdir", "rmdir", "undo", "browse", "watchdir", "json", "highlight", "pwd", "cd", "z"},
+		"⚙️ System Information":    {"whoami", "hostname", "sysinfo", "battery", "sensors", "diag", "ver", "clear", "echo"},
+		"🔍 Help & Discovery":       {"help", "lookup", "fav", "profile", "template", "completion", "install-completion", "exit"},
+		"🚀 FastCP File Transfer":   {"fastcp-send", "fastcp-recv", "fastcp-backup", "fastcp-restore", "fastcp-verify", "fastcp-dedup", "fastcp-key"},
 	}
 }
@@ -0,0 +1,158 @@
+package commands
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// asciiDecorations is 1 while decorations are shown as plain ASCII
+var asciiDecorations int32
+
+// SetASCIIDecorations switches every command's box drawing, emoji and progress
+// bars to plain ASCII, or back. The shell applies it where output is printed,
+// so commands keep writing the Unicode forms.
+func SetASCIIDecorations(on bool) {
+	var value int32
+	if on {
+		value = 1
+	}
+	atomic.StoreInt32(&asciiDecorations, value)
+}
+
+// ASCIIDecorations reports whether decorations are shown as plain ASCII
+func ASCIIDecorations() bool {
+	return atomic.LoadInt32(&asciiDecorations) == 1
+}
+
+// decorationFallbacks is the ASCII form of each Unicode decoration commands
+// use. Box drawing, block and braille characters missing here fall back by
+// range, and other pictographs are dropped, see ASCIIFallback.
+var decorationFallbacks = map[rune]string{
+	// Rules and tables
+	'─': "-", '━': "-", '═': "=", '│': "|", '┃': "|", '║': "|",
+	'┌': "+", '┐': "+", '└': "+", '┘': "+", '├': "+", '┤': "+", '┬': "+", '┴': "+", '┼': "+",
+	'╔': "+", '╗': "+", '╚': "+", '╝': "+", '╠': "+", '╣': "+", '╦': "+", '╩': "+", '╬': "+",
+	'╭': "+", '╮': "+", '╯': "+", '╰': "+",
+
+	// Progress bars and sparklines
+	'█': "#", '▓': "#", '▒': ":", '░': "-",
+	'▁': "_", '▂': "_", '▃': ".", '▄': "-", '▅': "=", '▆': "+", '▇': "*", '▀': "^",
+
+	// The braille spinner becomes the classic one
+	'⠋': "|", '⠙': "/", '⠹': "-", '⠸': "\\", '⠼': "|", '⠴': "/", '⠦': "-", '⠧': "\\", '⠇': "|", '⠏': "/",
+
+	// Badges
+	'✅': "[OK]", '✔': "[OK]", '✓': "[OK]", '🟢': "[OK]",
+	'❌': "[X]", '✗': "[X]", '✘': "[X]", '🔴': "[X]", '🛑': "[X]", '🚫': "[X]",
+	'⚠': "[!]", '🟡': "[!]", '🟠': "[!]", '🚨': "[!]",
+	'💡': "[i]", 'ℹ': "[i]", '❓': "[?]",
+
+	// Bullets, arrows and punctuation
+	'•': "*", '●': "*", '○': "o", '◆': "*", '◇': "o", '■': "#", '□': "o", '★': "*", '☆': "*", '⭐': "*",
+	'▶': ">", '❯': ">", '→': "->", '←': "<-", '↑': "^", '↓': "v", '⬆': "^", '⬇': "v", '↩': "<-", '⇒': "=>", '➕': "+",
+	'…': "...", '·': "-", '—': "-", '–': "-", '×': "x",
+}
+
+// ASCIIFallback replaces the decorations in s with their ASCII forms. A
+// dropped pictograph takes the space after it along, so "🔑 KEY" becomes "KEY".
+// Text that isn't decoration, such as accented file names, is kept.
+func ASCIIFallback(s string) string {
+	var out strings.Builder
+	out.Grow(len(s))
+	dropSpace := false
+	for _, r := range s {
+		// Variation selectors and joiners only modify what came before
+		if r == 0xFE0F || r == 0xFE0E || r == 0x200D {
+			continue
+		}
+		if dropSpace {
+			dropSpace = false
+			if r == ' ' {
+				continue
+			}
+		}
+		if r < utf8.RuneSelf {
+			out.WriteRune(r)
+			continue
+		}
+		if fallback, ok := decorationFallbacks[r]; ok {
+			out.WriteString(fallback)
+			continue
+		}
+		switch {
+		case r >= 0x2500 && r <= 0x257F:
+			out.WriteByte('+')
+		case r >= 0x2580 && r <= 0x259F:
+			out.WriteByte('#')
+		case r >= 0x2800 && r <= 0x28FF:
+			out.WriteByte('.')
+		case isPictograph(r):
+			dropSpace = true
+		default:
+			out.WriteRune(r)
+		}
+	}
+	return out.String()
+}
+
+// isPictograph reports whether r is an emoji or symbol from the blocks commands
+// decorate with
+func isPictograph(r rune) bool {
+	return (r >= 0x1F000 && r <= 0x1FAFF) || // emoji and pictographs
+		(r >= 0x2600 && r <= 0x27BF) || // miscellaneous symbols and dingbats
+		(r >= 0x2300 && r <= 0x23FF) || // technical symbols such as ⏱
+		(r >= 0x2B00 && r <= 0x2BFF) || // arrows and stars such as ⭐
+		(r >= 0x25A0 && r <= 0x25FF) // geometric shapes
+}
+
+// Decorate returns s as it should be printed: unchanged, or with ASCII
+// decorations when ASCIIDecorations is on
+func Decorate(s string) string {
+	if !ASCIIDecorations() {
+		return s
+	}
+	return ASCIIFallback(s)
+}
+
+// decoratingWriter applies Decorate to what passes through it, holding back a
+// character split across writes until the rest arrives
+type decoratingWriter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	partial []byte
+}
+
+// DecoratingWriter returns a writer that decorates what it writes to w as
+// Decorate does, for output printed as it goes
+func DecoratingWriter(w io.Writer) io.Writer {
+	return &decoratingWriter{w: w}
+}
+
+// Write decorates p and writes it on, reporting all of p as written
+func (d *decoratingWriter) Write(p []byte) (int, error) {
+	if !ASCIIDecorations() {
+		return d.w.Write(p)
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	data := append(d.partial, p...)
+	end := len(data)
+	// Keep an incomplete character at the end for the next write
+	for i := 1; i < utf8.UTFMax && i <= len(data); i++ {
+		if utf8.RuneStart(data[len(data)-i]) {
+			if !utf8.FullRune(data[len(data)-i:]) {
+				end = len(data) - i
+			}
+			break
+		}
+	}
+	d.partial = append([]byte(nil), data[end:]...)
+	if _, err := io.WriteString(d.w, ASCIIFallback(string(data[:end]))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"suppercommand/internal/monitoring"
+	"suppercommand/internal/security"
+	"suppercommand/pkg/errors"
+)
+
+// Elevation is how Registry.Execute deals with a command that needs privileges
+// the shell doesn't have
+type Elevation struct {
+	// IsElevated reports whether the shell already runs elevated
+	IsElevated func() bool
+	// Method names the way commands are re-run elevated, failing if there is none
+	Method func() (string, error)
+	// Confirm asks whether to re-run the command elevated
+	Confirm func(prompt string) bool
+	// Run runs a command line in an elevated shell and returns its exit code
+	Run func(ctx context.Context, line string) (int, error)
+}
+
+// DefaultElevation checks the privileges of the process and re-runs commands
+// elevated on the terminal after asking
+func DefaultElevation() Elevation {
+	return Elevation{
+		IsElevated: security.IsElevated,
+		Method:     security.ElevationMethod,
+		Confirm:    security.Confirm,
+		Run: func(ctx context.Context, line string) (int, error) {
+			return security.RunElevated(ctx, line, os.Stdin, os.Stdout, os.Stderr)
+		},
+	}
+}
+
+// NeedsElevation reports whether cmd needs elevated privileges to run with args
+func NeedsElevation(cmd Command, args *Arguments) bool {
+	if !cmd.RequiresElevation() {
+		return false
+	}
+	if policy, ok := cmd.(ElevationPolicy); ok {
+		return policy.NeedsElevation(args)
+	}
+	return true
+}
+
+// CommandLine joins a command name and its arguments back into a line the
+// shell parses into the same words
+func CommandLine(name string, args []string) string {
+	words := []string{name}
+	for _, arg := range args {
+		words = append(words, QuoteArgument(arg))
+	}
+	return strings.Join(words, " ")
+}
+
+// ElevationHint says how to run the shell with the privileges a command needs
+func ElevationHint() string {
+	if runtime.GOOS == "windows" {
+		return "run SuperShell from an elevated (Run as Administrator) prompt"
+	}
+	return "run SuperShell as root or with sudo"
+}
+
+// elevate offers to re-run cmd in an elevated shell, failing with how to get
+// the privileges when that isn't possible or the user declines
+func (r *Registry) elevate(ctx context.Context, cmd Command, raw []string) *Result {
+	startTime := time.Now()
+	name := cmd.Name()
+	refused := errors.NewPermissionError("%s requires administrator privileges; %s", name, ElevationHint())
+	refused.Context["command"] = name
+
+	method, err := r.elevation.Method()
+	if err != nil {
+		return ErrorResult("", refused, startTime)
+	}
+	prompt := fmt.Sprintf("🔐 %s requires administrator privileges. Re-run it elevated with %s? [y/N]: ", name, method)
+	if !r.elevation.Confirm(prompt) {
+		return ErrorResult("", refused, startTime)
+	}
+
+	line := CommandLine(name, raw)
+	r.logger.Info("Re-running command elevated",
+		monitoring.Field{Key: "command", Value: name},
+		monitoring.Field{Key: "method", Value: method})
+
+	code, err := r.elevation.Run(ctx, line)
+	if err != nil {
+		return ErrorResult("", errors.Wrap(err, "failed to re-run %s elevated", name), startTime)
+	}
+	return &Result{
+		Output:   "",
+		ExitCode: code,
+		Duration: time.Since(startTime),
+	}
+}
@@ -0,0 +1,149 @@
+package commands
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"suppercommand/pkg/errors"
+
+	"github.com/fatih/color"
+)
+
+// ErrorList collects the failures of a command that operates on several arguments
+type ErrorList []error
+
+// Error joins the failures one per line
+func (l ErrorList) Error() string {
+	messages := make([]string, 0, len(l))
+	for _, err := range l {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Err returns nil for an empty list, the only failure for a single one, or the list itself
+func (l ErrorList) Err() error {
+	switch len(l) {
+	case 0:
+		return nil
+	case 1:
+		return l[0]
+	}
+	return l
+}
+
+// ErrorResult returns a failed result carrying err, leaving the caller to render it
+func ErrorResult(output string, err error, startTime time.Time) *Result {
+	return &Result{
+		Output:   output,
+		Error:    err,
+		ExitCode: 1,
+		Duration: time.Since(startTime),
+	}
+}
+
+// UsageError creates a validation error for a command invoked with bad arguments
+func UsageError(command, format string, args ...interface{}) *errors.SuperShellError {
+	err := errors.NewValidationError("%s: %s", command, fmt.Sprintf(format, args...))
+	err.Context["command"] = command
+	return err
+}
+
+// UsageResult returns a failed result showing cmd's usage line, with a usage
+// error giving the reason the arguments were rejected
+func UsageResult(cmd Command, startTime time.Time, format string, args ...interface{}) *Result {
+	return &Result{
+		Output:   "Usage: " + cmd.Usage() + "\n",
+		Error:    UsageError(cmd.Name(), format, args...),
+		ExitCode: 1,
+		Duration: time.Since(startTime),
+	}
+}
+
+// FileError creates a structured error for an operation on path that failed with err.
+// Missing files are validation errors and access failures are permission errors.
+func FileError(command, path string, err error) *errors.SuperShellError {
+	errType := errors.ErrorTypeExecution
+	switch {
+	case os.IsNotExist(err):
+		errType = errors.ErrorTypeValidation
+	case os.IsPermission(err):
+		errType = errors.ErrorTypePermission
+	}
+
+	// When the failure is about path itself the message already names it,
+	// so keep only the underlying reason
+	cause := err
+	switch e := err.(type) {
+	case *os.PathError:
+		if e.Path == path {
+			cause = e.Err
+		}
+	case *os.LinkError:
+		if e.Old == path {
+			cause = e.Err
+		}
+	case *os.SyscallError:
+		cause = e.Err
+	}
+
+	return &errors.SuperShellError{
+		Type:        errType,
+		Message:     command + ": " + path,
+		Cause:       cause,
+		Context:     map[string]interface{}{"command": command, "path": path},
+		Recoverable: errType != errors.ErrorTypePermission,
+	}
+}
+
+// FormatError renders err in red for the terminal, one line per failure
+func FormatError(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var output strings.Builder
+	red := color.New(color.FgRed)
+	for _, line := range strings.Split(strings.TrimRight(err.Error(), "\n"), "\n") {
+		output.WriteString(red.Sprintf("❌ %s\n", line))
+	}
+	return output.String()
+}
+
+// ErrorReport is a failed command as supershell -c --error-format json
+// describes it on stderr
+type ErrorReport struct {
+	Error string `json:"error"`
+	// Type is the category of a structured error, see errors.ErrorType.String
+	Type     string `json:"type"`
+	Command  string `json:"command,omitempty"`
+	ExitCode int    `json:"exit_code"`
+}
+
+// NewErrorReport describes err, which made a command exit with exitCode. Errors
+// that aren't structured are execution errors, and a list takes the category
+// of its first failure.
+func NewErrorReport(err error, exitCode int) ErrorReport {
+	report := ErrorReport{Error: err.Error(), Type: errors.ErrorTypeExecution.String(), ExitCode: exitCode}
+	if list, ok := err.(ErrorList); ok && len(list) > 0 {
+		err = list[0]
+	}
+	var structured *errors.SuperShellError
+	if stderrors.As(err, &structured) {
+		report.Type = structured.Type.String()
+		if command, ok := structured.Context["command"].(string); ok {
+			report.Command = command
+		}
+	}
+	return report
+}
+
+// FormatErrorJSON renders err as one line of JSON, see ErrorReport
+func FormatErrorJSON(err error, exitCode int) string {
+	data, _ := json.Marshal(NewErrorReport(err, exitCode))
+	return string(data) + "\n"
+}
@@ -0,0 +1,137 @@
+package commands
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"suppercommand/pkg/errors"
+)
+
+// Policies for a glob pattern that matches no files, mirroring bash's defaults,
+// nullglob and failglob
+const (
+	GlobNoMatchPassthrough = "passthrough"
+	GlobNoMatchNull        = "null"
+	GlobNoMatchError       = "error"
+)
+
+// ExpandArguments applies brace expansion and then glob expansion to unquoted words.
+// Options (words starting with "-") are passed through untouched so patterns like
+// --include=*.go reach the command as written. noMatch selects what happens to a
+// pattern that matches nothing.
+func ExpandArguments(words []Word, noMatch string) ([]string, error) {
+	var expanded []string
+
+	for _, word := range words {
+		if word.Quoted || strings.HasPrefix(word.Text, "-") {
+			expanded = append(expanded, word.Text)
+			continue
+		}
+
+		for _, alternative := range ExpandBraces(word.Text) {
+			if !hasGlobMeta(alternative) {
+				expanded = append(expanded, alternative)
+				continue
+			}
+
+			matches, err := globVisible(alternative)
+			if err != nil {
+				// Not a valid pattern, so it was never meant as one
+				expanded = append(expanded, alternative)
+				continue
+			}
+
+			if len(matches) == 0 {
+				switch noMatch {
+				case GlobNoMatchNull:
+				case GlobNoMatchError:
+					err := errors.NewValidationError("no matches found: %s", alternative)
+					err.Context["pattern"] = alternative
+					return nil, err
+				default:
+					expanded = append(expanded, alternative)
+				}
+				continue
+			}
+			expanded = append(expanded, matches...)
+		}
+	}
+
+	return expanded, nil
+}
+
+// ExpandBraces expands comma-separated brace sets, so "file{1,2}.txt" becomes
+// "file1.txt" and "file2.txt". Sets may nest; braces without a top-level comma are
+// left as they are.
+func ExpandBraces(word string) []string {
+	open, close, parts := findBraceSet(word)
+	if open < 0 {
+		return []string{word}
+	}
+
+	prefix, suffix := word[:open], word[close+1:]
+	var expanded []string
+	for _, part := range parts {
+		expanded = append(expanded, ExpandBraces(prefix+part+suffix)...)
+	}
+	return expanded
+}
+
+// findBraceSet locates the first brace set with a top-level comma, returning its
+// bounds and alternatives, or -1 when the word has none
+func findBraceSet(word string) (int, int, []string) {
+	for open := 0; open < len(word); open++ {
+		if word[open] != '{' {
+			continue
+		}
+
+		depth := 0
+		start := open + 1
+		var parts []string
+		for i := open; i < len(word); i++ {
+			switch word[i] {
+			case '{':
+				depth++
+			case ',':
+				if depth == 1 {
+					parts = append(parts, word[start:i])
+					start = i + 1
+				}
+			case '}':
+				depth--
+				if depth == 0 {
+					if len(parts) > 0 {
+						return open, i, append(parts, word[start:i])
+					}
+					i = len(word)
+				}
+			}
+		}
+	}
+	return -1, -1, nil
+}
+
+// hasGlobMeta reports whether word contains glob wildcards
+func hasGlobMeta(word string) bool {
+	return strings.ContainsAny(word, "*?[")
+}
+
+// globVisible expands pattern like filepath.Glob, but like a shell it skips hidden
+// files unless the pattern itself starts with a dot
+func globVisible(pattern string) ([]string, error) {
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	showHidden := strings.HasPrefix(filepath.Base(pattern), ".")
+	visible := matches[:0]
+	for _, match := range matches {
+		if showHidden || !strings.HasPrefix(filepath.Base(match), ".") {
+			visible = append(visible, match)
+		}
+	}
+	sort.Strings(visible)
+	return visible, nil
+}
@@ -0,0 +1,225 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+
+	"github.com/c-bata/go-prompt"
+	"github.com/fatih/color"
+)
+
+// browsePlaceholder marks where the picked paths go in a follow-up command
+const browsePlaceholder = "{}"
+
+// BrowseCommand picks paths with an interactive file browser
+type BrowseCommand struct {
+	*commands.BaseCommand
+	registry *commands.Registry
+}
+
+// NewBrowseCommand creates a browse command running follow-up commands through registry
+func NewBrowseCommand(registry *commands.Registry) *BrowseCommand {
+	return &BrowseCommand{
+		BaseCommand: commands.NewBaseCommand(
+			"browse",
+			"Pick files interactively and print them or pass them to a command",
+			"browse [directory] [-- <command> [args...]]",
+			[]string{"windows", "linux", "darwin"},
+			false,
+		),
+		registry: registry,
+	}
+}
+
+// Execute opens the browser in the given directory, the working directory by
+// default. The picked paths are printed one per line, or with `-- <command>`
+// run as that command's arguments in place of {} (appended if there is none),
+// e.g. `browse -- fastcp-send {} 10.0.0.5` or `browse ~/photos -- cp {} /mnt/usb`.
+func (b *BrowseCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	startTime := time.Now()
+
+	operands, followUp := args.Raw, []string(nil)
+	for i, arg := range args.Raw {
+		if arg == "--" {
+			operands, followUp = args.Raw[:i], args.Raw[i+1:]
+			break
+		}
+	}
+	if len(operands) > 1 {
+		return commands.UsageResult(b, startTime, "expected at most one directory, got %d", len(operands)), nil
+	}
+	if followUp != nil && len(followUp) == 0 {
+		return commands.UsageResult(b, startTime, "missing command after --"), nil
+	}
+
+	start := "."
+	if len(operands) == 1 {
+		start = operands[0]
+	}
+	browser, err := NewFileBrowser(start)
+	if err != nil {
+		return commands.ErrorResult("", commands.FileError(b.Name(), start, err), startTime), nil
+	}
+
+	if err := browseTerminal(ctx, browser); err != nil {
+		return commands.ErrorResult(color.New(color.FgRed).Sprintf("❌ %v\n", err), err, startTime), nil
+	}
+	if browser.Cancelled() {
+		return &commands.Result{
+			Output:   color.New(color.FgYellow).Sprint("Nothing selected\n"),
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}, nil
+	}
+
+	paths := make([]string, 0, len(browser.Selected()))
+	for _, path := range browser.Selected() {
+		paths = append(paths, displayPath(path))
+	}
+
+	if followUp == nil {
+		return &commands.Result{
+			Output:   strings.Join(paths, "\n") + "\n",
+			ExitCode: 0,
+			Duration: time.Since(startTime),
+		}, nil
+	}
+	return b.runWith(ctx, followUp, paths, startTime)
+}
+
+// runWith runs the follow-up command with the picked paths
+func (b *BrowseCommand) runWith(ctx context.Context, words, paths []string, startTime time.Time) (*commands.Result, error) {
+	if b.registry == nil {
+		return commands.ErrorResult("", fmt.Errorf("command registry not available"), startTime), nil
+	}
+
+	line := SubstitutePaths(words, paths)
+	quoted := make([]string, len(line))
+	for i, word := range line {
+		quoted[i] = commands.QuoteArgument(word)
+	}
+
+	header := color.New(color.FgCyan).Sprintf("▶️  %s\n", strings.Join(quoted, " "))
+	result, err := b.registry.ExecuteLine(ctx, strings.Join(quoted, " "))
+	if result == nil {
+		return commands.ErrorResult(header, err, startTime), nil
+	}
+	result.Output = header + result.Output
+	return result, err
+}
+
+// SubstitutePaths puts paths in place of each {} in words, or after the last
+// word when there is no {}
+func SubstitutePaths(words, paths []string) []string {
+	var line []string
+	substituted := false
+	for _, word := range words {
+		if word == browsePlaceholder {
+			line = append(line, paths...)
+			substituted = true
+			continue
+		}
+		line = append(line, word)
+	}
+	if !substituted {
+		line = append(line, paths...)
+	}
+	return line
+}
+
+// browseTerminal runs browser on the terminal until the user picks something or quits
+func browseTerminal(ctx context.Context, browser *FileBrowser) error {
+	in, err := openConsole()
+	if err != nil {
+		return err
+	}
+	if err := in.Setup(); err != nil {
+		return fmt.Errorf("browse needs an interactive terminal: %w", err)
+	}
+	defer in.TearDown()
+
+	out := prompt.NewStdoutWriter()
+	out.HideCursor()
+	defer func() {
+		out.EraseScreen()
+		out.CursorGoTo(0, 0)
+		out.ShowCursor()
+		out.Flush()
+	}()
+
+	for !browser.Done() && !browser.Cancelled() {
+		rows, cols := consoleSize(in)
+		out.EraseScreen()
+		out.CursorGoTo(0, 0)
+		out.WriteRawStr(strings.Join(browser.Render(rows, cols), "\r\n"))
+		if err := out.Flush(); err != nil {
+			return err
+		}
+
+		input, err := readConsole(ctx, in)
+		if err != nil {
+			return err
+		}
+		browser.Press(prompt.GetKey(input), input)
+	}
+	return nil
+}
+
+// openConsole opens the terminal for raw key input. go-prompt panics rather
+// than returning an error when there is no terminal, as under a script.
+func openConsole() (console prompt.ConsoleParser, err error) {
+	defer func() {
+		if recover() != nil {
+			err = errors.New("browse needs an interactive terminal")
+		}
+	}()
+	return prompt.NewStandardInputParser(), nil
+}
+
+// consoleSize returns the terminal's rows and columns, 24x80 if unknown
+func consoleSize(console prompt.ConsoleParser) (rows, cols int) {
+	defer func() {
+		if recover() != nil {
+			rows, cols = 24, 80
+		}
+	}()
+	size := console.GetWinSize()
+	if size.Row == 0 || size.Col == 0 {
+		return 24, 80
+	}
+	return int(size.Row), int(size.Col)
+}
+
+// readConsole waits for the next key press; the console is non-blocking, so
+// reads that find nothing are retried until ctx is cancelled
+func readConsole(ctx context.Context, console prompt.ConsoleParser) ([]byte, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if input, err := console.Read(); err == nil && len(input) > 0 {
+			return input, nil
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// displayPath shortens path to be relative to the working directory when it is inside it
+func displayPath(path string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(wd, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return rel
+}
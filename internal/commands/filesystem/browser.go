@@ -0,0 +1,343 @@
+package filesystem
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"suppercommand/internal/commands"
+
+	"github.com/c-bata/go-prompt"
+	"github.com/fatih/color"
+)
+
+// browserChromeRows are the screen rows the browser needs besides the listing:
+// the path, two rules, the message line and the key help
+const browserChromeRows = 5
+
+// browserEntry is one row of the file browser listing
+type browserEntry struct {
+	name  string
+	isDir bool
+	size  int64
+}
+
+// FileBrowser is the state of an interactive file picker: the directory shown,
+// the highlighted row and the marked paths. Keys are fed in with Press and the
+// screen is drawn with Render, so the terminal handling stays with the caller.
+type FileBrowser struct {
+	dir     string
+	entries []browserEntry
+	cursor  int
+	offset  int
+	page    int
+	marked  map[string]bool
+	order   []string
+	message string
+
+	done      bool
+	cancelled bool
+}
+
+// NewFileBrowser opens a browser showing dir
+func NewFileBrowser(dir string) (*FileBrowser, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	browser := &FileBrowser{marked: make(map[string]bool), page: 10}
+	if err := browser.open(abs, ""); err != nil {
+		return nil, err
+	}
+	return browser, nil
+}
+
+// Dir returns the directory being shown
+func (b *FileBrowser) Dir() string {
+	return b.dir
+}
+
+// Selected returns the marked paths in the order they were marked
+func (b *FileBrowser) Selected() []string {
+	return append([]string(nil), b.order...)
+}
+
+// Done reports whether the user finished picking
+func (b *FileBrowser) Done() bool {
+	return b.done
+}
+
+// Cancelled reports whether the user quit without picking
+func (b *FileBrowser) Cancelled() bool {
+	return b.cancelled
+}
+
+// Press handles one key. input holds the raw bytes read, used for printable keys
+// that go-prompt doesn't name.
+func (b *FileBrowser) Press(key prompt.Key, input []byte) {
+	b.message = ""
+
+	switch key {
+	case prompt.Up, prompt.ControlP:
+		b.move(-1)
+	case prompt.Down, prompt.ControlN:
+		b.move(1)
+	case prompt.PageUp:
+		b.move(-b.page)
+	case prompt.PageDown:
+		b.move(b.page)
+	case prompt.Home:
+		b.move(-len(b.entries))
+	case prompt.End:
+		b.move(len(b.entries))
+	case prompt.Enter, prompt.ControlM, prompt.Right:
+		b.activate()
+	case prompt.Left, prompt.Backspace, prompt.ControlH:
+		b.parent()
+	case prompt.Escape, prompt.ControlC:
+		b.cancelled = true
+	case prompt.NotDefined:
+		switch string(input) {
+		case "k":
+			b.move(-1)
+		case "j":
+			b.move(1)
+		case "l":
+			b.activate()
+		case "h":
+			b.parent()
+		case " ":
+			b.toggle()
+		case "d":
+			b.finish()
+		case "q":
+			b.cancelled = true
+		}
+	}
+}
+
+// Render draws the browser for a terminal of the given size, one string per row
+func (b *FileBrowser) Render(rows, cols int) []string {
+	b.page = rows - browserChromeRows
+	if b.page < 1 {
+		b.page = 1
+	}
+	if b.cursor < b.offset {
+		b.offset = b.cursor
+	}
+	if b.cursor >= b.offset+b.page {
+		b.offset = b.cursor - b.page + 1
+	}
+
+	rule := strings.Repeat("─", clampWidth(cols))
+	lines := []string{
+		color.New(color.FgCyan, color.Bold).Sprint(truncate("📂 "+b.dir, cols)),
+		rule,
+	}
+
+	for i := b.offset; i < len(b.entries) && i < b.offset+b.page; i++ {
+		lines = append(lines, b.renderEntry(i, cols))
+	}
+	if len(b.entries) == 0 {
+		lines = append(lines, color.New(color.FgHiBlack).Sprint("  (empty directory)"))
+	}
+	for len(lines) < b.page+2 {
+		lines = append(lines, "")
+	}
+
+	lines = append(lines, rule)
+	if b.message != "" {
+		lines = append(lines, color.New(color.FgYellow).Sprint(truncate(b.message, cols)))
+	} else {
+		lines = append(lines, fmt.Sprintf("%d marked", len(b.order)))
+	}
+	lines = append(lines, color.New(color.FgHiBlack).Sprint(truncate("↑↓ move  ⏎ open/pick  ← up  space mark  d done  q quit", cols)))
+	return lines
+}
+
+// renderEntry draws listing row i
+func (b *FileBrowser) renderEntry(i, cols int) string {
+	entry := b.entries[i]
+	pointer, mark := "  ", "  "
+	if i == b.cursor {
+		pointer = "❯ "
+	}
+	if b.marked[filepath.Join(b.dir, entry.name)] {
+		mark = "✔ "
+	}
+
+	name, size := entry.name, ""
+	if entry.isDir {
+		name = "📁 " + name + string(filepath.Separator)
+	} else {
+		name = "📄 " + name
+		size = commands.HumanizeBytes(entry.size)
+	}
+	line := truncate(fmt.Sprintf("%s%s%-40s %10s", pointer, mark, name, size), cols)
+
+	switch {
+	case i == b.cursor:
+		return color.New(color.FgHiWhite, color.Bold).Sprint(line)
+	case entry.isDir:
+		return color.New(color.FgBlue).Sprint(line)
+	default:
+		return line
+	}
+}
+
+// open lists dir and puts the cursor on the entry named focus, leaving the
+// current listing alone when dir can't be read
+func (b *FileBrowser) open(dir, focus string) error {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	entries := make([]browserEntry, 0, len(infos)+1)
+	if parent := filepath.Dir(dir); parent != dir {
+		entries = append(entries, browserEntry{name: "..", isDir: true})
+	}
+	for _, info := range infos {
+		isDir := info.IsDir()
+		if info.Mode()&os.ModeSymlink != 0 {
+			if target, err := os.Stat(filepath.Join(dir, info.Name())); err == nil {
+				isDir = target.IsDir()
+			}
+		}
+		entries = append(entries, browserEntry{name: info.Name(), isDir: isDir, size: info.Size()})
+	}
+	// Directories first, both groups by name
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].name == ".." || entries[j].name == ".." {
+			return entries[i].name == ".."
+		}
+		if entries[i].isDir != entries[j].isDir {
+			return entries[i].isDir
+		}
+		return strings.ToLower(entries[i].name) < strings.ToLower(entries[j].name)
+	})
+
+	b.dir, b.entries, b.cursor, b.offset = dir, entries, 0, 0
+	for i, entry := range entries {
+		if entry.name == focus {
+			b.cursor = i
+		}
+	}
+	return nil
+}
+
+// enter opens dir, reporting why on the message line if it can't
+func (b *FileBrowser) enter(dir, focus string) {
+	if err := b.open(dir, focus); err != nil {
+		reason := err
+		if pathErr, ok := err.(*os.PathError); ok {
+			reason = pathErr.Err
+		}
+		if os.IsPermission(err) {
+			b.message = fmt.Sprintf("🚫 %s: permission denied", filepath.Base(dir))
+		} else {
+			b.message = fmt.Sprintf("⚠️  %s: %v", filepath.Base(dir), reason)
+		}
+	}
+}
+
+func (b *FileBrowser) move(delta int) {
+	b.cursor += delta
+	if b.cursor >= len(b.entries) {
+		b.cursor = len(b.entries) - 1
+	}
+	if b.cursor < 0 {
+		b.cursor = 0
+	}
+}
+
+func (b *FileBrowser) current() (browserEntry, bool) {
+	if b.cursor >= len(b.entries) {
+		return browserEntry{}, false
+	}
+	return b.entries[b.cursor], true
+}
+
+// activate opens the highlighted directory, or picks the highlighted file
+func (b *FileBrowser) activate() {
+	entry, ok := b.current()
+	switch {
+	case !ok:
+	case entry.name == "..":
+		b.parent()
+	case entry.isDir:
+		b.enter(filepath.Join(b.dir, entry.name), "")
+	default:
+		path := filepath.Join(b.dir, entry.name)
+		if !b.marked[path] {
+			b.mark(path)
+		}
+		b.done = true
+	}
+}
+
+// parent goes up a directory, keeping the cursor on the one just left
+func (b *FileBrowser) parent() {
+	if parent := filepath.Dir(b.dir); parent != b.dir {
+		b.enter(parent, filepath.Base(b.dir))
+	}
+}
+
+// toggle marks or unmarks the highlighted entry and moves to the next one
+func (b *FileBrowser) toggle() {
+	entry, ok := b.current()
+	if !ok || entry.name == ".." {
+		return
+	}
+	path := filepath.Join(b.dir, entry.name)
+	if b.marked[path] {
+		delete(b.marked, path)
+		for i, marked := range b.order {
+			if marked == path {
+				b.order = append(b.order[:i], b.order[i+1:]...)
+				break
+			}
+		}
+	} else {
+		b.mark(path)
+	}
+	b.move(1)
+}
+
+func (b *FileBrowser) mark(path string) {
+	b.marked[path] = true
+	b.order = append(b.order, path)
+}
+
+// finish ends browsing with the marked paths, or the highlighted one if none are
+func (b *FileBrowser) finish() {
+	if len(b.order) == 0 {
+		entry, ok := b.current()
+		if !ok || entry.name == ".." {
+			b.message = "Mark something with space first"
+			return
+		}
+		b.mark(filepath.Join(b.dir, entry.name))
+	}
+	b.done = true
+}
+
+// truncate shortens s to at most width runes
+func truncate(s string, width int) string {
+	width = clampWidth(width)
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+func clampWidth(cols int) int {
+	if cols < 20 {
+		return 20
+	}
+	return cols
+}
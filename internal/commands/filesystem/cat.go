@@ -36,15 +36,11 @@ func (c *CatCommand) Execute(ctx context.Context, args *commands.Arguments) (*co
 	startTime := time.Now()
 
 	if len(args.Raw) == 0 {
-		return &commands.Result{
-			Output:   "Usage: cat <file1> [file2] ...\n",
-			ExitCode: 1,
-			Duration: time.Since(startTime),
-		}, nil
+		return commands.ErrorResult("Usage: "+c.Usage()+"\n", commands.UsageError(c.Name(), "missing file operand"), startTime), nil
 	}
 
 	var output string
-	hasErrors := false
+	var failures commands.ErrorList
 
 	for i, filename := range args.Raw {
 		// Add separator between files if multiple files
@@ -59,26 +55,20 @@ func (c *CatCommand) Execute(ctx context.Context, args *commands.Arguments) (*co
 		// Check if file exists and is readable
 		info, err := os.Stat(filename)
 		if err != nil {
-			if os.IsNotExist(err) {
-				output += color.New(color.FgRed).Sprintf("cat: %s: No such file or directory\n", filename)
-			} else {
-				output += color.New(color.FgRed).Sprintf("cat: %s: %v\n", filename, err)
-			}
-			hasErrors = true
+			failures = append(failures, commands.FileError(c.Name(), filename, err))
 			continue
 		}
 
 		// Check if it's a directory
 		if info.IsDir() {
-			output += color.New(color.FgRed).Sprintf("cat: %s: Is a directory\n", filename)
-			hasErrors = true
+			failures = append(failures, commands.FileError(c.Name(), filename, errIsDirectory))
 			continue
 		}
 
 		// Check file size (warn for very large files)
 		if info.Size() > 10*1024*1024 { // 10MB
-			output += color.New(color.FgYellow).Sprintf("Warning: %s is large (%d bytes). Continue? (y/N): ",
-				filename, info.Size())
+			output += color.New(color.FgYellow).Sprintf("Warning: %s is large (%s). Continue? (y/N): ",
+				filename, commands.HumanizeBytes(info.Size()))
 			// For now, just show a warning and continue
 			output += color.New(color.FgYellow).Sprint("Proceeding...\n")
 		}
@@ -86,8 +76,7 @@ func (c *CatCommand) Execute(ctx context.Context, args *commands.Arguments) (*co
 		// Read and display file contents
 		content, err := ioutil.ReadFile(filename)
 		if err != nil {
-			output += color.New(color.FgRed).Sprintf("cat: %s: %v\n", filename, err)
-			hasErrors = true
+			failures = append(failures, commands.FileError(c.Name(), filename, err))
 			continue
 		}
 
@@ -99,14 +88,13 @@ func (c *CatCommand) Execute(ctx context.Context, args *commands.Arguments) (*co
 		}
 	}
 
-	exitCode := 0
-	if hasErrors {
-		exitCode = 1
+	if len(failures) > 0 {
+		return commands.ErrorResult(output, failures.Err(), startTime), nil
 	}
 
 	return &commands.Result{
 		Output:   output,
-		ExitCode: exitCode,
+		ExitCode: 0,
 		Duration: time.Since(startTime),
 	}, nil
 }
@@ -2,8 +2,11 @@ package filesystem
 
 import (
 	"context"
+	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"suppercommand/internal/commands"
@@ -13,10 +16,17 @@ import (
 // CdCommand changes the current directory
 type CdCommand struct {
 	*commands.BaseCommand
+	jumps *JumpDB
 }
 
-// NewCdCommand creates a new cd command
+// NewCdCommand creates a new cd command recording visits for z under ~/.supershell
 func NewCdCommand() *CdCommand {
+	return NewCdCommandWithJumps(DefaultJumpDB())
+}
+
+// NewCdCommandWithJumps creates a cd command recording the directories it
+// enters in jumps
+func NewCdCommandWithJumps(jumps *JumpDB) *CdCommand {
 	return &CdCommand{
 		BaseCommand: commands.NewBaseCommand(
 			"cd",
@@ -25,6 +35,7 @@ func NewCdCommand() *CdCommand {
 			[]string{"windows", "linux", "darwin"},
 			false,
 		),
+		jumps: jumps,
 	}
 }
 
@@ -36,12 +47,7 @@ func (c *CdCommand) Execute(ctx context.Context, args *commands.Arguments) (*com
 		// No arguments - go to home directory
 		homeDir, err := os.UserHomeDir()
 		if err != nil {
-			return &commands.Result{
-				Output:   "Error: Could not determine home directory\n",
-				Error:    err,
-				ExitCode: 1,
-				Duration: time.Since(startTime),
-			}, err
+			return commands.ErrorResult("", errors.Wrap(err, "%s: could not determine home directory", c.Name()), startTime), nil
 		}
 		args.Raw = []string{homeDir}
 	}
@@ -53,73 +59,44 @@ func (c *CdCommand) Execute(ctx context.Context, args *commands.Arguments) (*com
 	case "~":
 		homeDir, err := os.UserHomeDir()
 		if err != nil {
-			return &commands.Result{
-				Output:   "Error: Could not determine home directory\n",
-				Error:    err,
-				ExitCode: 1,
-				Duration: time.Since(startTime),
-			}, err
+			return commands.ErrorResult("", errors.Wrap(err, "%s: could not determine home directory", c.Name()), startTime), nil
 		}
 		targetDir = homeDir
 	case "-":
 		// TODO: Implement previous directory functionality
-		return &commands.Result{
-			Output:   "Previous directory functionality not yet implemented\n",
-			ExitCode: 1,
-			Duration: time.Since(startTime),
-		}, nil
+		return commands.ErrorResult("", commands.UsageError(c.Name(), "previous directory functionality not yet implemented"), startTime), nil
 	}
 
 	// Convert to absolute path
 	absPath, err := filepath.Abs(targetDir)
 	if err != nil {
-		return &commands.Result{
-			Output:   "Error: Invalid path\n",
-			Error:    err,
-			ExitCode: 1,
-			Duration: time.Since(startTime),
-		}, err
+		return commands.ErrorResult("", commands.FileError(c.Name(), targetDir, err), startTime), nil
 	}
 
 	// Check if directory exists
 	info, err := os.Stat(absPath)
 	if err != nil {
+		failure := commands.FileError(c.Name(), targetDir, err)
 		if os.IsNotExist(err) {
-			return &commands.Result{
-				Output:   "Error: Directory does not exist\n",
-				Error:    errors.NewValidationError("directory does not exist: %s", absPath),
-				ExitCode: 1,
-				Duration: time.Since(startTime),
-			}, nil
+			if suggestion := c.suggest(absPath); suggestion != "" {
+				failure.Cause = fmt.Errorf("%w (did you mean '%s'?)", failure.Cause, suggestion)
+				failure.Context["suggestion"] = suggestion
+			}
 		}
-		return &commands.Result{
-			Output:   "Error: Cannot access directory\n",
-			Error:    err,
-			ExitCode: 1,
-			Duration: time.Since(startTime),
-		}, err
+		return commands.ErrorResult("", failure, startTime), nil
 	}
 
 	// Check if it's actually a directory
 	if !info.IsDir() {
-		return &commands.Result{
-			Output:   "Error: Not a directory\n",
-			Error:    errors.NewValidationError("not a directory: %s", absPath),
-			ExitCode: 1,
-			Duration: time.Since(startTime),
-		}, nil
+		return commands.ErrorResult("", commands.FileError(c.Name(), targetDir, errNotDirectory), startTime), nil
 	}
 
 	// Change directory
-	err = os.Chdir(absPath)
-	if err != nil {
-		return &commands.Result{
-			Output:   "Error: Permission denied or cannot change directory\n",
-			Error:    err,
-			ExitCode: 1,
-			Duration: time.Since(startTime),
-		}, err
+	if err := os.Chdir(absPath); err != nil {
+		return commands.ErrorResult("", commands.FileError(c.Name(), targetDir, err), startTime), nil
 	}
+	// Losing a visit only makes z's ranking a little less accurate
+	c.jumps.Visit(absPath)
 
 	return &commands.Result{
 		Output:   "",
@@ -127,3 +104,59 @@ func (c *CdCommand) Execute(ctx context.Context, args *commands.Arguments) (*com
 		Duration: time.Since(startTime),
 	}, nil
 }
+
+// suggest names a directory the user may have meant by a missing one: a
+// sibling whose name differs only in case or by a typo or two, otherwise the
+// best-ranked visited directory with the missing name in its path
+func (c *CdCommand) suggest(missing string) string {
+	parent, name := filepath.Split(missing)
+	if entries, err := ioutil.ReadDir(parent); err == nil {
+		best, bestDistance := "", len(name)/3+1
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+			distance := editDistance(strings.ToLower(name), strings.ToLower(entry.Name()))
+			if distance <= 2 && distance < bestDistance {
+				best, bestDistance = filepath.Join(parent, entry.Name()), distance
+			}
+		}
+		if best != "" {
+			return best
+		}
+	}
+	if matches, err := c.jumps.Match([]string{name}); err == nil && len(matches) > 0 {
+		return matches[0].Path
+	}
+	return ""
+}
+
+// editDistance counts the single-character insertions, deletions and
+// substitutions that turn a into b
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current := make([]int, len(rb)+1)
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(minInt(previous[j]+1, current[j-1]+1), previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(rb)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
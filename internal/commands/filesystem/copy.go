@@ -0,0 +1,136 @@
+package filesystem
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"syscall"
+
+	"suppercommand/internal/commands"
+)
+
+// errNotSameDevice is ERROR_NOT_SAME_DEVICE, which Windows reports for a
+// rename between drives
+const errNotSameDevice = syscall.Errno(17)
+
+// isCrossDevice reports whether a rename failed because source and
+// destination are on different filesystems
+func isCrossDevice(err error) bool {
+	var linkErr *os.LinkError
+	if !errors.As(err, &linkErr) {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return linkErr.Err == errNotSameDevice
+	}
+	return linkErr.Err == syscall.EXDEV
+}
+
+// treeSize returns the bytes and regular files below path, path itself included
+func treeSize(path string) (int64, int, error) {
+	var size int64
+	files := 0
+	err := filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+			files++
+		}
+		return nil
+	})
+	return size, files, err
+}
+
+// copyPreserving copies a file, directory tree or symlink from one path to
+// another, keeping permissions and modification times. Copied bytes and files
+// are counted on progress when it is set.
+func copyPreserving(from, to string, progress *commands.ProgressReporter) error {
+	info, err := os.Lstat(from)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		target, err := os.Readlink(from)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(target, to)
+
+	case info.IsDir():
+		if err := os.Mkdir(to, info.Mode().Perm()|0700); err != nil {
+			return err
+		}
+		entries, err := ioutil.ReadDir(from)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := copyPreserving(filepath.Join(from, entry.Name()), filepath.Join(to, entry.Name()), progress); err != nil {
+				return err
+			}
+		}
+		// Set the mode last so read-only directories can be filled, and the
+		// time last because adding entries changed it
+		if err := os.Chmod(to, info.Mode().Perm()); err != nil {
+			return err
+		}
+		return os.Chtimes(to, info.ModTime(), info.ModTime())
+
+	default:
+		if err := copyFileContents(from, to, info.Mode().Perm(), progress); err != nil {
+			return err
+		}
+		if progress != nil {
+			progress.CompleteFile()
+		}
+		return os.Chtimes(to, info.ModTime(), info.ModTime())
+	}
+}
+
+// copyFileContents copies a regular file's data into a new file created with mode
+func copyFileContents(from, to string, mode os.FileMode, progress *commands.ProgressReporter) error {
+	src, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dest, err := os.OpenFile(to, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+	if err != nil {
+		return err
+	}
+
+	var writer io.Writer = dest
+	if progress != nil {
+		writer = io.MultiWriter(dest, progress)
+	}
+	if _, err := io.Copy(writer, src); err != nil {
+		dest.Close()
+		return err
+	}
+	if err := dest.Close(); err != nil {
+		return err
+	}
+	// The umask may have narrowed the mode the file was created with
+	return os.Chmod(to, mode)
+}
+
+// moveAcross moves from to a path on another filesystem by copying it and then
+// deleting the original. It refuses to replace anything already at to.
+func moveAcross(from, to string, progress *commands.ProgressReporter) error {
+	if _, err := os.Lstat(to); err == nil {
+		return &os.LinkError{Op: "rename", Old: from, New: to, Err: os.ErrExist}
+	}
+	if err := copyPreserving(from, to, progress); err != nil {
+		os.RemoveAll(to)
+		return err
+	}
+	return os.RemoveAll(from)
+}
@@ -38,11 +38,7 @@ func (c *CpCommand) Execute(ctx context.Context, args *commands.Arguments) (*com
 	startTime := time.Now()
 
 	if len(args.Raw) < 2 {
-		return &commands.Result{
-			Output:   "Usage: cp [-r] [-v] <source> <destination>\n",
-			ExitCode: 1,
-			Duration: time.Since(startTime),
-		}, nil
+		return commands.ErrorResult("Usage: "+c.Usage()+"\n", commands.UsageError(c.Name(), "missing file operand"), startTime), nil
 	}
 
 	// Parse flags
@@ -67,23 +63,15 @@ func (c *CpCommand) Execute(ctx context.Context, args *commands.Arguments) (*com
 	}
 
 	if len(sources) == 0 {
-		return &commands.Result{
-			Output:   "Error: No source files specified\n",
-			ExitCode: 1,
-			Duration: time.Since(startTime),
-		}, nil
+		return commands.ErrorResult("", commands.UsageError(c.Name(), "no source files specified"), startTime), nil
 	}
 
 	if destination == "" {
-		return &commands.Result{
-			Output:   "Error: No destination specified\n",
-			ExitCode: 1,
-			Duration: time.Since(startTime),
-		}, nil
+		return commands.ErrorResult("", commands.UsageError(c.Name(), "no destination specified"), startTime), nil
 	}
 
 	var output strings.Builder
-	hasErrors := false
+	var failures commands.ErrorList
 	successCount := 0
 
 	// Check if destination is a directory
@@ -94,14 +82,12 @@ func (c *CpCommand) Execute(ctx context.Context, args *commands.Arguments) (*com
 		// Expand glob patterns
 		matches, err := filepath.Glob(source)
 		if err != nil {
-			output.WriteString(color.New(color.FgRed).Sprintf("cp: %s: Invalid pattern: %v\n", source, err))
-			hasErrors = true
+			failures = append(failures, commands.UsageError(c.Name(), "%s: invalid pattern: %v", source, err))
 			continue
 		}
 
 		if len(matches) == 0 {
-			output.WriteString(color.New(color.FgRed).Sprintf("cp: %s: No such file or directory\n", source))
-			hasErrors = true
+			failures = append(failures, commands.FileError(c.Name(), source, os.ErrNotExist))
 			continue
 		}
 
@@ -115,8 +101,7 @@ func (c *CpCommand) Execute(ctx context.Context, args *commands.Arguments) (*com
 
 			err := c.copyItem(match, destPath, recursive, verbose, &output)
 			if err != nil {
-				output.WriteString(color.New(color.FgRed).Sprintf("cp: %s: %v\n", match, err))
-				hasErrors = true
+				failures = append(failures, commands.FileError(c.Name(), match, err))
 			} else {
 				successCount++
 				if verbose {
@@ -133,14 +118,13 @@ func (c *CpCommand) Execute(ctx context.Context, args *commands.Arguments) (*com
 			map[bool]string{true: "", false: "s"}[successCount == 1]))
 	}
 
-	exitCode := 0
-	if hasErrors {
-		exitCode = 1
+	if len(failures) > 0 {
+		return commands.ErrorResult(output.String(), failures.Err(), startTime), nil
 	}
 
 	return &commands.Result{
 		Output:   output.String(),
-		ExitCode: exitCode,
+		ExitCode: 0,
 		Duration: time.Since(startTime),
 	}, nil
 }
@@ -154,7 +138,7 @@ func (c *CpCommand) copyItem(src, dest string, recursive, verbose bool, output *
 
 	if srcInfo.IsDir() {
 		if !recursive {
-			return fmt.Errorf("is a directory (use -r to copy directories)")
+			return fmt.Errorf("%v (use -r to copy directories)", errIsDirectory)
 		}
 		return c.copyDirectory(src, dest, verbose, output)
 	}
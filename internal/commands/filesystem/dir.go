@@ -207,7 +207,7 @@ func (d *DirCommand) Execute(ctx context.Context, args *commands.Arguments) (*co
 			}
 
 			// Format size with appropriate units
-			sizeStr := d.formatFileSize(size)
+			sizeStr := commands.HumanizeBytes(size)
 
 			output.WriteString(fmt.Sprintf("%s %s %s %s %s\n",
 				dateColor.Sprint(modTime),
@@ -235,7 +235,7 @@ func (d *DirCommand) Execute(ctx context.Context, args *commands.Arguments) (*co
 	output.WriteString(fmt.Sprintf("%s %s (%s)\n",
 		statsColor.Sprint("📄 Files:      "),
 		color.New(color.FgHiWhite, color.Bold).Sprintf("%d", totalFiles),
-		color.New(color.FgHiCyan).Sprint(d.formatFileSize(totalSize))))
+		color.New(color.FgHiCyan).Sprint(commands.HumanizeBytes(totalSize))))
 
 	// Show available space if possible
 	if stat, err := os.Stat(dir); err == nil {
@@ -351,20 +351,6 @@ func (d *DirCommand) isConfig(ext string) bool {
 	return false
 }
 
-// formatFileSize formats file size in human-readable format
-func (d *DirCommand) formatFileSize(size int64) string {
-	const unit = 1024
-	if size < unit {
-		return fmt.Sprintf("%d B", size)
-	}
-	div, exp := int64(unit), 0
-	for n := size / unit; n >= unit; n /= unit {
-		div *= unit
-		exp++
-	}
-	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
-}
-
 // getFileTypeDescription returns a brief description of the file type
 func (d *DirCommand) getFileTypeDescription(ext string) string {
 	switch ext {
@@ -0,0 +1,11 @@
+package filesystem
+
+import "errors"
+
+// Reasons reported through commands.FileError when the path exists but is the wrong kind or state
+var (
+	errIsDirectory  = errors.New("is a directory")
+	errNotDirectory = errors.New("not a directory")
+	errNotEmpty     = errors.New("directory not empty")
+	errNotConfirmed = errors.New("not removed, removal was not confirmed")
+)
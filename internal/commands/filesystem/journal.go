@@ -0,0 +1,244 @@
+package filesystem
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxJournalEntries is how many operations undo can go back; the trash of
+// older operations is deleted when they fall off the journal
+const maxJournalEntries = 50
+
+// Kinds of change recorded in the undo journal
+const (
+	// ChangeMove is a rename from Path to To
+	ChangeMove = "move"
+	// ChangeTrash is a file or directory moved from Path into the trash at To
+	ChangeTrash = "trash"
+	// ChangeRmdir is an empty directory removed from Path
+	ChangeRmdir = "rmdir"
+)
+
+// Change is one reversible step of a journaled operation
+type Change struct {
+	Kind string      `json:"kind"`
+	Path string      `json:"path"`
+	To   string      `json:"to,omitempty"`
+	Mode os.FileMode `json:"mode,omitempty"`
+}
+
+// JournalEntry is one destructive command run, undone as a whole
+type JournalEntry struct {
+	ID      string    `json:"id"`
+	Command string    `json:"command"`
+	Time    time.Time `json:"time"`
+	Changes []Change  `json:"changes"`
+}
+
+// Journal records destructive filesystem operations so the undo command can
+// reverse them. Deleted files are not removed but moved into a trash
+// directory next to the journal file.
+type Journal struct {
+	file  string
+	trash string
+}
+
+// NewJournal creates a journal keeping undo.json and the trash in dir
+func NewJournal(dir string) *Journal {
+	return &Journal{
+		file:  filepath.Join(dir, "undo.json"),
+		trash: filepath.Join(dir, "trash"),
+	}
+}
+
+// DefaultJournal returns the journal under ~/.supershell
+func DefaultJournal() *Journal {
+	homeDir, _ := os.UserHomeDir()
+	return NewJournal(filepath.Join(homeDir, ".supershell"))
+}
+
+// Entries returns the journaled operations, oldest first; a missing journal has none
+func (j *Journal) Entries() ([]JournalEntry, error) {
+	data, err := ioutil.ReadFile(j.file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []JournalEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("invalid undo journal %s: %w", j.file, err)
+	}
+	return entries, nil
+}
+
+func (j *Journal) save(entries []JournalEntry) error {
+	if err := os.MkdirAll(filepath.Dir(j.file), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(j.file, data, 0644)
+}
+
+// Begin starts recording an operation for the given command line
+func (j *Journal) Begin(command string) *JournalEntry {
+	now := time.Now()
+	return &JournalEntry{
+		ID:      strconv.FormatInt(now.UnixNano(), 10),
+		Command: command,
+		Time:    now,
+	}
+}
+
+// Trash moves path into the trash and records the change on entry
+func (j *Journal) Trash(entry *JournalEntry, path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Join(j.trash, entry.ID)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("cannot create trash: %w", err)
+	}
+	to := filepath.Join(dir, fmt.Sprintf("%d-%s", len(entry.Changes), filepath.Base(abs)))
+	if err := movePath(abs, to); err != nil {
+		return err
+	}
+	entry.Changes = append(entry.Changes, Change{Kind: ChangeTrash, Path: abs, To: to})
+	return nil
+}
+
+// Moved records a completed rename from one path to another on entry
+func (j *Journal) Moved(entry *JournalEntry, from, to string) {
+	entry.Changes = append(entry.Changes, Change{Kind: ChangeMove, Path: absPath(from), To: absPath(to)})
+}
+
+// RemovedDir records an empty directory that was removed on entry
+func (j *Journal) RemovedDir(entry *JournalEntry, path string, mode os.FileMode) {
+	entry.Changes = append(entry.Changes, Change{Kind: ChangeRmdir, Path: absPath(path), Mode: mode.Perm()})
+}
+
+// Record appends entry to the journal if it changed anything, dropping the
+// oldest operations and their trash beyond maxJournalEntries
+func (j *Journal) Record(entry *JournalEntry) error {
+	if len(entry.Changes) == 0 {
+		return nil
+	}
+	entries, err := j.Entries()
+	if err != nil {
+		return err
+	}
+	entries = append(entries, *entry)
+	if excess := len(entries) - maxJournalEntries; excess > 0 {
+		for _, old := range entries[:excess] {
+			os.RemoveAll(filepath.Join(j.trash, old.ID))
+		}
+		entries = entries[excess:]
+	}
+	return j.save(entries)
+}
+
+// Undo reverts the most recent operation and returns it. Changes are reverted
+// newest first; if one fails, the ones not yet reverted stay in the journal so
+// undo can be retried once the obstacle is gone.
+func (j *Journal) Undo() (*JournalEntry, error) {
+	entries, err := j.Entries()
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	entry := entries[len(entries)-1]
+
+	for i := len(entry.Changes) - 1; i >= 0; i-- {
+		if err := revert(entry.Changes[i]); err != nil {
+			entry.Changes = entry.Changes[:i+1]
+			entries[len(entries)-1] = entry
+			if saveErr := j.save(entries); saveErr != nil {
+				return &entry, saveErr
+			}
+			return &entry, err
+		}
+	}
+
+	os.RemoveAll(filepath.Join(j.trash, entry.ID))
+	return &entry, j.save(entries[:len(entries)-1])
+}
+
+// Clear forgets every operation and permanently deletes the trash
+func (j *Journal) Clear() error {
+	if err := os.RemoveAll(j.trash); err != nil {
+		return err
+	}
+	if err := os.Remove(j.file); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// revert undoes a single change, refusing to overwrite anything at its original path
+func revert(change Change) error {
+	switch change.Kind {
+	case ChangeMove, ChangeTrash:
+		if _, err := os.Lstat(change.Path); err == nil {
+			return fmt.Errorf("cannot restore %s: %w", change.Path, os.ErrExist)
+		}
+		if err := os.MkdirAll(filepath.Dir(change.Path), 0755); err != nil {
+			return err
+		}
+		return movePath(change.To, change.Path)
+	case ChangeRmdir:
+		mode := change.Mode
+		if mode == 0 {
+			mode = 0755
+		}
+		if err := os.Mkdir(change.Path, mode); err != nil && !os.IsExist(err) {
+			return err
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown change kind '%s'", change.Kind)
+	}
+}
+
+// Describe summarises what undoing entry restores
+func (e *JournalEntry) Describe() string {
+	parts := make([]string, 0, len(e.Changes))
+	for _, change := range e.Changes {
+		switch change.Kind {
+		case ChangeMove:
+			parts = append(parts, fmt.Sprintf("%s → %s", change.To, change.Path))
+		default:
+			parts = append(parts, change.Path)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// movePath renames from to to, copying and deleting the original when they
+// are on different filesystems
+func movePath(from, to string) error {
+	err := os.Rename(from, to)
+	if err != nil && isCrossDevice(err) {
+		return moveAcross(from, to, nil)
+	}
+	return err
+}
+
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
@@ -0,0 +1,207 @@
+package filesystem
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxJumpRank is the total rank at which every directory's rank is aged, so
+// directories no longer visited fade away and the database stays small
+const maxJumpRank = 9000
+
+// JumpEntry is one directory cd has entered
+type JumpEntry struct {
+	Path string `json:"path"`
+	// Rank grows by one with each visit and shrinks as the database ages
+	Rank      float64   `json:"rank"`
+	LastVisit time.Time `json:"last_visit"`
+}
+
+// Score is the entry's frecency at now: its rank weighted by how recently the
+// directory was last visited
+func (e JumpEntry) Score(now time.Time) float64 {
+	switch age := now.Sub(e.LastVisit); {
+	case age < time.Hour:
+		return e.Rank * 4
+	case age < 24*time.Hour:
+		return e.Rank * 2
+	case age < 7*24*time.Hour:
+		return e.Rank / 2
+	default:
+		return e.Rank / 4
+	}
+}
+
+// JumpDB ranks the directories cd visits by frecency, how often and how
+// recently each was entered, so z can jump to one from part of its path
+type JumpDB struct {
+	file string
+	now  func() time.Time
+}
+
+// NewJumpDB creates a database kept in file
+func NewJumpDB(file string) *JumpDB {
+	return &JumpDB{file: file, now: time.Now}
+}
+
+// DefaultJumpDB returns the database under ~/.supershell
+func DefaultJumpDB() *JumpDB {
+	homeDir, _ := os.UserHomeDir()
+	return NewJumpDB(filepath.Join(homeDir, ".supershell", "jumps.json"))
+}
+
+// Entries returns the visited directories; a missing database has none
+func (db *JumpDB) Entries() ([]JumpEntry, error) {
+	data, err := ioutil.ReadFile(db.file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []JumpEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("invalid directory database %s: %w", db.file, err)
+	}
+	return entries, nil
+}
+
+func (db *JumpDB) save(entries []JumpEntry) error {
+	if err := os.MkdirAll(filepath.Dir(db.file), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(db.file, data, 0644)
+}
+
+// Visit records that dir was entered. Once the ranks add up to maxJumpRank
+// they are all aged, forgetting directories whose rank drops below one.
+func (db *JumpDB) Visit(dir string) error {
+	entries, err := db.Entries()
+	if err != nil {
+		return err
+	}
+	dir = absPath(dir)
+
+	found := false
+	total := 0.0
+	for i := range entries {
+		if samePath(entries[i].Path, dir) {
+			entries[i].Rank++
+			entries[i].LastVisit = db.now()
+			found = true
+		}
+		total += entries[i].Rank
+	}
+	if !found {
+		entries = append(entries, JumpEntry{Path: dir, Rank: 1, LastVisit: db.now()})
+		total++
+	}
+
+	if total > maxJumpRank {
+		kept := entries[:0]
+		for _, entry := range entries {
+			if entry.Rank *= 0.99; entry.Rank >= 1 {
+				kept = append(kept, entry)
+			}
+		}
+		entries = kept
+	}
+	return db.save(entries)
+}
+
+// Forget removes dirs from the database
+func (db *JumpDB) Forget(dirs ...string) error {
+	entries, err := db.Entries()
+	if err != nil {
+		return err
+	}
+	kept := entries[:0]
+	for _, entry := range entries {
+		forgotten := false
+		for _, dir := range dirs {
+			forgotten = forgotten || samePath(entry.Path, dir)
+		}
+		if !forgotten {
+			kept = append(kept, entry)
+		}
+	}
+	if len(kept) == len(entries) {
+		return nil
+	}
+	return db.save(kept)
+}
+
+// JumpMatch is a directory matching the parts z was given and its score
+type JumpMatch struct {
+	Path  string
+	Score float64
+}
+
+// Match returns the directories whose path contains every one of parts in
+// order, ignoring case, best score first. Directories that no longer exist
+// are left out and forgotten.
+func (db *JumpDB) Match(parts []string) ([]JumpMatch, error) {
+	entries, err := db.Entries()
+	if err != nil {
+		return nil, err
+	}
+	now := db.now()
+	var matches []JumpMatch
+	var gone []string
+	for _, entry := range entries {
+		if !matchesInOrder(entry.Path, parts) {
+			continue
+		}
+		if info, err := os.Stat(entry.Path); err != nil || !info.IsDir() {
+			gone = append(gone, entry.Path)
+			continue
+		}
+		matches = append(matches, JumpMatch{Path: entry.Path, Score: entry.Score(now)})
+	}
+	if len(gone) > 0 {
+		if err := db.Forget(gone...); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Path < matches[j].Path
+	})
+	return matches, nil
+}
+
+// matchesInOrder reports whether path contains every part, each after the
+// one before it, ignoring case
+func matchesInOrder(path string, parts []string) bool {
+	rest := strings.ToLower(filepath.ToSlash(path))
+	for _, part := range parts {
+		i := strings.Index(rest, strings.ToLower(filepath.ToSlash(part)))
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+len(part):]
+	}
+	return true
+}
+
+// samePath compares directories the way the filesystem does: ignoring case
+// on Windows
+func samePath(a, b string) bool {
+	if filepath.Separator == '\\' {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
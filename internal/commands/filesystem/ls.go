@@ -25,7 +25,7 @@ func NewLsCommand() *LsCommand {
 		BaseCommand: commands.NewBaseCommand(
 			"ls",
 			"List directory contents with rich formatting",
-			"ls [-a] [-l] [-h] [directory|pattern]",
+			"ls [-a] [-l] [-h] [directory|pattern|file...]",
 			[]string{"windows", "linux", "darwin"},
 			false,
 		),
@@ -42,6 +42,7 @@ func (l *LsCommand) Execute(ctx context.Context, args *commands.Arguments) (*com
 	showLong := false
 	showHuman := false
 	pattern := "*"
+	var operands []string
 
 	for _, arg := range args.Raw {
 		switch arg {
@@ -56,42 +57,53 @@ func (l *LsCommand) Execute(ctx context.Context, args *commands.Arguments) (*com
 			showAll = true
 		default:
 			if !strings.HasPrefix(arg, "-") {
-				if strings.Contains(arg, "*") || strings.Contains(arg, "?") {
-					pattern = filepath.Base(arg)
-					dir = filepath.Dir(arg)
-					if dir == "." {
-						dir = "."
-					}
-				} else {
-					dir = arg
-				}
+				operands = append(operands, arg)
 			}
 		}
 	}
 
-	// Read directory
-	entries, err := ioutil.ReadDir(dir)
-	if err != nil {
-		return &commands.Result{
-			Output:   "",
-			Error:    err,
-			ExitCode: 1,
-			Duration: time.Since(startTime),
-		}, err
-	}
-
-	// Filter entries
 	var filteredEntries []os.FileInfo
-	for _, entry := range entries {
-		// Skip hidden files unless -a flag is used
-		if !showAll && strings.HasPrefix(entry.Name(), ".") {
-			continue
+	var failures commands.ErrorList
+
+	if len(operands) > 1 || (len(operands) == 1 && !isDirectoryOrPattern(operands[0])) {
+		// Wildcards expanded by the shell arrive as separate paths, so list those paths
+		for _, operand := range operands {
+			info, err := os.Lstat(operand)
+			if err != nil {
+				failures = append(failures, commands.FileError(l.Name(), operand, err))
+				continue
+			}
+			filteredEntries = append(filteredEntries, namedFileInfo{FileInfo: info, name: operand})
+		}
+		dir = ""
+	} else {
+		if len(operands) == 1 {
+			if strings.Contains(operands[0], "*") || strings.Contains(operands[0], "?") {
+				pattern = filepath.Base(operands[0])
+				dir = filepath.Dir(operands[0])
+			} else {
+				dir = operands[0]
+			}
 		}
 
-		// Match pattern
-		matched, err := filepath.Match(pattern, entry.Name())
-		if err == nil && matched {
-			filteredEntries = append(filteredEntries, entry)
+		// Read directory
+		entries, err := ioutil.ReadDir(dir)
+		if err != nil {
+			return commands.ErrorResult("", commands.FileError(l.Name(), dir, err), startTime), nil
+		}
+
+		// Filter entries
+		for _, entry := range entries {
+			// Skip hidden files unless -a flag is used
+			if !showAll && strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+
+			// Match pattern
+			matched, err := filepath.Match(pattern, entry.Name())
+			if err == nil && matched {
+				filteredEntries = append(filteredEntries, entry)
+			}
 		}
 	}
 
@@ -107,7 +119,9 @@ func (l *LsCommand) Execute(ctx context.Context, args *commands.Arguments) (*com
 
 	if showLong {
 		// Long format with details
-		output.WriteString(fmt.Sprintf("📁 Directory: %s\n", color.New(color.FgCyan).Sprint(dir)))
+		if dir != "" {
+			output.WriteString(fmt.Sprintf("📁 Directory: %s\n", color.New(color.FgCyan).Sprint(dir)))
+		}
 		output.WriteString("═══════════════════════════════════════════════════════════════\n")
 
 		totalSize := int64(0)
@@ -172,6 +186,10 @@ func (l *LsCommand) Execute(ctx context.Context, args *commands.Arguments) (*com
 		}
 	}
 
+	if len(failures) > 0 {
+		return commands.ErrorResult(output.String(), failures.Err(), startTime), nil
+	}
+
 	return &commands.Result{
 		Output:   output.String(),
 		ExitCode: 0,
@@ -179,24 +197,33 @@ func (l *LsCommand) Execute(ctx context.Context, args *commands.Arguments) (*com
 	}, nil
 }
 
+// namedFileInfo reports a file under the path it was listed by rather than its base name
+type namedFileInfo struct {
+	os.FileInfo
+	name string
+}
+
+// Name returns the path the file was listed by
+func (f namedFileInfo) Name() string {
+	return f.name
+}
+
+// isDirectoryOrPattern reports whether a single ls operand names a directory to list
+// or a wildcard pattern that matched nothing and was passed through
+func isDirectoryOrPattern(operand string) bool {
+	if strings.ContainsAny(operand, "*?") {
+		return true
+	}
+	info, err := os.Stat(operand)
+	return err != nil || info.IsDir()
+}
+
 // formatSize formats file size in human readable format
 func formatSize(size int64, human bool) string {
 	if !human {
 		return fmt.Sprintf("%d", size)
 	}
-
-	const unit = 1024
-	if size < unit {
-		return fmt.Sprintf("%d B", size)
-	}
-
-	div, exp := int64(unit), 0
-	for n := size / unit; n >= unit; n /= unit {
-		div *= unit
-		exp++
-	}
-
-	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+	return commands.HumanizeBytes(size)
 }
 
 // isExecutable checks if a file is executable
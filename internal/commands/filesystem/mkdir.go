@@ -34,11 +34,7 @@ func (m *MkdirCommand) Execute(ctx context.Context, args *commands.Arguments) (*
 	startTime := time.Now()
 
 	if len(args.Raw) == 0 {
-		return &commands.Result{
-			Output:   "Usage: mkdir [-p] <directory1> [directory2] ...\n",
-			ExitCode: 1,
-			Duration: time.Since(startTime),
-		}, nil
+		return commands.ErrorResult("Usage: "+m.Usage()+"\n", commands.UsageError(m.Name(), "missing operand"), startTime), nil
 	}
 
 	// Parse flags
@@ -54,15 +50,11 @@ func (m *MkdirCommand) Execute(ctx context.Context, args *commands.Arguments) (*
 	}
 
 	if len(directories) == 0 {
-		return &commands.Result{
-			Output:   "Error: No directories specified\n",
-			ExitCode: 1,
-			Duration: time.Since(startTime),
-		}, nil
+		return commands.ErrorResult("", commands.UsageError(m.Name(), "no directories specified"), startTime), nil
 	}
 
 	var output string
-	hasErrors := false
+	var failures commands.ErrorList
 	successCount := 0
 
 	for _, dir := range directories {
@@ -78,8 +70,7 @@ func (m *MkdirCommand) Execute(ctx context.Context, args *commands.Arguments) (*
 			if os.IsExist(err) {
 				output += color.New(color.FgYellow).Sprintf("mkdir: %s: Directory already exists\n", dir)
 			} else {
-				output += color.New(color.FgRed).Sprintf("mkdir: %s: %v\n", dir, err)
-				hasErrors = true
+				failures = append(failures, commands.FileError(m.Name(), dir, err))
 			}
 		} else {
 			output += color.New(color.FgGreen).Sprintf("✅ Created directory: %s\n", dir)
@@ -94,14 +85,13 @@ func (m *MkdirCommand) Execute(ctx context.Context, args *commands.Arguments) (*
 			map[bool]string{true: "y", false: "ies"}[successCount == 1])
 	}
 
-	exitCode := 0
-	if hasErrors {
-		exitCode = 1
+	if len(failures) > 0 {
+		return commands.ErrorResult(output, failures.Err(), startTime), nil
 	}
 
 	return &commands.Result{
 		Output:   output,
-		ExitCode: exitCode,
+		ExitCode: 0,
 		Duration: time.Since(startTime),
 	}, nil
 }
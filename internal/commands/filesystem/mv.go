@@ -2,58 +2,141 @@ package filesystem
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"suppercommand/internal/commands"
 )
 
+// largeMoveSize is the size from which a move across filesystems shows progress
+const largeMoveSize = 64 << 20
+
 // MvCommand moves/renames files
 type MvCommand struct {
 	*commands.BaseCommand
+	journal *Journal
 }
 
-// NewMvCommand creates a new mv command
+// NewMvCommand creates a new mv command journaling to ~/.supershell
 func NewMvCommand() *MvCommand {
+	return NewMvCommandWithJournal(DefaultJournal())
+}
+
+// NewMvCommandWithJournal creates a mv command recording moves in journal
+func NewMvCommandWithJournal(journal *Journal) *MvCommand {
 	return &MvCommand{
 		BaseCommand: commands.NewBaseCommand(
 			"mv",
 			"Move/rename files",
-			"mv <source> <destination>",
+			"mv <source> <destination> | mv <source>... <directory>",
 			[]string{"windows", "linux", "darwin"},
 			false,
 		),
+		journal: journal,
 	}
 }
 
-// Execute moves a file from source to destination
+// Execute moves a file to a new name, or any number of files into a directory
 func (m *MvCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
 	startTime := time.Now()
 
 	if len(args.Raw) < 2 {
-		return &commands.Result{
-			Output:   "Usage: mv <source> <destination>\n",
-			ExitCode: 1,
-			Duration: time.Since(startTime),
-		}, nil
+		return commands.ErrorResult("Usage: "+m.Usage()+"\n", commands.UsageError(m.Name(), "missing file operand"), startTime), nil
 	}
 
-	source := args.Raw[0]
-	dest := args.Raw[1]
+	sources := args.Raw[:len(args.Raw)-1]
+	dest := args.Raw[len(args.Raw)-1]
 
-	err := os.Rename(source, dest)
-	if err != nil {
-		return &commands.Result{
-			Output:   "",
-			Error:    err,
-			ExitCode: 1,
-			Duration: time.Since(startTime),
-		}, err
+	destInfo, err := os.Stat(dest)
+	intoDir := err == nil && destInfo.IsDir()
+	if len(sources) > 1 && !intoDir {
+		return commands.ErrorResult("Usage: "+m.Usage()+"\n", commands.UsageError(m.Name(), "target '%s' is not a directory", dest), startTime), nil
+	}
+
+	var output strings.Builder
+	var failures commands.ErrorList
+	entry := m.journal.Begin(commands.CommandLine(m.Name(), args.Raw))
+	for _, source := range sources {
+		target := dest
+		if intoDir {
+			target = filepath.Join(dest, filepath.Base(source))
+		}
+		if err := m.move(ctx, entry, source, target, &output); err != nil {
+			failures = append(failures, commands.FileError(m.Name(), source, err))
+		}
+	}
+
+	var warnings []string
+	if err := m.journal.Record(entry); err != nil {
+		warnings = append(warnings, fmt.Sprintf("could not update the undo journal: %v", err))
+	}
+
+	if len(failures) > 0 {
+		result := commands.ErrorResult(output.String(), failures.Err(), startTime)
+		result.Warnings = warnings
+		return result, nil
 	}
 
 	return &commands.Result{
-		Output:   "",
+		Output:   output.String(),
 		ExitCode: 0,
 		Duration: time.Since(startTime),
+		Warnings: warnings,
 	}, nil
 }
+
+// move renames source to target and records it on entry. A file being
+// replaced goes to the trash first so undo can bring it back.
+func (m *MvCommand) move(ctx context.Context, entry *JournalEntry, source, target string, output *strings.Builder) error {
+	sourceInfo, err := os.Lstat(source)
+	if err != nil {
+		return err
+	}
+
+	replaced := false
+	if info, err := os.Lstat(target); err == nil && !os.SameFile(info, sourceInfo) {
+		if info.IsDir() {
+			return fmt.Errorf("cannot replace %s: %v", target, errIsDirectory)
+		}
+		if err := m.journal.Trash(entry, target); err != nil {
+			return err
+		}
+		replaced = true
+	}
+
+	err = os.Rename(source, target)
+	if err != nil && isCrossDevice(err) {
+		err = m.moveAcross(ctx, source, target, output)
+	}
+	if err != nil {
+		if replaced {
+			last := len(entry.Changes) - 1
+			revert(entry.Changes[last])
+			entry.Changes = entry.Changes[:last]
+		}
+		return err
+	}
+
+	m.journal.Moved(entry, source, target)
+	return nil
+}
+
+// moveAcross copies source to another filesystem and deletes it, showing
+// progress when there is a lot to copy
+func (m *MvCommand) moveAcross(ctx context.Context, source, target string, output *strings.Builder) error {
+	size, files, err := treeSize(source)
+	if err != nil {
+		return err
+	}
+	if size < largeMoveSize {
+		return moveAcross(source, target, nil)
+	}
+
+	progress := commands.NewProgressReporter(size, files, commands.ProgressWriter(ctx))
+	err = moveAcross(source, target, progress)
+	output.WriteString(progress.Finish())
+	return err
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"path/filepath"
 	"time"
 
 	"suppercommand/internal/commands"
@@ -12,13 +11,19 @@ import (
 	"github.com/fatih/color"
 )
 
-// RmCommand removes files
+// RmCommand removes files by moving them into the trash of the undo journal
 type RmCommand struct {
 	*commands.BaseCommand
+	journal *Journal
 }
 
-// NewRmCommand creates a new rm command
+// NewRmCommand creates a new rm command journaling to ~/.supershell
 func NewRmCommand() *RmCommand {
+	return NewRmCommandWithJournal(DefaultJournal())
+}
+
+// NewRmCommandWithJournal creates a rm command recording removals in journal
+func NewRmCommandWithJournal(journal *Journal) *RmCommand {
 	return &RmCommand{
 		BaseCommand: commands.NewBaseCommand(
 			"rm",
@@ -27,6 +32,7 @@ func NewRmCommand() *RmCommand {
 			[]string{"windows", "linux", "darwin"},
 			false,
 		),
+		journal: journal,
 	}
 }
 
@@ -35,11 +41,7 @@ func (r *RmCommand) Execute(ctx context.Context, args *commands.Arguments) (*com
 	startTime := time.Now()
 
 	if len(args.Raw) == 0 {
-		return &commands.Result{
-			Output:   "Usage: rm [-r] [-f] <file1> [file2] ...\n",
-			ExitCode: 1,
-			Duration: time.Since(startTime),
-		}, nil
+		return commands.ErrorResult("Usage: "+r.Usage()+"\n", commands.UsageError(r.Name(), "missing operand"), startTime), nil
 	}
 
 	// Parse flags
@@ -62,44 +64,26 @@ func (r *RmCommand) Execute(ctx context.Context, args *commands.Arguments) (*com
 	}
 
 	if len(targets) == 0 {
-		return &commands.Result{
-			Output:   "Error: No files or directories specified\n",
-			ExitCode: 1,
-			Duration: time.Since(startTime),
-		}, nil
+		return commands.ErrorResult("", commands.UsageError(r.Name(), "no files or directories specified"), startTime), nil
 	}
 
 	var output string
-	hasErrors := false
+	var failures commands.ErrorList
 	successCount := 0
+	entry := r.journal.Begin(commands.CommandLine(r.Name(), args.Raw))
 
+	// Wildcards were already expanded by the shell, so each target is a path
 	for _, target := range targets {
-		// Expand glob patterns
-		matches, err := filepath.Glob(target)
-		if err != nil {
-			output += color.New(color.FgRed).Sprintf("rm: %s: Invalid pattern: %v\n", target, err)
-			hasErrors = true
-			continue
+		if _, err := os.Lstat(target); os.IsNotExist(err) && force {
+			continue // Ignore non-existent files with -f
 		}
 
-		if len(matches) == 0 {
-			if !force {
-				output += color.New(color.FgRed).Sprintf("rm: %s: No such file or directory\n", target)
-				hasErrors = true
-			}
+		if err := r.removeTarget(entry, target, recursive); err != nil {
+			failures = append(failures, commands.FileError(r.Name(), target, err))
 			continue
 		}
-
-		for _, match := range matches {
-			err := r.removeTarget(match, recursive, force)
-			if err != nil {
-				output += color.New(color.FgRed).Sprintf("rm: %s: %v\n", match, err)
-				hasErrors = true
-			} else {
-				output += color.New(color.FgGreen).Sprintf("🗑️  Removed: %s\n", match)
-				successCount++
-			}
-		}
+		output += color.New(color.FgGreen).Sprintf("🗑️  Removed: %s\n", target)
+		successCount++
 	}
 
 	// Summary
@@ -107,36 +91,38 @@ func (r *RmCommand) Execute(ctx context.Context, args *commands.Arguments) (*com
 		output += fmt.Sprintf("\n✅ Successfully removed %d item%s\n",
 			successCount,
 			map[bool]string{true: "", false: "s"}[successCount == 1])
+		output += "↩️  Run 'undo' to restore\n"
 	}
 
-	exitCode := 0
-	if hasErrors {
-		exitCode = 1
+	var warnings []string
+	if err := r.journal.Record(entry); err != nil {
+		warnings = append(warnings, fmt.Sprintf("could not update the undo journal: %v", err))
+	}
+
+	if len(failures) > 0 {
+		result := commands.ErrorResult(output, failures.Err(), startTime)
+		result.Warnings = warnings
+		return result, nil
 	}
 
 	return &commands.Result{
 		Output:   output,
-		ExitCode: exitCode,
+		ExitCode: 0,
 		Duration: time.Since(startTime),
+		Warnings: warnings,
 	}, nil
 }
 
-// removeTarget removes a single file or directory
-func (r *RmCommand) removeTarget(target string, recursive, force bool) error {
-	info, err := os.Stat(target)
+// removeTarget moves a single file or directory into the trash
+func (r *RmCommand) removeTarget(entry *JournalEntry, target string, recursive bool) error {
+	info, err := os.Lstat(target)
 	if err != nil {
-		if os.IsNotExist(err) && force {
-			return nil // Ignore non-existent files with -f
-		}
 		return err
 	}
 
-	if info.IsDir() {
-		if !recursive {
-			return fmt.Errorf("is a directory (use -r to remove directories)")
-		}
-		return os.RemoveAll(target)
+	if info.IsDir() && !recursive {
+		return fmt.Errorf("%v (use -r to remove directories)", errIsDirectory)
 	}
 
-	return os.Remove(target)
+	return r.journal.Trash(entry, target)
 }
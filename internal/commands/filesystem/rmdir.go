@@ -2,57 +2,140 @@ package filesystem
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"os"
 	"time"
 
 	"suppercommand/internal/commands"
+	"suppercommand/internal/security"
 )
 
 // RmdirCommand removes directories
 type RmdirCommand struct {
 	*commands.BaseCommand
+	flags   *commands.FlagSet
+	journal *Journal
 }
 
-// NewRmdirCommand creates a new rmdir command
+// NewRmdirCommand creates a new rmdir command journaling to ~/.supershell
 func NewRmdirCommand() *RmdirCommand {
+	return NewRmdirCommandWithJournal(DefaultJournal())
+}
+
+// NewRmdirCommandWithJournal creates a rmdir command recording removals in journal
+func NewRmdirCommandWithJournal(journal *Journal) *RmdirCommand {
+	usage := "rmdir [-r [-f]] <directory>..."
 	return &RmdirCommand{
 		BaseCommand: commands.NewBaseCommand(
 			"rmdir",
 			"Remove empty directories",
-			"rmdir <directory>...",
+			usage,
 			[]string{"windows", "linux", "darwin"},
 			false,
 		),
+		flags: commands.NewFlagSet("rmdir", usage,
+			commands.FlagSpec{Name: "recursive", Short: "r", Help: "Also remove non-empty directories, moving them to the trash"},
+			commands.FlagSpec{Name: "force", Short: "f", Help: "Don't ask before removing a non-empty directory"},
+		),
+		journal: journal,
 	}
 }
 
-// Execute removes the specified directories
+// FlagSet returns the options rmdir accepts
+func (r *RmdirCommand) FlagSet() *commands.FlagSet {
+	return r.flags
+}
+
+// Execute removes the specified directories. Only empty directories are
+// removed unless -r is given, which moves whole trees into the trash after
+// asking for confirmation.
 func (r *RmdirCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
 	startTime := time.Now()
 
-	if len(args.Raw) == 0 {
-		return &commands.Result{
-			Output:   "Usage: rmdir <directory>...\n",
-			ExitCode: 1,
-			Duration: time.Since(startTime),
-		}, nil
-	}
-
-	for _, dir := range args.Raw {
-		err := os.Remove(dir)
-		if err != nil {
-			return &commands.Result{
-				Output:   "",
-				Error:    err,
-				ExitCode: 1,
-				Duration: time.Since(startTime),
-			}, err
+	flags, result := r.flags.ParseArguments(args, startTime)
+	if result != nil {
+		return result, nil
+	}
+	recursive := flags.Bool("recursive")
+	force := flags.Bool("force")
+	directories := flags.Args()
+
+	if len(directories) == 0 {
+		return commands.ErrorResult("Usage: "+r.Usage()+"\n", commands.UsageError(r.Name(), "missing operand"), startTime), nil
+	}
+
+	var failures commands.ErrorList
+	entry := r.journal.Begin(commands.CommandLine(r.Name(), args.Raw))
+	for _, dir := range directories {
+		if err := r.removeDirectory(entry, dir, recursive, force); err != nil {
+			failures = append(failures, commands.FileError(r.Name(), dir, err))
 		}
 	}
 
+	var warnings []string
+	if err := r.journal.Record(entry); err != nil {
+		warnings = append(warnings, fmt.Sprintf("could not update the undo journal: %v", err))
+	}
+
+	if len(failures) > 0 {
+		result := commands.ErrorResult("", failures.Err(), startTime)
+		result.Warnings = warnings
+		return result, nil
+	}
+
 	return &commands.Result{
 		Output:   "",
 		ExitCode: 0,
 		Duration: time.Since(startTime),
+		Warnings: warnings,
 	}, nil
 }
+
+// removeDirectory removes one directory, which must be empty unless recursive
+func (r *RmdirCommand) removeDirectory(entry *JournalEntry, dir string, recursive, force bool) error {
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return errNotDirectory
+	}
+
+	empty, err := isEmptyDir(dir)
+	if err != nil {
+		return err
+	}
+	if !empty {
+		if !recursive {
+			return fmt.Errorf("%v (use -r to remove it and its contents)", errNotEmpty)
+		}
+		if !force && !security.Confirm(fmt.Sprintf("❓ Remove %s and everything in it? [y/N]: ", dir)) {
+			return errNotConfirmed
+		}
+		return r.journal.Trash(entry, dir)
+	}
+
+	// os.Remove still refuses the directory if it gained entries since the check
+	if err := os.Remove(dir); err != nil {
+		return err
+	}
+	r.journal.RemovedDir(entry, dir, info.Mode())
+	return nil
+}
+
+// isEmptyDir reports whether dir has no entries
+func isEmptyDir(dir string) (bool, error) {
+	f, err := os.Open(dir)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	if _, err := f.Readdirnames(1); err == io.EOF {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+	return false, nil
+}
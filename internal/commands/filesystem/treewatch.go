@@ -0,0 +1,208 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Watch event kinds, in the order they are reported when one notification
+// carries several
+const (
+	WatchCreate = "create"
+	WatchRemove = "remove"
+	WatchRename = "rename"
+	WatchWrite  = "write"
+	WatchChmod  = "chmod"
+)
+
+// WatchEvent is one change under a watched tree
+type WatchEvent struct {
+	Time time.Time
+	Op   string
+	Path string
+}
+
+// TreeWatcher reports changes anywhere under a directory. fsnotify watches
+// single directories, so every subdirectory gets a watch of its own, including
+// those created while watching.
+type TreeWatcher struct {
+	Events <-chan WatchEvent
+	Errors <-chan error
+
+	watcher   *fsnotify.Watcher
+	recursive bool
+	ignore    []string
+	events    chan WatchEvent
+	errors    chan error
+	done      chan struct{}
+	closeOnce sync.Once
+
+	mu      sync.Mutex
+	watched int
+}
+
+// NewTreeWatcher starts watching root, and its subdirectories when recursive
+// is set. Files and directories whose name matches an ignore pattern, as in
+// filepath.Match, are left out.
+func NewTreeWatcher(root string, recursive bool, ignore []string) (*TreeWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	w := &TreeWatcher{
+		watcher:   watcher,
+		recursive: recursive,
+		ignore:    ignore,
+		events:    make(chan WatchEvent),
+		errors:    make(chan error),
+		done:      make(chan struct{}),
+	}
+	w.Events, w.Errors = w.events, w.errors
+
+	if err := w.addTree(root, false); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+	go w.run()
+	return w, nil
+}
+
+// Watched returns how many directories are being watched
+func (w *TreeWatcher) Watched() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.watched
+}
+
+// Close stops watching
+func (w *TreeWatcher) Close() error {
+	var err error
+	w.closeOnce.Do(func() {
+		close(w.done)
+		err = w.watcher.Close()
+	})
+	return err
+}
+
+// run translates fsnotify notifications into events, adding watches for new
+// directories as they appear
+func (w *TreeWatcher) run() {
+	for {
+		select {
+		case <-w.done:
+			return
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if w.ignored(event.Name) {
+				continue
+			}
+			if !w.emit(WatchEvent{Time: time.Now(), Op: watchOp(event.Op), Path: event.Name}) {
+				return
+			}
+			if event.Op&fsnotify.Create != 0 && w.recursive {
+				if info, err := os.Lstat(event.Name); err == nil && info.IsDir() {
+					// Anything created before the watch was in place is reported here
+					if err := w.addTree(event.Name, true); err != nil && !w.fail(err) {
+						return
+					}
+				}
+			}
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			if !w.fail(err) {
+				return
+			}
+		}
+	}
+}
+
+// addTree watches dir and, when recursive, the directories below it. With
+// report set, what it finds below dir is emitted as created.
+func (w *TreeWatcher) addTree(dir string, report bool) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if path == dir {
+				return err
+			}
+			// Gone or unreadable already; the rest of the tree is still watched
+			return nil
+		}
+		if path != dir && w.ignored(path) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if report && path != dir && !w.emit(WatchEvent{Time: time.Now(), Op: WatchCreate, Path: path}) {
+			return filepath.SkipDir
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if err := w.watcher.Add(path); err != nil {
+			return err
+		}
+		w.mu.Lock()
+		w.watched++
+		w.mu.Unlock()
+		if !w.recursive && path == dir {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+}
+
+// ignored reports whether the name of path matches an ignore pattern
+func (w *TreeWatcher) ignored(path string) bool {
+	name := filepath.Base(path)
+	for _, pattern := range w.ignore {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// emit delivers an event, reporting false once the watcher is closed
+func (w *TreeWatcher) emit(event WatchEvent) bool {
+	select {
+	case w.events <- event:
+		return true
+	case <-w.done:
+		return false
+	}
+}
+
+// fail delivers an error, reporting false once the watcher is closed
+func (w *TreeWatcher) fail(err error) bool {
+	select {
+	case w.errors <- err:
+		return true
+	case <-w.done:
+		return false
+	}
+}
+
+// watchOp names the most significant change in an fsnotify operation
+func watchOp(op fsnotify.Op) string {
+	switch {
+	case op&fsnotify.Create != 0:
+		return WatchCreate
+	case op&fsnotify.Remove != 0:
+		return WatchRemove
+	case op&fsnotify.Rename != 0:
+		return WatchRename
+	case op&fsnotify.Write != 0:
+		return WatchWrite
+	default:
+		return WatchChmod
+	}
+}
@@ -0,0 +1,136 @@
+package filesystem
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+
+	"github.com/fatih/color"
+)
+
+// UndoCommand reverts the most recent journaled rm, rmdir or mv
+type UndoCommand struct {
+	*commands.BaseCommand
+	journal *Journal
+}
+
+// NewUndoCommand creates an undo command reading the journal under ~/.supershell
+func NewUndoCommand() *UndoCommand {
+	return NewUndoCommandWithJournal(DefaultJournal())
+}
+
+// NewUndoCommandWithJournal creates an undo command working on journal
+func NewUndoCommandWithJournal(journal *Journal) *UndoCommand {
+	return &UndoCommand{
+		BaseCommand: commands.NewBaseCommand(
+			"undo",
+			"Revert the last rm, rmdir or mv",
+			"undo [list|clear]",
+			[]string{"windows", "linux", "darwin"},
+			false,
+		),
+		journal: journal,
+	}
+}
+
+// Execute reverts the last operation, lists the journal or empties the trash
+func (u *UndoCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	startTime := time.Now()
+
+	if len(args.Raw) == 0 {
+		return u.undo(startTime)
+	}
+	if len(args.Raw) > 1 {
+		return commands.UsageResult(u, startTime, "unexpected argument '%s'", args.Raw[1]), nil
+	}
+
+	switch args.Raw[0] {
+	case "list":
+		return u.list(startTime)
+	case "clear":
+		if err := u.journal.Clear(); err != nil {
+			return u.failure(err, startTime)
+		}
+		return &commands.Result{
+			Output:   color.New(color.FgGreen).Sprint("✅ Undo history cleared and trash emptied\n"),
+			ExitCode: 0,
+			Duration: time.Since(startTime),
+		}, nil
+	default:
+		return commands.UsageResult(u, startTime, "unknown subcommand '%s'", args.Raw[0]), nil
+	}
+}
+
+// CompleteArguments suggests the subcommands
+func (u *UndoCommand) CompleteArguments(args []string) []string {
+	if len(args) != 1 {
+		return nil
+	}
+	var matches []string
+	for _, sub := range []string{"list", "clear"} {
+		if strings.HasPrefix(sub, args[0]) {
+			matches = append(matches, sub)
+		}
+	}
+	return matches
+}
+
+// undo reverts the most recent operation
+func (u *UndoCommand) undo(startTime time.Time) (*commands.Result, error) {
+	entry, err := u.journal.Undo()
+	if entry == nil && err == nil {
+		return &commands.Result{
+			Output:   color.New(color.FgYellow).Sprint("Nothing to undo\n"),
+			ExitCode: 0,
+			Duration: time.Since(startTime),
+		}, nil
+	}
+	if err != nil {
+		if entry != nil {
+			err = fmt.Errorf("undo of '%s' stopped: %w", entry.Command, err)
+		}
+		return u.failure(err, startTime)
+	}
+
+	var output strings.Builder
+	output.WriteString(color.New(color.FgGreen).Sprintf("↩️  Undid: %s\n", entry.Command))
+	output.WriteString(fmt.Sprintf("   Restored: %s\n", entry.Describe()))
+	return &commands.Result{Output: output.String(), ExitCode: 0, Duration: time.Since(startTime)}, nil
+}
+
+// list shows the journaled operations, newest first
+func (u *UndoCommand) list(startTime time.Time) (*commands.Result, error) {
+	entries, err := u.journal.Entries()
+	if err != nil {
+		return u.failure(err, startTime)
+	}
+
+	var output strings.Builder
+	output.WriteString(color.New(color.FgCyan, color.Bold).Sprint("↩️  UNDO HISTORY\n"))
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+
+	if len(entries) == 0 {
+		output.WriteString("Nothing to undo\n")
+		return &commands.Result{Output: output.String(), ExitCode: 0, Duration: time.Since(startTime)}, nil
+	}
+
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		output.WriteString(fmt.Sprintf("  %s %s\n",
+			color.New(color.FgHiBlack).Sprint(entry.Time.Format("2006-01-02 15:04:05")),
+			color.New(color.FgGreen, color.Bold).Sprint(entry.Command)))
+		output.WriteString(fmt.Sprintf("                      %s\n", entry.Describe()))
+	}
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+	output.WriteString(color.New(color.FgHiBlack).Sprint("💡 'undo' reverts the top entry, 'undo clear' empties the trash\n"))
+
+	return &commands.Result{Output: output.String(), ExitCode: 0, Duration: time.Since(startTime)}, nil
+}
+
+// failure reports an error reading or reverting the journal
+func (u *UndoCommand) failure(err error, startTime time.Time) (*commands.Result, error) {
+	return commands.ErrorResult(color.New(color.FgRed).Sprintf("❌ %v\n", err), err, startTime), nil
+}
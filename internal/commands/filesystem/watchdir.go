@@ -0,0 +1,218 @@
+package filesystem
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/security"
+
+	"github.com/fatih/color"
+)
+
+// WatchdirCommand prints changes under a directory as they happen and can run
+// a command after each burst of them
+type WatchdirCommand struct {
+	*commands.BaseCommand
+	flags    *commands.FlagSet
+	registry *commands.Registry
+}
+
+// NewWatchdirCommand creates a watchdir command running --on-change commands
+// through registry
+func NewWatchdirCommand(registry *commands.Registry) *WatchdirCommand {
+	usage := "watchdir [directory] [--on-change <command>] [--debounce <duration>] [--ignore <pattern>]... [--no-recursive] [--chmod]"
+	return &WatchdirCommand{
+		BaseCommand: commands.NewBaseCommand(
+			"watchdir",
+			"Watch a directory tree and print or act on changes as they happen",
+			usage,
+			[]string{"windows", "linux", "darwin"},
+			false,
+		),
+		flags: commands.NewFlagSet("watchdir", usage,
+			commands.FlagSpec{Name: "on-change", Short: "c", Kind: commands.StringFlag, Value: "command", Help: "Run this SuperShell command once changes settle"},
+			commands.FlagSpec{Name: "debounce", Short: "d", Kind: commands.StringFlag, Default: "500ms", Value: "duration", Help: "How long changes must settle before --on-change runs"},
+			commands.FlagSpec{Name: "ignore", Short: "i", Kind: commands.ListFlag, Value: "pattern", Help: "Skip files and directories whose name matches, e.g. .git or *.tmp"},
+			commands.FlagSpec{Name: "recursive", Short: "r", Default: "true", Help: "Watch subdirectories too, --no-recursive for the top directory only"},
+			commands.FlagSpec{Name: "chmod", Help: "Also show permission and timestamp changes"},
+		),
+		registry: registry,
+	}
+}
+
+// FlagSet returns the options watchdir accepts
+func (w *WatchdirCommand) FlagSet() *commands.FlagSet {
+	return w.flags
+}
+
+// watchRun counts what a watchdir session saw and did
+type watchRun struct {
+	events int
+	runs   int
+	failed int
+}
+
+// Execute watches the directory, the working directory by default, until Enter
+// is pressed or the shell is interrupted. With --on-change the command runs
+// after changes have settled for the debounce time, so a burst of saves runs it
+// once, e.g. `watchdir ./src --on-change "fastcp-send ./src 10.0.0.5"`.
+func (w *WatchdirCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	startTime := time.Now()
+
+	flags, result := w.flags.ParseArguments(args, startTime)
+	if result != nil {
+		return result, nil
+	}
+	if len(flags.Args()) > 1 {
+		return commands.UsageResult(w, startTime, "expected at most one directory, got %d", len(flags.Args())), nil
+	}
+	dir := flags.Arg(0)
+	if dir == "" {
+		dir = "."
+	}
+
+	debounce, err := time.ParseDuration(flags.String("debounce"))
+	if err != nil || debounce < 0 {
+		return commands.UsageResult(w, startTime, "invalid --debounce '%s': expected a duration such as 500ms or 2s", flags.String("debounce")), nil
+	}
+
+	onChange := commands.SplitCommandLine(flags.String("on-change"))
+	if flags.Changed("on-change") {
+		if len(onChange) == 0 {
+			return commands.UsageResult(w, startTime, "--on-change needs a command"), nil
+		}
+		if w.registry == nil {
+			return commands.ErrorResult("", fmt.Errorf("command registry not available"), startTime), nil
+		}
+		if _, err := w.registry.Get(onChange[0]); err != nil {
+			return commands.ErrorResult("", fmt.Errorf("unknown --on-change command '%s'", onChange[0]), startTime), nil
+		}
+	}
+
+	if info, err := os.Stat(dir); err != nil {
+		return commands.ErrorResult("", commands.FileError(w.Name(), dir, err), startTime), nil
+	} else if !info.IsDir() {
+		return commands.ErrorResult("", commands.FileError(w.Name(), dir, fmt.Errorf("not a directory")), startTime), nil
+	}
+
+	watcher, err := NewTreeWatcher(dir, flags.Bool("recursive"), flags.Strings("ignore"))
+	if err != nil {
+		return commands.ErrorResult("", commands.FileError(w.Name(), dir, err), startTime), nil
+	}
+	defer watcher.Close()
+
+	fmt.Print(color.New(color.FgCyan).Sprintf("👀 Watching %s (%d directories), press Enter to stop\n", dir, watcher.Watched()))
+	if len(onChange) > 0 {
+		fmt.Print(color.New(color.FgHiBlack).Sprintf("   Changes run: %s (after %v without changes)\n", flags.String("on-change"), debounce))
+	}
+
+	watchCtx, cancel := security.CancelOnEnter(ctx)
+	defer cancel()
+
+	run := w.watch(watchCtx, watcher, flags.String("on-change"), debounce, flags.Bool("chmod"))
+
+	summary := fmt.Sprintf("Watched %s for %v: %d changes", dir, time.Since(startTime).Round(time.Second), run.events)
+	if len(onChange) > 0 {
+		summary += fmt.Sprintf(", %d runs of %s", run.runs, onChange[0])
+		if run.failed > 0 {
+			summary += fmt.Sprintf(" (%d failed)", run.failed)
+		}
+	}
+	return &commands.Result{
+		Output:   color.New(color.FgHiBlack).Sprint(summary + "\n"),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}, nil
+}
+
+// watch prints events until ctx is done, running onChange once events have
+// stopped arriving for debounce
+func (w *WatchdirCommand) watch(ctx context.Context, watcher *TreeWatcher, onChange string, debounce time.Duration, chmod bool) watchRun {
+	var run watchRun
+	settle := time.NewTimer(debounce)
+	settle.Stop()
+	var settled <-chan time.Time
+	changes := 0
+
+	for {
+		select {
+		case <-ctx.Done():
+			return run
+		case err := <-watcher.Errors:
+			fmt.Print(color.New(color.FgYellow).Sprintf("⚠️  %v\n", err))
+		case event := <-watcher.Events:
+			if event.Op == WatchChmod && !chmod {
+				continue
+			}
+			run.events++
+			fmt.Print(formatWatchEvent(event))
+			if onChange == "" {
+				continue
+			}
+			changes++
+			if settled != nil && !settle.Stop() {
+				<-settle.C
+			}
+			settle.Reset(debounce)
+			settled = settle.C
+		case <-settled:
+			settled = nil
+			run.runs++
+			if !w.runOnChange(ctx, onChange, changes) {
+				run.failed++
+			}
+			changes = 0
+		}
+	}
+}
+
+// runOnChange runs the --on-change command, reporting whether it succeeded.
+// Globs in it are expanded on each run, so they see the files as they are now.
+func (w *WatchdirCommand) runOnChange(ctx context.Context, line string, changes int) bool {
+	noun := "changes"
+	if changes == 1 {
+		noun = "change"
+	}
+	fmt.Print(color.New(color.FgCyan).Sprintf("▶️  %s (%d %s)\n", line, changes, noun))
+
+	result, err := w.registry.ExecuteLine(ctx, line)
+	if result != nil {
+		if result.Output != "" {
+			fmt.Print(result.Output)
+			if !strings.HasSuffix(result.Output, "\n") {
+				fmt.Println()
+			}
+		}
+		if err == nil {
+			err = result.Error
+		}
+	}
+	if err != nil {
+		fmt.Print(commands.FormatError(err))
+		return false
+	}
+	return result != nil && result.ExitCode == 0
+}
+
+// formatWatchEvent formats an event as a timestamped line, colored by kind
+func formatWatchEvent(event WatchEvent) string {
+	kind := color.New(color.FgHiBlack)
+	switch event.Op {
+	case WatchCreate:
+		kind = color.New(color.FgGreen)
+	case WatchWrite:
+		kind = color.New(color.FgYellow)
+	case WatchRemove:
+		kind = color.New(color.FgRed)
+	case WatchRename:
+		kind = color.New(color.FgMagenta)
+	}
+	return fmt.Sprintf("%s %s %s\n",
+		color.New(color.FgHiBlack).Sprint(event.Time.Format("15:04:05.000")),
+		kind.Sprintf("%-6s", strings.ToUpper(event.Op)),
+		event.Path)
+}
@@ -0,0 +1,143 @@
+package filesystem
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+	"suppercommand/pkg/errors"
+
+	"github.com/fatih/color"
+)
+
+// ZCommand jumps to a frequently and recently visited directory from part of
+// its path
+type ZCommand struct {
+	*commands.BaseCommand
+	flags *commands.FlagSet
+	jumps *JumpDB
+}
+
+// NewZCommand creates a z command ranking the directories cd records under ~/.supershell
+func NewZCommand() *ZCommand {
+	return NewZCommandWithJumps(DefaultJumpDB())
+}
+
+// NewZCommandWithJumps creates a z command ranking the directories in jumps
+func NewZCommandWithJumps(jumps *JumpDB) *ZCommand {
+	usage := "z [-l] <part>..."
+	return &ZCommand{
+		BaseCommand: commands.NewBaseCommand(
+			"z",
+			"Jump to a frequently visited directory matching part of its path",
+			usage,
+			[]string{"windows", "linux", "darwin"},
+			false,
+		),
+		flags: commands.NewFlagSet("z", usage,
+			commands.FlagSpec{Name: "list", Short: "l", Help: "List the matching directories and their scores instead of jumping"},
+		),
+		jumps: jumps,
+	}
+}
+
+// FlagSet returns the options z accepts
+func (z *ZCommand) FlagSet() *commands.FlagSet {
+	return z.flags
+}
+
+// Execute changes to the best-ranked directory whose path contains every
+// part in order, or lists the candidates with -l or without parts. A single
+// part naming an existing directory, or a last part completion replaced with
+// a directory's full path, is entered like cd would.
+func (z *ZCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	startTime := time.Now()
+
+	flags, result := z.flags.ParseArguments(args, startTime)
+	if result != nil {
+		return result, nil
+	}
+	parts := flags.Args()
+
+	if last := len(parts) - 1; last >= 0 && (last == 0 || filepath.IsAbs(parts[last])) && !flags.Bool("list") {
+		if info, err := os.Stat(parts[last]); err == nil && info.IsDir() {
+			return z.jump(parts[last], startTime)
+		}
+	}
+
+	matches, err := z.jumps.Match(parts)
+	if err != nil {
+		return commands.ErrorResult("", errors.Wrap(err, "%s: cannot read directory database", z.Name()), startTime), nil
+	}
+	if flags.Bool("list") || len(parts) == 0 {
+		return z.list(matches, startTime), nil
+	}
+	if len(matches) == 0 {
+		return commands.ErrorResult("", errors.NewNotFoundError("%s: no visited directory matches '%s'", z.Name(), strings.Join(parts, " ")), startTime), nil
+	}
+	return z.jump(matches[0].Path, startTime)
+}
+
+// jump enters dir and records the visit
+func (z *ZCommand) jump(dir string, startTime time.Time) (*commands.Result, error) {
+	if err := os.Chdir(dir); err != nil {
+		return commands.ErrorResult("", commands.FileError(z.Name(), dir, err), startTime), nil
+	}
+	// Losing a visit only makes the ranking a little less accurate
+	z.jumps.Visit(dir)
+	return &commands.Result{
+		Output:   absPath(dir) + "\n",
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}, nil
+}
+
+// list shows the matches with the best last, next to the prompt
+func (z *ZCommand) list(matches []JumpMatch, startTime time.Time) *commands.Result {
+	if len(matches) == 0 {
+		return &commands.Result{
+			Output:   color.New(color.FgHiBlack).Sprint("No visited directories match\n"),
+			ExitCode: 0,
+			Duration: time.Since(startTime),
+		}
+	}
+	var output strings.Builder
+	for i := len(matches) - 1; i >= 0; i-- {
+		output.WriteString(fmt.Sprintf("%s  %s\n", color.New(color.FgCyan).Sprintf("%8.1f", matches[i].Score), matches[i].Path))
+	}
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}
+}
+
+// CompleteArguments suggests the best-ranked directories matching the words
+// typed so far, replacing the last one with the directory's path
+func (z *ZCommand) CompleteArguments(args []string) []string {
+	var parts []string
+	for _, arg := range args {
+		if arg != "" && !strings.HasPrefix(arg, "-") {
+			parts = append(parts, arg)
+		}
+	}
+	matches, err := z.jumps.Match(parts)
+	if err != nil {
+		return nil
+	}
+	suggestions := make([]string, 0, len(matches))
+	for _, match := range matches {
+		suggestions = append(suggestions, match.Path)
+	}
+	return suggestions
+}
+
+// RanksArguments tells the completer the suggestions match by path fragment,
+// not by prefix, and come best first
+func (z *ZCommand) RanksArguments() bool {
+	return true
+}
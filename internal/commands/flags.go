@@ -0,0 +1,383 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FlagKind is the type of value a flag takes
+type FlagKind int
+
+const (
+	// BoolFlag is set by its presence; --no-<name> or --name=false clears it
+	BoolFlag FlagKind = iota
+	// StringFlag takes one value; the last one given wins
+	StringFlag
+	// IntFlag takes an integer value
+	IntFlag
+	// FloatFlag takes a decimal value
+	FloatFlag
+	// ListFlag may be repeated, collecting every value
+	ListFlag
+	// SizeFlag takes a byte count such as 4096, 512K or 2G, see ParseBytes
+	SizeFlag
+)
+
+// FlagSpec declares one flag a command accepts
+type FlagSpec struct {
+	// Name is the long form, given as --name
+	Name string
+	// Short is an optional one-letter alias, given as -s
+	Short string
+	Kind  FlagKind
+	// Default is the value, in text form, used when the flag isn't given
+	Default string
+	// Value names the flag's value in help, e.g. "port" for --port <port>
+	Value string
+	Help  string
+}
+
+// ErrHelp is returned by FlagSet.Parse when --help was given
+var ErrHelp = errors.New("help requested")
+
+// FlagSet is the set of flags a command declares. It is safe to share between
+// executions; each Parse returns its own ParsedFlags.
+type FlagSet struct {
+	command string
+	usage   string
+	specs   []FlagSpec
+}
+
+// FlagDeclarer is implemented by commands that parse their flags with a FlagSet,
+// so help and completion can list them
+type FlagDeclarer interface {
+	FlagSet() *FlagSet
+}
+
+// NewFlagSet declares the flags of a command with the given usage line
+func NewFlagSet(command, usage string, specs ...FlagSpec) *FlagSet {
+	for _, spec := range specs {
+		if spec.Name == "" || len(spec.Short) > 1 {
+			panic(fmt.Sprintf("%s: invalid flag spec %+v", command, spec))
+		}
+	}
+	return &FlagSet{command: command, usage: usage, specs: specs}
+}
+
+// Specs returns the declared flags
+func (f *FlagSet) Specs() []FlagSpec {
+	return f.specs
+}
+
+// lookup finds a flag by long name
+func (f *FlagSet) lookup(name string) (FlagSpec, bool) {
+	for _, spec := range f.specs {
+		if spec.Name == name {
+			return spec, true
+		}
+	}
+	return FlagSpec{}, false
+}
+
+// lookupShort finds a flag by its one-letter alias
+func (f *FlagSet) lookupShort(short string) (FlagSpec, bool) {
+	for _, spec := range f.specs {
+		if spec.Short != "" && spec.Short == short {
+			return spec, true
+		}
+	}
+	return FlagSpec{}, false
+}
+
+// Parse separates flags from positional arguments. Flags may appear anywhere;
+// "--" ends flag parsing and a lone "-" is positional. Bool short flags can be
+// combined, as in -an. Unknown flags and bad values are errors, and --help
+// (or -h when no flag claims it) returns ErrHelp.
+func (f *FlagSet) Parse(raw []string) (*ParsedFlags, error) {
+	parsed := &ParsedFlags{set: f, values: make(map[string][]string)}
+
+	for i := 0; i < len(raw); i++ {
+		arg := raw[i]
+		switch {
+		case arg == "--":
+			parsed.positional = append(parsed.positional, raw[i+1:]...)
+			return parsed, nil
+		case len(arg) < 2 || arg[0] != '-':
+			parsed.positional = append(parsed.positional, arg)
+			continue
+		}
+
+		if strings.HasPrefix(arg, "--") {
+			name, value, hasValue := arg[2:], "", false
+			if eq := strings.Index(name, "="); eq >= 0 {
+				name, value, hasValue = name[:eq], name[eq+1:], true
+			}
+			spec, ok := f.lookup(name)
+			if !ok && strings.HasPrefix(name, "no-") && !hasValue {
+				if negated, found := f.lookup(strings.TrimPrefix(name, "no-")); found && negated.Kind == BoolFlag {
+					parsed.values[negated.Name] = []string{"false"}
+					continue
+				}
+			}
+			if !ok {
+				if name == "help" {
+					return nil, ErrHelp
+				}
+				return nil, fmt.Errorf("unknown flag --%s", name)
+			}
+			if spec.Kind != BoolFlag && !hasValue {
+				if i+1 >= len(raw) {
+					return nil, fmt.Errorf("flag --%s needs a value", name)
+				}
+				i++
+				value, hasValue = raw[i], true
+			}
+			if err := parsed.add(spec, value, hasValue); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		// Short flags: -p 9000, -p9000 or combined bools such as -an
+		letters := arg[1:]
+		for j := 0; j < len(letters); j++ {
+			spec, ok := f.lookupShort(letters[j : j+1])
+			if !ok {
+				if letters[j] == 'h' {
+					return nil, ErrHelp
+				}
+				return nil, fmt.Errorf("unknown flag -%s", letters[j:j+1])
+			}
+			if spec.Kind == BoolFlag {
+				parsed.add(spec, "", false)
+				continue
+			}
+			value := letters[j+1:]
+			if value == "" {
+				if i+1 >= len(raw) {
+					return nil, fmt.Errorf("flag -%s needs a value", spec.Short)
+				}
+				i++
+				value = raw[i]
+			}
+			if err := parsed.add(spec, value, true); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	return parsed, nil
+}
+
+// ParseArguments parses args, returning the result the command should return
+// instead when --help was given or the flags are invalid
+func (f *FlagSet) ParseArguments(args *Arguments, startTime time.Time) (*ParsedFlags, *Result) {
+	parsed, err := f.Parse(args.Raw)
+	if err == ErrHelp {
+		return nil, &Result{Output: f.Help(), ExitCode: 0, Duration: time.Since(startTime)}
+	}
+	if err != nil {
+		return nil, &Result{
+			Output:   "Usage: " + f.usage + "\n",
+			Error:    UsageError(f.command, "%v", err),
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}
+	}
+	return parsed, nil
+}
+
+// Help returns the usage line followed by a table of the flags
+func (f *FlagSet) Help() string {
+	var help strings.Builder
+	help.WriteString("Usage: " + f.usage + "\n")
+	if len(f.specs) == 0 {
+		return help.String()
+	}
+
+	help.WriteString("\nFlags:\n")
+	names := make([]string, len(f.specs))
+	width := 0
+	for i, spec := range f.specs {
+		name := "    --" + spec.Name
+		if spec.Short != "" {
+			name = "-" + spec.Short + ", --" + spec.Name
+		}
+		if spec.Kind != BoolFlag {
+			value := spec.Value
+			if value == "" {
+				value = "value"
+			}
+			name += " <" + value + ">"
+		}
+		names[i] = name
+		if len(name) > width {
+			width = len(name)
+		}
+	}
+	for i, spec := range f.specs {
+		text := spec.Help
+		if spec.Default != "" && spec.Kind != BoolFlag {
+			text += fmt.Sprintf(" (default %s)", spec.Default)
+		}
+		if spec.Kind == ListFlag {
+			text += " (repeatable)"
+		}
+		help.WriteString(fmt.Sprintf("  %-*s  %s\n", width, names[i], text))
+	}
+	help.WriteString(fmt.Sprintf("  %-*s  %s\n", width, "-h, --help", "Show this help"))
+	return help.String()
+}
+
+// Names returns every spelling of the declared flags, for completion
+func (f *FlagSet) Names() []string {
+	var names []string
+	for _, spec := range f.specs {
+		names = append(names, "--"+spec.Name)
+		if spec.Short != "" {
+			names = append(names, "-"+spec.Short)
+		}
+	}
+	return names
+}
+
+// Spec returns the flag a command-line word such as --port or -p refers to
+func (f *FlagSet) Spec(word string) (FlagSpec, bool) {
+	if strings.HasPrefix(word, "--") {
+		return f.lookup(strings.SplitN(word[2:], "=", 2)[0])
+	}
+	if len(word) == 2 && word[0] == '-' {
+		return f.lookupShort(word[1:])
+	}
+	return FlagSpec{}, false
+}
+
+// SplitLeading separates a command's own leading options from a command line
+// it runs, which starts at the first word that isn't an option or after --.
+// The value of an option that takes one stays with it.
+func (f *FlagSet) SplitLeading(raw []string) ([]string, []string) {
+	i := 0
+	for ; i < len(raw); i++ {
+		word := raw[i]
+		if word == "--" {
+			return raw[:i], raw[i+1:]
+		}
+		if len(word) < 2 || word[0] != '-' {
+			break
+		}
+		if spec, ok := f.Spec(word); ok && spec.Kind != BoolFlag && !strings.Contains(word, "=") {
+			i++
+		}
+	}
+	if i > len(raw) {
+		i = len(raw)
+	}
+	return raw[:i], raw[i:]
+}
+
+// ParsedFlags holds the flag values and positional arguments of one invocation
+type ParsedFlags struct {
+	set        *FlagSet
+	values     map[string][]string
+	positional []string
+}
+
+// add records a value, checking that it converts to the flag's type
+func (p *ParsedFlags) add(spec FlagSpec, value string, hasValue bool) error {
+	switch spec.Kind {
+	case BoolFlag:
+		if !hasValue {
+			value = "true"
+		} else if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("invalid value '%s' for --%s: expected true or false", value, spec.Name)
+		}
+	case IntFlag:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("invalid value '%s' for --%s: expected a whole number", value, spec.Name)
+		}
+	case FloatFlag:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("invalid value '%s' for --%s: expected a number", value, spec.Name)
+		}
+	case SizeFlag:
+		if _, err := ParseBytes(value); err != nil {
+			return fmt.Errorf("invalid value '%s' for --%s: expected a size such as 512K or 2G", value, spec.Name)
+		}
+	}
+
+	if spec.Kind == ListFlag {
+		p.values[spec.Name] = append(p.values[spec.Name], value)
+	} else {
+		p.values[spec.Name] = []string{value}
+	}
+	return nil
+}
+
+// value returns the text of a flag, or its default
+func (p *ParsedFlags) value(name string) string {
+	spec, ok := p.set.lookup(name)
+	if !ok {
+		panic(fmt.Sprintf("%s: flag --%s is not declared", p.set.command, name))
+	}
+	if values := p.values[name]; len(values) > 0 {
+		return values[len(values)-1]
+	}
+	return spec.Default
+}
+
+// Args returns the positional arguments in order
+func (p *ParsedFlags) Args() []string {
+	return p.positional
+}
+
+// Arg returns the i-th positional argument, or "" if there are fewer
+func (p *ParsedFlags) Arg(i int) string {
+	if i < len(p.positional) {
+		return p.positional[i]
+	}
+	return ""
+}
+
+// Changed reports whether a flag was given on the command line
+func (p *ParsedFlags) Changed(name string) bool {
+	p.value(name)
+	return len(p.values[name]) > 0
+}
+
+// Bool returns a BoolFlag's value
+func (p *ParsedFlags) Bool(name string) bool {
+	value, _ := strconv.ParseBool(p.value(name))
+	return value
+}
+
+// String returns a StringFlag's value
+func (p *ParsedFlags) String(name string) string {
+	return p.value(name)
+}
+
+// Int returns an IntFlag's value
+func (p *ParsedFlags) Int(name string) int {
+	value, _ := strconv.Atoi(p.value(name))
+	return value
+}
+
+// Float returns a FloatFlag's value
+func (p *ParsedFlags) Float(name string) float64 {
+	value, _ := strconv.ParseFloat(p.value(name), 64)
+	return value
+}
+
+// Size returns a SizeFlag's value in bytes
+func (p *ParsedFlags) Size(name string) int64 {
+	value, _ := ParseBytes(p.value(name))
+	return value
+}
+
+// Strings returns every value given for a ListFlag
+func (p *ParsedFlags) Strings(name string) []string {
+	p.value(name)
+	return p.values[name]
+}
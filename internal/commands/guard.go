@@ -0,0 +1,41 @@
+package commands
+
+import "context"
+
+// CommandGuard screens commands before they run, so a script can be kept
+// from running destructive ones however they are reached
+type CommandGuard interface {
+	// Check returns an error when the command may not run
+	Check(name string, args []string) error
+}
+
+type commandGuardKey struct{}
+
+// WithCommandGuard returns a context in which every command the registry runs,
+// including those run by other commands such as each and retry, is screened
+// by guard first. A guard already in ctx still applies and is checked first,
+// so a nested script can't loosen the guard of the one that ran it.
+func WithCommandGuard(ctx context.Context, guard CommandGuard) context.Context {
+	if outer := commandGuard(ctx); outer != nil {
+		guard = guardChain{outer, guard}
+	}
+	return context.WithValue(ctx, commandGuardKey{}, guard)
+}
+
+// guardChain runs the command past each guard in turn
+type guardChain []CommandGuard
+
+func (c guardChain) Check(name string, args []string) error {
+	for _, guard := range c {
+		if err := guard.Check(name, args); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// commandGuard returns the guard set with WithCommandGuard, or nil
+func commandGuard(ctx context.Context) CommandGuard {
+	guard, _ := ctx.Value(commandGuardKey{}).(CommandGuard)
+	return guard
+}
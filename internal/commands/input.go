@@ -0,0 +1,31 @@
+package commands
+
+import (
+	"context"
+	"io"
+	"os"
+)
+
+type inputReaderKey struct{}
+
+// WithInputReader returns a context whose command reads its input from r
+// instead of stdin
+func WithInputReader(ctx context.Context, r io.Reader) context.Context {
+	return context.WithValue(ctx, inputReaderKey{}, r)
+}
+
+// InputReader returns what the running command reads as its input: stdin, or
+// the reader set with WithInputReader
+func InputReader(ctx context.Context) io.Reader {
+	if r, ok := ctx.Value(inputReaderKey{}).(io.Reader); ok {
+		return r
+	}
+	return os.Stdin
+}
+
+// InputIsTerminal reports whether InputReader is the keyboard, where a command
+// reading to the end would wait for the user instead of for a pipe or file
+func InputIsTerminal(ctx context.Context) bool {
+	file, ok := InputReader(ctx).(*os.File)
+	return ok && isTerminal(file)
+}
@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"regexp"
+	"sort"
+)
+
+// CommandInfo describes a registered command for tools that work from the
+// registry, such as completion scripts for other shells
+type CommandInfo struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Usage       string   `json:"usage"`
+	Platforms   []string `json:"platforms"`
+	Elevation   bool     `json:"requires_elevation"`
+	// Arguments are the subcommands and flags accepted directly after the name
+	Arguments []string `json:"arguments,omitempty"`
+}
+
+// usageFlagPattern finds the options mentioned in a usage string, e.g. --json in "[--json]"
+var usageFlagPattern = regexp.MustCompile(`(?:^|[\s\[(|])(--?[A-Za-z][A-Za-z0-9-]*)`)
+
+// UsageFlags returns the distinct options mentioned in a usage string
+func UsageFlags(usage string) []string {
+	var flags []string
+	seen := make(map[string]bool)
+	for _, match := range usageFlagPattern.FindAllStringSubmatch(usage, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			flags = append(flags, match[1])
+		}
+	}
+	return flags
+}
+
+// Describe returns every registered command sorted by name. Arguments come from
+// the command's own ArgumentCompleter, its declared flags, the global output
+// options, the predefined completions and the flags in its usage string, in that
+// order.
+func (r *Registry) Describe() []CommandInfo {
+	completions := GetAutoCompletions()
+
+	var infos []CommandInfo
+	for _, cmd := range r.GetAllCommands() {
+		var candidates []string
+		if completer, ok := cmd.(ArgumentCompleter); ok {
+			candidates = append(candidates, completer.CompleteArguments([]string{""})...)
+		}
+		if declarer, ok := cmd.(FlagDeclarer); ok {
+			candidates = append(candidates, declarer.FlagSet().Names()...)
+		}
+		if _, ok := cmd.(OutputFormatter); ok {
+			candidates = append(candidates, GlobalOutputFlags...)
+		}
+		candidates = append(candidates, completions[cmd.Name()]...)
+		candidates = append(candidates, UsageFlags(cmd.Usage())...)
+
+		var arguments []string
+		seen := make(map[string]bool)
+		for _, candidate := range candidates {
+			if candidate != "" && !seen[candidate] {
+				seen[candidate] = true
+				arguments = append(arguments, candidate)
+			}
+		}
+
+		infos = append(infos, CommandInfo{
+			Name:        cmd.Name(),
+			Description: cmd.Description(),
+			Usage:       cmd.Usage(),
+			Platforms:   cmd.SupportedPlatforms(),
+			Elevation:   cmd.RequiresElevation(),
+			Arguments:   arguments,
+		})
+	}
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
@@ -0,0 +1,256 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxJobOutput is how much of a background job's output is kept; older output
+// is dropped so a long-running receiver can't grow without bound
+const maxJobOutput = 1 << 20
+
+// JobState is where a background job is in its life
+type JobState string
+
+const (
+	JobRunning JobState = "running"
+	JobDone    JobState = "done"
+	JobFailed  JobState = "failed"
+	JobKilled  JobState = "killed"
+)
+
+// JobFunc runs a background job's command, writing what it would print to
+// output, and returns its exit code
+type JobFunc func(ctx context.Context, output io.Writer) (int, error)
+
+// Job is a command line running in the background, started with a trailing &
+type Job struct {
+	ID      int
+	Line    string
+	Started time.Time
+
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu       sync.Mutex
+	output   []byte
+	written  int64
+	state    JobState
+	exitCode int
+	err      error
+	ended    time.Time
+	killed   bool
+	reported bool
+}
+
+// Write captures the job's output, keeping the last maxJobOutput bytes
+func (j *Job) Write(p []byte) (int, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.output = append(j.output, p...)
+	if extra := len(j.output) - maxJobOutput; extra > 0 {
+		j.output = append(j.output[:0], j.output[extra:]...)
+	}
+	j.written += int64(len(p))
+	return len(p), nil
+}
+
+// Output returns the output captured so far
+func (j *Job) Output() string {
+	output, _ := j.OutputSince(0)
+	return output
+}
+
+// OutputSince returns the output written after offset bytes, and the offset to
+// ask for next time. Output that was dropped to stay under the limit is skipped.
+func (j *Job) OutputSince(offset int64) (string, int64) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	start := offset - (j.written - int64(len(j.output)))
+	if start < 0 {
+		start = 0
+	}
+	if start > int64(len(j.output)) {
+		start = int64(len(j.output))
+	}
+	return string(j.output[start:]), j.written
+}
+
+// State reports whether the job is running, or how it ended
+func (j *Job) State() JobState {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.state
+}
+
+// ExitCode returns the exit code of a finished job
+func (j *Job) ExitCode() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.exitCode
+}
+
+// Err returns the error a finished job failed with, if any
+func (j *Job) Err() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.err
+}
+
+// Elapsed is how long the job ran, or has been running
+func (j *Job) Elapsed() time.Duration {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.state == JobRunning {
+		return time.Since(j.Started)
+	}
+	return j.ended.Sub(j.Started)
+}
+
+// Done is closed when the job ends
+func (j *Job) Done() <-chan struct{} {
+	return j.done
+}
+
+// Kill cancels the job's context, which stops its command and any tools it runs
+func (j *Job) Kill() {
+	j.mu.Lock()
+	if j.state == JobRunning {
+		j.killed = true
+	}
+	j.mu.Unlock()
+	j.cancel()
+}
+
+// finish records how the job's command ended
+func (j *Job) finish(exitCode int, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.exitCode = exitCode
+	j.err = err
+	j.ended = time.Now()
+	switch {
+	case j.killed:
+		j.state = JobKilled
+	case err != nil || exitCode != 0:
+		j.state = JobFailed
+	default:
+		j.state = JobDone
+	}
+	close(j.done)
+}
+
+// JobTable tracks the background jobs of a shell. Jobs are numbered from 1 and
+// stay listed after they end until they are collected with fg or removed.
+type JobTable struct {
+	mu     sync.Mutex
+	nextID int
+	jobs   map[int]*Job
+}
+
+// NewJobTable creates an empty job table
+func NewJobTable() *JobTable {
+	return &JobTable{nextID: 1, jobs: make(map[int]*Job)}
+}
+
+// Start runs line in the background with run. The job stops when ctx ends or
+// it is killed.
+func (t *JobTable) Start(ctx context.Context, line string, run JobFunc) *Job {
+	jobCtx, cancel := context.WithCancel(ctx)
+
+	t.mu.Lock()
+	job := &Job{
+		ID:      t.nextID,
+		Line:    line,
+		Started: time.Now(),
+		cancel:  cancel,
+		done:    make(chan struct{}),
+		state:   JobRunning,
+	}
+	t.nextID++
+	t.jobs[job.ID] = job
+	t.mu.Unlock()
+
+	go func() {
+		defer cancel()
+		exitCode, err := run(jobCtx, job)
+		job.finish(exitCode, err)
+	}()
+	return job
+}
+
+// Get returns the job with the given ID
+func (t *JobTable) Get(id int) (*Job, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	job, ok := t.jobs[id]
+	return job, ok
+}
+
+// List returns every job, oldest first
+func (t *JobTable) List() []*Job {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	jobs := make([]*Job, 0, len(t.jobs))
+	for _, job := range t.jobs {
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].ID < jobs[j].ID })
+	return jobs
+}
+
+// Remove forgets a job, killing it first if it is still running
+func (t *JobTable) Remove(id int) {
+	t.mu.Lock()
+	job, ok := t.jobs[id]
+	delete(t.jobs, id)
+	t.mu.Unlock()
+	if ok {
+		job.Kill()
+	}
+}
+
+// Finished returns the jobs that ended since the last call, for the shell to
+// announce at the prompt
+func (t *JobTable) Finished() []*Job {
+	var finished []*Job
+	for _, job := range t.List() {
+		job.mu.Lock()
+		if job.state != JobRunning && !job.reported {
+			job.reported = true
+			finished = append(finished, job)
+		}
+		job.mu.Unlock()
+	}
+	return finished
+}
+
+// StopAll kills every running job and waits for them to end, or for ctx
+func (t *JobTable) StopAll(ctx context.Context) error {
+	for _, job := range t.List() {
+		job.Kill()
+	}
+	for _, job := range t.List() {
+		select {
+		case <-job.Done():
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// ParseJobID parses a job reference, either %<id> or a bare ID
+func ParseJobID(spec string) (int, error) {
+	id, err := strconv.Atoi(strings.TrimPrefix(spec, "%"))
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("invalid job '%s' (use %%<id> as listed by jobs)", spec)
+	}
+	return id, nil
+}
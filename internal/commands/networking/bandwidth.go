@@ -0,0 +1,468 @@
+package networking
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"suppercommand/internal/commands"
+
+	"github.com/fatih/color"
+)
+
+// Bandwidth test protocol
+//
+// Each client stream opens a TCP connection and writes a JSON hello line naming its
+// session and stream. It then streams generated data for the test duration and
+// half-closes the connection. The server counts every byte it reads and answers with
+// a JSON report line, so throughput is always measured on the receiving side.
+const (
+	bandwidthProtocolVersion = 1
+	bandwidthDefaultPort     = 9100
+	bandwidthMaxParallel     = 128
+)
+
+// bandwidthHello opens a test stream
+type bandwidthHello struct {
+	Version int    `json:"version"`
+	Session string `json:"session"`
+	Stream  int    `json:"stream"`
+	Streams int    `json:"streams"`
+}
+
+// bandwidthReport is the server's measurement of one stream
+type bandwidthReport struct {
+	Bytes   int64   `json:"bytes"`
+	Seconds float64 `json:"seconds"`
+	Error   string  `json:"error,omitempty"`
+}
+
+// bandwidthStreamResult is one stream's outcome as seen by the client
+type bandwidthStreamResult struct {
+	Stream int
+	Report bandwidthReport
+	Err    error
+}
+
+// bandwidthSession collects the streams of one client run on the server
+type bandwidthSession struct {
+	remote   string
+	streams  int
+	finished int
+	bytes    int64
+	started  time.Time
+	ended    time.Time
+}
+
+// BandwidthCommand measures raw TCP throughput between two SuperShell instances
+type BandwidthCommand struct {
+	*commands.BaseCommand
+}
+
+// NewBandwidthCommand creates a new bandwidth command
+func NewBandwidthCommand() *BandwidthCommand {
+	return &BandwidthCommand{
+		BaseCommand: commands.NewBaseCommand(
+			"bandwidth",
+			"Measure TCP throughput between two SuperShell instances",
+			"bandwidth --server [--port <port>] [--once] | bandwidth --client <host[:port]> [--duration <time>] [--parallel <n>]",
+			[]string{"windows", "linux", "darwin"},
+			false,
+		),
+	}
+}
+
+// Execute runs the bandwidth server or client
+func (b *BandwidthCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	startTime := time.Now()
+
+	server := false
+	once := false
+	target := ""
+	port := bandwidthDefaultPort
+	duration := 10 * time.Second
+	parallel := 1
+
+	for i := 0; i < len(args.Raw); i++ {
+		switch args.Raw[i] {
+		case "--server", "-s":
+			server = true
+		case "--once":
+			once = true
+		case "--client", "-c":
+			if i+1 < len(args.Raw) {
+				target = args.Raw[i+1]
+				i++
+			}
+		case "--port", "-p":
+			if i+1 < len(args.Raw) {
+				if p, err := strconv.Atoi(args.Raw[i+1]); err == nil {
+					port = p
+				}
+				i++
+			}
+		case "--duration", "-t":
+			if i+1 < len(args.Raw) {
+				if d, err := parseBandwidthDuration(args.Raw[i+1]); err == nil {
+					duration = d
+				}
+				i++
+			}
+		case "--parallel", "-P":
+			if i+1 < len(args.Raw) {
+				if n, err := strconv.Atoi(args.Raw[i+1]); err == nil {
+					parallel = n
+				}
+				i++
+			}
+		}
+	}
+
+	if server == (target != "") {
+		return &commands.Result{
+			Output:   "Usage: " + b.Usage() + "\n",
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}, nil
+	}
+	if parallel < 1 || parallel > bandwidthMaxParallel {
+		return &commands.Result{
+			Output:   color.New(color.FgRed).Sprintf("❌ --parallel must be between 1 and %d\n", bandwidthMaxParallel),
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}, nil
+	}
+
+	if server {
+		return b.runServer(ctx, port, once, startTime), nil
+	}
+
+	if _, _, err := net.SplitHostPort(target); err != nil {
+		target = net.JoinHostPort(target, strconv.Itoa(port))
+	}
+	return b.runClient(ctx, target, duration, parallel, startTime), nil
+}
+
+// runServer accepts test streams until ctx is cancelled, or after the first complete
+// session when once is set
+func (b *BandwidthCommand) runServer(ctx context.Context, port int, once bool, startTime time.Time) *commands.Result {
+	var output strings.Builder
+
+	output.WriteString(color.New(color.FgCyan, color.Bold).Sprint("📶 BANDWIDTH SERVER\n"))
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+
+	var lc net.ListenConfig
+	listener, err := lc.Listen(ctx, "tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		output.WriteString(color.New(color.FgRed).Sprintf("❌ Failed to listen on port %d: %v\n", port, err))
+		return &commands.Result{
+			Output:   output.String(),
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}
+	}
+
+	serveCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Closing the listener is what unblocks Accept on cancellation
+	go func() {
+		<-serveCtx.Done()
+		listener.Close()
+	}()
+
+	fmt.Printf("👂 Listening on port %d...\n", port)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	sessions := make(map[string]*bandwidthSession)
+	var completed []*bandwidthSession
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if serveCtx.Err() != nil {
+				break
+			}
+			fmt.Printf("⚠️  Accept failed: %v\n", err)
+			continue
+		}
+
+		wg.Add(1)
+		go func(conn net.Conn) {
+			defer wg.Done()
+
+			hello, report, streamStart := b.serveStream(serveCtx, conn)
+			if hello == nil {
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			session := sessions[hello.Session]
+			if session == nil {
+				session = &bandwidthSession{
+					remote:  conn.RemoteAddr().String(),
+					streams: hello.Streams,
+					started: streamStart,
+				}
+				sessions[hello.Session] = session
+			}
+			if streamStart.Before(session.started) {
+				session.started = streamStart
+			}
+			session.finished++
+			session.bytes += report.Bytes
+			session.ended = time.Now()
+
+			if session.finished < session.streams {
+				return
+			}
+
+			delete(sessions, hello.Session)
+			completed = append(completed, session)
+			fmt.Printf("✅ %s: %d streams, %s in %v - %s\n", session.remote, session.streams,
+				commands.HumanizeBytes(session.bytes), session.elapsed().Round(time.Millisecond),
+				formatMbps(session.bytes, session.elapsed()))
+			if once {
+				cancel()
+			}
+		}(conn)
+	}
+
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		output.WriteString(color.New(color.FgYellow).Sprint("⚠️  Server stopped\n"))
+	}
+	output.WriteString(color.New(color.FgYellow, color.Bold).Sprint("📋 SESSION SUMMARY\n"))
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+	if len(completed) == 0 {
+		output.WriteString("No tests completed\n")
+	}
+	for _, session := range completed {
+		output.WriteString(fmt.Sprintf("✅ %-22s %d streams, %s, %s\n", session.remote, session.streams,
+			commands.HumanizeBytes(session.bytes), formatMbps(session.bytes, session.elapsed())))
+	}
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}
+}
+
+// serveStream reads one test stream to EOF and reports the byte count back, returning
+// when the stream started. It returns a nil hello for connections that never start a test.
+func (b *BandwidthCommand) serveStream(ctx context.Context, conn net.Conn) (*bandwidthHello, bandwidthReport, time.Time) {
+	stop := closeOnCancel(ctx, conn)
+	defer stop()
+	defer conn.Close()
+
+	reader := bufio.NewReaderSize(conn, fastcpDefaultBlockSize)
+
+	conn.SetReadDeadline(time.Now().Add(30 * time.Second))
+	var hello bandwidthHello
+	if err := readFastcpMessage(reader, &hello); err != nil {
+		return nil, bandwidthReport{}, time.Time{}
+	}
+	conn.SetReadDeadline(time.Time{})
+
+	if hello.Version != bandwidthProtocolVersion || hello.Streams < 1 || hello.Streams > bandwidthMaxParallel {
+		writeFastcpMessage(conn, bandwidthReport{Error: fmt.Sprintf("unsupported test parameters (version %d, %d streams)", hello.Version, hello.Streams)})
+		return nil, bandwidthReport{}, time.Time{}
+	}
+
+	streamStart := time.Now()
+	received, err := io.CopyBuffer(ioutil.Discard, reader, make([]byte, fastcpDefaultBlockSize))
+	report := bandwidthReport{Bytes: received, Seconds: time.Since(streamStart).Seconds()}
+	if err != nil {
+		report.Error = err.Error()
+	}
+
+	writeFastcpMessage(conn, report)
+	return &hello, report, streamStart
+}
+
+// runClient streams generated data to a bandwidth server over parallel connections
+func (b *BandwidthCommand) runClient(ctx context.Context, target string, duration time.Duration, parallel int, startTime time.Time) *commands.Result {
+	var output strings.Builder
+
+	output.WriteString(color.New(color.FgCyan, color.Bold).Sprint("📶 BANDWIDTH TEST\n"))
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+	output.WriteString(fmt.Sprintf("🎯 Server:   %s\n", color.New(color.FgBlue).Sprint(target)))
+	output.WriteString(fmt.Sprintf("⏱️  Duration: %v\n", duration))
+	output.WriteString(fmt.Sprintf("🔀 Streams:  %d\n", parallel))
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+
+	session := newTransferID()
+	payload := make([]byte, fastcpDefaultBlockSize)
+	for i := range payload {
+		payload[i] = byte(i)
+	}
+
+	results := make([]*bandwidthStreamResult, parallel)
+	var wg sync.WaitGroup
+	for i := 0; i < parallel; i++ {
+		wg.Add(1)
+		go func(stream int) {
+			defer wg.Done()
+			results[stream] = b.runStream(ctx, target, bandwidthHello{
+				Version: bandwidthProtocolVersion,
+				Session: session,
+				Stream:  stream,
+				Streams: parallel,
+			}, duration, payload)
+		}(i)
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		output.WriteString(color.New(color.FgYellow).Sprint("⚠️  Test interrupted\n"))
+		return &commands.Result{
+			Output:   output.String(),
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}
+	}
+
+	output.WriteString(fmt.Sprintf("%-8s %-12s %-10s %s\n",
+		color.New(color.FgYellow, color.Bold).Sprint("Stream"),
+		color.New(color.FgGreen, color.Bold).Sprint("Transfer"),
+		color.New(color.FgBlue, color.Bold).Sprint("Time"),
+		color.New(color.FgMagenta, color.Bold).Sprint("Bandwidth")))
+
+	var totalBytes int64
+	var longest time.Duration
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+			output.WriteString(color.New(color.FgRed).Sprintf("[%3d]    ❌ %v\n", result.Stream, result.Err))
+			continue
+		}
+
+		elapsed := time.Duration(result.Report.Seconds * float64(time.Second))
+		if elapsed > longest {
+			longest = elapsed
+		}
+		totalBytes += result.Report.Bytes
+		output.WriteString(fmt.Sprintf("[%3d]    %-12s %-10s %s\n", result.Stream,
+			commands.HumanizeBytes(result.Report.Bytes), elapsed.Round(time.Millisecond), formatMbps(result.Report.Bytes, elapsed)))
+	}
+
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+
+	if failed == parallel {
+		output.WriteString(color.New(color.FgRed, color.Bold).Sprint("❌ BANDWIDTH TEST FAILED\n"))
+		output.WriteString("═══════════════════════════════════════════════════════════════\n")
+		return &commands.Result{
+			Output:   output.String(),
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}
+	}
+
+	output.WriteString(color.New(color.FgGreen, color.Bold).Sprintf("🚀 Total: %s in %v - %s\n",
+		commands.HumanizeBytes(totalBytes), longest.Round(time.Millisecond), formatMbps(totalBytes, longest)))
+	if failed > 0 {
+		output.WriteString(color.New(color.FgYellow).Sprintf("⚠️  %d of %d streams failed\n", failed, parallel))
+	}
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+
+	exitCode := 0
+	if failed > 0 {
+		exitCode = 1
+	}
+
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: exitCode,
+		Duration: time.Since(startTime),
+	}
+}
+
+// runStream writes payload to the server for duration and collects its report
+func (b *BandwidthCommand) runStream(ctx context.Context, target string, hello bandwidthHello, duration time.Duration, payload []byte) *bandwidthStreamResult {
+	result := &bandwidthStreamResult{Stream: hello.Stream}
+
+	dialer := net.Dialer{Timeout: 10 * time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", target)
+	if err != nil {
+		result.Err = fmt.Errorf("failed to connect: %v", err)
+		return result
+	}
+	stop := closeOnCancel(ctx, conn)
+	defer stop()
+	defer conn.Close()
+
+	if err := writeFastcpMessage(conn, hello); err != nil {
+		result.Err = err
+		return result
+	}
+
+	// The write deadline ends the stream even if a send blocks on a stalled link
+	deadline := time.Now().Add(duration)
+	conn.SetWriteDeadline(deadline)
+	for time.Now().Before(deadline) {
+		if _, err := conn.Write(payload); err != nil {
+			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+				break
+			}
+			result.Err = err
+			return result
+		}
+	}
+	conn.SetWriteDeadline(time.Time{})
+
+	if tcp, ok := conn.(*net.TCPConn); ok {
+		tcp.CloseWrite()
+	}
+
+	conn.SetReadDeadline(time.Now().Add(30 * time.Second))
+	if err := readFastcpMessage(bufio.NewReader(conn), &result.Report); err != nil {
+		result.Err = fmt.Errorf("no report from server: %v", err)
+		return result
+	}
+	if result.Report.Error != "" {
+		result.Err = fmt.Errorf("server: %s", result.Report.Error)
+	}
+	return result
+}
+
+// elapsed is the wall time from the first stream starting to the last one ending
+func (s *bandwidthSession) elapsed() time.Duration {
+	return s.ended.Sub(s.started)
+}
+
+// parseBandwidthDuration accepts Go durations such as "10s" or a plain number of seconds
+func parseBandwidthDuration(value string) (time.Duration, error) {
+	d, err := time.ParseDuration(value)
+	if seconds, atoiErr := strconv.Atoi(value); atoiErr == nil {
+		d, err = time.Duration(seconds)*time.Second, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("duration must be positive")
+	}
+	return d, nil
+}
+
+// formatMbps formats a byte count over a duration as megabits per second
+func formatMbps(bytes int64, elapsed time.Duration) string {
+	if elapsed <= 0 {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.2f Mbps", float64(bytes)*8/elapsed.Seconds()/1e6)
+}
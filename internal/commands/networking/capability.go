@@ -0,0 +1,263 @@
+package networking
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/security"
+
+	"github.com/fatih/color"
+)
+
+// The low-level features advanced commands depend on
+const (
+	CapabilityCapture = "packet capture"
+	CapabilitySend    = "raw frame sending"
+	CapabilityTools   = "network tools"
+	CapabilityTrace   = "syscall tracing"
+)
+
+// capabilityCommands names the commands that need each feature
+var capabilityCommands = map[string][]string{
+	CapabilityCapture: {"sniff", "netdiscover"},
+	CapabilitySend:    {"replay --send"},
+	CapabilityTools:   {"ping", "tracert", "mtu"},
+	CapabilityTrace:   {"trace"},
+}
+
+// capabilityOrder is the order features are reported in
+var capabilityOrder = []string{CapabilityCapture, CapabilitySend, CapabilityTools, CapabilityTrace}
+
+// Capability reports whether a feature can be used right now, and if not,
+// what to do about it
+type Capability struct {
+	Feature   string   `json:"feature"`
+	Commands  []string `json:"commands"`
+	Available bool     `json:"available"`
+	Detail    string   `json:"detail"`
+	Fix       string   `json:"fix,omitempty"`
+}
+
+// CapabilityError is returned when a command needs a feature this system
+// doesn't offer. Its message says what to do on this platform.
+type CapabilityError struct {
+	Capability
+}
+
+func (e *CapabilityError) Error() string {
+	message := fmt.Sprintf("%s unavailable: %s", e.Feature, e.Detail)
+	if e.Fix != "" {
+		message += "; to fix it, " + e.Fix
+	}
+	return message
+}
+
+// capabilityCheck probes a feature, returning what it found and, when the
+// feature is unusable, the fix for this platform
+type capabilityCheck func() (detail, fix string, ok bool)
+
+// capabilityChecks are the probes for each feature, see the platform files
+var capabilityChecks = map[string]capabilityCheck{
+	CapabilityCapture: checkPacketCapture,
+	CapabilitySend:    checkRawSend,
+	CapabilityTools:   checkNetworkTools,
+	CapabilityTrace:   checkSyscallTrace,
+}
+
+// CheckCapability probes one feature
+func CheckCapability(feature string) Capability {
+	capability := Capability{Feature: feature, Commands: capabilityCommands[feature]}
+	check, ok := capabilityChecks[feature]
+	if !ok {
+		capability.Detail = "unknown feature"
+		return capability
+	}
+	capability.Detail, capability.Fix, capability.Available = check()
+	if capability.Available {
+		capability.Fix = ""
+	}
+	return capability
+}
+
+// CheckCapabilities probes every feature
+func CheckCapabilities() []Capability {
+	capabilities := make([]Capability, 0, len(capabilityOrder))
+	for _, feature := range capabilityOrder {
+		capabilities = append(capabilities, CheckCapability(feature))
+	}
+	return capabilities
+}
+
+// RequireCapability returns a *CapabilityError when the feature is unusable
+func RequireCapability(feature string) error {
+	if capability := CheckCapability(feature); !capability.Available {
+		return &CapabilityError{capability}
+	}
+	return nil
+}
+
+// writeCapabilityWarning notes in output when a feature the command relies on
+// is unusable, with the fix, and reports whether it is usable
+func writeCapabilityWarning(feature string, output *strings.Builder) bool {
+	err, ok := RequireCapability(feature).(*CapabilityError)
+	if !ok {
+		return true
+	}
+	output.WriteString(color.New(color.FgYellow).Sprintf("⚠️  %s unavailable: %s\n", strings.ToUpper(err.Feature[:1])+err.Feature[1:], err.Detail))
+	if err.Fix != "" {
+		output.WriteString(color.New(color.FgYellow).Sprintf("💡 To fix it, %s\n", err.Fix))
+	}
+	output.WriteString(color.New(color.FgHiBlack).Sprint("   Run 'capabilities' to see what else is affected\n"))
+	return false
+}
+
+// missingToolError explains a system tool that couldn't be run because it
+// isn't installed, and returns other errors as they are
+func missingToolError(err error) error {
+	if !errors.Is(err, exec.ErrNotFound) {
+		return err
+	}
+	if capability := CheckCapability(CapabilityTools); !capability.Available {
+		return &CapabilityError{capability}
+	}
+	return err
+}
+
+// checkNetworkTools looks for the system tools ping, tracert and mtu run
+func checkNetworkTools() (string, string, bool) {
+	tools := []string{"ping", "traceroute"}
+	fix := "install them, for example with 'sudo apt install iputils-ping traceroute' or 'sudo dnf install iputils traceroute'"
+	switch runtime.GOOS {
+	case "windows":
+		tools = []string{"ping", "tracert"}
+		fix = "they ship with Windows; check that %SystemRoot%\\System32 is on PATH"
+	case "darwin":
+		fix = "they ship with macOS; check that /sbin and /usr/sbin are on PATH"
+	}
+
+	var found, missing []string
+	for _, tool := range tools {
+		if path, err := exec.LookPath(tool); err == nil {
+			found = append(found, path)
+		} else {
+			missing = append(missing, tool)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Sprintf("%s not found on PATH", strings.Join(missing, " and ")), fix, false
+	}
+	return "found " + strings.Join(found, ", "), "", true
+}
+
+// CapabilitiesCommand reports which low-level features are usable
+type CapabilitiesCommand struct {
+	*commands.BaseCommand
+	flags *commands.FlagSet
+}
+
+// NewCapabilitiesCommand creates a capabilities command
+func NewCapabilitiesCommand() *CapabilitiesCommand {
+	usage := "capabilities [--json]"
+	return &CapabilitiesCommand{
+		BaseCommand: commands.NewBaseCommand(
+			"capabilities",
+			"Check which packet capture, raw socket, tracing and system tool features can be used",
+			usage,
+			[]string{"windows", "linux", "darwin"},
+			false,
+		),
+		flags: commands.NewFlagSet("capabilities", usage,
+			commands.FlagSpec{Name: "json", Help: "Print the report as JSON"},
+		),
+	}
+}
+
+// FlagSet returns the options capabilities accepts
+func (c *CapabilitiesCommand) FlagSet() *commands.FlagSet {
+	return c.flags
+}
+
+// capabilityReport is what capabilities --json prints
+type capabilityReport struct {
+	Platform     string       `json:"platform"`
+	Elevated     bool         `json:"elevated"`
+	Capabilities []Capability `json:"capabilities"`
+}
+
+// Execute probes every feature and reports the result
+func (c *CapabilitiesCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	startTime := time.Now()
+
+	flags, result := c.flags.ParseArguments(args, startTime)
+	if result != nil {
+		return result, nil
+	}
+	if len(flags.Args()) > 0 {
+		return &commands.Result{
+			Output:   "Usage: " + c.Usage() + "\n",
+			Error:    commands.UsageError(c.Name(), "unexpected argument %q", flags.Arg(0)),
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}, nil
+	}
+
+	report := capabilityReport{
+		Platform:     runtime.GOOS + "/" + runtime.GOARCH,
+		Elevated:     security.IsElevated(),
+		Capabilities: CheckCapabilities(),
+	}
+
+	if flags.Bool("json") {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return commands.ErrorResult("", err, startTime), nil
+		}
+		return &commands.Result{
+			Output:   string(data) + "\n",
+			ExitCode: 0,
+			Duration: time.Since(startTime),
+		}, nil
+	}
+
+	var output strings.Builder
+	output.WriteString(color.New(color.FgCyan, color.Bold).Sprint("🧰 CAPABILITY CHECK\n"))
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+	elevated := color.New(color.FgYellow).Sprint("no")
+	if report.Elevated {
+		elevated = color.New(color.FgGreen).Sprint("yes")
+	}
+	output.WriteString(fmt.Sprintf("💻 Platform:   %s\n", report.Platform))
+	output.WriteString(fmt.Sprintf("🔑 Elevated:   %s\n", elevated))
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+
+	usable := 0
+	for _, capability := range report.Capabilities {
+		mark := color.New(color.FgRed).Sprint("❌")
+		if capability.Available {
+			mark = color.New(color.FgGreen).Sprint("✅")
+			usable++
+		}
+		output.WriteString(fmt.Sprintf("%s %s\n", mark, color.New(color.Bold).Sprint(capability.Feature)))
+		output.WriteString(fmt.Sprintf("   Used by:  %s\n", strings.Join(capability.Commands, ", ")))
+		output.WriteString(fmt.Sprintf("   Status:   %s\n", capability.Detail))
+		if capability.Fix != "" {
+			output.WriteString(fmt.Sprintf("   💡 Fix:   %s\n", color.New(color.FgYellow).Sprint(capability.Fix)))
+		}
+	}
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+	output.WriteString(fmt.Sprintf("📊 %d of %d features usable\n", usable, len(report.Capabilities)))
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}, nil
+}
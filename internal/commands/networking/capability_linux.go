@@ -0,0 +1,69 @@
+package networking
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// packetSocketProtocol is ETH_P_ALL in network byte order, as AF_PACKET
+// sockets take it
+var packetSocketProtocol = int(uint16(unix.ETH_P_ALL)<<8 | uint16(unix.ETH_P_ALL)>>8)
+
+// rawSocketFix explains how to get the privileges raw sockets need on Linux
+func rawSocketFix() string {
+	exe, err := os.Executable()
+	if err != nil {
+		exe = "supershell"
+	}
+	return fmt.Sprintf("run with sudo, or grant the capability once with 'sudo setcap cap_net_raw,cap_net_admin+eip %s'", exe)
+}
+
+// checkPacketSocket opens and closes an AF_PACKET socket, which capturing
+// and sending raw frames both need
+func checkPacketSocket() (string, string, bool) {
+	fd, err := unix.Socket(unix.AF_PACKET, unix.SOCK_RAW, packetSocketProtocol)
+	switch {
+	case err == unix.EPERM || err == unix.EACCES:
+		return "opening a packet socket needs CAP_NET_RAW", rawSocketFix(), false
+	case err == unix.EAFNOSUPPORT:
+		return "this kernel has no AF_PACKET support", "load the af_packet module with 'sudo modprobe af_packet'", false
+	case err != nil:
+		return fmt.Sprintf("cannot open a packet socket: %v", err), rawSocketFix(), false
+	}
+	unix.Close(fd)
+	return "AF_PACKET sockets can be opened", "", true
+}
+
+// checkPacketCapture checks that packets can be captured
+func checkPacketCapture() (string, string, bool) {
+	return checkPacketSocket()
+}
+
+// checkRawSend checks that raw frames can be sent
+func checkRawSend() (string, string, bool) {
+	return checkPacketSocket()
+}
+
+// checkSyscallTrace looks for strace and checks that the kernel lets it trace
+func checkSyscallTrace() (string, string, bool) {
+	path, err := exec.LookPath("strace")
+	if err != nil {
+		return "strace not found on PATH", "install it, for example with 'sudo apt install strace' or 'sudo dnf install strace'", false
+	}
+	data, _ := ioutil.ReadFile("/proc/sys/kernel/yama/ptrace_scope")
+	scope := strings.TrimSpace(string(data))
+	switch {
+	case scope == "3":
+		return "ptrace is disabled (kernel.yama.ptrace_scope=3)", "set kernel.yama.ptrace_scope to 1 at boot, as 3 can't be lowered while running", false
+	case scope == "2" && os.Geteuid() != 0:
+		return "only root may trace (kernel.yama.ptrace_scope=2)", "run with sudo", false
+	case scope == "1" && os.Geteuid() != 0:
+		return fmt.Sprintf("found %s; attaching to running processes needs sudo (kernel.yama.ptrace_scope=1), programs trace starts are fine", path), "", true
+	}
+	return "found " + path, "", true
+}
@@ -0,0 +1,46 @@
+//go:build !linux && !windows
+// +build !linux,!windows
+
+package networking
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+)
+
+// checkPacketCapture opens a BPF device, which capturing needs on macOS and
+// the BSDs
+func checkPacketCapture() (string, string, bool) {
+	denied := false
+	for i := 0; i < 256; i++ {
+		device := fmt.Sprintf("/dev/bpf%d", i)
+		file, err := os.OpenFile(device, os.O_RDONLY, 0)
+		if err == nil {
+			file.Close()
+			return device + " can be opened", "", true
+		}
+		if os.IsNotExist(err) {
+			break
+		}
+		// A busy device is in use by another capture, the next may be free
+		denied = denied || os.IsPermission(err)
+	}
+	if denied {
+		return "the BPF devices need root",
+			"run with sudo, or install Wireshark's ChmodBPF so members of access_bpf can capture", false
+	}
+	return "no free BPF device", "close other captures and try again", false
+}
+
+// checkRawSend reports that replay can't send frames here
+func checkRawSend() (string, string, bool) {
+	return fmt.Sprintf("sending raw frames is only supported on Linux, not %s", runtime.GOOS),
+		"replay the capture from a Linux machine, or show it without --send", false
+}
+
+// checkSyscallTrace reports that trace has no tracer to drive here
+func checkSyscallTrace() (string, string, bool) {
+	return fmt.Sprintf("tracing is only supported on Linux and Windows, not %s", runtime.GOOS),
+		"use the tools macOS ships, such as 'sudo fs_usage -w -f filesys <pid>' or dtruss", false
+}
@@ -0,0 +1,38 @@
+package networking
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// npcapFix is how to get packet capture on Windows
+const npcapFix = "install Npcap from https://npcap.com/#download with \"WinPcap API-compatible Mode\" ticked, then restart SuperShell"
+
+// checkPacketCapture looks for the Npcap (or WinPcap) driver's library
+func checkPacketCapture() (string, string, bool) {
+	system := filepath.Join(os.Getenv("SystemRoot"), "System32")
+	for _, dll := range []string{filepath.Join(system, "Npcap", "wpcap.dll"), filepath.Join(system, "wpcap.dll")} {
+		if _, err := os.Stat(dll); err == nil {
+			return "found " + dll, "", true
+		}
+	}
+	return "Npcap is not installed", npcapFix, false
+}
+
+// checkRawSend reports that replay can't send frames on Windows
+func checkRawSend() (string, string, bool) {
+	return "sending raw frames is only supported on Linux", "replay the capture from a Linux machine, or show it without --send", false
+}
+
+// checkSyscallTrace looks for Sysinternals Handle, which trace polls for the
+// files a process has open
+func checkSyscallTrace() (string, string, bool) {
+	for _, tool := range []string{"handle64", "handle"} {
+		if path, err := exec.LookPath(tool); err == nil {
+			return "found " + path + "; other users' processes need an elevated shell", "", true
+		}
+	}
+	return "Sysinternals Handle is not installed",
+		"download it from https://learn.microsoft.com/sysinternals/downloads/handle and put handle64.exe or handle.exe on PATH", false
+}
@@ -0,0 +1,363 @@
+package networking
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+
+	"github.com/fatih/color"
+)
+
+// CapstatsCommand summarizes saved captures offline
+type CapstatsCommand struct {
+	*commands.BaseCommand
+	flags *commands.FlagSet
+	sniff *SniffCommand
+}
+
+// NewCapstatsCommand creates a capstats command
+func NewCapstatsCommand() *CapstatsCommand {
+	usage := "capstats <file.pcap>... [--top <n>] [--json]"
+	return &CapstatsCommand{
+		BaseCommand: commands.NewBaseCommand(
+			"capstats",
+			"Summarize and compare saved pcap captures: protocols, top talkers, conversations and time span",
+			usage,
+			[]string{"windows", "linux", "darwin"},
+			false,
+		),
+		flags: commands.NewFlagSet("capstats", usage,
+			commands.FlagSpec{Name: "top", Short: "n", Kind: commands.IntFlag, Default: "10", Value: "n", Help: "Talkers and conversations to list, 0 for all"},
+			commands.FlagSpec{Name: "json", Help: "Print the statistics as JSON"},
+		),
+		sniff: NewSniffCommand(),
+	}
+}
+
+// FlagSet returns the options capstats accepts
+func (c *CapstatsCommand) FlagSet() *commands.FlagSet {
+	return c.flags
+}
+
+// captureShare is the part of a capture one protocol accounts for
+type captureShare struct {
+	Protocol string  `json:"protocol"`
+	Packets  int     `json:"packets"`
+	Bytes    int64   `json:"bytes"`
+	Percent  float64 `json:"percent"`
+}
+
+// captureTalker is the traffic of one host, in either direction
+type captureTalker struct {
+	Host     string `json:"host"`
+	Packets  int    `json:"packets"`
+	Sent     int64  `json:"sent"`
+	Received int64  `json:"received"`
+}
+
+// bytes is all the traffic of the host
+func (t captureTalker) bytes() int64 {
+	return t.Sent + t.Received
+}
+
+// captureSummary is the statistics of one capture, or of several together
+type captureSummary struct {
+	File          string          `json:"file"`
+	Packets       int             `json:"packets"`
+	Bytes         int64           `json:"bytes"`
+	Start         time.Time       `json:"start,omitempty"`
+	End           time.Time       `json:"end,omitempty"`
+	Seconds       float64         `json:"duration_seconds"`
+	Protocols     []captureShare  `json:"protocols"`
+	Talkers       []captureTalker `json:"top_talkers"`
+	Conversations []sniffFlow     `json:"conversations"`
+	// Error says why the capture could only be read in part
+	Error string `json:"error,omitempty"`
+}
+
+// captureStats collects the statistics of captured packets
+type captureStats struct {
+	flows     *sniffFlowTable
+	protocols map[string]*captureShare
+	talkers   map[string]*captureTalker
+	first     time.Time
+	last      time.Time
+}
+
+// newCaptureStats creates empty statistics
+func newCaptureStats() *captureStats {
+	return &captureStats{
+		flows:     newSniffFlowTable(),
+		protocols: make(map[string]*captureShare),
+		talkers:   make(map[string]*captureTalker),
+	}
+}
+
+// add counts one packet
+func (s *captureStats) add(packet Packet) {
+	s.flows.add(packet)
+
+	share, ok := s.protocols[packet.Protocol]
+	if !ok {
+		share = &captureShare{Protocol: packet.Protocol}
+		s.protocols[packet.Protocol] = share
+	}
+	share.Packets++
+	share.Bytes += int64(packet.Size)
+
+	if packet.Source != "" {
+		source := s.talker(packet.Source)
+		source.Packets++
+		source.Sent += int64(packet.Size)
+	}
+	if packet.Destination != "" {
+		destination := s.talker(packet.Destination)
+		destination.Packets++
+		destination.Received += int64(packet.Size)
+	}
+
+	if s.first.IsZero() || packet.Timestamp.Before(s.first) {
+		s.first = packet.Timestamp
+	}
+	if packet.Timestamp.After(s.last) {
+		s.last = packet.Timestamp
+	}
+}
+
+// talker returns the entry of host, creating it
+func (s *captureStats) talker(host string) *captureTalker {
+	talker, ok := s.talkers[host]
+	if !ok {
+		talker = &captureTalker{Host: host}
+		s.talkers[host] = talker
+	}
+	return talker
+}
+
+// span is the time between the first and last packet
+func (s *captureStats) span() time.Duration {
+	return s.last.Sub(s.first)
+}
+
+// summary returns the statistics with the top talkers and conversations, all
+// of them when top is 0
+func (s *captureStats) summary(file string, top int) captureSummary {
+	summary := captureSummary{
+		File:          file,
+		Packets:       s.flows.packets,
+		Bytes:         s.flows.bytes,
+		Start:         s.first,
+		End:           s.last,
+		Seconds:       s.span().Seconds(),
+		Protocols:     []captureShare{},
+		Talkers:       []captureTalker{},
+		Conversations: s.flows.sorted(),
+	}
+
+	for _, share := range s.protocols {
+		share := *share
+		if s.flows.bytes > 0 {
+			share.Percent = float64(share.Bytes) * 100 / float64(s.flows.bytes)
+		}
+		summary.Protocols = append(summary.Protocols, share)
+	}
+	sort.Slice(summary.Protocols, func(i, j int) bool {
+		if summary.Protocols[i].Bytes != summary.Protocols[j].Bytes {
+			return summary.Protocols[i].Bytes > summary.Protocols[j].Bytes
+		}
+		return summary.Protocols[i].Protocol < summary.Protocols[j].Protocol
+	})
+
+	for _, talker := range s.talkers {
+		summary.Talkers = append(summary.Talkers, *talker)
+	}
+	sort.Slice(summary.Talkers, func(i, j int) bool {
+		if summary.Talkers[i].bytes() != summary.Talkers[j].bytes() {
+			return summary.Talkers[i].bytes() > summary.Talkers[j].bytes()
+		}
+		return summary.Talkers[i].Host < summary.Talkers[j].Host
+	})
+
+	if top > 0 && len(summary.Talkers) > top {
+		summary.Talkers = summary.Talkers[:top]
+	}
+	if top > 0 && len(summary.Conversations) > top {
+		summary.Conversations = summary.Conversations[:top]
+	}
+	return summary
+}
+
+// capstatsReport is the JSON document capstats --json prints
+type capstatsReport struct {
+	Files []captureSummary `json:"files"`
+	// Combined covers all the files when there are several
+	Combined *captureSummary `json:"combined,omitempty"`
+}
+
+// Execute reads every capture and reports its statistics, comparing them when
+// there are several
+func (c *CapstatsCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	startTime := time.Now()
+
+	flags, result := c.flags.ParseArguments(args, startTime)
+	if result != nil {
+		return result, nil
+	}
+	if len(flags.Args()) == 0 {
+		return commands.UsageResult(c, startTime, "expected at least one capture file"), nil
+	}
+	top := flags.Int("top")
+	if top < 0 {
+		return commands.UsageResult(c, startTime, "--top can't be negative"), nil
+	}
+
+	var report capstatsReport
+	var files []*captureStats
+	combined := newCaptureStats()
+	failed := false
+	for _, file := range flags.Args() {
+		stats := newCaptureStats()
+		err := c.read(file, func(packet Packet) {
+			stats.add(packet)
+			combined.add(packet)
+		})
+		if err != nil && stats.flows.packets == 0 {
+			return commands.ErrorResult("", commands.FileError(c.Name(), file, err), startTime), nil
+		}
+		summary := stats.summary(file, top)
+		if err != nil {
+			// Statistics of what could be read are still worth showing
+			summary.Error = err.Error()
+			failed = true
+		}
+		report.Files = append(report.Files, summary)
+		files = append(files, stats)
+	}
+	if len(files) > 1 {
+		summary := combined.summary(fmt.Sprintf("%d captures", len(files)), top)
+		report.Combined = &summary
+	}
+
+	exitCode := 0
+	if failed {
+		exitCode = 1
+	}
+
+	if flags.Bool("json") {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return &commands.Result{
+			Output:   string(data) + "\n",
+			ExitCode: exitCode,
+			Duration: time.Since(startTime),
+		}, nil
+	}
+
+	var output strings.Builder
+	output.WriteString(color.New(color.FgCyan, color.Bold).Sprint("📊 CAPTURE STATISTICS\n"))
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+	detail, detailStats := report.Files[0], files[0]
+	if report.Combined != nil {
+		c.writeComparison(&output, report.Files)
+		detail, detailStats = *report.Combined, combined
+	} else if detail.Error != "" {
+		output.WriteString(color.New(color.FgYellow).Sprintf("⚠️  %s\n", detail.Error))
+	}
+	c.writeSummary(&output, detail, detailStats, top)
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: exitCode,
+		Duration: time.Since(startTime),
+	}, nil
+}
+
+// read decodes every packet of a capture file. An error after some packets
+// were read leaves those counted.
+func (c *CapstatsCommand) read(file string, fn func(packet Packet)) error {
+	reader, closer, err := openCaptureFile(file)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+
+	read := 0
+	for {
+		data, info, err := reader.ReadPacketData()
+		if err != nil {
+			return captureReadError(err, read)
+		}
+		read++
+		fn(c.sniff.decodePacket(data, info, reader.LinkType()))
+	}
+}
+
+// writeComparison lists the captures side by side
+func (c *CapstatsCommand) writeComparison(output *strings.Builder, files []captureSummary) {
+	output.WriteString(color.New(color.Bold).Sprintf("%-28s %9s %10s %10s  %s\n", "Capture", "Packets", "Bytes", "Span", "Top protocol"))
+	for _, file := range files {
+		protocol := "-"
+		if len(file.Protocols) > 0 {
+			protocol = fmt.Sprintf("%s (%.0f%%)", file.Protocols[0].Protocol, file.Protocols[0].Percent)
+		}
+		output.WriteString(fmt.Sprintf("%-28s %9d %10s %10v  %s\n",
+			truncateCaptureName(filepath.Base(file.File), 28), file.Packets, commands.HumanizeBytes(file.Bytes),
+			time.Duration(file.Seconds*float64(time.Second)).Round(time.Millisecond), protocol))
+		if file.Error != "" {
+			output.WriteString(color.New(color.FgYellow).Sprintf("   ⚠️  %s\n", file.Error))
+		}
+	}
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+}
+
+// writeSummary renders the statistics of one capture, or of all of them
+func (c *CapstatsCommand) writeSummary(output *strings.Builder, summary captureSummary, stats *captureStats, top int) {
+	output.WriteString(fmt.Sprintf("📄 Capture:     %s\n", summary.File))
+	output.WriteString(fmt.Sprintf("📦 Packets:     %d\n", summary.Packets))
+	output.WriteString(fmt.Sprintf("📊 Bytes:       %s\n", commands.HumanizeBytes(summary.Bytes)))
+	if summary.Packets > 0 {
+		output.WriteString(fmt.Sprintf("⏱️  Time span:   %s – %s (%v)\n",
+			summary.Start.Local().Format("2006-01-02 15:04:05.000"), summary.End.Local().Format("15:04:05.000"), stats.span().Round(time.Millisecond)))
+		if seconds := stats.span().Seconds(); seconds > 0 {
+			output.WriteString(fmt.Sprintf("🚀 Average:     %.1f packets/s, %s/s\n", float64(summary.Packets)/seconds, commands.HumanizeBytes(int64(float64(summary.Bytes)/seconds))))
+		}
+	}
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+
+	output.WriteString(color.New(color.FgGreen, color.Bold).Sprint("🌐 PROTOCOLS\n"))
+	output.WriteString(color.New(color.Bold).Sprintf("%-12s %9s %10s %7s\n", "Protocol", "Packets", "Bytes", "Share"))
+	for _, share := range summary.Protocols {
+		output.WriteString(fmt.Sprintf("%-12s %9d %10s %6.1f%%\n",
+			share.Protocol, share.Packets, commands.HumanizeBytes(share.Bytes), share.Percent))
+	}
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+
+	output.WriteString(color.New(color.FgGreen, color.Bold).Sprint("🗣️  TOP TALKERS\n"))
+	output.WriteString(color.New(color.Bold).Sprintf("%-40s %9s %10s %10s\n", "Host", "Packets", "Sent", "Received"))
+	for _, talker := range summary.Talkers {
+		output.WriteString(fmt.Sprintf("%-40s %9d %10s %10s\n",
+			talker.Host, talker.Packets, commands.HumanizeBytes(talker.Sent), commands.HumanizeBytes(talker.Received)))
+	}
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+
+	// Conversations are the flows sniff --summary shows live
+	output.WriteString(stats.flows.render(top, stats.span()))
+}
+
+// truncateCaptureName shortens a file name to fit a column
+func truncateCaptureName(name string, width int) string {
+	runes := []rune(name)
+	if len(runes) <= width {
+		return name
+	}
+	return string(runes[:width-1]) + "…"
+}
@@ -0,0 +1,94 @@
+package networking
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+)
+
+// pcapngMagic starts the section header block of a pcapng file
+const pcapngMagic = 0x0a0d0d0a
+
+// pcapMagics start a pcap file, with microsecond or nanosecond timestamps in
+// either byte order, or gzip data pcapgo unpacks
+var pcapMagics = [][]byte{
+	{0xd4, 0xc3, 0xb2, 0xa1}, {0xa1, 0xb2, 0xc3, 0xd4},
+	{0x4d, 0x3c, 0xb2, 0xa1}, {0xa1, 0xb2, 0x3c, 0x4d},
+	{0x1f, 0x8b},
+}
+
+// captureReader reads the packets of a pcap or pcapng file
+type captureReader interface {
+	ReadPacketData() ([]byte, gopacket.CaptureInfo, error)
+	LinkType() layers.LinkType
+}
+
+// openCaptureFile opens a capture file for reading, returning what closes it
+func openCaptureFile(path string) (captureReader, io.Closer, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	reader, err := openCapture(file)
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	return reader, file, nil
+}
+
+// openCapture reads the header of a pcap or pcapng file, explaining files that
+// are neither
+func openCapture(file io.Reader) (captureReader, error) {
+	buffered := bufio.NewReader(file)
+	magic, err := buffered.Peek(4)
+	if err == io.EOF && len(magic) == 0 {
+		return nil, fmt.Errorf("the file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("too short to be a capture file")
+	}
+
+	if binary.LittleEndian.Uint32(magic) == pcapngMagic {
+		reader, err := pcapgo.NewNgReader(buffered, pcapgo.DefaultNgReaderOptions)
+		if err != nil {
+			return nil, fmt.Errorf("not a readable pcapng file: %v", err)
+		}
+		return reader, nil
+	}
+	known := false
+	for _, prefix := range pcapMagics {
+		known = known || bytes.HasPrefix(magic, prefix)
+	}
+	if !known {
+		return nil, fmt.Errorf("not a pcap or pcapng file")
+	}
+	reader, err := pcapgo.NewReader(buffered)
+	if err == io.ErrUnexpectedEOF {
+		return nil, fmt.Errorf("the pcap header is truncated")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unsupported pcap file: %v", err)
+	}
+	return reader, nil
+}
+
+// captureReadError explains why reading the packet after the first read ones
+// failed, returning nil at the end of the capture
+func captureReadError(err error, read int) error {
+	switch err {
+	case io.EOF:
+		return nil
+	case io.ErrUnexpectedEOF:
+		return fmt.Errorf("the capture is truncated: packet %d is cut off, %d packets before it were read", read+1, read)
+	default:
+		return fmt.Errorf("the capture is corrupt at packet %d: %v", read+1, err)
+	}
+}
@@ -0,0 +1,174 @@
+package networking
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+)
+
+// captureSnaplen is the snapshot length written to the pcap headers
+const captureSnaplen = 65535
+
+// pcap files start with a 24 byte header, each packet with a 16 byte one
+const (
+	pcapFileHeaderSize   = 24
+	pcapPacketHeaderSize = 16
+)
+
+// captureRotation limits how much one capture file holds. With neither Size
+// nor Every set everything goes to a single file.
+type captureRotation struct {
+	// Size starts a new file before one would grow past this many bytes
+	Size int64
+	// Every starts a new file once the current one spans this much capture time
+	Every time.Duration
+	// Keep removes the oldest files beyond this many, 0 keeps them all
+	Keep int
+}
+
+// enabled reports whether the capture rolls over to numbered files
+func (r captureRotation) enabled() bool {
+	return r.Size > 0 || r.Every > 0
+}
+
+// captureWriter saves packets to a pcap file, rolling over to capture-001.pcap,
+// capture-002.pcap and so on when rotation is enabled
+type captureWriter struct {
+	path     string
+	linkType layers.LinkType
+	rotation captureRotation
+	// report is told about every rotation and removed file
+	report func(format string, args ...interface{})
+
+	file    *os.File
+	writer  *pcapgo.Writer
+	size    int64
+	packets int
+	opened  time.Time
+	number  int
+	files   []string
+}
+
+// newCaptureWriter creates the first capture file
+func newCaptureWriter(path string, linkType layers.LinkType, rotation captureRotation, report func(format string, args ...interface{})) (*captureWriter, error) {
+	w := &captureWriter{path: path, linkType: linkType, rotation: rotation, report: report}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// name returns the path of the numbered file n, or the path itself when the
+// capture doesn't rotate
+func (w *captureWriter) name(n int) string {
+	if !w.rotation.enabled() {
+		return w.path
+	}
+	ext := filepath.Ext(w.path)
+	if ext == "" {
+		ext = ".pcap"
+	}
+	return fmt.Sprintf("%s-%03d%s", strings.TrimSuffix(w.path, filepath.Ext(w.path)), n, ext)
+}
+
+// open starts the next file with a fresh pcap header
+func (w *captureWriter) open() error {
+	w.number++
+	name := w.name(w.number)
+	file, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	writer := pcapgo.NewWriter(file)
+	if err := writer.WriteFileHeader(captureSnaplen, w.linkType); err != nil {
+		file.Close()
+		return fmt.Errorf("writing the header of %s: %w", name, err)
+	}
+	w.file, w.writer = file, writer
+	w.size, w.packets = pcapFileHeaderSize, 0
+	w.opened = time.Time{}
+	w.files = append(w.files, name)
+	return nil
+}
+
+// due reports whether a packet captured at ts of n bytes belongs in a new file.
+// A file always takes at least one packet, so one larger than the size limit
+// doesn't rotate forever.
+func (w *captureWriter) due(ts time.Time, n int) bool {
+	if w.packets == 0 {
+		return false
+	}
+	if w.rotation.Size > 0 && w.size+int64(pcapPacketHeaderSize+n) > w.rotation.Size {
+		return true
+	}
+	return w.rotation.Every > 0 && ts.Sub(w.opened) >= w.rotation.Every
+}
+
+// rotate closes the current file, opens the next one and drops the oldest
+// files beyond the limit
+func (w *captureWriter) rotate() error {
+	previous, size, packets := w.file.Name(), w.size, w.packets
+	if err := w.file.Close(); err != nil {
+		return fmt.Errorf("closing %s: %w", previous, err)
+	}
+	w.file = nil
+	if err := w.open(); err != nil {
+		return err
+	}
+	w.report("🔄 Rotated %s (%d packets, %s) to %s\n", filepath.Base(previous), packets, commands.HumanizeBytes(size), filepath.Base(w.file.Name()))
+
+	for w.rotation.Keep > 0 && len(w.files) > w.rotation.Keep {
+		oldest := w.files[0]
+		w.files = w.files[1:]
+		if err := os.Remove(oldest); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("removing %s: %w", oldest, err)
+		}
+		w.report("🗑️  Removed %s, keeping the last %d files\n", filepath.Base(oldest), w.rotation.Keep)
+	}
+	return nil
+}
+
+// WritePacket saves a packet, starting a new file first when the current one
+// is full or old enough
+func (w *captureWriter) WritePacket(info gopacket.CaptureInfo, data []byte) error {
+	if w.file == nil {
+		return fmt.Errorf("the capture file is closed")
+	}
+	if w.due(info.Timestamp, len(data)) {
+		if err := w.rotate(); err != nil {
+			return err
+		}
+	}
+	if err := w.writer.WritePacket(info, data); err != nil {
+		return fmt.Errorf("writing %s: %w", w.file.Name(), err)
+	}
+	if w.packets == 0 {
+		w.opened = info.Timestamp
+	}
+	w.packets++
+	w.size += int64(pcapPacketHeaderSize + len(data))
+	return nil
+}
+
+// Files returns the capture files written and still on disk, oldest first
+func (w *captureWriter) Files() []string {
+	return w.files
+}
+
+// Close closes the current file
+func (w *captureWriter) Close() error {
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
@@ -0,0 +1,216 @@
+package networking
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"suppercommand/internal/commands"
+)
+
+// sendArchive streams files as one tar archive, gzipped when the transfer is
+// compressed, and follows it with the checksum of every file
+func (f *FastcpSendCommand) sendArchive(w io.Writer, header *fastcpHeader, files []fastcpSourceFile, stats *TransferStats, progress *commands.ProgressReporter) error {
+	stream := w
+	var compressor *gzip.Writer
+	if header.Compressed {
+		compressor = gzip.NewWriter(w)
+		stream = compressor
+	}
+
+	archive := tar.NewWriter(stream)
+	trailer := fastcpArchiveTrailer{Checksums: make(map[string]string, len(files))}
+	for _, file := range files {
+		checksum, err := writeFastcpArchiveEntry(archive, file, progress)
+		stats.addFile(file.entry.Path, file.entry.Size, checksum, err)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file.entry.Path, err)
+		}
+		trailer.Checksums[file.entry.Path] = checksum
+		progress.CompleteFile()
+	}
+	if err := archive.Close(); err != nil {
+		return err
+	}
+	if compressor != nil {
+		if err := compressor.Close(); err != nil {
+			return err
+		}
+	}
+	return writeFastcpMessage(w, trailer)
+}
+
+// writeFastcpArchiveEntry adds one file or link to the archive with its
+// permissions and modification time, and returns the checksum of its data
+func writeFastcpArchiveEntry(archive *tar.Writer, file fastcpSourceFile, progress *commands.ProgressReporter) (string, error) {
+	hasher := sha256.New()
+	if file.entry.Link != "" {
+		info, err := os.Lstat(file.local)
+		if err != nil {
+			return "", err
+		}
+		// The link target travels in the header too, so a symlink has no data
+		return hex.EncodeToString(hasher.Sum(nil)), archive.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeSymlink,
+			Name:     file.entry.Path,
+			Linkname: file.entry.Link,
+			Mode:     0777,
+			ModTime:  info.ModTime(),
+			Format:   tar.FormatPAX,
+		})
+	}
+
+	in, err := os.Open(file.local)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return "", err
+	}
+	// PAX keeps long paths and sub-second modification times
+	if err := archive.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     file.entry.Path,
+		Size:     file.entry.Size,
+		Mode:     int64(info.Mode().Perm()),
+		ModTime:  info.ModTime(),
+		Format:   tar.FormatPAX,
+	}); err != nil {
+		return "", err
+	}
+
+	written, err := io.CopyN(io.MultiWriter(archive, hasher, progress), in, file.entry.Size)
+	if err != nil {
+		if err == io.EOF {
+			return "", fmt.Errorf("file shrank during transfer (%d of %d bytes)", written, file.entry.Size)
+		}
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// fastcpArchivePart is a file of an archive transfer written to its part file
+// and waiting for the trailer to confirm its checksum
+type fastcpArchivePart struct {
+	path     string
+	checksum string
+	mode     os.FileMode
+	modTime  time.Time
+}
+
+// receiveArchive extracts the tar stream of an archive transfer. Its entries
+// must be the files of the header in order. Each file goes to a part file and
+// is moved into place, with the permissions and modification time it had on
+// the sender, once the trailer after the stream confirms its checksum.
+func (h *fastcpConnHandler) receiveArchive(header *fastcpHeader, targets []string, renamedTo func(int) string, result *fastcpConnResult) error {
+	var stream io.Reader = h.reader
+	var decompressor *gzip.Reader
+	if header.Compressed {
+		var err error
+		if decompressor, err = gzip.NewReader(h.reader); err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+		// The trailer follows the compressed stream and must not be taken for another
+		decompressor.Multistream(false)
+		stream = decompressor
+	}
+
+	archive := tar.NewReader(stream)
+	parts := make([]*fastcpArchivePart, len(header.Files))
+	buffer := make([]byte, h.blockSize)
+	for i, entry := range header.Files {
+		next, err := archive.Next()
+		if err == io.EOF {
+			return fmt.Errorf("archive ended before %s", entry.Path)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: failed to read archive: %w", entry.Path, err)
+		}
+		if next.Name != entry.Path {
+			return fmt.Errorf("archive holds %q where %q was announced", next.Name, entry.Path)
+		}
+		if entry.Link != "" {
+			if next.Typeflag != tar.TypeSymlink || next.Linkname != entry.Link {
+				return fmt.Errorf("%s: archive entry does not match the announced link", entry.Path)
+			}
+			continue
+		}
+		if next.Typeflag != tar.TypeReg || next.Size != entry.Size {
+			return fmt.Errorf("%s: archive entry does not match the announced file", entry.Path)
+		}
+
+		partPath, checksum, err := h.writePart(entry, targets[i], header.TransferID, archive, buffer)
+		if err != nil {
+			result.FileStats = append(result.FileStats, TransferFileStats{Path: entry.Path, Bytes: entry.Size, Status: "failed", Error: err.Error()})
+			return fmt.Errorf("%s: %w", entry.Path, err)
+		}
+		parts[i] = &fastcpArchivePart{path: partPath, checksum: checksum, mode: next.FileInfo().Mode().Perm(), modTime: next.ModTime}
+	}
+	if _, err := archive.Next(); err != io.EOF {
+		return fmt.Errorf("archive holds more than the announced files")
+	}
+	if decompressor != nil {
+		// Reading to the end checks the stream's own checksum
+		if _, err := io.Copy(ioutil.Discard, decompressor); err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+	}
+
+	var trailer fastcpArchiveTrailer
+	if err := readFastcpMessage(h.reader, &trailer); err != nil {
+		return fmt.Errorf("failed to read checksums: %w", err)
+	}
+	for i, entry := range header.Files {
+		if entry.Link != "" {
+			h.storeLink(entry, targets[i], result)
+			continue
+		}
+
+		part := parts[i]
+		fileStats := TransferFileStats{Path: entry.Path, Bytes: entry.Size, Checksum: part.checksum, Status: "ok", SavedAs: renamedTo(i)}
+		err := h.placeArchivePart(entry, part, targets[i], trailer.Checksums[entry.Path])
+		if err != nil {
+			fileStats.Status = "failed"
+			fileStats.Error = err.Error()
+		}
+		result.FileStats = append(result.FileStats, fileStats)
+		if err != nil {
+			return fmt.Errorf("%s: %w", entry.Path, err)
+		}
+		result.Files++
+		result.Bytes += entry.Size
+		result.Paths = append(result.Paths, targets[i])
+		if h.progress != nil {
+			h.progress.CompleteFile()
+		}
+	}
+	return nil
+}
+
+// placeArchivePart moves a verified part file into place with the permissions
+// and modification time from the archive
+func (h *fastcpConnHandler) placeArchivePart(entry fastcpFileEntry, part *fastcpArchivePart, target, checksum string) error {
+	if checksum != part.checksum {
+		os.Remove(part.path)
+		return fmt.Errorf("checksum mismatch")
+	}
+	if err := os.Chmod(part.path, part.mode); err != nil {
+		return err
+	}
+	if err := os.Chtimes(part.path, part.modTime, part.modTime); err != nil {
+		return err
+	}
+	if err := os.Rename(part.path, target); err != nil {
+		return err
+	}
+	h.journal.update(entry.Path, entry.Size, entry.Size, checksum, true)
+	return nil
+}
@@ -2,61 +2,123 @@ package networking
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"math/rand"
+	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"suppercommand/internal/commands"
+	"suppercommand/internal/security"
 
 	"github.com/fatih/color"
 )
 
+const (
+	fastcpDefaultRetries = 3
+	fastcpRetryBaseDelay = 500 * time.Millisecond
+	fastcpRetryMaxDelay  = 8 * time.Second
+)
+
 // FastcpBackupCommand backs up files to cloud storage
 type FastcpBackupCommand struct {
 	*commands.BaseCommand
+	flags *commands.FlagSet
 }
 
 // NewFastcpBackupCommand creates a new fastcp-backup command
 func NewFastcpBackupCommand() *FastcpBackupCommand {
+	usage := "fastcp-backup <source> <bucket> [--encrypt] [--compress] [--incremental] [--retries N] [--cred <name>] [--dry-run] [--stats <file>] [--quiet] [--output-format text|json|csv]"
 	return &FastcpBackupCommand{
 		BaseCommand: commands.NewBaseCommand(
 			"fastcp-backup",
 			"Backup files to cloud storage (S3-compatible)",
-			"fastcp-backup <source> <bucket> [--encrypt] [--compress] [--incremental]",
+			usage,
 			[]string{"windows", "linux", "darwin"},
 			false,
 		),
+		flags: commands.NewFlagSet("fastcp-backup", usage,
+			commands.FlagSpec{Name: "encrypt", Help: "Encrypt objects before upload"},
+			commands.FlagSpec{Name: "compress", Help: "Compress objects before upload"},
+			commands.FlagSpec{Name: "incremental", Help: "Only upload files changed since the last backup"},
+			commands.FlagSpec{Name: "retries", Kind: commands.IntFlag, Default: strconv.Itoa(fastcpDefaultRetries), Value: "N", Help: "Upload attempts to retry per object"},
+			fastcpCloudCredFlag,
+			commands.FlagSpec{Name: "dry-run", Help: "List what would be uploaded"},
+			fastcpStatsFlag,
+		),
 	}
 }
 
+// OutputFormats returns the structured formats of the transfer report
+func (f *FastcpBackupCommand) OutputFormats() []commands.OutputFormat {
+	return fastcpOutputFormats
+}
+
+// FlagSet returns the flags fastcp-backup accepts
+func (f *FastcpBackupCommand) FlagSet() *commands.FlagSet {
+	return f.flags
+}
+
 // Execute backs up files to cloud storage
 func (f *FastcpBackupCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	flags, usage := f.flags.ParseArguments(args, time.Now())
+	if usage != nil {
+		return usage, nil
+	}
+	stats := newTransferStats(f.Name())
+	result, err := f.execute(ctx, flags, stats)
+	return withTransferStats(ctx, flags.String("stats"), stats, result), err
+}
+
+// execute performs the backup, recording outcomes in stats
+func (f *FastcpBackupCommand) execute(ctx context.Context, flags *commands.ParsedFlags, stats *TransferStats) (*commands.Result, error) {
 	startTime := time.Now()
 
-	if len(args.Raw) < 2 {
+	if len(flags.Args()) < 2 {
 		return &commands.Result{
-			Output:   "Usage: fastcp-backup <source> <bucket> [--encrypt] [--compress] [--incremental]\n",
+			Output:   "Usage: " + f.Usage() + "\n",
 			ExitCode: 1,
 			Duration: time.Since(startTime),
 		}, nil
 	}
 
-	// Parse arguments
-	source := args.Raw[0]
-	bucket := args.Raw[1]
-	encrypt := false
-	compress := false
-	incremental := false
+	source := flags.Arg(0)
+	bucket := flags.Arg(1)
+	encrypt := flags.Bool("encrypt")
+	compress := flags.Bool("compress")
+	incremental := flags.Bool("incremental")
+	dryRun := flags.Bool("dry-run")
+	retries := flags.Int("retries")
+	credName := flags.String("cred")
+	stats.Source = source
+	stats.Destination = fastcpBucketURL(bucket)
+
+	if retries < 0 {
+		return &commands.Result{
+			Output:   fmt.Sprintf("Error: invalid --retries value '%s'\n", flags.String("retries")),
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}, nil
+	}
 
-	for _, arg := range args.Raw[2:] {
-		switch arg {
-		case "--encrypt":
+	// Cloud credentials come from the credential store rather than the command line
+	var cloudCred *security.Credential
+	if credName != "" {
+		cred, err := loadFastcpCredential(credName, security.CredentialTypeAWS)
+		if err != nil {
+			return &commands.Result{
+				Output:   fmt.Sprintf("Error: %v\n", err),
+				ExitCode: 1,
+				Duration: time.Since(startTime),
+			}, nil
+		}
+		cloudCred = cred
+		if cred.Get("encryption_key") != "" {
 			encrypt = true
-		case "--compress":
-			compress = true
-		case "--incremental":
-			incremental = true
 		}
 	}
 
@@ -72,57 +134,149 @@ func (f *FastcpBackupCommand) Execute(ctx context.Context, args *commands.Argume
 		map[bool]string{true: color.New(color.FgGreen).Sprint("Enabled"), false: color.New(color.FgRed).Sprint("Disabled")}[compress]))
 	output.WriteString(fmt.Sprintf("📈 Incremental:  %s\n",
 		map[bool]string{true: color.New(color.FgGreen).Sprint("Enabled"), false: color.New(color.FgRed).Sprint("Disabled")}[incremental]))
+	output.WriteString(fmt.Sprintf("🔁 Retries:      %d per object\n", retries))
 	output.WriteString("───────────────────────────────────────────────────────────────\n")
 
+	if dryRun {
+		return f.dryRun(source, bucket, startTime, &output), nil
+	}
+
 	// Initialize cloud connection
 	output.WriteString("🔧 Initializing cloud backup...\n")
-	time.Sleep(500 * time.Millisecond)
+	if err := commands.Sleep(ctx, 500*time.Millisecond); err != nil {
+		return fastcpCancelled(&output, startTime, err), nil
+	}
 
-	output.WriteString("🔑 Authenticating with cloud provider...\n")
-	time.Sleep(800 * time.Millisecond)
+	if cloudCred != nil {
+		output.WriteString(fmt.Sprintf("🔑 Authenticating with cloud provider as %s (credential '%s')...\n",
+			security.MaskSecret(cloudCred.Get("access_key")), credName))
+	} else {
+		output.WriteString("🔑 Authenticating with cloud provider...\n")
+	}
+	if err := commands.Sleep(ctx, 800*time.Millisecond); err != nil {
+		return fastcpCancelled(&output, startTime, err), nil
+	}
 	output.WriteString("✅ Authentication successful\n")
 
 	output.WriteString(fmt.Sprintf("🪣 Connecting to bucket '%s'...\n", bucket))
-	time.Sleep(400 * time.Millisecond)
+	if err := commands.Sleep(ctx, 400*time.Millisecond); err != nil {
+		return fastcpCancelled(&output, startTime, err), nil
+	}
 	output.WriteString("✅ Bucket connection established\n")
 
 	output.WriteString("───────────────────────────────────────────────────────────────\n")
 
 	// Analyze source
 	output.WriteString("🔍 Analyzing source files...\n")
-	time.Sleep(1 * time.Second)
-
-	backupInfo := struct {
-		totalFiles     int
-		totalSize      int64
-		newFiles       int
-		modifiedFiles  int
-		unchangedFiles int
-	}{
-		totalFiles:     2847,
-		totalSize:      5583459328, // 5.2 GB
-		newFiles:       156,
-		modifiedFiles:  89,
-		unchangedFiles: 2602,
-	}
-
-	output.WriteString(fmt.Sprintf("📊 Total files:     %d\n", backupInfo.totalFiles))
-	output.WriteString(fmt.Sprintf("📏 Total size:      %s\n", formatBytes(backupInfo.totalSize)))
-
-	if incremental {
+	files, _, err := collectFastcpFiles(source, nil, true)
+	if err != nil {
+		output.WriteString(color.New(color.FgRed).Sprintf("❌ %v\n", err))
+		return &commands.Result{
+			Output:   output.String(),
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}, nil
+	}
+
+	var totalSize int64
+	for _, file := range files {
+		totalSize += file.entry.Size
+	}
+	output.WriteString(fmt.Sprintf("📊 Total files:     %d\n", len(files)))
+	output.WriteString(fmt.Sprintf("📏 Total size:      %s\n", commands.HumanizeBytes(totalSize)))
+
+	// Warnings go to stderr, apart from the report
+	var warnings strings.Builder
+	previous, err := loadFastcpBackupManifest(bucket, source)
+	if err != nil {
+		warnings.WriteString(color.New(color.FgYellow).Sprintf("⚠️  Ignoring backup manifest: %v\n", err))
+	}
+
+	// The journal of a backup killed before its manifest caught up names
+	// every object that made it
+	journal, err := loadFastcpBackupProgress(bucket, source)
+	if err != nil {
+		warnings.WriteString(color.New(color.FgYellow).Sprintf("⚠️  Ignoring backup progress: %v\n", err))
+	}
+	if journal != nil {
+		if previous == nil || previous.BackupID != journal.BackupID {
+			previous = newFastcpBackupManifest(bucket, source, journal.BackupID)
+		}
+		previous.Complete = false
+		for _, record := range journal.Completed {
+			entry, ok := previous.Objects[record.Path]
+			if !ok {
+				entry = &fastcpManifestEntry{Attempts: 1}
+				previous.Objects[record.Path] = entry
+			}
+			entry.Size, entry.Checksum, entry.Uploaded, entry.Error = record.Size, record.Checksum, true, ""
+		}
+	}
+
+	// An unfinished backup is resumed under its own ID; a finished one is only
+	// consulted for incremental backups
+	manifest := newFastcpBackupManifest(bucket, source, fmt.Sprintf("backup_%d", time.Now().Unix()))
+	var reference *fastcpBackupManifest
+	resuming := previous != nil && !previous.Complete
+	if resuming {
+		manifest = previous
+		reference = previous
+	} else if incremental {
+		reference = previous
+	}
+
+	var pending []fastcpSourceFile
+	var pendingSize int64
+	skipped, newFiles, modifiedFiles := 0, 0, 0
+	for _, file := range files {
+		if reference != nil {
+			checksum, err := fastcpFileChecksum(file.local)
+			if err == nil && reference.uploaded(file.entry.Path, file.entry.Size, checksum) {
+				if reference != manifest {
+					entry := *reference.Objects[file.entry.Path]
+					if entry.BackupID == "" {
+						entry.BackupID = reference.BackupID
+					}
+					manifest.Objects[file.entry.Path] = &entry
+				}
+				skipped++
+				continue
+			}
+			if _, seen := reference.Objects[file.entry.Path]; seen {
+				modifiedFiles++
+			} else {
+				newFiles++
+			}
+		}
+		pending = append(pending, file)
+		pendingSize += file.entry.Size
+	}
+
+	if resuming {
+		output.WriteString(fmt.Sprintf("♻️  Resuming backup %s: %d objects already uploaded\n", manifest.BackupID, skipped))
+		if journal != nil {
+			output.WriteString(fmt.Sprintf("📒 Files completed before the interruption (%d):\n", len(journal.Completed)))
+			for i, record := range journal.Completed {
+				if i == 10 {
+					output.WriteString(fmt.Sprintf("   ... and %d more\n", len(journal.Completed)-i))
+					break
+				}
+				output.WriteString(color.New(color.FgHiBlack).Sprintf("   ✅ %s\n", record.Path))
+			}
+		}
+		output.WriteString(fmt.Sprintf("📤 Files to backup: %d (%s)\n", len(pending), commands.HumanizeBytes(pendingSize)))
+	} else if incremental {
 		output.WriteString("───────────────────────────────────────────────────────────────\n")
 		output.WriteString(color.New(color.FgYellow, color.Bold).Sprint("📈 INCREMENTAL ANALYSIS\n"))
 		output.WriteString("───────────────────────────────────────────────────────────────\n")
-		output.WriteString(fmt.Sprintf("🆕 New files:       %d\n", backupInfo.newFiles))
-		output.WriteString(fmt.Sprintf("📝 Modified files:  %d\n", backupInfo.modifiedFiles))
-		output.WriteString(fmt.Sprintf("✅ Unchanged files: %d (skipped)\n", backupInfo.unchangedFiles))
-
-		filesToBackup := backupInfo.newFiles + backupInfo.modifiedFiles
-		sizeToBackup := int64(float64(backupInfo.totalSize) * float64(filesToBackup) / float64(backupInfo.totalFiles))
-
-		output.WriteString(fmt.Sprintf("📤 Files to backup: %d (%s)\n", filesToBackup, formatBytes(sizeToBackup)))
-		backupInfo.totalFiles = filesToBackup
-		backupInfo.totalSize = sizeToBackup
+		if reference == nil {
+			output.WriteString("💡 No previous backup of this source, so every file is new\n")
+			newFiles = len(pending)
+		}
+		output.WriteString(fmt.Sprintf("🆕 New files:       %d\n", newFiles))
+		output.WriteString(fmt.Sprintf("📝 Modified files:  %d\n", modifiedFiles))
+		output.WriteString(fmt.Sprintf("✅ Unchanged files: %d (skipped)\n", skipped))
+		output.WriteString(fmt.Sprintf("📤 Files to backup: %d (%s)\n", len(pending), commands.HumanizeBytes(pendingSize)))
 	}
 
 	output.WriteString("───────────────────────────────────────────────────────────────\n")
@@ -131,70 +285,220 @@ func (f *FastcpBackupCommand) Execute(ctx context.Context, args *commands.Argume
 	output.WriteString(color.New(color.FgGreen, color.Bold).Sprint("📤 STARTING BACKUP\n"))
 	output.WriteString("───────────────────────────────────────────────────────────────\n")
 
-	backupStart := time.Now()
-	var uploaded int64
-	var uploadedFiles int
+	progressLog, err := startFastcpBackupProgress(bucket, source, manifest.BackupID)
+	if err != nil {
+		warnings.WriteString(color.New(color.FgYellow).Sprintf("⚠️  Not keeping a progress journal: %v\n", err))
+	}
+
+	store, _ := openCloudStore(bucket)
+	progress := commands.NewProgressReporter(pendingSize, len(pending), commands.ProgressWriter(ctx))
+	var uploadedBytes, processedBytes int64
+	var succeeded int
+	var failures []string
+	lastSave := time.Now()
+
+	for i, file := range pending {
+		checksum, attempts, err := f.uploadWithRetry(ctx, store, manifest.BackupID+"/"+file.entry.Path, file, retries)
+		if ctx.Err() != nil {
+			progress.Finish()
+			manifest.save()
+			if progressLog != nil {
+				progressLog.close()
+			}
+			result := fastcpCancelled(&output, startTime, ctx.Err())
+			result.Stderr = warnings.String()
+			return result, nil
+		}
 
-	// Simulate backup progress
-	for progress := 0; progress <= 100; progress += 2 {
-		uploaded = int64(float64(backupInfo.totalSize) * float64(progress) / 100.0)
-		uploadedFiles = int(float64(backupInfo.totalFiles) * float64(progress) / 100.0)
-		speed := float64(uploaded) / time.Since(backupStart).Seconds()
+		entry := &fastcpManifestEntry{Size: file.entry.Size, Checksum: checksum, Uploaded: err == nil, Attempts: attempts}
+		stats.addFile(file.entry.Path, file.entry.Size, checksum, err)
+		if err != nil {
+			entry.Error = err.Error()
+			failures = append(failures, fmt.Sprintf("%s: %v", file.entry.Path, err))
+		} else {
+			succeeded++
+			uploadedBytes += file.entry.Size
+			if progressLog != nil {
+				progressLog.record(file.entry.Path, file.entry.Size, checksum)
+			}
+		}
+		manifest.Objects[file.entry.Path] = entry
 
-		progressBar := f.createProgressBar(progress, 50)
-		eta := time.Duration(float64(backupInfo.totalSize-uploaded)/speed) * time.Second
+		processedBytes += file.entry.Size
+		progress.Update(processedBytes, i+1)
 
-		output.WriteString(fmt.Sprintf("\r📈 %s %d%% (%d/%d files, %s/%s) - %s/s - ETA: %v",
-			progressBar, progress, uploadedFiles, backupInfo.totalFiles,
-			formatBytes(uploaded), formatBytes(backupInfo.totalSize),
-			formatBytes(int64(speed)), eta.Round(time.Second)))
+		// Keep the manifest current so an interrupted backup loses little work
+		if time.Since(lastSave) >= time.Second {
+			manifest.save()
+			lastSave = time.Now()
+		}
+	}
+	output.WriteString(progress.Finish())
 
-		time.Sleep(100 * time.Millisecond)
+	manifest.Complete = len(failures) == 0
+	if err := manifest.save(); err != nil {
+		warnings.WriteString(color.New(color.FgYellow).Sprintf("⚠️  Failed to save backup manifest: %v\n", err))
+	}
+	// Failed objects are retried from the manifest, which is now up to date;
+	// the journal only matters if that save didn't happen
+	if progressLog != nil {
+		if manifest.Complete {
+			progressLog.remove()
+		} else {
+			progressLog.close()
+		}
+	}
+	// The bucket keeps its own copy so the backup can be verified from anywhere
+	if store != nil {
+		if err := manifest.upload(ctx, store); err != nil {
+			warnings.WriteString(color.New(color.FgYellow).Sprintf("⚠️  Failed to upload backup manifest: %v\n", err))
+		}
 	}
-	output.WriteString("\n")
 
-	backupDuration := time.Since(backupStart)
-	avgSpeed := float64(backupInfo.totalSize) / backupDuration.Seconds()
+	backupDuration := progress.Elapsed()
+	stats.FilesTransferred = succeeded
+	stats.BytesSent = uploadedBytes
+	avgSpeed := progress.Average(time.Now())
 
 	output.WriteString("───────────────────────────────────────────────────────────────\n")
-	output.WriteString(color.New(color.FgGreen, color.Bold).Sprint("✅ BACKUP COMPLETE\n"))
+	if len(failures) == 0 {
+		output.WriteString(color.New(color.FgGreen, color.Bold).Sprint("✅ BACKUP COMPLETE\n"))
+	} else {
+		output.WriteString(color.New(color.FgYellow, color.Bold).Sprint("⚠️  BACKUP INCOMPLETE\n"))
+	}
 	output.WriteString("───────────────────────────────────────────────────────────────\n")
-	output.WriteString(fmt.Sprintf("📊 Files backed up: %d\n", backupInfo.totalFiles))
-	output.WriteString(fmt.Sprintf("📤 Data uploaded:   %s\n", formatBytes(backupInfo.totalSize)))
+	output.WriteString(fmt.Sprintf("📊 Files backed up: %d\n", succeeded))
+	output.WriteString(fmt.Sprintf("📤 Data uploaded:   %s\n", commands.HumanizeBytes(uploadedBytes)))
 	output.WriteString(fmt.Sprintf("⏱️  Duration:       %v\n", backupDuration.Round(time.Millisecond)))
-	output.WriteString(fmt.Sprintf("🚀 Average speed:  %s/s\n", formatBytes(int64(avgSpeed))))
+	output.WriteString(fmt.Sprintf("🚀 Average speed:  %s/s\n", commands.HumanizeBytes(int64(avgSpeed))))
 
 	if compress {
 		compressionRatio := 0.25 + rand.Float64()*0.35 // 25-60% compression
-		savedBytes := int64(float64(backupInfo.totalSize) * compressionRatio)
+		savedBytes := int64(float64(uploadedBytes) * compressionRatio)
 		output.WriteString(fmt.Sprintf("🗜️  Compression:    %s saved (%.1f%%)\n",
-			formatBytes(savedBytes), compressionRatio*100))
+			commands.HumanizeBytes(savedBytes), compressionRatio*100))
 	}
 
 	if encrypt {
 		output.WriteString("🔐 Encryption:     AES-256 applied to all files\n")
 	}
 
-	// Generate backup ID
-	backupID := fmt.Sprintf("backup_%d", time.Now().Unix())
+	backupID := manifest.BackupID
 	output.WriteString(fmt.Sprintf("🆔 Backup ID:      %s\n", color.New(color.FgYellow).Sprint(backupID)))
-	output.WriteString(fmt.Sprintf("🪣 Location:       s3://%s/%s/\n", bucket, backupID))
+	output.WriteString(fmt.Sprintf("🪣 Location:       %s/%s/\n", fastcpBucketURL(bucket), backupID))
+
+	// Reconcile the objects of this run
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+	output.WriteString(color.New(color.FgCyan, color.Bold).Sprint("📋 RECONCILIATION\n"))
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+	output.WriteString(fmt.Sprintf("✅ Succeeded:  %d\n", succeeded))
+	output.WriteString(fmt.Sprintf("❌ Failed:     %d\n", len(failures)))
+	output.WriteString(fmt.Sprintf("⏭️  Skipped:    %d (already uploaded)\n", skipped))
+	for i, failure := range failures {
+		if i == 10 {
+			output.WriteString(fmt.Sprintf("   ... and %d more\n", len(failures)-i))
+			break
+		}
+		output.WriteString(color.New(color.FgRed).Sprintf("   ❌ %s\n", failure))
+	}
+	if len(failures) > 0 {
+		output.WriteString("💡 Run the same command again to retry only the failed objects\n")
+	}
 
 	output.WriteString("───────────────────────────────────────────────────────────────\n")
 	output.WriteString("💡 Use 'fastcp-restore' to restore from this backup\n")
 	output.WriteString(fmt.Sprintf("💡 Restore command: fastcp-restore %s %s <destination>\n", bucket, backupID))
 	output.WriteString("═══════════════════════════════════════════════════════════════\n")
 
+	exitCode := 0
+	if len(failures) > 0 {
+		exitCode = 1
+	}
+
 	return &commands.Result{
 		Output:   output.String(),
-		ExitCode: 0,
+		Stderr:   warnings.String(),
+		ExitCode: exitCode,
 		Duration: time.Since(startTime),
 	}, nil
 }
 
-// createProgressBar creates a visual progress bar
-func (f *FastcpBackupCommand) createProgressBar(progress, width int) string {
-	filled := progress * width / 100
-	bar := strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
-	return fmt.Sprintf("[%s]", bar)
+// uploadWithRetry uploads one object, retrying failures up to retries more times
+// with an exponential backoff. It returns the checksum and the attempts made.
+func (f *FastcpBackupCommand) uploadWithRetry(ctx context.Context, store cloudStore, key string, file fastcpSourceFile, retries int) (string, int, error) {
+	for attempt := 1; ; attempt++ {
+		checksum, err := f.uploadObject(ctx, store, key, file)
+		// Retrying won't bring back a missing or unreadable file
+		if err == nil || attempt > retries || os.IsNotExist(err) || os.IsPermission(err) {
+			return checksum, attempt, err
+		}
+		if sleepErr := commands.Sleep(ctx, fastcpRetryDelay(attempt)); sleepErr != nil {
+			return "", attempt, sleepErr
+		}
+	}
+}
+
+// uploadObject uploads one file under key and returns its SHA-256. Without a store
+// the provider side is simulated like the rest of the cloud backup, but the file is
+// still read in full so missing and unreadable files fail as a real upload would.
+func (f *FastcpBackupCommand) uploadObject(ctx context.Context, store cloudStore, key string, file fastcpSourceFile) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if store == nil {
+		return fastcpFileChecksum(file.local)
+	}
+
+	in, err := os.Open(file.local)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+
+	hasher := sha256.New()
+	if err := store.PutObject(ctx, key, io.TeeReader(in, hasher)); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// fastcpRetryDelay returns the backoff before the given retry
+func fastcpRetryDelay(attempt int) time.Duration {
+	delay := fastcpRetryBaseDelay << uint(attempt-1)
+	if delay > fastcpRetryMaxDelay {
+		delay = fastcpRetryMaxDelay
+	}
+	return delay
+}
+
+// dryRun lists the objects a backup would upload without contacting the provider
+func (f *FastcpBackupCommand) dryRun(source, bucket string, startTime time.Time, output *strings.Builder) *commands.Result {
+	files, _, err := collectFastcpFiles(source, nil, true)
+	if err != nil {
+		output.WriteString(color.New(color.FgRed).Sprintf("❌ %v\n", err))
+		return &commands.Result{
+			Output:   output.String(),
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}
+	}
+
+	backupID := fmt.Sprintf("backup_%d", time.Now().Unix())
+	var totalSize int64
+
+	output.WriteString(color.New(color.FgYellow, color.Bold).Sprint("🧪 DRY RUN - nothing will be uploaded\n"))
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+	for _, file := range files {
+		totalSize += file.entry.Size
+		output.WriteString(fmt.Sprintf("  %s/%s/%s  (%s)\n", fastcpBucketURL(bucket), backupID, file.entry.Path, commands.HumanizeBytes(file.entry.Size)))
+	}
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+	output.WriteString(fmt.Sprintf("📊 Would upload: %d objects, %s\n", len(files), commands.HumanizeBytes(totalSize)))
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}
 }
@@ -0,0 +1,180 @@
+package networking
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"suppercommand/internal/config"
+)
+
+// fastcpProgressDir holds the progress journals of backups that are running or
+// were interrupted
+const fastcpProgressDir = "~/.supershell/fastcp"
+
+// fastcpProgressHeader is the first line of a progress journal
+type fastcpProgressHeader struct {
+	BackupID  string    `json:"backup_id"`
+	Bucket    string    `json:"bucket"`
+	Source    string    `json:"source"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// fastcpProgressRecord is one object the backup finished uploading
+type fastcpProgressRecord struct {
+	Path     string `json:"path"`
+	Size     int64  `json:"size"`
+	Checksum string `json:"checksum"`
+}
+
+// fastcpBackupProgress is a local journal of the objects a backup has uploaded.
+// Unlike the manifest, which is saved at intervals, a line is appended as soon
+// as each object is stored, so a backup killed with the shell can report and
+// skip exactly what it finished. The file holds one JSON value per line: the
+// header, then a record per object. It is removed once a backup completes.
+type fastcpBackupProgress struct {
+	path string
+	file *os.File
+	fastcpProgressHeader
+	// Completed holds the records read back from an interrupted backup, in order
+	Completed []fastcpProgressRecord
+}
+
+// fastcpBucketFileName keeps a bucket name readable in a file name, minus
+// anything that isn't safe there
+func fastcpBucketFileName(bucket string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == '.' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, strings.TrimPrefix(bucket, fastcpLocalBucketScheme))
+}
+
+// fastcpProgressPath returns the journal of a backup, named after its bucket
+// and the key prefix its objects are stored under
+func fastcpProgressPath(bucket, backupID string) string {
+	return filepath.Join(config.ExpandHome(fastcpProgressDir), fmt.Sprintf("%s-%s.progress.json", fastcpBucketFileName(bucket), backupID))
+}
+
+// loadFastcpBackupProgress finds the journal an interrupted backup of source to
+// bucket left behind, returning nil when there is none. A torn last line, from
+// a backup killed mid-write, is ignored.
+func loadFastcpBackupProgress(bucket, source string) (*fastcpBackupProgress, error) {
+	if absolute, err := filepath.Abs(source); err == nil {
+		source = absolute
+	}
+	pattern := filepath.Join(config.ExpandHome(fastcpProgressDir), fastcpBucketFileName(bucket)+"-*.progress.json")
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	var found *fastcpBackupProgress
+	for _, path := range paths {
+		progress, err := readFastcpBackupProgress(path)
+		if err != nil {
+			return nil, err
+		}
+		if progress.Bucket != bucket || progress.Source != source {
+			continue
+		}
+		// Backup IDs grow with time, so the newest journal wins
+		if found == nil || progress.StartedAt.After(found.StartedAt) {
+			found = progress
+		}
+	}
+	return found, nil
+}
+
+// readFastcpBackupProgress reads one journal
+func readFastcpBackupProgress(path string) (*fastcpBackupProgress, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	progress := &fastcpBackupProgress{path: path}
+	decoder := json.NewDecoder(bufio.NewReader(file))
+	if err := decoder.Decode(&progress.fastcpProgressHeader); err != nil {
+		return nil, fmt.Errorf("corrupt backup progress %s: %w", path, err)
+	}
+	for {
+		var record fastcpProgressRecord
+		if err := decoder.Decode(&record); err != nil {
+			// The end of the journal, or a line torn by a kill
+			break
+		}
+		progress.Completed = append(progress.Completed, record)
+	}
+	return progress, nil
+}
+
+// startFastcpBackupProgress opens the journal of a backup for appending,
+// writing its header when the journal is new. A resumed backup keeps adding to
+// the journal it was interrupted with.
+func startFastcpBackupProgress(bucket, source, backupID string) (*fastcpBackupProgress, error) {
+	if absolute, err := filepath.Abs(source); err == nil {
+		source = absolute
+	}
+	progress := &fastcpBackupProgress{
+		path:                 fastcpProgressPath(bucket, backupID),
+		fastcpProgressHeader: fastcpProgressHeader{BackupID: backupID, Bucket: bucket, Source: source, StartedAt: time.Now()},
+	}
+	if err := os.MkdirAll(filepath.Dir(progress.path), 0700); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(progress.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	progress.file = file
+
+	if info, err := file.Stat(); err == nil && info.Size() == 0 {
+		if err := progress.append(progress.fastcpProgressHeader); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	return progress, nil
+}
+
+// record notes that an object reached the bucket
+func (p *fastcpBackupProgress) record(path string, size int64, checksum string) error {
+	return p.append(fastcpProgressRecord{Path: path, Size: size, Checksum: checksum})
+}
+
+// append writes one line in a single write so a kill can only tear the last one
+func (p *fastcpBackupProgress) append(value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	_, err = p.file.Write(append(data, '\n'))
+	return err
+}
+
+// close stops recording, leaving the journal for the next run
+func (p *fastcpBackupProgress) close() {
+	if p.file != nil {
+		p.file.Close()
+		p.file = nil
+	}
+}
+
+// remove deletes the journal once the backup has completed
+func (p *fastcpBackupProgress) remove() error {
+	p.close()
+	if err := os.Remove(p.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,287 @@
+package networking
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/config"
+)
+
+const (
+	// fastcpDedupBlockSize is the size of the blocks a deduplicated transfer
+	// hashes; the last block of a file may be shorter
+	fastcpDedupBlockSize = 1024 * 1024
+	// fastcpBlockStoreDir holds the blocks a receiver has received, so later
+	// transfers into any destination can reuse them
+	fastcpBlockStoreDir = "~/.supershell/fastcp/blocks"
+)
+
+// fastcpBlockHash matches the hex SHA-256 a block is stored under
+var fastcpBlockHash = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// fastcpBlockKey is where a block is kept in the store, fanned out by its
+// first two characters
+func fastcpBlockKey(hash string) string {
+	return hash[:2] + "/" + hash
+}
+
+// fastcpBlockCount is how many blocks a file of size has
+func fastcpBlockCount(size int64) int {
+	return int((size + fastcpDedupBlockSize - 1) / fastcpDedupBlockSize)
+}
+
+// fastcpBlockLength is the size of block i of a file of size
+func fastcpBlockLength(size int64, i int) int64 {
+	if rest := size - int64(i)*fastcpDedupBlockSize; rest < fastcpDedupBlockSize {
+		return rest
+	}
+	return fastcpDedupBlockSize
+}
+
+// addFastcpBlocks makes header a deduplicated transfer, hashing each block of
+// every file
+func addFastcpBlocks(header *fastcpHeader, files []fastcpSourceFile) error {
+	header.Dedup = true
+	buffer := make([]byte, fastcpDedupBlockSize)
+	for i := range files {
+		if files[i].entry.Link != "" {
+			continue
+		}
+		blocks, err := fastcpFileBlocks(files[i].local, files[i].entry.Size, buffer)
+		if err != nil {
+			return err
+		}
+		files[i].entry.Blocks = blocks
+		header.Files[i].Blocks = blocks
+	}
+	return nil
+}
+
+// fastcpFileBlocks hashes the blocks of the first size bytes of a file
+func fastcpFileBlocks(path string, size int64, buffer []byte) ([]string, error) {
+	in, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+
+	blocks := make([]string, 0, fastcpBlockCount(size))
+	for i := 0; i < fastcpBlockCount(size); i++ {
+		block := buffer[:fastcpBlockLength(size, i)]
+		if _, err := io.ReadFull(in, block); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		sum := sha256.Sum256(block)
+		blocks = append(blocks, hex.EncodeToString(sum[:]))
+	}
+	return blocks, nil
+}
+
+// sendBlocks streams the blocks of one file the receiver neither holds nor
+// got earlier in the transfer, followed by the file's checksum trailer. sent
+// collects the blocks written and reused counts the ones left out.
+func (f *FastcpSendCommand) sendBlocks(writer *bufio.Writer, file fastcpSourceFile, have, sent map[string]bool, reused *fastcpReuse, progress *commands.ProgressReporter) (string, error) {
+	in, err := os.Open(file.local)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+
+	hasher := sha256.New()
+	buffer := make([]byte, fastcpDedupBlockSize)
+	for i, hash := range file.entry.Blocks {
+		block := buffer[:fastcpBlockLength(file.entry.Size, i)]
+		if _, err := io.ReadFull(in, block); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return "", fmt.Errorf("file shrank during transfer")
+			}
+			return "", err
+		}
+		hasher.Write(block)
+		// The receiver rebuilds the file from the announced hashes
+		if sum := sha256.Sum256(block); hex.EncodeToString(sum[:]) != hash {
+			return "", fmt.Errorf("file changed during transfer")
+		}
+		if have[hash] || sent[hash] {
+			reused.blocks++
+			reused.bytes += int64(len(block))
+			continue
+		}
+		if _, err := io.MultiWriter(writer, progress).Write(block); err != nil {
+			return "", err
+		}
+		sent[hash] = true
+	}
+
+	checksum := hex.EncodeToString(hasher.Sum(nil))
+	return checksum, writeFastcpMessage(writer, fastcpFileTrailer{Checksum: checksum})
+}
+
+// fastcpReuse counts the blocks a deduplicated transfer didn't send
+type fastcpReuse struct {
+	blocks int
+	bytes  int64
+}
+
+// plannedReuse counts the blocks of files the sender leaves out of a
+// deduplicated transfer because the receiver holds them or an earlier file
+// already carried them
+func plannedReuse(files []fastcpSourceFile, skip, have map[string]bool) fastcpReuse {
+	var reuse fastcpReuse
+	sent := make(map[string]bool)
+	for _, file := range files {
+		if skip[file.entry.Path] {
+			continue
+		}
+		for i, hash := range file.entry.Blocks {
+			if have[hash] || sent[hash] {
+				reuse.blocks++
+				reuse.bytes += fastcpBlockLength(file.entry.Size, i)
+			}
+			sent[hash] = true
+		}
+	}
+	return reuse
+}
+
+// fastcpBlockStore is a receiver's store of received blocks, keyed by hash
+type fastcpBlockStore struct {
+	store *dirStore
+}
+
+// openFastcpBlockStore returns the receiver's block store
+func openFastcpBlockStore() *fastcpBlockStore {
+	return &fastcpBlockStore{store: &dirStore{root: config.ExpandHome(fastcpBlockStoreDir)}}
+}
+
+// holds reports whether the store has a block of the given size
+func (s *fastcpBlockStore) holds(hash string, size int64) bool {
+	object, err := s.store.HeadObject(context.Background(), fastcpBlockKey(hash))
+	return err == nil && object.Size == size
+}
+
+// get reads a block into buffer, checking it still has its hash
+func (s *fastcpBlockStore) get(hash string, buffer []byte) error {
+	in, err := s.store.GetObject(context.Background(), fastcpBlockKey(hash))
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	if _, err := io.ReadFull(in, buffer); err != nil {
+		return fmt.Errorf("block %s is damaged in %s: %w", hash[:12], s.store.root, err)
+	}
+	if sum := sha256.Sum256(buffer); hex.EncodeToString(sum[:]) != hash {
+		return fmt.Errorf("block %s is damaged in %s", hash[:12], s.store.root)
+	}
+	return nil
+}
+
+// held lists the blocks of the files to be received that the store already
+// holds, each once
+func (s *fastcpBlockStore) held(files []fastcpFileEntry, skip map[int]string) []string {
+	var hashes []string
+	seen := make(map[string]bool)
+	for i, entry := range files {
+		if _, ok := skip[i]; ok {
+			continue
+		}
+		for j, hash := range entry.Blocks {
+			if !seen[hash] && s.holds(hash, fastcpBlockLength(entry.Size, j)) {
+				hashes = append(hashes, hash)
+			}
+			seen[hash] = true
+		}
+	}
+	return hashes
+}
+
+// put keeps a block for later transfers
+func (s *fastcpBlockStore) put(hash string, block []byte) error {
+	return s.store.PutObject(context.Background(), fastcpBlockKey(hash), bytes.NewReader(block))
+}
+
+// checkFastcpBlocks validates the block lists of a deduplicated transfer
+// before any of their hashes is used as a store key
+func checkFastcpBlocks(header *fastcpHeader) error {
+	for _, entry := range header.Files {
+		if entry.Link != "" {
+			continue
+		}
+		if len(entry.Blocks) != fastcpBlockCount(entry.Size) {
+			return fmt.Errorf("invalid block list for %s", entry.Path)
+		}
+		for _, hash := range entry.Blocks {
+			if !fastcpBlockHash.MatchString(hash) {
+				return fmt.Errorf("invalid block hash for %s", entry.Path)
+			}
+		}
+	}
+	return nil
+}
+
+// fastcpBlockReader reads the data of one file of a deduplicated transfer,
+// taking the blocks the sender left out from the store and keeping the ones
+// it sent. received holds the blocks it may take from the store and grows
+// with each block stored.
+type fastcpBlockReader struct {
+	wire     io.Reader
+	store    *fastcpBlockStore
+	entry    fastcpFileEntry
+	received map[string]bool
+	buffer   []byte
+	next     int
+	pending  []byte
+}
+
+// Read returns the file's data block by block
+func (r *fastcpBlockReader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		if r.next >= len(r.entry.Blocks) {
+			return 0, io.EOF
+		}
+		if err := r.load(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// load fetches the next block from the store or the connection
+func (r *fastcpBlockReader) load() error {
+	hash := r.entry.Blocks[r.next]
+	block := r.buffer[:fastcpBlockLength(r.entry.Size, r.next)]
+	r.next++
+	if r.received[hash] {
+		if err := r.store.get(hash, block); err != nil {
+			return err
+		}
+		r.pending = block
+		return nil
+	}
+
+	if _, err := io.ReadFull(r.wire, block); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	if sum := sha256.Sum256(block); hex.EncodeToString(sum[:]) != hash {
+		return fmt.Errorf("block %d does not match its hash", r.next-1)
+	}
+	if err := r.store.put(hash, block); err != nil {
+		return fmt.Errorf("cannot keep block: %w", err)
+	}
+	r.received[hash] = true
+	r.pending = block
+	return nil
+}
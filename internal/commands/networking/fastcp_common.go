@@ -0,0 +1,66 @@
+package networking
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/security"
+
+	"github.com/fatih/color"
+)
+
+// Flags shared by the fastcp commands
+var (
+	fastcpPortFlag      = commands.FlagSpec{Name: "port", Short: "p", Kind: commands.IntFlag, Default: strconv.Itoa(fastcpDefaultPort), Value: "port", Help: "TCP port"}
+	fastcpKeyCredFlag   = commands.FlagSpec{Name: "cred", Kind: commands.StringFlag, Value: "name", Help: "Use a stored key credential (implies encryption)"}
+	fastcpCloudCredFlag = commands.FlagSpec{Name: "cred", Kind: commands.StringFlag, Value: "name", Help: "Use a stored cloud credential"}
+	fastcpStatsFlag     = commands.FlagSpec{Name: "stats", Kind: commands.StringFlag, Value: "file", Help: "Write transfer statistics to file as JSON"}
+	fastcpBlockSizeFlag = commands.FlagSpec{Name: "block-size", Kind: commands.SizeFlag, Default: "64K", Value: "size", Help: "I/O buffer size, e.g. 256K or 1M"}
+)
+
+// fastcpMinBlockSize is the smallest --block-size accepted
+const fastcpMinBlockSize = 4 * 1024
+
+// fastcpBlockSize returns the --block-size given, which must lie between 4 KB
+// and the largest protocol message
+func fastcpBlockSize(flags *commands.ParsedFlags) (int, error) {
+	size := flags.Size("block-size")
+	if size < fastcpMinBlockSize || size > fastcpMaxMessageSize {
+		return 0, fmt.Errorf("--block-size must be between %s and %s",
+			commands.HumanizeBytes(fastcpMinBlockSize), commands.HumanizeBytes(fastcpMaxMessageSize))
+	}
+	return int(size), nil
+}
+
+// loadFastcpCredential resolves a --cred reference and checks it has the expected type
+func loadFastcpCredential(name, expectedType string) (*security.Credential, error) {
+	cred, err := security.LoadCredential(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if expectedType != "" && cred.Type != expectedType {
+		return nil, fmt.Errorf("credential '%s' is of type '%s', expected '%s'", name, cred.Type, expectedType)
+	}
+
+	return cred, nil
+}
+
+// fastcpCancelled finishes a transfer report when the command context is cancelled
+func fastcpCancelled(output *strings.Builder, startTime time.Time, err error) *commands.Result {
+	output.WriteString(color.New(color.FgYellow).Sprintf("\n⚠️  Transfer interrupted: %v\n", err))
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: 1,
+		Duration: time.Since(startTime),
+	}
+}
+
+// fastcpSourceFile pairs a local file with its entry in the transfer header
+type fastcpSourceFile struct {
+	local string
+	entry fastcpFileEntry
+}
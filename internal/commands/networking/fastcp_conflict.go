@@ -0,0 +1,258 @@
+package networking
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"suppercommand/internal/config"
+)
+
+// Conflict strategies for two-way transfers, applied when a file changed on
+// both ends since they last agreed on it
+const (
+	fastcpConflictNewer  = "newer"
+	fastcpConflictLarger = "larger"
+	fastcpConflictRename = "rename"
+	fastcpConflictSkip   = "skip"
+)
+
+// fastcpConflictStrategies are the values --conflict accepts
+var fastcpConflictStrategies = []string{fastcpConflictNewer, fastcpConflictLarger, fastcpConflictRename, fastcpConflictSkip}
+
+// What the receiver did with a file that changed on its side
+const (
+	// fastcpResolutionKept leaves the receiver's copy alone and nothing is sent
+	fastcpResolutionKept = "kept"
+	// fastcpResolutionReplaced overwrites it with the sender's copy
+	fastcpResolutionReplaced = "replaced"
+	// fastcpResolutionRenamed stores the sender's copy next to it
+	fastcpResolutionRenamed = "renamed"
+)
+
+// fastcpSyncStateDir holds what each synced tree last agreed on with its peers
+const fastcpSyncStateDir = "~/.supershell/sync"
+
+// fastcpConflict reports a file of a two-way transfer that changed on the
+// receiver since the last sync. Both is set when the sender changed it too,
+// which makes it a conflict rather than a change the other direction carries.
+type fastcpConflict struct {
+	Path       string `json:"path"`
+	Both       bool   `json:"both,omitempty"`
+	Resolution string `json:"resolution"`
+	// Copy is where a renamed sender copy was stored, relative to the destination
+	Copy string `json:"copy,omitempty"`
+}
+
+// describe explains a conflict for a transfer report
+func (c fastcpConflict) describe() string {
+	switch {
+	case !c.Both:
+		return fmt.Sprintf("%s changed only on the receiver, kept", c.Path)
+	case c.Resolution == fastcpResolutionRenamed:
+		return fmt.Sprintf("%s changed on both sides, sender's copy saved as %s", c.Path, c.Copy)
+	case c.Resolution == fastcpResolutionReplaced:
+		return fmt.Sprintf("%s changed on both sides, replaced with sender's copy", c.Path)
+	default:
+		return fmt.Sprintf("%s changed on both sides, receiver's copy kept", c.Path)
+	}
+}
+
+// status is the per-file status a conflict is reported with
+func (c fastcpConflict) status() string {
+	switch {
+	case !c.Both:
+		return "kept"
+	case c.Resolution == fastcpResolutionKept:
+		return "conflict"
+	default:
+		return c.Resolution
+	}
+}
+
+// fastcpSyncEntry is the last content both ends agreed on for one file
+type fastcpSyncEntry struct {
+	Checksum string `json:"checksum"`
+	ModTime  int64  `json:"mtime"`
+}
+
+// fastcpSyncState records, for one synced tree, the checksum and modification
+// time of every file as of its last two-way transfer. Both ends keep one, so
+// either can tell whether a file changed on its side since then.
+type fastcpSyncState struct {
+	Root      string                     `json:"root"`
+	UpdatedAt time.Time                  `json:"updated_at"`
+	Files     map[string]fastcpSyncEntry `json:"files"`
+}
+
+// fastcpSyncStateMu serializes updates, as concurrent receivers may share a tree
+var fastcpSyncStateMu sync.Mutex
+
+// fastcpSyncStatePath returns where the state of the tree at root lives. The
+// tree is the transferred directory itself on either end, so sending it back
+// the other way finds the same state.
+func fastcpSyncStatePath(root string) string {
+	if absolute, err := filepath.Abs(root); err == nil {
+		root = absolute
+	}
+	hash := fnv.New64a()
+	hash.Write([]byte(root))
+	return filepath.Join(config.ExpandHome(fastcpSyncStateDir), fmt.Sprintf("%s-%x.json", filepath.Base(root), hash.Sum64()))
+}
+
+// loadFastcpSyncState reads the state of the tree at root, empty if it was
+// never synced two-way
+func loadFastcpSyncState(root string) (*fastcpSyncState, error) {
+	state := &fastcpSyncState{Root: root, Files: make(map[string]fastcpSyncEntry)}
+	data, err := ioutil.ReadFile(fastcpSyncStatePath(root))
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("corrupt sync state for %s: %w", root, err)
+	}
+	if state.Files == nil {
+		state.Files = make(map[string]fastcpSyncEntry)
+	}
+	return state, nil
+}
+
+// recordFastcpSyncState stores the content both ends now agree on for the
+// given transfer paths of the tree at root
+func recordFastcpSyncState(root string, agreed map[string]fastcpSyncEntry) error {
+	if len(agreed) == 0 {
+		return nil
+	}
+	fastcpSyncStateMu.Lock()
+	defer fastcpSyncStateMu.Unlock()
+
+	state, err := loadFastcpSyncState(root)
+	if err != nil {
+		return err
+	}
+	for name, entry := range agreed {
+		state.Files[name] = entry
+	}
+	state.UpdatedAt = time.Now()
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	statePath := fastcpSyncStatePath(root)
+	if err := os.MkdirAll(filepath.Dir(statePath), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(statePath, data, 0600)
+}
+
+// fastcpStateRoot returns the local tree a transfer path belongs to: the
+// top-level file or directory it was sent under, inside dir
+func fastcpStateRoot(dir, transferPath string) string {
+	return filepath.Join(dir, strings.SplitN(transferPath, "/", 2)[0])
+}
+
+// validFastcpConflictStrategy reports whether strategy is one --conflict accepts
+func validFastcpConflictStrategy(strategy string) bool {
+	for _, known := range fastcpConflictStrategies {
+		if strategy == known {
+			return true
+		}
+	}
+	return false
+}
+
+// prepareFastcpTwoWay turns header into a two-way transfer resolving conflicts
+// with strategy. Every file carries the checksum it last had when both ends
+// agreed, and its modification time for the newer strategy.
+func prepareFastcpTwoWay(header *fastcpHeader, files []fastcpSourceFile, source, strategy string) error {
+	state, err := loadFastcpSyncState(source)
+	if err != nil {
+		return err
+	}
+	header.Delta = true
+	header.Conflict = strategy
+	for i := range header.Files {
+		entry := &header.Files[i]
+		if entry.Link != "" {
+			continue
+		}
+		entry.Base = state.Files[entry.Path].Checksum
+		if info, err := os.Stat(files[i].local); err == nil {
+			entry.ModTime = info.ModTime().UnixNano()
+		}
+	}
+	return nil
+}
+
+// recordFastcpSent remembers what the receiver now holds after a successful
+// two-way transfer from source: every file except those it kept its own copy
+// of or stored under another name
+func recordFastcpSent(source string, header *fastcpHeader, reply *fastcpReply) error {
+	differs := make(map[string]bool, len(reply.Conflicts))
+	for _, conflict := range reply.Conflicts {
+		if conflict.Resolution != fastcpResolutionReplaced {
+			differs[conflict.Path] = true
+		}
+	}
+	agreed := make(map[string]fastcpSyncEntry)
+	for _, entry := range header.Files {
+		if entry.Link == "" && entry.Checksum != "" && !differs[entry.Path] {
+			agreed[entry.Path] = fastcpSyncEntry{Checksum: entry.Checksum, ModTime: entry.ModTime}
+		}
+	}
+	return recordFastcpSyncState(source, agreed)
+}
+
+// resolveFastcpConflict decides what happens to a file of a two-way transfer
+// whose content differs from the receiver's existing copy. It returns nil when
+// the receiver's copy hasn't changed since the last sync, so the sender's
+// simply replaces it. A file never synced before counts as changed on both
+// ends.
+func resolveFastcpConflict(entry fastcpFileEntry, local os.FileInfo, localChecksum string, base fastcpSyncEntry, strategy string) *fastcpConflict {
+	if base.Checksum != "" && localChecksum == base.Checksum {
+		return nil
+	}
+	conflict := &fastcpConflict{Path: entry.Path, Resolution: fastcpResolutionKept}
+	if entry.Base != "" && entry.Checksum == entry.Base {
+		// Only the receiver changed it; sending it back the other way syncs it
+		return conflict
+	}
+
+	conflict.Both = true
+	switch strategy {
+	case fastcpConflictNewer:
+		if entry.ModTime > local.ModTime().UnixNano() {
+			conflict.Resolution = fastcpResolutionReplaced
+		}
+	case fastcpConflictLarger:
+		if entry.Size > local.Size() {
+			conflict.Resolution = fastcpResolutionReplaced
+		}
+	case fastcpConflictRename:
+		conflict.Resolution = fastcpResolutionRenamed
+		conflict.Copy = fastcpConflictCopy(entry.Path, entry.Checksum)
+	}
+	return conflict
+}
+
+// fastcpConflictCopy names the copy a renamed conflict is stored under, e.g.
+// notes.conflict-1a2b3c4d.txt, so the same sender version always lands in the
+// same place
+func fastcpConflictCopy(transferPath, checksum string) string {
+	ext := path.Ext(transferPath)
+	if len(checksum) > 8 {
+		checksum = checksum[:8]
+	}
+	return strings.TrimSuffix(transferPath, ext) + ".conflict-" + checksum + ext
+}
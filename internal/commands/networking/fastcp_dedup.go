@@ -15,51 +15,55 @@ import (
 // FastcpDedupCommand performs deduplication analysis and operations
 type FastcpDedupCommand struct {
 	*commands.BaseCommand
+	flags *commands.FlagSet
 }
 
 // NewFastcpDedupCommand creates a new fastcp-dedup command
 func NewFastcpDedupCommand() *FastcpDedupCommand {
+	usage := "fastcp-dedup <path> [analyze|clean] [--dry-run] [--threshold <size>]"
 	return &FastcpDedupCommand{
 		BaseCommand: commands.NewBaseCommand(
 			"fastcp-dedup",
 			"Deduplication analysis and statistics",
-			"fastcp-dedup <path> [analyze|clean] [--dry-run] [--threshold <size>]",
+			usage,
 			[]string{"windows", "linux", "darwin"},
 			false,
 		),
+		flags: commands.NewFlagSet("fastcp-dedup", usage,
+			commands.FlagSpec{Name: "dry-run", Help: "Report what clean would remove"},
+			commands.FlagSpec{Name: "threshold", Kind: commands.SizeFlag, Default: "1K", Value: "size", Help: "Ignore files smaller than size, e.g. 512K or 2M"},
+		),
 	}
 }
 
+// FlagSet returns the flags fastcp-dedup accepts
+func (f *FastcpDedupCommand) FlagSet() *commands.FlagSet {
+	return f.flags
+}
+
 // Execute performs deduplication analysis or cleanup
 func (f *FastcpDedupCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
 	startTime := time.Now()
 
-	if len(args.Raw) < 1 {
+	flags, usage := f.flags.ParseArguments(args, startTime)
+	if usage != nil {
+		return usage, nil
+	}
+	if len(flags.Args()) < 1 {
 		return &commands.Result{
-			Output:   "Usage: fastcp-dedup <path> [analyze|clean] [--dry-run] [--threshold <size>]\n",
+			Output:   "Usage: " + f.Usage() + "\n",
 			ExitCode: 1,
 			Duration: time.Since(startTime),
 		}, nil
 	}
 
-	// Parse arguments
-	path := args.Raw[0]
+	path := flags.Arg(0)
 	action := "analyze" // Default action
-	dryRun := false
-	threshold := int64(1024) // 1KB default threshold
-
-	for i, arg := range args.Raw[1:] {
-		switch arg {
-		case "analyze", "clean":
-			action = arg
-		case "--dry-run":
-			dryRun = true
-		case "--threshold":
-			if i+1 < len(args.Raw[1:]) {
-				fmt.Sscanf(args.Raw[1:][i+1], "%d", &threshold)
-			}
-		}
+	if flags.Arg(1) != "" {
+		action = flags.Arg(1)
 	}
+	dryRun := flags.Bool("dry-run")
+	threshold := flags.Size("threshold")
 
 	var output strings.Builder
 
@@ -69,21 +73,23 @@ func (f *FastcpDedupCommand) Execute(ctx context.Context, args *commands.Argumen
 	output.WriteString(fmt.Sprintf("🎯 Action:      %s\n", color.New(color.FgBlue).Sprint(action)))
 	output.WriteString(fmt.Sprintf("🧪 Dry run:     %s\n",
 		map[bool]string{true: color.New(color.FgYellow).Sprint("Enabled"), false: color.New(color.FgRed).Sprint("Disabled")}[dryRun]))
-	output.WriteString(fmt.Sprintf("📏 Threshold:   %s\n", formatBytes(threshold)))
+	output.WriteString(fmt.Sprintf("📏 Threshold:   %s\n", commands.HumanizeBytes(threshold)))
 	output.WriteString("───────────────────────────────────────────────────────────────\n")
 
 	// Initialize deduplication engine
 	output.WriteString("🔧 Initializing deduplication engine...\n")
-	time.Sleep(500 * time.Millisecond)
+	if err := commands.Sleep(ctx, 500*time.Millisecond); err != nil {
+		return fastcpCancelled(&output, startTime, err), nil
+	}
 	output.WriteString("✅ Engine ready\n")
 
 	output.WriteString("───────────────────────────────────────────────────────────────\n")
 
 	switch action {
 	case "analyze":
-		return f.analyzeDeduplication(path, threshold, startTime, &output)
+		return f.analyzeDeduplication(ctx, path, threshold, startTime, &output)
 	case "clean":
-		return f.performDeduplication(path, threshold, dryRun, startTime, &output)
+		return f.performDeduplication(ctx, path, threshold, dryRun, startTime, &output)
 	default:
 		output.WriteString("Error: Invalid action. Use 'analyze' or 'clean'\n")
 		return &commands.Result{
@@ -95,7 +101,7 @@ func (f *FastcpDedupCommand) Execute(ctx context.Context, args *commands.Argumen
 }
 
 // analyzeDeduplication performs deduplication analysis
-func (f *FastcpDedupCommand) analyzeDeduplication(path string, threshold int64, startTime time.Time, output *strings.Builder) (*commands.Result, error) {
+func (f *FastcpDedupCommand) analyzeDeduplication(ctx context.Context, path string, threshold int64, startTime time.Time, output *strings.Builder) (*commands.Result, error) {
 	output.WriteString(color.New(color.FgBlue, color.Bold).Sprint("🔍 ANALYZING DUPLICATES\n"))
 	output.WriteString("───────────────────────────────────────────────────────────────\n")
 
@@ -110,7 +116,9 @@ func (f *FastcpDedupCommand) analyzeDeduplication(path string, threshold int64,
 
 	for i, step := range scanSteps {
 		output.WriteString(fmt.Sprintf("⏳ %s\n", step))
-		time.Sleep(time.Duration(500+rand.Intn(1000)) * time.Millisecond)
+		if err := commands.Sleep(ctx, time.Duration(500+rand.Intn(1000))*time.Millisecond); err != nil {
+			return fastcpCancelled(output, startTime, err), nil
+		}
 		if i < len(scanSteps)-1 {
 			output.WriteString("✅ Complete\n")
 		}
@@ -143,14 +151,14 @@ func (f *FastcpDedupCommand) analyzeDeduplication(path string, threshold int64,
 	output.WriteString(color.New(color.FgGreen, color.Bold).Sprint("📊 DEDUPLICATION ANALYSIS\n"))
 	output.WriteString("───────────────────────────────────────────────────────────────\n")
 	output.WriteString(fmt.Sprintf("📁 Total files:      %d\n", analysisResults.totalFiles))
-	output.WriteString(fmt.Sprintf("📏 Total size:       %s\n", formatBytes(analysisResults.totalSize)))
+	output.WriteString(fmt.Sprintf("📏 Total size:       %s\n", commands.HumanizeBytes(analysisResults.totalSize)))
 	output.WriteString(fmt.Sprintf("✨ Unique files:     %d\n", analysisResults.uniqueFiles))
 	output.WriteString("───────────────────────────────────────────────────────────────\n")
 	output.WriteString(fmt.Sprintf("🔄 Duplicate groups: %d\n", analysisResults.duplicateGroups))
 	output.WriteString(fmt.Sprintf("📄 Duplicate files:  %d\n", analysisResults.duplicateFiles))
-	output.WriteString(fmt.Sprintf("💾 Duplicate size:   %s\n", color.New(color.FgRed, color.Bold).Sprint(formatBytes(analysisResults.duplicateSize))))
-	output.WriteString(fmt.Sprintf("📈 Largest dupe:     %s\n", formatBytes(analysisResults.largestDupe)))
-	output.WriteString(fmt.Sprintf("📊 Average dupe:     %s\n", formatBytes(analysisResults.avgDupeSize)))
+	output.WriteString(fmt.Sprintf("💾 Duplicate size:   %s\n", color.New(color.FgRed, color.Bold).Sprint(commands.HumanizeBytes(analysisResults.duplicateSize))))
+	output.WriteString(fmt.Sprintf("📈 Largest dupe:     %s\n", commands.HumanizeBytes(analysisResults.largestDupe)))
+	output.WriteString(fmt.Sprintf("📊 Average dupe:     %s\n", commands.HumanizeBytes(analysisResults.avgDupeSize)))
 
 	// Calculate savings potential
 	savingsPercent := float64(analysisResults.duplicateSize) / float64(analysisResults.totalSize) * 100
@@ -158,7 +166,7 @@ func (f *FastcpDedupCommand) analyzeDeduplication(path string, threshold int64,
 	output.WriteString(color.New(color.FgYellow, color.Bold).Sprint("💰 SAVINGS POTENTIAL\n"))
 	output.WriteString("───────────────────────────────────────────────────────────────\n")
 	output.WriteString(fmt.Sprintf("💾 Space savings:    %s (%.1f%%)\n",
-		color.New(color.FgGreen, color.Bold).Sprint(formatBytes(analysisResults.duplicateSize)), savingsPercent))
+		color.New(color.FgGreen, color.Bold).Sprint(commands.HumanizeBytes(analysisResults.duplicateSize)), savingsPercent))
 	output.WriteString(fmt.Sprintf("📁 Files to remove:  %d\n", analysisResults.duplicateFiles))
 
 	// Top duplicate file types
@@ -188,7 +196,7 @@ func (f *FastcpDedupCommand) analyzeDeduplication(path string, threshold int64,
 		output.WriteString(fmt.Sprintf("%-10s %-8d %s\n",
 			color.New(color.FgYellow).Sprint(dupeType.extension),
 			dupeType.count,
-			color.New(color.FgGreen).Sprint(formatBytes(dupeType.size))))
+			color.New(color.FgGreen).Sprint(commands.HumanizeBytes(dupeType.size))))
 	}
 
 	output.WriteString("───────────────────────────────────────────────────────────────\n")
@@ -204,7 +212,7 @@ func (f *FastcpDedupCommand) analyzeDeduplication(path string, threshold int64,
 }
 
 // performDeduplication performs actual deduplication cleanup
-func (f *FastcpDedupCommand) performDeduplication(path string, threshold int64, dryRun bool, startTime time.Time, output *strings.Builder) (*commands.Result, error) {
+func (f *FastcpDedupCommand) performDeduplication(ctx context.Context, path string, threshold int64, dryRun bool, startTime time.Time, output *strings.Builder) (*commands.Result, error) {
 	actionText := "CLEANING DUPLICATES"
 	if dryRun {
 		actionText = "SIMULATING CLEANUP (DRY RUN)"
@@ -240,9 +248,11 @@ func (f *FastcpDedupCommand) performDeduplication(path string, threshold int64,
 
 		progressBar := f.createProgressBar(progress, 50)
 		output.WriteString(fmt.Sprintf("\r🧹 %s %d%% (Groups: %d, Files: %d, Freed: %s)",
-			progressBar, progress, processed, removed, formatBytes(freed)))
+			progressBar, progress, processed, removed, commands.HumanizeBytes(freed)))
 
-		time.Sleep(150 * time.Millisecond)
+		if err := commands.Sleep(ctx, 150*time.Millisecond); err != nil {
+			return fastcpCancelled(output, startTime, err), nil
+		}
 	}
 	output.WriteString("\n")
 
@@ -261,10 +271,10 @@ func (f *FastcpDedupCommand) performDeduplication(path string, threshold int64,
 
 	if dryRun {
 		output.WriteString(fmt.Sprintf("📄 Files to remove:  %d\n", cleanupResults.filesRemoved))
-		output.WriteString(fmt.Sprintf("💾 Space to free:    %s\n", color.New(color.FgGreen, color.Bold).Sprint(formatBytes(cleanupResults.spaceFreed))))
+		output.WriteString(fmt.Sprintf("💾 Space to free:    %s\n", color.New(color.FgGreen, color.Bold).Sprint(commands.HumanizeBytes(cleanupResults.spaceFreed))))
 	} else {
 		output.WriteString(fmt.Sprintf("📄 Files removed:    %d\n", cleanupResults.filesRemoved))
-		output.WriteString(fmt.Sprintf("💾 Space freed:      %s\n", color.New(color.FgGreen, color.Bold).Sprint(formatBytes(cleanupResults.spaceFreed))))
+		output.WriteString(fmt.Sprintf("💾 Space freed:      %s\n", color.New(color.FgGreen, color.Bold).Sprint(commands.HumanizeBytes(cleanupResults.spaceFreed))))
 	}
 
 	output.WriteString(fmt.Sprintf("⏱️  Duration:        %v\n", cleanupDuration.Round(time.Millisecond)))
@@ -0,0 +1,198 @@
+package networking
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"strings"
+
+	"suppercommand/internal/config"
+)
+
+// fastcpPattern is one .gitignore-style pattern
+type fastcpPattern struct {
+	segments []string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// fastcpFilter selects which files under a directory source are sent. Patterns are
+// matched against paths relative to the source root, using forward slashes.
+type fastcpFilter struct {
+	includes []fastcpPattern
+	excludes []fastcpPattern
+
+	excludedFiles int
+	excludedDirs  int
+}
+
+// newFastcpFilter builds a filter from --include and --exclude globs and the
+// .gitignore-style files given with --exclude-from
+func newFastcpFilter(includes, excludes, excludeFrom []string) (*fastcpFilter, error) {
+	filter := &fastcpFilter{}
+
+	for _, glob := range includes {
+		if pattern, ok := parseFastcpPattern(glob); ok {
+			filter.includes = append(filter.includes, pattern)
+		}
+	}
+
+	for _, name := range excludeFrom {
+		patterns, err := readFastcpIgnoreFile(name)
+		if err != nil {
+			return nil, err
+		}
+		filter.excludes = append(filter.excludes, patterns...)
+	}
+
+	for _, glob := range excludes {
+		if pattern, ok := parseFastcpPattern(glob); ok {
+			filter.excludes = append(filter.excludes, pattern)
+		}
+	}
+
+	return filter, nil
+}
+
+// readFastcpIgnoreFile reads the patterns of a .gitignore-style file
+func readFastcpIgnoreFile(name string) ([]fastcpPattern, error) {
+	file, err := os.Open(config.ExpandHome(name))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var patterns []fastcpPattern
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if pattern, ok := parseFastcpPattern(line); ok {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns, scanner.Err()
+}
+
+// parseFastcpPattern parses a pattern: a leading "!" re-includes, a trailing "/" only
+// matches directories, and a pattern containing "/" is anchored to the source root
+// while one without matches at any depth
+func parseFastcpPattern(text string) (fastcpPattern, bool) {
+	var pattern fastcpPattern
+
+	text = strings.Replace(text, `\`, "/", -1)
+	if strings.HasPrefix(text, "!") {
+		pattern.negate = true
+		text = text[1:]
+	}
+	if strings.HasSuffix(text, "/") {
+		pattern.dirOnly = true
+		text = strings.TrimRight(text, "/")
+	}
+	if strings.Contains(text, "/") {
+		pattern.anchored = true
+		text = strings.TrimLeft(text, "/")
+	}
+	if text == "" {
+		return pattern, false
+	}
+
+	pattern.segments = strings.Split(text, "/")
+	return pattern, true
+}
+
+// matches reports whether the pattern matches a relative slash-separated path
+func (p fastcpPattern) matches(rel string, isDir bool) bool {
+	if p.dirOnly && !isDir {
+		return false
+	}
+
+	segments := strings.Split(rel, "/")
+	if p.anchored {
+		return matchFastcpSegments(p.segments, segments)
+	}
+
+	// An unanchored pattern may match the path starting at any directory
+	for start := range segments {
+		if matchFastcpSegments(p.segments, segments[start:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchFastcpSegments matches path segments against pattern segments, where "**"
+// stands for any number of directories
+func matchFastcpSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+
+	if pattern[0] == "**" {
+		for skip := 0; skip <= len(segments); skip++ {
+			if matchFastcpSegments(pattern[1:], segments[skip:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(segments) == 0 {
+		return false
+	}
+	if ok, err := path.Match(pattern[0], segments[0]); err != nil || !ok {
+		return false
+	}
+	return matchFastcpSegments(pattern[1:], segments[1:])
+}
+
+// excluded reports whether rel is excluded; as in .gitignore the last matching
+// pattern wins, so a later "!" pattern can bring a path back
+func (f *fastcpFilter) excluded(rel string, isDir bool) bool {
+	excluded := false
+	for _, pattern := range f.excludes {
+		if pattern.matches(rel, isDir) {
+			excluded = !pattern.negate
+		}
+	}
+	return excluded
+}
+
+// included reports whether a file passes the --include globs, if any were given
+func (f *fastcpFilter) included(rel string) bool {
+	if len(f.includes) == 0 {
+		return true
+	}
+	for _, pattern := range f.includes {
+		if pattern.matches(rel, false) {
+			return true
+		}
+	}
+	return false
+}
+
+// selectDir reports whether the walk should descend into a directory
+func (f *fastcpFilter) selectDir(rel string) bool {
+	if f == nil || !f.excluded(rel, true) {
+		return true
+	}
+	f.excludedDirs++
+	return false
+}
+
+// selectFile reports whether a file should be sent
+func (f *fastcpFilter) selectFile(rel string) bool {
+	if f == nil || (f.included(rel) && !f.excluded(rel, false)) {
+		return true
+	}
+	f.excludedFiles++
+	return false
+}
+
+// active reports whether any patterns were given
+func (f *fastcpFilter) active() bool {
+	return f != nil && (len(f.includes) > 0 || len(f.excludes) > 0)
+}
@@ -0,0 +1,241 @@
+package networking
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/security"
+
+	"github.com/fatih/color"
+)
+
+const (
+	// fastcpKeyAlphabet leaves out characters that are easily misread, such as 0/O and 1/l/I
+	fastcpKeyAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz23456789"
+	// The minimums keep every generated key above 60 bits
+	fastcpKeyMinLength = 16
+	fastcpKeyMaxLength = 128
+	fastcpKeyMinWords  = 6
+	fastcpKeyMaxWords  = 24
+	// fastcpKeyStrongBits is the strength below which fastcp-key suggests a longer key
+	fastcpKeyStrongBits = 80
+)
+
+// FastcpKeyCommand generates strong random transfer keys, optionally saving them
+// in the credential store for fastcp-send and fastcp-recv --cred
+type FastcpKeyCommand struct {
+	*commands.BaseCommand
+	flags *commands.FlagSet
+}
+
+// NewFastcpKeyCommand creates a new fastcp-key command
+func NewFastcpKeyCommand() *FastcpKeyCommand {
+	usage := "fastcp-key [--length <n> | --words <n> [--separator <text>]] [--save <name> [--force]] [--qr] [--quiet] [--output-format text|json]"
+	return &FastcpKeyCommand{
+		BaseCommand: commands.NewBaseCommand(
+			"fastcp-key",
+			"Generate a strong random transfer key for fastcp",
+			usage,
+			[]string{"windows", "linux", "darwin"},
+			false,
+		),
+		flags: commands.NewFlagSet("fastcp-key", usage,
+			commands.FlagSpec{Name: "length", Short: "l", Kind: commands.IntFlag, Default: "32", Value: "n", Help: "Characters in a random key"},
+			commands.FlagSpec{Name: "words", Short: "w", Kind: commands.IntFlag, Value: "n", Help: "Make a passphrase of n words instead"},
+			commands.FlagSpec{Name: "separator", Kind: commands.StringFlag, Default: "-", Value: "text", Help: "What joins the words of a passphrase"},
+			commands.FlagSpec{Name: "save", Kind: commands.StringFlag, Value: "name", Help: "Store the key as a key credential for --cred"},
+			commands.FlagSpec{Name: "force", Help: "Replace an existing credential with --save"},
+			commands.FlagSpec{Name: "qr", Help: "Also show the key as a QR code"},
+		),
+	}
+}
+
+// FlagSet returns the flags fastcp-key accepts
+func (f *FastcpKeyCommand) FlagSet() *commands.FlagSet {
+	return f.flags
+}
+
+// OutputFormats returns the structured formats fastcp-key supports
+func (f *FastcpKeyCommand) OutputFormats() []commands.OutputFormat {
+	return []commands.OutputFormat{commands.FormatJSON}
+}
+
+// fastcpKey is a generated key as printed by --output-format json
+type fastcpKey struct {
+	Key   string `json:"key"`
+	Kind  string `json:"kind"`
+	Bits  int    `json:"bits"`
+	Saved string `json:"saved_as,omitempty"`
+}
+
+// Execute generates a key, saves it when asked, and prints it
+func (f *FastcpKeyCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	startTime := time.Now()
+
+	flags, usage := f.flags.ParseArguments(args, startTime)
+	if usage != nil {
+		return usage, nil
+	}
+
+	var err error
+	length, words := flags.Int("length"), flags.Int("words")
+	switch {
+	case len(flags.Args()) > 0:
+		err = fmt.Errorf("unexpected argument '%s'", flags.Arg(0))
+	case flags.Changed("words") && flags.Changed("length"):
+		err = fmt.Errorf("--length and --words cannot be combined")
+	case flags.Changed("words") && (words < fastcpKeyMinWords || words > fastcpKeyMaxWords):
+		err = fmt.Errorf("--words must be between %d and %d", fastcpKeyMinWords, fastcpKeyMaxWords)
+	case !flags.Changed("words") && (length < fastcpKeyMinLength || length > fastcpKeyMaxLength):
+		err = fmt.Errorf("--length must be between %d and %d", fastcpKeyMinLength, fastcpKeyMaxLength)
+	case flags.Bool("force") && flags.String("save") == "":
+		err = fmt.Errorf("--force only applies to --save")
+	}
+	if err != nil {
+		return &commands.Result{
+			Output:   "Usage: " + f.Usage() + "\n",
+			Error:    commands.UsageError(f.Name(), "%v", err),
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}, nil
+	}
+
+	var key fastcpKey
+	if flags.Changed("words") {
+		key, err = generatePassphrase(words, flags.String("separator"))
+	} else {
+		key, err = generateKey(length)
+	}
+	if err != nil {
+		return commands.ErrorResult(color.New(color.FgRed).Sprintf("❌ Failed to generate a key: %v\n", err), err, startTime), nil
+	}
+
+	// Render the code before saving so a key too long to show isn't stored unseen
+	var qr string
+	if flags.Bool("qr") {
+		if qr, err = commands.RenderQR(key.Key); err != nil {
+			return commands.ErrorResult(color.New(color.FgRed).Sprintf("❌ %v; use a shorter key\n", err), err, startTime), nil
+		}
+	}
+
+	if name := flags.String("save"); name != "" {
+		if err := saveFastcpKey(name, key.Key, flags.Bool("force")); err != nil {
+			return commands.ErrorResult(color.New(color.FgRed).Sprintf("❌ Failed to save the key: %v\n", err), err, startTime), nil
+		}
+		key.Saved = name
+	}
+
+	options := commands.OutputOptionsFrom(ctx)
+	if options.Structured() {
+		result, err := commands.JSONResult(key, startTime)
+		if err != nil {
+			return commands.ErrorResult("", err, startTime), nil
+		}
+		return result, nil
+	}
+	if options.Quiet {
+		return &commands.Result{
+			Output:   key.Key + "\n" + qr,
+			ExitCode: 0,
+			Duration: time.Since(startTime),
+		}, nil
+	}
+
+	var output strings.Builder
+	output.WriteString(color.New(color.FgCyan, color.Bold).Sprint("🔑 FASTCP TRANSFER KEY\n"))
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+	output.WriteString("  " + color.New(color.FgYellow, color.Bold).Sprint(key.Key) + "\n")
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+	if flags.Changed("words") {
+		output.WriteString(fmt.Sprintf("📏 %d words · %d bits of entropy\n", words, key.Bits))
+	} else {
+		output.WriteString(fmt.Sprintf("📏 %d characters · %d bits of entropy\n", length, key.Bits))
+	}
+	if key.Bits < fastcpKeyStrongBits {
+		output.WriteString(color.New(color.FgYellow).Sprintf("⚠️  Under %d bits; prefer more words for keys that protect sensitive data\n", fastcpKeyStrongBits))
+	}
+	if key.Saved != "" {
+		output.WriteString(color.New(color.FgGreen).Sprintf("💾 Saved as credential '%s'\n", key.Saved))
+		output.WriteString(fmt.Sprintf("💡 Use --cred %s with fastcp-send and fastcp-recv on both machines\n", key.Saved))
+	} else {
+		output.WriteString("💡 Store it with --save <name>, or cred add <name>, and pass --cred <name> instead of typing it\n")
+	}
+	output.WriteString("💡 Share the key over a different channel from the files it protects\n")
+	if qr != "" {
+		output.WriteString("\n" + qr)
+		output.WriteString(color.New(color.FgHiBlack).Sprint("📱 Scan to copy the key to another device\n"))
+	}
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}, nil
+}
+
+// generateKey returns length characters drawn at random from fastcpKeyAlphabet
+func generateKey(length int) (fastcpKey, error) {
+	var key strings.Builder
+	for i := 0; i < length; i++ {
+		n, err := randomIndex(len(fastcpKeyAlphabet))
+		if err != nil {
+			return fastcpKey{}, err
+		}
+		key.WriteByte(fastcpKeyAlphabet[n])
+	}
+	return fastcpKey{Key: key.String(), Kind: "characters", Bits: entropyBits(length, len(fastcpKeyAlphabet))}, nil
+}
+
+// generatePassphrase returns words drawn at random from fastcpWords, diceware style
+func generatePassphrase(words int, separator string) (fastcpKey, error) {
+	chosen := make([]string, words)
+	for i := range chosen {
+		n, err := randomIndex(len(fastcpWords))
+		if err != nil {
+			return fastcpKey{}, err
+		}
+		chosen[i] = fastcpWords[n]
+	}
+	return fastcpKey{Key: strings.Join(chosen, separator), Kind: "words", Bits: entropyBits(words, len(fastcpWords))}, nil
+}
+
+// randomIndex returns a uniformly random index below n from the system's secure source
+func randomIndex(n int) (int, error) {
+	index, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+	return int(index.Int64()), nil
+}
+
+// entropyBits is the strength of count symbols each picked from choices, rounded down
+func entropyBits(count, choices int) int {
+	return int(float64(count) * math.Log2(float64(choices)))
+}
+
+// saveFastcpKey stores a key credential, refusing to replace one unless force is set
+func saveFastcpKey(name, key string, force bool) error {
+	password, err := security.MasterPassword()
+	if err != nil {
+		return err
+	}
+	store, err := security.OpenCredentialStore(security.DefaultCredentialStorePath(), password)
+	if err != nil {
+		return err
+	}
+	if !force {
+		for _, info := range store.List() {
+			if info.Name == name {
+				return fmt.Errorf("credential '%s' already exists; add --force to replace it", name)
+			}
+		}
+	}
+	return store.Add(&security.Credential{Name: name, Type: security.CredentialTypeKey, Fields: map[string]string{"secret": key}})
+}
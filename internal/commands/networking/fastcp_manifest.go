@@ -0,0 +1,144 @@
+package networking
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"suppercommand/internal/config"
+)
+
+const (
+	// fastcpManifestDir holds one backup manifest per source and bucket
+	fastcpManifestDir = "~/.supershell/backups"
+	// fastcpManifestObject is the key of the manifest copy stored with a backup
+	fastcpManifestObject = "manifest.json"
+)
+
+// fastcpManifestEntry records the upload state of one object
+type fastcpManifestEntry struct {
+	Size     int64  `json:"size"`
+	Checksum string `json:"checksum,omitempty"`
+	Uploaded bool   `json:"uploaded"`
+	Attempts int    `json:"attempts"`
+	Error    string `json:"error,omitempty"`
+	// BackupID is set when the object was carried over from an earlier backup
+	BackupID string `json:"backup_id,omitempty"`
+}
+
+// fastcpBackupManifest records which objects of a backup reached the bucket, so a
+// backup that was interrupted or had failures can be resumed by running it again
+type fastcpBackupManifest struct {
+	path      string
+	BackupID  string                          `json:"backup_id"`
+	Bucket    string                          `json:"bucket"`
+	Source    string                          `json:"source"`
+	Complete  bool                            `json:"complete"`
+	UpdatedAt time.Time                       `json:"updated_at"`
+	Objects   map[string]*fastcpManifestEntry `json:"objects"`
+}
+
+// fastcpManifestPath returns where the manifest for backing up source to bucket lives
+func fastcpManifestPath(bucket, source string) string {
+	if absolute, err := filepath.Abs(source); err == nil {
+		source = absolute
+	}
+	hash := fnv.New64a()
+	hash.Write([]byte(bucket + "\x00" + source))
+	return filepath.Join(config.ExpandHome(fastcpManifestDir), fmt.Sprintf("%s-%x.json", fastcpBucketFileName(bucket), hash.Sum64()))
+}
+
+// newFastcpBackupManifest starts an empty manifest for a new backup
+func newFastcpBackupManifest(bucket, source, backupID string) *fastcpBackupManifest {
+	return &fastcpBackupManifest{
+		path:     fastcpManifestPath(bucket, source),
+		BackupID: backupID,
+		Bucket:   bucket,
+		Source:   source,
+		Objects:  make(map[string]*fastcpManifestEntry),
+	}
+}
+
+// loadFastcpBackupManifest reads the manifest of the last backup of source to bucket,
+// returning nil when there is none
+func loadFastcpBackupManifest(bucket, source string) (*fastcpBackupManifest, error) {
+	path := fastcpManifestPath(bucket, source)
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest fastcpBackupManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("corrupt backup manifest %s: %w", path, err)
+	}
+	manifest.path = path
+	if manifest.Objects == nil {
+		manifest.Objects = make(map[string]*fastcpManifestEntry)
+	}
+	return &manifest, nil
+}
+
+// save writes the manifest to disk
+func (m *fastcpBackupManifest) save() error {
+	m.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(m.path), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(m.path, data, 0600)
+}
+
+// upload stores a copy of the manifest next to the backup's objects
+func (m *fastcpBackupManifest) upload(ctx context.Context, store cloudStore) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return store.PutObject(ctx, m.BackupID+"/"+fastcpManifestObject, bytes.NewReader(data))
+}
+
+// objectKey returns the key an uploaded object is stored under
+func (m *fastcpBackupManifest) objectKey(name string) string {
+	backupID := m.BackupID
+	if entry, ok := m.Objects[name]; ok && entry.BackupID != "" {
+		backupID = entry.BackupID
+	}
+	return backupID + "/" + name
+}
+
+// uploaded reports whether name was uploaded with the given size and content
+func (m *fastcpBackupManifest) uploaded(name string, size int64, checksum string) bool {
+	entry, ok := m.Objects[name]
+	return ok && entry.Uploaded && entry.Size == size && entry.Checksum == checksum
+}
+
+// fastcpFileChecksum returns the SHA-256 of a local file
+func fastcpFileChecksum(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
@@ -0,0 +1,184 @@
+package networking
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"suppercommand/internal/commands"
+)
+
+// FastCP wire protocol
+//
+// The sender opens a TCP connection and writes a JSON header line describing every
+// file. The receiver answers with a JSON reply line accepting or rejecting the
+// transfer. Each file's raw bytes follow, each one trailed by a JSON checksum line,
+// and the receiver finishes with a JSON reply summarizing what it stored. Symlinks
+// are entries with a link target and no data, so they keep the same framing.
+//
+// A delta transfer carries each file's checksum in the header. The receiver lists
+// the paths it already holds with identical content in its accept reply, and the
+// sender leaves those out of the data that follows, trailer included.
+//
+// A two-way transfer is a delta transfer naming a conflict strategy. Each file
+// also carries the checksum both ends last agreed on and its modification time.
+// The receiver compares them with its own sync state and answers with the files
+// that changed on its side; the ones it keeps are left out like unchanged files.
+//
+// An archive transfer sends the data of every file as a single tar stream,
+// gzipped when the transfer is compressed, instead of one framed file after
+// another. The tar headers carry each file's permissions and modification time,
+// and one JSON line after the stream holds the checksums of all files. It never
+// is a delta transfer.
+//
+// A deduplicated transfer lists the SHA-256 of each fastcpDedupBlockSize block of
+// every file. The receiver keeps the blocks it has received in a store keyed by
+// their hash and names the ones it holds in its accept reply. For each file the
+// sender then writes only the blocks that are neither held nor already written
+// earlier in the transfer, and the receiver reassembles the rest from its store.
+const (
+	fastcpProtocolVersion  = 1
+	fastcpDefaultPort      = 8888
+	fastcpDefaultBlockSize = 64 * 1024
+	fastcpMaxMessageSize   = 16 * 1024 * 1024
+)
+
+// fastcpFileEntry describes one file in a transfer; Link is set for a symlink,
+// Checksum for a file in a delta transfer, Base and ModTime (in Unix
+// nanoseconds) for a file in a two-way transfer, and Blocks for a file in a
+// deduplicated transfer
+type fastcpFileEntry struct {
+	Path     string `json:"path"`
+	Size     int64  `json:"size"`
+	Link     string `json:"link,omitempty"`
+	Checksum string `json:"checksum,omitempty"`
+	Base     string `json:"base,omitempty"`
+	ModTime  int64  `json:"mtime,omitempty"`
+	// Blocks holds the hash of each block of a file in a deduplicated transfer
+	Blocks []string `json:"blocks,omitempty"`
+}
+
+// fastcpHeader is sent by the sender before any file data
+type fastcpHeader struct {
+	Version    int               `json:"version"`
+	TransferID string            `json:"transfer_id"`
+	Files      []fastcpFileEntry `json:"files"`
+	TotalSize  int64             `json:"total_size"`
+	Encrypted  bool              `json:"encrypted"`
+	Compressed bool              `json:"compressed"`
+	Delta      bool              `json:"delta,omitempty"`
+	// Conflict names the strategy of a two-way transfer
+	Conflict string `json:"conflict,omitempty"`
+	// Archive sends the file data as one tar stream
+	Archive bool `json:"archive,omitempty"`
+	// Dedup leaves out the blocks the receiver already holds
+	Dedup bool `json:"dedup,omitempty"`
+}
+
+// fastcpReply is sent by the receiver to accept a transfer and again when it completes
+type fastcpReply struct {
+	Accepted bool   `json:"accepted"`
+	Error    string `json:"error,omitempty"`
+	Files    int    `json:"files,omitempty"`
+	Bytes    int64  `json:"bytes,omitempty"`
+	// Have lists the paths of a delta transfer the receiver already holds
+	Have    []string `json:"have,omitempty"`
+	Skipped int      `json:"skipped,omitempty"`
+	// Conflict echoes a two-way transfer's strategy, so the sender knows the
+	// receiver checked for conflicts rather than ignoring the request
+	Conflict  string           `json:"conflict,omitempty"`
+	Conflicts []fastcpConflict `json:"conflicts,omitempty"`
+	// Archive echoes an archive transfer, so the sender knows the receiver
+	// will read a tar stream rather than framed files
+	Archive bool `json:"archive,omitempty"`
+	// Dedup echoes a deduplicated transfer, and HaveBlocks lists the hashes of
+	// the blocks the receiver holds
+	Dedup      bool     `json:"dedup,omitempty"`
+	HaveBlocks []string `json:"have_blocks,omitempty"`
+}
+
+// fastcpFileTrailer follows the data of each file
+type fastcpFileTrailer struct {
+	Checksum string `json:"checksum"`
+}
+
+// fastcpArchiveTrailer follows the tar stream of an archive transfer with the
+// checksum of every file by transfer path
+type fastcpArchiveTrailer struct {
+	Checksums map[string]string `json:"checksums"`
+}
+
+// writeFastcpMessage writes a single JSON message line
+func writeFastcpMessage(w io.Writer, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// readFastcpMessage reads a single JSON message line
+func readFastcpMessage(r *bufio.Reader, v interface{}) error {
+	var line []byte
+	for {
+		chunk, err := r.ReadSlice('\n')
+		line = append(line, chunk...)
+		if len(line) > fastcpMaxMessageSize {
+			return fmt.Errorf("protocol message exceeds %s", commands.HumanizeBytes(fastcpMaxMessageSize))
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		break
+	}
+	return json.Unmarshal(line, v)
+}
+
+// newTransferID returns a random identifier for a transfer
+func newTransferID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%x", buf)
+	}
+	return hex.EncodeToString(buf)
+}
+
+// safeJoin resolves a transfer path inside root, rejecting absolute paths and traversal
+func safeJoin(root, transferPath string) (string, error) {
+	if transferPath == "" || strings.Contains(transferPath, "\\") || path.IsAbs(transferPath) {
+		return "", fmt.Errorf("invalid path in transfer: %q", transferPath)
+	}
+
+	cleaned := path.Clean(transferPath)
+	if cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") || filepath.VolumeName(cleaned) != "" {
+		return "", fmt.Errorf("path escapes destination: %q", transferPath)
+	}
+
+	return filepath.Join(root, filepath.FromSlash(cleaned)), nil
+}
+
+// closeOnCancel closes conn when ctx is cancelled so blocked reads and writes return.
+// The returned function must be called once the connection is no longer in use.
+func closeOnCancel(ctx context.Context, conn net.Conn) func() {
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+	return func() { close(done) }
+}
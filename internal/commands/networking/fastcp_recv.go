@@ -3,10 +3,13 @@ package networking
 import (
 	"context"
 	"fmt"
+	"net"
 	"strings"
+	"sync"
 	"time"
 
 	"suppercommand/internal/commands"
+	"suppercommand/internal/security"
 
 	"github.com/fatih/color"
 )
@@ -14,46 +17,107 @@ import (
 // FastcpRecvCommand receives files via ultra-fast encrypted transfer
 type FastcpRecvCommand struct {
 	*commands.BaseCommand
+	flags *commands.FlagSet
 }
 
 // NewFastcpRecvCommand creates a new fastcp-recv command
 func NewFastcpRecvCommand() *FastcpRecvCommand {
+	usage := "fastcp-recv [destination] [-p <port>] [-e] [--auto-accept] [--serve [--max-conns <n>]] [--block-size <size>] [--dst-structure preserve|flatten] [--cred <name>] [--stats <file>] [--quiet] [--output-format text|json|csv]"
 	return &FastcpRecvCommand{
 		BaseCommand: commands.NewBaseCommand(
 			"fastcp-recv",
 			"Ultra-fast encrypted file/directory transfer (receiver)",
-			"fastcp-recv [destination] [-p <port>] [-e] [--auto-accept]",
+			usage,
 			[]string{"windows", "linux", "darwin"},
 			false,
 		),
+		flags: commands.NewFlagSet("fastcp-recv", usage,
+			fastcpPortFlag,
+			commands.FlagSpec{Name: "encrypt", Short: "e", Help: "Require an encrypted transfer"},
+			commands.FlagSpec{Name: "auto-accept", Help: "Accept transfers without prompting"},
+			commands.FlagSpec{Name: "serve", Help: "Keep accepting senders until interrupted"},
+			commands.FlagSpec{Name: "max-conns", Kind: commands.IntFlag, Default: "4", Value: "n", Help: "Concurrent senders with --serve"},
+			fastcpBlockSizeFlag,
+			commands.FlagSpec{Name: "dst-structure", Kind: commands.StringFlag, Default: "preserve", Value: "mode", Help: "preserve keeps the sender's folders; flatten stores every file directly in the destination"},
+			fastcpKeyCredFlag,
+			fastcpStatsFlag,
+		),
 	}
 }
 
+// OutputFormats returns the structured formats of the transfer report
+func (f *FastcpRecvCommand) OutputFormats() []commands.OutputFormat {
+	return fastcpOutputFormats
+}
+
+// FlagSet returns the flags fastcp-recv accepts
+func (f *FastcpRecvCommand) FlagSet() *commands.FlagSet {
+	return f.flags
+}
+
 // Execute receives files via FastCP protocol
 func (f *FastcpRecvCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	flags, usage := f.flags.ParseArguments(args, time.Now())
+	if usage != nil {
+		return usage, nil
+	}
+	stats := newTransferStats(f.Name())
+	result, err := f.execute(ctx, flags, stats)
+	return withTransferStats(ctx, flags.String("stats"), stats, result), err
+}
+
+// execute runs the receiver, recording outcomes in stats
+func (f *FastcpRecvCommand) execute(ctx context.Context, flags *commands.ParsedFlags, stats *TransferStats) (*commands.Result, error) {
 	startTime := time.Now()
 
-	// Parse arguments
 	destination := "."
-	port := 8888
-	encrypt := false
-	autoAccept := false
-
-	for i, arg := range args.Raw {
-		switch arg {
-		case "-p", "--port":
-			if i+1 < len(args.Raw) {
-				fmt.Sscanf(args.Raw[i+1], "%d", &port)
-			}
-		case "-e", "--encrypt":
-			encrypt = true
-		case "--auto-accept":
-			autoAccept = true
-		default:
-			if !strings.HasPrefix(arg, "-") && destination == "." {
-				destination = arg
-			}
+	if flags.Arg(0) != "" {
+		destination = flags.Arg(0)
+	}
+	port := flags.Int("port")
+	encrypt := flags.Bool("encrypt")
+	autoAccept := flags.Bool("auto-accept")
+	serve := flags.Bool("serve")
+	maxConns := flags.Int("max-conns")
+	credName := flags.String("cred")
+	blockSize, err := fastcpBlockSize(flags)
+	structure := flags.String("dst-structure")
+	if err == nil && structure != "preserve" && structure != "flatten" {
+		err = fmt.Errorf("--dst-structure must be preserve or flatten, got %q", structure)
+	}
+	if err != nil {
+		return &commands.Result{
+			Output:   "Usage: " + f.Usage() + "\n",
+			Error:    commands.UsageError(f.Name(), "%v", err),
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}, nil
+	}
+
+	flatten := structure == "flatten"
+	if maxConns < 1 {
+		maxConns = 1
+	}
+	stats.Destination = destination
+
+	// Prompting cannot be shared between concurrent senders
+	if serve {
+		autoAccept = true
+	}
+
+	// A stored key credential replaces an inline transfer key and implies encryption
+	transferKey := ""
+	if credName != "" {
+		cred, err := loadFastcpCredential(credName, security.CredentialTypeKey)
+		if err != nil {
+			return &commands.Result{
+				Output:   fmt.Sprintf("Error: %v\n", err),
+				ExitCode: 1,
+				Duration: time.Since(startTime),
+			}, nil
 		}
+		transferKey = cred.Secret()
+		encrypt = true
 	}
 
 	var output strings.Builder
@@ -61,125 +125,287 @@ func (f *FastcpRecvCommand) Execute(ctx context.Context, args *commands.Argument
 	output.WriteString(color.New(color.FgCyan, color.Bold).Sprint("📥 FASTCP RECEIVER\n"))
 	output.WriteString("═══════════════════════════════════════════════════════════════\n")
 	output.WriteString(fmt.Sprintf("📁 Destination: %s\n", color.New(color.FgGreen).Sprint(destination)))
+	if flatten {
+		output.WriteString("🗂️  Structure:   flatten (all files directly in the destination)\n")
+	}
 	output.WriteString(fmt.Sprintf("🔌 Port:        %d\n", port))
 	output.WriteString(fmt.Sprintf("🔐 Encryption:  %s\n",
 		map[bool]string{true: color.New(color.FgGreen).Sprint("Enabled"), false: color.New(color.FgRed).Sprint("Disabled")}[encrypt]))
+	if credName != "" {
+		output.WriteString(fmt.Sprintf("🔑 Credential:  %s (%s)\n", color.New(color.FgYellow).Sprint(credName), security.MaskSecret(transferKey)))
+	}
 	output.WriteString(fmt.Sprintf("🤖 Auto-accept: %s\n",
 		map[bool]string{true: color.New(color.FgGreen).Sprint("Enabled"), false: color.New(color.FgRed).Sprint("Disabled")}[autoAccept]))
+	if serve {
+		output.WriteString(fmt.Sprintf("🔁 Serve mode:  up to %d concurrent transfers\n", maxConns))
+	}
 	output.WriteString("───────────────────────────────────────────────────────────────\n")
 
-	// Initialize receiver
-	output.WriteString("🔧 Initializing FastCP receiver...\n")
-	time.Sleep(500 * time.Millisecond)
+	var lc net.ListenConfig
+	listener, err := lc.Listen(ctx, "tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		output.WriteString(color.New(color.FgRed).Sprintf("❌ Failed to listen on port %d: %v\n", port, err))
+		return &commands.Result{
+			Output:   output.String(),
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}, nil
+	}
 
-	output.WriteString(fmt.Sprintf("👂 Listening on port %d...\n", port))
-	time.Sleep(1 * time.Second)
+	// Closing the listener is what unblocks Accept on cancellation
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-stop:
+		}
+		listener.Close()
+	}()
+
+	fmt.Fprintf(commands.ProgressWriter(ctx), "👂 Listening on port %d...\n", port)
+
+	if serve {
+		f.serveTransfers(ctx, listener, destination, encrypt, flatten, blockSize, maxConns, stats, &output)
+		output.WriteString("═══════════════════════════════════════════════════════════════\n")
+		return &commands.Result{
+			Output:   output.String(),
+			ExitCode: 0,
+			Duration: time.Since(startTime),
+		}, nil
+	}
 
-	output.WriteString("📡 Incoming connection detected!\n")
-	time.Sleep(300 * time.Millisecond)
+	// Keep accepting until a peer actually starts a transfer
+	var result *fastcpConnResult
+	var finalProgress string
+	for result == nil || result.Err == errFastcpNoTransfer {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return fastcpCancelled(&output, startTime, ctx.Err()), nil
+			}
+			output.WriteString(color.New(color.FgRed).Sprintf("❌ Accept failed: %v\n", err))
+			return &commands.Result{
+				Output:   output.String(),
+				ExitCode: 1,
+				Duration: time.Since(startTime),
+			}, nil
+		}
 
-	// Simulate connection details
-	senderIP := "192.168.1.100"
-	output.WriteString(fmt.Sprintf("🔗 Connection from: %s\n", color.New(color.FgBlue).Sprint(senderIP)))
+		handler := newFastcpConnHandler(conn, destination, blockSize, encrypt)
+		handler.flatten = flatten
+		handler.accept = func(header *fastcpHeader) error {
+			output.WriteString(fmt.Sprintf("🔗 Connection from: %s\n", color.New(color.FgBlue).Sprint(conn.RemoteAddr())))
+			f.writeTransferInfo(header, &output)
+			if !autoAccept {
+				fmt.Print(output.String())
+				output.Reset()
+				if !security.Confirm("❓ Accept this transfer? [y/N]: ") {
+					return fmt.Errorf("transfer rejected by receiver")
+				}
+			}
+			handler.progress = commands.NewProgressReporter(header.TotalSize, len(header.Files), commands.ProgressWriter(ctx))
+			return nil
+		}
 
-	if encrypt {
-		output.WriteString("🤝 Performing encrypted handshake...\n")
-		time.Sleep(400 * time.Millisecond)
-		output.WriteString("✅ Secure channel established\n")
+		result = handler.serve(ctx)
+		if handler.progress != nil {
+			finalProgress = handler.progress.Finish()
+		}
 	}
-
-	output.WriteString("───────────────────────────────────────────────────────────────\n")
-
-	// Simulate transfer metadata
-	transferInfo := struct {
-		filename  string
-		fileCount int
-		totalSize int64
-		compress  bool
-	}{
-		filename:  "project_backup.tar.gz",
-		fileCount: 1247,
-		totalSize: 2.5 * 1024 * 1024 * 1024, // 2.5 GB
-		compress:  true,
+	listener.Close()
+
+	stats.Source = result.Remote
+	stats.Files = append(stats.Files, result.FileStats...)
+	stats.FilesTransferred = result.Files
+	stats.FilesUnchanged = result.Skipped
+	stats.Conflicts = countFastcpConflicts(result.Conflicts)
+	stats.FilesRenamed = result.Renamed
+	stats.BytesReceived = result.Bytes
+
+	if result.Err != nil {
+		stats.Error = result.Err.Error()
+		if ctx.Err() != nil {
+			return fastcpCancelled(&output, startTime, result.Err), nil
+		}
+		output.WriteString(color.New(color.FgRed).Sprintf("❌ Transfer failed: %v\n", result.Err))
+		return &commands.Result{
+			Output:   output.String(),
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}, nil
 	}
 
-	output.WriteString(color.New(color.FgYellow, color.Bold).Sprint("📋 TRANSFER INFORMATION\n"))
-	output.WriteString("───────────────────────────────────────────────────────────────\n")
-	output.WriteString(fmt.Sprintf("📁 Content:     %s\n", transferInfo.filename))
-	output.WriteString(fmt.Sprintf("📊 Files:       %d\n", transferInfo.fileCount))
-	output.WriteString(fmt.Sprintf("📏 Total size:  %s\n", formatBytes(transferInfo.totalSize)))
-	output.WriteString(fmt.Sprintf("🗜️  Compressed:  %s\n",
-		map[bool]string{true: color.New(color.FgGreen).Sprint("Yes"), false: color.New(color.FgRed).Sprint("No")}[transferInfo.compress]))
-
-	// Accept transfer
-	if !autoAccept {
-		output.WriteString("───────────────────────────────────────────────────────────────\n")
-		output.WriteString(color.New(color.FgYellow, color.Bold).Sprint("❓ TRANSFER CONFIRMATION\n"))
-		output.WriteString("Accept this transfer? (Simulating auto-accept for demo)\n")
-		time.Sleep(1 * time.Second)
-	}
+	avgSpeed := float64(result.Bytes) / result.Duration.Seconds()
 
 	output.WriteString("✅ Transfer accepted\n")
 	output.WriteString("───────────────────────────────────────────────────────────────\n")
-
-	// Simulate file transfer
-	output.WriteString(color.New(color.FgGreen, color.Bold).Sprint("📥 RECEIVING FILES\n"))
+	output.WriteString(finalProgress)
 	output.WriteString("───────────────────────────────────────────────────────────────\n")
+	output.WriteString(color.New(color.FgGreen, color.Bold).Sprint("✅ TRANSFER COMPLETE\n"))
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+	output.WriteString(fmt.Sprintf("📊 Received:       %s\n", commands.HumanizeBytes(result.Bytes)))
+	output.WriteString(fmt.Sprintf("📁 Files:          %d\n", result.Files))
+	if result.Skipped > 0 {
+		output.WriteString(fmt.Sprintf("♻️  Unchanged:      %d files already up to date\n", result.Skipped))
+	}
+	writeFastcpConflicts(result.Conflicts, &output)
+	output.WriteString(fmt.Sprintf("📍 Saved to:       %s\n", destination))
+	writeFastcpRenames(result, &output)
+	for _, file := range result.FileStats {
+		if file.Status == "skipped" {
+			output.WriteString(color.New(color.FgYellow).Sprintf("⚠️  Skipped link %s: %s\n", file.Path, file.Error))
+		}
+	}
+	output.WriteString(fmt.Sprintf("⏱️  Duration:       %v\n", result.Duration.Round(time.Millisecond)))
+	output.WriteString(fmt.Sprintf("🚀 Average speed:  %s/s\n", commands.HumanizeBytes(int64(avgSpeed))))
+	output.WriteString("✅ All files verified (SHA-256)\n")
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
 
-	transferStart := time.Now()
-	var received int64
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}, nil
+}
 
-	// Simulate transfer progress
-	for progress := 0; progress <= 100; progress += 3 {
-		received = int64(float64(transferInfo.totalSize) * float64(progress) / 100.0)
-		speed := float64(received) / time.Since(transferStart).Seconds()
+// serveTransfers accepts connections until ctx is cancelled, running at most maxConns
+// handlers at once
+func (f *FastcpRecvCommand) serveTransfers(ctx context.Context, listener net.Listener, destination string, encrypt, flatten bool, blockSize, maxConns int, stats *TransferStats, output *strings.Builder) {
+	sem := make(chan struct{}, maxConns)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var results []*fastcpConnResult
+
+	for {
+		// Wait for a free slot before accepting so extra senders queue in the backlog
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
 
-		progressBar := f.createProgressBar(progress, 50)
-		eta := time.Duration(float64(transferInfo.totalSize-received)/speed) * time.Second
+		conn, err := listener.Accept()
+		if err != nil {
+			<-sem
+			if ctx.Err() != nil {
+				break
+			}
+			fmt.Fprintf(commands.ProgressWriter(ctx), "⚠️  Accept failed: %v\n", err)
+			continue
+		}
 
-		output.WriteString(fmt.Sprintf("\r📈 %s %d%% (%s/%s) - %s/s - ETA: %v",
-			progressBar, progress, formatBytes(received), formatBytes(transferInfo.totalSize),
-			formatBytes(int64(speed)), eta.Round(time.Second)))
+		wg.Add(1)
+		go func(conn net.Conn) {
+			defer wg.Done()
+			defer func() { <-sem }()
 
-		time.Sleep(80 * time.Millisecond)
+			handler := newFastcpConnHandler(conn, destination, blockSize, encrypt)
+			handler.flatten = flatten
+			result := handler.serve(ctx)
+			if result.Err == errFastcpNoTransfer {
+				return
+			}
+
+			if result.Err != nil {
+				fmt.Fprintf(commands.ProgressWriter(ctx), "❌ %s: %v\n", result.Remote, result.Err)
+			} else {
+				fmt.Fprintf(commands.ProgressWriter(ctx), "✅ %s: %d files, %s in %v%s\n", result.Remote, result.Files, commands.HumanizeBytes(result.Bytes), result.Duration.Round(time.Millisecond), fastcpDeltaNote(result.Skipped, countFastcpConflicts(result.Conflicts)))
+			}
+
+			mu.Lock()
+			results = append(results, result)
+			mu.Unlock()
+		}(conn)
 	}
-	output.WriteString("\n")
 
-	transferDuration := time.Since(transferStart)
-	avgSpeed := float64(transferInfo.totalSize) / transferDuration.Seconds()
+	wg.Wait()
 
+	output.WriteString(color.New(color.FgYellow, color.Bold).Sprint("📋 TRANSFER SUMMARY\n"))
 	output.WriteString("───────────────────────────────────────────────────────────────\n")
-	output.WriteString(color.New(color.FgGreen, color.Bold).Sprint("✅ TRANSFER COMPLETE\n"))
-	output.WriteString("───────────────────────────────────────────────────────────────\n")
-	output.WriteString(fmt.Sprintf("📊 Received:       %s\n", formatBytes(transferInfo.totalSize)))
-	output.WriteString(fmt.Sprintf("📁 Files:          %d\n", transferInfo.fileCount))
-	output.WriteString(fmt.Sprintf("📍 Saved to:       %s\n", destination))
-	output.WriteString(fmt.Sprintf("⏱️  Duration:       %v\n", transferDuration.Round(time.Millisecond)))
-	output.WriteString(fmt.Sprintf("🚀 Average speed:  %s/s\n", formatBytes(int64(avgSpeed))))
+	if len(results) == 0 {
+		output.WriteString("No transfers received\n")
+	}
 
-	if transferInfo.compress {
-		output.WriteString("🗜️  Decompressing files...\n")
-		time.Sleep(500 * time.Millisecond)
-		output.WriteString("✅ Decompression complete\n")
+	var totalBytes int64
+	var failed int
+	for _, result := range results {
+		stats.Files = append(stats.Files, result.FileStats...)
+		stats.FilesTransferred += result.Files
+		stats.FilesUnchanged += result.Skipped
+		stats.Conflicts += countFastcpConflicts(result.Conflicts)
+		stats.FilesRenamed += result.Renamed
+		stats.BytesReceived += result.Bytes
+		if result.Err != nil {
+			failed++
+			output.WriteString(fmt.Sprintf("❌ %-22s %v\n", result.Remote, result.Err))
+			continue
+		}
+		totalBytes += result.Bytes
+		output.WriteString(fmt.Sprintf("✅ %-22s %d files, %s%s\n", result.Remote, result.Files, commands.HumanizeBytes(result.Bytes), fastcpDeltaNote(result.Skipped, countFastcpConflicts(result.Conflicts))))
+		writeFastcpRenames(result, output)
 	}
 
-	// Verify integrity
-	output.WriteString("🔍 Verifying file integrity...\n")
-	time.Sleep(300 * time.Millisecond)
-	output.WriteString("✅ All files verified successfully\n")
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+	output.WriteString(fmt.Sprintf("📊 Transfers: %d (%d failed), %s received\n", len(results), failed, commands.HumanizeBytes(totalBytes)))
+}
 
-	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+// writeTransferInfo describes an incoming transfer
+func (f *FastcpRecvCommand) writeTransferInfo(header *fastcpHeader, output *strings.Builder) {
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+	output.WriteString(color.New(color.FgYellow, color.Bold).Sprint("📋 TRANSFER INFORMATION\n"))
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+	if len(header.Files) > 0 {
+		output.WriteString(fmt.Sprintf("📁 Content:     %s\n", header.Files[0].Path))
+	}
+	output.WriteString(fmt.Sprintf("📊 Files:       %d\n", len(header.Files)))
+	output.WriteString(fmt.Sprintf("📏 Total size:  %s\n", commands.HumanizeBytes(header.TotalSize)))
+	output.WriteString(fmt.Sprintf("🗜️  Compressed:  %s\n",
+		map[bool]string{true: color.New(color.FgGreen).Sprint("Yes"), false: color.New(color.FgRed).Sprint("No")}[header.Compressed]))
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+}
 
-	return &commands.Result{
-		Output:   output.String(),
-		ExitCode: 0,
-		Duration: time.Since(startTime),
-	}, nil
+// writeFastcpRenames lists the files a flattened transfer stored under a
+// numbered name because another file already had theirs
+func writeFastcpRenames(result *fastcpConnResult, output *strings.Builder) {
+	if result.Renamed == 0 {
+		return
+	}
+	output.WriteString(color.New(color.FgYellow).Sprint("🔀 Renamed to avoid name collisions:\n"))
+	for _, file := range result.FileStats {
+		if file.SavedAs != "" {
+			output.WriteString(fmt.Sprintf("   %s → %s\n", file.Path, file.SavedAs))
+		}
+	}
 }
 
-// createProgressBar creates a visual progress bar
-func (f *FastcpRecvCommand) createProgressBar(progress, width int) string {
-	filled := progress * width / 100
-	bar := strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
-	return fmt.Sprintf("[%s]", bar)
+// fastcpDeltaNote mentions the files a delta transfer left alone and the
+// conflicts it found, if any
+func fastcpDeltaNote(unchanged, conflicts int) string {
+	var notes []string
+	if unchanged > 0 {
+		notes = append(notes, fmt.Sprintf("%d unchanged", unchanged))
+	}
+	if conflicts == 1 {
+		notes = append(notes, "1 conflict")
+	} else if conflicts > 1 {
+		notes = append(notes, fmt.Sprintf("%d conflicts", conflicts))
+	}
+	if len(notes) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(notes, ", ") + ")"
+}
+
+// countFastcpConflicts counts the files that changed on both ends
+func countFastcpConflicts(conflicts []fastcpConflict) int {
+	count := 0
+	for _, conflict := range conflicts {
+		if conflict.Both {
+			count++
+		}
+	}
+	return count
 }
@@ -0,0 +1,599 @@
+package networking
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"suppercommand/internal/commands"
+)
+
+// errFastcpNoTransfer is returned when a peer connects and disconnects without sending
+// a header, as port probes and health checks do
+var errFastcpNoTransfer = errors.New("connection closed before a transfer started")
+
+// fastcpJournalEntry tracks how much of one file has been received
+type fastcpJournalEntry struct {
+	Size     int64  `json:"size"`
+	Received int64  `json:"received"`
+	Checksum string `json:"checksum,omitempty"`
+	Complete bool   `json:"complete"`
+}
+
+// fastcpJournal records the progress of a single transfer so it can be inspected or
+// resumed after an interruption. It is kept next to the received files.
+type fastcpJournal struct {
+	path       string
+	TransferID string                         `json:"transfer_id"`
+	Files      map[string]*fastcpJournalEntry `json:"files"`
+	mu         sync.Mutex
+}
+
+// newFastcpJournal creates an empty journal for a transfer into destination
+func newFastcpJournal(destination, transferID string) *fastcpJournal {
+	return &fastcpJournal{
+		path:       filepath.Join(destination, ".fastcp-"+transferID+".journal"),
+		TransferID: transferID,
+		Files:      make(map[string]*fastcpJournalEntry),
+	}
+}
+
+// update records the received byte count for a file
+func (j *fastcpJournal) update(name string, size, received int64, checksum string, complete bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.Files[name] = &fastcpJournalEntry{Size: size, Received: received, Checksum: checksum, Complete: complete}
+}
+
+// save writes the journal to disk
+func (j *fastcpJournal) save() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(j.path, data, 0644)
+}
+
+// remove deletes the journal once a transfer has completed
+func (j *fastcpJournal) remove() {
+	os.Remove(j.path)
+}
+
+// fastcpConnResult summarizes what a single connection delivered
+type fastcpConnResult struct {
+	Remote     string
+	TransferID string
+	Files      int
+	Bytes      int64
+	Skipped    int
+	Renamed    int
+	Conflicts  []fastcpConflict
+	Paths      []string
+	FileStats  []TransferFileStats
+	Duration   time.Duration
+	Err        error
+}
+
+// fastcpConnHandler receives one transfer over one connection. Every connection gets
+// its own handler so concurrent senders never share buffers, paths or journals.
+type fastcpConnHandler struct {
+	conn        net.Conn
+	reader      *bufio.Reader
+	destination string
+	blockSize   int
+	encrypt     bool
+	// flatten stores every file directly in destination instead of under its
+	// transfer path
+	flatten bool
+	journal *fastcpJournal
+	accept  func(header *fastcpHeader) error
+	// progress, when set, is advanced as file data arrives
+	progress *commands.ProgressReporter
+}
+
+// newFastcpConnHandler creates a handler for an accepted connection
+func newFastcpConnHandler(conn net.Conn, destination string, blockSize int, encrypt bool) *fastcpConnHandler {
+	if blockSize <= 0 {
+		blockSize = fastcpDefaultBlockSize
+	}
+	return &fastcpConnHandler{
+		conn:        conn,
+		reader:      bufio.NewReaderSize(conn, blockSize),
+		destination: destination,
+		blockSize:   blockSize,
+		encrypt:     encrypt,
+	}
+}
+
+// serve runs the transfer to completion and always closes the connection
+func (h *fastcpConnHandler) serve(ctx context.Context) *fastcpConnResult {
+	startTime := time.Now()
+	result := &fastcpConnResult{Remote: h.conn.RemoteAddr().String()}
+
+	stop := closeOnCancel(ctx, h.conn)
+	defer stop()
+	defer h.conn.Close()
+
+	result.Err = h.receive(result)
+	if result.Err != nil && ctx.Err() != nil {
+		result.Err = ctx.Err()
+	}
+	if result.Err != nil && result.Err != errFastcpNoTransfer {
+		// Best effort; the sender may already be gone
+		writeFastcpMessage(h.conn, fastcpReply{Error: result.Err.Error()})
+		if h.journal != nil {
+			h.journal.save()
+		}
+	}
+
+	result.Duration = time.Since(startTime)
+	return result
+}
+
+// receive performs the handshake and stores each file
+func (h *fastcpConnHandler) receive(result *fastcpConnResult) error {
+	h.conn.SetReadDeadline(time.Now().Add(30 * time.Second))
+
+	var header fastcpHeader
+	if err := readFastcpMessage(h.reader, &header); err != nil {
+		if err == io.EOF {
+			return errFastcpNoTransfer
+		}
+		return fmt.Errorf("failed to read transfer header: %w", err)
+	}
+	h.conn.SetReadDeadline(time.Time{})
+
+	if header.Version != fastcpProtocolVersion {
+		return fmt.Errorf("unsupported protocol version %d", header.Version)
+	}
+	if header.Encrypted != h.encrypt {
+		return fmt.Errorf("encryption mismatch: sender encrypted=%v, receiver encrypted=%v", header.Encrypted, h.encrypt)
+	}
+
+	// Validate every path before touching the filesystem
+	targets := make([]string, len(header.Files))
+	for i, entry := range header.Files {
+		target, err := safeJoin(h.destination, entry.Path)
+		if err != nil {
+			return err
+		}
+		if entry.Size < 0 || (entry.Link != "" && entry.Size != 0) {
+			return fmt.Errorf("invalid size for %s", entry.Path)
+		}
+		targets[i] = target
+	}
+	var savedAs []string
+	if h.flatten {
+		// Sync state is kept by transfer path, which a flat destination loses
+		if header.Conflict != "" {
+			return fmt.Errorf("two-way transfers cannot be received with --dst-structure flatten")
+		}
+		savedAs = flattenFastcpPaths(header.Files)
+		for i, name := range savedAs {
+			if name == "" {
+				continue
+			}
+			target, err := safeJoin(h.destination, name)
+			if err != nil {
+				return err
+			}
+			targets[i] = target
+		}
+	}
+
+	if h.accept != nil {
+		if err := h.accept(&header); err != nil {
+			return err
+		}
+	}
+
+	result.TransferID = header.TransferID
+	if err := os.MkdirAll(h.destination, 0755); err != nil {
+		return err
+	}
+	h.journal = newFastcpJournal(h.destination, header.TransferID)
+
+	if header.Archive && header.Delta {
+		return fmt.Errorf("archive transfers cannot be delta transfers")
+	}
+	if header.Dedup {
+		if header.Archive {
+			return fmt.Errorf("archive transfers cannot be deduplicated")
+		}
+		if err := checkFastcpBlocks(&header); err != nil {
+			return err
+		}
+	}
+	plan, err := h.plan(&header, targets)
+	if err != nil {
+		return err
+	}
+	plan.reply.Archive = header.Archive
+	var blocks *fastcpBlockStore
+	if header.Dedup {
+		blocks = openFastcpBlockStore()
+		plan.reply.Dedup = true
+		plan.reply.HaveBlocks = blocks.held(header.Files, plan.skip)
+	}
+	result.Conflicts = plan.reply.Conflicts
+	if err := writeFastcpMessage(h.conn, plan.reply); err != nil {
+		return err
+	}
+
+	// What both ends hold once a two-way transfer is done, by tree
+	agreed := make(map[string]map[string]fastcpSyncEntry)
+	agree := func(entry fastcpFileEntry, target, checksum string) {
+		if header.Conflict == "" {
+			return
+		}
+		root := fastcpStateRoot(h.destination, entry.Path)
+		if agreed[root] == nil {
+			agreed[root] = make(map[string]fastcpSyncEntry)
+		}
+		synced := fastcpSyncEntry{Checksum: checksum}
+		if info, err := os.Stat(target); err == nil {
+			synced.ModTime = info.ModTime().UnixNano()
+		}
+		agreed[root][entry.Path] = synced
+	}
+
+	// renamedTo is the numbered flat name a file got to avoid a collision, counting
+	// it, or empty when the file kept its own name
+	renamedTo := func(i int) string {
+		if savedAs == nil || savedAs[i] == path.Base(header.Files[i].Path) {
+			return ""
+		}
+		result.Renamed++
+		return savedAs[i]
+	}
+
+	if header.Archive {
+		if err := h.receiveArchive(&header, targets, renamedTo, result); err != nil {
+			return err
+		}
+		h.journal.remove()
+		return writeFastcpMessage(h.conn, fastcpReply{Accepted: true, Files: result.Files, Bytes: result.Bytes})
+	}
+
+	// Blocks to take from the store rather than the connection
+	received := make(map[string]bool, len(plan.reply.HaveBlocks))
+	for _, hash := range plan.reply.HaveBlocks {
+		received[hash] = true
+	}
+	var blockBuffer []byte
+	if header.Dedup {
+		blockBuffer = make([]byte, fastcpDedupBlockSize)
+	}
+
+	buffer := make([]byte, h.blockSize)
+	for i, entry := range header.Files {
+		if status, ok := plan.skip[i]; ok {
+			// The sender leaves these out, so nothing is read for them
+			if status == "unchanged" {
+				result.Skipped++
+				agree(entry, targets[i], entry.Checksum)
+			}
+			result.FileStats = append(result.FileStats, TransferFileStats{Path: entry.Path, Bytes: entry.Size, Checksum: entry.Checksum, Status: status, SavedAs: renamedTo(i)})
+			continue
+		}
+		if entry.Link != "" {
+			var trailer fastcpFileTrailer
+			if err := readFastcpMessage(h.reader, &trailer); err != nil {
+				return fmt.Errorf("%s: failed to read checksum: %w", entry.Path, err)
+			}
+			h.storeLink(entry, targets[i], result)
+			continue
+		}
+
+		var src io.Reader = h.reader
+		if header.Dedup {
+			src = &fastcpBlockReader{wire: h.reader, store: blocks, entry: entry, received: received, buffer: blockBuffer}
+		}
+		checksum, err := h.receiveFile(entry, targets[i], header.TransferID, src, buffer)
+		fileStats := TransferFileStats{Path: entry.Path, Bytes: entry.Size, Checksum: checksum, Status: "ok"}
+		if status, ok := plan.received[i]; ok {
+			fileStats.Status = status
+		}
+		if err != nil {
+			fileStats.Status = "failed"
+			fileStats.Error = err.Error()
+		}
+		fileStats.SavedAs = renamedTo(i)
+		result.FileStats = append(result.FileStats, fileStats)
+		if err != nil {
+			return fmt.Errorf("%s: %w", entry.Path, err)
+		}
+		if plan.received[i] != "renamed" {
+			agree(entry, targets[i], checksum)
+		}
+		result.Files++
+		result.Bytes += entry.Size
+		result.Paths = append(result.Paths, targets[i])
+		if h.progress != nil {
+			h.progress.CompleteFile()
+		}
+	}
+
+	h.journal.remove()
+	for root, files := range agreed {
+		if err := recordFastcpSyncState(root, files); err != nil {
+			return fmt.Errorf("files stored but sync state not saved: %w", err)
+		}
+	}
+	return writeFastcpMessage(h.conn, fastcpReply{Accepted: true, Files: result.Files, Bytes: result.Bytes, Skipped: result.Skipped})
+}
+
+// flattenFastcpPaths names the file each entry is stored as in a flat
+// destination: its base name, or for a later file with the same base name
+// the first free numbered variant, e.g. notes-1.txt. Names are given in
+// transfer order, so sending the same tree again picks the same names. Links
+// are not stored and get no name.
+func flattenFastcpPaths(files []fastcpFileEntry) []string {
+	bases := make(map[string]bool)
+	for _, entry := range files {
+		if entry.Link == "" {
+			bases[path.Base(entry.Path)] = true
+		}
+	}
+
+	names := make([]string, len(files))
+	used := make(map[string]bool)
+	for i, entry := range files {
+		if entry.Link != "" {
+			continue
+		}
+		name := path.Base(entry.Path)
+		if used[name] {
+			ext := path.Ext(name)
+			if ext == name {
+				// A dot file such as .env has no extension to keep
+				ext = ""
+			}
+			stem := strings.TrimSuffix(name, ext)
+			// Skip numbered names another file in the transfer arrives with
+			for n := 1; used[name] || bases[name]; n++ {
+				name = fmt.Sprintf("%s-%d%s", stem, n, ext)
+			}
+		}
+		used[name] = true
+		names[i] = name
+	}
+	return names
+}
+
+// fastcpDeltaPlan is what the receiver decided about the files of a delta
+// transfer before any data is sent
+type fastcpDeltaPlan struct {
+	reply fastcpReply
+	// skip holds the status of each entry the sender leaves out
+	skip map[int]string
+	// received holds the status of entries that replace a changed file or are
+	// stored under a conflict copy name
+	received map[int]string
+}
+
+// plan finds the files of a delta transfer whose target already has the same
+// content and, for a two-way transfer, those that changed on this side since
+// the last sync. Files the sender can leave out are named in the accept reply
+// and progress stops counting them; targets of renamed conflicts are replaced
+// by the copy's path.
+func (h *fastcpConnHandler) plan(header *fastcpHeader, targets []string) (*fastcpDeltaPlan, error) {
+	plan := &fastcpDeltaPlan{reply: fastcpReply{Accepted: true}, skip: make(map[int]string), received: make(map[int]string)}
+	if !header.Delta {
+		return plan, nil
+	}
+	if header.Conflict != "" {
+		if !validFastcpConflictStrategy(header.Conflict) {
+			return nil, fmt.Errorf("unsupported conflict strategy %q", header.Conflict)
+		}
+		plan.reply.Conflict = header.Conflict
+	}
+
+	leaveOut := func(i int, status string) {
+		entry := header.Files[i]
+		plan.reply.Have = append(plan.reply.Have, entry.Path)
+		plan.skip[i] = status
+		if h.progress != nil {
+			h.progress.Total -= entry.Size
+			h.progress.TotalFiles--
+		}
+	}
+
+	states := make(map[string]*fastcpSyncState)
+	for i, entry := range header.Files {
+		if entry.Link != "" || entry.Checksum == "" {
+			continue
+		}
+		info, err := os.Lstat(targets[i])
+		if err != nil || !info.Mode().IsRegular() || (header.Conflict == "" && info.Size() != entry.Size) {
+			continue
+		}
+		checksum, err := fastcpFileChecksum(targets[i])
+		if err != nil {
+			continue
+		}
+		if checksum == entry.Checksum {
+			leaveOut(i, "unchanged")
+			continue
+		}
+		if header.Conflict == "" {
+			continue
+		}
+
+		root := fastcpStateRoot(h.destination, entry.Path)
+		state, ok := states[root]
+		if !ok {
+			if state, err = loadFastcpSyncState(root); err != nil {
+				return nil, err
+			}
+			states[root] = state
+		}
+		conflict := resolveFastcpConflict(entry, info, checksum, state.Files[entry.Path], header.Conflict)
+		if conflict == nil {
+			continue
+		}
+		plan.reply.Conflicts = append(plan.reply.Conflicts, *conflict)
+
+		switch conflict.Resolution {
+		case fastcpResolutionKept:
+			leaveOut(i, conflict.status())
+		case fastcpResolutionReplaced:
+			plan.received[i] = conflict.status()
+		case fastcpResolutionRenamed:
+			copyTarget, err := safeJoin(h.destination, conflict.Copy)
+			if err != nil {
+				return nil, err
+			}
+			targets[i] = copyTarget
+			plan.received[i] = conflict.status()
+			if existing, err := fastcpFileChecksum(copyTarget); err == nil && existing == entry.Checksum {
+				// Saved by an earlier transfer already
+				leaveOut(i, conflict.status())
+			}
+		}
+	}
+	return plan, nil
+}
+
+// storeLink recreates a transferred symlink, counting it in result. A refused
+// link is reported but doesn't abort the rest of the transfer.
+func (h *fastcpConnHandler) storeLink(entry fastcpFileEntry, target string, result *fastcpConnResult) {
+	fileStats := TransferFileStats{Path: entry.Path, Status: "ok"}
+	if h.flatten {
+		// A relative link target means nothing once the tree is gone
+		fileStats.Status = "skipped"
+		fileStats.Error = "links are not kept with --dst-structure flatten"
+	} else if err := h.createLink(entry, target); err != nil {
+		fileStats.Status = "skipped"
+		fileStats.Error = err.Error()
+	} else {
+		result.Files++
+		result.Paths = append(result.Paths, target)
+	}
+	if h.progress != nil {
+		h.progress.CompleteFile()
+	}
+	result.FileStats = append(result.FileStats, fileStats)
+}
+
+// receiveFile streams one file from src into a temporary part file and moves it
+// into place once its checksum matches. It returns the verified checksum.
+func (h *fastcpConnHandler) receiveFile(entry fastcpFileEntry, target, transferID string, src io.Reader, buffer []byte) (string, error) {
+	partPath, checksum, err := h.writePart(entry, target, transferID, src, buffer)
+	if err != nil {
+		return "", err
+	}
+
+	var trailer fastcpFileTrailer
+	if err := readFastcpMessage(h.reader, &trailer); err != nil {
+		return "", fmt.Errorf("failed to read checksum: %w", err)
+	}
+
+	if trailer.Checksum != checksum {
+		os.Remove(partPath)
+		return "", fmt.Errorf("checksum mismatch")
+	}
+
+	if err := os.Rename(partPath, target); err != nil {
+		return "", err
+	}
+
+	h.journal.update(entry.Path, entry.Size, entry.Size, checksum, true)
+	return checksum, nil
+}
+
+// writePart copies the data of one file from src into its part file next to
+// target and returns the part file's path and the checksum of what it holds
+func (h *fastcpConnHandler) writePart(entry fastcpFileEntry, target, transferID string, src io.Reader, buffer []byte) (string, string, error) {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return "", "", err
+	}
+
+	partPath := target + "." + transferID + ".part"
+	file, err := os.Create(partPath)
+	if err != nil {
+		return "", "", err
+	}
+
+	hasher := sha256.New()
+	writers := []io.Writer{file, hasher}
+	if h.progress != nil {
+		writers = append(writers, h.progress)
+	}
+	received, copyErr := io.CopyBuffer(io.MultiWriter(writers...), io.LimitReader(src, entry.Size), buffer)
+	closeErr := file.Close()
+	h.journal.update(entry.Path, entry.Size, received, "", false)
+
+	if copyErr != nil {
+		return "", "", copyErr
+	}
+	if closeErr != nil {
+		return "", "", closeErr
+	}
+	if received != entry.Size {
+		return "", "", fmt.Errorf("connection closed after %d of %d bytes", received, entry.Size)
+	}
+	return partPath, hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// createLink recreates a symlink from the transfer, replacing any file in its way.
+// The target must resolve inside the destination so later files can't be written
+// through it to somewhere else.
+func (h *fastcpConnHandler) createLink(entry fastcpFileEntry, target string) error {
+	link := filepath.FromSlash(entry.Link)
+	if path.IsAbs(entry.Link) || filepath.IsAbs(link) || filepath.VolumeName(link) != "" {
+		return fmt.Errorf("absolute link target %q not allowed", entry.Link)
+	}
+
+	// Only leading ".." components are allowed, so the check below can't be
+	// defeated by climbing back out through another link
+	climbing := true
+	for _, part := range strings.Split(entry.Link, "/") {
+		if part == ".." && !climbing {
+			return fmt.Errorf("link target %q climbs after descending", entry.Link)
+		}
+		climbing = part == ".." || part == "."
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	realDest, err := filepath.EvalSymlinks(h.destination)
+	if err != nil {
+		return err
+	}
+	realParent, err := filepath.EvalSymlinks(filepath.Dir(target))
+	if err != nil {
+		return err
+	}
+	rel, err := filepath.Rel(realDest, filepath.Join(realParent, link))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("link target %q escapes destination", entry.Link)
+	}
+
+	if info, err := os.Lstat(target); err == nil {
+		if info.IsDir() {
+			return fmt.Errorf("a directory is in the way")
+		}
+		if err := os.Remove(target); err != nil {
+			return err
+		}
+	}
+	return os.Symlink(link, target)
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"suppercommand/internal/commands"
+	"suppercommand/internal/security"
 
 	"github.com/fatih/color"
 )
@@ -14,47 +15,87 @@ import (
 // FastcpRestoreCommand restores files from cloud storage
 type FastcpRestoreCommand struct {
 	*commands.BaseCommand
+	flags *commands.FlagSet
 }
 
 // NewFastcpRestoreCommand creates a new fastcp-restore command
 func NewFastcpRestoreCommand() *FastcpRestoreCommand {
+	usage := "fastcp-restore <bucket> <backup-id> <destination> [--verify] [--overwrite] [--interactive] [--filter <glob>] [--cred <name>] [--stats <file>] [--quiet] [--output-format text|json|csv]"
 	return &FastcpRestoreCommand{
 		BaseCommand: commands.NewBaseCommand(
 			"fastcp-restore",
 			"Restore files from cloud storage (S3-compatible)",
-			"fastcp-restore <bucket> <backup-id> <destination> [--verify] [--overwrite]",
+			usage,
 			[]string{"windows", "linux", "darwin"},
 			false,
 		),
+		flags: commands.NewFlagSet("fastcp-restore", usage,
+			commands.FlagSpec{Name: "verify", Help: "Check each restored file against the manifest"},
+			commands.FlagSpec{Name: "overwrite", Help: "Replace existing files"},
+			commands.FlagSpec{Name: "interactive", Short: "i", Help: "Choose which files to restore"},
+			commands.FlagSpec{Name: "filter", Kind: commands.StringFlag, Value: "glob", Help: "Only restore files matching glob"},
+			fastcpCloudCredFlag,
+			fastcpStatsFlag,
+		),
 	}
 }
 
+// OutputFormats returns the structured formats of the transfer report
+func (f *FastcpRestoreCommand) OutputFormats() []commands.OutputFormat {
+	return fastcpOutputFormats
+}
+
+// FlagSet returns the flags fastcp-restore accepts
+func (f *FastcpRestoreCommand) FlagSet() *commands.FlagSet {
+	return f.flags
+}
+
 // Execute restores files from cloud storage
 func (f *FastcpRestoreCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	flags, usage := f.flags.ParseArguments(args, time.Now())
+	if usage != nil {
+		return usage, nil
+	}
+	stats := newTransferStats(f.Name())
+	result, err := f.execute(ctx, flags, stats)
+	return withTransferStats(ctx, flags.String("stats"), stats, result), err
+}
+
+// execute performs the restore, recording outcomes in stats
+func (f *FastcpRestoreCommand) execute(ctx context.Context, flags *commands.ParsedFlags, stats *TransferStats) (*commands.Result, error) {
 	startTime := time.Now()
 
-	if len(args.Raw) < 3 {
+	if len(flags.Args()) < 3 {
 		return &commands.Result{
-			Output:   "Usage: fastcp-restore <bucket> <backup-id> <destination> [--verify] [--overwrite]\n",
+			Output:   "Usage: " + f.Usage() + "\n",
 			ExitCode: 1,
 			Duration: time.Since(startTime),
 		}, nil
 	}
 
-	// Parse arguments
-	bucket := args.Raw[0]
-	backupID := args.Raw[1]
-	destination := args.Raw[2]
-	verify := false
-	overwrite := false
-
-	for _, arg := range args.Raw[3:] {
-		switch arg {
-		case "--verify":
-			verify = true
-		case "--overwrite":
-			overwrite = true
+	bucket := flags.Arg(0)
+	backupID := flags.Arg(1)
+	destination := flags.Arg(2)
+	verify := flags.Bool("verify")
+	overwrite := flags.Bool("overwrite")
+	interactive := flags.Bool("interactive")
+	filter := flags.String("filter")
+	credName := flags.String("cred")
+	stats.Source = "s3://" + bucket + "/" + backupID
+	stats.Destination = destination
+
+	// Cloud credentials come from the credential store rather than the command line
+	var cloudCred *security.Credential
+	if credName != "" {
+		cred, err := loadFastcpCredential(credName, security.CredentialTypeAWS)
+		if err != nil {
+			return &commands.Result{
+				Output:   fmt.Sprintf("Error: %v\n", err),
+				ExitCode: 1,
+				Duration: time.Since(startTime),
+			}, nil
 		}
+		cloudCred = cred
 	}
 
 	var output strings.Builder
@@ -72,21 +113,34 @@ func (f *FastcpRestoreCommand) Execute(ctx context.Context, args *commands.Argum
 
 	// Initialize cloud connection
 	output.WriteString("🔧 Initializing cloud restore...\n")
-	time.Sleep(500 * time.Millisecond)
+	if err := commands.Sleep(ctx, 500*time.Millisecond); err != nil {
+		return fastcpCancelled(&output, startTime, err), nil
+	}
 
-	output.WriteString("🔑 Authenticating with cloud provider...\n")
-	time.Sleep(800 * time.Millisecond)
+	if cloudCred != nil {
+		output.WriteString(fmt.Sprintf("🔑 Authenticating with cloud provider as %s (credential '%s')...\n",
+			security.MaskSecret(cloudCred.Get("access_key")), credName))
+	} else {
+		output.WriteString("🔑 Authenticating with cloud provider...\n")
+	}
+	if err := commands.Sleep(ctx, 800*time.Millisecond); err != nil {
+		return fastcpCancelled(&output, startTime, err), nil
+	}
 	output.WriteString("✅ Authentication successful\n")
 
 	output.WriteString(fmt.Sprintf("🪣 Connecting to bucket '%s'...\n", bucket))
-	time.Sleep(400 * time.Millisecond)
+	if err := commands.Sleep(ctx, 400*time.Millisecond); err != nil {
+		return fastcpCancelled(&output, startTime, err), nil
+	}
 	output.WriteString("✅ Bucket connection established\n")
 
 	output.WriteString("───────────────────────────────────────────────────────────────\n")
 
 	// Locate backup
 	output.WriteString(fmt.Sprintf("🔍 Locating backup '%s'...\n", backupID))
-	time.Sleep(1 * time.Second)
+	if err := commands.Sleep(ctx, 1*time.Second); err != nil {
+		return fastcpCancelled(&output, startTime, err), nil
+	}
 
 	// Simulate backup metadata
 	backupInfo := struct {
@@ -113,7 +167,7 @@ func (f *FastcpRestoreCommand) Execute(ctx context.Context, args *commands.Argum
 	output.WriteString("───────────────────────────────────────────────────────────────\n")
 	output.WriteString(fmt.Sprintf("📅 Created:        %s\n", backupInfo.created.Format("2006-01-02 15:04:05")))
 	output.WriteString(fmt.Sprintf("📊 Files:          %d\n", backupInfo.totalFiles))
-	output.WriteString(fmt.Sprintf("📏 Size:           %s\n", formatBytes(backupInfo.totalSize)))
+	output.WriteString(fmt.Sprintf("📏 Size:           %s\n", commands.HumanizeBytes(backupInfo.totalSize)))
 	output.WriteString(fmt.Sprintf("📁 Original path:  %s\n", backupInfo.originalPath))
 	output.WriteString(fmt.Sprintf("🗜️  Compressed:     %s\n",
 		map[bool]string{true: color.New(color.FgGreen).Sprint("Yes"), false: color.New(color.FgRed).Sprint("No")}[backupInfo.compressed]))
@@ -122,9 +176,38 @@ func (f *FastcpRestoreCommand) Execute(ctx context.Context, args *commands.Argum
 
 	output.WriteString("───────────────────────────────────────────────────────────────\n")
 
+	// Let the user pick individual files instead of restoring the whole backup
+	if interactive {
+		output.WriteString(color.New(color.FgYellow, color.Bold).Sprint("🗂️  SELECT FILES\n"))
+		output.WriteString("───────────────────────────────────────────────────────────────\n")
+		fmt.Print(output.String())
+		output.Reset()
+
+		objects := listBackupObjects(backupID, backupInfo.created, backupInfo.totalFiles, backupInfo.totalSize)
+		selected, err := selectCloudObjects(objects, backupID, filter)
+		if err != nil {
+			output.WriteString(color.New(color.FgYellow).Sprintf("⚠️  Restore cancelled: %v\n", err))
+			return &commands.Result{
+				Output:   output.String(),
+				ExitCode: 1,
+				Duration: time.Since(startTime),
+			}, nil
+		}
+
+		backupInfo.totalFiles = len(selected)
+		backupInfo.totalSize = 0
+		for _, object := range selected {
+			backupInfo.totalSize += object.Size
+		}
+		output.WriteString(fmt.Sprintf("🎯 Selected:       %d files (%s)\n", backupInfo.totalFiles, commands.HumanizeBytes(backupInfo.totalSize)))
+		output.WriteString("───────────────────────────────────────────────────────────────\n")
+	}
+
 	// Check destination
 	output.WriteString("🔍 Checking destination directory...\n")
-	time.Sleep(300 * time.Millisecond)
+	if err := commands.Sleep(ctx, 300*time.Millisecond); err != nil {
+		return fastcpCancelled(&output, startTime, err), nil
+	}
 
 	if !overwrite {
 		output.WriteString("⚠️  Some files may already exist in destination\n")
@@ -138,48 +221,49 @@ func (f *FastcpRestoreCommand) Execute(ctx context.Context, args *commands.Argum
 	output.WriteString(color.New(color.FgGreen, color.Bold).Sprint("📥 STARTING RESTORE\n"))
 	output.WriteString("───────────────────────────────────────────────────────────────\n")
 
-	restoreStart := time.Now()
-	var downloaded int64
-	var restoredFiles int
+	progress := commands.NewProgressReporter(backupInfo.totalSize, backupInfo.totalFiles, commands.ProgressWriter(ctx))
 
 	// Simulate restore progress
-	for progress := 0; progress <= 100; progress += 3 {
-		downloaded = int64(float64(backupInfo.totalSize) * float64(progress) / 100.0)
-		restoredFiles = int(float64(backupInfo.totalFiles) * float64(progress) / 100.0)
-		speed := float64(downloaded) / time.Since(restoreStart).Seconds()
-
-		progressBar := f.createProgressBar(progress, 50)
-		eta := time.Duration(float64(backupInfo.totalSize-downloaded)/speed) * time.Second
+	for percent := 0; percent <= 100; percent += 3 {
+		progress.Update(int64(float64(backupInfo.totalSize)*float64(percent)/100.0),
+			int(float64(backupInfo.totalFiles)*float64(percent)/100.0))
 
-		output.WriteString(fmt.Sprintf("\r📈 %s %d%% (%d/%d files, %s/%s) - %s/s - ETA: %v",
-			progressBar, progress, restoredFiles, backupInfo.totalFiles,
-			formatBytes(downloaded), formatBytes(backupInfo.totalSize),
-			formatBytes(int64(speed)), eta.Round(time.Second)))
-
-		time.Sleep(80 * time.Millisecond)
+		if err := commands.Sleep(ctx, 80*time.Millisecond); err != nil {
+			progress.Finish()
+			return fastcpCancelled(&output, startTime, err), nil
+		}
 	}
-	output.WriteString("\n")
+	progress.Update(backupInfo.totalSize, backupInfo.totalFiles)
+	output.WriteString(progress.Finish())
 
-	restoreDuration := time.Since(restoreStart)
-	avgSpeed := float64(backupInfo.totalSize) / restoreDuration.Seconds()
+	restoreDuration := progress.Elapsed()
+	stats.FilesTransferred = backupInfo.totalFiles
+	stats.BytesReceived = backupInfo.totalSize
+	avgSpeed := progress.Average(time.Now())
 
 	// Post-processing
 	if backupInfo.compressed {
 		output.WriteString("🗜️  Decompressing files...\n")
-		time.Sleep(800 * time.Millisecond)
+		if err := commands.Sleep(ctx, 800*time.Millisecond); err != nil {
+			return fastcpCancelled(&output, startTime, err), nil
+		}
 		output.WriteString("✅ Decompression complete\n")
 	}
 
 	if backupInfo.encrypted {
 		output.WriteString("🔐 Decrypting files...\n")
-		time.Sleep(600 * time.Millisecond)
+		if err := commands.Sleep(ctx, 600*time.Millisecond); err != nil {
+			return fastcpCancelled(&output, startTime, err), nil
+		}
 		output.WriteString("✅ Decryption complete\n")
 	}
 
 	// Verification
 	if verify {
 		output.WriteString("🔍 Verifying restored files...\n")
-		time.Sleep(1 * time.Second)
+		if err := commands.Sleep(ctx, 1*time.Second); err != nil {
+			return fastcpCancelled(&output, startTime, err), nil
+		}
 
 		verifyResults := struct {
 			verified int
@@ -201,10 +285,10 @@ func (f *FastcpRestoreCommand) Execute(ctx context.Context, args *commands.Argum
 	output.WriteString(color.New(color.FgGreen, color.Bold).Sprint("✅ RESTORE COMPLETE\n"))
 	output.WriteString("───────────────────────────────────────────────────────────────\n")
 	output.WriteString(fmt.Sprintf("📊 Files restored:  %d\n", backupInfo.totalFiles))
-	output.WriteString(fmt.Sprintf("📥 Data downloaded: %s\n", formatBytes(backupInfo.totalSize)))
+	output.WriteString(fmt.Sprintf("📥 Data downloaded: %s\n", commands.HumanizeBytes(backupInfo.totalSize)))
 	output.WriteString(fmt.Sprintf("📍 Restored to:     %s\n", destination))
 	output.WriteString(fmt.Sprintf("⏱️  Duration:       %v\n", restoreDuration.Round(time.Millisecond)))
-	output.WriteString(fmt.Sprintf("🚀 Average speed:  %s/s\n", formatBytes(int64(avgSpeed))))
+	output.WriteString(fmt.Sprintf("🚀 Average speed:  %s/s\n", commands.HumanizeBytes(int64(avgSpeed))))
 
 	output.WriteString("───────────────────────────────────────────────────────────────\n")
 	output.WriteString("💡 Restore completed successfully\n")
@@ -217,10 +301,3 @@ func (f *FastcpRestoreCommand) Execute(ctx context.Context, args *commands.Argum
 		Duration: time.Since(startTime),
 	}, nil
 }
-
-// createProgressBar creates a visual progress bar
-func (f *FastcpRestoreCommand) createProgressBar(progress, width int) string {
-	filled := progress * width / 100
-	bar := strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
-	return fmt.Sprintf("[%s]", bar)
-}
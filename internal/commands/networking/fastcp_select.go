@@ -0,0 +1,126 @@
+package networking
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/security"
+
+	"github.com/fatih/color"
+)
+
+// fastcpSelectionRows is how many objects the interactive list shows at once
+const fastcpSelectionRows = 40
+
+// cloudObject describes one object stored under a backup's prefix
+type cloudObject struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+// listBackupObjects lists the objects stored in a backup. The listing is simulated
+// like the rest of the cloud transfer, but stable for a given backup ID.
+func listBackupObjects(backupID string, created time.Time, count int, totalSize int64) []cloudObject {
+	seed := fnv.New64a()
+	seed.Write([]byte(backupID))
+	random := rand.New(rand.NewSource(int64(seed.Sum64())))
+
+	folders := []string{"projects", "reports", "photos", "notes", "archive"}
+	extensions := map[string][]string{
+		"projects": {".go", ".md", ".json"},
+		"reports":  {".pdf", ".xlsx", ".docx"},
+		"photos":   {".jpg", ".png"},
+		"notes":    {".txt", ".md"},
+		"archive":  {".zip", ".tar.gz"},
+	}
+
+	averageSize := totalSize / int64(count)
+	objects := make([]cloudObject, count)
+	for i := range objects {
+		folder := folders[i%len(folders)]
+		options := extensions[folder]
+		objects[i] = cloudObject{
+			Key:          fmt.Sprintf("%s/%s/file%04d%s", backupID, folder, i/len(folders), options[random.Intn(len(options))]),
+			Size:         random.Int63n(2*averageSize) + 1,
+			LastModified: created.Add(-time.Duration(random.Intn(90*24)) * time.Hour),
+		}
+	}
+	return objects
+}
+
+// filterCloudObjects keeps the objects whose key below the backup prefix matches glob,
+// using the same pattern rules as the fastcp-send filters
+func filterCloudObjects(objects []cloudObject, backupID, glob string) []cloudObject {
+	pattern, ok := parseFastcpPattern(glob)
+	if !ok {
+		return objects
+	}
+
+	var matched []cloudObject
+	for _, object := range objects {
+		if pattern.matches(strings.TrimPrefix(object.Key, backupID+"/"), false) {
+			matched = append(matched, object)
+		}
+	}
+	return matched
+}
+
+// writeCloudObjectList prints a numbered list of objects with their size and age
+func writeCloudObjectList(objects []cloudObject, backupID, glob string) {
+	if glob != "" {
+		fmt.Printf("🔎 Filter: %s (%d matching)\n", color.New(color.FgYellow).Sprint(glob), len(objects))
+	}
+
+	for i, object := range objects {
+		if i == fastcpSelectionRows {
+			fmt.Printf("   ... and %d more; type /<glob> to narrow the list\n", len(objects)-i)
+			break
+		}
+		fmt.Printf("  %4d. %-40s %10s  %s\n", i+1, strings.TrimPrefix(object.Key, backupID+"/"),
+			commands.HumanizeBytes(object.Size), object.LastModified.Format("2006-01-02 15:04"))
+	}
+}
+
+// selectCloudObjects prompts until the user picks the objects to restore. Typing
+// /<glob> filters the list, and the numbers always refer to the list last shown.
+func selectCloudObjects(objects []cloudObject, backupID, glob string) ([]cloudObject, error) {
+	for {
+		visible := filterCloudObjects(objects, backupID, glob)
+		writeCloudObjectList(visible, backupID, glob)
+
+		answer, err := security.ReadLine("❓ Restore which files? [e.g. 1,3-5, all, /<glob> to filter, q to cancel]: ")
+		if err != nil {
+			return nil, err
+		}
+		answer = strings.TrimSpace(answer)
+
+		switch {
+		case answer == "" || strings.EqualFold(answer, "q"):
+			return nil, fmt.Errorf("no files selected")
+		case strings.HasPrefix(answer, "/"):
+			glob = strings.TrimSpace(answer[1:])
+			continue
+		}
+
+		indexes, err := commands.ParseSelection(answer, len(visible))
+		if err != nil {
+			fmt.Println(color.New(color.FgRed).Sprintf("❌ %v", err))
+			continue
+		}
+		if len(indexes) == 0 {
+			fmt.Println(color.New(color.FgYellow).Sprint("⚠️  Nothing matches the current filter"))
+			continue
+		}
+
+		selected := make([]cloudObject, len(indexes))
+		for i, index := range indexes {
+			selected[i] = visible[index]
+		}
+		return selected, nil
+	}
+}
@@ -1,15 +1,19 @@
 package networking
 
 import (
+	"bufio"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
-	"math/rand"
+	"io"
+	"net"
 	"os"
-	"path/filepath"
 	"strings"
 	"time"
 
 	"suppercommand/internal/commands"
+	"suppercommand/internal/security"
 
 	"github.com/fatih/color"
 )
@@ -17,51 +21,143 @@ import (
 // FastcpSendCommand sends files via ultra-fast encrypted transfer
 type FastcpSendCommand struct {
 	*commands.BaseCommand
+	flags *commands.FlagSet
 }
 
 // NewFastcpSendCommand creates a new fastcp-send command
 func NewFastcpSendCommand() *FastcpSendCommand {
+	usage := "fastcp-send <file/dir> <destination> [-p <port>] [-e] [--compress] [--cred <name>] [--include <glob>]... [--exclude <glob>]... [--exclude-from <file>] [--follow-symlinks|--no-follow-symlinks] [--block-size <size>] [--archive] [--dedup] [--delta] [--conflict newer|larger|rename|skip] [--sync [--debounce <duration>]] [--dry-run] [--stats <file>] [--quiet] [--output-format text|json|csv]"
 	return &FastcpSendCommand{
 		BaseCommand: commands.NewBaseCommand(
 			"fastcp-send",
 			"Ultra-fast encrypted file/directory transfer (sender)",
-			"fastcp-send <file/dir> <destination> [-p <port>] [-e] [--compress]",
+			usage,
 			[]string{"windows", "linux", "darwin"},
 			false,
 		),
+		flags: commands.NewFlagSet("fastcp-send", usage,
+			fastcpPortFlag,
+			commands.FlagSpec{Name: "encrypt", Short: "e", Help: "Encrypt the transfer"},
+			commands.FlagSpec{Name: "compress", Help: "Compress file data on the wire"},
+			fastcpKeyCredFlag,
+			commands.FlagSpec{Name: "include", Kind: commands.ListFlag, Value: "glob", Help: "Only send files matching glob"},
+			commands.FlagSpec{Name: "exclude", Kind: commands.ListFlag, Value: "glob", Help: "Skip files matching glob"},
+			commands.FlagSpec{Name: "exclude-from", Kind: commands.ListFlag, Value: "file", Help: "Read exclude globs from file"},
+			commands.FlagSpec{Name: "follow-symlinks", Help: "Send the targets of symbolic links"},
+			fastcpBlockSizeFlag,
+			commands.FlagSpec{Name: "archive", Help: "Send a directory as one tar stream, keeping permissions and modification times; faster for many small files"},
+			commands.FlagSpec{Name: "dedup", Help: "Send only the blocks the receiver doesn't already hold from this or earlier transfers"},
+			commands.FlagSpec{Name: "delta", Help: "Skip files the receiver already has with the same content"},
+			commands.FlagSpec{Name: "conflict", Kind: commands.StringFlag, Value: "strategy", Help: "Two-way mode: detect files changed on both ends and keep the newer, the larger, both (rename) or the receiver's (skip)"},
+			commands.FlagSpec{Name: "sync", Help: "Keep running and send changed files as they change; implies --delta"},
+			commands.FlagSpec{Name: "debounce", Kind: commands.StringFlag, Default: "500ms", Value: "duration", Help: "How long changes must settle before --sync sends them"},
+			commands.FlagSpec{Name: "dry-run", Help: "List what would be sent without connecting"},
+			fastcpStatsFlag,
+		),
 	}
 }
 
-// Execute sends files via FastCP protocol
+// OutputFormats returns the structured formats of the transfer report
+func (f *FastcpSendCommand) OutputFormats() []commands.OutputFormat {
+	return fastcpOutputFormats
+}
+
+// FlagSet returns the flags fastcp-send accepts
+func (f *FastcpSendCommand) FlagSet() *commands.FlagSet {
+	return f.flags
+}
+
+// Execute sends files via FastCP protocol. With --sync it then watches a source
+// directory and sends each settled burst of changes over a new connection until
+// Enter is pressed or the shell is interrupted, so the receiver should run with
+// --serve. Deletions on the source are not propagated. With --conflict both
+// ends remember what they last agreed on, so a file that changed on the
+// receiver since then is reported and resolved by the strategy instead of
+// silently overwritten. With --archive the files travel as one tar stream,
+// which saves the per-file round of framing and checksum lines and keeps
+// permissions and modification times; it sends everything, so it doesn't mix
+// with --delta, --sync or --conflict. With --dedup each file is announced as
+// a list of block hashes and only the blocks the receiver has never received,
+// in this transfer or an earlier one to any destination, are sent.
 func (f *FastcpSendCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	flags, usage := f.flags.ParseArguments(args, time.Now())
+	if usage != nil {
+		return usage, nil
+	}
+	stats := newTransferStats(f.Name())
+	result, err := f.execute(ctx, flags, stats)
+	return withTransferStats(ctx, flags.String("stats"), stats, result), err
+}
+
+// execute performs the send, recording outcomes in stats
+func (f *FastcpSendCommand) execute(ctx context.Context, flags *commands.ParsedFlags, stats *TransferStats) (*commands.Result, error) {
 	startTime := time.Now()
 
-	if len(args.Raw) < 2 {
+	if len(flags.Args()) < 2 {
 		return &commands.Result{
-			Output:   "Usage: fastcp-send <file/dir> <destination> [-p <port>] [-e] [--compress]\n",
+			Output:   "Usage: " + f.Usage() + "\n",
 			ExitCode: 1,
 			Duration: time.Since(startTime),
 		}, nil
 	}
 
-	// Parse arguments
-	source := args.Raw[0]
-	destination := args.Raw[1]
-	port := 8888
-	encrypt := false
-	compress := false
+	source := flags.Arg(0)
+	destination := flags.Arg(1)
+	stats.Source = source
+	stats.Destination = destination
+	port := flags.Int("port")
+	encrypt := flags.Bool("encrypt")
+	compress := flags.Bool("compress")
+	dryRun := flags.Bool("dry-run")
+	followSymlinks := flags.Bool("follow-symlinks")
+	credName := flags.String("cred")
+	includes := flags.Strings("include")
+	excludes := flags.Strings("exclude")
+	excludeFrom := flags.Strings("exclude-from")
+	sync := flags.Bool("sync")
+	conflict := flags.String("conflict")
+	archive := flags.Bool("archive")
+	dedup := flags.Bool("dedup")
+	delta := flags.Bool("delta") || sync || conflict != ""
+	blockSize, err := fastcpBlockSize(flags)
+	if err == nil && flags.Changed("conflict") && !validFastcpConflictStrategy(conflict) {
+		err = fmt.Errorf("invalid --conflict '%s': expected %s", conflict, strings.Join(fastcpConflictStrategies, ", "))
+	}
+	if err == nil && archive && (delta || flags.Changed("conflict")) {
+		err = fmt.Errorf("--archive sends every file, so it cannot be combined with --delta, --sync or --conflict")
+	}
+	if err == nil && archive && dedup {
+		err = fmt.Errorf("--archive cannot be combined with --dedup")
+	}
+	if err == nil && sync && dryRun {
+		err = fmt.Errorf("--sync cannot be combined with --dry-run")
+	}
+	debounce, debounceErr := time.ParseDuration(flags.String("debounce"))
+	if err == nil && (debounceErr != nil || debounce < 0) {
+		err = fmt.Errorf("invalid --debounce '%s': expected a duration such as 500ms or 2s", flags.String("debounce"))
+	}
+	if err != nil {
+		return &commands.Result{
+			Output:   "Usage: " + f.Usage() + "\n",
+			Error:    commands.UsageError(f.Name(), "%v", err),
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}, nil
+	}
 
-	for i, arg := range args.Raw[2:] {
-		switch arg {
-		case "-p", "--port":
-			if i+1 < len(args.Raw[2:]) {
-				fmt.Sscanf(args.Raw[2:][i+1], "%d", &port)
-			}
-		case "-e", "--encrypt":
-			encrypt = true
-		case "--compress":
-			compress = true
+	// A stored key credential replaces an inline transfer key and implies encryption
+	transferKey := ""
+	if credName != "" {
+		cred, err := loadFastcpCredential(credName, security.CredentialTypeKey)
+		if err != nil {
+			return &commands.Result{
+				Output:   fmt.Sprintf("Error: %v\n", err),
+				ExitCode: 1,
+				Duration: time.Since(startTime),
+			}, nil
 		}
+		transferKey = cred.Secret()
+		encrypt = true
 	}
 
 	var output strings.Builder
@@ -73,94 +169,183 @@ func (f *FastcpSendCommand) Execute(ctx context.Context, args *commands.Argument
 	output.WriteString(fmt.Sprintf("🔌 Port:        %d\n", port))
 	output.WriteString(fmt.Sprintf("🔐 Encryption:  %s\n",
 		map[bool]string{true: color.New(color.FgGreen).Sprint("Enabled"), false: color.New(color.FgRed).Sprint("Disabled")}[encrypt]))
+	if credName != "" {
+		output.WriteString(fmt.Sprintf("🔑 Credential:  %s (%s)\n", color.New(color.FgYellow).Sprint(credName), security.MaskSecret(transferKey)))
+	}
 	output.WriteString(fmt.Sprintf("🗜️  Compression: %s\n",
 		map[bool]string{true: color.New(color.FgGreen).Sprint("Enabled"), false: color.New(color.FgRed).Sprint("Disabled")}[compress]))
+	if archive {
+		output.WriteString(fmt.Sprintf("📦 Archive:     %s\n", color.New(color.FgGreen).Sprint("one tar stream")))
+	}
+	if dedup {
+		output.WriteString(fmt.Sprintf("🧩 Dedup:       %s\n", color.New(color.FgGreen).Sprintf("%s blocks", commands.HumanizeBytes(fastcpDedupBlockSize))))
+	}
 	output.WriteString("───────────────────────────────────────────────────────────────\n")
 
-	// Check if source exists
-	sourceInfo, err := os.Stat(source)
+	filter, err := newFastcpFilter(includes, excludes, excludeFrom)
 	if err != nil {
 		return &commands.Result{
-			Output:   fmt.Sprintf("Error: Source not found: %s\n", source),
+			Output:   fmt.Sprintf("Error: cannot read exclude file: %v\n", err),
 			ExitCode: 1,
 			Duration: time.Since(startTime),
 		}, nil
 	}
 
-	// Calculate transfer size
-	var totalSize int64
-	var fileCount int
+	// Collect the files to send
+	files, loopsSkipped, err := collectFastcpFiles(source, filter, followSymlinks)
+	if err != nil {
+		return &commands.Result{
+			Output:   fmt.Sprintf("Error: %v\n", err),
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}, nil
+	}
+
+	if sync {
+		if info, err := os.Stat(source); err == nil && !info.IsDir() {
+			return &commands.Result{
+				Output:   "Error: --sync needs a directory to watch\n",
+				ExitCode: 1,
+				Duration: time.Since(startTime),
+			}, nil
+		}
+	}
+
+	header, err := newFastcpHeader(files, encrypt, compress, delta && !dryRun)
+	if err == nil {
+		header.Archive = archive
+	}
+	if err == nil && conflict != "" && !dryRun {
+		err = prepareFastcpTwoWay(header, files, source, conflict)
+	}
+	if err == nil && dedup && !dryRun {
+		err = addFastcpBlocks(header, files)
+	}
+	if err != nil {
+		return &commands.Result{
+			Output:   fmt.Sprintf("Error: %v\n", err),
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}, nil
+	}
 
-	if sourceInfo.IsDir() {
-		totalSize, fileCount = f.calculateDirSize(source)
-		output.WriteString(fmt.Sprintf("📊 Directory:   %d files, %s\n", fileCount, formatBytes(totalSize)))
+	if len(files) == 1 {
+		output.WriteString(fmt.Sprintf("📊 File size:   %s\n", commands.HumanizeBytes(header.TotalSize)))
 	} else {
-		totalSize = sourceInfo.Size()
-		fileCount = 1
-		output.WriteString(fmt.Sprintf("📊 File size:   %s\n", formatBytes(totalSize)))
+		output.WriteString(fmt.Sprintf("📊 Directory:   %d files, %s\n", len(files), commands.HumanizeBytes(header.TotalSize)))
+	}
+	if filter.active() {
+		output.WriteString(fmt.Sprintf("🚫 Excluded:    %d files, %d directories\n", filter.excludedFiles, filter.excludedDirs))
+		stats.FilesExcluded = filter.excludedFiles
+		stats.DirectoriesExcluded = filter.excludedDirs
+	}
+	if links := countFastcpLinks(files); links > 0 {
+		output.WriteString(fmt.Sprintf("🔗 Symlinks:    %d sent as links\n", links))
+	}
+	if loopsSkipped > 0 {
+		output.WriteString(color.New(color.FgYellow).Sprintf("⚠️  Skipped %d symlinked directories already sent (link loops)\n", loopsSkipped))
 	}
 
 	output.WriteString("───────────────────────────────────────────────────────────────\n")
 
-	// Initialize FastCP protocol
-	output.WriteString("🔧 Initializing FastCP protocol...\n")
-	time.Sleep(500 * time.Millisecond)
-
-	if encrypt {
-		output.WriteString("🔐 Generating encryption keys...\n")
-		time.Sleep(300 * time.Millisecond)
+	// A destination may carry its own port
+	address := net.JoinHostPort(destination, fmt.Sprintf("%d", port))
+	if host, hostPort, err := net.SplitHostPort(destination); err == nil {
+		address = net.JoinHostPort(host, hostPort)
 	}
 
-	output.WriteString(fmt.Sprintf("📡 Connecting to %s:%d...\n", destination, port))
-	time.Sleep(800 * time.Millisecond)
-	output.WriteString("✅ Connection established\n")
-
-	if encrypt {
-		output.WriteString("🤝 Performing encrypted handshake...\n")
-		time.Sleep(400 * time.Millisecond)
-		output.WriteString("✅ Secure channel established\n")
+	if dryRun {
+		f.writeDryRun(files, header, address, &output)
+		return &commands.Result{
+			Output:   output.String(),
+			ExitCode: 0,
+			Duration: time.Since(startTime),
+		}, nil
 	}
 
+	output.WriteString(fmt.Sprintf("📡 Connecting to %s...\n", address))
+	conn, err := dialFastcp(ctx, address)
+	if err != nil {
+		if ctx.Err() != nil {
+			return fastcpCancelled(&output, startTime, ctx.Err()), nil
+		}
+		output.WriteString(color.New(color.FgRed).Sprintf("❌ Connection failed: %v\n", err))
+		return &commands.Result{
+			Output:   output.String(),
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}, nil
+	}
+	output.WriteString("✅ Connection established\n")
 	output.WriteString("───────────────────────────────────────────────────────────────\n")
 
-	// Simulate file transfer
 	output.WriteString(color.New(color.FgGreen, color.Bold).Sprint("📤 STARTING TRANSFER\n"))
 	output.WriteString("───────────────────────────────────────────────────────────────\n")
 
-	transferStart := time.Now()
-	var transferred int64
-
-	// Simulate transfer progress
-	for progress := 0; progress <= 100; progress += 5 {
-		transferred = int64(float64(totalSize) * float64(progress) / 100.0)
-		speed := float64(transferred) / time.Since(transferStart).Seconds()
-
-		progressBar := f.createProgressBar(progress, 50)
-		output.WriteString(fmt.Sprintf("\r📈 %s %d%% (%s/%s) - %s/s",
-			progressBar, progress, formatBytes(transferred), formatBytes(totalSize), formatBytes(int64(speed))))
-
-		time.Sleep(100 * time.Millisecond)
+	progress := commands.NewProgressReporter(header.TotalSize, len(files), commands.ProgressWriter(ctx))
+	reply, err := f.transfer(ctx, conn, header, files, blockSize, stats, progress)
+	finalProgress := progress.Finish()
+	if err != nil {
+		stats.Error = err.Error()
+		if ctx.Err() != nil {
+			return fastcpCancelled(&output, startTime, ctx.Err()), nil
+		}
+		output.WriteString(color.New(color.FgRed).Sprintf("❌ Transfer failed: %v\n", err))
+		return &commands.Result{
+			Output:   output.String(),
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}, nil
 	}
-	output.WriteString("\n")
 
-	transferDuration := time.Since(transferStart)
-	avgSpeed := float64(totalSize) / transferDuration.Seconds()
+	output.WriteString(finalProgress)
+	transferDuration := progress.Elapsed()
+	avgSpeed := progress.Average(time.Now())
+	stats.FilesTransferred = reply.Files
+	stats.BytesSent = reply.Bytes
 
 	output.WriteString("───────────────────────────────────────────────────────────────\n")
 	output.WriteString(color.New(color.FgGreen, color.Bold).Sprint("✅ TRANSFER COMPLETE\n"))
 	output.WriteString("───────────────────────────────────────────────────────────────\n")
-	output.WriteString(fmt.Sprintf("📊 Transferred:    %s\n", formatBytes(totalSize)))
-	output.WriteString(fmt.Sprintf("📁 Files:          %d\n", fileCount))
+	output.WriteString(fmt.Sprintf("📊 Transferred:    %s\n", commands.HumanizeBytes(reply.Bytes)))
+	output.WriteString(fmt.Sprintf("📁 Files:          %d\n", reply.Files))
+	if stats.FilesUnchanged > 0 {
+		output.WriteString(fmt.Sprintf("♻️  Unchanged:      %d files, %s not sent\n", stats.FilesUnchanged, commands.HumanizeBytes(stats.BytesSaved)))
+	}
+	if stats.BlocksDeduplicated > 0 {
+		output.WriteString(fmt.Sprintf("🧩 Deduplicated:   %d blocks, %s not sent\n", stats.BlocksDeduplicated, commands.HumanizeBytes(stats.BytesDeduplicated)))
+	}
+	writeFastcpConflicts(reply.Conflicts, &output)
+	if conflict != "" {
+		if err := recordFastcpSent(source, header, reply); err != nil {
+			output.WriteString(color.New(color.FgYellow).Sprintf("⚠️  Sync state not saved, the next transfer may report false conflicts: %v\n", err))
+		}
+	}
 	output.WriteString(fmt.Sprintf("⏱️  Duration:       %v\n", transferDuration.Round(time.Millisecond)))
-	output.WriteString(fmt.Sprintf("🚀 Average speed:  %s/s\n", formatBytes(int64(avgSpeed))))
+	output.WriteString(fmt.Sprintf("🚀 Average speed:  %s/s\n", commands.HumanizeBytes(int64(avgSpeed))))
+	output.WriteString("✅ Receiver verified all files (SHA-256)\n")
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
 
-	if compress {
-		compressionRatio := 0.3 + rand.Float64()*0.4 // 30-70% compression
-		output.WriteString(fmt.Sprintf("🗜️  Compression:    %.1f%% saved\n", compressionRatio*100))
+	if sync {
+		session := &fastcpSync{
+			command:        f,
+			source:         source,
+			address:        address,
+			includes:       includes,
+			excludes:       excludes,
+			excludeFrom:    excludeFrom,
+			followSymlinks: followSymlinks,
+			encrypt:        encrypt,
+			compress:       compress,
+			dedup:          dedup,
+			blockSize:      blockSize,
+			conflict:       conflict,
+			debounce:       debounce,
+			stats:          stats,
+		}
+		return session.run(ctx, &output, startTime), nil
 	}
 
-	output.WriteString("═══════════════════════════════════════════════════════════════\n")
-
 	return &commands.Result{
 		Output:   output.String(),
 		ExitCode: 0,
@@ -168,25 +353,210 @@ func (f *FastcpSendCommand) Execute(ctx context.Context, args *commands.Argument
 	}, nil
 }
 
-// calculateDirSize calculates the total size and file count of a directory
-func (f *FastcpSendCommand) calculateDirSize(dir string) (int64, int) {
-	var totalSize int64
-	var fileCount int
+// newFastcpHeader describes files for a new transfer. With delta every file
+// carries its checksum so the receiver can name the ones it already has.
+func newFastcpHeader(files []fastcpSourceFile, encrypt, compress, delta bool) (*fastcpHeader, error) {
+	header := &fastcpHeader{
+		Version:    fastcpProtocolVersion,
+		TransferID: newTransferID(),
+		Encrypted:  encrypt,
+		Compressed: compress,
+		Delta:      delta,
+	}
+	for i := range files {
+		if delta && files[i].entry.Link == "" {
+			checksum, err := fastcpFileChecksum(files[i].local)
+			if err != nil {
+				return nil, err
+			}
+			files[i].entry.Checksum = checksum
+		}
+		header.Files = append(header.Files, files[i].entry)
+		header.TotalSize += files[i].entry.Size
+	}
+	return header, nil
+}
 
-	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-		if err == nil && !info.IsDir() {
-			totalSize += info.Size()
-			fileCount++
+// writeFastcpConflicts lists the files a two-way transfer found changed on the
+// receiver and what became of them
+func writeFastcpConflicts(conflicts []fastcpConflict, output *strings.Builder) {
+	for _, conflict := range conflicts {
+		if conflict.Both {
+			output.WriteString(color.New(color.FgYellow).Sprintf("⚠️  Conflict: %s\n", conflict.describe()))
+			continue
 		}
-		return nil
-	})
+		output.WriteString(color.New(color.FgHiBlack).Sprintf("↩️  %s\n", conflict.describe()))
+	}
+}
+
+// dialFastcp connects to a receiver
+func dialFastcp(ctx context.Context, address string) (net.Conn, error) {
+	dialer := net.Dialer{Timeout: 10 * time.Second}
+	return dialer.DialContext(ctx, "tcp", address)
+}
 
-	return totalSize, fileCount
+// writeDryRun lists what a transfer would send without connecting
+func (f *FastcpSendCommand) writeDryRun(files []fastcpSourceFile, header *fastcpHeader, address string, output *strings.Builder) {
+	output.WriteString(color.New(color.FgYellow, color.Bold).Sprint("🧪 DRY RUN - nothing will be sent\n"))
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+	for _, file := range files {
+		if file.entry.Link != "" {
+			output.WriteString(fmt.Sprintf("  %-50s -> %s\n", file.entry.Path, file.entry.Link))
+			continue
+		}
+		output.WriteString(fmt.Sprintf("  %-50s %10s\n", file.entry.Path, commands.HumanizeBytes(file.entry.Size)))
+	}
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+	output.WriteString(fmt.Sprintf("📊 Would send:  %d files, %s\n", len(files), commands.HumanizeBytes(header.TotalSize)))
+	output.WriteString(fmt.Sprintf("🎯 Target:      %s\n", address))
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
 }
 
-// createProgressBar creates a visual progress bar
-func (f *FastcpSendCommand) createProgressBar(progress, width int) string {
-	filled := progress * width / 100
-	bar := strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
-	return fmt.Sprintf("[%s]", bar)
+// transfer runs the sender side of the FastCP protocol over conn and returns the
+// receiver's completion reply
+func (f *FastcpSendCommand) transfer(ctx context.Context, conn net.Conn, header *fastcpHeader, files []fastcpSourceFile, blockSize int, stats *TransferStats, progress *commands.ProgressReporter) (*fastcpReply, error) {
+	stop := closeOnCancel(ctx, conn)
+	defer stop()
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	writer := bufio.NewWriterSize(conn, blockSize)
+
+	if err := writeFastcpMessage(writer, header); err != nil {
+		return nil, err
+	}
+	if err := writer.Flush(); err != nil {
+		return nil, err
+	}
+
+	var reply fastcpReply
+	if err := readFastcpMessage(reader, &reply); err != nil {
+		return nil, fmt.Errorf("no response from receiver: %w", err)
+	}
+	if !reply.Accepted {
+		return nil, fmt.Errorf("receiver rejected transfer: %s", reply.Error)
+	}
+	if header.Conflict != "" && reply.Conflict != header.Conflict {
+		// An older receiver would silently overwrite what changed on its side
+		return nil, fmt.Errorf("receiver does not support conflict detection")
+	}
+	if header.Archive {
+		// An older receiver would read the tar stream as framed files
+		if !reply.Archive {
+			return nil, fmt.Errorf("receiver does not support archive transfers")
+		}
+		if err := f.sendArchive(writer, header, files, stats, progress); err != nil {
+			return nil, err
+		}
+		return f.awaitCompletion(reader, writer, nil)
+	}
+	if header.Dedup && !reply.Dedup {
+		// An older receiver would read the remaining blocks as whole files
+		return nil, fmt.Errorf("receiver does not support deduplicated transfers")
+	}
+	conflicts := make(map[string]fastcpConflict, len(reply.Conflicts))
+	for _, conflict := range reply.Conflicts {
+		conflicts[conflict.Path] = conflict
+	}
+
+	// Files the receiver already has are left out, data and trailer alike
+	have := make(map[string]bool, len(reply.Have))
+	if header.Delta {
+		for _, path := range reply.Have {
+			have[path] = true
+		}
+	}
+	for _, file := range files {
+		if have[file.entry.Path] {
+			progress.Total -= file.entry.Size
+			progress.TotalFiles--
+		}
+	}
+
+	// Blocks the receiver holds, or got earlier in this transfer, are left out
+	held := make(map[string]bool, len(reply.HaveBlocks))
+	for _, hash := range reply.HaveBlocks {
+		held[hash] = true
+	}
+	sent := make(map[string]bool)
+	if header.Dedup {
+		progress.Total -= plannedReuse(files, have, held).bytes
+	}
+	var reused fastcpReuse
+
+	for _, file := range files {
+		conflict, conflicted := conflicts[file.entry.Path]
+		if have[file.entry.Path] {
+			if conflicted {
+				stats.addConflict(file.entry.Path, file.entry.Size, file.entry.Checksum, conflict)
+				continue
+			}
+			stats.addUnchanged(file.entry.Path, file.entry.Size, file.entry.Checksum)
+			continue
+		}
+		var checksum string
+		var err error
+		if header.Dedup && file.entry.Link == "" {
+			checksum, err = f.sendBlocks(writer, file, held, sent, &reused, progress)
+		} else {
+			checksum, err = f.sendFile(writer, file, progress)
+		}
+		stats.addFile(file.entry.Path, file.entry.Size, checksum, err)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file.entry.Path, err)
+		}
+		if conflicted {
+			stats.markConflict(conflict)
+		}
+		progress.CompleteFile()
+	}
+	stats.BlocksDeduplicated += reused.blocks
+	stats.BytesDeduplicated += reused.bytes
+	return f.awaitCompletion(reader, writer, reply.Conflicts)
+}
+
+// awaitCompletion flushes what is left to send and reads the receiver's
+// completion reply, carrying over the conflicts it found when accepting
+func (f *FastcpSendCommand) awaitCompletion(reader *bufio.Reader, writer *bufio.Writer, conflicts []fastcpConflict) (*fastcpReply, error) {
+	if err := writer.Flush(); err != nil {
+		return nil, err
+	}
+
+	var done fastcpReply
+	if err := readFastcpMessage(reader, &done); err != nil {
+		return nil, fmt.Errorf("receiver did not confirm transfer: %w", err)
+	}
+	if done.Error != "" {
+		return nil, fmt.Errorf("receiver error: %s", done.Error)
+	}
+	done.Conflicts = conflicts
+
+	return &done, nil
+}
+
+// sendFile streams one file followed by its checksum trailer and returns the checksum
+func (f *FastcpSendCommand) sendFile(writer *bufio.Writer, file fastcpSourceFile, progress *commands.ProgressReporter) (string, error) {
+	if file.entry.Link != "" {
+		// The link target travels in the header, so a symlink has no data
+		checksum := hex.EncodeToString(sha256.New().Sum(nil))
+		return checksum, writeFastcpMessage(writer, fastcpFileTrailer{Checksum: checksum})
+	}
+
+	in, err := os.Open(file.local)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+
+	hasher := sha256.New()
+	written, err := io.CopyN(io.MultiWriter(writer, hasher, progress), in, file.entry.Size)
+	if err != nil {
+		if err == io.EOF {
+			return "", fmt.Errorf("file shrank during transfer (%d of %d bytes)", written, file.entry.Size)
+		}
+		return "", err
+	}
+
+	checksum := hex.EncodeToString(hasher.Sum(nil))
+	return checksum, writeFastcpMessage(writer, fastcpFileTrailer{Checksum: checksum})
 }
@@ -0,0 +1,191 @@
+package networking
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+
+	"github.com/fatih/color"
+)
+
+// TransferFileStats is the outcome for a single file in a transfer
+type TransferFileStats struct {
+	Path     string `json:"path"`
+	Bytes    int64  `json:"bytes"`
+	Checksum string `json:"checksum,omitempty"`
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	// SavedAs is the name a file was stored under when it differs from its path
+	SavedAs string `json:"saved_as,omitempty"`
+}
+
+// TransferStats is the machine-readable report written by --stats
+type TransferStats struct {
+	Command             string              `json:"command"`
+	Source              string              `json:"source,omitempty"`
+	Destination         string              `json:"destination,omitempty"`
+	Success             bool                `json:"success"`
+	Error               string              `json:"error,omitempty"`
+	StartedAt           time.Time           `json:"started_at"`
+	FinishedAt          time.Time           `json:"finished_at"`
+	DurationSeconds     float64             `json:"duration_seconds"`
+	FilesTransferred    int                 `json:"files_transferred"`
+	FilesUnchanged      int                 `json:"files_unchanged,omitempty"`
+	Conflicts           int                 `json:"conflicts,omitempty"`
+	FilesRenamed        int                 `json:"files_renamed,omitempty"`
+	FilesExcluded       int                 `json:"files_excluded,omitempty"`
+	DirectoriesExcluded int                 `json:"directories_excluded,omitempty"`
+	BytesSent           int64               `json:"bytes_sent"`
+	BytesReceived       int64               `json:"bytes_received"`
+	ThroughputBps       float64             `json:"throughput_bytes_per_second"`
+	BytesSaved          int64               `json:"bytes_saved"`
+	BlocksDeduplicated  int                 `json:"blocks_deduplicated,omitempty"`
+	BytesDeduplicated   int64               `json:"bytes_deduplicated,omitempty"`
+	Files               []TransferFileStats `json:"files"`
+}
+
+// newTransferStats starts a report for a command run
+func newTransferStats(command string) *TransferStats {
+	return &TransferStats{
+		Command:   command,
+		StartedAt: time.Now(),
+		Files:     []TransferFileStats{},
+	}
+}
+
+// addFile records the outcome of one file
+func (s *TransferStats) addFile(path string, bytes int64, checksum string, err error) {
+	file := TransferFileStats{Path: path, Bytes: bytes, Checksum: checksum, Status: "ok"}
+	if err != nil {
+		file.Status = "failed"
+		file.Error = err.Error()
+	}
+	s.Files = append(s.Files, file)
+}
+
+// addUnchanged records a file a delta transfer skipped because the receiver
+// already had it
+func (s *TransferStats) addUnchanged(path string, bytes int64, checksum string) {
+	s.Files = append(s.Files, TransferFileStats{Path: path, Bytes: bytes, Checksum: checksum, Status: "unchanged"})
+	s.FilesUnchanged++
+	s.BytesSaved += bytes
+}
+
+// addConflict records a file a two-way transfer left out because it changed
+// on the receiver
+func (s *TransferStats) addConflict(path string, bytes int64, checksum string, conflict fastcpConflict) {
+	s.Files = append(s.Files, TransferFileStats{Path: path, Bytes: bytes, Checksum: checksum, Status: conflict.status()})
+	if conflict.Both {
+		s.Conflicts++
+	}
+}
+
+// markConflict notes that the file just recorded replaced or was stored next
+// to one that changed on the receiver
+func (s *TransferStats) markConflict(conflict fastcpConflict) {
+	s.Files[len(s.Files)-1].Status = conflict.status()
+	if conflict.Both {
+		s.Conflicts++
+	}
+}
+
+// finish stamps the end time and derived throughput
+func (s *TransferStats) finish(err error) {
+	s.FinishedAt = time.Now()
+	s.DurationSeconds = s.FinishedAt.Sub(s.StartedAt).Seconds()
+	s.Success = err == nil
+	if err != nil && s.Error == "" {
+		s.Error = err.Error()
+	}
+
+	moved := s.BytesSent
+	if s.BytesReceived > moved {
+		moved = s.BytesReceived
+	}
+	if s.DurationSeconds > 0 {
+		s.ThroughputBps = float64(moved) / s.DurationSeconds
+	}
+}
+
+// fastcpOutputFormats are the structured forms of the transfer report
+var fastcpOutputFormats = []commands.OutputFormat{commands.FormatJSON, commands.FormatCSV}
+
+// withTransferStats finishes stats and writes them to path, the value of --stats,
+// noting the outcome in the result. With structured output the stats replace the
+// human report, as JSON or as one CSV row per file; quiet output keeps a single
+// summary line. Text results without --stats are returned unchanged.
+func withTransferStats(ctx context.Context, path string, stats *TransferStats, result *commands.Result) *commands.Result {
+	options := commands.OutputOptionsFrom(ctx)
+	if result == nil || (path == "" && options.Decorated()) {
+		return result
+	}
+
+	var err error
+	if result.ExitCode != 0 {
+		err = errors.New(transferFailure(result))
+	}
+	stats.finish(err)
+
+	if path != "" {
+		data, writeErr := json.MarshalIndent(stats, "", "  ")
+		if writeErr == nil {
+			if dir := filepath.Dir(path); dir != "." {
+				os.MkdirAll(dir, 0755)
+			}
+			writeErr = ioutil.WriteFile(path, data, 0644)
+		}
+
+		switch {
+		case writeErr != nil && options.Decorated():
+			result.Stderr += color.New(color.FgYellow).Sprintf("⚠️  Failed to write stats to %s: %v\n", path, writeErr)
+		case writeErr != nil:
+			result.Warnings = append(result.Warnings, fmt.Sprintf("failed to write stats to %s: %v", path, writeErr))
+		case options.Decorated():
+			result.Stderr += fmt.Sprintf("📄 Stats written to %s\n", path)
+		}
+	}
+
+	switch {
+	case options.Format == commands.FormatJSON:
+		data, _ := json.MarshalIndent(stats, "", "  ")
+		result.Output = string(data) + "\n"
+	case options.Format == commands.FormatCSV:
+		rows := make([][]string, 0, len(stats.Files))
+		for _, file := range stats.Files {
+			rows = append(rows, []string{file.Path, strconv.FormatInt(file.Bytes, 10), file.Checksum, file.Status, file.Error})
+		}
+		result.Output = commands.FormatCSVRows([]string{"path", "bytes", "checksum", "status", "error"}, rows)
+	case options.Quiet && err != nil:
+		result.Output = err.Error() + "\n"
+	case options.Quiet:
+		moved := stats.BytesSent
+		if stats.BytesReceived > moved {
+			moved = stats.BytesReceived
+		}
+		result.Output = fmt.Sprintf("%d files, %s in %v\n", stats.FilesTransferred, commands.HumanizeBytes(moved),
+			time.Duration(stats.DurationSeconds*float64(time.Second)).Round(time.Millisecond))
+	}
+	return result
+}
+
+// transferFailure describes why a fastcp command failed: its error, else the last
+// line of its output, which is where the commands report what went wrong
+func transferFailure(result *commands.Result) string {
+	if result.Error != nil {
+		return result.Error.Error()
+	}
+	lines := strings.Split(strings.TrimSpace(result.Output), "\n")
+	if last := strings.TrimSpace(lines[len(lines)-1]); last != "" {
+		return strings.TrimPrefix(last, "Error: ")
+	}
+	return fmt.Sprintf("exited with code %d", result.ExitCode)
+}
@@ -0,0 +1,146 @@
+package networking
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"suppercommand/internal/config"
+)
+
+// fastcpLocalBucketScheme marks a bucket that is a directory on a local or mounted disk
+const fastcpLocalBucketScheme = "file://"
+
+// cloudStore is the object storage a backup is written to. Keys are slash-separated.
+type cloudStore interface {
+	PutObject(ctx context.Context, key string, body io.Reader) error
+	GetObject(ctx context.Context, key string) (io.ReadCloser, error)
+	HeadObject(ctx context.Context, key string) (cloudObject, error)
+	ListObjects(ctx context.Context, prefix string) ([]cloudObject, error)
+}
+
+// openCloudStore returns the store for a bucket. Only file:// buckets can be reached
+// directly; S3 buckets report false and are handled by the simulated transfer.
+func openCloudStore(bucket string) (cloudStore, bool) {
+	if !strings.HasPrefix(bucket, fastcpLocalBucketScheme) {
+		return nil, false
+	}
+	return &dirStore{root: config.ExpandHome(strings.TrimPrefix(bucket, fastcpLocalBucketScheme))}, true
+}
+
+// fastcpBucketURL formats a bucket for display, defaulting to the s3:// scheme
+func fastcpBucketURL(bucket string) string {
+	if strings.Contains(bucket, "://") {
+		return strings.TrimRight(bucket, "/")
+	}
+	return "s3://" + bucket
+}
+
+// dirStore keeps objects as files below a root directory
+type dirStore struct {
+	root string
+}
+
+// path maps a key to its file, refusing keys that would leave the root
+func (d *dirStore) path(key string) (string, error) {
+	return safeJoin(d.root, key)
+}
+
+// PutObject writes an object, replacing it only once the whole body has been stored
+func (d *dirStore) PutObject(ctx context.Context, key string, body io.Reader) error {
+	path, err := d.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	temp, err := ioutil.TempFile(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	_, copyErr := io.Copy(temp, body)
+	closeErr := temp.Close()
+	if copyErr == nil {
+		copyErr = closeErr
+	}
+	if copyErr == nil {
+		copyErr = ctx.Err()
+	}
+	if copyErr != nil {
+		os.Remove(temp.Name())
+		return copyErr
+	}
+	return os.Rename(temp.Name(), path)
+}
+
+// GetObject opens an object for reading
+func (d *dirStore) GetObject(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := d.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+// HeadObject returns an object's metadata without reading it
+func (d *dirStore) HeadObject(ctx context.Context, key string) (cloudObject, error) {
+	path, err := d.path(key)
+	if err != nil {
+		return cloudObject{}, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return cloudObject{}, err
+	}
+	if info.IsDir() {
+		return cloudObject{}, &os.PathError{Op: "head", Path: path, Err: os.ErrNotExist}
+	}
+	return cloudObject{Key: key, Size: info.Size(), LastModified: info.ModTime()}, nil
+}
+
+// ListObjects lists the objects whose keys start with prefix, sorted by key
+func (d *dirStore) ListObjects(ctx context.Context, prefix string) ([]cloudObject, error) {
+	// Only walk the directory the prefix points into
+	start := d.root
+	if slash := strings.LastIndex(prefix, "/"); slash >= 0 {
+		start = filepath.Join(d.root, filepath.FromSlash(prefix[:slash]))
+	}
+
+	var objects []cloudObject
+	err := filepath.Walk(start, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == start {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(d.root, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if strings.HasPrefix(key, prefix) && !strings.HasPrefix(filepath.Base(key), ".upload-") {
+			objects = append(objects, cloudObject{Key: key, Size: info.Size(), LastModified: info.ModTime()})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects, nil
+}
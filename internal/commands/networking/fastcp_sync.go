@@ -0,0 +1,218 @@
+package networking
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/commands/filesystem"
+	"suppercommand/internal/security"
+
+	"github.com/fatih/color"
+)
+
+// fastcpSyncRetry is how long fastcp-send --sync waits before sending changes
+// again after the receiver could not be reached
+const fastcpSyncRetry = 5 * time.Second
+
+// fastcpSync keeps a receiver up to date with a source directory once the
+// initial transfer is done. Every burst of changes is sent as a delta transfer
+// of the files it touched, so unchanged content never crosses the wire.
+type fastcpSync struct {
+	command        *FastcpSendCommand
+	source         string
+	address        string
+	includes       []string
+	excludes       []string
+	excludeFrom    []string
+	followSymlinks bool
+	encrypt        bool
+	compress       bool
+	dedup          bool
+	blockSize      int
+	conflict       string
+	debounce       time.Duration
+	stats          *TransferStats
+
+	syncs  int
+	failed int
+	files  int
+	bytes  int64
+}
+
+// run prints the initial report, then sends changes until Enter is pressed or
+// ctx is cancelled and returns the session summary
+func (s *fastcpSync) run(ctx context.Context, output *strings.Builder, startTime time.Time) *commands.Result {
+	live := commands.ProgressWriter(ctx)
+	fmt.Fprint(live, output.String())
+	output.Reset()
+
+	watcher, err := filesystem.NewTreeWatcher(filepath.Clean(s.source), true, nil)
+	if err != nil {
+		output.WriteString(color.New(color.FgRed).Sprintf("❌ Cannot watch %s: %v\n", s.source, err))
+		return &commands.Result{
+			Output:   output.String(),
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}
+	}
+	defer watcher.Close()
+
+	fmt.Fprint(live, color.New(color.FgCyan).Sprintf("👀 Syncing changes in %s to %s (%d directories), press Enter to stop\n", s.source, s.address, watcher.Watched()))
+
+	syncCtx, cancel := security.CancelOnEnter(ctx)
+	defer cancel()
+
+	s.watch(syncCtx, watcher)
+
+	summary := fmt.Sprintf("🔄 Synced %s to %s for %v: %d syncs, %d files, %s sent",
+		s.source, s.address, time.Since(startTime).Round(time.Second), s.syncs, s.files, commands.HumanizeBytes(s.bytes))
+	if s.failed > 0 {
+		summary += fmt.Sprintf(" (%d failed)", s.failed)
+	}
+	output.WriteString(color.New(color.FgHiBlack).Sprint(summary + "\n"))
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}
+}
+
+// watch collects changed paths until ctx is done, sending them once changes
+// have settled for the debounce time. Changes that could not be sent are kept
+// and tried again later.
+func (s *fastcpSync) watch(ctx context.Context, watcher *filesystem.TreeWatcher) {
+	live := commands.ProgressWriter(ctx)
+	settle := time.NewTimer(s.debounce)
+	settle.Stop()
+	var settled <-chan time.Time
+	pending := make(map[string]bool)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case err := <-watcher.Errors:
+			fmt.Fprint(live, color.New(color.FgYellow).Sprintf("⚠️  %v\n", err))
+		case event := <-watcher.Events:
+			// Deletions stay on the receiver; a file replaced by a rename shows
+			// up as created under its new name
+			if event.Op == filesystem.WatchChmod || event.Op == filesystem.WatchRemove || event.Op == filesystem.WatchRename {
+				continue
+			}
+			pending[event.Path] = true
+			if settled != nil && !settle.Stop() {
+				<-settle.C
+			}
+			settle.Reset(s.debounce)
+			settled = settle.C
+		case <-settled:
+			settled = nil
+			if !s.send(ctx, pending) {
+				pending = make(map[string]bool)
+				continue
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			settle.Reset(fastcpSyncRetry)
+			settled = settle.C
+		}
+	}
+}
+
+// send transfers the files at or below the changed paths, reporting whether
+// they should be tried again
+func (s *fastcpSync) send(ctx context.Context, changed map[string]bool) bool {
+	live := commands.ProgressWriter(ctx)
+	startTime := time.Now()
+	warn := func(format string, args ...interface{}) {
+		fmt.Fprint(live, color.New(color.FgYellow).Sprintf("⚠️  %s %s\n", startTime.Format("15:04:05"), fmt.Sprintf(format, args...)))
+	}
+
+	filter, err := newFastcpFilter(s.includes, s.excludes, s.excludeFrom)
+	if err != nil {
+		warn("cannot read exclude file: %v", err)
+		return false
+	}
+	all, _, err := collectFastcpFiles(s.source, filter, s.followSymlinks)
+	if err != nil {
+		warn("%v", err)
+		return false
+	}
+	var files []fastcpSourceFile
+	for _, file := range all {
+		if fastcpChanged(file.local, changed) {
+			files = append(files, file)
+		}
+	}
+	if len(files) == 0 {
+		return false
+	}
+
+	header, err := newFastcpHeader(files, s.encrypt, s.compress, true)
+	if err == nil && s.conflict != "" {
+		err = prepareFastcpTwoWay(header, files, s.source, s.conflict)
+	}
+	if err == nil && s.dedup {
+		err = addFastcpBlocks(header, files)
+	}
+	if err != nil {
+		// Most likely a file still being written or replaced
+		warn("%v, retrying in %v", err, fastcpSyncRetry)
+		return true
+	}
+	conn, err := dialFastcp(ctx, s.address)
+	if err != nil {
+		if ctx.Err() != nil {
+			return false
+		}
+		s.failed++
+		warn("cannot reach %s: %v, retrying in %v", s.address, err, fastcpSyncRetry)
+		return true
+	}
+
+	unchanged := s.stats.FilesUnchanged
+	progress := commands.NewProgressReporter(header.TotalSize, len(files), nil)
+	reply, err := s.command.transfer(ctx, conn, header, files, s.blockSize, s.stats, progress)
+	if err != nil {
+		if ctx.Err() != nil {
+			return false
+		}
+		s.failed++
+		warn("sync failed: %v, retrying in %v", err, fastcpSyncRetry)
+		return true
+	}
+
+	s.syncs++
+	s.files += reply.Files
+	s.bytes += reply.Bytes
+	s.stats.FilesTransferred += reply.Files
+	s.stats.BytesSent += reply.Bytes
+	fmt.Fprintf(live, "🔄 %s sent %d files, %s in %v%s\n", startTime.Format("15:04:05"),
+		reply.Files, commands.HumanizeBytes(reply.Bytes), time.Since(startTime).Round(time.Millisecond),
+		fastcpDeltaNote(s.stats.FilesUnchanged-unchanged, countFastcpConflicts(reply.Conflicts)))
+	var conflicts strings.Builder
+	writeFastcpConflicts(reply.Conflicts, &conflicts)
+	fmt.Fprint(live, conflicts.String())
+	if s.conflict != "" {
+		if err := recordFastcpSent(s.source, header, reply); err != nil {
+			warn("sync state not saved, the next transfer may report false conflicts: %v", err)
+		}
+	}
+	return false
+}
+
+// fastcpChanged reports whether local is one of the changed paths or inside a
+// changed directory
+func fastcpChanged(local string, changed map[string]bool) bool {
+	for path := range changed {
+		if local == path || strings.HasPrefix(local, path+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,278 @@
+package networking
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+
+	"github.com/fatih/color"
+)
+
+// FastcpVerifyCommand checks a backup in a bucket against its manifest
+type FastcpVerifyCommand struct {
+	*commands.BaseCommand
+	flags *commands.FlagSet
+}
+
+// NewFastcpVerifyCommand creates a new fastcp-verify command
+func NewFastcpVerifyCommand() *FastcpVerifyCommand {
+	usage := "fastcp-verify <bucket> <backup-id> [--deep] [--stats <file>] [--quiet] [--output-format text|json|csv]"
+	return &FastcpVerifyCommand{
+		BaseCommand: commands.NewBaseCommand(
+			"fastcp-verify",
+			"Verify a backup in cloud storage against its manifest",
+			usage,
+			[]string{"windows", "linux", "darwin"},
+			false,
+		),
+		flags: commands.NewFlagSet("fastcp-verify", usage,
+			commands.FlagSpec{Name: "deep", Help: "Download every object and check its SHA-256"},
+			fastcpStatsFlag,
+		),
+	}
+}
+
+// OutputFormats returns the structured formats of the transfer report
+func (f *FastcpVerifyCommand) OutputFormats() []commands.OutputFormat {
+	return fastcpOutputFormats
+}
+
+// FlagSet returns the flags fastcp-verify accepts
+func (f *FastcpVerifyCommand) FlagSet() *commands.FlagSet {
+	return f.flags
+}
+
+// Execute verifies a backup
+func (f *FastcpVerifyCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	flags, usage := f.flags.ParseArguments(args, time.Now())
+	if usage != nil {
+		return usage, nil
+	}
+	stats := newTransferStats(f.Name())
+	result, err := f.execute(ctx, flags, stats)
+	return withTransferStats(ctx, flags.String("stats"), stats, result), err
+}
+
+// execute performs the verification, recording each object in stats
+func (f *FastcpVerifyCommand) execute(ctx context.Context, flags *commands.ParsedFlags, stats *TransferStats) (*commands.Result, error) {
+	startTime := time.Now()
+
+	if len(flags.Args()) < 2 {
+		return &commands.Result{
+			Output:   "Usage: " + f.Usage() + "\n",
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}, nil
+	}
+
+	bucket := flags.Arg(0)
+	backupID := flags.Arg(1)
+	deep := flags.Bool("deep")
+	stats.Source = fastcpBucketURL(bucket) + "/" + backupID
+
+	var output strings.Builder
+
+	output.WriteString(color.New(color.FgCyan, color.Bold).Sprint("🔍 FASTCP BACKUP VERIFY\n"))
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+	output.WriteString(fmt.Sprintf("🪣 Bucket:       %s\n", color.New(color.FgBlue).Sprint(fastcpBucketURL(bucket))))
+	output.WriteString(fmt.Sprintf("🆔 Backup ID:    %s\n", color.New(color.FgYellow).Sprint(backupID)))
+	output.WriteString(fmt.Sprintf("🔬 Mode:         %s\n",
+		map[bool]string{true: "Deep (download and check SHA-256)", false: "Quick (existence and size)"}[deep]))
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+
+	store, ok := openCloudStore(bucket)
+	if !ok {
+		output.WriteString(color.New(color.FgRed).Sprint("❌ Verifying needs direct access to the bucket, which is only available for file:// buckets\n"))
+		return &commands.Result{
+			Output:   output.String(),
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}, nil
+	}
+
+	output.WriteString("📄 Reading backup manifest...\n")
+	manifest, err := f.readManifest(ctx, store, backupID)
+	if err != nil {
+		output.WriteString(color.New(color.FgRed).Sprintf("❌ %v\n", err))
+		return &commands.Result{
+			Output:   output.String(),
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}, nil
+	}
+
+	names := make([]string, 0, len(manifest.Objects))
+	var expectedSize int64
+	for name, entry := range manifest.Objects {
+		names = append(names, name)
+		expectedSize += entry.Size
+	}
+	sort.Strings(names)
+	output.WriteString(fmt.Sprintf("✅ Manifest lists %d objects (%s)\n", len(names), commands.HumanizeBytes(expectedSize)))
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+
+	var missing, corrupted, extra []string
+	verified := 0
+	expected := map[string]bool{backupID + "/" + fastcpManifestObject: true}
+
+	liveProgress := io.Writer(nil)
+	if deep {
+		liveProgress = commands.ProgressWriter(ctx)
+	}
+	progress := commands.NewProgressReporter(expectedSize, len(names), liveProgress)
+	var checked int64
+
+	for _, name := range names {
+		entry := manifest.Objects[name]
+		key := manifest.objectKey(name)
+		expected[key] = true
+
+		if !entry.Uploaded {
+			missing = append(missing, key)
+			stats.addFile(key, entry.Size, "", fmt.Errorf("never uploaded"))
+			output.WriteString(color.New(color.FgRed).Sprintf("❌ Missing:    %s (never uploaded)\n", key))
+			continue
+		}
+
+		checksum, problem, err := f.verifyObject(ctx, store, key, entry, deep)
+		if ctx.Err() != nil {
+			progress.Finish()
+			return fastcpCancelled(&output, startTime, ctx.Err()), nil
+		}
+		switch {
+		case os.IsNotExist(err):
+			missing = append(missing, key)
+			output.WriteString(color.New(color.FgRed).Sprintf("❌ Missing:    %s\n", key))
+		case err != nil:
+			missing = append(missing, key)
+			output.WriteString(color.New(color.FgRed).Sprintf("❌ Missing:    %s (%v)\n", key, err))
+		case problem != "":
+			corrupted = append(corrupted, key)
+			err = fmt.Errorf("%s", problem)
+			output.WriteString(color.New(color.FgRed).Sprintf("💥 Corrupted:  %s (%s)\n", key, problem))
+		default:
+			verified++
+		}
+		stats.addFile(key, entry.Size, checksum, err)
+
+		checked += entry.Size
+		progress.Update(checked, verified+len(missing)+len(corrupted))
+	}
+	if deep {
+		output.WriteString(progress.Finish())
+		stats.BytesReceived = checked
+	}
+
+	// Anything else under the backup's prefix was never recorded in the manifest
+	objects, listErr := store.ListObjects(ctx, backupID+"/")
+	if listErr != nil {
+		output.WriteString(color.New(color.FgRed).Sprintf("❌ Could not list the backup's objects: %v\n", listErr))
+	}
+	for _, object := range objects {
+		if !expected[object.Key] {
+			extra = append(extra, object.Key)
+			output.WriteString(color.New(color.FgYellow).Sprintf("➕ Extra:      %s (%s)\n", object.Key, commands.HumanizeBytes(object.Size)))
+		}
+	}
+
+	stats.FilesTransferred = verified
+	passed := len(missing) == 0 && len(corrupted) == 0 && len(extra) == 0 && listErr == nil
+
+	if len(missing)+len(corrupted)+len(extra) > 0 {
+		output.WriteString("───────────────────────────────────────────────────────────────\n")
+	}
+	if passed {
+		output.WriteString(color.New(color.FgGreen, color.Bold).Sprint("✅ VERIFICATION PASSED\n"))
+	} else {
+		output.WriteString(color.New(color.FgRed, color.Bold).Sprint("❌ VERIFICATION FAILED\n"))
+	}
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+	output.WriteString(fmt.Sprintf("📊 Verified:     %d\n", verified))
+	output.WriteString(fmt.Sprintf("❌ Missing:      %d\n", len(missing)))
+	output.WriteString(fmt.Sprintf("💥 Corrupted:    %d\n", len(corrupted)))
+	output.WriteString(fmt.Sprintf("➕ Extra:        %d\n", len(extra)))
+	output.WriteString(fmt.Sprintf("⏱️  Duration:     %v\n", time.Since(startTime).Round(time.Millisecond)))
+	if passed {
+		output.WriteString(fmt.Sprintf("💡 Backup can be restored with: fastcp-restore %s %s <destination>\n", bucket, backupID))
+	}
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+
+	exitCode := 0
+	if !passed {
+		exitCode = 1
+	}
+
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: exitCode,
+		Duration: time.Since(startTime),
+	}, nil
+}
+
+// readManifest downloads and decodes the manifest stored with a backup
+func (f *FastcpVerifyCommand) readManifest(ctx context.Context, store cloudStore, backupID string) (*fastcpBackupManifest, error) {
+	body, err := store.GetObject(ctx, backupID+"/"+fastcpManifestObject)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no manifest found for backup '%s'", backupID)
+		}
+		return nil, err
+	}
+	defer body.Close()
+
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest fastcpBackupManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("corrupt manifest for backup '%s': %w", backupID, err)
+	}
+	if manifest.Objects == nil {
+		manifest.Objects = make(map[string]*fastcpManifestEntry)
+	}
+	return &manifest, nil
+}
+
+// verifyObject checks one object against its manifest entry. It returns the checksum
+// computed in deep mode and a description of any mismatch; err is set when the object
+// can't be examined at all.
+func (f *FastcpVerifyCommand) verifyObject(ctx context.Context, store cloudStore, key string, entry *fastcpManifestEntry, deep bool) (string, string, error) {
+	object, err := store.HeadObject(ctx, key)
+	if err != nil {
+		return "", "", err
+	}
+	if object.Size != entry.Size {
+		return "", fmt.Sprintf("size %s, expected %s", commands.HumanizeBytes(object.Size), commands.HumanizeBytes(entry.Size)), nil
+	}
+	if !deep {
+		return "", "", nil
+	}
+
+	body, err := store.GetObject(ctx, key)
+	if err != nil {
+		return "", "", err
+	}
+	defer body.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, body); err != nil {
+		return "", "", err
+	}
+	checksum := hex.EncodeToString(hasher.Sum(nil))
+	if checksum != entry.Checksum {
+		return checksum, "checksum mismatch", nil
+	}
+	return checksum, "", nil
+}
@@ -0,0 +1,155 @@
+package networking
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// fastcpWalker collects the files under a directory source. Symlinks are recorded as
+// links unless followSymlinks is set, in which case their targets are sent instead and
+// every real directory is entered at most once, so link loops cannot recurse forever.
+type fastcpWalker struct {
+	base           string
+	filter         *fastcpFilter
+	followSymlinks bool
+	visited        map[string]bool
+	loopsSkipped   int
+	files          []fastcpSourceFile
+}
+
+// collectFastcpFiles lists the files under source. Directories are sent under their
+// own name so the receiver recreates them inside its destination. A non-nil filter
+// prunes the walk and counts what it leaves out. The source itself is always
+// dereferenced; the returned count is how many already-visited directories reached
+// through followed symlinks were skipped.
+func collectFastcpFiles(source string, filter *fastcpFilter, followSymlinks bool) ([]fastcpSourceFile, int, error) {
+	sourceInfo, err := os.Stat(source)
+	if err != nil {
+		return nil, 0, fmt.Errorf("source not found: %s", source)
+	}
+
+	if !sourceInfo.IsDir() {
+		return []fastcpSourceFile{{
+			local: source,
+			entry: fastcpFileEntry{Path: filepath.Base(source), Size: sourceInfo.Size()},
+		}}, 0, nil
+	}
+
+	root := filepath.Clean(source)
+	walker := &fastcpWalker{
+		base:           filepath.Base(root),
+		filter:         filter,
+		followSymlinks: followSymlinks,
+		visited:        make(map[string]bool),
+	}
+	if err := walker.enterDir(root, ""); err != nil {
+		return nil, 0, err
+	}
+
+	if len(walker.files) == 0 {
+		if filter.active() {
+			return nil, 0, fmt.Errorf("no files to send in %s after applying filters", source)
+		}
+		return nil, 0, fmt.Errorf("no files to send in %s", source)
+	}
+
+	return walker.files, walker.loopsSkipped, nil
+}
+
+// enterDir walks a directory unless its real location was already walked
+func (w *fastcpWalker) enterDir(dir, rel string) error {
+	if w.followSymlinks {
+		real, err := filepath.EvalSymlinks(dir)
+		if err != nil {
+			return err
+		}
+		if real, err = filepath.Abs(real); err != nil {
+			return err
+		}
+		if w.visited[real] {
+			w.loopsSkipped++
+			return nil
+		}
+		w.visited[real] = true
+	}
+
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, info := range entries {
+		local := filepath.Join(dir, info.Name())
+		childRel := path.Join(rel, info.Name())
+
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			err = w.addSymlink(local, childRel)
+		case info.IsDir():
+			if w.filter.selectDir(childRel) {
+				err = w.enterDir(local, childRel)
+			}
+		case info.Mode().IsRegular():
+			if w.filter.selectFile(childRel) {
+				w.addFile(local, childRel, info.Size(), "")
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addSymlink follows a link when asked to, otherwise records the link itself. Broken
+// links and links to special files are always recorded as links.
+func (w *fastcpWalker) addSymlink(local, rel string) error {
+	if w.followSymlinks {
+		if info, err := os.Stat(local); err == nil {
+			if info.IsDir() {
+				if !w.filter.selectDir(rel) {
+					return nil
+				}
+				return w.enterDir(local, rel)
+			}
+			if info.Mode().IsRegular() {
+				if w.filter.selectFile(rel) {
+					w.addFile(local, rel, info.Size(), "")
+				}
+				return nil
+			}
+		}
+	}
+
+	if !w.filter.selectFile(rel) {
+		return nil
+	}
+	target, err := os.Readlink(local)
+	if err != nil {
+		return err
+	}
+	w.addFile(local, rel, 0, filepath.ToSlash(target))
+	return nil
+}
+
+// addFile records a file, or a symlink when link is set, under the source's name
+func (w *fastcpWalker) addFile(local, rel string, size int64, link string) {
+	w.files = append(w.files, fastcpSourceFile{
+		local: local,
+		entry: fastcpFileEntry{Path: path.Join(w.base, rel), Size: size, Link: link},
+	})
+}
+
+// countFastcpLinks counts the entries sent as symlinks
+func countFastcpLinks(files []fastcpSourceFile) int {
+	links := 0
+	for _, file := range files {
+		if file.entry.Link != "" {
+			links++
+		}
+	}
+	return links
+}
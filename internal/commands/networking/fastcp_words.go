@@ -0,0 +1,97 @@
+package networking
+
+import "strings"
+
+// fastcpWords is the list fastcp-key --words draws from: 1024 short, common
+// words that are easy to read out and type, so each word adds 10 bits
+var fastcpWords = strings.Fields(`
+able acid acorn acre actor adapt adobe agent agile aglow agree ahead aim
+air aisle alarm album alert algae alien alley allow almond aloe alpha
+alps amber amble amend ample amuse anchor angel angle ankle annex anvil
+apex apple april apron aqua arbor arch arctic arena arise armor army
+aroma arrow art ascot ash aspen atlas atom attic audio august aunt aura
+autumn avenue avid awake award axis axle azure bacon badge bagel baker
+balmy bamboo banjo barge barn baron basalt basil basin basket batch bath
+beach beacon bead beagle beam bean bear beaver bed beech beef beetle
+begin bell belt bench berry bevel bike birch bird bison blade blank
+blaze blend bliss block bloom blossom blue blush board boat bold bolt
+bonus book boost boot border botany bottle boulder bounce bowl brave
+bread breeze brick bridge brief bright brisk brook broom brush bubble
+bucket buckle buddy budget buffalo bugle bulb bundle bunny burrow butter
+button buzz cabin cable cactus cadet cake calm camel camera camp canal
+candle candy canoe canopy canvas canyon cape caper carbon cargo carpet
+carrot cart carve cash castle cedar celery cello cement chalk chapel
+charm chart cheek cheer cherry chess chest chili chime chip chord cider
+cinema circle citrus city civic clam clap clasp claw clay clerk cliff
+climb clock cloud clover club coast cobalt cocoa coconut code coffee
+coin comet comic compass cone coral cord corn cottage cotton couch cove
+cozy crab cradle craft crane crayon creek crest cricket crisp crown
+crumb crystal cube cuckoo cupcake curl curtain cushion cycle cymbal
+daisy dance dart dash dawn deck decor deer delta denim depot desert desk
+dew dial diary diesel dingo dinner disco dish dock dolphin domain donkey
+donut door dove dragon drama drawer dream drift drill drum duck dune
+dusk dust eagle earth easel echo eclipse edge eel elbow elder elk elm
+ember emerald empire enamel energy engine envoy epic equal escape estate
+evening exact expert fable fabric falcon fame fancy farm fawn feast
+feather fence fennel fern ferret ferry fiber fiddle field fig film finch
+fir fjord flag flame flask fleet flint flock flora flour flute foam
+focus fog folk forest forge fork fossil fox frame fresh frost fruit
+fudge fuel fungi funnel gable galaxy gale garden garlic garnet gate
+gauge gazebo gecko gem genius ghost giant ginger glacier glade glass
+glide globe glove glow gnome goat gold golf goose gopher gorge gourd
+grain granite grape graph grass gravel gravy green grill grove guide
+guitar gull gust habit hail hammer hamster harbor harp harvest hatch
+haven hawk hazel heart hedge helium helmet herb heron hill hinge hippo
+hobby hockey honey hood hook hope horizon horn horse hotel hound hub
+hull humble husky hyena hymn ice icicle icon idea igloo iguana image
+inch index ink inlet insect iris iron island ivory ivy jacket jade
+jaguar jam jar jasmine jazz jeans jelly jersey jewel jigsaw jockey jolly
+journal joy judge juice jumbo jungle juniper jury kale kayak kazoo
+kernel kettle key kiln kilt kimono kind king kiosk kite kitten kiwi
+knack knee knight knob knot koala label lace ladder lady lagoon lake
+lamb lamp lance lantern lapel laser lasso latch lava lawn layer leaf
+ledge lemon lens lentil level lever lilac lily lime linen lion liquid
+lizard llama lobby lobster locket lodge loft logic loom lotus lucky
+lumber lunar lunch lute lynx lyric macro magic magnet magpie mango maple
+marble march margin marmot marsh mason meadow medal melody melon mentor
+menu merit mesa metal meteor mill mimic mint minute mirror mist mitten
+moat model mohair monkey month moose moral morning mosaic moss motor
+mound mouse mouth mule mural museum music mustard myth nacho napkin
+narrow native nature navy nebula nectar needle nest nettle newt nickel
+night ninja noble noodle nook north notch novel nugget number nutmeg
+nylon oak oar oasis oat ocean ocelot octave office olive omega onion
+onyx opal opera orange orbit orchard orchid organ otter ounce outfit
+oval oven owl oxygen oyster ozone paddle pagoda paint palace palm panda
+panel panther paper parade parcel park parrot pasta pastel patch path
+patio pavilion peach peak peanut pear pebble pecan pedal pelican pencil
+penny pepper perch petal piano picnic pigeon pillow pilot pine pink
+pioneer pipe pirate pixel pizza plain planet plank plaza plum plume
+pocket poem polar pole pollen pond pony poppy porch portal potato pouch
+powder prairie prism prize pulse pumpkin puppet puzzle pyramid quail
+quake quart quartz queen quest quick quiet quill quilt quince quiver
+quota rabbit raccoon radar radio radish raft rafter rain ramp ranch
+range raven razor recipe reef reptile rescue ribbon rice ridge ring
+ripple river road robin robot rocket rodeo roof rookie rose rotor route
+rover ruby rudder rug ruler rumba runway rustic saddle safari saffron
+saga sage sail salad salmon salt sample sand sandal sapphire satin sauce
+sausage savvy scarf scene school scout screen scroll seal season seed
+shadow shark shelf shell shield shore shrimp sierra signal silk silver
+siren skate sketch ski sky slate sled slope sloth smile smoke snail
+snake snow soap soccer socket sofa solar sonar sonnet soup spade spark
+sphere spice spider spiral spoon spring sprout spruce squid stable
+stadium stage stamp star statue steam steel stem stone storm story stove
+straw stream string studio sugar summer summit sun sunset swamp swan
+sweater swift syrup table tablet taco talent tango tank tapir target
+tavern taxi teacup teapot temple tennis tent thistle thorn thread
+thunder thyme ticket tide tiger timber tinsel toast token tomato tonic
+topaz torch tornado toucan tower toy track tractor trail train tree
+trend trial tribe trophy trout truck tulip tuna tundra tunnel turkey
+turtle tuxedo twig twin umbrella uncle unicorn union unit urban utopia
+vacuum valley valve vapor vase vault velvet vendor venture verse vessel
+vest video villa vine violet violin visor vista vivid voice volcano vole
+voyage wafer wagon walnut walrus wand warm wasp watch water wave wax
+weasel weather wedge whale wheat wheel whisk whistle widget willow
+window winter wizard wolf wombat wonder wood wool world wreath wrist
+yacht yak yam yard yarn year yeast yellow yield yodel yogurt yolk young
+yoyo zebra zenith zephyr zero zest zigzag zinc zipper zodiac zone zoom
+`)
@@ -0,0 +1,321 @@
+package networking
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+
+	"github.com/fatih/color"
+)
+
+// NetInterface is a network interface as the capture and monitoring commands
+// show and select it
+type NetInterface struct {
+	Index        int      `json:"index"`
+	Name         string   `json:"name"`
+	Description  string   `json:"description"`
+	HardwareAddr string   `json:"mac,omitempty"`
+	MTU          int      `json:"mtu"`
+	Up           bool     `json:"up"`
+	Loopback     bool     `json:"loopback"`
+	Addresses    []string `json:"addresses"`
+}
+
+// State returns "up" or "down"
+func (i NetInterface) State() string {
+	if i.Up {
+		return "up"
+	}
+	return "down"
+}
+
+// IPv4Network returns the first IPv4 network the interface is on
+func (i NetInterface) IPv4Network() (*net.IPNet, bool) {
+	for _, addr := range i.Addresses {
+		ip, network, err := net.ParseCIDR(addr)
+		if err == nil && ip.To4() != nil {
+			return network, true
+		}
+	}
+	return nil, false
+}
+
+// hasAddress reports whether ip is one of the interface's addresses
+func (i NetInterface) hasAddress(ip net.IP) bool {
+	for _, addr := range i.Addresses {
+		if own, _, err := net.ParseCIDR(addr); err == nil && own.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ListInterfaces returns the system's network interfaces ordered by index
+func ListInterfaces() ([]NetInterface, error) {
+	system, err := net.Interfaces()
+	if err != nil {
+		return nil, fmt.Errorf("cannot list network interfaces: %w", err)
+	}
+	interfaces := make([]NetInterface, 0, len(system))
+	for _, iface := range system {
+		entry := NetInterface{
+			Index:       iface.Index,
+			Name:        iface.Name,
+			Description: describeInterface(iface),
+			MTU:         iface.MTU,
+			Up:          iface.Flags&net.FlagUp != 0,
+			Loopback:    iface.Flags&net.FlagLoopback != 0,
+			Addresses:   []string{},
+		}
+		if iface.HardwareAddr != nil {
+			entry.HardwareAddr = iface.HardwareAddr.String()
+		}
+		if addrs, err := iface.Addrs(); err == nil {
+			for _, addr := range addrs {
+				entry.Addresses = append(entry.Addresses, addr.String())
+			}
+		}
+		interfaces = append(interfaces, entry)
+	}
+	sort.Slice(interfaces, func(a, b int) bool { return interfaces[a].Index < interfaces[b].Index })
+	return interfaces, nil
+}
+
+// ResolveInterface finds the interface a user means by its index, its name or
+// one of its addresses
+func ResolveInterface(token string) (NetInterface, error) {
+	interfaces, err := ListInterfaces()
+	if err != nil {
+		return NetInterface{}, err
+	}
+	return resolveInterface(interfaces, token)
+}
+
+// resolveInterface picks the interface token names out of interfaces. A name
+// wins over an index, so an interface called "2" can still be chosen.
+func resolveInterface(interfaces []NetInterface, token string) (NetInterface, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return NetInterface{}, fmt.Errorf("no interface given")
+	}
+	for _, iface := range interfaces {
+		if iface.Name == token {
+			return iface, nil
+		}
+	}
+	for _, iface := range interfaces {
+		if strings.EqualFold(iface.Name, token) {
+			return iface, nil
+		}
+	}
+	if index, err := strconv.Atoi(token); err == nil {
+		for _, iface := range interfaces {
+			if iface.Index == index {
+				return iface, nil
+			}
+		}
+		return NetInterface{}, fmt.Errorf("no interface has index %d; %s", index, interfaceChoices(interfaces))
+	}
+	if ip := net.ParseIP(token); ip != nil {
+		for _, iface := range interfaces {
+			if iface.hasAddress(ip) {
+				return iface, nil
+			}
+		}
+		return NetInterface{}, fmt.Errorf("no interface has the address %s; %s", ip, interfaceChoices(interfaces))
+	}
+	return NetInterface{}, fmt.Errorf("no interface named %q; %s", token, interfaceChoices(interfaces))
+}
+
+// DefaultInterface returns the first interface that is up and not loopback,
+// falling back to loopback when nothing else is up
+func DefaultInterface() (NetInterface, error) {
+	interfaces, err := ListInterfaces()
+	if err != nil {
+		return NetInterface{}, err
+	}
+	var loopback *NetInterface
+	for i, iface := range interfaces {
+		if !iface.Up {
+			continue
+		}
+		if !iface.Loopback {
+			return iface, nil
+		}
+		if loopback == nil {
+			loopback = &interfaces[i]
+		}
+	}
+	if loopback != nil {
+		return *loopback, nil
+	}
+	return NetInterface{}, fmt.Errorf("no network interface is up")
+}
+
+// interfaceChoices names the interfaces there are to choose from
+func interfaceChoices(interfaces []NetInterface) string {
+	if len(interfaces) == 0 {
+		return "there are no network interfaces"
+	}
+	names := make([]string, 0, len(interfaces))
+	for _, iface := range interfaces {
+		names = append(names, fmt.Sprintf("%d %s", iface.Index, iface.Name))
+	}
+	return "available: " + strings.Join(names, ", ") + " (run 'interfaces' for details)"
+}
+
+// describeInterface guesses what kind of interface it is from its flags and
+// the naming conventions of the platforms
+func describeInterface(iface net.Interface) string {
+	name := strings.ToLower(iface.Name)
+	switch {
+	case iface.Flags&net.FlagLoopback != 0:
+		return "Loopback"
+	case strings.HasPrefix(name, "wl") || strings.Contains(name, "wi-fi") || strings.Contains(name, "wireless"):
+		return "Wireless"
+	case strings.HasPrefix(name, "docker") || strings.HasPrefix(name, "br") || strings.HasPrefix(name, "virbr") || strings.HasPrefix(name, "bridge"):
+		return "Bridge"
+	case strings.HasPrefix(name, "veth") || strings.HasPrefix(name, "vmnet") || strings.HasPrefix(name, "vethernet"):
+		return "Virtual Ethernet"
+	case strings.HasPrefix(name, "tun") || strings.HasPrefix(name, "tap") || strings.HasPrefix(name, "utun") || strings.HasPrefix(name, "wg") || iface.Flags&net.FlagPointToPoint != 0:
+		return "Tunnel"
+	case len(iface.HardwareAddr) > 0:
+		return "Ethernet"
+	default:
+		return "Other"
+	}
+}
+
+// InterfacesCommand lists the interfaces sniff, replay, mtu and netdiscover
+// can work on
+type InterfacesCommand struct {
+	*commands.BaseCommand
+	flags *commands.FlagSet
+}
+
+// NewInterfacesCommand creates an interfaces command
+func NewInterfacesCommand() *InterfacesCommand {
+	usage := "interfaces [index|name|address] [--up] [--json]"
+	return &InterfacesCommand{
+		BaseCommand: commands.NewBaseCommand(
+			"interfaces",
+			"List network interfaces with their index, addresses and state",
+			usage,
+			[]string{"windows", "linux", "darwin"},
+			false,
+		),
+		flags: commands.NewFlagSet("interfaces", usage,
+			commands.FlagSpec{Name: "up", Help: "Only list interfaces that are up"},
+			commands.FlagSpec{Name: "json", Help: "Print the interfaces as JSON"},
+		),
+	}
+}
+
+// FlagSet returns the options interfaces accepts
+func (c *InterfacesCommand) FlagSet() *commands.FlagSet {
+	return c.flags
+}
+
+// Execute lists the interfaces, or the one a token resolves to
+func (c *InterfacesCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	startTime := time.Now()
+
+	flags, result := c.flags.ParseArguments(args, startTime)
+	if result != nil {
+		return result, nil
+	}
+	if len(flags.Args()) > 1 {
+		return &commands.Result{
+			Output:   "Usage: " + c.Usage() + "\n",
+			Error:    commands.UsageError(c.Name(), "expected at most one interface"),
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}, nil
+	}
+
+	interfaces, err := ListInterfaces()
+	if err != nil {
+		return commands.ErrorResult("", err, startTime), nil
+	}
+	if token := flags.Arg(0); token != "" {
+		iface, err := resolveInterface(interfaces, token)
+		if err != nil {
+			return commands.ErrorResult("", err, startTime), nil
+		}
+		interfaces = []NetInterface{iface}
+	} else if flags.Bool("up") {
+		var up []NetInterface
+		for _, iface := range interfaces {
+			if iface.Up {
+				up = append(up, iface)
+			}
+		}
+		interfaces = up
+	}
+
+	if flags.Bool("json") {
+		if interfaces == nil {
+			interfaces = []NetInterface{}
+		}
+		data, err := json.MarshalIndent(interfaces, "", "  ")
+		if err != nil {
+			return commands.ErrorResult("", err, startTime), nil
+		}
+		return &commands.Result{
+			Output:   string(data) + "\n",
+			ExitCode: 0,
+			Duration: time.Since(startTime),
+		}, nil
+	}
+
+	var output strings.Builder
+	output.WriteString(color.New(color.FgCyan, color.Bold).Sprint("🔌 NETWORK INTERFACES\n"))
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+	output.WriteString(color.New(color.Bold).Sprintf("%-6s %-16s %-6s %-6s %-18s %-17s %s\n",
+		"Index", "Name", "State", "MTU", "Type", "MAC", "Addresses"))
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+	up := 0
+	for _, iface := range interfaces {
+		state := color.New(color.FgRed).Sprintf("%-6s", iface.State())
+		if iface.Up {
+			state = color.New(color.FgGreen).Sprintf("%-6s", iface.State())
+			up++
+		}
+		mac := iface.HardwareAddr
+		if mac == "" {
+			mac = "-"
+		}
+		addresses := "-"
+		if len(iface.Addresses) > 0 {
+			addresses = strings.Join(iface.Addresses, ", ")
+		}
+		output.WriteString(fmt.Sprintf("%-6d %-16s %s %-6d %-18s %-17s %s\n",
+			iface.Index,
+			color.New(color.FgBlue).Sprintf("%-16s", iface.Name),
+			state,
+			iface.MTU,
+			iface.Description,
+			color.New(color.FgMagenta).Sprintf("%-17s", mac),
+			addresses))
+	}
+	if len(interfaces) == 0 {
+		output.WriteString(color.New(color.FgYellow).Sprint("⚠️  No interfaces to show\n"))
+	}
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+	output.WriteString(fmt.Sprintf("📊 %d interfaces, %d up\n", len(interfaces), up))
+	output.WriteString("💡 sniff, replay, mtu and netdiscover take an interface by index, name or address\n")
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}, nil
+}
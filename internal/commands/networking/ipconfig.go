@@ -5,7 +5,6 @@ import (
 	"context"
 	"fmt"
 	"net"
-	"os/exec"
 	"runtime"
 	"strings"
 	"time"
@@ -18,6 +17,7 @@ import (
 // IpconfigCommand shows network interface configuration
 type IpconfigCommand struct {
 	*commands.BaseCommand
+	commands.ToolRunner
 }
 
 // NewIpconfigCommand creates a new ipconfig command
@@ -103,14 +103,12 @@ func (i *IpconfigCommand) Execute(ctx context.Context, args *commands.Arguments)
 		output.WriteString("\n" + color.New(color.FgYellow, color.Bold).Sprint("📋 System Configuration Details:\n"))
 		output.WriteString("═══════════════════════════════════════════════════════════════\n")
 
-		var cmd *exec.Cmd
+		name, toolArgs := "ifconfig", []string{"-a"}
 		if runtime.GOOS == "windows" {
-			cmd = exec.CommandContext(ctx, "ipconfig", "/all")
-		} else {
-			cmd = exec.CommandContext(ctx, "ifconfig", "-a")
+			name, toolArgs = "ipconfig", []string{"/all"}
 		}
 
-		if cmdOutput, err := cmd.Output(); err == nil {
+		if cmdOutput, err := i.Runner().Run(ctx, name, toolArgs...); err == nil {
 			scanner := bufio.NewScanner(strings.NewReader(string(cmdOutput)))
 			for scanner.Scan() {
 				line := scanner.Text()
@@ -256,15 +254,13 @@ func (i *IpconfigCommand) handleSpecialOperations(ctx context.Context, release,
 	if flushDNS {
 		output.WriteString("🔄 Flushing DNS cache...\n")
 
-		var cmd *exec.Cmd
-		if runtime.GOOS == "windows" {
-			cmd = exec.CommandContext(ctx, "ipconfig", "/flushdns")
-		} else {
+		name, toolArgs := "ipconfig", []string{"/flushdns"}
+		if runtime.GOOS != "windows" {
 			// On Linux/macOS, try different methods
-			cmd = exec.CommandContext(ctx, "sudo", "systemctl", "restart", "systemd-resolved")
+			name, toolArgs = "sudo", []string{"systemctl", "restart", "systemd-resolved"}
 		}
 
-		if err := cmd.Run(); err != nil {
+		if _, err := i.Runner().Run(ctx, name, toolArgs...); err != nil {
 			output.WriteString(color.New(color.FgRed).Sprintf("❌ Failed to flush DNS: %v\n", err))
 		} else {
 			output.WriteString(color.New(color.FgGreen).Sprint("✅ DNS cache flushed successfully\n"))
@@ -275,19 +271,12 @@ func (i *IpconfigCommand) handleSpecialOperations(ctx context.Context, release,
 	if release {
 		output.WriteString("📤 Releasing IP configuration...\n")
 
-		var cmd *exec.Cmd
-		if runtime.GOOS == "windows" {
-			cmd = exec.CommandContext(ctx, "ipconfig", "/release")
-		} else {
+		if runtime.GOOS != "windows" {
 			output.WriteString(color.New(color.FgYellow).Sprint("⚠️  Release operation not supported on this platform\n"))
-		}
-
-		if cmd != nil {
-			if err := cmd.Run(); err != nil {
-				output.WriteString(color.New(color.FgRed).Sprintf("❌ Failed to release IP: %v\n", err))
-			} else {
-				output.WriteString(color.New(color.FgGreen).Sprint("✅ IP configuration released\n"))
-			}
+		} else if _, err := i.Runner().Run(ctx, "ipconfig", "/release"); err != nil {
+			output.WriteString(color.New(color.FgRed).Sprintf("❌ Failed to release IP: %v\n", err))
+		} else {
+			output.WriteString(color.New(color.FgGreen).Sprint("✅ IP configuration released\n"))
 		}
 		output.WriteString("\n")
 	}
@@ -295,19 +284,12 @@ func (i *IpconfigCommand) handleSpecialOperations(ctx context.Context, release,
 	if renew {
 		output.WriteString("📥 Renewing IP configuration...\n")
 
-		var cmd *exec.Cmd
-		if runtime.GOOS == "windows" {
-			cmd = exec.CommandContext(ctx, "ipconfig", "/renew")
-		} else {
+		if runtime.GOOS != "windows" {
 			output.WriteString(color.New(color.FgYellow).Sprint("⚠️  Renew operation not supported on this platform\n"))
-		}
-
-		if cmd != nil {
-			if err := cmd.Run(); err != nil {
-				output.WriteString(color.New(color.FgRed).Sprintf("❌ Failed to renew IP: %v\n", err))
-			} else {
-				output.WriteString(color.New(color.FgGreen).Sprint("✅ IP configuration renewed\n"))
-			}
+		} else if _, err := i.Runner().Run(ctx, "ipconfig", "/renew"); err != nil {
+			output.WriteString(color.New(color.FgRed).Sprintf("❌ Failed to renew IP: %v\n", err))
+		} else {
+			output.WriteString(color.New(color.FgGreen).Sprint("✅ IP configuration renewed\n"))
 		}
 	}
 
@@ -0,0 +1,499 @@
+package networking
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/config"
+
+	"github.com/fatih/color"
+)
+
+// reservedNetworks are ranges that geolocation services know nothing about
+var reservedNetworks = []string{
+	"0.0.0.0/8", "10.0.0.0/8", "100.64.0.0/10", "127.0.0.0/8", "169.254.0.0/16",
+	"172.16.0.0/12", "192.168.0.0/16", "224.0.0.0/4", "240.0.0.0/4",
+	"::1/128", "fc00::/7", "fe80::/10", "ff00::/8",
+}
+
+// IPInfo is everything known about one address
+type IPInfo struct {
+	Query        string   `json:"query"`
+	IP           string   `json:"ip"`
+	Hostname     string   `json:"hostname,omitempty"`
+	Country      string   `json:"country,omitempty"`
+	Region       string   `json:"region,omitempty"`
+	City         string   `json:"city,omitempty"`
+	ASN          string   `json:"asn,omitempty"`
+	Organization string   `json:"organization,omitempty"`
+	Scope        string   `json:"scope"`
+	Source       string   `json:"source"`
+	Cached       bool     `json:"cached,omitempty"`
+	Warnings     []string `json:"warnings,omitempty"`
+}
+
+// ipinfoCacheEntry is a cached lookup result
+type ipinfoCacheEntry struct {
+	Info      IPInfo    `json:"info"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// IpinfoCommand looks up geolocation and ASN details for IPs and hostnames
+type IpinfoCommand struct {
+	*commands.BaseCommand
+	config config.IPInfoConfig
+	mu     sync.Mutex
+}
+
+// NewIpinfoCommand creates a new ipinfo command
+func NewIpinfoCommand(cfg config.IPInfoConfig) *IpinfoCommand {
+	return &IpinfoCommand{
+		BaseCommand: commands.NewBaseCommand(
+			"ipinfo",
+			"Show geolocation, ASN and organization for an IP or hostname",
+			"ipinfo <ip|hostname>... [--json] [--refresh]",
+			[]string{"windows", "linux", "darwin"},
+			false,
+		),
+		config: cfg,
+	}
+}
+
+// Execute looks up each target and reports what could be determined
+func (i *IpinfoCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	startTime := time.Now()
+
+	jsonOutput := false
+	refresh := false
+	var targets []string
+
+	for _, arg := range args.Raw {
+		switch arg {
+		case "--json":
+			jsonOutput = true
+		case "--refresh":
+			refresh = true
+		default:
+			if !strings.HasPrefix(arg, "-") {
+				targets = append(targets, arg)
+			}
+		}
+	}
+
+	if len(targets) == 0 {
+		return &commands.Result{
+			Output:   "Usage: " + i.Usage() + "\n",
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}, nil
+	}
+
+	cache := i.loadCache()
+	var results []IPInfo
+	exitCode := 0
+
+	for _, target := range targets {
+		info, err := i.lookup(ctx, target, cache, refresh)
+		if err != nil {
+			exitCode = 1
+			info = IPInfo{Query: target, Source: "none", Warnings: []string{err.Error()}}
+		}
+		results = append(results, info)
+	}
+
+	i.saveCache(cache)
+
+	if jsonOutput {
+		var data []byte
+		var err error
+		if len(results) == 1 {
+			data, err = json.MarshalIndent(results[0], "", "  ")
+		} else {
+			data, err = json.MarshalIndent(results, "", "  ")
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &commands.Result{
+			Output:   string(data) + "\n",
+			ExitCode: exitCode,
+			Duration: time.Since(startTime),
+		}, nil
+	}
+
+	var output strings.Builder
+	output.WriteString(color.New(color.FgCyan, color.Bold).Sprint("🌍 IP INFORMATION\n"))
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+
+	for n, info := range results {
+		if n > 0 {
+			output.WriteString("───────────────────────────────────────────────────────────────\n")
+		}
+		i.writeInfo(info, &output)
+	}
+
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: exitCode,
+		Duration: time.Since(startTime),
+	}, nil
+}
+
+// lookup resolves target and gathers details from the cache, database or API,
+// falling back to reverse DNS when no source is available
+func (i *IpinfoCommand) lookup(ctx context.Context, target string, cache map[string]ipinfoCacheEntry, refresh bool) (IPInfo, error) {
+	ip := net.ParseIP(target)
+	if ip == nil {
+		resolved, err := ResolveTarget(ctx, target, false, false)
+		if err != nil {
+			return IPInfo{}, err
+		}
+		ip = resolved
+	}
+
+	key := ip.String()
+	if entry, ok := cache[key]; ok && !refresh && time.Since(entry.FetchedAt) < i.config.CacheTTL {
+		info := entry.Info
+		info.Query = target
+		info.Cached = true
+		return info, nil
+	}
+
+	info := IPInfo{Query: target, IP: key, Scope: "public"}
+
+	if isReservedIP(ip) {
+		info.Scope = "private"
+		info.Source = "local"
+		info.Hostname = reverseLookup(ctx, ip)
+		return info, nil
+	}
+
+	found := false
+	if i.config.Database != "" {
+		record, err := i.lookupDatabase(ip)
+		if err != nil {
+			info.Warnings = append(info.Warnings, err.Error())
+		} else if record != nil {
+			mergeIPInfo(&info, record)
+			info.Source = "database"
+			found = true
+		}
+	}
+
+	if !found && i.config.Endpoint != "" {
+		remote, err := i.lookupAPI(ctx, ip)
+		if err != nil {
+			info.Warnings = append(info.Warnings, err.Error())
+		} else {
+			mergeIPInfo(&info, remote)
+			info.Source = "api"
+			found = true
+		}
+	}
+
+	if info.Hostname == "" {
+		info.Hostname = reverseLookup(ctx, ip)
+	}
+
+	if !found {
+		info.Source = "reverse-dns"
+		return info, nil
+	}
+
+	cache[key] = ipinfoCacheEntry{Info: info, FetchedAt: time.Now()}
+	return info, nil
+}
+
+// lookupAPI queries the configured IP-info endpoint
+func (i *IpinfoCommand) lookupAPI(ctx context.Context, ip net.IP) (*IPInfo, error) {
+	endpoint := strings.Replace(i.config.Endpoint, "{ip}", url.PathEscape(ip.String()), -1)
+	endpoint = strings.Replace(endpoint, "{key}", url.QueryEscape(i.config.APIKey), -1)
+
+	timeout := i.config.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ipinfo endpoint: %v", err)
+	}
+	req = req.WithContext(reqCtx)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "SuperShell/1.0")
+	if i.config.APIKey != "" && !strings.Contains(i.config.Endpoint, "{key}") {
+		req.Header.Set("Authorization", "Bearer "+i.config.APIKey)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("ipinfo API unavailable: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return nil, fmt.Errorf("ipinfo API rejected the request (HTTP %d); set networking.ipinfo.api_key in %s", resp.StatusCode, config.DefaultPath())
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, fmt.Errorf("ipinfo API rate limit reached; set networking.ipinfo.api_key in %s", config.DefaultPath())
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ipinfo API returned HTTP %d", resp.StatusCode)
+	}
+
+	var fields map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&fields); err != nil {
+		return nil, fmt.Errorf("invalid ipinfo API response: %v", err)
+	}
+
+	return parseIPInfoResponse(fields), nil
+}
+
+// parseIPInfoResponse maps the field names used by common IP-info services
+func parseIPInfoResponse(fields map[string]interface{}) *IPInfo {
+	pick := func(keys ...string) string {
+		for _, key := range keys {
+			if value, ok := fields[key]; ok && value != nil {
+				if text := strings.TrimSpace(fmt.Sprint(value)); text != "" {
+					return text
+				}
+			}
+		}
+		return ""
+	}
+
+	info := &IPInfo{
+		Hostname:     pick("hostname", "reverse"),
+		Country:      pick("country", "country_code", "countryCode", "country_name"),
+		Region:       pick("region", "regionName", "region_name"),
+		City:         pick("city"),
+		ASN:          pick("asn"),
+		Organization: pick("org", "organization", "isp"),
+	}
+
+	// Services such as ipinfo.io and ip-api.com report "AS15169 Google LLC"
+	asField := pick("as")
+	if asField == "" && strings.HasPrefix(info.Organization, "AS") {
+		asField = info.Organization
+		info.Organization = ""
+	}
+	if asField != "" {
+		parts := strings.SplitN(asField, " ", 2)
+		if info.ASN == "" {
+			info.ASN = parts[0]
+		}
+		if info.Organization == "" && len(parts) == 2 {
+			info.Organization = parts[1]
+		}
+	}
+	if info.ASN != "" && !strings.HasPrefix(strings.ToUpper(info.ASN), "AS") {
+		info.ASN = "AS" + info.ASN
+	}
+
+	return info
+}
+
+// lookupDatabase finds the most specific network containing ip in the local database.
+// The database is a CSV file of network,country,city,asn,organization rows where
+// network is in CIDR notation, as exported from GeoLite2-style databases.
+func (i *IpinfoCommand) lookupDatabase(ip net.IP) (*IPInfo, error) {
+	file, err := os.Open(config.ExpandHome(i.config.Database))
+	if err != nil {
+		return nil, fmt.Errorf("ipinfo database unavailable: %v", err)
+	}
+	defer file.Close()
+
+	var best *IPInfo
+	bestSize := -1
+
+	reader := csv.NewReader(file)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	for {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ipinfo database: %v", err)
+		}
+
+		_, network, err := net.ParseCIDR(strings.TrimSpace(fields[0]))
+		if err != nil || !network.Contains(ip) {
+			// Header rows and malformed lines are skipped
+			continue
+		}
+
+		size, _ := network.Mask.Size()
+		if size <= bestSize {
+			continue
+		}
+
+		for len(fields) < 5 {
+			fields = append(fields, "")
+		}
+		best = &IPInfo{
+			Country:      fields[1],
+			City:         fields[2],
+			ASN:          fields[3],
+			Organization: fields[4],
+		}
+		bestSize = size
+	}
+
+	if best == nil {
+		return nil, nil
+	}
+	if best.ASN != "" && !strings.HasPrefix(strings.ToUpper(best.ASN), "AS") {
+		best.ASN = "AS" + best.ASN
+	}
+	return best, nil
+}
+
+// writeInfo formats one lookup result
+func (i *IpinfoCommand) writeInfo(info IPInfo, output *strings.Builder) {
+	field := func(label, value string) {
+		if value == "" {
+			value = color.New(color.FgHiBlack).Sprint("unknown")
+		}
+		output.WriteString(fmt.Sprintf("%-16s %s\n", label, value))
+	}
+
+	output.WriteString(fmt.Sprintf("🎯 %s\n", color.New(color.FgYellow, color.Bold).Sprint(info.Query)))
+	if info.IP == "" {
+		for _, warning := range info.Warnings {
+			output.WriteString(color.New(color.FgRed).Sprintf("❌ %s\n", warning))
+		}
+		return
+	}
+
+	field("📍 IP:", info.IP)
+	field("🏷️  Hostname:", info.Hostname)
+
+	if info.Scope == "private" {
+		output.WriteString(color.New(color.FgBlue).Sprint("🏠 Private or reserved address - no geolocation available\n"))
+		return
+	}
+
+	location := strings.Join(nonEmpty(info.City, info.Region, info.Country), ", ")
+	field("🌍 Location:", location)
+	field("🔢 ASN:", info.ASN)
+	field("🏢 Organization:", info.Organization)
+
+	source := info.Source
+	if info.Cached {
+		source += " (cached)"
+	}
+	field("📚 Source:", source)
+
+	for _, warning := range info.Warnings {
+		output.WriteString(color.New(color.FgYellow).Sprintf("⚠️  %s\n", warning))
+	}
+	if info.Source == "reverse-dns" {
+		output.WriteString("💡 Only reverse DNS is available; configure networking.ipinfo in " + config.DefaultPath() + "\n")
+	}
+}
+
+// loadCache reads cached lookups, returning an empty cache when none exists
+func (i *IpinfoCommand) loadCache() map[string]ipinfoCacheEntry {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	cache := make(map[string]ipinfoCacheEntry)
+	if i.config.CacheFile == "" {
+		return cache
+	}
+	if data, err := ioutil.ReadFile(config.ExpandHome(i.config.CacheFile)); err == nil {
+		json.Unmarshal(data, &cache)
+	}
+	return cache
+}
+
+// saveCache writes cached lookups, dropping expired entries
+func (i *IpinfoCommand) saveCache(cache map[string]ipinfoCacheEntry) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.config.CacheFile == "" {
+		return
+	}
+	for key, entry := range cache {
+		if time.Since(entry.FetchedAt) >= i.config.CacheTTL {
+			delete(cache, key)
+		}
+	}
+
+	path := config.ExpandHome(i.config.CacheFile)
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	ioutil.WriteFile(path, data, 0644)
+}
+
+// mergeIPInfo copies the details found by a lookup source into info
+func mergeIPInfo(info *IPInfo, found *IPInfo) {
+	if found.Hostname != "" {
+		info.Hostname = found.Hostname
+	}
+	info.Country = found.Country
+	info.Region = found.Region
+	info.City = found.City
+	info.ASN = found.ASN
+	info.Organization = found.Organization
+}
+
+// reverseLookup returns the first PTR name for ip, or an empty string
+func reverseLookup(ctx context.Context, ip net.IP) string {
+	lookupCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	names, err := net.DefaultResolver.LookupAddr(lookupCtx, ip.String())
+	if err != nil || len(names) == 0 {
+		return ""
+	}
+	return strings.TrimSuffix(names[0], ".")
+}
+
+// isReservedIP reports whether ip is private, loopback, link-local or multicast
+func isReservedIP(ip net.IP) bool {
+	for _, cidr := range reservedNetworks {
+		if _, network, err := net.ParseCIDR(cidr); err == nil && network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// nonEmpty returns the non-empty values in order
+func nonEmpty(values ...string) []string {
+	var result []string
+	for _, value := range values {
+		if value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
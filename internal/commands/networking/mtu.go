@@ -0,0 +1,310 @@
+package networking
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+
+	"github.com/fatih/color"
+)
+
+// IP and ICMP header overhead added to an echo payload
+const (
+	ipv4EchoOverhead = 28 // 20-byte IPv4 header + 8-byte ICMP header
+	ipv6EchoOverhead = 48 // 40-byte IPv6 header + 8-byte ICMPv6 header
+)
+
+// MtuCommand reports interface MTUs and discovers the path MTU to a host
+type MtuCommand struct {
+	*commands.BaseCommand
+}
+
+// NewMtuCommand creates a new mtu command
+func NewMtuCommand() *MtuCommand {
+	return &MtuCommand{
+		BaseCommand: commands.NewBaseCommand(
+			"mtu",
+			"Show interface MTUs and discover the path MTU to a host",
+			"mtu [host] [-4|-6] [-i <interface>] [--max <bytes>]",
+			[]string{"windows", "linux", "darwin"},
+			false,
+		),
+	}
+}
+
+// Execute lists interface MTUs and optionally runs path-MTU discovery
+func (m *MtuCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	startTime := time.Now()
+
+	target := ""
+	forceV4 := false
+	forceV6 := false
+	maxMTU := 0
+	selected := ""
+
+	for i := 0; i < len(args.Raw); i++ {
+		switch arg := args.Raw[i]; arg {
+		case "-4":
+			forceV4 = true
+		case "-6":
+			forceV6 = true
+		case "--max":
+			if i+1 >= len(args.Raw) {
+				return commands.UsageResult(m, startTime, "--max needs a size in bytes"), nil
+			}
+			i++
+			value, err := strconv.Atoi(args.Raw[i])
+			if err != nil || value <= 0 {
+				return commands.UsageResult(m, startTime, "invalid --max value '%s'", args.Raw[i]), nil
+			}
+			maxMTU = value
+		case "-i", "--interface":
+			if i+1 < len(args.Raw) {
+				selected = args.Raw[i+1]
+				i++
+			}
+		default:
+			if !strings.HasPrefix(arg, "-") && target == "" {
+				target = arg
+			}
+		}
+	}
+
+	var output strings.Builder
+
+	output.WriteString(color.New(color.FgCyan, color.Bold).Sprint("📏 MTU DIAGNOSTICS\n"))
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+
+	largestMTU, err := m.writeInterfaces(selected, &output)
+	if err != nil {
+		output.WriteString(color.New(color.FgRed).Sprintf("❌ %v\n", err))
+		return &commands.Result{
+			Output:   output.String(),
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}, nil
+	}
+
+	if target == "" {
+		output.WriteString("───────────────────────────────────────────────────────────────\n")
+		output.WriteString("💡 Use 'mtu <host>' to discover the path MTU to a target\n")
+		output.WriteString("═══════════════════════════════════════════════════════════════\n")
+		return &commands.Result{
+			Output:   output.String(),
+			ExitCode: 0,
+			Duration: time.Since(startTime),
+		}, nil
+	}
+
+	ip, err := ResolveTarget(ctx, target, forceV4, forceV6)
+	if err != nil {
+		output.WriteString(color.New(color.FgRed).Sprintf("❌ %v\n", err))
+		return &commands.Result{
+			Output:   output.String(),
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}, nil
+	}
+
+	if maxMTU <= 0 {
+		maxMTU = largestMTU
+	}
+	if maxMTU <= 0 {
+		maxMTU = 1500
+	}
+
+	overhead := ipv4EchoOverhead
+	family := "IPv4"
+	if ip.To4() == nil {
+		overhead = ipv6EchoOverhead
+		family = "IPv6"
+	}
+
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+	output.WriteString(color.New(color.FgYellow, color.Bold).Sprint("🔍 PATH MTU DISCOVERY\n"))
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+	output.WriteString(fmt.Sprintf("🎯 Target:  %s (%s, %s)\n", target, ip, family))
+	output.WriteString(fmt.Sprintf("📐 Probing: up to %d bytes with don't-fragment set\n", maxMTU))
+
+	pathMTU, err := m.discoverPathMTU(ctx, ip, overhead, maxMTU)
+	if err != nil {
+		output.WriteString(color.New(color.FgRed).Sprintf("❌ %v\n", err))
+		return &commands.Result{
+			Output:   output.String(),
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}, nil
+	}
+
+	output.WriteString(color.New(color.FgGreen, color.Bold).Sprintf("✅ Path MTU: %d bytes (largest echo payload %d bytes)\n", pathMTU, pathMTU-overhead))
+	if pathMTU < largestMTU {
+		output.WriteString(color.New(color.FgYellow).Sprintf("⚠️  Path MTU is below the local interface MTU (%d); a tunnel or VPN is likely fragmenting traffic\n", largestMTU))
+	}
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}, nil
+}
+
+// writeInterfaces lists each interface MTU, or only that of the selected one,
+// and returns the largest MTU of an up, non-loopback interface listed. The
+// selected interface's MTU is returned whatever its state.
+func (m *MtuCommand) writeInterfaces(selected string, output *strings.Builder) (int, error) {
+	interfaces, err := ListInterfaces()
+	if err != nil {
+		return 0, err
+	}
+	if selected != "" {
+		iface, err := resolveInterface(interfaces, selected)
+		if err != nil {
+			return 0, err
+		}
+		interfaces = []NetInterface{iface}
+	}
+
+	output.WriteString(fmt.Sprintf("%-20s %-8s %-6s %s\n",
+		color.New(color.FgYellow, color.Bold).Sprint("Interface"),
+		color.New(color.FgGreen, color.Bold).Sprint("MTU"),
+		color.New(color.FgBlue, color.Bold).Sprint("State"),
+		color.New(color.FgMagenta, color.Bold).Sprint("Addresses")))
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+
+	largest := 0
+	for _, iface := range interfaces {
+		if iface.Up && !iface.Loopback && iface.MTU > largest {
+			largest = iface.MTU
+		}
+		output.WriteString(fmt.Sprintf("%-20s %-8d %-6s %s\n", iface.Name, iface.MTU, iface.State(), strings.Join(iface.Addresses, ", ")))
+	}
+	if selected != "" {
+		return interfaces[0].MTU, nil
+	}
+	return largest, nil
+}
+
+// discoverPathMTU binary-searches the largest don't-fragment echo that gets a reply
+func (m *MtuCommand) discoverPathMTU(ctx context.Context, ip net.IP, overhead, maxMTU int) (int, error) {
+	tool := "ping"
+	if runtime.GOOS == "darwin" && ip.To4() == nil {
+		tool = "ping6"
+	}
+	if _, err := exec.LookPath(tool); err != nil {
+		return 0, fmt.Errorf("path MTU discovery needs the system '%s' tool: %v", tool, missingToolError(err))
+	}
+
+	if !m.pingDontFragment(ctx, ip, 0) {
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		return 0, fmt.Errorf("%s does not answer ICMP echo requests", ip)
+	}
+
+	low, high := 0, maxMTU-overhead
+	for low < high {
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+
+		mid := (low + high + 1) / 2
+		if m.pingDontFragment(ctx, ip, mid) {
+			low = mid
+		} else {
+			high = mid - 1
+		}
+	}
+
+	return low + overhead, nil
+}
+
+// pingDontFragment sends one echo of the given payload size with fragmentation
+// disabled using the system ping tool
+func (m *MtuCommand) pingDontFragment(ctx context.Context, ip net.IP, payload int) bool {
+	probeCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	size := strconv.Itoa(payload)
+	v6 := ip.To4() == nil
+	var cmd *exec.Cmd
+
+	switch runtime.GOOS {
+	case "windows":
+		family := "-4"
+		if v6 {
+			family = "-6"
+		}
+		args := []string{family, "-n", "1", "-w", "1000", "-l", size}
+		if !v6 {
+			// IPv6 never fragments in transit, so -f only applies to IPv4
+			args = append(args, "-f")
+		}
+		cmd = exec.CommandContext(probeCtx, "ping", append(args, ip.String())...)
+	case "darwin":
+		if v6 {
+			cmd = exec.CommandContext(probeCtx, "ping6", "-c", "1", "-s", size, ip.String())
+		} else {
+			cmd = exec.CommandContext(probeCtx, "ping", "-D", "-c", "1", "-t", "2", "-s", size, ip.String())
+		}
+	default:
+		family := "-4"
+		if v6 {
+			family = "-6"
+		}
+		cmd = exec.CommandContext(probeCtx, "ping", family, "-M", "do", "-c", "1", "-W", "2", "-s", size, ip.String())
+	}
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return false
+	}
+
+	// Windows exits 0 for some ICMP errors, so look for an actual reply
+	lower := strings.ToLower(string(out))
+	if strings.Contains(lower, "fragment") || strings.Contains(lower, "too long") {
+		return false
+	}
+	return true
+}
+
+// ResolveTarget resolves host to an address of the requested family
+func ResolveTarget(ctx context.Context, host string, forceV4, forceV6 bool) (net.IP, error) {
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %v", host, err)
+	}
+
+	var v4, v6 net.IP
+	for _, addr := range addrs {
+		if addr.IP.To4() != nil {
+			if v4 == nil {
+				v4 = addr.IP
+			}
+		} else if v6 == nil {
+			v6 = addr.IP
+		}
+	}
+
+	switch {
+	case forceV6 && v6 == nil:
+		return nil, fmt.Errorf("%s has no IPv6 address", host)
+	case forceV6:
+		return v6, nil
+	case forceV4 && v4 == nil:
+		return nil, fmt.Errorf("%s has no IPv4 address", host)
+	case v4 != nil:
+		return v4, nil
+	case v6 != nil:
+		return v6, nil
+	}
+
+	return nil, fmt.Errorf("no addresses found for %s", host)
+}
@@ -24,7 +24,7 @@ func NewNetdiscoverCommand() *NetdiscoverCommand {
 		BaseCommand: commands.NewBaseCommand(
 			"netdiscover",
 			"Discover live hosts on a subnet",
-			"netdiscover [-r <range>] [-t <timeout>] [-p] [--passive]",
+			"netdiscover [-r <range> | -i <interface>] [-t <timeout>] [-p] [--passive]",
 			[]string{"windows", "linux", "darwin"},
 			false,
 		),
@@ -40,12 +40,19 @@ func (n *NetdiscoverCommand) Execute(ctx context.Context, args *commands.Argumen
 	timeout := 1000             // Default timeout in ms
 	passive := false
 	showProgress := true
+	rangeGiven := false
+	iface := ""
 
 	for i, arg := range args.Raw {
 		switch arg {
 		case "-r", "--range":
 			if i+1 < len(args.Raw) {
 				ipRange = args.Raw[i+1]
+				rangeGiven = true
+			}
+		case "-i", "--interface":
+			if i+1 < len(args.Raw) {
+				iface = args.Raw[i+1]
 			}
 		case "-t", "--timeout":
 			if i+1 < len(args.Raw) {
@@ -64,6 +71,27 @@ func (n *NetdiscoverCommand) Execute(ctx context.Context, args *commands.Argumen
 
 	output.WriteString(color.New(color.FgCyan, color.Bold).Sprint("🔍 NETWORK DISCOVERY\n"))
 	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+
+	// Without -r the interface's own subnet is scanned
+	if iface != "" {
+		resolved, err := ResolveInterface(iface)
+		if err == nil && !rangeGiven {
+			if network, ok := resolved.IPv4Network(); ok {
+				ipRange = network.String()
+			} else {
+				err = fmt.Errorf("%s has no IPv4 address to scan from", resolved.Name)
+			}
+		}
+		if err != nil {
+			output.WriteString(color.New(color.FgRed).Sprintf("❌ %v\n", err))
+			return &commands.Result{
+				Output:   output.String(),
+				ExitCode: 1,
+				Duration: time.Since(startTime),
+			}, nil
+		}
+		output.WriteString(fmt.Sprintf("🔌 Interface:   %s\n", color.New(color.FgBlue).Sprint(resolved.Name)))
+	}
 	output.WriteString(fmt.Sprintf("📡 Target Range: %s\n", color.New(color.FgBlue).Sprint(ipRange)))
 	output.WriteString(fmt.Sprintf("⏱️  Timeout:     %d ms\n", timeout))
 	output.WriteString(fmt.Sprintf("🔧 Mode:        %s\n",
@@ -87,11 +115,15 @@ func (n *NetdiscoverCommand) Execute(ctx context.Context, args *commands.Argumen
 		hostCount = 254 // Limit for demo
 	}
 
+	if !passive {
+		// Active discovery sends ARP requests
+		writeCapabilityWarning(CapabilityCapture, &output)
+	}
 	output.WriteString(fmt.Sprintf("🎯 Scanning %d hosts...\n", hostCount))
 	output.WriteString("───────────────────────────────────────────────────────────────\n")
 
 	// Simulate network discovery
-	discoveredHosts := n.simulateDiscovery(ipNet, hostCount, timeout, passive, showProgress, &output)
+	discoveredHosts := n.simulateDiscovery(ctx, ipNet, hostCount, timeout, passive, showProgress, &output)
 
 	// Results summary
 	output.WriteString("───────────────────────────────────────────────────────────────\n")
@@ -118,6 +150,15 @@ func (n *NetdiscoverCommand) Execute(ctx context.Context, args *commands.Argumen
 	}
 
 	output.WriteString("───────────────────────────────────────────────────────────────\n")
+	if ctx.Err() != nil {
+		output.WriteString(color.New(color.FgYellow).Sprintf("⚠️  Scan interrupted: %d live hosts found so far\n", len(discoveredHosts)))
+		output.WriteString("═══════════════════════════════════════════════════════════════\n")
+		return &commands.Result{
+			Output:   output.String(),
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}, nil
+	}
 	output.WriteString(fmt.Sprintf("✅ Scan completed: %d live hosts found\n", len(discoveredHosts)))
 	output.WriteString(fmt.Sprintf("⏱️  Total time: %v\n", time.Since(startTime).Round(time.Millisecond)))
 	output.WriteString("═══════════════════════════════════════════════════════════════\n")
@@ -138,7 +179,7 @@ type DiscoveredHost struct {
 }
 
 // simulateDiscovery simulates network host discovery
-func (n *NetdiscoverCommand) simulateDiscovery(ipNet *net.IPNet, hostCount, timeout int, passive, showProgress bool, output *strings.Builder) []DiscoveredHost {
+func (n *NetdiscoverCommand) simulateDiscovery(ctx context.Context, ipNet *net.IPNet, hostCount, timeout int, passive, showProgress bool, output *strings.Builder) []DiscoveredHost {
 	var hosts []DiscoveredHost
 
 	// Sample discovered hosts
@@ -170,7 +211,10 @@ func (n *NetdiscoverCommand) simulateDiscovery(ipNet *net.IPNet, hostCount, time
 			hosts = append(hosts, sampleHosts[len(hosts)])
 		}
 
-		time.Sleep(100 * time.Millisecond)
+		if err := commands.Sleep(ctx, 100*time.Millisecond); err != nil {
+			output.WriteString("\n")
+			return hosts
+		}
 	}
 
 	if showProgress {
@@ -4,8 +4,9 @@ import (
 	"bufio"
 	"context"
 	"fmt"
-	"os/exec"
+	"io"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
@@ -17,115 +18,156 @@ import (
 // NetstatCommand shows network connections and statistics
 type NetstatCommand struct {
 	*commands.BaseCommand
+	commands.ToolRunner
+	flags *commands.FlagSet
 }
 
 // NewNetstatCommand creates a new netstat command
 func NewNetstatCommand() *NetstatCommand {
+	usage := "netstat [-a] [-n] [-p] [-r] [-s] [--live [--interval <seconds>] [--count <n>] [--filter <text>] [--group]] [--watch-new [--closed] [--remote <address>] [--notify]] [--resolve] [--resolve-dns] [--quiet] [--output-format text|json|csv]"
 	return &NetstatCommand{
 		BaseCommand: commands.NewBaseCommand(
 			"netstat",
 			"Display network connections, routing tables, and network statistics",
-			"netstat [-a] [-n] [-p] [-r] [-s]",
+			usage,
 			[]string{"windows", "linux", "darwin"},
 			false,
 		),
+		flags: commands.NewFlagSet("netstat", usage,
+			commands.FlagSpec{Name: "all", Short: "a", Help: "Show all connections and listening ports"},
+			commands.FlagSpec{Name: "numeric", Short: "n", Help: "Show addresses and ports as numbers"},
+			commands.FlagSpec{Name: "processes", Short: "p", Help: "Show the owning process"},
+			commands.FlagSpec{Name: "route", Short: "r", Help: "Show the routing table"},
+			commands.FlagSpec{Name: "statistics", Short: "s", Help: "Show per-protocol statistics"},
+			commands.FlagSpec{Name: "live", Help: "Refresh the connection table until interrupted"},
+			commands.FlagSpec{Name: "interval", Kind: commands.FloatFlag, Default: "2", Value: "seconds", Help: "Seconds between live samples"},
+			commands.FlagSpec{Name: "count", Kind: commands.IntFlag, Value: "n", Help: "Stop after n live samples"},
+			commands.FlagSpec{Name: "filter", Kind: commands.StringFlag, Value: "text", Help: "Only show live connections containing text"},
+			commands.FlagSpec{Name: "group", Help: "Group live connections by process"},
+			commands.FlagSpec{Name: "watch-new", Help: "Report connections as they appear, after a baseline of the current ones"},
+			commands.FlagSpec{Name: "closed", Help: "With --watch-new, also report connections that close"},
+			commands.FlagSpec{Name: "remote", Kind: commands.StringFlag, Value: "address", Help: "With --watch-new, only report connections to an IP, CIDR range or host, with an optional :port"},
+			commands.FlagSpec{Name: "notify", Help: "With --watch-new, raise a desktop notification for new connections"},
+			commands.FlagSpec{Name: "resolve", Help: "Show the process behind each connection"},
+			commands.FlagSpec{Name: "resolve-dns", Help: "Like --resolve, and look up remote host names"},
+		),
 	}
 }
 
+// FlagSet returns the flags netstat accepts
+func (n *NetstatCommand) FlagSet() *commands.FlagSet {
+	return n.flags
+}
+
 // Execute shows network information with enhanced formatting
 func (n *NetstatCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
 	startTime := time.Now()
 
-	// Parse flags
-	showAll := false
-	showNumeric := false
-	showProcesses := false
-	showRouting := false
-	showStatistics := false
-
-	for _, arg := range args.Raw {
-		switch arg {
-		case "-a", "--all":
-			showAll = true
-		case "-n", "--numeric":
-			showNumeric = true
-		case "-p", "--processes":
-			showProcesses = true
-		case "-r", "--route":
-			showRouting = true
-		case "-s", "--statistics":
-			showStatistics = true
+	flags, usage := n.flags.ParseArguments(args, startTime)
+	if usage != nil {
+		return usage, nil
+	}
+	showAll := flags.Bool("all")
+	showNumeric := flags.Bool("numeric")
+	showProcesses := flags.Bool("processes")
+	showRouting := flags.Bool("route")
+	showStatistics := flags.Bool("statistics")
+	live := flags.Bool("live")
+	watch := flags.Bool("watch-new")
+	resolveDNS := flags.Bool("resolve-dns")
+	resolve := flags.Bool("resolve") || resolveDNS
+	liveOpts := liveOptions{group: flags.Bool("group"), filter: flags.String("filter")}
+
+	interval := flags.Float("interval")
+	if interval <= 0 {
+		return n.usageError(fmt.Sprintf("invalid --interval value '%s'", flags.String("interval")), startTime), nil
+	}
+	liveOpts.interval = time.Duration(interval * float64(time.Second))
+	if flags.Changed("count") {
+		if liveOpts.count = flags.Int("count"); liveOpts.count <= 0 {
+			return n.usageError(fmt.Sprintf("invalid --count value '%s'", flags.String("count")), startTime), nil
 		}
 	}
 
-	var output strings.Builder
-
-	// Header
-	output.WriteString(color.New(color.FgCyan, color.Bold).Sprint("🌐 NETWORK STATUS\n"))
-	output.WriteString("═══════════════════════════════════════════════════════════════\n\n")
-
-	// Show progress
-	fmt.Print("📊 Gathering network information")
-	done := make(chan bool)
-	go n.showProgress(done)
-
-	// Build netstat command based on OS and flags
-	var cmd *exec.Cmd
-	var cmdArgs []string
+	if resolve {
+		liveOpts.resolver = NewConnResolver(resolveDNS, n.Runner())
+	}
+	switch {
+	case watch && live:
+		return n.usageError("--watch-new and --live can't be combined", startTime), nil
+	case !watch && (flags.Bool("closed") || flags.Changed("remote") || flags.Bool("notify")):
+		return n.usageError("--closed, --remote and --notify need --watch-new", startTime), nil
+	}
 
-	if runtime.GOOS == "windows" {
-		cmdArgs = []string{}
-		if showAll {
-			cmdArgs = append(cmdArgs, "-a")
-		}
-		if showNumeric {
-			cmdArgs = append(cmdArgs, "-n")
-		}
-		if showProcesses {
-			cmdArgs = append(cmdArgs, "-o")
+	options := commands.OutputOptionsFrom(ctx)
+	if options.Structured() {
+		if live || watch || showRouting || showStatistics {
+			return n.usageError("--live, --watch-new, -r and -s have no structured output; use --output-format text", startTime), nil
 		}
-		if showRouting {
-			cmdArgs = append(cmdArgs, "-r")
-		}
-		if showStatistics {
-			cmdArgs = append(cmdArgs, "-s")
-		}
-		if len(cmdArgs) == 0 {
-			cmdArgs = []string{"-an"}
-		}
-	} else {
-		cmdArgs = []string{}
-		if showAll {
-			cmdArgs = append(cmdArgs, "-a")
-		}
-		if showNumeric {
-			cmdArgs = append(cmdArgs, "-n")
-		}
-		if showProcesses {
-			cmdArgs = append(cmdArgs, "-p")
+		return n.showStructured(ctx, options.Format, showProcesses, liveOpts.resolver, startTime), nil
+	}
+
+	if resolve {
+		if !live && !watch {
+			return n.showResolved(ctx, liveOpts, startTime), nil
 		}
-		if showRouting {
-			cmdArgs = append(cmdArgs, "-r")
+	}
+
+	if watch {
+		watchOpts := watchOptions{
+			interval: liveOpts.interval,
+			count:    liveOpts.count,
+			filter:   liveOpts.filter,
+			closed:   flags.Bool("closed"),
+			notify:   flags.Bool("notify"),
+			resolver: liveOpts.resolver,
 		}
-		if showStatistics {
-			cmdArgs = append(cmdArgs, "-s")
+		if flags.Changed("remote") {
+			remote, err := parseRemoteFilter(ctx, flags.String("remote"))
+			if err != nil {
+				return n.usageError(err.Error(), startTime), nil
+			}
+			watchOpts.remote = remote
 		}
-		if len(cmdArgs) == 0 {
-			cmdArgs = []string{"-an"}
+		return n.showWatch(ctx, watchOpts, startTime), nil
+	}
+
+	if live {
+		samples, err := n.runLive(ctx, liveOpts)
+		if err != nil {
+			return commands.ErrorResult("", fmt.Errorf("failed to sample connections: %w", err), startTime), nil
 		}
+		return &commands.Result{
+			Stderr:   color.New(color.FgHiBlack).Sprintf("Monitored connections for %v (%d samples)\n", time.Since(startTime).Round(time.Second), samples),
+			ExitCode: 0,
+			Duration: time.Since(startTime),
+		}, nil
+	}
+
+	if options.Quiet {
+		return n.showQuiet(ctx, netstatArgs(showAll, showNumeric, showProcesses, showRouting, showStatistics), startTime), nil
 	}
 
-	cmd = exec.CommandContext(ctx, "netstat", cmdArgs...)
+	var output strings.Builder
+
+	// Header
+	output.WriteString(color.New(color.FgCyan, color.Bold).Sprint("🌐 NETWORK STATUS\n"))
+	output.WriteString("═══════════════════════════════════════════════════════════════\n\n")
+
+	// Show progress on stderr, out of the way of the listing
+	progress := commands.ProgressWriter(ctx)
+	fmt.Fprint(progress, "📊 Gathering network information")
+	done := make(chan bool)
+	go n.showProgress(progress, done)
 
 	// Execute command
-	cmdOutput, err := cmd.Output()
+	cmdOutput, err := n.Runner().Run(ctx, "netstat", netstatArgs(showAll, showNumeric, showProcesses, showRouting, showStatistics)...)
 	done <- true
-	fmt.Print("\r\033[K") // Clear progress line
+	fmt.Fprint(progress, "\r\033[K") // Clear progress line
 
 	if err != nil {
-		output.WriteString(color.New(color.FgRed, color.Bold).Sprintf("❌ Failed to execute netstat: %v\n", err))
+		err = fmt.Errorf("failed to execute netstat: %w", err)
 		return &commands.Result{
-			Output:   output.String(),
 			Error:    err,
 			ExitCode: 1,
 			Duration: time.Since(startTime),
@@ -188,18 +230,166 @@ func (n *NetstatCommand) Execute(ctx context.Context, args *commands.Arguments)
 	}
 
 	output.WriteString("\n═══════════════════════════════════════════════════════════════\n")
-	output.WriteString(color.New(color.FgHiBlack).Sprintf("Completed in %v (%d lines processed)\n",
-		time.Since(startTime).Round(time.Millisecond), lineCount))
 
 	return &commands.Result{
-		Output:   output.String(),
+		Output: output.String(),
+		Stderr: color.New(color.FgHiBlack).Sprintf("Completed in %v (%d lines processed)\n",
+			time.Since(startTime).Round(time.Millisecond), lineCount),
 		ExitCode: 0,
 		Duration: time.Since(startTime),
 	}, nil
 }
 
-// showProgress shows a spinner during netstat execution
-func (n *NetstatCommand) showProgress(done chan bool) {
+// sampleResolved reads the current connections and, given a resolver, adds
+// their process and host names
+func sampleResolved(ctx context.Context, runner commands.CommandRunner, withProcesses bool, resolver *ConnResolver) ([]ConnSample, error) {
+	connections, err := sampleConnections(ctx, runner, withProcesses)
+	if err != nil || resolver == nil {
+		return connections, err
+	}
+	resolver.Resolve(ctx, connections)
+	if resolver.dns {
+		// Give the lookups just started a chance to land, then pick up their results
+		resolver.wait(netstatLookupTimeout)
+		resolver.Resolve(ctx, connections)
+	}
+	return connections, nil
+}
+
+// showResolved lists the current connections once with their processes and, when
+// resolving DNS, remote host names
+func (n *NetstatCommand) showResolved(ctx context.Context, options liveOptions, startTime time.Time) *commands.Result {
+	connections, err := sampleResolved(ctx, n.Runner(), true, options.resolver)
+	if err != nil {
+		return commands.ErrorResult("", fmt.Errorf("failed to sample connections: %w", err), startTime)
+	}
+
+	options.snapshot = true
+	rates := ConnectionRates(nil, connections, 0)
+
+	return &commands.Result{
+		Output:   renderLiveFrame(rates, options, 1, time.Now()),
+		Stderr:   color.New(color.FgHiBlack).Sprintf("Completed in %v\n", time.Since(startTime).Round(time.Millisecond)),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}
+}
+
+// netstatArgs maps the selected views onto the system netstat's flags
+func netstatArgs(all, numeric, processes, routing, statistics bool) []string {
+	var cmdArgs []string
+	if all {
+		cmdArgs = append(cmdArgs, "-a")
+	}
+	if numeric {
+		cmdArgs = append(cmdArgs, "-n")
+	}
+	if processes {
+		// Windows reports the owning PID with -o
+		if runtime.GOOS == "windows" {
+			cmdArgs = append(cmdArgs, "-o")
+		} else {
+			cmdArgs = append(cmdArgs, "-p")
+		}
+	}
+	if routing {
+		cmdArgs = append(cmdArgs, "-r")
+	}
+	if statistics {
+		cmdArgs = append(cmdArgs, "-s")
+	}
+	if len(cmdArgs) == 0 {
+		cmdArgs = []string{"-an"}
+	}
+	return cmdArgs
+}
+
+// showQuiet returns the system netstat's output as is, without the banner,
+// progress spinner or summary
+func (n *NetstatCommand) showQuiet(ctx context.Context, cmdArgs []string, startTime time.Time) *commands.Result {
+	cmdOutput, err := n.Runner().Run(ctx, "netstat", cmdArgs...)
+	if err != nil {
+		return commands.ErrorResult("", err, startTime)
+	}
+	return &commands.Result{
+		Output:   string(cmdOutput),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}
+}
+
+// netstatConnection is one connection in netstat's structured output
+type netstatConnection struct {
+	Local         string `json:"local"`
+	Remote        string `json:"remote"`
+	State         string `json:"state"`
+	PID           int    `json:"pid,omitempty"`
+	Process       string `json:"process,omitempty"`
+	RemoteHost    string `json:"remote_host,omitempty"`
+	BytesSent     int64  `json:"bytes_sent,omitempty"`
+	BytesReceived int64  `json:"bytes_received,omitempty"`
+}
+
+// OutputFormats returns the structured formats netstat supports
+func (n *NetstatCommand) OutputFormats() []commands.OutputFormat {
+	return []commands.OutputFormat{commands.FormatJSON, commands.FormatCSV}
+}
+
+// showStructured lists the current TCP connections as JSON or CSV. Processes are
+// included with -p or a resolver, host names when the resolver looks them up.
+func (n *NetstatCommand) showStructured(ctx context.Context, format commands.OutputFormat, withProcesses bool, resolver *ConnResolver, startTime time.Time) *commands.Result {
+	samples, err := sampleResolved(ctx, n.Runner(), withProcesses || resolver != nil, resolver)
+	if err != nil {
+		return commands.ErrorResult("", err, startTime)
+	}
+
+	connections := make([]netstatConnection, 0, len(samples))
+	rows := make([][]string, 0, len(samples))
+	for _, sample := range samples {
+		connection := netstatConnection{
+			Local:      sample.Local,
+			Remote:     sample.Remote,
+			State:      sample.State,
+			PID:        sample.PID,
+			Process:    sample.Process,
+			RemoteHost: sample.RemoteHost,
+		}
+		if sample.HasBytes {
+			connection.BytesSent = sample.Sent
+			connection.BytesReceived = sample.Received
+		}
+		connections = append(connections, connection)
+
+		pid := ""
+		if sample.PID != 0 {
+			pid = strconv.Itoa(sample.PID)
+		}
+		rows = append(rows, []string{connection.Local, connection.Remote, connection.State, pid, connection.Process,
+			connection.RemoteHost, strconv.FormatInt(connection.BytesSent, 10), strconv.FormatInt(connection.BytesReceived, 10)})
+	}
+
+	if format == commands.FormatCSV {
+		return commands.CSVResult([]string{"local", "remote", "state", "pid", "process", "remote_host", "bytes_sent", "bytes_received"}, rows, startTime)
+	}
+	result, err := commands.JSONResult(connections, startTime)
+	if err != nil {
+		return commands.ErrorResult("", err, startTime)
+	}
+	return result
+}
+
+// usageError reports a bad argument along with the usage line
+func (n *NetstatCommand) usageError(message string, startTime time.Time) *commands.Result {
+	return &commands.Result{
+		Output:   "Usage: " + n.Usage() + "\n",
+		Error:    commands.UsageError(n.Name(), "%s", message),
+		ExitCode: 1,
+		Duration: time.Since(startTime),
+	}
+}
+
+// showProgress shows a spinner on progress during netstat execution
+func (n *NetstatCommand) showProgress(progress io.Writer, done chan bool) {
 	spinner := []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
 	i := 0
 
@@ -208,7 +398,7 @@ func (n *NetstatCommand) showProgress(done chan bool) {
 		case <-done:
 			return
 		default:
-			fmt.Printf("\r📊 Gathering network information %s",
+			fmt.Fprintf(progress, "\r📊 Gathering network information %s",
 				color.New(color.FgYellow).Sprint(spinner[i%len(spinner)]))
 			time.Sleep(100 * time.Millisecond)
 			i++
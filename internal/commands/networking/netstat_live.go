@@ -0,0 +1,502 @@
+package networking
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/security"
+
+	"github.com/fatih/color"
+)
+
+// netstatLiveRows is how many connections a live frame lists
+const netstatLiveRows = 30
+
+// tcpStates maps the state codes in /proc/net/tcp to their names
+var tcpStates = map[string]string{
+	"01": "ESTABLISHED", "02": "SYN_SENT", "03": "SYN_RECV", "04": "FIN_WAIT1",
+	"05": "FIN_WAIT2", "06": "TIME_WAIT", "07": "CLOSE", "08": "CLOSE_WAIT",
+	"09": "LAST_ACK", "0A": "LISTEN", "0B": "CLOSING",
+}
+
+// ssStates maps the abbreviated states printed by ss to the names netstat uses
+var ssStates = map[string]string{
+	"ESTAB": "ESTABLISHED", "SYN-SENT": "SYN_SENT", "SYN-RECV": "SYN_RECV",
+	"FIN-WAIT-1": "FIN_WAIT1", "FIN-WAIT-2": "FIN_WAIT2", "TIME-WAIT": "TIME_WAIT",
+	"CLOSE-WAIT": "CLOSE_WAIT", "LAST-ACK": "LAST_ACK", "UNCONN": "CLOSE",
+}
+
+// ConnSample is one TCP connection as seen by a single sample
+type ConnSample struct {
+	Local    string
+	Remote   string
+	State    string
+	Sent     int64
+	Received int64
+	// HasBytes is false when the platform doesn't expose per-connection counters
+	HasBytes bool
+	// PID is the owning process, 0 when unknown or not requested
+	PID     int
+	Process string
+	// RemoteHost is the reverse-DNS name of the remote address, once resolved
+	RemoteHost string
+	// Inode is the socket inode from /proc, used to find the owning process
+	Inode string
+}
+
+// Key identifies a connection across samples
+func (c ConnSample) Key() string {
+	return c.Local + " " + c.Remote
+}
+
+// ConnRate is a connection with its transfer rates since the previous sample
+type ConnRate struct {
+	ConnSample
+	SendRate float64
+	RecvRate float64
+	// Known is false until the connection has been seen in two samples
+	Known bool
+}
+
+// liveOptions controls the live connection monitor
+type liveOptions struct {
+	interval time.Duration
+	count    int
+	filter   string
+	group    bool
+	// resolver adds process and host names; nil shows raw addresses only
+	resolver *ConnResolver
+	// snapshot renders a single table without rates or refresh details
+	snapshot bool
+}
+
+// runLive redraws the connection table in place until Enter is pressed, count
+// samples have been shown, or ctx is cancelled. It returns the samples shown.
+func (n *NetstatCommand) runLive(ctx context.Context, options liveOptions) (int, error) {
+	stop := stopOnEnter(ctx, options.count)
+	out := commands.OutputWriter(ctx)
+
+	var previous map[string]ConnSample
+	var previousAt time.Time
+	samples := 0
+
+	for {
+		connections, err := sampleConnections(ctx, n.Runner(), options.resolver != nil)
+		if err != nil {
+			return samples, err
+		}
+		if options.resolver != nil {
+			options.resolver.Resolve(ctx, connections)
+		}
+		now := time.Now()
+		rates := ConnectionRates(previous, connections, now.Sub(previousAt))
+		samples++
+
+		fmt.Fprint(out, "\033[H\033[2J")
+		fmt.Fprint(out, renderLiveFrame(rates, options, samples, now))
+
+		previous = make(map[string]ConnSample, len(connections))
+		for _, connection := range connections {
+			previous[connection.Key()] = connection
+		}
+		previousAt = now
+
+		if options.count > 0 && samples >= options.count {
+			return samples, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return samples, nil
+		case <-stop:
+			return samples, nil
+		case <-time.After(options.interval):
+		}
+	}
+}
+
+// stopOnEnter is security.StopOnEnter for a run that is open-ended when count
+// is 0. A run with a sample count never reads stdin, so no reader is left
+// behind to steal the shell's next line.
+func stopOnEnter(ctx context.Context, count int) <-chan struct{} {
+	if count > 0 {
+		return nil
+	}
+	return security.StopOnEnter(ctx)
+}
+
+// sampleConnections reads the current TCP connections. On Linux byte counters come
+// from ss -i, falling back to /proc/net/tcp without them; elsewhere netstat -an
+// provides the connection list only. withProcesses also looks up the owning PIDs,
+// which macOS's netstat doesn't report.
+func sampleConnections(ctx context.Context, runner commands.CommandRunner, withProcesses bool) ([]ConnSample, error) {
+	if runtime.GOOS == "linux" {
+		ssFlags := "-tin"
+		if withProcesses {
+			ssFlags += "p"
+		}
+		if out, err := runner.Run(ctx, "ss", ssFlags); err == nil {
+			return ParseSSConnections(string(out)), nil
+		}
+		connections, err := readProcConnections()
+		if err == nil && withProcesses {
+			owners := socketOwners()
+			for i := range connections {
+				connections[i].PID = owners[connections[i].Inode]
+			}
+		}
+		return connections, err
+	}
+
+	netstatFlags := "-an"
+	if withProcesses && runtime.GOOS == "windows" {
+		netstatFlags = "-ano"
+	}
+	out, err := runner.Run(ctx, "netstat", netstatFlags)
+	if err != nil {
+		return nil, err
+	}
+	return ParseNetstatConnections(string(out)), nil
+}
+
+// ParseSSConnections parses `ss -tin`, where each connection line is followed by an
+// indented line of TCP info that carries the byte counters. With -p the connection
+// line ends in the owning process.
+func ParseSSConnections(text string) []ConnSample {
+	var connections []ConnSample
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "State") {
+			continue
+		}
+
+		if line[0] == ' ' || line[0] == '\t' {
+			if len(connections) == 0 {
+				continue
+			}
+			last := &connections[len(connections)-1]
+			for _, field := range strings.Fields(line) {
+				switch {
+				case strings.HasPrefix(field, "bytes_sent:"):
+					last.Sent, _ = strconv.ParseInt(field[len("bytes_sent:"):], 10, 64)
+					last.HasBytes = true
+				case strings.HasPrefix(field, "bytes_acked:") && last.Sent == 0:
+					// Older ss versions only report acknowledged bytes
+					last.Sent, _ = strconv.ParseInt(field[len("bytes_acked:"):], 10, 64)
+					last.HasBytes = true
+				case strings.HasPrefix(field, "bytes_received:"):
+					last.Received, _ = strconv.ParseInt(field[len("bytes_received:"):], 10, 64)
+					last.HasBytes = true
+				}
+			}
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 5 {
+			continue
+		}
+		state := fields[0]
+		if name, ok := ssStates[state]; ok {
+			state = name
+		}
+		process, pid := ParseSSProcess(line)
+		connections = append(connections, ConnSample{Local: fields[3], Remote: fields[4], State: state, PID: pid, Process: process})
+	}
+	return connections
+}
+
+// readProcConnections reads the IPv4 and IPv6 connection tables from /proc
+func readProcConnections() ([]ConnSample, error) {
+	var connections []ConnSample
+	for _, path := range []string{"/proc/net/tcp", "/proc/net/tcp6"} {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			if path == "/proc/net/tcp" {
+				return nil, err
+			}
+			continue
+		}
+		connections = append(connections, ParseProcConnections(string(data))...)
+	}
+	return connections, nil
+}
+
+// ParseProcConnections parses one /proc/net/tcp table, skipping listening sockets
+func ParseProcConnections(text string) []ConnSample {
+	var connections []ConnSample
+	lines := strings.Split(text, "\n")
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line)
+		if len(fields) < 10 || fields[3] == "0A" {
+			continue
+		}
+		local, localOK := DecodeProcAddress(fields[1])
+		remote, remoteOK := DecodeProcAddress(fields[2])
+		if !localOK || !remoteOK {
+			continue
+		}
+		state := tcpStates[fields[3]]
+		if state == "" {
+			state = fields[3]
+		}
+		connections = append(connections, ConnSample{Local: local, Remote: remote, State: state, Inode: fields[9]})
+	}
+	return connections
+}
+
+// DecodeProcAddress decodes a hex "address:port" from /proc/net/tcp. Addresses are
+// stored as 32-bit words in host byte order, which is little-endian on Linux's
+// common platforms.
+func DecodeProcAddress(field string) (string, bool) {
+	parts := strings.Split(field, ":")
+	if len(parts) != 2 {
+		return "", false
+	}
+	raw, err := hex.DecodeString(parts[0])
+	if err != nil || (len(raw) != 4 && len(raw) != 16) {
+		return "", false
+	}
+	port, err := strconv.ParseUint(parts[1], 16, 16)
+	if err != nil {
+		return "", false
+	}
+
+	ip := make(net.IP, len(raw))
+	for word := 0; word < len(raw); word += 4 {
+		for i := 0; i < 4; i++ {
+			ip[word+i] = raw[word+3-i]
+		}
+	}
+	return net.JoinHostPort(ip.String(), strconv.FormatUint(port, 10)), true
+}
+
+// ParseNetstatConnections parses the TCP lines of `netstat -an` on Windows and macOS,
+// including the PID column that -o adds on Windows
+func ParseNetstatConnections(text string) []ConnSample {
+	var connections []ConnSample
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || !strings.HasPrefix(strings.ToLower(fields[0]), "tcp") {
+			continue
+		}
+
+		var sample ConnSample
+		switch {
+		case strings.EqualFold(fields[0], "TCP"): // Windows: TCP local remote state
+			sample = ConnSample{Local: fields[1], Remote: fields[2], State: fields[3]}
+			if len(fields) >= 5 {
+				sample.PID, _ = strconv.Atoi(fields[4])
+			}
+		case len(fields) >= 6: // macOS: tcp4 recv-q send-q local remote state
+			sample = ConnSample{Local: fields[3], Remote: fields[4], State: fields[5]}
+		default:
+			continue
+		}
+		if state := strings.ToUpper(sample.State); state == "LISTEN" || state == "LISTENING" {
+			continue
+		}
+		connections = append(connections, sample)
+	}
+	return connections
+}
+
+// ConnectionRates pairs each connection with its rates since the previous sample
+func ConnectionRates(previous map[string]ConnSample, current []ConnSample, elapsed time.Duration) []ConnRate {
+	rates := make([]ConnRate, len(current))
+	for i, connection := range current {
+		rates[i] = ConnRate{ConnSample: connection}
+
+		before, seen := previous[connection.Key()]
+		if !seen || !connection.HasBytes || elapsed <= 0 ||
+			connection.Sent < before.Sent || connection.Received < before.Received {
+			continue
+		}
+		rates[i].Known = true
+		rates[i].SendRate = float64(connection.Sent-before.Sent) / elapsed.Seconds()
+		rates[i].RecvRate = float64(connection.Received-before.Received) / elapsed.Seconds()
+	}
+
+	// Busiest connections first
+	sort.SliceStable(rates, func(i, j int) bool {
+		a, b := rates[i].SendRate+rates[i].RecvRate, rates[j].SendRate+rates[j].RecvRate
+		if a != b {
+			return a > b
+		}
+		return rates[i].Key() < rates[j].Key()
+	})
+	return rates
+}
+
+// matchesFilter reports whether a connection matches a case-insensitive filter on its
+// addresses, ports, state, process or remote host name
+func (c ConnRate) matchesFilter(filter string) bool {
+	if filter == "" {
+		return true
+	}
+	filter = strings.ToLower(filter)
+	return strings.Contains(strings.ToLower(c.Local+" "+c.Remote+" "+c.State+" "+c.Process+" "+c.RemoteHost), filter)
+}
+
+// RemoteLabel returns the remote address, with the host name in place of the IP once
+// it has been resolved
+func (c ConnSample) RemoteLabel() string {
+	if c.RemoteHost == "" {
+		return c.Remote
+	}
+	if _, port, err := net.SplitHostPort(c.Remote); err == nil {
+		return net.JoinHostPort(c.RemoteHost, port)
+	}
+	return c.RemoteHost
+}
+
+// ProcessLabel returns "name(pid)", or just the PID when the name is unknown
+func (c ConnSample) ProcessLabel() string {
+	switch {
+	case c.PID == 0:
+		return "-"
+	case c.Process == "":
+		return strconv.Itoa(c.PID)
+	default:
+		return fmt.Sprintf("%s(%d)", c.Process, c.PID)
+	}
+}
+
+// FitColumn shortens a value that would overflow its column, keeping the end, which
+// holds the port
+func FitColumn(value string, width int) string {
+	if len(value) <= width {
+		return value
+	}
+	return "..." + value[len(value)-width+3:]
+}
+
+// renderLiveFrame renders one refresh of the live monitor
+func renderLiveFrame(rates []ConnRate, options liveOptions, sample int, now time.Time) string {
+	var output strings.Builder
+
+	if options.snapshot {
+		output.WriteString(color.New(color.FgCyan, color.Bold).Sprint("🌐 NETWORK CONNECTIONS"))
+		output.WriteString(color.New(color.FgHiBlack).Sprintf("  %s\n", now.Format("15:04:05")))
+	} else {
+		output.WriteString(color.New(color.FgCyan, color.Bold).Sprint("🌐 LIVE CONNECTIONS"))
+		output.WriteString(color.New(color.FgHiBlack).Sprintf("  refresh %v · sample %d · %s\n",
+			options.interval, sample, now.Format("15:04:05")))
+	}
+	output.WriteString("═══════════════════════════════════════════════════════════════════════════════\n")
+
+	var shown []ConnRate
+	var totalSend, totalRecv float64
+	for _, rate := range rates {
+		if rate.matchesFilter(options.filter) {
+			shown = append(shown, rate)
+			totalSend += rate.SendRate
+			totalRecv += rate.RecvRate
+		}
+	}
+
+	if options.group {
+		groups := make(map[string][]ConnRate)
+		var states []string
+		for _, rate := range shown {
+			state := strings.ToUpper(rate.State)
+			if _, ok := groups[state]; !ok {
+				states = append(states, state)
+			}
+			groups[state] = append(groups[state], rate)
+		}
+		sort.Strings(states)
+		for _, state := range states {
+			output.WriteString(color.New(color.FgYellow, color.Bold).Sprintf("\n%s %s (%d)\n", stateIcon(state), state, len(groups[state])))
+			writeLiveRows(&output, groups[state], options)
+		}
+	} else {
+		writeLiveRows(&output, shown, options)
+	}
+
+	output.WriteString("───────────────────────────────────────────────────────────────────────────────\n")
+	if options.snapshot {
+		output.WriteString(fmt.Sprintf("📊 %d connections\n", len(shown)))
+	} else {
+		output.WriteString(fmt.Sprintf("📊 %d connections · ↑ %s/s · ↓ %s/s\n", len(shown), commands.HumanizeBytes(int64(totalSend)), commands.HumanizeBytes(int64(totalRecv))))
+	}
+	if options.filter != "" {
+		output.WriteString(fmt.Sprintf("🔎 Filter: %s (%d of %d)\n", color.New(color.FgYellow).Sprint(options.filter), len(shown), len(rates)))
+	}
+	if len(rates) > 0 && !rates[0].HasBytes && !options.snapshot {
+		output.WriteString(color.New(color.FgHiBlack).Sprint("💡 Per-connection byte counters are not available on this system\n"))
+	}
+	if options.resolver != nil && options.resolver.dns && !options.snapshot {
+		output.WriteString(color.New(color.FgHiBlack).Sprint("💡 Host names appear as reverse lookups complete\n"))
+	}
+	if options.count == 0 && !options.snapshot {
+		output.WriteString(color.New(color.FgHiBlack).Sprint("Press Enter to stop\n"))
+	}
+
+	return output.String()
+}
+
+// writeLiveRows writes a table of connections with their rates, and their processes
+// when resolving
+func writeLiveRows(output *strings.Builder, rates []ConnRate, options liveOptions) {
+	resolve := options.resolver != nil
+
+	header := fmt.Sprintf("   %-28s %-28s %-12s", "Local", "Remote", "State")
+	if !options.snapshot {
+		// The arrows take three bytes but one column, hence the wider verbs
+		header += fmt.Sprintf(" %13s %13s", "↑ Send", "↓ Recv")
+	}
+	if resolve {
+		header += "  Process"
+	}
+	output.WriteString(color.New(color.FgCyan).Sprint(header + "\n"))
+
+	for i, rate := range rates {
+		if i == netstatLiveRows {
+			output.WriteString(fmt.Sprintf("   ... and %d more\n", len(rates)-i))
+			break
+		}
+		row := fmt.Sprintf("%s %-28s %-28s %-12s",
+			stateIcon(rate.State), FitColumn(rate.Local, 28), FitColumn(rate.RemoteLabel(), 28), rate.State)
+		if !options.snapshot {
+			send, recv := "—", "—"
+			if rate.Known {
+				send = commands.HumanizeBytes(int64(rate.SendRate)) + "/s"
+				recv = commands.HumanizeBytes(int64(rate.RecvRate)) + "/s"
+			}
+			row += fmt.Sprintf(" %11s %11s", send, recv)
+		}
+		if resolve {
+			row += "  " + rate.ProcessLabel()
+		}
+		output.WriteString(row + "\n")
+	}
+}
+
+// stateIcon returns the colored marker used for a connection state
+func stateIcon(state string) string {
+	switch strings.ToUpper(state) {
+	case "ESTABLISHED":
+		return color.New(color.FgGreen).Sprint("🟢")
+	case "TIME_WAIT":
+		return color.New(color.FgYellow).Sprint("🟡")
+	case "CLOSE_WAIT":
+		return color.New(color.FgRed).Sprint("🔴")
+	case "SYN_SENT", "SYN_RECV":
+		return color.New(color.FgMagenta).Sprint("🟣")
+	default:
+		return "⚪"
+	}
+}
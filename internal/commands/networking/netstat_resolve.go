@@ -0,0 +1,227 @@
+package networking
+
+import (
+	"context"
+	"encoding/csv"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"suppercommand/internal/commands"
+)
+
+// netstatLookupTimeout bounds a single reverse-DNS lookup
+const netstatLookupTimeout = 2 * time.Second
+
+// ConnResolver fills in process names and remote host names for connections. Both
+// are cached so a live refresh never waits on a lookup it has already made; reverse
+// DNS runs in the background and shows up on a later refresh.
+type ConnResolver struct {
+	dns    bool
+	runner commands.CommandRunner
+	// processTable lists the running processes by PID
+	processTable func(ctx context.Context) map[int]string
+
+	mu        sync.Mutex
+	processes map[int]string
+	hosts     map[string]string
+	pending   map[string]bool
+	lookups   sync.WaitGroup
+}
+
+// NewConnResolver creates a resolver; dns enables reverse lookups of remote addresses
+func NewConnResolver(dns bool, runner commands.CommandRunner) *ConnResolver {
+	r := &ConnResolver{
+		dns:       dns,
+		runner:    runner,
+		processes: make(map[int]string),
+		hosts:     make(map[string]string),
+		pending:   make(map[string]bool),
+	}
+	r.processTable = r.listProcesses
+	return r
+}
+
+// SetProcessTable replaces how the resolver lists the running processes
+func (r *ConnResolver) SetProcessTable(table func(ctx context.Context) map[int]string) {
+	r.processTable = table
+}
+
+// Resolve annotates connections in place
+func (r *ConnResolver) Resolve(ctx context.Context, connections []ConnSample) {
+	refreshed := false
+	for i := range connections {
+		connection := &connections[i]
+
+		if connection.PID > 0 && connection.Process == "" {
+			name, ok := r.processName(connection.PID)
+			if !ok && !refreshed {
+				// A process we haven't seen; reload the table once per sample
+				r.loadProcesses(ctx)
+				refreshed = true
+				name, _ = r.processName(connection.PID)
+			}
+			connection.Process = name
+		}
+
+		if r.dns {
+			connection.RemoteHost = r.hostName(ctx, connection.Remote)
+		}
+	}
+}
+
+// wait gives outstanding reverse lookups up to timeout to finish, for one-shot output
+func (r *ConnResolver) wait(timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		r.lookups.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+	}
+}
+
+// processName returns the cached name of a process
+func (r *ConnResolver) processName(pid int) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	name, ok := r.processes[pid]
+	return name, ok
+}
+
+// loadProcesses refreshes the PID to name table
+func (r *ConnResolver) loadProcesses(ctx context.Context) {
+	names := r.processTable(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for pid, name := range names {
+		r.processes[pid] = name
+	}
+}
+
+// listProcesses maps the running processes to their names. Linux reads names per
+// PID from /proc; Windows lists every process with tasklist.
+func (r *ConnResolver) listProcesses(ctx context.Context) map[int]string {
+	names := make(map[int]string)
+	switch runtime.GOOS {
+	case "linux":
+		entries, _ := ioutil.ReadDir("/proc")
+		for _, entry := range entries {
+			pid, err := strconv.Atoi(entry.Name())
+			if err != nil {
+				continue
+			}
+			if comm, err := ioutil.ReadFile(filepath.Join("/proc", entry.Name(), "comm")); err == nil {
+				names[pid] = strings.TrimSpace(string(comm))
+			}
+		}
+	case "windows":
+		out, err := r.runner.Run(ctx, "tasklist", "/FO", "CSV", "/NH")
+		if err != nil {
+			break
+		}
+		records, _ := csv.NewReader(strings.NewReader(string(out))).ReadAll()
+		for _, record := range records {
+			if len(record) < 2 {
+				continue
+			}
+			if pid, err := strconv.Atoi(record[1]); err == nil {
+				names[pid] = record[0]
+			}
+		}
+	}
+	return names
+}
+
+// hostName returns the cached reverse-DNS name for the host of address, starting a
+// lookup when there is none yet. It returns "" until a name is known.
+func (r *ConnResolver) hostName(ctx context.Context, address string) string {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsUnspecified() {
+		return ""
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if name, ok := r.hosts[host]; ok || r.pending[host] {
+		return name
+	}
+
+	r.pending[host] = true
+	r.lookups.Add(1)
+	go func() {
+		defer r.lookups.Done()
+		lookupCtx, cancel := context.WithTimeout(ctx, netstatLookupTimeout)
+		defer cancel()
+
+		name := ""
+		if names, err := net.DefaultResolver.LookupAddr(lookupCtx, host); err == nil && len(names) > 0 {
+			name = strings.TrimSuffix(names[0], ".")
+		}
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.hosts[host] = name
+		delete(r.pending, host)
+	}()
+	return ""
+}
+
+// socketOwners maps socket inodes to the PIDs holding them open, by scanning the file
+// descriptors in /proc. Processes of other users are skipped unless running as root.
+func socketOwners() map[string]int {
+	owners := make(map[string]int)
+	entries, _ := ioutil.ReadDir("/proc")
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		fdDir := filepath.Join("/proc", entry.Name(), "fd")
+		fds, err := ioutil.ReadDir(fdDir)
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			link, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+			if err == nil && strings.HasPrefix(link, "socket:[") {
+				owners[strings.TrimSuffix(strings.TrimPrefix(link, "socket:["), "]")] = pid
+			}
+		}
+	}
+	return owners
+}
+
+// ParseSSProcess extracts the first process from the users:(("name",pid=N,fd=M))
+// column that ss -p adds
+func ParseSSProcess(line string) (string, int) {
+	start := strings.Index(line, `users:(("`)
+	if start < 0 {
+		return "", 0
+	}
+	fields := strings.SplitN(line[start+len(`users:(("`):], ")", 2)[0]
+	quote := strings.Index(fields, `"`)
+	if quote < 0 {
+		return "", 0
+	}
+
+	pid := 0
+	for _, part := range strings.Split(fields[quote+1:], ",") {
+		if strings.HasPrefix(part, "pid=") {
+			pid, _ = strconv.Atoi(strings.TrimPrefix(part, "pid="))
+		}
+	}
+	return fields[:quote], pid
+}
@@ -0,0 +1,280 @@
+package networking
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+
+	"github.com/fatih/color"
+)
+
+// watchOptions controls netstat --watch-new
+type watchOptions struct {
+	interval time.Duration
+	count    int
+	filter   string
+	// remote limits the reported connections to a remote address; nil allows any
+	remote *remoteFilter
+	closed bool
+	notify bool
+	// resolver adds process and host names; nil shows raw addresses only
+	resolver *ConnResolver
+}
+
+// watchSummary counts what a watch reported
+type watchSummary struct {
+	samples  int
+	baseline int
+	opened   int
+	closed   int
+}
+
+// remoteFilter matches the remote end of a connection against an IP, a CIDR
+// range or a host name, optionally with a port
+type remoteFilter struct {
+	network *net.IPNet
+	ips     []net.IP
+	name    string
+	port    string
+}
+
+// parseRemoteFilter parses a --remote value: 203.0.113.7, 10.0.0.0/8,
+// example.com or any of them with :port. Host names are looked up once, so
+// connections are matched by the addresses they had when the watch started.
+func parseRemoteFilter(ctx context.Context, value string) (*remoteFilter, error) {
+	filter := &remoteFilter{}
+	host := value
+	if h, port, err := net.SplitHostPort(value); err == nil {
+		host, filter.port = h, port
+	}
+	if host == "" || host == "*" {
+		if filter.port == "" {
+			return nil, fmt.Errorf("invalid --remote value '%s'", value)
+		}
+		return filter, nil
+	}
+
+	if _, network, err := net.ParseCIDR(host); err == nil {
+		filter.network = network
+		return filter, nil
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		filter.ips = []net.IP{ip}
+		return filter, nil
+	}
+
+	filter.name = host
+	addresses, err := net.DefaultResolver.LookupHost(ctx, host)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve --remote host '%s': %v", host, err)
+	}
+	for _, address := range addresses {
+		if ip := net.ParseIP(address); ip != nil {
+			filter.ips = append(filter.ips, ip)
+		}
+	}
+	return filter, nil
+}
+
+// matches reports whether a connection's remote end is one the filter names
+func (f *remoteFilter) matches(c ConnSample) bool {
+	if f == nil {
+		return true
+	}
+	host, port := splitEndpoint(c.Remote)
+	if f.port != "" && port != f.port {
+		return false
+	}
+	if f.network == nil && len(f.ips) == 0 && f.name == "" {
+		return true
+	}
+	if f.name != "" && strings.EqualFold(strings.TrimSuffix(c.RemoteHost, "."), f.name) {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	if f.network != nil {
+		return f.network.Contains(ip)
+	}
+	for _, want := range f.ips {
+		if want.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitEndpoint splits an address into host and port. Besides host:port and
+// [v6]:port it accepts the host.port form macOS's netstat prints.
+func splitEndpoint(address string) (string, string) {
+	if host, port, err := net.SplitHostPort(address); err == nil {
+		if i := strings.IndexByte(host, '%'); i >= 0 {
+			host = host[:i]
+		}
+		return host, port
+	}
+	if i := strings.LastIndexByte(address, '.'); i >= 0 {
+		return address[:i], address[i+1:]
+	}
+	return address, ""
+}
+
+// connectionChanges compares two samples, returning the connections that
+// appeared and the ones that went away, each sorted by address
+func connectionChanges(previous map[string]ConnSample, current []ConnSample) ([]ConnSample, []ConnSample) {
+	var opened, closed []ConnSample
+	seen := make(map[string]bool, len(current))
+	for _, connection := range current {
+		seen[connection.Key()] = true
+		if _, ok := previous[connection.Key()]; !ok {
+			opened = append(opened, connection)
+		}
+	}
+	for key, connection := range previous {
+		if !seen[key] {
+			closed = append(closed, connection)
+		}
+	}
+	sort.Slice(opened, func(i, j int) bool { return opened[i].Key() < opened[j].Key() })
+	sort.Slice(closed, func(i, j int) bool { return closed[i].Key() < closed[j].Key() })
+	return opened, closed
+}
+
+// runWatch takes a baseline of the current connections and then, every interval,
+// prints a timestamped line for each connection that has appeared since the last
+// sample, and with options.closed each that has gone. It stops like runLive.
+func (n *NetstatCommand) runWatch(ctx context.Context, options watchOptions) (watchSummary, error) {
+	stop := stopOnEnter(ctx, options.count)
+	out := commands.OutputWriter(ctx)
+	progress := commands.ProgressWriter(ctx)
+	summary := watchSummary{}
+	notifying := options.notify
+
+	previous := make(map[string]ConnSample)
+	for {
+		connections, err := sampleConnections(ctx, n.Runner(), options.resolver != nil)
+		if err != nil {
+			if ctx.Err() != nil {
+				return summary, nil
+			}
+			return summary, err
+		}
+		if options.resolver != nil {
+			options.resolver.Resolve(ctx, connections)
+		}
+		now := time.Now()
+		summary.samples++
+
+		if summary.samples == 1 {
+			summary.baseline = len(connections)
+			fmt.Fprint(progress, color.New(color.FgCyan, color.Bold).Sprint("👀 WATCHING FOR NEW CONNECTIONS"))
+			fmt.Fprint(progress, color.New(color.FgHiBlack).Sprintf("  baseline %d connections · every %v · %s\n",
+				len(connections), options.interval, now.Format("15:04:05")))
+			if options.count == 0 {
+				fmt.Fprint(progress, color.New(color.FgHiBlack).Sprint("Press Enter to stop\n"))
+			}
+		} else {
+			opened, closed := connectionChanges(previous, connections)
+			var matched []ConnSample
+			for _, connection := range opened {
+				if options.shows(connection) {
+					matched = append(matched, connection)
+					fmt.Fprintln(out, watchLine(now, "+", connection, options.resolver != nil))
+				}
+			}
+			summary.opened += len(matched)
+			if options.closed {
+				for _, connection := range closed {
+					if options.shows(connection) {
+						summary.closed++
+						fmt.Fprintln(out, watchLine(now, "-", connection, options.resolver != nil))
+					}
+				}
+			}
+
+			if notifying && len(matched) > 0 {
+				title, message := newConnectionNotice(matched)
+				if err := commands.SendNotification(ctx, title, message); err != nil {
+					// Keep watching, but say once why nothing pops up
+					fmt.Fprint(commands.ErrorWriter(ctx), color.New(color.FgYellow).Sprintf("⚠️  Notification not shown: %v\n", err))
+					notifying = false
+				}
+			}
+		}
+
+		previous = make(map[string]ConnSample, len(connections))
+		for _, connection := range connections {
+			previous[connection.Key()] = connection
+		}
+
+		if options.count > 0 && summary.samples >= options.count {
+			return summary, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return summary, nil
+		case <-stop:
+			return summary, nil
+		case <-time.After(options.interval):
+		}
+	}
+}
+
+// shows reports whether a change to a connection passes the watch's filters
+func (options watchOptions) shows(c ConnSample) bool {
+	return options.remote.matches(c) && ConnRate{ConnSample: c}.matchesFilter(options.filter)
+}
+
+// watchLine formats one reported change: + for a new connection, - for a closed one
+func watchLine(at time.Time, mark string, c ConnSample, withProcess bool) string {
+	line := fmt.Sprintf("%s %s %-28s → %-28s %-12s", at.Format("15:04:05"), mark, c.Local, c.RemoteLabel(), c.State)
+	if withProcess {
+		line += " " + c.ProcessLabel()
+	}
+	line = strings.TrimRight(line, " ")
+	if mark == "-" {
+		return color.New(color.FgRed).Sprint(line)
+	}
+	return color.New(color.FgGreen).Sprint(line)
+}
+
+// newConnectionNotice returns the title and message of the notification for the
+// connections that appeared in one sample
+func newConnectionNotice(opened []ConnSample) (string, string) {
+	first := opened[0]
+	target := first.RemoteLabel()
+	if first.PID > 0 {
+		target = first.ProcessLabel() + " → " + target
+	}
+	if len(opened) == 1 {
+		return "🔌 New connection", target
+	}
+	return fmt.Sprintf("🔌 %d new connections", len(opened)), fmt.Sprintf("%s and %d more", target, len(opened)-1)
+}
+
+// showWatch runs the watch and summarizes what it reported
+func (n *NetstatCommand) showWatch(ctx context.Context, options watchOptions, startTime time.Time) *commands.Result {
+	summary, err := n.runWatch(ctx, options)
+	if err != nil {
+		return commands.ErrorResult("", fmt.Errorf("failed to sample connections: %w", err), startTime)
+	}
+	outcome := fmt.Sprintf("%d new", summary.opened)
+	if options.closed {
+		outcome += fmt.Sprintf(", %d closed", summary.closed)
+	}
+	return &commands.Result{
+		Stderr: color.New(color.FgHiBlack).Sprintf("Watched connections for %v (%d samples, baseline %d): %s\n",
+			time.Since(startTime).Round(time.Second), summary.samples, summary.baseline, outcome),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}
+}
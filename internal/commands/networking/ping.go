@@ -108,6 +108,7 @@ func (p *PingCommand) Execute(ctx context.Context, args *commands.Arguments) (*c
 
 	// Start the command
 	if err := cmd.Start(); err != nil {
+		err = missingToolError(err)
 		return &commands.Result{
 			Output:   fmt.Sprintf("Failed to start ping: %v\n", err),
 			Error:    err,
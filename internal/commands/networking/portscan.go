@@ -3,6 +3,7 @@ package networking
 import (
 	"context"
 	"fmt"
+	"io"
 	"net"
 	"sort"
 	"strconv"
@@ -26,7 +27,7 @@ func NewPortscanCommand() *PortscanCommand {
 		BaseCommand: commands.NewBaseCommand(
 			"portscan",
 			"Fast TCP port scanner with live feedback",
-			"portscan [-p ports] [-t timeout] [-c concurrency] <host>",
+			"portscan [-p ports] [-t timeout] [-c concurrency] [--quiet] [--output-format text|json|csv] <host>",
 			[]string{"windows", "linux", "darwin"},
 			false,
 		),
@@ -39,7 +40,7 @@ func (p *PortscanCommand) Execute(ctx context.Context, args *commands.Arguments)
 
 	if len(args.Raw) == 0 {
 		return &commands.Result{
-			Output:   "Usage: portscan [-p ports] [-t timeout] [-c concurrency] <host>\n",
+			Output:   "Usage: " + p.Usage() + "\n",
 			ExitCode: 1,
 			Duration: time.Since(startTime),
 		}, nil
@@ -88,11 +89,15 @@ func (p *PortscanCommand) Execute(ctx context.Context, args *commands.Arguments)
 		}, nil
 	}
 
+	options := commands.OutputOptionsFrom(ctx)
+	progress := commands.ProgressWriter(ctx)
 	var output strings.Builder
 
 	// Header
-	output.WriteString(color.New(color.FgCyan, color.Bold).Sprintf("🎯 PORT SCAN: %s\n", host))
-	output.WriteString("═══════════════════════════════════════════════════════════════\n\n")
+	if options.Decorated() {
+		output.WriteString(color.New(color.FgCyan, color.Bold).Sprintf("🎯 PORT SCAN: %s\n", host))
+		output.WriteString("═══════════════════════════════════════════════════════════════\n\n")
+	}
 
 	// Parse port range
 	portList, err := p.parsePortRange(ports)
@@ -106,15 +111,17 @@ func (p *PortscanCommand) Execute(ctx context.Context, args *commands.Arguments)
 	}
 
 	// Show scan parameters
-	output.WriteString(color.New(color.FgBlue, color.Bold).Sprint("📋 Scan Parameters:\n"))
-	output.WriteString(fmt.Sprintf("  Target:       %s\n", color.New(color.FgWhite, color.Bold).Sprint(host)))
-	output.WriteString(fmt.Sprintf("  Ports:        %s (%d ports)\n", ports, len(portList)))
-	output.WriteString(fmt.Sprintf("  Timeout:      %v\n", timeout))
-	output.WriteString(fmt.Sprintf("  Concurrency:  %d\n", concurrency))
-	output.WriteString("\n")
+	if options.Decorated() {
+		output.WriteString(color.New(color.FgBlue, color.Bold).Sprint("📋 Scan Parameters:\n"))
+		output.WriteString(fmt.Sprintf("  Target:       %s\n", color.New(color.FgWhite, color.Bold).Sprint(host)))
+		output.WriteString(fmt.Sprintf("  Ports:        %s (%d ports)\n", ports, len(portList)))
+		output.WriteString(fmt.Sprintf("  Timeout:      %v\n", timeout))
+		output.WriteString(fmt.Sprintf("  Concurrency:  %d\n", concurrency))
+		output.WriteString("\n")
+	}
 
 	// Resolve host
-	fmt.Printf("🔍 Resolving %s...\n", host)
+	fmt.Fprintf(progress, "🔍 Resolving %s...\n", host)
 	ips, err := net.LookupIP(host)
 	if err != nil {
 		output.WriteString(color.New(color.FgRed).Sprintf("❌ Failed to resolve host: %v\n", err))
@@ -144,12 +151,17 @@ func (p *PortscanCommand) Execute(ctx context.Context, args *commands.Arguments)
 		}, nil
 	}
 
-	output.WriteString(color.New(color.FgGreen).Sprintf("✅ Resolved to: %s\n\n", targetIP))
-
 	// Start scanning
-	fmt.Printf("🚀 Scanning %d ports...\n\n", len(portList))
+	fmt.Fprintf(progress, "🚀 Scanning %d ports...\n\n", len(portList))
 
-	openPorts := p.scanPorts(ctx, targetIP, portList, timeout, concurrency)
+	openPorts := p.scanPorts(ctx, targetIP, portList, timeout, concurrency, progress)
+	sort.Ints(openPorts)
+
+	if !options.Decorated() {
+		return p.report(options, host, targetIP, len(portList), openPorts, ctx.Err() != nil, startTime)
+	}
+
+	output.WriteString(color.New(color.FgGreen).Sprintf("✅ Resolved to: %s\n\n", targetIP))
 
 	// Results
 	output.WriteString(color.New(color.FgYellow, color.Bold).Sprint("📊 SCAN RESULTS\n"))
@@ -160,9 +172,6 @@ func (p *PortscanCommand) Execute(ctx context.Context, args *commands.Arguments)
 	} else {
 		output.WriteString(color.New(color.FgGreen, color.Bold).Sprintf("✅ Found %d open port(s):\n\n", len(openPorts)))
 
-		// Sort ports
-		sort.Ints(openPorts)
-
 		for _, port := range openPorts {
 			service := p.getServiceName(port)
 			output.WriteString(fmt.Sprintf("  %s %d/tcp %s\n",
@@ -173,6 +182,15 @@ func (p *PortscanCommand) Execute(ctx context.Context, args *commands.Arguments)
 	}
 
 	output.WriteString("\n═══════════════════════════════════════════════════════════════\n")
+	if ctx.Err() != nil {
+		output.WriteString(color.New(color.FgYellow).Sprintf("⚠️  Scan interrupted after %v, results are partial\n",
+			time.Since(startTime).Round(time.Millisecond)))
+		return &commands.Result{
+			Output:   output.String(),
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}, nil
+	}
 	output.WriteString(color.New(color.FgHiBlack).Sprintf("Scan completed in %v\n",
 		time.Since(startTime).Round(time.Millisecond)))
 
@@ -183,6 +201,66 @@ func (p *PortscanCommand) Execute(ctx context.Context, args *commands.Arguments)
 	}, nil
 }
 
+// portscanReport is the structured form of a scan
+type portscanReport struct {
+	Host         string         `json:"host"`
+	IP           string         `json:"ip"`
+	PortsScanned int            `json:"ports_scanned"`
+	Open         []portscanOpen `json:"open"`
+	// Partial is set when the scan was interrupted
+	Partial         bool    `json:"partial"`
+	DurationSeconds float64 `json:"duration_seconds"`
+}
+
+// portscanOpen is an open port and its usual service
+type portscanOpen struct {
+	Port    int    `json:"port"`
+	Service string `json:"service"`
+}
+
+// OutputFormats returns the structured formats portscan supports
+func (p *PortscanCommand) OutputFormats() []commands.OutputFormat {
+	return []commands.OutputFormat{commands.FormatJSON, commands.FormatCSV}
+}
+
+// report renders the open ports as JSON, CSV or, when quiet, one "port/tcp service"
+// line each. An interrupted scan still fails so scripts don't trust partial results.
+func (p *PortscanCommand) report(options commands.OutputOptions, host, ip string, scanned int, openPorts []int, partial bool, startTime time.Time) (*commands.Result, error) {
+	report := portscanReport{
+		Host:            host,
+		IP:              ip,
+		PortsScanned:    scanned,
+		Open:            make([]portscanOpen, 0, len(openPorts)),
+		Partial:         partial,
+		DurationSeconds: time.Since(startTime).Seconds(),
+	}
+	rows := make([][]string, 0, len(openPorts))
+	var lines strings.Builder
+	for _, port := range openPorts {
+		service := p.getServiceName(port)
+		report.Open = append(report.Open, portscanOpen{Port: port, Service: service})
+		rows = append(rows, []string{strconv.Itoa(port), "tcp", service})
+		lines.WriteString(fmt.Sprintf("%d/tcp %s\n", port, service))
+	}
+
+	var result *commands.Result
+	switch options.Format {
+	case commands.FormatJSON:
+		var err error
+		if result, err = commands.JSONResult(report, startTime); err != nil {
+			return nil, err
+		}
+	case commands.FormatCSV:
+		result = commands.CSVResult([]string{"port", "protocol", "service"}, rows, startTime)
+	default:
+		result = &commands.Result{Output: lines.String(), Duration: time.Since(startTime)}
+	}
+	if partial {
+		result.ExitCode = 1
+	}
+	return result, nil
+}
+
 // parsePortRange parses port range specification
 func (p *PortscanCommand) parsePortRange(portSpec string) ([]int, error) {
 	var ports []int
@@ -234,7 +312,7 @@ func (p *PortscanCommand) parsePortRange(portSpec string) ([]int, error) {
 }
 
 // scanPorts performs concurrent port scanning with live feedback
-func (p *PortscanCommand) scanPorts(ctx context.Context, host string, ports []int, timeout time.Duration, concurrency int) []int {
+func (p *PortscanCommand) scanPorts(ctx context.Context, host string, ports []int, timeout time.Duration, concurrency int, progress io.Writer) []int {
 	var openPorts []int
 	var mu sync.Mutex
 
@@ -251,15 +329,19 @@ func (p *PortscanCommand) scanPorts(ctx context.Context, host string, ports []in
 		go func(port int) {
 			defer wg.Done()
 
-			// Acquire semaphore
-			sem <- struct{}{}
+			// Acquire semaphore, giving up if the scan was cancelled
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
 			defer func() { <-sem }()
 
 			// Scan port
 			if p.isPortOpen(ctx, host, port, timeout) {
 				mu.Lock()
 				openPorts = append(openPorts, port)
-				fmt.Printf("🟢 Found open port: %d/tcp\n", port)
+				fmt.Fprintf(progress, "🟢 Found open port: %d/tcp\n", port)
 				mu.Unlock()
 			}
 
@@ -267,7 +349,7 @@ func (p *PortscanCommand) scanPorts(ctx context.Context, host string, ports []in
 			mu.Lock()
 			completed++
 			if completed%50 == 0 || completed == total {
-				fmt.Printf("📊 Progress: %d/%d ports scanned (%.1f%%)\n",
+				fmt.Fprintf(progress, "📊 Progress: %d/%d ports scanned (%.1f%%)\n",
 					completed, total, float64(completed)/float64(total)*100)
 			}
 			mu.Unlock()
@@ -275,14 +357,15 @@ func (p *PortscanCommand) scanPorts(ctx context.Context, host string, ports []in
 	}
 
 	wg.Wait()
-	fmt.Println()
+	fmt.Fprintln(progress)
 
 	return openPorts
 }
 
 // isPortOpen checks if a port is open
 func (p *PortscanCommand) isPortOpen(ctx context.Context, host string, port int, timeout time.Duration) bool {
-	conn, err := net.DialTimeout("tcp", fmt.Sprintf("%s:%d", host, port), timeout)
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", fmt.Sprintf("%s:%d", host, port))
 	if err != nil {
 		return false
 	}
@@ -0,0 +1,258 @@
+package networking
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/security"
+
+	"github.com/fatih/color"
+	"github.com/google/gopacket/layers"
+)
+
+// replaySender puts raw frames onto an interface
+type replaySender interface {
+	WritePacketData(data []byte) error
+	Close() error
+}
+
+// ReplayCommand shows or re-sends the packets of a capture file
+type ReplayCommand struct {
+	*commands.BaseCommand
+	flags *commands.FlagSet
+	sniff *SniffCommand
+}
+
+// NewReplayCommand creates a replay command
+func NewReplayCommand() *ReplayCommand {
+	usage := "replay <file.pcap> [index|interface|address] [--send [--fast]] [-f <bpf>] [--host <ip>] [--port <port>] [--proto <proto>] [-c <count>] [-v] [--hex]"
+	return &ReplayCommand{
+		BaseCommand: commands.NewBaseCommand(
+			"replay",
+			"Show the packets of a pcap capture or send them onto an interface again",
+			usage,
+			[]string{"windows", "linux", "darwin"},
+			true, // --send writes raw frames
+		),
+		flags: commands.NewFlagSet("replay", usage,
+			commands.FlagSpec{Name: "send", Help: "Send the packets onto the interface instead of showing them"},
+			commands.FlagSpec{Name: "fast", Help: "With --send, don't wait between packets as the capture did"},
+			commands.FlagSpec{Name: "filter", Short: "f", Kind: commands.StringFlag, Value: "bpf", Help: "Only replay packets matching this capture filter"},
+			commands.FlagSpec{Name: "host", Kind: commands.StringFlag, Value: "ip", Help: "Only replay packets from or to this address"},
+			commands.FlagSpec{Name: "port", Kind: commands.StringFlag, Value: "port", Help: "Only replay packets from or to this port"},
+			commands.FlagSpec{Name: "proto", Short: "p", Kind: commands.StringFlag, Value: "proto", Help: "Only replay packets of this protocol, such as tcp or dns"},
+			commands.FlagSpec{Name: "count", Short: "c", Kind: commands.IntFlag, Value: "n", Help: "Stop after this many packets"},
+			commands.FlagSpec{Name: "verbose", Short: "v", Help: "Show every packet in detail"},
+			commands.FlagSpec{Name: "hex", Help: "Show the start of each payload in hex"},
+		),
+		sniff: NewSniffCommand(),
+	}
+}
+
+// FlagSet returns the options replay accepts
+func (r *ReplayCommand) FlagSet() *commands.FlagSet {
+	return r.flags
+}
+
+// NeedsElevation reports whether the replay sends packets; showing a capture
+// file needs no privileges
+func (r *ReplayCommand) NeedsElevation(args *commands.Arguments) bool {
+	flags, err := r.flags.Parse(args.Raw)
+	return err == nil && flags.Bool("send")
+}
+
+// replayRun counts what a replay read and did
+type replayRun struct {
+	read     int
+	matched  int
+	sent     int
+	bytes    int64
+	filtered int
+}
+
+// Execute reads the capture and shows or sends its packets
+func (r *ReplayCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	startTime := time.Now()
+
+	flags, result := r.flags.ParseArguments(args, startTime)
+	if result != nil {
+		return result, nil
+	}
+	if len(flags.Args()) == 0 || len(flags.Args()) > 2 {
+		return commands.UsageResult(r, startTime, "expected a capture file and an optional interface"), nil
+	}
+	file, iface := flags.Arg(0), flags.Arg(1)
+	send := flags.Bool("send")
+	switch {
+	case send && iface == "":
+		return commands.UsageResult(r, startTime, "--send needs the interface to send on"), nil
+	case !send && iface != "":
+		return commands.UsageResult(r, startTime, "an interface only applies with --send"), nil
+	case !send && flags.Bool("fast"):
+		return commands.UsageResult(r, startTime, "--fast only applies with --send"), nil
+	case flags.Int("count") < 0:
+		return commands.UsageResult(r, startTime, "--count can't be negative"), nil
+	}
+	if send {
+		// The interface can be given by index or address as well as by name
+		resolved, err := ResolveInterface(iface)
+		if err != nil {
+			return commands.ErrorResult("", err, startTime), nil
+		}
+		iface = resolved.Name
+	}
+
+	opts := SniffOptions{
+		Interface:   iface,
+		PacketCount: flags.Int("count"),
+		Protocol:    flags.String("proto"),
+		Host:        flags.String("host"),
+		Port:        flags.String("port"),
+		Filter:      flags.String("filter"),
+		Verbose:     flags.Bool("verbose"),
+		ShowHex:     flags.Bool("hex"),
+	}
+	bpf, err := CompileSniffFilter(opts)
+	if err != nil {
+		return r.sniff.filterError(err, startTime), nil
+	}
+	opts.BPF = bpf
+	if bpf != "" {
+		// Replayed packets are real, so the whole filter applies as libpcap would
+		opts.match, _ = parseSniffFilter(bpf)
+	}
+
+	reader, closer, err := openCaptureFile(file)
+	if err != nil {
+		return commands.ErrorResult("", commands.FileError(r.Name(), file, err), startTime), nil
+	}
+	defer closer.Close()
+
+	var output strings.Builder
+	output.WriteString(color.New(color.FgCyan, color.Bold).Sprint("⏪ PACKET REPLAY\n"))
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+	output.WriteString(fmt.Sprintf("📄 Capture:     %s (%s)\n", file, reader.LinkType()))
+	if bpf != "" {
+		output.WriteString(fmt.Sprintf("🧪 BPF filter:  %s\n", color.New(color.FgYellow).Sprint(bpf)))
+	}
+
+	var run replayRun
+	filtered := func() {
+		if run.filtered > 0 {
+			output.WriteString(color.New(color.FgHiBlack).Sprintf("%d of %d packets didn't match the filter\n", run.filtered, run.read))
+		}
+	}
+	if send {
+		err = r.send(ctx, reader, iface, opts, flags.Bool("fast"), &run, &output)
+		filtered()
+	} else {
+		var packets []Packet
+		packets, err = r.read(reader, opts, &run)
+		filtered()
+		output.WriteString("───────────────────────────────────────────────────────────────\n")
+		r.sniff.displayPackets(packets, opts, &output)
+		r.sniff.displayStatistics(packets, opts, startTime, &output)
+	}
+
+	exitCode := 0
+	if err != nil {
+		output.WriteString(color.New(color.FgRed).Sprintf("❌ %v\n", err))
+		exitCode = 1
+	}
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: exitCode,
+		Duration: time.Since(startTime),
+	}, nil
+}
+
+// read decodes the matching packets of the capture
+func (r *ReplayCommand) read(reader captureReader, opts SniffOptions, run *replayRun) ([]Packet, error) {
+	var packets []Packet
+	err := r.each(reader, opts, run, func(data []byte, packet Packet) error {
+		packets = append(packets, packet)
+		return nil
+	})
+	return packets, err
+}
+
+// send writes the matching packets onto iface, spaced as they were captured
+// unless fast is set. Enter stops a long replay.
+func (r *ReplayCommand) send(ctx context.Context, reader captureReader, iface string, opts SniffOptions, fast bool, run *replayRun, output *strings.Builder) error {
+	if reader.LinkType() != layers.LinkTypeEthernet {
+		return fmt.Errorf("only Ethernet captures can be sent, this one is %s", reader.LinkType())
+	}
+	if err := RequireCapability(CapabilitySend); err != nil {
+		return err
+	}
+	sender, err := openReplaySender(iface)
+	if err != nil {
+		return fmt.Errorf("cannot send on %s: %w", iface, err)
+	}
+	defer sender.Close()
+
+	replayCtx, cancel := security.CancelOnEnter(ctx)
+	defer cancel()
+
+	timing := "with the captured timing"
+	if fast {
+		timing = "as fast as possible"
+	}
+	live := commands.ProgressWriter(ctx)
+	fmt.Fprint(live, color.New(color.FgCyan).Sprintf("📤 Sending to %s %s, press Enter to stop\n", iface, timing))
+
+	startTime := time.Now()
+	var first time.Time
+	err = r.each(reader, opts, run, func(data []byte, packet Packet) error {
+		if !fast {
+			if first.IsZero() {
+				first = packet.Timestamp
+			}
+			if wait := time.Until(startTime.Add(packet.Timestamp.Sub(first))); wait > 0 {
+				if err := commands.Sleep(replayCtx, wait); err != nil {
+					return err
+				}
+			}
+		}
+		if err := sender.WritePacketData(data); err != nil {
+			return fmt.Errorf("sending packet %d: %w", run.read, err)
+		}
+		run.sent++
+		run.bytes += int64(len(data))
+		return nil
+	})
+	if err != nil && replayCtx.Err() != nil && ctx.Err() == nil {
+		// Stopped with Enter
+		err = nil
+	}
+
+	output.WriteString(fmt.Sprintf("📤 Sent %d packets (%s) to %s in %v\n", run.sent, commands.HumanizeBytes(run.bytes), iface, time.Since(startTime).Round(time.Millisecond)))
+	return err
+}
+
+// each calls fn with every packet matching opts, stopping at the count limit.
+// A file that ends inside a packet or holds a packet the format doesn't allow
+// fails with an error naming the packet.
+func (r *ReplayCommand) each(reader captureReader, opts SniffOptions, run *replayRun, fn func(data []byte, packet Packet) error) error {
+	for opts.PacketCount == 0 || run.matched < opts.PacketCount {
+		data, info, err := reader.ReadPacketData()
+		if err != nil {
+			return captureReadError(err, run.read)
+		}
+		run.read++
+
+		packet := r.sniff.decodePacket(data, info, reader.LinkType())
+		if opts.match != nil && !opts.match.match(packet) {
+			run.filtered++
+			continue
+		}
+		run.matched++
+		if err := fn(data, packet); err != nil {
+			return err
+		}
+	}
+	return nil
+}
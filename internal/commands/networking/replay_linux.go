@@ -0,0 +1,42 @@
+package networking
+
+import (
+	"net"
+
+	"golang.org/x/sys/unix"
+)
+
+// packetSocket sends frames through an AF_PACKET socket bound to one interface
+type packetSocket struct {
+	fd int
+}
+
+// openReplaySender opens a raw packet socket on iface, which needs root or
+// CAP_NET_RAW
+func openReplaySender(iface string) (replaySender, error) {
+	nic, err := net.InterfaceByName(iface)
+	if err != nil {
+		return nil, err
+	}
+	// The protocol only matters for receiving
+	fd, err := unix.Socket(unix.AF_PACKET, unix.SOCK_RAW, packetSocketProtocol)
+	if err != nil {
+		return nil, err
+	}
+	if err := unix.Bind(fd, &unix.SockaddrLinklayer{Protocol: uint16(packetSocketProtocol), Ifindex: nic.Index}); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+	return &packetSocket{fd: fd}, nil
+}
+
+// WritePacketData sends one frame as it was captured
+func (s *packetSocket) WritePacketData(data []byte) error {
+	_, err := unix.Write(s.fd, data)
+	return err
+}
+
+// Close closes the socket
+func (s *packetSocket) Close() error {
+	return unix.Close(s.fd)
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+// +build !linux
+
+package networking
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// openReplaySender fails: sending raw frames needs a packet socket, which only
+// Linux offers without libpcap
+func openReplaySender(iface string) (replaySender, error) {
+	return nil, fmt.Errorf("sending captured packets is not supported on %s", runtime.GOOS)
+}
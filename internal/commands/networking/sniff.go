@@ -23,7 +23,7 @@ func NewSniffCommand() *SniffCommand {
 		BaseCommand: commands.NewBaseCommand(
 			"sniff",
 			"Capture and analyze network packets with advanced filtering",
-			"sniff [-i <interface>] [-c <count>] [-p <protocol>] [-s <source>] [-d <dest>] [--port <port>] [--save <file>] [-v]",
+			"sniff [interface] [-i <index|name|address>] [-c <count>] [-p <protocol>] [--host <ip>] [-s <source>] [-d <dest>] [--port <port>] [-f <bpf>] [--save <file> [--rotate-size <size>] [--rotate-time <dur>] [--keep <n>]] [--jsonl | --jsonl-file <file>] [-v] [--summary [--top <n>]]",
 			[]string{"windows", "linux", "darwin"},
 			true, // Requires elevation for packet capture
 		),
@@ -35,6 +35,7 @@ type SniffOptions struct {
 	Interface   string
 	PacketCount int
 	Protocol    string
+	Host        string
 	SourceIP    string
 	DestIP      string
 	Port        string
@@ -43,6 +44,39 @@ type SniffOptions struct {
 	ShowHex     bool
 	Continuous  bool
 	Timeout     int
+	// Summary replaces per-packet lines with a live table of flows
+	Summary bool
+	// Top limits the flows the summary lists, 0 for all of them
+	Top int
+	// Filter is a raw BPF expression, combined with the other filters
+	Filter string
+	// BPF is the capture filter all the filter options compile to
+	BPF string
+	// RotateSize and RotateTime roll SaveFile over to numbered files, of which
+	// Keep are kept
+	RotateSize string
+	RotateTime string
+	Keep       int
+	// JSONLines streams each packet as a line of JSON: to JSONLFile when set,
+	// otherwise to stdout in place of the report
+	JSONLines bool
+	JSONLFile string
+
+	// match applies Filter to captured packets
+	match sniffMatcher
+	// saved lists the capture files SaveFile ended up as
+	saved []string
+	// streamed counts the packets written to JSONLFile
+	streamed int
+}
+
+// sniffValueFlags are the options followed by a value, so that value isn't
+// taken for the interface
+var sniffValueFlags = map[string]bool{
+	"-i": true, "--interface": true, "-c": true, "--count": true, "-p": true, "--protocol": true, "--proto": true,
+	"--host": true, "-f": true, "--filter": true, "-s": true, "--source": true, "-d": true, "--dest": true, "--destination": true,
+	"--port": true, "--save": true, "-t": true, "--timeout": true, "--top": true,
+	"--rotate-size": true, "--rotate-time": true, "--keep": true, "--jsonl-file": true,
 }
 
 // Execute captures and analyzes network packets
@@ -52,6 +86,37 @@ func (s *SniffCommand) Execute(ctx context.Context, args *commands.Arguments) (*
 	// Parse arguments with enhanced options
 	opts := s.parseArguments(args.Raw)
 
+	// A filter that doesn't compile would only fail once the capture is open
+	bpf, err := CompileSniffFilter(opts)
+	if err != nil {
+		return s.filterError(err, startTime), nil
+	}
+	opts.BPF = bpf
+	if opts.Filter != "" {
+		opts.match, _ = parseSniffFilter(opts.Filter)
+	}
+	if _, err := sniffRotation(opts); err != nil {
+		return commands.ErrorResult("Usage: "+s.Usage()+"\n", commands.UsageError(s.Name(), "%v", err), startTime), nil
+	}
+	// Only the packets are written to stdout, leaving nowhere for the flow table
+	streamOnly := opts.JSONLines && opts.JSONLFile == ""
+	if streamOnly && opts.Summary {
+		err := fmt.Errorf("--summary can't share stdout with --jsonl; use --jsonl-file <file>")
+		return commands.ErrorResult("Usage: "+s.Usage()+"\n", commands.UsageError(s.Name(), "%v", err), startTime), nil
+	}
+
+	// The interface can be given by index, name or address
+	var iface NetInterface
+	if opts.Interface == "" {
+		iface, err = DefaultInterface()
+	} else {
+		iface, err = ResolveInterface(opts.Interface)
+	}
+	if err != nil {
+		return commands.ErrorResult("", err, startTime), nil
+	}
+	opts.Interface = iface.Name
+
 	var output strings.Builder
 
 	output.WriteString(color.New(color.FgCyan, color.Bold).Sprint("📡 ADVANCED PACKET SNIFFER\n"))
@@ -67,27 +132,105 @@ func (s *SniffCommand) Execute(ctx context.Context, args *commands.Arguments) (*
 
 	// Simulate packet capture initialization
 	output.WriteString("🔧 Initializing packet capture...\n")
-	time.Sleep(500 * time.Millisecond)
+	writeCapabilityWarning(CapabilityCapture, &output)
+	if err := commands.Sleep(ctx, 500*time.Millisecond); err != nil {
+		if streamOnly {
+			return s.streamResult(ctx, nil, startTime), nil
+		}
+		output.WriteString(color.New(color.FgYellow).Sprint("⚠️  Capture cancelled\n"))
+		return &commands.Result{
+			Output:   output.String(),
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}, nil
+	}
 	output.WriteString("✅ Capture interface ready\n")
+
+	var saver *sniffSaver
+	if opts.SaveFile != "" {
+		saver, err = s.saveCapture(opts, &output)
+		if err != nil {
+			output.WriteString(color.New(color.FgRed).Sprintf("❌ Cannot save the capture: %v\n", err))
+			return commands.ErrorResult(output.String(), commands.FileError(s.Name(), opts.SaveFile, err), startTime), nil
+		}
+		defer saver.Close()
+	}
+	var stream *sniffStream
+	if opts.JSONLines {
+		stream, err = s.streamPackets(opts, commands.OutputWriter(ctx), &output)
+		if err != nil {
+			output.WriteString(color.New(color.FgRed).Sprintf("❌ Cannot stream packets: %v\n", err))
+			return commands.ErrorResult(output.String(), commands.FileError(s.Name(), opts.JSONLFile, err), startTime), nil
+		}
+		defer stream.Close()
+	}
+	observe := func(packet Packet) {
+		saver.observe(packet)
+		stream.observe(packet)
+	}
 	output.WriteString("🎯 Starting packet capture...\n")
 	output.WriteString("───────────────────────────────────────────────────────────────\n")
 
-	// Simulate packet capture with advanced filtering
-	packets := s.simulateAdvancedPacketCapture(opts, &output)
+	// The capture stops at the configured timeout or when the shell cancels it
+	captureCtx := ctx
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		captureCtx, cancel = context.WithTimeout(ctx, time.Duration(opts.Timeout)*time.Second)
+		defer cancel()
+	}
 
-	// Display captured packets with enhanced formatting
-	s.displayPackets(packets, opts, &output)
+	if !opts.Summary {
+		// Simulate packet capture with advanced filtering
+		packets := s.simulateAdvancedPacketCapture(captureCtx, opts, &output, observe)
+		opts.saved, opts.streamed = saver.Files(), stream.Lines()
+		if streamOnly {
+			return s.streamResult(ctx, stream, startTime), nil
+		}
 
-	// Enhanced statistics
-	s.displayStatistics(packets, opts, startTime, &output)
+		// Display captured packets with enhanced formatting
+		s.displayPackets(packets, opts, &output)
+		s.displayStatistics(packets, opts, startTime, &output)
+	} else {
+		// The flow table updates in place while capturing and the final one is
+		// part of the result
+		view := &sniffFlowView{live: commands.ProgressWriter(ctx), table: newSniffFlowTable(), top: opts.Top, started: time.Now()}
+		view.draw()
+		packets := s.simulateAdvancedPacketCapture(captureCtx, opts, &output, func(packet Packet) {
+			view.observe(packet)
+			observe(packet)
+		})
+		view.clear()
+		opts.saved, opts.streamed = saver.Files(), stream.Lines()
+
+		output.WriteString(view.table.render(opts.Top, time.Since(view.started)))
+		s.displayStatistics(packets, opts, startTime, &output)
+	}
+
+	exitCode := 0
+	if ctx.Err() != nil || saver.Failed() || stream.Failed() {
+		exitCode = 1
+	}
 
 	return &commands.Result{
 		Output:   output.String(),
-		ExitCode: 0,
+		ExitCode: exitCode,
 		Duration: time.Since(startTime),
 	}, nil
 }
 
+// streamResult ends a capture streamed to stdout with --jsonl, whose packets
+// have all been written already. Anything going wrong is left to the error.
+func (s *SniffCommand) streamResult(ctx context.Context, stream *sniffStream, startTime time.Time) *commands.Result {
+	result := &commands.Result{ExitCode: 0, Duration: time.Since(startTime)}
+	switch {
+	case stream.Failed():
+		result.Error, result.ExitCode = fmt.Errorf("%s: streaming stopped after %d packets: %v", s.Name(), stream.Lines(), stream.err), 1
+	case ctx.Err() != nil:
+		result.Error, result.ExitCode = ctx.Err(), 1
+	}
+	return result
+}
+
 // Packet represents a captured network packet with enhanced details
 type Packet struct {
 	Timestamp   time.Time
@@ -119,12 +262,17 @@ type PacketStats struct {
 // parseArguments parses command line arguments into SniffOptions
 func (s *SniffCommand) parseArguments(args []string) SniffOptions {
 	opts := SniffOptions{
-		Interface:   "eth0",
 		PacketCount: 10,
 		Timeout:     30,
+		Top:         20,
 	}
 
 	for i, arg := range args {
+		if !strings.HasPrefix(arg, "-") && (i == 0 || !sniffValueFlags[args[i-1]]) {
+			// sniff <interface> is the same as sniff -i <interface>
+			opts.Interface = arg
+			continue
+		}
 		switch arg {
 		case "-i", "--interface":
 			if i+1 < len(args) {
@@ -134,10 +282,18 @@ func (s *SniffCommand) parseArguments(args []string) SniffOptions {
 			if i+1 < len(args) {
 				fmt.Sscanf(args[i+1], "%d", &opts.PacketCount)
 			}
-		case "-p", "--protocol":
+		case "-p", "--protocol", "--proto":
 			if i+1 < len(args) {
 				opts.Protocol = args[i+1]
 			}
+		case "--host":
+			if i+1 < len(args) {
+				opts.Host = args[i+1]
+			}
+		case "-f", "--filter":
+			if i+1 < len(args) {
+				opts.Filter = args[i+1]
+			}
 		case "-s", "--source":
 			if i+1 < len(args) {
 				opts.SourceIP = args[i+1]
@@ -154,12 +310,37 @@ func (s *SniffCommand) parseArguments(args []string) SniffOptions {
 			if i+1 < len(args) {
 				opts.SaveFile = args[i+1]
 			}
+		case "--rotate-size":
+			if i+1 < len(args) {
+				opts.RotateSize = args[i+1]
+			}
+		case "--rotate-time":
+			if i+1 < len(args) {
+				opts.RotateTime = args[i+1]
+			}
+		case "--keep":
+			if i+1 < len(args) {
+				fmt.Sscanf(args[i+1], "%d", &opts.Keep)
+			}
 		case "-v", "--verbose":
 			opts.Verbose = true
 		case "--hex":
 			opts.ShowHex = true
 		case "--continuous":
 			opts.Continuous = true
+		case "--summary":
+			opts.Summary = true
+		case "--jsonl":
+			opts.JSONLines = true
+		case "--jsonl-file":
+			if i+1 < len(args) {
+				opts.JSONLines = true
+				opts.JSONLFile = args[i+1]
+			}
+		case "--top":
+			if i+1 < len(args) {
+				fmt.Sscanf(args[i+1], "%d", &opts.Top)
+			}
 		case "-t", "--timeout":
 			if i+1 < len(args) {
 				fmt.Sscanf(args[i+1], "%d", &opts.Timeout)
@@ -178,6 +359,9 @@ func (s *SniffCommand) displayConfiguration(opts SniffOptions, output *strings.B
 	if opts.Protocol != "" {
 		output.WriteString(fmt.Sprintf("🔍 Protocol:    %s\n", color.New(color.FgYellow).Sprint(opts.Protocol)))
 	}
+	if opts.Host != "" {
+		output.WriteString(fmt.Sprintf("🖥️  Host:        %s\n", color.New(color.FgGreen).Sprint(opts.Host)))
+	}
 	if opts.SourceIP != "" {
 		output.WriteString(fmt.Sprintf("📡 Source IP:   %s\n", color.New(color.FgGreen).Sprint(opts.SourceIP)))
 	}
@@ -187,9 +371,29 @@ func (s *SniffCommand) displayConfiguration(opts SniffOptions, output *strings.B
 	if opts.Port != "" {
 		output.WriteString(fmt.Sprintf("🚪 Port:        %s\n", color.New(color.FgMagenta).Sprint(opts.Port)))
 	}
+	if opts.BPF != "" {
+		output.WriteString(fmt.Sprintf("🧪 BPF filter:  %s\n", color.New(color.FgYellow).Sprint(opts.BPF)))
+	}
 	if opts.SaveFile != "" {
 		output.WriteString(fmt.Sprintf("💾 Save to:     %s\n", color.New(color.FgCyan).Sprint(opts.SaveFile)))
 	}
+	if opts.JSONLFile != "" {
+		output.WriteString(fmt.Sprintf("📜 JSON lines:  %s\n", color.New(color.FgCyan).Sprint(opts.JSONLFile)))
+	}
+	if opts.RotateSize != "" || opts.RotateTime != "" {
+		var limits []string
+		if opts.RotateSize != "" {
+			limits = append(limits, "every "+opts.RotateSize)
+		}
+		if opts.RotateTime != "" {
+			limits = append(limits, "every "+opts.RotateTime)
+		}
+		kept := "keeping all files"
+		if opts.Keep > 0 {
+			kept = fmt.Sprintf("keeping the last %d", opts.Keep)
+		}
+		output.WriteString(fmt.Sprintf("🔄 Rotate:      %s\n", color.New(color.FgCyan).Sprintf("%s, %s", strings.Join(limits, " or "), kept)))
+	}
 	if opts.Continuous {
 		output.WriteString(fmt.Sprintf("♾️  Mode:        %s\n", color.New(color.FgYellow).Sprint("Continuous")))
 		output.WriteString(fmt.Sprintf("⏱️  Timeout:     %d seconds\n", opts.Timeout))
@@ -197,10 +401,19 @@ func (s *SniffCommand) displayConfiguration(opts SniffOptions, output *strings.B
 	if opts.ShowHex {
 		output.WriteString(fmt.Sprintf("🔢 Hex dump:    %s\n", color.New(color.FgCyan).Sprint("Enabled")))
 	}
+	if opts.Summary {
+		flows := "all flows"
+		if opts.Top > 0 {
+			flows = fmt.Sprintf("top %d flows by bytes", opts.Top)
+		}
+		output.WriteString(fmt.Sprintf("📋 Summary:     %s\n", color.New(color.FgYellow).Sprint(flows)))
+	}
 }
 
-// simulateAdvancedPacketCapture simulates capturing network packets with advanced filtering
-func (s *SniffCommand) simulateAdvancedPacketCapture(opts SniffOptions, output *strings.Builder) []Packet {
+// simulateAdvancedPacketCapture simulates capturing network packets with advanced filtering.
+// When observe is set it sees every packet as it is captured. The summary's
+// live table takes the place of progress lines.
+func (s *SniffCommand) simulateAdvancedPacketCapture(ctx context.Context, opts SniffOptions, output *strings.Builder, observe func(Packet)) []Packet {
 	var packets []Packet
 
 	protocols := []string{"TCP", "UDP", "ICMP", "HTTP", "HTTPS", "DNS", "ARP", "SSH", "FTP", "SMTP"}
@@ -216,7 +429,7 @@ func (s *SniffCommand) simulateAdvancedPacketCapture(opts SniffOptions, output *
 		attempts++
 
 		// Show progress every 10 attempts
-		if attempts%10 == 0 {
+		if attempts%10 == 0 && !opts.Summary {
 			fmt.Fprintf(output, "📡 Captured %d/%d packets (attempt %d)...\n", capturedCount, opts.PacketCount, attempts)
 		}
 
@@ -244,10 +457,19 @@ func (s *SniffCommand) simulateAdvancedPacketCapture(opts SniffOptions, output *
 			PayloadHex:  s.generateHexDump(),
 			Direction:   s.determineDirection(source),
 		}
+		if opts.match != nil && !opts.match.match(packet) {
+			continue
+		}
 
 		packets = append(packets, packet)
 		capturedCount++
-		time.Sleep(50 * time.Millisecond) // Simulate capture delay
+		if observe != nil {
+			observe(packet)
+		}
+		if err := commands.Sleep(ctx, 50*time.Millisecond); err != nil { // Simulate capture delay
+			fmt.Fprintf(output, "⚠️  Capture stopped: %v\n", err)
+			break
+		}
 	}
 
 	fmt.Fprintf(output, "✅ Capture complete: %d packets captured\n", len(packets))
@@ -261,6 +483,11 @@ func (s *SniffCommand) matchesFilters(protocol, source, dest string, srcPort, ds
 		return false
 	}
 
+	// Host filter (matches either source or destination)
+	if opts.Host != "" && source != opts.Host && dest != opts.Host {
+		return false
+	}
+
 	// Source IP filter
 	if opts.SourceIP != "" && source != opts.SourceIP {
 		return false
@@ -283,6 +510,24 @@ func (s *SniffCommand) matchesFilters(protocol, source, dest string, srcPort, ds
 	return true
 }
 
+// filterError explains a capture filter that doesn't compile, with examples of
+// ones that do
+func (s *SniffCommand) filterError(err error, startTime time.Time) *commands.Result {
+	var output strings.Builder
+	output.WriteString(color.New(color.FgRed).Sprintf("❌ %v\n", err))
+	output.WriteString("💡 Capture filter examples:\n")
+	for _, example := range sniffFilterExamples {
+		output.WriteString(fmt.Sprintf("   sniff -f %q\n", example))
+	}
+	output.WriteString("   sniff --host 10.0.0.5 --proto https   (no BPF needed)\n")
+	return &commands.Result{
+		Output:   output.String(),
+		Error:    commands.UsageError(s.Name(), "%v", err),
+		ExitCode: 1,
+		Duration: time.Since(startTime),
+	}
+}
+
 // displayPackets displays captured packets with enhanced formatting
 func (s *SniffCommand) displayPackets(packets []Packet, opts SniffOptions, output *strings.Builder) {
 	if opts.Verbose {
@@ -386,8 +631,14 @@ func (s *SniffCommand) displayStatistics(packets []Packet, opts SniffOptions, st
 
 	output.WriteString(fmt.Sprintf("⏱️  Capture time:      %v\n", time.Since(startTime).Round(time.Millisecond)))
 
-	if opts.SaveFile != "" {
-		output.WriteString(fmt.Sprintf("💾 Saved to:          %s\n", opts.SaveFile))
+	switch {
+	case len(opts.saved) == 1:
+		output.WriteString(fmt.Sprintf("💾 Saved to:          %s\n", opts.saved[0]))
+	case len(opts.saved) > 1:
+		output.WriteString(fmt.Sprintf("💾 Saved to:          %d files, %s … %s\n", len(opts.saved), opts.saved[0], opts.saved[len(opts.saved)-1]))
+	}
+	if opts.JSONLFile != "" {
+		output.WriteString(fmt.Sprintf("📜 Streamed:          %d packets to %s\n", opts.streamed, opts.JSONLFile))
 	}
 
 	output.WriteString("═══════════════════════════════════════════════════════════════\n")
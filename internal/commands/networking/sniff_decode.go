@@ -0,0 +1,153 @@
+package networking
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+// sniffTCPServices label TCP packets by the well-known port of either end
+var sniffTCPServices = map[layers.TCPPort]string{
+	21: "FTP", 22: "SSH", 25: "SMTP", 80: "HTTP", 8080: "HTTP", 443: "HTTPS",
+}
+
+// sniffHexBytes is how much payload a packet's hex dump shows
+const sniffHexBytes = 16
+
+// decodePacket turns a captured frame into a Packet as sniff displays it.
+// Frames that don't decode fully keep what was read and say why in Info.
+func (s *SniffCommand) decodePacket(data []byte, info gopacket.CaptureInfo, linkType layers.LinkType) Packet {
+	decoded := gopacket.NewPacket(data, linkType, gopacket.DecodeOptions{Lazy: true, NoCopy: true})
+	packet := Packet{
+		Timestamp: info.Timestamp,
+		Protocol:  linkType.String(),
+		Size:      info.Length,
+		Flags:     []string{},
+	}
+
+	if arp, ok := decoded.Layer(layers.LayerTypeARP).(*layers.ARP); ok {
+		packet.Protocol = "ARP"
+		packet.Source = net.IP(arp.SourceProtAddress).String()
+		packet.Destination = net.IP(arp.DstProtAddress).String()
+		if arp.Operation == layers.ARPRequest {
+			packet.Info = fmt.Sprintf("Who has %s? Tell %s", packet.Destination, packet.Source)
+		} else {
+			packet.Info = fmt.Sprintf("%s is at %s", packet.Source, net.HardwareAddr(arp.SourceHwAddress))
+		}
+	}
+	if network := decoded.NetworkLayer(); network != nil {
+		source, destination := network.NetworkFlow().Endpoints()
+		packet.Source, packet.Destination = source.String(), destination.String()
+		packet.Protocol = network.LayerType().String()
+	}
+
+	var payload []byte
+	switch transport := decoded.TransportLayer().(type) {
+	case *layers.TCP:
+		packet.SourcePort, packet.DestPort = int(transport.SrcPort), int(transport.DstPort)
+		packet.Flags = sniffTCPFlags(transport)
+		payload = transport.Payload
+		packet.Protocol = "TCP"
+		if service, ok := sniffTCPServices[transport.DstPort]; ok {
+			packet.Protocol = service
+		} else if service, ok := sniffTCPServices[transport.SrcPort]; ok {
+			packet.Protocol = service
+		}
+		packet.Info = fmt.Sprintf("TCP %d→%d [%s] Len=%d", packet.SourcePort, packet.DestPort, strings.Join(packet.Flags, ","), len(payload))
+		if line := sniffHTTPLine(payload); line != "" {
+			packet.Info = line
+		}
+	case *layers.UDP:
+		packet.SourcePort, packet.DestPort = int(transport.SrcPort), int(transport.DstPort)
+		payload = transport.Payload
+		packet.Protocol = "UDP"
+		packet.Info = fmt.Sprintf("UDP %d→%d Len=%d", packet.SourcePort, packet.DestPort, len(payload))
+		if dns, ok := decoded.Layer(layers.LayerTypeDNS).(*layers.DNS); ok {
+			packet.Protocol = "DNS"
+			packet.Info = sniffDNSInfo(dns)
+		}
+	}
+	if icmp, ok := decoded.Layer(layers.LayerTypeICMPv4).(*layers.ICMPv4); ok {
+		packet.Protocol = "ICMP"
+		packet.Info = icmp.TypeCode.String()
+		payload = icmp.Payload
+	}
+	if icmp, ok := decoded.Layer(layers.LayerTypeICMPv6).(*layers.ICMPv6); ok {
+		packet.Protocol = "ICMPv6"
+		packet.Info = icmp.TypeCode.String()
+		payload = icmp.Payload
+	}
+
+	if failure := decoded.ErrorLayer(); failure != nil {
+		packet.Info = strings.TrimSpace(packet.Info + " (malformed: " + failure.Error().Error() + ")")
+	}
+	if packet.Info == "" {
+		packet.Info = fmt.Sprintf("%s frame, %d bytes", packet.Protocol, info.Length)
+	}
+	if len(payload) == 0 {
+		payload = data
+	}
+	packet.PayloadHex = sniffHexDump(payload)
+	if packet.Source != "" {
+		packet.Direction = s.determineDirection(packet.Source)
+	}
+	return packet
+}
+
+// sniffTCPFlags names the flags set on a segment
+func sniffTCPFlags(tcp *layers.TCP) []string {
+	flags := []string{}
+	for _, flag := range []struct {
+		set  bool
+		name string
+	}{{tcp.SYN, "SYN"}, {tcp.FIN, "FIN"}, {tcp.RST, "RST"}, {tcp.PSH, "PSH"}, {tcp.ACK, "ACK"}, {tcp.URG, "URG"}} {
+		if flag.set {
+			flags = append(flags, flag.name)
+		}
+	}
+	return flags
+}
+
+// sniffHTTPLine returns the request or status line a payload starts with, if
+// it is HTTP
+func sniffHTTPLine(payload []byte) string {
+	for _, prefix := range []string{"GET ", "POST ", "PUT ", "DELETE ", "HEAD ", "OPTIONS ", "PATCH ", "HTTP/1."} {
+		if bytes.HasPrefix(payload, []byte(prefix)) {
+			line := payload
+			if end := bytes.IndexAny(payload, "\r\n"); end >= 0 {
+				line = payload[:end]
+			}
+			return string(line)
+		}
+	}
+	return ""
+}
+
+// sniffDNSInfo summarizes a DNS message by its first question, as in
+// "A example.com" or "response A example.com (2 answers)"
+func sniffDNSInfo(dns *layers.DNS) string {
+	question := "no question"
+	if len(dns.Questions) > 0 {
+		question = fmt.Sprintf("%s %s", dns.Questions[0].Type, dns.Questions[0].Name)
+	}
+	if !dns.QR {
+		return question
+	}
+	return fmt.Sprintf("response %s (%d answers)", question, len(dns.Answers))
+}
+
+// sniffHexDump formats the start of a payload as spaced hex bytes
+func sniffHexDump(payload []byte) string {
+	if len(payload) > sniffHexBytes {
+		payload = payload[:sniffHexBytes]
+	}
+	hex := make([]string, len(payload))
+	for i, b := range payload {
+		hex[i] = fmt.Sprintf("%02x", b)
+	}
+	return strings.Join(hex, " ")
+}
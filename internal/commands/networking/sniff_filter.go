@@ -0,0 +1,489 @@
+package networking
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// sniffFilterExamples are capture filters shown when one doesn't parse
+var sniffFilterExamples = []string{
+	"host 192.168.1.10",
+	"tcp port 443",
+	"udp and port 53",
+	"src net 10.0.0.0/8 and not port 22",
+	"portrange 8000-8100 or icmp",
+}
+
+// sniffProtocolFilters translates the protocols --proto accepts into capture
+// filters. Application protocols have no BPF keyword and match on their port.
+var sniffProtocolFilters = map[string]string{
+	"tcp":   "tcp",
+	"udp":   "udp",
+	"icmp":  "icmp",
+	"arp":   "arp",
+	"http":  "tcp port 80",
+	"https": "tcp port 443",
+	"ssh":   "tcp port 22",
+	"ftp":   "tcp port 21",
+	"smtp":  "tcp port 25",
+	"dns":   "port 53",
+}
+
+// sniffServicePorts are the service names a port may be given as
+var sniffServicePorts = map[string]int{
+	"ftp": 21, "ssh": 22, "smtp": 25, "domain": 53, "dns": 53, "http": 80,
+	"pop3": 110, "imap": 143, "https": 443, "imaps": 993, "pop3s": 995, "rdp": 3389,
+}
+
+// sniffTransports maps the protocols the capture labels packets with to the
+// protocol a capture filter sees on the wire
+var sniffTransports = map[string]string{
+	"TCP": "tcp", "HTTP": "tcp", "HTTPS": "tcp", "SSH": "tcp", "FTP": "tcp", "SMTP": "tcp",
+	"UDP": "udp", "DNS": "udp", "ICMP": "icmp", "ARP": "arp",
+}
+
+// sniffHostPattern matches host names a filter may use instead of an address
+var sniffHostPattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9-]*[A-Za-z0-9])?(\.[A-Za-z0-9]([A-Za-z0-9-]*[A-Za-z0-9])?)*$`)
+
+// sniffDisplayFilterPattern matches the field names of Wireshark display
+// filters, such as tcp.port or ip.addr
+var sniffDisplayFilterPattern = regexp.MustCompile(`\b(tcp|udp|ip|eth|http|dns)\.[a-z]`)
+
+// CompileSniffFilter returns the capture filter the options of a sniff run
+// amount to: --host, --source, --dest, --port and --proto translated to BPF and
+// joined with the raw --filter expression. It fails with an explanation when
+// the result is not a valid filter.
+func CompileSniffFilter(opts SniffOptions) (string, error) {
+	var parts []string
+	if opts.Host != "" {
+		parts = append(parts, "host "+opts.Host)
+	}
+	if opts.SourceIP != "" {
+		parts = append(parts, "src host "+opts.SourceIP)
+	}
+	if opts.DestIP != "" {
+		parts = append(parts, "dst host "+opts.DestIP)
+	}
+	if opts.Port != "" {
+		parts = append(parts, "port "+opts.Port)
+	}
+	if opts.Protocol != "" {
+		filter, ok := sniffProtocolFilters[strings.ToLower(opts.Protocol)]
+		if !ok {
+			return "", fmt.Errorf("unknown protocol '%s', expected one of %s", opts.Protocol, strings.Join(sniffProtocolNames(), ", "))
+		}
+		parts = append(parts, filter)
+	}
+	if opts.Filter != "" {
+		if _, err := parseSniffFilter(opts.Filter); err != nil {
+			return "", err
+		}
+		if len(parts) > 0 {
+			parts = append(parts, "("+opts.Filter+")")
+		} else {
+			parts = append(parts, opts.Filter)
+		}
+	}
+
+	filter := strings.Join(parts, " and ")
+	if filter == "" {
+		return "", nil
+	}
+	if _, err := parseSniffFilter(filter); err != nil {
+		return "", err
+	}
+	return filter, nil
+}
+
+// sniffProtocolNames lists the protocols --proto accepts
+func sniffProtocolNames() []string {
+	names := make([]string, 0, len(sniffProtocolFilters))
+	for name := range sniffProtocolFilters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sniffFilterError explains why a capture filter doesn't parse
+type sniffFilterError struct {
+	Filter  string
+	Message string
+}
+
+func (e *sniffFilterError) Error() string {
+	return fmt.Sprintf("invalid capture filter '%s': %s", e.Filter, e.Message)
+}
+
+// sniffMatcher decides whether a captured packet passes a filter
+type sniffMatcher interface {
+	match(packet Packet) bool
+}
+
+type sniffAnd struct{ left, right sniffMatcher }
+
+func (m sniffAnd) match(packet Packet) bool { return m.left.match(packet) && m.right.match(packet) }
+
+type sniffOr struct{ left, right sniffMatcher }
+
+func (m sniffOr) match(packet Packet) bool { return m.left.match(packet) || m.right.match(packet) }
+
+type sniffNot struct{ operand sniffMatcher }
+
+func (m sniffNot) match(packet Packet) bool { return !m.operand.match(packet) }
+
+// sniffPrimitive is one test such as `tcp dst port 22`, with the qualifiers
+// that weren't given left empty
+type sniffPrimitive struct {
+	proto string
+	dir   string
+	kind  string
+	// The value of the test: a port is a range with low equal to high
+	host    string
+	network *net.IPNet
+	low     int
+	high    int
+	size    int
+}
+
+func (p sniffPrimitive) match(packet Packet) bool {
+	transport := sniffTransports[packet.Protocol]
+	switch p.proto {
+	case "":
+	case "ip", "ip6":
+		source := net.ParseIP(packet.Source)
+		if transport == "arp" || source == nil || (source.To4() != nil) != (p.proto == "ip") {
+			return false
+		}
+	default:
+		if transport != p.proto {
+			return false
+		}
+	}
+
+	switch p.kind {
+	case "host":
+		return p.either(packet.Source == p.host, packet.Destination == p.host)
+	case "net":
+		return p.either(p.network.Contains(net.ParseIP(packet.Source)), p.network.Contains(net.ParseIP(packet.Destination)))
+	case "port", "portrange":
+		if transport != "tcp" && transport != "udp" {
+			return false
+		}
+		return p.either(packet.SourcePort >= p.low && packet.SourcePort <= p.high, packet.DestPort >= p.low && packet.DestPort <= p.high)
+	case "less":
+		return packet.Size <= p.size
+	case "greater":
+		return packet.Size >= p.size
+	}
+	return true
+}
+
+// either applies the direction qualifier to the source and destination tests
+func (p sniffPrimitive) either(source, destination bool) bool {
+	switch p.dir {
+	case "src":
+		return source
+	case "dst":
+		return destination
+	default:
+		return source || destination
+	}
+}
+
+// sniffFilterParser parses the subset of pcap-filter(7) the capture supports:
+// host, net, port and portrange tests qualified by src/dst and tcp, udp, icmp,
+// arp, ip or ip6, less and greater, combined with and, or, not and parentheses.
+// As in libpcap, a bare value after and/or repeats the previous test, so
+// `port 80 or 443` works.
+type sniffFilterParser struct {
+	filter string
+	tokens []string
+	pos    int
+	last   *sniffPrimitive
+}
+
+// parseSniffFilter parses a capture filter into a matcher
+func parseSniffFilter(filter string) (sniffMatcher, error) {
+	p := &sniffFilterParser{filter: filter}
+	if err := p.tokenize(); err != nil {
+		return nil, err
+	}
+	if len(p.tokens) == 0 {
+		return nil, p.fail("the filter is empty")
+	}
+	matcher, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if token := p.peek(); token != "" {
+		if token == ")" {
+			return nil, p.fail("')' without a matching '('")
+		}
+		return nil, p.fail("expected 'and' or 'or' before '%s'", token)
+	}
+	return matcher, nil
+}
+
+// tokenize splits the filter into words and operators, catching display
+// filter syntax early as it is the most common mistake
+func (p *sniffFilterParser) tokenize() error {
+	if strings.Contains(p.filter, "==") || strings.Contains(p.filter, "!=") || sniffDisplayFilterPattern.MatchString(p.filter) {
+		return p.fail("this is Wireshark display filter syntax; capture filters are written like 'tcp port 80' or 'host 10.0.0.5'")
+	}
+	replacer := strings.NewReplacer("(", " ( ", ")", " ) ", "&&", " and ", "||", " or ", "!", " not ")
+	for _, token := range strings.Fields(replacer.Replace(p.filter)) {
+		p.tokens = append(p.tokens, strings.ToLower(token))
+	}
+	return nil
+}
+
+func (p *sniffFilterParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *sniffFilterParser) next() string {
+	token := p.peek()
+	if token != "" {
+		p.pos++
+	}
+	return token
+}
+
+func (p *sniffFilterParser) fail(format string, args ...interface{}) error {
+	return &sniffFilterError{Filter: p.filter, Message: fmt.Sprintf(format, args...)}
+}
+
+func (p *sniffFilterParser) or() (sniffMatcher, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "or" {
+		p.next()
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = sniffOr{left, right}
+	}
+	return left, nil
+}
+
+func (p *sniffFilterParser) and() (sniffMatcher, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "and" {
+		p.next()
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = sniffAnd{left, right}
+	}
+	return left, nil
+}
+
+func (p *sniffFilterParser) unary() (sniffMatcher, error) {
+	switch token := p.peek(); token {
+	case "":
+		if p.pos > 0 {
+			return nil, p.fail("'%s' needs an expression after it", p.tokens[p.pos-1])
+		}
+		return nil, p.fail("the filter is empty")
+	case "not":
+		p.next()
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return sniffNot{operand}, nil
+	case "(":
+		p.next()
+		inner, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, p.fail("'(' without a matching ')'")
+		}
+		return inner, nil
+	case "and", "or", ")":
+		return nil, p.fail("expected a test such as 'host 10.0.0.5' before '%s'", token)
+	}
+	return p.primitive()
+}
+
+// primitive parses [proto] [dir] [kind] value, or a bare value repeating the
+// qualifiers of the previous test
+func (p *sniffFilterParser) primitive() (sniffMatcher, error) {
+	var prim sniffPrimitive
+	token := p.peek()
+
+	if p.last != nil && sniffBareValue(token) {
+		prim = sniffPrimitive{proto: p.last.proto, dir: p.last.dir, kind: p.last.kind}
+		return p.value(prim)
+	}
+
+	switch token {
+	case "tcp", "udp", "icmp", "arp", "ip", "ip6":
+		prim.proto = p.next()
+		token = p.peek()
+	}
+	if token == "src" || token == "dst" {
+		prim.dir = p.next()
+		token = p.peek()
+	}
+	switch token {
+	case "host", "net", "port", "portrange":
+		prim.kind = p.next()
+		return p.value(prim)
+	case "less", "greater":
+		if prim.proto != "" || prim.dir != "" {
+			return nil, p.fail("'%s' can't be qualified, write it on its own, e.g. 'tcp and less 128'", token)
+		}
+		prim.kind = p.next()
+		return p.value(prim)
+	}
+
+	if prim.dir != "" {
+		// `src 10.0.0.5` is short for `src host 10.0.0.5`
+		prim.kind = "host"
+		return p.value(prim)
+	}
+	if prim.proto != "" {
+		p.last = nil
+		return prim, nil
+	}
+
+	if filter, ok := sniffProtocolFilters[token]; ok {
+		return nil, p.fail("'%s' is not a capture protocol, match its port instead: '%s'", token, filter)
+	}
+	if sniffBareValue(token) {
+		return nil, p.fail("'%s' needs a test in front of it, e.g. 'host %s' or 'port %s'", token, token, token)
+	}
+	return nil, p.fail("unknown keyword '%s'; filters combine host, net, port, portrange, less, greater, tcp, udp, icmp, arp, ip and ip6 with and, or and not", token)
+}
+
+// value parses the value of a test of the given kind
+func (p *sniffFilterParser) value(prim sniffPrimitive) (sniffMatcher, error) {
+	token := p.peek()
+	if token == "" || token == "and" || token == "or" || token == "not" || token == "(" || token == ")" {
+		switch prim.kind {
+		case "host":
+			return nil, p.fail("'host' needs an address or host name, e.g. 'host 10.0.0.5'")
+		case "net":
+			return nil, p.fail("'net' needs a network, e.g. 'net 192.168.1.0/24'")
+		case "port":
+			return nil, p.fail("'port' needs a number, e.g. 'port 443'")
+		case "portrange":
+			return nil, p.fail("'portrange' needs a range, e.g. 'portrange 8000-8100'")
+		default:
+			return nil, p.fail("'%s' needs a size in bytes, e.g. '%s 512'", prim.kind, prim.kind)
+		}
+	}
+	p.next()
+
+	switch prim.kind {
+	case "host":
+		if net.ParseIP(token) == nil && !sniffHostPattern.MatchString(token) {
+			return nil, p.fail("'%s' is not an address or host name", token)
+		}
+		prim.host = token
+	case "net":
+		network, err := sniffNetwork(token)
+		if err != nil {
+			return nil, p.fail("'%s' is not a network, write it like '10.0.0.0/8'", token)
+		}
+		prim.network = network
+	case "port":
+		if strings.Contains(token, "-") {
+			return nil, p.fail("'port' takes a single port, use 'portrange %s' for a range", token)
+		}
+		port, err := sniffPort(token)
+		if err != nil {
+			return nil, p.fail("%v", err)
+		}
+		prim.low, prim.high = port, port
+	case "portrange":
+		bounds := strings.SplitN(token, "-", 2)
+		if len(bounds) != 2 {
+			return nil, p.fail("'portrange' needs a range like 8000-8100, got '%s'", token)
+		}
+		low, err := sniffPort(bounds[0])
+		if err != nil {
+			return nil, p.fail("%v", err)
+		}
+		high, err := sniffPort(bounds[1])
+		if err != nil {
+			return nil, p.fail("%v", err)
+		}
+		if low > high {
+			return nil, p.fail("port range %s runs backwards, write it as %d-%d", token, high, low)
+		}
+		prim.low, prim.high = low, high
+	case "less", "greater":
+		size, err := strconv.Atoi(token)
+		if err != nil || size < 0 {
+			return nil, p.fail("'%s' needs a size in bytes, got '%s'", prim.kind, token)
+		}
+		prim.size = size
+	}
+	p.last = &prim
+	return prim, nil
+}
+
+// sniffBareValue reports whether token looks like a value rather than a keyword
+func sniffBareValue(token string) bool {
+	if token == "" {
+		return false
+	}
+	if _, err := strconv.Atoi(token); err == nil {
+		return true
+	}
+	if net.ParseIP(token) != nil {
+		return true
+	}
+	_, _, err := net.ParseCIDR(token)
+	return err == nil
+}
+
+// sniffNetwork parses a network as CIDR, or a single address as its own network
+func sniffNetwork(value string) (*net.IPNet, error) {
+	if _, network, err := net.ParseCIDR(value); err == nil {
+		return network, nil
+	}
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid network %s", value)
+	}
+	bits := 128
+	if ip.To4() != nil {
+		ip, bits = ip.To4(), 32
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
+
+// sniffPort parses a port number or service name
+func sniffPort(value string) (int, error) {
+	if port, ok := sniffServicePorts[value]; ok {
+		return port, nil
+	}
+	port, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("'%s' is not a port number or known service name", value)
+	}
+	if port < 0 || port > 65535 {
+		return 0, fmt.Errorf("port %d is out of range, ports run from 0 to 65535", port)
+	}
+	return port, nil
+}
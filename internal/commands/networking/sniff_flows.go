@@ -0,0 +1,154 @@
+package networking
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+
+	"github.com/fatih/color"
+)
+
+// sniffFlowRedraw is how often sniff --summary redraws its live flow table
+const sniffFlowRedraw = 500 * time.Millisecond
+
+// sniffFlow is the traffic of one conversation: packets of a protocol from a
+// source to a destination port
+type sniffFlow struct {
+	Protocol    string `json:"protocol"`
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	DestPort    int    `json:"port"`
+	Packets     int    `json:"packets"`
+	Bytes       int64  `json:"bytes"`
+}
+
+// label names the flow as src→dst:port, or src→dst for protocols without ports
+func (f sniffFlow) label() string {
+	if f.DestPort == 0 {
+		return fmt.Sprintf("%s→%s", f.Source, f.Destination)
+	}
+	return fmt.Sprintf("%s→%s:%d", f.Source, f.Destination, f.DestPort)
+}
+
+// sniffFlowTable aggregates captured packets into flows
+type sniffFlowTable struct {
+	flows   map[string]*sniffFlow
+	packets int
+	bytes   int64
+}
+
+// newSniffFlowTable creates an empty flow table
+func newSniffFlowTable() *sniffFlowTable {
+	return &sniffFlowTable{flows: make(map[string]*sniffFlow)}
+}
+
+// add counts a packet towards its flow
+func (t *sniffFlowTable) add(packet Packet) {
+	key := fmt.Sprintf("%s %s %s %d", packet.Protocol, packet.Source, packet.Destination, packet.DestPort)
+	flow, ok := t.flows[key]
+	if !ok {
+		flow = &sniffFlow{Protocol: packet.Protocol, Source: packet.Source, Destination: packet.Destination, DestPort: packet.DestPort}
+		t.flows[key] = flow
+	}
+	flow.Packets++
+	flow.Bytes += int64(packet.Size)
+	t.packets++
+	t.bytes += int64(packet.Size)
+}
+
+// sorted returns the flows with the most bytes first, ties broken by packets
+// and then by label so the table doesn't jitter between redraws
+func (t *sniffFlowTable) sorted() []sniffFlow {
+	flows := make([]sniffFlow, 0, len(t.flows))
+	for _, flow := range t.flows {
+		flows = append(flows, *flow)
+	}
+	sort.Slice(flows, func(i, j int) bool {
+		if flows[i].Bytes != flows[j].Bytes {
+			return flows[i].Bytes > flows[j].Bytes
+		}
+		if flows[i].Packets != flows[j].Packets {
+			return flows[i].Packets > flows[j].Packets
+		}
+		if flows[i].label() != flows[j].label() {
+			return flows[i].label() < flows[j].label()
+		}
+		return flows[i].Protocol < flows[j].Protocol
+	})
+	return flows
+}
+
+// render formats the top flows as a table, all of them when top is 0
+func (t *sniffFlowTable) render(top int, elapsed time.Duration) string {
+	flows := t.sorted()
+	hidden := 0
+	if top > 0 && len(flows) > top {
+		hidden = len(flows) - top
+		flows = flows[:top]
+	}
+
+	if elapsed < time.Second {
+		elapsed = elapsed.Round(time.Millisecond)
+	} else {
+		elapsed = elapsed.Round(time.Second)
+	}
+
+	var output strings.Builder
+	output.WriteString(color.New(color.FgGreen, color.Bold).Sprintf("📦 TRAFFIC FLOWS (%d packets, %s in %v)\n",
+		t.packets, commands.HumanizeBytes(t.bytes), elapsed))
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+	output.WriteString(color.New(color.Bold).Sprintf("%-40s %-6s %8s %10s\n", "Flow", "Proto", "Packets", "Bytes"))
+	for _, flow := range flows {
+		output.WriteString(fmt.Sprintf("%-40s %-6s %8d %10s\n",
+			flow.label(),
+			color.New(color.FgBlue).Sprintf("%-6s", flow.Protocol),
+			flow.Packets,
+			commands.HumanizeBytes(flow.Bytes)))
+	}
+	if len(flows) == 0 {
+		output.WriteString(color.New(color.FgHiBlack).Sprint("No packets captured yet\n"))
+	}
+	if hidden > 0 {
+		output.WriteString(color.New(color.FgHiBlack).Sprintf("… %d more flows, raise --top to see them\n", hidden))
+	}
+	return output.String()
+}
+
+// sniffFlowView redraws a flow table in place on the terminal
+type sniffFlowView struct {
+	live    io.Writer
+	table   *sniffFlowTable
+	top     int
+	started time.Time
+	drawn   time.Time
+	lines   int
+}
+
+// observe adds a packet and redraws the table when it is due
+func (v *sniffFlowView) observe(packet Packet) {
+	v.table.add(packet)
+	if time.Since(v.drawn) >= sniffFlowRedraw {
+		v.draw()
+	}
+}
+
+// draw replaces the previous frame with the current table
+func (v *sniffFlowView) draw() {
+	frame := v.table.render(v.top, time.Since(v.started))
+	v.clear()
+	fmt.Fprint(v.live, frame)
+	v.lines = strings.Count(frame, "\n")
+	v.drawn = time.Now()
+}
+
+// clear removes the last frame, leaving the cursor where it started
+func (v *sniffFlowView) clear() {
+	if v.lines > 0 {
+		fmt.Fprintf(v.live, "\033[%dF\033[J", v.lines)
+		v.lines = 0
+	}
+}
@@ -0,0 +1,103 @@
+package networking
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// sniffRecord is one packet as sniff --jsonl writes it
+type sniffRecord struct {
+	Timestamp string `json:"timestamp"`
+	Protocol  string `json:"proto"`
+	Source    string `json:"src"`
+	SrcPort   int    `json:"src_port,omitempty"`
+	Dest      string `json:"dst"`
+	DstPort   int    `json:"dst_port,omitempty"`
+	Length    int    `json:"length"`
+}
+
+// newSniffRecord describes a packet, leaving out ports for the protocols that
+// have none
+func newSniffRecord(packet Packet) sniffRecord {
+	record := sniffRecord{
+		Timestamp: packet.Timestamp.UTC().Format(time.RFC3339Nano),
+		Protocol:  packet.Protocol,
+		Source:    packet.Source,
+		Dest:      packet.Destination,
+		Length:    packet.Size,
+	}
+	if packet.Protocol != "ICMP" && packet.Protocol != "ARP" {
+		record.SrcPort, record.DstPort = packet.SourcePort, packet.DestPort
+	}
+	return record
+}
+
+// sniffStream writes every packet sniff captures as a line of JSON, for log
+// pipelines to read as the capture goes. Each line is a single unbuffered
+// write, so an interrupted capture leaves only whole lines behind. A nil
+// stream writes nothing.
+type sniffStream struct {
+	out    io.Writer
+	file   *os.File
+	output io.Writer
+	lines  int
+	err    error
+}
+
+// streamPackets opens the --jsonl-file, or streams to out with plain --jsonl.
+// Problems are reported to output.
+func (s *SniffCommand) streamPackets(opts SniffOptions, out, output io.Writer) (*sniffStream, error) {
+	if opts.JSONLFile == "" {
+		return &sniffStream{out: out, output: output}, nil
+	}
+	file, err := os.OpenFile(opts.JSONLFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &sniffStream{out: file, file: file, output: output}, nil
+}
+
+// observe writes a captured packet. Failing to write, say because the reader
+// went away, stops the stream but not the capture.
+func (v *sniffStream) observe(packet Packet) {
+	if v == nil || v.err != nil {
+		return
+	}
+	line, err := json.Marshal(newSniffRecord(packet))
+	if err == nil {
+		_, err = v.out.Write(append(line, '\n'))
+	}
+	if err != nil {
+		v.err = err
+		v.Close()
+		fmt.Fprintf(v.output, "❌ Stopped streaming packets: %v\n", err)
+		return
+	}
+	v.lines++
+}
+
+// Lines returns how many packets were written
+func (v *sniffStream) Lines() int {
+	if v == nil {
+		return 0
+	}
+	return v.lines
+}
+
+// Failed reports whether streaming stopped early
+func (v *sniffStream) Failed() bool {
+	return v != nil && v.err != nil
+}
+
+// Close closes the --jsonl-file
+func (v *sniffStream) Close() error {
+	if v == nil || v.file == nil {
+		return nil
+	}
+	err := v.file.Close()
+	v.file = nil
+	return err
+}
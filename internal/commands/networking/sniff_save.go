@@ -0,0 +1,212 @@
+package networking
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"suppercommand/internal/commands"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+// sniffTCPProtocols are the protocols sniff reports that travel over TCP
+var sniffTCPProtocols = map[string]bool{"TCP": true, "HTTP": true, "HTTPS": true, "SSH": true, "FTP": true, "SMTP": true}
+
+// sniffRotation reads the --rotate-size, --rotate-time and --keep options
+func sniffRotation(opts SniffOptions) (captureRotation, error) {
+	var rotation captureRotation
+	if opts.RotateSize != "" {
+		size, err := commands.ParseBytes(opts.RotateSize)
+		if err != nil {
+			return rotation, fmt.Errorf("--rotate-size: %v", err)
+		}
+		if size <= pcapFileHeaderSize+pcapPacketHeaderSize {
+			return rotation, fmt.Errorf("--rotate-size %s is too small to hold a packet", opts.RotateSize)
+		}
+		rotation.Size = size
+	}
+	if opts.RotateTime != "" {
+		every, err := time.ParseDuration(opts.RotateTime)
+		if err != nil || every <= 0 {
+			return rotation, fmt.Errorf("--rotate-time needs a duration such as 30s or 1h, not %q", opts.RotateTime)
+		}
+		rotation.Every = every
+	}
+	switch {
+	case opts.Keep < 0:
+		return rotation, fmt.Errorf("--keep can't be negative")
+	case opts.Keep > 0 && !rotation.enabled():
+		return rotation, fmt.Errorf("--keep only applies with --rotate-size or --rotate-time")
+	case rotation.enabled() && opts.SaveFile == "":
+		return rotation, fmt.Errorf("rotation needs --save <file> to name the capture files")
+	}
+	rotation.Keep = opts.Keep
+	return rotation, nil
+}
+
+// sniffSaver writes the packets sniff captures to --save. A nil saver saves
+// nothing.
+type sniffSaver struct {
+	writer *captureWriter
+	output io.Writer
+	err    error
+}
+
+// saveCapture opens the capture file for --save, reporting rotations to output
+func (s *SniffCommand) saveCapture(opts SniffOptions, output io.Writer) (*sniffSaver, error) {
+	rotation, err := sniffRotation(opts)
+	if err != nil {
+		return nil, err
+	}
+	writer, err := newCaptureWriter(opts.SaveFile, layers.LinkTypeEthernet, rotation, func(format string, args ...interface{}) {
+		fmt.Fprintf(output, format, args...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &sniffSaver{writer: writer, output: output}, nil
+}
+
+// observe saves a captured packet. Failing to save, say on a full disk, stops
+// the saving but not the capture.
+func (v *sniffSaver) observe(packet Packet) {
+	if v == nil || v.err != nil {
+		return
+	}
+	frame, err := packetFrame(packet)
+	if err == nil {
+		err = v.writer.WritePacket(gopacket.CaptureInfo{Timestamp: packet.Timestamp, CaptureLength: len(frame), Length: len(frame)}, frame)
+	}
+	if err != nil {
+		v.err = err
+		v.Close()
+		fmt.Fprintf(v.output, "❌ Stopped saving the capture: %v\n", err)
+	}
+}
+
+// Files returns the capture files on disk
+func (v *sniffSaver) Files() []string {
+	if v == nil {
+		return nil
+	}
+	return v.writer.Files()
+}
+
+// Failed reports whether saving stopped early
+func (v *sniffSaver) Failed() bool {
+	return v != nil && v.err != nil
+}
+
+// Close closes the capture file
+func (v *sniffSaver) Close() error {
+	if v == nil {
+		return nil
+	}
+	return v.writer.Close()
+}
+
+// packetFrame builds the Ethernet frame a packet stands for, so the captures
+// sniff saves open in replay, capstats and Wireshark. The payload carries the
+// packet's info and pads the frame to its size.
+func packetFrame(packet Packet) ([]byte, error) {
+	src, dst := net.ParseIP(packet.Source), net.ParseIP(packet.Destination)
+	if src == nil || dst == nil {
+		return nil, fmt.Errorf("packet %s→%s has no IP addresses", packet.Source, packet.Destination)
+	}
+	ethernet := &layers.Ethernet{SrcMAC: frameMAC(src), DstMAC: frameMAC(dst), EthernetType: layers.EthernetTypeIPv4}
+	all := []gopacket.SerializableLayer{ethernet}
+
+	if packet.Protocol == "ARP" {
+		ethernet.EthernetType = layers.EthernetTypeARP
+		ethernet.DstMAC = layers.EthernetBroadcast
+		all = append(all, &layers.ARP{
+			AddrType: layers.LinkTypeEthernet, Protocol: layers.EthernetTypeIPv4,
+			HwAddressSize: 6, ProtAddressSize: 4, Operation: layers.ARPRequest,
+			SourceHwAddress: frameMAC(src), SourceProtAddress: src.To4(),
+			DstHwAddress: make([]byte, 6), DstProtAddress: dst.To4(),
+		})
+		return serializeFrame(all, nil, packet.Size)
+	}
+
+	var network gopacket.NetworkLayer
+	var protocol layers.IPProtocol
+	if src.To4() != nil && dst.To4() != nil {
+		ip := &layers.IPv4{Version: 4, TTL: 64, SrcIP: src.To4(), DstIP: dst.To4()}
+		network = ip
+		all = append(all, ip)
+	} else {
+		ethernet.EthernetType = layers.EthernetTypeIPv6
+		ip := &layers.IPv6{Version: 6, HopLimit: 64, SrcIP: src, DstIP: dst}
+		network = ip
+		all = append(all, ip)
+	}
+
+	switch {
+	case sniffTCPProtocols[packet.Protocol]:
+		protocol = layers.IPProtocolTCP
+		tcp := &layers.TCP{SrcPort: layers.TCPPort(packet.SourcePort), DstPort: layers.TCPPort(packet.DestPort), Window: 65535}
+		for _, flag := range packet.Flags {
+			switch flag {
+			case "SYN":
+				tcp.SYN = true
+			case "ACK":
+				tcp.ACK = true
+			case "PSH":
+				tcp.PSH = true
+			case "FIN":
+				tcp.FIN = true
+			case "RST":
+				tcp.RST = true
+			case "URG":
+				tcp.URG = true
+			}
+		}
+		tcp.SetNetworkLayerForChecksum(network)
+		all = append(all, tcp)
+	case packet.Protocol == "ICMP":
+		protocol = layers.IPProtocolICMPv4
+		all = append(all, &layers.ICMPv4{TypeCode: layers.CreateICMPv4TypeCode(layers.ICMPv4TypeEchoRequest, 0)})
+	default:
+		protocol = layers.IPProtocolUDP
+		udp := &layers.UDP{SrcPort: layers.UDPPort(packet.SourcePort), DstPort: layers.UDPPort(packet.DestPort)}
+		udp.SetNetworkLayerForChecksum(network)
+		all = append(all, udp)
+	}
+	if ip, ok := network.(*layers.IPv4); ok {
+		ip.Protocol = protocol
+	} else {
+		network.(*layers.IPv6).NextHeader = protocol
+	}
+	return serializeFrame(all, []byte(packet.Info), packet.Size)
+}
+
+// serializeFrame encodes the layers followed by the payload, padded with zeros
+// so the frame is size bytes when it fits
+func serializeFrame(all []gopacket.SerializableLayer, payload []byte, size int) ([]byte, error) {
+	options := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+	buffer := gopacket.NewSerializeBuffer()
+	if err := gopacket.SerializeLayers(buffer, options, all...); err != nil {
+		return nil, err
+	}
+	if pad := size - len(buffer.Bytes()) - len(payload); pad > 0 {
+		payload = append(payload, make([]byte, pad)...)
+	}
+
+	buffer = gopacket.NewSerializeBuffer()
+	if err := gopacket.SerializeLayers(buffer, options, append(all, gopacket.Payload(payload))...); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// frameMAC makes up a locally administered MAC address from an IP address, so
+// each host keeps the same one across the capture
+func frameMAC(ip net.IP) net.HardwareAddr {
+	if v4 := ip.To4(); v4 != nil {
+		return net.HardwareAddr{0x02, 0x00, v4[0], v4[1], v4[2], v4[3]}
+	}
+	return net.HardwareAddr{0x02, 0x00, ip[12], ip[13], ip[14], ip[15]}
+}
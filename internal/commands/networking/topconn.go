@@ -0,0 +1,485 @@
+package networking
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+
+	"github.com/fatih/color"
+)
+
+// TopConnectionsCommand shows which processes use the most network bandwidth,
+// refreshing like nethogs. Traffic is attributed from the byte counters of each
+// process's TCP connections between samples.
+type TopConnectionsCommand struct {
+	*commands.BaseCommand
+	commands.ToolRunner
+	flags *commands.FlagSet
+}
+
+// NewTopConnectionsCommand creates a new top-connections command
+func NewTopConnectionsCommand() *TopConnectionsCommand {
+	usage := "top-connections [--interval <seconds>] [--count <n>] [--filter <text>] [--limit <n>] [--output-format text|json|csv]"
+	return &TopConnectionsCommand{
+		BaseCommand: commands.NewBaseCommand(
+			"top-connections",
+			"Show the processes using the most network bandwidth, live",
+			usage,
+			[]string{"windows", "linux", "darwin"},
+			false,
+		),
+		flags: commands.NewFlagSet("top-connections", usage,
+			commands.FlagSpec{Name: "interval", Kind: commands.FloatFlag, Default: "2", Value: "seconds", Help: "Seconds between samples"},
+			commands.FlagSpec{Name: "count", Kind: commands.IntFlag, Value: "n", Help: "Stop after n refreshes"},
+			commands.FlagSpec{Name: "filter", Kind: commands.StringFlag, Value: "text", Help: "Only show processes whose name or PID contains text"},
+			commands.FlagSpec{Name: "limit", Kind: commands.IntFlag, Default: "20", Value: "n", Help: "Show at most n processes"},
+		),
+	}
+}
+
+// FlagSet returns the flags top-connections accepts
+func (t *TopConnectionsCommand) FlagSet() *commands.FlagSet {
+	return t.flags
+}
+
+// OutputFormats lists the structured formats top-connections can print, a
+// single measurement over one interval
+func (t *TopConnectionsCommand) OutputFormats() []commands.OutputFormat {
+	return []commands.OutputFormat{commands.FormatJSON, commands.FormatCSV}
+}
+
+// processTraffic is the network use of one process between two samples
+type processTraffic struct {
+	PID           int     `json:"pid"`
+	Process       string  `json:"process"`
+	Connections   int     `json:"connections"`
+	BytesSent     int64   `json:"bytes_sent"`
+	BytesReceived int64   `json:"bytes_received"`
+	SendRate      float64 `json:"send_rate"`
+	RecvRate      float64 `json:"recv_rate"`
+}
+
+// label returns "name(pid)" for the table
+func (p processTraffic) label() string {
+	return ConnSample{PID: p.PID, Process: p.Process}.ProcessLabel()
+}
+
+// interfaceCounters are the bytes moved by every non-loopback interface
+type interfaceCounters struct {
+	Received int64
+	Sent     int64
+}
+
+// topSample is one reading of the connections and interface counters
+type topSample struct {
+	at          time.Time
+	connections map[string]ConnSample
+	interfaces  interfaceCounters
+	// hasInterfaces is false when the platform's counters couldn't be read
+	hasInterfaces bool
+}
+
+// topOptions controls the live process table
+type topOptions struct {
+	interval time.Duration
+	count    int
+	filter   string
+	limit    int
+}
+
+// Execute samples the connections and shows the busiest processes
+func (t *TopConnectionsCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	startTime := time.Now()
+
+	flags, usage := t.flags.ParseArguments(args, startTime)
+	if usage != nil {
+		return usage, nil
+	}
+	options := topOptions{filter: flags.String("filter"), limit: flags.Int("limit")}
+
+	interval := flags.Float("interval")
+	if interval <= 0 {
+		return t.usageError(fmt.Sprintf("invalid --interval value '%s'", flags.String("interval")), startTime), nil
+	}
+	options.interval = time.Duration(interval * float64(time.Second))
+	if flags.Changed("count") {
+		if options.count = flags.Int("count"); options.count <= 0 {
+			return t.usageError(fmt.Sprintf("invalid --count value '%s'", flags.String("count")), startTime), nil
+		}
+	}
+	if options.limit <= 0 {
+		return t.usageError(fmt.Sprintf("invalid --limit value '%s'", flags.String("limit")), startTime), nil
+	}
+
+	resolver := NewConnResolver(false, t.Runner())
+	if output := commands.OutputOptionsFrom(ctx); output.Structured() {
+		return t.showStructured(ctx, output.Format, resolver, options, startTime), nil
+	}
+
+	samples, err := t.runLive(ctx, resolver, options)
+	if err != nil {
+		output := color.New(color.FgRed, color.Bold).Sprintf("❌ Failed to sample connections: %v\n", err)
+		return commands.ErrorResult(output, err, startTime), nil
+	}
+	return &commands.Result{
+		Output:   color.New(color.FgHiBlack).Sprintf("Monitored network use for %v (%d samples)\n", time.Since(startTime).Round(time.Second), samples),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}, nil
+}
+
+// runLive redraws the process table until Enter is pressed, count refreshes have
+// been shown, or ctx is cancelled. Rates need two samples, so the first frame
+// appears after one interval. It returns the samples taken.
+func (t *TopConnectionsCommand) runLive(ctx context.Context, resolver *ConnResolver, options topOptions) (int, error) {
+	stop := stopOnEnter(ctx, options.count)
+
+	out := commands.OutputWriter(ctx)
+	previous, err := t.sample(ctx, resolver)
+	if err != nil {
+		return 0, err
+	}
+	samples, frames := 1, 0
+	fmt.Fprint(out, color.New(color.FgHiBlack).Sprintf("📶 Measuring network use for %v...\n", options.interval))
+
+	for {
+		select {
+		case <-ctx.Done():
+			return samples, nil
+		case <-stop:
+			return samples, nil
+		case <-time.After(options.interval):
+		}
+
+		current, err := t.sample(ctx, resolver)
+		if err != nil {
+			return samples, err
+		}
+		samples++
+		frames++
+
+		fmt.Fprint(out, "\033[H\033[2J")
+		fmt.Fprint(out, renderTopFrame(previous, current, options, frames))
+		previous = current
+
+		if options.count > 0 && frames >= options.count {
+			return samples, nil
+		}
+	}
+}
+
+// showStructured measures for one interval and prints the processes as JSON or CSV
+func (t *TopConnectionsCommand) showStructured(ctx context.Context, format commands.OutputFormat, resolver *ConnResolver, options topOptions, startTime time.Time) *commands.Result {
+	first, err := t.sample(ctx, resolver)
+	if err != nil {
+		return commands.ErrorResult("", err, startTime)
+	}
+	select {
+	case <-ctx.Done():
+		return commands.ErrorResult("", ctx.Err(), startTime)
+	case <-time.After(options.interval):
+	}
+	second, err := t.sample(ctx, resolver)
+	if err != nil {
+		return commands.ErrorResult("", err, startTime)
+	}
+
+	processes := filterProcesses(processTrafficBetween(first, second), options.filter)
+	if format == commands.FormatCSV {
+		rows := make([][]string, 0, len(processes))
+		for _, process := range processes {
+			rows = append(rows, []string{strconv.Itoa(process.PID), process.Process, strconv.Itoa(process.Connections),
+				strconv.FormatInt(process.BytesSent, 10), strconv.FormatInt(process.BytesReceived, 10),
+				strconv.FormatFloat(process.SendRate, 'f', 0, 64), strconv.FormatFloat(process.RecvRate, 'f', 0, 64)})
+		}
+		return commands.CSVResult([]string{"pid", "process", "connections", "bytes_sent", "bytes_received", "send_rate", "recv_rate"}, rows, startTime)
+	}
+	result, err := commands.JSONResult(processes, startTime)
+	if err != nil {
+		return commands.ErrorResult("", err, startTime)
+	}
+	return result
+}
+
+// sample reads the connections with their owning processes, and the interface
+// counters when the platform offers them
+func (t *TopConnectionsCommand) sample(ctx context.Context, resolver *ConnResolver) (topSample, error) {
+	connections, err := sampleConnections(ctx, t.Runner(), true)
+	if err != nil {
+		return topSample{}, err
+	}
+	resolver.Resolve(ctx, connections)
+
+	sample := topSample{at: time.Now(), connections: make(map[string]ConnSample, len(connections))}
+	for _, connection := range connections {
+		sample.connections[connection.Key()] = connection
+	}
+	sample.interfaces, sample.hasInterfaces = readInterfaceCounters(ctx, t.Runner())
+	return sample, nil
+}
+
+// processTrafficBetween attributes the bytes each connection moved between two
+// samples to its process, busiest first. Connections to loopback addresses are
+// left out, as they never cross an interface.
+func processTrafficBetween(previous, current topSample) []processTraffic {
+	elapsed := current.at.Sub(previous.at).Seconds()
+	byPID := make(map[int]*processTraffic)
+	for key, connection := range current.connections {
+		if isLoopback(connection.Remote) {
+			continue
+		}
+		process := byPID[connection.PID]
+		if process == nil {
+			process = &processTraffic{PID: connection.PID, Process: connection.Process}
+			byPID[connection.PID] = process
+		}
+		process.Connections++
+
+		before, seen := previous.connections[key]
+		if !seen || !connection.HasBytes || connection.Sent < before.Sent || connection.Received < before.Received {
+			continue
+		}
+		process.BytesSent += connection.Sent - before.Sent
+		process.BytesReceived += connection.Received - before.Received
+	}
+
+	processes := make([]processTraffic, 0, len(byPID))
+	for _, process := range byPID {
+		if elapsed > 0 {
+			process.SendRate = float64(process.BytesSent) / elapsed
+			process.RecvRate = float64(process.BytesReceived) / elapsed
+		}
+		processes = append(processes, *process)
+	}
+	sort.Slice(processes, func(i, j int) bool {
+		a, b := processes[i].BytesSent+processes[i].BytesReceived, processes[j].BytesSent+processes[j].BytesReceived
+		if a != b {
+			return a > b
+		}
+		if processes[i].Connections != processes[j].Connections {
+			return processes[i].Connections > processes[j].Connections
+		}
+		return processes[i].PID < processes[j].PID
+	})
+	return processes
+}
+
+// filterProcesses keeps the processes whose name or PID contains filter, ignoring case
+func filterProcesses(processes []processTraffic, filter string) []processTraffic {
+	if filter == "" {
+		return processes
+	}
+	filter = strings.ToLower(filter)
+	kept := []processTraffic{}
+	for _, process := range processes {
+		if strings.Contains(strings.ToLower(process.Process+" "+strconv.Itoa(process.PID)), filter) {
+			kept = append(kept, process)
+		}
+	}
+	return kept
+}
+
+// isLoopback reports whether an address:port is on a loopback address
+func isLoopback(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+	ip := net.ParseIP(strings.SplitN(host, "%", 2)[0])
+	return ip != nil && ip.IsLoopback()
+}
+
+// readInterfaceCounters totals the bytes moved by the non-loopback interfaces.
+// Linux reads /proc/net/dev, Windows netstat -e and macOS netstat -ib.
+func readInterfaceCounters(ctx context.Context, runner commands.CommandRunner) (interfaceCounters, bool) {
+	switch runtime.GOOS {
+	case "linux":
+		data, err := ioutil.ReadFile("/proc/net/dev")
+		if err != nil {
+			return interfaceCounters{}, false
+		}
+		return parseProcNetDev(string(data))
+	case "windows":
+		out, err := runner.Run(ctx, "netstat", "-e")
+		if err != nil {
+			return interfaceCounters{}, false
+		}
+		return parseNetstatInterfaceBytes(string(out))
+	case "darwin":
+		out, err := runner.Run(ctx, "netstat", "-ib")
+		if err != nil {
+			return interfaceCounters{}, false
+		}
+		return parseNetstatIB(string(out))
+	}
+	return interfaceCounters{}, false
+}
+
+// parseProcNetDev sums the receive and transmit byte columns of /proc/net/dev
+func parseProcNetDev(text string) (interfaceCounters, bool) {
+	var counters interfaceCounters
+	found := false
+	for _, line := range strings.Split(text, "\n") {
+		colon := strings.Index(line, ":")
+		if colon < 0 {
+			continue
+		}
+		name := strings.TrimSpace(line[:colon])
+		fields := strings.Fields(line[colon+1:])
+		if name == "lo" || len(fields) < 9 {
+			continue
+		}
+		received, errRx := strconv.ParseInt(fields[0], 10, 64)
+		sent, errTx := strconv.ParseInt(fields[8], 10, 64)
+		if errRx != nil || errTx != nil {
+			continue
+		}
+		counters.Received += received
+		counters.Sent += sent
+		found = true
+	}
+	return counters, found
+}
+
+// parseNetstatInterfaceBytes reads the Bytes line of Windows' netstat -e, which
+// lists the received and then the sent totals
+func parseNetstatInterfaceBytes(text string) (interfaceCounters, bool) {
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 || fields[0] != "Bytes" {
+			continue
+		}
+		received, errRx := strconv.ParseInt(fields[1], 10, 64)
+		sent, errTx := strconv.ParseInt(fields[2], 10, 64)
+		if errRx == nil && errTx == nil {
+			return interfaceCounters{Received: received, Sent: sent}, true
+		}
+	}
+	return interfaceCounters{}, false
+}
+
+// parseNetstatIB sums the link rows of macOS's netstat -ib. Every interface has one
+// row per address, so only the <Link#n> rows are counted; their Address column is
+// empty for interfaces without a hardware address.
+func parseNetstatIB(text string) (interfaceCounters, bool) {
+	var counters interfaceCounters
+	found := false
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || !strings.HasPrefix(fields[2], "<Link#") || strings.HasPrefix(fields[0], "lo") {
+			continue
+		}
+		// Name Mtu Network [Address] Ipkts Ierrs Ibytes Opkts Oerrs Obytes Coll
+		ibytes, obytes := 6, 9
+		if len(fields) == 10 {
+			ibytes, obytes = 5, 8
+		}
+		received, errRx := strconv.ParseInt(fields[ibytes], 10, 64)
+		sent, errTx := strconv.ParseInt(fields[obytes], 10, 64)
+		if errRx != nil || errTx != nil {
+			continue
+		}
+		counters.Received += received
+		counters.Sent += sent
+		found = true
+	}
+	return counters, found
+}
+
+// renderTopFrame renders one refresh of the process table
+func renderTopFrame(previous, current topSample, options topOptions, frame int) string {
+	var output strings.Builder
+
+	output.WriteString(color.New(color.FgCyan, color.Bold).Sprint("📶 TOP CONNECTIONS"))
+	output.WriteString(color.New(color.FgHiBlack).Sprintf("  refresh %v · sample %d · %s\n",
+		options.interval, frame, current.at.Format("15:04:05")))
+	output.WriteString("═══════════════════════════════════════════════════════════════════════════════\n")
+
+	all := processTrafficBetween(previous, current)
+	processes := filterProcesses(all, options.filter)
+
+	// The arrows take three bytes but one column, hence the wider verbs
+	output.WriteString(color.New(color.FgCyan).Sprintf("   %-32s %5s %13s %13s %12s\n", "Process", "Conns", "↑ Send", "↓ Recv", "Total"))
+
+	var totalSend, totalRecv float64
+	connections := 0
+	hasBytes := false
+	for i, process := range processes {
+		totalSend += process.SendRate
+		totalRecv += process.RecvRate
+		connections += process.Connections
+		if i >= options.limit {
+			continue
+		}
+
+		icon := "⚪"
+		if process.BytesSent+process.BytesReceived > 0 {
+			icon = color.New(color.FgGreen).Sprint("🟢")
+		}
+		output.WriteString(fmt.Sprintf("%s %-32s %5d %11s %11s %10s\n", icon, FitColumn(process.label(), 32), process.Connections,
+			commands.HumanizeBytes(int64(process.SendRate))+"/s", commands.HumanizeBytes(int64(process.RecvRate))+"/s",
+			commands.HumanizeBytes(process.BytesSent+process.BytesReceived)))
+	}
+	if len(processes) > options.limit {
+		output.WriteString(fmt.Sprintf("   ... and %d more\n", len(processes)-options.limit))
+	}
+	for _, connection := range current.connections {
+		hasBytes = hasBytes || connection.HasBytes
+	}
+
+	output.WriteString("───────────────────────────────────────────────────────────────────────────────\n")
+	output.WriteString(fmt.Sprintf("📊 %d processes · %d connections · ↑ %s/s · ↓ %s/s\n", len(processes), connections,
+		commands.HumanizeBytes(int64(totalSend)), commands.HumanizeBytes(int64(totalRecv))))
+
+	if previous.hasInterfaces && current.hasInterfaces {
+		elapsed := current.at.Sub(previous.at).Seconds()
+		sendRate := float64(current.interfaces.Sent-previous.interfaces.Sent) / elapsed
+		recvRate := float64(current.interfaces.Received-previous.interfaces.Received) / elapsed
+		line := fmt.Sprintf("🖧  Interfaces: ↑ %s/s · ↓ %s/s", commands.HumanizeBytes(int64(sendRate)), commands.HumanizeBytes(int64(recvRate)))
+		if options.filter == "" {
+			// UDP, and connections that closed between samples, show up only here
+			if other := sendRate + recvRate - totalSend - totalRecv; other > 0 && hasBytes {
+				line += color.New(color.FgHiBlack).Sprintf(" (%s/s not attributed to a process)", commands.HumanizeBytes(int64(other)))
+			}
+		}
+		output.WriteString(line + "\n")
+	}
+	if options.filter != "" {
+		output.WriteString(fmt.Sprintf("🔎 Filter: %s (%d of %d)\n", color.New(color.FgYellow).Sprint(options.filter), len(processes), len(all)))
+	}
+	if !hasBytes && len(current.connections) > 0 {
+		output.WriteString(color.New(color.FgHiBlack).Sprint("💡 Per-connection byte counters are not available on this system; only connection counts are shown\n"))
+	}
+	for _, process := range all {
+		if process.PID == 0 {
+			output.WriteString(color.New(color.FgHiBlack).Sprintf("💡 Connections shown as - belong to processes you can't inspect; %s\n", commands.ElevationHint()))
+			break
+		}
+	}
+	if options.count == 0 {
+		output.WriteString(color.New(color.FgHiBlack).Sprint("Press Enter to stop\n"))
+	}
+
+	return output.String()
+}
+
+// usageError reports a bad argument along with the usage line
+func (t *TopConnectionsCommand) usageError(message string, startTime time.Time) *commands.Result {
+	return &commands.Result{
+		Output:   "Usage: " + t.Usage() + "\n",
+		Error:    commands.UsageError(t.Name(), "%s", message),
+		ExitCode: 1,
+		Duration: time.Since(startTime),
+	}
+}
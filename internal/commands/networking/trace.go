@@ -0,0 +1,679 @@
+package networking
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/types"
+	"suppercommand/pkg/errors"
+
+	"github.com/fatih/color"
+)
+
+// TraceCommand reports what a process does with files and the network, the
+// way sniff shows its packets: trace -p 1234 follows a running process and
+// trace curl example.com starts one under the tracer.
+type TraceCommand struct {
+	*commands.BaseCommand
+	commands.ToolRunner
+	flags *commands.FlagSet
+}
+
+// NewTraceCommand creates a new trace command
+func NewTraceCommand() *TraceCommand {
+	usage := "trace [-p <pid>] [-e <events>] [--path <pattern>] [--failed] [--lib] [-c <count>] [-t <duration>] [--interval <seconds>] [--json] [<program> [args...]]"
+	return &TraceCommand{
+		BaseCommand: commands.NewBaseCommand(
+			"trace",
+			"Show the file and network activity of a process, like a lightweight strace",
+			usage,
+			[]string{"windows", "linux"},
+			false,
+		),
+		flags: commands.NewFlagSet("trace", usage,
+			commands.FlagSpec{Name: "pid", Short: "p", Kind: commands.IntFlag, Value: "pid", Help: "Attach to a running process instead of starting a program"},
+			commands.FlagSpec{Name: "events", Short: "e", Kind: commands.StringFlag, Default: "file,net", Value: "events", Help: "Kinds to show: open, read, write, close, exec, delete, rename, connect, accept, bind, or the groups file, io, net and all"},
+			commands.FlagSpec{Name: "path", Kind: commands.StringFlag, Value: "pattern", Help: "Only show events whose file or address contains the text or matches the glob"},
+			commands.FlagSpec{Name: "failed", Help: "Only show calls that failed"},
+			commands.FlagSpec{Name: "lib", Help: "Trace C library calls with ltrace instead of system calls with strace (Linux)"},
+			commands.FlagSpec{Name: "count", Short: "c", Kind: commands.IntFlag, Default: "0", Value: "n", Help: "Stop after n events, 0 for no limit"},
+			commands.FlagSpec{Name: "duration", Short: "t", Kind: commands.StringFlag, Value: "duration", Help: "Stop after this long, such as 30s"},
+			commands.FlagSpec{Name: "interval", Kind: commands.FloatFlag, Default: "1", Value: "seconds", Help: "Seconds between samples of open files on Windows"},
+			commands.FlagSpec{Name: "json", Help: "Stream the events as JSON lines"},
+		),
+	}
+}
+
+// FlagSet returns the options trace accepts
+func (t *TraceCommand) FlagSet() *commands.FlagSet {
+	return t.flags
+}
+
+// traceOptions controls what trace follows and shows
+type traceOptions struct {
+	pid      int
+	program  []string
+	kinds    map[string]bool
+	pattern  string
+	failed   bool
+	library  bool
+	count    int
+	duration time.Duration
+	interval time.Duration
+	json     bool
+}
+
+// traceSummary counts what a trace saw
+type traceSummary struct {
+	shown   int
+	failed  int
+	kinds   map[string]int
+	targets map[string]int
+	// exitCode is the exit code of the program trace started
+	exitCode int
+}
+
+// Execute traces the process until it exits, the count or duration is
+// reached, or the trace is cancelled, then summarizes what it saw
+func (t *TraceCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	startTime := time.Now()
+
+	// Options end at the program so its own flags pass through
+	options, program := t.flags.SplitLeading(args.Raw)
+	flags, result := t.flags.ParseArguments(commands.ParseArguments(options), startTime)
+	if result != nil {
+		return result, nil
+	}
+	opts, usageErr := t.parseOptions(flags, program)
+	if usageErr != "" {
+		return commands.UsageResult(t, startTime, "%s", usageErr), nil
+	}
+
+	if opts.library {
+		if _, err := t.Runner().LookPath("ltrace"); err != nil {
+			return commands.ErrorResult("", errors.NewNotFoundError("ltrace not found on PATH; install it, for example with 'sudo apt install ltrace' or 'sudo dnf install ltrace'"), startTime), nil
+		}
+	} else if err := RequireCapability(CapabilityTrace); err != nil {
+		return commands.ErrorResult("", err, startTime), nil
+	}
+	if opts.pid > 0 && runtime.GOOS == "linux" {
+		if _, err := os.Stat(fmt.Sprintf("/proc/%d", opts.pid)); err != nil {
+			return commands.ErrorResult("", errors.NewNotFoundError("no process with pid %d", opts.pid), startTime), nil
+		}
+	}
+
+	traceCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if opts.duration > 0 {
+		traceCtx, cancel = context.WithTimeout(traceCtx, opts.duration)
+		defer cancel()
+	}
+
+	summary := traceSummary{kinds: make(map[string]int), targets: make(map[string]int)}
+	// The traced program's stdout is copied into the same writer by exec
+	out := &lockedWriter{w: commands.OutputWriter(ctx)}
+	var mu sync.Mutex
+	emit := func(event TraceEvent) {
+		if !opts.shows(event) {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if opts.count > 0 && summary.shown >= opts.count {
+			return
+		}
+		if event.Kind != TraceExit {
+			summary.shown++
+			summary.kinds[event.Kind]++
+			if event.Failed() {
+				summary.failed++
+			}
+			if event.Target != "" {
+				summary.targets[event.Target]++
+			}
+		}
+		if opts.json {
+			data, _ := json.Marshal(event)
+			fmt.Fprintln(out, string(data))
+		} else {
+			fmt.Fprintln(out, traceLine(event))
+		}
+		if opts.count > 0 && summary.shown >= opts.count {
+			cancel()
+		}
+	}
+
+	t.header(ctx, opts)
+	var err error
+	if runtime.GOOS == "windows" {
+		summary.exitCode, err = t.pollHandles(traceCtx, opts, emit)
+	} else {
+		summary.exitCode, err = t.runTracer(traceCtx, opts, out, emit)
+	}
+	if err != nil {
+		return commands.ErrorResult("", err, startTime), nil
+	}
+	return t.finish(ctx, opts, summary, startTime), nil
+}
+
+// lockedWriter serialises writes to w, so a traced program's output and the
+// events between its lines don't interleave mid-line
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
+
+// parseOptions checks the flags and program, returning why they are unusable
+func (t *TraceCommand) parseOptions(flags *commands.ParsedFlags, program []string) (traceOptions, string) {
+	opts := traceOptions{
+		pid:     flags.Int("pid"),
+		program: program,
+		pattern: flags.String("path"),
+		failed:  flags.Bool("failed"),
+		library: flags.Bool("lib"),
+		count:   flags.Int("count"),
+		json:    flags.Bool("json"),
+	}
+	switch {
+	case flags.Changed("pid") && opts.pid <= 0:
+		return opts, fmt.Sprintf("invalid --pid value '%s'", flags.String("pid"))
+	case opts.pid > 0 && len(program) > 0:
+		return opts, "give either --pid or a program to start, not both"
+	case opts.pid == 0 && len(program) == 0:
+		return opts, "expected --pid or a program to trace"
+	case opts.count < 0:
+		return opts, "--count can't be negative"
+	case opts.library && runtime.GOOS != "linux":
+		return opts, "--lib uses ltrace, which only runs on Linux"
+	}
+
+	kinds, unknown, ok := parseTraceEvents(flags.String("events"))
+	if !ok {
+		if len(unknown) > 0 {
+			return opts, fmt.Sprintf("unknown event kind '%s'", strings.Join(unknown, ", "))
+		}
+		return opts, "--events needs at least one kind"
+	}
+	if runtime.GOOS == "windows" && !kinds[TraceOpen] && !kinds[TraceClose] && !kinds[TraceConnect] && !kinds[TraceBind] {
+		return opts, "on Windows trace sees opened and closed files and connections, not reads and writes"
+	}
+	opts.kinds = kinds
+
+	if flags.Changed("duration") {
+		duration, err := time.ParseDuration(flags.String("duration"))
+		if err != nil || duration <= 0 {
+			return opts, fmt.Sprintf("invalid --duration value '%s'", flags.String("duration"))
+		}
+		opts.duration = duration
+	}
+	interval := flags.Float("interval")
+	if interval <= 0 {
+		return opts, fmt.Sprintf("invalid --interval value '%s'", flags.String("interval"))
+	}
+	opts.interval = time.Duration(interval * float64(time.Second))
+	return opts, ""
+}
+
+// shows reports whether an event passes the filters; a process exiting
+// always does
+func (opts traceOptions) shows(event TraceEvent) bool {
+	if event.Kind == TraceExit {
+		return true
+	}
+	if !opts.kinds[event.Kind] || (opts.failed && !event.Failed()) {
+		return false
+	}
+	if opts.pattern == "" {
+		return true
+	}
+	if strings.ContainsAny(opts.pattern, "*?[") {
+		if ok, _ := path.Match(opts.pattern, event.Target); ok {
+			return true
+		}
+		ok, _ := path.Match(opts.pattern, path.Base(strings.Replace(event.Target, `\`, "/", -1)))
+		return ok
+	}
+	return strings.Contains(strings.ToLower(event.Target), strings.ToLower(opts.pattern))
+}
+
+// header announces what is traced and how
+func (t *TraceCommand) header(ctx context.Context, opts traceOptions) {
+	var kinds []string
+	for _, kind := range traceKinds {
+		if opts.kinds[kind] {
+			kinds = append(kinds, kind)
+		}
+	}
+	target := "pid " + strconv.Itoa(opts.pid)
+	if len(opts.program) > 0 {
+		target = commands.CommandLine(opts.program[0], opts.program[1:])
+	}
+	tool := "strace"
+	switch {
+	case opts.library:
+		tool = "ltrace"
+	case runtime.GOOS == "windows":
+		tool = "handle"
+	}
+
+	progress := commands.ProgressWriter(ctx)
+	fmt.Fprint(progress, color.New(color.FgCyan, color.Bold).Sprint("🔬 TRACING "+target))
+	fmt.Fprint(progress, color.New(color.FgHiBlack).Sprintf("  with %s · %s\n", tool, strings.Join(kinds, ",")))
+	if runtime.GOOS == "windows" {
+		fmt.Fprint(progress, color.New(color.FgHiBlack).Sprintf("Sampling open files and connections every %v; reads and writes aren't visible\n", opts.interval))
+	}
+	if opts.pid > 0 && opts.count == 0 && opts.duration == 0 {
+		fmt.Fprint(progress, color.New(color.FgHiBlack).Sprint("Press Enter to stop\n"))
+	}
+}
+
+// traceLine formats one event for the stream
+func traceLine(event TraceEvent) string {
+	at := event.Time
+	if i := strings.IndexByte(at, '.'); i >= 0 && len(at) > i+4 {
+		at = at[:i+4]
+	}
+	pid := color.New(color.FgHiBlack).Sprintf("[%d]", event.PID)
+	if event.Kind == TraceExit {
+		if event.Error != "" {
+			return color.New(color.FgYellow).Sprintf("%s %s process killed by %s", at, pid, event.Result)
+		}
+		return color.New(color.FgYellow).Sprintf("%s %s process exited with %s", at, pid, event.Result)
+	}
+
+	line := fmt.Sprintf("%s %s %s %s", at, pid, traceKindColor(event).Sprintf("%-7s", event.Kind), event.Target)
+	switch {
+	case event.Failed():
+		line += color.New(color.FgRed).Sprintf("  ✗ %s", event.Error)
+	case event.Bytes > 0:
+		line += color.New(color.FgHiBlack).Sprintf("  %s", types.FormatBytes(uint64(event.Bytes)))
+	}
+	return line
+}
+
+// traceKindColor is the color of an event's kind, red when the call failed
+func traceKindColor(event TraceEvent) *color.Color {
+	if event.Failed() {
+		return color.New(color.FgRed, color.Bold)
+	}
+	switch event.Kind {
+	case TraceOpen, TraceClose:
+		return color.New(color.FgCyan)
+	case TraceRead, TraceWrite:
+		return color.New(color.FgBlue)
+	case TraceExec:
+		return color.New(color.FgYellow, color.Bold)
+	case TraceDelete, TraceRename:
+		return color.New(color.FgMagenta)
+	}
+	return color.New(color.FgGreen)
+}
+
+// runTracer runs strace, or ltrace, on the process and streams its events.
+// The tracer writes them to a pipe of their own, so the program's output
+// goes to ours untouched, and its complaints on stderr explain a failure.
+func (t *TraceCommand) runTracer(ctx context.Context, opts traceOptions, stdout io.Writer, emit func(TraceEvent)) (int, error) {
+	tool := "strace"
+	calls := traceCallNames(opts.kinds, opts.library)
+	args := []string{"-f", "-tt", "-s", "32", "-o", "/dev/fd/3"}
+	if opts.library {
+		tool = "ltrace"
+		args = append(args, "-e", strings.Join(calls, "+"))
+	} else {
+		// ? keeps strace from refusing calls this architecture doesn't have, like open on arm64
+		args = append(args, "-q", "-yy", "-e", "trace=?"+strings.Join(calls, ",?"))
+	}
+	if opts.pid > 0 {
+		args = append(args, "-p", strconv.Itoa(opts.pid))
+	} else {
+		args = append(append(args, "--"), opts.program...)
+	}
+
+	events, writer, err := os.Pipe()
+	if err != nil {
+		return -1, err
+	}
+	defer events.Close()
+	complaints := &toolComplaints{w: commands.ErrorWriter(ctx), prefix: tool + ":"}
+	cmd := exec.Command(tool, args...)
+	cmd.ExtraFiles = []*os.File{writer}
+	cmd.Stdout, cmd.Stderr = stdout, complaints
+	if opts.pid == 0 {
+		cmd.Stdin = commands.InputReader(ctx)
+	}
+	if err := cmd.Start(); err != nil {
+		writer.Close()
+		return -1, errors.Wrap(err, "failed to start %s", tool)
+	}
+	writer.Close()
+
+	// An attached process is left running: the tracer is interrupted so it
+	// detaches, and killed only if it doesn't stop
+	finished := make(chan struct{})
+	defer close(finished)
+	stop := stopOnEnter(ctx, boolCount(opts.pid == 0 || opts.count > 0 || opts.duration > 0))
+	go func() {
+		select {
+		case <-finished:
+			return
+		case <-ctx.Done():
+		case <-stop:
+		}
+		cmd.Process.Signal(os.Interrupt)
+		select {
+		case <-finished:
+		case <-time.After(2 * time.Second):
+			cmd.Process.Kill()
+		}
+	}()
+
+	parser := NewTraceParser(opts.library)
+	root, exitCode := 0, -1
+	scanner := bufio.NewScanner(events)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		event, ok := parser.Parse(scanner.Text())
+		if !ok {
+			continue
+		}
+		if root == 0 {
+			root = event.PID
+		}
+		if event.Kind == TraceExit && event.PID == root && event.Error == "" {
+			exitCode, _ = strconv.Atoi(event.Result)
+		}
+		emit(event)
+	}
+
+	code, err := exitCodeOf(cmd.Wait())
+	if err != nil {
+		return -1, errors.Wrap(err, "%s failed", tool)
+	}
+	if refusal := traceRefusal(tool, opts, complaints.lines()); refusal != nil {
+		return -1, refusal
+	}
+	if opts.pid == 0 {
+		if exitCode < 0 {
+			exitCode = code
+		}
+		return exitCode, nil
+	}
+	return 0, nil
+}
+
+// boolCount turns whether a run has its own end into the count stopOnEnter takes
+func boolCount(limited bool) int {
+	if limited {
+		return 1
+	}
+	return 0
+}
+
+// exitCodeOf turns the error of a finished process into its exit code, leaving
+// only failures to wait for it as errors
+func exitCodeOf(err error) (int, error) {
+	if err == nil {
+		return 0, nil
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return exitErr.ExitCode(), nil
+	}
+	return -1, err
+}
+
+// traceRefusal explains what the tracer complained about when it couldn't
+// trace, or returns nil when it didn't complain
+func traceRefusal(tool string, opts traceOptions, complaints []string) error {
+	target := fmt.Sprintf("pid %d", opts.pid)
+	if len(opts.program) > 0 {
+		target = opts.program[0]
+	}
+	for _, complaint := range complaints {
+		lower := strings.ToLower(complaint)
+		switch {
+		case strings.Contains(lower, "no such process"):
+			return errors.NewNotFoundError("no process with pid %d", opts.pid)
+		case strings.Contains(lower, "can't stat") || strings.Contains(lower, "cannot find executable") || strings.Contains(lower, "no such file"):
+			return errors.NewNotFoundError("%s: program not found", target)
+		case strings.Contains(lower, "operation not permitted") && strings.Contains(lower, "traceme"):
+			return errors.NewPermissionError("%s may not trace here (%s); in a container it needs --cap-add SYS_PTRACE", tool, complaint)
+		case strings.Contains(lower, "operation not permitted"):
+			return errors.NewPermissionError("%s may not attach to %s; run with sudo, or allow it with 'sudo sysctl kernel.yama.ptrace_scope=0'", tool, target)
+		}
+	}
+	return nil
+}
+
+// toolComplaints passes a tool's stderr through while keeping the lines the
+// tool itself wrote, which start with its name
+type toolComplaints struct {
+	w      io.Writer
+	prefix string
+
+	mu      sync.Mutex
+	partial string
+	kept    []string
+}
+
+func (c *toolComplaints) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	text := c.partial + string(p)
+	for {
+		i := strings.IndexByte(text, '\n')
+		if i < 0 {
+			break
+		}
+		c.keep(text[:i])
+		text = text[i+1:]
+	}
+	// Only the start of a line matters, so a long one isn't held on to
+	if len(text) > 256 {
+		text = text[:256]
+	}
+	c.partial = text
+	c.mu.Unlock()
+	return c.w.Write(p)
+}
+
+// keep records a line when the tool wrote it
+func (c *toolComplaints) keep(line string) {
+	if line = strings.TrimSpace(line); strings.HasPrefix(line, c.prefix) && len(c.kept) < 16 {
+		c.kept = append(c.kept, line)
+	}
+}
+
+// lines returns what the tool complained about, including an unfinished last line
+func (c *toolComplaints) lines() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.keep(c.partial)
+	c.partial = ""
+	return append([]string(nil), c.kept...)
+}
+
+// pollHandles follows a process on Windows by listing its open files with
+// Sysinternals Handle and its connections with netstat every interval. Files
+// and connections already open when attaching are the baseline, not events.
+func (t *TraceCommand) pollHandles(ctx context.Context, opts traceOptions, emit func(TraceEvent)) (int, error) {
+	tool := "handle64"
+	if _, err := t.Runner().LookPath(tool); err != nil {
+		tool = "handle"
+	}
+
+	pid, exited := opts.pid, make(chan struct{})
+	exitCode := 0
+	if len(opts.program) > 0 {
+		cmd := exec.Command(opts.program[0], opts.program[1:]...)
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = commands.InputReader(ctx), commands.OutputWriter(ctx), commands.ErrorWriter(ctx)
+		if err := cmd.Start(); err != nil {
+			if execErr, ok := err.(*exec.Error); ok && execErr.Err == exec.ErrNotFound {
+				return -1, errors.NewNotFoundError("%s: program not found", opts.program[0])
+			}
+			return -1, err
+		}
+		pid = cmd.Process.Pid
+		go func() {
+			exitCode, _ = exitCodeOf(cmd.Wait())
+			close(exited)
+		}()
+		defer func() {
+			select {
+			case <-exited:
+			default:
+				cmd.Process.Kill()
+				<-exited
+			}
+		}()
+	}
+	stop := stopOnEnter(ctx, boolCount(len(opts.program) > 0 || opts.count > 0 || opts.duration > 0))
+
+	files := make(map[string]bool)
+	connections := make(map[string]ConnSample)
+	for sample := 0; ; sample++ {
+		output, err := t.Runner().Run(ctx, tool, "-accepteula", "-nobanner", "-p", strconv.Itoa(pid))
+		if ctx.Err() != nil {
+			break
+		}
+		if err != nil && !strings.Contains(string(output), "No matching handles") {
+			return -1, errors.Wrap(err, "failed to list the open files of pid %d", pid)
+		}
+		if strings.Contains(string(output), "No matching handles") && opts.pid > 0 && sample == 0 {
+			return -1, errors.NewNotFoundError("no process with pid %d, or no access to it; other users' processes need an elevated shell", pid)
+		}
+		current := make(map[string]bool)
+		for _, file := range ParseHandleList(string(output)) {
+			current[file] = true
+		}
+		active, _ := sampleConnections(ctx, t.Runner(), true)
+		owned := make(map[string]ConnSample)
+		for _, connection := range active {
+			if connection.PID == pid {
+				owned[connection.Key()] = connection
+			}
+		}
+
+		// Attaching starts from what is already open; a started program from nothing
+		if sample > 0 || len(opts.program) > 0 {
+			now := time.Now().Format("15:04:05.000")
+			for _, file := range sortedKeys(current) {
+				if !files[file] {
+					emit(TraceEvent{Time: now, PID: pid, Kind: TraceOpen, Call: "handle", Target: file})
+				}
+			}
+			for _, file := range sortedKeys(files) {
+				if !current[file] {
+					emit(TraceEvent{Time: now, PID: pid, Kind: TraceClose, Call: "handle", Target: file})
+				}
+			}
+			opened, _ := connectionChanges(connections, values(owned))
+			for _, connection := range opened {
+				kind, target := TraceConnect, connection.Remote
+				if strings.EqualFold(connection.State, "LISTEN") || strings.EqualFold(connection.State, "LISTENING") {
+					kind, target = TraceBind, connection.Local
+				}
+				emit(TraceEvent{Time: now, PID: pid, Kind: kind, Call: "netstat", Target: target, Result: connection.State})
+			}
+		}
+		files, connections = current, owned
+
+		select {
+		case <-ctx.Done():
+		case <-stop:
+		case <-exited:
+			emit(TraceEvent{Time: time.Now().Format("15:04:05.000"), PID: pid, Kind: TraceExit, Call: "exit", Result: strconv.Itoa(exitCode)})
+			return exitCode, nil
+		case <-time.After(opts.interval):
+			continue
+		}
+		break
+	}
+	return exitCode, nil
+}
+
+// sortedKeys returns the keys of a set in order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// values returns the connections of a sample keyed by address
+func values(connections map[string]ConnSample) []ConnSample {
+	list := make([]ConnSample, 0, len(connections))
+	for _, connection := range connections {
+		list = append(list, connection)
+	}
+	return list
+}
+
+// finish summarizes the trace on stderr and exits with the code of the
+// program trace started
+func (t *TraceCommand) finish(ctx context.Context, opts traceOptions, summary traceSummary, startTime time.Time) *commands.Result {
+	var stderr strings.Builder
+	var counts []string
+	for _, kind := range traceKinds {
+		if n := summary.kinds[kind]; n > 0 {
+			counts = append(counts, fmt.Sprintf("%d %s", n, kind))
+		}
+	}
+	if summary.failed > 0 {
+		counts = append(counts, color.New(color.FgRed).Sprintf("%d failed", summary.failed))
+	}
+	if len(counts) == 0 {
+		counts = append(counts, "nothing matched")
+	}
+	stderr.WriteString(color.New(color.FgHiBlack).Sprintf("Traced %d events in %v: ", summary.shown, time.Since(startTime).Round(time.Second)))
+	stderr.WriteString(strings.Join(counts, ", ") + "\n")
+
+	if commands.OutputOptionsFrom(ctx).Decorated() && len(summary.targets) > 1 {
+		targets := make([]string, 0, len(summary.targets))
+		for target := range summary.targets {
+			targets = append(targets, target)
+		}
+		sort.Slice(targets, func(i, j int) bool {
+			if summary.targets[targets[i]] != summary.targets[targets[j]] {
+				return summary.targets[targets[i]] > summary.targets[targets[j]]
+			}
+			return targets[i] < targets[j]
+		})
+		if len(targets) > 5 {
+			targets = targets[:5]
+		}
+		stderr.WriteString(color.New(color.FgHiBlack).Sprint("Most active:\n"))
+		for _, target := range targets {
+			stderr.WriteString(color.New(color.FgHiBlack).Sprintf("  %5d  %s\n", summary.targets[target], target))
+		}
+	}
+
+	exitCode := 0
+	if len(opts.program) > 0 {
+		exitCode = summary.exitCode
+	}
+	return &commands.Result{
+		Stderr:   stderr.String(),
+		ExitCode: exitCode,
+		Duration: time.Since(startTime),
+	}
+}
@@ -0,0 +1,410 @@
+package networking
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The kinds of activity trace reports
+const (
+	TraceOpen    = "open"
+	TraceRead    = "read"
+	TraceWrite   = "write"
+	TraceClose   = "close"
+	TraceExec    = "exec"
+	TraceDelete  = "delete"
+	TraceRename  = "rename"
+	TraceConnect = "connect"
+	TraceAccept  = "accept"
+	TraceBind    = "bind"
+	// TraceExit marks a traced process ending; it is always reported
+	TraceExit = "exit"
+)
+
+// traceKinds are the kinds --events accepts, in the order summaries list them
+var traceKinds = []string{TraceOpen, TraceRead, TraceWrite, TraceClose, TraceExec, TraceDelete, TraceRename, TraceConnect, TraceAccept, TraceBind}
+
+// traceGroups are the names --events accepts for several kinds at once
+var traceGroups = map[string][]string{
+	"file": {TraceOpen, TraceExec, TraceDelete, TraceRename},
+	"io":   {TraceRead, TraceWrite},
+	"net":  {TraceConnect, TraceAccept, TraceBind},
+	"all":  traceKinds,
+}
+
+// straceCalls maps the system calls strace reports to the kind of each
+var straceCalls = map[string]string{
+	"open": TraceOpen, "openat": TraceOpen, "openat2": TraceOpen, "creat": TraceOpen,
+	"read": TraceRead, "pread64": TraceRead, "readv": TraceRead, "preadv": TraceRead, "preadv2": TraceRead,
+	"recvfrom": TraceRead, "recvmsg": TraceRead,
+	"write": TraceWrite, "pwrite64": TraceWrite, "writev": TraceWrite, "pwritev": TraceWrite, "pwritev2": TraceWrite,
+	"sendto": TraceWrite, "sendmsg": TraceWrite,
+	"close":  TraceClose,
+	"execve": TraceExec, "execveat": TraceExec,
+	"unlink": TraceDelete, "unlinkat": TraceDelete, "rmdir": TraceDelete,
+	"rename": TraceRename, "renameat": TraceRename, "renameat2": TraceRename,
+	"connect": TraceConnect,
+	"accept":  TraceAccept, "accept4": TraceAccept,
+	"bind": TraceBind,
+}
+
+// ltraceCalls maps the C library functions ltrace reports to the kind of each
+var ltraceCalls = map[string]string{
+	"fopen": TraceOpen, "fopen64": TraceOpen, "freopen": TraceOpen, "open": TraceOpen, "open64": TraceOpen,
+	"openat": TraceOpen, "openat64": TraceOpen, "creat": TraceOpen,
+	"read": TraceRead, "fread": TraceRead, "recv": TraceRead, "recvfrom": TraceRead,
+	"write": TraceWrite, "fwrite": TraceWrite, "send": TraceWrite, "sendto": TraceWrite,
+	"close": TraceClose, "fclose": TraceClose,
+	"execve": TraceExec, "execv": TraceExec, "execvp": TraceExec, "execl": TraceExec, "execlp": TraceExec,
+	"system": TraceExec, "popen": TraceExec, "posix_spawn": TraceExec,
+	"unlink": TraceDelete, "remove": TraceDelete, "rmdir": TraceDelete,
+	"rename":  TraceRename,
+	"connect": TraceConnect,
+	"accept":  TraceAccept, "accept4": TraceAccept,
+	"bind": TraceBind,
+}
+
+// TraceEvent is one piece of activity of a traced process
+type TraceEvent struct {
+	Time string `json:"time"`
+	PID  int    `json:"pid"`
+	Kind string `json:"kind"`
+	// Call is the system call or library function that did it
+	Call string `json:"call"`
+	// Target is the file, address or descriptor acted on
+	Target string `json:"target"`
+	Result string `json:"result,omitempty"`
+	// Error is the errno of a failed call, such as ENOENT
+	Error string `json:"error,omitempty"`
+	// Bytes is how much a read or write moved
+	Bytes int64 `json:"bytes,omitempty"`
+}
+
+// Failed reports whether the call failed
+func (e TraceEvent) Failed() bool {
+	return e.Error != ""
+}
+
+// TraceParser turns the lines strace or ltrace write with -f -tt into
+// events, joining calls they split over two lines while another process ran
+type TraceParser struct {
+	calls   map[string]string
+	library bool
+	pending map[int]string
+}
+
+// NewTraceParser creates a parser for the output of strace, or of ltrace when
+// library is set
+func NewTraceParser(library bool) *TraceParser {
+	calls := straceCalls
+	if library {
+		calls = ltraceCalls
+	}
+	return &TraceParser{calls: calls, library: library, pending: make(map[int]string)}
+}
+
+var (
+	// tracePrefix matches the pid, written as [pid N] or N, and the time of a line
+	tracePrefix = regexp.MustCompile(`^(?:\[pid\s+(\d+)\]\s+|(\d+)\s+)?(\d{2}:\d{2}:\d{2}(?:\.\d+)?)\s+`)
+	// traceResumed matches the rest of a call that was split
+	traceResumed = regexp.MustCompile(`^<\.\.\. (\w+) resumed>\s?`)
+	// traceExited matches the line strace, or ltrace, writes when a process ends
+	traceExited = regexp.MustCompile(`^\+\+\+ (?:exited with (\d+)|exited \(status (\d+)\)|killed by (\w+).*) \+\+\+$`)
+	// traceErrno matches the result of a failed system call, -1 ENOENT (...)
+	traceErrno = regexp.MustCompile(`^-\d+ (E[A-Z0-9]+)`)
+	// traceAnnotation matches a descriptor strace -yy annotated with what it is
+	traceAnnotation = regexp.MustCompile(`^(?:AT_FDCWD|-?\d+)<(.*)>$`)
+	// traceSocket matches the annotation of a connected socket
+	traceSocket = regexp.MustCompile(`^(TCP|UDP|TCPv6|UDPv6):\[(.+)->(.+)\]$`)
+	// traceInet and traceInet6 match the address of an IPv4 or IPv6 sockaddr
+	traceInet  = regexp.MustCompile(`sin_port=htons\((\d+)\), sin_addr=inet_addr\("([^"]+)"\)`)
+	traceInet6 = regexp.MustCompile(`sin6_port=htons\((\d+)\).*inet_pton\(AF_INET6, "([^"]+)"`)
+	traceUnix  = regexp.MustCompile(`sun_path=(@?"[^"]*")`)
+)
+
+// Parse parses one line, reporting false for lines that aren't an event of a
+// known kind, such as signals, calls of other kinds and the first half of a
+// split call
+func (p *TraceParser) Parse(line string) (TraceEvent, bool) {
+	line = strings.TrimRight(line, "\r\n")
+	match := tracePrefix.FindStringSubmatch(line)
+	if match == nil {
+		return TraceEvent{}, false
+	}
+	event := TraceEvent{Time: match[3]}
+	if pid := match[1] + match[2]; pid != "" {
+		event.PID, _ = strconv.Atoi(pid)
+	}
+	rest := line[len(match[0]):]
+
+	if exited := traceExited.FindStringSubmatch(rest); exited != nil {
+		event.Kind, event.Call = TraceExit, "exit"
+		event.Result = exited[1] + exited[2]
+		if exited[3] != "" {
+			event.Result, event.Error = exited[3], "killed"
+		}
+		delete(p.pending, event.PID)
+		return event, true
+	}
+	if resumed := traceResumed.FindStringIndex(rest); resumed != nil {
+		first, ok := p.pending[event.PID]
+		if !ok {
+			return TraceEvent{}, false
+		}
+		delete(p.pending, event.PID)
+		rest = first + rest[resumed[1]:]
+	} else if i := strings.Index(rest, " <unfinished ...>"); i >= 0 {
+		p.pending[event.PID] = rest[:i]
+		return TraceEvent{}, false
+	}
+
+	open := strings.IndexByte(rest, '(')
+	end := strings.LastIndex(rest, ") = ")
+	if open <= 0 || end < open {
+		return TraceEvent{}, false
+	}
+	event.Call = rest[:open]
+	if event.Kind = p.calls[event.Call]; event.Kind == "" {
+		return TraceEvent{}, false
+	}
+	args := rest[open+1 : end]
+	result := strings.TrimSpace(rest[end+4:])
+	event.Result = result
+	p.fillResult(&event, result)
+	p.fillTarget(&event, splitTraceArgs(args), result)
+	return event, true
+}
+
+// fillResult sets the outcome of the call
+func (p *TraceParser) fillResult(event *TraceEvent, result string) {
+	if errno := traceErrno.FindStringSubmatch(result); errno != nil {
+		// A non-blocking connect reports its start as EINPROGRESS, which isn't a failure
+		if errno[1] != "EINPROGRESS" {
+			event.Error = errno[1]
+		}
+	} else if p.library && (result == "-1" || (event.Kind == TraceOpen && strings.HasPrefix(event.Call, "f") && (result == "0" || result == "nil"))) {
+		event.Error = "failed"
+	}
+	if event.Kind == TraceRead || event.Kind == TraceWrite {
+		if n, err := strconv.ParseInt(strings.Fields(result + " ")[0], 10, 64); err == nil && n > 0 {
+			event.Bytes = n
+		}
+	}
+}
+
+// fillTarget sets what the call acted on from its arguments and result
+func (p *TraceParser) fillTarget(event *TraceEvent, args []string, result string) {
+	switch event.Kind {
+	case TraceOpen, TraceExec, TraceDelete:
+		// An opened descriptor annotated with its path names the file best
+		if resolved := traceAnnotation.FindStringSubmatch(strings.Fields(result + " ")[0]); resolved != nil && strings.HasPrefix(resolved[1], "/") {
+			event.Target = resolved[1]
+			return
+		}
+		event.Target = tracePath(args)
+		if event.Target == "" && event.Call == "system" && len(args) > 0 {
+			event.Target = unquoteTrace(args[0])
+		}
+	case TraceRename:
+		var paths []string
+		for _, arg := range args {
+			if strings.HasPrefix(arg, `"`) {
+				paths = append(paths, unquoteTrace(arg))
+			}
+		}
+		event.Target = strings.Join(paths, " → ")
+	case TraceConnect, TraceBind:
+		event.Target = traceAddress(strings.Join(args, ", "))
+		if event.Target == "" && len(args) > 0 {
+			event.Target = traceDescriptor(args[0])
+		}
+	case TraceAccept:
+		event.Target = traceDescriptor(strings.Fields(result + " ")[0])
+		if event.Target == "" || strings.HasPrefix(event.Target, "fd ") {
+			if address := traceAddress(strings.Join(args, ", ")); address != "" {
+				event.Target = address
+			}
+		}
+	default:
+		if len(args) > 0 {
+			event.Target = traceDescriptor(args[0])
+			if p.library && strings.HasPrefix(event.Call, "f") && len(args) > 1 {
+				// fread and fwrite take the stream last
+				event.Target = "stream " + args[len(args)-1]
+			}
+		}
+	}
+}
+
+// tracePath returns the path a call names, made absolute from the directory
+// strace -yy annotates the directory descriptor of an *at call with
+func tracePath(args []string) string {
+	for i, arg := range args {
+		if !strings.HasPrefix(arg, `"`) {
+			continue
+		}
+		name := unquoteTrace(arg)
+		if i > 0 && !strings.HasPrefix(name, "/") {
+			if dir := traceAnnotation.FindStringSubmatch(args[i-1]); dir != nil && strings.HasPrefix(dir[1], "/") {
+				name = path.Join(dir[1], name)
+			}
+		}
+		return name
+	}
+	return ""
+}
+
+// traceDescriptor describes a descriptor argument: its path or socket when
+// strace annotated it, otherwise its number
+func traceDescriptor(arg string) string {
+	annotation := traceAnnotation.FindStringSubmatch(arg)
+	if annotation == nil {
+		if _, err := strconv.Atoi(arg); err == nil {
+			return "fd " + arg
+		}
+		return arg
+	}
+	if socket := traceSocket.FindStringSubmatch(annotation[1]); socket != nil {
+		return strings.ToLower(strings.TrimSuffix(socket[1], "v6")) + " " + socket[2] + " → " + socket[3]
+	}
+	return annotation[1]
+}
+
+// traceAddress returns the address in a sockaddr argument, or ""
+func traceAddress(args string) string {
+	if inet := traceInet.FindStringSubmatch(args); inet != nil {
+		return inet[2] + ":" + inet[1]
+	}
+	if inet6 := traceInet6.FindStringSubmatch(args); inet6 != nil {
+		return "[" + inet6[2] + "]:" + inet6[1]
+	}
+	if unix := traceUnix.FindStringSubmatch(args); unix != nil {
+		name := unix[1]
+		abstract := strings.HasPrefix(name, "@")
+		name = unquoteTrace(strings.TrimPrefix(name, "@"))
+		if abstract {
+			name = "@" + name
+		}
+		return "unix " + name
+	}
+	return ""
+}
+
+// splitTraceArgs splits the arguments of a call at its top-level commas,
+// keeping quoted strings, structures and arrays whole
+func splitTraceArgs(args string) []string {
+	var parts []string
+	depth, start := 0, 0
+	quoted, escaped := false, false
+	for i := 0; i < len(args); i++ {
+		c := args[i]
+		switch {
+		case escaped:
+			escaped = false
+		case quoted:
+			if c == '\\' {
+				escaped = true
+			} else if c == '"' {
+				quoted = false
+			}
+		case c == '"':
+			quoted = true
+		case c == '{' || c == '[' || c == '(' || c == '<':
+			depth++
+		case c == '}' || c == ']' || c == ')' || c == '>':
+			depth--
+		case c == ',' && depth == 0:
+			parts = append(parts, strings.TrimSpace(args[start:i]))
+			start = i + 1
+		}
+	}
+	if rest := strings.TrimSpace(args[start:]); rest != "" {
+		parts = append(parts, rest)
+	}
+	return parts
+}
+
+// unquoteTrace returns the text of a quoted string argument, which strace
+// ends with ... when it was cut short
+func unquoteTrace(arg string) string {
+	arg = strings.TrimSuffix(arg, "...")
+	if text, err := strconv.Unquote(arg); err == nil {
+		return text
+	}
+	return strings.Trim(arg, `"`)
+}
+
+// parseTraceEvents turns an --events list of kinds and groups into the set of
+// kinds to report
+func parseTraceEvents(list string) (map[string]bool, []string, bool) {
+	kinds := make(map[string]bool)
+	var unknown []string
+	for _, name := range strings.Split(strings.ToLower(list), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if group, ok := traceGroups[name]; ok {
+			for _, kind := range group {
+				kinds[kind] = true
+			}
+			continue
+		}
+		if _, ok := traceKindNames[name]; ok {
+			kinds[name] = true
+			continue
+		}
+		unknown = append(unknown, name)
+	}
+	return kinds, unknown, len(kinds) > 0 && len(unknown) == 0
+}
+
+// traceKindNames are the kinds as a set
+var traceKindNames = func() map[string]bool {
+	names := make(map[string]bool, len(traceKinds))
+	for _, kind := range traceKinds {
+		names[kind] = true
+	}
+	return names
+}()
+
+// traceCallNames lists the calls of the given kinds for strace -e trace= or
+// ltrace -e
+func traceCallNames(kinds map[string]bool, library bool) []string {
+	calls := straceCalls
+	if library {
+		calls = ltraceCalls
+	}
+	var names []string
+	for name, kind := range calls {
+		if kinds[kind] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseHandleList returns the files in the output of Sysinternals handle -p,
+// whose lines look like "  44: File  (RW-)   C:\Users\me\notes.txt"
+func ParseHandleList(output string) []string {
+	var files []string
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(output, "\n") {
+		match := handleFileLine.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if match == nil {
+			continue
+		}
+		if file := strings.TrimSpace(match[1]); file != "" && !seen[file] {
+			seen[file] = true
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// handleFileLine matches a file handle in handle's listing
+var handleFileLine = regexp.MustCompile(`^\s*[0-9A-Fa-f]+:\s+File\s+(?:\([^)]*\)\s+)?(.+)$`)
@@ -103,6 +103,7 @@ func (t *TracertCommand) Execute(ctx context.Context, args *commands.Arguments)
 
 	// Start the command
 	if err := cmd.Start(); err != nil {
+		err = missingToolError(err)
 		return &commands.Result{
 			Output:   fmt.Sprintf("Failed to start tracert: %v\n", err),
 			Error:    err,
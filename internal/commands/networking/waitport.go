@@ -0,0 +1,169 @@
+package networking
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"suppercommand/internal/commands"
+	"suppercommand/pkg/errors"
+
+	"github.com/fatih/color"
+)
+
+// maxProbeTimeout bounds a single connection attempt, so a filtered port that
+// never answers is still probed again before the wait runs out
+const maxProbeTimeout = 5 * time.Second
+
+// WaitPortCommand blocks until a TCP port accepts connections, or with
+// --invert until it stops accepting them. The exit code says whether that
+// happened in time, which makes it the step that waits for a service in
+// scripts and CI.
+type WaitPortCommand struct {
+	*commands.BaseCommand
+	flags *commands.FlagSet
+}
+
+// NewWaitPortCommand creates a new wait-port command
+func NewWaitPortCommand() *WaitPortCommand {
+	usage := "wait-port <host:port> [--timeout <duration>] [--interval <duration>] [--invert]"
+	return &WaitPortCommand{
+		BaseCommand: commands.NewBaseCommand(
+			"wait-port",
+			"Wait until a TCP port accepts connections, or stops with --invert",
+			usage,
+			[]string{"windows", "linux", "darwin"},
+			false,
+		),
+		flags: commands.NewFlagSet("wait-port", usage,
+			commands.FlagSpec{Name: "timeout", Short: "t", Kind: commands.StringFlag, Default: "30s", Value: "duration", Help: "Give up after this long, 0 to wait indefinitely"},
+			commands.FlagSpec{Name: "interval", Short: "i", Kind: commands.StringFlag, Default: "1s", Value: "duration", Help: "Time between connection attempts"},
+			commands.FlagSpec{Name: "invert", Help: "Wait for the port to close instead"},
+		),
+	}
+}
+
+// FlagSet returns the options wait-port accepts
+func (w *WaitPortCommand) FlagSet() *commands.FlagSet {
+	return w.flags
+}
+
+// Execute probes the port every interval until it is in the wanted state, the
+// timeout passes or the command is cancelled
+func (w *WaitPortCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	startTime := time.Now()
+
+	flags, result := w.flags.ParseArguments(args, startTime)
+	if result != nil {
+		return result, nil
+	}
+	if len(flags.Args()) != 1 {
+		return commands.UsageResult(w, startTime, "expected one host:port to wait for"), nil
+	}
+	address, err := waitAddress(flags.Arg(0))
+	if err != nil {
+		return commands.UsageResult(w, startTime, "%v", err), nil
+	}
+	timeout, err := time.ParseDuration(flags.String("timeout"))
+	if err != nil || timeout < 0 {
+		return commands.UsageResult(w, startTime, "invalid --timeout '%s': expected a duration such as 30s or 2m", flags.String("timeout")), nil
+	}
+	interval, err := time.ParseDuration(flags.String("interval"))
+	if err != nil || interval <= 0 {
+		return commands.UsageResult(w, startTime, "invalid --interval '%s': expected a duration such as 500ms or 2s", flags.String("interval")), nil
+	}
+	wantOpen := !flags.Bool("invert")
+	state := "open"
+	if !wantOpen {
+		state = "closed"
+	}
+
+	waitCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	progress := commands.ProgressWriter(ctx)
+	attempts := 0
+	for {
+		attempts++
+		// A probe cut short by the timeout says nothing about a closed port
+		open := w.probe(waitCtx, address, interval)
+		if open == wantOpen && (open || waitCtx.Err() == nil) {
+			break
+		}
+		if attempts == 1 {
+			fmt.Fprint(progress, color.New(color.FgYellow).Sprintf("⏳ Waiting for %s to be %s...\n", address, state))
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-waitCtx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+		if waitCtx.Err() == nil {
+			continue
+		}
+
+		elapsed := time.Since(startTime).Round(time.Millisecond)
+		if ctx.Err() != nil {
+			err := errors.Wrap(ctx.Err(), "wait-port: interrupted waiting for %s to be %s", address, state)
+			return commands.ErrorResult(color.New(color.FgYellow).Sprintf("⚠️  Interrupted after %v waiting for %s\n", elapsed, address), err, startTime), nil
+		}
+		err := errors.NewNetworkError("wait-port: %s still not %s after %v (%d attempts)", address, state, timeout, attempts)
+		return commands.ErrorResult(color.New(color.FgRed).Sprintf("❌ %s was not %s within %v\n", address, state, timeout), err, startTime), nil
+	}
+
+	var output string
+	if commands.OutputOptionsFrom(ctx).Decorated() {
+		elapsed := time.Since(startTime).Round(time.Millisecond)
+		output = color.New(color.FgGreen).Sprintf("✅ %s is %s (waited %v, %d attempts)\n", address, state, elapsed, attempts)
+	}
+	return &commands.Result{
+		Output:   output,
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}, nil
+}
+
+// probe reports whether address accepts a TCP connection. A port that doesn't
+// answer before the probe times out counts as closed.
+func (w *WaitPortCommand) probe(ctx context.Context, address string, interval time.Duration) bool {
+	probeTimeout := interval
+	if probeTimeout < time.Second {
+		probeTimeout = time.Second
+	}
+	if probeTimeout > maxProbeTimeout {
+		probeTimeout = maxProbeTimeout
+	}
+	probeCtx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	conn, err := (&net.Dialer{}).DialContext(probeCtx, "tcp", address)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// waitAddress checks a host:port argument, defaulting the host to localhost
+// for :port
+func waitAddress(arg string) (string, error) {
+	host, port, err := net.SplitHostPort(arg)
+	if err != nil {
+		return "", fmt.Errorf("invalid address '%s': expected host:port, e.g. localhost:5432", arg)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return "", fmt.Errorf("invalid port '%s': expected a number from 1 to 65535", port)
+	}
+	if host == "" {
+		host = "localhost"
+	}
+	return net.JoinHostPort(host, port), nil
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"suppercommand/internal/commands"
+	"suppercommand/pkg/errors"
 
 	"github.com/fatih/color"
 )
@@ -38,11 +39,7 @@ func (w *WgetCommand) Execute(ctx context.Context, args *commands.Arguments) (*c
 	startTime := time.Now()
 
 	if len(args.Raw) == 0 {
-		return &commands.Result{
-			Output:   "Usage: wget [-v] <url> [filename]\n",
-			ExitCode: 1,
-			Duration: time.Since(startTime),
-		}, nil
+		return commands.ErrorResult("Usage: "+w.Usage()+"\n", commands.UsageError(w.Name(), "no URL specified"), startTime), nil
 	}
 
 	// Parse arguments
@@ -62,11 +59,7 @@ func (w *WgetCommand) Execute(ctx context.Context, args *commands.Arguments) (*c
 	}
 
 	if url == "" {
-		return &commands.Result{
-			Output:   "Error: No URL specified\n",
-			ExitCode: 1,
-			Duration: time.Since(startTime),
-		}, nil
+		return commands.ErrorResult("Usage: "+w.Usage()+"\n", commands.UsageError(w.Name(), "no URL specified"), startTime), nil
 	}
 
 	// If no filename specified, extract from URL
@@ -77,14 +70,21 @@ func (w *WgetCommand) Execute(ctx context.Context, args *commands.Arguments) (*c
 		}
 	}
 
-	var output strings.Builder
+	// The download's progress goes to stderr, as wget's does, leaving stdout
+	// for the summary of what was saved
+	var output, progress strings.Builder
 
 	if verbose {
-		output.WriteString(color.New(color.FgCyan, color.Bold).Sprintf("🌐 WGET - File Download\n"))
-		output.WriteString("═══════════════════════════════════════════════════════════════\n")
-		output.WriteString(fmt.Sprintf("📡 URL:      %s\n", color.New(color.FgBlue).Sprint(url)))
-		output.WriteString(fmt.Sprintf("📁 File:     %s\n", color.New(color.FgGreen).Sprint(filename)))
-		output.WriteString("───────────────────────────────────────────────────────────────\n")
+		progress.WriteString(color.New(color.FgCyan, color.Bold).Sprintf("🌐 WGET - File Download\n"))
+		progress.WriteString("═══════════════════════════════════════════════════════════════\n")
+		progress.WriteString(fmt.Sprintf("📡 URL:      %s\n", color.New(color.FgBlue).Sprint(url)))
+		progress.WriteString(fmt.Sprintf("📁 File:     %s\n", color.New(color.FgGreen).Sprint(filename)))
+		progress.WriteString("───────────────────────────────────────────────────────────────\n")
+	}
+	failed := func(err error) *commands.Result {
+		result := commands.ErrorResult("", err, startTime)
+		result.Stderr = progress.String()
+		return result
 	}
 
 	// Create HTTP client with timeout
@@ -94,46 +94,34 @@ func (w *WgetCommand) Execute(ctx context.Context, args *commands.Arguments) (*c
 
 	// Make the request
 	if verbose {
-		output.WriteString("🔄 Connecting to server...\n")
+		progress.WriteString("🔄 Connecting to server...\n")
 	}
 
 	resp, err := client.Get(url)
 	if err != nil {
-		return &commands.Result{
-			Output:   fmt.Sprintf("Error: Failed to connect to %s: %v\n", url, err),
-			ExitCode: 1,
-			Duration: time.Since(startTime),
-		}, nil
+		return failed(errors.NewNetworkError("%s: failed to connect to %s: %v", w.Name(), url, err)), nil
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return &commands.Result{
-			Output:   fmt.Sprintf("Error: HTTP %d - %s\n", resp.StatusCode, resp.Status),
-			ExitCode: 1,
-			Duration: time.Since(startTime),
-		}, nil
+		return failed(errors.NewNetworkError("%s: %s: HTTP %s", w.Name(), url, resp.Status)), nil
 	}
 
 	// Get content length for progress
 	contentLength := resp.ContentLength
 	if verbose {
 		if contentLength > 0 {
-			output.WriteString(fmt.Sprintf("📊 Size:     %s\n", formatBytes(contentLength)))
+			progress.WriteString(fmt.Sprintf("📊 Size:     %s\n", commands.HumanizeBytes(contentLength)))
 		}
-		output.WriteString(fmt.Sprintf("✅ Status:   %s\n", resp.Status))
-		output.WriteString("───────────────────────────────────────────────────────────────\n")
-		output.WriteString("⬇️  Downloading...\n")
+		progress.WriteString(fmt.Sprintf("✅ Status:   %s\n", resp.Status))
+		progress.WriteString("───────────────────────────────────────────────────────────────\n")
+		progress.WriteString("⬇️  Downloading...\n")
 	}
 
 	// Create the output file
 	file, err := os.Create(filename)
 	if err != nil {
-		return &commands.Result{
-			Output:   fmt.Sprintf("Error: Cannot create file %s: %v\n", filename, err),
-			ExitCode: 1,
-			Duration: time.Since(startTime),
-		}, nil
+		return failed(commands.FileError(w.Name(), filename, err)), nil
 	}
 	defer file.Close()
 
@@ -152,32 +140,24 @@ func (w *WgetCommand) Execute(ctx context.Context, args *commands.Arguments) (*c
 
 				// Show progress every 1MB or at end
 				if written%1048576 == 0 || err == io.EOF {
-					progress := float64(written) / float64(contentLength) * 100
-					output.WriteString(fmt.Sprintf("\r📈 Progress: %.1f%% (%s/%s)",
-						progress, formatBytes(written), formatBytes(contentLength)))
+					percent := float64(written) / float64(contentLength) * 100
+					progress.WriteString(fmt.Sprintf("\r📈 Progress: %.1f%% (%s/%s)",
+						percent, commands.HumanizeBytes(written), commands.HumanizeBytes(contentLength)))
 				}
 			}
 			if err == io.EOF {
 				break
 			}
 			if err != nil {
-				return &commands.Result{
-					Output:   fmt.Sprintf("Error: Download failed: %v\n", err),
-					ExitCode: 1,
-					Duration: time.Since(startTime),
-				}, nil
+				return failed(errors.NewNetworkError("%s: download failed: %v", w.Name(), err)), nil
 			}
 		}
-		output.WriteString("\n")
+		progress.WriteString("\n")
 	} else {
 		// Simple copy for unknown size or non-verbose
 		written, err = io.Copy(file, resp.Body)
 		if err != nil {
-			return &commands.Result{
-				Output:   fmt.Sprintf("Error: Download failed: %v\n", err),
-				ExitCode: 1,
-				Duration: time.Since(startTime),
-			}, nil
+			return failed(errors.NewNetworkError("%s: download failed: %v", w.Name(), err)), nil
 		}
 	}
 
@@ -187,34 +167,21 @@ func (w *WgetCommand) Execute(ctx context.Context, args *commands.Arguments) (*c
 		output.WriteString("───────────────────────────────────────────────────────────────\n")
 		output.WriteString(color.New(color.FgGreen, color.Bold).Sprint("✅ DOWNLOAD COMPLETE\n"))
 		output.WriteString(fmt.Sprintf("📁 File:     %s\n", filename))
-		output.WriteString(fmt.Sprintf("📊 Size:     %s\n", formatBytes(written)))
+		output.WriteString(fmt.Sprintf("📊 Size:     %s\n", commands.HumanizeBytes(written)))
 		output.WriteString(fmt.Sprintf("⏱️  Time:     %v\n", downloadDuration.Round(time.Millisecond)))
 		if downloadDuration.Seconds() > 0 {
 			speed := float64(written) / downloadDuration.Seconds()
-			output.WriteString(fmt.Sprintf("🚀 Speed:    %s/s\n", formatBytes(int64(speed))))
+			output.WriteString(fmt.Sprintf("🚀 Speed:    %s/s\n", commands.HumanizeBytes(int64(speed))))
 		}
 		output.WriteString("═══════════════════════════════════════════════════════════════\n")
 	} else {
-		output.WriteString(fmt.Sprintf("✅ Downloaded: %s (%s)\n", filename, formatBytes(written)))
+		output.WriteString(fmt.Sprintf("✅ Downloaded: %s (%s)\n", filename, commands.HumanizeBytes(written)))
 	}
 
 	return &commands.Result{
 		Output:   output.String(),
+		Stderr:   progress.String(),
 		ExitCode: 0,
 		Duration: time.Since(startTime),
 	}, nil
 }
-
-// formatBytes formats byte count as human readable string
-func formatBytes(bytes int64) string {
-	const unit = 1024
-	if bytes < unit {
-		return fmt.Sprintf("%d B", bytes)
-	}
-	div, exp := int64(unit), 0
-	for n := bytes / unit; n >= unit; n /= unit {
-		div *= unit
-		exp++
-	}
-	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
-}
@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// ErrNotificationsUnavailable is returned when the desktop cannot show notifications,
+// for example over SSH or when no notifier tool is installed
+var ErrNotificationsUnavailable = errors.New("desktop notifications are not available")
+
+// SendNotification raises a desktop notification using the platform's notifier
+func SendNotification(ctx context.Context, title, message string) error {
+	switch runtime.GOOS {
+	case "linux":
+		if os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == "" {
+			return fmt.Errorf("%w: no graphical session", ErrNotificationsUnavailable)
+		}
+		if _, err := exec.LookPath("notify-send"); err != nil {
+			return fmt.Errorf("%w: notify-send not installed", ErrNotificationsUnavailable)
+		}
+		return exec.CommandContext(ctx, "notify-send", "--app-name=SuperShell", title, message).Run()
+
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(message), appleScriptString(title))
+		return exec.CommandContext(ctx, "osascript", "-e", script).Run()
+
+	case "windows":
+		script := fmt.Sprintf(`Add-Type -AssemblyName System.Windows.Forms
+$n = New-Object System.Windows.Forms.NotifyIcon
+$n.Icon = [System.Drawing.SystemIcons]::Information
+$n.BalloonTipTitle = %s
+$n.BalloonTipText = %s
+$n.Visible = $true
+$n.ShowBalloonTip(10000)
+Start-Sleep -Seconds 10
+$n.Dispose()`, powerShellString(title), powerShellString(message))
+
+		// The balloon lives as long as the script, so don't block the prompt on it
+		cmd := exec.Command("powershell", "-NoProfile", "-WindowStyle", "Hidden", "-Command", script)
+		if err := cmd.Start(); err != nil {
+			return fmt.Errorf("%w: %v", ErrNotificationsUnavailable, err)
+		}
+		go cmd.Wait()
+		return nil
+	}
+
+	return fmt.Errorf("%w on %s", ErrNotificationsUnavailable, runtime.GOOS)
+}
+
+// CompletionNotice builds the notification text for a finished command
+func CompletionNotice(command string, exitCode int, duration time.Duration) (string, string) {
+	if exitCode == 0 {
+		return "✅ " + command + " finished", fmt.Sprintf("Completed successfully in %v", duration.Round(time.Second))
+	}
+	return "❌ " + command + " failed", fmt.Sprintf("Exited with code %d after %v", exitCode, duration.Round(time.Second))
+}
+
+// appleScriptString quotes s as an AppleScript string literal
+func appleScriptString(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	return `"` + strings.Replace(s, `"`, `\"`, -1) + `"`
+}
+
+// powerShellString quotes s as a PowerShell single-quoted string literal
+func powerShellString(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
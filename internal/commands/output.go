@@ -0,0 +1,214 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+)
+
+// OutputFormat selects how a command renders its results
+type OutputFormat string
+
+const (
+	// FormatText is the default human-readable output
+	FormatText OutputFormat = "text"
+	// FormatJSON is an indented JSON document
+	FormatJSON OutputFormat = "json"
+	// FormatCSV is a header row followed by one row per record
+	FormatCSV OutputFormat = "csv"
+)
+
+// OutputOptions are the global --quiet and --output-format options of a command run
+type OutputOptions struct {
+	Format OutputFormat
+	// Quiet drops headers, progress and summaries, leaving only the results
+	Quiet bool
+}
+
+// Structured reports whether the results should be machine-readable
+func (o OutputOptions) Structured() bool {
+	return o.Format == FormatJSON || o.Format == FormatCSV
+}
+
+// Decorated reports whether banners, progress and summaries should be shown
+func (o OutputOptions) Decorated() bool {
+	return !o.Quiet && !o.Structured()
+}
+
+// OutputFormatter is implemented by commands that can render their results as
+// structured data. Registry.Execute resolves --quiet and --output-format for these
+// commands and passes them on through the context, see OutputOptionsFrom.
+type OutputFormatter interface {
+	// OutputFormats lists the supported formats besides text
+	OutputFormats() []OutputFormat
+}
+
+// GlobalOutputFlags are the options Registry.Execute handles for an OutputFormatter
+var GlobalOutputFlags = []string{"--quiet", "--output-format"}
+
+type outputOptionsKey struct{}
+
+// WithOutputOptions returns a context carrying the output options
+func WithOutputOptions(ctx context.Context, options OutputOptions) context.Context {
+	return context.WithValue(ctx, outputOptionsKey{}, options)
+}
+
+// OutputOptionsFrom returns the output options of the running command, text by default
+func OutputOptionsFrom(ctx context.Context) OutputOptions {
+	if options, ok := ctx.Value(outputOptionsKey{}).(OutputOptions); ok {
+		return options
+	}
+	return OutputOptions{Format: FormatText}
+}
+
+type outputWriterKey struct{}
+
+// WithOutputWriter returns a context whose command prints to w instead of the
+// terminal, as background jobs do so they don't write over the prompt
+func WithOutputWriter(ctx context.Context, w io.Writer) context.Context {
+	return context.WithValue(ctx, outputWriterKey{}, w)
+}
+
+// decoratedStdout and decoratedStderr print to the terminal in ASCII mode,
+// see SetASCIIDecorations
+var (
+	decoratedStdout = DecoratingWriter(os.Stdout)
+	decoratedStderr = DecoratingWriter(os.Stderr)
+)
+
+// OutputWriter returns where the running command prints as it goes: the
+// terminal, or the writer set with WithOutputWriter
+func OutputWriter(ctx context.Context) io.Writer {
+	if w, ok := ctx.Value(outputWriterKey{}).(io.Writer); ok {
+		return w
+	}
+	if ASCIIDecorations() {
+		return decoratedStdout
+	}
+	return os.Stdout
+}
+
+type errorWriterKey struct{}
+
+// WithErrorWriter returns a context whose command writes diagnostics to w
+// instead of stderr
+func WithErrorWriter(ctx context.Context, w io.Writer) context.Context {
+	return context.WithValue(ctx, errorWriterKey{}, w)
+}
+
+// ErrorWriter returns where the running command writes diagnostics as it
+// goes: stderr, or the writer set with WithErrorWriter. A context with only
+// an output writer sends both to it, so background jobs capture everything.
+func ErrorWriter(ctx context.Context) io.Writer {
+	if w, ok := ctx.Value(errorWriterKey{}).(io.Writer); ok {
+		return w
+	}
+	if w, ok := ctx.Value(outputWriterKey{}).(io.Writer); ok {
+		return w
+	}
+	if ASCIIDecorations() {
+		return decoratedStderr
+	}
+	return os.Stderr
+}
+
+// ProgressWriter returns where live progress for the running command goes:
+// the error writer normally, keeping stdout for results, and nowhere when the
+// output is quiet or structured
+func ProgressWriter(ctx context.Context) io.Writer {
+	if OutputOptionsFrom(ctx).Decorated() {
+		return ErrorWriter(ctx)
+	}
+	return ioutil.Discard
+}
+
+// ExtractOutputOptions removes --quiet, --output-format <format> and
+// --output-format=<format> from raw, starting from the options in base
+func ExtractOutputOptions(raw []string, base OutputOptions) (OutputOptions, []string, error) {
+	options := base
+	if options.Format == "" {
+		options.Format = FormatText
+	}
+
+	rest := make([]string, 0, len(raw))
+	for i := 0; i < len(raw); i++ {
+		arg := raw[i]
+		switch {
+		case arg == "--":
+			rest = append(rest, raw[i:]...)
+			return options, rest, nil
+		case arg == "--quiet":
+			options.Quiet = true
+		case arg == "--output-format" || strings.HasPrefix(arg, "--output-format="):
+			value := strings.TrimPrefix(arg, "--output-format=")
+			if arg == "--output-format" {
+				if i+1 >= len(raw) {
+					return options, nil, fmt.Errorf("--output-format needs a value (text, json or csv)")
+				}
+				i++
+				value = raw[i]
+			}
+			format := OutputFormat(strings.ToLower(value))
+			if format != FormatText && format != FormatJSON && format != FormatCSV {
+				return options, nil, fmt.Errorf("unknown output format '%s' (use text, json or csv)", value)
+			}
+			options.Format = format
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	return options, rest, nil
+}
+
+// SupportsOutputFormat reports whether cmd can render format
+func SupportsOutputFormat(cmd Command, format OutputFormat) bool {
+	if format == FormatText || format == "" {
+		return true
+	}
+	if formatter, ok := cmd.(OutputFormatter); ok {
+		for _, supported := range formatter.OutputFormats() {
+			if supported == format {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// JSONResult returns a successful result holding v as indented JSON
+func JSONResult(v interface{}, startTime time.Time) (*Result, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return &Result{
+		Output:   string(data) + "\n",
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}, nil
+}
+
+// CSVResult returns a successful result holding a header row followed by rows
+func CSVResult(header []string, rows [][]string, startTime time.Time) *Result {
+	return &Result{
+		Output:   FormatCSVRows(header, rows),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}
+}
+
+// FormatCSVRows renders a header row and rows as CSV
+func FormatCSVRows(header []string, rows [][]string) string {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Write(header)
+	writer.WriteAll(rows)
+	return buf.String()
+}
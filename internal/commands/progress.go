@@ -0,0 +1,161 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	// progressSmoothing weights the newest speed sample in the moving average
+	progressSmoothing = 0.3
+	// progressSampleInterval is the shortest span a speed sample is taken over,
+	// so bursts of small writes don't make the estimate jump around
+	progressSampleInterval = 200 * time.Millisecond
+	// progressRenderInterval limits how often the live line is redrawn
+	progressRenderInterval = 100 * time.Millisecond
+	progressBarWidth       = 50
+)
+
+// ProgressReporter tracks a transfer and renders progress lines with an exponentially
+// smoothed speed and an ETA. It is an io.Writer so it can count bytes as they are
+// copied. When live is set the current line is redrawn there as progress is made.
+type ProgressReporter struct {
+	Total      int64
+	TotalFiles int
+
+	done       int64
+	files      int
+	speed      float64
+	start      time.Time
+	sampleAt   time.Time
+	sampleDone int64
+	renderedAt time.Time
+	live       io.Writer
+}
+
+// NewProgressReporter starts tracking a transfer of total bytes
+func NewProgressReporter(total int64, totalFiles int, live io.Writer) *ProgressReporter {
+	now := time.Now()
+	return &ProgressReporter{
+		Total:      total,
+		TotalFiles: totalFiles,
+		start:      now,
+		sampleAt:   now,
+		live:       live,
+	}
+}
+
+// Write counts len(b) transferred bytes
+func (p *ProgressReporter) Write(b []byte) (int, error) {
+	p.Update(p.done+int64(len(b)), p.files)
+	return len(b), nil
+}
+
+// CompleteFile counts one more finished file
+func (p *ProgressReporter) CompleteFile() {
+	p.files++
+}
+
+// Update records the bytes and files transferred so far
+func (p *ProgressReporter) Update(done int64, files int) {
+	p.UpdateAt(done, files, time.Now())
+}
+
+// UpdateAt records progress as of the given time
+func (p *ProgressReporter) UpdateAt(done int64, files int, now time.Time) {
+	p.done = done
+	p.files = files
+
+	if elapsed := now.Sub(p.sampleAt); elapsed >= progressSampleInterval {
+		rate := float64(done-p.sampleDone) / elapsed.Seconds()
+		if p.speed == 0 {
+			p.speed = rate
+		} else {
+			p.speed = progressSmoothing*rate + (1-progressSmoothing)*p.speed
+		}
+		p.sampleAt = now
+		p.sampleDone = done
+	}
+
+	if p.live != nil && now.Sub(p.renderedAt) >= progressRenderInterval {
+		p.renderedAt = now
+		fmt.Fprintf(p.live, "\r\033[K%s", p.Line())
+	}
+}
+
+// Speed returns the smoothed transfer speed in bytes per second
+func (p *ProgressReporter) Speed() float64 {
+	return p.speed
+}
+
+// ETA estimates the time remaining at the current speed; it is negative while unknown
+func (p *ProgressReporter) ETA() time.Duration {
+	if p.done >= p.Total {
+		return 0
+	}
+	if p.speed <= 0 {
+		return -1
+	}
+	return time.Duration(float64(p.Total-p.done) / p.speed * float64(time.Second))
+}
+
+// Elapsed returns how long the transfer has been running
+func (p *ProgressReporter) Elapsed() time.Duration {
+	return time.Since(p.start)
+}
+
+// Average returns the overall throughput since the transfer started
+func (p *ProgressReporter) Average(now time.Time) float64 {
+	elapsed := now.Sub(p.start).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(p.done) / elapsed
+}
+
+// Line renders the current progress as a single line
+func (p *ProgressReporter) Line() string {
+	percent := 100
+	if p.Total > 0 {
+		percent = int(p.done * 100 / p.Total)
+	}
+	if percent > 100 {
+		percent = 100
+	}
+
+	counts := fmt.Sprintf("%s/%s", HumanizeBytes(p.done), HumanizeBytes(p.Total))
+	if p.TotalFiles > 0 {
+		counts = fmt.Sprintf("%d/%d files, %s", p.files, p.TotalFiles, counts)
+	}
+
+	return fmt.Sprintf("📈 %s %d%% (%s) - %s/s - ETA: %s",
+		renderProgressBar(percent, progressBarWidth), percent, counts, HumanizeBytes(int64(p.speed)), formatETA(p.ETA()))
+}
+
+// Finish clears the live line and returns the final progress line for the command's output
+func (p *ProgressReporter) Finish() string {
+	if p.live != nil {
+		fmt.Fprint(p.live, "\r\033[K")
+	}
+	if p.speed == 0 {
+		// Too quick for a sample, so report the overall speed instead
+		p.speed = p.Average(time.Now())
+	}
+	return p.Line() + "\n"
+}
+
+// renderProgressBar draws a bar percent full
+func renderProgressBar(percent, width int) string {
+	filled := percent * width / 100
+	return fmt.Sprintf("[%s]", strings.Repeat("█", filled)+strings.Repeat("░", width-filled))
+}
+
+// formatETA formats a remaining time, showing dashes while it is unknown
+func formatETA(eta time.Duration) string {
+	if eta < 0 {
+		return "--"
+	}
+	return eta.Round(time.Second).String()
+}
@@ -0,0 +1,491 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+)
+
+// QR codes are built in byte mode at error correction level M, which survives
+// a scratched or glared screen while keeping the code small enough for a
+// terminal. Versions 1 to 10 hold up to 213 bytes.
+
+// qrQuietZone is the light border around a rendered code, in modules. The
+// standard asks for four; two scans reliably off a screen and saves space.
+const qrQuietZone = 2
+
+// qrVersion describes the error-correction blocks of one version at level M
+type qrVersion struct {
+	// eccPerBlock is the number of error-correction codewords in every block
+	eccPerBlock int
+	// blocks lists the number of data codewords of each block
+	blocks []int
+	// alignment lists the centre coordinates of the alignment patterns
+	alignment []int
+}
+
+// qrVersions are indexed by version number
+var qrVersions = []qrVersion{
+	{},
+	{10, []int{16}, nil},
+	{16, []int{28}, []int{6, 18}},
+	{26, []int{44}, []int{6, 22}},
+	{18, []int{32, 32}, []int{6, 26}},
+	{24, []int{43, 43}, []int{6, 30}},
+	{16, []int{27, 27, 27, 27}, []int{6, 34}},
+	{18, []int{31, 31, 31, 31}, []int{6, 22, 38}},
+	{22, []int{38, 38, 39, 39}, []int{6, 24, 42}},
+	{22, []int{36, 36, 36, 37, 37}, []int{6, 26, 46}},
+	{26, []int{43, 43, 43, 43, 44}, []int{6, 28, 50}},
+}
+
+// QRCode is a matrix of dark and light modules
+type QRCode struct {
+	size     int
+	modules  [][]bool
+	function [][]bool
+}
+
+// Size returns the width of the code in modules, without the quiet zone
+func (q *QRCode) Size() int {
+	return q.size
+}
+
+// Dark reports whether the module at column x, row y is dark
+func (q *QRCode) Dark(x, y int) bool {
+	return q.modules[y][x]
+}
+
+// EncodeQR encodes text as the smallest QR code that holds it
+func EncodeQR(text string) (*QRCode, error) {
+	data := []byte(text)
+	for version := 1; version < len(qrVersions); version++ {
+		capacity := 0
+		for _, blockLen := range qrVersions[version].blocks {
+			capacity += blockLen
+		}
+		if 4+qrCountBits(version)+8*len(data) <= capacity*8 {
+			return newQRCode(version, qrCodewords(version, data, capacity)), nil
+		}
+	}
+	return nil, fmt.Errorf("%d bytes is too long for a QR code (at most 213)", len(data))
+}
+
+// RenderQR draws text as a QR code with half-block characters, two rows of
+// modules per line. Dark modules are blank, which suits the usual dark
+// terminal background; the light modules and quiet zone are drawn solid.
+func RenderQR(text string) (string, error) {
+	qr, err := EncodeQR(text)
+	if err != nil {
+		return "", err
+	}
+
+	light := func(x, y int) bool {
+		if x < 0 || y < 0 || x >= qr.size || y >= qr.size {
+			return true
+		}
+		return !qr.Dark(x, y)
+	}
+
+	var output strings.Builder
+	for y := -qrQuietZone; y < qr.size+qrQuietZone; y += 2 {
+		for x := -qrQuietZone; x < qr.size+qrQuietZone; x++ {
+			switch top, bottom := light(x, y), light(x, y+1); {
+			case top && bottom:
+				output.WriteString("█")
+			case top:
+				output.WriteString("▀")
+			case bottom:
+				output.WriteString("▄")
+			default:
+				output.WriteString(" ")
+			}
+		}
+		output.WriteString("\n")
+	}
+	return output.String(), nil
+}
+
+// qrCountBits is the width of the byte count that follows the mode indicator
+func qrCountBits(version int) int {
+	if version < 10 {
+		return 8
+	}
+	return 16
+}
+
+// qrCodewords builds the data codewords, pads them to capacity, and appends the
+// error correction, interleaving the blocks as the standard lays them out
+func qrCodewords(version int, data []byte, capacity int) []byte {
+	var bits qrBits
+	bits.append(0x4, 4) // byte mode
+	bits.append(len(data), qrCountBits(version))
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+	terminator := capacity*8 - len(bits)
+	if terminator > 4 {
+		terminator = 4
+	}
+	bits.append(0, terminator)
+	bits.append(0, (8-len(bits)%8)%8)
+
+	codewords := bits.bytes()
+	for pad := 0xEC; len(codewords) < capacity; pad ^= 0xEC ^ 0x11 {
+		codewords = append(codewords, byte(pad))
+	}
+
+	info := qrVersions[version]
+	divisor := reedSolomonDivisor(info.eccPerBlock)
+	var blocks, eccs [][]byte
+	for _, blockLen := range info.blocks {
+		block := codewords[:blockLen]
+		codewords = codewords[blockLen:]
+		blocks = append(blocks, block)
+		eccs = append(eccs, reedSolomonRemainder(block, divisor))
+	}
+
+	var result []byte
+	longest := info.blocks[len(info.blocks)-1]
+	for i := 0; i < longest; i++ {
+		for _, block := range blocks {
+			if i < len(block) {
+				result = append(result, block[i])
+			}
+		}
+	}
+	for i := 0; i < info.eccPerBlock; i++ {
+		for _, ecc := range eccs {
+			result = append(result, ecc[i])
+		}
+	}
+	return result
+}
+
+// newQRCode lays out the codewords in a code of the given version, choosing the
+// mask that leaves the fewest patterns a scanner could confuse
+func newQRCode(version int, codewords []byte) *QRCode {
+	size := version*4 + 17
+	qr := &QRCode{size: size, modules: make([][]bool, size), function: make([][]bool, size)}
+	for y := range qr.modules {
+		qr.modules[y] = make([]bool, size)
+		qr.function[y] = make([]bool, size)
+	}
+
+	qr.drawFunctionPatterns(version)
+	qr.drawCodewords(codewords)
+
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		qr.applyMask(mask)
+		qr.drawFormatBits(mask)
+		if penalty := qr.penalty(); bestPenalty < 0 || penalty < bestPenalty {
+			best, bestPenalty = mask, penalty
+		}
+		qr.applyMask(mask) // masking twice undoes it
+	}
+	qr.applyMask(best)
+	qr.drawFormatBits(best)
+	return qr
+}
+
+// setFunction sets a module that belongs to a fixed pattern rather than the data
+func (q *QRCode) setFunction(x, y int, dark bool) {
+	q.modules[y][x] = dark
+	q.function[y][x] = true
+}
+
+// drawFunctionPatterns draws the timing, finder and alignment patterns, and
+// reserves the format and version areas
+func (q *QRCode) drawFunctionPatterns(version int) {
+	for i := 0; i < q.size; i++ {
+		q.setFunction(6, i, i%2 == 0)
+		q.setFunction(i, 6, i%2 == 0)
+	}
+
+	for _, corner := range [][2]int{{3, 3}, {q.size - 4, 3}, {3, q.size - 4}} {
+		for dy := -4; dy <= 4; dy++ {
+			for dx := -4; dx <= 4; dx++ {
+				x, y := corner[0]+dx, corner[1]+dy
+				if x >= 0 && y >= 0 && x < q.size && y < q.size {
+					distance := qrMax(qrAbs(dx), qrAbs(dy))
+					q.setFunction(x, y, distance != 2 && distance != 4)
+				}
+			}
+		}
+	}
+
+	// Alignment patterns go on every pair of centres except under the finders
+	alignment := qrVersions[version].alignment
+	last := len(alignment) - 1
+	for i, cy := range alignment {
+		for j, cx := range alignment {
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					q.setFunction(cx+dx, cy+dy, qrMax(qrAbs(dx), qrAbs(dy)) != 1)
+				}
+			}
+		}
+	}
+
+	q.drawFormatBits(0)
+	if version >= 7 {
+		remainder := version
+		for i := 0; i < 12; i++ {
+			remainder = (remainder << 1) ^ ((remainder >> 11) * 0x1F25)
+		}
+		bits := version<<12 | remainder
+		for i := 0; i < 18; i++ {
+			dark := (bits>>uint(i))&1 != 0
+			a, b := q.size-11+i%3, i/3
+			q.setFunction(a, b, dark)
+			q.setFunction(b, a, dark)
+		}
+	}
+}
+
+// drawFormatBits writes both copies of the error correction level and mask
+func (q *QRCode) drawFormatBits(mask int) {
+	data := mask // level M is 00
+	remainder := data
+	for i := 0; i < 10; i++ {
+		remainder = (remainder << 1) ^ ((remainder >> 9) * 0x537)
+	}
+	bits := (data<<10 | remainder) ^ 0x5412
+	bit := func(i int) bool { return (bits>>uint(i))&1 != 0 }
+
+	for i := 0; i < 6; i++ {
+		q.setFunction(8, i, bit(i))
+	}
+	q.setFunction(8, 7, bit(6))
+	q.setFunction(8, 8, bit(7))
+	q.setFunction(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		q.setFunction(14-i, 8, bit(i))
+	}
+
+	for i := 0; i < 8; i++ {
+		q.setFunction(q.size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		q.setFunction(8, q.size-15+i, bit(i))
+	}
+	q.setFunction(8, q.size-8, true) // always dark
+}
+
+// drawCodewords fills the data area in the zigzag order, two columns at a time
+// from the bottom right, skipping the vertical timing pattern
+func (q *QRCode) drawCodewords(codewords []byte) {
+	i := 0
+	for right := q.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vertical := 0; vertical < q.size; vertical++ {
+			y := vertical
+			if upward {
+				y = q.size - 1 - vertical
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if q.function[y][x] || i >= len(codewords)*8 {
+					continue
+				}
+				q.modules[y][x] = (codewords[i>>3]>>uint(7-(i&7)))&1 != 0
+				i++
+			}
+		}
+	}
+}
+
+// applyMask flips the data modules the mask pattern selects
+func (q *QRCode) applyMask(mask int) {
+	for y := 0; y < q.size; y++ {
+		for x := 0; x < q.size; x++ {
+			var flip bool
+			switch mask {
+			case 0:
+				flip = (x+y)%2 == 0
+			case 1:
+				flip = y%2 == 0
+			case 2:
+				flip = x%3 == 0
+			case 3:
+				flip = (x+y)%3 == 0
+			case 4:
+				flip = (x/3+y/2)%2 == 0
+			case 5:
+				flip = x*y%2+x*y%3 == 0
+			case 6:
+				flip = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				flip = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if flip && !q.function[y][x] {
+				q.modules[y][x] = !q.modules[y][x]
+			}
+		}
+	}
+}
+
+// penalty scores the code by the standard's four rules: long runs, 2x2 blocks,
+// finder-like patterns, and an uneven balance of dark and light
+func (q *QRCode) penalty() int {
+	penalty := 0
+	dark := 0
+	finder := []bool{true, false, true, true, true, false, true}
+
+	for a := 0; a < q.size; a++ {
+		for _, line := range [][]bool{q.row(a), q.column(a)} {
+			run := 1
+			for i := 1; i <= len(line); i++ {
+				if i < len(line) && line[i] == line[i-1] {
+					run++
+					continue
+				}
+				if run >= 5 {
+					penalty += run - 2
+				}
+				run = 1
+			}
+
+			for i := 0; i+len(finder) <= len(line); i++ {
+				match := true
+				for k, want := range finder {
+					if line[i+k] != want {
+						match = false
+						break
+					}
+				}
+				if match && (qrLightRun(line, i-4, i) || qrLightRun(line, i+7, i+11)) {
+					penalty += 40
+				}
+			}
+		}
+	}
+
+	for y := 0; y < q.size; y++ {
+		for x := 0; x < q.size; x++ {
+			if q.modules[y][x] {
+				dark++
+			}
+			if x+1 < q.size && y+1 < q.size {
+				c := q.modules[y][x]
+				if c == q.modules[y][x+1] && c == q.modules[y+1][x] && c == q.modules[y+1][x+1] {
+					penalty += 3
+				}
+			}
+		}
+	}
+
+	total := q.size * q.size
+	deviation := qrAbs(dark*20-total*10) / total
+	return penalty + deviation*10
+}
+
+// row returns row y of the modules
+func (q *QRCode) row(y int) []bool {
+	return q.modules[y]
+}
+
+// column returns column x of the modules
+func (q *QRCode) column(x int) []bool {
+	column := make([]bool, q.size)
+	for y := range column {
+		column[y] = q.modules[y][x]
+	}
+	return column
+}
+
+// qrLightRun reports whether line[from:to] is all light, counting modules past
+// the edge as the light quiet zone
+func qrLightRun(line []bool, from, to int) bool {
+	for i := from; i < to; i++ {
+		if i >= 0 && i < len(line) && line[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// qrBits accumulates a bit stream, most significant bit first
+type qrBits []bool
+
+// append adds the low n bits of value
+func (b *qrBits) append(value, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, (value>>uint(i))&1 != 0)
+	}
+}
+
+// bytes packs the bits, whose length is a multiple of eight
+func (b qrBits) bytes() []byte {
+	result := make([]byte, len(b)/8)
+	for i, bit := range b {
+		if bit {
+			result[i/8] |= 0x80 >> uint(i%8)
+		}
+	}
+	return result
+}
+
+// reedSolomonDivisor returns the generator polynomial of the given degree,
+// highest coefficient first with the leading 1 left out
+func reedSolomonDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = gfMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMultiply(root, 0x02)
+	}
+	return result
+}
+
+// reedSolomonRemainder returns the error correction codewords of data
+func reedSolomonRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i, coefficient := range divisor {
+			result[i] ^= gfMultiply(coefficient, factor)
+		}
+	}
+	return result
+}
+
+// gfMultiply multiplies in GF(2^8) modulo x^8 + x^4 + x^3 + x^2 + 1
+func gfMultiply(x, y byte) byte {
+	z := 0
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		if (y>>uint(i))&1 != 0 {
+			z ^= int(x)
+		}
+	}
+	return byte(z)
+}
+
+func qrAbs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func qrMax(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
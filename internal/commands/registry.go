@@ -2,7 +2,10 @@ package commands
 
 import (
 	"context"
+	"os"
+	"strings"
 	"sync"
+	"time"
 
 	"suppercommand/internal/monitoring"
 	"suppercommand/internal/security"
@@ -11,20 +14,43 @@ import (
 
 // Registry manages command registration and execution with dependency injection
 type Registry struct {
-	mu       sync.RWMutex
-	commands map[string]Command
-	security security.Validator
-	logger   monitoring.Logger
+	mu        sync.RWMutex
+	commands  map[string]Command
+	security  security.Validator
+	elevation Elevation
+	logger    monitoring.Logger
+	audit     *security.AuditLog
+	// globNoMatch is the policy ExpandLine applies to a glob matching no files
+	globNoMatch string
 }
 
 // NewRegistry creates a new command registry
 func NewRegistry(logger monitoring.Logger) *Registry {
 	return &Registry{
-		commands: make(map[string]Command),
-		logger:   logger,
+		commands:    make(map[string]Command),
+		elevation:   DefaultElevation(),
+		logger:      logger,
+		globNoMatch: GlobNoMatchPassthrough,
 	}
 }
 
+// SetElevation replaces how commands needing elevation are checked and re-run
+func (r *Registry) SetElevation(elevation Elevation) {
+	r.elevation = elevation
+}
+
+// SetGlobNoMatch sets what ExpandLine does with a glob that matches no files,
+// one of the GlobNoMatch policies
+func (r *Registry) SetGlobNoMatch(policy string) {
+	r.globNoMatch = policy
+}
+
+// SetAuditLog records every command the registry runs in audit, whether typed
+// at the prompt or run by a script, a scheduled task or another command
+func (r *Registry) SetAuditLog(audit *security.AuditLog) {
+	r.audit = audit
+}
+
 // Initialize initializes the registry and registers built-in commands
 func (r *Registry) Initialize(ctx context.Context, validator security.Validator) error {
 	r.security = validator
@@ -120,13 +146,52 @@ func (r *Registry) GetAllCommands() []Command {
 	return commands
 }
 
-// Execute executes a command with validation
+// Execute executes a command with validation, recording it in the audit log
 func (r *Registry) Execute(ctx context.Context, name string, args *Arguments) (*Result, error) {
+	if !r.audit.Enabled() {
+		return r.execute(ctx, name, args)
+	}
+
+	// The audit log records where the command ran, before cd and the like move on
+	startTime := time.Now()
+	dir, _ := os.Getwd()
+	result, err := r.execute(ctx, name, args)
+	exitCode := 1
+	if err == nil && result != nil {
+		exitCode = result.ExitCode
+	}
+	r.audit.Record(CommandLine(name, args.Raw), dir, exitCode, time.Since(startTime))
+	return result, err
+}
+
+// execute validates and runs a command
+func (r *Registry) execute(ctx context.Context, name string, args *Arguments) (*Result, error) {
 	// Get command
 	cmd, err := r.Get(name)
 	if err != nil {
 		return nil, err
 	}
+	raw := args.Raw
+
+	// Commands with structured output share the global --quiet and --output-format
+	// options, which reach them through the context
+	if _, ok := cmd.(OutputFormatter); ok {
+		startTime := time.Now()
+		options, raw, err := ExtractOutputOptions(args.Raw, OutputOptionsFrom(ctx))
+		if err == nil && !SupportsOutputFormat(cmd, options.Format) {
+			err = errors.NewValidationError("--output-format %s is not supported (use %s)", options.Format, supportedFormats(cmd))
+		}
+		if err != nil {
+			return &Result{
+				Output:   "Usage: " + cmd.Usage() + "\n",
+				Error:    UsageError(name, "%v", err),
+				ExitCode: 1,
+				Duration: time.Since(startTime),
+			}, nil
+		}
+		ctx = WithOutputOptions(ctx, options)
+		args = ParseArguments(raw)
+	}
 
 	// Validate arguments
 	if err := cmd.Validate(args); err != nil {
@@ -140,6 +205,19 @@ func (r *Registry) Execute(ctx context.Context, name string, args *Arguments) (*
 		}
 	}
 
+	// A script run with a guard may not reach the commands it screens out
+	if guard := commandGuard(ctx); guard != nil {
+		if err := guard.Check(name, args.Raw); err != nil {
+			return ErrorResult("", err, time.Now()), nil
+		}
+	}
+
+	// Commands needing privileges the shell lacks are offered a re-run in an
+	// elevated shell instead, with the arguments as they were given
+	if NeedsElevation(cmd, args) && !r.elevation.IsElevated() {
+		return r.elevate(ctx, cmd, raw), nil
+	}
+
 	// Execute command
 	result, err := cmd.Execute(ctx, args)
 	if err != nil {
@@ -155,6 +233,45 @@ func (r *Registry) Execute(ctx context.Context, name string, args *Arguments) (*
 	return result, nil
 }
 
+// ExpandLine splits a command line into words and expands braces and globs in
+// its arguments as the prompt does, returning the command name and its final
+// arguments
+func (r *Registry) ExpandLine(line string) ([]string, error) {
+	words := SplitCommandWords(line)
+	if len(words) == 0 {
+		return nil, errors.NewValidationError("empty command")
+	}
+	expanded, err := ExpandArguments(words[1:], r.globNoMatch)
+	if err != nil {
+		return nil, errors.Wrap(err, "%s", words[0].Text)
+	}
+	return append([]string{words[0].Text}, expanded...), nil
+}
+
+// ExecuteLine runs a command line exactly as if it had been typed at the
+// prompt. Commands that run other commands dispatch through here; a line
+// built from words that were already expanded should quote them with
+// QuoteArgument or CommandLine.
+func (r *Registry) ExecuteLine(ctx context.Context, line string) (*Result, error) {
+	startTime := time.Now()
+	words, err := r.ExpandLine(line)
+	if err != nil {
+		return ErrorResult("", err, startTime), nil
+	}
+	return r.Execute(ctx, words[0], ParseArguments(words[1:]))
+}
+
+// supportedFormats lists the output formats of cmd for error messages
+func supportedFormats(cmd Command) string {
+	formats := []string{string(FormatText)}
+	if formatter, ok := cmd.(OutputFormatter); ok {
+		for _, format := range formatter.OutputFormats() {
+			formats = append(formats, string(format))
+		}
+	}
+	return strings.Join(formats, ", ")
+}
+
 // registerBuiltinCommands registers all built-in commands
 func (r *Registry) registerBuiltinCommands() error {
 	// Register all new commands using the adapter
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"suppercommand/internal/managers/remote_disabled"
+	"suppercommand/internal/security"
 	"suppercommand/internal/types"
 )
 
@@ -109,7 +110,7 @@ func (r *SimpleRemoteCommand) listServers(ctx context.Context) error {
 // addServer adds a new remote server
 func (r *SimpleRemoteCommand) addServer(ctx context.Context, args []string) error {
 	if len(args) < 2 {
-		fmt.Println("Usage: remote add <name> <user@host> [--port <port>] [--key <keyfile>] [--password <password>]")
+		fmt.Println("Usage: remote add <name> <user@host> [--port <port>] [--key <keyfile>] [--cred <name>]")
 		return nil
 	}
 
@@ -131,6 +132,45 @@ func (r *SimpleRemoteCommand) addServer(ctx context.Context, args []string) erro
 		Password: "demo", // Simplified for demo
 	}
 
+	// Resolve secrets from the credential store instead of the command line
+	for i := 2; i < len(args); i++ {
+		switch args[i] {
+		case "--port":
+			if i+1 < len(args) {
+				fmt.Sscanf(args[i+1], "%d", &config.Port)
+				i++
+			}
+		case "--key":
+			if i+1 < len(args) {
+				config.KeyPath = args[i+1]
+				config.AuthMethod = types.AuthMethodKey
+				i++
+			}
+		case "--cred":
+			if i+1 < len(args) {
+				cred, err := security.LoadCredential(args[i+1])
+				if err != nil {
+					fmt.Printf("Error loading credential: %v\n", err)
+					return err
+				}
+				if username := cred.Get("username"); username != "" && config.Username == "" {
+					config.Username = username
+				}
+				if keyFile := cred.Get("key_file"); keyFile != "" {
+					config.KeyPath = keyFile
+					config.AuthMethod = types.AuthMethodKey
+				}
+				if password := cred.Get("password"); password != "" {
+					config.Password = password
+					if config.KeyPath == "" {
+						config.AuthMethod = types.AuthMethodPassword
+					}
+				}
+				i++
+			}
+		}
+	}
+
 	fmt.Printf("Adding server: %s (%s@%s:%d)\n", name, config.Username, config.Host, config.Port)
 
 	err := r.manager.AddServer(ctx, config)
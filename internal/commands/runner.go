@@ -0,0 +1,188 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// CommandRunner runs the system tools commands wrap, such as netstat, ss or
+// powershell. Commands take one so tests can hand them canned output, and so
+// every tool they start stops with the command's context.
+type CommandRunner interface {
+	// Run runs name with args and returns what it wrote to stdout. A failure
+	// is a *RunError carrying what the tool wrote to stderr.
+	Run(ctx context.Context, name string, args ...string) ([]byte, error)
+	// LookPath reports where a tool is installed, as exec.LookPath does
+	LookPath(file string) (string, error)
+}
+
+// DefaultRunner runs tools with os/exec
+var DefaultRunner CommandRunner = ExecRunner{}
+
+// RunnerOr returns runner, or DefaultRunner when a command was built without one
+func RunnerOr(runner CommandRunner) CommandRunner {
+	if runner == nil {
+		return DefaultRunner
+	}
+	return runner
+}
+
+// RunError is returned when a tool can't be started, fails or is cancelled
+type RunError struct {
+	Name   string
+	Err    error
+	Stderr string
+}
+
+func (e *RunError) Error() string {
+	if e.Stderr != "" {
+		return fmt.Sprintf("%s: %v: %s", e.Name, e.Err, e.Stderr)
+	}
+	return fmt.Sprintf("%s: %v", e.Name, e.Err)
+}
+
+// Unwrap lets errors.Is see exec.ErrNotFound and context cancellation
+func (e *RunError) Unwrap() error {
+	return e.Err
+}
+
+// ExecRunner runs tools as child processes, killing them when ctx is done
+type ExecRunner struct{}
+
+// Run runs the tool and waits for it
+func (ExecRunner) Run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		return stdout.Bytes(), &RunError{Name: name, Err: err, Stderr: strings.TrimSpace(stderr.String())}
+	}
+	return stdout.Bytes(), nil
+}
+
+// LookPath finds the tool on PATH
+func (ExecRunner) LookPath(file string) (string, error) {
+	return exec.LookPath(file)
+}
+
+// MockRunner hands out canned tool output instead of running anything, for
+// tests. Command lines are the tool and its arguments joined by spaces, such as
+// "ss -tunap".
+type MockRunner struct {
+	// Outputs maps a command line to what the tool writes to stdout
+	Outputs map[string]string
+	// Errors maps a command line to the error running it returns
+	Errors map[string]error
+	// Installed are the tools LookPath finds. When nil it finds every tool
+	// that has an output.
+	Installed map[string]bool
+
+	mu    sync.Mutex
+	calls []string
+}
+
+// NewMockRunner creates a mock runner with no canned output
+func NewMockRunner() *MockRunner {
+	return &MockRunner{Outputs: map[string]string{}, Errors: map[string]error{}}
+}
+
+// On sets the output of a command line
+func (m *MockRunner) On(line, output string) *MockRunner {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Outputs == nil {
+		m.Outputs = map[string]string{}
+	}
+	m.Outputs[line] = output
+	return m
+}
+
+// Fail makes a command line fail with err
+func (m *MockRunner) Fail(line string, err error) *MockRunner {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Errors == nil {
+		m.Errors = map[string]error{}
+	}
+	m.Errors[line] = err
+	return m
+}
+
+// Run returns the canned output of the command line. Lines with neither an
+// output nor an error fail as if the tool weren't installed.
+func (m *MockRunner) Run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	line := strings.Join(append([]string{name}, args...), " ")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, line)
+
+	if err := ctx.Err(); err != nil {
+		return nil, &RunError{Name: name, Err: err}
+	}
+	if err, ok := m.Errors[line]; ok {
+		return []byte(m.Outputs[line]), &RunError{Name: name, Err: err}
+	}
+	if output, ok := m.Outputs[line]; ok {
+		return []byte(output), nil
+	}
+	return nil, &RunError{Name: name, Err: exec.ErrNotFound}
+}
+
+// LookPath finds the installed tools
+func (m *MockRunner) LookPath(file string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	found := m.Installed[file]
+	if m.Installed == nil {
+		for line := range m.Outputs {
+			if line == file || strings.HasPrefix(line, file+" ") {
+				found = true
+				break
+			}
+		}
+	}
+	if !found {
+		return "", &exec.Error{Name: file, Err: exec.ErrNotFound}
+	}
+	return "/usr/bin/" + file, nil
+}
+
+// Calls returns the command lines run so far, in order
+func (m *MockRunner) Calls() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.calls...)
+}
+
+// Ran reports whether the command line was run
+func (m *MockRunner) Ran(line string) bool {
+	for _, call := range m.Calls() {
+		if call == line {
+			return true
+		}
+	}
+	return false
+}
+
+// ToolRunner is embedded by commands that run system tools, so tests can swap
+// in a *MockRunner
+type ToolRunner struct {
+	runner CommandRunner
+}
+
+// SetRunner replaces the runner the command's tools run with
+func (t *ToolRunner) SetRunner(runner CommandRunner) {
+	t.runner = runner
+}
+
+// Runner returns the runner the command's tools run with
+func (t *ToolRunner) Runner() CommandRunner {
+	return RunnerOr(t.runner)
+}
@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"suppercommand/pkg/errors"
+)
+
+// ParseSelection parses a numbered selection typed at a prompt, such as "1,3 5-7" or
+// "all", against a list of count items. It returns the chosen zero-based indexes in
+// ascending order without duplicates.
+func ParseSelection(input string, count int) ([]int, error) {
+	input = strings.TrimSpace(strings.ToLower(input))
+	if input == "all" || input == "*" {
+		indexes := make([]int, count)
+		for i := range indexes {
+			indexes[i] = i
+		}
+		return indexes, nil
+	}
+
+	chosen := make(map[int]bool)
+	fields := strings.FieldsFunc(input, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+	for _, field := range fields {
+		first, last := field, field
+		if dash := strings.Index(field, "-"); dash > 0 {
+			first, last = field[:dash], field[dash+1:]
+		}
+
+		from, err := strconv.Atoi(first)
+		if err != nil {
+			return nil, errors.NewValidationError("invalid selection: %s", field)
+		}
+		to, err := strconv.Atoi(last)
+		if err != nil {
+			return nil, errors.NewValidationError("invalid selection: %s", field)
+		}
+		if from < 1 || to > count || from > to {
+			return nil, errors.NewValidationError("selection out of range: %s (1-%d)", field, count)
+		}
+
+		for n := from; n <= to; n++ {
+			chosen[n-1] = true
+		}
+	}
+
+	indexes := make([]int, 0, len(chosen))
+	for index := range chosen {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	return indexes, nil
+}
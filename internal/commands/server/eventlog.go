@@ -0,0 +1,196 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"suppercommand/internal/types"
+
+	"github.com/fatih/color"
+)
+
+// eventLogDefaultCount is how many events are shown when --count isn't given
+const eventLogDefaultCount = 20
+
+// eventLogMessageWidth is where table messages are cut off
+const eventLogMessageWidth = 70
+
+// queryEventLog shows the newest events of a log:
+// server eventlog [log] [--level <level>] [--since <time>] [--count N] [--json]
+func (s *SimpleServerCommand) queryEventLog(ctx context.Context, args []string) error {
+	query := &types.EventLogQuery{Count: eventLogDefaultCount}
+	jsonOutput := false
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch arg {
+		case "--json":
+			jsonOutput = true
+		case "--level", "--since", "--count", "-n":
+			if i+1 >= len(args) {
+				return s.eventLogUsage(fmt.Errorf("%s needs a value", arg))
+			}
+			i++
+			value := args[i]
+
+			switch arg {
+			case "--level":
+				level, err := types.ParseEventLevel(value)
+				if err != nil {
+					return s.eventLogUsage(err)
+				}
+				query.MaxLevel = level
+			case "--since":
+				since, err := parseEventSince(value, time.Now())
+				if err != nil {
+					return s.eventLogUsage(err)
+				}
+				query.Since = since
+			default:
+				count, err := strconv.Atoi(value)
+				if err != nil || count <= 0 {
+					return s.eventLogUsage(fmt.Errorf("invalid count '%s'", value))
+				}
+				query.Count = count
+			}
+		default:
+			if strings.HasPrefix(arg, "-") || query.Log != "" {
+				return s.eventLogUsage(fmt.Errorf("unexpected argument '%s'", arg))
+			}
+			query.Log = arg
+		}
+	}
+	if query.Log == "" {
+		query.Log = "System"
+	}
+
+	entries, err := s.manager.GetEventLog(ctx, query)
+	if err != nil {
+		if errors.Is(err, os.ErrPermission) {
+			color.New(color.FgRed).Printf("🔒 Access denied reading the %s log\n", query.Log)
+			fmt.Println("   Run the shell elevated (as Administrator on Windows, with sudo on Linux) and try again.")
+			return err
+		}
+		color.New(color.FgRed).Printf("❌ Error reading event log: %v\n", err)
+		return err
+	}
+
+	if jsonOutput {
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	color.New(color.FgCyan, color.Bold).Printf("📋 EVENT LOG: %s\n", query.Log)
+	fmt.Println("═══════════════════════════════════════════════════════════════")
+	if query.MaxLevel != 0 {
+		fmt.Printf("🚦 Level:    %s and above\n", query.MaxLevel)
+	}
+	if !query.Since.IsZero() {
+		fmt.Printf("🕒 Since:    %s\n", query.Since.Format("2006-01-02 15:04:05"))
+	}
+	if len(entries) == 0 {
+		fmt.Println("📭 No matching events")
+		return nil
+	}
+
+	headerColor := color.New(color.FgWhite, color.Bold)
+	headerColor.Printf("%-19s  %-11s  %-24s  %6s  %s\n", "TIME", "LEVEL", "SOURCE", "ID", "MESSAGE")
+	fmt.Println("───────────────────────────────────────────────────────────────")
+	counts := make(map[types.EventLevel]int)
+	for _, entry := range entries {
+		counts[entry.Level]++
+		fmt.Printf("%s  %s  %s  %6d  %s\n",
+			color.New(color.FgHiBlack).Sprint(entry.Time.Format("2006-01-02 15:04:05")),
+			eventLevelColor(entry.Level).Sprintf("%-11s", entry.Level),
+			color.New(color.FgBlue).Sprintf("%-24s", truncateEventText(entry.Source, 24)),
+			entry.EventID,
+			truncateEventText(entry.Message, eventLogMessageWidth))
+	}
+	fmt.Println("───────────────────────────────────────────────────────────────")
+
+	var parts []string
+	for _, level := range []types.EventLevel{types.EventLevelCritical, types.EventLevelError, types.EventLevelWarning} {
+		if counts[level] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[level], level))
+		}
+	}
+	summary := fmt.Sprintf("📊 %d events", len(entries))
+	if len(parts) > 0 {
+		summary += " (" + strings.Join(parts, ", ") + ")"
+	}
+	fmt.Println(summary)
+	return nil
+}
+
+// eventLogUsage reports a bad argument along with the subcommand's usage
+func (s *SimpleServerCommand) eventLogUsage(err error) error {
+	fmt.Printf("Error: %v\n", err)
+	fmt.Println("Usage: server eventlog [log] [--level <level>] [--since <time>] [--count N] [--json]")
+	return err
+}
+
+// eventLevelColor returns the color an event level is shown in
+func eventLevelColor(level types.EventLevel) *color.Color {
+	switch level {
+	case types.EventLevelCritical:
+		return color.New(color.FgWhite, color.BgRed, color.Bold)
+	case types.EventLevelError:
+		return color.New(color.FgRed, color.Bold)
+	case types.EventLevelWarning:
+		return color.New(color.FgYellow, color.Bold)
+	case types.EventLevelInformation:
+		return color.New(color.FgGreen)
+	default:
+		return color.New(color.FgHiBlack)
+	}
+}
+
+// truncateEventText keeps the first line of text, cut to width runes
+func truncateEventText(text string, width int) string {
+	if index := strings.IndexAny(text, "\r\n"); index >= 0 {
+		text = text[:index]
+	}
+	runes := []rune(strings.TrimSpace(text))
+	if len(runes) <= width {
+		return string(runes)
+	}
+	return string(runes[:width-3]) + "..."
+}
+
+// parseEventSince reads a --since value: a relative age like 30m, 2h or 7d, or
+// a local date and time such as 2024-03-01 or "2024-03-01 08:00"
+func parseEventSince(value string, now time.Time) (time.Time, error) {
+	if len(value) > 1 {
+		amount, err := strconv.Atoi(value[:len(value)-1])
+		if err == nil && amount > 0 {
+			switch value[len(value)-1] {
+			case 'm':
+				return now.Add(-time.Duration(amount) * time.Minute), nil
+			case 'h':
+				return now.Add(-time.Duration(amount) * time.Hour), nil
+			case 'd':
+				return now.AddDate(0, 0, -amount), nil
+			}
+		}
+	}
+
+	for _, layout := range []string{"2006-01-02", "2006-01-02 15:04", "2006-01-02 15:04:05"} {
+		if since, err := time.ParseInLocation(layout, value, now.Location()); err == nil {
+			return since, nil
+		}
+	}
+	if since, err := time.Parse(time.RFC3339, value); err == nil {
+		return since, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time '%s' (use 30m, 2h, 7d or YYYY-MM-DD [HH:MM])", value)
+}
@@ -28,7 +28,7 @@ func NewServerCommand() *SimpleServerCommand {
 	return &SimpleServerCommand{
 		name:        "server",
 		description: "Server management and monitoring",
-		usage:       "server [health|services|users|alerts|backup] [options]",
+		usage:       "server [health|services|users|eventlog|alerts|backup] [options]",
 		manager:     manager,
 	}
 }
@@ -72,6 +72,8 @@ func (s *SimpleServerCommand) Execute(ctx context.Context, args []string) error
 		return s.manageBackup(ctx, args[1:])
 	case "session":
 		return s.manageSessions(ctx, args[1:])
+	case "eventlog", "events":
+		return s.queryEventLog(ctx, args[1:])
 	case "help", "--help", "-h":
 		return s.showHelp()
 	default:
@@ -291,6 +293,12 @@ Commands:
   users               List active users
   session [cmd]       Manage user sessions
     list              List active sessions
+  eventlog [log]      Show recent events (System, Application, Security;
+                      on Linux the journal, or a systemd unit)
+    --level <level>   critical, error, warning, information or verbose
+    --since <time>    30m, 2h, 7d or YYYY-MM-DD [HH:MM]
+    --count <n>       Number of events (default 20)
+    --json            Output events as JSON
   alerts [cmd]        Manage server alerts (not implemented)
   backup [cmd]        Manage server backups (not implemented)
   help                Show this help message
@@ -300,6 +308,7 @@ Examples:
   server services list # List all services
   server users        # List active users
   server services start "Print Spooler"  # Start a service
+  server eventlog System --level error --since 2h  # Recent errors
 `
 	fmt.Println(strings.TrimSpace(help))
 	return nil
@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// sizeUnits are the unit prefixes HumanizeBytes uses and ParseBytes accepts, in
+// steps of 1024
+const sizeUnits = "KMGTPE"
+
+// HumanizeBytes formats a byte count in binary units: 512 B, 1.5 KB, 2.0 GB
+func HumanizeBytes(n int64) string {
+	if n < 0 {
+		return "-" + humanizeMagnitude(uint64(-(n+1))+1)
+	}
+	return humanizeMagnitude(uint64(n))
+}
+
+func humanizeMagnitude(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), sizeUnits[exp])
+}
+
+// ParseBytes reads a size such as 4096, 512K, 2MB, 1.5 GiB or HumanizeBytes'
+// own "1.5 KB". Units are binary and case-insensitive, fractions round to the
+// nearest byte, and negative sizes are refused.
+func ParseBytes(s string) (int64, error) {
+	text := strings.TrimSpace(s)
+	end := 0
+	for end < len(text) && (text[end] >= '0' && text[end] <= '9' || text[end] == '.') {
+		end++
+	}
+	number, unit := text[:end], strings.ToUpper(strings.TrimSpace(text[end:]))
+	if number == "" {
+		return 0, fmt.Errorf("invalid size '%s': expected a number with an optional unit such as 512K or 2G", s)
+	}
+
+	multiplier, ok := sizeMultiplier(unit)
+	if !ok {
+		return 0, fmt.Errorf("invalid size '%s': unknown unit '%s' (use B, K, M, G, T, P or E)", s, text[end:])
+	}
+
+	tooLarge := fmt.Errorf("invalid size '%s': larger than %s", s, HumanizeBytes(math.MaxInt64))
+	if !strings.Contains(number, ".") {
+		value, err := strconv.ParseInt(number, 10, 64)
+		if err != nil || value > math.MaxInt64/multiplier {
+			return 0, tooLarge
+		}
+		return value * multiplier, nil
+	}
+
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size '%s': '%s' is not a number", s, number)
+	}
+	bytes := math.Round(value * float64(multiplier))
+	if bytes >= math.MaxInt64 {
+		return 0, tooLarge
+	}
+	return int64(bytes), nil
+}
+
+// sizeMultiplier returns the bytes in one unit, written as K, KB or KiB
+func sizeMultiplier(unit string) (int64, bool) {
+	switch unit {
+	case "", "B":
+		return 1, true
+	}
+	unit = strings.TrimSuffix(strings.TrimSuffix(unit, "B"), "I")
+	if len(unit) != 1 {
+		return 0, false
+	}
+	exp := strings.Index(sizeUnits, unit)
+	if exp < 0 {
+		return 0, false
+	}
+	return int64(1) << (10 * uint(exp+1)), true
+}
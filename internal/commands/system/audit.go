@@ -0,0 +1,297 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/config"
+	"suppercommand/internal/security"
+
+	"github.com/fatih/color"
+)
+
+// AuditCommand shows and follows the command execution audit log
+type AuditCommand struct {
+	*commands.BaseCommand
+	flags *commands.FlagSet
+	audit *security.AuditLog
+}
+
+// NewAuditCommand creates an audit command reading audit's log file
+func NewAuditCommand(audit *security.AuditLog) *AuditCommand {
+	usage := "audit [status] | audit [-n <count>] [--user <name>] [--grep <text>] [--failed] [-f|--follow] [--json]"
+	return &AuditCommand{
+		BaseCommand: commands.NewBaseCommand(
+			"audit",
+			"Show and follow the command execution audit log",
+			usage,
+			[]string{"windows", "linux", "darwin"},
+			false,
+		),
+		flags: commands.NewFlagSet("audit", usage,
+			commands.FlagSpec{Name: "lines", Short: "n", Kind: commands.IntFlag, Default: "20", Value: "count", Help: "Show the last count entries, 0 for all"},
+			commands.FlagSpec{Name: "user", Kind: commands.StringFlag, Value: "name", Help: "Only entries run by this user"},
+			commands.FlagSpec{Name: "grep", Kind: commands.StringFlag, Value: "text", Help: "Only commands containing text, ignoring case"},
+			commands.FlagSpec{Name: "failed", Help: "Only commands that exited non-zero"},
+			commands.FlagSpec{Name: "follow", Short: "f", Help: "Keep showing new entries until Enter is pressed"},
+			commands.FlagSpec{Name: "json", Help: "Print entries as JSON lines"},
+		),
+		audit: audit,
+	}
+}
+
+// FlagSet returns the options audit accepts
+func (a *AuditCommand) FlagSet() *commands.FlagSet {
+	return a.flags
+}
+
+// CompleteArguments suggests the status subcommand
+func (a *AuditCommand) CompleteArguments(args []string) []string {
+	if len(args) <= 1 {
+		return []string{"status"}
+	}
+	return nil
+}
+
+// auditFilter selects the entries audit shows
+type auditFilter struct {
+	user   string
+	text   string
+	failed bool
+}
+
+func (f auditFilter) matches(entry security.AuditEntry) bool {
+	if f.user != "" && !strings.EqualFold(entry.User, f.user) {
+		return false
+	}
+	if f.failed && entry.ExitCode == 0 {
+		return false
+	}
+	return f.text == "" || strings.Contains(strings.ToLower(entry.Command), strings.ToLower(f.text))
+}
+
+// Execute shows the newest audit entries, then follows new ones if asked
+func (a *AuditCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	startTime := time.Now()
+
+	flags, result := a.flags.ParseArguments(args, startTime)
+	if result != nil {
+		return result, nil
+	}
+	switch flags.Arg(0) {
+	case "":
+	case "status":
+		if len(flags.Args()) > 1 {
+			return commands.UsageResult(a, startTime, "status takes no arguments"), nil
+		}
+		return a.status(startTime), nil
+	default:
+		return commands.UsageResult(a, startTime, "unknown subcommand '%s'", flags.Arg(0)), nil
+	}
+	if flags.Int("lines") < 0 {
+		return commands.UsageResult(a, startTime, "--lines must not be negative"), nil
+	}
+
+	// Show what has been queued so far, including this shell's latest commands
+	a.audit.Flush()
+
+	filter := auditFilter{user: flags.String("user"), text: flags.String("grep"), failed: flags.Bool("failed")}
+	entries, offset, err := a.read(filter, flags.Int("lines"))
+	if err != nil {
+		return commands.ErrorResult("", commands.FileError(a.Name(), a.audit.Path(), err), startTime), nil
+	}
+
+	var output strings.Builder
+	jsonOutput := flags.Bool("json")
+	if jsonOutput {
+		for _, entry := range entries {
+			if data, err := json.Marshal(entry); err == nil {
+				output.Write(data)
+				output.WriteByte('\n')
+			}
+		}
+	} else {
+		output.WriteString(color.New(color.FgCyan, color.Bold).Sprint("📜 COMMAND AUDIT LOG\n"))
+		output.WriteString("═══════════════════════════════════════════════════════════════\n")
+		if !a.audit.Enabled() {
+			output.WriteString(color.New(color.FgYellow).Sprintf("⚠️  Audit logging is off; enable it by setting %s\n", auditEnableSetting()))
+		}
+		if len(entries) == 0 {
+			output.WriteString(color.New(color.FgHiBlack).Sprint("No matching entries\n"))
+		}
+		for _, entry := range entries {
+			output.WriteString(formatAuditEntry(entry))
+		}
+	}
+
+	if flags.Bool("follow") {
+		return a.follow(ctx, filter, jsonOutput, offset, &output, startTime), nil
+	}
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}, nil
+}
+
+// read returns the last limit entries matching filter, or all of them when
+// limit is 0, and how far into the file it read
+func (a *AuditCommand) read(filter auditFilter, limit int) ([]security.AuditEntry, int64, error) {
+	file, err := os.Open(a.audit.Path())
+	if os.IsNotExist(err) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	defer file.Close()
+
+	all, offset, err := security.ScanAuditLog(file)
+	var entries []security.AuditEntry
+	for _, entry := range all {
+		if filter.matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	if limit > 0 && len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+	return entries, offset, err
+}
+
+// follow polls the log for appended entries until Enter is pressed or ctx is
+// cancelled. What has been gathered so far is printed first.
+func (a *AuditCommand) follow(ctx context.Context, filter auditFilter, jsonOutput bool, offset int64, output *strings.Builder, startTime time.Time) *commands.Result {
+	if !jsonOutput {
+		output.WriteString(color.New(color.FgHiBlack).Sprint("👀 Following new entries, press Enter to stop\n"))
+	}
+	fmt.Print(output.String())
+
+	followCtx, cancel := security.CancelOnEnter(ctx)
+	defer cancel()
+
+	followed := 0
+	for {
+		select {
+		case <-followCtx.Done():
+			return &commands.Result{
+				Output:   color.New(color.FgHiBlack).Sprintf("Followed the audit log for %v (%d new entries)\n", time.Since(startTime).Round(time.Second), followed),
+				ExitCode: 0,
+				Duration: time.Since(startTime),
+			}
+		case <-time.After(logFollowInterval):
+		}
+
+		file, err := os.Open(a.audit.Path())
+		if err != nil {
+			// Not created yet, or mid-rotation
+			continue
+		}
+		if info, err := file.Stat(); err == nil && info.Size() < offset {
+			offset = 0
+		}
+		var entries []security.AuditEntry
+		if _, err = file.Seek(offset, io.SeekStart); err == nil {
+			var read int64
+			entries, read, err = security.ScanAuditLog(file)
+			offset += read
+		}
+		file.Close()
+		if err != nil {
+			output := color.New(color.FgRed).Sprintf("❌ Stopped following the audit log: %v\n", err)
+			return commands.ErrorResult(output, commands.FileError(a.Name(), a.audit.Path(), err), startTime)
+		}
+
+		for _, entry := range entries {
+			if !filter.matches(entry) {
+				continue
+			}
+			followed++
+			if jsonOutput {
+				if data, err := json.Marshal(entry); err == nil {
+					fmt.Println(string(data))
+				}
+				continue
+			}
+			fmt.Print(formatAuditEntry(entry))
+		}
+	}
+}
+
+// status shows whether auditing is on and where entries go
+func (a *AuditCommand) status(startTime time.Time) *commands.Result {
+	a.audit.Flush()
+
+	var output strings.Builder
+	output.WriteString(color.New(color.FgCyan, color.Bold).Sprint("📜 AUDIT LOG STATUS\n"))
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+	if a.audit.Enabled() {
+		output.WriteString("Recording:   " + color.New(color.FgGreen).Sprint("✅ on") + "\n")
+	} else {
+		output.WriteString("Recording:   " + color.New(color.FgYellow).Sprint("⚠️  off") + "\n")
+	}
+
+	file := a.audit.Path()
+	if info, err := os.Stat(file); err == nil {
+		file += fmt.Sprintf(" (%s)", commands.HumanizeBytes(info.Size()))
+	}
+	output.WriteString(fmt.Sprintf("File:        %s\n", file))
+
+	systemLog := "off"
+	if a.audit.SystemLog() {
+		systemLog = "syslog (authpriv)"
+		if runtime.GOOS == "windows" {
+			systemLog = "Application event log"
+		}
+	}
+	output.WriteString(fmt.Sprintf("System log:  %s\n", systemLog))
+
+	if dropped, err := a.audit.Status(); dropped > 0 || err != nil {
+		output.WriteString("───────────────────────────────────────────────────────────────\n")
+		if dropped > 0 {
+			output.WriteString(color.New(color.FgYellow).Sprintf("⚠️  %d entries dropped because the writer fell behind\n", dropped))
+		}
+		if err != nil {
+			output.WriteString(color.New(color.FgRed).Sprintf("❌ Last write failed: %v\n", err))
+		}
+	}
+	if !a.audit.Enabled() {
+		output.WriteString(color.New(color.FgHiBlack).Sprintf("💡 Turn it on by setting %s\n", auditEnableSetting()))
+	}
+
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}
+}
+
+// formatAuditEntry formats one entry as a colored line
+func formatAuditEntry(entry security.AuditEntry) string {
+	status := color.New(color.FgGreen).Sprintf("✅ %3d", entry.ExitCode)
+	if entry.ExitCode != 0 {
+		status = color.New(color.FgRed).Sprintf("❌ %3d", entry.ExitCode)
+	}
+	user := entry.User
+	if entry.Elevated {
+		user += "#"
+	}
+	return fmt.Sprintf("%s %s %s %s %s\n",
+		color.New(color.FgHiBlack).Sprint(entry.Time.Local().Format("2006-01-02 15:04:05")),
+		status,
+		color.New(color.FgBlue).Sprintf("%-12s", user),
+		color.New(color.FgHiBlack).Sprint(entry.Dir),
+		entry.Command)
+}
+
+// auditEnableSetting names the configuration that turns the audit log on
+func auditEnableSetting() string {
+	return fmt.Sprintf("security.audit.enabled: true in %s", config.DefaultPath())
+}
@@ -0,0 +1,173 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+
+	"github.com/fatih/color"
+)
+
+// batteryLowPercent and batteryCriticalPercent are when running on battery is warned about
+const (
+	batteryLowPercent      = 20
+	batteryCriticalPercent = 10
+)
+
+// BatteryCommand shows battery charge and power settings
+type BatteryCommand struct {
+	*commands.BaseCommand
+}
+
+// NewBatteryCommand creates a new battery command
+func NewBatteryCommand() *BatteryCommand {
+	return &BatteryCommand{
+		BaseCommand: commands.NewBaseCommand(
+			"battery",
+			"Show battery charge, power source and power plan",
+			"battery [--json]",
+			[]string{"windows", "linux"},
+			false,
+		),
+	}
+}
+
+// Execute reports the state of each battery
+func (b *BatteryCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	startTime := time.Now()
+
+	jsonOutput := false
+	for _, arg := range args.Raw {
+		if arg != "--json" {
+			return &commands.Result{
+				Output:   "Usage: " + b.Usage() + "\n",
+				Error:    commands.UsageError(b.Name(), "unknown option '%s'", arg),
+				ExitCode: 1,
+				Duration: time.Since(startTime),
+			}, nil
+		}
+		jsonOutput = true
+	}
+
+	status, err := readPowerStatus(ctx)
+	if err != nil {
+		output := color.New(color.FgRed).Sprintf("❌ %v\n", err)
+		return commands.ErrorResult(output, err, startTime), nil
+	}
+
+	if jsonOutput {
+		data, err := json.MarshalIndent(status, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return &commands.Result{
+			Output:   string(data) + "\n",
+			ExitCode: 0,
+			Duration: time.Since(startTime),
+		}, nil
+	}
+
+	var output strings.Builder
+	output.WriteString(color.New(color.FgCyan, color.Bold).Sprint("🔋 POWER STATUS\n"))
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+
+	if len(status.Batteries) == 0 {
+		output.WriteString("🔌 No battery found, running on mains power\n")
+	} else if status.OnBattery {
+		output.WriteString(fmt.Sprintf("⚡ Power source: %s\n", color.New(color.FgYellow).Sprint("battery")))
+	} else {
+		output.WriteString(fmt.Sprintf("⚡ Power source: %s\n", color.New(color.FgGreen).Sprint("AC adapter")))
+	}
+	if status.Policy != "" {
+		output.WriteString(fmt.Sprintf("⚙️  %s: %s\n", status.PolicyLabel, color.New(color.FgBlue).Sprint(status.Policy)))
+	}
+
+	lowest := 100
+	for _, battery := range status.Batteries {
+		output.WriteString("───────────────────────────────────────────────────────────────\n")
+		b.writeBattery(&output, battery)
+		if battery.Percent < lowest {
+			lowest = battery.Percent
+		}
+	}
+
+	if status.OnBattery && len(status.Batteries) > 0 {
+		switch {
+		case lowest <= batteryCriticalPercent:
+			output.WriteString(color.New(color.FgRed, color.Bold).Sprintf("\n🪫 Battery critical (%d%%), plug in now\n", lowest))
+		case lowest <= batteryLowPercent:
+			output.WriteString(color.New(color.FgYellow, color.Bold).Sprintf("\n🪫 Battery low (%d%%), consider plugging in\n", lowest))
+		}
+	}
+
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}, nil
+}
+
+// writeBattery renders one battery's gauge and details
+func (b *BatteryCommand) writeBattery(output *strings.Builder, battery BatteryInfo) {
+	title := battery.Name
+	if model := strings.TrimSpace(battery.Vendor + " " + battery.Model); model != "" && model != battery.Name {
+		title += " (" + model + ")"
+	}
+	output.WriteString(color.New(color.FgWhite, color.Bold).Sprintf("🔋 %s\n", title))
+	output.WriteString(fmt.Sprintf("   %s %3d%%\n", batteryGauge(battery.Percent, 30), battery.Percent))
+
+	state := battery.State
+	switch state {
+	case BatteryCharging:
+		state = color.New(color.FgGreen).Sprint("⚡ charging")
+	case BatteryDischarging:
+		state = color.New(color.FgYellow).Sprint("🔻 discharging")
+	case BatteryFull:
+		state = color.New(color.FgGreen).Sprint("✅ full")
+	}
+	output.WriteString(fmt.Sprintf("   State:     %s\n", state))
+
+	if battery.MinutesRemaining > 0 {
+		label := "until empty"
+		if battery.State == BatteryCharging {
+			label = "until full"
+		}
+		output.WriteString(fmt.Sprintf("   Remaining: %dh %02dm %s\n", battery.MinutesRemaining/60, battery.MinutesRemaining%60, label))
+	}
+	if battery.Health > 0 {
+		health := fmt.Sprintf("%.0f%% of design capacity", battery.Health)
+		if battery.Health < 80 {
+			health = color.New(color.FgYellow).Sprint(health)
+		}
+		output.WriteString(fmt.Sprintf("   Health:    %s\n", health))
+	}
+	if battery.CycleCount > 0 {
+		output.WriteString(fmt.Sprintf("   Cycles:    %d\n", battery.CycleCount))
+	}
+	if battery.Technology != "" && battery.Technology != "Unknown" {
+		output.WriteString(fmt.Sprintf("   Type:      %s\n", battery.Technology))
+	}
+}
+
+// batteryGauge draws a bar colored green, yellow or red by charge
+func batteryGauge(percent, width int) string {
+	if percent < 0 {
+		percent = 0
+	} else if percent > 100 {
+		percent = 100
+	}
+	filled := percent * width / 100
+
+	gaugeColor := color.New(color.FgGreen)
+	switch {
+	case percent <= batteryLowPercent:
+		gaugeColor = color.New(color.FgRed)
+	case percent <= 50:
+		gaugeColor = color.New(color.FgYellow)
+	}
+	return "[" + gaugeColor.Sprint(strings.Repeat("█", filled)) + strings.Repeat("░", width-filled) + "]"
+}
@@ -0,0 +1,252 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Battery states, as reported by sysfs and normalized from Win32_Battery
+const (
+	BatteryCharging    = "charging"
+	BatteryDischarging = "discharging"
+	BatteryFull        = "full"
+	BatteryNotCharging = "not charging"
+	BatteryUnknown     = "unknown"
+)
+
+// powerSupplyDir is where Linux exposes batteries and AC adapters
+const powerSupplyDir = "/sys/class/power_supply"
+
+// BatteryInfo is the state of one battery
+type BatteryInfo struct {
+	Name       string `json:"name"`
+	Vendor     string `json:"vendor,omitempty"`
+	Model      string `json:"model,omitempty"`
+	Technology string `json:"technology,omitempty"`
+	Percent    int    `json:"percent"`
+	State      string `json:"state"`
+	// MinutesRemaining is the time to empty while discharging or to full while
+	// charging; zero when the platform can't estimate it
+	MinutesRemaining int `json:"minutes_remaining,omitempty"`
+	// Health is the full capacity as a percentage of the design capacity
+	Health     float64 `json:"health,omitempty"`
+	CycleCount int     `json:"cycle_count,omitempty"`
+}
+
+// PowerStatus is the machine's batteries, power source and power policy
+type PowerStatus struct {
+	Batteries []BatteryInfo `json:"batteries"`
+	// OnBattery is true when no AC adapter is supplying power
+	OnBattery bool `json:"on_battery"`
+	// Policy is the Windows power plan or the Linux CPU frequency governor
+	Policy      string `json:"policy,omitempty"`
+	PolicyLabel string `json:"policy_label,omitempty"`
+}
+
+// readPowerStatus gathers the power status of this machine
+func readPowerStatus(ctx context.Context) (*PowerStatus, error) {
+	if runtime.GOOS == "windows" {
+		return readWindowsPowerStatus(ctx)
+	}
+	return readLinuxPowerStatus(powerSupplyDir, "/sys/devices/system/cpu/cpu0/cpufreq/scaling_governor")
+}
+
+// readLinuxPowerStatus reads the supplies under root and the CPU governor
+func readLinuxPowerStatus(root, governorFile string) (*PowerStatus, error) {
+	status := &PowerStatus{Batteries: make([]BatteryInfo, 0), PolicyLabel: "CPU governor"}
+	supplies, err := ioutil.ReadDir(root)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read power supplies: %w", err)
+	}
+
+	adapters, online := 0, 0
+	for _, supply := range supplies {
+		dir := filepath.Join(root, supply.Name())
+		switch sysfsValue(dir, "type") {
+		case "Battery":
+			// Peripherals such as wireless mice report their batteries here too
+			if sysfsValue(dir, "scope") == "Device" {
+				continue
+			}
+			status.Batteries = append(status.Batteries, ReadPowerSupply(dir))
+		case "Mains", "USB":
+			adapters++
+			if sysfsValue(dir, "online") == "1" {
+				online++
+			}
+		}
+	}
+	if adapters > 0 {
+		status.OnBattery = online == 0
+	} else {
+		// Without an adapter entry the batteries tell us whether power is coming in
+		for _, battery := range status.Batteries {
+			if battery.State == BatteryDischarging {
+				status.OnBattery = true
+			}
+		}
+	}
+
+	if data, err := ioutil.ReadFile(governorFile); err == nil {
+		status.Policy = strings.TrimSpace(string(data))
+	}
+	return status, nil
+}
+
+// ReadPowerSupply reads a battery from its sysfs directory. Batteries report
+// either energy (µWh, µW) or charge (µAh, µA); both give the same ratios.
+func ReadPowerSupply(dir string) BatteryInfo {
+	battery := BatteryInfo{
+		Name:       filepath.Base(dir),
+		Vendor:     sysfsValue(dir, "manufacturer"),
+		Model:      sysfsValue(dir, "model_name"),
+		Technology: sysfsValue(dir, "technology"),
+		State:      strings.ToLower(sysfsValue(dir, "status")),
+	}
+	switch battery.State {
+	case BatteryCharging, BatteryDischarging, BatteryFull, BatteryNotCharging:
+	default:
+		battery.State = BatteryUnknown
+	}
+
+	now, full, design, rate := sysfsNumber(dir, "energy_now"), sysfsNumber(dir, "energy_full"),
+		sysfsNumber(dir, "energy_full_design"), sysfsNumber(dir, "power_now")
+	if now == 0 && full == 0 {
+		now, full, design, rate = sysfsNumber(dir, "charge_now"), sysfsNumber(dir, "charge_full"),
+			sysfsNumber(dir, "charge_full_design"), sysfsNumber(dir, "current_now")
+	}
+	if rate < 0 {
+		// Some drivers report the discharge rate as negative
+		rate = -rate
+	}
+
+	if percent, err := strconv.Atoi(sysfsValue(dir, "capacity")); err == nil {
+		battery.Percent = percent
+	} else if full > 0 {
+		battery.Percent = int(now * 100 / full)
+	}
+	if full > 0 && design > 0 {
+		battery.Health = float64(full) * 100 / float64(design)
+	}
+	if rate > 0 {
+		switch battery.State {
+		case BatteryDischarging:
+			battery.MinutesRemaining = int(now * 60 / rate)
+		case BatteryCharging:
+			if full > now {
+				battery.MinutesRemaining = int((full - now) * 60 / rate)
+			}
+		}
+	}
+	battery.CycleCount = int(sysfsNumber(dir, "cycle_count"))
+	return battery
+}
+
+// sysfsValue returns the trimmed contents of a sysfs attribute, or "" if it
+// doesn't exist
+func sysfsValue(dir, name string) string {
+	data, err := ioutil.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// sysfsNumber returns a numeric sysfs attribute, or 0 if it is missing
+func sysfsNumber(dir, name string) int64 {
+	value, _ := strconv.ParseInt(sysfsValue(dir, name), 10, 64)
+	return value
+}
+
+// win32BatteryStates maps Win32_Battery.BatteryStatus to battery states
+var win32BatteryStates = map[int]string{
+	1: BatteryDischarging, 2: BatteryNotCharging, 3: BatteryFull, 4: BatteryDischarging,
+	5: BatteryDischarging, 6: BatteryCharging, 7: BatteryCharging, 8: BatteryCharging,
+	9: BatteryCharging, 11: BatteryNotCharging,
+}
+
+// win32UnknownRunTime is the EstimatedRunTime Windows reports when it has no estimate
+const win32UnknownRunTime = 71582788
+
+// ParseWin32Battery reads Win32_Battery instances exported as JSON
+func ParseWin32Battery(data []byte) ([]BatteryInfo, error) {
+	var instances []struct {
+		Name                     string `json:"Name"`
+		DeviceID                 string `json:"DeviceID"`
+		EstimatedChargeRemaining int    `json:"EstimatedChargeRemaining"`
+		EstimatedRunTime         int    `json:"EstimatedRunTime"`
+		BatteryStatus            int    `json:"BatteryStatus"`
+	}
+	if err := json.Unmarshal(data, &instances); err != nil {
+		return nil, fmt.Errorf("failed to parse battery status: %w", err)
+	}
+
+	batteries := make([]BatteryInfo, 0, len(instances))
+	for _, instance := range instances {
+		battery := BatteryInfo{
+			Name:    instance.Name,
+			Model:   instance.DeviceID,
+			Percent: instance.EstimatedChargeRemaining,
+			State:   win32BatteryStates[instance.BatteryStatus],
+		}
+		if battery.State == "" {
+			battery.State = BatteryUnknown
+		}
+		if battery.State == BatteryDischarging && instance.EstimatedRunTime > 0 && instance.EstimatedRunTime != win32UnknownRunTime {
+			battery.MinutesRemaining = instance.EstimatedRunTime
+		}
+		batteries = append(batteries, battery)
+	}
+	return batteries, nil
+}
+
+// powerSchemePattern matches the plan name powercfg prints in parentheses
+var powerSchemePattern = regexp.MustCompile(`GUID:\s*[0-9a-fA-F-]+\s+\((.+)\)`)
+
+// ParsePowerScheme returns the plan name from `powercfg /getactivescheme`
+func ParsePowerScheme(output string) string {
+	if match := powerSchemePattern.FindStringSubmatch(output); match != nil {
+		return strings.TrimSpace(match[1])
+	}
+	return ""
+}
+
+// readWindowsPowerStatus reads Win32_Battery, the AC line status and the active power plan
+func readWindowsPowerStatus(ctx context.Context) (*PowerStatus, error) {
+	script := `Add-Type -AssemblyName System.Windows.Forms
+		[PSCustomObject]@{
+			Batteries = @(Get-CimInstance Win32_Battery | Select-Object Name, DeviceID, EstimatedChargeRemaining, EstimatedRunTime, BatteryStatus)
+			PowerLine = [string][System.Windows.Forms.SystemInformation]::PowerStatus.PowerLineStatus
+		} | ConvertTo-Json -Depth 3`
+	output, err := exec.CommandContext(ctx, "powershell", "-NoProfile", "-Command", script).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query battery status: %w", err)
+	}
+
+	var result struct {
+		Batteries json.RawMessage `json:"Batteries"`
+		PowerLine string          `json:"PowerLine"`
+	}
+	if err := json.Unmarshal(output, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse battery status: %w", err)
+	}
+	batteries, err := ParseWin32Battery(result.Batteries)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &PowerStatus{Batteries: batteries, OnBattery: result.PowerLine == "Offline", PolicyLabel: "Power plan"}
+	if output, err := exec.CommandContext(ctx, "powercfg", "/getactivescheme").Output(); err == nil {
+		status.Policy = ParsePowerScheme(string(output))
+	}
+	return status, nil
+}
@@ -0,0 +1,299 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+
+	"github.com/fatih/color"
+)
+
+// TimingStats summarizes the durations of repeated runs
+type TimingStats struct {
+	Runs   int
+	Min    time.Duration
+	Max    time.Duration
+	Mean   time.Duration
+	Median time.Duration
+	StdDev time.Duration
+}
+
+// benchmarkRun is the outcome of one timed execution
+type benchmarkRun struct {
+	duration time.Duration
+	exitCode int
+	output   string
+	err      error
+}
+
+// BenchmarkCommand times repeated executions of a command
+type BenchmarkCommand struct {
+	*commands.BaseCommand
+	registry *commands.Registry
+}
+
+// NewBenchmarkCommand creates a new benchmark command
+func NewBenchmarkCommand(registry *commands.Registry) *BenchmarkCommand {
+	return &BenchmarkCommand{
+		BaseCommand: commands.NewBaseCommand(
+			"benchmark",
+			"Time repeated runs of a command and report min/max/mean/median/stddev",
+			"benchmark [--runs N] [--show-output] <command...> | benchmark [--runs N] --compare \"<cmd1>\" \"<cmd2>\"",
+			[]string{"windows", "linux", "darwin"},
+			false,
+		),
+		registry: registry,
+	}
+}
+
+// Execute runs the benchmark
+func (b *BenchmarkCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	startTime := time.Now()
+
+	runs := 5
+	showOutput := false
+	compare := false
+
+	// Options end at the first word of the benchmarked command so its own flags pass through
+	i := 0
+	for ; i < len(args.Raw) && strings.HasPrefix(args.Raw[i], "--"); i++ {
+		switch args.Raw[i] {
+		case "--runs":
+			if i+1 < len(args.Raw) {
+				if n, err := strconv.Atoi(args.Raw[i+1]); err == nil {
+					runs = n
+				}
+				i++
+			}
+		case "--show-output":
+			showOutput = true
+		case "--compare":
+			compare = true
+		}
+	}
+	rest := args.Raw[i:]
+
+	var targets [][]string
+	if compare {
+		if len(rest) != 2 {
+			return b.usageResult("Usage: benchmark [--runs N] --compare \"<cmd1>\" \"<cmd2>\"", startTime), nil
+		}
+		// Each command is a quoted line, expanded here as the prompt would have
+		for _, line := range rest {
+			words := commands.SplitCommandLine(line)
+			if len(words) > 0 {
+				expanded, err := b.registry.ExpandLine(line)
+				if err != nil {
+					return commands.ErrorResult("", err, startTime), nil
+				}
+				words = expanded
+			}
+			targets = append(targets, words)
+		}
+	} else {
+		targets = append(targets, rest)
+	}
+
+	for _, words := range targets {
+		if len(words) == 0 {
+			return b.usageResult("Usage: "+b.Usage(), startTime), nil
+		}
+		if words[0] == b.Name() {
+			return b.usageResult("benchmark cannot benchmark itself", startTime), nil
+		}
+	}
+	if runs < 1 {
+		return b.usageResult("--runs must be at least 1", startTime), nil
+	}
+
+	var output strings.Builder
+	output.WriteString(color.New(color.FgCyan, color.Bold).Sprint("⏱️  BENCHMARK\n"))
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+	output.WriteString(fmt.Sprintf("🔁 Runs: %d\n", runs))
+
+	var summaries []TimingStats
+	allFailed := true
+
+	for _, words := range targets {
+		label := strings.Join(words, " ")
+		output.WriteString("───────────────────────────────────────────────────────────────\n")
+		output.WriteString(fmt.Sprintf("💻 %s\n", color.New(color.FgYellow, color.Bold).Sprint(label)))
+
+		results, err := b.runAll(ctx, words, runs)
+		if err != nil {
+			output.WriteString(color.New(color.FgYellow).Sprintf("⚠️  Benchmark interrupted: %v\n", err))
+			return &commands.Result{
+				Output:   output.String(),
+				ExitCode: 1,
+				Duration: time.Since(startTime),
+			}, nil
+		}
+
+		durations := make([]time.Duration, 0, len(results))
+		failed := 0
+		for n, run := range results {
+			durations = append(durations, run.duration)
+
+			status := color.New(color.FgGreen).Sprint("✅")
+			if run.err != nil || run.exitCode != 0 {
+				failed++
+				status = color.New(color.FgRed).Sprintf("❌ exit %d", run.exitCode)
+				if run.err != nil {
+					status = color.New(color.FgRed).Sprintf("❌ %v", run.err)
+				}
+			}
+			output.WriteString(fmt.Sprintf("   Run %-3d %10v  %s\n", n+1, run.duration.Round(time.Microsecond), status))
+
+			if showOutput && run.output != "" {
+				output.WriteString(run.output)
+				if !strings.HasSuffix(run.output, "\n") {
+					output.WriteString("\n")
+				}
+			}
+		}
+		if failed < len(results) {
+			allFailed = false
+		}
+
+		stats := SummarizeDurations(durations)
+		summaries = append(summaries, stats)
+		b.writeStats(stats, &output)
+		if failed > 0 {
+			output.WriteString(color.New(color.FgYellow).Sprintf("⚠️  %d of %d runs failed\n", failed, runs))
+		}
+	}
+
+	if len(summaries) == 2 {
+		b.writeComparison(targets, summaries, &output)
+	}
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+
+	exitCode := 0
+	if allFailed {
+		exitCode = 1
+	}
+
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: exitCode,
+		Duration: time.Since(startTime),
+	}, nil
+}
+
+// runAll executes the command runs times, stopping early if ctx is cancelled
+func (b *BenchmarkCommand) runAll(ctx context.Context, words []string, runs int) ([]benchmarkRun, error) {
+	var results []benchmarkRun
+
+	for n := 0; n < runs; n++ {
+		if ctx.Err() != nil {
+			return results, ctx.Err()
+		}
+
+		runStart := time.Now()
+		result, err := b.registry.ExecuteLine(ctx, commands.CommandLine(words[0], words[1:]))
+		run := benchmarkRun{duration: time.Since(runStart), err: err}
+
+		if result != nil {
+			// Prefer the command's own timing, which excludes dispatch overhead
+			if result.Duration > 0 {
+				run.duration = result.Duration
+			}
+			run.exitCode = result.ExitCode
+			run.output = result.Output
+			if run.err == nil {
+				run.err = result.Error
+			}
+		} else if err != nil {
+			run.exitCode = 1
+		}
+		results = append(results, run)
+	}
+
+	return results, nil
+}
+
+// writeStats formats a timing summary
+func (b *BenchmarkCommand) writeStats(stats TimingStats, output *strings.Builder) {
+	valueColor := color.New(color.FgHiCyan)
+
+	output.WriteString(fmt.Sprintf("   📉 Min:     %s\n", valueColor.Sprint(stats.Min.Round(time.Microsecond))))
+	output.WriteString(fmt.Sprintf("   📈 Max:     %s\n", valueColor.Sprint(stats.Max.Round(time.Microsecond))))
+	output.WriteString(fmt.Sprintf("   📊 Mean:    %s\n", valueColor.Sprint(stats.Mean.Round(time.Microsecond))))
+	output.WriteString(fmt.Sprintf("   🎯 Median:  %s\n", valueColor.Sprint(stats.Median.Round(time.Microsecond))))
+	output.WriteString(fmt.Sprintf("   〰️  Std dev: %s\n", valueColor.Sprint(stats.StdDev.Round(time.Microsecond))))
+}
+
+// writeComparison reports which of two commands was faster by mean duration
+func (b *BenchmarkCommand) writeComparison(targets [][]string, summaries []TimingStats, output *strings.Builder) {
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+	output.WriteString(color.New(color.FgYellow, color.Bold).Sprint("🏁 COMPARISON\n"))
+
+	first, second := summaries[0].Mean, summaries[1].Mean
+	if first <= 0 || second <= 0 || first == second {
+		output.WriteString("   Both commands took the same time\n")
+		return
+	}
+
+	faster, slower := 0, 1
+	if second < first {
+		faster, slower = 1, 0
+	}
+	ratio := float64(summaries[slower].Mean) / float64(summaries[faster].Mean)
+
+	output.WriteString(color.New(color.FgGreen, color.Bold).Sprintf("   🚀 %s is %.2fx faster than %s\n",
+		strings.Join(targets[faster], " "), ratio, strings.Join(targets[slower], " ")))
+	output.WriteString(fmt.Sprintf("   Mean difference: %v\n", (summaries[slower].Mean - summaries[faster].Mean).Round(time.Microsecond)))
+}
+
+// usageResult returns a usage message with a failing exit code
+func (b *BenchmarkCommand) usageResult(usage string, startTime time.Time) *commands.Result {
+	return &commands.Result{
+		Output:   usage + "\n",
+		ExitCode: 1,
+		Duration: time.Since(startTime),
+	}
+}
+
+// SummarizeDurations computes min, max, mean, median and sample standard deviation
+func SummarizeDurations(durations []time.Duration) TimingStats {
+	stats := TimingStats{Runs: len(durations)}
+	if len(durations) == 0 {
+		return stats
+	}
+
+	sorted := make([]time.Duration, len(durations))
+	copy(sorted, durations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	stats.Min = sorted[0]
+	stats.Max = sorted[len(sorted)-1]
+
+	if len(sorted)%2 == 1 {
+		stats.Median = sorted[len(sorted)/2]
+	} else {
+		stats.Median = (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+	}
+
+	var sum float64
+	for _, d := range sorted {
+		sum += float64(d)
+	}
+	mean := sum / float64(len(sorted))
+	stats.Mean = time.Duration(mean)
+
+	if len(sorted) > 1 {
+		var squares float64
+		for _, d := range sorted {
+			squares += (float64(d) - mean) * (float64(d) - mean)
+		}
+		stats.StdDev = time.Duration(math.Sqrt(squares / float64(len(sorted)-1)))
+	}
+
+	return stats
+}
@@ -323,19 +323,17 @@ func (b *BookmarkCommand) runBookmark(name string, startTime time.Time) (*comman
 	}, nil
 }
 
-// Helper methods
-func (b *BookmarkCommand) loadBookmarksFromFile() ([]Bookmark, error) {
+// LoadBookmarks reads a bookmark file; a missing file has no bookmarks
+func LoadBookmarks(path string) ([]Bookmark, error) {
 	var bookmarks []Bookmark
 
-	if _, err := os.Stat(b.bookmarkFile); os.IsNotExist(err) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
 		return bookmarks, nil
 	}
-
-	data, err := ioutil.ReadFile(b.bookmarkFile)
 	if err != nil {
 		return nil, err
 	}
-
 	if len(data) == 0 {
 		return bookmarks, nil
 	}
@@ -344,13 +342,23 @@ func (b *BookmarkCommand) loadBookmarksFromFile() ([]Bookmark, error) {
 	return bookmarks, err
 }
 
-func (b *BookmarkCommand) saveBookmarksToFile(bookmarks []Bookmark) error {
+// SaveBookmarks writes a bookmark file
+func SaveBookmarks(path string, bookmarks []Bookmark) error {
 	data, err := json.MarshalIndent(bookmarks, "", "  ")
 	if err != nil {
 		return err
 	}
 
-	return ioutil.WriteFile(b.bookmarkFile, data, 0644)
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// Helper methods
+func (b *BookmarkCommand) loadBookmarksFromFile() ([]Bookmark, error) {
+	return LoadBookmarks(b.bookmarkFile)
+}
+
+func (b *BookmarkCommand) saveBookmarksToFile(bookmarks []Bookmark) error {
+	return SaveBookmarks(b.bookmarkFile, bookmarks)
 }
 
 func (b *BookmarkCommand) categorizeCommand(command string) string {
@@ -0,0 +1,312 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/security"
+
+	"github.com/fatih/color"
+)
+
+// credentialFields lists the fields prompted for each credential type
+var credentialFields = map[string][]string{
+	security.CredentialTypeKey: {"secret"},
+	security.CredentialTypeAWS: {"access_key", "secret_key", "region"},
+	security.CredentialTypeSSH: {"username", "password"},
+}
+
+// secretFields are never shown without --show and are read without echo
+var secretFields = map[string]bool{
+	"secret":     true,
+	"key":        true,
+	"secret_key": true,
+	"password":   true,
+}
+
+// CredCommand manages the encrypted credential store
+type CredCommand struct {
+	*commands.BaseCommand
+	storePath string
+}
+
+// NewCredCommand creates a new cred command
+func NewCredCommand() *CredCommand {
+	return &CredCommand{
+		BaseCommand: commands.NewBaseCommand(
+			"cred",
+			"Manage encrypted credentials for fastcp, cloud and remote commands",
+			"cred [add|get|list|remove] <name> [--type key|aws|ssh] [field=value ...] [--show]",
+			[]string{"windows", "linux", "darwin"},
+			false,
+		),
+		storePath: security.DefaultCredentialStorePath(),
+	}
+}
+
+// Execute handles credential store operations
+func (c *CredCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	startTime := time.Now()
+
+	if len(args.Raw) == 0 {
+		return c.showHelp(startTime), nil
+	}
+
+	subcommand := args.Raw[0]
+	rest := args.Raw[1:]
+
+	switch subcommand {
+	case "add", "set":
+		if len(rest) < 1 {
+			return c.usageResult("Usage: cred add <name> [--type key|aws|ssh] [field=value ...]", startTime), nil
+		}
+		return c.addCredential(rest, startTime), nil
+	case "get", "show":
+		if len(rest) < 1 {
+			return c.usageResult("Usage: cred get <name> [--show]", startTime), nil
+		}
+		return c.getCredential(rest, startTime), nil
+	case "list", "ls":
+		return c.listCredentials(startTime), nil
+	case "remove", "rm", "delete":
+		if len(rest) < 1 {
+			return c.usageResult("Usage: cred remove <name>", startTime), nil
+		}
+		return c.removeCredential(rest[0], startTime), nil
+	default:
+		return c.showHelp(startTime), nil
+	}
+}
+
+// addCredential stores a credential, prompting for any missing fields
+func (c *CredCommand) addCredential(args []string, startTime time.Time) *commands.Result {
+	name := args[0]
+	credType := security.CredentialTypeKey
+	fields := make(map[string]string)
+
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--type" || arg == "-t":
+			if i+1 < len(args) {
+				credType = args[i+1]
+				i++
+			}
+		case strings.HasPrefix(arg, "--type="):
+			credType = strings.TrimPrefix(arg, "--type=")
+		case strings.Contains(arg, "="):
+			parts := strings.SplitN(arg, "=", 2)
+			fields[parts[0]] = parts[1]
+		}
+	}
+
+	required, ok := credentialFields[credType]
+	if !ok {
+		return c.errorResult(fmt.Errorf("unknown credential type '%s' (valid: key, aws, ssh)", credType), startTime)
+	}
+
+	for _, field := range required {
+		if _, exists := fields[field]; exists {
+			continue
+		}
+		prompt := fmt.Sprintf("  %s: ", field)
+		var value string
+		var err error
+		if secretFields[field] {
+			value, err = security.ReadSecret(prompt)
+		} else {
+			value, err = security.ReadLine(prompt)
+		}
+		if err != nil {
+			return c.errorResult(err, startTime)
+		}
+		if value != "" {
+			fields[field] = value
+		}
+	}
+
+	store, err := c.openStore()
+	if err != nil {
+		return c.errorResult(err, startTime)
+	}
+
+	if err := store.Add(&security.Credential{Name: name, Type: credType, Fields: fields}); err != nil {
+		return c.errorResult(err, startTime)
+	}
+
+	var output strings.Builder
+	output.WriteString(color.New(color.FgGreen).Sprintf("✅ Credential '%s' (%s) saved\n", name, credType))
+	output.WriteString(fmt.Sprintf("💡 Reference it with --cred %s instead of passing secrets on the command line\n", name))
+
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}
+}
+
+// getCredential shows a credential, masking secrets unless --show is given
+func (c *CredCommand) getCredential(args []string, startTime time.Time) *commands.Result {
+	name := args[0]
+	show := false
+	for _, arg := range args[1:] {
+		if arg == "--show" {
+			show = true
+		}
+	}
+
+	store, err := c.openStore()
+	if err != nil {
+		return c.errorResult(err, startTime)
+	}
+
+	cred, err := store.Get(name)
+	if err != nil {
+		return c.errorResult(err, startTime)
+	}
+
+	var output strings.Builder
+	output.WriteString(color.New(color.FgCyan, color.Bold).Sprintf("🔑 CREDENTIAL: %s\n", cred.Name))
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+	output.WriteString(fmt.Sprintf("📋 Type:     %s\n", cred.Type))
+	output.WriteString(fmt.Sprintf("📅 Created:  %s\n", cred.Created.Format("2006-01-02 15:04:05")))
+	output.WriteString(fmt.Sprintf("📝 Updated:  %s\n", cred.Updated.Format("2006-01-02 15:04:05")))
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+
+	for _, field := range sortedFieldNames(cred.Fields) {
+		value := cred.Fields[field]
+		if secretFields[field] && !show {
+			value = security.MaskSecret(value)
+		}
+		output.WriteString(fmt.Sprintf("  %-12s %s\n", color.New(color.FgYellow).Sprint(field), value))
+	}
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}
+}
+
+// listCredentials lists stored credential names without decrypting them
+func (c *CredCommand) listCredentials(startTime time.Time) *commands.Result {
+	store, err := c.openStore()
+	if err != nil {
+		return c.errorResult(err, startTime)
+	}
+
+	infos := store.List()
+
+	var output strings.Builder
+	output.WriteString(color.New(color.FgCyan, color.Bold).Sprint("🔐 STORED CREDENTIALS\n"))
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+
+	if len(infos) == 0 {
+		output.WriteString("No credentials stored. Use 'cred add <name>' to add one.\n")
+	} else {
+		output.WriteString(fmt.Sprintf("%-20s %-6s %-30s %s\n", "NAME", "TYPE", "FIELDS", "UPDATED"))
+		output.WriteString("───────────────────────────────────────────────────────────────\n")
+		for _, info := range infos {
+			output.WriteString(fmt.Sprintf("%-20s %-6s %-30s %s\n",
+				color.New(color.FgGreen).Sprintf("%-20s", info.Name),
+				info.Type,
+				strings.Join(info.Fields, ","),
+				info.Updated.Format("2006-01-02 15:04")))
+		}
+	}
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+	output.WriteString(fmt.Sprintf("📍 Store: %s\n", store.Path()))
+
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}
+}
+
+// removeCredential deletes a credential from the store
+func (c *CredCommand) removeCredential(name string, startTime time.Time) *commands.Result {
+	store, err := c.openStore()
+	if err != nil {
+		return c.errorResult(err, startTime)
+	}
+
+	if err := store.Remove(name); err != nil {
+		return c.errorResult(err, startTime)
+	}
+
+	return &commands.Result{
+		Output:   color.New(color.FgGreen).Sprintf("✅ Credential '%s' removed\n", name),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}
+}
+
+// openStore opens the credential store with the master password
+func (c *CredCommand) openStore() (*security.CredentialStore, error) {
+	password, err := security.MasterPassword()
+	if err != nil {
+		return nil, err
+	}
+	return security.OpenCredentialStore(c.storePath, password)
+}
+
+// showHelp displays cred usage
+func (c *CredCommand) showHelp(startTime time.Time) *commands.Result {
+	var output strings.Builder
+	output.WriteString(color.New(color.FgCyan, color.Bold).Sprint("🔐 CREDENTIAL STORE\n"))
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+	output.WriteString("  cred add <name> [--type key|aws|ssh] [field=value ...]\n")
+	output.WriteString("  cred get <name> [--show]\n")
+	output.WriteString("  cred list\n")
+	output.WriteString("  cred remove <name>\n")
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+	output.WriteString("Types and fields:\n")
+	output.WriteString("  key  secret                          (fastcp-send/recv transfer key)\n")
+	output.WriteString("  aws  access_key, secret_key, region  (fastcp-backup/restore)\n")
+	output.WriteString("  ssh  username, password | key_file   (remote, runas)\n")
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+	output.WriteString(fmt.Sprintf("💡 Missing fields are prompted for; set %s to skip the password prompt\n", security.MasterPasswordEnv))
+	output.WriteString("💡 Use --cred <name> with fastcp-send, fastcp-recv, fastcp-backup, fastcp-restore, remote and runas\n")
+	output.WriteString("💡 fastcp-key --save <name> generates a strong transfer key and stores it\n")
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}
+}
+
+// usageResult returns a usage message with a failing exit code
+func (c *CredCommand) usageResult(usage string, startTime time.Time) *commands.Result {
+	return &commands.Result{
+		Output:   usage + "\n",
+		ExitCode: 1,
+		Duration: time.Since(startTime),
+	}
+}
+
+// errorResult formats an error as a failing result
+func (c *CredCommand) errorResult(err error, startTime time.Time) *commands.Result {
+	return &commands.Result{
+		Output:   color.New(color.FgRed).Sprintf("❌ Error: %v\n", err),
+		ExitCode: 1,
+		Duration: time.Since(startTime),
+	}
+}
+
+// sortedFieldNames returns credential field names in a stable order
+func sortedFieldNames(fields map[string]string) []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
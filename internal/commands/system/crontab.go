@@ -0,0 +1,181 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/security"
+
+	"github.com/fatih/color"
+)
+
+// CrontabCommand shows the tasks the operating system has scheduled
+type CrontabCommand struct {
+	*commands.BaseCommand
+}
+
+// NewCrontabCommand creates a new crontab command
+func NewCrontabCommand() *CrontabCommand {
+	return &CrontabCommand{
+		BaseCommand: commands.NewBaseCommand(
+			"crontab",
+			"List the system's scheduled tasks (cron, systemd timers, Task Scheduler)",
+			"crontab [list] [name] [--all] [--json]",
+			[]string{"windows", "linux"},
+			false,
+		),
+	}
+}
+
+// Execute lists scheduled tasks whose name contains the given filter
+func (c *CrontabCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	startTime := time.Now()
+
+	showAll := false
+	jsonOutput := false
+	var words []string
+	for i, arg := range args.Raw {
+		switch {
+		case arg == "list" && i == 0:
+		case arg == "-a" || arg == "--all":
+			showAll = true
+		case arg == "--json":
+			jsonOutput = true
+		case strings.HasPrefix(arg, "-"):
+			return &commands.Result{
+				Output:   "Usage: " + c.Usage() + "\n",
+				Error:    commands.UsageError(c.Name(), "unknown option '%s'", arg),
+				ExitCode: 1,
+				Duration: time.Since(startTime),
+			}, nil
+		default:
+			words = append(words, arg)
+		}
+	}
+	filter := strings.ToLower(strings.Join(words, " "))
+
+	tasks := make([]SystemTask, 0)
+	var warnings []string
+	hidden := 0
+	for _, source := range taskSources() {
+		found, err := source.List(ctx, startTime)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s: %v", source.Name(), err))
+			continue
+		}
+		for _, task := range found {
+			if filter != "" && !strings.Contains(strings.ToLower(task.Name), filter) {
+				continue
+			}
+			// Windows ships hundreds of its own maintenance tasks
+			if !showAll && strings.HasPrefix(task.ID, `\Microsoft\`) {
+				hidden++
+				continue
+			}
+			tasks = append(tasks, task)
+		}
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if tasks[i].Source != tasks[j].Source {
+			return tasks[i].Source < tasks[j].Source
+		}
+		return strings.ToLower(tasks[i].Name) < strings.ToLower(tasks[j].Name)
+	})
+
+	if jsonOutput {
+		data, err := json.MarshalIndent(tasks, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return &commands.Result{
+			Output:   string(data) + "\n",
+			ExitCode: 0,
+			Duration: time.Since(startTime),
+		}, nil
+	}
+
+	var output strings.Builder
+	output.WriteString(color.New(color.FgCyan, color.Bold).Sprint("🗓️  SYSTEM SCHEDULED TASKS\n"))
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+	if filter != "" {
+		output.WriteString(fmt.Sprintf("🔎 Filter: %s\n", color.New(color.FgYellow).Sprint(filter)))
+	}
+
+	source := ""
+	for _, task := range tasks {
+		if task.Source != source {
+			source = task.Source
+			output.WriteString(color.New(color.FgBlue, color.Bold).Sprintf("\n📂 %s\n", source))
+			output.WriteString(color.New(color.FgWhite, color.Bold).Sprintf("   %-28s %-22s %-17s %s\n", "NAME", "SCHEDULE", "NEXT RUN", "STATUS"))
+		}
+		output.WriteString(c.formatTask(task))
+	}
+
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+	if len(tasks) == 0 {
+		output.WriteString("📭 No scheduled tasks found\n")
+	} else {
+		output.WriteString(fmt.Sprintf("📊 %d tasks", len(tasks)))
+		if hidden > 0 {
+			output.WriteString(fmt.Sprintf(" (%d built-in Microsoft tasks hidden, use --all)", hidden))
+		}
+		output.WriteString("\n")
+	}
+	for _, warning := range warnings {
+		output.WriteString(color.New(color.FgYellow).Sprintf("⚠️  %s\n", warning))
+	}
+	if !security.IsElevated() {
+		output.WriteString(color.New(color.FgHiBlack).Sprint("💡 Some tasks are only visible with elevated privileges\n"))
+	}
+	output.WriteString(color.New(color.FgHiBlack).Sprint("💡 SuperShell's own tasks are listed with 'schedule list'\n"))
+
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}, nil
+}
+
+// formatTask renders a task as a table row with its command underneath
+func (c *CrontabCommand) formatTask(task SystemTask) string {
+	nextRun := fmt.Sprintf("%-17s", "-")
+	if !task.NextRun.IsZero() {
+		nextRun = fmt.Sprintf("%-17s", task.NextRun.Local().Format("2006-01-02 15:04"))
+	}
+
+	var statusColor *color.Color
+	switch strings.ToLower(task.Status) {
+	case "enabled", "ready", "waiting", "running", "at boot":
+		statusColor = color.New(color.FgGreen)
+	case "disabled", "inactive", "failed", "invalid schedule":
+		statusColor = color.New(color.FgRed)
+	default:
+		statusColor = color.New(color.FgYellow)
+	}
+
+	row := fmt.Sprintf("   %-28s %s %s %s\n",
+		truncateText(task.Name, 28),
+		color.New(color.FgYellow).Sprintf("%-22s", truncateText(task.Schedule, 22)),
+		nextRun,
+		statusColor.Sprint(task.Status))
+
+	details := task.Command
+	if task.User != "" {
+		details = task.User + ": " + details
+	}
+	return row + color.New(color.FgHiBlack).Sprintf("     ↳ %s\n", truncateText(details, 90))
+}
+
+// truncateText cuts text to width runes, marking the cut with an ellipsis
+func truncateText(text string, width int) string {
+	runes := []rune(text)
+	if len(runes) <= width {
+		return text
+	}
+	return string(runes[:width-1]) + "…"
+}
@@ -0,0 +1,432 @@
+package system
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// SystemTask is a job scheduled by the operating system rather than by SuperShell
+type SystemTask struct {
+	// ID identifies the task within its source: file:line for cron entries,
+	// the task path for Task Scheduler and the unit name for timers
+	ID       string    `json:"id"`
+	Name     string    `json:"name"`
+	Source   string    `json:"source"`
+	User     string    `json:"user,omitempty"`
+	Schedule string    `json:"schedule"`
+	NextRun  time.Time `json:"next_run,omitempty"`
+	LastRun  time.Time `json:"last_run,omitempty"`
+	Status   string    `json:"status"`
+	Command  string    `json:"command"`
+}
+
+// taskSource lists the tasks of one scheduler
+type taskSource interface {
+	Name() string
+	List(ctx context.Context, now time.Time) ([]SystemTask, error)
+}
+
+// taskSources returns the schedulers present on this platform
+func taskSources() []taskSource {
+	if runtime.GOOS == "windows" {
+		return []taskSource{schtasksSource{}}
+	}
+	return []taskSource{cronSource{}, timerSource{}}
+}
+
+// cronEnvPattern matches the variable assignments crontabs may contain
+var cronEnvPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*\s*=`)
+
+// ParseCrontab reads crontab entries. System crontabs (/etc/crontab, /etc/cron.d)
+// name the user in a sixth field and are parsed with an empty owner; a user's
+// crontab runs as its owner. Next runs are computed from now.
+func ParseCrontab(data, source, owner string, now time.Time) []SystemTask {
+	var tasks []SystemTask
+	for number, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || cronEnvPattern.MatchString(line) {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		scheduleFields := 5
+		if strings.HasPrefix(fields[0], "@") {
+			scheduleFields = 1
+		}
+		commandStart := scheduleFields
+		if owner == "" {
+			commandStart++
+		}
+		if len(fields) <= commandStart {
+			continue
+		}
+
+		task := SystemTask{
+			ID:       fmt.Sprintf("%s:%d", source, number+1),
+			Source:   source,
+			User:     owner,
+			Schedule: strings.Join(fields[:scheduleFields], " "),
+			Status:   "enabled",
+			Command:  strings.Join(fields[commandStart:], " "),
+		}
+		if owner == "" {
+			task.User = fields[scheduleFields]
+		}
+		task.Name = cronTaskName(task.Command)
+
+		if strings.EqualFold(task.Schedule, "@reboot") {
+			task.Status = "at boot"
+		} else if schedule, err := ParseCronSchedule(task.Schedule); err == nil {
+			task.NextRun = schedule.Next(now)
+		} else {
+			task.Status = "invalid schedule"
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks
+}
+
+// cronSeparatorPattern splits a cron command into its shell commands
+var cronSeparatorPattern = regexp.MustCompile(`&&|\|\||;|\|`)
+
+// cronTaskName names an entry after the program it runs, skipping the cd and
+// test guards that commonly come first
+func cronTaskName(command string) string {
+	for _, segment := range cronSeparatorPattern.Split(command, -1) {
+		words := strings.Fields(strings.Trim(strings.TrimSpace(segment), "()"))
+		if len(words) == 0 {
+			continue
+		}
+		switch words[0] {
+		case "cd", "test", "[", "[[", "sleep":
+			continue
+		}
+		return filepath.Base(words[0])
+	}
+	return command
+}
+
+// cronSource reads the system crontabs, the periodic script directories and the
+// user crontabs that can be read
+type cronSource struct{}
+
+// Name returns the source name
+func (cronSource) Name() string {
+	return "cron"
+}
+
+// List returns every cron entry that is readable with the current privileges
+func (cronSource) List(ctx context.Context, now time.Time) ([]SystemTask, error) {
+	var tasks []SystemTask
+	if data, err := ioutil.ReadFile("/etc/crontab"); err == nil {
+		tasks = append(tasks, ParseCrontab(string(data), "/etc/crontab", "", now)...)
+	}
+	if files, err := filepath.Glob("/etc/cron.d/*"); err == nil {
+		for _, file := range files {
+			data, err := ioutil.ReadFile(file)
+			if err != nil {
+				continue
+			}
+			entries := ParseCrontab(string(data), file, "", now)
+			for i := range entries {
+				// Files in cron.d usually belong to one package, which is a better name
+				entries[i].Name = filepath.Base(file)
+			}
+			tasks = append(tasks, entries...)
+		}
+	}
+
+	// run-parts directories, run from /etc/crontab or anacron at a distribution-specific time
+	for _, period := range []string{"hourly", "daily", "weekly", "monthly"} {
+		dir := "/etc/cron." + period
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, file := range files {
+			if file.IsDir() || file.Mode()&0111 == 0 || strings.HasPrefix(file.Name(), ".") {
+				continue
+			}
+			path := filepath.Join(dir, file.Name())
+			tasks = append(tasks, SystemTask{
+				ID: path, Name: file.Name(), Source: dir, User: "root",
+				Schedule: "@" + period, Status: "enabled", Command: path,
+			})
+		}
+	}
+
+	// User crontabs live in a root-only spool, so fall back to the current user's
+	spooled := false
+	for _, dir := range []string{"/var/spool/cron/crontabs", "/var/spool/cron"} {
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, file := range files {
+			if file.IsDir() {
+				continue
+			}
+			path := filepath.Join(dir, file.Name())
+			if data, err := ioutil.ReadFile(path); err == nil {
+				spooled = true
+				tasks = append(tasks, ParseCrontab(string(data), path, file.Name(), now)...)
+			}
+		}
+	}
+	if !spooled {
+		if output, err := exec.CommandContext(ctx, "crontab", "-l").Output(); err == nil {
+			owner := os.Getenv("USER")
+			if current, err := user.Current(); err == nil {
+				owner = current.Username
+			}
+			tasks = append(tasks, ParseCrontab(string(output), "crontab -l", owner, now)...)
+		}
+	}
+	return tasks, nil
+}
+
+// timerSource reads systemd timers
+type timerSource struct{}
+
+// Name returns the source name
+func (timerSource) Name() string {
+	return "systemd timers"
+}
+
+// List returns all timers, active or not, with their calendar specs
+func (timerSource) List(ctx context.Context, now time.Time) ([]SystemTask, error) {
+	// Like sd_booted(), treat a missing /run/systemd/system as no systemd at all
+	if _, err := os.Stat("/run/systemd/system"); err != nil {
+		return nil, nil
+	}
+	output, err := exec.CommandContext(ctx, "systemctl", "list-timers", "--all", "--no-pager", "--no-legend").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list timers: %w", err)
+	}
+	tasks := ParseTimerList(string(output), time.Local)
+	if len(tasks) == 0 {
+		return tasks, nil
+	}
+
+	args := []string{"show", "-p", "Id", "-p", "TimersCalendar", "-p", "TimersMonotonic", "-p", "ActiveState"}
+	for _, task := range tasks {
+		args = append(args, task.ID)
+	}
+	if output, err := exec.CommandContext(ctx, "systemctl", args...).Output(); err == nil {
+		applyTimerProperties(tasks, string(output))
+	}
+	return tasks, nil
+}
+
+// timerTimestampPattern matches the timestamps list-timers prints, such as
+// "Mon 2024-03-11 00:00:00 UTC"
+var timerTimestampPattern = regexp.MustCompile(`[A-Z][a-z]{2} \d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2} \S+`)
+
+// ParseTimerList reads `systemctl list-timers --all --no-legend`, whose columns
+// are NEXT LEFT LAST PASSED UNIT ACTIVATES; unknown times print as n/a
+func ParseTimerList(output string, loc *time.Location) []SystemTask {
+	var tasks []SystemTask
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		unit := -1
+		for i, field := range fields {
+			if strings.HasSuffix(field, ".timer") {
+				unit = i
+			}
+		}
+		if unit < 0 {
+			continue
+		}
+
+		task := SystemTask{
+			ID:       fields[unit],
+			Name:     strings.TrimSuffix(fields[unit], ".timer"),
+			Source:   "systemd",
+			Schedule: "timer",
+			Status:   "waiting",
+		}
+		if unit+1 < len(fields) {
+			task.Command = fields[unit+1]
+		}
+
+		prefix := strings.Join(fields[:unit], " ")
+		for _, match := range timerTimestampPattern.FindAllStringIndex(prefix, -1) {
+			stamp, err := time.ParseInLocation("Mon 2006-01-02 15:04:05 MST", prefix[match[0]:match[1]], loc)
+			if err != nil {
+				continue
+			}
+			if match[0] == 0 {
+				task.NextRun = stamp
+			} else {
+				task.LastRun = stamp
+			}
+		}
+		if task.NextRun.IsZero() {
+			task.Status = "inactive"
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks
+}
+
+// timerCalendarPattern pulls the On* settings out of TimersCalendar and TimersMonotonic
+var timerCalendarPattern = regexp.MustCompile(`\{ (On\w+)=([^;]+?) ;`)
+
+// applyTimerProperties fills in schedules and state from `systemctl show`, which
+// prints one block of properties per unit
+func applyTimerProperties(tasks []SystemTask, output string) {
+	byID := make(map[string]*SystemTask)
+	for i := range tasks {
+		byID[tasks[i].ID] = &tasks[i]
+	}
+
+	for _, block := range strings.Split(output, "\n\n") {
+		var task *SystemTask
+		var schedules []string
+		state := ""
+		for _, line := range strings.Split(block, "\n") {
+			key, value := line, ""
+			if index := strings.Index(line, "="); index >= 0 {
+				key, value = line[:index], line[index+1:]
+			}
+			switch key {
+			case "Id":
+				task = byID[value]
+			case "ActiveState":
+				state = value
+			case "TimersCalendar", "TimersMonotonic":
+				for _, match := range timerCalendarPattern.FindAllStringSubmatch(value, -1) {
+					setting := match[2]
+					if match[1] != "OnCalendar" {
+						setting = match[1] + "=" + setting
+					}
+					schedules = append(schedules, setting)
+				}
+			}
+		}
+		if task == nil {
+			continue
+		}
+		if len(schedules) > 0 {
+			task.Schedule = strings.Join(schedules, ", ")
+		}
+		if state != "" && state != "active" {
+			task.Status = state
+		}
+	}
+}
+
+// schtasksSource reads the Windows Task Scheduler
+type schtasksSource struct{}
+
+// Name returns the source name
+func (schtasksSource) Name() string {
+	return "Task Scheduler"
+}
+
+// List returns every task schtasks can see
+func (schtasksSource) List(ctx context.Context, now time.Time) ([]SystemTask, error) {
+	output, err := exec.CommandContext(ctx, "schtasks", "/query", "/fo", "csv", "/v").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scheduled tasks: %w", err)
+	}
+	return ParseSchtasksCSV(string(output))
+}
+
+// schtasksTimeLayouts are the Next Run Time formats of common locales
+var schtasksTimeLayouts = []string{
+	"1/2/2006 3:04:05 PM",
+	"2006-01-02 15:04:05",
+	"02/01/2006 15:04:05",
+	"02.01.2006 15:04:05",
+}
+
+// ParseSchtasksCSV reads `schtasks /query /fo csv /v`. A task with several
+// triggers has a row per trigger and is merged into one task; the header row
+// repeats for each folder.
+func ParseSchtasksCSV(output string) ([]SystemTask, error) {
+	reader := csv.NewReader(strings.NewReader(output))
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse schtasks output: %w", err)
+	}
+
+	// Columns are looked up by their English headers, falling back to the documented order
+	columns := map[string]int{
+		"TaskName": 1, "Next Run Time": 2, "Status": 3, "Last Run Time": 5,
+		"Task To Run": 8, "Scheduled Task State": 11, "Run As User": 14, "Schedule Type": 18,
+	}
+	var tasks []SystemTask
+	seen := make(map[string]int)
+	for _, row := range rows {
+		if len(row) > 1 && (row[1] == "TaskName" || row[0] == "HostName") {
+			for index, header := range row {
+				if _, ok := columns[header]; ok {
+					columns[header] = index
+				}
+			}
+			continue
+		}
+		field := func(name string) string {
+			if index := columns[name]; index < len(row) {
+				value := strings.TrimSpace(row[index])
+				if value != "N/A" {
+					return value
+				}
+			}
+			return ""
+		}
+
+		id := field("TaskName")
+		if id == "" {
+			continue
+		}
+		schedule := field("Schedule Type")
+		if index, ok := seen[id]; ok {
+			if schedule != "" && !strings.Contains(tasks[index].Schedule, schedule) {
+				tasks[index].Schedule += ", " + schedule
+			}
+			continue
+		}
+
+		status := field("Status")
+		if state := field("Scheduled Task State"); strings.EqualFold(state, "Disabled") {
+			status = "Disabled"
+		}
+		seen[id] = len(tasks)
+		tasks = append(tasks, SystemTask{
+			ID:       id,
+			Name:     id[strings.LastIndex(id, `\`)+1:],
+			Source:   "Task Scheduler",
+			User:     field("Run As User"),
+			Schedule: schedule,
+			NextRun:  parseSchtasksTime(field("Next Run Time")),
+			LastRun:  parseSchtasksTime(field("Last Run Time")),
+			Status:   status,
+			Command:  field("Task To Run"),
+		})
+	}
+	return tasks, nil
+}
+
+// parseSchtasksTime parses a schtasks timestamp, returning the zero time for
+// N/A, never-run markers and unrecognised locales
+func parseSchtasksTime(value string) time.Time {
+	for _, layout := range schtasksTimeLayouts {
+		if parsed, err := time.ParseInLocation(layout, value, time.Local); err == nil && parsed.Year() > 1999 {
+			return parsed
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,229 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+
+	"github.com/fatih/color"
+)
+
+// DiagCommand runs diagnostics on the environment SuperShell runs in. For now
+// that is diag terminal, which checks whether the terminal can show
+// SuperShell's colors, emoji and box drawing.
+type DiagCommand struct {
+	*commands.BaseCommand
+	flags *commands.FlagSet
+}
+
+// NewDiagCommand creates a new diag command
+func NewDiagCommand() *DiagCommand {
+	usage := "diag terminal [--output-format text|json]"
+	return &DiagCommand{
+		BaseCommand: commands.NewBaseCommand(
+			"diag",
+			"Check what the terminal supports and show a rendering test",
+			usage,
+			[]string{"windows", "linux", "darwin"},
+			false,
+		),
+		flags: commands.NewFlagSet("diag", usage),
+	}
+}
+
+// FlagSet returns the options diag accepts
+func (d *DiagCommand) FlagSet() *commands.FlagSet {
+	return d.flags
+}
+
+// OutputFormats returns the structured formats diag supports
+func (d *DiagCommand) OutputFormats() []commands.OutputFormat {
+	return []commands.OutputFormat{commands.FormatJSON}
+}
+
+// CompleteArguments offers the diagnostics diag can run
+func (d *DiagCommand) CompleteArguments(args []string) []string {
+	if len(args) > 1 {
+		return nil
+	}
+	return []string{"terminal"}
+}
+
+// terminalReport is diag terminal --output-format json
+type terminalReport struct {
+	commands.TerminalInfo
+	Colors string `json:"colors"`
+}
+
+// Execute runs the diagnostic named by the first argument
+func (d *DiagCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	startTime := time.Now()
+
+	flags, result := d.flags.ParseArguments(args, startTime)
+	if result != nil {
+		return result, nil
+	}
+	var err error
+	switch {
+	case len(flags.Args()) == 0:
+		err = fmt.Errorf("expected a diagnostic, such as terminal")
+	case flags.Arg(0) != "terminal":
+		err = fmt.Errorf("unknown diagnostic '%s'", flags.Arg(0))
+	case len(flags.Args()) > 1:
+		err = fmt.Errorf("unexpected argument '%s'", flags.Arg(1))
+	}
+	if err != nil {
+		return &commands.Result{
+			Output:   "Usage: " + d.Usage() + "\n",
+			Error:    commands.UsageError(d.Name(), "%v", err),
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}, nil
+	}
+
+	info := commands.DetectTerminal()
+	if commands.OutputOptionsFrom(ctx).Structured() {
+		result, err := commands.JSONResult(terminalReport{TerminalInfo: info, Colors: info.Colors.String()}, startTime)
+		if err != nil {
+			return commands.ErrorResult("", err, startTime), nil
+		}
+		return result, nil
+	}
+
+	return &commands.Result{
+		Output:   d.terminal(info),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}, nil
+}
+
+// terminal describes the terminal and prints a rendering test. The labels are
+// plain text, so the report stays readable on the terminals whose test lines
+// come out garbled.
+func (d *DiagCommand) terminal(info commands.TerminalInfo) string {
+	heavy, light := strings.Repeat("═", 63), strings.Repeat("─", 63)
+	yes, no := color.New(color.FgGreen).Sprint("yes"), color.New(color.FgYellow).Sprint("no")
+	answer := func(ok bool) string {
+		if ok {
+			return yes
+		}
+		return no
+	}
+
+	var output strings.Builder
+	output.WriteString(color.New(color.FgCyan, color.Bold).Sprint("TERMINAL DIAGNOSTICS\n"))
+	output.WriteString(heavy + "\n")
+	term := info.Term
+	if term == "" {
+		term = "unknown"
+	}
+	if info.Program != "" {
+		term += " (" + info.Program + ")"
+	}
+	output.WriteString(fmt.Sprintf("Terminal:     %s\n", term))
+	output.WriteString(fmt.Sprintf("Interactive:  %s\n", answer(info.Interactive)))
+	output.WriteString(fmt.Sprintf("Colors:       %s (%s)\n", info.Colors, info.ColorReason))
+	output.WriteString(fmt.Sprintf("UTF-8:        %s, %s\n", answer(info.UTF8), info.Encoding))
+	if info.Width > 0 && info.Height > 0 {
+		output.WriteString(fmt.Sprintf("Window size:  %d columns x %d rows\n", info.Width, info.Height))
+	} else {
+		output.WriteString("Window size:  unknown\n")
+	}
+
+	output.WriteString(light + "\n")
+	output.WriteString(color.New(color.FgCyan, color.Bold).Sprint("RENDERING TEST\n"))
+	if commands.ASCIIDecorations() {
+		output.WriteString("ASCII mode is on, so the symbols below show as their fallbacks; start\n")
+		output.WriteString("SuperShell with decorations: unicode in the config to test the real ones.\n")
+	}
+	if color.NoColor {
+		output.WriteString("Colors:       (colors are off, so no escape codes are printed)\n")
+	} else {
+		output.WriteString("16 colors:    " + basicSwatches() + "\n")
+		output.WriteString("256 colors:   " + paletteRamp() + "\n")
+		output.WriteString("Truecolor:    " + trueColorGradient() + "\n")
+	}
+	output.WriteString("Box drawing:  ┌──┬──┐ ═══ ║ ╔═╗ ╚═╝ ├──┤ └──┴──┘\n")
+	output.WriteString("Emoji:        ✅ ❌ ⚠️  🚀 📁 🌐 🔑 ⏱️  📊\n")
+	output.WriteString("Blocks:       ░▒▓█ ▏▎▍▌▋▊▉ ●○◆\n")
+	output.WriteString("ASCII:        +--+--+ === | [OK] [X] [!] ###--\n")
+	output.WriteString("Each line should show clean symbols, with the colors running smoothly.\n")
+
+	output.WriteString(light + "\n")
+	output.WriteString(color.New(color.FgCyan, color.Bold).Sprint("DEFAULTS\n"))
+	if info.Colors == commands.ColorNone {
+		output.WriteString("Colors:       off, as the terminal can't show them\n")
+	} else if !info.Interactive {
+		output.WriteString("Colors:       off while output goes to a pipe or file\n")
+	} else {
+		output.WriteString("Colors:       on\n")
+	}
+	switch {
+	case commands.ASCIIDecorations() && info.ASCII:
+		output.WriteString("Decorations:  ASCII fallbacks, as the terminal isn't UTF-8\n")
+	case commands.ASCIIDecorations():
+		output.WriteString("Decorations:  ASCII fallbacks, chosen with --ascii or the config\n")
+	case info.ASCII:
+		output.WriteString("Decorations:  Unicode, chosen in the config although the terminal isn't UTF-8\n")
+	default:
+		output.WriteString("Decorations:  Unicode box drawing and emoji\n")
+	}
+
+	if info.ASCII || info.Colors == commands.ColorNone {
+		output.WriteString(light + "\n")
+		output.WriteString(terminalHint(info) + "\n")
+	}
+	output.WriteString(heavy + "\n")
+	return output.String()
+}
+
+// terminalHint says how to get full rendering on a limited terminal
+func terminalHint(info commands.TerminalInfo) string {
+	switch {
+	case info.Term == "conhost" && info.ASCII:
+		return "Tip: use Windows Terminal, or run chcp 65001 before starting SuperShell, for emoji and box drawing"
+	case info.ASCII:
+		return "Tip: set a UTF-8 locale, e.g. export LANG=en_US.UTF-8, for emoji and box drawing"
+	}
+	return "Tip: set TERM to your terminal's type, e.g. xterm-256color, to get colors"
+}
+
+// basicSwatches shows the 8 normal and 8 bright ANSI colors
+func basicSwatches() string {
+	var swatches strings.Builder
+	for code := 40; code <= 47; code++ {
+		swatches.WriteString(fmt.Sprintf("\x1b[%dm  \x1b[0m", code))
+	}
+	swatches.WriteString(" ")
+	for code := 100; code <= 107; code++ {
+		swatches.WriteString(fmt.Sprintf("\x1b[%dm  \x1b[0m", code))
+	}
+	return swatches.String()
+}
+
+// paletteRamp shows a slice of the 256-color cube and the gray ramp
+func paletteRamp() string {
+	var ramp strings.Builder
+	for code := 16; code < 52; code++ {
+		ramp.WriteString(fmt.Sprintf("\x1b[48;5;%dm \x1b[0m", code))
+	}
+	ramp.WriteString(" ")
+	for code := 232; code < 256; code++ {
+		ramp.WriteString(fmt.Sprintf("\x1b[48;5;%dm \x1b[0m", code))
+	}
+	return ramp.String()
+}
+
+// trueColorGradient fades from red to blue, which only looks smooth with 24-bit color
+func trueColorGradient() string {
+	var gradient strings.Builder
+	const steps = 48
+	for i := 0; i < steps; i++ {
+		red := 255 - i*255/(steps-1)
+		gradient.WriteString(fmt.Sprintf("\x1b[48;2;%d;%d;%dm \x1b[0m", red, 64, 255-red))
+	}
+	return gradient.String()
+}
@@ -0,0 +1,301 @@
+package system
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"suppercommand/internal/commands"
+	"suppercommand/pkg/errors"
+
+	"github.com/fatih/color"
+)
+
+// EachCommand runs a command once for every item it reads, like xargs. Items
+// come one per line from stdin or a file, and {} in the command is replaced
+// by the item: find . -name '*.log' | supershell -c each cat {}.
+type EachCommand struct {
+	*commands.BaseCommand
+	flags    *commands.FlagSet
+	registry *commands.Registry
+}
+
+// NewEachCommand creates an each command running commands through registry
+func NewEachCommand(registry *commands.Registry) *EachCommand {
+	usage := "each [--file <file>] [--split <chars>] [--batch <n>] [--parallel <n>] [--fail-fast] <command... {}>"
+	return &EachCommand{
+		BaseCommand: commands.NewBaseCommand(
+			"each",
+			"Run a command for every line of input, substituting it for {}",
+			usage,
+			[]string{"windows", "linux", "darwin"},
+			false,
+		),
+		flags: commands.NewFlagSet("each", usage,
+			commands.FlagSpec{Name: "file", Short: "f", Kind: commands.StringFlag, Value: "file", Help: "Read the items from file instead of stdin"},
+			commands.FlagSpec{Name: "split", Short: "s", Kind: commands.StringFlag, Value: "chars", Help: "Split the input on any of these characters instead of newlines"},
+			commands.FlagSpec{Name: "batch", Short: "n", Kind: commands.IntFlag, Default: "1", Value: "n", Help: "Pass up to n items to each run"},
+			commands.FlagSpec{Name: "parallel", Short: "P", Kind: commands.IntFlag, Default: "1", Value: "n", Help: "Run up to n commands at the same time"},
+			commands.FlagSpec{Name: "fail-fast", Help: "Start no more commands once one has failed"},
+		),
+		registry: registry,
+	}
+}
+
+// FlagSet returns the options each accepts
+func (e *EachCommand) FlagSet() *commands.FlagSet {
+	return e.flags
+}
+
+// Execute reads the items and runs the command for each batch of them. The
+// output of the runs is joined in input order whatever order they finish in,
+// and each fails when any run did.
+func (e *EachCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	startTime := time.Now()
+
+	// Options end at the first word of the command so its own flags pass through
+	options, words := e.flags.SplitLeading(args.Raw)
+	flags, result := e.flags.ParseArguments(commands.ParseArguments(options), startTime)
+	if result != nil {
+		return result, nil
+	}
+	if len(words) == 0 {
+		return commands.UsageResult(e, startTime, "expected a command to run for each item"), nil
+	}
+	batch, parallel := flags.Int("batch"), flags.Int("parallel")
+	switch {
+	case batch < 1:
+		return commands.UsageResult(e, startTime, "--batch must be at least 1"), nil
+	case parallel < 1:
+		return commands.UsageResult(e, startTime, "--parallel must be at least 1"), nil
+	case flags.Changed("split") && flags.String("split") == "":
+		return commands.UsageResult(e, startTime, "--split needs the characters to split on"), nil
+	}
+
+	if e.registry == nil {
+		return commands.ErrorResult("", fmt.Errorf("command registry not available"), startTime), nil
+	}
+	if len(words) == 1 {
+		// The command may also be given as a single quoted line, expanded
+		// here as the prompt would have
+		line, err := e.registry.ExpandLine(words[0])
+		if err != nil {
+			return commands.ErrorResult("", err, startTime), nil
+		}
+		words = line
+	}
+	if _, err := e.registry.Get(words[0]); err != nil {
+		return commands.ErrorResult("", errors.NewNotFoundError("unknown command '%s'; each runs SuperShell commands", words[0]), startTime), nil
+	}
+
+	input := commands.InputReader(ctx)
+	if flags.Changed("file") {
+		file, err := os.Open(flags.String("file"))
+		if err != nil {
+			return commands.ErrorResult("", err, startTime), nil
+		}
+		defer file.Close()
+		input = file
+	} else if commands.InputIsTerminal(ctx) {
+		return commands.UsageResult(e, startTime, "items are read from a pipe or --file, and stdin is a terminal"), nil
+	}
+	items, err := ReadItems(input, flags.String("split"))
+	if err != nil {
+		return commands.ErrorResult("", fmt.Errorf("failed to read items: %v", err), startTime), nil
+	}
+
+	var batches [][]string
+	for len(items) > 0 {
+		n := batch
+		if n > len(items) {
+			n = len(items)
+		}
+		batches = append(batches, items[:n])
+		items = items[n:]
+	}
+	results := e.runBatches(ctx, words, batches, parallel, flags.Bool("fail-fast"))
+	return e.collect(ctx, words, batches, results, startTime), nil
+}
+
+// runBatches runs the command for each batch, at most parallel at a time. A
+// batch left unstarted, after a failure with failFast or a cancel, has no result.
+func (e *EachCommand) runBatches(ctx context.Context, words []string, batches [][]string, parallel int, failFast bool) []*commands.Result {
+	results := make([]*commands.Result, len(batches))
+	progress := commands.ProgressWriter(ctx)
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	failed := false
+
+	for i, items := range batches {
+		// Acquire a worker, giving up if each was cancelled
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		mu.Lock()
+		stop := failFast && failed
+		mu.Unlock()
+		if stop {
+			<-sem
+			break
+		}
+
+		line := ExpandItems(words, items)
+		if len(batches) > 1 {
+			fmt.Fprint(progress, color.New(color.FgHiBlack).Sprintf("▶ [%d/%d] %s\n", i+1, len(batches), commands.CommandLine(line[0], line[1:])))
+		}
+		wg.Add(1)
+		go func(i int, line []string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			result := runLine(ctx, e.registry, commands.CommandLine(line[0], line[1:]))
+			mu.Lock()
+			results[i] = result
+			if result.ExitCode != 0 {
+				failed = true
+			}
+			mu.Unlock()
+		}(i, line)
+	}
+	wg.Wait()
+	return results
+}
+
+// collect joins the results of the runs into the result of each
+func (e *EachCommand) collect(ctx context.Context, words []string, batches [][]string, results []*commands.Result, startTime time.Time) *commands.Result {
+	var output, stderr strings.Builder
+	ran, failures := 0, 0
+	for i, result := range results {
+		if result == nil {
+			continue
+		}
+		ran++
+		output.WriteString(result.Output)
+		if result.Output != "" && !strings.HasSuffix(result.Output, "\n") {
+			output.WriteString("\n")
+		}
+		stderr.WriteString(result.Stderr)
+		if result.ExitCode == 0 {
+			continue
+		}
+		failures++
+		reason := fmt.Sprintf("exit code %d", result.ExitCode)
+		if result.Error != nil {
+			reason = result.Error.Error()
+		}
+		line := ExpandItems(words, batches[i])
+		stderr.WriteString(color.New(color.FgRed).Sprintf("❌ %s: %s\n", commands.CommandLine(line[0], line[1:]), reason))
+	}
+
+	elapsed := time.Since(startTime).Round(time.Millisecond)
+	var err error
+	var outcome string
+	switch {
+	case ctx.Err() != nil && ran < len(batches):
+		err = errors.Wrap(ctx.Err(), "each interrupted after %d of %d runs", ran, len(batches))
+		outcome = color.New(color.FgYellow).Sprintf("⚠️  Interrupted after %d of %d runs (%v)\n", ran, len(batches), elapsed)
+	case failures > 0:
+		err = errors.NewExecutionError("each: %d of %d runs failed", failures, ran)
+		outcome = color.New(color.FgRed).Sprintf("❌ %d of %d runs failed, %d not started (%v)\n", failures, ran, len(batches)-ran, elapsed)
+		if ran == len(batches) {
+			outcome = color.New(color.FgRed).Sprintf("❌ %d of %d runs failed (%v)\n", failures, ran, elapsed)
+		}
+	case len(batches) == 0:
+		outcome = color.New(color.FgYellow).Sprint("⚠️  No input, so nothing was run\n")
+	default:
+		outcome = color.New(color.FgGreen).Sprintf("✅ %d runs succeeded (%v)\n", ran, elapsed)
+	}
+	if commands.OutputOptionsFrom(ctx).Decorated() {
+		stderr.WriteString(outcome)
+	}
+
+	exitCode := 0
+	if err != nil {
+		exitCode = 1
+	}
+	return &commands.Result{
+		Output:   output.String(),
+		Stderr:   stderr.String(),
+		Error:    err,
+		ExitCode: exitCode,
+		Duration: time.Since(startTime),
+	}
+}
+
+// ReadItems reads the items each runs a command for: the lines of r, or the
+// fields between any of the characters in split. Blank items are skipped.
+func ReadItems(r io.Reader, split string) ([]string, error) {
+	var items []string
+	if split != "" {
+		data, err := ioutil.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range strings.FieldsFunc(string(data), func(c rune) bool { return strings.ContainsRune(split, c) }) {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return items, nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if item := strings.TrimRight(scanner.Text(), "\r"); strings.TrimSpace(item) != "" {
+			items = append(items, item)
+		}
+	}
+	return items, scanner.Err()
+}
+
+// ExpandItems fills a command template with items. A word that is exactly {}
+// becomes the items as separate arguments and {} inside a word becomes the
+// items joined by spaces, so each item stays one argument whatever it
+// contains. Without any {} the items are appended, as xargs does.
+func ExpandItems(words []string, items []string) []string {
+	var line []string
+	substituted := false
+	for _, word := range words {
+		switch {
+		case word == "{}":
+			line = append(line, items...)
+			substituted = true
+		case strings.Contains(word, "{}"):
+			line = append(line, strings.Replace(word, "{}", strings.Join(items, " "), -1))
+			substituted = true
+		default:
+			line = append(line, word)
+		}
+	}
+	if !substituted {
+		line = append(line, items...)
+	}
+	return line
+}
+
+// runLine runs one SuperShell command line, turning a dispatch failure into a
+// failed result
+func runLine(ctx context.Context, registry *commands.Registry, line string) *commands.Result {
+	startTime := time.Now()
+	result, err := registry.ExecuteLine(ctx, line)
+	if result == nil {
+		result = commands.ErrorResult("", err, startTime)
+	} else if result.Error == nil && err != nil {
+		result.Error = err
+	}
+	if result.Error != nil && result.ExitCode == 0 {
+		result.ExitCode = 1
+	}
+	return result
+}
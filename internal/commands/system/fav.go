@@ -0,0 +1,377 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/security"
+
+	"github.com/fatih/color"
+)
+
+// Favorite is a complete command line saved under a label
+type Favorite struct {
+	Label    string    `json:"label"`
+	Command  string    `json:"command"`
+	Created  time.Time `json:"created"`
+	LastUsed time.Time `json:"last_used,omitempty"`
+	UseCount int       `json:"use_count"`
+}
+
+// favoriteLabelPattern allows labels that can be typed without quoting. Labels
+// can't be plain numbers so `fav run 2` always means the second entry.
+var favoriteLabelPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ValidFavoriteLabel reports whether a label can name a favorite
+func ValidFavoriteLabel(label string) bool {
+	if !favoriteLabelPattern.MatchString(label) {
+		return false
+	}
+	_, err := strconv.Atoi(label)
+	return err != nil
+}
+
+// FindFavorite returns the index of the favorite selected by a 1-based number
+// or a label, or -1 if none matches
+func FindFavorite(favorites []Favorite, selector string) int {
+	if n, err := strconv.Atoi(selector); err == nil {
+		if n >= 1 && n <= len(favorites) {
+			return n - 1
+		}
+		return -1
+	}
+	for i, favorite := range favorites {
+		if favorite.Label == selector {
+			return i
+		}
+	}
+	return -1
+}
+
+// JoinCommandWords rebuilds a command line from words the shell has already
+// split, quoting any that need it. A single word is taken as a whole line, so
+// `fav add x "sniff -i eth0 port 53"` and `fav add x sniff -i eth0 port 53` agree.
+func JoinCommandWords(words []string) string {
+	if len(words) == 1 {
+		return strings.TrimSpace(words[0])
+	}
+	quoted := make([]string, len(words))
+	for i, word := range words {
+		quoted[i] = commands.QuoteArgument(word)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// LoadFavorites reads the favorites file; a missing file has no favorites
+func LoadFavorites(path string) ([]Favorite, error) {
+	favorites := make([]Favorite, 0)
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return favorites, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return favorites, nil
+	}
+	if err := json.Unmarshal(data, &favorites); err != nil {
+		return nil, fmt.Errorf("corrupt favorites file %s: %w", path, err)
+	}
+	return favorites, nil
+}
+
+// SaveFavorites writes the favorites file
+func SaveFavorites(path string, favorites []Favorite) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(favorites, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// FavCommand saves complete command lines under a label and runs them by number
+type FavCommand struct {
+	*commands.BaseCommand
+	registry *commands.Registry
+	file     string
+}
+
+// NewFavCommand creates a fav command storing favorites under ~/.supershell
+func NewFavCommand(registry *commands.Registry) *FavCommand {
+	homeDir, _ := os.UserHomeDir()
+	return &FavCommand{
+		BaseCommand: commands.NewBaseCommand(
+			"fav",
+			"Save favorite command lines and run them from a numbered menu",
+			"fav [list] | fav add <label> <command...> | fav run <n|label> | fav edit <n|label> [--label <new>] [command...] | fav remove <n|label>",
+			[]string{"windows", "linux", "darwin"},
+			false,
+		),
+		registry: registry,
+		file:     filepath.Join(homeDir, ".supershell", "favorites.json"),
+	}
+}
+
+// Execute dispatches the fav subcommands
+func (f *FavCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	startTime := time.Now()
+
+	if len(args.Raw) == 0 {
+		return f.list(startTime)
+	}
+
+	rest := args.Raw[1:]
+	switch args.Raw[0] {
+	case "list", "ls":
+		return f.list(startTime)
+	case "add":
+		if len(rest) < 2 {
+			return commands.UsageResult(f, startTime, "add needs a label and a command"), nil
+		}
+		return f.add(rest[0], JoinCommandWords(rest[1:]), startTime)
+	case "run":
+		if len(rest) != 1 {
+			return commands.UsageResult(f, startTime, "run needs a favorite number or label"), nil
+		}
+		return f.run(ctx, rest[0], startTime)
+	case "edit":
+		if len(rest) < 1 {
+			return commands.UsageResult(f, startTime, "edit needs a favorite number or label"), nil
+		}
+		return f.edit(rest[0], rest[1:], startTime)
+	case "remove", "rm":
+		if len(rest) != 1 {
+			return commands.UsageResult(f, startTime, "remove needs a favorite number or label"), nil
+		}
+		return f.remove(rest[0], startTime)
+	default:
+		return commands.UsageResult(f, startTime, "unknown subcommand '%s'", args.Raw[0]), nil
+	}
+}
+
+// CompleteArguments suggests subcommands, then favorite labels for run, edit and remove
+func (f *FavCommand) CompleteArguments(args []string) []string {
+	switch {
+	case len(args) == 1:
+		return []string{"list", "add", "run", "edit", "remove"}
+	case len(args) == 2 && (args[0] == "run" || args[0] == "edit" || args[0] == "remove" || args[0] == "rm"):
+		favorites, err := LoadFavorites(f.file)
+		if err != nil {
+			return nil
+		}
+		labels := make([]string, len(favorites))
+		for i, favorite := range favorites {
+			labels[i] = favorite.Label
+		}
+		return labels
+	}
+	return nil
+}
+
+// list shows the numbered menu of favorites
+func (f *FavCommand) list(startTime time.Time) (*commands.Result, error) {
+	favorites, err := LoadFavorites(f.file)
+	if err != nil {
+		return f.failure(err, startTime)
+	}
+
+	var output strings.Builder
+	output.WriteString(color.New(color.FgCyan, color.Bold).Sprint("⭐ FAVORITE COMMANDS\n"))
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+
+	if len(favorites) == 0 {
+		output.WriteString("No favorites saved yet\n")
+		output.WriteString(color.New(color.FgHiBlack).Sprint("💡 Save one with: fav add <label> <command...>\n"))
+		return &commands.Result{Output: output.String(), ExitCode: 0, Duration: time.Since(startTime)}, nil
+	}
+
+	for i, favorite := range favorites {
+		output.WriteString(fmt.Sprintf("  %s %s\n", color.New(color.FgYellow, color.Bold).Sprintf("%3d.", i+1),
+			color.New(color.FgGreen, color.Bold).Sprint(favorite.Label)))
+		output.WriteString(fmt.Sprintf("       %s\n", favorite.Command))
+		if favorite.UseCount > 0 {
+			output.WriteString(color.New(color.FgHiBlack).Sprintf("       used %d times, last %s\n",
+				favorite.UseCount, favorite.LastUsed.Format("2006-01-02 15:04")))
+		}
+	}
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+	output.WriteString(color.New(color.FgHiBlack).Sprint("💡 Run one with: fav run <n|label>\n"))
+
+	return &commands.Result{Output: output.String(), ExitCode: 0, Duration: time.Since(startTime)}, nil
+}
+
+// add saves a new favorite
+func (f *FavCommand) add(label, line string, startTime time.Time) (*commands.Result, error) {
+	if !ValidFavoriteLabel(label) {
+		return commands.UsageResult(f, startTime, "invalid label '%s': use letters, digits, '.', '_' or '-' and not just a number", label), nil
+	}
+	if err := checkFavoriteCommand(line); err != nil {
+		return commands.UsageResult(f, startTime, "%v", err), nil
+	}
+
+	favorites, err := LoadFavorites(f.file)
+	if err != nil {
+		return f.failure(err, startTime)
+	}
+	if FindFavorite(favorites, label) >= 0 {
+		return f.failure(fmt.Errorf("favorite '%s' already exists; use fav edit to change it", label), startTime)
+	}
+
+	favorites = append(favorites, Favorite{Label: label, Command: line, Created: time.Now()})
+	if err := SaveFavorites(f.file, favorites); err != nil {
+		return f.failure(fmt.Errorf("failed to save favorites: %w", err), startTime)
+	}
+
+	return &commands.Result{
+		Output:   color.New(color.FgGreen).Sprintf("✅ Saved favorite #%d '%s': %s\n", len(favorites), label, line),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}, nil
+}
+
+// run executes a favorite through the registry, as if it had been typed
+func (f *FavCommand) run(ctx context.Context, selector string, startTime time.Time) (*commands.Result, error) {
+	favorites, err := LoadFavorites(f.file)
+	if err != nil {
+		return f.failure(err, startTime)
+	}
+	index := FindFavorite(favorites, selector)
+	if index < 0 {
+		return f.failure(fmt.Errorf("no favorite '%s'; run fav to see the list", selector), startTime)
+	}
+	favorite := favorites[index]
+
+	words := commands.SplitCommandWords(favorite.Command)
+	if len(words) == 0 {
+		return f.failure(fmt.Errorf("favorite '%s' has an empty command", favorite.Label), startTime)
+	}
+	if f.registry == nil {
+		return f.failure(fmt.Errorf("command registry not available"), startTime)
+	}
+	favorites[index].LastUsed = time.Now()
+	favorites[index].UseCount++
+	// A failure to record usage shouldn't stop the command from running
+	SaveFavorites(f.file, favorites)
+
+	header := color.New(color.FgCyan).Sprintf("▶️  %s: %s\n", favorite.Label, favorite.Command)
+	result, err := f.registry.ExecuteLine(ctx, favorite.Command)
+	if result == nil {
+		return f.failure(err, startTime)
+	}
+	result.Output = header + result.Output
+	return result, err
+}
+
+// edit changes a favorite's command and optionally its label, prompting for the
+// new command line when none is given
+func (f *FavCommand) edit(selector string, rest []string, startTime time.Time) (*commands.Result, error) {
+	newLabel := ""
+	var words []string
+	for i := 0; i < len(rest); i++ {
+		if rest[i] == "--label" && i+1 < len(rest) {
+			newLabel = rest[i+1]
+			i++
+			continue
+		}
+		words = append(words, rest[i])
+	}
+
+	favorites, err := LoadFavorites(f.file)
+	if err != nil {
+		return f.failure(err, startTime)
+	}
+	index := FindFavorite(favorites, selector)
+	if index < 0 {
+		return f.failure(fmt.Errorf("no favorite '%s'; run fav to see the list", selector), startTime)
+	}
+
+	line := favorites[index].Command
+	if len(words) > 0 {
+		line = JoinCommandWords(words)
+	} else if newLabel == "" {
+		fmt.Printf("Current: %s\n", line)
+		answer, err := security.ReadLine("✏️  New command (Enter to keep): ")
+		if err != nil {
+			return f.failure(err, startTime)
+		}
+		if answer = strings.TrimSpace(answer); answer != "" {
+			line = answer
+		}
+	}
+	if err := checkFavoriteCommand(line); err != nil {
+		return commands.UsageResult(f, startTime, "%v", err), nil
+	}
+
+	if newLabel != "" && newLabel != favorites[index].Label {
+		if !ValidFavoriteLabel(newLabel) {
+			return commands.UsageResult(f, startTime, "invalid label '%s': use letters, digits, '.', '_' or '-' and not just a number", newLabel), nil
+		}
+		if FindFavorite(favorites, newLabel) >= 0 {
+			return f.failure(fmt.Errorf("favorite '%s' already exists", newLabel), startTime)
+		}
+		favorites[index].Label = newLabel
+	}
+	favorites[index].Command = line
+
+	if err := SaveFavorites(f.file, favorites); err != nil {
+		return f.failure(fmt.Errorf("failed to save favorites: %w", err), startTime)
+	}
+	return &commands.Result{
+		Output:   color.New(color.FgGreen).Sprintf("✅ Updated favorite #%d '%s': %s\n", index+1, favorites[index].Label, line),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}, nil
+}
+
+// remove deletes a favorite, renumbering the ones after it
+func (f *FavCommand) remove(selector string, startTime time.Time) (*commands.Result, error) {
+	favorites, err := LoadFavorites(f.file)
+	if err != nil {
+		return f.failure(err, startTime)
+	}
+	index := FindFavorite(favorites, selector)
+	if index < 0 {
+		return f.failure(fmt.Errorf("no favorite '%s'; run fav to see the list", selector), startTime)
+	}
+
+	label := favorites[index].Label
+	favorites = append(favorites[:index], favorites[index+1:]...)
+	if err := SaveFavorites(f.file, favorites); err != nil {
+		return f.failure(fmt.Errorf("failed to save favorites: %w", err), startTime)
+	}
+	return &commands.Result{
+		Output:   color.New(color.FgGreen).Sprintf("🗑️  Removed favorite '%s'\n", label),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}, nil
+}
+
+// checkFavoriteCommand rejects empty lines and favorites that would run fav itself
+func checkFavoriteCommand(line string) error {
+	words := commands.SplitCommandLine(line)
+	if len(words) == 0 {
+		return fmt.Errorf("command cannot be empty")
+	}
+	if words[0] == "fav" {
+		return fmt.Errorf("a favorite can't run fav itself")
+	}
+	return nil
+}
+
+// failure reports an error reading, changing or running favorites
+func (f *FavCommand) failure(err error, startTime time.Time) (*commands.Result, error) {
+	return commands.ErrorResult(color.New(color.FgRed).Sprintf("❌ %v\n", err), err, startTime), nil
+}
@@ -0,0 +1,317 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// GPUInfo describes one graphics adapter
+type GPUInfo struct {
+	Name       string `json:"name"`
+	Vendor     string `json:"vendor,omitempty"`
+	BusID      string `json:"bus_id,omitempty"`
+	Driver     string `json:"driver,omitempty"`
+	Version    string `json:"driver_version,omitempty"`
+	VRAM       uint64 `json:"vram_bytes,omitempty"`
+	VRAMUsed   uint64 `json:"vram_used_bytes,omitempty"`
+	Integrated bool   `json:"integrated"`
+	// Utilization is the busy percentage, when the driver reports one
+	Utilization *float64 `json:"utilization,omitempty"`
+}
+
+// gpuVendors shortens the vendor names lspci and Windows report
+var gpuVendors = []struct{ match, name string }{
+	{"nvidia", "NVIDIA"},
+	{"advanced micro devices", "AMD"},
+	{"amd", "AMD"},
+	{"ati", "AMD"},
+	{"intel", "Intel"},
+	{"microsoft", "Microsoft"},
+	{"vmware", "VMware"},
+	{"red hat", "Red Hat"},
+}
+
+// shortVendor returns the common name of a GPU vendor
+func shortVendor(vendor string) string {
+	lower := strings.ToLower(vendor)
+	for _, known := range gpuVendors {
+		if strings.HasPrefix(lower, known.match) {
+			return known.name
+		}
+	}
+	return vendor
+}
+
+// isIntegratedGPU guesses whether an adapter shares system memory. Intel graphics
+// are integrated apart from Arc; AMD APUs only have a small carve-out of VRAM.
+func isIntegratedGPU(gpu GPUInfo) bool {
+	switch gpu.Vendor {
+	case "Intel":
+		return !strings.Contains(gpu.Name, "Arc")
+	case "AMD":
+		return gpu.VRAM > 0 && gpu.VRAM < 1<<30
+	}
+	return false
+}
+
+// gatherGPUs returns the graphics adapters of this machine
+func gatherGPUs(ctx context.Context) ([]GPUInfo, error) {
+	var gpus []GPUInfo
+	var err error
+	if runtime.GOOS == "windows" {
+		gpus, err = windowsGPUs(ctx)
+	} else {
+		gpus, err = linuxGPUs(ctx, "/sys/bus/pci/devices")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// nvidia-smi ships with the driver on both platforms and knows usage figures
+	if output, err := exec.CommandContext(ctx, "nvidia-smi",
+		"--query-gpu=pci.bus_id,name,memory.total,memory.used,driver_version,utilization.gpu",
+		"--format=csv,noheader,nounits").Output(); err == nil {
+		gpus = MergeNvidiaGPUs(gpus, ParseNvidiaSmi(string(output)))
+	}
+	for i := range gpus {
+		gpus[i].Integrated = isIntegratedGPU(gpus[i])
+	}
+	return gpus, nil
+}
+
+// lspciQuotedPattern matches the quoted fields of `lspci -mm` output
+var lspciQuotedPattern = regexp.MustCompile(`"([^"]*)"`)
+
+// ParseLspciGPUs returns the display controllers in `lspci -Dmm` output, whose
+// quoted fields are class, vendor, device, subsystem vendor and subsystem device
+func ParseLspciGPUs(output string) []GPUInfo {
+	gpus := make([]GPUInfo, 0)
+	for _, line := range strings.Split(output, "\n") {
+		fields := lspciQuotedPattern.FindAllStringSubmatch(line, -1)
+		if len(fields) < 3 {
+			continue
+		}
+		class := fields[0][1]
+		if !strings.Contains(class, "VGA") && !strings.Contains(class, "3D") && !strings.Contains(class, "Display") {
+			continue
+		}
+
+		vendor := shortVendor(fields[1][1])
+		device := fields[2][1]
+		// Marketing names are in brackets after the chip code name, e.g. "GA104 [GeForce RTX 3070]"
+		if open, end := strings.LastIndex(device, "["), strings.LastIndex(device, "]"); open >= 0 && end > open {
+			device = device[open+1 : end]
+		}
+		gpus = append(gpus, GPUInfo{
+			Name:   strings.TrimSpace(vendor + " " + device),
+			Vendor: vendor,
+			BusID:  strings.Fields(line)[0],
+		})
+	}
+	return gpus
+}
+
+// linuxGPUs lists display controllers with lspci, adding the driver and, for
+// amdgpu, VRAM and load from sysfs
+func linuxGPUs(ctx context.Context, pciDir string) ([]GPUInfo, error) {
+	output, err := exec.CommandContext(ctx, "lspci", "-Dmm").Output()
+	if err != nil {
+		return sysfsGPUs(pciDir), nil
+	}
+
+	gpus := ParseLspciGPUs(string(output))
+	for i := range gpus {
+		readSysfsGPU(filepath.Join(pciDir, gpus[i].BusID), &gpus[i])
+	}
+	return gpus, nil
+}
+
+// pciDisplayClass is the PCI class prefix of display controllers
+const pciDisplayClass = "0x03"
+
+// pciVendors names the PCI vendor IDs of the GPUs sysfs can report without lspci
+var pciVendors = map[string]string{"0x10de": "NVIDIA", "0x1002": "AMD", "0x8086": "Intel", "0x1af4": "Red Hat", "0x15ad": "VMware"}
+
+// sysfsGPUs finds display controllers in sysfs when lspci isn't installed
+func sysfsGPUs(pciDir string) []GPUInfo {
+	devices, err := ioutil.ReadDir(pciDir)
+	if err != nil {
+		return nil
+	}
+
+	gpus := make([]GPUInfo, 0)
+	for _, device := range devices {
+		dir := filepath.Join(pciDir, device.Name())
+		if !strings.HasPrefix(sysfsValue(dir, "class"), pciDisplayClass) {
+			continue
+		}
+		vendor := pciVendors[sysfsValue(dir, "vendor")]
+		if vendor == "" {
+			vendor = sysfsValue(dir, "vendor")
+		}
+		gpu := GPUInfo{
+			Name:   fmt.Sprintf("%s device %s", vendor, strings.TrimPrefix(sysfsValue(dir, "device"), "0x")),
+			Vendor: vendor,
+			BusID:  device.Name(),
+		}
+		readSysfsGPU(dir, &gpu)
+		gpus = append(gpus, gpu)
+	}
+	return gpus
+}
+
+// readSysfsGPU fills in what the kernel driver exposes about a PCI device
+func readSysfsGPU(dir string, gpu *GPUInfo) {
+	if driver, err := os.Readlink(filepath.Join(dir, "driver")); err == nil {
+		gpu.Driver = filepath.Base(driver)
+	}
+	if version := sysfsValue(dir, "driver/module/version"); version != "" {
+		gpu.Version = version
+	}
+	if vram := sysfsNumber(dir, "mem_info_vram_total"); vram > 0 {
+		gpu.VRAM = uint64(vram)
+		gpu.VRAMUsed = uint64(sysfsNumber(dir, "mem_info_vram_used"))
+	}
+	if busy, err := strconv.ParseFloat(sysfsValue(dir, "gpu_busy_percent"), 64); err == nil {
+		gpu.Utilization = &busy
+	}
+}
+
+// ParseNvidiaSmi reads `nvidia-smi --query-gpu=pci.bus_id,name,memory.total,
+// memory.used,driver_version,utilization.gpu --format=csv,noheader,nounits`.
+// Memory is in MiB; values the GPU doesn't support read [N/A].
+func ParseNvidiaSmi(output string) []GPUInfo {
+	gpus := make([]GPUInfo, 0)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(line, ",")
+		if len(fields) < 6 {
+			continue
+		}
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+
+		gpu := GPUInfo{
+			Name:    fields[1],
+			Vendor:  "NVIDIA",
+			BusID:   fields[0],
+			Driver:  "nvidia",
+			Version: fields[4],
+		}
+		if total, err := strconv.ParseUint(fields[2], 10, 64); err == nil {
+			gpu.VRAM = total << 20
+		}
+		if used, err := strconv.ParseUint(fields[3], 10, 64); err == nil {
+			gpu.VRAMUsed = used << 20
+		}
+		if utilization, err := strconv.ParseFloat(fields[5], 64); err == nil {
+			gpu.Utilization = &utilization
+		}
+		gpus = append(gpus, gpu)
+	}
+	return gpus
+}
+
+// normalizeBusID reduces a PCI address to bus:device.function, since nvidia-smi
+// pads the domain to eight digits and Windows has no bus IDs at all
+func normalizeBusID(id string) string {
+	id = strings.ToLower(id)
+	if parts := strings.Split(id, ":"); len(parts) == 3 {
+		return parts[1] + ":" + parts[2]
+	}
+	return id
+}
+
+// MergeNvidiaGPUs adds nvidia-smi's details to the matching adapters, by bus ID
+// or, on Windows, by name. Unmatched NVIDIA GPUs are appended.
+func MergeNvidiaGPUs(gpus, nvidia []GPUInfo) []GPUInfo {
+	for _, card := range nvidia {
+		matched := false
+		for i := range gpus {
+			sameBus := gpus[i].BusID != "" && normalizeBusID(gpus[i].BusID) == normalizeBusID(card.BusID)
+			if !sameBus && !(gpus[i].BusID == "" && strings.EqualFold(gpus[i].Name, card.Name)) {
+				continue
+			}
+			gpus[i].Name = card.Name
+			gpus[i].Vendor = card.Vendor
+			gpus[i].Version = card.Version
+			gpus[i].VRAM = card.VRAM
+			gpus[i].VRAMUsed = card.VRAMUsed
+			gpus[i].Utilization = card.Utilization
+			if gpus[i].Driver == "" {
+				gpus[i].Driver = card.Driver
+			}
+			matched = true
+			break
+		}
+		if !matched {
+			gpus = append(gpus, card)
+		}
+	}
+	return gpus
+}
+
+// ParseWin32VideoControllers reads the adapters exported by windowsGPUs.
+// AdapterRAM is a 32-bit field that tops out at 4 GB, so the driver's
+// qwMemorySize registry value is preferred when present.
+func ParseWin32VideoControllers(data []byte) ([]GPUInfo, error) {
+	var controllers []struct {
+		Name          string `json:"Name"`
+		Vendor        string `json:"AdapterCompatibility"`
+		AdapterRAM    uint64 `json:"AdapterRAM"`
+		MemorySize    uint64 `json:"MemorySize"`
+		DriverVersion string `json:"DriverVersion"`
+	}
+	if err := json.Unmarshal(data, &controllers); err != nil {
+		return nil, fmt.Errorf("failed to parse video controllers: %w", err)
+	}
+
+	gpus := make([]GPUInfo, 0, len(controllers))
+	for _, controller := range controllers {
+		gpu := GPUInfo{
+			Name:    controller.Name,
+			Vendor:  shortVendor(controller.Vendor),
+			Version: controller.DriverVersion,
+			VRAM:    controller.AdapterRAM,
+		}
+		if controller.MemorySize > 0 {
+			gpu.VRAM = controller.MemorySize
+		}
+		gpus = append(gpus, gpu)
+	}
+	return gpus, nil
+}
+
+// windowsGPUs reads Win32_VideoController, looking up each adapter's full memory
+// size in the display class key of the registry
+func windowsGPUs(ctx context.Context) ([]GPUInfo, error) {
+	script := `$sizes = @{}
+		Get-ChildItem 'HKLM:\SYSTEM\CurrentControlSet\Control\Class\{4d36e968-e325-11ce-bfc1-08002be10318}' -ErrorAction SilentlyContinue |
+			ForEach-Object {
+				$key = Get-ItemProperty $_.PSPath -ErrorAction SilentlyContinue
+				if ($key.DriverDesc -and $key.'HardwareInformation.qwMemorySize') { $sizes[$key.DriverDesc] = [uint64]$key.'HardwareInformation.qwMemorySize' }
+			}
+		ConvertTo-Json -InputObject @(Get-CimInstance Win32_VideoController | ForEach-Object {
+			[PSCustomObject]@{
+				Name = $_.Name
+				AdapterCompatibility = $_.AdapterCompatibility
+				AdapterRAM = [uint64]$_.AdapterRAM
+				MemorySize = if ($sizes.ContainsKey($_.Name)) { $sizes[$_.Name] } else { 0 }
+				DriverVersion = $_.DriverVersion
+			}
+		})`
+	output, err := exec.CommandContext(ctx, "powershell", "-NoProfile", "-Command", script).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query video controllers: %w", err)
+	}
+	return ParseWin32VideoControllers(output)
+}
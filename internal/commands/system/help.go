@@ -63,6 +63,8 @@ func (h *HelpCommand) showGeneralHelp(startTime time.Time) (*commands.Result, er
 	}
 
 	output.WriteString("\nUse 'help <command>' for detailed information about a specific command.\n")
+	output.WriteString("Chain commands with && (run next on success), || (on failure) and ; (always).\n")
+	output.WriteString("End a command with & to run it in the background; see jobs, fg and kill.\n")
 
 	return &commands.Result{
 		Output:   output.String(),
@@ -96,12 +98,24 @@ func (h *HelpCommand) showCommandHelp(commandName string, startTime time.Time) (
 	}
 
 	if cmd.RequiresElevation() {
-		output.WriteString("Privileges:  Requires administrator/root privileges\n")
+		if _, partial := cmd.(commands.ElevationPolicy); partial {
+			output.WriteString("Privileges:  Some actions require administrator/root privileges\n")
+		} else {
+			output.WriteString("Privileges:  Requires administrator/root privileges\n")
+		}
+	}
+
+	if formatter, ok := cmd.(commands.OutputFormatter); ok {
+		formats := []string{string(commands.FormatText)}
+		for _, format := range formatter.OutputFormats() {
+			formats = append(formats, string(format))
+		}
+		output.WriteString(fmt.Sprintf("Output:      %s via --output-format; --quiet prints results only\n", strings.Join(formats, ", ")))
 	}
 
 	// Add detailed help for specific commands
 	output.WriteString("\n")
-	output.WriteString(h.getDetailedHelp(commandName))
+	output.WriteString(detailedHelp(commandName))
 
 	return &commands.Result{
 		Output:   output.String(),
@@ -110,22 +124,72 @@ func (h *HelpCommand) showCommandHelp(commandName string, startTime time.Time) (
 	}, nil
 }
 
-// getDetailedHelp returns detailed help text for specific commands
-func (h *HelpCommand) getDetailedHelp(commandName string) string {
+// noDetailedHelp is shown for commands without detailed help
+const noDetailedHelp = "No additional help available for this command.\n"
+
+// detailedHelp returns detailed help text for specific commands
+func detailedHelp(commandName string) string {
 	switch commandName {
+	case "trace":
+		return `Detailed Options:
+  -p, --pid <pid>           Attach to a running process instead of starting a program
+  -e, --events <events>     Kinds to show (default: file,net): open, read, write, close,
+                            exec, delete, rename, connect, accept, bind, or the groups
+                            file (open, exec, delete, rename), io (read, write), net
+                            (connect, accept, bind) and all
+  --path <pattern>          Only events whose file or address contains the text, or
+                            matches the glob, such as '*.conf'
+  --failed                  Only calls that failed, such as opens giving ENOENT
+  --lib                     Trace C library calls with ltrace instead of system calls
+  -c, --count <n>           Stop after n events
+  -t, --duration <dur>      Stop after this long, for example 30s
+  --interval <seconds>      Seconds between samples of open files on Windows (default: 1)
+  --json                    Stream the events as JSON lines
+  <program> [args...]       The program to start and trace
+
+Each event is printed as it happens with its time, process, kind and the file
+or address involved; failed calls show their error in red. Child processes are
+followed. A summary with the busiest files and addresses is printed on stderr
+at the end, and a started program's exit code becomes trace's.
+
+On Linux trace drives strace, or ltrace with --lib. Starting a program needs
+no privileges, but attaching to one you didn't start usually needs sudo
+(kernel.yama.ptrace_scope). On Windows it samples the process's open files
+with Sysinternals Handle and its connections with netstat, so reads and
+writes aren't visible and short-lived files can be missed. 'capabilities'
+shows whether tracing works here and what to install if not.
+
+Examples:
+  trace -p 1234                         # Files and connections of a running process
+  trace --failed -e open curl example.com
+  trace -e net -c 20 -p 1234
+  trace --path '*.conf' nginx -t
+  trace --json -t 30s -p 1234 > events.jsonl
+`
+
 	case "sniff":
 		return `Detailed Options:
-  -i, --interface <name>    Network interface to monitor (default: eth0)
+  -i, --interface <name>    Interface to monitor by name, index or address (default: first one up)
   -c, --count <number>      Number of packets to capture (default: 10)
   -p, --protocol <proto>    Filter by protocol (TCP, UDP, HTTP, HTTPS, DNS, SSH, FTP, etc.)
+  --proto <proto>           Same as --protocol
+  --host <ip>               Filter by IP address (matches source or destination)
   -s, --source <ip>         Filter by source IP address
   -d, --dest <ip>           Filter by destination IP address
   --port <port>             Filter by port number (matches source or destination)
+  -f, --filter <bpf>        Raw BPF capture filter, combined with the options above
   -v, --verbose             Show detailed packet information
   --hex                     Display hexadecimal payload dump
-  --save <file>             Save capture to file
+  --save <file>             Save capture to a pcap file
+  --rotate-size <size>      Start a new numbered file (file-001.pcap, ...) at this size
+  --rotate-time <dur>       Start a new numbered file after this long, e.g. 15m
+  --keep <n>                Keep only the last n rotated files
+  --jsonl                   Write each packet to stdout as a line of JSON instead of the report
+  --jsonl-file <file>       Append the JSON lines to a file, keeping the report
   --continuous              Continuous capture mode
   -t, --timeout <seconds>   Capture timeout for continuous mode
+  --summary                 Show a live table of flows by bytes instead of packets
+  --top <n>                 Flows the summary lists (default: 20, 0 for all)
 
 Examples:
   sniff -c 10                           # Capture 10 packets
@@ -133,6 +197,10 @@ Examples:
   sniff -s 192.168.1.100 --hex         # Capture from specific IP with hex dump
   sniff --port 80 -c 5                 # Capture packets on port 80
   sniff -p TCP -d 8.8.8.8 --save cap.pcap  # Capture TCP to 8.8.8.8 and save
+  sniff eth0 --summary --top 5 -c 500  # Traffic volume of the 5 busiest flows
+  sniff -c 100000 --save cap.pcap --rotate-size 100M --keep 5  # Bounded continuous capture
+  sniff -f "tcp port 443 or udp port 53"  # Raw BPF filter
+  sniff eth0 -c 100000 --jsonl | my-siem-forwarder  # Feed packets to a log pipeline
 `
 
 	case "wget":
@@ -201,6 +269,23 @@ Examples:
   portscan example.com --top-ports 100 # Scan top 100 ports
 `
 
+	case "wait-port":
+		return `Detailed Options:
+  <host:port>               Port to wait for; :port means localhost
+  -t, --timeout <duration>  Give up after this long, 0 to wait indefinitely (default: 30s)
+  -i, --interval <duration> Time between connection attempts (default: 1s)
+  --invert                  Wait for the port to stop accepting connections instead
+
+wait-port exits 0 once the port is in the wanted state and 1 when the timeout
+passes, so the next line of a script only runs when the service is there.
+Ctrl+C stops waiting at once.
+
+Examples:
+  wait-port localhost:5432 --timeout 60s      # Wait for PostgreSQL to start
+  wait-port db01:3306 -i 500ms && server health
+  wait-port :8080 --invert --timeout 10s      # Wait for the old server to stop
+`
+
 	case "ping":
 		return `Detailed Options:
   <host>                    Target host to ping
@@ -266,13 +351,190 @@ Examples:
   killtask 1234 5678 notepad # Terminate multiple processes
 `
 
+	case "retry":
+		return `Detailed Options:
+  -n, --attempts <n>        Give up after this many runs (default: 3)
+  -d, --delay <duration>    Wait between attempts (default: 1s)
+  -b, --backoff <factor>    Multiply the delay by this after each attempt (default: 1)
+  --max-delay <duration>    Never wait longer than this between attempts
+  --until-success           Stop at the first run that exits 0 (the default)
+  --until-failure           Stop at the first run that fails instead
+  <command...>              The SuperShell command to run; its own flags follow it
+
+The output and exit code are those of the last attempt. Each retry and the
+final outcome are reported on stderr, and Ctrl+C stops waiting at once.
+
+Examples:
+  retry --attempts 10 --delay 3s ping -c 1 backup-host && fastcp-send ./data backup-host
+  retry -n 5 -d 2s --backoff 2 --max-delay 30s wget https://example.com/big.iso
+  retry --until-failure -n 100 -d 0s fastcp-verify s3://backups   # Hunt a flaky failure
+`
+
+	case "each":
+		return `Detailed Options:
+  -f, --file <file>         Read the items from file instead of stdin
+  -s, --split <chars>       Split the input on any of these characters instead of newlines
+  -n, --batch <n>           Pass up to n items to each run (default: 1)
+  -P, --parallel <n>        Run up to n commands at the same time (default: 1)
+  --fail-fast               Start no more commands once one has failed
+  <command... {}>           The SuperShell command to run; {} is replaced by the item
+
+A {} on its own becomes the items of a batch as separate arguments, so an item
+with spaces stays one argument. Without {} the items are added at the end, as
+xargs does. The output of the runs comes back in input order even when they
+run in parallel; failures and a summary are reported on stderr, and each exits
+non-zero when any run failed. Ctrl+C stops starting new runs.
+
+Examples:
+  supershell -c each ping -c 1 {} < hosts.txt
+  each --file hosts.txt --parallel 8 ping -c 1 {}
+  each -f logs.txt --batch 10 cat {}
+  each -f names.txt --split , --fail-fast mkdir backups/{}
+`
+
+	case "runas":
+		return `Detailed Options:
+  --cred <name>             Windows: take the user's password from this stored credential
+  <user>                    The user to run as: name, DOMAIN\name or name@domain
+  <command...>              The SuperShell command to run as them
+
+The command runs in a new SuperShell started as the user, in the current
+directory, with its output streamed back; runas exits with its exit code.
+Where priv elevate raises privileges, runas switches to another account, for
+example to see what a service account can reach.
+
+On Linux and macOS the switch goes through sudo -u, which asks for your own
+password when its policy needs one. On Windows the user is logged on through
+CreateProcessWithLogonW with their password, asked for unless --cred names a
+credential holding it. A wrong password, a locked or disabled account and a
+sudo policy that doesn't allow the switch are reported as such.
+
+Examples:
+  runas svc-backup sysinfo
+  runas www-data ls /var/www
+  runas --cred svc-sql CORP\svc-sql sysinfo
+  cred add svc-sql --type ssh username=svc-sql
+`
+
+	case "source":
+		return `Detailed Options:
+  --safe                    Block the guarded commands, whatever security.scripts.guard says
+  --confirm                 Ask before running each guarded command
+  <script.ss>               The script to run
+
+Each line is run as a SuperShell command, as if it had been typed at the
+prompt; blank lines and lines starting with # are skipped, and && || ; work
+within a line. A failing line doesn't stop the script, but source exits
+non-zero when any line failed.
+
+The guarded commands are listed in security.scripts.guarded_commands: by
+default rm, rmdir, kill, killtask, firewall disable, user disable, user
+passwd, server session kill and priv elevate. An entry is a command, or a
+command and its subcommand. security.scripts.guard sets what happens to them
+without --safe or --confirm: run (the default), confirm or block. The guard
+also covers commands run through each, retry and other scripts, and every
+blocked command is listed on stderr when the script ends.
+
+The interactive shell sources ~/.supershellrc.ss at startup when it exists
+(shell.rc_file), then the .ss scripts in shell.startup_dir when that is set.
+Start it with --no-rc to skip them; commands run with -c never run them.
+
+Examples:
+  source automation.ss
+  source --safe downloaded.ss            # Run a script from elsewhere without rm or kill
+  source --confirm cleanup.ss            # Ask before each destructive command
+`
+
+	case "diag":
+		return `Detailed Options:
+  terminal                  Detect the terminal type, color support, UTF-8 and window size
+  --output-format json      Print what was detected as JSON
+
+diag terminal ends with a rendering test of colors, box drawing and emoji.
+If those lines look garbled, the terminal or its font can't show SuperShell's
+output: use a UTF-8 locale, or Windows Terminal (or chcp 65001) on Windows.
+Colors are turned off automatically for terminals that don't support them,
+and setting NO_COLOR turns them off anywhere.
+
+Examples:
+  diag terminal                         # Check the current terminal
+  diag terminal --output-format json    # Detected settings for a bug report
+`
+
+	case "json":
+		return `Detailed Options:
+  merge <base> <override>...  Apply each override to base as a JSON merge patch and print the result
+  -o, --output <file>         Write the merged document to file instead
+  get <file> <path>           Print the value at path; strings are printed without quotes
+  set <file> <path> <value>   Store value at path, creating missing objects, and rewrite the file
+  --string                    Store the value as a string even if it looks like a number or JSON
+
+Paths are keys separated by dots, with array indexes as [n] or .n, such as
+servers[0].host; an index one past the end appends. A set value is parsed as
+JSON when it can be, so 8080, true and '{"a":1}' keep their types. In a merge
+objects are merged key by key, null removes a key and arrays are replaced.
+Files keep their key order and indentation, and are only replaced once the
+new document has been checked to be valid JSON.
+
+Examples:
+  json merge base.json local.json -o settings.json
+  json get stats.json top_commands[0].command
+  json set config.json prompt.theme powerline
+  json set servers.json servers[2] '{"host": "db01", "port": 5432}'
+`
+
+	case "highlight":
+		return `Detailed Options:
+  -i, --ignore-case         Match the patterns regardless of case
+  -F, --fixed-strings       Treat the patterns as plain text instead of regular expressions
+  --line                    Color the whole line a pattern matches instead of the match
+  -f, --file <file>         Read the input from file instead of stdin
+  <pattern[:color]>...      A regular expression and the color of its matches
+
+Every line is passed through, matching or not, as soon as it is read, so
+highlight keeps up with tail -f. A color is one of black, red, green, yellow,
+blue, magenta, cyan, white or gray, optionally joined with bold, underline or
+a background such as bgred: red+bold, black+bgyellow. It follows the last
+colon, so "\d+:\d+:cyan" colors times; a pattern without a color is marked
+in black on yellow. Where matches overlap the earlier pattern wins. With
+colors turned off, as with NO_COLOR, the input passes through unchanged.
+
+Examples:
+  tail -f app.log | supershell -c highlight ERROR:red+bold WARN:yellow
+  highlight -f build.log -i "error|failed:red" "warning:yellow"
+  highlight --line -f app.log "level=error:red" "level=debug:gray"
+`
+
+	case "z":
+		return `Detailed Options:
+  -l, --list                List the matching directories with their scores, best last
+  <part>...                 Fragments of the path, matched in order and ignoring case
+
+Every directory cd enters is remembered in ~/.supershell/jumps.json with how
+often and how recently it was visited. z changes to the highest-scoring
+directory whose path contains all the parts, so 'z proj api' finds
+~/work/projects/billing-api once you've been there. Visits in the last hour
+count four times, in the last day twice, and older ones less; directories
+that no longer exist are forgotten. Without parts z lists everything it knows.
+
+Tab after z completes the typed parts to the matching directories, best
+first. When cd is given a directory that doesn't exist it suggests a sibling
+with a similar name, or the best z match for it.
+
+Examples:
+  z docs                               # Jump to the best match for "docs"
+  z proj api                           # Both parts, in that order
+  z -l src                             # Show candidates and scores
+`
+
 	case "lookup":
 		return `Detailed Options:
   -m, --menu                Show interactive dropdown-style menu
   -s, --similar             Show similar commands using fuzzy matching
   -c, --categories          Show all command categories
   -t, --task <task>         Get task-based command suggestions
-  [query]                   Search term for command lookup
+  -e, --examples            Show the first example of each match
+  [query]                   Search names, descriptions and help text
 
 Examples:
   lookup -m                 # Interactive menu with dropdown navigation
@@ -284,6 +546,7 @@ Examples:
   lookup -s net             # Find similar commands to 'net'
   lookup copy               # Find commands related to copying
   lookup -t security        # Get security-related commands
+  lookup port --examples    # Find port commands and how to use them
 
 Task Categories:
   network, file, system, security, monitoring
@@ -556,6 +819,12 @@ Export & Backup:
   history export csv        # Export as CSV for spreadsheet analysis
   history export txt        # Export as human-readable text
 
+Export as a Script:
+  history export setup.ss                        # Last 20 commands as a .ss script
+  history export --from 100 --to 120 setup.ss    # Entries 100-120, as numbered by history
+  history export --last 50 --skip-failed fix.ss  # Recent commands that succeeded
+  history export --from 100 -                    # Print the script instead of writing it
+
 Advanced Features:
   • Automatic Tracking - All commands automatically recorded with metadata
   • Smart Categorization - Commands auto-classified (filesystem, network, development, etc.)
@@ -585,6 +854,6 @@ The Smart History system transforms your command history from a simple list into
 `
 
 	default:
-		return "No additional help available for this command.\n"
+		return noDetailedHelp
 	}
 }
@@ -70,7 +70,7 @@ func (h *HelpHTMLCommand) Execute(ctx context.Context, args *commands.Arguments)
 	fileSize := fileInfo.Size()
 
 	output.WriteString(color.New(color.FgGreen).Sprint("✅ HTML documentation generated successfully\n"))
-	output.WriteString(fmt.Sprintf("📊 File size: %d bytes\n", fileSize))
+	output.WriteString(fmt.Sprintf("📊 File size: %s\n", commands.HumanizeBytes(fileSize)))
 	output.WriteString(fmt.Sprintf("📋 Commands documented: %d\n", len(h.registry.GetAllCommands())))
 	output.WriteString("───────────────────────────────────────────────────────────────\n")
 	output.WriteString("💡 Open the file in your web browser to view the documentation\n")
@@ -599,6 +599,8 @@ func (h *HelpHTMLCommand) generateHTML() string {
                     <a href="#rm" class="nav-item" data-category="files"><span class="emoji">🗑️</span>rm</a>
                     <a href="#mkdir" class="nav-item" data-category="files"><span class="emoji">📁</span>mkdir</a>
                     <a href="#rmdir" class="nav-item" data-category="files"><span class="emoji">🗂️</span>rmdir</a>
+                    <a href="#undo" class="nav-item" data-category="files"><span class="emoji">↩️</span>undo</a>
+                    <a href="#browse" class="nav-item" data-category="files"><span class="emoji">🧭</span>browse</a>
                     <a href="#pwd" class="nav-item" data-category="files"><span class="emoji">📍</span>pwd</a>
                     <a href="#cd" class="nav-item" data-category="files"><span class="emoji">📂</span>cd</a>
                 </div>
@@ -628,6 +630,7 @@ func (h *HelpHTMLCommand) generateHTML() string {
                     <a href="#fastcp-backup" class="nav-item" data-category="fastcp"><span class="emoji">💾</span>fastcp-backup</a>
                     <a href="#fastcp-restore" class="nav-item" data-category="fastcp"><span class="emoji">♻️</span>fastcp-restore</a>
                     <a href="#fastcp-dedup" class="nav-item" data-category="fastcp"><span class="emoji">🔄</span>fastcp-dedup</a>
+                    <a href="#fastcp-key" class="nav-item" data-category="fastcp"><span class="emoji">🔑</span>fastcp-key</a>
                 </div>
             </div>
         </nav>
@@ -676,7 +679,7 @@ func (h *HelpHTMLCommand) generateHTML() string {
 			cat := categories["Performance Monitoring"]
 			cat.Commands = append(cat.Commands, cmd)
 			categories["Performance Monitoring"] = cat
-		case name == "server" || name == "sysinfo" || name == "killtask" || name == "winupdate":
+		case name == "server" || name == "sysinfo" || name == "killtask" || name == "jobs" || name == "fg" || name == "kill" || name == "retry" || name == "each" || name == "source" || name == "winupdate":
 			cat := categories["Server Management"]
 			cat.Commands = append(cat.Commands, cmd)
 			categories["Server Management"] = cat
@@ -919,7 +922,7 @@ func (h *HelpHTMLCommand) generateHTML() string {
 
 // isSystemCommand checks if a command is a system command
 func (h *HelpHTMLCommand) isSystemCommand(name string) bool {
-	systemCommands := []string{"whoami", "hostname", "ver", "clear", "echo"}
+	systemCommands := []string{"whoami", "hostname", "ver", "diag", "clear", "echo"}
 	for _, cmd := range systemCommands {
 		if cmd == name {
 			return true
@@ -930,7 +933,7 @@ func (h *HelpHTMLCommand) isSystemCommand(name string) bool {
 
 // isFilesystemCommand checks if a command is a filesystem command
 func (h *HelpHTMLCommand) isFilesystemCommand(name string) bool {
-	fsCommands := []string{"pwd", "ls", "dir", "echo", "cd", "cat", "mkdir", "rm", "rmdir", "cp", "mv"}
+	fsCommands := []string{"pwd", "ls", "dir", "echo", "cd", "z", "cat", "mkdir", "rm", "rmdir", "cp", "mv", "undo", "browse", "watchdir", "json", "highlight"}
 	for _, cmd := range fsCommands {
 		if cmd == name {
 			return true
@@ -941,7 +944,7 @@ func (h *HelpHTMLCommand) isFilesystemCommand(name string) bool {
 
 // isNetworkCommand checks if a command is a network command
 func (h *HelpHTMLCommand) isNetworkCommand(name string) bool {
-	networkCommands := []string{"ping", "tracert", "nslookup", "netstat", "portscan", "sniff", "wget", "arp", "route", "speedtest", "ipconfig", "netdiscover"}
+	networkCommands := []string{"ping", "tracert", "nslookup", "netstat", "top-connections", "portscan", "wait-port", "sniff", "trace", "replay", "capstats", "wget", "arp", "route", "speedtest", "ipconfig", "interfaces", "capabilities", "netdiscover"}
 	for _, cmd := range networkCommands {
 		if cmd == name {
 			return true
@@ -963,7 +966,7 @@ func (h *HelpHTMLCommand) isHelpCommand(name string) bool {
 
 // isFastCPCommand checks if a command is a FastCP command
 func (h *HelpHTMLCommand) isFastCPCommand(name string) bool {
-	fastcpCommands := []string{"fastcp-send", "fastcp-recv", "fastcp-backup", "fastcp-restore", "fastcp-dedup"}
+	fastcpCommands := []string{"fastcp-send", "fastcp-recv", "fastcp-backup", "fastcp-restore", "fastcp-dedup", "fastcp-key"}
 	for _, cmd := range fastcpCommands {
 		if cmd == name {
 			return true
@@ -1886,9 +1889,13 @@ func (h *HelpHTMLCommand) getDetailedHTMLHelp(commandName string) string {
             <span class="option-flag">-m, --menu</span>
             <span class="option-description">Show interactive menu for command exploration</span>
         </div>
+        <div class="option-item">
+            <span class="option-flag">-e, --examples</span>
+            <span class="option-description">Show the first example of each match</span>
+        </div>
         <div class="option-item">
             <span class="option-flag">[query]</span>
-            <span class="option-description">Search term for command lookup</span>
+            <span class="option-description">Search names, descriptions and help text</span>
         </div>
     </div>
     
@@ -0,0 +1,228 @@
+package system
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+
+	"github.com/fatih/color"
+)
+
+// highlightColors are the names a highlight color is built from, joined by +
+// as in red+bold or black+bgyellow
+var highlightColors = map[string]color.Attribute{
+	"black": color.FgBlack, "red": color.FgRed, "green": color.FgGreen, "yellow": color.FgYellow,
+	"blue": color.FgBlue, "magenta": color.FgMagenta, "cyan": color.FgCyan, "white": color.FgWhite,
+	"gray": color.FgHiBlack, "grey": color.FgHiBlack,
+	"bgblack": color.BgBlack, "bgred": color.BgRed, "bggreen": color.BgGreen, "bgyellow": color.BgYellow,
+	"bgblue": color.BgBlue, "bgmagenta": color.BgMagenta, "bgcyan": color.BgCyan, "bgwhite": color.BgWhite,
+	"bold": color.Bold, "underline": color.Underline,
+}
+
+// defaultHighlight marks matches of a pattern given without a color
+var defaultHighlight = []color.Attribute{color.BgYellow, color.FgBlack}
+
+// HighlightCommand copies its input to its output, coloring what matches the
+// given patterns, so logs stay whole but their errors stand out:
+// tail -f app.log | supershell -c highlight ERROR:red WARN:yellow.
+type HighlightCommand struct {
+	*commands.BaseCommand
+	flags *commands.FlagSet
+}
+
+// NewHighlightCommand creates a new highlight command
+func NewHighlightCommand() *HighlightCommand {
+	usage := "highlight [-i] [-F] [--line] [--file <file>] <pattern[:color]>..."
+	return &HighlightCommand{
+		BaseCommand: commands.NewBaseCommand(
+			"highlight",
+			"Color the parts of the input matching patterns, passing every line through",
+			usage,
+			[]string{"windows", "linux", "darwin"},
+			false,
+		),
+		flags: commands.NewFlagSet("highlight", usage,
+			commands.FlagSpec{Name: "ignore-case", Short: "i", Help: "Match the patterns regardless of case"},
+			commands.FlagSpec{Name: "fixed-strings", Short: "F", Help: "Treat the patterns as plain text instead of regular expressions"},
+			commands.FlagSpec{Name: "line", Help: "Color the whole line a pattern matches instead of the match"},
+			commands.FlagSpec{Name: "file", Short: "f", Kind: commands.StringFlag, Value: "file", Help: "Read the input from file instead of stdin"},
+		),
+	}
+}
+
+// FlagSet returns the options highlight accepts
+func (h *HighlightCommand) FlagSet() *commands.FlagSet {
+	return h.flags
+}
+
+// Execute copies the input to the output a line at a time as it arrives, so
+// it keeps up with a follow. With colors turned off the input passes through
+// unchanged.
+func (h *HighlightCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	startTime := time.Now()
+
+	flags, result := h.flags.ParseArguments(args, startTime)
+	if result != nil {
+		return result, nil
+	}
+	if len(flags.Args()) == 0 {
+		return commands.UsageResult(h, startTime, "expected at least one pattern"), nil
+	}
+	highlighter, err := NewHighlighter(flags.Args(), flags.Bool("ignore-case"), flags.Bool("fixed-strings"), flags.Bool("line"))
+	if err != nil {
+		return commands.UsageResult(h, startTime, "%v", err), nil
+	}
+
+	input := commands.InputReader(ctx)
+	if flags.Changed("file") {
+		file, err := os.Open(flags.String("file"))
+		if err != nil {
+			return commands.ErrorResult("", err, startTime), nil
+		}
+		defer file.Close()
+		input = file
+	} else if commands.InputIsTerminal(ctx) {
+		return commands.UsageResult(h, startTime, "the input is read from a pipe or --file, and stdin is a terminal"), nil
+	}
+
+	if err := highlighter.Copy(ctx, commands.OutputWriter(ctx), input); err != nil {
+		return commands.ErrorResult("", fmt.Errorf("failed to read the input: %v", err), startTime), nil
+	}
+	return &commands.Result{
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}, nil
+}
+
+// highlightRule is one pattern and the color of what it matches
+type highlightRule struct {
+	pattern *regexp.Regexp
+	color   *color.Color
+}
+
+// Highlighter colors the matches of a list of patterns in lines of text
+type Highlighter struct {
+	rules     []highlightRule
+	wholeLine bool
+}
+
+// NewHighlighter builds a highlighter from pattern[:color] specs. The color
+// follows the last colon, so patterns may contain colons of their own; a spec
+// whose last part is not a color is all pattern and gets the default color.
+// Where matches overlap, the earlier pattern wins.
+func NewHighlighter(specs []string, ignoreCase, fixed, wholeLine bool) (*Highlighter, error) {
+	h := &Highlighter{wholeLine: wholeLine}
+	for _, spec := range specs {
+		pattern, attributes := spec, defaultHighlight
+		if i := strings.LastIndex(spec, ":"); i > 0 {
+			if parsed, ok := parseHighlightColor(spec[i+1:]); ok {
+				pattern, attributes = spec[:i], parsed
+			}
+		}
+		if pattern == "" {
+			return nil, fmt.Errorf("empty pattern in '%s'", spec)
+		}
+		if fixed {
+			pattern = regexp.QuoteMeta(pattern)
+		}
+		if ignoreCase {
+			pattern = "(?i)" + pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern '%s': %v", spec, err)
+		}
+		h.rules = append(h.rules, highlightRule{pattern: re, color: color.New(attributes...)})
+	}
+	return h, nil
+}
+
+// parseHighlightColor parses a color such as red, red+bold or black+bgyellow
+func parseHighlightColor(name string) ([]color.Attribute, bool) {
+	var attributes []color.Attribute
+	for _, part := range strings.Split(strings.ToLower(name), "+") {
+		attribute, ok := highlightColors[strings.Replace(part, "-", "", -1)]
+		if !ok {
+			return nil, false
+		}
+		attributes = append(attributes, attribute)
+	}
+	return attributes, true
+}
+
+// Line returns line with its matches colored, or the whole line in the color
+// of the first pattern matching it when coloring whole lines
+func (h *Highlighter) Line(line string) string {
+	if h.wholeLine {
+		for _, rule := range h.rules {
+			if rule.pattern.MatchString(line) {
+				return rule.color.Sprint(line)
+			}
+		}
+		return line
+	}
+
+	// Each byte takes the color of the first pattern matching over it
+	owners := make([]int, len(line))
+	matched := false
+	for i, rule := range h.rules {
+		for _, span := range rule.pattern.FindAllStringIndex(line, -1) {
+			for at := span[0]; at < span[1]; at++ {
+				if owners[at] == 0 {
+					owners[at] = i + 1
+					matched = true
+				}
+			}
+		}
+	}
+	if !matched {
+		return line
+	}
+
+	var result strings.Builder
+	for start := 0; start < len(line); {
+		end := start + 1
+		for end < len(line) && owners[end] == owners[start] {
+			end++
+		}
+		if owner := owners[start]; owner > 0 {
+			result.WriteString(h.rules[owner-1].color.Sprint(line[start:end]))
+		} else {
+			result.WriteString(line[start:end])
+		}
+		start = end
+	}
+	return result.String()
+}
+
+// Copy writes each line of r to w as it is read, highlighted, until r ends or
+// ctx is cancelled. With colors turned off the lines are copied unchanged.
+func (h *Highlighter) Copy(ctx context.Context, w io.Writer, r io.Reader) error {
+	reader := bufio.NewReader(r)
+	for ctx.Err() == nil {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			if !color.NoColor {
+				text := strings.TrimRight(line, "\r\n")
+				line = h.Line(text) + line[len(text):]
+			}
+			if _, writeErr := io.WriteString(w, line); writeErr != nil {
+				return writeErr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
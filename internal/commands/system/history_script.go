@@ -0,0 +1,189 @@
+package system
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+
+	"github.com/fatih/color"
+)
+
+// historyScriptUsage is the usage of history export when it writes a script
+const historyScriptUsage = "history export [--from ID] [--to ID] [--last N] [--skip-failed] [--force] <file.ss|->"
+
+// historyScriptFlags are the flags of history export
+var historyScriptFlags = commands.NewFlagSet("history", historyScriptUsage,
+	commands.FlagSpec{Name: "from", Kind: commands.IntFlag, Value: "ID", Help: "First history entry to export"},
+	commands.FlagSpec{Name: "to", Kind: commands.IntFlag, Value: "ID", Help: "Last history entry to export"},
+	commands.FlagSpec{Name: "last", Kind: commands.IntFlag, Value: "N", Help: "Export only the N most recent entries of the range"},
+	commands.FlagSpec{Name: "skip-failed", Help: "Leave out commands that failed"},
+	commands.FlagSpec{Name: "force", Short: "f", Help: "Overwrite an existing script"},
+)
+
+// defaultScriptEntries is how many recent commands a script gets without a
+// range, the same as history shows
+const defaultScriptEntries = 20
+
+// HistoryScriptOptions selects the history entries written to a script
+type HistoryScriptOptions struct {
+	// From and To are entry IDs as history shows them, inclusive; 0 leaves
+	// that end of the range open
+	From int
+	To   int
+	// Last keeps only the most recent entries of the selection, 0 for all
+	Last int
+	// SkipFailed leaves out commands that exited non-zero
+	SkipFailed bool
+}
+
+// SelectHistory returns the entries a script is made of, oldest first. Empty
+// lines and history commands themselves are always left out, as replaying
+// them would only export or clear the history again.
+func SelectHistory(entries []HistoryEntry, opts HistoryScriptOptions) []HistoryEntry {
+	var selected []HistoryEntry
+	for _, entry := range entries {
+		if opts.From > 0 && entry.ID < opts.From || opts.To > 0 && entry.ID > opts.To {
+			continue
+		}
+		if opts.SkipFailed && entry.ExitCode != 0 {
+			continue
+		}
+		fields := strings.Fields(entry.Command)
+		if len(fields) == 0 || fields[0] == "history" {
+			continue
+		}
+		selected = append(selected, entry)
+	}
+	if opts.Last > 0 && len(selected) > opts.Last {
+		selected = selected[len(selected)-opts.Last:]
+	}
+	return selected
+}
+
+// HistoryScript turns entries into a script with one command per line. The
+// header records when and from which entries it was generated, and a failed
+// command that was kept is marked with a comment so it can be fixed or removed.
+func HistoryScript(entries []HistoryEntry, opts HistoryScriptOptions, generated time.Time) string {
+	var script strings.Builder
+	script.WriteString("# SuperShell script exported from history\n")
+	script.WriteString("#\n")
+	script.WriteString(fmt.Sprintf("# Generated %s by history export\n", generated.Format("2006-01-02 15:04:05")))
+	if len(entries) > 0 {
+		first, last := entries[0], entries[len(entries)-1]
+		script.WriteString(fmt.Sprintf("# From %d commands run between %s and %s (entries %d-%d)\n",
+			len(entries), first.Timestamp.Format("2006-01-02 15:04"), last.Timestamp.Format("2006-01-02 15:04"), first.ID, last.ID))
+		if first.Directory != "" && first.Directory != "." {
+			script.WriteString(fmt.Sprintf("# Recorded in %s\n", first.Directory))
+		}
+	}
+	if opts.SkipFailed {
+		script.WriteString("# Commands that failed were left out.\n")
+	}
+	script.WriteString("#\n")
+	script.WriteString("# Each line is run as a SuperShell command, exactly as if it had been typed at\n")
+	script.WriteString("# the prompt. Blank lines and lines starting with # are ignored.\n\n")
+
+	for _, entry := range entries {
+		if entry.ExitCode != 0 {
+			script.WriteString(fmt.Sprintf("# The next command failed with exit code %d\n", entry.ExitCode))
+		}
+		script.WriteString(strings.TrimSpace(entry.Command) + "\n")
+	}
+	return script.String()
+}
+
+// exportScript writes history entries to a script, or prints it for "-"
+func (h *SmartHistoryCommand) exportScript(args []string, startTime time.Time) *commands.Result {
+	flags, usage := historyScriptFlags.ParseArguments(commands.ParseArguments(args), startTime)
+	if usage != nil {
+		return usage
+	}
+	opts := HistoryScriptOptions{
+		From:       flags.Int("from"),
+		To:         flags.Int("to"),
+		Last:       flags.Int("last"),
+		SkipFailed: flags.Bool("skip-failed"),
+	}
+	for _, name := range []string{"from", "to", "last"} {
+		if flags.Changed(name) && flags.Int(name) <= 0 {
+			return h.scriptUsage(startTime, "--%s needs a positive number, not '%d'", name, flags.Int(name))
+		}
+	}
+	force := flags.Bool("force")
+	if len(flags.Args()) > 1 {
+		return h.scriptUsage(startTime, "unexpected argument '%s'", flags.Arg(1))
+	}
+	file := flags.Arg(0)
+	if file == "" {
+		return h.scriptUsage(startTime, "export needs a file to write the script to")
+	}
+	if opts.From > 0 && opts.To > 0 && opts.From > opts.To {
+		return h.scriptUsage(startTime, "--from %d is after --to %d", opts.From, opts.To)
+	}
+	if opts.From == 0 && opts.To == 0 && opts.Last == 0 {
+		opts.Last = defaultScriptEntries
+	}
+
+	entries, err := h.loadHistory()
+	if err != nil {
+		err = fmt.Errorf("failed to load history: %w", err)
+		return commands.ErrorResult(color.New(color.FgRed).Sprintf("❌ %v\n", err), err, startTime)
+	}
+	selected := SelectHistory(entries, opts)
+	if len(selected) == 0 {
+		err := fmt.Errorf("no commands in history match the selection")
+		return commands.ErrorResult(color.New(color.FgYellow).Sprintf("⚠️  %v\n", err), err, startTime)
+	}
+	script := HistoryScript(selected, opts, time.Now())
+
+	if file == "-" {
+		return &commands.Result{
+			Output:   script,
+			ExitCode: 0,
+			Duration: time.Since(startTime),
+		}
+	}
+
+	if _, err := os.Stat(file); err == nil && !force {
+		err := fmt.Errorf("%s already exists; use --force to overwrite it", file)
+		return commands.ErrorResult(color.New(color.FgRed).Sprintf("❌ %v\n", err), err, startTime)
+	}
+	if dir := filepath.Dir(file); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return commands.ErrorResult(color.New(color.FgRed).Sprintf("❌ %v\n", err), err, startTime)
+		}
+	}
+	if err := ioutil.WriteFile(file, []byte(script), 0644); err != nil {
+		err = fmt.Errorf("failed to write %s: %w", file, err)
+		return commands.ErrorResult(color.New(color.FgRed).Sprintf("❌ %v\n", err), err, startTime)
+	}
+
+	var output strings.Builder
+	output.WriteString(color.New(color.FgGreen).Sprintf("✅ Wrote %d commands to %s\n", len(selected), file))
+	for _, entry := range selected {
+		if entry.ExitCode != 0 {
+			output.WriteString(color.New(color.FgHiBlack).Sprint("💡 Commands that failed are marked with a comment; fix or remove them, or export with --skip-failed\n"))
+			break
+		}
+	}
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}
+}
+
+// scriptUsage reports a mistake in the history export arguments
+func (h *SmartHistoryCommand) scriptUsage(startTime time.Time, format string, args ...interface{}) *commands.Result {
+	return &commands.Result{
+		Output:   "Usage: " + historyScriptUsage + "\n",
+		Error:    commands.UsageError(h.Name(), format, args...),
+		ExitCode: 1,
+		Duration: time.Since(startTime),
+	}
+}
@@ -0,0 +1,142 @@
+package system
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// historySessionGap separates sessions in entries recorded before session IDs existed
+const historySessionGap = 30 * time.Minute
+
+// CommandUsage is how often one command name was run
+type CommandUsage struct {
+	Command string `json:"command"`
+	Count   int    `json:"count"`
+	Failed  int    `json:"failed"`
+}
+
+// HistoryStats summarizes command usage across the history file
+type HistoryStats struct {
+	TotalCommands      int            `json:"total_commands"`
+	Sessions           int            `json:"sessions"`
+	CommandsPerSession float64        `json:"commands_per_session"`
+	SuccessRate        float64        `json:"success_rate"`
+	FirstCommand       time.Time      `json:"first_command,omitempty"`
+	LastCommand        time.Time      `json:"last_command,omitempty"`
+	TopCommands        []CommandUsage `json:"top_commands"`
+	HourlyUsage        [24]int        `json:"hourly_usage"`
+	BusiestHour        int            `json:"busiest_hour"`
+	Categories         map[string]int `json:"categories"`
+}
+
+// AnalyzeHistory computes usage statistics from a history file. Entries are decoded
+// one at a time so large files never need to be held in memory.
+func AnalyzeHistory(r io.Reader, top int) (*HistoryStats, error) {
+	stats := &HistoryStats{Categories: make(map[string]int)}
+	usage := make(map[string]*CommandUsage)
+
+	decoder := json.NewDecoder(r)
+	token, err := decoder.Token()
+	if err == io.EOF {
+		return stats, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid history file: %v", err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return nil, fmt.Errorf("invalid history file: expected a JSON array")
+	}
+
+	succeeded := 0
+	lastSession := ""
+	var previous time.Time
+
+	for decoder.More() {
+		var entry HistoryEntry
+		if err := decoder.Decode(&entry); err != nil {
+			return nil, fmt.Errorf("invalid history entry %d: %v", stats.TotalCommands+1, err)
+		}
+
+		fields := strings.Fields(entry.Command)
+		if len(fields) == 0 {
+			continue
+		}
+
+		stats.TotalCommands++
+		if entry.ExitCode == 0 {
+			succeeded++
+		}
+
+		item := usage[fields[0]]
+		if item == nil {
+			item = &CommandUsage{Command: fields[0]}
+			usage[fields[0]] = item
+		}
+		item.Count++
+		if entry.ExitCode != 0 {
+			item.Failed++
+		}
+
+		if entry.Category != "" {
+			stats.Categories[entry.Category]++
+		}
+
+		if !entry.Timestamp.IsZero() {
+			stats.HourlyUsage[entry.Timestamp.Hour()]++
+			if stats.FirstCommand.IsZero() || entry.Timestamp.Before(stats.FirstCommand) {
+				stats.FirstCommand = entry.Timestamp
+			}
+			if entry.Timestamp.After(stats.LastCommand) {
+				stats.LastCommand = entry.Timestamp
+			}
+		}
+
+		// Prefer recorded session IDs; fall back to idle gaps for older entries
+		newSession := stats.Sessions == 0
+		switch {
+		case entry.Session != "":
+			newSession = newSession || entry.Session != lastSession
+		case lastSession != "":
+			newSession = true
+		case !previous.IsZero() && entry.Timestamp.Sub(previous) > historySessionGap:
+			newSession = true
+		}
+		if newSession {
+			stats.Sessions++
+		}
+		lastSession = entry.Session
+		previous = entry.Timestamp
+	}
+
+	if stats.TotalCommands == 0 {
+		return stats, nil
+	}
+
+	stats.SuccessRate = float64(succeeded) / float64(stats.TotalCommands) * 100
+	stats.CommandsPerSession = float64(stats.TotalCommands) / float64(stats.Sessions)
+
+	for hour, count := range stats.HourlyUsage {
+		if count > stats.HourlyUsage[stats.BusiestHour] {
+			stats.BusiestHour = hour
+		}
+	}
+
+	for _, item := range usage {
+		stats.TopCommands = append(stats.TopCommands, *item)
+	}
+	sort.Slice(stats.TopCommands, func(i, j int) bool {
+		if stats.TopCommands[i].Count != stats.TopCommands[j].Count {
+			return stats.TopCommands[i].Count > stats.TopCommands[j].Count
+		}
+		return stats.TopCommands[i].Command < stats.TopCommands[j].Command
+	})
+	if top > 0 && len(stats.TopCommands) > top {
+		stats.TopCommands = stats.TopCommands[:top]
+	}
+
+	return stats, nil
+}
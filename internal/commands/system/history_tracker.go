@@ -4,6 +4,7 @@ import (
 	"io/ioutil"
 
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -14,6 +15,7 @@ import (
 type HistoryTracker struct {
 	historyFile string
 	maxEntries  int
+	session     string
 }
 
 // NewHistoryTracker creates a new history tracker
@@ -24,6 +26,7 @@ func NewHistoryTracker() *HistoryTracker {
 	return &HistoryTracker{
 		historyFile: historyFile,
 		maxEntries:  1000, // Keep last 1000 commands
+		session:     fmt.Sprintf("%d-%d", time.Now().Unix(), os.Getpid()),
 	}
 }
 
@@ -45,6 +48,7 @@ func (ht *HistoryTracker) TrackCommand(command string, directory string, exitCod
 		Duration:  duration.Milliseconds(),
 		Tags:      ht.generateTags(command),
 		Category:  ht.categorizeCommand(command),
+		Session:   ht.session,
 	}
 
 	// Add to entries
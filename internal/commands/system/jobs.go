@@ -0,0 +1,307 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+
+	"github.com/fatih/color"
+)
+
+const (
+	// jobPollInterval is how often fg checks a job for new output
+	jobPollInterval = 100 * time.Millisecond
+	// jobStopWait is how long kill waits for a job to stop
+	jobStopWait = 5 * time.Second
+)
+
+// JobsCommand lists the background jobs started with a trailing &
+type JobsCommand struct {
+	*commands.BaseCommand
+	flags *commands.FlagSet
+	jobs  *commands.JobTable
+}
+
+// NewJobsCommand creates a jobs command listing the jobs in jobs
+func NewJobsCommand(jobs *commands.JobTable) *JobsCommand {
+	usage := "jobs [--json]"
+	return &JobsCommand{
+		BaseCommand: commands.NewBaseCommand(
+			"jobs",
+			"List background jobs started with a trailing &",
+			usage,
+			[]string{"windows", "linux", "darwin"},
+			false,
+		),
+		flags: commands.NewFlagSet("jobs", usage,
+			commands.FlagSpec{Name: "json", Help: "Print the jobs as JSON"},
+		),
+		jobs: jobs,
+	}
+}
+
+// FlagSet returns the options jobs accepts
+func (j *JobsCommand) FlagSet() *commands.FlagSet {
+	return j.flags
+}
+
+// jobInfo is one job in jobs --json
+type jobInfo struct {
+	ID       int     `json:"id"`
+	Command  string  `json:"command"`
+	State    string  `json:"state"`
+	ExitCode int     `json:"exit_code"`
+	Started  string  `json:"started"`
+	Seconds  float64 `json:"seconds"`
+}
+
+// Execute lists the jobs, oldest first
+func (j *JobsCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	startTime := time.Now()
+
+	flags, result := j.flags.ParseArguments(args, startTime)
+	if result != nil {
+		return result, nil
+	}
+
+	jobs := j.jobs.List()
+	if flags.Bool("json") {
+		infos := make([]jobInfo, 0, len(jobs))
+		for _, job := range jobs {
+			infos = append(infos, jobInfo{
+				ID:       job.ID,
+				Command:  job.Line,
+				State:    string(job.State()),
+				ExitCode: job.ExitCode(),
+				Started:  job.Started.Format(time.RFC3339),
+				Seconds:  job.Elapsed().Seconds(),
+			})
+		}
+		return commands.JSONResult(infos, startTime)
+	}
+
+	if len(jobs) == 0 {
+		return &commands.Result{
+			Output:   "No background jobs. End a command with & to run it in the background.\n",
+			ExitCode: 0,
+			Duration: time.Since(startTime),
+		}, nil
+	}
+
+	var output strings.Builder
+	for _, job := range jobs {
+		state := string(job.State())
+		switch job.State() {
+		case commands.JobRunning:
+			state = color.New(color.FgGreen).Sprintf("%-8s", state)
+		case commands.JobDone:
+			state = color.New(color.FgHiBlack).Sprintf("%-8s", state)
+		default:
+			state = color.New(color.FgRed).Sprintf("%-8s", fmt.Sprintf("%s(%d)", state, job.ExitCode()))
+		}
+		output.WriteString(fmt.Sprintf("[%d] %s %8s  %s\n", job.ID, state, job.Elapsed().Round(time.Second), job.Line))
+	}
+
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}, nil
+}
+
+// FgCommand follows a background job's output until it ends
+type FgCommand struct {
+	*commands.BaseCommand
+	flags *commands.FlagSet
+	jobs  *commands.JobTable
+}
+
+// NewFgCommand creates an fg command for the jobs in jobs
+func NewFgCommand(jobs *commands.JobTable) *FgCommand {
+	usage := "fg [%<id>]"
+	return &FgCommand{
+		BaseCommand: commands.NewBaseCommand(
+			"fg",
+			"Bring a background job to the foreground and wait for it",
+			usage,
+			[]string{"windows", "linux", "darwin"},
+			false,
+		),
+		flags: commands.NewFlagSet("fg", usage),
+		jobs:  jobs,
+	}
+}
+
+// FlagSet returns the options fg accepts
+func (f *FgCommand) FlagSet() *commands.FlagSet {
+	return f.flags
+}
+
+// Execute prints what the job has written so far and then follows it until it
+// ends, returning its exit code. The most recent job is used without an ID.
+// Interrupting fg leaves the job running.
+func (f *FgCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	startTime := time.Now()
+
+	flags, result := f.flags.ParseArguments(args, startTime)
+	if result != nil {
+		return result, nil
+	}
+	job, err := findJob(f.jobs, flags.Args())
+	if err != nil {
+		return &commands.Result{
+			Output:   "Usage: " + f.Usage() + "\n",
+			Error:    commands.UsageError(f.Name(), "%v", err),
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}, nil
+	}
+
+	out := commands.OutputWriter(ctx)
+	fmt.Fprintln(out, color.New(color.FgHiBlack).Sprintf("[%d] %s", job.ID, job.Line))
+
+	var offset int64
+	ticker := time.NewTicker(jobPollInterval)
+	defer ticker.Stop()
+	for {
+		var text string
+		text, offset = job.OutputSince(offset)
+		io.WriteString(out, text)
+
+		select {
+		case <-job.Done():
+			// Catch what was written between the last poll and the end
+			text, _ = job.OutputSince(offset)
+			io.WriteString(out, text)
+			f.jobs.Remove(job.ID)
+			return &commands.Result{
+				ExitCode: job.ExitCode(),
+				Duration: time.Since(startTime),
+			}, nil
+		case <-ctx.Done():
+			return &commands.Result{
+				Output:   color.New(color.FgYellow).Sprintf("[%d] still running in the background\n", job.ID),
+				ExitCode: 0,
+				Duration: time.Since(startTime),
+			}, nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// KillCommand stops background jobs given as %<id>, and processes given by PID
+// or name as killtask does
+type KillCommand struct {
+	*commands.BaseCommand
+	flags *commands.FlagSet
+	jobs  *commands.JobTable
+}
+
+// NewKillCommand creates a kill command for the jobs in jobs
+func NewKillCommand(jobs *commands.JobTable) *KillCommand {
+	usage := "kill [-f] <%id|pid|process_name> ..."
+	return &KillCommand{
+		BaseCommand: commands.NewBaseCommand(
+			"kill",
+			"Stop background jobs by %id, or processes by PID or name",
+			usage,
+			[]string{"windows", "linux", "darwin"},
+			false,
+		),
+		flags: commands.NewFlagSet("kill", usage,
+			commands.FlagSpec{Name: "force", Short: "f", Help: "Terminate processes immediately; jobs are always stopped at once"},
+		),
+		jobs: jobs,
+	}
+}
+
+// FlagSet returns the options kill accepts
+func (k *KillCommand) FlagSet() *commands.FlagSet {
+	return k.flags
+}
+
+// Execute stops each target, reporting on every one
+func (k *KillCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	startTime := time.Now()
+
+	flags, result := k.flags.ParseArguments(args, startTime)
+	if result != nil {
+		return result, nil
+	}
+	if len(flags.Args()) == 0 {
+		return &commands.Result{
+			Output:   "Usage: " + k.Usage() + "\n",
+			Error:    commands.UsageError(k.Name(), "nothing to kill"),
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}, nil
+	}
+
+	var output strings.Builder
+	var errs commands.ErrorList
+	for _, target := range flags.Args() {
+		if !strings.HasPrefix(target, "%") {
+			killed := (&KillTaskCommand{}).killProcess(target, flags.Bool("force"), false)
+			output.WriteString(killed.message)
+			if !killed.success {
+				errs = append(errs, fmt.Errorf("%s: could not be terminated", target))
+			}
+			continue
+		}
+
+		job, err := findJob(k.jobs, []string{target})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		k.jobs.Remove(job.ID)
+		select {
+		case <-job.Done():
+			output.WriteString(color.New(color.FgGreen).Sprintf("✅ [%d] stopped: %s\n", job.ID, job.Line))
+		case <-time.After(jobStopWait):
+			// Commands that don't watch their context finish their current step first
+			output.WriteString(color.New(color.FgYellow).Sprintf("⏳ [%d] told to stop, still finishing: %s\n", job.ID, job.Line))
+		case <-ctx.Done():
+			return commands.ErrorResult(output.String(), ctx.Err(), startTime), nil
+		}
+	}
+
+	exitCode := 0
+	if len(errs) > 0 {
+		exitCode = 1
+	}
+	return &commands.Result{
+		Output:   output.String(),
+		Error:    errs.Err(),
+		ExitCode: exitCode,
+		Duration: time.Since(startTime),
+	}, nil
+}
+
+// findJob returns the job named by the only argument, or the most recent job
+// when there is none
+func findJob(jobs *commands.JobTable, args []string) (*commands.Job, error) {
+	switch len(args) {
+	case 0:
+		all := jobs.List()
+		if len(all) == 0 {
+			return nil, fmt.Errorf("no background jobs")
+		}
+		return all[len(all)-1], nil
+	case 1:
+		id, err := commands.ParseJobID(args[0])
+		if err != nil {
+			return nil, err
+		}
+		job, ok := jobs.Get(id)
+		if !ok {
+			return nil, fmt.Errorf("no job %%%d (see jobs)", id)
+		}
+		return job, nil
+	}
+	return nil, fmt.Errorf("unexpected argument %q", args[1])
+}
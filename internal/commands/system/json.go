@@ -0,0 +1,214 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+
+	"github.com/fatih/color"
+)
+
+// JSONCommand merges JSON documents and reads or sets values by path, for
+// scripting changes to JSON files and picking apart --json output without jq.
+// Files it writes keep their key order and indentation.
+type JSONCommand struct {
+	*commands.BaseCommand
+}
+
+// NewJSONCommand creates a new json command
+func NewJSONCommand() *JSONCommand {
+	return &JSONCommand{
+		BaseCommand: commands.NewBaseCommand(
+			"json",
+			"Merge JSON files and get or set values by path",
+			"json merge <base.json> <override.json>... [-o <file>] | json get <file> <path> | json set <file> <path> <value> [--string]",
+			[]string{"windows", "linux", "darwin"},
+			false,
+		),
+	}
+}
+
+// CompleteArguments offers the subcommands
+func (j *JSONCommand) CompleteArguments(args []string) []string {
+	if len(args) > 1 {
+		return nil
+	}
+	return []string{"merge", "get", "set"}
+}
+
+// Execute runs the json subcommand
+func (j *JSONCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	startTime := time.Now()
+	if len(args.Raw) == 0 {
+		return commands.UsageResult(j, startTime, "expected merge, get or set"), nil
+	}
+
+	switch args.Raw[0] {
+	case "merge":
+		return j.merge(args.Raw[1:], startTime), nil
+	case "get":
+		if len(args.Raw) != 3 {
+			return commands.UsageResult(j, startTime, "get needs a file and a path"), nil
+		}
+		return j.get(args.Raw[1], args.Raw[2], startTime), nil
+	case "set":
+		forceString := false
+		var positional []string
+		for _, arg := range args.Raw[1:] {
+			if arg == "--string" {
+				forceString = true
+				continue
+			}
+			positional = append(positional, arg)
+		}
+		if len(positional) != 3 {
+			return commands.UsageResult(j, startTime, "set needs a file, a path and a value"), nil
+		}
+		return j.set(positional[0], positional[1], JSONValue(positional[2], forceString), startTime), nil
+	}
+	return commands.UsageResult(j, startTime, "unknown subcommand '%s'", args.Raw[0]), nil
+}
+
+// merge applies each override to the base in turn and prints or writes the result
+func (j *JSONCommand) merge(args []string, startTime time.Time) *commands.Result {
+	output := ""
+	var files []string
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "-o", "--output":
+			if i+1 >= len(args) {
+				return commands.UsageResult(j, startTime, "%s needs a file", args[i])
+			}
+			i++
+			output = args[i]
+		default:
+			files = append(files, args[i])
+		}
+	}
+	if len(files) < 2 {
+		return commands.UsageResult(j, startTime, "merge needs a base file and at least one override")
+	}
+
+	merged, err := readJSONDocument(files[0])
+	if err != nil {
+		return j.failed(err, startTime)
+	}
+	for _, file := range files[1:] {
+		override, err := readJSONDocument(file)
+		if err != nil {
+			return j.failed(err, startTime)
+		}
+		merged.Merge(override)
+	}
+
+	if output == "" {
+		return &commands.Result{
+			Output:   string(merged.Bytes()),
+			ExitCode: 0,
+			Duration: time.Since(startTime),
+		}
+	}
+	if err := writeJSONDocument(output, merged); err != nil {
+		return j.failed(err, startTime)
+	}
+	return &commands.Result{
+		Output:   color.New(color.FgGreen).Sprintf("✅ Merged %d files into %s\n", len(files), output),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}
+}
+
+// get prints the value at path. Strings are printed without quotes so they can
+// be used as they are.
+func (j *JSONCommand) get(file, path string, startTime time.Time) *commands.Result {
+	doc, err := readJSONDocument(file)
+	if err != nil {
+		return j.failed(err, startTime)
+	}
+	value, err := doc.Get(path)
+	if err != nil {
+		return j.failed(fmt.Errorf("%s: %v", file, err), startTime)
+	}
+	text := string(value.Bytes())
+	if s, ok := value.root.(string); ok {
+		text = s + "\n"
+	}
+	return &commands.Result{
+		Output:   text,
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}
+}
+
+// set stores value at path and rewrites the file
+func (j *JSONCommand) set(file, path string, value *JSONDocument, startTime time.Time) *commands.Result {
+	doc, err := readJSONDocument(file)
+	if err != nil {
+		return j.failed(err, startTime)
+	}
+	if err := doc.Set(path, value); err != nil {
+		return j.failed(fmt.Errorf("%s: %v", file, err), startTime)
+	}
+	if err := writeJSONDocument(file, doc); err != nil {
+		return j.failed(err, startTime)
+	}
+	compact := strings.TrimSpace(string((&JSONDocument{root: value.root}).Bytes()))
+	return &commands.Result{
+		Output:   color.New(color.FgGreen).Sprintf("✅ Set %s to %s in %s\n", strings.TrimPrefix(path, "."), compact, file),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}
+}
+
+// readJSONDocument reads and parses file
+func readJSONDocument(file string) (*JSONDocument, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := ParseJSONDocument(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+	return doc, nil
+}
+
+// writeJSONDocument checks that doc encodes to valid JSON, then replaces file
+// through a temporary file so an interrupted write never leaves half a document
+func writeJSONDocument(file string, doc *JSONDocument) error {
+	data := doc.Bytes()
+	if _, err := ParseJSONDocument(data); err != nil {
+		return fmt.Errorf("refusing to write %s: %v", file, err)
+	}
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(file); err == nil {
+		mode = info.Mode().Perm()
+	}
+	temp, err := ioutil.TempFile(filepath.Dir(file), "."+filepath.Base(file)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(temp.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), file)
+}
+
+// failed reports an error reading, changing or writing a document
+func (j *JSONCommand) failed(err error, startTime time.Time) *commands.Result {
+	return commands.ErrorResult(color.New(color.FgRed).Sprintf("❌ %v\n", err), err, startTime)
+}
@@ -0,0 +1,333 @@
+package system
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// jsonObject is a JSON object that remembers the order of its keys, so a
+// file edited with json set or merge keeps its layout
+type jsonObject struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// set replaces the value of key, adding the key at the end when it is new
+func (o *jsonObject) set(key string, value interface{}) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+// remove deletes key and its value
+func (o *jsonObject) remove(key string) {
+	if _, ok := o.values[key]; !ok {
+		return
+	}
+	delete(o.values, key)
+	for i, k := range o.keys {
+		if k == key {
+			o.keys = append(o.keys[:i], o.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// JSONDocument is a parsed JSON file together with the indentation it was
+// written with. Values are *jsonObject, []interface{}, json.Number, string,
+// bool or nil.
+type JSONDocument struct {
+	root   interface{}
+	indent string
+}
+
+// ParseJSONDocument parses data, keeping key order, number text and the
+// indentation of the first indented line. A document on one line stays compact.
+func ParseJSONDocument(data []byte) (*JSONDocument, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	root, err := decodeJSONValue(decoder)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON: %v", err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("invalid JSON: unexpected data after the document")
+	}
+	return &JSONDocument{root: root, indent: detectIndent(data)}, nil
+}
+
+// decodeJSONValue reads the next value from decoder
+func decodeJSONValue(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return token, nil
+	}
+	switch delim {
+	case '{':
+		object := &jsonObject{values: make(map[string]interface{})}
+		for decoder.More() {
+			token, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeJSONValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			object.set(token.(string), value)
+		}
+		_, err := decoder.Token()
+		return object, err
+	case '[':
+		array := []interface{}{}
+		for decoder.More() {
+			value, err := decodeJSONValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		_, err := decoder.Token()
+		return array, err
+	}
+	return nil, fmt.Errorf("unexpected %v", delim)
+}
+
+// detectIndent returns the leading whitespace of the first indented line, or
+// "" when the document has no line breaks
+func detectIndent(data []byte) string {
+	for _, line := range strings.Split(string(data), "\n")[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed != "" && len(trimmed) < len(line) {
+			return line[:len(line)-len(trimmed)]
+		}
+	}
+	if bytes.Contains(bytes.TrimSpace(data), []byte("\n")) {
+		return "  "
+	}
+	return ""
+}
+
+// Bytes encodes the document with its original indentation and a final newline
+func (d *JSONDocument) Bytes() []byte {
+	var buf bytes.Buffer
+	writeJSONValue(&buf, d.root, d.indent, "")
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
+
+// writeJSONValue encodes value at the nesting given by prefix
+func writeJSONValue(buf *bytes.Buffer, value interface{}, indent, prefix string) {
+	newline, inner, colon := "", prefix+indent, ":"
+	if indent != "" {
+		newline, colon = "\n", ": "
+	}
+	switch v := value.(type) {
+	case *jsonObject:
+		if len(v.keys) == 0 {
+			buf.WriteString("{}")
+			return
+		}
+		buf.WriteString("{" + newline)
+		for i, key := range v.keys {
+			buf.WriteString(inner)
+			writeJSONString(buf, key)
+			buf.WriteString(colon)
+			writeJSONValue(buf, v.values[key], indent, inner)
+			if i < len(v.keys)-1 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(newline)
+		}
+		buf.WriteString(prefix + "}")
+	case []interface{}:
+		if len(v) == 0 {
+			buf.WriteString("[]")
+			return
+		}
+		buf.WriteString("[" + newline)
+		for i, item := range v {
+			buf.WriteString(inner)
+			writeJSONValue(buf, item, indent, inner)
+			if i < len(v)-1 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(newline)
+		}
+		buf.WriteString(prefix + "]")
+	case string:
+		writeJSONString(buf, v)
+	case json.Number:
+		buf.WriteString(v.String())
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case nil:
+		buf.WriteString("null")
+	}
+}
+
+// writeJSONString quotes s, leaving <, > and & as they are
+func writeJSONString(buf *bytes.Buffer, s string) {
+	var quoted bytes.Buffer
+	encoder := json.NewEncoder(&quoted)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s)
+	buf.Write(bytes.TrimRight(quoted.Bytes(), "\n"))
+}
+
+// Merge applies override to the document as a JSON merge patch (RFC 7386):
+// objects are merged key by key, null removes a key, and anything else,
+// arrays included, replaces what was there
+func (d *JSONDocument) Merge(override *JSONDocument) {
+	d.root = mergeJSONValue(d.root, override.root)
+}
+
+// mergeJSONValue merges patch into target and returns the result
+func mergeJSONValue(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(*jsonObject)
+	if !ok {
+		return patch
+	}
+	targetObject, ok := target.(*jsonObject)
+	if !ok {
+		targetObject = &jsonObject{values: make(map[string]interface{})}
+	}
+	for _, key := range patchObject.keys {
+		value := patchObject.values[key]
+		if value == nil {
+			targetObject.remove(key)
+			continue
+		}
+		targetObject.set(key, mergeJSONValue(targetObject.values[key], value))
+	}
+	return targetObject
+}
+
+// parseJSONPath splits a path such as .servers[0].host or servers.0.host
+// into its keys and indexes
+func parseJSONPath(path string) ([]string, error) {
+	path = strings.TrimPrefix(path, ".")
+	path = strings.Replace(strings.Replace(path, "[", ".", -1), "]", "", -1)
+	if path == "" {
+		return nil, fmt.Errorf("empty path")
+	}
+	parts := strings.Split(path, ".")
+	for _, part := range parts {
+		if part == "" {
+			return nil, fmt.Errorf("invalid path '%s'", path)
+		}
+	}
+	return parts, nil
+}
+
+// Get returns the value at path
+func (d *JSONDocument) Get(path string) (*JSONDocument, error) {
+	parts, err := parseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+	value := d.root
+	for i, part := range parts {
+		at := strings.Join(parts[:i+1], ".")
+		switch v := value.(type) {
+		case *jsonObject:
+			next, ok := v.values[part]
+			if !ok {
+				return nil, fmt.Errorf("%s: no such key", at)
+			}
+			value = next
+		case []interface{}:
+			index, err := strconv.Atoi(part)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, fmt.Errorf("%s: index out of range, the array has %d items", at, len(v))
+			}
+			value = v[index]
+		default:
+			return nil, fmt.Errorf("%s: %s is not an object or array", at, jsonPathName(parts[:i]))
+		}
+	}
+	return &JSONDocument{root: value, indent: d.indent}, nil
+}
+
+// Set stores value at path, creating the objects along it that don't exist.
+// An array index may be one past the end to append.
+func (d *JSONDocument) Set(path string, value *JSONDocument) error {
+	parts, err := parseJSONPath(path)
+	if err != nil {
+		return err
+	}
+	root, err := setJSONValue(d.root, parts, 0, value.root)
+	if err != nil {
+		return err
+	}
+	d.root = root
+	return nil
+}
+
+// setJSONValue stores value at parts[depth:] below current and returns the
+// updated current
+func setJSONValue(current interface{}, parts []string, depth int, value interface{}) (interface{}, error) {
+	if depth == len(parts) {
+		return value, nil
+	}
+	part, at := parts[depth], strings.Join(parts[:depth+1], ".")
+	switch v := current.(type) {
+	case *jsonObject:
+		child, err := setJSONValue(v.values[part], parts, depth+1, value)
+		if err != nil {
+			return nil, err
+		}
+		v.set(part, child)
+		return v, nil
+	case []interface{}:
+		index, err := strconv.Atoi(part)
+		if err != nil || index < 0 || index > len(v) {
+			return nil, fmt.Errorf("%s: index out of range, the array has %d items", at, len(v))
+		}
+		var existing interface{}
+		if index < len(v) {
+			existing = v[index]
+		}
+		child, err := setJSONValue(existing, parts, depth+1, value)
+		if err != nil {
+			return nil, err
+		}
+		if index == len(v) {
+			return append(v, child), nil
+		}
+		v[index] = child
+		return v, nil
+	case nil:
+		object := &jsonObject{values: make(map[string]interface{})}
+		return setJSONValue(object, parts, depth, value)
+	}
+	return nil, fmt.Errorf("%s: %s is not an object or array", at, jsonPathName(parts[:depth]))
+}
+
+// jsonPathName names the value at parts in an error
+func jsonPathName(parts []string) string {
+	if len(parts) == 0 {
+		return "the document"
+	}
+	return strings.Join(parts, ".")
+}
+
+// JSONValue parses a value given on the command line: JSON when it parses as
+// such, so 8080, true and {"a":1} keep their types, and a string otherwise
+func JSONValue(text string, forceString bool) *JSONDocument {
+	if !forceString {
+		if doc, err := ParseJSONDocument([]byte(text)); err == nil {
+			return doc
+		}
+	}
+	return &JSONDocument{root: text}
+}
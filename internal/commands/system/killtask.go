@@ -26,7 +26,7 @@ func NewKillTaskCommand() *KillTaskCommand {
 			"Terminate processes by PID or process name",
 			"killtask [-f] [-t] <pid|process_name> [pid2] [process_name2] ...",
 			[]string{"windows", "linux", "darwin"},
-			true, // May require elevation for some processes
+			false, // Only other users' processes need elevation
 		),
 	}
 }
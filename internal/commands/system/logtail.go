@@ -0,0 +1,346 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/security"
+
+	"github.com/fatih/color"
+)
+
+// logtailDefaultLines is how many entries are shown when -n isn't given
+const logtailDefaultLines = 50
+
+// LogtailCommand shows and follows system logs
+type LogtailCommand struct {
+	*commands.BaseCommand
+}
+
+// NewLogtailCommand creates a new logtail command
+func NewLogtailCommand() *LogtailCommand {
+	return &LogtailCommand{
+		BaseCommand: commands.NewBaseCommand(
+			"logtail",
+			"Show, filter and follow system logs",
+			"logtail [text] [--source <unit|log|file>] [-n <lines>] [--level <level>] [--since <time>] [-f|--follow] [--json]",
+			[]string{"windows", "linux"},
+			false,
+		),
+	}
+}
+
+// logtailOptions are the parsed arguments of a logtail run
+type logtailOptions struct {
+	source     string
+	filter     string
+	lines      int
+	priority   int
+	since      time.Time
+	follow     bool
+	jsonOutput bool
+}
+
+// Execute shows the newest matching log entries, then follows new ones if asked
+func (l *LogtailCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	startTime := time.Now()
+
+	options, err := l.parseOptions(args.Raw, startTime)
+	if err != nil {
+		return &commands.Result{
+			Output:   "Usage: " + l.Usage() + "\n",
+			Error:    commands.UsageError(l.Name(), "%v", err),
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}, nil
+	}
+
+	source, err := openLogSource(options.source)
+	if err != nil {
+		output := color.New(color.FgRed).Sprintf("❌ %v\n", err)
+		return commands.ErrorResult(output, err, startTime), nil
+	}
+
+	query := logQuery{lines: options.lines, priority: options.priority, since: options.since}
+	if options.filter != "" {
+		// The text filter is applied here, so the source has to supply everything
+		query.lines = 0
+	}
+
+	// Keep the newest matching entries
+	var entries []LogEntry
+	counts := make(map[string]int)
+	readErr := source.Read(ctx, query, func(entry LogEntry) {
+		if !entryMatches(entry, options.filter) {
+			return
+		}
+		entries = append(entries, entry)
+		if len(entries) > options.lines {
+			entries = entries[1:]
+		}
+	})
+	if readErr != nil && len(entries) == 0 {
+		output := color.New(color.FgRed).Sprintf("❌ Failed to read %s: %v\n", source.Name(), readErr)
+		return commands.ErrorResult(output, readErr, startTime), nil
+	}
+	for _, entry := range entries {
+		counts[entry.Level]++
+	}
+
+	if options.jsonOutput && !options.follow {
+		if entries == nil {
+			entries = []LogEntry{}
+		}
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return &commands.Result{
+			Output:   string(data) + "\n",
+			ExitCode: 0,
+			Duration: time.Since(startTime),
+		}, nil
+	}
+
+	var output strings.Builder
+	if !options.jsonOutput {
+		l.writeHeader(&output, source, options)
+	}
+	for _, entry := range entries {
+		if options.jsonOutput {
+			// Followed JSON is one entry per line, starting with the existing entries
+			data, err := json.Marshal(entry)
+			if err != nil {
+				return nil, err
+			}
+			output.WriteString(string(data) + "\n")
+			continue
+		}
+		output.WriteString(formatLogEntry(entry, options))
+	}
+	if readErr != nil {
+		output.WriteString(color.New(color.FgYellow).Sprintf("⚠️  Stopped reading early: %v\n", readErr))
+	}
+
+	if options.follow {
+		return l.follow(ctx, source, query, options, &output, len(entries), startTime), nil
+	}
+
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+	if len(entries) == 0 {
+		output.WriteString("📭 No matching log entries\n")
+	} else {
+		output.WriteString(fmt.Sprintf("📊 %d entries%s\n", len(entries), formatLevelCounts(counts)))
+	}
+	output.WriteString(color.New(color.FgHiBlack).Sprintf("Completed in %v\n", time.Since(startTime).Round(time.Millisecond)))
+
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}, nil
+}
+
+// parseOptions reads the command line. Words that aren't flags make up the text filter.
+func (l *LogtailCommand) parseOptions(raw []string, now time.Time) (logtailOptions, error) {
+	options := logtailOptions{lines: logtailDefaultLines, priority: PriorityDebug}
+	var words []string
+
+	for i := 0; i < len(raw); i++ {
+		arg := raw[i]
+		switch arg {
+		case "-f", "--follow":
+			options.follow = true
+		case "--json":
+			options.jsonOutput = true
+		case "--source", "-n", "--lines", "--level", "--since":
+			if i+1 >= len(raw) {
+				return options, fmt.Errorf("%s needs a value", arg)
+			}
+			i++
+			value := raw[i]
+
+			switch arg {
+			case "--source":
+				options.source = value
+			case "-n", "--lines":
+				lines, err := strconv.Atoi(value)
+				if err != nil || lines <= 0 {
+					return options, fmt.Errorf("invalid line count '%s'", value)
+				}
+				options.lines = lines
+			case "--level":
+				priority, err := ParseLogLevel(value)
+				if err != nil {
+					return options, err
+				}
+				options.priority = priority
+			case "--since":
+				since, err := ParseLogSince(value, now)
+				if err != nil {
+					return options, err
+				}
+				options.since = since
+			}
+		default:
+			if strings.HasPrefix(arg, "-") {
+				return options, fmt.Errorf("unknown option '%s'", arg)
+			}
+			words = append(words, arg)
+		}
+	}
+
+	options.filter = strings.Join(words, " ")
+	return options, nil
+}
+
+// writeHeader describes what is being shown
+func (l *LogtailCommand) writeHeader(output *strings.Builder, source logSource, options logtailOptions) {
+	output.WriteString(color.New(color.FgCyan, color.Bold).Sprint("📜 SYSTEM LOGS\n"))
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+	output.WriteString(fmt.Sprintf("📂 Source:   %s\n", color.New(color.FgBlue).Sprint(source.Name())))
+	if options.priority < PriorityDebug {
+		output.WriteString(fmt.Sprintf("🚦 Level:    %s and above\n", LevelName(options.priority)))
+	}
+	if !options.since.IsZero() {
+		output.WriteString(fmt.Sprintf("🕒 Since:    %s\n", options.since.Format("2006-01-02 15:04:05")))
+	}
+	if options.filter != "" {
+		output.WriteString(fmt.Sprintf("🔎 Filter:   %s\n", color.New(color.FgYellow).Sprint(options.filter)))
+	}
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+}
+
+// follow streams new entries to the terminal until Enter is pressed or ctx is
+// cancelled. What has been gathered so far is printed first.
+func (l *LogtailCommand) follow(ctx context.Context, source logSource, query logQuery, options logtailOptions, output *strings.Builder, shown int, startTime time.Time) *commands.Result {
+	if !options.jsonOutput {
+		output.WriteString(color.New(color.FgHiBlack).Sprint("👀 Following new entries, press Enter to stop\n"))
+	}
+	fmt.Print(output.String())
+
+	followCtx, cancel := security.CancelOnEnter(ctx)
+	defer cancel()
+
+	followed := 0
+	err := source.Follow(followCtx, query, func(entry LogEntry) {
+		if !entryMatches(entry, options.filter) {
+			return
+		}
+		followed++
+		if options.jsonOutput {
+			if data, err := json.Marshal(entry); err == nil {
+				fmt.Println(string(data))
+			}
+			return
+		}
+		fmt.Print(formatLogEntry(entry, options))
+	})
+	if err != nil {
+		output := color.New(color.FgRed).Sprintf("❌ Stopped following %s: %v\n", source.Name(), err)
+		return commands.ErrorResult(output, err, startTime)
+	}
+
+	return &commands.Result{
+		Output: color.New(color.FgHiBlack).Sprintf("Followed %s for %v (%d new entries, %d shown in total)\n",
+			source.Name(), time.Since(startTime).Round(time.Second), followed, shown+followed),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}
+}
+
+// entryMatches reports whether an entry's source or message contains filter,
+// ignoring case
+func entryMatches(entry LogEntry, filter string) bool {
+	if filter == "" {
+		return true
+	}
+	filter = strings.ToLower(filter)
+	return strings.Contains(strings.ToLower(entry.Source), filter) ||
+		strings.Contains(strings.ToLower(entry.Message), filter)
+}
+
+// formatLogEntry renders one entry as a colored line, highlighting the filter text
+func formatLogEntry(entry LogEntry, options logtailOptions) string {
+	timestamp := "               "
+	if !entry.Time.IsZero() {
+		timestamp = entry.Time.Local().Format("Jan 02 15:04:05")
+	}
+
+	source := entry.Source
+	if entry.PID > 0 {
+		source += "[" + strconv.Itoa(entry.PID) + "]"
+	}
+
+	// Continuation lines are indented past the timestamp and level
+	message := strings.Replace(entry.Message, "\n", "\n"+strings.Repeat(" ", 23), -1)
+
+	return fmt.Sprintf("%s %s %s %s\n",
+		color.New(color.FgHiBlack).Sprint(timestamp),
+		levelBadge(entry.Priority),
+		color.New(color.FgBlue).Sprint(source),
+		highlightText(message, options.filter))
+}
+
+// levelBadge returns the fixed-width colored level marker for a priority
+func levelBadge(priority int) string {
+	switch {
+	case priority <= PriorityCritical:
+		return color.New(color.FgWhite, color.BgRed, color.Bold).Sprint("CRIT  ")
+	case priority == PriorityError:
+		return color.New(color.FgRed, color.Bold).Sprint("ERROR ")
+	case priority == PriorityWarning:
+		return color.New(color.FgYellow, color.Bold).Sprint("WARN  ")
+	case priority == PriorityNotice:
+		return color.New(color.FgCyan).Sprint("NOTICE")
+	case priority == PriorityInfo:
+		return color.New(color.FgGreen).Sprint("INFO  ")
+	default:
+		return color.New(color.FgHiBlack).Sprint("DEBUG ")
+	}
+}
+
+// highlightText marks every case-insensitive occurrence of query in text
+func highlightText(text, query string) string {
+	if query == "" {
+		return text
+	}
+	highlightColor := color.New(color.BgYellow, color.FgBlack)
+	lower := strings.ToLower(text)
+	query = strings.ToLower(query)
+	if len(lower) != len(text) {
+		// Lowercasing changed byte offsets, so they can't be mapped back onto text
+		return text
+	}
+
+	var result strings.Builder
+	for {
+		index := strings.Index(lower, query)
+		if index < 0 {
+			result.WriteString(text)
+			return result.String()
+		}
+		result.WriteString(text[:index])
+		result.WriteString(highlightColor.Sprint(text[index : index+len(query)]))
+		text, lower = text[index+len(query):], lower[index+len(query):]
+	}
+}
+
+// formatLevelCounts summarizes how many entries there were of each level
+func formatLevelCounts(counts map[string]int) string {
+	var parts []string
+	for _, level := range []string{"critical", "error", "warning"} {
+		if counts[level] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[level], level))
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
@@ -0,0 +1,651 @@
+package system
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Syslog priorities, which every source's levels are mapped onto
+const (
+	PriorityCritical = 2
+	PriorityError    = 3
+	PriorityWarning  = 4
+	PriorityNotice   = 5
+	PriorityInfo     = 6
+	PriorityDebug    = 7
+)
+
+// logFollowInterval is how often sources without a push mechanism are polled
+const logFollowInterval = time.Second
+
+// LogEntry is one log message from any of the supported sources
+type LogEntry struct {
+	Time     time.Time `json:"time"`
+	Level    string    `json:"level"`
+	Priority int       `json:"priority"`
+	Source   string    `json:"source"`
+	PID      int       `json:"pid,omitempty"`
+	Host     string    `json:"host,omitempty"`
+	Message  string    `json:"message"`
+	// Log is the journal, file or event log the entry was read from
+	Log string `json:"log"`
+
+	// cursor is the journal position, used to follow on from the last entry read
+	cursor string
+}
+
+// LevelName returns the level name used for a syslog priority
+func LevelName(priority int) string {
+	switch {
+	case priority <= PriorityCritical:
+		return "critical"
+	case priority == PriorityError:
+		return "error"
+	case priority == PriorityWarning:
+		return "warning"
+	case priority == PriorityNotice:
+		return "notice"
+	case priority == PriorityInfo:
+		return "info"
+	default:
+		return "debug"
+	}
+}
+
+// ParseLogLevel returns the priority for a --level name
+func ParseLogLevel(name string) (int, error) {
+	switch strings.ToLower(name) {
+	case "critical", "crit", "emerg", "alert":
+		return PriorityCritical, nil
+	case "error", "err":
+		return PriorityError, nil
+	case "warning", "warn":
+		return PriorityWarning, nil
+	case "notice":
+		return PriorityNotice, nil
+	case "info":
+		return PriorityInfo, nil
+	case "debug":
+		return PriorityDebug, nil
+	}
+	return 0, fmt.Errorf("unknown level '%s' (use critical, error, warning, notice, info or debug)", name)
+}
+
+// ParseLogSince parses a --since value: a duration back from now such as "30m", "2h"
+// or "7d", "today", "yesterday", a time of day, or a date with an optional time
+func ParseLogSince(value string, now time.Time) (time.Time, error) {
+	lower := strings.ToLower(strings.TrimSpace(value))
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	switch lower {
+	case "today":
+		return midnight, nil
+	case "yesterday":
+		return midnight.AddDate(0, 0, -1), nil
+	}
+
+	if strings.HasSuffix(lower, "d") {
+		if days, err := strconv.Atoi(strings.TrimSuffix(lower, "d")); err == nil && days > 0 {
+			return now.AddDate(0, 0, -days), nil
+		}
+	}
+	if d, err := time.ParseDuration(lower); err == nil && d > 0 {
+		return now.Add(-d), nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+	for _, layout := range []string{"15:04:05", "15:04"} {
+		if t, err := time.ParseInLocation(layout, value, now.Location()); err == nil {
+			return midnight.Add(time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time '%s' (use e.g. 30m, 2h, 7d, today, 15:04 or 2006-01-02)", value)
+}
+
+// ParseJournalEntry parses one line of `journalctl -o json`
+func ParseJournalEntry(line string) (LogEntry, error) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &fields); err != nil {
+		return LogEntry{}, err
+	}
+
+	entry := LogEntry{Priority: PriorityInfo, Log: "journal", Host: journalString(fields["_HOSTNAME"])}
+	if micros, err := strconv.ParseInt(journalString(fields["__REALTIME_TIMESTAMP"]), 10, 64); err == nil {
+		entry.Time = time.Unix(0, micros*int64(time.Microsecond))
+	}
+	if priority, err := strconv.Atoi(journalString(fields["PRIORITY"])); err == nil {
+		entry.Priority = priority
+	}
+	entry.Level = LevelName(entry.Priority)
+	entry.PID, _ = strconv.Atoi(journalString(fields["_PID"]))
+	entry.Message = strings.TrimRight(journalString(fields["MESSAGE"]), "\n")
+	entry.cursor = journalString(fields["__CURSOR"])
+
+	for _, key := range []string{"SYSLOG_IDENTIFIER", "_COMM", "_SYSTEMD_UNIT"} {
+		if source := journalString(fields[key]); source != "" {
+			entry.Source = source
+			break
+		}
+	}
+	if entry.Source == "" {
+		entry.Source = "kernel"
+	}
+	if unit := journalString(fields["_SYSTEMD_UNIT"]); unit != "" {
+		entry.Log = unit
+	}
+	return entry, nil
+}
+
+// journalString returns a journal field as text. Fields that aren't valid UTF-8 are
+// exported as arrays of bytes.
+func journalString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []interface{}:
+		data := make([]byte, 0, len(v))
+		for _, b := range v {
+			if n, ok := b.(float64); ok {
+				data = append(data, byte(n))
+			}
+		}
+		return string(data)
+	}
+	return ""
+}
+
+// syslogLine matches "Mar 10 09:00:01 host proc[123]: message", also with an RFC 3339
+// timestamp as newer rsyslog writes by default
+var syslogLine = regexp.MustCompile(`^(\w{3} [ \d]\d \d{2}:\d{2}:\d{2}|\d{4}-\d{2}-\d{2}T[\d:.]+(?:Z|[+-]\d{2}:\d{2})) (\S+) ([^:\[\s]+)(?:\[(\d+)\])?: ?(.*)$`)
+
+// ParseSyslogLine parses a line of a syslog-format file. The classic format has no
+// year, so the one that puts the entry closest before now is used. Files don't record
+// a priority either, so it is guessed from the message.
+func ParseSyslogLine(line string, now time.Time) (LogEntry, bool) {
+	match := syslogLine.FindStringSubmatch(line)
+	if match == nil {
+		return LogEntry{}, false
+	}
+
+	var timestamp time.Time
+	if t, err := time.Parse(time.RFC3339Nano, match[1]); err == nil {
+		timestamp = t
+	} else if t, err := time.ParseInLocation("Jan _2 15:04:05", match[1], now.Location()); err == nil {
+		timestamp = t.AddDate(now.Year(), 0, 0)
+		if timestamp.After(now.Add(24 * time.Hour)) {
+			timestamp = timestamp.AddDate(-1, 0, 0)
+		}
+	}
+
+	entry := LogEntry{
+		Time:     timestamp,
+		Host:     match[2],
+		Source:   match[3],
+		Message:  match[5],
+		Priority: guessPriority(match[5]),
+	}
+	entry.PID, _ = strconv.Atoi(match[4])
+	entry.Level = LevelName(entry.Priority)
+	return entry, true
+}
+
+// guessPriority infers a priority from the wording of a message
+func guessPriority(message string) int {
+	lower := strings.ToLower(message)
+	switch {
+	case strings.Contains(lower, "panic") || strings.Contains(lower, "fatal") || strings.Contains(lower, "critical"):
+		return PriorityCritical
+	case strings.Contains(lower, "error") || strings.Contains(lower, "failed") || strings.Contains(lower, "failure"):
+		return PriorityError
+	case strings.Contains(lower, "warn"):
+		return PriorityWarning
+	}
+	return PriorityInfo
+}
+
+// eventLevels maps Windows event levels to priorities
+var eventLevels = map[string]int{
+	"critical": PriorityCritical, "error": PriorityError, "warning": PriorityWarning,
+	"information": PriorityInfo, "verbose": PriorityDebug,
+}
+
+// ParseEventLogText parses the output of `wevtutil qe <log> /f:text`, where each
+// event is a block of "Key: value" lines starting at "Event[N]:" and ending with a
+// free-form description
+func ParseEventLogText(text string) []LogEntry {
+	var entries []LogEntry
+	var current *LogEntry
+	inDescription := false
+
+	flush := func() {
+		if current != nil {
+			current.Message = strings.TrimSpace(current.Message)
+			entries = append(entries, *current)
+		}
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.HasPrefix(line, "Event[") {
+			flush()
+			current = &LogEntry{Priority: PriorityInfo, Level: LevelName(PriorityInfo)}
+			inDescription = false
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		if inDescription {
+			if current.Message != "" {
+				current.Message += "\n"
+			}
+			current.Message += line
+			continue
+		}
+
+		key, value := line, ""
+		if colon := strings.Index(line, ":"); colon >= 0 {
+			key, value = strings.TrimSpace(line[:colon]), strings.TrimSpace(line[colon+1:])
+		}
+		switch key {
+		case "Log Name":
+			current.Log = value
+		case "Source":
+			current.Source = value
+		case "Computer":
+			current.Host = value
+		case "Level":
+			if priority, ok := eventLevels[strings.ToLower(value)]; ok {
+				current.Priority = priority
+				current.Level = LevelName(priority)
+			}
+		case "Date":
+			for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.000", "2006-01-02T15:04:05"} {
+				if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+					current.Time = t
+					break
+				}
+			}
+		case "Description":
+			inDescription = true
+			current.Message = value
+		}
+	}
+	flush()
+	return entries
+}
+
+// logQuery is what a source is asked to read
+type logQuery struct {
+	// lines limits how many of the newest entries are read; 0 reads them all
+	lines    int
+	priority int
+	since    time.Time
+}
+
+// logSource is a system log that can be read and followed
+type logSource interface {
+	// Name describes the source for display
+	Name() string
+	// Read emits the existing entries matching query, oldest first
+	Read(ctx context.Context, query logQuery, emit func(LogEntry)) error
+	// Follow emits entries written after the last Read until ctx is done
+	Follow(ctx context.Context, query logQuery, emit func(LogEntry)) error
+}
+
+// openLogSource picks the log to read. An explicit file path is read directly; with
+// no source Linux uses the journal, or the syslog file when there is no journalctl,
+// and Windows reads the Application and System event logs.
+func openLogSource(source string) (logSource, error) {
+	if source != "" && (strings.ContainsAny(source, `/\`) || fileExists(source)) {
+		return &fileLogSource{path: source}, nil
+	}
+
+	if runtime.GOOS == "windows" {
+		if source == "" {
+			return &eventLogSource{logs: []string{"Application", "System"}}, nil
+		}
+		return &eventLogSource{logs: []string{canonicalEventLog(source)}}, nil
+	}
+
+	if _, err := exec.LookPath("journalctl"); err == nil {
+		return &journalLogSource{unit: source}, nil
+	}
+
+	candidates := []string{"/var/log/syslog", "/var/log/messages"}
+	if source != "" {
+		candidates = []string{filepath.Join("/var/log", source), filepath.Join("/var/log", source+".log")}
+	}
+	for _, path := range candidates {
+		if fileExists(path) {
+			return &fileLogSource{path: path}, nil
+		}
+	}
+	if source != "" {
+		return nil, fmt.Errorf("no journal and no log file for '%s' in /var/log", source)
+	}
+	return nil, fmt.Errorf("no journalctl and no /var/log/syslog or /var/log/messages to read")
+}
+
+// fileExists reports whether path is an existing regular file
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// canonicalEventLog capitalizes the standard event log names
+func canonicalEventLog(name string) string {
+	for _, log := range []string{"Application", "System", "Security", "Setup"} {
+		if strings.EqualFold(name, log) {
+			return log
+		}
+	}
+	return name
+}
+
+// journalLogSource reads the systemd journal, optionally for one unit
+type journalLogSource struct {
+	unit   string
+	cursor string
+}
+
+// Name describes the journal being read
+func (j *journalLogSource) Name() string {
+	if j.unit != "" {
+		return "journal (" + j.unit + ")"
+	}
+	return "journal"
+}
+
+// args returns the journalctl arguments that apply query
+func (j *journalLogSource) args(query logQuery) []string {
+	args := []string{"-o", "json", "--no-pager", "-p", strconv.Itoa(query.priority)}
+	if j.unit != "" {
+		args = append(args, "-u", j.unit)
+	}
+	if !query.since.IsZero() {
+		args = append(args, "--since", query.since.Format("2006-01-02 15:04:05"))
+	}
+	return args
+}
+
+// Read runs journalctl once
+func (j *journalLogSource) Read(ctx context.Context, query logQuery, emit func(LogEntry)) error {
+	args := j.args(query)
+	if query.lines > 0 {
+		args = append(args, "-n", strconv.Itoa(query.lines))
+	}
+	return j.run(ctx, args, emit)
+}
+
+// Follow streams new journal entries, picking up after the last one read
+func (j *journalLogSource) Follow(ctx context.Context, query logQuery, emit func(LogEntry)) error {
+	args := append(j.args(query), "-f")
+	if j.cursor != "" {
+		args = append(args, "--after-cursor", j.cursor)
+	} else {
+		args = append(args, "-n", "0")
+	}
+	err := j.run(ctx, args, emit)
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// run streams the JSON lines printed by journalctl
+func (j *journalLogSource) run(ctx context.Context, args []string, emit func(LogEntry)) error {
+	cmd := exec.CommandContext(ctx, "journalctl", args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		entry, err := ParseJournalEntry(scanner.Text())
+		if err != nil {
+			continue
+		}
+		if entry.cursor != "" {
+			j.cursor = entry.cursor
+		}
+		emit(entry)
+	}
+
+	if err := cmd.Wait(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return fmt.Errorf("journalctl: %s", message)
+		}
+		return fmt.Errorf("journalctl: %v", err)
+	}
+	return scanner.Err()
+}
+
+// fileLogSource reads a syslog-format text file
+type fileLogSource struct {
+	path   string
+	offset int64
+}
+
+// Name returns the file's path
+func (f *fileLogSource) Name() string {
+	return f.path
+}
+
+// Read scans the whole file; the caller keeps the newest entries
+func (f *fileLogSource) Read(ctx context.Context, query logQuery, emit func(LogEntry)) error {
+	file, err := os.Open(f.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	offset, err := f.scan(ctx, file, query, emit)
+	f.offset = offset
+	return err
+}
+
+// Follow polls the file for appended lines, starting over if it is truncated or
+// rotated to a smaller file
+func (f *fileLogSource) Follow(ctx context.Context, query logQuery, emit func(LogEntry)) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(logFollowInterval):
+		}
+
+		file, err := os.Open(f.path)
+		if err != nil {
+			// Mid-rotation; try again on the next poll
+			continue
+		}
+		if info, err := file.Stat(); err == nil && info.Size() < f.offset {
+			f.offset = 0
+		}
+		if _, err := file.Seek(f.offset, io.SeekStart); err == nil {
+			var read int64
+			read, err = f.scan(ctx, file, query, emit)
+			f.offset += read
+		}
+		file.Close()
+		if err != nil && ctx.Err() == nil {
+			return err
+		}
+	}
+}
+
+// scan parses complete lines from r, folding lines that don't start a new entry into
+// the previous message. It returns the number of bytes consumed.
+func (f *fileLogSource) scan(ctx context.Context, r io.Reader, query logQuery, emit func(LogEntry)) (int64, error) {
+	now := time.Now()
+	reader := bufio.NewReaderSize(r, 64*1024)
+	var consumed int64
+	var pending *LogEntry
+	lines := 0
+
+	flush := func() {
+		if pending != nil && pending.Priority <= query.priority && !pending.Time.Before(query.since) {
+			emit(*pending)
+		}
+		pending = nil
+	}
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			// A partial last line is still being written; leave it for the next poll
+			break
+		}
+		consumed += int64(len(line))
+		if lines++; lines%10000 == 0 && ctx.Err() != nil {
+			return consumed, ctx.Err()
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		if entry, ok := ParseSyslogLine(line, now); ok {
+			flush()
+			entry.Log = f.path
+			pending = &entry
+		} else if pending != nil && strings.TrimSpace(line) != "" {
+			pending.Message += "\n" + line
+		}
+	}
+	flush()
+	return consumed, nil
+}
+
+// eventLogSource reads Windows event logs through wevtutil
+type eventLogSource struct {
+	logs []string
+	last time.Time
+}
+
+// Name lists the event logs being read
+func (e *eventLogSource) Name() string {
+	return "Event Log (" + strings.Join(e.logs, ", ") + ")"
+}
+
+// Read queries each log for its newest matching events and merges them by time
+func (e *eventLogSource) Read(ctx context.Context, query logQuery, emit func(LogEntry)) error {
+	entries, err := e.query(ctx, query)
+	if err != nil {
+		return err
+	}
+	if query.lines > 0 && len(entries) > query.lines {
+		entries = entries[len(entries)-query.lines:]
+	}
+	for _, entry := range entries {
+		emit(entry)
+	}
+	return nil
+}
+
+// Follow polls for events newer than the last one seen
+func (e *eventLogSource) Follow(ctx context.Context, query logQuery, emit func(LogEntry)) error {
+	if e.last.IsZero() {
+		e.last = time.Now()
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(logFollowInterval):
+		}
+
+		since := e.last
+		next := query
+		next.lines = 0
+		next.since = since
+		entries, err := e.query(ctx, next)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		for _, entry := range entries {
+			// The query's bound is inclusive, so the last event seen comes back again
+			if entry.Time.After(since) {
+				emit(entry)
+			}
+		}
+	}
+}
+
+// query runs wevtutil for every log and returns the events oldest first
+func (e *eventLogSource) query(ctx context.Context, query logQuery) ([]LogEntry, error) {
+	var entries []LogEntry
+	for _, log := range e.logs {
+		args := []string{"qe", log, "/rd:true", "/f:text"}
+		if query.lines > 0 {
+			args = append(args, "/c:"+strconv.Itoa(query.lines))
+		}
+		if xpath := eventLogXPath(query); xpath != "" {
+			args = append(args, "/q:"+xpath)
+		}
+
+		out, err := exec.CommandContext(ctx, "wevtutil", args...).CombinedOutput()
+		if err != nil {
+			return nil, fmt.Errorf("wevtutil %s: %s", log, strings.TrimSpace(string(out)))
+		}
+		entries = append(entries, ParseEventLogText(string(out))...)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Time.Before(entries[j].Time) })
+	if len(entries) > 0 && entries[len(entries)-1].Time.After(e.last) {
+		e.last = entries[len(entries)-1].Time
+	}
+	return entries, nil
+}
+
+// eventLogXPath builds the wevtutil query selecting events by level and time
+func eventLogXPath(query logQuery) string {
+	var conditions []string
+
+	if query.priority < PriorityInfo {
+		var levels []string
+		for level, priority := range []int{1: PriorityCritical, 2: PriorityError, 3: PriorityWarning} {
+			if level > 0 && priority <= query.priority {
+				levels = append(levels, fmt.Sprintf("Level=%d", level))
+			}
+		}
+		conditions = append(conditions, "("+strings.Join(levels, " or ")+")")
+	}
+	if !query.since.IsZero() {
+		conditions = append(conditions, fmt.Sprintf("TimeCreated[@SystemTime>='%s']", query.since.UTC().Format("2006-01-02T15:04:05.000Z")))
+	}
+
+	if len(conditions) == 0 {
+		return ""
+	}
+	return "*[System[" + strings.Join(conditions, " and ") + "]]"
+}
@@ -24,7 +24,7 @@ func NewLookupCommand(registry *commands.Registry) *LookupCommand {
 		BaseCommand: commands.NewBaseCommand(
 			"lookup",
 			"Intelligent command discovery and suggestions",
-			"lookup [-m] [-s] [-c] [-t <task>] [query]",
+			"lookup [-m] [-s] [-c] [-e] [-t <task>] [query]",
 			[]string{"windows", "linux", "darwin"},
 			false,
 		),
@@ -40,6 +40,7 @@ func (l *LookupCommand) Execute(ctx context.Context, args *commands.Arguments) (
 	showSimilar := false
 	showCategories := false
 	showMenu := false
+	showExamples := false
 	taskBased := ""
 	query := ""
 	menuSelection := 0
@@ -52,6 +53,8 @@ func (l *LookupCommand) Execute(ctx context.Context, args *commands.Arguments) (
 			showCategories = true
 		case "-m", "--menu":
 			showMenu = true
+		case "-e", "--examples":
+			showExamples = true
 		case "-t", "--task":
 			if i+1 < len(args.Raw) {
 				taskBased = args.Raw[i+1]
@@ -112,15 +115,18 @@ func (l *LookupCommand) Execute(ctx context.Context, args *commands.Arguments) (
 	allMatches := []CommandInfo{}
 	allMatches = append(allMatches, results.ExactMatches...)
 	allMatches = append(allMatches, results.PartialMatches...)
+	allMatches = append(allMatches, results.DescriptionMatches...)
 	allMatches = append(allMatches, results.SimilarCommands...)
 
 	if len(allMatches) > 0 {
 		output.WriteString(color.New(color.FgGreen, color.Bold).Sprintf("📋 COMMANDS MATCHING '%s' (%d found)\n", query, len(allMatches)))
 		output.WriteString("───────────────────────────────────────────────────────────────\n")
 
-		// Sort matches by relevance (exact first, then partial, then similar)
+		// Matches are already in order of relevance: exact name, then name,
+		// then description or help text, then similar names
 		exactCount := len(results.ExactMatches)
 		partialCount := len(results.PartialMatches)
+		descriptionCount := len(results.DescriptionMatches)
 
 		for i, match := range allMatches {
 			var prefix string
@@ -132,6 +138,9 @@ func (l *LookupCommand) Execute(ctx context.Context, args *commands.Arguments) (
 			} else if i < exactCount+partialCount {
 				prefix = "📝"
 				nameColor = color.New(color.FgYellow, color.Bold)
+			} else if i < exactCount+partialCount+descriptionCount {
+				prefix = "📄"
+				nameColor = color.New(color.FgWhite, color.Bold)
 			} else {
 				prefix = "🔗"
 				nameColor = color.New(color.FgBlue, color.Bold)
@@ -142,16 +151,19 @@ func (l *LookupCommand) Execute(ctx context.Context, args *commands.Arguments) (
 				nameColor.Sprint(match.Name),
 				match.Description))
 
-			// Show usage for exact matches
-			if i < exactCount && match.Usage != "" {
+			if match.Usage != "" {
 				output.WriteString(fmt.Sprintf("     Usage: %s\n",
 					color.New(color.FgCyan).Sprint(match.Usage)))
 			}
+			if showExamples && match.Example != "" {
+				output.WriteString(fmt.Sprintf("     Example: %s\n",
+					color.New(color.FgGreen).Sprint(match.Example)))
+			}
 		}
 		output.WriteString("\n")
 
 		// Show legend
-		output.WriteString(color.New(color.FgHiBlack).Sprint("Legend: 🎯 Exact match  📝 Partial match  🔗 Similar command\n"))
+		output.WriteString(color.New(color.FgHiBlack).Sprint("Legend: 🎯 Exact match  📝 Name match  📄 Description or help match  🔗 Similar command\n"))
 		output.WriteString("\n")
 	}
 
@@ -164,7 +176,7 @@ func (l *LookupCommand) Execute(ctx context.Context, args *commands.Arguments) (
 		output.WriteString("\n")
 	}
 
-	if len(results.ExactMatches) == 0 && len(results.PartialMatches) == 0 && len(results.SimilarCommands) == 0 {
+	if len(allMatches) == 0 {
 		output.WriteString(color.New(color.FgRed).Sprint("❌ No matches found for: ") + query + "\n\n")
 		output.WriteString("💡 Try:\n")
 		output.WriteString("  - lookup -m (interactive menu)\n")
@@ -174,7 +186,7 @@ func (l *LookupCommand) Execute(ctx context.Context, args *commands.Arguments) (
 	}
 
 	// Add interactive suggestion
-	if len(results.ExactMatches) > 0 || len(results.PartialMatches) > 0 || len(results.SimilarCommands) > 0 {
+	if len(allMatches) > 0 {
 		output.WriteString("💡 Use 'lookup -m' for an interactive menu to explore commands\n")
 	}
 
@@ -189,10 +201,14 @@ func (l *LookupCommand) Execute(ctx context.Context, args *commands.Arguments) (
 
 // LookupResult contains the results of a command lookup
 type LookupResult struct {
-	ExactMatches    []CommandInfo
-	PartialMatches  []CommandInfo
-	SimilarCommands []CommandInfo
-	Suggestions     []string
+	ExactMatches []CommandInfo
+	// PartialMatches have the query in their name, those starting with it first
+	PartialMatches []CommandInfo
+	// DescriptionMatches have the query in their description, or failing
+	// that in their help text
+	DescriptionMatches []CommandInfo
+	SimilarCommands    []CommandInfo
+	Suggestions        []string
 }
 
 // CommandInfo contains information about a command
@@ -201,6 +217,8 @@ type CommandInfo struct {
 	Description string
 	Category    string
 	Usage       string
+	// Example is the first example in the command's help, if it has one
+	Example string
 }
 
 // performLookup performs the actual intelligent lookup
@@ -208,49 +226,82 @@ func (l *LookupCommand) performLookup(query string, includeSimilar bool) LookupR
 	result := LookupResult{}
 	query = strings.ToLower(query)
 
-	allCommands := l.registry.GetAllCommands()
-
-	for _, cmd := range allCommands {
+	var prefixMatches, nameMatches, helpMatches []CommandInfo
+	for _, cmd := range l.registry.GetAllCommands() {
 		cmdInfo := CommandInfo{
 			Name:        cmd.Name(),
 			Description: cmd.Description(),
 			Category:    l.getCommandCategory(cmd.Name()),
 			Usage:       cmd.Usage(),
+			Example:     firstExample(cmd.Name()),
 		}
 
 		cmdName := strings.ToLower(cmd.Name())
 		cmdDesc := strings.ToLower(cmd.Description())
 
-		// Exact match
-		if cmdName == query {
+		switch {
+		case cmdName == query:
 			result.ExactMatches = append(result.ExactMatches, cmdInfo)
-			continue
-		}
-
-		// Partial name match
-		if strings.Contains(cmdName, query) {
-			result.PartialMatches = append(result.PartialMatches, cmdInfo)
-			continue
-		}
-
-		// Description match
-		if strings.Contains(cmdDesc, query) {
-			result.PartialMatches = append(result.PartialMatches, cmdInfo)
-			continue
-		}
-
-		// Similar commands (fuzzy matching) - always check for better user experience
-		if l.isSimilar(cmdName, query) {
+		case strings.HasPrefix(cmdName, query):
+			prefixMatches = append(prefixMatches, cmdInfo)
+		case strings.Contains(cmdName, query):
+			nameMatches = append(nameMatches, cmdInfo)
+		case strings.Contains(cmdDesc, query):
+			result.DescriptionMatches = append(result.DescriptionMatches, cmdInfo)
+		case strings.Contains(strings.ToLower(helpText(cmd)), query):
+			helpMatches = append(helpMatches, cmdInfo)
+		case l.isSimilar(cmdName, query):
+			// Similar commands (fuzzy matching) - always check for better user experience
 			result.SimilarCommands = append(result.SimilarCommands, cmdInfo)
 		}
 	}
 
+	// The registry has no order, so sort each group by name
+	for _, group := range [][]CommandInfo{prefixMatches, nameMatches, result.DescriptionMatches, helpMatches, result.SimilarCommands} {
+		sort.Slice(group, func(i, j int) bool { return group[i].Name < group[j].Name })
+	}
+	result.PartialMatches = append(prefixMatches, nameMatches...)
+	result.DescriptionMatches = append(result.DescriptionMatches, helpMatches...)
+
 	// Generate smart suggestions
 	result.Suggestions = l.generateSuggestions(query)
 
 	return result
 }
 
+// helpText returns everything help shows about a command beyond its name and
+// description: the summary from completion, the detailed help and its flags
+func helpText(cmd commands.Command) string {
+	text := []string{commands.GetCommandHelp()[cmd.Name()], cmd.Usage()}
+	if details := detailedHelp(cmd.Name()); details != noDetailedHelp {
+		text = append(text, details)
+	}
+	if declarer, ok := cmd.(commands.FlagDeclarer); ok {
+		for _, spec := range declarer.FlagSet().Specs() {
+			text = append(text, spec.Name+" "+spec.Help)
+		}
+	}
+	return strings.Join(text, "\n")
+}
+
+// firstExample returns the first line of the Examples section of a command's
+// detailed help, or "" when it has none
+func firstExample(name string) string {
+	inExamples := false
+	for _, line := range strings.Split(detailedHelp(name), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if !inExamples {
+			inExamples = trimmed == "Examples:"
+			continue
+		}
+		if trimmed == "" {
+			return ""
+		}
+		return trimmed
+	}
+	return ""
+}
+
 // taskBasedLookup provides task-based command suggestions
 func (l *LookupCommand) taskBasedLookup(task string, startTime time.Time) (*commands.Result, error) {
 	var output strings.Builder
@@ -409,6 +460,7 @@ func (l *LookupCommand) getTaskSuggestions(task string) []TaskSuggestion {
 		},
 		"security": {
 			{"sniff", "Packet capture", "sniff -c 10 -p HTTP"},
+			{"trace", "File and network activity of a process", "trace -p 1234 --failed"},
 			{"portscan", "Port scanning", "portscan target.com"},
 			{"netstat", "Network monitoring", "netstat -an"},
 			{"killtask", "Process management", "killtask suspicious_process"},
@@ -430,9 +482,9 @@ func (l *LookupCommand) getTaskSuggestions(task string) []TaskSuggestion {
 
 // getCommandCategory returns the category of a command
 func (l *LookupCommand) getCommandCategory(name string) string {
-	systemCommands := []string{"help", "clear", "sysinfo", "whoami", "hostname", "exit", "ver", "helphtml", "winupdate", "killtask", "lookup"}
-	fsCommands := []string{"pwd", "ls", "dir", "echo", "cd", "cat", "mkdir", "rm", "rmdir", "cp", "mv"}
-	advancedTools := []string{"fastcp-send", "fastcp-recv", "fastcp-backup", "fastcp-restore", "fastcp-dedup", "netdiscover", "sniff"}
+	systemCommands := []string{"help", "clear", "sysinfo", "whoami", "hostname", "exit", "ver", "helphtml", "winupdate", "killtask", "priv", "runas", "audit", "lookup", "profile", "retry", "each", "source", "diag"}
+	fsCommands := []string{"pwd", "ls", "dir", "echo", "cd", "z", "cat", "mkdir", "rm", "rmdir", "cp", "mv", "undo", "browse", "watchdir", "json", "highlight"}
+	advancedTools := []string{"fastcp-send", "fastcp-recv", "fastcp-backup", "fastcp-restore", "fastcp-dedup", "fastcp-key", "netdiscover", "sniff", "trace", "replay", "capstats", "capabilities"}
 
 	for _, cmd := range systemCommands {
 		if cmd == name {
@@ -537,17 +589,43 @@ func (l *LookupCommand) generateSuggestions(query string) []string {
 
 	// Common typos and alternatives
 	alternatives := map[string][]string{
-		"list":    {"ls", "dir"},
-		"copy":    {"cp"},
-		"move":    {"mv"},
-		"delete":  {"rm"},
-		"remove":  {"rm", "rmdir"},
-		"network": {"ping", "netstat", "nslookup"},
-		"info":    {"sysinfo", "whoami", "hostname"},
-		"kill":    {"killtask"},
-		"process": {"killtask", "sysinfo"},
-		"file":    {"ls", "cat", "cp", "mv"},
-		"test":    {"ping", "speedtest", "portscan"},
+		"list":     {"ls", "dir"},
+		"copy":     {"cp"},
+		"move":     {"mv"},
+		"delete":   {"rm"},
+		"remove":   {"rm", "rmdir"},
+		"restore":  {"undo"},
+		"pick":     {"browse"},
+		"watch":    {"watchdir"},
+		"jq":       {"json"},
+		"grep":     {"highlight"},
+		"colorize": {"highlight"},
+		"sync":     {"watchdir", "fastcp-send"},
+		"network":  {"ping", "netstat", "nslookup"},
+		"nethogs":  {"top-connections"},
+		"traffic":  {"top-connections", "netstat"},
+		"info":     {"sysinfo", "whoami", "hostname"},
+		"kill":     {"killtask"},
+		"sudo":     {"priv", "runas"},
+		"su":       {"runas"},
+		"wait":     {"wait-port", "retry"},
+		"color":    {"diag"},
+		"unicode":  {"diag"},
+		"again":    {"retry"},
+		"xargs":    {"each"},
+		"autojump": {"z"},
+		"zoxide":   {"z"},
+		"jump":     {"z", "cd"},
+		"strace":   {"trace"},
+		"ltrace":   {"trace"},
+		"procmon":  {"trace"},
+		"foreach":  {"each"},
+		"script":   {"source"},
+		"run":      {"source"},
+		"admin":    {"priv"},
+		"process":  {"killtask", "sysinfo"},
+		"file":     {"ls", "cat", "cp", "mv"},
+		"test":     {"ping", "speedtest", "portscan"},
 	}
 
 	if alts, exists := alternatives[query]; exists {
@@ -733,6 +811,16 @@ func (l *LookupCommand) getPopularCommands() []PopularCommand {
 			Description: "Show network connections",
 			Example:     "netstat -an",
 		},
+		{
+			Name:        "wait-port",
+			Description: "Wait until a TCP port accepts connections",
+			Example:     "wait-port localhost:5432 --timeout 60s",
+		},
+		{
+			Name:        "top-connections",
+			Description: "Show which processes use the most bandwidth",
+			Example:     "top-connections --interval 1",
+		},
 		{
 			Name:        "speedtest",
 			Description: "Test internet connection speed",
@@ -0,0 +1,67 @@
+package system
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+
+	"github.com/fatih/color"
+)
+
+// NotifyCommand raises a desktop notification
+type NotifyCommand struct {
+	*commands.BaseCommand
+}
+
+// NewNotifyCommand creates a new notify command
+func NewNotifyCommand() *NotifyCommand {
+	return &NotifyCommand{
+		BaseCommand: commands.NewBaseCommand(
+			"notify",
+			"Show a desktop notification (add --notify to any command to be notified when it finishes)",
+			"notify [--title <title>] <message...>",
+			[]string{"windows", "linux", "darwin"},
+			false,
+		),
+	}
+}
+
+// Execute sends the notification, warning instead of failing when the desktop has none
+func (n *NotifyCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	startTime := time.Now()
+
+	title := "SuperShell"
+	var words []string
+	for i := 0; i < len(args.Raw); i++ {
+		if args.Raw[i] == "--title" && i+1 < len(args.Raw) {
+			title = args.Raw[i+1]
+			i++
+			continue
+		}
+		words = append(words, args.Raw[i])
+	}
+
+	if len(words) == 0 {
+		return &commands.Result{
+			Output:   "Usage: " + n.Usage() + "\n",
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}, nil
+	}
+
+	if err := commands.SendNotification(ctx, title, strings.Join(words, " ")); err != nil {
+		return &commands.Result{
+			Output:   color.New(color.FgYellow).Sprintf("⚠️  Notification not shown: %v\n", err),
+			ExitCode: 0,
+			Duration: time.Since(startTime),
+		}, nil
+	}
+
+	return &commands.Result{
+		Output:   color.New(color.FgGreen).Sprint("🔔 Notification sent\n"),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}, nil
+}
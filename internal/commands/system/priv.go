@@ -0,0 +1,220 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"os/user"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+
+	"github.com/fatih/color"
+)
+
+// PrivCommand reports the shell's privileges and runs commands elevated
+type PrivCommand struct {
+	*commands.BaseCommand
+	registry  *commands.Registry
+	elevation commands.Elevation
+}
+
+// NewPrivCommand creates a priv command elevating through sudo, pkexec or UAC
+func NewPrivCommand(registry *commands.Registry) *PrivCommand {
+	return NewPrivCommandWithElevation(registry, commands.DefaultElevation())
+}
+
+// NewPrivCommandWithElevation creates a priv command checking and gaining
+// privileges through elevation
+func NewPrivCommandWithElevation(registry *commands.Registry, elevation commands.Elevation) *PrivCommand {
+	return &PrivCommand{
+		BaseCommand: commands.NewBaseCommand(
+			"priv",
+			"Show the shell's privilege level and run commands elevated",
+			"priv [status] | priv elevate [command...]",
+			[]string{"windows", "linux", "darwin"},
+			false,
+		),
+		registry:  registry,
+		elevation: elevation,
+	}
+}
+
+// CompleteArguments suggests the subcommands, then command names after elevate
+func (p *PrivCommand) CompleteArguments(args []string) []string {
+	switch {
+	case len(args) <= 1:
+		return []string{"status", "elevate"}
+	case len(args) == 2 && args[0] == "elevate" && p.registry != nil:
+		names := p.registry.List()
+		sort.Strings(names)
+		return names
+	}
+	return nil
+}
+
+// Execute shows the privilege status by default. `priv elevate <command...>`
+// runs the command in an elevated SuperShell, e.g. `priv elevate winupdate
+// install`, and `priv elevate` alone opens an elevated shell.
+func (p *PrivCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	startTime := time.Now()
+
+	action, rest := "status", []string(nil)
+	if len(args.Raw) > 0 {
+		action, rest = args.Raw[0], args.Raw[1:]
+	}
+
+	switch action {
+	case "status":
+		if len(rest) > 0 {
+			return commands.UsageResult(p, startTime, "status takes no arguments"), nil
+		}
+		return p.status(startTime)
+	case "elevate":
+		if len(rest) == 0 {
+			return p.elevateShell(ctx, startTime)
+		}
+		return p.elevate(ctx, JoinCommandWords(rest), startTime)
+	default:
+		return commands.UsageResult(p, startTime, "unknown subcommand '%s'", action), nil
+	}
+}
+
+// status shows who the shell runs as, whether it is elevated and which
+// commands need it to be
+func (p *PrivCommand) status(startTime time.Time) (*commands.Result, error) {
+	var output strings.Builder
+	output.WriteString(color.New(color.FgCyan, color.Bold).Sprint("🔐 PRIVILEGE STATUS\n"))
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+
+	name := "unknown"
+	if current, err := user.Current(); err == nil {
+		name = current.Username
+	}
+	output.WriteString(fmt.Sprintf("User:        %s\n", name))
+	output.WriteString(fmt.Sprintf("Platform:    %s\n", runtime.GOOS))
+
+	elevated := p.elevation.IsElevated()
+	if elevated {
+		output.WriteString("Level:       " + color.New(color.FgGreen).Sprint("✅ Elevated (administrator/root)") + "\n")
+	} else {
+		output.WriteString("Level:       " + color.New(color.FgYellow).Sprint("⚠️  Standard user") + "\n")
+	}
+	if method, err := p.elevation.Method(); err == nil {
+		output.WriteString(fmt.Sprintf("Elevation:   available through %s\n", method))
+	} else {
+		output.WriteString(fmt.Sprintf("Elevation:   %s\n", color.New(color.FgRed).Sprintf("unavailable, %v", err)))
+	}
+
+	if needing := p.elevatedCommands(); len(needing) > 0 {
+		output.WriteString("───────────────────────────────────────────────────────────────\n")
+		output.WriteString(fmt.Sprintf("Commands needing elevation: %s\n", strings.Join(needing, ", ")))
+	}
+	if !elevated {
+		output.WriteString(color.New(color.FgHiBlack).Sprint("💡 Run one elevated with 'priv elevate <command> [args...]', or open an elevated shell with 'priv elevate'\n"))
+	}
+
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}, nil
+}
+
+// elevatedCommands lists the registered commands that need elevation, marking
+// those that only need it for some actions
+func (p *PrivCommand) elevatedCommands() []string {
+	if p.registry == nil {
+		return nil
+	}
+	var names []string
+	for _, cmd := range p.registry.GetAllCommands() {
+		if !cmd.RequiresElevation() {
+			continue
+		}
+		if _, partial := cmd.(commands.ElevationPolicy); partial {
+			names = append(names, cmd.Name()+" (some actions)")
+		} else {
+			names = append(names, cmd.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// elevate runs a command line elevated. In an elevated shell it simply runs
+// here; otherwise a new elevated shell runs it with its output streamed back.
+func (p *PrivCommand) elevate(ctx context.Context, line string, startTime time.Time) (*commands.Result, error) {
+	words := commands.SplitCommandWords(line)
+	if len(words) == 0 {
+		return commands.UsageResult(p, startTime, "missing command after elevate"), nil
+	}
+	if p.registry == nil {
+		return p.failure(fmt.Errorf("command registry not available"), startTime)
+	}
+	if _, err := p.registry.Get(words[0].Text); err != nil {
+		return p.failure(fmt.Errorf("unknown command '%s'", words[0].Text), startTime)
+	}
+
+	if p.elevation.IsElevated() {
+		header := color.New(color.FgCyan).Sprintf("▶️  %s (already elevated)\n", line)
+		result, err := p.registry.ExecuteLine(ctx, line)
+		if result == nil {
+			return p.failure(err, startTime)
+		}
+		result.Output = header + result.Output
+		return result, err
+	}
+
+	method, err := p.elevation.Method()
+	if err != nil {
+		return p.failure(fmt.Errorf("cannot elevate: %v; %s", err, commands.ElevationHint()), startTime)
+	}
+	fmt.Fprint(commands.ProgressWriter(ctx), color.New(color.FgCyan).Sprintf("🔐 Running elevated with %s: %s\n", method, line))
+	code, err := p.elevation.Run(ctx, line)
+	if err != nil {
+		return p.failure(err, startTime)
+	}
+	return &commands.Result{
+		Output:   "",
+		ExitCode: code,
+		Duration: time.Since(startTime),
+	}, nil
+}
+
+// elevateShell opens an interactive elevated SuperShell
+func (p *PrivCommand) elevateShell(ctx context.Context, startTime time.Time) (*commands.Result, error) {
+	if p.elevation.IsElevated() {
+		return &commands.Result{
+			Output:   color.New(color.FgGreen).Sprint("✅ This shell is already elevated\n"),
+			ExitCode: 0,
+			Duration: time.Since(startTime),
+		}, nil
+	}
+
+	method, err := p.elevation.Method()
+	if err != nil {
+		return p.failure(fmt.Errorf("cannot elevate: %v; %s", err, commands.ElevationHint()), startTime)
+	}
+	note := "exit it to come back here"
+	if method == "UAC" {
+		note = "it opens in a new console"
+	}
+	fmt.Fprint(commands.ProgressWriter(ctx), color.New(color.FgCyan).Sprintf("🔐 Opening an elevated shell with %s; %s\n", method, note))
+	code, err := p.elevation.Run(ctx, "")
+	if err != nil {
+		return p.failure(err, startTime)
+	}
+	return &commands.Result{
+		Output:   "",
+		ExitCode: code,
+		Duration: time.Since(startTime),
+	}, nil
+}
+
+// failure reports an error that stopped the command
+func (p *PrivCommand) failure(err error, startTime time.Time) (*commands.Result, error) {
+	return commands.ErrorResult("", err, startTime), nil
+}
@@ -0,0 +1,493 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+
+	"github.com/fatih/color"
+)
+
+// Profile is a saved working context: the directory to work in, the
+// environment changes made for it and the bookmarks and favorites that go with
+// it
+type Profile struct {
+	Name      string            `json:"name"`
+	Dir       string            `json:"dir"`
+	Env       map[string]string `json:"env,omitempty"`
+	Unset     []string          `json:"unset,omitempty"`
+	Bookmarks []Bookmark        `json:"bookmarks,omitempty"`
+	Favorites []Favorite        `json:"favorites,omitempty"`
+	Saved     time.Time         `json:"saved"`
+}
+
+// profileVolatileEnv are variables that describe the moment rather than the
+// context, so they are never saved
+var profileVolatileEnv = map[string]bool{"PWD": true, "OLDPWD": true, "SHLVL": true, "_": true}
+
+// ValidProfileName reports whether a name can be typed without quoting
+func ValidProfileName(name string) bool {
+	return favoriteLabelPattern.MatchString(name)
+}
+
+// FindProfile returns the index of the named profile, or -1
+func FindProfile(profiles []Profile, name string) int {
+	for i, profile := range profiles {
+		if profile.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// LoadProfiles reads the profiles file; a missing file has no profiles
+func LoadProfiles(path string) ([]Profile, error) {
+	profiles := make([]Profile, 0)
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return profiles, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return profiles, nil
+	}
+	if err := json.Unmarshal(data, &profiles); err != nil {
+		return nil, fmt.Errorf("corrupt profiles file %s: %w", path, err)
+	}
+	return profiles, nil
+}
+
+// SaveProfiles writes the profiles file
+func SaveProfiles(path string, profiles []Profile) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(profiles, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// ProfileCommand saves and restores working contexts so switching between
+// projects is a single command
+type ProfileCommand struct {
+	*commands.BaseCommand
+	flags        *commands.FlagSet
+	file         string
+	bookmarkFile string
+	favoriteFile string
+	// baseline is the environment the shell started with; a profile saves the
+	// differences from it
+	baseline map[string]string
+	// active is the loaded profile and restore undoes its environment changes
+	active  string
+	restore map[string]*string
+}
+
+// NewProfileCommand creates a profile command storing profiles under
+// ~/.supershell. It must be created at startup, as the environment then is what
+// profiles are compared with.
+func NewProfileCommand() *ProfileCommand {
+	homeDir, _ := os.UserHomeDir()
+	usage := "profile [list] | profile save <name> [--env KEY=VALUE]... | profile load <name> | profile delete <name>"
+	return &ProfileCommand{
+		BaseCommand: commands.NewBaseCommand(
+			"profile",
+			"Save and restore working contexts: directory, environment, bookmarks and favorites",
+			usage,
+			[]string{"windows", "linux", "darwin"},
+			false,
+		),
+		flags: commands.NewFlagSet("profile", usage,
+			commands.FlagSpec{Name: "env", Short: "e", Kind: commands.ListFlag, Value: "KEY=VALUE", Help: "Also save this variable with the profile"},
+		),
+		file:         filepath.Join(homeDir, ".supershell", "profiles.json"),
+		bookmarkFile: filepath.Join(homeDir, ".supershell_bookmarks.json"),
+		favoriteFile: filepath.Join(homeDir, ".supershell", "favorites.json"),
+		baseline:     environMap(os.Environ()),
+	}
+}
+
+// FlagSet returns the options profile accepts
+func (p *ProfileCommand) FlagSet() *commands.FlagSet {
+	return p.flags
+}
+
+// CompleteArguments suggests subcommands, then profile names
+func (p *ProfileCommand) CompleteArguments(args []string) []string {
+	if len(args) <= 1 {
+		return []string{"list", "save", "load", "delete"}
+	}
+	profiles, err := LoadProfiles(p.file)
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(profiles))
+	for _, profile := range profiles {
+		names = append(names, profile.Name)
+	}
+	return names
+}
+
+// Execute dispatches the profile subcommands
+func (p *ProfileCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	startTime := time.Now()
+
+	flags, result := p.flags.ParseArguments(args, startTime)
+	if result != nil {
+		return result, nil
+	}
+	if flags.Changed("env") && flags.Arg(0) != "save" {
+		return commands.UsageResult(p, startTime, "--env only applies to profile save"), nil
+	}
+
+	switch flags.Arg(0) {
+	case "", "list", "ls":
+		return p.list(startTime), nil
+	case "save", "load", "delete", "remove", "rm":
+		if len(flags.Args()) != 2 {
+			return commands.UsageResult(p, startTime, "%s needs exactly one profile name", flags.Arg(0)), nil
+		}
+	default:
+		return commands.UsageResult(p, startTime, "unknown subcommand '%s'", flags.Arg(0)), nil
+	}
+
+	name := flags.Arg(1)
+	switch flags.Arg(0) {
+	case "save":
+		return p.save(name, flags.Strings("env"), startTime), nil
+	case "load":
+		return p.load(name, startTime), nil
+	default:
+		return p.delete(name, startTime), nil
+	}
+}
+
+// save snapshots the current context under name, replacing any profile of
+// that name
+func (p *ProfileCommand) save(name string, extra []string, startTime time.Time) *commands.Result {
+	if !ValidProfileName(name) {
+		return commands.ErrorResult("", fmt.Errorf("invalid profile name '%s': use letters, digits, '.', '_' and '-'", name), startTime)
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return commands.ErrorResult("", err, startTime)
+	}
+	profile := Profile{Name: name, Dir: dir, Saved: time.Now()}
+	profile.Env, profile.Unset = p.environmentChanges()
+	for _, assignment := range extra {
+		key, value, ok := splitAssignment(assignment)
+		if !ok {
+			return commands.ErrorResult("", fmt.Errorf("invalid --env '%s': expected KEY=VALUE", assignment), startTime)
+		}
+		if profile.Env == nil {
+			profile.Env = make(map[string]string)
+		}
+		profile.Env[key] = value
+	}
+	if profile.Bookmarks, err = LoadBookmarks(p.bookmarkFile); err != nil {
+		return commands.ErrorResult("", fmt.Errorf("failed to read bookmarks: %w", err), startTime)
+	}
+	if profile.Favorites, err = LoadFavorites(p.favoriteFile); err != nil {
+		return commands.ErrorResult("", fmt.Errorf("failed to read favorites: %w", err), startTime)
+	}
+
+	profiles, err := LoadProfiles(p.file)
+	if err != nil {
+		return commands.ErrorResult("", err, startTime)
+	}
+	verb := "Saved"
+	if i := FindProfile(profiles, name); i >= 0 {
+		profiles[i] = profile
+		verb = "Updated"
+	} else {
+		profiles = append(profiles, profile)
+	}
+	if err := SaveProfiles(p.file, profiles); err != nil {
+		return commands.ErrorResult("", err, startTime)
+	}
+	p.active = name
+
+	var output strings.Builder
+	output.WriteString(color.New(color.FgGreen).Sprintf("✅ %s profile '%s'\n", verb, name))
+	p.describe(&output, profile)
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}
+}
+
+// load switches to a saved context. The environment changes of a profile
+// loaded earlier are undone first, so switching doesn't pile them up.
+// Bookmarks and favorites are merged: the profile's replace those of the same
+// name and the rest stay.
+func (p *ProfileCommand) load(name string, startTime time.Time) *commands.Result {
+	profiles, err := LoadProfiles(p.file)
+	if err != nil {
+		return commands.ErrorResult("", err, startTime)
+	}
+	i := FindProfile(profiles, name)
+	if i < 0 {
+		return commands.ErrorResult("", fmt.Errorf("no profile named '%s'", name), startTime)
+	}
+	profile := profiles[i]
+
+	// Check the directory before changing anything
+	if info, err := os.Stat(profile.Dir); err != nil {
+		return commands.ErrorResult("", commands.FileError(p.Name(), profile.Dir, err), startTime)
+	} else if !info.IsDir() {
+		return commands.ErrorResult("", commands.FileError(p.Name(), profile.Dir, fmt.Errorf("not a directory")), startTime)
+	}
+	if err := os.Chdir(profile.Dir); err != nil {
+		return commands.ErrorResult("", commands.FileError(p.Name(), profile.Dir, err), startTime)
+	}
+
+	p.undoEnvironment()
+	p.restore = make(map[string]*string)
+	for key, value := range profile.Env {
+		p.remember(key)
+		os.Setenv(key, value)
+	}
+	for _, key := range profile.Unset {
+		p.remember(key)
+		os.Unsetenv(key)
+	}
+	p.active = name
+
+	var output strings.Builder
+	output.WriteString(color.New(color.FgGreen).Sprintf("✅ Loaded profile '%s'\n", name))
+	output.WriteString(fmt.Sprintf("📂 Directory:   %s\n", profile.Dir))
+	output.WriteString(fmt.Sprintf("🌱 Environment: %d set, %d unset\n", len(profile.Env), len(profile.Unset)))
+
+	if len(profile.Bookmarks) > 0 {
+		restored, err := p.restoreBookmarks(profile.Bookmarks)
+		if err != nil {
+			output.WriteString(color.New(color.FgRed).Sprintf("❌ Bookmarks not restored: %v\n", err))
+		} else {
+			output.WriteString(fmt.Sprintf("🔖 Bookmarks:   %s\n", restored))
+		}
+	}
+	if len(profile.Favorites) > 0 {
+		restored, err := p.restoreFavorites(profile.Favorites)
+		if err != nil {
+			output.WriteString(color.New(color.FgRed).Sprintf("❌ Favorites not restored: %v\n", err))
+		} else {
+			output.WriteString(fmt.Sprintf("⭐ Favorites:   %s\n", restored))
+		}
+	}
+
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}
+}
+
+// delete removes a saved profile; the current context is left as it is
+func (p *ProfileCommand) delete(name string, startTime time.Time) *commands.Result {
+	profiles, err := LoadProfiles(p.file)
+	if err != nil {
+		return commands.ErrorResult("", err, startTime)
+	}
+	i := FindProfile(profiles, name)
+	if i < 0 {
+		return commands.ErrorResult("", fmt.Errorf("no profile named '%s'", name), startTime)
+	}
+	profiles = append(profiles[:i], profiles[i+1:]...)
+	if err := SaveProfiles(p.file, profiles); err != nil {
+		return commands.ErrorResult("", err, startTime)
+	}
+	if p.active == name {
+		p.active = ""
+	}
+	return &commands.Result{
+		Output:   color.New(color.FgGreen).Sprintf("🗑️  Deleted profile '%s'\n", name),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}
+}
+
+// list shows the saved profiles, marking the one in use
+func (p *ProfileCommand) list(startTime time.Time) *commands.Result {
+	profiles, err := LoadProfiles(p.file)
+	if err != nil {
+		return commands.ErrorResult("", err, startTime)
+	}
+
+	var output strings.Builder
+	output.WriteString(color.New(color.FgCyan, color.Bold).Sprint("🗂️  PROFILES\n"))
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+	if len(profiles) == 0 {
+		output.WriteString(color.New(color.FgHiBlack).Sprint("No profiles yet; save the current context with: profile save <name>\n"))
+	}
+	for _, profile := range profiles {
+		marker := "  "
+		if profile.Name == p.active {
+			marker = color.New(color.FgGreen).Sprint("▶ ")
+		}
+		output.WriteString(fmt.Sprintf("%s%s  %s\n", marker, color.New(color.FgYellow, color.Bold).Sprint(profile.Name), profile.Dir))
+		output.WriteString(color.New(color.FgHiBlack).Sprintf("    %d env, %d bookmarks, %d favorites, saved %s\n",
+			len(profile.Env)+len(profile.Unset), len(profile.Bookmarks), len(profile.Favorites), profile.Saved.Local().Format("2006-01-02 15:04")))
+	}
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}
+}
+
+// describe lists what a profile holds
+func (p *ProfileCommand) describe(output *strings.Builder, profile Profile) {
+	output.WriteString(fmt.Sprintf("📂 Directory:   %s\n", profile.Dir))
+	if len(profile.Env) == 0 && len(profile.Unset) == 0 {
+		output.WriteString("🌱 Environment: no changes since the shell started\n")
+	}
+	keys := make([]string, 0, len(profile.Env))
+	for key := range profile.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		output.WriteString(fmt.Sprintf("🌱 %s=%s\n", key, profile.Env[key]))
+	}
+	for _, key := range profile.Unset {
+		output.WriteString(fmt.Sprintf("🌱 unset %s\n", key))
+	}
+	output.WriteString(fmt.Sprintf("🔖 Bookmarks:   %d\n", len(profile.Bookmarks)))
+	output.WriteString(fmt.Sprintf("⭐ Favorites:   %d\n", len(profile.Favorites)))
+}
+
+// environmentChanges returns the variables set or changed since the shell
+// started and those removed
+func (p *ProfileCommand) environmentChanges() (map[string]string, []string) {
+	current := environMap(os.Environ())
+	var set map[string]string
+	for key, value := range current {
+		if profileVolatileEnv[key] {
+			continue
+		}
+		if old, ok := p.baseline[key]; !ok || old != value {
+			if set == nil {
+				set = make(map[string]string)
+			}
+			set[key] = value
+		}
+	}
+	var unset []string
+	for key := range p.baseline {
+		if _, ok := current[key]; !ok && !profileVolatileEnv[key] {
+			unset = append(unset, key)
+		}
+	}
+	sort.Strings(unset)
+	return set, unset
+}
+
+// remember records a variable's value before a profile changes it, once
+func (p *ProfileCommand) remember(key string) {
+	if _, ok := p.restore[key]; ok {
+		return
+	}
+	if value, ok := os.LookupEnv(key); ok {
+		p.restore[key] = &value
+		return
+	}
+	p.restore[key] = nil
+}
+
+// undoEnvironment puts back the variables the loaded profile changed
+func (p *ProfileCommand) undoEnvironment() {
+	for key, value := range p.restore {
+		if value == nil {
+			os.Unsetenv(key)
+			continue
+		}
+		os.Setenv(key, *value)
+	}
+	p.restore = nil
+}
+
+// restoreBookmarks merges a profile's bookmarks into the bookmark file
+func (p *ProfileCommand) restoreBookmarks(saved []Bookmark) (string, error) {
+	existing, err := LoadBookmarks(p.bookmarkFile)
+	if err != nil {
+		return "", err
+	}
+	added, replaced := 0, 0
+	for _, bookmark := range saved {
+		found := false
+		for i := range existing {
+			if existing[i].Name == bookmark.Name {
+				existing[i] = bookmark
+				found = true
+				break
+			}
+		}
+		if found {
+			replaced++
+			continue
+		}
+		existing = append(existing, bookmark)
+		added++
+	}
+	if err := SaveBookmarks(p.bookmarkFile, existing); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d added, %d restored", added, replaced), nil
+}
+
+// restoreFavorites merges a profile's favorites into the favorites file
+func (p *ProfileCommand) restoreFavorites(saved []Favorite) (string, error) {
+	existing, err := LoadFavorites(p.favoriteFile)
+	if err != nil {
+		return "", err
+	}
+	added, replaced := 0, 0
+	for _, favorite := range saved {
+		if i := FindFavorite(existing, favorite.Label); i >= 0 {
+			existing[i] = favorite
+			replaced++
+			continue
+		}
+		existing = append(existing, favorite)
+		added++
+	}
+	if err := SaveFavorites(p.favoriteFile, existing); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d added, %d restored", added, replaced), nil
+}
+
+// environMap turns KEY=VALUE pairs into a map. Windows keeps per-drive
+// directories in variables starting with '=', which are skipped.
+func environMap(environ []string) map[string]string {
+	env := make(map[string]string, len(environ))
+	for _, assignment := range environ {
+		if key, value, ok := splitAssignment(assignment); ok {
+			env[key] = value
+		}
+	}
+	return env
+}
+
+// splitAssignment splits KEY=VALUE, rejecting an empty key
+func splitAssignment(assignment string) (string, string, bool) {
+	i := strings.Index(assignment, "=")
+	if i <= 0 {
+		return "", "", false
+	}
+	return assignment[:i], assignment[i+1:], true
+}
@@ -0,0 +1,186 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"suppercommand/internal/commands"
+	"suppercommand/pkg/errors"
+
+	"github.com/fatih/color"
+)
+
+// RetryCommand re-runs a command until it succeeds, or fails with
+// --until-failure, giving up after a number of attempts. Unlike a fixed repeat
+// the condition is the command's exit code, so it suits waiting for a service
+// to come up or riding out a flaky download.
+type RetryCommand struct {
+	*commands.BaseCommand
+	flags    *commands.FlagSet
+	registry *commands.Registry
+}
+
+// NewRetryCommand creates a retry command running commands through registry
+func NewRetryCommand(registry *commands.Registry) *RetryCommand {
+	usage := "retry [--attempts <n>] [--delay <duration>] [--backoff <factor>] [--max-delay <duration>] [--until-success | --until-failure] <command...>"
+	return &RetryCommand{
+		BaseCommand: commands.NewBaseCommand(
+			"retry",
+			"Re-run a command until it succeeds or runs out of attempts",
+			usage,
+			[]string{"windows", "linux", "darwin"},
+			false,
+		),
+		flags: commands.NewFlagSet("retry", usage,
+			commands.FlagSpec{Name: "attempts", Short: "n", Kind: commands.IntFlag, Default: "3", Value: "n", Help: "Give up after this many runs"},
+			commands.FlagSpec{Name: "delay", Short: "d", Kind: commands.StringFlag, Default: "1s", Value: "duration", Help: "Wait between attempts, e.g. 500ms or 3s"},
+			commands.FlagSpec{Name: "backoff", Short: "b", Kind: commands.FloatFlag, Default: "1", Value: "factor", Help: "Multiply the delay by this after each attempt, e.g. 2 to double it"},
+			commands.FlagSpec{Name: "max-delay", Kind: commands.StringFlag, Value: "duration", Help: "Never wait longer than this between attempts"},
+			commands.FlagSpec{Name: "until-success", Help: "Stop at the first run that exits 0 (the default)"},
+			commands.FlagSpec{Name: "until-failure", Help: "Stop at the first run that fails instead"},
+		),
+		registry: registry,
+	}
+}
+
+// FlagSet returns the options retry accepts
+func (r *RetryCommand) FlagSet() *commands.FlagSet {
+	return r.flags
+}
+
+// retrySchedule is how many times retry runs a command and how long it waits
+// in between
+type retrySchedule struct {
+	attempts int
+	delay    time.Duration
+	backoff  float64
+	maxDelay time.Duration
+}
+
+// next returns the wait after one that lasted delay
+func (s retrySchedule) next(delay time.Duration) time.Duration {
+	delay = time.Duration(float64(delay) * s.backoff)
+	if s.maxDelay > 0 && delay > s.maxDelay {
+		delay = s.maxDelay
+	}
+	return delay
+}
+
+// Execute runs the command until the condition holds. The result is that of
+// the last attempt, so retry passes its output and exit code through, and the
+// attempt count goes to stderr.
+func (r *RetryCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	startTime := time.Now()
+
+	// Options end at the first word of the retried command so its own flags pass through
+	options, words := r.flags.SplitLeading(args.Raw)
+	flags, result := r.flags.ParseArguments(commands.ParseArguments(options), startTime)
+	if result != nil {
+		return result, nil
+	}
+	if len(words) == 0 {
+		return commands.UsageResult(r, startTime, "expected a command to retry"), nil
+	}
+
+	schedule := retrySchedule{attempts: flags.Int("attempts"), backoff: flags.Float("backoff")}
+	var err error
+	if schedule.delay, err = time.ParseDuration(flags.String("delay")); err != nil || schedule.delay < 0 {
+		return commands.UsageResult(r, startTime, "invalid --delay '%s': expected a duration such as 500ms or 3s", flags.String("delay")), nil
+	}
+	if flags.Changed("max-delay") {
+		if schedule.maxDelay, err = time.ParseDuration(flags.String("max-delay")); err != nil || schedule.maxDelay <= 0 {
+			return commands.UsageResult(r, startTime, "invalid --max-delay '%s': expected a duration such as 30s or 2m", flags.String("max-delay")), nil
+		}
+	}
+	switch {
+	case schedule.attempts < 1:
+		return commands.UsageResult(r, startTime, "--attempts must be at least 1"), nil
+	case schedule.backoff < 1:
+		return commands.UsageResult(r, startTime, "--backoff must be at least 1"), nil
+	case flags.Bool("until-success") && flags.Bool("until-failure"):
+		return commands.UsageResult(r, startTime, "--until-success and --until-failure cannot be combined"), nil
+	}
+	untilFailure := flags.Bool("until-failure")
+
+	if r.registry == nil {
+		return commands.ErrorResult("", fmt.Errorf("command registry not available"), startTime), nil
+	}
+	if len(words) == 1 {
+		// The command may also be given as a single quoted line, expanded
+		// here as the prompt would have
+		line, err := r.registry.ExpandLine(words[0])
+		if err != nil {
+			return commands.ErrorResult("", err, startTime), nil
+		}
+		words = line
+	}
+	if _, err := r.registry.Get(words[0]); err != nil {
+		return commands.ErrorResult("", errors.NewNotFoundError("unknown command '%s'; retry runs SuperShell commands", words[0]), startTime), nil
+	}
+
+	line := commands.CommandLine(words[0], words[1:])
+	progress := commands.ProgressWriter(ctx)
+	delay := schedule.delay
+	var last *commands.Result
+	attempt := 0
+	for attempt < schedule.attempts {
+		attempt++
+		last = runLine(ctx, r.registry, line)
+		if (last.ExitCode == 0) != untilFailure {
+			break
+		}
+		if ctx.Err() != nil || attempt == schedule.attempts {
+			break
+		}
+
+		r.reportAttempt(progress, attempt, schedule.attempts, last, delay)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		delay = schedule.next(delay)
+	}
+
+	elapsed := time.Since(startTime).Round(time.Millisecond)
+	stopped := (last.ExitCode == 0) != untilFailure
+	var outcome string
+	switch {
+	case ctx.Err() != nil && !stopped:
+		outcome = color.New(color.FgYellow).Sprintf("⚠️  %s interrupted after %d of %d attempts (%v)\n", line, attempt, schedule.attempts, elapsed)
+		if last.Error == nil {
+			last.Error = errors.Wrap(ctx.Err(), "retry of %s interrupted", words[0])
+		}
+		if last.ExitCode == 0 {
+			last.ExitCode = 1
+		}
+	case stopped && untilFailure:
+		outcome = color.New(color.FgGreen).Sprintf("✅ %s failed on attempt %d of %d (%v)\n", line, attempt, schedule.attempts, elapsed)
+	case stopped:
+		outcome = color.New(color.FgGreen).Sprintf("✅ %s succeeded on attempt %d of %d (%v)\n", line, attempt, schedule.attempts, elapsed)
+	case untilFailure:
+		outcome = color.New(color.FgRed).Sprintf("❌ %s never failed in %d attempts (%v)\n", line, attempt, elapsed)
+	default:
+		outcome = color.New(color.FgRed).Sprintf("❌ %s still failing after %d attempts, exit code %d (%v)\n", line, attempt, last.ExitCode, elapsed)
+	}
+	if commands.OutputOptionsFrom(ctx).Decorated() {
+		last.Stderr += outcome
+	}
+	last.Duration = time.Since(startTime)
+	return last, nil
+}
+
+// reportAttempt notes an attempt that didn't end the retry, and the wait before the next
+func (r *RetryCommand) reportAttempt(progress io.Writer, attempt, attempts int, result *commands.Result, delay time.Duration) {
+	reason := fmt.Sprintf("exit code %d", result.ExitCode)
+	if result.Error != nil {
+		reason = result.Error.Error()
+	}
+	fmt.Fprint(progress, color.New(color.FgYellow).Sprintf("🔁 Attempt %d of %d: %s; retrying in %v\n", attempt, attempts, reason, delay))
+}
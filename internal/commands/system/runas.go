@@ -0,0 +1,182 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/security"
+	"suppercommand/pkg/errors"
+
+	"github.com/fatih/color"
+)
+
+// UserSwitch is how runas runs a command line as another user
+type UserSwitch struct {
+	// NeedsPassword reports whether the target user's password is needed, as
+	// it is on Windows, where the user is logged on, but not with sudo
+	NeedsPassword bool
+	// Method names the way commands are run as another user, failing if there is none
+	Method func() (string, error)
+	// ReadPassword prompts for the target user's password
+	ReadPassword func(prompt string) (string, error)
+	// LoadCredential returns a credential from the credential store
+	LoadCredential func(name string) (*security.Credential, error)
+	// Run runs a command line as the account with the given streams and returns its exit code
+	Run func(ctx context.Context, account security.Account, line string, stdin io.Reader, stdout, stderr io.Writer) (int, error)
+}
+
+// DefaultUserSwitch switches user with CreateProcessWithLogonW on Windows and
+// sudo -u elsewhere
+func DefaultUserSwitch() UserSwitch {
+	return UserSwitch{
+		NeedsPassword:  security.RunAsNeedsPassword,
+		Method:         security.RunAsMethod,
+		ReadPassword:   security.ReadSecret,
+		LoadCredential: security.LoadCredential,
+		Run:            security.RunAs,
+	}
+}
+
+// RunAsCommand runs a command as a different user, where priv elevate runs
+// it with more privileges: runas svc-backup sysinfo shows what the service
+// account sees.
+type RunAsCommand struct {
+	*commands.BaseCommand
+	flags    *commands.FlagSet
+	registry *commands.Registry
+	switcher UserSwitch
+}
+
+// NewRunAsCommand creates a runas command switching user the platform's way
+func NewRunAsCommand(registry *commands.Registry) *RunAsCommand {
+	return NewRunAsCommandWithSwitch(registry, DefaultUserSwitch())
+}
+
+// NewRunAsCommandWithSwitch creates a runas command switching user through switcher
+func NewRunAsCommandWithSwitch(registry *commands.Registry, switcher UserSwitch) *RunAsCommand {
+	usage := "runas [--cred <name>] <user> <command...>"
+	return &RunAsCommand{
+		BaseCommand: commands.NewBaseCommand(
+			"runas",
+			"Run a command as a different user",
+			usage,
+			[]string{"windows", "linux", "darwin"},
+			false,
+		),
+		flags: commands.NewFlagSet("runas", usage,
+			commands.FlagSpec{Name: "cred", Kind: commands.StringFlag, Value: "name", Help: "Take the user's Windows password from this stored credential instead of asking"},
+		),
+		registry: registry,
+		switcher: switcher,
+	}
+}
+
+// FlagSet returns the options runas accepts
+func (r *RunAsCommand) FlagSet() *commands.FlagSet {
+	return r.flags
+}
+
+// Execute runs the command line in a new SuperShell running as the user, with
+// its output streamed back, and exits with its exit code. On Windows the
+// user's password comes from --cred or a prompt; sudo asks for the caller's
+// own when its policy needs one.
+func (r *RunAsCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	startTime := time.Now()
+
+	// Options end at the user so the command's own flags pass through
+	options, words := r.flags.SplitLeading(args.Raw)
+	flags, result := r.flags.ParseArguments(commands.ParseArguments(options), startTime)
+	if result != nil {
+		return result, nil
+	}
+	if len(words) < 2 {
+		return commands.UsageResult(r, startTime, "expected a user and a command to run as them"), nil
+	}
+	account := security.ParseAccount(words[0])
+	if account.User == "" {
+		return commands.UsageResult(r, startTime, "invalid user '%s'", words[0]), nil
+	}
+	if flags.Changed("cred") && !r.switcher.NeedsPassword {
+		return commands.UsageResult(r, startTime, "--cred supplies a Windows logon password; sudo asks for your own password instead"), nil
+	}
+
+	line := JoinCommandWords(words[1:])
+	command := commands.SplitCommandWords(line)
+	if len(command) == 0 {
+		return commands.UsageResult(r, startTime, "expected a command to run as %s", account), nil
+	}
+	if r.registry == nil {
+		return commands.ErrorResult("", fmt.Errorf("command registry not available"), startTime), nil
+	}
+	if _, err := r.registry.Get(command[0].Text); err != nil {
+		return commands.ErrorResult("", errors.NewNotFoundError("unknown command '%s'; runas runs SuperShell commands", command[0].Text), startTime), nil
+	}
+
+	method, err := r.switcher.Method()
+	if err != nil {
+		return commands.ErrorResult("", fmt.Errorf("cannot run commands as another user: %v", err), startTime), nil
+	}
+	if r.switcher.NeedsPassword {
+		password, err := r.password(account, flags.String("cred"))
+		if err != nil {
+			return commands.ErrorResult("", err, startTime), nil
+		}
+		account.Password = password
+	}
+
+	fmt.Fprint(commands.ProgressWriter(ctx), color.New(color.FgCyan).Sprintf("👤 Running as %s with %s: %s\n", account, method, line))
+	code, err := r.switcher.Run(ctx, account, line, commands.InputReader(ctx), commands.OutputWriter(ctx), commands.ErrorWriter(ctx))
+	if err != nil {
+		return commands.ErrorResult("", err, startTime), nil
+	}
+
+	var stderr string
+	if commands.OutputOptionsFrom(ctx).Decorated() {
+		elapsed := time.Since(startTime).Round(time.Millisecond)
+		if code == 0 {
+			stderr = color.New(color.FgGreen).Sprintf("✅ Ran as %s, exit code 0 (%v)\n", account, elapsed)
+		} else {
+			stderr = color.New(color.FgRed).Sprintf("❌ Ran as %s, exit code %d (%v)\n", account, code, elapsed)
+		}
+	}
+	return &commands.Result{
+		Stderr:   stderr,
+		ExitCode: code,
+		Duration: time.Since(startTime),
+	}, nil
+}
+
+// password returns the user's password from the named credential, or asks
+// for it when no credential is named
+func (r *RunAsCommand) password(account security.Account, credential string) (string, error) {
+	if credential == "" {
+		password, err := r.switcher.ReadPassword(fmt.Sprintf("🔑 Password for %s: ", account))
+		if err != nil {
+			return "", err
+		}
+		if password == "" {
+			return "", errors.NewValidationError("no password given for %s", account)
+		}
+		return password, nil
+	}
+
+	stored, err := r.switcher.LoadCredential(credential)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to load credential '%s'", credential)
+	}
+	if name := stored.Get("username"); name != "" && !strings.EqualFold(security.ParseAccount(name).User, account.User) {
+		return "", errors.NewValidationError("credential '%s' is for %s, not %s", credential, name, account)
+	}
+	password := stored.Get("password")
+	if password == "" {
+		password = stored.Secret()
+	}
+	if password == "" {
+		return "", errors.NewValidationError("credential '%s' has no password; store one with cred add %s --type ssh username=%s", credential, credential, account.User)
+	}
+	return password, nil
+}
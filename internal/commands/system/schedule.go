@@ -0,0 +1,288 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+
+	"github.com/fatih/color"
+)
+
+// ScheduleCommand manages cron-style scheduled commands
+type ScheduleCommand struct {
+	*commands.BaseCommand
+	scheduler *Scheduler
+}
+
+// NewScheduleCommand creates a new schedule command
+func NewScheduleCommand(scheduler *Scheduler) *ScheduleCommand {
+	return &ScheduleCommand{
+		BaseCommand: commands.NewBaseCommand(
+			"schedule",
+			"Run commands on a cron schedule in the background",
+			"schedule [add|list|remove|run|log] \"<cron>\" \"<command>\"",
+			[]string{"windows", "linux", "darwin"},
+			false,
+		),
+		scheduler: scheduler,
+	}
+}
+
+// Execute handles scheduled task operations
+func (s *ScheduleCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	startTime := time.Now()
+
+	if len(args.Raw) == 0 {
+		return s.showHelp(startTime), nil
+	}
+
+	subcommand := args.Raw[0]
+	rest := args.Raw[1:]
+
+	switch subcommand {
+	case "add":
+		if len(rest) < 2 {
+			return s.usageResult("Usage: schedule add \"<cron>\" \"<command>\"", startTime), nil
+		}
+		return s.addTask(rest, startTime), nil
+	case "list", "ls":
+		return s.listTasks(startTime), nil
+	case "remove", "rm", "delete":
+		if len(rest) < 1 {
+			return s.usageResult("Usage: schedule remove <id>", startTime), nil
+		}
+		return s.removeTask(rest[0], startTime), nil
+	case "run":
+		if len(rest) < 1 {
+			return s.usageResult("Usage: schedule run <id>", startTime), nil
+		}
+		return s.runTask(ctx, rest[0], startTime), nil
+	case "log":
+		return s.showLog(rest, startTime), nil
+	default:
+		return s.usageResult(fmt.Sprintf("Unknown subcommand '%s'. Use: add, list, remove, run, log", subcommand), startTime), nil
+	}
+}
+
+// addTask registers a new scheduled command
+func (s *ScheduleCommand) addTask(args []string, startTime time.Time) *commands.Result {
+	spec := args[0]
+
+	// An unquoted command arrives as several words; requote them so it round-trips
+	words := make([]string, 0, len(args)-1)
+	for _, word := range args[1:] {
+		words = append(words, commands.QuoteArgument(word))
+	}
+	command := strings.Join(words, " ")
+	if len(args) == 2 {
+		command = args[1]
+	}
+
+	task, err := s.scheduler.AddTask(spec, command)
+	if err != nil {
+		return s.errorResult(err, startTime)
+	}
+
+	schedule, _ := ParseCronSchedule(task.Schedule)
+
+	var output strings.Builder
+	output.WriteString(color.New(color.FgGreen).Sprintf("✅ Scheduled task #%d added\n", task.ID))
+	output.WriteString(fmt.Sprintf("⏰ Schedule: %s\n", task.Schedule))
+	output.WriteString(fmt.Sprintf("💻 Command:  %s\n", task.Command))
+	output.WriteString(fmt.Sprintf("⏭️  Next run: %s\n", formatNextRun(schedule)))
+	if !s.scheduler.Running() {
+		output.WriteString(color.New(color.FgYellow).Sprint("⚠️  Tasks only run while an interactive SuperShell session is open\n"))
+	}
+
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}
+}
+
+// listTasks shows all scheduled tasks with their next run time and last result
+func (s *ScheduleCommand) listTasks(startTime time.Time) *commands.Result {
+	tasks, err := s.scheduler.LoadTasks()
+	if err != nil {
+		return s.errorResult(err, startTime)
+	}
+
+	var output strings.Builder
+	output.WriteString(color.New(color.FgCyan, color.Bold).Sprint("⏰ SCHEDULED TASKS\n"))
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+
+	if len(tasks) == 0 {
+		output.WriteString("No scheduled tasks. Add one with: schedule add \"0 2 * * *\" \"<command>\"\n")
+	}
+
+	for _, task := range tasks {
+		output.WriteString(fmt.Sprintf("#%-3d %s  %s\n",
+			task.ID, color.New(color.FgYellow).Sprintf("%-15s", task.Schedule), task.Command))
+
+		nextRun := "invalid schedule"
+		if schedule, err := ParseCronSchedule(task.Schedule); err == nil {
+			nextRun = formatNextRun(schedule)
+		}
+		output.WriteString(fmt.Sprintf("     Next: %s\n", nextRun))
+
+		if task.RunCount > 0 {
+			status := color.New(color.FgGreen).Sprint("ok")
+			if task.LastExitCode != 0 {
+				status = color.New(color.FgRed).Sprintf("exit %d", task.LastExitCode)
+			}
+			output.WriteString(fmt.Sprintf("     Last: %s (%s), %d runs\n",
+				task.LastRun.Format("2006-01-02 15:04"), status, task.RunCount))
+		}
+	}
+
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+	if s.scheduler.Running() {
+		output.WriteString(color.New(color.FgGreen).Sprint("🟢 Scheduler running\n"))
+	} else {
+		output.WriteString(color.New(color.FgYellow).Sprint("⚠️  Scheduler not running (starts with the interactive shell)\n"))
+	}
+	output.WriteString(fmt.Sprintf("📄 Run log: %s\n", s.scheduler.LogFile()))
+
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}
+}
+
+// removeTask deletes a scheduled task
+func (s *ScheduleCommand) removeTask(idArg string, startTime time.Time) *commands.Result {
+	id, err := strconv.Atoi(strings.TrimPrefix(idArg, "#"))
+	if err != nil {
+		return s.errorResult(fmt.Errorf("invalid task id '%s'", idArg), startTime)
+	}
+
+	if err := s.scheduler.RemoveTask(id); err != nil {
+		return s.errorResult(err, startTime)
+	}
+
+	return &commands.Result{
+		Output:   color.New(color.FgGreen).Sprintf("✅ Scheduled task #%d removed\n", id),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}
+}
+
+// runTask runs a scheduled task immediately and shows its output
+func (s *ScheduleCommand) runTask(ctx context.Context, idArg string, startTime time.Time) *commands.Result {
+	id, err := strconv.Atoi(strings.TrimPrefix(idArg, "#"))
+	if err != nil {
+		return s.errorResult(fmt.Errorf("invalid task id '%s'", idArg), startTime)
+	}
+
+	result, err := s.scheduler.RunTask(ctx, id)
+	if result == nil {
+		return s.errorResult(err, startTime)
+	}
+
+	output := result.Output
+	if err != nil {
+		output += color.New(color.FgRed).Sprintf("❌ Error: %v\n", err)
+	}
+
+	return &commands.Result{
+		Output:   output,
+		ExitCode: result.ExitCode,
+		Duration: time.Since(startTime),
+	}
+}
+
+// showLog prints the tail of the scheduler run log
+func (s *ScheduleCommand) showLog(args []string, startTime time.Time) *commands.Result {
+	lines := 40
+	if len(args) > 0 {
+		if n, err := strconv.Atoi(args[0]); err == nil && n > 0 {
+			lines = n
+		}
+	}
+
+	data, err := ioutil.ReadFile(s.scheduler.LogFile())
+	if os.IsNotExist(err) {
+		return &commands.Result{
+			Output:   "No scheduled runs logged yet\n",
+			ExitCode: 0,
+			Duration: time.Since(startTime),
+		}
+	}
+	if err != nil {
+		return s.errorResult(err, startTime)
+	}
+
+	logLines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(logLines) > lines {
+		logLines = logLines[len(logLines)-lines:]
+	}
+
+	return &commands.Result{
+		Output:   strings.Join(logLines, "\n") + "\n",
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}
+}
+
+// showHelp displays schedule usage
+func (s *ScheduleCommand) showHelp(startTime time.Time) *commands.Result {
+	var output strings.Builder
+	output.WriteString(color.New(color.FgCyan, color.Bold).Sprint("⏰ SCHEDULED TASKS\n"))
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+	output.WriteString("  schedule add \"<cron>\" \"<command>\"\n")
+	output.WriteString("  schedule list\n")
+	output.WriteString("  schedule remove <id>\n")
+	output.WriteString("  schedule run <id>\n")
+	output.WriteString("  schedule log [lines]\n")
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+	output.WriteString("Cron format: minute hour day month weekday (or @hourly, @daily, @weekly, @monthly)\n")
+	output.WriteString("  schedule add \"0 2 * * *\" \"fastcp-backup ./data mybucket\"\n")
+	output.WriteString("  schedule add \"*/15 * * * *\" \"ping 8.8.8.8\"\n")
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+	output.WriteString("💡 Output is written to the run log, not the prompt\n")
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}
+}
+
+// usageResult returns a usage message with a failing exit code
+func (s *ScheduleCommand) usageResult(usage string, startTime time.Time) *commands.Result {
+	return &commands.Result{
+		Output:   usage + "\n",
+		ExitCode: 1,
+		Duration: time.Since(startTime),
+	}
+}
+
+// errorResult formats an error as a failing result
+func (s *ScheduleCommand) errorResult(err error, startTime time.Time) *commands.Result {
+	return &commands.Result{
+		Output:   color.New(color.FgRed).Sprintf("❌ Error: %v\n", err),
+		ExitCode: 1,
+		Duration: time.Since(startTime),
+	}
+}
+
+// formatNextRun describes when a schedule fires next
+func formatNextRun(schedule *CronSchedule) string {
+	if schedule == nil {
+		return "unknown"
+	}
+	next := schedule.Next(time.Now())
+	if next.IsZero() {
+		return "never"
+	}
+	return fmt.Sprintf("%s (in %v)", next.Format("2006-01-02 15:04"), time.Until(next).Round(time.Minute))
+}
@@ -0,0 +1,380 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"suppercommand/internal/commands"
+)
+
+// ansiPattern matches terminal color sequences so logs stay readable
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;]*[a-zA-Z]`)
+
+// runOutput collects what a scheduled command prints while it runs, which may
+// come from several goroutines
+type runOutput struct {
+	mu  sync.Mutex
+	out strings.Builder
+}
+
+func (o *runOutput) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.out.Write(p)
+}
+
+func (o *runOutput) String() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.out.String()
+}
+
+// ScheduledTask is a SuperShell command registered to run on a cron schedule
+type ScheduledTask struct {
+	ID           int       `json:"id"`
+	Schedule     string    `json:"schedule"`
+	Command      string    `json:"command"`
+	Enabled      bool      `json:"enabled"`
+	Created      time.Time `json:"created"`
+	LastRun      time.Time `json:"last_run,omitempty"`
+	LastExitCode int       `json:"last_exit_code"`
+	LastError    string    `json:"last_error,omitempty"`
+	RunCount     int       `json:"run_count"`
+}
+
+// Scheduler runs scheduled tasks in the background of an interactive session
+type Scheduler struct {
+	registry *commands.Registry
+	taskFile string
+	logFile  string
+
+	mu      sync.Mutex
+	fileMu  sync.Mutex
+	cancel  context.CancelFunc
+	done    chan struct{}
+	running bool
+}
+
+// NewScheduler creates a scheduler persisting tasks under ~/.supershell
+func NewScheduler(registry *commands.Registry) *Scheduler {
+	homeDir, _ := os.UserHomeDir()
+	baseDir := filepath.Join(homeDir, ".supershell")
+
+	return &Scheduler{
+		registry: registry,
+		taskFile: filepath.Join(baseDir, "schedules.json"),
+		logFile:  filepath.Join(baseDir, "logs", "schedule.log"),
+	}
+}
+
+// Start launches the background scheduler goroutine
+func (s *Scheduler) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		return nil
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	s.running = true
+
+	go s.loop(runCtx)
+	return nil
+}
+
+// Stop stops the scheduler and waits for the loop to exit
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return nil
+	}
+	s.cancel()
+	done := s.done
+	s.running = false
+	s.mu.Unlock()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Running reports whether the background loop is active
+func (s *Scheduler) Running() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.running
+}
+
+// LogFile returns the path of the run log
+func (s *Scheduler) LogFile() string {
+	return s.logFile
+}
+
+// loop wakes at each minute boundary and runs the tasks due in that minute
+func (s *Scheduler) loop(ctx context.Context) {
+	defer close(s.done)
+
+	for {
+		now := time.Now()
+		next := now.Truncate(time.Minute).Add(time.Minute)
+		timer := time.NewTimer(next.Sub(now))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		// Reload each minute so tasks added from other sessions are picked up
+		tasks, err := s.LoadTasks()
+		if err != nil {
+			s.appendLog(fmt.Sprintf("[%s] scheduler: failed to load tasks: %v\n", time.Now().Format("2006-01-02 15:04:05"), err))
+			continue
+		}
+
+		for _, task := range tasks {
+			if !task.Enabled {
+				continue
+			}
+			schedule, err := ParseCronSchedule(task.Schedule)
+			if err != nil || !schedule.Matches(next) {
+				continue
+			}
+			go s.RunTask(ctx, task.ID)
+		}
+	}
+}
+
+// LoadTasks reads the persisted task list
+func (s *Scheduler) LoadTasks() ([]ScheduledTask, error) {
+	s.fileMu.Lock()
+	defer s.fileMu.Unlock()
+	return s.loadTasks()
+}
+
+// AddTask validates and persists a new scheduled task
+func (s *Scheduler) AddTask(spec, command string) (*ScheduledTask, error) {
+	if _, err := ParseCronSchedule(spec); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(command) == "" {
+		return nil, fmt.Errorf("command cannot be empty")
+	}
+
+	s.fileMu.Lock()
+	defer s.fileMu.Unlock()
+
+	tasks, err := s.loadTasks()
+	if err != nil {
+		return nil, err
+	}
+
+	nextID := 1
+	for _, task := range tasks {
+		if task.ID >= nextID {
+			nextID = task.ID + 1
+		}
+	}
+
+	task := ScheduledTask{
+		ID:       nextID,
+		Schedule: spec,
+		Command:  command,
+		Enabled:  true,
+		Created:  time.Now(),
+	}
+	tasks = append(tasks, task)
+
+	if err := s.saveTasks(tasks); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// ReplaceTasks validates and persists a complete task list, replacing all existing tasks
+func (s *Scheduler) ReplaceTasks(tasks []ScheduledTask) error {
+	for _, task := range tasks {
+		if _, err := ParseCronSchedule(task.Schedule); err != nil {
+			return fmt.Errorf("task #%d: %w", task.ID, err)
+		}
+	}
+
+	s.fileMu.Lock()
+	defer s.fileMu.Unlock()
+	return s.saveTasks(tasks)
+}
+
+// RemoveTask deletes a task by ID
+func (s *Scheduler) RemoveTask(id int) error {
+	s.fileMu.Lock()
+	defer s.fileMu.Unlock()
+
+	tasks, err := s.loadTasks()
+	if err != nil {
+		return err
+	}
+
+	for i, task := range tasks {
+		if task.ID == id {
+			tasks = append(tasks[:i], tasks[i+1:]...)
+			return s.saveTasks(tasks)
+		}
+	}
+
+	return fmt.Errorf("scheduled task #%d not found", id)
+}
+
+// RunTask executes a task now, capturing its output into the run log
+func (s *Scheduler) RunTask(ctx context.Context, id int) (*commands.Result, error) {
+	tasks, err := s.LoadTasks()
+	if err != nil {
+		return nil, err
+	}
+
+	var task *ScheduledTask
+	for i := range tasks {
+		if tasks[i].ID == id {
+			task = &tasks[i]
+			break
+		}
+	}
+	if task == nil {
+		return nil, fmt.Errorf("scheduled task #%d not found", id)
+	}
+
+	startTime := time.Now()
+	// What the command prints as it goes, progress included, is kept for the
+	// log rather than drawn over the prompt
+	printed := &runOutput{}
+	result, runErr := s.execute(commands.WithOutputWriter(ctx, printed), task.Command)
+	if result == nil {
+		result = &commands.Result{ExitCode: 1, Duration: time.Since(startTime)}
+	}
+	if runErr == nil && result.Error != nil {
+		runErr = result.Error
+	}
+	if runErr != nil && result.ExitCode == 0 {
+		result.ExitCode = 1
+	}
+
+	// Output goes to the log, never to the interactive prompt
+	var entry strings.Builder
+	entry.WriteString(fmt.Sprintf("[%s] task #%d %q exit=%d duration=%v\n",
+		startTime.Format("2006-01-02 15:04:05"), task.ID, task.Command, result.ExitCode, time.Since(startTime).Round(time.Millisecond)))
+	if runErr != nil {
+		entry.WriteString(fmt.Sprintf("  error: %v\n", runErr))
+	}
+	for _, line := range strings.Split(strings.TrimRight(ansiPattern.ReplaceAllString(printed.String()+result.Output, ""), "\n"), "\n") {
+		// A progress line redrawn with \r keeps only its last state
+		if line = line[strings.LastIndex(line, "\r")+1:]; line != "" {
+			entry.WriteString("  " + line + "\n")
+		}
+	}
+	s.appendLog(entry.String())
+
+	s.recordRun(task.ID, startTime, result.ExitCode, runErr)
+
+	return result, runErr
+}
+
+// execute dispatches a command line through the registry, expanding globs
+// when the task runs
+func (s *Scheduler) execute(ctx context.Context, line string) (*commands.Result, error) {
+	if s.registry == nil {
+		return nil, fmt.Errorf("command registry not available")
+	}
+	return s.registry.ExecuteLine(ctx, line)
+}
+
+// recordRun stores the outcome of a run on the task
+func (s *Scheduler) recordRun(id int, when time.Time, exitCode int, runErr error) {
+	s.fileMu.Lock()
+	defer s.fileMu.Unlock()
+
+	tasks, err := s.loadTasks()
+	if err != nil {
+		return
+	}
+
+	for i := range tasks {
+		if tasks[i].ID != id {
+			continue
+		}
+		tasks[i].LastRun = when
+		tasks[i].LastExitCode = exitCode
+		tasks[i].LastError = ""
+		if runErr != nil {
+			tasks[i].LastError = runErr.Error()
+		}
+		tasks[i].RunCount++
+	}
+
+	s.saveTasks(tasks)
+}
+
+// loadTasks reads the task file; callers must hold fileMu
+func (s *Scheduler) loadTasks() ([]ScheduledTask, error) {
+	var tasks []ScheduledTask
+
+	data, err := ioutil.ReadFile(s.taskFile)
+	if os.IsNotExist(err) {
+		return tasks, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return tasks, nil
+	}
+
+	err = json.Unmarshal(data, &tasks)
+	return tasks, err
+}
+
+// saveTasks writes the task file; callers must hold fileMu
+func (s *Scheduler) saveTasks(tasks []ScheduledTask) error {
+	if err := os.MkdirAll(filepath.Dir(s.taskFile), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(tasks, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(s.taskFile, data, 0644)
+}
+
+// appendLog appends an entry to the scheduler run log
+func (s *Scheduler) appendLog(entry string) {
+	s.fileMu.Lock()
+	defer s.fileMu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(s.logFile), 0755); err != nil {
+		return
+	}
+
+	file, err := os.OpenFile(s.logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	file.WriteString(entry)
+}
@@ -0,0 +1,150 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+
+	"github.com/fatih/color"
+)
+
+// Temperatures used to color readings when the hardware reports no thresholds
+const (
+	sensorWarmCelsius = 65
+	sensorHotCelsius  = 80
+)
+
+// SensorsCommand shows temperature and fan sensors
+type SensorsCommand struct {
+	*commands.BaseCommand
+}
+
+// NewSensorsCommand creates a new sensors command
+func NewSensorsCommand() *SensorsCommand {
+	return &SensorsCommand{
+		BaseCommand: commands.NewBaseCommand(
+			"sensors",
+			"Show temperature and fan sensor readings",
+			"sensors [--json]",
+			[]string{"windows", "linux"},
+			false,
+		),
+	}
+}
+
+// Execute shows each sensor with its thresholds
+func (s *SensorsCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	startTime := time.Now()
+
+	jsonOutput := false
+	for _, arg := range args.Raw {
+		if arg != "--json" {
+			return &commands.Result{
+				Output:   "Usage: " + s.Usage() + "\n",
+				Error:    commands.UsageError(s.Name(), "unknown option '%s'", arg),
+				ExitCode: 1,
+				Duration: time.Since(startTime),
+			}, nil
+		}
+		jsonOutput = true
+	}
+
+	readings, err := readSensors(ctx)
+	if err != nil {
+		output := color.New(color.FgRed).Sprintf("❌ %v\n", err)
+		return commands.ErrorResult(output, err, startTime), nil
+	}
+
+	if jsonOutput {
+		data, err := json.MarshalIndent(readings, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return &commands.Result{
+			Output:   string(data) + "\n",
+			ExitCode: 0,
+			Duration: time.Since(startTime),
+		}, nil
+	}
+
+	var output strings.Builder
+	output.WriteString(color.New(color.FgCyan, color.Bold).Sprint("🌡️  HARDWARE SENSORS\n"))
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+	writeSensorReadings(&output, readings)
+
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}, nil
+}
+
+// writeSensorReadings renders readings grouped by chip, for both the sensors
+// command and the sysinfo section
+func writeSensorReadings(output *strings.Builder, readings []SensorReading) {
+	if len(readings) == 0 {
+		output.WriteString("  No temperature or fan sensors are exposed on this machine\n")
+		if runtime.GOOS == "windows" {
+			output.WriteString(color.New(color.FgHiBlack).Sprint("  💡 Some firmware only reports thermal zones to an elevated prompt\n"))
+		}
+		return
+	}
+
+	chip := ""
+	for _, reading := range readings {
+		if reading.Chip != chip {
+			chip = reading.Chip
+			output.WriteString(color.New(color.FgBlue, color.Bold).Sprintf("  %s\n", chip))
+		}
+
+		value := fmt.Sprintf("%.0f %s", reading.Value, reading.Unit)
+		var limits []string
+		if reading.Kind == SensorTemperature {
+			value = fmt.Sprintf("%+.1f%s", reading.Value, reading.Unit)
+			if reading.High > 0 {
+				limits = append(limits, fmt.Sprintf("high %+.1f%s", reading.High, reading.Unit))
+			}
+			if reading.Critical > 0 {
+				limits = append(limits, fmt.Sprintf("crit %+.1f%s", reading.Critical, reading.Unit))
+			}
+		} else if reading.Min > 0 {
+			limits = append(limits, fmt.Sprintf("min %.0f %s", reading.Min, reading.Unit))
+		}
+
+		line := fmt.Sprintf("    %-20s %s", truncateText(reading.Label, 20), sensorColor(reading).Sprintf("%-12s", value))
+		if len(limits) > 0 {
+			line += color.New(color.FgHiBlack).Sprintf(" (%s)", strings.Join(limits, ", "))
+		}
+		output.WriteString(line + "\n")
+	}
+}
+
+// sensorColor colors temperatures red when at a threshold and yellow when close
+// to one, and fans yellow below their minimum
+func sensorColor(reading SensorReading) *color.Color {
+	if reading.Kind == SensorFan {
+		if reading.Min > 0 && reading.Value < reading.Min {
+			return color.New(color.FgYellow, color.Bold)
+		}
+		return color.New(color.FgGreen)
+	}
+
+	switch {
+	case reading.Critical > 0 && reading.Value >= reading.Critical:
+		return color.New(color.FgWhite, color.BgRed, color.Bold)
+	case reading.High > 0 && reading.Value >= reading.High,
+		reading.High == 0 && reading.Critical == 0 && reading.Value >= sensorHotCelsius:
+		return color.New(color.FgRed, color.Bold)
+	case reading.High > 0 && reading.Value >= reading.High-10,
+		reading.High == 0 && reading.Critical > 0 && reading.Value >= reading.Critical-15,
+		reading.High == 0 && reading.Critical == 0 && reading.Value >= sensorWarmCelsius:
+		return color.New(color.FgYellow)
+	default:
+		return color.New(color.FgGreen)
+	}
+}
@@ -0,0 +1,229 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Sensor kinds
+const (
+	SensorTemperature = "temperature"
+	SensorFan         = "fan"
+)
+
+// SensorReading is the current value of a temperature or fan sensor. Thresholds
+// are zero when the hardware doesn't report them.
+type SensorReading struct {
+	Chip     string  `json:"chip"`
+	Label    string  `json:"label"`
+	Kind     string  `json:"kind"`
+	Value    float64 `json:"value"`
+	Unit     string  `json:"unit"`
+	High     float64 `json:"high,omitempty"`
+	Critical float64 `json:"critical,omitempty"`
+	// Min is the fan speed below which the chip raises an alarm
+	Min float64 `json:"min,omitempty"`
+}
+
+// readSensors returns the sensors this machine exposes, which may be none at all
+// in virtual machines and on many desktops
+func readSensors(ctx context.Context) ([]SensorReading, error) {
+	if runtime.GOOS == "windows" {
+		return readWindowsSensors(ctx)
+	}
+
+	readings := ReadHwmon("/sys/class/hwmon")
+	for _, reading := range readings {
+		if reading.Kind == SensorTemperature {
+			return readings, nil
+		}
+	}
+	// Thermal zones usually duplicate hwmon's acpitz chip, so they're only a fallback
+	return append(readings, ReadThermalZones("/sys/class/thermal")...), nil
+}
+
+// hwmonInputPattern matches the sensor inputs of a hwmon chip, such as temp1_input
+var hwmonInputPattern = regexp.MustCompile(`^(temp|fan)(\d+)_input$`)
+
+// ReadHwmon reads every chip under a hwmon class directory. Temperatures are in
+// millidegrees Celsius and fan speeds in RPM.
+func ReadHwmon(root string) []SensorReading {
+	chips, err := ioutil.ReadDir(root)
+	if err != nil {
+		return nil
+	}
+
+	readings := make([]SensorReading, 0)
+	seen := make(map[string]int)
+	for _, chip := range chips {
+		dir := filepath.Join(root, chip.Name())
+		name := sysfsValue(dir, "name")
+		if name == "" {
+			name = chip.Name()
+		}
+		// Machines often have several chips of one type, such as one per NVMe drive
+		seen[name]++
+		if seen[name] > 1 {
+			name = fmt.Sprintf("%s #%d", name, seen[name])
+		}
+
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		type input struct {
+			kind  string
+			index int
+		}
+		var inputs []input
+		for _, file := range files {
+			if match := hwmonInputPattern.FindStringSubmatch(file.Name()); match != nil {
+				index, _ := strconv.Atoi(match[2])
+				inputs = append(inputs, input{match[1], index})
+			}
+		}
+		sort.Slice(inputs, func(i, j int) bool {
+			if inputs[i].kind != inputs[j].kind {
+				return inputs[i].kind > inputs[j].kind
+			}
+			return inputs[i].index < inputs[j].index
+		})
+
+		for _, in := range inputs {
+			prefix := fmt.Sprintf("%s%d", in.kind, in.index)
+			raw, err := strconv.ParseFloat(sysfsValue(dir, prefix+"_input"), 64)
+			if err != nil {
+				// Unreadable inputs return errors such as ENODATA when the sensor is idle
+				continue
+			}
+			label := sysfsValue(dir, prefix+"_label")
+			if label == "" {
+				label = prefix
+			}
+
+			reading := SensorReading{Chip: name, Label: label}
+			if in.kind == "temp" {
+				reading.Kind, reading.Unit = SensorTemperature, "°C"
+				reading.Value = raw / 1000
+				reading.High = positiveMillidegrees(sysfsValue(dir, prefix+"_max"))
+				reading.Critical = positiveMillidegrees(sysfsValue(dir, prefix+"_crit"))
+			} else {
+				reading.Kind, reading.Unit = SensorFan, "RPM"
+				reading.Value = raw
+				reading.Min = float64(sysfsNumber(dir, prefix+"_min"))
+			}
+			readings = append(readings, reading)
+		}
+	}
+	return readings
+}
+
+// ReadThermalZones reads the ACPI thermal zones under a thermal class directory,
+// taking each zone's critical trip point as its threshold
+func ReadThermalZones(root string) []SensorReading {
+	zones, err := filepath.Glob(filepath.Join(root, "thermal_zone*"))
+	if err != nil {
+		return nil
+	}
+	sort.Strings(zones)
+
+	readings := make([]SensorReading, 0)
+	for _, zone := range zones {
+		raw, err := strconv.ParseFloat(sysfsValue(zone, "temp"), 64)
+		if err != nil {
+			continue
+		}
+		reading := SensorReading{
+			Chip:  filepath.Base(zone),
+			Label: sysfsValue(zone, "type"),
+			Kind:  SensorTemperature,
+			Value: raw / 1000,
+			Unit:  "°C",
+		}
+		for i := 0; ; i++ {
+			tripType := sysfsValue(zone, fmt.Sprintf("trip_point_%d_type", i))
+			if tripType == "" {
+				break
+			}
+			temp := positiveMillidegrees(sysfsValue(zone, fmt.Sprintf("trip_point_%d_temp", i)))
+			switch tripType {
+			case "critical":
+				reading.Critical = temp
+			case "hot":
+				reading.High = temp
+			}
+		}
+		readings = append(readings, reading)
+	}
+	return readings
+}
+
+// positiveMillidegrees converts a sysfs threshold to degrees, dropping the zero
+// and negative placeholders some drivers report
+func positiveMillidegrees(value string) float64 {
+	millidegrees, err := strconv.ParseFloat(value, 64)
+	if err != nil || millidegrees <= 0 {
+		return 0
+	}
+	return millidegrees / 1000
+}
+
+// kelvinOffset converts kelvin to degrees Celsius
+const kelvinOffset = 273.15
+
+// ParseWindowsThermal reads the thermal zones exported by readWindowsSensors, in kelvin
+func ParseWindowsThermal(data []byte) ([]SensorReading, error) {
+	var zones []struct {
+		Name           string  `json:"Name"`
+		Kelvin         float64 `json:"Kelvin"`
+		CriticalKelvin float64 `json:"CriticalKelvin"`
+	}
+	if err := json.Unmarshal(data, &zones); err != nil {
+		return nil, fmt.Errorf("failed to parse thermal zones: %w", err)
+	}
+
+	readings := make([]SensorReading, 0, len(zones))
+	for _, zone := range zones {
+		if zone.Kelvin <= 0 {
+			continue
+		}
+		reading := SensorReading{
+			Chip:  "ACPI",
+			Label: strings.TrimPrefix(zone.Name, `\_TZ.`),
+			Kind:  SensorTemperature,
+			Value: zone.Kelvin - kelvinOffset,
+			Unit:  "°C",
+		}
+		if zone.CriticalKelvin > 0 {
+			reading.Critical = zone.CriticalKelvin - kelvinOffset
+		}
+		readings = append(readings, reading)
+	}
+	return readings, nil
+}
+
+// readWindowsSensors reads ACPI thermal zones. The performance counter class
+// works without elevation; MSAcpi_ThermalZoneTemperature needs it but also has
+// the critical trip point. Windows has no standard class for fan speeds.
+func readWindowsSensors(ctx context.Context) ([]SensorReading, error) {
+	script := `$zones = @(Get-CimInstance Win32_PerfFormattedData_Counters_ThermalZoneInformation -ErrorAction SilentlyContinue |
+			ForEach-Object { [PSCustomObject]@{ Name = $_.Name; Kelvin = [double]$_.Temperature; CriticalKelvin = 0 } })
+		$acpi = @(Get-CimInstance -Namespace root/wmi MSAcpi_ThermalZoneTemperature -ErrorAction SilentlyContinue |
+			ForEach-Object { [PSCustomObject]@{ Name = $_.InstanceName; Kelvin = $_.CurrentTemperature / 10; CriticalKelvin = $_.CriticalTripPoint / 10 } })
+		if ($acpi.Count -gt 0) { $zones = $acpi }
+		ConvertTo-Json -InputObject $zones`
+	output, err := exec.CommandContext(ctx, "powershell", "-NoProfile", "-Command", script).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query thermal zones: %w", err)
+	}
+	return ParseWindowsThermal(output)
+}
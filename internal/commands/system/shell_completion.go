@@ -0,0 +1,386 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+
+	"github.com/fatih/color"
+)
+
+// completionProgram is the executable the generated scripts complete
+const completionProgram = "supershell"
+
+// CompletionShells are the outer shells completion scripts can be generated for
+var CompletionShells = []string{"bash", "zsh", "fish", "powershell"}
+
+// CompletionScript returns a script that completes `supershell -c <command>
+// [arguments]` in the given shell from the registry's command descriptions
+func CompletionScript(shell string, infos []commands.CommandInfo) (string, error) {
+	switch shell {
+	case "bash":
+		return bashCompletion(infos), nil
+	case "zsh":
+		return zshCompletion(infos), nil
+	case "fish":
+		return fishCompletion(infos), nil
+	case "powershell", "pwsh":
+		return powershellCompletion(infos), nil
+	}
+	return "", fmt.Errorf("unsupported shell '%s' (supported: %s)", shell, strings.Join(CompletionShells, ", "))
+}
+
+// singleQuoted quotes text for POSIX shells and fish
+func singleQuoted(text string) string {
+	return "'" + strings.Replace(text, "'", `'\''`, -1) + "'"
+}
+
+func bashCompletion(infos []commands.CommandInfo) string {
+	var script strings.Builder
+	names := make([]string, len(infos))
+	for i, info := range infos {
+		names[i] = info.Name
+	}
+
+	script.WriteString("# bash completion for supershell, generated by `supershell -c completion bash`\n")
+	script.WriteString("_supershell() {\n")
+	script.WriteString("    local cur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	script.WriteString("    if [ \"$COMP_CWORD\" -eq 1 ]; then\n")
+	script.WriteString("        COMPREPLY=($(compgen -W \"-c\" -- \"$cur\"))\n")
+	script.WriteString("        return\n")
+	script.WriteString("    fi\n")
+	script.WriteString("    [ \"${COMP_WORDS[1]}\" = \"-c\" ] || return\n")
+	script.WriteString("    if [ \"$COMP_CWORD\" -eq 2 ]; then\n")
+	script.WriteString(fmt.Sprintf("        COMPREPLY=($(compgen -W %s -- \"$cur\"))\n", singleQuoted(strings.Join(names, " "))))
+	script.WriteString("        return\n")
+	script.WriteString("    fi\n")
+	script.WriteString("    local words=\"\"\n")
+	script.WriteString("    case \"${COMP_WORDS[2]}\" in\n")
+	for _, info := range infos {
+		if len(info.Arguments) > 0 {
+			script.WriteString(fmt.Sprintf("        %s) words=%s ;;\n", singleQuoted(info.Name), singleQuoted(strings.Join(info.Arguments, " "))))
+		}
+	}
+	script.WriteString("    esac\n")
+	script.WriteString("    COMPREPLY=($(compgen -W \"$words\" -- \"$cur\"))\n")
+	script.WriteString("}\n")
+	// -o default falls back to file names when no argument matches
+	script.WriteString("complete -o default -F _supershell supershell\n")
+	return script.String()
+}
+
+func zshCompletion(infos []commands.CommandInfo) string {
+	var script strings.Builder
+	script.WriteString("#compdef supershell\n")
+	script.WriteString("# zsh completion for supershell, generated by `supershell -c completion zsh`\n")
+	script.WriteString("_supershell() {\n")
+	script.WriteString("    local -a cmds\n")
+	script.WriteString("    cmds=(\n")
+	for _, info := range infos {
+		script.WriteString(fmt.Sprintf("        %s\n", singleQuoted(info.Name+":"+info.Description)))
+	}
+	script.WriteString("    )\n")
+	script.WriteString("    if (( CURRENT == 2 )); then\n")
+	script.WriteString("        compadd -- -c\n")
+	script.WriteString("        return\n")
+	script.WriteString("    fi\n")
+	script.WriteString("    [[ $words[2] == -c ]] || return\n")
+	script.WriteString("    if (( CURRENT == 3 )); then\n")
+	script.WriteString("        _describe 'command' cmds\n")
+	script.WriteString("        return\n")
+	script.WriteString("    fi\n")
+	script.WriteString("    case $words[3] in\n")
+	for _, info := range infos {
+		if len(info.Arguments) == 0 {
+			continue
+		}
+		quoted := make([]string, len(info.Arguments))
+		for i, argument := range info.Arguments {
+			quoted[i] = singleQuoted(argument)
+		}
+		script.WriteString(fmt.Sprintf("        %s) compadd -- %s ;;\n", singleQuoted(info.Name), strings.Join(quoted, " ")))
+	}
+	script.WriteString("        *) _files ;;\n")
+	script.WriteString("    esac\n")
+	script.WriteString("}\n")
+	// Loaded from fpath the file is the function body; sourced it registers itself
+	script.WriteString("if [ \"$funcstack[1]\" = \"_supershell\" ]; then\n")
+	script.WriteString("    _supershell \"$@\"\n")
+	script.WriteString("else\n")
+	script.WriteString("    compdef _supershell supershell\n")
+	script.WriteString("fi\n")
+	return script.String()
+}
+
+func fishCompletion(infos []commands.CommandInfo) string {
+	var script strings.Builder
+	script.WriteString("# fish completion for supershell, generated by `supershell -c completion fish`\n")
+	script.WriteString("function __supershell_needs_command\n")
+	script.WriteString("    set -l tokens (commandline -opc)\n")
+	script.WriteString("    test (count $tokens) -eq 2; and test \"$tokens[2]\" = -c\n")
+	script.WriteString("end\n")
+	script.WriteString("function __supershell_using\n")
+	script.WriteString("    set -l tokens (commandline -opc)\n")
+	script.WriteString("    test (count $tokens) -ge 3; and test \"$tokens[2]\" = -c; and test \"$tokens[3]\" = $argv[1]\n")
+	script.WriteString("end\n")
+	script.WriteString("complete -c supershell -n 'test (count (commandline -opc)) -eq 1' -f -a '-c' -d 'Run a command and exit'\n")
+	for _, info := range infos {
+		script.WriteString(fmt.Sprintf("complete -c supershell -n __supershell_needs_command -f -a %s -d %s\n",
+			singleQuoted(info.Name), singleQuoted(info.Description)))
+	}
+	for _, info := range infos {
+		if len(info.Arguments) > 0 {
+			script.WriteString(fmt.Sprintf("complete -c supershell -n %s -a %s\n",
+				singleQuoted("__supershell_using "+info.Name), singleQuoted(strings.Join(info.Arguments, " "))))
+		}
+	}
+	return script.String()
+}
+
+// powershellQuoted quotes text as a PowerShell single-quoted string
+func powershellQuoted(text string) string {
+	return "'" + strings.Replace(text, "'", "''", -1) + "'"
+}
+
+func powershellCompletion(infos []commands.CommandInfo) string {
+	var script strings.Builder
+	script.WriteString("# PowerShell completion for supershell, generated by `supershell -c completion powershell`\n")
+	script.WriteString("Register-ArgumentCompleter -Native -CommandName 'supershell', 'supershell.exe' -ScriptBlock {\n")
+	script.WriteString("    param($wordToComplete, $commandAst, $cursorPosition)\n")
+	script.WriteString("    $commands = [ordered]@{\n")
+	for _, info := range infos {
+		script.WriteString(fmt.Sprintf("        %s = %s\n", powershellQuoted(info.Name), powershellQuoted(info.Description)))
+	}
+	script.WriteString("    }\n")
+	script.WriteString("    $arguments = @{\n")
+	for _, info := range infos {
+		if len(info.Arguments) == 0 {
+			continue
+		}
+		quoted := make([]string, len(info.Arguments))
+		for i, argument := range info.Arguments {
+			quoted[i] = powershellQuoted(argument)
+		}
+		script.WriteString(fmt.Sprintf("        %s = @(%s)\n", powershellQuoted(info.Name), strings.Join(quoted, ", ")))
+	}
+	script.WriteString("    }\n")
+	script.WriteString("    $words = @($commandAst.CommandElements | ForEach-Object { $_.ToString() })\n")
+	script.WriteString("    if ($wordToComplete -ne '') { $words = @($words | Select-Object -First ($words.Count - 1)) }\n")
+	script.WriteString("    $candidates = @()\n")
+	script.WriteString("    if ($words.Count -eq 1) {\n")
+	script.WriteString("        $candidates = @('-c')\n")
+	script.WriteString("    } elseif ($words.Count -eq 2 -and $words[1] -eq '-c') {\n")
+	script.WriteString("        $candidates = @($commands.Keys)\n")
+	script.WriteString("    } elseif ($words.Count -ge 3 -and $words[1] -eq '-c' -and $arguments.Contains($words[2])) {\n")
+	script.WriteString("        $candidates = $arguments[$words[2]]\n")
+	script.WriteString("    }\n")
+	script.WriteString("    $candidates | Where-Object { $_ -like \"$wordToComplete*\" } | ForEach-Object {\n")
+	script.WriteString("        $tip = if ($commands.Contains($_)) { $commands[$_] } else { $_ }\n")
+	script.WriteString("        [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $tip)\n")
+	script.WriteString("    }\n")
+	script.WriteString("}\n")
+	return script.String()
+}
+
+// CompletionInstallPath returns where a shell loads user completion scripts from.
+// zsh and PowerShell have no such directory, so their scripts go under
+// ~/.supershell and the shell's startup file has to load them.
+func CompletionInstallPath(shell, home string) (string, error) {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		configHome = filepath.Join(home, ".config")
+	}
+
+	switch shell {
+	case "bash":
+		return filepath.Join(dataHome, "bash-completion", "completions", completionProgram), nil
+	case "zsh":
+		return filepath.Join(home, ".supershell", "completion", "_"+completionProgram), nil
+	case "fish":
+		return filepath.Join(configHome, "fish", "completions", completionProgram+".fish"), nil
+	case "powershell", "pwsh":
+		return filepath.Join(home, ".supershell", "completion", completionProgram+".ps1"), nil
+	}
+	return "", fmt.Errorf("unsupported shell '%s' (supported: %s)", shell, strings.Join(CompletionShells, ", "))
+}
+
+// defaultCompletionShell guesses the user's outer shell
+func defaultCompletionShell() string {
+	if runtime.GOOS == "windows" {
+		return "powershell"
+	}
+	if shell := filepath.Base(os.Getenv("SHELL")); shell == "zsh" || shell == "fish" {
+		return shell
+	}
+	return "bash"
+}
+
+// CompletionCommand prints a completion script for an outer shell
+type CompletionCommand struct {
+	*commands.BaseCommand
+	registry *commands.Registry
+}
+
+// NewCompletionCommand creates a new completion command
+func NewCompletionCommand(registry *commands.Registry) *CompletionCommand {
+	return &CompletionCommand{
+		BaseCommand: commands.NewBaseCommand(
+			"completion",
+			"Print a bash, zsh, fish or PowerShell completion script for supershell -c",
+			"completion <bash|zsh|fish|powershell>",
+			[]string{"windows", "linux", "darwin"},
+			false,
+		),
+		registry: registry,
+	}
+}
+
+// Execute prints the completion script
+func (c *CompletionCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	startTime := time.Now()
+
+	if len(args.Raw) != 1 {
+		return &commands.Result{
+			Output:   "Usage: " + c.Usage() + "\n",
+			Error:    commands.UsageError(c.Name(), "expected one shell name"),
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}, nil
+	}
+
+	script, err := CompletionScript(args.Raw[0], c.registry.Describe())
+	if err != nil {
+		return &commands.Result{
+			Output:   "Usage: " + c.Usage() + "\n",
+			Error:    commands.UsageError(c.Name(), "%v", err),
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}, nil
+	}
+
+	return &commands.Result{
+		Output:   script,
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}, nil
+}
+
+// CompleteArguments suggests the supported shells
+func (c *CompletionCommand) CompleteArguments(args []string) []string {
+	if len(args) == 1 {
+		return CompletionShells
+	}
+	return nil
+}
+
+// InstallCompletionCommand writes the completion script where the outer shell loads it
+type InstallCompletionCommand struct {
+	*commands.BaseCommand
+	registry *commands.Registry
+}
+
+// NewInstallCompletionCommand creates a new install-completion command
+func NewInstallCompletionCommand(registry *commands.Registry) *InstallCompletionCommand {
+	return &InstallCompletionCommand{
+		BaseCommand: commands.NewBaseCommand(
+			"install-completion",
+			"Install the completion script for your outer shell",
+			"install-completion [bash|zsh|fish|powershell] [--path <file>]",
+			[]string{"windows", "linux", "darwin"},
+			false,
+		),
+		registry: registry,
+	}
+}
+
+// Execute writes the script and explains any step left to the user
+func (c *InstallCompletionCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	startTime := time.Now()
+
+	shell, path := "", ""
+	for i := 0; i < len(args.Raw); i++ {
+		switch arg := args.Raw[i]; {
+		case arg == "--path" && i+1 < len(args.Raw):
+			path = args.Raw[i+1]
+			i++
+		case shell == "" && !strings.HasPrefix(arg, "-"):
+			shell = arg
+		default:
+			return &commands.Result{
+				Output:   "Usage: " + c.Usage() + "\n",
+				Error:    commands.UsageError(c.Name(), "unexpected argument '%s'", arg),
+				ExitCode: 1,
+				Duration: time.Since(startTime),
+			}, nil
+		}
+	}
+	if shell == "" {
+		shell = defaultCompletionShell()
+	}
+
+	script, err := CompletionScript(shell, c.registry.Describe())
+	if err != nil {
+		return &commands.Result{
+			Output:   "Usage: " + c.Usage() + "\n",
+			Error:    commands.UsageError(c.Name(), "%v", err),
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}, nil
+	}
+	if path == "" {
+		home, _ := os.UserHomeDir()
+		if path, err = CompletionInstallPath(shell, home); err != nil {
+			return commands.ErrorResult(color.New(color.FgRed).Sprintf("❌ %v\n", err), err, startTime), nil
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return commands.ErrorResult(color.New(color.FgRed).Sprintf("❌ %v\n", err), err, startTime), nil
+	}
+	if err := ioutil.WriteFile(path, []byte(script), 0644); err != nil {
+		err = fmt.Errorf("failed to write %s: %w", path, err)
+		return commands.ErrorResult(color.New(color.FgRed).Sprintf("❌ %v\n", err), err, startTime), nil
+	}
+
+	var output strings.Builder
+	output.WriteString(color.New(color.FgGreen).Sprintf("✅ Installed %s completion to %s\n", shell, path))
+	hint := color.New(color.FgHiBlack)
+	switch shell {
+	case "bash":
+		output.WriteString(hint.Sprint("💡 Loaded by bash-completion in new shells; without it, add to ~/.bashrc:\n"))
+		output.WriteString(fmt.Sprintf("   source %s\n", path))
+	case "zsh":
+		output.WriteString(hint.Sprint("💡 Add to ~/.zshrc before compinit runs:\n"))
+		output.WriteString(fmt.Sprintf("   fpath=(%s $fpath)\n", filepath.Dir(path)))
+	case "fish":
+		output.WriteString(hint.Sprint("💡 fish loads it automatically in new shells\n"))
+	default:
+		output.WriteString(hint.Sprint("💡 Load it from your PowerShell profile:\n"))
+		output.WriteString(fmt.Sprintf("   Add-Content $PROFILE \". '%s'\"\n", path))
+	}
+
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}, nil
+}
+
+// CompleteArguments suggests the supported shells
+func (c *InstallCompletionCommand) CompleteArguments(args []string) []string {
+	if len(args) == 1 {
+		return append(append([]string{}, CompletionShells...), "--path")
+	}
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -34,6 +35,7 @@ type HistoryEntry struct {
 	Duration  int64     `json:"duration_ms"`
 	Tags      []string  `json:"tags"`
 	Category  string    `json:"category"`
+	Session   string    `json:"session,omitempty"`
 }
 
 // CommandPattern represents a detected usage pattern
@@ -55,7 +57,7 @@ func NewSmartHistoryCommand(registry *commands.Registry) *SmartHistoryCommand {
 		BaseCommand: commands.NewBaseCommand(
 			"history",
 			"Smart command history with AI-powered search and analysis",
-			"history [smart|patterns|suggest|timeline|export|stats] [query]",
+			"history [smart|patterns|suggest|timeline|export [json|csv|txt|<file.ss>]|stats [--json [file]] [--top N]] [query]",
 			[]string{"windows", "linux", "darwin"},
 			false,
 		),
@@ -91,9 +93,14 @@ func (h *SmartHistoryCommand) Execute(ctx context.Context, args *commands.Argume
 		if len(args.Raw) > 1 {
 			format = args.Raw[1]
 		}
-		return h.exportHistory(format, startTime)
+		// Anything other than a format is a script to write
+		switch format {
+		case "json", "csv", "txt":
+			return h.exportHistory(format, startTime)
+		}
+		return h.exportScript(args.Raw[1:], startTime), nil
 	case "stats":
-		return h.showStatistics(startTime)
+		return h.showStatistics(args.Raw[1:], startTime)
 	case "clear":
 		return h.clearHistory(startTime)
 	case "add":
@@ -323,9 +330,39 @@ func (h *SmartHistoryCommand) showTimeline(startTime time.Time) (*commands.Resul
 	}, nil
 }
 
-// showStatistics displays command usage statistics
-func (h *SmartHistoryCommand) showStatistics(startTime time.Time) (*commands.Result, error) {
-	entries, err := h.loadHistory()
+// showStatistics displays command usage statistics, or writes them as JSON with
+// --json [file]. The history file is streamed rather than loaded whole.
+func (h *SmartHistoryCommand) showStatistics(args []string, startTime time.Time) (*commands.Result, error) {
+	jsonOutput := false
+	jsonFile := ""
+	top := 10
+
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--json":
+			jsonOutput = true
+			if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+				jsonFile = args[i+1]
+				i++
+			}
+		case "--top":
+			if i+1 < len(args) {
+				if n, err := strconv.Atoi(args[i+1]); err == nil && n > 0 {
+					top = n
+				}
+				i++
+			}
+		}
+	}
+
+	stats := &HistoryStats{Categories: map[string]int{}}
+	file, err := os.Open(h.historyFile)
+	if err == nil {
+		stats, err = AnalyzeHistory(file, top)
+		file.Close()
+	} else if os.IsNotExist(err) {
+		err = nil
+	}
 	if err != nil {
 		return &commands.Result{
 			Output:   fmt.Sprintf("Error loading history: %v", err),
@@ -334,7 +371,33 @@ func (h *SmartHistoryCommand) showStatistics(startTime time.Time) (*commands.Res
 		}, nil
 	}
 
-	stats := h.calculateStatistics(entries)
+	if jsonOutput {
+		data, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+
+		if jsonFile == "" {
+			return &commands.Result{
+				Output:   string(data) + "\n",
+				ExitCode: 0,
+				Duration: time.Since(startTime),
+			}, nil
+		}
+
+		if err := ioutil.WriteFile(jsonFile, data, 0644); err != nil {
+			return &commands.Result{
+				Output:   fmt.Sprintf("Error writing statistics: %v", err),
+				ExitCode: 1,
+				Duration: time.Since(startTime),
+			}, nil
+		}
+		return &commands.Result{
+			Output:   color.New(color.FgGreen).Sprintf("✅ Statistics exported to %s\n", jsonFile),
+			ExitCode: 0,
+			Duration: time.Since(startTime),
+		}, nil
+	}
 
 	var output strings.Builder
 	headerColor := color.New(color.FgHiYellow, color.Bold)
@@ -342,7 +405,7 @@ func (h *SmartHistoryCommand) showStatistics(startTime time.Time) (*commands.Res
 	output.WriteString(headerColor.Sprint("📊 Command Statistics\n"))
 	output.WriteString("═══════════════════════════════════════════════════════════════\n")
 
-	h.formatStatistics(&output, stats, entries)
+	h.formatStatistics(&output, stats)
 
 	return &commands.Result{
 		Output:   output.String(),
@@ -811,134 +874,68 @@ func (h *SmartHistoryCommand) formatTimelineDate(output *strings.Builder, date s
 	output.WriteString("\n")
 }
 
-func (h *SmartHistoryCommand) calculateStatistics(entries []HistoryEntry) map[string]interface{} {
-	stats := make(map[string]interface{})
-
-	if len(entries) == 0 {
-		return stats
-	}
-
-	// Basic counts
-	stats["total_commands"] = len(entries)
-
-	// Command frequency
-	cmdFreq := make(map[string]int)
-	categoryFreq := make(map[string]int)
-	hourlyUsage := make(map[int]int)
-	dailyUsage := make(map[string]int)
-	successRate := 0
-
-	for _, entry := range entries {
-		cmd := strings.Fields(entry.Command)[0]
-		cmdFreq[cmd]++
-		categoryFreq[entry.Category]++
-		hourlyUsage[entry.Timestamp.Hour()]++
-		dailyUsage[entry.Timestamp.Format("2006-01-02")]++
-
-		if entry.ExitCode == 0 {
-			successRate++
-		}
-	}
-
-	stats["command_frequency"] = cmdFreq
-	stats["category_frequency"] = categoryFreq
-	stats["hourly_usage"] = hourlyUsage
-	stats["daily_usage"] = dailyUsage
-	stats["success_rate"] = float64(successRate) / float64(len(entries)) * 100
-
-	// Find most used command
-	maxFreq := 0
-	mostUsed := ""
-	for cmd, freq := range cmdFreq {
-		if freq > maxFreq {
-			maxFreq = freq
-			mostUsed = cmd
-		}
-	}
-	stats["most_used_command"] = mostUsed
-	stats["most_used_frequency"] = maxFreq
-
-	// Find busiest hour
-	maxHourUsage := 0
-	busiestHour := 0
-	for hour, usage := range hourlyUsage {
-		if usage > maxHourUsage {
-			maxHourUsage = usage
-			busiestHour = hour
-		}
-	}
-	stats["busiest_hour"] = busiestHour
-	stats["busiest_hour_usage"] = maxHourUsage
-
-	return stats
-}
-
-func (h *SmartHistoryCommand) formatStatistics(output *strings.Builder, stats map[string]interface{}, entries []HistoryEntry) {
+// formatStatistics renders usage statistics with bar charts
+func (h *SmartHistoryCommand) formatStatistics(output *strings.Builder, stats *HistoryStats) {
 	headerColor := color.New(color.FgHiYellow, color.Bold)
 	labelColor := color.New(color.FgHiBlack)
 	valueColor := color.New(color.FgHiCyan)
 
+	if stats.TotalCommands == 0 {
+		output.WriteString("No command history recorded yet\n")
+		return
+	}
+
 	// Basic statistics
 	output.WriteString(headerColor.Sprint("📊 Overview\n"))
 	output.WriteString(fmt.Sprintf("   Total Commands: %s\n",
-		valueColor.Sprint(stats["total_commands"])))
+		valueColor.Sprint(stats.TotalCommands)))
+	output.WriteString(fmt.Sprintf("   Sessions: %s (%s commands per session)\n",
+		valueColor.Sprint(stats.Sessions),
+		valueColor.Sprintf("%.1f", stats.CommandsPerSession)))
 	output.WriteString(fmt.Sprintf("   Success Rate: %s%%\n",
-		valueColor.Sprintf("%.1f", stats["success_rate"])))
-	output.WriteString(fmt.Sprintf("   Most Used: %s (%s times)\n\n",
-		valueColor.Sprint(stats["most_used_command"]),
-		valueColor.Sprint(stats["most_used_frequency"])))
+		valueColor.Sprintf("%.1f", stats.SuccessRate)))
+	if !stats.FirstCommand.IsZero() {
+		output.WriteString(fmt.Sprintf("   Period: %s → %s\n",
+			valueColor.Sprint(stats.FirstCommand.Format("2006-01-02")),
+			valueColor.Sprint(stats.LastCommand.Format("2006-01-02"))))
+	}
+	output.WriteString("\n")
 
 	// Top commands
 	output.WriteString(headerColor.Sprint("🏆 Top Commands\n"))
-	cmdFreq := stats["command_frequency"].(map[string]int)
-	topCommands := h.getTopItems(cmdFreq, 5)
-	for i, item := range topCommands {
-		bar := h.createUsageBar(item.Count, stats["total_commands"].(int))
-		output.WriteString(fmt.Sprintf("   %d. %s %s %s\n",
+	for i, item := range stats.TopCommands {
+		bar := h.createUsageBar(item.Count, stats.TotalCommands)
+		output.WriteString(fmt.Sprintf("   %2d. %s %s %s\n",
 			i+1,
-			valueColor.Sprintf("%-12s", item.Key),
+			valueColor.Sprintf("%-12s", item.Command),
 			bar,
 			labelColor.Sprintf("(%d)", item.Count)))
 	}
 	output.WriteString("\n")
 
-	// Activity patterns
-	output.WriteString(headerColor.Sprint("⏰ Activity Patterns\n"))
-	output.WriteString(fmt.Sprintf("   Busiest Hour: %s:00 (%s commands)\n",
-		valueColor.Sprintf("%02d", stats["busiest_hour"]),
-		valueColor.Sprint(stats["busiest_hour_usage"])))
-
-	// Category breakdown
-	output.WriteString(fmt.Sprintf("   Categories: "))
-	categoryFreq := stats["category_frequency"].(map[string]int)
-	categories := []string{}
-	for cat, freq := range categoryFreq {
-		categories = append(categories, fmt.Sprintf("%s(%d)", cat, freq))
-	}
-	output.WriteString(strings.Join(categories, ", "))
-	output.WriteString("\n")
-}
-
-type KeyValue struct {
-	Key   string
-	Count int
-}
-
-func (h *SmartHistoryCommand) getTopItems(freq map[string]int, limit int) []KeyValue {
-	var items []KeyValue
-	for key, count := range freq {
-		items = append(items, KeyValue{Key: key, Count: count})
+	// Activity by hour, scaled to the busiest hour
+	output.WriteString(headerColor.Sprint("⏰ Busiest Hours\n"))
+	busiest := stats.HourlyUsage[stats.BusiestHour]
+	for hour, count := range stats.HourlyUsage {
+		if count == 0 {
+			continue
+		}
+		output.WriteString(fmt.Sprintf("   %s %s %s\n",
+			valueColor.Sprintf("%02d:00", hour),
+			h.createUsageBar(count, busiest),
+			labelColor.Sprintf("(%d)", count)))
 	}
 
-	sort.Slice(items, func(i, j int) bool {
-		return items[i].Count > items[j].Count
-	})
-
-	if len(items) > limit {
-		items = items[:limit]
+	// Category breakdown
+	if len(stats.Categories) > 0 {
+		categories := make([]string, 0, len(stats.Categories))
+		for category, count := range stats.Categories {
+			categories = append(categories, fmt.Sprintf("%s(%d)", category, count))
+		}
+		sort.Strings(categories)
+		output.WriteString("\n")
+		output.WriteString(fmt.Sprintf("   Categories: %s\n", strings.Join(categories, ", ")))
 	}
-
-	return items
 }
 
 func (h *SmartHistoryCommand) createUsageBar(count, total int) string {
@@ -0,0 +1,480 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+
+	"github.com/fatih/color"
+)
+
+// SectionDiff is what changed in one section between two snapshots
+type SectionDiff struct {
+	Section string   `json:"section"`
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+	// Changed lists packages whose version differs, as "name: old → new"
+	Changed []string `json:"changed,omitempty"`
+	// Skipped is set when either snapshot couldn't gather the section
+	Skipped bool `json:"skipped,omitempty"`
+}
+
+// SnapshotDiff is the difference between a baseline snapshot and a later one
+type SnapshotDiff struct {
+	From     string        `json:"from"`
+	To       string        `json:"to"`
+	FromTime time.Time     `json:"from_time"`
+	ToTime   time.Time     `json:"to_time"`
+	Sections []SectionDiff `json:"sections"`
+}
+
+// Changes counts the added, removed and changed items across all sections
+func (d *SnapshotDiff) Changes() int {
+	total := 0
+	for _, section := range d.Sections {
+		total += len(section.Added) + len(section.Removed) + len(section.Changed)
+	}
+	return total
+}
+
+// DiffSnapshots compares a later snapshot against a baseline
+func DiffSnapshots(from, to *SystemSnapshot) *SnapshotDiff {
+	diff := &SnapshotDiff{From: from.Name, To: to.Name, FromTime: from.TakenAt, ToTime: to.TakenAt}
+
+	skipped := make(map[string]bool)
+	for _, section := range append(append([]string{}, from.Unavailable...), to.Unavailable...) {
+		skipped[section] = true
+	}
+
+	packages := SectionDiff{Section: SectionPackages, Skipped: skipped[SectionPackages]}
+	if !packages.Skipped {
+		for name, version := range to.Packages {
+			old, existed := from.Packages[name]
+			switch {
+			case !existed:
+				packages.Added = append(packages.Added, strings.TrimSpace(name+" "+version))
+			case old != version:
+				packages.Changed = append(packages.Changed, fmt.Sprintf("%s: %s → %s", name, old, version))
+			}
+		}
+		for name, version := range from.Packages {
+			if _, exists := to.Packages[name]; !exists {
+				packages.Removed = append(packages.Removed, strings.TrimSpace(name+" "+version))
+			}
+		}
+		sort.Strings(packages.Added)
+		sort.Strings(packages.Removed)
+		sort.Strings(packages.Changed)
+	}
+	diff.Sections = append(diff.Sections, packages)
+
+	lists := []struct {
+		section  string
+		from, to []string
+	}{
+		{SectionServices, from.Services, to.Services},
+		{SectionPorts, from.Ports, to.Ports},
+		{SectionNetwork, from.Network, to.Network},
+	}
+	for _, list := range lists {
+		section := SectionDiff{Section: list.section, Skipped: skipped[list.section]}
+		if !section.Skipped {
+			section.Added = missingFrom(list.to, list.from)
+			section.Removed = missingFrom(list.from, list.to)
+		}
+		diff.Sections = append(diff.Sections, section)
+	}
+	return diff
+}
+
+// missingFrom returns the values of a that aren't in b
+func missingFrom(a, b []string) []string {
+	present := make(map[string]bool, len(b))
+	for _, value := range b {
+		present[value] = true
+	}
+	var missing []string
+	for _, value := range a {
+		if !present[value] {
+			missing = append(missing, value)
+		}
+	}
+	return missing
+}
+
+// SaveSnapshot writes a snapshot as JSON
+func SaveSnapshot(snapshot *SystemSnapshot, path string) error {
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// LoadSnapshot reads a snapshot written by SaveSnapshot
+func LoadSnapshot(path string) (*SystemSnapshot, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var snapshot SystemSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("corrupt snapshot %s: %w", path, err)
+	}
+	return &snapshot, nil
+}
+
+// SnapshotCommand saves system state and shows what changed since
+type SnapshotCommand struct {
+	*commands.BaseCommand
+	commands.ToolRunner
+	dir string
+}
+
+// NewSnapshotCommand creates a snapshot command storing snapshots under ~/.supershell
+func NewSnapshotCommand() *SnapshotCommand {
+	homeDir, _ := os.UserHomeDir()
+
+	return &SnapshotCommand{
+		BaseCommand: commands.NewBaseCommand(
+			"snapshot",
+			"Snapshot packages, services, ports and network settings, and diff against them later",
+			"snapshot [save [name|file]|list|show <name>|diff <name> [other] [--json]|remove <name>]",
+			[]string{"windows", "linux", "darwin"},
+			false,
+		),
+		dir: filepath.Join(homeDir, ".supershell", "snapshots"),
+	}
+}
+
+// Execute handles snapshot operations
+func (s *SnapshotCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	startTime := time.Now()
+
+	if len(args.Raw) == 0 {
+		return s.showHelp(startTime), nil
+	}
+
+	subcommand := args.Raw[0]
+	rest := args.Raw[1:]
+
+	switch subcommand {
+	case "save", "take":
+		name := ""
+		if len(rest) > 0 {
+			name = rest[0]
+		}
+		return s.save(ctx, name, startTime), nil
+	case "list", "ls":
+		return s.list(startTime), nil
+	case "show":
+		if len(rest) < 1 {
+			return commands.ErrorResult("Usage: snapshot show <name>\n", commands.UsageError(s.Name(), "missing snapshot name"), startTime), nil
+		}
+		return s.show(rest[0], startTime), nil
+	case "diff":
+		jsonOutput := false
+		var names []string
+		for _, arg := range rest {
+			if arg == "--json" {
+				jsonOutput = true
+			} else {
+				names = append(names, arg)
+			}
+		}
+		if len(names) < 1 || len(names) > 2 {
+			return commands.ErrorResult("Usage: snapshot diff <name> [other] [--json]\n", commands.UsageError(s.Name(), "diff takes one or two snapshot names"), startTime), nil
+		}
+		return s.diff(ctx, names, jsonOutput, startTime), nil
+	case "remove", "rm", "delete":
+		if len(rest) < 1 {
+			return commands.ErrorResult("Usage: snapshot remove <name>\n", commands.UsageError(s.Name(), "missing snapshot name"), startTime), nil
+		}
+		return s.remove(rest[0], startTime), nil
+	default:
+		return commands.ErrorResult("Use: save, list, show, diff, remove\n", commands.UsageError(s.Name(), "unknown subcommand '%s'", subcommand), startTime), nil
+	}
+}
+
+// path returns the file for a snapshot name; anything that looks like a path is used
+// as given
+func (s *SnapshotCommand) path(name string) string {
+	if strings.ContainsAny(name, `/\`) || strings.HasSuffix(name, ".json") {
+		return name
+	}
+	return filepath.Join(s.dir, name+".json")
+}
+
+// save gathers the current state and writes it to disk
+func (s *SnapshotCommand) save(ctx context.Context, name string, startTime time.Time) *commands.Result {
+	if name == "" {
+		name = startTime.Format("20060102-150405")
+	}
+
+	fmt.Print("📸 Gathering system state...")
+	snapshot := TakeSnapshot(ctx, s.Runner(), strings.TrimSuffix(filepath.Base(name), ".json"))
+	fmt.Print("\r\033[K")
+
+	path := s.path(name)
+	if err := SaveSnapshot(snapshot, path); err != nil {
+		return commands.ErrorResult("", err, startTime)
+	}
+
+	var output strings.Builder
+	output.WriteString(color.New(color.FgGreen, color.Bold).Sprintf("✅ Saved snapshot '%s'\n", snapshot.Name))
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+	s.writeCounts(&output, snapshot)
+	output.WriteString(fmt.Sprintf("💾 File:       %s\n", path))
+	output.WriteString(fmt.Sprintf("💡 Later, run 'snapshot diff %s' to see what changed\n", name))
+
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}
+}
+
+// list shows the saved snapshots, newest first
+func (s *SnapshotCommand) list(startTime time.Time) *commands.Result {
+	files, _ := filepath.Glob(filepath.Join(s.dir, "*.json"))
+
+	var snapshots []*SystemSnapshot
+	for _, file := range files {
+		if snapshot, err := LoadSnapshot(file); err == nil {
+			snapshot.Name = strings.TrimSuffix(filepath.Base(file), ".json")
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+	if len(snapshots) == 0 {
+		return &commands.Result{
+			Output:   "No snapshots saved yet. Use: snapshot save [name]\n",
+			ExitCode: 0,
+			Duration: time.Since(startTime),
+		}
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].TakenAt.After(snapshots[j].TakenAt) })
+
+	var output strings.Builder
+	output.WriteString(color.New(color.FgCyan, color.Bold).Sprint("📸 SYSTEM SNAPSHOTS\n"))
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+	output.WriteString(fmt.Sprintf("%-24s %-17s %-16s %9s %9s %6s\n", "Name", "Taken", "Host", "Packages", "Services", "Ports"))
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+	for _, snapshot := range snapshots {
+		output.WriteString(fmt.Sprintf("%s %-17s %-16s %9d %9d %6d\n",
+			color.New(color.FgYellow).Sprintf("%-24s", snapshot.Name), snapshot.TakenAt.Format("2006-01-02 15:04"),
+			snapshot.Host, len(snapshot.Packages), len(snapshot.Services), len(snapshot.Ports)))
+	}
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}
+}
+
+// show prints the contents of a saved snapshot
+func (s *SnapshotCommand) show(name string, startTime time.Time) *commands.Result {
+	snapshot, err := s.load(name)
+	if err != nil {
+		return commands.ErrorResult("", err, startTime)
+	}
+
+	var output strings.Builder
+	output.WriteString(color.New(color.FgCyan, color.Bold).Sprintf("📸 SNAPSHOT %s\n", snapshot.Name))
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+	output.WriteString(fmt.Sprintf("🖥️  Host:       %s (%s)\n", snapshot.Host, snapshot.OS))
+	output.WriteString(fmt.Sprintf("🕒 Taken:      %s\n", snapshot.TakenAt.Format("2006-01-02 15:04:05")))
+	s.writeCounts(&output, snapshot)
+
+	sections := []struct {
+		title string
+		items []string
+	}{
+		{"🔧 Services", snapshot.Services},
+		{"🔌 Listening ports", snapshot.Ports},
+		{"🌐 Network", snapshot.Network},
+	}
+	for _, section := range sections {
+		output.WriteString("\n" + color.New(color.FgYellow, color.Bold).Sprint(section.title) + "\n")
+		for _, item := range section.items {
+			output.WriteString("  " + item + "\n")
+		}
+	}
+	output.WriteString(fmt.Sprintf("\n💡 %d packages not listed; see %s\n", len(snapshot.Packages), s.path(name)))
+
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}
+}
+
+// diff compares a saved snapshot against the current state or another snapshot
+func (s *SnapshotCommand) diff(ctx context.Context, names []string, jsonOutput bool, startTime time.Time) *commands.Result {
+	from, err := s.load(names[0])
+	if err != nil {
+		return commands.ErrorResult("", err, startTime)
+	}
+
+	var to *SystemSnapshot
+	if len(names) == 2 {
+		if to, err = s.load(names[1]); err != nil {
+			return commands.ErrorResult("", err, startTime)
+		}
+	} else {
+		if !jsonOutput {
+			fmt.Print("📸 Gathering system state...")
+		}
+		to = TakeSnapshot(ctx, s.Runner(), "now")
+		if !jsonOutput {
+			fmt.Print("\r\033[K")
+		}
+	}
+
+	diff := DiffSnapshots(from, to)
+
+	if jsonOutput {
+		data, err := json.MarshalIndent(diff, "", "  ")
+		if err != nil {
+			return commands.ErrorResult("", err, startTime)
+		}
+		return &commands.Result{
+			Output:   string(data) + "\n",
+			ExitCode: 0,
+			Duration: time.Since(startTime),
+		}
+	}
+
+	var output strings.Builder
+	output.WriteString(color.New(color.FgCyan, color.Bold).Sprint("🔍 SYSTEM CHANGES\n"))
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+	output.WriteString(fmt.Sprintf("📸 From:       %s (%s)\n", color.New(color.FgYellow).Sprint(from.Name), from.TakenAt.Format("2006-01-02 15:04:05")))
+	output.WriteString(fmt.Sprintf("📸 To:         %s (%s)\n", color.New(color.FgYellow).Sprint(to.Name), to.TakenAt.Format("2006-01-02 15:04:05")))
+	if from.Host != to.Host {
+		output.WriteString(color.New(color.FgYellow).Sprintf("⚠️  Snapshots are from different hosts (%s, %s)\n", from.Host, to.Host))
+	}
+
+	titles := map[string]string{
+		SectionPackages: "📦 Packages",
+		SectionServices: "🔧 Services",
+		SectionPorts:    "🔌 Listening ports",
+		SectionNetwork:  "🌐 Network",
+	}
+	added := color.New(color.FgGreen)
+	removed := color.New(color.FgRed)
+	changed := color.New(color.FgYellow)
+
+	for _, section := range diff.Sections {
+		output.WriteString("\n" + color.New(color.FgCyan, color.Bold).Sprint(titles[section.Section]))
+		switch {
+		case section.Skipped:
+			output.WriteString(color.New(color.FgHiBlack).Sprint("  not captured by one of the snapshots\n"))
+			continue
+		case len(section.Added)+len(section.Removed)+len(section.Changed) == 0:
+			output.WriteString(color.New(color.FgHiBlack).Sprint("  no changes\n"))
+			continue
+		}
+		output.WriteString(fmt.Sprintf("  %s %s %s\n",
+			added.Sprintf("+%d", len(section.Added)), removed.Sprintf("-%d", len(section.Removed)), changed.Sprintf("~%d", len(section.Changed))))
+
+		for _, item := range section.Added {
+			output.WriteString(added.Sprintf("  + %s\n", item))
+		}
+		for _, item := range section.Removed {
+			output.WriteString(removed.Sprintf("  - %s\n", item))
+		}
+		for _, item := range section.Changed {
+			output.WriteString(changed.Sprintf("  ~ %s\n", item))
+		}
+	}
+
+	output.WriteString("\n═══════════════════════════════════════════════════════════════\n")
+	if diff.Changes() == 0 {
+		output.WriteString(color.New(color.FgGreen, color.Bold).Sprint("✅ Nothing changed\n"))
+	} else {
+		output.WriteString(fmt.Sprintf("📊 %d changes in %v\n", diff.Changes(), to.TakenAt.Sub(from.TakenAt).Round(time.Minute)))
+	}
+
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}
+}
+
+// remove deletes a saved snapshot
+func (s *SnapshotCommand) remove(name string, startTime time.Time) *commands.Result {
+	if err := os.Remove(s.path(name)); err != nil {
+		if os.IsNotExist(err) {
+			err = fmt.Errorf("no snapshot named '%s'", name)
+		}
+		return commands.ErrorResult("", err, startTime)
+	}
+	return &commands.Result{
+		Output:   color.New(color.FgGreen).Sprintf("✅ Removed snapshot '%s'\n", name),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}
+}
+
+// load reads a saved snapshot by name or path
+func (s *SnapshotCommand) load(name string) (*SystemSnapshot, error) {
+	snapshot, err := LoadSnapshot(s.path(name))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no snapshot named '%s' (see 'snapshot list')", name)
+	}
+	return snapshot, err
+}
+
+// writeCounts summarizes the size of each section
+func (s *SnapshotCommand) writeCounts(output *strings.Builder, snapshot *SystemSnapshot) {
+	unavailable := make(map[string]bool)
+	for _, section := range snapshot.Unavailable {
+		unavailable[section] = true
+	}
+	count := func(section string, n int) string {
+		if unavailable[section] {
+			return color.New(color.FgHiBlack).Sprint("unavailable")
+		}
+		return fmt.Sprintf("%d", n)
+	}
+
+	output.WriteString(fmt.Sprintf("📦 Packages:   %s\n", count(SectionPackages, len(snapshot.Packages))))
+	output.WriteString(fmt.Sprintf("🔧 Services:   %s\n", count(SectionServices, len(snapshot.Services))))
+	output.WriteString(fmt.Sprintf("🔌 Ports:      %s\n", count(SectionPorts, len(snapshot.Ports))))
+	output.WriteString(fmt.Sprintf("🌐 Network:    %s\n", count(SectionNetwork, len(snapshot.Network))))
+}
+
+// showHelp displays snapshot usage
+func (s *SnapshotCommand) showHelp(startTime time.Time) *commands.Result {
+	var output strings.Builder
+	output.WriteString(color.New(color.FgCyan, color.Bold).Sprint("📸 SYSTEM SNAPSHOTS\n"))
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+	output.WriteString("  snapshot save [name|file]        Record installed packages, services, ports and network\n")
+	output.WriteString("  snapshot list                    List saved snapshots\n")
+	output.WriteString("  snapshot show <name>             Show a saved snapshot\n")
+	output.WriteString("  snapshot diff <name> [other]     Show what changed since a snapshot, or between two\n")
+	output.WriteString("  snapshot remove <name>           Delete a saved snapshot\n")
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+	output.WriteString("  snapshot save before-upgrade\n")
+	output.WriteString("  snapshot diff before-upgrade --json\n")
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}
+}
@@ -0,0 +1,364 @@
+package system
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+)
+
+// Snapshot sections, also used as their names in Unavailable
+const (
+	SectionPackages = "packages"
+	SectionServices = "services"
+	SectionPorts    = "ports"
+	SectionNetwork  = "network"
+)
+
+// SystemSnapshot is the state of a machine at one point in time, as compared by
+// snapshot diff. Every list is sorted.
+type SystemSnapshot struct {
+	Name    string    `json:"name"`
+	Host    string    `json:"host"`
+	OS      string    `json:"os"`
+	TakenAt time.Time `json:"taken_at"`
+	// Packages maps installed package or program names to their versions
+	Packages map[string]string `json:"packages"`
+	// Services are the running services
+	Services []string `json:"services"`
+	// Ports are the listening sockets as "proto address:port"
+	Ports []string `json:"ports"`
+	// Network holds interface addresses, DNS servers and the default gateway
+	Network []string `json:"network"`
+	// Unavailable lists the sections that couldn't be gathered on this machine
+	Unavailable []string `json:"unavailable,omitempty"`
+}
+
+// TakeSnapshot gathers the current system state. A section that can't be read is
+// recorded as unavailable rather than failing the snapshot.
+func TakeSnapshot(ctx context.Context, runner commands.CommandRunner, name string) *SystemSnapshot {
+	snapshot := &SystemSnapshot{Name: name, OS: runtime.GOOS, TakenAt: time.Now()}
+	snapshot.Host, _ = os.Hostname()
+
+	var err error
+	if snapshot.Packages, err = gatherPackages(ctx, runner); err != nil {
+		snapshot.Unavailable = append(snapshot.Unavailable, SectionPackages)
+	}
+	if snapshot.Services, err = gatherServices(ctx, runner); err != nil {
+		snapshot.Unavailable = append(snapshot.Unavailable, SectionServices)
+	}
+	if snapshot.Ports, err = gatherListeningPorts(ctx, runner); err != nil {
+		snapshot.Unavailable = append(snapshot.Unavailable, SectionPorts)
+	}
+	if snapshot.Network, err = gatherNetworkConfig(ctx, runner); err != nil {
+		snapshot.Unavailable = append(snapshot.Unavailable, SectionNetwork)
+	}
+	return snapshot
+}
+
+// gatherPackages lists installed software with the platform's package database
+func gatherPackages(ctx context.Context, runner commands.CommandRunner) (map[string]string, error) {
+	switch runtime.GOOS {
+	case "windows":
+		// The uninstall keys are what Programs and Features lists, and unlike
+		// Win32_Product reading them doesn't trigger installer consistency checks
+		script := `Get-ItemProperty HKLM:\Software\Microsoft\Windows\CurrentVersion\Uninstall\*, ` +
+			`HKLM:\Software\WOW6432Node\Microsoft\Windows\CurrentVersion\Uninstall\* -ErrorAction SilentlyContinue | ` +
+			`Where-Object DisplayName | ForEach-Object { $_.DisplayName + "` + "`t" + `" + $_.DisplayVersion }`
+		out, err := runner.Run(ctx, "powershell", "-NoProfile", "-Command", script)
+		if err != nil {
+			return nil, err
+		}
+		return parseTabbedPackages(string(out)), nil
+	case "darwin":
+		out, err := runner.Run(ctx, "brew", "list", "--versions")
+		if err != nil {
+			return nil, err
+		}
+		packages := make(map[string]string)
+		for _, line := range strings.Split(string(out), "\n") {
+			if fields := strings.Fields(line); len(fields) > 0 {
+				packages[fields[0]] = strings.Join(fields[1:], " ")
+			}
+		}
+		return packages, nil
+	}
+
+	if out, err := runner.Run(ctx, "dpkg-query", "-W", "-f", "${Package}\t${Version}\t${Status}\n"); err == nil {
+		packages := make(map[string]string)
+		for _, line := range strings.Split(string(out), "\n") {
+			// Removed packages whose configuration is left behind are listed too
+			fields := strings.Split(line, "\t")
+			if len(fields) == 3 && strings.HasSuffix(fields[2], " installed") {
+				packages[fields[0]] = fields[1]
+			}
+		}
+		return packages, nil
+	}
+	if out, err := runner.Run(ctx, "rpm", "-qa", "--qf", "%{NAME}\t%{VERSION}-%{RELEASE}\n"); err == nil {
+		return parseTabbedPackages(string(out)), nil
+	}
+	if out, err := runner.Run(ctx, "apk", "list", "--installed"); err == nil {
+		packages := make(map[string]string)
+		for _, line := range strings.Split(string(out), "\n") {
+			// "musl-1.2.4-r2 x86_64 {musl} (MIT) [installed]", where the version is
+			// the last two dash-separated parts of the first field
+			fields := strings.Fields(line)
+			if len(fields) == 0 {
+				continue
+			}
+			parts := strings.Split(fields[0], "-")
+			if len(parts) < 3 {
+				continue
+			}
+			packages[strings.Join(parts[:len(parts)-2], "-")] = strings.Join(parts[len(parts)-2:], "-")
+		}
+		return packages, nil
+	}
+	return nil, fmt.Errorf("no supported package manager found")
+}
+
+// parseTabbedPackages parses "name<TAB>version" lines
+func parseTabbedPackages(text string) map[string]string {
+	packages := make(map[string]string)
+	for _, line := range strings.Split(text, "\n") {
+		fields := strings.SplitN(strings.TrimRight(line, "\r"), "\t", 2)
+		if fields[0] == "" {
+			continue
+		}
+		version := ""
+		if len(fields) == 2 {
+			version = fields[1]
+		}
+		packages[fields[0]] = version
+	}
+	return packages
+}
+
+// gatherServices lists the running services
+func gatherServices(ctx context.Context, runner commands.CommandRunner) ([]string, error) {
+	var services []string
+
+	switch runtime.GOOS {
+	case "windows":
+		out, err := runner.Run(ctx, "sc", "query", "type=", "service", "state=", "active")
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range strings.Split(string(out), "\n") {
+			if name := strings.TrimSpace(line); strings.HasPrefix(name, "SERVICE_NAME:") {
+				services = append(services, strings.TrimSpace(strings.TrimPrefix(name, "SERVICE_NAME:")))
+			}
+		}
+	case "darwin":
+		out, err := runner.Run(ctx, "launchctl", "list")
+		if err != nil {
+			return nil, err
+		}
+		// PID, last exit status and label; jobs that aren't running have no PID
+		for _, line := range strings.Split(string(out), "\n")[1:] {
+			if fields := strings.Fields(line); len(fields) == 3 && fields[0] != "-" {
+				services = append(services, fields[2])
+			}
+		}
+	default:
+		out, err := runner.Run(ctx, "systemctl", "list-units", "--type=service", "--state=running", "--no-legend", "--plain")
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range strings.Split(string(out), "\n") {
+			if fields := strings.Fields(line); len(fields) > 0 {
+				services = append(services, fields[0])
+			}
+		}
+	}
+
+	return sortedUnique(services), nil
+}
+
+// gatherListeningPorts lists the listening TCP sockets and bound UDP sockets
+func gatherListeningPorts(ctx context.Context, runner commands.CommandRunner) ([]string, error) {
+	if runtime.GOOS == "linux" {
+		if out, err := runner.Run(ctx, "ss", "-tuln"); err == nil {
+			var ports []string
+			for _, line := range strings.Split(string(out), "\n")[1:] {
+				// Netid State Recv-Q Send-Q Local Peer
+				if fields := strings.Fields(line); len(fields) >= 5 {
+					ports = append(ports, fields[0]+" "+fields[4])
+				}
+			}
+			return sortedUnique(ports), nil
+		}
+	}
+
+	out, err := runner.Run(ctx, "netstat", "-an")
+	if err != nil {
+		return nil, err
+	}
+	return parseListeningPorts(string(out)), nil
+}
+
+// parseListeningPorts picks the listening sockets out of `netstat -an`, whose columns
+// differ between Windows (proto local remote state) and Unix (proto recv-q send-q
+// local remote state)
+func parseListeningPorts(text string) []string {
+	var ports []string
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 {
+			continue
+		}
+		proto := strings.ToLower(fields[0])
+		local := fields[1]
+		if fields[0] != "TCP" && fields[0] != "UDP" {
+			if len(fields) < 5 {
+				continue
+			}
+			local = fields[3]
+		}
+
+		state := strings.ToUpper(fields[len(fields)-1])
+		switch {
+		case strings.HasPrefix(proto, "tcp") && (state == "LISTEN" || state == "LISTENING"):
+		case strings.HasPrefix(proto, "udp"):
+			// UDP has no listening state; every bound socket is reported
+		default:
+			continue
+		}
+		ports = append(ports, strings.TrimRight(proto, "46")+" "+local)
+	}
+	return sortedUnique(ports)
+}
+
+// gatherNetworkConfig describes the interface addresses, DNS servers and default
+// gateway, one "kind value" item each
+func gatherNetworkConfig(ctx context.Context, runner commands.CommandRunner) ([]string, error) {
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	var items []string
+	for _, iface := range interfaces {
+		if iface.Flags&net.FlagUp == 0 {
+			continue
+		}
+		addrs, _ := iface.Addrs()
+		for _, addr := range addrs {
+			items = append(items, fmt.Sprintf("address %s %s", iface.Name, addr.String()))
+		}
+	}
+
+	for _, server := range dnsServers(ctx, runner) {
+		items = append(items, "dns "+server)
+	}
+	if gateway := defaultGateway(ctx, runner); gateway != "" {
+		items = append(items, "gateway "+gateway)
+	}
+	return sortedUnique(items), nil
+}
+
+// dnsServers returns the configured DNS servers
+func dnsServers(ctx context.Context, runner commands.CommandRunner) []string {
+	var servers []string
+
+	if runtime.GOOS == "windows" {
+		out, err := runner.Run(ctx, "ipconfig", "/all")
+		if err != nil {
+			return nil
+		}
+		// "DNS Servers . . . : 8.8.8.8" with further servers alone on the next lines
+		inServers := false
+		for _, line := range strings.Split(string(out), "\n") {
+			value := strings.TrimSpace(line)
+			switch {
+			case strings.HasPrefix(value, "DNS Servers"):
+				inServers = true
+				value = strings.TrimSpace(value[strings.Index(value, ":")+1:])
+			case inServers && net.ParseIP(value) != nil:
+			default:
+				inServers = false
+				continue
+			}
+			if net.ParseIP(value) != nil {
+				servers = append(servers, value)
+			}
+		}
+		return servers
+	}
+
+	data, err := ioutil.ReadFile("/etc/resolv.conf")
+	if err != nil {
+		return nil
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if fields := strings.Fields(line); len(fields) >= 2 && fields[0] == "nameserver" {
+			servers = append(servers, fields[1])
+		}
+	}
+	return servers
+}
+
+// defaultGateway returns the gateway of the default IPv4 route
+func defaultGateway(ctx context.Context, runner commands.CommandRunner) string {
+	switch runtime.GOOS {
+	case "windows":
+		out, err := runner.Run(ctx, "route", "print", "0.0.0.0")
+		if err != nil {
+			return ""
+		}
+		// Network Destination, Netmask, Gateway, Interface, Metric
+		for _, line := range strings.Split(string(out), "\n") {
+			if fields := strings.Fields(line); len(fields) >= 3 && fields[0] == "0.0.0.0" && fields[1] == "0.0.0.0" {
+				return fields[2]
+			}
+		}
+	case "darwin":
+		out, err := runner.Run(ctx, "route", "-n", "get", "default")
+		if err != nil {
+			return ""
+		}
+		for _, line := range strings.Split(string(out), "\n") {
+			if fields := strings.Fields(line); len(fields) == 2 && fields[0] == "gateway:" {
+				return fields[1]
+			}
+		}
+	default:
+		out, err := runner.Run(ctx, "ip", "route", "show", "default")
+		if err != nil {
+			return ""
+		}
+		// "default via 10.0.0.1 dev eth0 ..."
+		fields := strings.Fields(string(out))
+		for i := 0; i+1 < len(fields); i++ {
+			if fields[i] == "via" {
+				if i+3 < len(fields) && fields[i+2] == "dev" {
+					return fields[i+1] + " dev " + fields[i+3]
+				}
+				return fields[i+1]
+			}
+		}
+	}
+	return ""
+}
+
+// sortedUnique sorts values and drops duplicates
+func sortedUnique(values []string) []string {
+	sort.Strings(values)
+	unique := make([]string, 0, len(values))
+	for i, value := range values {
+		if i == 0 || value != values[i-1] {
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
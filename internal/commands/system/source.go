@@ -0,0 +1,239 @@
+package system
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/config"
+	"suppercommand/internal/security"
+	"suppercommand/pkg/errors"
+
+	"github.com/fatih/color"
+)
+
+// SourceCommand runs a .ss script a line at a time, each line as if it had
+// been typed at the prompt. Scripts from elsewhere can be run with --safe,
+// which blocks the destructive commands listed in security.scripts, or
+// --confirm, which asks before each of them.
+type SourceCommand struct {
+	*commands.BaseCommand
+	flags    *commands.FlagSet
+	registry *commands.Registry
+	policy   config.ScriptsConfig
+}
+
+// NewSourceCommand creates a source command running scripts through registry
+// under policy
+func NewSourceCommand(registry *commands.Registry, policy config.ScriptsConfig) *SourceCommand {
+	usage := "source [--safe | --confirm] <script.ss>"
+	return &SourceCommand{
+		BaseCommand: commands.NewBaseCommand(
+			"source",
+			"Run the commands in a .ss script, optionally blocking destructive ones",
+			usage,
+			[]string{"windows", "linux", "darwin"},
+			false,
+		),
+		flags: commands.NewFlagSet("source", usage,
+			commands.FlagSpec{Name: "safe", Help: "Block the guarded commands, whatever security.scripts.guard says"},
+			commands.FlagSpec{Name: "confirm", Help: "Ask before running each guarded command"},
+		),
+		registry: registry,
+		policy:   policy,
+	}
+}
+
+// FlagSet returns the options source accepts
+func (s *SourceCommand) FlagSet() *commands.FlagSet {
+	return s.flags
+}
+
+// scriptLine is a command read from a script, with its line number
+type scriptLine struct {
+	number int
+	text   string
+}
+
+// Execute runs the script. Each line's output is printed as it finishes and a
+// failing line doesn't stop the ones after it; source fails when any line
+// failed or was blocked, listing the blocked commands.
+func (s *SourceCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	startTime := time.Now()
+
+	flags, result := s.flags.ParseArguments(args, startTime)
+	if result != nil {
+		return result, nil
+	}
+	if len(flags.Args()) != 1 {
+		return commands.UsageResult(s, startTime, "expected one script to run"), nil
+	}
+	if flags.Bool("safe") && flags.Bool("confirm") {
+		return commands.UsageResult(s, startTime, "--safe and --confirm can't be combined"), nil
+	}
+	if s.registry == nil {
+		return commands.ErrorResult("", fmt.Errorf("command registry not available"), startTime), nil
+	}
+
+	script := flags.Arg(0)
+	file, err := os.Open(script)
+	if err != nil {
+		return commands.ErrorResult("", err, startTime), nil
+	}
+	lines, err := readScript(file)
+	file.Close()
+	if err != nil {
+		return commands.ErrorResult("", fmt.Errorf("failed to read %s: %v", script, err), startTime), nil
+	}
+
+	mode := s.policy.Guard
+	switch {
+	case flags.Bool("safe"):
+		mode = security.ScriptGuardBlock
+	case flags.Bool("confirm"):
+		mode = security.ScriptGuardConfirm
+	}
+	var guard *security.ScriptGuard
+	if mode != security.ScriptGuardRun && mode != "" {
+		guard = security.NewScriptGuard(mode, s.policy.GuardedCommands)
+		ctx = commands.WithCommandGuard(ctx, guard)
+	}
+
+	out, diagnostics := commands.OutputWriter(ctx), commands.ErrorWriter(ctx)
+	var blocked []string
+	ran, failures := 0, 0
+	for _, line := range lines {
+		if ctx.Err() != nil {
+			break
+		}
+		before := 0
+		if guard != nil {
+			before = len(guard.Blocked())
+		}
+
+		result := s.runScriptLine(ctx, line.text)
+		ran++
+		if result.Output != "" {
+			fmt.Fprint(out, result.Output)
+			if !strings.HasSuffix(result.Output, "\n") {
+				fmt.Fprintln(out)
+			}
+		}
+		fmt.Fprint(diagnostics, result.Stderr)
+
+		if guard != nil {
+			if stopped := guard.Blocked()[before:]; len(stopped) > 0 {
+				for _, command := range stopped {
+					blocked = append(blocked, fmt.Sprintf("line %d: %s", line.number, command))
+				}
+				fmt.Fprint(diagnostics, color.New(color.FgYellow).Sprintf("⛔ %s:%d: %v\n", script, line.number, result.Error))
+				continue
+			}
+		}
+		if result.ExitCode != 0 {
+			failures++
+			reason := fmt.Sprintf("exit code %d", result.ExitCode)
+			if result.Error != nil {
+				reason = result.Error.Error()
+			}
+			fmt.Fprint(diagnostics, color.New(color.FgRed).Sprintf("❌ %s:%d: %s\n", script, line.number, reason))
+		}
+	}
+
+	return s.summary(ctx, script, len(lines), ran, failures, blocked, startTime), nil
+}
+
+// runScriptLine runs one line of a script: a command, or commands joined by
+// &&, || and ;. The result is that of the last command that ran.
+func (s *SourceCommand) runScriptLine(ctx context.Context, line string) *commands.Result {
+	startTime := time.Now()
+	steps, err := commands.SplitChain(line)
+	if err != nil {
+		return commands.ErrorResult("", errors.NewValidationError("%v", err), startTime)
+	}
+
+	last := &commands.Result{}
+	var output, stderr strings.Builder
+	for _, step := range steps {
+		if step.Background {
+			return commands.ErrorResult(output.String(), errors.NewValidationError("background jobs (&) can't be started from a script"), startTime)
+		}
+		if !step.ShouldRun(last.ExitCode) {
+			continue
+		}
+		words := commands.SplitCommandWords(step.Line)
+		if _, err := s.registry.Get(words[0].Text); err != nil {
+			last = commands.ErrorResult("", errors.NewNotFoundError("unknown command '%s'; scripts run SuperShell commands", words[0].Text), startTime)
+			continue
+		}
+		last = runLine(ctx, s.registry, step.Line)
+		output.WriteString(last.Output)
+		stderr.WriteString(last.Stderr)
+	}
+	last.Output, last.Stderr = output.String(), stderr.String()
+	return last
+}
+
+// summary reports what the script did: the blocked commands always, as the
+// reason a guarded script failed, and the outcome when output is decorated
+func (s *SourceCommand) summary(ctx context.Context, script string, total, ran, failures int, blocked []string, startTime time.Time) *commands.Result {
+	var stderr strings.Builder
+	if len(blocked) > 0 {
+		stderr.WriteString(color.New(color.FgYellow, color.Bold).Sprintf("⛔ Blocked %d guarded commands in %s:\n", len(blocked), script))
+		for _, command := range blocked {
+			stderr.WriteString("   " + command + "\n")
+		}
+	}
+
+	elapsed := time.Since(startTime).Round(time.Millisecond)
+	var err error
+	var outcome string
+	switch {
+	case ctx.Err() != nil && ran < total:
+		err = errors.Wrap(ctx.Err(), "source interrupted after %d of %d lines", ran, total)
+		outcome = color.New(color.FgYellow).Sprintf("⚠️  Interrupted after %d of %d lines (%v)\n", ran, total, elapsed)
+	case failures > 0:
+		err = errors.NewExecutionError("source: %d of %d lines failed", failures, ran)
+		outcome = color.New(color.FgRed).Sprintf("❌ %d of %d lines failed (%v)\n", failures, ran, elapsed)
+	case len(blocked) > 0:
+		err = errors.NewSecurityError("source: %d guarded commands blocked", len(blocked))
+		outcome = color.New(color.FgYellow).Sprintf("⚠️  Ran %d lines with %d commands blocked (%v)\n", ran, len(blocked), elapsed)
+	default:
+		outcome = color.New(color.FgGreen).Sprintf("✅ Ran %d lines from %s (%v)\n", ran, script, elapsed)
+	}
+	if commands.OutputOptionsFrom(ctx).Decorated() {
+		stderr.WriteString(outcome)
+	}
+
+	exitCode := 0
+	if err != nil {
+		exitCode = 1
+	}
+	return &commands.Result{
+		Stderr:   stderr.String(),
+		Error:    err,
+		ExitCode: exitCode,
+		Duration: time.Since(startTime),
+	}
+}
+
+// readScript reads the commands of a script, skipping blank lines and
+// comments starting with #
+func readScript(r io.Reader) ([]scriptLine, error) {
+	var lines []scriptLine
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for number := 1; scanner.Scan(); number++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		lines = append(lines, scriptLine{number: number, text: text})
+	}
+	return lines, scanner.Err()
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
@@ -24,7 +25,7 @@ func NewSysInfoCommand() *SysInfoCommand {
 		BaseCommand: commands.NewBaseCommand(
 			"sysinfo",
 			"Display comprehensive system information",
-			"sysinfo [-v|--verbose]",
+			"sysinfo [gpu] [sensors] [-v|--verbose] [--quiet] [--output-format text|json|csv]",
 			[]string{"windows", "linux", "darwin"},
 			false,
 		),
@@ -36,12 +37,31 @@ func (s *SysInfoCommand) Execute(ctx context.Context, args *commands.Arguments)
 	startTime := time.Now()
 
 	verbose := false
+	showGPU := false
+	showSensors := false
+	jsonOutput := false
 	for _, arg := range args.Raw {
-		if arg == "-v" || arg == "--verbose" {
+		switch arg {
+		case "-v", "--verbose":
 			verbose = true
+		case "gpu", "--gpu":
+			showGPU = true
+		case "sensors", "--sensors":
+			showSensors = true
+		case "--json":
+			jsonOutput = true
 		}
 	}
 
+	// --json predates the global --output-format and is kept as a shorthand for it
+	options := commands.OutputOptionsFrom(ctx)
+	if jsonOutput {
+		options.Format = commands.FormatJSON
+	}
+	if options.Structured() || options.Quiet {
+		return s.report(ctx, options, showSensors || verbose, startTime)
+	}
+
 	var output strings.Builder
 
 	// Header
@@ -69,9 +89,9 @@ func (s *SysInfoCommand) Execute(ctx context.Context, args *commands.Arguments)
 	var m runtime.MemStats
 	runtime.ReadMemStats(&m)
 
-	output.WriteString(fmt.Sprintf("  Memory Alloc: %s\n", formatBytes(m.Alloc)))
-	output.WriteString(fmt.Sprintf("  Total Alloc:  %s\n", formatBytes(m.TotalAlloc)))
-	output.WriteString(fmt.Sprintf("  Sys Memory:   %s\n", formatBytes(m.Sys)))
+	output.WriteString(fmt.Sprintf("  Memory Alloc: %s\n", commands.HumanizeBytes(int64(m.Alloc))))
+	output.WriteString(fmt.Sprintf("  Total Alloc:  %s\n", commands.HumanizeBytes(int64(m.TotalAlloc))))
+	output.WriteString(fmt.Sprintf("  Sys Memory:   %s\n", commands.HumanizeBytes(int64(m.Sys))))
 	output.WriteString("\n")
 
 	// Environment
@@ -107,6 +127,28 @@ func (s *SysInfoCommand) Execute(ctx context.Context, args *commands.Arguments)
 
 	output.WriteString("\n")
 
+	// Graphics adapters
+	if showGPU || verbose {
+		output.WriteString(color.New(color.FgGreen, color.Bold).Sprint("🎮 Graphics\n"))
+		if gpus, err := gatherGPUs(ctx); err != nil {
+			output.WriteString(fmt.Sprintf("  Unavailable: %v\n", err))
+		} else {
+			writeGPUInfo(&output, gpus)
+		}
+		output.WriteString("\n")
+	}
+
+	// Hardware sensors
+	if showSensors || verbose {
+		output.WriteString(color.New(color.FgRed, color.Bold).Sprint("🌡️  Sensors\n"))
+		if readings, err := readSensors(ctx); err != nil {
+			output.WriteString(fmt.Sprintf("  Unavailable: %v\n", err))
+		} else {
+			writeSensorReadings(&output, readings)
+		}
+		output.WriteString("\n")
+	}
+
 	// Verbose information
 	if verbose {
 		output.WriteString(color.New(color.FgRed, color.Bold).Sprint("🔍 Detailed Information\n"))
@@ -123,11 +165,11 @@ func (s *SysInfoCommand) Execute(ctx context.Context, args *commands.Arguments)
 
 		// Detailed memory stats
 		output.WriteString("Detailed Memory Statistics:\n")
-		output.WriteString(fmt.Sprintf("  HeapAlloc:    %s\n", formatBytes(m.HeapAlloc)))
-		output.WriteString(fmt.Sprintf("  HeapSys:      %s\n", formatBytes(m.HeapSys)))
-		output.WriteString(fmt.Sprintf("  HeapIdle:     %s\n", formatBytes(m.HeapIdle)))
-		output.WriteString(fmt.Sprintf("  HeapInuse:    %s\n", formatBytes(m.HeapInuse)))
-		output.WriteString(fmt.Sprintf("  HeapReleased: %s\n", formatBytes(m.HeapReleased)))
+		output.WriteString(fmt.Sprintf("  HeapAlloc:    %s\n", commands.HumanizeBytes(int64(m.HeapAlloc))))
+		output.WriteString(fmt.Sprintf("  HeapSys:      %s\n", commands.HumanizeBytes(int64(m.HeapSys))))
+		output.WriteString(fmt.Sprintf("  HeapIdle:     %s\n", commands.HumanizeBytes(int64(m.HeapIdle))))
+		output.WriteString(fmt.Sprintf("  HeapInuse:    %s\n", commands.HumanizeBytes(int64(m.HeapInuse))))
+		output.WriteString(fmt.Sprintf("  HeapReleased: %s\n", commands.HumanizeBytes(int64(m.HeapReleased))))
 		output.WriteString(fmt.Sprintf("  HeapObjects:  %d\n", m.HeapObjects))
 		output.WriteString(fmt.Sprintf("  GC Cycles:    %d\n", m.NumGC))
 		output.WriteString(fmt.Sprintf("  Last GC:      %s ago\n", time.Since(time.Unix(0, int64(m.LastGC))).Round(time.Millisecond)))
@@ -144,18 +186,107 @@ func (s *SysInfoCommand) Execute(ctx context.Context, args *commands.Arguments)
 	}, nil
 }
 
-// formatBytes formats bytes in human readable format
-func formatBytes(bytes uint64) string {
-	const unit = 1024
-	if bytes < unit {
-		return fmt.Sprintf("%d B", bytes)
+// sysInfoReport is the structured form of sysinfo
+type sysInfoReport struct {
+	OS           string          `json:"os"`
+	Architecture string          `json:"architecture"`
+	Hostname     string          `json:"hostname"`
+	CPUs         int             `json:"cpus"`
+	GoVersion    string          `json:"go_version"`
+	GPUs         []GPUInfo       `json:"gpus"`
+	Sensors      []SensorReading `json:"sensors,omitempty"`
+}
+
+// OutputFormats returns the structured formats sysinfo supports
+func (s *SysInfoCommand) OutputFormats() []commands.OutputFormat {
+	return []commands.OutputFormat{commands.FormatJSON, commands.FormatCSV}
+}
+
+// report returns the system information as JSON, as CSV field/value rows or, when
+// quiet, as plain "field: value" lines. GPUs are always included; sensors only when
+// asked for, since reading them can be slow on Windows.
+func (s *SysInfoCommand) report(ctx context.Context, options commands.OutputOptions, withSensors bool, startTime time.Time) (*commands.Result, error) {
+	report := sysInfoReport{
+		OS:           runtime.GOOS,
+		Architecture: runtime.GOARCH,
+		CPUs:         runtime.NumCPU(),
+		GoVersion:    runtime.Version(),
+		GPUs:         make([]GPUInfo, 0),
+	}
+	report.Hostname, _ = os.Hostname()
+	if gpus, err := gatherGPUs(ctx); err == nil && gpus != nil {
+		report.GPUs = gpus
+	}
+	if withSensors {
+		report.Sensors, _ = readSensors(ctx)
+	}
+
+	if options.Format == commands.FormatJSON {
+		return commands.JSONResult(report, startTime)
+	}
+
+	fields := report.fields()
+	if options.Format == commands.FormatCSV {
+		return commands.CSVResult([]string{"field", "value"}, fields, startTime), nil
+	}
+
+	var output strings.Builder
+	for _, field := range fields {
+		output.WriteString(fmt.Sprintf("%s: %s\n", field[0], field[1]))
+	}
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}, nil
+}
+
+// fields flattens the report into field/value pairs, one per GPU and sensor
+func (r sysInfoReport) fields() [][]string {
+	fields := [][]string{
+		{"os", r.OS},
+		{"architecture", r.Architecture},
+		{"hostname", r.Hostname},
+		{"cpus", strconv.Itoa(r.CPUs)},
+		{"go_version", r.GoVersion},
 	}
+	for i, gpu := range r.GPUs {
+		fields = append(fields, []string{fmt.Sprintf("gpu%d", i), gpu.Name})
+	}
+	for _, reading := range r.Sensors {
+		fields = append(fields, []string{reading.Chip + "/" + reading.Label, strconv.FormatFloat(reading.Value, 'f', -1, 64) + reading.Unit})
+	}
+	return fields
+}
 
-	div, exp := uint64(unit), 0
-	for n := bytes / unit; n >= unit; n /= unit {
-		div *= unit
-		exp++
+// writeGPUInfo renders each graphics adapter
+func writeGPUInfo(output *strings.Builder, gpus []GPUInfo) {
+	if len(gpus) == 0 {
+		output.WriteString("  No graphics adapters found\n")
+		return
 	}
 
-	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+	for i, gpu := range gpus {
+		kind := "discrete"
+		if gpu.Integrated {
+			kind = "integrated"
+		}
+		output.WriteString(fmt.Sprintf("  GPU %d:        %s %s\n", i, gpu.Name, color.New(color.FgHiBlack).Sprintf("(%s)", kind)))
+		if gpu.VRAM > 0 {
+			vram := commands.HumanizeBytes(int64(gpu.VRAM))
+			if gpu.VRAMUsed > 0 {
+				vram = fmt.Sprintf("%s used of %s", commands.HumanizeBytes(int64(gpu.VRAMUsed)), vram)
+			}
+			output.WriteString(fmt.Sprintf("    VRAM:       %s\n", vram))
+		}
+		if driver := strings.TrimSpace(gpu.Driver + " " + gpu.Version); driver != "" {
+			output.WriteString(fmt.Sprintf("    Driver:     %s\n", driver))
+		}
+		if gpu.Utilization != nil {
+			output.WriteString(fmt.Sprintf("    Usage:      %.0f%%\n", *gpu.Utilization))
+		}
+		if gpu.BusID != "" {
+			output.WriteString(fmt.Sprintf("    Bus:        %s\n", gpu.BusID))
+		}
+	}
 }
@@ -0,0 +1,141 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+
+	"github.com/fatih/color"
+)
+
+// TemplateCommand writes starter scripts and settings files
+type TemplateCommand struct {
+	*commands.BaseCommand
+}
+
+// NewTemplateCommand creates a new template command
+func NewTemplateCommand() *TemplateCommand {
+	return &TemplateCommand{
+		BaseCommand: commands.NewBaseCommand(
+			"template",
+			"Create starter scripts and config files from built-in templates",
+			"template [list] | template new <name> [file|-] [--force]",
+			[]string{"windows", "linux", "darwin"},
+			false,
+		),
+	}
+}
+
+// Execute lists the templates or writes one
+func (t *TemplateCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	startTime := time.Now()
+
+	if len(args.Raw) == 0 || args.Raw[0] == "list" {
+		return t.list(startTime), nil
+	}
+	if args.Raw[0] != "new" {
+		return commands.UsageResult(t, startTime, "unknown subcommand '%s'", args.Raw[0]), nil
+	}
+
+	force := false
+	var positional []string
+	for _, arg := range args.Raw[1:] {
+		if arg == "--force" || arg == "-f" {
+			force = true
+			continue
+		}
+		positional = append(positional, arg)
+	}
+	if len(positional) == 0 || len(positional) > 2 {
+		return commands.UsageResult(t, startTime, "new needs a template name and optionally a file"), nil
+	}
+
+	template, ok := FindTemplate(positional[0])
+	if !ok {
+		return commands.UsageResult(t, startTime, "unknown template '%s'; run template list", positional[0]), nil
+	}
+	file := template.File
+	if len(positional) == 2 {
+		file = positional[1]
+	}
+
+	// "-" prints the template so it can be read or piped first
+	if file == "-" {
+		return &commands.Result{
+			Output:   template.Content,
+			ExitCode: 0,
+			Duration: time.Since(startTime),
+		}, nil
+	}
+	return t.write(template, file, force, startTime), nil
+}
+
+// CompleteArguments suggests subcommands, then template names for new
+func (t *TemplateCommand) CompleteArguments(args []string) []string {
+	switch {
+	case len(args) == 1:
+		return []string{"list", "new"}
+	case len(args) == 2 && args[0] == "new":
+		var names []string
+		for _, template := range BuiltinTemplates() {
+			names = append(names, template.Name)
+		}
+		return names
+	}
+	return nil
+}
+
+// list shows each template with the file it writes by default
+func (t *TemplateCommand) list(startTime time.Time) *commands.Result {
+	var output strings.Builder
+	output.WriteString(color.New(color.FgCyan, color.Bold).Sprint("📝 TEMPLATES\n"))
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+	for _, template := range BuiltinTemplates() {
+		output.WriteString(fmt.Sprintf("  %s %s\n", color.New(color.FgGreen, color.Bold).Sprintf("%-8s", template.Name),
+			template.Description))
+		output.WriteString(color.New(color.FgHiBlack).Sprintf("           writes %s\n", template.File))
+	}
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+	output.WriteString(color.New(color.FgHiBlack).Sprint("💡 Create one with: template new <name> [file]\n"))
+
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}
+}
+
+// write saves a template to file, refusing to replace an existing file unless forced
+func (t *TemplateCommand) write(template FileTemplate, file string, force bool, startTime time.Time) *commands.Result {
+	if _, err := os.Stat(file); err == nil && !force {
+		err := fmt.Errorf("%s already exists; use --force to overwrite it", file)
+		return commands.ErrorResult(color.New(color.FgRed).Sprintf("❌ %v\n", err), err, startTime)
+	}
+	if dir := filepath.Dir(file); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return commands.ErrorResult(color.New(color.FgRed).Sprintf("❌ %v\n", err), err, startTime)
+		}
+	}
+	if err := ioutil.WriteFile(file, []byte(template.Content), 0644); err != nil {
+		err = fmt.Errorf("failed to write %s: %w", file, err)
+		return commands.ErrorResult(color.New(color.FgRed).Sprintf("❌ %v\n", err), err, startTime)
+	}
+
+	var output strings.Builder
+	output.WriteString(color.New(color.FgGreen).Sprintf("✅ Wrote %s template to %s\n", template.Name, file))
+	if placeholders := TemplatePlaceholders(template.Content); len(placeholders) > 0 {
+		output.WriteString(color.New(color.FgYellow).Sprintf("✏️  Replace before use: %s\n", strings.Join(placeholders, ", ")))
+	}
+
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}
+}
@@ -0,0 +1,232 @@
+package system
+
+import (
+	"regexp"
+	"sort"
+)
+
+// FileTemplate is a starter file the template command can write
+type FileTemplate struct {
+	Name        string
+	File        string
+	Description string
+	Content     string
+}
+
+// The templates live in Go source rather than go:embed because the module still
+// targets Go 1.14; they are versioned and shipped with the binary all the same.
+var builtinTemplates = []FileTemplate{
+	{
+		Name:        "script",
+		File:        "automation.ss",
+		Description: "Automation script: one SuperShell command per line",
+		Content:     scriptTemplate,
+	},
+	{
+		Name:        "config",
+		File:        "supershell.yaml",
+		Description: "Settings file with every option and its default documented",
+		Content:     configTemplate,
+	},
+	{
+		Name:        "backup",
+		File:        "backup.ss",
+		Description: "fastcp backup and verification script with placeholders",
+		Content:     backupTemplate,
+	},
+}
+
+// BuiltinTemplates returns the available templates sorted by name
+func BuiltinTemplates() []FileTemplate {
+	templates := make([]FileTemplate, len(builtinTemplates))
+	copy(templates, builtinTemplates)
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+	return templates
+}
+
+// FindTemplate returns the named template
+func FindTemplate(name string) (FileTemplate, bool) {
+	for _, template := range builtinTemplates {
+		if template.Name == name {
+			return template, true
+		}
+	}
+	return FileTemplate{}, false
+}
+
+// templatePlaceholderPattern matches the values a user must fill in, like <BUCKET>
+var templatePlaceholderPattern = regexp.MustCompile(`<[A-Z][A-Z0-9_]*>`)
+
+// TemplatePlaceholders returns the distinct placeholders in content, in order of appearance
+func TemplatePlaceholders(content string) []string {
+	var placeholders []string
+	seen := make(map[string]bool)
+	for _, placeholder := range templatePlaceholderPattern.FindAllString(content, -1) {
+		if !seen[placeholder] {
+			seen[placeholder] = true
+			placeholders = append(placeholders, placeholder)
+		}
+	}
+	return placeholders
+}
+
+const scriptTemplate = `# SuperShell automation script
+#
+# Each line is run as a SuperShell command, exactly as if it had been typed at
+# the prompt. Blank lines and lines starting with # are ignored. Quote arguments
+# that contain spaces: echo "hello world".
+
+# Record where and what this script runs on
+echo "Running checks on this machine"
+hostname
+sysinfo
+
+# Service and disk health
+server health
+server services list
+
+# Recent problems in the system log
+logtail --level error --since 1h -n 20
+
+# Connectivity to the outside world
+ping -c 3 8.8.8.8
+nslookup example.com
+`
+
+const configTemplate = `# SuperShell settings
+#
+# SuperShell reads its settings from ~/.supershell/config.yaml; run
+# "config path" to print the location on this machine. Every option below is
+# shown with its default value, so delete whatever you don't need to change.
+# Durations use Go syntax, such as 500ms, 30s, 5m or 24h.
+
+shell:
+  # Text shown before each command
+  prompt: "supershell> "
+  # Number of commands kept in the history
+  history_size: 1000
+  # Longest a single command may run
+  timeout: 30s
+  history_file: ~/.supershell_history
+  save_history: true
+  auto_complete: true
+  case_sensitive: false
+  # What happens when a wildcard matches no files: "passthrough" keeps it as
+  # typed, "null" drops it and "error" fails the command
+  glob_no_match: passthrough
+  # How box drawing, emoji and progress bars are shown: "auto" uses plain
+  # ASCII when the terminal isn't UTF-8, "unicode" or "ascii" always one of them
+  decorations: auto
+  # Script run with source at startup when it exists; "none" never runs one
+  rc_file: ~/.supershellrc.ss
+  # Directory whose .ss scripts also run at startup, in name order; nothing
+  # runs from a directory unless this is set
+  startup_dir: ""
+  colors:
+    enabled: true
+    # default, dark, light or custom
+    scheme: default
+    # Used with the custom scheme, e.g. error: red
+    custom_colors: {}
+
+intelligence:
+  enabled: true
+  fuzzy_matching_enabled: true
+  smart_suggestions_enabled: true
+  external_tools_enabled: true
+  learning_enabled: true
+  # Most suggestions offered at once (up to 100)
+  max_suggestions: 10
+  # How often a command sequence must repeat before it is suggested
+  min_pattern_occurrences: 3
+  learning_threshold: 5
+  # Give up on a suggestion after this long (up to 10s)
+  response_timeout: 100ms
+  data_directory: ~/.supershell/intelligence
+
+security:
+  validation_enabled: true
+  sanitization_enabled: true
+  # Longest command line accepted, in characters
+  max_input_length: 1024
+  # When set, only these commands may run
+  allowed_commands: []
+  # These commands never run; a command can't be both allowed and blocked
+  blocked_commands: []
+  allow_elevation: true
+  # Ask before running commands that change the system
+  require_confirmation: false
+  log_security_events: true
+  strict_mode: false
+  scripts:
+    # What source does with a guarded command: "run" runs it, "confirm" asks
+    # first and "block" skips it; source --safe always blocks
+    guard: run
+    # A command name, or a name and subcommand such as "firewall disable"
+    guarded_commands: [rm, rmdir, kill, killtask, firewall disable, user disable, user passwd, server session kill, priv elevate]
+
+monitoring:
+  enabled: true
+  # debug, info, warn or error
+  log_level: info
+  log_file: ~/.supershell/logs/supershell.log
+  log_rotation: true
+  # Rotate the log at this size, in bytes (10 MB)
+  max_log_size: 10485760
+  max_log_files: 5
+  metrics_enabled: true
+  metrics_interval: 30s
+  performance_tracking: true
+  memory_tracking: true
+
+commands:
+  timeout: 30s
+  # Commands allowed to run at the same time (up to 1000)
+  max_concurrent: 10
+  # Retries for commands that fail with a temporary error (up to 10)
+  retry_attempts: 3
+  retry_delay: 1s
+  # Commands that can't be run at all
+  disabled_commands: []
+  # Per-command overrides, for example:
+  #   portscan:
+  #     enabled: true
+  #     timeout: 5m
+  #     require_elevation: false
+  #     max_args: 10
+  custom_commands: {}
+
+networking:
+  ipinfo:
+    # {ip} is replaced by the address and {key} by api_key
+    endpoint: https://ipinfo.io/{ip}/json
+    # Prefer storing the key with "cred add" over writing it here
+    api_key: ""
+    # Optional local GeoIP database used instead of the endpoint
+    database: ""
+    cache_file: ~/.supershell/cache/ipinfo.json
+    cache_ttl: 24h
+    timeout: 5s
+`
+
+const backupTemplate = `# fastcp backup script
+#
+# Replace each placeholder in angle brackets before running:
+#   <SOURCE_DIR>  directory to back up, e.g. /srv/data or C:\Data
+#   <BUCKET>      S3-compatible bucket, e.g. s3://backups/web1
+#   <CRED_NAME>   credential holding the bucket keys; create it with
+#                 "cred add <CRED_NAME> --type aws"
+#   <BACKUP_ID>   backup to verify, as printed by fastcp-backup
+#
+# To run the upload every night at 02:00 instead, schedule it:
+#   schedule add "0 2 * * *" "fastcp-backup <SOURCE_DIR> <BUCKET> --cred <CRED_NAME> --incremental --compress --encrypt"
+
+# Preview what would be uploaded without transferring anything
+fastcp-backup <SOURCE_DIR> <BUCKET> --cred <CRED_NAME> --incremental --compress --dry-run
+
+# Upload changed files, compressed and encrypted, with statistics for reporting
+fastcp-backup <SOURCE_DIR> <BUCKET> --cred <CRED_NAME> --incremental --compress --encrypt --retries 5 --stats backup-stats.json
+
+# Check the uploaded objects against the backup manifest
+fastcp-verify <BUCKET> <BACKUP_ID>
+`
@@ -0,0 +1,249 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/user"
+	"runtime"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/security"
+
+	"github.com/fatih/color"
+)
+
+// UserCommand lists and manages local accounts
+type UserCommand struct {
+	*commands.BaseCommand
+}
+
+// NewUserCommand creates a new user command
+func NewUserCommand() *UserCommand {
+	return &UserCommand{
+		BaseCommand: commands.NewBaseCommand(
+			"user",
+			"List and manage local user accounts",
+			"user [list [--all] [--json] | disable <name> | enable <name> | passwd <name>]",
+			[]string{"windows", "linux"},
+			true, // disable, enable and passwd require elevation
+		),
+	}
+}
+
+// NeedsElevation reports whether args change an account; listing them, or a
+// malformed change that only gets the usage, doesn't
+func (u *UserCommand) NeedsElevation(args *commands.Arguments) bool {
+	if len(args.Raw) != 2 {
+		return false
+	}
+	switch args.Raw[0] {
+	case "disable", "enable", "passwd":
+		return true
+	}
+	return false
+}
+
+// Execute runs a user subcommand, listing accounts by default
+func (u *UserCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	startTime := time.Now()
+
+	action := "list"
+	rest := args.Raw
+	if len(rest) > 0 && !strings.HasPrefix(rest[0], "-") {
+		action, rest = rest[0], rest[1:]
+	}
+
+	switch action {
+	case "list":
+		return u.list(ctx, rest, startTime)
+	case "disable", "enable", "passwd":
+		if len(rest) != 1 {
+			return commands.UsageResult(u, startTime, "%s needs exactly one account name", action), nil
+		}
+		return u.change(ctx, action, rest[0], startTime)
+	default:
+		return commands.UsageResult(u, startTime, "unknown subcommand '%s'", action), nil
+	}
+}
+
+// list shows the local accounts. System accounts are hidden unless --all is given.
+func (u *UserCommand) list(ctx context.Context, args []string, startTime time.Time) (*commands.Result, error) {
+	showAll := false
+	jsonOutput := false
+	for _, arg := range args {
+		switch arg {
+		case "-a", "--all":
+			showAll = true
+		case "--json":
+			jsonOutput = true
+		default:
+			return commands.UsageResult(u, startTime, "unknown option '%s'", arg), nil
+		}
+	}
+
+	users, err := listLocalUsers(ctx)
+	if err != nil {
+		output := color.New(color.FgRed).Sprintf("❌ %v\n", err)
+		return commands.ErrorResult(output, err, startTime), nil
+	}
+
+	shown := make([]LocalUser, 0, len(users))
+	hidden := 0
+	for _, account := range users {
+		if account.System && !showAll {
+			hidden++
+			continue
+		}
+		shown = append(shown, account)
+	}
+
+	if jsonOutput {
+		data, err := json.MarshalIndent(shown, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return &commands.Result{
+			Output:   string(data) + "\n",
+			ExitCode: 0,
+			Duration: time.Since(startTime),
+		}, nil
+	}
+
+	var output strings.Builder
+	output.WriteString(color.New(color.FgCyan, color.Bold).Sprint("👥 LOCAL USER ACCOUNTS\n"))
+	output.WriteString("═══════════════════════════════════════════════════════════════\n")
+	output.WriteString(color.New(color.FgWhite, color.Bold).Sprintf("%-20s %-9s %-17s %s\n", "ACCOUNT", "STATUS", "LAST LOGON", "NAME"))
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+
+	enabled := 0
+	for _, account := range shown {
+		status := color.New(color.FgGreen).Sprintf("%-9s", "enabled")
+		if account.Enabled {
+			enabled++
+		} else {
+			status = color.New(color.FgRed).Sprintf("%-9s", "disabled")
+		}
+
+		lastLogon := color.New(color.FgHiBlack).Sprintf("%-17s", "never")
+		if !account.LastLogon.IsZero() {
+			lastLogon = fmt.Sprintf("%-17s", account.LastLogon.Local().Format("2006-01-02 15:04"))
+		}
+
+		description := account.FullName
+		if description == "" {
+			description = account.Home
+		}
+
+		name := fmt.Sprintf("%-20s", account.Name)
+		if account.System {
+			name = color.New(color.FgHiBlack).Sprint(name)
+		}
+		output.WriteString(fmt.Sprintf("%s %s %s %s\n", name, status, lastLogon, description))
+	}
+
+	output.WriteString("───────────────────────────────────────────────────────────────\n")
+	output.WriteString(fmt.Sprintf("📊 %d accounts, %d enabled", len(shown), enabled))
+	if hidden > 0 {
+		output.WriteString(fmt.Sprintf(" (%d system accounts hidden, use --all)", hidden))
+	}
+	output.WriteString("\n")
+	if runtime.GOOS != "windows" && !security.IsElevated() {
+		output.WriteString(color.New(color.FgHiBlack).Sprint("💡 Run as root to see locked passwords; only nologin shells show as disabled\n"))
+	}
+
+	return &commands.Result{
+		Output:   output.String(),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}, nil
+}
+
+// change disables, enables or sets the password of an existing account
+func (u *UserCommand) change(ctx context.Context, action, name string, startTime time.Time) (*commands.Result, error) {
+	if !ValidUserName(name) {
+		return commands.UsageResult(u, startTime, "invalid account name '%s'", name), nil
+	}
+
+	users, err := listLocalUsers(ctx)
+	if err != nil {
+		output := color.New(color.FgRed).Sprintf("❌ %v\n", err)
+		return commands.ErrorResult(output, err, startTime), nil
+	}
+	found := false
+	for _, account := range users {
+		if strings.EqualFold(account.Name, name) {
+			found, name = true, account.Name
+			break
+		}
+	}
+	if !found {
+		err := fmt.Errorf("no local account named '%s'", name)
+		output := color.New(color.FgRed).Sprintf("❌ %v\n", err)
+		return commands.ErrorResult(output, err, startTime), nil
+	}
+
+	var message string
+	switch action {
+	case "disable":
+		if isCurrentUser(name) {
+			err := fmt.Errorf("refusing to disable '%s', the account running this shell", name)
+			output := color.New(color.FgRed).Sprintf("❌ %v\n", err)
+			return commands.ErrorResult(output, err, startTime), nil
+		}
+		err = setUserEnabled(ctx, name, false)
+		message = fmt.Sprintf("🔒 Disabled account %s\n", name)
+	case "enable":
+		err = setUserEnabled(ctx, name, true)
+		message = fmt.Sprintf("🔓 Enabled account %s\n", name)
+	case "passwd":
+		var password string
+		password, err = readNewPassword(name)
+		if err == nil {
+			err = setUserPassword(ctx, name, password)
+		}
+		message = fmt.Sprintf("🔑 Changed the password of %s\n", name)
+	}
+	if err != nil {
+		output := color.New(color.FgRed).Sprintf("❌ Failed to %s %s: %v\n", action, name, err)
+		return commands.ErrorResult(output, err, startTime), nil
+	}
+
+	return &commands.Result{
+		Output:   color.New(color.FgGreen).Sprint(message),
+		ExitCode: 0,
+		Duration: time.Since(startTime),
+	}, nil
+}
+
+// readNewPassword prompts twice for the new password of an account
+func readNewPassword(name string) (string, error) {
+	password, err := security.ReadSecret(fmt.Sprintf("🔑 New password for %s: ", name))
+	if err != nil {
+		return "", err
+	}
+	if password == "" {
+		return "", fmt.Errorf("password cannot be empty")
+	}
+	confirm, err := security.ReadSecret("🔑 Repeat the password: ")
+	if err != nil {
+		return "", err
+	}
+	if confirm != password {
+		return "", fmt.Errorf("passwords do not match")
+	}
+	return password, nil
+}
+
+// isCurrentUser reports whether name is the account running the shell. Windows
+// reports it as DOMAIN\name.
+func isCurrentUser(name string) bool {
+	current, err := user.Current()
+	if err != nil {
+		return false
+	}
+	username := current.Username[strings.LastIndex(current.Username, `\`)+1:]
+	return strings.EqualFold(username, name)
+}
@@ -0,0 +1,229 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// firstRegularUID is where login.defs starts numbering regular accounts on most
+// distributions; Windows uses the same boundary for relative IDs
+const firstRegularUID = 1000
+
+// nobodyUID is the overflow account, which is a system account despite its number
+const nobodyUID = 65534
+
+// LocalUser is a local account on this machine
+type LocalUser struct {
+	Name      string    `json:"name"`
+	FullName  string    `json:"full_name,omitempty"`
+	UID       int       `json:"uid"`
+	Home      string    `json:"home,omitempty"`
+	Shell     string    `json:"shell,omitempty"`
+	Enabled   bool      `json:"enabled"`
+	System    bool      `json:"system"`
+	LastLogon time.Time `json:"last_logon,omitempty"`
+}
+
+// userNamePattern accepts Windows and POSIX account names while keeping out the
+// characters used to quote them for net user, PowerShell and chpasswd
+var userNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.][A-Za-z0-9_. $-]{0,63}$`)
+
+// ValidUserName reports whether name can safely be passed to the account tools
+func ValidUserName(name string) bool {
+	return userNamePattern.MatchString(name) && !strings.HasSuffix(name, " ")
+}
+
+// ParsePasswd reads /etc/passwd. Accounts start out enabled unless their shell
+// refuses logins; the shadow file refines that when it can be read.
+func ParsePasswd(data string) []LocalUser {
+	var users []LocalUser
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.Split(strings.TrimSpace(line), ":")
+		if len(fields) < 7 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		uid, err := strconv.Atoi(fields[2])
+		if err != nil {
+			continue
+		}
+
+		shell := fields[6]
+		users = append(users, LocalUser{
+			Name:     fields[0],
+			FullName: strings.Split(fields[4], ",")[0],
+			UID:      uid,
+			Home:     fields[5],
+			Shell:    shell,
+			Enabled:  !strings.HasSuffix(shell, "/nologin") && !strings.HasSuffix(shell, "/false"),
+			System:   uid != 0 && (uid < firstRegularUID || uid == nobodyUID),
+		})
+	}
+	return users
+}
+
+// ShadowDisabled returns the accounts in /etc/shadow that are locked (a password
+// starting with '!') or whose expiry date has passed
+func ShadowDisabled(data string, now time.Time) map[string]bool {
+	disabled := make(map[string]bool)
+	today := int(now.Unix() / 86400)
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.Split(strings.TrimSpace(line), ":")
+		if len(fields) < 2 || fields[0] == "" {
+			continue
+		}
+		if strings.HasPrefix(fields[1], "!") {
+			disabled[fields[0]] = true
+			continue
+		}
+		if len(fields) >= 8 {
+			if expires, err := strconv.Atoi(fields[7]); err == nil && expires <= today {
+				disabled[fields[0]] = true
+			}
+		}
+	}
+	return disabled
+}
+
+// ParseLastlog reads the output of lastlog, skipping accounts that never logged in
+func ParseLastlog(output string) map[string]time.Time {
+	logons := make(map[string]time.Time)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 7 || fields[0] == "Username" || strings.Contains(line, "**Never") {
+			continue
+		}
+		stamp := strings.Join(fields[len(fields)-6:], " ")
+		if logon, err := time.Parse("Mon Jan 2 15:04:05 -0700 2006", stamp); err == nil {
+			logons[fields[0]] = logon
+		}
+	}
+	return logons
+}
+
+// listLocalUsers returns the local accounts, sorted the way the platform lists them
+func listLocalUsers(ctx context.Context) ([]LocalUser, error) {
+	if runtime.GOOS == "windows" {
+		return listWindowsUsers(ctx)
+	}
+
+	data, err := ioutil.ReadFile("/etc/passwd")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read accounts: %w", err)
+	}
+	users := ParsePasswd(string(data))
+
+	// The shadow file is only readable by root, so without it lock state comes from the shell alone
+	if shadow, err := ioutil.ReadFile("/etc/shadow"); err == nil {
+		disabled := ShadowDisabled(string(shadow), time.Now())
+		for i := range users {
+			if disabled[users[i].Name] {
+				users[i].Enabled = false
+			}
+		}
+	}
+
+	if output, err := exec.CommandContext(ctx, "lastlog").Output(); err == nil {
+		logons := ParseLastlog(string(output))
+		for i := range users {
+			users[i].LastLogon = logons[users[i].Name]
+		}
+	}
+	return users, nil
+}
+
+// listWindowsUsers reads local accounts through Get-LocalUser
+func listWindowsUsers(ctx context.Context) ([]LocalUser, error) {
+	script := `ConvertTo-Json -InputObject @(Get-LocalUser | ForEach-Object {
+		[PSCustomObject]@{
+			Name = $_.Name
+			FullName = $_.FullName
+			Enabled = $_.Enabled
+			SID = $_.SID.Value
+			LastLogon = if ($_.LastLogon) { $_.LastLogon.ToUniversalTime().ToString('o') } else { '' }
+		}
+	})`
+	output, err := exec.CommandContext(ctx, "powershell", "-NoProfile", "-Command", script).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list accounts: %w", err)
+	}
+
+	var accounts []struct {
+		Name      string `json:"Name"`
+		FullName  string `json:"FullName"`
+		Enabled   bool   `json:"Enabled"`
+		SID       string `json:"SID"`
+		LastLogon string `json:"LastLogon"`
+	}
+	if err := json.Unmarshal(output, &accounts); err != nil {
+		return nil, fmt.Errorf("failed to parse accounts: %w", err)
+	}
+
+	users := make([]LocalUser, 0, len(accounts))
+	for _, account := range accounts {
+		// The relative ID ends the SID; built-in accounts such as Guest are below 1000
+		rid, _ := strconv.Atoi(account.SID[strings.LastIndex(account.SID, "-")+1:])
+		logon, _ := time.Parse(time.RFC3339Nano, account.LastLogon)
+		users = append(users, LocalUser{
+			Name:      account.Name,
+			FullName:  account.FullName,
+			UID:       rid,
+			Enabled:   account.Enabled,
+			System:    rid < firstRegularUID,
+			LastLogon: logon,
+		})
+	}
+	return users, nil
+}
+
+// setUserEnabled enables or disables an account. On Linux disabling both locks
+// the password and expires the account, so key-based logins stop too.
+func setUserEnabled(ctx context.Context, name string, enabled bool) error {
+	var cmd *exec.Cmd
+	switch {
+	case runtime.GOOS == "windows" && enabled:
+		cmd = exec.CommandContext(ctx, "net", "user", name, "/active:yes")
+	case runtime.GOOS == "windows":
+		cmd = exec.CommandContext(ctx, "net", "user", name, "/active:no")
+	case enabled:
+		cmd = exec.CommandContext(ctx, "usermod", "-U", "-e", "", name)
+	default:
+		cmd = exec.CommandContext(ctx, "usermod", "-L", "-e", "1", name)
+	}
+	return runAccountTool(cmd)
+}
+
+// setUserPassword changes an account's password. The password goes to the tool
+// on stdin so it never shows up in the process list.
+func setUserPassword(ctx context.Context, name, password string) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		script := fmt.Sprintf(`$password = [Console]::In.ReadLine() | ConvertTo-SecureString -AsPlainText -Force
+			Set-LocalUser -Name '%s' -Password $password -ErrorAction Stop`, strings.Replace(name, "'", "''", -1))
+		cmd = exec.CommandContext(ctx, "powershell", "-NoProfile", "-Command", script)
+	} else {
+		cmd = exec.CommandContext(ctx, "chpasswd")
+		password = name + ":" + password
+	}
+	cmd.Stdin = strings.NewReader(password + "\n")
+	return runAccountTool(cmd)
+}
+
+// runAccountTool runs an account tool, turning its output into the error message
+func runAccountTool(cmd *exec.Cmd) error {
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if message := strings.TrimSpace(string(output)); message != "" {
+			return fmt.Errorf("%s", message)
+		}
+		return err
+	}
+	return nil
+}
@@ -26,11 +26,23 @@ func NewWinUpdateCommand() *WinUpdateCommand {
 			"Windows Update management and information",
 			"winupdate [list|check|install|history] [--auto] [--reboot]",
 			[]string{"windows"}, // Windows only
-			true,                // Requires elevation
+			true,                // Installing requires elevation
 		),
 	}
 }
 
+// NeedsElevation reports whether args install updates; the other actions only read
+func (w *WinUpdateCommand) NeedsElevation(args *commands.Arguments) bool {
+	action := "list"
+	for _, arg := range args.Raw {
+		switch arg {
+		case "list", "check", "install", "history":
+			action = arg
+		}
+	}
+	return action == "install"
+}
+
 // Execute manages Windows updates
 func (w *WinUpdateCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
 	startTime := time.Now()
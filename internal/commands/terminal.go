@@ -0,0 +1,141 @@
+package commands
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// ColorSupport is how many colors a terminal can show
+type ColorSupport int
+
+const (
+	// ColorNone means escape codes would show up as text, or colors were turned off
+	ColorNone ColorSupport = iota
+	// Color16 is the basic ANSI palette fatih/color uses
+	Color16
+	// Color256 is the xterm 256-color palette
+	Color256
+	// ColorTrue is 24-bit color
+	ColorTrue
+)
+
+var colorSupportNames = map[ColorSupport]string{
+	ColorNone: "none",
+	Color16:   "16 colors",
+	Color256:  "256 colors",
+	ColorTrue: "truecolor",
+}
+
+// String names the color support as diag terminal shows it
+func (c ColorSupport) String() string {
+	return colorSupportNames[c]
+}
+
+// TerminalInfo is what SuperShell could find out about the terminal it writes to
+type TerminalInfo struct {
+	// Term is $TERM, or the console on Windows
+	Term string `json:"term"`
+	// Program is the terminal emulator when it identifies itself, such as iTerm.app
+	Program string `json:"program,omitempty"`
+	// Interactive is set when stdout is a terminal rather than a pipe or file
+	Interactive bool         `json:"interactive"`
+	Colors      ColorSupport `json:"-"`
+	// ColorReason says what the color support was decided from
+	ColorReason string `json:"color_reason"`
+	UTF8        bool   `json:"utf8"`
+	// Encoding is the character set of the locale or console code page
+	Encoding string `json:"encoding"`
+	// Width and Height are the window size in characters, 0 when unknown
+	Width  int `json:"width"`
+	Height int `json:"height"`
+	// ASCII is set when box drawing and emoji should fall back to plain ASCII
+	ASCII bool `json:"ascii"`
+}
+
+// DetectTerminal inspects stdout and the environment. It only reads state, so
+// it is cheap enough to call again after the environment changes.
+func DetectTerminal() TerminalInfo {
+	info := TerminalInfo{Term: os.Getenv("TERM"), Program: os.Getenv("TERM_PROGRAM")}
+	if stat, err := os.Stdout.Stat(); err == nil {
+		info.Interactive = stat.Mode()&os.ModeCharDevice != 0
+	}
+	if version := os.Getenv("TERM_PROGRAM_VERSION"); info.Program != "" && version != "" {
+		info.Program += " " + version
+	}
+
+	info.Encoding, info.UTF8 = localeEncoding()
+	info.Colors, info.ColorReason = envColorSupport(info.Term)
+	detectConsole(&info)
+
+	if info.Width == 0 {
+		info.Width, _ = strconv.Atoi(os.Getenv("COLUMNS"))
+	}
+	if info.Height == 0 {
+		info.Height, _ = strconv.Atoi(os.Getenv("LINES"))
+	}
+	info.ASCII = !info.UTF8
+	return info
+}
+
+// ApplyTerminalDefaults turns colors off for a terminal that can't show them,
+// as fatih/color already does when stdout isn't a terminal, and switches to
+// ASCII decorations when it isn't UTF-8
+func ApplyTerminalDefaults(info TerminalInfo) {
+	if info.Colors == ColorNone {
+		color.NoColor = true
+	}
+	SetASCIIDecorations(info.ASCII)
+}
+
+// localeEncoding reads the character set from the first locale variable set,
+// in the order the C library uses them
+func localeEncoding() (string, bool) {
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		locale := os.Getenv(name)
+		if locale == "" {
+			continue
+		}
+		if locale == "C" || locale == "POSIX" {
+			return "ASCII (" + name + "=" + locale + ")", false
+		}
+		encoding := locale
+		if dot := strings.Index(locale, "."); dot >= 0 {
+			encoding = strings.SplitN(locale[dot+1:], "@", 2)[0]
+		}
+		normalized := strings.ToLower(strings.Replace(encoding, "-", "", -1))
+		return encoding + " (" + name + "=" + locale + ")", normalized == "utf8"
+	}
+	return "unknown (no locale set)", false
+}
+
+// envColorSupport decides color support from NO_COLOR, COLORTERM, the
+// terminal program and $TERM, in that order
+func envColorSupport(term string) (ColorSupport, string) {
+	if os.Getenv("NO_COLOR") != "" {
+		return ColorNone, "NO_COLOR is set"
+	}
+	switch strings.ToLower(os.Getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return ColorTrue, "COLORTERM=" + os.Getenv("COLORTERM")
+	}
+	switch os.Getenv("TERM_PROGRAM") {
+	case "iTerm.app", "WezTerm", "vscode", "Hyper":
+		return ColorTrue, "TERM_PROGRAM=" + os.Getenv("TERM_PROGRAM")
+	case "Apple_Terminal":
+		return Color256, "TERM_PROGRAM=Apple_Terminal"
+	}
+	switch {
+	case term == "":
+		return ColorNone, "TERM is not set"
+	case term == "dumb":
+		return ColorNone, "TERM=dumb"
+	case strings.Contains(term, "truecolor") || strings.Contains(term, "24bit") || strings.HasSuffix(term, "-direct"):
+		return ColorTrue, "TERM=" + term
+	case strings.Contains(term, "256color"):
+		return Color256, "TERM=" + term
+	}
+	return Color16, "TERM=" + term
+}
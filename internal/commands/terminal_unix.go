@@ -0,0 +1,27 @@
+//go:build !windows
+// +build !windows
+
+package commands
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// detectConsole reads the window size from the terminal
+func detectConsole(info *TerminalInfo) {
+	if !info.Interactive {
+		return
+	}
+	if size, err := unix.IoctlGetWinsize(int(os.Stdout.Fd()), unix.TIOCGWINSZ); err == nil {
+		info.Width, info.Height = int(size.Col), int(size.Row)
+	}
+}
+
+// isTerminal reports whether file is a terminal, which unlike /dev/null has a
+// window size
+func isTerminal(file *os.File) bool {
+	_, err := unix.IoctlGetWinsize(int(file.Fd()), unix.TIOCGWINSZ)
+	return err == nil
+}
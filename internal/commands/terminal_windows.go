@@ -0,0 +1,66 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// getConsoleOutputCP isn't wrapped by x/sys/windows
+var getConsoleOutputCP = windows.NewLazySystemDLL("kernel32.dll").NewProc("GetConsoleOutputCP")
+
+// detectConsole fills in what Windows tells about the console, which has no
+// $TERM or locale variables to go by. Windows Terminal identifies itself with
+// WT_SESSION; anything else is the classic console host.
+func detectConsole(info *TerminalInfo) {
+	handle := windows.Handle(os.Stdout.Fd())
+	var mode uint32
+	if windows.GetConsoleMode(handle, &mode) != nil {
+		// Redirected, so there is no console to ask
+		return
+	}
+	info.Interactive = true
+
+	var buffer windows.ConsoleScreenBufferInfo
+	if windows.GetConsoleScreenBufferInfo(handle, &buffer) == nil {
+		info.Width = int(buffer.Window.Right-buffer.Window.Left) + 1
+		info.Height = int(buffer.Window.Bottom-buffer.Window.Top) + 1
+	}
+
+	noColor := os.Getenv("NO_COLOR") != ""
+	if os.Getenv("WT_SESSION") != "" {
+		info.Program = "Windows Terminal"
+		if info.Term == "" {
+			info.Term = "windows-terminal"
+		}
+		if !noColor {
+			info.Colors, info.ColorReason = ColorTrue, "WT_SESSION is set"
+		}
+		info.Encoding, info.UTF8 = "UTF-8 (Windows Terminal)", true
+		return
+	}
+
+	if info.Term == "" {
+		info.Term = "conhost"
+	}
+	if !noColor && info.Colors < Color16 {
+		if mode&windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING != 0 {
+			info.Colors, info.ColorReason = ColorTrue, "virtual terminal processing is on"
+		} else {
+			info.Colors, info.ColorReason = Color16, "console colors"
+		}
+	}
+	if codePage, _, _ := getConsoleOutputCP.Call(); codePage != 0 {
+		info.Encoding, info.UTF8 = fmt.Sprintf("CP%d (console code page)", codePage), codePage == 65001
+		if info.UTF8 {
+			info.Encoding = "UTF-8 (console code page 65001)"
+		}
+	}
+}
+
+// isTerminal reports whether file is a console, which unlike NUL has a mode
+func isTerminal(file *os.File) bool {
+	var mode uint32
+	return windows.GetConsoleMode(windows.Handle(file.Fd()), &mode) == nil
+}
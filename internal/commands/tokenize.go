@@ -0,0 +1,80 @@
+package commands
+
+import "strings"
+
+// Word is one word of a command line, remembering whether any part of it was quoted
+// so that expansion can leave quoted wildcards alone
+type Word struct {
+	Text   string
+	Quoted bool
+}
+
+// SplitCommandLine splits a command line into words, honoring single and double quotes.
+// Backslashes only escape quotes and backslashes inside double quotes so Windows paths
+// like C:\Users\me keep working unquoted.
+func SplitCommandLine(input string) []string {
+	var words []string
+	for _, word := range SplitCommandWords(input) {
+		words = append(words, word.Text)
+	}
+	return words
+}
+
+// SplitCommandWords splits a command line like SplitCommandLine, keeping quoting information
+func SplitCommandWords(input string) []Word {
+	var words []Word
+	var current strings.Builder
+	inWord := false
+	quoted := false
+	var quote rune
+
+	runes := []rune(input)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+				continue
+			}
+			if quote == '"' && r == '\\' && i+1 < len(runes) && (runes[i+1] == '"' || runes[i+1] == '\\') {
+				i++
+				r = runes[i]
+			}
+			current.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote = r
+			inWord = true
+			quoted = true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inWord {
+				words = append(words, Word{Text: current.String(), Quoted: quoted})
+				current.Reset()
+				inWord = false
+				quoted = false
+			}
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if inWord {
+		words = append(words, Word{Text: current.String(), Quoted: quoted})
+	}
+
+	return words
+}
+
+// QuoteArgument quotes a word so SplitCommandLine returns it unchanged and
+// ExpandArguments leaves any wildcards or braces in it alone
+func QuoteArgument(word string) string {
+	if word == "" {
+		return `""`
+	}
+	if !strings.ContainsAny(word, " \t\n\r\"'*?[{") {
+		return word
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(word) + `"`
+}
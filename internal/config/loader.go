@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"os"
 	"path/filepath"
 	"strings"
 	"time"
@@ -69,6 +70,24 @@ func (l *BasicLoader) Load(path string) (*Config, error) {
 	return config, nil
 }
 
+// DefaultPath returns the user configuration file, ~/.supershell/config.yaml
+func DefaultPath() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".supershell", "config.yaml")
+}
+
+// ExpandHome replaces a leading ~ with the user's home directory
+func ExpandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, `~\`) {
+		return path
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(homeDir, path[1:])
+}
+
 // LoadWithDefaults returns a configuration with default values
 func (l *BasicLoader) LoadWithDefaults() *Config {
 	config := &Config{}
@@ -103,6 +122,15 @@ func (l *BasicLoader) applyDefaults(config *Config) {
 	if config.Shell.HistoryFile == "" {
 		config.Shell.HistoryFile = "~/.supershell_history"
 	}
+	if config.Shell.GlobNoMatch == "" {
+		config.Shell.GlobNoMatch = "passthrough"
+	}
+	if config.Shell.Decorations == "" {
+		config.Shell.Decorations = "auto"
+	}
+	if config.Shell.RCFile == "" {
+		config.Shell.RCFile = "~/.supershellrc.ss"
+	}
 
 	// Color defaults
 	if config.Shell.Colors.CustomColors == nil {
@@ -145,6 +173,16 @@ func (l *BasicLoader) applyDefaults(config *Config) {
 	}
 	config.Security.AllowElevation = true
 	config.Security.LogSecurityEvents = true
+	if config.Security.Audit.File == "" {
+		config.Security.Audit.File = "~/.supershell/audit.log"
+	}
+	if config.Security.Scripts.Guard == "" {
+		config.Security.Scripts.Guard = "run"
+	}
+	if config.Security.Scripts.GuardedCommands == nil {
+		config.Security.Scripts.GuardedCommands = []string{"rm", "rmdir", "kill", "killtask", "firewall disable",
+			"user disable", "user passwd", "server session kill", "priv elevate"}
+	}
 
 	// Monitoring defaults
 	config.Monitoring.Enabled = true
@@ -184,4 +222,18 @@ func (l *BasicLoader) applyDefaults(config *Config) {
 	if config.Commands.CustomCommands == nil {
 		config.Commands.CustomCommands = make(map[string]CommandConfig)
 	}
+
+	// Networking defaults
+	if config.Networking.IPInfo.Endpoint == "" {
+		config.Networking.IPInfo.Endpoint = "https://ipinfo.io/{ip}/json"
+	}
+	if config.Networking.IPInfo.CacheFile == "" {
+		config.Networking.IPInfo.CacheFile = "~/.supershell/cache/ipinfo.json"
+	}
+	if config.Networking.IPInfo.CacheTTL == 0 {
+		config.Networking.IPInfo.CacheTTL = 24 * time.Hour
+	}
+	if config.Networking.IPInfo.Timeout == 0 {
+		config.Networking.IPInfo.Timeout = 5 * time.Second
+	}
 }
@@ -9,6 +9,7 @@ type Config struct {
 	Security     SecurityConfig     `yaml:"security" json:"security"`
 	Monitoring   MonitoringConfig   `yaml:"monitoring" json:"monitoring"`
 	Commands     CommandsConfig     `yaml:"commands" json:"commands"`
+	Networking   NetworkingConfig   `yaml:"networking" json:"networking"`
 }
 
 // ShellConfig contains shell-specific configuration
@@ -21,6 +22,19 @@ type ShellConfig struct {
 	CaseSensitive bool          `yaml:"case_sensitive" json:"case_sensitive"`
 	SaveHistory   bool          `yaml:"save_history" json:"save_history"`
 	HistoryFile   string        `yaml:"history_file" json:"history_file"`
+	// GlobNoMatch is what happens to a wildcard matching no files:
+	// "passthrough" keeps it as typed, "null" drops it and "error" fails the command
+	GlobNoMatch string `yaml:"glob_no_match" json:"glob_no_match"`
+	// Decorations is how box drawing, emoji and progress bars are shown:
+	// "auto" uses ASCII when the terminal isn't UTF-8, "unicode" and "ascii"
+	// always use one or the other
+	Decorations string `yaml:"decorations" json:"decorations"`
+	// RCFile is a script run by source at startup when it exists; "none"
+	// never runs one
+	RCFile string `yaml:"rc_file" json:"rc_file"`
+	// StartupDir is a directory whose .ss scripts run at startup after the rc
+	// file, in name order; none run unless it is set
+	StartupDir string `yaml:"startup_dir" json:"startup_dir"`
 }
 
 // ColorConfig contains color configuration
@@ -46,15 +60,34 @@ type IntelligenceConfig struct {
 
 // SecurityConfig contains security-related configuration
 type SecurityConfig struct {
-	ValidationEnabled   bool     `yaml:"validation_enabled" json:"validation_enabled"`
-	SanitizationEnabled bool     `yaml:"sanitization_enabled" json:"sanitization_enabled"`
-	MaxInputLength      int      `yaml:"max_input_length" json:"max_input_length"`
-	AllowedCommands     []string `yaml:"allowed_commands" json:"allowed_commands"`
-	BlockedCommands     []string `yaml:"blocked_commands" json:"blocked_commands"`
-	AllowElevation      bool     `yaml:"allow_elevation" json:"allow_elevation"`
-	RequireConfirmation bool     `yaml:"require_confirmation" json:"require_confirmation"`
-	LogSecurityEvents   bool     `yaml:"log_security_events" json:"log_security_events"`
-	StrictMode          bool     `yaml:"strict_mode" json:"strict_mode"`
+	ValidationEnabled   bool          `yaml:"validation_enabled" json:"validation_enabled"`
+	SanitizationEnabled bool          `yaml:"sanitization_enabled" json:"sanitization_enabled"`
+	MaxInputLength      int           `yaml:"max_input_length" json:"max_input_length"`
+	AllowedCommands     []string      `yaml:"allowed_commands" json:"allowed_commands"`
+	BlockedCommands     []string      `yaml:"blocked_commands" json:"blocked_commands"`
+	AllowElevation      bool          `yaml:"allow_elevation" json:"allow_elevation"`
+	RequireConfirmation bool          `yaml:"require_confirmation" json:"require_confirmation"`
+	LogSecurityEvents   bool          `yaml:"log_security_events" json:"log_security_events"`
+	StrictMode          bool          `yaml:"strict_mode" json:"strict_mode"`
+	Audit               AuditConfig   `yaml:"audit" json:"audit"`
+	Scripts             ScriptsConfig `yaml:"scripts" json:"scripts"`
+}
+
+// ScriptsConfig guards the commands run from .ss scripts by source
+type ScriptsConfig struct {
+	// Guard is what happens to a guarded command in a script: "run" runs it,
+	// "confirm" asks first and "block" skips it
+	Guard string `yaml:"guard" json:"guard"`
+	// GuardedCommands are command names, or a name and its subcommand such as
+	// "firewall disable"
+	GuardedCommands []string `yaml:"guarded_commands" json:"guarded_commands"`
+}
+
+// AuditConfig controls the command execution audit log
+type AuditConfig struct {
+	Enabled bool   `yaml:"enabled" json:"enabled"`
+	File    string `yaml:"file" json:"file"`
+	Syslog  bool   `yaml:"syslog" json:"syslog"` // also send entries to syslog, or the Event Log on Windows
 }
 
 // MonitoringConfig contains monitoring and logging configuration
@@ -90,3 +123,19 @@ type CommandConfig struct {
 	BlockedArgs      []string      `yaml:"blocked_args" json:"blocked_args"`
 	MaxArgs          int           `yaml:"max_args" json:"max_args"`
 }
+
+// NetworkingConfig contains networking command configuration
+type NetworkingConfig struct {
+	IPInfo IPInfoConfig `yaml:"ipinfo" json:"ipinfo"`
+}
+
+// IPInfoConfig configures IP geolocation and ASN lookups. The endpoint is a URL
+// template where {ip} is replaced by the address and {key} by the API key.
+type IPInfoConfig struct {
+	Endpoint  string        `yaml:"endpoint" json:"endpoint"`
+	APIKey    string        `yaml:"api_key" json:"api_key"`
+	Database  string        `yaml:"database" json:"database"`
+	CacheFile string        `yaml:"cache_file" json:"cache_file"`
+	CacheTTL  time.Duration `yaml:"cache_ttl" json:"cache_ttl"`
+	Timeout   time.Duration `yaml:"timeout" json:"timeout"`
+}
@@ -45,6 +45,11 @@ func (v *ConfigValidator) Validate(config *Config) error {
 		return fmt.Errorf("commands configuration error: %w", err)
 	}
 
+	// Validate networking configuration
+	if err := v.validateNetworkingConfig(&config.Networking); err != nil {
+		return fmt.Errorf("networking configuration error: %w", err)
+	}
+
 	return nil
 }
 
@@ -70,6 +75,18 @@ func (v *ConfigValidator) validateShellConfig(config *ShellConfig) error {
 		return fmt.Errorf("timeout too large (max: 24h)")
 	}
 
+	validGlobPolicies := []string{"passthrough", "null", "error"}
+	if !contains(validGlobPolicies, config.GlobNoMatch) {
+		return fmt.Errorf("invalid glob_no_match policy: %s (valid: %s)",
+			config.GlobNoMatch, strings.Join(validGlobPolicies, ", "))
+	}
+
+	validDecorations := []string{"auto", "unicode", "ascii"}
+	if !contains(validDecorations, config.Decorations) {
+		return fmt.Errorf("invalid decorations: %s (valid: %s)",
+			config.Decorations, strings.Join(validDecorations, ", "))
+	}
+
 	// Validate color scheme
 	validSchemes := []string{"default", "dark", "light", "custom"}
 	if !contains(validSchemes, config.Colors.Scheme) {
@@ -126,6 +143,17 @@ func (v *ConfigValidator) validateSecurityConfig(config *SecurityConfig) error {
 		}
 	}
 
+	validGuards := []string{"run", "confirm", "block"}
+	if !contains(validGuards, config.Scripts.Guard) {
+		return fmt.Errorf("invalid scripts guard: %s (valid: %s)",
+			config.Scripts.Guard, strings.Join(validGuards, ", "))
+	}
+	for _, guarded := range config.Scripts.GuardedCommands {
+		if strings.TrimSpace(guarded) == "" {
+			return fmt.Errorf("guarded script commands cannot be empty")
+		}
+	}
+
 	return nil
 }
 
@@ -201,6 +229,23 @@ func (v *ConfigValidator) validateCommandsConfig(config *CommandsConfig) error {
 	return nil
 }
 
+// validateNetworkingConfig validates networking configuration
+func (v *ConfigValidator) validateNetworkingConfig(config *NetworkingConfig) error {
+	if config.IPInfo.Endpoint != "" && !strings.Contains(config.IPInfo.Endpoint, "{ip}") {
+		return fmt.Errorf("ipinfo endpoint must contain an {ip} placeholder")
+	}
+
+	if config.IPInfo.CacheTTL < 0 {
+		return fmt.Errorf("ipinfo cache ttl cannot be negative")
+	}
+
+	if config.IPInfo.Timeout < 0 {
+		return fmt.Errorf("ipinfo timeout cannot be negative")
+	}
+
+	return nil
+}
+
 // contains checks if a slice contains a string
 func contains(slice []string, item string) bool {
 	for _, s := range slice {
@@ -135,7 +135,7 @@ func (es *EnhancedShell) ExecuteEnhanced(input string) {
 		es.displayResult(result)
 	} else {
 		// Fallback to original execution
-		output := Dispatch(input)
+		output := DispatchInterruptible(input)
 		if output != "" {
 			fmt.Println(output)
 		}
@@ -253,7 +253,7 @@ func (lcb *LegacyCommandBridge) Execute(ctx context.Context, args []string) (*ag
 	start := time.Now()
 
 	// Execute legacy command
-	output := lcb.legacyCmd.Execute(args)
+	output := ExecuteCommand(ctx, lcb.legacyCmd, args)
 
 	// Determine result type based on output
 	resultType := agent.ResultTypeSuccess
@@ -271,7 +271,7 @@ func (lcb *LegacyCommandBridge) Execute(ctx context.Context, args []string) (*ag
 		ExitCode: exitCode,
 		Duration: time.Since(start),
 		Type:     resultType,
-		Metadata: map[string]any{
+		Metadata: map[string]interface{}{
 			"legacy_command": true,
 			"bridge_version": "1.0.0",
 		},
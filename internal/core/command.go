@@ -2,8 +2,11 @@ package core
 
 import (
 	"bufio"
+	"context"
 	"fmt"
+	"os"
 	"os/exec"
+	"os/signal"
 	"runtime"
 	"strings"
 	"time"
@@ -17,6 +20,13 @@ type Command interface {
 	Description() string // Add a description method for help output
 }
 
+// ContextCommand is implemented by legacy commands that can be cancelled.
+// Dispatch prefers ExecuteContext over Execute when a command provides it.
+type ContextCommand interface {
+	Command
+	ExecuteContext(ctx context.Context, args []string) string
+}
+
 var commandRegistry = make(map[string]Command)
 
 func Register(cmd Command) {
@@ -24,6 +34,37 @@ func Register(cmd Command) {
 }
 
 func Dispatch(input string, depth ...int) string {
+	return DispatchContext(context.Background(), input, depth...)
+}
+
+// DispatchInterruptible runs a command typed at the prompt, cancelling it on
+// Ctrl+C rather than letting the signal end the shell
+func DispatchInterruptible(input string) string {
+	ctx, stop := interruptContext()
+	defer stop()
+	return DispatchContext(ctx, input)
+}
+
+// interruptContext returns a context that Ctrl+C cancels until stop is called
+func interruptContext() (ctx context.Context, stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt)
+	go func() {
+		select {
+		case <-sigChan:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, func() {
+		signal.Stop(sigChan)
+		cancel()
+	}
+}
+
+// DispatchContext runs a command, passing ctx through to commands that support cancellation
+func DispatchContext(ctx context.Context, input string, depth ...int) string {
 	d := 0
 	if len(depth) > 0 {
 		d = depth[0]
@@ -44,7 +85,15 @@ func Dispatch(input string, depth ...int) string {
 	if !ok {
 		return "Unknown command: " + cmdName
 	}
-	return cmd.Execute(parts[1:])
+	return ExecuteCommand(ctx, cmd, parts[1:])
+}
+
+// ExecuteCommand runs a legacy command with ctx when it implements ContextCommand
+func ExecuteCommand(ctx context.Context, cmd Command, args []string) string {
+	if ctxCmd, ok := cmd.(ContextCommand); ok {
+		return ctxCmd.ExecuteContext(ctx, args)
+	}
+	return cmd.Execute(args)
 }
 
 type PingCommand struct{}
@@ -52,6 +101,10 @@ type PingCommand struct{}
 func (p *PingCommand) Name() string        { return "ping" }
 func (p *PingCommand) Description() string { return "Ping a host to test connectivity" }
 func (p *PingCommand) Execute(args []string) string {
+	return p.ExecuteContext(context.Background(), args)
+}
+
+func (p *PingCommand) ExecuteContext(ctx context.Context, args []string) string {
 	if len(args) == 0 {
 		return "Usage: ping <host> [count]"
 	}
@@ -62,9 +115,9 @@ func (p *PingCommand) Execute(args []string) string {
 	}
 	var cmd *exec.Cmd
 	if runtime.GOOS == "windows" {
-		cmd = exec.Command("ping", host, "-n", count)
+		cmd = exec.CommandContext(ctx, "ping", host, "-n", count)
 	} else {
-		cmd = exec.Command("ping", host, "-c", count)
+		cmd = exec.CommandContext(ctx, "ping", host, "-c", count)
 	}
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
@@ -97,11 +150,15 @@ type NslookupCommand struct{}
 func (n *NslookupCommand) Name() string        { return "nslookup" }
 func (n *NslookupCommand) Description() string { return "Query DNS records for a domain" }
 func (n *NslookupCommand) Execute(args []string) string {
+	return n.ExecuteContext(context.Background(), args)
+}
+
+func (n *NslookupCommand) ExecuteContext(ctx context.Context, args []string) string {
 	if len(args) == 0 {
 		return "Usage: nslookup <domain>"
 	}
 	domain := args[0]
-	out, err := exec.Command("nslookup", domain).CombinedOutput()
+	out, err := exec.CommandContext(ctx, "nslookup", domain).CombinedOutput()
 	if err != nil {
 		return fmt.Sprintf("nslookup failed: %v\n%s", err, string(out))
 	}
@@ -113,15 +170,19 @@ type TracertCommand struct{}
 func (t *TracertCommand) Name() string        { return "tracert" }
 func (t *TracertCommand) Description() string { return "Trace the route to a host" }
 func (t *TracertCommand) Execute(args []string) string {
+	return t.ExecuteContext(context.Background(), args)
+}
+
+func (t *TracertCommand) ExecuteContext(ctx context.Context, args []string) string {
 	if len(args) == 0 {
 		return "Usage: tracert <host>"
 	}
 	host := args[0]
 	var cmd *exec.Cmd
 	if runtime.GOOS == "windows" {
-		cmd = exec.Command("tracert", host)
+		cmd = exec.CommandContext(ctx, "tracert", host)
 	} else {
-		cmd = exec.Command("traceroute", host)
+		cmd = exec.CommandContext(ctx, "traceroute", host)
 	}
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
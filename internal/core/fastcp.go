@@ -0,0 +1,80 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"suppercommand/internal/commands"
+)
+
+const (
+	// fastcpRecvBufferSize is how much fastcp-recv reads from the connection at a time
+	fastcpRecvBufferSize = 32 * 1024
+	// The --buffer-size limits keep reads from degenerating into tiny chunks or
+	// holding an unreasonable amount of memory per transfer
+	fastcpRecvMinBufferSize = 4 * 1024
+	fastcpRecvMaxBufferSize = 16 * 1024 * 1024
+	// fastcpMaxStreams bounds the parallel connections of one transfer
+	fastcpMaxStreams = 16
+)
+
+// parseFastcpBufferSize reads a --buffer-size value such as 65536, 64K or 1M
+func parseFastcpBufferSize(value string) (int, error) {
+	size, err := commands.ParseBytes(value)
+	if err != nil {
+		return 0, err
+	}
+	if size < fastcpRecvMinBufferSize || size > fastcpRecvMaxBufferSize {
+		return 0, fmt.Errorf("buffer size must be between %s and %s, got %s",
+			commands.HumanizeBytes(fastcpRecvMinBufferSize), commands.HumanizeBytes(fastcpRecvMaxBufferSize), value)
+	}
+	return int(size), nil
+}
+
+// closeOnCancel closes c once ctx is cancelled, unblocking whatever is reading
+// or writing it. The returned function stops watching.
+func closeOnCancel(ctx context.Context, c io.Closer) func() {
+	stop := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			c.Close()
+		case <-stop:
+		}
+	}()
+	return func() { close(stop) }
+}
+
+// ReceiveFastcpFile copies the next size bytes of a normal transfer from conn to
+// file, reading up to len(buffer) at a time. Only the read is limited to what is
+// left of the file: buffer keeps its full length, so it can be shared by every
+// file of a session. progress, when set, is told the bytes received so far
+// after each read.
+func ReceiveFastcpFile(conn io.Reader, file io.Writer, size int64, buffer []byte, progress func(received int64)) (int64, error) {
+	var received int64
+	for received < size {
+		chunk := buffer
+		if remaining := size - received; remaining < int64(len(chunk)) {
+			chunk = chunk[:remaining]
+		}
+
+		n, err := conn.Read(chunk)
+		if n > 0 {
+			if _, writeErr := file.Write(chunk[:n]); writeErr != nil {
+				return received, fmt.Errorf("error writing to file: %w", writeErr)
+			}
+			received += int64(n)
+			if progress != nil {
+				progress(received)
+			}
+		}
+		if err != nil {
+			if err == io.EOF && received == size {
+				break
+			}
+			return received, fmt.Errorf("error receiving file data: %w", err)
+		}
+	}
+	return received, nil
+}
@@ -0,0 +1,96 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sendFastcpDirectory announces a directory below src with its permissions
+// and modification time
+func sendFastcpDirectory(conn io.Writer, src, dirPath string) error {
+	info, err := os.Stat(dirPath)
+	if err != nil {
+		return err
+	}
+	relPath, err := filepath.Rel(src, dirPath)
+	if err != nil {
+		return err
+	}
+	return writeFastcpHeader(conn, FileHeader{
+		Name:      filepath.ToSlash(relPath),
+		ModTime:   info.ModTime().Unix(),
+		Mode:      uint32(info.Mode().Perm()),
+		Directory: true,
+	})
+}
+
+// applyFastcpAttributes gives a received file or directory the permissions
+// and modification time it has at the sender. Only permission bits are
+// applied, never setuid, setgid or sticky.
+func applyFastcpAttributes(path string, header FileHeader) error {
+	if err := os.Chmod(path, os.FileMode(header.Mode).Perm()); err != nil {
+		return err
+	}
+	return os.Chtimes(path, time.Now(), time.Unix(header.ModTime, 0))
+}
+
+// makeFastcpWritable lets the receiver write to a file an earlier transfer
+// left read-only, as the sender's copy was
+func makeFastcpWritable(path string) error {
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm()&0200 != 0 {
+		return nil
+	}
+	return os.Chmod(path, info.Mode().Perm()|0200)
+}
+
+// fastcpDirectories collects the directories of a transfer. Their attributes
+// are applied once every file is written, as writing into a directory changes
+// its modification time and a read-only directory can't be written into.
+// Streams add to it concurrently.
+type fastcpDirectories struct {
+	mu      sync.Mutex
+	headers map[string]FileHeader
+}
+
+// add records a directory created at path
+func (d *fastcpDirectories) add(path string, header FileHeader) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.headers == nil {
+		d.headers = make(map[string]FileHeader)
+	}
+	d.headers[path] = header
+}
+
+// apply gives every directory its attributes, deepest first so a parent is
+// still accessible while its children are done. It returns the failures.
+func (d *fastcpDirectories) apply() []error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	paths := make([]string, 0, len(d.headers))
+	for path := range d.headers {
+		paths = append(paths, path)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		di, dj := strings.Count(paths[i], string(filepath.Separator)), strings.Count(paths[j], string(filepath.Separator))
+		if di != dj {
+			return di > dj
+		}
+		return paths[i] < paths[j]
+	})
+
+	var failures []error
+	for _, path := range paths {
+		if err := applyFastcpAttributes(path, d.headers[path]); err != nil {
+			failures = append(failures, fmt.Errorf("%s: %v", path, err))
+		}
+	}
+	return failures
+}
@@ -0,0 +1,200 @@
+package core
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+const (
+	// fastcpHeaderPrefix starts the metadata line announcing each file
+	fastcpHeaderPrefix = "HEADER:"
+	// fastcpMaxFrame bounds one frame of a compressed file stream
+	fastcpMaxFrame = 1024 * 1024
+)
+
+// writeFastcpHeader announces a file with its header on one line
+func writeFastcpHeader(conn io.Writer, header FileHeader) error {
+	data, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+	_, err = conn.Write([]byte(fastcpHeaderPrefix + string(data) + "\n"))
+	return err
+}
+
+// parseFastcpHeader reads the header of a metadata line
+func parseFastcpHeader(line string) (FileHeader, error) {
+	var header FileHeader
+	if !strings.HasPrefix(line, fastcpHeaderPrefix) {
+		return header, fmt.Errorf("invalid metadata format: %.64s", line)
+	}
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(line, fastcpHeaderPrefix)), &header); err != nil {
+		return header, fmt.Errorf("invalid file header: %w", err)
+	}
+	if header.Name == "" || header.Size < 0 || (header.DeltaSync && header.BlockSize <= 0) ||
+		(header.Directory && (header.Size != 0 || header.DeltaSync)) {
+		return header, fmt.Errorf("invalid file header for %q", header.Name)
+	}
+	return header, nil
+}
+
+// fastcpBlockCount is how many blocks of a delta sync the header announces
+func fastcpBlockCount(header FileHeader) int {
+	return int((header.Size + int64(header.BlockSize) - 1) / int64(header.BlockSize))
+}
+
+// fastcpFrameWriter carries a compressed file stream on the connection. Each
+// write becomes frames of a 4-byte length and the data, and Close writes the
+// empty frame that ends the stream, so the receiver's decompressor never reads
+// into the file after it.
+type fastcpFrameWriter struct {
+	conn io.Writer
+}
+
+// Write sends p as one frame per fastcpMaxFrame bytes
+func (w *fastcpFrameWriter) Write(p []byte) (int, error) {
+	written := 0
+	for written < len(p) {
+		frame := p[written:]
+		if len(frame) > fastcpMaxFrame {
+			frame = frame[:fastcpMaxFrame]
+		}
+		var length [4]byte
+		binary.BigEndian.PutUint32(length[:], uint32(len(frame)))
+		if _, err := w.conn.Write(append(length[:], frame...)); err != nil {
+			return written, err
+		}
+		written += len(frame)
+	}
+	return written, nil
+}
+
+// Close ends the stream
+func (w *fastcpFrameWriter) Close() error {
+	_, err := w.conn.Write(make([]byte, 4))
+	return err
+}
+
+// fastcpFrameReader reads the frames of one compressed file stream, returning
+// io.EOF at the empty frame that ends it
+type fastcpFrameReader struct {
+	conn io.Reader
+	left int
+	done bool
+}
+
+// Read returns the data of the current frame
+func (r *fastcpFrameReader) Read(p []byte) (int, error) {
+	for r.left == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		var length [4]byte
+		if _, err := io.ReadFull(r.conn, length[:]); err != nil {
+			if err == io.EOF {
+				return 0, io.ErrUnexpectedEOF
+			}
+			return 0, err
+		}
+		r.left = int(binary.BigEndian.Uint32(length[:]))
+		if r.left > fastcpMaxFrame {
+			return 0, fmt.Errorf("invalid compressed frame of %d bytes", r.left)
+		}
+		r.done = r.left == 0
+	}
+	if len(p) > r.left {
+		p = p[:r.left]
+	}
+	n, err := r.conn.Read(p)
+	r.left -= n
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+// fastcpCompressor compresses the data of one file onto the connection
+type fastcpCompressor struct {
+	*gzip.Writer
+	frames *fastcpFrameWriter
+}
+
+// newFastcpCompressor starts a compressed stream on conn
+func newFastcpCompressor(conn io.Writer) *fastcpCompressor {
+	frames := &fastcpFrameWriter{conn: conn}
+	// Favour speed: the network, not the ratio, is what the transfer waits on
+	gz, _ := gzip.NewWriterLevel(frames, gzip.BestSpeed)
+	return &fastcpCompressor{Writer: gz, frames: frames}
+}
+
+// Close flushes the compressed data and ends the stream
+func (c *fastcpCompressor) Close() error {
+	if err := c.Writer.Close(); err != nil {
+		return err
+	}
+	return c.frames.Close()
+}
+
+// fastcpDecompressor reads the data of one compressed file from the connection
+type fastcpDecompressor struct {
+	*gzip.Reader
+}
+
+// newFastcpDecompressor opens the compressed stream that follows on conn
+func newFastcpDecompressor(conn io.Reader) (*fastcpDecompressor, error) {
+	gz, err := gzip.NewReader(&fastcpFrameReader{conn: conn})
+	if err != nil {
+		return nil, fmt.Errorf("invalid compressed stream: %w", err)
+	}
+	return &fastcpDecompressor{Reader: gz}, nil
+}
+
+// finish reads the stream to its end, checking it held no more than the file
+// and that its checksum matches
+func (d *fastcpDecompressor) finish() error {
+	extra, err := io.Copy(ioutil.Discard, d.Reader)
+	if err != nil {
+		return fmt.Errorf("invalid compressed stream: %w", err)
+	}
+	if extra > 0 {
+		return fmt.Errorf("compressed stream holds %d bytes more than announced", extra)
+	}
+	return nil
+}
+
+// compressFastcpBlock compresses one delta sync block on its own, so each
+// block can still be placed by its index
+func compressFastcpBlock(block []byte) ([]byte, error) {
+	var compressed bytes.Buffer
+	gz, _ := gzip.NewWriterLevel(&compressed, gzip.BestSpeed)
+	if _, err := gz.Write(block); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return compressed.Bytes(), nil
+}
+
+// decompressFastcpBlock restores a block compressed by compressFastcpBlock,
+// refusing one that expands beyond limit bytes
+func decompressFastcpBlock(compressed []byte, limit int) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, fmt.Errorf("invalid compressed block: %w", err)
+	}
+	block, err := ioutil.ReadAll(io.LimitReader(gz, int64(limit)+1))
+	if err != nil {
+		return nil, fmt.Errorf("invalid compressed block: %w", err)
+	}
+	if len(block) > limit {
+		return nil, fmt.Errorf("compressed block expands beyond %d bytes", limit)
+	}
+	return block, nil
+}
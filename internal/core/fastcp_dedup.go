@@ -0,0 +1,283 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	// dedupCacheMaxAge is how long fastcp-dedup clean keeps an analyzed file
+	dedupCacheMaxAge = 7 * 24 * time.Hour
+	// dedupCacheMaxBlocks bounds the unique blocks kept in the cache
+	dedupCacheMaxBlocks = 100000
+)
+
+// DedupBlock is one block of an analyzed file
+type DedupBlock struct {
+	Hash string
+	Size int64
+}
+
+// DedupFile is what the cache knows of one analyzed file
+type DedupFile struct {
+	Blocks   []string  `json:"blocks"`
+	Size     int64     `json:"size"`
+	Analyzed time.Time `json:"analyzed"`
+}
+
+// DedupCache is the block-hash map fastcp-dedup analyze keeps on disk, so
+// stats and clean work from real data across sessions
+type DedupCache struct {
+	path string
+	// Files maps the absolute path of each analyzed file to its blocks
+	Files map[string]*DedupFile `json:"files"`
+	// Blocks maps the SHA-256 of each block to its size
+	Blocks map[string]int64 `json:"blocks"`
+}
+
+// DefaultDedupCachePath is ~/.fastcp/dedup_cache.json
+func DefaultDedupCachePath() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".fastcp", "dedup_cache.json")
+}
+
+// OpenDedupCache loads the cache at path; a missing cache is empty
+func OpenDedupCache(path string) (*DedupCache, error) {
+	cache := &DedupCache{path: path}
+	data, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, cache); err != nil {
+			return nil, fmt.Errorf("invalid dedup cache %s: %w", path, err)
+		}
+	}
+	if cache.Files == nil {
+		cache.Files = make(map[string]*DedupFile)
+	}
+	if cache.Blocks == nil {
+		cache.Blocks = make(map[string]int64)
+	}
+	return cache, nil
+}
+
+// Path is where the cache is stored
+func (c *DedupCache) Path() string { return c.path }
+
+// Record stores the blocks of the file at path, replacing what an earlier
+// analysis found in it. Blocks only the earlier analysis held are dropped on
+// Save.
+func (c *DedupCache) Record(path string, blocks []DedupBlock, analyzed time.Time) {
+	file := &DedupFile{Blocks: make([]string, 0, len(blocks)), Analyzed: analyzed}
+	for _, block := range blocks {
+		file.Blocks = append(file.Blocks, block.Hash)
+		file.Size += block.Size
+		c.Blocks[block.Hash] = block.Size
+	}
+	c.Files[path] = file
+}
+
+// Clean drops the files analyzed before cutoff and the blocks only they
+// held. It returns how many of each were removed.
+func (c *DedupCache) Clean(cutoff time.Time) (files, blocks int) {
+	for path, file := range c.Files {
+		if file.Analyzed.Before(cutoff) {
+			delete(c.Files, path)
+			files++
+		}
+	}
+	return files, c.prune()
+}
+
+// prune drops the blocks no file holds any more and returns how many
+func (c *DedupCache) prune() int {
+	referenced := make(map[string]bool, len(c.Blocks))
+	for _, file := range c.Files {
+		for _, hash := range file.Blocks {
+			referenced[hash] = true
+		}
+	}
+	removed := 0
+	for hash := range c.Blocks {
+		if !referenced[hash] {
+			delete(c.Blocks, hash)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Save writes the cache, first dropping the files analyzed longest ago while
+// it holds more than dedupCacheMaxBlocks blocks
+func (c *DedupCache) Save() error {
+	c.prune()
+	if len(c.Blocks) > dedupCacheMaxBlocks {
+		paths := make([]string, 0, len(c.Files))
+		for path := range c.Files {
+			paths = append(paths, path)
+		}
+		sort.Slice(paths, func(i, j int) bool {
+			return c.Files[paths[i]].Analyzed.Before(c.Files[paths[j]].Analyzed)
+		})
+		for _, path := range paths {
+			if len(c.Blocks) <= dedupCacheMaxBlocks {
+				break
+			}
+			delete(c.Files, path)
+			c.prune()
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.path, data, 0644)
+}
+
+// DedupStats are the totals of everything in the cache
+type DedupStats struct {
+	Files         int
+	UniqueBlocks  int
+	LogicalBlocks int
+	UniqueBytes   int64
+	LogicalBytes  int64
+	LastAnalyzed  time.Time
+}
+
+// DuplicateBlocks is how many blocks repeat one stored elsewhere
+func (s DedupStats) DuplicateBlocks() int { return s.LogicalBlocks - s.UniqueBlocks }
+
+// Savings is how many bytes storing each block once would save
+func (s DedupStats) Savings() int64 { return s.LogicalBytes - s.UniqueBytes }
+
+// Ratio is the percentage of the logical bytes that deduplicate away
+func (s DedupStats) Ratio() float64 {
+	if s.LogicalBytes == 0 {
+		return 0
+	}
+	return float64(s.Savings()) / float64(s.LogicalBytes) * 100
+}
+
+// Stats totals the cache
+func (c *DedupCache) Stats() DedupStats {
+	stats := DedupStats{Files: len(c.Files), UniqueBlocks: len(c.Blocks)}
+	for _, size := range c.Blocks {
+		stats.UniqueBytes += size
+	}
+	for _, file := range c.Files {
+		stats.LogicalBlocks += len(file.Blocks)
+		stats.LogicalBytes += file.Size
+		if file.Analyzed.After(stats.LastAnalyzed) {
+			stats.LastAnalyzed = file.Analyzed
+		}
+	}
+	return stats
+}
+
+// DedupTypeStats is how well the files of one type deduplicate
+type DedupTypeStats struct {
+	// Type is the file type, see dedupFileType
+	Type            string
+	Files           int
+	Size            int64
+	Blocks          int
+	DuplicateBlocks int
+	DuplicateSize   int64
+}
+
+// Ratio is the percentage of the type's blocks already seen elsewhere
+func (s *DedupTypeStats) Ratio() float64 {
+	if s.Blocks == 0 {
+		return 0
+	}
+	return float64(s.DuplicateBlocks) / float64(s.Blocks) * 100
+}
+
+// TypeStats breaks the cache down by file type. Taking the files in path
+// order, a block counts as a duplicate of the type holding it when an earlier
+// file already held it.
+func (c *DedupCache) TypeStats() map[string]*DedupTypeStats {
+	paths := make([]string, 0, len(c.Files))
+	for path := range c.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	types := make(map[string]*DedupTypeStats)
+	seen := make(map[string]bool, len(c.Blocks))
+	for _, path := range paths {
+		file := c.Files[path]
+		fileType := dedupFileType(path)
+		stats := types[fileType]
+		if stats == nil {
+			stats = &DedupTypeStats{Type: fileType}
+			types[fileType] = stats
+		}
+		stats.Files++
+		stats.Size += file.Size
+		for _, hash := range file.Blocks {
+			stats.Blocks++
+			if seen[hash] {
+				stats.DuplicateBlocks++
+				stats.DuplicateSize += c.Blocks[hash]
+			}
+			seen[hash] = true
+		}
+	}
+	return types
+}
+
+// DedupDuplicate is a block held more than once
+type DedupDuplicate struct {
+	Hash  string
+	Size  int64
+	Refs  int
+	Files []string
+}
+
+// TopDuplicates returns up to n of the blocks held most often, with the
+// files holding them
+func (c *DedupCache) TopDuplicates(n int) []DedupDuplicate {
+	byHash := make(map[string]*DedupDuplicate)
+	for path, file := range c.Files {
+		for _, hash := range file.Blocks {
+			dup := byHash[hash]
+			if dup == nil {
+				dup = &DedupDuplicate{Hash: hash, Size: c.Blocks[hash]}
+				byHash[hash] = dup
+			}
+			if dup.Refs == 0 || dup.Files[len(dup.Files)-1] != path {
+				dup.Files = append(dup.Files, path)
+			}
+			dup.Refs++
+		}
+	}
+
+	var duplicates []DedupDuplicate
+	for _, dup := range byHash {
+		if dup.Refs > 1 {
+			sort.Strings(dup.Files)
+			duplicates = append(duplicates, *dup)
+		}
+	}
+	sort.Slice(duplicates, func(i, j int) bool {
+		if duplicates[i].Refs != duplicates[j].Refs {
+			return duplicates[i].Refs > duplicates[j].Refs
+		}
+		return duplicates[i].Hash < duplicates[j].Hash
+	})
+	if len(duplicates) > n {
+		duplicates = duplicates[:n]
+	}
+	return duplicates
+}
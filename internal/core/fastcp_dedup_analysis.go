@@ -0,0 +1,228 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// dedupSampleLocations is how many locations of a block an analysis keeps
+	dedupSampleLocations = 3
+	// dedupMaxWorkers bounds fastcp-dedup analyze --workers
+	dedupMaxWorkers = 64
+)
+
+// dedupLocation is a block of an analysis: the index of its file and its
+// index in the file
+type dedupLocation struct {
+	file  int
+	block int
+}
+
+// before orders locations the way a serial scan of the files meets them
+func (l dedupLocation) before(other dedupLocation) bool {
+	return l.file < other.file || (l.file == other.file && l.block < other.block)
+}
+
+// dedupBlockRefs is all an analysis keeps of one distinct block
+type dedupBlockRefs struct {
+	count int
+	size  int64
+	// samples are the earliest dedupSampleLocations locations, in order, so
+	// samples[0] is the occurrence a serial scan counts as unique
+	samples []dedupLocation
+}
+
+// add records another location of the block
+func (r *dedupBlockRefs) add(location dedupLocation) {
+	r.count++
+	i := sort.Search(len(r.samples), func(i int) bool { return location.before(r.samples[i]) })
+	if i >= dedupSampleLocations {
+		return
+	}
+	if len(r.samples) < dedupSampleLocations {
+		r.samples = append(r.samples, dedupLocation{})
+	}
+	copy(r.samples[i+1:], r.samples[i:])
+	r.samples[i] = location
+}
+
+// DedupTypeBlocks is how the blocks of one file type deduplicate
+type DedupTypeBlocks struct {
+	Blocks          int
+	DuplicateBlocks int
+	DuplicateSize   int64
+}
+
+// DedupResult is what an analysis found. A block counts as a duplicate when
+// a serial scan of the files in order would have met it before.
+type DedupResult struct {
+	TotalBlocks     int
+	UniqueBlocks    int
+	DuplicateBlocks int
+	// Types breaks the blocks down by dedupFileType
+	Types map[string]DedupTypeBlocks
+	// Top are the blocks seen most often, with up to dedupSampleLocations
+	// of their locations as <file>:block<n>
+	Top []DedupDuplicate
+}
+
+// DedupAnalysis hashes files in fixed-size blocks on a pool of workers. It
+// keeps a count and a few locations per distinct block rather than every
+// occurrence, and the result is the same whatever the number of workers.
+type DedupAnalysis struct {
+	blockSize int
+	workers   int
+	cache     *DedupCache
+	analyzed  time.Time
+	hashed    int64
+
+	mu         sync.Mutex
+	files      []string
+	blocks     map[string]*dedupBlockRefs
+	typeBlocks map[string]int
+	typeBytes  map[string]int64
+}
+
+// NewDedupAnalysis prepares an analysis hashing blocks of blockSize bytes on
+// workers goroutines. When cache is set, the blocks of every file read in
+// full are recorded in it.
+func NewDedupAnalysis(blockSize, workers int, cache *DedupCache) *DedupAnalysis {
+	if workers < 1 {
+		workers = 1
+	}
+	return &DedupAnalysis{
+		blockSize:  blockSize,
+		workers:    workers,
+		cache:      cache,
+		analyzed:   time.Now(),
+		blocks:     make(map[string]*dedupBlockRefs),
+		typeBlocks: make(map[string]int),
+		typeBytes:  make(map[string]int64),
+	}
+}
+
+// Hashed is how many files the analysis has finished so far
+func (a *DedupAnalysis) Hashed() int64 { return atomic.LoadInt64(&a.hashed) }
+
+// Run hashes files, skipping those that can't be opened
+func (a *DedupAnalysis) Run(files []string) {
+	a.files = files
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < a.workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			buffer := make([]byte, a.blockSize)
+			for index := range jobs {
+				a.hashFile(index, buffer)
+				atomic.AddInt64(&a.hashed, 1)
+			}
+		}()
+	}
+	for index := range files {
+		jobs <- index
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// hashFile hashes the blocks of one file and merges them into the analysis
+func (a *DedupAnalysis) hashFile(index int, buffer []byte) {
+	path := a.files[index]
+	file, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	var blocks []DedupBlock
+	complete := true
+	for {
+		n, err := io.ReadFull(file, buffer)
+		if n > 0 {
+			sum := sha256.Sum256(buffer[:n])
+			blocks = append(blocks, DedupBlock{Hash: hex.EncodeToString(sum[:]), Size: int64(n)})
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			complete = false
+			break
+		}
+	}
+	file.Close()
+
+	fileType := dedupFileType(path)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for i, block := range blocks {
+		refs := a.blocks[block.Hash]
+		if refs == nil {
+			refs = &dedupBlockRefs{size: block.Size}
+			a.blocks[block.Hash] = refs
+		}
+		refs.add(dedupLocation{file: index, block: i})
+		a.typeBlocks[fileType]++
+		a.typeBytes[fileType] += block.Size
+	}
+	if a.cache != nil && complete {
+		if absPath, err := filepath.Abs(path); err == nil {
+			a.cache.Record(absPath, blocks, a.analyzed)
+		}
+	}
+}
+
+// Result totals the analysis, with up to top of the blocks seen most often
+func (a *DedupAnalysis) Result(top int) DedupResult {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	result := DedupResult{UniqueBlocks: len(a.blocks), Types: make(map[string]DedupTypeBlocks)}
+	uniqueBlocks := make(map[string]int)
+	uniqueBytes := make(map[string]int64)
+	var duplicates []DedupDuplicate
+	for hash, refs := range a.blocks {
+		result.TotalBlocks += refs.count
+		fileType := dedupFileType(a.files[refs.samples[0].file])
+		uniqueBlocks[fileType]++
+		uniqueBytes[fileType] += refs.size
+		if refs.count > 1 {
+			duplicates = append(duplicates, DedupDuplicate{Hash: hash, Size: refs.size, Refs: refs.count})
+		}
+	}
+	result.DuplicateBlocks = result.TotalBlocks - result.UniqueBlocks
+	for fileType, blocks := range a.typeBlocks {
+		result.Types[fileType] = DedupTypeBlocks{
+			Blocks:          blocks,
+			DuplicateBlocks: blocks - uniqueBlocks[fileType],
+			DuplicateSize:   a.typeBytes[fileType] - uniqueBytes[fileType],
+		}
+	}
+
+	sort.Slice(duplicates, func(i, j int) bool {
+		if duplicates[i].Refs != duplicates[j].Refs {
+			return duplicates[i].Refs > duplicates[j].Refs
+		}
+		return duplicates[i].Hash < duplicates[j].Hash
+	})
+	if len(duplicates) > top {
+		duplicates = duplicates[:top]
+	}
+	for i := range duplicates {
+		for _, location := range a.blocks[duplicates[i].Hash].samples {
+			duplicates[i].Files = append(duplicates[i].Files,
+				fmt.Sprintf("%s:block%d", filepath.Base(a.files[location.file]), location.block))
+		}
+	}
+	result.Top = duplicates
+	return result
+}
@@ -0,0 +1,238 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// fastcpPartialSuffix names the manifest kept next to a file while it is
+	// being received, so an interrupted transfer can be resumed
+	fastcpPartialSuffix = ".fastcp-partial"
+	// fastcpPartialSaveInterval is how much data arrives between manifest
+	// updates, bounding what a receiver that dies mid-file has to receive again
+	fastcpPartialSaveInterval = 8 * 1024 * 1024
+	// fastcpMaxLineLength bounds a protocol line read from the other end
+	fastcpMaxLineLength = 64 * 1024
+)
+
+// fastcpPartial is the manifest of a partly received file
+type fastcpPartial struct {
+	Size     int64 `json:"size"`
+	Received int64 `json:"received"`
+}
+
+// fastcpPartialPath is the manifest of the file at path
+func fastcpPartialPath(path string) string {
+	return path + fastcpPartialSuffix
+}
+
+// saveFastcpPartial records that received bytes of a file of size are in path
+func saveFastcpPartial(path string, size, received int64) error {
+	data, err := json.Marshal(fastcpPartial{Size: size, Received: received})
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fastcpPartialPath(path), data, 0644)
+}
+
+// fastcpResumeOffset is where a file of size can be resumed in path: the bytes
+// its manifest records, as long as the manifest is for a file of the same size
+// and they are all still on disk. Without a usable manifest it is 0.
+func fastcpResumeOffset(path string, size int64) int64 {
+	data, err := ioutil.ReadFile(fastcpPartialPath(path))
+	if err != nil {
+		return 0
+	}
+	var partial fastcpPartial
+	if json.Unmarshal(data, &partial) != nil || partial.Size != size {
+		return 0
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	offset := partial.Received
+	if info.Size() < offset {
+		offset = info.Size()
+	}
+	if offset < 0 || offset > size {
+		return 0
+	}
+	return offset
+}
+
+// openFastcpPartial opens path to receive a file of size and returns the
+// offset data is needed from. Anything in path past the offset is discarded.
+func openFastcpPartial(path string, size int64, resume bool) (*os.File, int64, error) {
+	var offset int64
+	if resume {
+		offset = fastcpResumeOffset(path, size)
+	} else {
+		os.Remove(fastcpPartialPath(path))
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := file.Truncate(offset); err != nil {
+		file.Close()
+		return nil, 0, err
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, 0, err
+	}
+	if resume {
+		if err := saveFastcpPartial(path, size, offset); err != nil {
+			file.Close()
+			return nil, 0, fmt.Errorf("cannot record partial transfer: %w", err)
+		}
+	}
+	return file, offset, nil
+}
+
+// ReceiveFastcpPartial receives the data of a normal transfer into path after
+// its metadata. It first tells the sender the offset to resume from: with
+// resume, what an interrupted transfer of a file of the same size left in
+// path, and 0 otherwise. While data arrives a sidecar manifest records how
+// much has been received, so the next session can pick it up; it is removed
+// once the file is complete. A compressed file arrives as a gzip stream of the
+// data from the offset on. progress, when set, is told how much of the file is
+// at the receiver after each read. It returns the offset and the bytes of the
+// file received in this session.
+func ReceiveFastcpPartial(conn io.ReadWriter, path string, size int64, resume, compressed bool, buffer []byte, progress func(position int64)) (int64, int64, error) {
+	file, offset, err := openFastcpPartial(path, size, resume)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer file.Close()
+
+	if _, err := fmt.Fprintf(conn, "RESUME:%d\n", offset); err != nil {
+		return offset, 0, fmt.Errorf("error sending resume offset: %w", err)
+	}
+
+	var src io.Reader = conn
+	var decompressor *fastcpDecompressor
+	if compressed {
+		if decompressor, err = newFastcpDecompressor(conn); err != nil {
+			return offset, 0, err
+		}
+		src = decompressor
+	}
+
+	saved := offset
+	received, err := ReceiveFastcpFile(src, file, size-offset, buffer, func(received int64) {
+		if resume && offset+received-saved >= fastcpPartialSaveInterval {
+			saved = offset + received
+			saveFastcpPartial(path, size, saved)
+		}
+		if progress != nil {
+			progress(offset + received)
+		}
+	})
+	if err != nil {
+		if resume {
+			saveFastcpPartial(path, size, offset+received)
+		}
+		return offset, received, err
+	}
+	if decompressor != nil {
+		if err := decompressor.finish(); err != nil {
+			// Data with a bad checksum can't be trusted to resume from
+			if resume {
+				saveFastcpPartial(path, size, offset)
+			}
+			return offset, received, err
+		}
+	}
+
+	if err := file.Close(); err != nil {
+		return offset, received, fmt.Errorf("error writing to file: %w", err)
+	}
+	if resume {
+		os.Remove(fastcpPartialPath(path))
+	}
+	return offset, received, nil
+}
+
+// SendFastcpFile sends the data of a normal transfer after its metadata. It
+// reads the offset the receiver resumes from and sends the rest of file from
+// there, up to len(buffer) at a time, as a gzip stream when compressed is set.
+// progress, when set, is told how much of the file is at the receiver after
+// each write. It returns the offset and the bytes of the file sent.
+func SendFastcpFile(conn io.ReadWriter, file io.ReadSeeker, size int64, compressed bool, buffer []byte, progress func(position int64)) (int64, int64, error) {
+	line, err := readFastcpLine(conn)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error reading resume offset: %w", err)
+	}
+	offset, err := strconv.ParseInt(strings.TrimPrefix(line, "RESUME:"), 10, 64)
+	if !strings.HasPrefix(line, "RESUME:") || err != nil || offset < 0 || offset > size {
+		return 0, 0, fmt.Errorf("invalid resume offset: %q", line)
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return offset, 0, err
+	}
+
+	var out io.Writer = conn
+	var compressor *fastcpCompressor
+	if compressed {
+		compressor = newFastcpCompressor(conn)
+		out = compressor
+	}
+
+	var sent int64
+	for offset+sent < size {
+		chunk := buffer
+		if remaining := size - offset - sent; remaining < int64(len(chunk)) {
+			chunk = chunk[:remaining]
+		}
+
+		n, err := file.Read(chunk)
+		if n > 0 {
+			if _, err := out.Write(chunk[:n]); err != nil {
+				return offset, sent, fmt.Errorf("error sending data: %w", err)
+			}
+			sent += int64(n)
+			if progress != nil {
+				progress(offset + sent)
+			}
+		}
+		if err == io.EOF && offset+sent < size {
+			// The receiver waits for every byte announced
+			return offset, sent, fmt.Errorf("file shrank during transfer")
+		}
+		if err != nil && err != io.EOF {
+			return offset, sent, err
+		}
+	}
+	if compressor != nil {
+		if err := compressor.Close(); err != nil {
+			return offset, sent, fmt.Errorf("error sending data: %w", err)
+		}
+	}
+	return offset, sent, nil
+}
+
+// readFastcpLine reads one protocol line, a byte at a time so nothing past
+// the newline is consumed
+func readFastcpLine(conn io.Reader) (string, error) {
+	var line strings.Builder
+	b := make([]byte, 1)
+	for line.Len() < fastcpMaxLineLength {
+		if _, err := io.ReadFull(conn, b); err != nil {
+			return "", err
+		}
+		if b[0] == '\n' {
+			return line.String(), nil
+		}
+		line.WriteByte(b[0])
+	}
+	return "", fmt.Errorf("line too long")
+}
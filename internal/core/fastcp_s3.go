@@ -0,0 +1,244 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// S3Storage is a CloudStorageProvider for S3-compatible storage such as S3,
+// Wasabi and IDrive e2, addressing buckets path-style below Endpoint.
+// Requests are signed when Signer is set and sent anonymously otherwise.
+type S3Storage struct {
+	Endpoint string
+	Bucket   string
+	Signer   *S3Signer
+	Client   *http.Client
+}
+
+// NewS3Storage creates storage for bucket at endpoint
+func NewS3Storage(endpoint, bucket string, signer *S3Signer) *S3Storage {
+	return &S3Storage{
+		Endpoint: strings.TrimRight(endpoint, "/"),
+		Bucket:   bucket,
+		Signer:   signer,
+		Client:   &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// Init configures the storage from the endpoint, bucket, region, access_key
+// and secret_key settings
+func (s *S3Storage) Init(ctx context.Context, config map[string]string) error {
+	if config["endpoint"] == "" || config["bucket"] == "" {
+		return fmt.Errorf("s3 storage needs an endpoint and a bucket")
+	}
+	var signer *S3Signer
+	if config["access_key"] != "" && config["secret_key"] != "" {
+		signer = NewS3Signer(config["access_key"], config["secret_key"], config["region"])
+	}
+	*s = *NewS3Storage(config["endpoint"], config["bucket"], signer)
+	return nil
+}
+
+// UploadFile stores a local file as remoteKey, sealed with encryptionKey
+// when encrypt is set
+func (s *S3Storage) UploadFile(ctx context.Context, localPath string, remoteKey string, encrypt bool, encryptionKey string) error {
+	data, err := ioutil.ReadFile(localPath)
+	if err != nil {
+		return err
+	}
+	if encrypt {
+		if data, err = SealFastcpObject(data, encryptionKey); err != nil {
+			return err
+		}
+	}
+	resp, err := s.send(ctx, "PUT", remoteKey, nil, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return s3ResponseError(resp)
+}
+
+// DownloadFile writes remoteKey to localPath, opening it with decryptionKey
+// when decrypt is set. Nothing is written for an object that fails to open.
+func (s *S3Storage) DownloadFile(ctx context.Context, remoteKey string, localPath string, decrypt bool, decryptionKey string) error {
+	resp, err := s.send(ctx, "GET", remoteKey, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := s3ResponseError(resp); err != nil {
+		return err
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if decrypt {
+		if data, err = OpenFastcpObject(data, decryptionKey); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(localPath, data, 0644)
+}
+
+// ListObjects lists every object whose key starts with prefix, following
+// ListObjectsV2 continuation tokens until the listing is complete
+func (s *S3Storage) ListObjects(ctx context.Context, prefix string) ([]CloudObject, error) {
+	var objects []CloudObject
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}}
+		if prefix != "" {
+			query.Set("prefix", prefix)
+		}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		resp, err := s.send(ctx, "GET", "", query, nil)
+		if err != nil {
+			return nil, err
+		}
+		page, next, err := func() ([]CloudObject, string, error) {
+			defer resp.Body.Close()
+			if err := s3ResponseError(resp); err != nil {
+				return nil, "", err
+			}
+			return ParseListBucketResult(resp.Body)
+		}()
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, page...)
+		if next == "" {
+			return objects, nil
+		}
+		token = next
+	}
+}
+
+// HeadObject returns the size, modification time and user metadata of remoteKey
+func (s *S3Storage) HeadObject(ctx context.Context, remoteKey string) (CloudObject, error) {
+	resp, err := s.send(ctx, "HEAD", remoteKey, nil, nil)
+	if err != nil {
+		return CloudObject{}, err
+	}
+	defer resp.Body.Close()
+	if err := s3ResponseError(resp); err != nil {
+		return CloudObject{}, err
+	}
+
+	object := CloudObject{Key: remoteKey, Size: resp.ContentLength, Metadata: make(map[string]string)}
+	if modified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		object.LastModified = modified
+	}
+	for name, values := range resp.Header {
+		if lower := strings.ToLower(name); strings.HasPrefix(lower, "x-amz-meta-") && len(values) > 0 {
+			object.Metadata[strings.TrimPrefix(lower, "x-amz-meta-")] = values[0]
+		}
+	}
+	return object, nil
+}
+
+// send makes one request for key, or for the bucket itself when key is empty
+func (s *S3Storage) send(ctx context.Context, method, key string, query url.Values, payload []byte) (*http.Response, error) {
+	target, err := url.Parse(s.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint %q: %w", s.Endpoint, err)
+	}
+	target.Path = path.Join("/", target.Path, s.Bucket, key)
+	if key == "" {
+		target.Path += "/"
+	}
+	target.RawQuery = query.Encode()
+
+	req, err := http.NewRequest(method, target.String(), bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+	if s.Signer != nil {
+		s.Signer.Sign(req, payload, time.Now())
+	}
+	return s.Client.Do(req)
+}
+
+// s3Error is the body S3 sends with a failed request
+type s3Error struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// s3ResponseError turns a failed response into an error, with the provider's
+// error code when the body has one
+func s3ResponseError(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	var failure s3Error
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if xml.Unmarshal(body, &failure) == nil && failure.Code != "" {
+		return fmt.Errorf("%s: %s (%s)", resp.Status, failure.Message, failure.Code)
+	}
+	return fmt.Errorf("%s", resp.Status)
+}
+
+// listBucketResult is one page of a ListObjectsV2 response
+type listBucketResult struct {
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+	Contents              []struct {
+		Key          string `xml:"Key"`
+		LastModified string `xml:"LastModified"`
+		ETag         string `xml:"ETag"`
+		Size         int64  `xml:"Size"`
+	} `xml:"Contents"`
+}
+
+// ParseListBucketResult reads a ListObjectsV2 ListBucketResult and returns its
+// objects and the token of the next page, empty on the last one
+func ParseListBucketResult(body io.Reader) ([]CloudObject, string, error) {
+	var result listBucketResult
+	if err := xml.NewDecoder(body).Decode(&result); err != nil {
+		return nil, "", fmt.Errorf("invalid object listing: %w", err)
+	}
+
+	objects := make([]CloudObject, 0, len(result.Contents))
+	for _, content := range result.Contents {
+		object := CloudObject{Key: content.Key, Size: content.Size, Metadata: map[string]string{}}
+		if content.ETag != "" {
+			object.Metadata["etag"] = strings.Trim(content.ETag, `"`)
+		}
+		if modified, err := time.Parse(time.RFC3339, content.LastModified); err == nil {
+			object.LastModified = modified
+		}
+		objects = append(objects, object)
+	}
+
+	next := ""
+	if result.IsTruncated {
+		if result.NextContinuationToken == "" {
+			return nil, "", fmt.Errorf("truncated object listing has no continuation token")
+		}
+		next = result.NextContinuationToken
+	}
+	return objects, next, nil
+}
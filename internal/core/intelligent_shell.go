@@ -125,7 +125,7 @@ func (is *IntelligentShell) intelligentExecutor(input string) {
 
 	// Execute command
 	startTime := time.Now()
-	output := Dispatch(input)
+	output := DispatchInterruptible(input)
 	if output != "" {
 		fmt.Println(output)
 	}
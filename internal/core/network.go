@@ -0,0 +1,783 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"suppercommand/internal/commands"
+
+	prompt "github.com/c-bata/go-prompt"
+	"github.com/fatih/color"
+)
+
+// colorizeLines colours each line of tool output with the colour pick chooses
+// for it, leaving the lines pick returns nil for as they are
+func colorizeLines(out string, pick func(lower string) *color.Color) string {
+	lines := strings.Split(strings.TrimRight(out, "\r\n"), "\n")
+	for i, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		if c := pick(strings.ToLower(line)); c != nil {
+			line = c.Sprint(line)
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
+type IpconfigCommand struct {
+	Runner commands.CommandRunner
+}
+
+func (i *IpconfigCommand) Name() string        { return "ipconfig" }
+func (i *IpconfigCommand) Description() string { return "Show network interfaces and IP addresses" }
+func (i *IpconfigCommand) Execute(args []string) string {
+	return i.ExecuteContext(context.Background(), args)
+}
+
+func (i *IpconfigCommand) ExecuteContext(ctx context.Context, args []string) string {
+	runner := commands.RunnerOr(i.Runner)
+	name, toolArgs := "ip", []string{"addr"}
+	if runtime.GOOS == "windows" {
+		name, toolArgs = "ipconfig", []string{"/all"}
+	} else if _, err := runner.LookPath("ifconfig"); err == nil {
+		// Prefer 'ifconfig', fall back to 'ip addr'
+		name, toolArgs = "ifconfig", nil
+	}
+	out, err := runner.Run(ctx, name, toolArgs...)
+	if err != nil {
+		return fmt.Sprintf("ipconfig failed: %v", err)
+	}
+	return colorizeLines(string(out), ipconfigColor)
+}
+
+// ipconfigColor picks the colour of a line of ipconfig, ifconfig or ip addr output
+func ipconfigColor(lower string) *color.Color {
+	switch {
+	case strings.Contains(lower, "adapter") || strings.HasPrefix(lower, "interface") || strings.HasPrefix(lower, "en") || strings.HasPrefix(lower, "eth"):
+		return color.New(color.FgCyan, color.Bold)
+	case strings.Contains(lower, "ipv4") || strings.Contains(lower, "inet "):
+		return color.New(color.FgGreen)
+	case strings.Contains(lower, "ipv6"):
+		return color.New(color.FgHiGreen)
+	case strings.Contains(lower, "physical") || strings.Contains(lower, "mac") || strings.Contains(lower, "ether"):
+		return color.New(color.FgMagenta)
+	case strings.Contains(lower, "dns") || strings.Contains(lower, "gateway") || strings.Contains(lower, "router"):
+		return color.New(color.FgBlue)
+	}
+	return nil
+}
+
+type NetstatCommand struct {
+	Runner commands.CommandRunner
+	// Input reads the live filter shown after the table. Without it netstat
+	// returns the table and stops.
+	Input func(prefix string) string
+	// Output is where the live filter redraws the connections, os.Stdout when nil
+	Output io.Writer
+}
+
+func (n *NetstatCommand) Name() string { return "netstat" }
+func (n *NetstatCommand) Description() string {
+	return "Show open network connections (type 'netstat --help' for options)"
+}
+
+func (n *NetstatCommand) Help() string {
+	return `Show open network connections
+
+Usage:
+  netstat [options]
+
+Options:
+  -tcp, --tcp           Show only TCP connections
+  -udp, --udp           Show only UDP connections
+  -state <STATE>        Filter by connection state (e.g. ESTABLISHED, LISTEN)
+  -p, --process <PID>   Filter by process ID
+  :<port>               Filter by local port (e.g. :80)
+  --sort <column>       Sort by column (proto, local, remote, state, pid)
+  --desc                Sort descending
+  --group               Group by state
+  --csv                 Export as CSV
+  --json                Export as JSON
+  --regex               Treat every live filter as a regular expression
+  --ignore-case         Ignore case in regular expression filters
+  --user                (Not yet implemented) Show only connections for current user
+
+Interactive filter:
+  After output, type to filter live. Type enter on empty line to exit filter.
+  Plain text matches anywhere in a connection, ignoring case. Text written as
+  /pattern/ is a regular expression matched against each column on its own,
+  so ^tcp matches the protocol and a pattern starting with : is anchored to
+  the end of an address: /:(80|443)/ matches those ports only. A pattern that
+  doesn't compile is matched as plain text.`
+}
+
+type NetstatEntry struct {
+	Proto, Local, Remote, State, PID, Program string
+	RawLine                                   string
+}
+
+// netstatTool is the tool whose output the netstat builtin parses. Each lays
+// out its columns differently.
+type netstatTool int
+
+const (
+	// netstat -ano on Windows: Proto, Local, Foreign, State, PID. UDP sockets
+	// have no state.
+	windowsNetstat netstatTool = iota
+	// netstat -tunap: Proto, Recv-Q, Send-Q, Local, Foreign, State,
+	// PID/Program. UDP sockets usually have no state.
+	unixNetstat
+	// ss -tunap: Netid, State, Recv-Q, Send-Q, Local, Peer, Process
+	ssTool
+)
+
+// ssStates maps the states ss abbreviates to the names netstat uses
+var ssStates = map[string]string{
+	"ESTAB":      "ESTABLISHED",
+	"FIN-WAIT-1": "FIN_WAIT1",
+	"FIN-WAIT-2": "FIN_WAIT2",
+}
+
+var ssProcessPattern = regexp.MustCompile(`\(\("([^"]*)",pid=(\d+)`)
+
+// parseNetstat reads the connections out of a tool's output, skipping the
+// banners and column headers
+func parseNetstat(out string, tool netstatTool) []NetstatEntry {
+	entries := []NetstatEntry{}
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimRight(line, "\r")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch strings.ToLower(fields[0]) {
+		case "tcp", "tcp6", "udp", "udp6":
+		default:
+			continue
+		}
+		entry := NetstatEntry{Proto: fields[0], RawLine: line}
+
+		switch tool {
+		case ssTool:
+			if len(fields) < 6 {
+				continue
+			}
+			entry.State = fields[1]
+			if state, ok := ssStates[entry.State]; ok {
+				entry.State = state
+			} else {
+				entry.State = strings.ReplaceAll(entry.State, "-", "_")
+			}
+			entry.Local, entry.Remote = fields[4], fields[5]
+			if match := ssProcessPattern.FindStringSubmatch(strings.Join(fields[6:], " ")); match != nil {
+				entry.Program, entry.PID = match[1], match[2]
+			}
+		case unixNetstat:
+			if len(fields) < 5 {
+				continue
+			}
+			entry.Local, entry.Remote = fields[3], fields[4]
+			rest := fields[5:]
+			if len(rest) > 0 && rest[0] != "-" && !strings.Contains(rest[0], "/") && strings.ToUpper(rest[0]) == rest[0] {
+				entry.State, rest = rest[0], rest[1:]
+			}
+			if process := strings.Join(rest, " "); process != "" && process != "-" {
+				entry.PID, entry.Program = process, ""
+				if slash := strings.Index(process, "/"); slash >= 0 {
+					entry.PID, entry.Program = process[:slash], process[slash+1:]
+				}
+			}
+		default:
+			if len(fields) < 4 {
+				continue
+			}
+			entry.Local, entry.Remote = fields[1], fields[2]
+			if len(fields) >= 5 {
+				entry.State, entry.PID = fields[3], fields[4]
+			} else {
+				entry.PID = fields[3]
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// netstatPort returns the port of an address such as 0.0.0.0:80 or [::]:443
+func netstatPort(addr string) string {
+	if i := strings.LastIndex(addr, ":"); i >= 0 {
+		return addr[i+1:]
+	}
+	if i := strings.LastIndex(addr, "."); i >= 0 {
+		return addr[i+1:]
+	}
+	return ""
+}
+
+// netstatFilter holds the -tcp/-udp, :port, -state and -p options
+type netstatFilter struct {
+	proto, port, state, pid string
+}
+
+func (f netstatFilter) match(e NetstatEntry) bool {
+	switch {
+	case f.proto != "" && !strings.HasPrefix(strings.ToLower(e.Proto), f.proto):
+		return false
+	case f.port != "" && netstatPort(e.Local) != f.port:
+		return false
+	case f.state != "" && !strings.Contains(strings.ToLower(e.State), f.state):
+		return false
+	case f.pid != "" && e.PID != f.pid:
+		return false
+	}
+	return true
+}
+
+// process returns the PID/Program column of an entry
+func (e NetstatEntry) process() string {
+	switch {
+	case e.PID == "":
+		return "-"
+	case e.Program != "":
+		return e.PID + "/" + e.Program
+	}
+	return e.PID
+}
+
+// netstatTable renders the connections with a summary of what they are
+func netstatTable(entries []NetstatEntry) string {
+	localWidth, remoteWidth := len("Local Address"), len("Foreign Address")
+	for _, e := range entries {
+		if len(e.Local) > localWidth {
+			localWidth = len(e.Local)
+		}
+		if len(e.Remote) > remoteWidth {
+			remoteWidth = len(e.Remote)
+		}
+	}
+
+	var b strings.Builder
+	color.New(color.FgCyan, color.Bold).Fprintf(&b, "%-6s  %-*s  %-*s  %-12s  %s\n",
+		"Proto", localWidth, "Local Address", remoteWidth, "Foreign Address", "State", "PID/Program")
+	var tcpCount, udpCount, established, listening int
+	for _, e := range entries {
+		proto := fmt.Sprintf("%-6s", e.Proto)
+		if strings.HasPrefix(strings.ToLower(e.Proto), "udp") {
+			udpCount++
+			proto = color.MagentaString(proto)
+		} else {
+			tcpCount++
+			proto = color.BlueString(proto)
+		}
+		state := fmt.Sprintf("%-12s", e.State)
+		lower := strings.ToLower(e.State)
+		switch {
+		case strings.Contains(lower, "established"):
+			established++
+			state = color.HiGreenString(state)
+		case strings.Contains(lower, "listen"):
+			listening++
+			state = color.GreenString(state)
+		case strings.Contains(lower, "close"):
+			state = color.RedString(state)
+		}
+		process := e.process()
+		if process != "-" {
+			process = color.YellowString(process)
+		}
+		fmt.Fprintf(&b, "%s  %s  %s  %s  %s\n",
+			proto,
+			color.CyanString("%-*s", localWidth, e.Local),
+			color.CyanString("%-*s", remoteWidth, e.Remote),
+			state,
+			process)
+	}
+	color.New(color.FgHiBlack).Fprintf(&b, "\nTotal: %d | TCP: %d | UDP: %d | ESTABLISHED: %d | LISTENING: %d\n", len(entries), tcpCount, udpCount, established, listening)
+	color.New(color.FgHiBlack).Fprint(&b, "Options: -tcp, -udp, -state <STATE>, -p/--process <PID>, :<port> (e.g. netstat -tcp :80 -state established)")
+	return b.String()
+}
+
+func highlightFilter(line, filter string) string {
+	if filter == "" {
+		return line
+	}
+	lowerLine := strings.ToLower(line)
+	lowerFilter := strings.ToLower(filter)
+	var result strings.Builder
+	i := 0
+	for i < len(line) {
+		if len(lowerLine[i:]) >= len(lowerFilter) && lowerLine[i:i+len(lowerFilter)] == lowerFilter {
+			// Highlight the match
+			result.WriteString(color.New(color.BgYellow, color.FgBlack, color.Bold).Sprint(line[i : i+len(lowerFilter)]))
+			i += len(lowerFilter)
+		} else {
+			result.WriteByte(line[i])
+			i++
+		}
+	}
+	return result.String()
+}
+
+func badge(text, colorName string) string {
+	var c *color.Color
+	switch colorName {
+	case "green":
+		c = color.New(color.FgGreen, color.Bold)
+	case "yellow":
+		c = color.New(color.FgYellow, color.Bold)
+	case "red":
+		c = color.New(color.FgRed, color.Bold)
+	case "blue":
+		c = color.New(color.FgBlue, color.Bold)
+	case "magenta":
+		c = color.New(color.FgMagenta, color.Bold)
+	default:
+		c = color.New(color.Bold)
+	}
+	return c.Sprintf("[%s]", text)
+}
+
+// netstatRow renders one connection of the dashboards, highlighting filter
+func netstatRow(e NetstatEntry, filter netstatLiveFilter) string {
+	// Protocol icon
+	protoIcon := ""
+	switch strings.ToLower(e.Proto) {
+	case "tcp", "tcp6":
+		protoIcon = "🌐"
+	case "udp", "udp6":
+		protoIcon = "📡"
+	}
+	// State badge
+	stateBadge := ""
+	stateLower := strings.ToLower(e.State)
+	switch {
+	case strings.Contains(stateLower, "established"):
+		stateBadge = badge("ESTABLISHED", "green")
+	case strings.Contains(stateLower, "listen"):
+		stateBadge = badge("LISTEN", "yellow")
+	case strings.Contains(stateLower, "close"):
+		stateBadge = badge("CLOSE", "red")
+	default:
+		stateBadge = badge(e.State, "blue")
+	}
+	// Highlight filter in addresses
+	local := filter.highlight(e.Local)
+	remote := filter.highlight(e.Remote)
+	pid := filter.highlight(e.PID)
+	return fmt.Sprintf("%-2s %-6s %-25s %-25s %-15s %-8s\n",
+		protoIcon, e.Proto, local, remote, stateBadge, pid)
+}
+
+// netstatLiveFilter is the filter typed at the live prompt: plain text, or a
+// regular expression compiled once when the filter changes
+type netstatLiveFilter struct {
+	text string
+	re   *regexp.Regexp
+}
+
+// compileNetstatFilter reads text as a regular expression when it is written
+// as /pattern/ or regex is set, and as plain text otherwise or when the
+// pattern doesn't compile. A pattern starting with : is anchored to the end
+// of the column so it matches whole ports.
+func compileNetstatFilter(text string, regex, ignoreCase bool) netstatLiveFilter {
+	filter := netstatLiveFilter{text: text}
+	if len(text) >= 2 && strings.HasPrefix(text, "/") && strings.HasSuffix(text, "/") {
+		filter.text, regex = text[1:len(text)-1], true
+	}
+	if !regex || filter.text == "" {
+		return filter
+	}
+	pattern := filter.text
+	if strings.HasPrefix(pattern, ":") {
+		pattern = "(?:" + pattern + ")$"
+	}
+	if ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+	if re, err := regexp.Compile(pattern); err == nil {
+		filter.re = re
+	}
+	return filter
+}
+
+// match reports whether the filter matches a connection. Plain text may match
+// anywhere in the tool's line; a regular expression must match one column.
+func (f netstatLiveFilter) match(e NetstatEntry) bool {
+	if f.re == nil {
+		return f.text == "" || strings.Contains(strings.ToLower(e.RawLine), strings.ToLower(f.text))
+	}
+	for _, column := range []string{e.Proto, e.Local, e.Remote, e.State, e.process()} {
+		if f.re.MatchString(column) {
+			return true
+		}
+	}
+	return false
+}
+
+// highlight marks what the filter matches in one column
+func (f netstatLiveFilter) highlight(column string) string {
+	if f.re == nil {
+		return highlightFilter(column, f.text)
+	}
+	var result strings.Builder
+	last := 0
+	for _, loc := range f.re.FindAllStringIndex(column, -1) {
+		if loc[0] == loc[1] {
+			continue
+		}
+		result.WriteString(column[last:loc[0]])
+		result.WriteString(color.New(color.BgYellow, color.FgBlack, color.Bold).Sprint(column[loc[0]:loc[1]]))
+		last = loc[1]
+	}
+	result.WriteString(column[last:])
+	return result.String()
+}
+
+func modernNetstatDisplay(entries []NetstatEntry, filter netstatLiveFilter) string {
+	var b strings.Builder
+	// Summary bar
+	total, established, listening := 0, 0, 0
+	for _, e := range entries {
+		if filter.match(e) {
+			total++
+			if strings.Contains(strings.ToLower(e.State), "established") {
+				established++
+			}
+			if strings.Contains(strings.ToLower(e.State), "listen") {
+				listening++
+			}
+		}
+	}
+	color.New(color.BgBlue, color.FgWhite, color.Bold).Fprint(&b, " Netstat Dashboard ")
+	fmt.Fprintf(&b, "  Total: %d  ", total)
+	color.New(color.BgGreen, color.FgBlack).Fprintf(&b, " ESTABLISHED: %d ", established)
+	color.New(color.BgYellow, color.FgBlack).Fprintf(&b, " LISTENING: %d ", listening)
+	b.WriteString("\n")
+
+	// Headers
+	color.New(color.FgCyan, color.Bold).Fprintf(&b, "%-8s %-25s %-25s %-15s %-8s\n", "PROTO", "LOCAL", "REMOTE", "STATE", "PID")
+	color.New(color.FgHiBlack).Fprintln(&b, strings.Repeat("─", 90))
+
+	for _, e := range entries {
+		if filter.match(e) {
+			b.WriteString(netstatRow(e, filter))
+		}
+	}
+	return b.String()
+}
+
+func (n *NetstatCommand) Execute(args []string) string {
+	return n.ExecuteContext(context.Background(), args)
+}
+
+// ExecuteContext lists the connections the system tools report
+func (n *NetstatCommand) ExecuteContext(ctx context.Context, args []string) string {
+	for _, arg := range args {
+		if arg == "--help" || arg == "-h" {
+			return n.Help()
+		}
+	}
+
+	var filter netstatFilter
+	exportCSV := false
+	exportJSON := false
+	sortColumn := ""
+	sortAsc := true
+	groupByState := false
+	regex, ignoreCase := false, false
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "-tcp" || arg == "--tcp":
+			filter.proto = "tcp"
+		case arg == "-udp" || arg == "--udp":
+			filter.proto = "udp"
+		case arg == "-state" && i+1 < len(args):
+			filter.state = strings.ToLower(args[i+1])
+			i++
+		case (arg == "-p" || arg == "--process") && i+1 < len(args):
+			filter.pid = args[i+1]
+			i++
+		case arg == "--csv":
+			exportCSV = true
+		case arg == "--json":
+			exportJSON = true
+		case arg == "--sort":
+			if i+1 < len(args) {
+				sortColumn = strings.ToLower(args[i+1])
+				i++
+			}
+		case arg == "--desc":
+			sortAsc = false
+		case arg == "--group":
+			groupByState = true
+		case arg == "--regex":
+			regex = true
+		case arg == "--ignore-case":
+			ignoreCase = true
+		case arg == "--user":
+			// This feature is complex and requires platform-specific logic
+			// For now, we'll just print a placeholder message.
+			return "User-specific filtering (--user) is not yet implemented."
+		case strings.HasPrefix(arg, ":"):
+			filter.port = arg[1:]
+		}
+	}
+
+	runner := commands.RunnerOr(n.Runner)
+	name, tool := "netstat", unixNetstat
+	toolArgs := []string{"-tunap"}
+	if runtime.GOOS == "windows" {
+		tool, toolArgs = windowsNetstat, []string{"-ano"}
+	} else if _, err := runner.LookPath("ss"); err == nil {
+		name, tool = "ss", ssTool
+	}
+	out, err := runner.Run(ctx, name, toolArgs...)
+	if err != nil {
+		return fmt.Sprintf("netstat failed: %v", err)
+	}
+
+	entries := []NetstatEntry{}
+	for _, e := range parseNetstat(string(out), tool) {
+		if filter.match(e) {
+			entries = append(entries, e)
+		}
+	}
+	if sortColumn != "" {
+		sortNetstatEntries(entries, sortColumn, sortAsc)
+	}
+
+	// Export to CSV
+	if exportCSV {
+		var b strings.Builder
+		b.WriteString("proto,local,remote,state,pid")
+		for _, e := range entries {
+			fmt.Fprintf(&b, "\n%s,%s,%s,%s,%s", e.Proto, e.Local, e.Remote, e.State, e.PID)
+		}
+		return b.String()
+	}
+	// Export to JSON
+	if exportJSON {
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return "Failed to marshal JSON: " + err.Error()
+		}
+		return string(data)
+	}
+
+	if n.Input == nil {
+		if groupByState {
+			return strings.TrimRight(modernNetstatDisplayGrouped(entries, netstatLiveFilter{}), "\n")
+		}
+		return netstatTable(entries)
+	}
+
+	// Interactive filtering loop
+	screen := n.Output
+	if screen == nil {
+		screen = os.Stdout
+	}
+	prefix := "Filter (/regex/, enter to exit): "
+	if regex {
+		prefix = "Regex filter (enter to exit): "
+	}
+	fmt.Fprintln(screen, netstatTable(entries))
+	var live netstatLiveFilter
+	for {
+		if groupByState {
+			fmt.Fprint(screen, modernNetstatDisplayGrouped(entries, live))
+		} else {
+			fmt.Fprint(screen, modernNetstatDisplay(entries, live))
+		}
+		fmt.Fprintln(screen)
+		topPorts(entries, 5) // Show top 5 ports
+		text := n.Input(prefix)
+		if text == "" {
+			break
+		}
+		live = compileNetstatFilter(text, regex, ignoreCase)
+		fmt.Fprint(screen, "\033[H\033[2J") // Clear screen
+	}
+
+	return ""
+}
+
+// netstatFilterInput reads the live netstat filter at the shell prompt
+func netstatFilterInput(prefix string) string {
+	return prompt.Input(prefix, func(d prompt.Document) []prompt.Suggest { return nil })
+}
+
+func groupByState(entries []NetstatEntry) map[string][]NetstatEntry {
+	groups := make(map[string][]NetstatEntry)
+	for _, e := range entries {
+		state := strings.ToUpper(e.State)
+		groups[state] = append(groups[state], e)
+	}
+	return groups
+}
+
+func modernNetstatDisplayGrouped(entries []NetstatEntry, filter netstatLiveFilter) string {
+	groups := groupByState(entries)
+	states := make([]string, 0, len(groups))
+	for state := range groups {
+		states = append(states, state)
+	}
+	sort.Strings(states)
+
+	var b strings.Builder
+	for _, state := range states {
+		color.New(color.FgHiMagenta, color.Bold).Fprintf(&b, "\n=== %s ===\n", state)
+		for _, e := range groups[state] {
+			if filter.match(e) {
+				b.WriteString(netstatRow(e, filter))
+			}
+		}
+	}
+	return b.String()
+}
+
+func topPorts(entries []NetstatEntry, n int) {
+	portCount := make(map[string]int)
+	for _, e := range entries {
+		parts := strings.Split(e.Local, ":")
+		if len(parts) > 1 {
+			port := parts[len(parts)-1]
+			portCount[port]++
+		}
+	}
+	// Sort and print top N
+	// ...
+}
+
+func sortNetstatEntries(entries []NetstatEntry, column string, asc bool) {
+	sort.Slice(entries, func(i, j int) bool {
+		var a, b string
+		switch column {
+		case "proto":
+			a, b = entries[i].Proto, entries[j].Proto
+		case "local":
+			a, b = entries[i].Local, entries[j].Local
+		case "remote":
+			a, b = entries[i].Remote, entries[j].Remote
+		case "state":
+			a, b = entries[i].State, entries[j].State
+		case "pid":
+			// PIDs sort as numbers, sockets without one first
+			a, b = fmt.Sprintf("%10s", entries[i].PID), fmt.Sprintf("%10s", entries[j].PID)
+		default:
+			a, b = entries[i].RawLine, entries[j].RawLine
+		}
+		if asc {
+			return a < b
+		}
+		return a > b
+	})
+}
+
+type ArpCommand struct {
+	Runner commands.CommandRunner
+}
+
+func (a *ArpCommand) Name() string { return "arp" }
+func (a *ArpCommand) Description() string {
+	return `Show the ARP table
+
+Usage:
+  arp
+
+Options:
+  (no options yet)
+
+Shows the system ARP table. On Windows, uses 'arp -a'. On Unix, uses 'ip neigh' or 'arp -a'.`
+}
+func (a *ArpCommand) Execute(args []string) string {
+	return a.ExecuteContext(context.Background(), args)
+}
+
+func (a *ArpCommand) ExecuteContext(ctx context.Context, args []string) string {
+	runner := commands.RunnerOr(a.Runner)
+	name, toolArgs := "arp", []string{"-a"}
+	if runtime.GOOS != "windows" {
+		if _, err := runner.LookPath("ip"); err == nil {
+			name, toolArgs = "ip", []string{"neigh"}
+		}
+	}
+	out, err := runner.Run(ctx, name, toolArgs...)
+	if err != nil {
+		return fmt.Sprintf("arp failed: %v", err)
+	}
+	return colorizeLines(string(out), arpColor)
+}
+
+// arpColor picks the colour of a line of arp -a or ip neigh output
+func arpColor(lower string) *color.Color {
+	switch {
+	case strings.Contains(lower, "dynamic") || strings.Contains(lower, "reachable"):
+		return color.New(color.FgGreen)
+	case strings.Contains(lower, "static") || strings.Contains(lower, "permanent"):
+		return color.New(color.FgCyan)
+	case strings.Contains(lower, "incomplete") || strings.Contains(lower, "failed"):
+		return color.New(color.FgRed)
+	}
+	return nil
+}
+
+type RouteCommand struct {
+	Runner commands.CommandRunner
+}
+
+func (r *RouteCommand) Name() string { return "route" }
+func (r *RouteCommand) Description() string {
+	return `route - Show the routing table
+
+  Usage:
+    route
+
+  Options:
+    (no options yet)
+
+  Notes:
+    - Shows the system routing table
+    - On Windows, uses 'route print'
+    - On Unix, uses 'ip route' or 'netstat -rn'
+`
+}
+func (r *RouteCommand) Execute(args []string) string {
+	return r.ExecuteContext(context.Background(), args)
+}
+
+func (r *RouteCommand) ExecuteContext(ctx context.Context, args []string) string {
+	runner := commands.RunnerOr(r.Runner)
+	name, toolArgs := "netstat", []string{"-rn"}
+	if runtime.GOOS == "windows" {
+		name, toolArgs = "route", []string{"print"}
+	} else if _, err := runner.LookPath("ip"); err == nil {
+		name, toolArgs = "ip", []string{"route"}
+	}
+	out, err := runner.Run(ctx, name, toolArgs...)
+	if err != nil {
+		return fmt.Sprintf("route failed: %v", err)
+	}
+	return colorizeLines(string(out), routeColor)
+}
+
+// routeColor picks the colour of a line of route print, ip route or netstat -rn output
+func routeColor(lower string) *color.Color {
+	switch {
+	case strings.Contains(lower, "default") || strings.Contains(lower, "gateway"):
+		return color.New(color.FgGreen, color.Bold)
+	case strings.Contains(lower, "metric"):
+		return color.New(color.FgCyan)
+	case strings.Contains(lower, "interface"):
+		return color.New(color.FgYellow)
+	}
+	return nil
+}
@@ -30,6 +30,7 @@ func NewShell() *Shell {
 	Register(&CdCommand{})
 	Register(&ExitCommand{})
 	Register(&CatCommand{})
+	Register(&GrepCommand{})
 	Register(&MkdirCommand{})
 	Register(&RmCommand{})
 	Register(&RmdirCommand{})
@@ -45,13 +46,14 @@ func NewShell() *Shell {
 	Register(&TracertCommand{})
 	Register(&WgetCommand{})
 	Register(&IpconfigCommand{})
-	Register(&NetstatCommand{})
+	Register(&NetstatCommand{Input: netstatFilterInput})
 	Register(&ArpCommand{})
 	Register(&RouteCommand{})
 	Register(&SpeedtestCommand{})
 	Register(&NetdiscoverCommand{})
 	Register(&SniffCommand{})
 	Register(&SysInfoCommand{})
+	Register(&CompareSystemsCommand{})
 	Register(&PrivCommand{})
 	Register(&RemoteCommand{})
 	Register(&WinUpdateCommand{})
@@ -133,7 +135,7 @@ func executor(in string) {
 		fmt.Println("Goodbye!")
 		os.Exit(0)
 	}
-	output := Dispatch(in)
+	output := DispatchInterruptible(in)
 	if output != "" {
 		fmt.Println(output)
 	}
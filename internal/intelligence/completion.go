@@ -21,15 +21,15 @@ const (
 )
 
 type Completion struct {
-	Text        string         `json:"text"`
-	Display     string         `json:"display"`
-	Description string         `json:"description"`
-	Type        CompletionType `json:"type"`
-	Category    string         `json:"category"`
-	Icon        string         `json:"icon"`
-	Score       float64        `json:"score"`
-	InsertText  string         `json:"insert_text"`
-	Metadata    map[string]any `json:"metadata"`
+	Text        string                 `json:"text"`
+	Display     string                 `json:"display"`
+	Description string                 `json:"description"`
+	Type        CompletionType         `json:"type"`
+	Category    string                 `json:"category"`
+	Icon        string                 `json:"icon"`
+	Score       float64                `json:"score"`
+	InsertText  string                 `json:"insert_text"`
+	Metadata    map[string]interface{} `json:"metadata"`
 }
 
 type CompletionResult struct {
@@ -330,7 +330,7 @@ func (p *SmartSuggestionProvider) GetCompletions(ctx context.Context, input *Inp
 			Icon:        "🤖",
 			Score:       float64(suggestion.Frequency) * 10,
 			InsertText:  suggestion.Command,
-			Metadata: map[string]any{
+			Metadata: map[string]interface{}{
 				"frequency":  suggestion.Frequency,
 				"confidence": suggestion.Confidence,
 			},
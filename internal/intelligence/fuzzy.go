@@ -53,7 +53,7 @@ func (fm *FuzzyMatcher) FilterAndRank(query string, completions []Completion) []
 		completion := sc.Completion
 		completion.Score = sc.Completion.Score + sc.FuzzyScore*50
 		if completion.Metadata == nil {
-			completion.Metadata = make(map[string]any)
+			completion.Metadata = make(map[string]interface{})
 		}
 		completion.Metadata["fuzzy_score"] = sc.FuzzyScore
 		completion.Metadata["highlight"] = sc.MatchResult.Highlight
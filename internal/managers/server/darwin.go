@@ -577,6 +577,12 @@ func (d *DarwinServerManager) GetServiceLogs(ctx context.Context, serviceName st
 	return logStream, nil
 }
 
+// GetEventLog is not available on macOS, whose unified log has no event logs to map
+// the query onto
+func (d *DarwinServerManager) GetEventLog(ctx context.Context, query *types.EventLogQuery) ([]*types.EventLogEntry, error) {
+	return nil, types.NewServiceError(query.Log, "eventlog", nil, "event logs are not supported on macOS; use 'log show' instead")
+}
+
 // ConfigureAlerts configures macOS-specific alert settings
 func (d *DarwinServerManager) ConfigureAlerts(ctx context.Context, config *types.AlertConfig) error {
 	// macOS-specific alert configuration would go here
@@ -2,7 +2,9 @@ package server
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"os"
 	"os/exec"
 	"strconv"
 	"strings"
@@ -654,6 +656,116 @@ func (l *LinuxServerManager) GetServiceLogs(ctx context.Context, serviceName str
 	return logStream, nil
 }
 
+// journalPriorities maps event levels to the journalctl priority that selects them
+var journalPriorities = map[types.EventLevel]string{
+	types.EventLevelCritical:    "crit",
+	types.EventLevelError:       "err",
+	types.EventLevelWarning:     "warning",
+	types.EventLevelInformation: "info",
+	types.EventLevelVerbose:     "debug",
+}
+
+// GetEventLog returns recent journal entries. The Windows log names map onto the
+// journal: System and Application read all of it, Security reads the auth
+// facilities and Kernel the kernel ring buffer; any other name is a systemd unit.
+func (l *LinuxServerManager) GetEventLog(ctx context.Context, query *types.EventLogQuery) ([]*types.EventLogEntry, error) {
+	logName := query.Log
+	if logName == "" {
+		logName = "System"
+	}
+	if !l.useSystemd {
+		return nil, types.NewServiceError(logName, "eventlog", nil, "the systemd journal is not available; see /var/log instead")
+	}
+
+	args := []string{"-o", "json", "--no-pager", "-r", "-n", strconv.Itoa(eventCount(query))}
+	switch strings.ToLower(logName) {
+	case "system", "application":
+	case "security":
+		args = append(args, "SYSLOG_FACILITY=4", "SYSLOG_FACILITY=10")
+	case "kernel":
+		args = append(args, "-k")
+	default:
+		args = append(args, "-u", logName)
+	}
+	if priority, ok := journalPriorities[query.MaxLevel]; ok {
+		args = append(args, "-p", priority)
+	}
+	if !query.Since.IsZero() {
+		args = append(args, "--since", query.Since.Format("2006-01-02 15:04:05"))
+	}
+
+	cmd := exec.CommandContext(ctx, "journalctl", args...)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if strings.Contains(stderr.String(), "insufficient permissions") {
+		return nil, types.NewServiceError(logName, "eventlog", os.ErrPermission,
+			"access denied; run as root or join the systemd-journal or adm group to read the journal")
+	}
+	if err != nil {
+		return nil, types.NewServiceError(logName, "eventlog", err, strings.TrimSpace(stderr.String()))
+	}
+
+	entries := make([]*types.EventLogEntry, 0)
+	for _, line := range strings.Split(string(output), "\n") {
+		if entry, ok := ParseJournalEvent(line, logName); ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// ParseJournalEvent converts one line of `journalctl -o json`. Journal priorities are
+// syslog's, with emergency through critical folded into Critical. Lines that
+// aren't JSON objects are skipped.
+func ParseJournalEvent(line, logName string) (*types.EventLogEntry, bool) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(line), &fields); err != nil {
+		return nil, false
+	}
+	field := func(name string) string {
+		var value string
+		if json.Unmarshal(fields[name], &value) == nil {
+			return value
+		}
+		// Values that aren't valid UTF-8 are exported as arrays of bytes
+		var data []byte
+		var numbers []int
+		if json.Unmarshal(fields[name], &numbers) == nil {
+			for _, n := range numbers {
+				data = append(data, byte(n))
+			}
+		}
+		return string(data)
+	}
+
+	entry := &types.EventLogEntry{Level: types.EventLevelInformation, Message: field("MESSAGE"), Log: logName}
+	if micros, err := strconv.ParseInt(field("__REALTIME_TIMESTAMP"), 10, 64); err == nil {
+		entry.Time = time.Unix(0, micros*int64(time.Microsecond))
+	}
+	if priority, err := strconv.Atoi(field("PRIORITY")); err == nil {
+		switch {
+		case priority <= 2:
+			entry.Level = types.EventLevelCritical
+		case priority == 3:
+			entry.Level = types.EventLevelError
+		case priority == 4:
+			entry.Level = types.EventLevelWarning
+		case priority == 7:
+			entry.Level = types.EventLevelVerbose
+		}
+	}
+	for _, name := range []string{"SYSLOG_IDENTIFIER", "_COMM", "_SYSTEMD_UNIT"} {
+		if entry.Source = field(name); entry.Source != "" {
+			break
+		}
+	}
+	if entry.Source == "" {
+		entry.Source = "kernel"
+	}
+	return entry, true
+}
+
 // ConfigureAlerts configures Linux-specific alert settings
 func (l *LinuxServerManager) ConfigureAlerts(ctx context.Context, config *types.AlertConfig) error {
 	// Linux-specific alert configuration would go here
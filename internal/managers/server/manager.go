@@ -33,6 +33,17 @@ func (f *Factory) CreateManager() (types.ServerManager, error) {
 	}
 }
 
+// defaultEventCount is how many events GetEventLog returns when the query sets no count
+const defaultEventCount = 50
+
+// eventCount returns the number of events a query asks for
+func eventCount(query *types.EventLogQuery) int {
+	if query.Count <= 0 {
+		return defaultEventCount
+	}
+	return query.Count
+}
+
 // BaseServerManager provides common functionality for all server managers
 type BaseServerManager struct {
 	platform types.Platform
@@ -335,6 +346,37 @@ func (m *MockServerManager) GetServiceLogs(ctx context.Context, serviceName stri
 	return logStream, nil
 }
 
+// GetEventLog returns mock event log entries, newest first
+func (m *MockServerManager) GetEventLog(ctx context.Context, query *types.EventLogQuery) ([]*types.EventLogEntry, error) {
+	logName := query.Log
+	if logName == "" {
+		logName = "System"
+	}
+
+	now := time.Now()
+	events := []*types.EventLogEntry{
+		{Time: now.Add(-5 * time.Minute), Level: types.EventLevelInformation, Source: "Service Control Manager", EventID: 7036, Message: "The Windows Update service entered the running state.", Log: logName},
+		{Time: now.Add(-20 * time.Minute), Level: types.EventLevelWarning, Source: "disk", EventID: 153, Message: "The IO operation at logical block address 0x1f2 was retried.", Log: logName},
+		{Time: now.Add(-45 * time.Minute), Level: types.EventLevelError, Source: "Service Control Manager", EventID: 7000, Message: "The Print Spooler service failed to start.", Log: logName},
+		{Time: now.Add(-2 * time.Hour), Level: types.EventLevelCritical, Source: "Kernel-Power", EventID: 41, Message: "The system has rebooted without cleanly shutting down first.", Log: logName},
+	}
+
+	var entries []*types.EventLogEntry
+	for _, event := range events {
+		if len(entries) >= eventCount(query) {
+			break
+		}
+		if query.MaxLevel != 0 && event.Level > query.MaxLevel {
+			continue
+		}
+		if !query.Since.IsZero() && event.Time.Before(query.Since) {
+			continue
+		}
+		entries = append(entries, event)
+	}
+	return entries, nil
+}
+
 // ConfigureAlerts configures mock alert settings
 func (m *MockServerManager) ConfigureAlerts(ctx context.Context, config *types.AlertConfig) error {
 	// Mock implementation - would store configuration in real implementation
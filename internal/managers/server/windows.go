@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
 	"os/exec"
 	"strconv"
 	"strings"
@@ -587,6 +588,95 @@ func (w *WindowsServerManager) GetServiceLogs(ctx context.Context, serviceName s
 	return logStream, nil
 }
 
+// GetEventLog returns recent events from a Windows event log
+func (w *WindowsServerManager) GetEventLog(ctx context.Context, query *types.EventLogQuery) ([]*types.EventLogEntry, error) {
+	logName := query.Log
+	if logName == "" {
+		logName = "System"
+	}
+
+	filter := fmt.Sprintf("LogName = '%s'", strings.Replace(logName, "'", "''", -1))
+	if query.MaxLevel > 0 && query.MaxLevel < types.EventLevelVerbose {
+		levels := make([]string, 0, int(query.MaxLevel))
+		for level := types.EventLevelCritical; level <= query.MaxLevel; level++ {
+			levels = append(levels, strconv.Itoa(int(level)))
+		}
+		if query.MaxLevel >= types.EventLevelInformation {
+			// Level 0 (LogAlways) is shown as Information
+			levels = append(levels, "0")
+		}
+		filter += "; Level = " + strings.Join(levels, ",")
+	}
+	if !query.Since.IsZero() {
+		filter += fmt.Sprintf("; StartTime = [datetime]'%s'", query.Since.Format("2006-01-02T15:04:05"))
+	}
+
+	// TimeCreated is formatted here because ConvertTo-Json writes dates as /Date(ms)/
+	cmd := exec.CommandContext(ctx, "powershell", "-NoProfile", "-Command", fmt.Sprintf(`
+		try {
+			$events = @(Get-WinEvent -FilterHashtable @{ %s } -MaxEvents %d -ErrorAction Stop |
+				ForEach-Object {
+					[PSCustomObject]@{
+						TimeCreated = $_.TimeCreated.ToUniversalTime().ToString('o')
+						Level = [int]$_.Level
+						ProviderName = $_.ProviderName
+						Id = $_.Id
+						Message = $_.Message
+					}
+				})
+			ConvertTo-Json -InputObject $events -Depth 2
+		} catch {
+			if ($_.FullyQualifiedErrorId -match 'NoMatchingEventsFound') { '[]' } else { throw }
+		}
+	`, filter, eventCount(query)))
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		message := string(output)
+		if strings.Contains(message, "UnauthorizedAccess") || strings.Contains(message, "unauthorized operation") {
+			return nil, types.NewServiceError(logName, "eventlog", os.ErrPermission,
+				"access denied; reading this log requires an elevated (Run as Administrator) prompt")
+		}
+		return nil, types.NewServiceError(logName, "eventlog", err, strings.TrimSpace(message))
+	}
+
+	return ParseWinEvents(output, logName)
+}
+
+// ParseWinEvents converts the JSON array GetEventLog's Get-WinEvent script writes.
+// Level 0 (LogAlways) is reported as Information.
+func ParseWinEvents(output []byte, logName string) ([]*types.EventLogEntry, error) {
+	var events []struct {
+		TimeCreated  string `json:"TimeCreated"`
+		Level        int    `json:"Level"`
+		ProviderName string `json:"ProviderName"`
+		ID           int    `json:"Id"`
+		Message      string `json:"Message"`
+	}
+	if err := json.Unmarshal(output, &events); err != nil {
+		return nil, types.NewServiceError(logName, "eventlog", err, "failed to parse events")
+	}
+
+	entries := make([]*types.EventLogEntry, 0, len(events))
+	for _, event := range events {
+		level := types.EventLevel(event.Level)
+		if level == 0 {
+			level = types.EventLevelInformation
+		}
+		timestamp, _ := time.Parse(time.RFC3339Nano, event.TimeCreated)
+		entries = append(entries, &types.EventLogEntry{
+			Time:    timestamp.Local(),
+			Level:   level,
+			Source:  event.ProviderName,
+			EventID: event.ID,
+			Message: strings.TrimSpace(event.Message),
+			Log:     logName,
+		})
+	}
+
+	return entries, nil
+}
+
 // ConfigureAlerts configures Windows-specific alert settings
 func (w *WindowsServerManager) ConfigureAlerts(ctx context.Context, config *types.AlertConfig) error {
 	// Windows-specific alert configuration would go here
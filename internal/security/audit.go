@@ -0,0 +1,275 @@
+package security
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"suppercommand/internal/config"
+)
+
+// auditQueueSize is how many entries may wait for the writer before recording
+// one waits for room
+const auditQueueSize = 256
+
+// auditQueueWait is how long recording an entry waits for room in a full queue
+// before dropping it rather than holding up the prompt any longer
+const auditQueueWait = 250 * time.Millisecond
+
+// AuditEntry is one executed command in the audit log
+type AuditEntry struct {
+	Time     time.Time `json:"time"`
+	User     string    `json:"user"`
+	Host     string    `json:"host,omitempty"`
+	PID      int       `json:"pid"`
+	Dir      string    `json:"cwd"`
+	Command  string    `json:"command"`
+	ExitCode int       `json:"exit_code"`
+	Duration int64     `json:"duration_ms"`
+	Elevated bool      `json:"elevated,omitempty"`
+}
+
+// String formats the entry as a single line for syslog and the Event Log
+func (e AuditEntry) String() string {
+	return fmt.Sprintf("user=%s cwd=%s exit=%d duration=%dms command=%s", e.User, e.Dir, e.ExitCode, e.Duration, e.Command)
+}
+
+// auditSink mirrors audit entries to the system log
+type auditSink interface {
+	Write(entry AuditEntry) error
+	Close() error
+}
+
+// AuditLog appends every executed command to a JSON-lines file, one entry per
+// line, and optionally to syslog or the Windows Event Log. Entries are written
+// by a background goroutine so recording one never waits on the disk.
+type AuditLog struct {
+	config config.AuditConfig
+	path   string
+	user   string
+	host   string
+
+	mu      sync.Mutex
+	queue   chan AuditEntry
+	pending sync.WaitGroup
+	closed  bool
+	dropped int
+	err     error
+
+	// Owned by the writer goroutine
+	file       *os.File
+	sink       auditSink
+	sinkOpened bool
+}
+
+// NewAuditLog creates the audit log described by cfg. A disabled log records
+// nothing but can still be read.
+func NewAuditLog(cfg config.AuditConfig) *AuditLog {
+	path := cfg.File
+	if path == "" {
+		path = filepath.Join("~", ".supershell", "audit.log")
+	}
+
+	a := &AuditLog{
+		config: cfg,
+		path:   config.ExpandHome(path),
+		user:   "unknown",
+	}
+	if current, err := user.Current(); err == nil {
+		a.user = current.Username
+	}
+	a.host, _ = os.Hostname()
+
+	if cfg.Enabled {
+		a.queue = make(chan AuditEntry, auditQueueSize)
+		go a.writeLoop()
+	}
+	return a
+}
+
+// Enabled reports whether commands are being recorded
+func (a *AuditLog) Enabled() bool {
+	return a != nil && a.config.Enabled
+}
+
+// Path returns the audit log file
+func (a *AuditLog) Path() string {
+	return a.path
+}
+
+// SystemLog reports whether entries are also sent to syslog or the Event Log
+func (a *AuditLog) SystemLog() bool {
+	return a.config.Syslog
+}
+
+// Record queues an executed command for the log with its secrets redacted.
+// When the writer has fallen behind it waits up to auditQueueWait for room,
+// then drops the entry, counts it and warns on stderr.
+func (a *AuditLog) Record(command, dir string, exitCode int, duration time.Duration) {
+	if !a.Enabled() {
+		return
+	}
+	entry := AuditEntry{
+		Time:     time.Now(),
+		User:     a.user,
+		Host:     a.host,
+		PID:      os.Getpid(),
+		Dir:      dir,
+		Command:  RedactSecrets(command),
+		ExitCode: exitCode,
+		Duration: duration.Milliseconds(),
+	}
+
+	// Close waits for pending entries before closing the queue, so once one is
+	// added the queue stays open while this waits for room
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return
+	}
+	a.pending.Add(1)
+	a.mu.Unlock()
+
+	select {
+	case a.queue <- entry:
+		return
+	default:
+	}
+	timer := time.NewTimer(auditQueueWait)
+	defer timer.Stop()
+	select {
+	case a.queue <- entry:
+		return
+	case <-timer.C:
+	}
+	a.pending.Done()
+
+	a.mu.Lock()
+	a.dropped++
+	dropped := a.dropped
+	a.mu.Unlock()
+	fmt.Fprintf(os.Stderr, "⚠️  audit log writer fell behind, an entry was dropped (%d so far, see audit status)\n", dropped)
+}
+
+// Flush waits until every queued entry has been written
+func (a *AuditLog) Flush() {
+	if a.Enabled() {
+		a.pending.Wait()
+	}
+}
+
+// Close writes the queued entries and closes the log
+func (a *AuditLog) Close() error {
+	if !a.Enabled() {
+		return nil
+	}
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return nil
+	}
+	a.closed = true
+	a.mu.Unlock()
+
+	a.pending.Wait()
+	close(a.queue)
+
+	var err error
+	if a.file != nil {
+		err = a.file.Close()
+	}
+	if a.sink != nil {
+		a.sink.Close()
+	}
+	return err
+}
+
+// Status reports how many entries were dropped and the last write error
+func (a *AuditLog) Status() (dropped int, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.dropped, a.err
+}
+
+// writeLoop writes queued entries until the queue is closed
+func (a *AuditLog) writeLoop() {
+	for entry := range a.queue {
+		err := a.write(entry)
+		a.mu.Lock()
+		if err != nil {
+			a.err = err
+		}
+		a.mu.Unlock()
+		a.pending.Done()
+	}
+}
+
+// write appends one entry to the file and the system log, opening them on
+// first use
+func (a *AuditLog) write(entry AuditEntry) error {
+	// Checking elevation can start a process on Windows, so it happens here
+	// rather than at the prompt
+	entry.Elevated = IsElevated()
+
+	var sinkErr error
+	if a.config.Syslog && !a.sinkOpened {
+		// Tried once; without a system log the file carries on alone
+		a.sinkOpened = true
+		if a.sink, sinkErr = newSystemAuditSink(); sinkErr != nil {
+			sinkErr = fmt.Errorf("system log unavailable: %w", sinkErr)
+		}
+	}
+	if a.sink != nil {
+		sinkErr = a.sink.Write(entry)
+	}
+
+	if a.file == nil {
+		if err := os.MkdirAll(filepath.Dir(a.path), 0700); err != nil {
+			return err
+		}
+		file, err := os.OpenFile(a.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+		if err != nil {
+			return err
+		}
+		a.file = file
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if _, err := a.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	return sinkErr
+}
+
+// ScanAuditLog parses the complete lines of an audit log, returning the entries
+// and the number of bytes consumed. A partial last line is left unread, as it
+// may still be being written.
+func ScanAuditLog(r io.Reader) ([]AuditEntry, int64, error) {
+	var entries []AuditEntry
+	var consumed int64
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			return entries, consumed, nil
+		}
+		if err != nil {
+			return entries, consumed, err
+		}
+		consumed += int64(len(line))
+
+		var entry AuditEntry
+		if line = bytes.TrimSpace(line); len(line) > 0 && json.Unmarshal(line, &entry) == nil {
+			entries = append(entries, entry)
+		}
+	}
+}
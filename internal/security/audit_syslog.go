@@ -0,0 +1,29 @@
+//go:build !windows
+// +build !windows
+
+package security
+
+import "log/syslog"
+
+// syslogAuditSink sends audit entries to the local syslog daemon
+type syslogAuditSink struct {
+	writer *syslog.Writer
+}
+
+// newSystemAuditSink connects to syslog under the authpriv facility, which
+// syslog daemons keep readable only by administrators
+func newSystemAuditSink() (auditSink, error) {
+	writer, err := syslog.New(syslog.LOG_AUTHPRIV|syslog.LOG_INFO, "supershell")
+	if err != nil {
+		return nil, err
+	}
+	return &syslogAuditSink{writer: writer}, nil
+}
+
+func (s *syslogAuditSink) Write(entry AuditEntry) error {
+	return s.writer.Info(entry.String())
+}
+
+func (s *syslogAuditSink) Close() error {
+	return s.writer.Close()
+}
@@ -0,0 +1,30 @@
+package security
+
+import "golang.org/x/sys/windows/svc/eventlog"
+
+// auditEventID is the Event Log ID of audit entries
+const auditEventID = 1000
+
+// eventLogAuditSink writes audit entries to the Application event log
+type eventLogAuditSink struct {
+	log *eventlog.Log
+}
+
+// newSystemAuditSink opens the Application event log under the SuperShell
+// source. The source needn't be registered, though Event Viewer then prefixes
+// entries with a note that its message file is missing.
+func newSystemAuditSink() (auditSink, error) {
+	log, err := eventlog.Open("SuperShell")
+	if err != nil {
+		return nil, err
+	}
+	return &eventLogAuditSink{log: log}, nil
+}
+
+func (s *eventLogAuditSink) Write(entry AuditEntry) error {
+	return s.log.Info(auditEventID, entry.String())
+}
+
+func (s *eventLogAuditSink) Close() error {
+	return s.log.Close()
+}
@@ -0,0 +1,358 @@
+package security
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// Credential types understood by the commands that consume stored credentials
+const (
+	CredentialTypeKey = "key" // single secret such as a fastcp transfer key
+	CredentialTypeAWS = "aws" // access_key, secret_key and optional region/endpoint
+	CredentialTypeSSH = "ssh" // username plus password and/or key_file
+)
+
+// credentialStoreVersion is bumped whenever the on-disk format changes. Version 1
+// sealed entries without their name as additional data.
+const credentialStoreVersion = 2
+
+// verifierPlaintext is encrypted with the master key so a wrong password is detected on open
+const verifierPlaintext = "supershell-credential-store"
+
+// Credential is a named set of secret fields
+type Credential struct {
+	Name    string            `json:"name"`
+	Type    string            `json:"type"`
+	Fields  map[string]string `json:"fields"`
+	Created time.Time         `json:"created"`
+	Updated time.Time         `json:"updated"`
+}
+
+// Get returns a field value, or an empty string when the field is not set
+func (c *Credential) Get(field string) string {
+	if c == nil || c.Fields == nil {
+		return ""
+	}
+	return c.Fields[field]
+}
+
+// Secret returns the primary secret of the credential regardless of its type
+func (c *Credential) Secret() string {
+	for _, field := range []string{"secret", "key", "secret_key", "password"} {
+		if value := c.Get(field); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// CredentialInfo is the non-secret metadata of a stored credential
+type CredentialInfo struct {
+	Name    string
+	Type    string
+	Fields  []string
+	Created time.Time
+	Updated time.Time
+}
+
+// credentialFile is the on-disk representation of the store
+type credentialFile struct {
+	Version  int                        `json:"version"`
+	Salt     []byte                     `json:"salt"`
+	Verifier []byte                     `json:"verifier"`
+	Entries  map[string]credentialEntry `json:"entries"`
+}
+
+// credentialEntry holds one encrypted credential; only name and type are stored in clear
+type credentialEntry struct {
+	Type       string    `json:"type"`
+	Fields     []string  `json:"fields"`
+	Ciphertext []byte    `json:"ciphertext"`
+	Created    time.Time `json:"created"`
+	Updated    time.Time `json:"updated"`
+}
+
+// CredentialStore saves named credentials encrypted at rest with AES-256-GCM.
+// The encryption key is derived from a master password with scrypt.
+type CredentialStore struct {
+	mu   sync.Mutex
+	path string
+	key  []byte
+	data *credentialFile
+}
+
+// DefaultCredentialStorePath returns the default location of the credential store
+func DefaultCredentialStorePath() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".supershell", "credentials.json")
+}
+
+// OpenCredentialStore opens (or initializes) the store at path using the master password
+func OpenCredentialStore(path, masterPassword string) (*CredentialStore, error) {
+	if masterPassword == "" {
+		return nil, fmt.Errorf("master password cannot be empty")
+	}
+
+	store := &CredentialStore{path: path}
+
+	raw, err := ioutil.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		salt := make([]byte, 16)
+		if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+			return nil, fmt.Errorf("failed to generate salt: %w", err)
+		}
+		store.data = &credentialFile{
+			Version: credentialStoreVersion,
+			Salt:    salt,
+			Entries: make(map[string]credentialEntry),
+		}
+		if store.key, err = deriveStoreKey(masterPassword, salt); err != nil {
+			return nil, err
+		}
+		if store.data.Verifier, err = store.seal("", []byte(verifierPlaintext)); err != nil {
+			return nil, err
+		}
+		return store, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to read credential store: %w", err)
+	}
+
+	data := &credentialFile{}
+	if err := json.Unmarshal(raw, data); err != nil {
+		return nil, fmt.Errorf("credential store is corrupted: %w", err)
+	}
+	if data.Version > credentialStoreVersion {
+		return nil, fmt.Errorf("credential store version %d is newer than supported version %d", data.Version, credentialStoreVersion)
+	}
+	if data.Entries == nil {
+		data.Entries = make(map[string]credentialEntry)
+	}
+	store.data = data
+
+	if store.key, err = deriveStoreKey(masterPassword, data.Salt); err != nil {
+		return nil, err
+	}
+	if plain, err := store.open("", data.Verifier); err != nil || string(plain) != verifierPlaintext {
+		return nil, fmt.Errorf("invalid master password")
+	}
+	if data.Version < credentialStoreVersion {
+		if err := store.upgrade(); err != nil {
+			return nil, err
+		}
+	}
+
+	return store, nil
+}
+
+// Path returns the file backing the store
+func (s *CredentialStore) Path() string {
+	return s.path
+}
+
+// Add stores a credential, replacing any existing credential with the same name
+func (s *CredentialStore) Add(cred *Credential) error {
+	if cred == nil || cred.Name == "" {
+		return fmt.Errorf("credential name cannot be empty")
+	}
+	if len(cred.Fields) == 0 {
+		return fmt.Errorf("credential '%s' has no fields", cred.Name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	plain, err := json.Marshal(cred.Fields)
+	if err != nil {
+		return err
+	}
+	sealed, err := s.seal(cred.Name, plain)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	entry := credentialEntry{
+		Type:       cred.Type,
+		Fields:     sortedKeys(cred.Fields),
+		Ciphertext: sealed,
+		Created:    now,
+		Updated:    now,
+	}
+	if existing, ok := s.data.Entries[cred.Name]; ok {
+		entry.Created = existing.Created
+	}
+	s.data.Entries[cred.Name] = entry
+
+	return s.save()
+}
+
+// Get decrypts and returns the named credential
+func (s *CredentialStore) Get(name string) (*Credential, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.data.Entries[name]
+	if !ok {
+		return nil, fmt.Errorf("credential '%s' not found", name)
+	}
+
+	plain, err := s.open(name, entry.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt credential '%s': %w", name, err)
+	}
+
+	fields := make(map[string]string)
+	if err := json.Unmarshal(plain, &fields); err != nil {
+		return nil, fmt.Errorf("credential '%s' is corrupted: %w", name, err)
+	}
+
+	return &Credential{
+		Name:    name,
+		Type:    entry.Type,
+		Fields:  fields,
+		Created: entry.Created,
+		Updated: entry.Updated,
+	}, nil
+}
+
+// List returns metadata for all stored credentials sorted by name
+func (s *CredentialStore) List() []CredentialInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	infos := make([]CredentialInfo, 0, len(s.data.Entries))
+	for name, entry := range s.data.Entries {
+		infos = append(infos, CredentialInfo{
+			Name:    name,
+			Type:    entry.Type,
+			Fields:  entry.Fields,
+			Created: entry.Created,
+			Updated: entry.Updated,
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+
+	return infos
+}
+
+// Remove deletes the named credential
+func (s *CredentialStore) Remove(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.data.Entries[name]; !ok {
+		return fmt.Errorf("credential '%s' not found", name)
+	}
+	delete(s.data.Entries, name)
+
+	return s.save()
+}
+
+// save writes the store atomically with owner-only permissions
+func (s *CredentialStore) save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create credential store directory: %w", err)
+	}
+
+	raw, err := json.MarshalIndent(s.data, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := s.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, raw, 0600); err != nil {
+		return fmt.Errorf("failed to write credential store: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// upgrade reseals the entries of a version 1 store with their names, so an
+// entry can't be passed off as another by renaming it in the file. The store is
+// rewritten on the next change.
+func (s *CredentialStore) upgrade() error {
+	for name, entry := range s.data.Entries {
+		// Sealing with no additional data is the same as with an empty name
+		plain, err := s.open("", entry.Ciphertext)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt credential '%s': %w", name, err)
+		}
+		if entry.Ciphertext, err = s.seal(name, plain); err != nil {
+			return err
+		}
+		s.data.Entries[name] = entry
+	}
+	s.data.Version = credentialStoreVersion
+	return nil
+}
+
+// seal encrypts plaintext and prepends the random nonce. The credential's name
+// is authenticated with it, so open fails for any other name.
+func (s *CredentialStore) seal(name string, plaintext []byte) ([]byte, error) {
+	gcm, err := newStoreCipher(s.key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	return gcm.Seal(nonce, nonce, plaintext, []byte(name)), nil
+}
+
+// open decrypts data that seal produced for name
+func (s *CredentialStore) open(name string, sealed []byte) ([]byte, error) {
+	gcm, err := newStoreCipher(s.key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, []byte(name))
+}
+
+// deriveStoreKey derives the 32-byte store key from the master password
+func deriveStoreKey(password string, salt []byte) ([]byte, error) {
+	key, err := scrypt.Key([]byte(password), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	return key, nil
+}
+
+// newStoreCipher creates the AES-256-GCM cipher for the store
+func newStoreCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// sortedKeys returns the map keys in sorted order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
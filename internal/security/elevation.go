@@ -0,0 +1,193 @@
+package security
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"unicode/utf16"
+)
+
+var (
+	elevatedOnce sync.Once
+	elevated     bool
+)
+
+// IsElevated reports whether the shell runs as root or as an elevated
+// administrator. The answer is worked out once, privileges don't change while
+// the process runs.
+func IsElevated() bool {
+	elevatedOnce.Do(func() {
+		if runtime.GOOS != "windows" {
+			elevated = os.Geteuid() == 0
+			return
+		}
+
+		script := `$principal = New-Object Security.Principal.WindowsPrincipal([Security.Principal.WindowsIdentity]::GetCurrent())
+		if ($principal.IsInRole([Security.Principal.WindowsBuiltInRole]::Administrator)) { 'IS_ADMIN' } else { 'NOT_ADMIN' }`
+		output, _ := exec.Command("powershell", "-NoProfile", "-Command", script).Output()
+		elevated = strings.Contains(string(output), "IS_ADMIN")
+	})
+	return elevated
+}
+
+// ElevationMethod names how RunElevated gains privileges here: UAC on
+// Windows, otherwise sudo or else pkexec. It fails when none is available.
+func ElevationMethod() (string, error) {
+	if runtime.GOOS == "windows" {
+		return "UAC", nil
+	}
+	for _, tool := range []string{"sudo", "pkexec"} {
+		if _, err := exec.LookPath(tool); err == nil {
+			return tool, nil
+		}
+	}
+	return "", fmt.Errorf("neither sudo nor pkexec is installed")
+}
+
+// RunElevated runs a SuperShell command line in a new elevated instance of this
+// executable (`supershell -c <line>`) in the current directory and returns its
+// exit code; an empty line starts an interactive elevated shell instead. On
+// Unix the instance shares the given streams, so its output is streamed and it
+// can prompt. On Windows it runs in its own console behind the UAC prompt and
+// the output of a command line is copied to stdout and stderr once it exits.
+func RunElevated(ctx context.Context, line string, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	method, err := ElevationMethod()
+	if err != nil {
+		return -1, err
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return -1, fmt.Errorf("failed to locate the shell executable: %w", err)
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return -1, err
+	}
+
+	if method == "UAC" {
+		if line == "" {
+			return runElevatedConsole(ctx, exe, dir)
+		}
+		return runElevatedWindows(ctx, exe, dir, line, stdout, stderr)
+	}
+
+	shellArgs := []string{exe}
+	if line != "" {
+		shellArgs = append(shellArgs, "-c", line)
+	}
+	var cmd *exec.Cmd
+	if method == "sudo" {
+		cmd = exec.CommandContext(ctx, "sudo", shellArgs...)
+	} else {
+		// pkexec starts in root's home directory, so change back first
+		script := []string{"/bin/sh", "-c", `cd "$1" && shift && exec "$@"`, "sh", dir}
+		cmd = exec.CommandContext(ctx, "pkexec", append(script, shellArgs...)...)
+	}
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = stdin, stdout, stderr
+	return exitCode(cmd.Run())
+}
+
+// runElevatedWindows starts an elevated PowerShell through UAC which runs the
+// shell with its output sent to temporary files, then copies those back
+func runElevatedWindows(ctx context.Context, exe, dir, line string, stdout, stderr io.Writer) (int, error) {
+	tmp, err := ioutil.TempDir("", "supershell-elevated")
+	if err != nil {
+		return -1, err
+	}
+	defer os.RemoveAll(tmp)
+	outFile := filepath.Join(tmp, "stdout")
+	errFile := filepath.Join(tmp, "stderr")
+
+	// The elevated side is encoded so none of its quoting has to survive the
+	// Start-Process command line
+	inner := fmt.Sprintf("$p = Start-Process -FilePath %s -ArgumentList %s -WorkingDirectory %s -NoNewWindow -Wait -PassThru -RedirectStandardOutput %s -RedirectStandardError %s\nexit $p.ExitCode",
+		powerShellQuote(exe), powerShellQuote("-c "+windowsArg(line)), powerShellQuote(dir),
+		powerShellQuote(outFile), powerShellQuote(errFile))
+	outer := fmt.Sprintf("$p = Start-Process -FilePath powershell -ArgumentList '-NoProfile','-EncodedCommand','%s' -Verb RunAs -Wait -PassThru\nexit $p.ExitCode",
+		encodePowerShell(inner))
+
+	code, err := exitCode(exec.CommandContext(ctx, "powershell", "-NoProfile", "-NonInteractive", "-Command", outer).Run())
+	for file, w := range map[string]io.Writer{outFile: stdout, errFile: stderr} {
+		if data, readErr := ioutil.ReadFile(file); readErr == nil {
+			w.Write(data)
+		}
+	}
+	if err != nil {
+		return code, fmt.Errorf("elevation failed or was declined: %w", err)
+	}
+	return code, nil
+}
+
+// runElevatedConsole opens an interactive elevated shell in a console of its
+// own through UAC, which carries on independently of this one
+func runElevatedConsole(ctx context.Context, exe, dir string) (int, error) {
+	script := fmt.Sprintf("Start-Process -FilePath %s -WorkingDirectory %s -Verb RunAs",
+		powerShellQuote(exe), powerShellQuote(dir))
+	code, err := exitCode(exec.CommandContext(ctx, "powershell", "-NoProfile", "-NonInteractive", "-Command", script).Run())
+	if err == nil && code != 0 {
+		err = fmt.Errorf("exit status %d", code)
+	}
+	if err != nil {
+		return code, fmt.Errorf("elevation failed or was declined: %w", err)
+	}
+	return 0, nil
+}
+
+// exitCode turns the error of a finished process into its exit code, leaving
+// only failures to start or wait for it as errors
+func exitCode(err error) (int, error) {
+	if err == nil {
+		return 0, nil
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return exitErr.ExitCode(), nil
+	}
+	return -1, err
+}
+
+// powerShellQuote quotes s as a literal PowerShell string
+func powerShellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
+
+// encodePowerShell encodes a script for powershell -EncodedCommand
+func encodePowerShell(script string) string {
+	units := utf16.Encode([]rune(script))
+	data := make([]byte, 0, len(units)*2)
+	for _, unit := range units {
+		data = append(data, byte(unit), byte(unit>>8))
+	}
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+// windowsArg quotes s as one argument of a Windows command line, the way the
+// C runtime and Go's os.Args split them
+func windowsArg(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	slashes := 0
+	for _, r := range s {
+		switch r {
+		case '\\':
+			slashes++
+			continue
+		case '"':
+			b.WriteString(strings.Repeat(`\`, slashes*2+1))
+		default:
+			b.WriteString(strings.Repeat(`\`, slashes))
+		}
+		slashes = 0
+		b.WriteRune(r)
+	}
+	b.WriteString(strings.Repeat(`\`, slashes*2))
+	b.WriteByte('"')
+	return b.String()
+}
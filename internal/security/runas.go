@@ -0,0 +1,59 @@
+package security
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Account is the user RunAs runs a command line as
+type Account struct {
+	User   string
+	Domain string
+	// Password is the account's own password, which Windows needs to log it
+	// on; sudo asks for the caller's instead and ignores it
+	Password string
+}
+
+// ParseAccount splits a DOMAIN\user or user@domain name into its user and
+// domain. A plain name has no domain, meaning the local machine on Windows.
+func ParseAccount(name string) Account {
+	if i := strings.IndexByte(name, '\\'); i >= 0 {
+		return Account{User: name[i+1:], Domain: name[:i]}
+	}
+	if i := strings.LastIndexByte(name, '@'); i > 0 {
+		return Account{User: name[:i], Domain: name[i+1:]}
+	}
+	return Account{User: name}
+}
+
+// String returns the account the way it was written, DOMAIN\user or user
+func (a Account) String() string {
+	if a.Domain == "" {
+		return a.User
+	}
+	return a.Domain + `\` + a.User
+}
+
+// RunAs runs a SuperShell command line in a new instance of this executable
+// (`supershell -c <line>`) as another user, in the current directory, and
+// returns its exit code. The instance shares the given streams, so its output
+// is streamed. On Windows the account is logged on with its password through
+// CreateProcessWithLogonW; elsewhere sudo -u switches to it. A rejected logon
+// is reported as a security error saying why.
+func RunAs(ctx context.Context, account Account, line string, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	if account.User == "" {
+		return -1, fmt.Errorf("no user to run as")
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return -1, fmt.Errorf("failed to locate the shell executable: %w", err)
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return -1, err
+	}
+	return runAsUser(ctx, account, exe, dir, line, stdin, stdout, stderr)
+}
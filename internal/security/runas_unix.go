@@ -0,0 +1,114 @@
+//go:build !windows
+// +build !windows
+
+package security
+
+import (
+	"context"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"suppercommand/pkg/errors"
+)
+
+// RunAsNeedsPassword reports whether RunAs needs the target account's
+// password. sudo asks for the caller's own when its policy wants one.
+const RunAsNeedsPassword = false
+
+// RunAsMethod names how RunAs switches user here, failing when it can't
+func RunAsMethod() (string, error) {
+	if _, err := exec.LookPath("sudo"); err != nil {
+		return "", errors.NewNotFoundError("sudo is not installed")
+	}
+	return "sudo", nil
+}
+
+// runAsUser runs the shell through sudo -u, watching sudo's own complaints on
+// stderr to tell a refused switch from the command failing
+func runAsUser(ctx context.Context, account Account, exe, dir, line string, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	if _, err := RunAsMethod(); err != nil {
+		return -1, err
+	}
+	cmd := exec.CommandContext(ctx, "sudo", "-u", account.User, "--", exe, "-c", line)
+	cmd.Dir = dir
+	watch := &sudoWatcher{w: stderr}
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = stdin, stdout, watch
+
+	code, err := exitCode(cmd.Run())
+	if err != nil {
+		return code, err
+	}
+	if code != 0 {
+		if failure := sudoFailure(account, watch.complaints()); failure != nil {
+			return code, failure
+		}
+	}
+	return code, nil
+}
+
+// sudoFailure turns what sudo printed when it refused to run the command into
+// an error, or nil when sudo didn't complain and the failure was the command's
+func sudoFailure(account Account, complaints []string) error {
+	for _, complaint := range complaints {
+		lower := strings.ToLower(complaint)
+		switch {
+		case strings.Contains(lower, "incorrect password"), strings.Contains(lower, "a password is required"),
+			strings.Contains(lower, "authentication failure"), strings.Contains(lower, "no password was provided"):
+			return errors.NewSecurityError("authentication failed: sudo did not accept your password to run as %s", account.User)
+		case strings.Contains(lower, "unknown user"):
+			return errors.NewNotFoundError("unknown user '%s'", account.User)
+		case strings.Contains(lower, "not in the sudoers"), strings.Contains(lower, "is not allowed to"),
+			strings.Contains(lower, "may not run sudo"):
+			return errors.NewSecurityError("sudo does not allow you to run commands as %s", account.User)
+		}
+	}
+	return nil
+}
+
+// sudoWatcher passes stderr through while keeping the lines sudo itself
+// printed, which start with "sudo:"
+type sudoWatcher struct {
+	w io.Writer
+
+	mu      sync.Mutex
+	partial string
+	lines   []string
+}
+
+func (s *sudoWatcher) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	text := s.partial + string(p)
+	for {
+		i := strings.IndexByte(text, '\n')
+		if i < 0 {
+			break
+		}
+		s.keep(text[:i])
+		text = text[i+1:]
+	}
+	// Only the start of a line matters, so a long one isn't held on to
+	if len(text) > 256 {
+		text = text[:256]
+	}
+	s.partial = text
+	s.mu.Unlock()
+	return s.w.Write(p)
+}
+
+// keep records a line when it came from sudo
+func (s *sudoWatcher) keep(line string) {
+	if line = strings.TrimSpace(line); strings.HasPrefix(line, "sudo:") && len(s.lines) < 16 {
+		s.lines = append(s.lines, line)
+	}
+}
+
+// complaints returns what sudo printed, including an unfinished last line
+func (s *sudoWatcher) complaints() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keep(s.partial)
+	s.partial = ""
+	return append([]string(nil), s.lines...)
+}
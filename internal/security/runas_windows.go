@@ -0,0 +1,193 @@
+package security
+
+import (
+	"context"
+	"io"
+	"os"
+	"syscall"
+	"unsafe"
+
+	"suppercommand/pkg/errors"
+
+	"golang.org/x/sys/windows"
+)
+
+// RunAsNeedsPassword reports whether RunAs needs the target account's
+// password. Windows logs the account on, so it does.
+const RunAsNeedsPassword = true
+
+// createProcessWithLogonW isn't wrapped by x/sys/windows
+var createProcessWithLogonW = windows.NewLazySystemDLL("advapi32.dll").NewProc("CreateProcessWithLogonW")
+
+// logonWithProfile loads the account's profile, so HKCU and %APPDATA% are its own
+const logonWithProfile = 0x00000001
+
+// RunAsMethod names how RunAs switches user here, failing when it can't
+func RunAsMethod() (string, error) {
+	if err := createProcessWithLogonW.Find(); err != nil {
+		return "", err
+	}
+	return "CreateProcessWithLogonW", nil
+}
+
+// runAsUser logs the account on and starts the shell under it without a
+// console of its own, its standard streams piped back to ours
+func runAsUser(ctx context.Context, account Account, exe, dir, line string, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	domain := account.Domain
+	if domain == "" {
+		domain = "."
+	}
+	params := make([]*uint16, 0, 5)
+	for _, s := range []string{account.User, domain, account.Password, windowsArg(exe) + " -c " + windowsArg(line), dir} {
+		p, err := windows.UTF16PtrFromString(s)
+		if err != nil {
+			return -1, err
+		}
+		params = append(params, p)
+	}
+
+	child, parent, err := runAsPipes(stdin)
+	if err != nil {
+		return -1, err
+	}
+	defer closeHandles(child[:])
+
+	startup := windows.StartupInfo{
+		Flags:     windows.STARTF_USESTDHANDLES,
+		StdInput:  child[0],
+		StdOutput: child[1],
+		StdErr:    child[2],
+	}
+	startup.Cb = uint32(unsafe.Sizeof(startup))
+	var process windows.ProcessInformation
+	r, _, callErr := createProcessWithLogonW.Call(
+		uintptr(unsafe.Pointer(params[0])), uintptr(unsafe.Pointer(params[1])), uintptr(unsafe.Pointer(params[2])),
+		logonWithProfile, 0, uintptr(unsafe.Pointer(params[3])),
+		windows.CREATE_UNICODE_ENVIRONMENT|windows.CREATE_NO_WINDOW, 0, uintptr(unsafe.Pointer(params[4])),
+		uintptr(unsafe.Pointer(&startup)), uintptr(unsafe.Pointer(&process)))
+	if r == 0 {
+		for _, f := range parent {
+			if f != nil {
+				f.Close()
+			}
+		}
+		return -1, logonFailure(account, callErr)
+	}
+	defer windows.CloseHandle(process.Process)
+	defer windows.CloseHandle(process.Thread)
+
+	// Our copies of the child's ends are closed so its output ends when it exits
+	closeHandles(child[:])
+	child = [3]windows.Handle{}
+	if parent[0] != nil {
+		go func() {
+			io.Copy(parent[0], stdin)
+			parent[0].Close()
+		}()
+	}
+	done := make(chan struct{}, 2)
+	for i, w := range []io.Writer{stdout, stderr} {
+		go func(r *os.File, w io.Writer) {
+			io.Copy(w, r)
+			r.Close()
+			done <- struct{}{}
+		}(parent[i+1], w)
+	}
+
+	// The process is polled rather than waited on so a cancel can end it
+	for {
+		event, err := windows.WaitForSingleObject(process.Process, 100)
+		if err != nil {
+			return -1, err
+		}
+		if event != uint32(windows.WAIT_TIMEOUT) {
+			break
+		}
+		if ctx.Err() != nil {
+			windows.TerminateProcess(process.Process, 1)
+		}
+	}
+	<-done
+	<-done
+
+	var code uint32
+	if err := windows.GetExitCodeProcess(process.Process, &code); err != nil {
+		return -1, err
+	}
+	return int(code), nil
+}
+
+// runAsPipes creates the child's standard handles, which it inherits, and our
+// ends of them: a writer feeding its input, or nil when it reads our own
+// stdin file directly, and readers of its output and error
+func runAsPipes(stdin io.Reader) ([3]windows.Handle, [3]*os.File, error) {
+	var child [3]windows.Handle
+	var parent [3]*os.File
+	fail := func(err error) ([3]windows.Handle, [3]*os.File, error) {
+		closeHandles(child[:])
+		for _, f := range parent {
+			if f != nil {
+				f.Close()
+			}
+		}
+		return [3]windows.Handle{}, [3]*os.File{}, err
+	}
+
+	current := windows.CurrentProcess()
+	if file, ok := stdin.(*os.File); ok {
+		if err := windows.DuplicateHandle(current, windows.Handle(file.Fd()), current, &child[0], 0, true, windows.DUPLICATE_SAME_ACCESS); err != nil {
+			return fail(err)
+		}
+	}
+
+	inherit := &windows.SecurityAttributes{InheritHandle: 1}
+	inherit.Length = uint32(unsafe.Sizeof(*inherit))
+	for i := range child {
+		if i == 0 && child[0] != 0 {
+			continue
+		}
+		var r, w windows.Handle
+		if err := windows.CreatePipe(&r, &w, inherit, 0); err != nil {
+			return fail(err)
+		}
+		// Only the child's end is inherited
+		ours, theirs := r, w
+		if i == 0 {
+			ours, theirs = w, r
+		}
+		windows.SetHandleInformation(ours, windows.HANDLE_FLAG_INHERIT, 0)
+		child[i] = theirs
+		parent[i] = os.NewFile(uintptr(ours), "runas")
+	}
+	return child, parent, nil
+}
+
+// closeHandles closes the handles that are set
+func closeHandles(handles []windows.Handle) {
+	for _, h := range handles {
+		if h != 0 {
+			windows.CloseHandle(h)
+		}
+	}
+}
+
+// logonFailure explains why Windows refused to log the account on
+func logonFailure(account Account, err error) error {
+	errno, ok := err.(syscall.Errno)
+	if !ok {
+		return errors.Wrap(err, "failed to start the shell as %s", account)
+	}
+	switch errno {
+	case windows.ERROR_LOGON_FAILURE:
+		return errors.NewSecurityError("authentication failed for %s: the user name or password is incorrect", account)
+	case windows.ERROR_ACCOUNT_DISABLED:
+		return errors.NewSecurityError("authentication failed for %s: the account is disabled", account)
+	case windows.ERROR_ACCOUNT_LOCKED_OUT:
+		return errors.NewSecurityError("authentication failed for %s: the account is locked out", account)
+	case windows.ERROR_PASSWORD_EXPIRED, windows.ERROR_PASSWORD_MUST_CHANGE:
+		return errors.NewSecurityError("authentication failed for %s: the password has expired and must be changed", account)
+	case windows.ERROR_ACCOUNT_RESTRICTION, windows.ERROR_LOGON_TYPE_NOT_GRANTED:
+		return errors.NewSecurityError("authentication failed for %s: the account may not log on here (%v)", account, errno)
+	}
+	return errors.Wrap(err, "failed to start the shell as %s", account)
+}
@@ -0,0 +1,102 @@
+package security
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"suppercommand/pkg/errors"
+)
+
+// Script guard modes, as set by security.scripts.guard
+const (
+	ScriptGuardRun     = "run"
+	ScriptGuardConfirm = "confirm"
+	ScriptGuardBlock   = "block"
+)
+
+// ScriptGuard screens the commands a script runs against a list of guarded
+// ones, blocking them or asking before each runs, and remembers what it
+// stopped so the script's summary can report it
+type ScriptGuard struct {
+	mode    string
+	rules   [][]string
+	confirm func(prompt string) bool
+
+	mu      sync.Mutex
+	blocked []string
+}
+
+// NewScriptGuard creates a guard for the guarded commands in the given mode.
+// Each guarded entry is a command name, or a name and subcommand such as
+// "firewall disable".
+func NewScriptGuard(mode string, guarded []string) *ScriptGuard {
+	guard := &ScriptGuard{mode: mode, confirm: Confirm}
+	for _, entry := range guarded {
+		if words := strings.Fields(strings.ToLower(entry)); len(words) > 0 {
+			guard.rules = append(guard.rules, words)
+		}
+	}
+	return guard
+}
+
+// SetConfirm replaces the terminal prompt used in confirm mode
+func (g *ScriptGuard) SetConfirm(confirm func(prompt string) bool) {
+	g.confirm = confirm
+}
+
+// Check returns a security error when the command is guarded and either the
+// guard blocks or the user declines to run it
+func (g *ScriptGuard) Check(name string, args []string) error {
+	rule := g.match(name, args)
+	if rule == "" || g.mode == ScriptGuardRun {
+		return nil
+	}
+	line := strings.TrimSpace(name + " " + strings.Join(args, " "))
+	if g.mode == ScriptGuardConfirm && g.confirm(fmt.Sprintf("⚠️  The script wants to run '%s'. Run it? [y/N] ", line)) {
+		return nil
+	}
+
+	g.mu.Lock()
+	g.blocked = append(g.blocked, line)
+	g.mu.Unlock()
+	if g.mode == ScriptGuardConfirm {
+		return errors.NewSecurityError("'%s' was not confirmed", line)
+	}
+	return errors.NewSecurityError("'%s' is blocked by the script guard (guarded: %s)", line, rule)
+}
+
+// Blocked returns the command lines stopped so far, in the order they were tried
+func (g *ScriptGuard) Blocked() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]string(nil), g.blocked...)
+}
+
+// match returns the guarded entry a command falls under, or "". Options are
+// skipped when matching subcommands, so "user --json disable" is still
+// "user disable".
+func (g *ScriptGuard) match(name string, args []string) string {
+	var words []string
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			words = append(words, strings.ToLower(arg))
+		}
+	}
+	for _, rule := range g.rules {
+		if rule[0] != strings.ToLower(name) || len(words) < len(rule)-1 {
+			continue
+		}
+		matched := true
+		for i, word := range rule[1:] {
+			if words[i] != word {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return strings.Join(rule, " ")
+		}
+	}
+	return ""
+}
@@ -0,0 +1,166 @@
+package security
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// MasterPasswordEnv names the environment variable that supplies the credential store password
+const MasterPasswordEnv = "SUPERSHELL_MASTER_PASSWORD"
+
+// stdinReader is shared so consecutive prompts don't lose buffered input
+var (
+	stdinReader = bufio.NewReader(os.Stdin)
+	stdinMu     sync.Mutex
+)
+
+// readStdinLine reads the next line of stdinReader, one reader at a time
+func readStdinLine() (string, error) {
+	stdinMu.Lock()
+	defer stdinMu.Unlock()
+	return stdinReader.ReadString('\n')
+}
+
+// ReadSecret prompts on the terminal and reads a line without echoing it where supported
+func ReadSecret(prompt string) (string, error) {
+	fmt.Print(prompt)
+
+	// Disable echo on Unix terminals; on Windows the console keeps echoing
+	echoDisabled := false
+	if runtime.GOOS != "windows" {
+		cmd := exec.Command("stty", "-echo")
+		cmd.Stdin = os.Stdin
+		echoDisabled = cmd.Run() == nil
+	}
+
+	line, err := readStdinLine()
+
+	if echoDisabled {
+		cmd := exec.Command("stty", "echo")
+		cmd.Stdin = os.Stdin
+		cmd.Run()
+		fmt.Println()
+	}
+
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// ReadLine prompts on the terminal and reads a single echoed line
+func ReadLine(prompt string) (string, error) {
+	fmt.Print(prompt)
+
+	line, err := readStdinLine()
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// Confirm asks a yes/no question on the terminal; anything but y or yes,
+// including no input at all, is a no
+func Confirm(prompt string) bool {
+	answer, err := ReadLine(prompt)
+	if err != nil {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// StopOnEnter returns a channel closed when Enter is pressed or ctx is done,
+// for stopping an open-ended run. Without a terminal there is no stop key and
+// only ctx closes it.
+func StopOnEnter(ctx context.Context) <-chan struct{} {
+	stop := make(chan struct{})
+	read := pendingEnter()
+	go func() {
+		select {
+		case <-read.done:
+			if read.err != nil {
+				<-ctx.Done()
+			}
+		case <-ctx.Done():
+		}
+		close(stop)
+	}()
+	return stop
+}
+
+// enterRead is a read of one line from stdin, there for stop keys to wait on
+type enterRead struct {
+	done chan struct{}
+	err  error
+}
+
+// enterPending is the read stop keys are waiting on, if any
+var (
+	enterMu      sync.Mutex
+	enterPending *enterRead
+)
+
+// pendingEnter returns the read stop keys wait on, starting one when none is
+// pending. A read left behind by a run that has ended serves the next run
+// rather than racing it for the line.
+func pendingEnter() *enterRead {
+	enterMu.Lock()
+	defer enterMu.Unlock()
+	if enterPending == nil {
+		read := &enterRead{done: make(chan struct{})}
+		enterPending = read
+		go func() {
+			_, err := ReadLine("")
+			enterMu.Lock()
+			read.err = err
+			enterPending = nil
+			enterMu.Unlock()
+			close(read.done)
+		}()
+	}
+	return enterPending
+}
+
+// CancelOnEnter returns a copy of ctx that is cancelled when Enter is pressed,
+// for runs that stop through their context
+func CancelOnEnter(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	stop := StopOnEnter(ctx)
+	go func() {
+		<-stop
+		cancel()
+	}()
+	return ctx, cancel
+}
+
+// MasterPassword returns the credential store password from the environment or the terminal
+func MasterPassword() (string, error) {
+	if password := os.Getenv(MasterPasswordEnv); password != "" {
+		return password, nil
+	}
+	return ReadSecret("🔑 Credential store master password: ")
+}
+
+// LoadCredential opens the default credential store and returns the named credential
+func LoadCredential(name string) (*Credential, error) {
+	password, err := MasterPassword()
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := OpenCredentialStore(DefaultCredentialStorePath(), password)
+	if err != nil {
+		return nil, err
+	}
+
+	return store.Get(name)
+}
@@ -2,6 +2,7 @@ package security
 
 import (
 	"regexp"
+	"runtime"
 	"strings"
 
 	"suppercommand/pkg/errors"
@@ -121,13 +122,20 @@ func (v *BasicValidator) SanitizeInput(input string) (string, error) {
 
 // CheckPrivileges checks if a command can be executed with current privileges
 func (v *BasicValidator) CheckPrivileges(cmd Command) (*PrivilegeInfo, error) {
-	// This is a placeholder implementation
-	// Real implementation would check actual system privileges
+	required := PrivilegeLevelUser
+	if cmd.RequiresElevation() {
+		required = PrivilegeLevelRoot
+		if runtime.GOOS == "windows" {
+			required = PrivilegeLevelAdmin
+		}
+	}
+	_, noMethod := ElevationMethod()
+
 	return &PrivilegeInfo{
-		IsElevated:    false,
-		CanElevate:    true,
-		RequiredLevel: PrivilegeLevelUser,
-		Platform:      "unknown",
+		IsElevated:    IsElevated(),
+		CanElevate:    noMethod == nil,
+		RequiredLevel: required,
+		Platform:      runtime.GOOS,
 		Capabilities:  []string{},
 	}, nil
 }
@@ -0,0 +1,109 @@
+package shell
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/monitoring"
+
+	"github.com/fatih/color"
+)
+
+// executeChain runs the commands of a line joined by &&, || and ; in turn,
+// skipping those their operator rules out, and starts the lists ended by & as
+// background jobs. The output of every command that ran is collected in order,
+// with the errors of all but the last inline, as are their diagnostics, and the
+// exit code is the last one that ran; starting a job counts as success.
+func (e *Executor) executeChain(ctx context.Context, steps []commands.ChainStep, startTime time.Time) *ExecutionResult {
+	chain := &ExecutionResult{}
+	var output, stderr strings.Builder
+
+	for i := 0; i < len(steps); i++ {
+		step := steps[i]
+		if step.Background {
+			// The list's && and || run inside the job, on the job's exit codes
+			end := i + 1
+			for end < len(steps) && steps[end].Operator != commands.ChainThen {
+				end++
+			}
+			job := e.startJob(ctx, steps[i:end])
+			output.WriteString(color.New(color.FgHiBlack).Sprintf("[%d] started: %s\n", job.ID, job.Line))
+			chain.Error = nil
+			chain.ExitCode = 0
+			i = end - 1
+			continue
+		}
+		if !step.ShouldRun(chain.ExitCode) {
+			e.logger.Debug("Skipping chained command",
+				monitoring.Field{Key: "command", Value: step.Line},
+				monitoring.Field{Key: "operator", Value: string(step.Operator)})
+			continue
+		}
+		// Ctrl+C stops the rest of the chain, not just the running command
+		if ctx.Err() != nil {
+			chain.Error = ctx.Err()
+			chain.ExitCode = 1
+			break
+		}
+
+		result, err := e.Execute(ctx, step.Line)
+		if result == nil {
+			result = &ExecutionResult{ExitCode: 1}
+		}
+		if err != nil {
+			result.Error = err
+			result.ExitCode = 1
+		}
+
+		if result.Output != "" {
+			output.WriteString(result.Output)
+			if !strings.HasSuffix(result.Output, "\n") {
+				output.WriteString("\n")
+			}
+		}
+		stderr.WriteString(result.Stderr)
+		if i < len(steps)-1 && result.Error != nil {
+			output.WriteString(commands.FormatError(result.Error))
+			result.Error = nil
+		}
+
+		chain.Error = result.Error
+		chain.ExitCode = result.ExitCode
+		chain.MemoryUsed += result.MemoryUsed
+		chain.Warnings = append(chain.Warnings, result.Warnings...)
+	}
+
+	chain.Output = output.String()
+	chain.Stderr = stderr.String()
+	chain.Duration = time.Since(startTime)
+	return chain
+}
+
+// startJob runs a list of chained commands as a background job. Its output,
+// live progress included, is captured for fg instead of going to the terminal.
+func (e *Executor) startJob(ctx context.Context, steps []commands.ChainStep) *commands.Job {
+	parts := []string{steps[0].Line}
+	for _, step := range steps[1:] {
+		parts = append(parts, string(step.Operator), step.Line)
+	}
+	line := strings.Join(parts, " ")
+
+	e.logger.Debug("Starting background job", monitoring.Field{Key: "command", Value: line})
+	return e.jobs.Start(ctx, line, func(jobCtx context.Context, output io.Writer) (int, error) {
+		result, err := e.Execute(commands.WithOutputWriter(jobCtx, output), line)
+		if err != nil {
+			io.WriteString(output, commands.FormatError(err))
+			return 1, err
+		}
+		io.WriteString(output, result.Output)
+		if result.Output != "" && !strings.HasSuffix(result.Output, "\n") {
+			io.WriteString(output, "\n")
+		}
+		io.WriteString(output, result.Stderr)
+		io.WriteString(output, commands.FormatError(result.Error))
+		return result.ExitCode, result.Error
+	})
+}
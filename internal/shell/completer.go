@@ -70,7 +70,12 @@ func (c *Completer) GetCompletions(input string, cursorPos int) []Completion {
 		return c.getCommandCompletions(parts[0])
 	}
 
-	// Completing arguments - for now, just file/directory completion
+	// Commands that know their arguments, such as saved names, suggest them first
+	if completions := c.getArgumentCompletions(parts, strings.HasSuffix(textBeforeCursor, " ")); len(completions) > 0 {
+		return completions
+	}
+
+	// Otherwise fall back to file/directory completion
 	lastPart := ""
 	if len(parts) > 1 {
 		lastPart = parts[len(parts)-1]
@@ -113,6 +118,86 @@ func (c *Completer) getCommandCompletions(prefix string) []Completion {
 	return completions
 }
 
+// getArgumentCompletions asks the command being typed for suggestions matching
+// the current word. Words starting with "-" complete to the flags the command
+// declares, plus the global output options for commands with structured output;
+// otherwise commands.ArgumentCompleter supplies the suggestions, kept only when
+// they start with the word unless the command is a RankedArgumentCompleter.
+func (c *Completer) getArgumentCompletions(parts []string, newWord bool) []Completion {
+	cmd, err := c.registry.Get(parts[0])
+	if err != nil {
+		return nil
+	}
+
+	args := append([]string{}, parts[1:]...)
+	if newWord {
+		args = append(args, "")
+	}
+	prefix := args[len(args)-1]
+
+	var completions []Completion
+	if declarer, ok := cmd.(commands.FlagDeclarer); ok {
+		flags := declarer.FlagSet()
+		// The value of a flag such as --port is left to file completion
+		if len(args) > 1 {
+			if spec, ok := flags.Spec(args[len(args)-2]); ok && spec.Kind != commands.BoolFlag && !strings.Contains(args[len(args)-2], "=") {
+				return nil
+			}
+		}
+		if strings.HasPrefix(prefix, "-") {
+			for _, spec := range flags.Specs() {
+				if name := "--" + spec.Name; strings.HasPrefix(name, prefix) {
+					completions = append(completions, Completion{
+						Text:        name,
+						Description: spec.Help,
+						Type:        CompletionTypeOption,
+					})
+				}
+			}
+		}
+	}
+
+	if formatter, ok := cmd.(commands.OutputFormatter); ok {
+		var suggestions []string
+		switch {
+		case len(args) > 1 && args[len(args)-2] == "--output-format":
+			suggestions = []string{string(commands.FormatText)}
+			for _, format := range formatter.OutputFormats() {
+				suggestions = append(suggestions, string(format))
+			}
+		case strings.HasPrefix(prefix, "-"):
+			suggestions = commands.GlobalOutputFlags
+		}
+		for _, suggestion := range suggestions {
+			if strings.HasPrefix(suggestion, prefix) {
+				completions = append(completions, Completion{
+					Text:        suggestion,
+					Description: "Global output option",
+					Type:        CompletionTypeOption,
+				})
+			}
+		}
+	}
+
+	if completer, ok := cmd.(commands.ArgumentCompleter); ok && len(completions) == 0 {
+		ranked, _ := cmd.(commands.RankedArgumentCompleter)
+		for _, suggestion := range completer.CompleteArguments(args) {
+			if strings.HasPrefix(suggestion, prefix) || (ranked != nil && ranked.RanksArguments()) {
+				completions = append(completions, Completion{
+					Text:        suggestion,
+					Description: cmd.Name(),
+					Type:        CompletionTypeOption,
+				})
+			}
+		}
+	}
+
+	if len(completions) > 8 {
+		completions = completions[:8]
+	}
+	return completions
+}
+
 // getFileCompletions returns file and directory completions
 func (c *Completer) getFileCompletions(prefix string) []Completion {
 	var completions []Completion
@@ -1,6 +1,7 @@
 package shell
 
 import (
+	"bytes"
 	"context"
 	"os"
 	"os/exec"
@@ -10,28 +11,42 @@ import (
 
 	"suppercommand/internal/commands"
 	"suppercommand/internal/commands/system"
+	"suppercommand/internal/config"
 	"suppercommand/internal/monitoring"
+	"suppercommand/internal/security"
+	"suppercommand/pkg/errors"
+
+	"github.com/fatih/color"
 )
 
 // Executor handles command execution
 type Executor struct {
+	config         config.ShellConfig
 	registry       *commands.Registry
 	monitor        monitoring.Monitor
 	logger         monitoring.Logger
 	historyTracker *system.HistoryTracker
+	audit          *security.AuditLog
+	jobs           *commands.JobTable
 }
 
 // NewExecutor creates a new command executor
 func NewExecutor(
+	config config.ShellConfig,
 	registry *commands.Registry,
 	monitor monitoring.Monitor,
 	logger monitoring.Logger,
+	audit *security.AuditLog,
+	jobs *commands.JobTable,
 ) *Executor {
 	return &Executor{
+		config:         config,
 		registry:       registry,
 		monitor:        monitor,
 		logger:         logger,
 		historyTracker: system.NewHistoryTracker(),
+		audit:          audit,
+		jobs:           jobs,
 	}
 }
 
@@ -63,30 +78,59 @@ func (e *Executor) Execute(ctx context.Context, input string) (*ExecutionResult,
 		}, nil
 	}
 
-	// Parse command and arguments
-	parts := strings.Fields(input)
-	if len(parts) == 0 {
+	// Commands joined by &&, || and ; run one at a time, each parsed on its own,
+	// and those ended by & run as background jobs
+	steps, err := commands.SplitChain(input)
+	if err != nil {
+		return &ExecutionResult{
+			Error:    errors.NewValidationError("%v", err),
+			ExitCode: 1,
+			Duration: time.Since(startTime),
+		}, nil
+	}
+	if len(steps) > 1 || steps[0].Background {
+		return e.executeChain(ctx, steps, startTime), nil
+	}
+
+	// Parse command and arguments, keeping quoted words together
+	words := commands.SplitCommandWords(input)
+	if len(words) == 0 {
 		return &ExecutionResult{
 			Output:   "",
 			Duration: time.Since(startTime),
 		}, nil
 	}
 
-	commandName := parts[0]
-	args := commands.ParseArguments(parts[1:])
+	// --notify works with every command so long runs can be left unattended
+	if stripped, ok := stripNotifyFlag(words); ok {
+		line := make([]string, len(stripped))
+		for i, word := range stripped {
+			line[i] = word.Text
+			if word.Quoted {
+				line[i] = commands.QuoteArgument(word.Text)
+			}
+		}
+
+		result, err := e.Execute(ctx, strings.Join(line, " "))
+		e.notifyCompletion(ctx, stripped[0].Text, startTime, result, err)
+		return result, err
+	}
+
+	commandName := words[0].Text
 
 	// Check if command exists in registry first
-	_, err := e.registry.Get(commandName)
-	if err != nil {
-		// Command not found in registry, try external command execution
+	if _, err := e.registry.Get(commandName); err != nil {
+		// Command not found in registry, try external command execution; the
+		// system shell does its own expansion
 		e.logger.Debug("Internal command not found, trying external command",
 			monitoring.Field{Key: "command", Value: commandName})
 
 		return e.executeExternalCommand(ctx, input, startTime)
 	}
 
-	// Execute command through registry
-	result, err := e.registry.Execute(ctx, commandName, args)
+	// Execute command through registry, which expands braces and globs so every
+	// builtin receives the final arguments and records it in the audit log
+	result, err := e.registry.ExecuteLine(ctx, input)
 
 	duration := time.Since(startTime)
 	success := err == nil
@@ -111,13 +155,16 @@ func (e *Executor) Execute(ctx context.Context, input string) (*ExecutionResult,
 		monitoring.Field{Key: "command", Value: commandName},
 		monitoring.Field{Key: "duration", Value: duration})
 
-	// Track command in history
-	cwd, _ := os.Getwd()
-	e.historyTracker.TrackCommand(input, cwd, result.ExitCode, result.Duration)
+	// Track command in history, except credential commands which may carry secrets
+	if commandName != "cred" {
+		cwd, _ := os.Getwd()
+		e.historyTracker.TrackCommand(input, cwd, result.ExitCode, result.Duration)
+	}
 
 	// Convert commands.Result to ExecutionResult
 	return &ExecutionResult{
 		Output:     result.Output,
+		Stderr:     result.Stderr,
 		Error:      result.Error,
 		ExitCode:   result.ExitCode,
 		Duration:   result.Duration,
@@ -126,8 +173,45 @@ func (e *Executor) Execute(ctx context.Context, input string) (*ExecutionResult,
 	}, nil
 }
 
-// Shutdown gracefully shuts down the executor
+// notifyCompletion raises a desktop notification for a finished command, noting in
+// the output when notifications are unavailable
+func (e *Executor) notifyCompletion(ctx context.Context, commandName string, startTime time.Time, result *ExecutionResult, err error) {
+	exitCode := 1
+	if err == nil && result != nil {
+		exitCode = result.ExitCode
+	}
+
+	title, message := commands.CompletionNotice(commandName, exitCode, time.Since(startTime))
+	if notifyErr := commands.SendNotification(ctx, title, message); notifyErr != nil {
+		e.logger.Debug("Completion notification not shown",
+			monitoring.Field{Key: "command", Value: commandName},
+			monitoring.Field{Key: "error", Value: notifyErr.Error()})
+		if result != nil {
+			result.Stderr += color.New(color.FgYellow).Sprintf("⚠️  Notification not shown: %v\n", notifyErr)
+		}
+	}
+}
+
+// stripNotifyFlag removes --notify from a command's arguments, reporting whether it was present
+func stripNotifyFlag(words []commands.Word) ([]commands.Word, bool) {
+	stripped := []commands.Word{words[0]}
+	found := false
+	for _, word := range words[1:] {
+		if word.Text == "--notify" && !word.Quoted {
+			found = true
+			continue
+		}
+		stripped = append(stripped, word)
+	}
+	return stripped, found
+}
+
+// Shutdown gracefully shuts down the executor, stopping any background jobs
 func (e *Executor) Shutdown(ctx context.Context) error {
+	if err := e.jobs.StopAll(ctx); err != nil {
+		e.logger.Warn("Background jobs still running at shutdown",
+			monitoring.Field{Key: "error", Value: err.Error()})
+	}
 	e.logger.Info("Command executor shutdown")
 	return nil
 }
@@ -135,6 +219,7 @@ func (e *Executor) Shutdown(ctx context.Context) error {
 // executeExternalCommand executes external system commands
 func (e *Executor) executeExternalCommand(ctx context.Context, input string, startTime time.Time) (*ExecutionResult, error) {
 	var cmd *exec.Cmd
+	cwd, _ := os.Getwd()
 
 	// Determine the shell to use based on the operating system
 	switch runtime.GOOS {
@@ -152,8 +237,10 @@ func (e *Executor) executeExternalCommand(ctx context.Context, input string, sta
 		}, nil
 	}
 
-	// Execute the command
-	output, err := cmd.CombinedOutput()
+	// Execute the command, keeping its diagnostics apart from its output
+	var output, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &output, &stderr
+	err := cmd.Run()
 	duration := time.Since(startTime)
 
 	exitCode := 0
@@ -168,6 +255,14 @@ func (e *Executor) executeExternalCommand(ctx context.Context, input string, sta
 
 	// Record metrics for external command
 	commandName := strings.Fields(input)[0]
+	var commandErr error
+	if externalCommandNotFound(exitCode, stderr.String()) {
+		// The system shell's own message gives way to one that scripts can
+		// tell apart from a command that ran and failed
+		commandErr = errors.WithContext(errors.NewNotFoundError("command '%s' not found", commandName), "command", commandName)
+		stderr.Reset()
+		exitCode = exitCodeNotFound
+	}
 	success := exitCode == 0
 	e.monitor.RecordCommandExecution("external:"+commandName, duration, success)
 
@@ -183,12 +278,27 @@ func (e *Executor) executeExternalCommand(ctx context.Context, input string, sta
 	}
 
 	// Track external command in history
-	cwd, _ := os.Getwd()
 	e.historyTracker.TrackCommand(input, cwd, exitCode, duration)
+	e.audit.Record(input, cwd, exitCode, duration)
 
 	return &ExecutionResult{
-		Output:   string(output),
+		Output:   output.String(),
+		Stderr:   stderr.String(),
+		Error:    commandErr,
 		ExitCode: exitCode,
 		Duration: duration,
 	}, nil
 }
+
+// exitCodeNotFound is the exit code of a command that doesn't exist, as in
+// POSIX shells
+const exitCodeNotFound = 127
+
+// externalCommandNotFound reports whether the system shell failed because it
+// found no such command: bash exits with 127, PowerShell names the exception
+func externalCommandNotFound(exitCode int, output string) bool {
+	if runtime.GOOS == "windows" {
+		return exitCode != 0 && strings.Contains(output, "CommandNotFoundException")
+	}
+	return exitCode == exitCodeNotFound
+}
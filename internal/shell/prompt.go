@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"suppercommand/internal/commands"
 	"suppercommand/internal/config"
 	"suppercommand/internal/monitoring"
 )
@@ -34,9 +35,9 @@ func (p *Prompter) Initialize(ctx context.Context) error {
 	return nil
 }
 
-// GetPrompt returns the current prompt string
+// GetPrompt returns the current prompt string, the plain one in ASCII mode
 func (p *Prompter) GetPrompt() string {
-	if p.config.Colors.Enabled {
+	if p.config.Colors.Enabled && !commands.ASCIIDecorations() {
 		return p.getColoredPrompt()
 	}
 	return p.getPlainPrompt()
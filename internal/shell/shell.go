@@ -11,9 +11,11 @@ import (
 	"suppercommand/internal/commands"
 	"suppercommand/internal/config"
 	"suppercommand/internal/monitoring"
+	"suppercommand/internal/security"
 	"suppercommand/pkg/errors"
 
 	prompt "github.com/c-bata/go-prompt"
+	"github.com/fatih/color"
 )
 
 // Shell interface defines the core shell functionality
@@ -26,7 +28,9 @@ type Shell interface {
 
 // ExecutionResult contains the result of command execution
 type ExecutionResult struct {
-	Output     string
+	Output string
+	// Stderr holds the command's diagnostics, see commands.Result
+	Stderr     string
 	Error      error
 	ExitCode   int
 	Duration   time.Duration
@@ -85,9 +89,14 @@ type BasicShell struct {
 	registry  *commands.Registry
 	monitor   monitoring.Monitor
 	logger    monitoring.Logger
+	audit     *security.AuditLog
+	jobs      *commands.JobTable
 	executor  *Executor
 	completer *Completer
 	prompter  *Prompter
+
+	// runCtx is the context passed to Run; go-prompt callbacks have no context of their own
+	runCtx context.Context
 }
 
 // NewShell creates a new shell instance
@@ -96,12 +105,16 @@ func NewShell(
 	registry *commands.Registry,
 	monitor monitoring.Monitor,
 	logger monitoring.Logger,
+	audit *security.AuditLog,
+	jobs *commands.JobTable,
 ) Shell {
 	return &BasicShell{
 		config:   config,
 		registry: registry,
 		monitor:  monitor,
 		logger:   logger,
+		audit:    audit,
+		jobs:     jobs,
 	}
 }
 
@@ -110,7 +123,7 @@ func (s *BasicShell) Initialize(ctx context.Context) error {
 	s.logger.Info("Initializing shell components")
 
 	// Initialize executor
-	s.executor = NewExecutor(s.registry, s.monitor, s.logger)
+	s.executor = NewExecutor(s.config, s.registry, s.monitor, s.logger, s.audit, s.jobs)
 	if err := s.executor.Initialize(ctx); err != nil {
 		return errors.Wrap(err, "failed to initialize executor")
 	}
@@ -134,6 +147,7 @@ func (s *BasicShell) Initialize(ctx context.Context) error {
 // Run starts the shell main loop
 func (s *BasicShell) Run(ctx context.Context) error {
 	s.logger.Info("Starting shell main loop")
+	s.runCtx = ctx
 
 	// Use go-prompt by default, simple shell can be enabled with SUPERSHELL_SIMPLE=1
 	// Use stable terminal mode with SUPERSHELL_STABLE=1 for better resize handling
@@ -193,26 +207,23 @@ func (s *BasicShell) promptExecutor(input string) {
 
 	// Handle exit commands
 	if input == "exit" || input == "quit" {
-		fmt.Println("👋 Goodbye!")
+		printDecorated("👋 Goodbye!\n")
 		os.Exit(0)
 	}
 
-	// Execute command
-	ctx := context.Background()
+	// Execute command under the shell context so shutdown interrupts it
+	ctx := s.runCtx
+	if ctx == nil {
+		ctx = context.Background()
+	}
 	result, err := s.executor.Execute(ctx, input)
 	if err != nil {
-		fmt.Printf("❌ Error: %v\n", err)
+		printDecorated("❌ Error: %v\n", err)
 		return
 	}
 
-	// Display result with proper newline handling
-	if result.Output != "" {
-		fmt.Print(result.Output)
-		// Ensure we end with a newline
-		if !strings.HasSuffix(result.Output, "\n") {
-			fmt.Println()
-		}
-	}
+	printResult(result)
+	s.printFinishedJobs()
 }
 
 // promptCompleter adapts our completer to go-prompt's expected signature
@@ -289,7 +300,7 @@ func (s *BasicShell) runSimpleShell(ctx context.Context) error {
 			// Read input with proper error handling
 			if !scanner.Scan() {
 				if err := scanner.Err(); err != nil {
-					fmt.Printf("❌ Input error: %v\n", err)
+					printDecorated("❌ Input error: %v\n", err)
 				}
 				break
 			}
@@ -301,33 +312,23 @@ func (s *BasicShell) runSimpleShell(ctx context.Context) error {
 
 			// Handle exit
 			if input == "exit" || input == "quit" {
-				fmt.Println("👋 Goodbye!")
+				printDecorated("👋 Goodbye!\n")
 				return nil
 			}
 
 			// Execute command
 			result, err := s.executor.Execute(ctx, input)
 			if err != nil {
-				fmt.Printf("❌ Error: %v\n", err)
+				printDecorated("❌ Error: %v\n", err)
 				continue
 			}
 
-			// Display result with proper newline handling
-			if result.Output != "" {
-				fmt.Print(result.Output)
-				if !strings.HasSuffix(result.Output, "\n") {
-					fmt.Println()
-				}
-			}
-
-			// Display error if any
-			if result.Error != nil {
-				fmt.Printf("❌ Error: %v\n", result.Error)
-			}
+			printResult(result)
+			s.printFinishedJobs()
 
 			// Display warnings if any
 			for _, warning := range result.Warnings {
-				fmt.Printf("⚠️  Warning: %s\n", warning)
+				printDecorated("⚠️  Warning: %s\n", warning)
 			}
 		}
 	}
@@ -364,7 +365,7 @@ func (s *BasicShell) runStableShell(ctx context.Context) error {
 			// Read input with proper error handling
 			if !scanner.Scan() {
 				if err := scanner.Err(); err != nil {
-					fmt.Printf("❌ Input error: %v\n", err)
+					printDecorated("❌ Input error: %v\n", err)
 				}
 				break
 			}
@@ -376,29 +377,52 @@ func (s *BasicShell) runStableShell(ctx context.Context) error {
 
 			// Handle exit
 			if input == "exit" || input == "quit" {
-				fmt.Println("👋 Goodbye!")
+				printDecorated("👋 Goodbye!\n")
 				return nil
 			}
 
 			// Execute command
 			result, err := s.executor.Execute(ctx, input)
 			if err != nil {
-				fmt.Printf("❌ Error: %v\n", err)
+				printDecorated("❌ Error: %v\n", err)
 				continue
 			}
 
-			// Display result
-			if result.Output != "" {
-				fmt.Print(result.Output)
-				// Ensure we end with a newline
-				if !strings.HasSuffix(result.Output, "\n") {
-					fmt.Println()
-				}
-			}
+			printResult(result)
+			s.printFinishedJobs()
 		}
 	}
 }
 
+// printResult displays a command's output, then on stderr its diagnostics and
+// its error, if any, in red. Decorations become ASCII here in ASCII mode.
+func printResult(result *ExecutionResult) {
+	// Display result with proper newline handling
+	if result.Output != "" {
+		fmt.Print(commands.Decorate(result.Output))
+		// Ensure we end with a newline
+		if !strings.HasSuffix(result.Output, "\n") {
+			fmt.Println()
+		}
+	}
+
+	fmt.Fprint(os.Stderr, commands.Decorate(result.Stderr))
+	fmt.Fprint(os.Stderr, commands.Decorate(commands.FormatError(result.Error)))
+}
+
+// printDecorated prints a message of the shell's own, in ASCII in ASCII mode
+func printDecorated(format string, args ...interface{}) {
+	fmt.Print(commands.Decorate(fmt.Sprintf(format, args...)))
+}
+
+// printFinishedJobs announces the background jobs that ended since the last
+// command, as sh does before the next prompt
+func (s *BasicShell) printFinishedJobs() {
+	for _, job := range s.jobs.Finished() {
+		fmt.Print(color.New(color.FgHiBlack).Sprintf("[%d] %s  %s  (fg %d shows its output)\n", job.ID, job.State(), job.Line, job.ID))
+	}
+}
+
 // clearTerminalState clears any problematic terminal state
 func (s *BasicShell) clearTerminalState() {
 	// Send escape sequence to reset terminal state
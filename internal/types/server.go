@@ -2,6 +2,8 @@ package types
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 )
 
@@ -12,6 +14,7 @@ type ServerManager interface {
 	ControlService(ctx context.Context, serviceName string, action ServiceAction) error
 	GetActiveUsers(ctx context.Context) ([]*UserSession, error)
 	GetServiceLogs(ctx context.Context, serviceName string, tail bool) (*LogStream, error)
+	GetEventLog(ctx context.Context, query *EventLogQuery) ([]*EventLogEntry, error)
 	ConfigureAlerts(ctx context.Context, config *AlertConfig) error
 	BackupConfiguration(ctx context.Context, backupPath string) error
 }
@@ -87,6 +90,85 @@ type LogEntry struct {
 	Source    string    `json:"source"`
 }
 
+// EventLevel is the severity of an event, numbered as in the Windows Event Log
+type EventLevel int
+
+const (
+	EventLevelCritical    EventLevel = 1
+	EventLevelError       EventLevel = 2
+	EventLevelWarning     EventLevel = 3
+	EventLevelInformation EventLevel = 4
+	EventLevelVerbose     EventLevel = 5
+)
+
+// String returns the level's display name
+func (l EventLevel) String() string {
+	switch l {
+	case EventLevelCritical:
+		return "Critical"
+	case EventLevelError:
+		return "Error"
+	case EventLevelWarning:
+		return "Warning"
+	case EventLevelVerbose:
+		return "Verbose"
+	default:
+		return "Information"
+	}
+}
+
+// MarshalText exports the level by name
+func (l EventLevel) MarshalText() ([]byte, error) {
+	return []byte(strings.ToLower(l.String())), nil
+}
+
+// UnmarshalText reads a level exported by MarshalText
+func (l *EventLevel) UnmarshalText(text []byte) error {
+	level, err := ParseEventLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
+
+// ParseEventLevel parses a level name such as "error" or "warn"
+func ParseEventLevel(name string) (EventLevel, error) {
+	switch strings.ToLower(name) {
+	case "critical", "crit":
+		return EventLevelCritical, nil
+	case "error", "err":
+		return EventLevelError, nil
+	case "warning", "warn":
+		return EventLevelWarning, nil
+	case "information", "info":
+		return EventLevelInformation, nil
+	case "verbose", "debug":
+		return EventLevelVerbose, nil
+	}
+	return 0, fmt.Errorf("unknown event level '%s' (use critical, error, warning, information or verbose)", name)
+}
+
+// EventLogQuery selects recent events from a system event log
+type EventLogQuery struct {
+	// Log is the event log (System, Application, Security) or, on Linux, a systemd unit
+	Log string `json:"log"`
+	// MaxLevel keeps events at this severity or worse; zero keeps all of them
+	MaxLevel EventLevel `json:"max_level,omitempty"`
+	Since    time.Time  `json:"since,omitempty"`
+	Count    int        `json:"count"`
+}
+
+// EventLogEntry is a single event, newest first in query results
+type EventLogEntry struct {
+	Time    time.Time  `json:"time"`
+	Level   EventLevel `json:"level"`
+	Source  string     `json:"source"`
+	EventID int        `json:"event_id,omitempty"`
+	Message string     `json:"message"`
+	Log     string     `json:"log"`
+}
+
 // AlertConfig contains alert configuration settings
 type AlertConfig struct {
 	Enabled       bool                      `json:"enabled"`
@@ -15,8 +15,29 @@ const (
 	ErrorTypeNetwork
 	ErrorTypePermission
 	ErrorTypeInternal
+	ErrorTypeNotFound
 )
 
+// errorTypeNames are the names ErrorType.String gives each error type
+var errorTypeNames = map[ErrorType]string{
+	ErrorTypeValidation:    "validation",
+	ErrorTypeSecurity:      "security",
+	ErrorTypeExecution:     "execution",
+	ErrorTypeConfiguration: "configuration",
+	ErrorTypeNetwork:       "network",
+	ErrorTypePermission:    "permission",
+	ErrorTypeInternal:      "internal",
+	ErrorTypeNotFound:      "not_found",
+}
+
+// String returns the name of the error type, as used in machine-readable errors
+func (t ErrorType) String() string {
+	if name, ok := errorTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("ErrorType(%d)", int(t))
+}
+
 // SuperShellError represents a structured error with context
 type SuperShellError struct {
 	Type        ErrorType
@@ -124,6 +145,16 @@ func NewInternalError(format string, args ...interface{}) *SuperShellError {
 	}
 }
 
+// NewNotFoundError creates a new error for a command or resource that doesn't exist
+func NewNotFoundError(format string, args ...interface{}) *SuperShellError {
+	return &SuperShellError{
+		Type:        ErrorTypeNotFound,
+		Message:     fmt.Sprintf(format, args...),
+		Recoverable: true,
+		Context:     make(map[string]interface{}),
+	}
+}
+
 // Wrap wraps an existing error with additional context
 func Wrap(err error, format string, args ...interface{}) *SuperShellError {
 	message := fmt.Sprintf(format, args...)
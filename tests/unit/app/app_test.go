@@ -2,6 +2,9 @@ package app_test
 
 import (
 	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -92,3 +95,53 @@ func TestApplication_ExecuteCommand(t *testing.T) {
 		})
 	}
 }
+
+func TestApplication_StartupScripts(t *testing.T) {
+	home, err := ioutil.TempDir("", "supershell-home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+	oldHome, oldProfile := os.Getenv("HOME"), os.Getenv("USERPROFILE")
+	os.Setenv("HOME", home)
+	os.Setenv("USERPROFILE", home)
+	defer func() {
+		os.Setenv("HOME", oldHome)
+		os.Setenv("USERPROFILE", oldProfile)
+	}()
+
+	startup := filepath.Join(home, "startup")
+	os.MkdirAll(filepath.Join(home, ".supershell"), 0755)
+	os.MkdirAll(startup, 0755)
+	ioutil.WriteFile(filepath.Join(home, ".supershell", "config.yaml"), []byte("shell:\n  startup_dir: "+startup+"\n"), 0644)
+	ioutil.WriteFile(filepath.Join(home, ".supershellrc.ss"), []byte("mkdir "+filepath.Join(home, "from-rc")+"\n"), 0644)
+	ioutil.WriteFile(filepath.Join(startup, "10-first.ss"), []byte("mkdir "+filepath.Join(home, "from-dir")+"\n"), 0644)
+	ioutil.WriteFile(filepath.Join(startup, "notes.txt"), []byte("mkdir "+filepath.Join(home, "from-txt")+"\n"), 0644)
+
+	initialize := func(skip bool) {
+		application := app.NewApplication()
+		if skip {
+			application.SkipStartupScripts()
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := application.Initialize(ctx); err != nil {
+			t.Fatalf("Failed to initialize application: %v", err)
+		}
+		application.Shutdown(ctx)
+	}
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(home, name))
+		return err == nil
+	}
+
+	initialize(true)
+	if exists("from-rc") || exists("from-dir") {
+		t.Fatal("SkipStartupScripts() should keep the startup scripts from running")
+	}
+	initialize(false)
+	if !exists("from-rc") || !exists("from-dir") || exists("from-txt") {
+		t.Errorf("expected the rc file and the .ss scripts of startup_dir to run: rc %v, dir %v, txt %v",
+			exists("from-rc"), exists("from-dir"), exists("from-txt"))
+	}
+}
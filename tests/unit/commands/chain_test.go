@@ -0,0 +1,78 @@
+package commands_test
+
+import (
+	"reflect"
+	"testing"
+
+	"suppercommand/internal/commands"
+)
+
+func TestSplitChain(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []commands.ChainStep
+	}{
+		{"ls -la", []commands.ChainStep{{Operator: commands.ChainThen, Line: "ls -la"}}},
+		{"mkdir out && cd out || echo failed; pwd", []commands.ChainStep{
+			{Operator: commands.ChainThen, Line: "mkdir out"},
+			{Operator: commands.ChainAnd, Line: "cd out"},
+			{Operator: commands.ChainOr, Line: "echo failed"},
+			{Operator: commands.ChainThen, Line: "pwd"},
+		}},
+		{"a&&b||c;d", []commands.ChainStep{
+			{Operator: commands.ChainThen, Line: "a"},
+			{Operator: commands.ChainAnd, Line: "b"},
+			{Operator: commands.ChainOr, Line: "c"},
+			{Operator: commands.ChainThen, Line: "d"},
+		}},
+		{`echo "a && b" 'c; d' && echo "say \"x || y\""`, []commands.ChainStep{
+			{Operator: commands.ChainThen, Line: `echo "a && b" 'c; d'`},
+			{Operator: commands.ChainAnd, Line: `echo "say \"x || y\""`},
+		}},
+		{"fastcp-recv key & sleep 1 && fastcp-send key file", []commands.ChainStep{
+			{Operator: commands.ChainThen, Line: "fastcp-recv key", Background: true},
+			{Operator: commands.ChainThen, Line: "sleep 1"},
+			{Operator: commands.ChainAnd, Line: "fastcp-send key file"},
+		}},
+		{"a && b & c ; d &", []commands.ChainStep{
+			{Operator: commands.ChainThen, Line: "a", Background: true},
+			{Operator: commands.ChainAnd, Line: "b"},
+			{Operator: commands.ChainThen, Line: "c"},
+			{Operator: commands.ChainThen, Line: "d", Background: true},
+		}},
+		{"make 2>&1 &>/dev/null", []commands.ChainStep{{Operator: commands.ChainThen, Line: "make 2>&1 &>/dev/null"}}},
+		{"echo done;", []commands.ChainStep{{Operator: commands.ChainThen, Line: "echo done"}}},
+	}
+	for _, tt := range tests {
+		got, err := commands.SplitChain(tt.input)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SplitChain(%q) = %+v, %v, want %+v", tt.input, got, err, tt.want)
+		}
+	}
+
+	for _, input := range []string{"&& ls", "ls ||", "ls && ; pwd", "; ls", "ls ;; pwd", "& ls", "ls && &"} {
+		if steps, err := commands.SplitChain(input); err == nil {
+			t.Errorf("SplitChain(%q) should be a syntax error, got %+v", input, steps)
+		}
+	}
+}
+
+func TestChainStep_ShouldRun(t *testing.T) {
+	tests := []struct {
+		operator commands.ChainOperator
+		exitCode int
+		want     bool
+	}{
+		{commands.ChainThen, 0, true},
+		{commands.ChainThen, 1, true},
+		{commands.ChainAnd, 0, true},
+		{commands.ChainAnd, 2, false},
+		{commands.ChainOr, 0, false},
+		{commands.ChainOr, 2, true},
+	}
+	for _, tt := range tests {
+		if got := (commands.ChainStep{Operator: tt.operator}).ShouldRun(tt.exitCode); got != tt.want {
+			t.Errorf("%s after exit code %d: ShouldRun() = %v", tt.operator, tt.exitCode, got)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package commands_test
+
+import (
+	"bytes"
+	"testing"
+
+	"suppercommand/internal/commands"
+)
+
+func TestASCIIFallback(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"🔑 FASTCP TRANSFER KEY\n═══════\n", "FASTCP TRANSFER KEY\n=======\n"},
+		{"✅ done  ❌ failed  ⚠️  careful  💡 tip\n", "[OK] done  [X] failed  [!]  careful  [i] tip\n"},
+		{"┌──┬──┐\n│ a│ b│\n└──┴──┘\n", "+--+--+\n| a| b|\n+--+--+\n"},
+		{"[████░░░░] 50%", "[####----] 50%"},
+		{"   📉 Min:     2ms · 3 runs → fast…", "   Min:     2ms - 3 runs -> fast..."},
+		{"⠋ Gathering", "| Gathering"},
+		// Text that isn't decoration stays, even outside ASCII
+		{"café.txt 12µs", "café.txt 12µs"},
+		{"plain text", "plain text"},
+	}
+	for _, test := range tests {
+		if got := commands.ASCIIFallback(test.in); got != test.want {
+			t.Errorf("ASCIIFallback(%q) = %q, want %q", test.in, got, test.want)
+		}
+	}
+}
+
+func TestDecorate_FollowsTheMode(t *testing.T) {
+	defer commands.SetASCIIDecorations(false)
+
+	commands.SetASCIIDecorations(false)
+	if got := commands.Decorate("✅ ok"); got != "✅ ok" {
+		t.Errorf("Unicode mode changed the text to %q", got)
+	}
+	commands.SetASCIIDecorations(true)
+	if got := commands.Decorate("✅ ok"); got != "[OK] ok" {
+		t.Errorf("ASCII mode gave %q", got)
+	}
+}
+
+func TestDecoratingWriter(t *testing.T) {
+	defer commands.SetASCIIDecorations(false)
+	commands.SetASCIIDecorations(true)
+
+	var out bytes.Buffer
+	w := commands.DecoratingWriter(&out)
+	// A character split across writes is converted once it is complete
+	check := []byte("✅ ok ═")
+	if n, err := w.Write(check[:2]); err != nil || n != 2 {
+		t.Fatalf("write returned %d, %v", n, err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("half a character was written: %q", out.String())
+	}
+	w.Write(check[2:])
+	if out.String() != "[OK] ok =" {
+		t.Errorf("got %q", out.String())
+	}
+}
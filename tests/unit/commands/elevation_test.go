@@ -0,0 +1,145 @@
+package commands_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/config"
+	"suppercommand/internal/monitoring"
+)
+
+// fakeElevation records what the registry asks of it
+type fakeElevation struct {
+	elevated bool
+	answer   bool
+	method   error
+	prompted string
+	ran      []string
+	code     int
+}
+
+func (f *fakeElevation) Elevation() commands.Elevation {
+	return commands.Elevation{
+		IsElevated: func() bool { return f.elevated },
+		Method:     func() (string, error) { return "sudo", f.method },
+		Confirm: func(prompt string) bool {
+			f.prompted = prompt
+			return f.answer
+		},
+		Run: func(ctx context.Context, line string) (int, error) {
+			f.ran = append(f.ran, line)
+			return f.code, nil
+		},
+	}
+}
+
+// partialCommand needs elevation only for its "apply" subcommand
+type partialCommand struct {
+	MockCommand
+}
+
+func (p *partialCommand) NeedsElevation(args *commands.Arguments) bool {
+	return len(args.Raw) > 0 && args.Raw[0] == "apply"
+}
+
+func elevationRegistry(t *testing.T, cmd commands.Command, fake *fakeElevation) *commands.Registry {
+	t.Helper()
+	registry := commands.NewRegistry(monitoring.NewLogger(config.MonitoringConfig{}))
+	registry.SetElevation(fake.Elevation())
+	if err := registry.Register(cmd); err != nil {
+		t.Fatal(err)
+	}
+	return registry
+}
+
+func TestRegistry_ElevationDeclined(t *testing.T) {
+	executed := false
+	cmd := &MockCommand{name: "sniff", elevation: true,
+		executeFunc: func(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+			executed = true
+			return &commands.Result{}, nil
+		}}
+	fake := &fakeElevation{}
+	registry := elevationRegistry(t, cmd, fake)
+
+	result, err := registry.Execute(context.Background(), "sniff", commands.ParseArguments([]string{"-i", "eth0"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if executed || len(fake.ran) != 0 {
+		t.Fatal("a declined command must not run")
+	}
+	if result.ExitCode == 0 || result.Error == nil || !strings.Contains(result.Error.Error(), "sniff requires administrator privileges") {
+		t.Errorf("result = %+v, want a precise privilege error", result)
+	}
+	if !strings.Contains(fake.prompted, "sudo") {
+		t.Errorf("prompt %q should name how the command is re-run", fake.prompted)
+	}
+}
+
+func TestRegistry_ElevationRerun(t *testing.T) {
+	cmd := &MockCommand{name: "sniff", elevation: true}
+	fake := &fakeElevation{answer: true, code: 3}
+	registry := elevationRegistry(t, cmd, fake)
+
+	result, err := registry.Execute(context.Background(), "sniff", commands.ParseArguments([]string{"--save", "my capture.pcap"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `sniff --save "my capture.pcap"`; len(fake.ran) != 1 || fake.ran[0] != want {
+		t.Errorf("re-ran %q, want %q", fake.ran, want)
+	}
+	if result.ExitCode != 3 {
+		t.Errorf("exit code = %d, want the elevated run's 3", result.ExitCode)
+	}
+}
+
+func TestRegistry_ElevationUnavailable(t *testing.T) {
+	cmd := &MockCommand{name: "sniff", elevation: true}
+	fake := &fakeElevation{answer: true, method: context.Canceled}
+	registry := elevationRegistry(t, cmd, fake)
+
+	result, _ := registry.Execute(context.Background(), "sniff", commands.ParseArguments(nil))
+	if fake.prompted != "" || len(fake.ran) != 0 {
+		t.Error("there should be no offer to re-run without a way to elevate")
+	}
+	if result.ExitCode == 0 {
+		t.Error("the command should fail")
+	}
+}
+
+func TestRegistry_ElevationNotNeeded(t *testing.T) {
+	tests := []struct {
+		name     string
+		cmd      commands.Command
+		args     []string
+		elevated bool
+	}{
+		{"plain command", &MockCommand{name: "ls"}, nil, false},
+		{"already elevated", &MockCommand{name: "sniff", elevation: true}, nil, true},
+		{"read-only subcommand", &partialCommand{MockCommand{name: "fw", elevation: true}}, []string{"list"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeElevation{elevated: tt.elevated}
+			registry := elevationRegistry(t, tt.cmd, fake)
+			result, err := registry.Execute(context.Background(), tt.cmd.Name(), commands.ParseArguments(tt.args))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Output != "mock output" || fake.prompted != "" {
+				t.Errorf("command should run directly, got %+v (prompted %q)", result, fake.prompted)
+			}
+		})
+	}
+
+	fake := &fakeElevation{}
+	registry := elevationRegistry(t, &partialCommand{MockCommand{name: "fw", elevation: true}}, fake)
+	registry.Execute(context.Background(), "fw", commands.ParseArguments([]string{"apply"}))
+	if fake.prompted == "" {
+		t.Error("the subcommand the policy marks should need elevation")
+	}
+}
@@ -0,0 +1,136 @@
+package commands_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/commands/filesystem"
+	"suppercommand/pkg/errors"
+)
+
+func TestFileError_Classifies(t *testing.T) {
+	_, err := os.Stat(filepath.Join(os.TempDir(), "supershell-missing-file"))
+	missing := commands.FileError("cat", "notes.txt", err)
+
+	if missing.Type != errors.ErrorTypeValidation {
+		t.Errorf("missing file should be a validation error, got %v", missing.Type)
+	}
+	if !os.IsNotExist(missing.Cause) {
+		t.Errorf("cause should still report not-exist, got %v", missing.Cause)
+	}
+	if missing.Context["path"] != "notes.txt" || missing.Context["command"] != "cat" {
+		t.Errorf("unexpected context %v", missing.Context)
+	}
+
+	denied := commands.FileError("rm", "secret", os.ErrPermission)
+	if denied.Type != errors.ErrorTypePermission || denied.Recoverable {
+		t.Errorf("permission failure should be an unrecoverable permission error, got %+v", denied)
+	}
+}
+
+func TestErrorList(t *testing.T) {
+	var list commands.ErrorList
+	if list.Err() != nil {
+		t.Error("an empty list should not be an error")
+	}
+
+	first := commands.UsageError("cp", "no destination specified")
+	list = append(list, first)
+	if list.Err() != first {
+		t.Error("a single failure should be returned as is")
+	}
+
+	list = append(list, commands.FileError("cp", "b.txt", os.ErrNotExist))
+	rendered := commands.FormatError(list.Err())
+	if strings.Count(rendered, "❌") != 2 || !strings.Contains(rendered, "cp: b.txt") {
+		t.Errorf("expected one rendered line per failure, got %q", rendered)
+	}
+}
+
+func TestCat_MissingFileFails(t *testing.T) {
+	dir, err := ioutil.TempDir("", "supershell-cat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	present := filepath.Join(dir, "present.txt")
+	if err := ioutil.WriteFile(present, []byte("hello\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(dir, "missing.txt")
+
+	args := commands.ParseArguments([]string{present, missing})
+	result, err := filesystem.NewCatCommand().Execute(context.Background(), args)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if result.ExitCode == 0 {
+		t.Error("cat of a missing file should exit non-zero")
+	}
+	if !strings.Contains(result.Output, "hello") {
+		t.Errorf("readable files should still be printed, got %q", result.Output)
+	}
+
+	failure, ok := result.Error.(*errors.SuperShellError)
+	if !ok {
+		t.Fatalf("expected a structured error, got %T", result.Error)
+	}
+	if failure.Context["path"] != missing || !os.IsNotExist(failure.Cause) {
+		t.Errorf("unexpected failure %+v", failure)
+	}
+}
+
+func TestFormatErrorJSON(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		err      error
+		exitCode int
+		want     commands.ErrorReport
+	}{
+		{
+			name:     "usage",
+			err:      commands.UsageError("cp", "no destination specified"),
+			exitCode: 1,
+			want:     commands.ErrorReport{Error: "cp: no destination specified", Type: "validation", Command: "cp", ExitCode: 1},
+		},
+		{
+			name:     "not found",
+			err:      errors.WithContext(errors.NewNotFoundError("command 'bogus' not found"), "command", "bogus"),
+			exitCode: 127,
+			want:     commands.ErrorReport{Error: "command 'bogus' not found", Type: "not_found", Command: "bogus", ExitCode: 127},
+		},
+		{
+			name:     "list takes its first failure's category",
+			err:      commands.ErrorList{commands.FileError("rm", "secret", os.ErrPermission), commands.UsageError("rm", "oops")},
+			exitCode: 1,
+			want:     commands.ErrorReport{Error: "rm: secret: permission denied\nrm: oops", Type: "permission", Command: "rm", ExitCode: 1},
+		},
+		{
+			name:     "plain",
+			err:      fmt.Errorf("context canceled"),
+			exitCode: 130,
+			want:     commands.ErrorReport{Error: "context canceled", Type: "execution", ExitCode: 130},
+		},
+	} {
+		line := commands.FormatErrorJSON(tc.err, tc.exitCode)
+		if strings.Count(line, "\n") != 1 || !strings.HasSuffix(line, "\n") {
+			t.Errorf("%s: expected a single line, got %q", tc.name, line)
+		}
+		var got commands.ErrorReport
+		if err := json.Unmarshal([]byte(line), &got); err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if got != tc.want {
+			t.Errorf("%s: got %+v, want %+v", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package commands_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"suppercommand/internal/commands"
+)
+
+func TestExpandBraces(t *testing.T) {
+	tests := []struct {
+		word string
+		want []string
+	}{
+		{"file{1,2,3}.txt", []string{"file1.txt", "file2.txt", "file3.txt"}},
+		{"{a,b}{x,y}", []string{"ax", "ay", "bx", "by"}},
+		{"src/{cmd/{a,b},lib}", []string{"src/cmd/a", "src/cmd/b", "src/lib"}},
+		{"pre{,fix}", []string{"pre", "prefix"}},
+		{"{single}", []string{"{single}"}},
+		{"open{a,b", []string{"open{a,b"}},
+		{"plain", []string{"plain"}},
+	}
+
+	for _, tt := range tests {
+		if got := commands.ExpandBraces(tt.word); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ExpandBraces(%q) = %q, want %q", tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestExpandArguments_Globs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "supershell-glob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"a.log", "b.log", ".hidden.log", "notes.txt"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	words := commands.SplitCommandWords(filepath.Join(dir, "*.log") + ` "*.log" --include=*.log`)
+	got, err := commands.ExpandArguments(words, commands.GlobNoMatchPassthrough)
+	if err != nil {
+		t.Fatalf("ExpandArguments() error = %v", err)
+	}
+	want := []string{filepath.Join(dir, "a.log"), filepath.Join(dir, "b.log"), "*.log", "--include=*.log"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExpandArguments() = %q, want %q", got, want)
+	}
+
+	// Brace alternatives are globbed individually
+	words = []commands.Word{{Text: filepath.Join(dir, "{*.txt,a.*}")}}
+	got, _ = commands.ExpandArguments(words, commands.GlobNoMatchPassthrough)
+	want = []string{filepath.Join(dir, "notes.txt"), filepath.Join(dir, "a.log")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("brace and glob expansion = %q, want %q", got, want)
+	}
+}
+
+func TestExpandArguments_NoMatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "supershell-glob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	pattern := filepath.Join(dir, "*.missing")
+	words := []commands.Word{{Text: "keep"}, {Text: pattern}}
+
+	got, err := commands.ExpandArguments(words, commands.GlobNoMatchPassthrough)
+	if err != nil || !reflect.DeepEqual(got, []string{"keep", pattern}) {
+		t.Errorf("passthrough = %q, %v; want the pattern kept", got, err)
+	}
+
+	got, err = commands.ExpandArguments(words, commands.GlobNoMatchNull)
+	if err != nil || !reflect.DeepEqual(got, []string{"keep"}) {
+		t.Errorf("null = %q, %v; want the pattern dropped", got, err)
+	}
+
+	if _, err := commands.ExpandArguments(words, commands.GlobNoMatchError); err == nil {
+		t.Error("error policy should fail when a pattern matches nothing")
+	}
+}
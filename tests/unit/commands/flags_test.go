@@ -0,0 +1,126 @@
+package commands_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"suppercommand/internal/commands"
+)
+
+func testFlagSet() *commands.FlagSet {
+	return commands.NewFlagSet("copy", "copy <src> <dst> [-p <port>] [-v] [-a] [--ratio <n>] [--exclude <glob>]...",
+		commands.FlagSpec{Name: "port", Short: "p", Kind: commands.IntFlag, Default: "8888", Value: "port", Help: "TCP port"},
+		commands.FlagSpec{Name: "verbose", Short: "v", Help: "Print every file"},
+		commands.FlagSpec{Name: "all", Short: "a", Help: "Include hidden files"},
+		commands.FlagSpec{Name: "ratio", Kind: commands.FloatFlag, Default: "0.5", Help: "Compression ratio"},
+		commands.FlagSpec{Name: "exclude", Kind: commands.ListFlag, Value: "glob", Help: "Skip matching files"},
+		commands.FlagSpec{Name: "name", Kind: commands.StringFlag, Help: "Transfer name"},
+	)
+}
+
+func TestFlagSetParse(t *testing.T) {
+	flags, err := testFlagSet().Parse([]string{"src", "-p", "9000", "--exclude", "*.tmp", "dst", "-va", "--exclude=*.log", "--ratio=0.25", "--", "--name"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := flags.Args(); !reflect.DeepEqual(got, []string{"src", "dst", "--name"}) {
+		t.Errorf("Args() = %q", got)
+	}
+	if flags.Int("port") != 9000 || !flags.Bool("verbose") || !flags.Bool("all") || flags.Float("ratio") != 0.25 {
+		t.Errorf("unexpected values: port=%d verbose=%v all=%v ratio=%v", flags.Int("port"), flags.Bool("verbose"), flags.Bool("all"), flags.Float("ratio"))
+	}
+	if got := flags.Strings("exclude"); !reflect.DeepEqual(got, []string{"*.tmp", "*.log"}) {
+		t.Errorf("Strings(exclude) = %q", got)
+	}
+	if flags.Changed("name") || flags.String("name") != "" {
+		t.Error("--name after -- should be positional")
+	}
+}
+
+func TestFlagSetDefaults(t *testing.T) {
+	flags, err := testFlagSet().Parse([]string{"src", "-p9001", "--verbose", "--no-verbose"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if flags.Int("port") != 9001 || flags.Bool("verbose") || flags.Float("ratio") != 0.5 || flags.Changed("ratio") {
+		t.Errorf("unexpected values: port=%d verbose=%v ratio=%v", flags.Int("port"), flags.Bool("verbose"), flags.Float("ratio"))
+	}
+	if flags.Arg(1) != "" {
+		t.Errorf("missing positionals should be empty, got %q", flags.Arg(1))
+	}
+}
+
+func TestFlagSetErrors(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--bogus"}, "unknown flag --bogus"},
+		{[]string{"-x"}, "unknown flag -x"},
+		{[]string{"--port"}, "needs a value"},
+		{[]string{"-p", "ten"}, "expected a whole number"},
+		{[]string{"--ratio", "lots"}, "expected a number"},
+		{[]string{"--verbose=maybe"}, "expected true or false"},
+	}
+	for _, tt := range tests {
+		_, err := testFlagSet().Parse(tt.args)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Parse(%q) error = %v, want %q", tt.args, err, tt.want)
+		}
+	}
+}
+
+func TestFlagSetSize(t *testing.T) {
+	set := commands.NewFlagSet("send", "send <file> [--block-size <size>]",
+		commands.FlagSpec{Name: "block-size", Kind: commands.SizeFlag, Default: "64K", Value: "size", Help: "Buffer size"},
+	)
+	flags, err := set.Parse([]string{"file"})
+	if err != nil || flags.Size("block-size") != 64*1024 {
+		t.Fatalf("default block size = %d, %v", flags.Size("block-size"), err)
+	}
+	flags, err = set.Parse([]string{"--block-size", "2M", "file"})
+	if err != nil || flags.Size("block-size") != 2<<20 {
+		t.Errorf("--block-size 2M = %d, %v", flags.Size("block-size"), err)
+	}
+	if _, err := set.Parse([]string{"--block-size=lots"}); err == nil || !strings.Contains(err.Error(), "expected a size") {
+		t.Errorf("invalid size error = %v", err)
+	}
+}
+
+func TestFlagSetHelp(t *testing.T) {
+	set := testFlagSet()
+	for _, arg := range []string{"--help", "-h"} {
+		if _, err := set.Parse([]string{"src", arg}); err != commands.ErrHelp {
+			t.Errorf("Parse(%s) error = %v, want ErrHelp", arg, err)
+		}
+	}
+
+	flags, result := set.ParseArguments(commands.ParseArguments([]string{"--help"}), time.Now())
+	if flags != nil || result == nil || result.ExitCode != 0 || result.Error != nil {
+		t.Fatalf("--help should return a successful result, got %+v", result)
+	}
+	for _, want := range []string{"Usage: copy", "-p, --port <port>", "(default 8888)", "--exclude <glob>", "(repeatable)", "-h, --help"} {
+		if !strings.Contains(result.Output, want) {
+			t.Errorf("help is missing %q:\n%s", want, result.Output)
+		}
+	}
+
+	_, result = set.ParseArguments(commands.ParseArguments([]string{"--bogus"}), time.Now())
+	if result == nil || result.ExitCode != 1 || result.Error == nil || !strings.Contains(result.Error.Error(), "copy: unknown flag --bogus") {
+		t.Errorf("unknown flags should be a usage error, got %+v", result)
+	}
+}
+
+func TestFlagSetSpec(t *testing.T) {
+	set := testFlagSet()
+	for _, word := range []string{"--port", "-p", "--port=1"} {
+		if spec, ok := set.Spec(word); !ok || spec.Name != "port" {
+			t.Errorf("Spec(%q) = %+v, %v", word, spec, ok)
+		}
+	}
+	if _, ok := set.Spec("port"); ok {
+		t.Error("positional words are not flags")
+	}
+}
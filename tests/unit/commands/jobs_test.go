@@ -0,0 +1,154 @@
+package commands_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"suppercommand/internal/commands"
+)
+
+// waitJob waits for a job to end, failing the test if it takes too long
+func waitJob(t *testing.T, job *commands.Job) {
+	t.Helper()
+	select {
+	case <-job.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("job %d didn't end", job.ID)
+	}
+}
+
+func TestJobTable(t *testing.T) {
+	jobs := commands.NewJobTable()
+
+	ok := jobs.Start(context.Background(), "echo hi", func(ctx context.Context, output io.Writer) (int, error) {
+		io.WriteString(output, "hi\n")
+		return 0, nil
+	})
+	failed := jobs.Start(context.Background(), "false", func(ctx context.Context, output io.Writer) (int, error) {
+		return 2, errors.New("exit status 2")
+	})
+	waitJob(t, ok)
+	waitJob(t, failed)
+
+	if ok.ID != 1 || failed.ID != 2 {
+		t.Errorf("jobs should be numbered from 1, got %d and %d", ok.ID, failed.ID)
+	}
+	if ok.State() != commands.JobDone || ok.Output() != "hi\n" {
+		t.Errorf("job 1 is %s with output %q", ok.State(), ok.Output())
+	}
+	if failed.State() != commands.JobFailed || failed.ExitCode() != 2 || failed.Err() == nil {
+		t.Errorf("job 2 is %s with exit code %d and error %v", failed.State(), failed.ExitCode(), failed.Err())
+	}
+
+	if finished := jobs.Finished(); len(finished) != 2 {
+		t.Errorf("both jobs should be announced once, got %d", len(finished))
+	}
+	if finished := jobs.Finished(); len(finished) != 0 {
+		t.Errorf("jobs should only be announced once, got %d again", len(finished))
+	}
+
+	jobs.Remove(1)
+	if _, found := jobs.Get(1); found || len(jobs.List()) != 1 {
+		t.Errorf("job 1 should be gone, have %d jobs", len(jobs.List()))
+	}
+}
+
+func TestJob_Kill(t *testing.T) {
+	jobs := commands.NewJobTable()
+	started := make(chan struct{})
+	job := jobs.Start(context.Background(), "fastcp-recv key --serve", func(ctx context.Context, output io.Writer) (int, error) {
+		close(started)
+		<-ctx.Done()
+		return 1, ctx.Err()
+	})
+	<-started
+	if job.State() != commands.JobRunning {
+		t.Fatalf("job should be running, is %s", job.State())
+	}
+
+	job.Kill()
+	waitJob(t, job)
+	if job.State() != commands.JobKilled || !errors.Is(job.Err(), context.Canceled) {
+		t.Errorf("a killed job should end killed, is %s with %v", job.State(), job.Err())
+	}
+}
+
+func TestJobTable_StopAll(t *testing.T) {
+	jobs := commands.NewJobTable()
+	for i := 0; i < 3; i++ {
+		jobs.Start(context.Background(), "sleep", func(ctx context.Context, output io.Writer) (int, error) {
+			<-ctx.Done()
+			return 1, ctx.Err()
+		})
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := jobs.StopAll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, job := range jobs.List() {
+		if job.State() != commands.JobKilled {
+			t.Errorf("job %d is %s after StopAll", job.ID, job.State())
+		}
+	}
+}
+
+func TestJob_OutputSince(t *testing.T) {
+	jobs := commands.NewJobTable()
+	release := make(chan struct{})
+	job := jobs.Start(context.Background(), "progress", func(ctx context.Context, output io.Writer) (int, error) {
+		io.WriteString(output, "one\n")
+		<-release
+		io.WriteString(output, "two\n")
+		return 0, nil
+	})
+
+	deadline := time.Now().Add(5 * time.Second)
+	text, offset := job.OutputSince(0)
+	for text == "" && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+		text, offset = job.OutputSince(0)
+	}
+	if text != "one\n" {
+		t.Fatalf("first read = %q", text)
+	}
+	close(release)
+	waitJob(t, job)
+	if text, _ = job.OutputSince(offset); text != "two\n" {
+		t.Errorf("reading on from the offset = %q", text)
+	}
+
+	// Only the last megabyte of a chatty job is kept
+	big := jobs.Start(context.Background(), "chatty", func(ctx context.Context, output io.Writer) (int, error) {
+		line := strings.Repeat("x", 1023) + "\n"
+		for i := 0; i < 2048; i++ {
+			io.WriteString(output, line)
+		}
+		io.WriteString(output, "end\n")
+		return 0, nil
+	})
+	waitJob(t, big)
+	if output := big.Output(); len(output) != 1<<20 || !strings.HasSuffix(output, "end\n") {
+		t.Errorf("kept %d bytes, ending %q", len(output), output[len(output)-4:])
+	}
+	if text, _ := big.OutputSince(10); len(text) != 1<<20 {
+		t.Errorf("an offset before the kept output should read all of it, got %d bytes", len(text))
+	}
+}
+
+func TestParseJobID(t *testing.T) {
+	for spec, want := range map[string]int{"%1": 1, "12": 12, "%3": 3} {
+		if id, err := commands.ParseJobID(spec); err != nil || id != want {
+			t.Errorf("ParseJobID(%q) = %d, %v", spec, id, err)
+		}
+	}
+	for _, spec := range []string{"%", "%0", "%x", "-1", ""} {
+		if _, err := commands.ParseJobID(spec); err == nil {
+			t.Errorf("ParseJobID(%q) should fail", spec)
+		}
+	}
+}
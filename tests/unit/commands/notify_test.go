@@ -0,0 +1,44 @@
+package commands_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"suppercommand/internal/commands"
+)
+
+func TestCompletionNotice(t *testing.T) {
+	title, message := commands.CompletionNotice("fastcp-send", 0, 90*time.Second)
+	if !strings.Contains(title, "fastcp-send finished") || !strings.Contains(message, "1m30s") {
+		t.Errorf("unexpected success notice %q / %q", title, message)
+	}
+
+	title, message = commands.CompletionNotice("portscan", 2, time.Second)
+	if !strings.Contains(title, "failed") || !strings.Contains(message, "code 2") {
+		t.Errorf("unexpected failure notice %q / %q", title, message)
+	}
+}
+
+func TestSendNotification_NoDisplay(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("display detection only applies on linux")
+	}
+
+	for _, name := range []string{"DISPLAY", "WAYLAND_DISPLAY"} {
+		old, had := os.LookupEnv(name)
+		os.Unsetenv(name)
+		if had {
+			defer os.Setenv(name, old)
+		}
+	}
+
+	err := commands.SendNotification(context.Background(), "title", "message")
+	if !errors.Is(err, commands.ErrNotificationsUnavailable) {
+		t.Errorf("expected ErrNotificationsUnavailable, got %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package commands_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/config"
+	"suppercommand/internal/monitoring"
+)
+
+// formattedCommand is a MockCommand that supports JSON output
+type formattedCommand struct {
+	MockCommand
+}
+
+func (f *formattedCommand) OutputFormats() []commands.OutputFormat {
+	return []commands.OutputFormat{commands.FormatJSON}
+}
+
+func TestExtractOutputOptions(t *testing.T) {
+	options, rest, err := commands.ExtractOutputOptions([]string{"-a", "--quiet", "--output-format", "JSON", "host", "--", "--quiet"}, commands.OutputOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if options.Format != commands.FormatJSON || !options.Quiet || !options.Structured() || options.Decorated() {
+		t.Errorf("unexpected options %+v", options)
+	}
+	if want := []string{"-a", "host", "--", "--quiet"}; !reflect.DeepEqual(rest, want) {
+		t.Errorf("remaining args = %q, want %q", rest, want)
+	}
+
+	if options, _, _ := commands.ExtractOutputOptions([]string{"--output-format=csv"}, commands.OutputOptions{}); options.Format != commands.FormatCSV {
+		t.Errorf("--output-format=csv gave %+v", options)
+	}
+	for _, raw := range [][]string{{"--output-format"}, {"--output-format", "xml"}} {
+		if _, _, err := commands.ExtractOutputOptions(raw, commands.OutputOptions{}); err == nil {
+			t.Errorf("%q should be rejected", raw)
+		}
+	}
+}
+
+func TestRegistry_OutputOptions(t *testing.T) {
+	registry := commands.NewRegistry(monitoring.NewLogger(config.MonitoringConfig{}))
+
+	var seen commands.OutputOptions
+	var seenArgs []string
+	record := func(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+		seen = commands.OutputOptionsFrom(ctx)
+		seenArgs = args.Raw
+		return &commands.Result{}, nil
+	}
+	registry.Register(&formattedCommand{MockCommand{name: "report", usage: "report", executeFunc: record}})
+	registry.Register(&MockCommand{name: "plain", usage: "plain", executeFunc: record})
+
+	if _, err := registry.Execute(context.Background(), "report", commands.ParseArguments([]string{"x", "--output-format", "json", "--quiet"})); err != nil {
+		t.Fatal(err)
+	}
+	if seen.Format != commands.FormatJSON || !seen.Quiet || !reflect.DeepEqual(seenArgs, []string{"x"}) {
+		t.Errorf("report got options %+v and args %q", seen, seenArgs)
+	}
+
+	result, err := registry.Execute(context.Background(), "report", commands.ParseArguments([]string{"--output-format", "csv"}))
+	if err != nil || result.ExitCode != 1 || result.Error == nil || !strings.Contains(result.Error.Error(), "use text, json") {
+		t.Errorf("unsupported formats should be a usage error, got %+v, %v", result, err)
+	}
+
+	// Commands without structured output keep their arguments as typed
+	if _, err := registry.Execute(context.Background(), "plain", commands.ParseArguments([]string{"--quiet"})); err != nil {
+		t.Fatal(err)
+	}
+	if seen.Format != commands.FormatText || seen.Quiet || !reflect.DeepEqual(seenArgs, []string{"--quiet"}) {
+		t.Errorf("plain got options %+v and args %q", seen, seenArgs)
+	}
+}
+
+func TestErrorWriter(t *testing.T) {
+	if commands.ErrorWriter(context.Background()) != os.Stderr {
+		t.Error("diagnostics should go to stderr by default")
+	}
+
+	var out, diagnostics bytes.Buffer
+	ctx := commands.WithOutputWriter(context.Background(), &out)
+	if commands.ErrorWriter(ctx) != &out {
+		t.Error("with only an output writer, diagnostics should go to it too")
+	}
+	ctx = commands.WithErrorWriter(ctx, &diagnostics)
+	if commands.ErrorWriter(ctx) != &diagnostics || commands.OutputWriter(ctx) != &out {
+		t.Error("an error writer should take diagnostics only")
+	}
+	if commands.ProgressWriter(ctx) != &diagnostics {
+		t.Error("live progress should go to the error writer")
+	}
+}
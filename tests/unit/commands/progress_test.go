@@ -0,0 +1,73 @@
+package commands_test
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"suppercommand/internal/commands"
+)
+
+func TestProgressReporter_SmoothedSpeedAndETA(t *testing.T) {
+	progress := commands.NewProgressReporter(10000, 4, nil)
+	if progress.ETA() >= 0 {
+		t.Errorf("ETA should be unknown before any sample, got %v", progress.ETA())
+	}
+	if line := progress.Line(); !strings.Contains(line, "ETA: --") {
+		t.Errorf("expected an unknown ETA in %q", line)
+	}
+
+	base := time.Now()
+	progress.UpdateAt(1000, 1, base.Add(time.Second))
+	if math.Abs(progress.Speed()-1000) > 0.1 {
+		t.Errorf("first sample should set the speed directly, got %v", progress.Speed())
+	}
+
+	// 2000 B/s blended into the previous 1000 B/s
+	progress.UpdateAt(3000, 2, base.Add(2*time.Second))
+	if math.Abs(progress.Speed()-1300) > 0.1 {
+		t.Errorf("smoothed speed = %v, want 1300", progress.Speed())
+	}
+
+	remaining := 7000.0
+	want := time.Duration(remaining / 1300 * float64(time.Second))
+	if diff := progress.ETA() - want; diff < -time.Millisecond || diff > time.Millisecond {
+		t.Errorf("ETA = %v, want %v", progress.ETA(), want)
+	}
+
+	// Samples closer together than the sample interval are folded into the next one
+	progress.UpdateAt(3100, 2, base.Add(2*time.Second+50*time.Millisecond))
+	if math.Abs(progress.Speed()-1300) > 0.1 {
+		t.Errorf("speed should not change within a sample interval, got %v", progress.Speed())
+	}
+
+	line := progress.Line()
+	for _, part := range []string{"31%", "2/4 files", "ETA: 5s"} {
+		if !strings.Contains(line, part) {
+			t.Errorf("expected %q in %q", part, line)
+		}
+	}
+}
+
+func TestProgressReporter_Finish(t *testing.T) {
+	var live bytes.Buffer
+	progress := commands.NewProgressReporter(2048, 0, &live)
+
+	if _, err := progress.Write(make([]byte, 2048)); err != nil {
+		t.Fatal(err)
+	}
+	progress.CompleteFile()
+
+	final := progress.Finish()
+	if !strings.HasSuffix(live.String(), "\r\033[K") {
+		t.Errorf("Finish should clear the live line, got %q", live.String())
+	}
+	if !strings.Contains(final, "100%") || !strings.Contains(final, "ETA: 0s") || !strings.HasSuffix(final, "\n") {
+		t.Errorf("unexpected final line %q", final)
+	}
+	if progress.ETA() != 0 {
+		t.Errorf("a finished transfer has no time remaining, got %v", progress.ETA())
+	}
+}
@@ -0,0 +1,84 @@
+package commands_test
+
+import (
+	"strings"
+	"testing"
+
+	"suppercommand/internal/commands"
+)
+
+func TestEncodeQR_PicksSmallestVersion(t *testing.T) {
+	tests := []struct {
+		length int
+		size   int
+	}{
+		{1, 21}, // version 1 holds 14 bytes at level M
+		{14, 21},
+		{15, 25}, // version 2
+		{62, 33}, // version 4
+		{63, 37},
+		{213, 57}, // version 10, the largest supported
+	}
+	for _, tt := range tests {
+		qr, err := commands.EncodeQR(strings.Repeat("k", tt.length))
+		if err != nil {
+			t.Fatalf("%d bytes: %v", tt.length, err)
+		}
+		if qr.Size() != tt.size {
+			t.Errorf("%d bytes should make a %dx%d code, got %d", tt.length, tt.size, tt.size, qr.Size())
+		}
+	}
+
+	if _, err := commands.EncodeQR(strings.Repeat("k", 214)); err == nil {
+		t.Error("214 bytes should be too long")
+	}
+}
+
+func TestEncodeQR_FunctionPatterns(t *testing.T) {
+	qr, err := commands.EncodeQR("correct-horse-battery-staple")
+	if err != nil {
+		t.Fatal(err)
+	}
+	size := qr.Size()
+
+	// Each finder is a dark ring, a light ring and a dark 3x3 centre
+	for _, corner := range [][2]int{{0, 0}, {size - 7, 0}, {0, size - 7}} {
+		for dy := 0; dy < 7; dy++ {
+			for dx := 0; dx < 7; dx++ {
+				ring := dx == 0 || dy == 0 || dx == 6 || dy == 6
+				centre := dx >= 2 && dx <= 4 && dy >= 2 && dy <= 4
+				if qr.Dark(corner[0]+dx, corner[1]+dy) != (ring || centre) {
+					t.Fatalf("finder at %v is wrong at %d,%d", corner, dx, dy)
+				}
+			}
+		}
+	}
+	for i := 8; i < size-8; i++ {
+		if qr.Dark(i, 6) != (i%2 == 0) || qr.Dark(6, i) != (i%2 == 0) {
+			t.Fatalf("timing patterns should alternate, wrong at %d", i)
+		}
+	}
+	if !qr.Dark(8, size-8) {
+		t.Error("the module beside the bottom-left finder is always dark")
+	}
+}
+
+func TestRenderQR(t *testing.T) {
+	out, err := commands.RenderQR("hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	// 21 modules plus a quiet zone of 2 on each side, two rows to a line
+	if len(lines) != 13 {
+		t.Fatalf("expected 13 lines, got %d:\n%s", len(lines), out)
+	}
+	for _, line := range lines {
+		if n := len([]rune(line)); n != 25 {
+			t.Fatalf("every line should be 25 columns, got %d:\n%s", n, out)
+		}
+	}
+	if strings.Trim(lines[0], "█") != "" {
+		t.Errorf("the code should start with the light quiet zone:\n%s", out)
+	}
+}
@@ -1,13 +1,18 @@
 package commands_test
 
 import (
+	"bytes"
 	"context"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
 	"testing"
 	"time"
 
 	"suppercommand/internal/commands"
 	"suppercommand/internal/config"
 	"suppercommand/internal/monitoring"
+	"suppercommand/internal/security"
 )
 
 // MockCommand implements the Command interface for testing
@@ -114,6 +119,90 @@ func TestRegistry_List(t *testing.T) {
 	}
 }
 
+func TestRegistry_ExecuteLine(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	registry := commands.NewRegistry(monitoring.NewLogger(config.MonitoringConfig{}))
+	var got []string
+	registry.Register(&MockCommand{
+		name:      "echoargs",
+		platforms: []string{"windows", "linux", "darwin"},
+		executeFunc: func(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+			got = args.Raw
+			return &commands.Result{}, nil
+		},
+	})
+
+	glob := filepath.Join(dir, "*.txt")
+	if _, err := registry.ExecuteLine(context.Background(), "echoargs "+commands.QuoteArgument(dir)+"/{x,y} "+glob+" "+commands.QuoteArgument(glob)); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{dir + "/x", dir + "/y", filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt"), glob}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExecuteLine() args = %q, want %q", got, want)
+	}
+
+	// A glob matching nothing follows the registry's policy
+	missing := filepath.Join(dir, "*.log")
+	registry.SetGlobNoMatch(commands.GlobNoMatchError)
+	result, err := registry.ExecuteLine(context.Background(), "echoargs "+missing)
+	if err != nil || result.ExitCode == 0 || result.Error == nil {
+		t.Errorf("ExecuteLine() with an unmatched glob = %+v, %v, want a failed result", result, err)
+	}
+	registry.SetGlobNoMatch(commands.GlobNoMatchNull)
+	if _, err := registry.ExecuteLine(context.Background(), "echoargs "+missing); err != nil || len(got) != 0 {
+		t.Errorf("ExecuteLine() with the null policy args = %q, %v, want none", got, err)
+	}
+}
+
+func TestRegistry_AuditsNestedCommands(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	audit := security.NewAuditLog(config.AuditConfig{Enabled: true, File: path})
+
+	registry := commands.NewRegistry(monitoring.NewLogger(config.MonitoringConfig{}))
+	registry.SetAuditLog(audit)
+	platforms := []string{"windows", "linux", "darwin"}
+	registry.Register(&MockCommand{name: "inner", platforms: platforms})
+	registry.Register(&MockCommand{
+		name:      "outer",
+		platforms: platforms,
+		executeFunc: func(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+			// Like each or source, run another command through the registry
+			return registry.ExecuteLine(ctx, "inner 'two words'")
+		},
+	})
+
+	if _, err := registry.Execute(context.Background(), "outer", commands.ParseArguments([]string{"--token=s3cret"})); err != nil {
+		t.Fatal(err)
+	}
+	if err := audit.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, _, err := security.ScanAuditLog(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, entry := range entries {
+		got = append(got, entry.Command)
+	}
+	// The inner command finishes first
+	want := []string{`inner "two words"`, "outer --token=********"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("audited %q, want %q", got, want)
+	}
+}
+
 func TestParseArguments(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -158,3 +247,14 @@ func TestParseArguments(t *testing.T) {
 		})
 	}
 }
+
+func TestUsageFlags(t *testing.T) {
+	got := commands.UsageFlags("logtail [text] [--source <unit>] [-n <lines>] [-f|--follow] [--json] [--json]")
+	want := []string{"--source", "-n", "-f", "--follow", "--json"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("UsageFlags() = %v, want %v", got, want)
+	}
+	if got := commands.UsageFlags("fastcp-backup <source> <bucket> [file|-]"); got != nil {
+		t.Errorf("hyphenated names and bare dashes aren't flags: %v", got)
+	}
+}
@@ -0,0 +1,108 @@
+package commands_test
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"runtime"
+	"testing"
+	"time"
+
+	"suppercommand/internal/commands"
+)
+
+func TestExecRunner(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("runs sh")
+	}
+	runner := commands.ExecRunner{}
+
+	out, err := runner.Run(context.Background(), "sh", "-c", "echo out; echo oops >&2")
+	if err != nil || string(out) != "out\n" {
+		t.Errorf("expected only stdout back, got %q, %v", out, err)
+	}
+
+	out, err = runner.Run(context.Background(), "sh", "-c", "echo partial; echo broken >&2; exit 3")
+	var runErr *commands.RunError
+	if !errors.As(err, &runErr) {
+		t.Fatalf("a failing tool should return a *RunError, got %v", err)
+	}
+	if runErr.Name != "sh" || runErr.Stderr != "broken" || string(out) != "partial\n" {
+		t.Errorf("unexpected failure %+v with output %q", runErr, out)
+	}
+	if err.Error() != "sh: exit status 3: broken" {
+		t.Errorf("unexpected message %q", err)
+	}
+
+	if _, err := runner.Run(context.Background(), "no-such-tool-0"); !errors.Is(err, exec.ErrNotFound) {
+		t.Errorf("a missing tool should be exec.ErrNotFound, got %v", err)
+	}
+}
+
+func TestExecRunner_Cancelled(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("runs sleep")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := commands.ExecRunner{}.Run(ctx, "sleep", "5")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the deadline as the error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("the tool should be killed when the context ends, took %v", elapsed)
+	}
+}
+
+func TestMockRunner(t *testing.T) {
+	mock := commands.NewMockRunner().
+		On("ss -tunap", "tcp LISTEN 0 128 0.0.0.0:22 0.0.0.0:*\n").
+		Fail("ip route", errors.New("exit status 2"))
+
+	out, err := mock.Run(context.Background(), "ss", "-tunap")
+	if err != nil || string(out) != "tcp LISTEN 0 128 0.0.0.0:22 0.0.0.0:*\n" {
+		t.Errorf("expected the canned output, got %q, %v", out, err)
+	}
+	if _, err := mock.Run(context.Background(), "ip", "route"); err == nil || err.Error() != "ip: exit status 2" {
+		t.Errorf("expected the canned failure, got %v", err)
+	}
+	if _, err := mock.Run(context.Background(), "arp", "-a"); !errors.Is(err, exec.ErrNotFound) {
+		t.Errorf("a command line without output should look uninstalled, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := mock.Run(ctx, "ss", "-tunap"); !errors.Is(err, context.Canceled) {
+		t.Errorf("a cancelled context should fail the run, got %v", err)
+	}
+
+	calls := mock.Calls()
+	if len(calls) != 4 || calls[0] != "ss -tunap" || !mock.Ran("arp -a") || mock.Ran("netstat -an") {
+		t.Errorf("unexpected calls %v", calls)
+	}
+
+	if _, err := mock.LookPath("ss"); err != nil {
+		t.Errorf("tools with output should be found: %v", err)
+	}
+	if _, err := mock.LookPath("netstat"); !errors.Is(err, exec.ErrNotFound) {
+		t.Errorf("tools without output shouldn't be found, got %v", err)
+	}
+	mock.Installed = map[string]bool{"netstat": true}
+	if _, err := mock.LookPath("ss"); err == nil {
+		t.Error("with Installed set only those tools should be found")
+	}
+}
+
+func TestToolRunner(t *testing.T) {
+	var tools commands.ToolRunner
+	if _, ok := tools.Runner().(commands.ExecRunner); !ok {
+		t.Errorf("commands should run tools with os/exec by default, got %T", tools.Runner())
+	}
+	mock := commands.NewMockRunner()
+	tools.SetRunner(mock)
+	if tools.Runner() != mock {
+		t.Error("SetRunner should replace the runner")
+	}
+}
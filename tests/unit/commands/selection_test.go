@@ -0,0 +1,41 @@
+package commands_test
+
+import (
+	"reflect"
+	"testing"
+
+	"suppercommand/internal/commands"
+)
+
+func TestParseSelection(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []int
+	}{
+		{"1", []int{0}},
+		{"3,1", []int{0, 2}},
+		{"2-4 6", []int{1, 2, 3, 5}},
+		{"1-2, 2-3", []int{0, 1, 2}},
+		{"all", []int{0, 1, 2, 3, 4, 5}},
+		{"", []int{}},
+	}
+
+	for _, tt := range tests {
+		got, err := commands.ParseSelection(tt.input, 6)
+		if err != nil {
+			t.Errorf("ParseSelection(%q) error = %v", tt.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseSelection(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseSelection_Invalid(t *testing.T) {
+	for _, input := range []string{"0", "7", "5-2", "x", "1-", "-3"} {
+		if _, err := commands.ParseSelection(input, 6); err == nil {
+			t.Errorf("ParseSelection(%q) should fail", input)
+		}
+	}
+}
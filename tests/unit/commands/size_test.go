@@ -0,0 +1,110 @@
+package commands_test
+
+import (
+	"math"
+	"testing"
+
+	"suppercommand/internal/commands"
+)
+
+func TestHumanizeBytes(t *testing.T) {
+	tests := []struct {
+		bytes int64
+		want  string
+	}{
+		{0, "0 B"},
+		{1, "1 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KB"},
+		{1536, "1.5 KB"},
+		{1024*1024 - 1, "1024.0 KB"},
+		{1024 * 1024, "1.0 MB"},
+		{5 * 1024 * 1024 * 1024 / 2, "2.5 GB"},
+		{1 << 40, "1.0 TB"},
+		{1 << 50, "1.0 PB"},
+		{1 << 60, "1.0 EB"},
+		{math.MaxInt64, "8.0 EB"},
+		{-1536, "-1.5 KB"},
+		{-1, "-1 B"},
+		{math.MinInt64, "-8.0 EB"},
+	}
+	for _, tt := range tests {
+		if got := commands.HumanizeBytes(tt.bytes); got != tt.want {
+			t.Errorf("HumanizeBytes(%d) = %q, want %q", tt.bytes, got, tt.want)
+		}
+	}
+}
+
+func TestParseBytes(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int64
+	}{
+		{"0", 0},
+		{"4096", 4096},
+		{"512B", 512},
+		{"512K", 512 * 1024},
+		{"512k", 512 * 1024},
+		{"512KB", 512 * 1024},
+		{"512KiB", 512 * 1024},
+		{"2M", 2 << 20},
+		{"2mb", 2 << 20},
+		{"2G", 2 << 30},
+		{"1T", 1 << 40},
+		{"1P", 1 << 50},
+		{"7E", 7 << 60},
+		{"1.5K", 1536},
+		{"0.5M", 512 * 1024},
+		{".5K", 512},
+		{"1.3K", 1331},
+		{"  64 K  ", 64 * 1024},
+		{"1.5 KB", 1536},
+		{"2.0 GB", 2 << 30},
+		{"9223372036854775807", math.MaxInt64},
+	}
+	for _, tt := range tests {
+		got, err := commands.ParseBytes(tt.input)
+		if err != nil {
+			t.Errorf("ParseBytes(%q) failed: %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseBytes(%q) = %d, want %d", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseBytes_Invalid(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"   ",
+		"K",
+		"-1",
+		"-2K",
+		"2X",
+		"2KX",
+		"2BB",
+		"2 megabytes",
+		"1.2.3K",
+		".",
+		"8E",
+		"9223372036854775808",
+		"1e3",
+		"99999999999P",
+	} {
+		if got, err := commands.ParseBytes(input); err == nil {
+			t.Errorf("ParseBytes(%q) = %d, want an error", input, got)
+		}
+	}
+}
+
+func TestHumanizeBytes_RoundTrip(t *testing.T) {
+	// Whole units survive formatting and parsing unchanged
+	for _, bytes := range []int64{0, 1, 1023, 1024, 1536, 3 << 20, 5 << 30, 1 << 40} {
+		text := commands.HumanizeBytes(bytes)
+		got, err := commands.ParseBytes(text)
+		if err != nil || got != bytes {
+			t.Errorf("ParseBytes(HumanizeBytes(%d) = %q) = %d, %v", bytes, text, got, err)
+		}
+	}
+}
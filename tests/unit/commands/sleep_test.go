@@ -0,0 +1,32 @@
+package commands_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"suppercommand/internal/commands"
+)
+
+func TestSleep_Completes(t *testing.T) {
+	if err := commands.Sleep(context.Background(), time.Millisecond); err != nil {
+		t.Errorf("Sleep() error = %v, want nil", err)
+	}
+}
+
+func TestSleep_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	err := commands.Sleep(ctx, 10*time.Second)
+	if err != context.Canceled {
+		t.Errorf("Sleep() error = %v, want %v", err, context.Canceled)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Sleep() returned after %v, expected prompt cancellation", elapsed)
+	}
+}
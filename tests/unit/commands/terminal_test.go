@@ -0,0 +1,76 @@
+package commands_test
+
+import (
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	"suppercommand/internal/commands"
+)
+
+// setTerminalEnv sets the variables terminal detection reads, clearing the
+// others, and returns a function restoring them all
+func setTerminalEnv(values map[string]string) func() {
+	names := []string{"TERM", "TERM_PROGRAM", "TERM_PROGRAM_VERSION", "COLORTERM", "NO_COLOR", "LC_ALL", "LC_CTYPE", "LANG", "COLUMNS", "LINES"}
+	saved := make(map[string]string)
+	for _, name := range names {
+		saved[name] = os.Getenv(name)
+		os.Setenv(name, values[name])
+	}
+	return func() {
+		for name, value := range saved {
+			os.Setenv(name, value)
+		}
+	}
+}
+
+func TestDetectTerminal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the Windows console is detected through its API")
+	}
+	tests := []struct {
+		env    map[string]string
+		colors commands.ColorSupport
+		utf8   bool
+	}{
+		{map[string]string{"TERM": "xterm-256color", "LANG": "en_US.UTF-8"}, commands.Color256, true},
+		{map[string]string{"TERM": "xterm", "COLORTERM": "truecolor", "LC_ALL": "de_DE.utf8", "LANG": "C"}, commands.ColorTrue, true},
+		{map[string]string{"TERM": "xterm-256color", "TERM_PROGRAM": "iTerm.app", "LC_CTYPE": "en_US.UTF-8@euro"}, commands.ColorTrue, true},
+		{map[string]string{"TERM": "vt100", "LANG": "en_US.ISO-8859-1"}, commands.Color16, false},
+		{map[string]string{"TERM": "dumb", "LANG": "C"}, commands.ColorNone, false},
+		{map[string]string{"TERM": "xterm-256color", "NO_COLOR": "1", "LANG": "en_US.UTF-8"}, commands.ColorNone, true},
+		{map[string]string{}, commands.ColorNone, false},
+	}
+	for _, test := range tests {
+		restore := setTerminalEnv(test.env)
+		info := commands.DetectTerminal()
+		restore()
+
+		if info.Colors != test.colors || info.UTF8 != test.utf8 || info.ASCII == test.utf8 {
+			t.Errorf("%v: expected %v and UTF-8 %v, got %+v", test.env, test.colors, test.utf8, info)
+		}
+	}
+}
+
+func TestDetectTerminal_DescribesTheTerminal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the Windows console is detected through its API")
+	}
+	restore := setTerminalEnv(map[string]string{
+		"TERM": "xterm-256color", "TERM_PROGRAM": "WezTerm", "TERM_PROGRAM_VERSION": "20240203", "LANG": "C", "COLUMNS": "132", "LINES": "43",
+	})
+	defer restore()
+
+	info := commands.DetectTerminal()
+	if info.Term != "xterm-256color" || info.Program != "WezTerm 20240203" || info.ColorReason != "TERM_PROGRAM=WezTerm" {
+		t.Errorf("unexpected terminal %+v", info)
+	}
+	if !strings.HasPrefix(info.Encoding, "ASCII") || !info.ASCII {
+		t.Errorf("the C locale should fall back to ASCII, got %+v", info)
+	}
+	// Test output isn't a terminal, so the size comes from the environment
+	if info.Interactive || info.Width != 132 || info.Height != 43 {
+		t.Errorf("unexpected size %dx%d (interactive %v)", info.Width, info.Height, info.Interactive)
+	}
+}
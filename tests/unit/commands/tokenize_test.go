@@ -0,0 +1,38 @@
+package commands_test
+
+import (
+	"reflect"
+	"testing"
+
+	"suppercommand/internal/commands"
+)
+
+func TestSplitCommandLine(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"ls -la", []string{"ls", "-la"}},
+		{`schedule add "0 2 * * *" "fastcp-backup ./data bucket"`, []string{"schedule", "add", "0 2 * * *", "fastcp-backup ./data bucket"}},
+		{`echo 'single quoted'`, []string{"echo", "single quoted"}},
+		{`cd C:\Users\me`, []string{"cd", `C:\Users\me`}},
+		{`echo "say \"hi\""`, []string{"echo", `say "hi"`}},
+		{`echo ""`, []string{"echo", ""}},
+		{"  ", nil},
+	}
+
+	for _, tt := range tests {
+		if got := commands.SplitCommandLine(tt.input); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SplitCommandLine(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestQuoteArgument_RoundTrip(t *testing.T) {
+	for _, word := range []string{"plain", "two words", `C:\Program Files\app`, `say "hi"`, ""} {
+		got := commands.SplitCommandLine(commands.QuoteArgument(word))
+		if len(got) != 1 || got[0] != word {
+			t.Errorf("round trip of %q = %q", word, got)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package config_test
 
 import (
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"suppercommand/internal/config"
@@ -66,3 +68,73 @@ func TestConfigValidator_Validate(t *testing.T) {
 		t.Error("Validate() should fail for invalid config")
 	}
 }
+
+func TestConfigValidator_IPInfoEndpoint(t *testing.T) {
+	cfg := config.NewLoader().LoadWithDefaults()
+	if !strings.Contains(cfg.Networking.IPInfo.Endpoint, "{ip}") || cfg.Networking.IPInfo.CacheTTL <= 0 {
+		t.Errorf("unexpected ipinfo defaults: %+v", cfg.Networking.IPInfo)
+	}
+
+	cfg.Networking.IPInfo.Endpoint = "https://ipinfo.io/json"
+	if err := config.NewConfigValidator().Validate(cfg); err == nil {
+		t.Error("Validate() should reject an ipinfo endpoint without {ip}")
+	}
+}
+
+func TestConfigValidator_Decorations(t *testing.T) {
+	cfg := config.NewLoader().LoadWithDefaults()
+	if cfg.Shell.Decorations != "auto" {
+		t.Errorf("expected decorations to default to auto, got %q", cfg.Shell.Decorations)
+	}
+
+	cfg.Shell.Decorations = "ascii"
+	if err := config.NewConfigValidator().Validate(cfg); err != nil {
+		t.Errorf("Validate() rejected decorations: ascii: %v", err)
+	}
+	cfg.Shell.Decorations = "fancy"
+	if err := config.NewConfigValidator().Validate(cfg); err == nil {
+		t.Error("Validate() should reject an unknown decorations value")
+	}
+}
+
+func TestConfigValidator_ScriptGuard(t *testing.T) {
+	cfg := config.NewLoader().LoadWithDefaults()
+	if cfg.Security.Scripts.Guard != "run" || len(cfg.Security.Scripts.GuardedCommands) == 0 {
+		t.Errorf("unexpected script guard defaults: %+v", cfg.Security.Scripts)
+	}
+
+	cfg.Security.Scripts.Guard = "block"
+	if err := config.NewConfigValidator().Validate(cfg); err != nil {
+		t.Errorf("Validate() rejected guard: block: %v", err)
+	}
+	cfg.Security.Scripts.Guard = "off"
+	if err := config.NewConfigValidator().Validate(cfg); err == nil {
+		t.Error("Validate() should reject an unknown scripts guard")
+	}
+	cfg.Security.Scripts.Guard = "confirm"
+	cfg.Security.Scripts.GuardedCommands = []string{"rm", " "}
+	if err := config.NewConfigValidator().Validate(cfg); err == nil {
+		t.Error("Validate() should reject an empty guarded command")
+	}
+}
+
+func TestExpandHome(t *testing.T) {
+	t.Setenv("HOME", "/home/tester")
+	t.Setenv("USERPROFILE", "/home/tester")
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"~", filepath.Join("/home/tester")},
+		{"~/.supershell/audit.log", filepath.Join("/home/tester", ".supershell", "audit.log")},
+		{`~\.supershell`, filepath.Join("/home/tester", `\.supershell`)},
+		{"/var/log/audit.log", "/var/log/audit.log"},
+		{"~other/file", "~other/file"},
+	}
+	for _, tt := range tests {
+		if got := config.ExpandHome(tt.path); got != tt.want {
+			t.Errorf("ExpandHome(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
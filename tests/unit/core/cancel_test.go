@@ -0,0 +1,75 @@
+package core_test
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"suppercommand/internal/core"
+)
+
+func TestPortscan_IPv6(t *testing.T) {
+	listener, err := net.Listen("tcp", "[::1]:0")
+	if err != nil {
+		t.Skipf("IPv6 loopback unavailable: %v", err)
+	}
+	defer listener.Close()
+	port := strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+
+	output := (&core.PortscanCommand{}).Execute([]string{"::1", port})
+	if !strings.Contains(output, port+" OPEN") || !strings.Contains(output, "1 open, 0 closed") {
+		t.Errorf("portscan of ::1 should find port %s open:\n%s", port, output)
+	}
+}
+
+func TestPortscan_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	output := (&core.PortscanCommand{}).ExecuteContext(ctx, []string{"127.0.0.1", "1-50"})
+	if !strings.Contains(output, "interrupted, 0 of 50 ports scanned") {
+		t.Errorf("a cancelled portscan should scan nothing:\n%s", output)
+	}
+}
+
+func TestFastcpRecv_StopsWhenCancelled(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+	listener.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan string, 1)
+	go func() {
+		done <- (&core.FastcpRecvCommand{}).ExecuteContext(ctx, []string{"key", "--port", port, "--dst", t.TempDir(), "--listen", "127.0.0.1"})
+	}()
+
+	// Wait until the receiver listens, then interrupt it
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		conn, err := net.Dial("tcp", net.JoinHostPort("127.0.0.1", port))
+		if err == nil {
+			conn.Close()
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("fastcp-recv never listened: %v", err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	cancel()
+
+	select {
+	case output := <-done:
+		if !strings.Contains(output, "receiver stopped") {
+			t.Errorf("fastcp-recv = %q, want it stopped", output)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("fastcp-recv kept running after its context was cancelled")
+	}
+}
@@ -0,0 +1,139 @@
+package core_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"suppercommand/internal/core"
+)
+
+// goodSystem is the known-good side of a comparison
+func goodSystem() core.SystemInfo {
+	return core.SystemInfo{
+		Timestamp: "2026-10-01 09:00:00",
+		OS:        core.OSInfo{Name: "linux", Version: "6.1.0", Architecture: "amd64", Hostname: "web-1", Username: "ops", Uptime: "3 days"},
+		Hardware:  core.HWInfo{CPU: "Xeon", Memory: "16.00 GB", Disk: "/: 100 GB"},
+		Network: core.NetInfo{
+			Interfaces: []core.NetworkInterface{
+				{Name: "eth0", IP: "10.0.0.5", MAC: "aa:bb:cc:00:00:01"},
+				{Name: "eth1", IP: "10.0.1.5", MAC: "aa:bb:cc:00:00:02"},
+				{Name: "docker0", IP: "172.17.0.1", MAC: "02:42:00:00:00:01"},
+			},
+			DNS:     []string{"10.0.0.2", "1.1.1.1"},
+			Gateway: "10.0.0.1",
+		},
+		Software: core.SWInfo{Services: []string{"nginx", "sshd"}, Software: []string{"curl", "openssl"}},
+	}
+}
+
+// writeSystem exports info the way sysinfo --export does
+func writeSystem(t *testing.T, name string, info core.SystemInfo) string {
+	t.Helper()
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), name)
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// brokenSystem differs from goodSystem in every section but hardware
+func brokenSystem() core.SystemInfo {
+	info := goodSystem()
+	info.Timestamp = "2026-10-02 17:30:00"
+	info.OS.Hostname = "web-2"
+	info.OS.Uptime = "2 hours"
+	info.OS.Version = "6.5.0"
+	info.Network.DNS = []string{"1.1.1.1", "8.8.8.8"}
+	info.Network.Interfaces = []core.NetworkInterface{
+		{Name: "ens3", IP: "10.0.0.5", MAC: "aa:bb:cc:00:00:01"},
+		{Name: "eth1", IP: "10.0.1.9", MAC: "aa:bb:cc:00:00:02"},
+		{Name: "docker0", IP: "172.17.0.1", MAC: "02:42:00:00:00:01"},
+		{Name: "wg0", IP: "10.8.0.2", MAC: ""},
+	}
+	info.Software.Software = []string{"openssl", "tcpdump"}
+	return info
+}
+
+func TestCompareSystems_JSON(t *testing.T) {
+	first, second := writeSystem(t, "good.json", goodSystem()), writeSystem(t, "broken.json", brokenSystem())
+
+	var diff core.SystemDiff
+	if err := json.Unmarshal([]byte((&core.CompareSystemsCommand{}).Execute([]string{first, second, "--json"})), &diff); err != nil {
+		t.Fatal(err)
+	}
+	if diff.First != first || diff.Second != second {
+		t.Errorf("files = %s and %s, want %s and %s", diff.First, diff.Second, first, second)
+	}
+
+	want := []core.SystemChange{
+		{Section: "os", Field: "Version", Kind: "changed", First: "6.1.0", Second: "6.5.0"},
+		{Section: "network", Field: "DNS", Kind: "removed", First: "10.0.0.2"},
+		{Section: "network", Field: "DNS", Kind: "added", Second: "8.8.8.8"},
+		{Section: "network", Field: "Interface ens3", Kind: "added", Second: "10.0.0.5 (aa:bb:cc:00:00:01)"},
+		{Section: "network", Field: "Interface eth1", Kind: "changed", First: "10.0.1.5 (aa:bb:cc:00:00:02)", Second: "10.0.1.9 (aa:bb:cc:00:00:02)"},
+		{Section: "network", Field: "Interface wg0", Kind: "added", Second: "10.8.0.2 ()"},
+		{Section: "network", Field: "Interface eth0", Kind: "removed", First: "10.0.0.5 (aa:bb:cc:00:00:01)"},
+		{Section: "software", Field: "Package", Kind: "removed", First: "curl"},
+		{Section: "software", Field: "Package", Kind: "added", Second: "tcpdump"},
+	}
+	if !reflect.DeepEqual(diff.Changes, want) {
+		t.Errorf("changes =\n%+v\nwant\n%+v", diff.Changes, want)
+	}
+	if sections := map[string]int{"os": 1, "hardware": 0, "network": 6, "software": 2}; diff.Differences != 9 || !reflect.DeepEqual(diff.Sections, sections) {
+		t.Errorf("%d differences by section %v, want 9 by %v", diff.Differences, diff.Sections, sections)
+	}
+}
+
+func TestCompareSystems_Text(t *testing.T) {
+	first, second := writeSystem(t, "good.json", goodSystem()), writeSystem(t, "broken.json", brokenSystem())
+	compare := &core.CompareSystemsCommand{}
+
+	result := compare.Execute([]string{first, second})
+	for _, want := range []string{
+		"web-1, 2026-10-01 09:00:00", "web-2, 2026-10-02 17:30:00",
+		"~ Version: 6.1.0 → 6.5.0", "- DNS: 10.0.0.2", "+ DNS: 8.8.8.8",
+		"+ Interface ens3", "- Interface eth0", "+ Package: tcpdump",
+		"9 differences (os 1, hardware 0, network 6, software 2)",
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("comparison should contain %q:\n%s", want, result)
+		}
+	}
+	if strings.Contains(result, "HARDWARE") || strings.Contains(result, "Uptime") {
+		t.Errorf("unchanged sections and uptime should be left out:\n%s", result)
+	}
+
+	if result := compare.Execute([]string{first, first}); !strings.Contains(result, "No differences found") {
+		t.Errorf("a file compared with itself = %s", result)
+	}
+}
+
+func TestCompareSystems_Errors(t *testing.T) {
+	dir := t.TempDir()
+	notes := filepath.Join(dir, "notes.json")
+	if err := ioutil.WriteFile(notes, []byte(`["not", "an", "export"]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	good := writeSystem(t, "good.json", goodSystem())
+	compare := &core.CompareSystemsCommand{}
+
+	if result := compare.Execute([]string{good, notes}); !strings.Contains(result, "Error parsing "+notes+": not a sysinfo export") {
+		t.Errorf("a JSON file that isn't an export = %s", result)
+	}
+	if result := compare.Execute([]string{good, filepath.Join(dir, "missing.json")}); !strings.HasPrefix(result, "Error reading ") {
+		t.Errorf("a missing file = %s", result)
+	}
+	for _, args := range [][]string{nil, {good}, {good, good, good}, {"--json", good}} {
+		if result := compare.Execute(args); !strings.HasPrefix(result, "Usage: compare-systems") {
+			t.Errorf("compare-systems %q = %s", args, result)
+		}
+	}
+}
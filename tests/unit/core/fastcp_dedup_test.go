@@ -0,0 +1,277 @@
+package core_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/core"
+)
+
+// useHome points the home directory, and with it the dedup cache, at dir
+func useHome(t *testing.T, dir string) {
+	t.Helper()
+	home, profile := os.Getenv("HOME"), os.Getenv("USERPROFILE")
+	os.Setenv("HOME", dir)
+	os.Setenv("USERPROFILE", dir)
+	t.Cleanup(func() {
+		os.Setenv("HOME", home)
+		os.Setenv("USERPROFILE", profile)
+	})
+}
+
+func TestFastcpDedup_AnalyzePersistsCache(t *testing.T) {
+	home, dir := t.TempDir(), t.TempDir()
+	useHome(t, home)
+
+	// original.bin is three blocks, copy.bin repeats all of them
+	original := make([]byte, 2*1024*1024+100)
+	rand.New(rand.NewSource(1)).Read(original)
+	notes := []byte("nothing else looks like this")
+	for name, data := range map[string][]byte{"original.bin": original, "copy.bin": original, "notes.txt": notes} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dedup := &core.FastcpDedupCommand{}
+	// Analyzing twice replaces the files' entries rather than counting them again
+	for run := 1; run <= 2; run++ {
+		result := dedup.Execute([]string{"analyze", dir})
+		if !strings.Contains(result, "Duplicate blocks:         3 blocks") {
+			t.Fatalf("run %d: analyze = %s", run, result)
+		}
+	}
+
+	cache, err := core.OpenDedupCache(filepath.Join(home, ".fastcp", "dedup_cache.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats := cache.Stats()
+	if stats.Files != 3 || stats.UniqueBlocks != 4 || stats.LogicalBlocks != 7 || stats.DuplicateBlocks() != 3 {
+		t.Errorf("cache stats = %+v, want 3 files holding 7 blocks, 4 of them unique", stats)
+	}
+	if want := int64(2*len(original) + len(notes)); stats.LogicalBytes != want {
+		t.Errorf("logical bytes = %d, want %d", stats.LogicalBytes, want)
+	}
+	if want := int64(len(original) + len(notes)); stats.UniqueBytes != want {
+		t.Errorf("unique bytes = %d, want %d", stats.UniqueBytes, want)
+	}
+
+	result := dedup.Execute([]string{"stats"})
+	for _, want := range []string{"Total unique blocks:      4 blocks", "Duplicate blocks:         3 blocks", "Files tracked:            3 files", "original.bin"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("stats should contain %q:\n%s", want, result)
+		}
+	}
+	if strings.Contains(result, "simulation") {
+		t.Errorf("stats should not be simulated:\n%s", result)
+	}
+}
+
+func TestFastcpDedup_StatsWithoutCache(t *testing.T) {
+	useHome(t, t.TempDir())
+	if result := (&core.FastcpDedupCommand{}).Execute([]string{"stats"}); !strings.Contains(result, "cache is empty") {
+		t.Errorf("stats = %s", result)
+	}
+}
+
+func TestDedupCache_CleanDropsOldEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dedup_cache.json")
+	cache, err := core.OpenDedupCache(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	cache.Record("/data/old.txt", []core.DedupBlock{{Hash: "aa", Size: 10}, {Hash: "bb", Size: 20}}, now.Add(-8*24*time.Hour))
+	cache.Record("/data/new.txt", []core.DedupBlock{{Hash: "bb", Size: 20}, {Hash: "cc", Size: 30}}, now)
+
+	if files, blocks := cache.Clean(now.Add(-7 * 24 * time.Hour)); files != 1 || blocks != 1 {
+		t.Errorf("Clean removed %d files and %d blocks, want 1 and 1", files, blocks)
+	}
+	if err := cache.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := core.OpenDedupCache(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := reloaded.Files["/data/new.txt"]; !ok || len(reloaded.Files) != 1 {
+		t.Errorf("files after clean = %v, want only new.txt", reloaded.Files)
+	}
+	if stats := reloaded.Stats(); stats.UniqueBlocks != 2 || stats.UniqueBytes != 50 {
+		t.Errorf("stats after clean = %+v, want the blocks of new.txt", stats)
+	}
+}
+
+// dedupTree writes files of whole blocks filled with one of 11 byte values,
+// every fourth one with a tail of its own, and returns them in order
+func dedupTree(t *testing.T, blockSize int) []string {
+	t.Helper()
+	dir := t.TempDir()
+	var files []string
+	for i := 0; i < 60; i++ {
+		var data []byte
+		for j := 0; j < 1+i%5; j++ {
+			data = append(data, bytes.Repeat([]byte{byte((i + j) % 11)}, blockSize)...)
+		}
+		if i%4 == 0 {
+			data = append(data, bytes.Repeat([]byte{0xff}, i+1)...)
+		}
+		name := filepath.Join(dir, fmt.Sprintf("file%02d%s", i, []string{".log", ".dat", ""}[i%3]))
+		if err := ioutil.WriteFile(name, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, name)
+	}
+	return files
+}
+
+func TestDedupAnalysis_ParallelMatchesSerial(t *testing.T) {
+	const blockSize = 4096
+	files := dedupTree(t, blockSize)
+
+	serial := core.NewDedupAnalysis(blockSize, 1, nil)
+	serial.Run(files)
+	want := serial.Result(5)
+	// 180 whole blocks of 11 kinds, and 15 tails that each differ
+	if want.TotalBlocks != 195 || want.UniqueBlocks != 26 || want.DuplicateBlocks != 169 {
+		t.Fatalf("serial analysis found %d blocks, %d unique and %d duplicate; want 195, 26 and 169",
+			want.TotalBlocks, want.UniqueBlocks, want.DuplicateBlocks)
+	}
+	if want.Types[".log"].Blocks+want.Types[".dat"].Blocks+want.Types["(none)"].Blocks != 195 {
+		t.Errorf("types = %+v, want every block in one", want.Types)
+	}
+
+	for _, workers := range []int{2, 8, 32} {
+		parallel := core.NewDedupAnalysis(blockSize, workers, nil)
+		parallel.Run(files)
+		if got := parallel.Result(5); !reflect.DeepEqual(got, want) {
+			t.Errorf("%d workers found %+v, serial %+v", workers, got, want)
+		}
+		if parallel.Hashed() != int64(len(files)) {
+			t.Errorf("%d workers hashed %d of %d files", workers, parallel.Hashed(), len(files))
+		}
+	}
+}
+
+func TestDedupAnalysis_KeepsFewLocations(t *testing.T) {
+	files := dedupTree(t, 4096)
+	analysis := core.NewDedupAnalysis(4096, 4, nil)
+	analysis.Run(files)
+	for _, dup := range analysis.Result(3).Top {
+		if dup.Refs <= 3 || len(dup.Files) != 3 {
+			t.Errorf("block %s: %d refs, %d locations kept %v; want 3 of many", dup.Hash[:16], dup.Refs, len(dup.Files), dup.Files)
+		}
+	}
+}
+
+func BenchmarkDedupAnalysis(b *testing.B) {
+	const blockSize = 64 * 1024
+	dir, err := ioutil.TempDir("", "dedup-bench")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// 500 files of 4 blocks, half of them shared
+	random := rand.New(rand.NewSource(1))
+	shared := make([][]byte, 50)
+	for i := range shared {
+		shared[i] = make([]byte, blockSize)
+		random.Read(shared[i])
+	}
+	var files []string
+	var total int64
+	for i := 0; i < 500; i++ {
+		var data []byte
+		for j := 0; j < 4; j++ {
+			block := make([]byte, blockSize)
+			if j%2 == 0 {
+				block = shared[random.Intn(len(shared))]
+			} else {
+				random.Read(block)
+			}
+			data = append(data, block...)
+		}
+		name := filepath.Join(dir, fmt.Sprintf("file%03d.bin", i))
+		if err := ioutil.WriteFile(name, data, 0644); err != nil {
+			b.Fatal(err)
+		}
+		files = append(files, name)
+		total += int64(len(data))
+	}
+
+	counts := []int{1, 2, 4}
+	if runtime.NumCPU() > 4 {
+		counts = append(counts, runtime.NumCPU())
+	}
+	for _, workers := range counts {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			b.SetBytes(total)
+			for i := 0; i < b.N; i++ {
+				core.NewDedupAnalysis(blockSize, workers, nil).Run(files)
+			}
+		})
+	}
+}
+
+func TestFastcpDedup_TypeBreakdown(t *testing.T) {
+	home, dir := t.TempDir(), t.TempDir()
+	useHome(t, home)
+
+	// The two .bin files are the same two blocks, the .txt files share nothing
+	image := make([]byte, 1024*1024+100)
+	rand.New(rand.NewSource(2)).Read(image)
+	one, two := []byte("first notes"), []byte("second, longer notes")
+	for name, data := range map[string][]byte{"a.bin": image, "b.bin": image, "one.txt": one, "two.txt": two} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dedup := &core.FastcpDedupCommand{}
+	analyzed := dedup.Execute([]string{"analyze", dir})
+
+	cache, err := core.OpenDedupCache(filepath.Join(home, ".fastcp", "dedup_cache.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]core.DedupTypeStats{
+		".bin": {Type: ".bin", Files: 2, Size: int64(2 * len(image)), Blocks: 4, DuplicateBlocks: 2, DuplicateSize: int64(len(image))},
+		".txt": {Type: ".txt", Files: 2, Size: int64(len(one) + len(two)), Blocks: 2},
+	}
+	types := cache.TypeStats()
+	if len(types) != len(want) {
+		t.Errorf("cache types = %v, want .bin and .txt", types)
+	}
+	for fileType, stats := range want {
+		if got := types[fileType]; got == nil || *got != stats {
+			t.Errorf("cache %s = %+v, want %+v", fileType, got, stats)
+		}
+	}
+
+	// analyze reports what it just found and stats what the cache holds,
+	// which here are the same
+	stored := dedup.Execute([]string{"stats"})
+	for fileType, stats := range want {
+		row := fmt.Sprintf("  %-12s %8d %12s %9.1f%% %12s\n", fileType, stats.Files,
+			commands.HumanizeBytes(stats.Size), stats.Ratio(), commands.HumanizeBytes(stats.DuplicateSize))
+		if !strings.Contains(analyzed, row) {
+			t.Errorf("analyze should list %q:\n%s", row, analyzed)
+		}
+		if !strings.Contains(stored, row) {
+			t.Errorf("stats should list %q:\n%s", row, stored)
+		}
+	}
+}
@@ -0,0 +1,560 @@
+package core_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"suppercommand/internal/core"
+)
+
+// recordingReader notes the size of every read asked of it
+type recordingReader struct {
+	io.Reader
+	asked []int
+}
+
+func (r *recordingReader) Read(p []byte) (int, error) {
+	r.asked = append(r.asked, len(p))
+	return r.Reader.Read(p)
+}
+
+func TestReceiveFastcpFile_SessionOfMixedSizes(t *testing.T) {
+	const bufferSize = 4096
+	sizes := []int64{10, 3*bufferSize + 7, 1, 2 * bufferSize, 0, bufferSize - 1}
+
+	var stream bytes.Buffer
+	var files [][]byte
+	for i, size := range sizes {
+		data := bytes.Repeat([]byte{byte('a' + i)}, int(size))
+		files = append(files, data)
+		stream.Write(data)
+	}
+
+	conn := &recordingReader{Reader: &stream}
+	buffer := make([]byte, bufferSize)
+	for i, size := range sizes {
+		conn.asked = nil
+		var file bytes.Buffer
+		var lastProgress int64
+		received, err := core.ReceiveFastcpFile(conn, &file, size, buffer, func(n int64) { lastProgress = n })
+		if err != nil {
+			t.Fatalf("file %d: ReceiveFastcpFile() error = %v", i, err)
+		}
+		if received != size || lastProgress != size {
+			t.Errorf("file %d: received %d bytes, last progress %d, want %d", i, received, lastProgress, size)
+		}
+		if !bytes.Equal(file.Bytes(), files[i]) {
+			t.Errorf("file %d: content mixed up with its neighbours", i)
+		}
+
+		// A short file must not shrink the reads of the ones after it
+		for j, asked := range conn.asked {
+			want := int64(bufferSize)
+			if remaining := size - int64(j)*bufferSize; remaining < want {
+				want = remaining
+			}
+			if int64(asked) != want {
+				t.Errorf("file %d: read %d asked for %d bytes, want %d", i, j, asked, want)
+			}
+		}
+	}
+	if stream.Len() != 0 {
+		t.Errorf("%d bytes left unread", stream.Len())
+	}
+}
+
+func TestReceiveFastcpFile_TruncatedStream(t *testing.T) {
+	var file bytes.Buffer
+	received, err := core.ReceiveFastcpFile(bytes.NewReader([]byte("short")), &file, 100, make([]byte, 4096), nil)
+	if err == nil {
+		t.Fatal("a stream that ends early should fail")
+	}
+	if received != 5 || file.String() != "short" {
+		t.Errorf("received %d bytes (%q), want the 5 that arrived", received, file.String())
+	}
+}
+
+// tappedConn keeps a copy of every byte written to the wire
+type tappedConn struct {
+	net.Conn
+	mu   sync.Mutex
+	wire bytes.Buffer
+}
+
+// written is how many bytes went on the wire so far
+func (c *tappedConn) written() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.wire.Len()
+}
+
+func (c *tappedConn) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	c.wire.Write(p)
+	c.mu.Unlock()
+	return c.Conn.Write(p)
+}
+
+// fastcpSession connects to an in-process receiver holding receiverKey and
+// returns the sender's tapped connection and the receiver's handshake outcome
+func fastcpSession(t *testing.T, receiverKey string) (*tappedConn, <-chan *core.FastcpConn, <-chan error) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	accepted := make(chan *core.FastcpConn, 1)
+	failed := make(chan error, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			failed <- err
+			return
+		}
+		secure, err := core.FastcpReceiverHandshake(conn, receiverKey)
+		if err != nil {
+			conn.Close()
+			failed <- err
+			return
+		}
+		t.Cleanup(func() { conn.Close() })
+		accepted <- secure
+	}()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return &tappedConn{Conn: conn}, accepted, failed
+}
+
+func TestFastcpHandshake_EncryptsTheWire(t *testing.T) {
+	const key = "SecretTransferKey"
+	data := bytes.Repeat([]byte("confidential payroll record\n"), 10000)
+
+	tap, accepted, failed := fastcpSession(t, key)
+	sender, err := core.FastcpSenderHandshake(tap, key)
+	if err != nil {
+		t.Fatalf("sender handshake: %v", err)
+	}
+	var receiver *core.FastcpConn
+	select {
+	case receiver = <-accepted:
+	case err := <-failed:
+		t.Fatalf("receiver handshake: %v", err)
+	}
+
+	// A normal transfer and a delta block go through the same connection
+	go func() {
+		sender.Write(data)
+		sender.Write([]byte("BLOCK:0:5\nhello"))
+	}()
+	var file bytes.Buffer
+	if _, err := core.ReceiveFastcpFile(receiver, &file, int64(len(data)), make([]byte, 32*1024), nil); err != nil {
+		t.Fatalf("ReceiveFastcpFile() error = %v", err)
+	}
+	if !bytes.Equal(file.Bytes(), data) {
+		t.Errorf("received %d bytes that differ from the %d sent", file.Len(), len(data))
+	}
+	block := make([]byte, len("BLOCK:0:5\nhello"))
+	if _, err := io.ReadFull(receiver, block); err != nil || string(block) != "BLOCK:0:5\nhello" {
+		t.Errorf("block read back as %q, %v", block, err)
+	}
+
+	// Replies travel encrypted too
+	go receiver.Write([]byte("SEND_ALL\n"))
+	reply := make([]byte, 64)
+	n, err := sender.Read(reply)
+	if err != nil || string(reply[:n]) != "SEND_ALL\n" {
+		t.Errorf("reply read back as %q, %v", reply[:n], err)
+	}
+
+	tap.mu.Lock()
+	defer tap.mu.Unlock()
+	for _, plain := range []string{"confidential payroll", "BLOCK:0:5", key} {
+		if bytes.Contains(tap.wire.Bytes(), []byte(plain)) {
+			t.Errorf("%q crossed the wire in plaintext", plain)
+		}
+	}
+	if tap.wire.Len() <= len(data) {
+		t.Errorf("only %d bytes on the wire for %d of data", tap.wire.Len(), len(data))
+	}
+}
+
+func TestFastcpHandshake_RejectsWrongKey(t *testing.T) {
+	tap, _, failed := fastcpSession(t, "ReceiverKey")
+	if _, err := core.FastcpSenderHandshake(tap, "SenderKey"); err == nil {
+		t.Error("a sender with another key should be rejected")
+	}
+	if err := <-failed; err == nil {
+		t.Error("the receiver should reject a sender with another key")
+	}
+	tap.mu.Lock()
+	defer tap.mu.Unlock()
+	if bytes.Contains(tap.wire.Bytes(), []byte("SenderKey")) {
+		t.Error("the key crossed the wire in plaintext")
+	}
+}
+
+func TestFastcpConn_RejectsTamperedFrames(t *testing.T) {
+	const key = "SecretTransferKey"
+	tap, accepted, failed := fastcpSession(t, key)
+	if _, err := core.FastcpSenderHandshake(tap, key); err != nil {
+		t.Fatalf("sender handshake: %v", err)
+	}
+	var receiver *core.FastcpConn
+	select {
+	case receiver = <-accepted:
+	case err := <-failed:
+		t.Fatalf("receiver handshake: %v", err)
+	}
+
+	// A frame of the right shape sealed without the key
+	frame := append([]byte{0, 0, 0, 32}, make([]byte, 12+32)...)
+	if _, err := tap.Conn.Write(frame); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := receiver.Read(make([]byte, 64)); err == nil {
+		t.Error("a frame not sealed with the session key should fail")
+	}
+}
+
+func TestFastcpObject_RoundTrip(t *testing.T) {
+	data := bytes.Repeat([]byte("quarterly ledger "), 1000)
+	object, err := core.SealFastcpObject(data, "BackupKey")
+	if err != nil {
+		t.Fatalf("SealFastcpObject() error = %v", err)
+	}
+	if bytes.Contains(object, []byte("quarterly ledger")) {
+		t.Error("the sealed object holds the plaintext")
+	}
+	again, _ := core.SealFastcpObject(data, "BackupKey")
+	if bytes.Equal(object[:12], again[:12]) {
+		t.Error("two objects were sealed with the same nonce")
+	}
+
+	path := filepath.Join(t.TempDir(), "ledger.txt")
+	if err := ioutil.WriteFile(path, object, 0644); err != nil {
+		t.Fatal(err)
+	}
+	stored, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opened, err := core.OpenFastcpObject(stored, "BackupKey")
+	if err != nil {
+		t.Fatalf("OpenFastcpObject() error = %v", err)
+	}
+	if !bytes.Equal(opened, data) {
+		t.Errorf("opened %d bytes that differ from the %d sealed", len(opened), len(data))
+	}
+}
+
+func TestFastcpObject_WrongKeyFails(t *testing.T) {
+	object, err := core.SealFastcpObject([]byte("secret"), "BackupKey")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := core.OpenFastcpObject(object, "OtherKey"); err == nil {
+		t.Error("an object should not open with another key")
+	}
+	object[len(object)-1] ^= 1
+	if _, err := core.OpenFastcpObject(object, "BackupKey"); err == nil {
+		t.Error("a changed object should not open")
+	}
+	if _, err := core.OpenFastcpObject([]byte("short"), "BackupKey"); err == nil {
+		t.Error("an object shorter than its nonce should not open")
+	}
+}
+
+// fastcpResumeRun is the outcome of one end of a resumable file transfer
+type fastcpResumeRun struct {
+	offset, bytes int64
+	err           error
+}
+
+// resumableTransfer sends source as a file of size over an encrypted session
+// to a receiver writing into target, and returns how many bytes the sender
+// put on the wire. The sender hangs up once source runs dry, as a killed
+// sender would.
+func resumableTransfer(t *testing.T, target string, source []byte, size int64, resume, compressed bool) (sent, received fastcpResumeRun, wire int) {
+	t.Helper()
+	const key = "SecretTransferKey"
+	tap, accepted, failed := fastcpSession(t, key)
+	sender, err := core.FastcpSenderHandshake(tap, key)
+	if err != nil {
+		t.Fatalf("sender handshake: %v", err)
+	}
+	var receiver *core.FastcpConn
+	select {
+	case receiver = <-accepted:
+	case err := <-failed:
+		t.Fatalf("receiver handshake: %v", err)
+	}
+
+	done := make(chan fastcpResumeRun, 1)
+	go func() {
+		offset, n, err := core.ReceiveFastcpPartial(receiver, target, size, resume, compressed, make([]byte, 32*1024), nil)
+		done <- fastcpResumeRun{offset, n, err}
+	}()
+	before := tap.written()
+	offset, n, err := core.SendFastcpFile(sender, bytes.NewReader(source), size, compressed, make([]byte, 64*1024), nil)
+	tap.Close()
+	return fastcpResumeRun{offset, n, err}, <-done, tap.written() - before
+}
+
+func TestFastcpResume_InterruptedTransferContinues(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789abcdef"), 200*1024)
+	size := int64(len(data))
+	cut := size/2 + 12345
+	target := filepath.Join(t.TempDir(), "disk.img")
+
+	// The sender dies part way through the file
+	sent, received, _ := resumableTransfer(t, target, data[:cut], size, true, false)
+	if sent.err == nil || received.err == nil {
+		t.Fatalf("an interrupted transfer should fail on both ends: %v, %v", sent.err, received.err)
+	}
+	if received.offset != 0 || received.bytes != cut {
+		t.Fatalf("first run received %d bytes from %d, want %d from 0", received.bytes, received.offset, cut)
+	}
+	manifest, err := ioutil.ReadFile(target + ".fastcp-partial")
+	if err != nil {
+		t.Fatalf("no partial manifest left for the next run: %v", err)
+	}
+	if !bytes.Contains(manifest, []byte(`"size":3276800`)) {
+		t.Errorf("manifest = %s", manifest)
+	}
+
+	// The next run sends only what is missing
+	sent, received, _ = resumableTransfer(t, target, data, size, true, false)
+	if sent.err != nil || received.err != nil {
+		t.Fatalf("resumed transfer: %v, %v", sent.err, received.err)
+	}
+	if sent.offset != cut || sent.bytes != size-cut {
+		t.Errorf("resumed run sent %d bytes from %d, want %d from %d", sent.bytes, sent.offset, size-cut, cut)
+	}
+	if received.bytes != size-cut {
+		t.Errorf("resumed run received %d bytes, want %d", received.bytes, size-cut)
+	}
+	got, err := ioutil.ReadFile(target)
+	if err != nil || !bytes.Equal(got, data) {
+		t.Errorf("resumed file has %d bytes that differ from the %d sent (%v)", len(got), size, err)
+	}
+	if _, err := os.Stat(target + ".fastcp-partial"); !os.IsNotExist(err) {
+		t.Errorf("manifest left behind after the file completed: %v", err)
+	}
+}
+
+func TestFastcpResume_NoResumeStartsOver(t *testing.T) {
+	data := bytes.Repeat([]byte("z"), 100000)
+	size := int64(len(data))
+	target := filepath.Join(t.TempDir(), "notes.txt")
+
+	resumableTransfer(t, target, data[:40000], size, true, false)
+	sent, received, _ := resumableTransfer(t, target, data, size, false, false)
+	if sent.err != nil || received.err != nil {
+		t.Fatalf("transfer: %v, %v", sent.err, received.err)
+	}
+	if sent.offset != 0 || sent.bytes != size {
+		t.Errorf("--no-resume sent %d bytes from %d, want the whole file", sent.bytes, sent.offset)
+	}
+	if got, _ := ioutil.ReadFile(target); !bytes.Equal(got, data) {
+		t.Errorf("received %d bytes that differ from the %d sent", len(got), size)
+	}
+
+	// A partial file of another size is not resumed either
+	resumableTransfer(t, target, data[:40000], size, true, false)
+	sent, _, _ = resumableTransfer(t, target, data[:size-1], size-1, true, false)
+	if sent.offset != 0 {
+		t.Errorf("resumed at %d into a partial file of another size", sent.offset)
+	}
+}
+
+func TestFastcpCompression_ShrinksTheWire(t *testing.T) {
+	data := bytes.Repeat([]byte("2024-03-01 12:00:00 INFO request served in 12ms\n"), 100000)
+	size := int64(len(data))
+	dir := t.TempDir()
+
+	_, received, plainWire := resumableTransfer(t, filepath.Join(dir, "plain.log"), data, size, false, false)
+	if received.err != nil {
+		t.Fatalf("uncompressed transfer: %v", received.err)
+	}
+	sent, received, wire := resumableTransfer(t, filepath.Join(dir, "app.log"), data, size, false, true)
+	if sent.err != nil || received.err != nil {
+		t.Fatalf("compressed transfer: %v, %v", sent.err, received.err)
+	}
+	if wire*10 > plainWire || plainWire < len(data) {
+		t.Errorf("compressed transfer put %d bytes on the wire, uncompressed %d, for %d of data", wire, plainWire, len(data))
+	}
+	if sent.bytes != size || received.bytes != size {
+		t.Errorf("sent %d and received %d bytes of the file, want %d", sent.bytes, received.bytes, size)
+	}
+	if got, _ := ioutil.ReadFile(filepath.Join(dir, "app.log")); !bytes.Equal(got, data) {
+		t.Errorf("decompressed %d bytes that differ from the %d sent", len(got), size)
+	}
+}
+
+func TestFastcpCompression_ResumesInterruptedTransfer(t *testing.T) {
+	data := bytes.Repeat([]byte("compressible "), 300000)
+	size := int64(len(data))
+	target := filepath.Join(t.TempDir(), "archive.txt")
+
+	_, received, _ := resumableTransfer(t, target, data[:size/3], size, true, true)
+	if received.err == nil || received.bytes == 0 {
+		t.Fatalf("first run received %d bytes (%v), want part of the file and an error", received.bytes, received.err)
+	}
+	sent, received, _ := resumableTransfer(t, target, data, size, true, true)
+	if sent.err != nil || received.err != nil {
+		t.Fatalf("resumed transfer: %v, %v", sent.err, received.err)
+	}
+	if sent.offset == 0 || sent.offset+sent.bytes != size {
+		t.Errorf("resumed at %d and sent %d bytes of %d", sent.offset, sent.bytes, size)
+	}
+	if got, _ := ioutil.ReadFile(target); !bytes.Equal(got, data) {
+		t.Errorf("resumed file has %d bytes that differ from the %d sent", len(got), size)
+	}
+}
+
+// waitForListener waits until something listens on addr
+func waitForListener(t *testing.T, addr string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		probe, err := net.Listen("tcp", addr)
+		if err != nil {
+			return
+		}
+		probe.Close()
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("nothing listening on %s", addr)
+}
+
+// fastcpLoopback runs fastcp-recv into dst and fastcp-send of src with args
+// over 127.0.0.1, returning what fastcp-recv reports
+func fastcpLoopback(t *testing.T, src, dst string, args ...string) string {
+	t.Helper()
+	probe, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := probe.Addr().String()
+	_, port, _ := net.SplitHostPort(addr)
+	probe.Close()
+
+	const key = "SecretTransferKey"
+	received := make(chan string, 1)
+	go func() {
+		received <- (&core.FastcpRecvCommand{}).Execute([]string{key, "--port", port, "--dst", dst, "--listen", "127.0.0.1"})
+	}()
+	waitForListener(t, addr)
+
+	sent := (&core.FastcpSendCommand{}).Execute(append([]string{src, addr, key}, args...))
+	if !strings.Contains(sent, "All files transferred successfully") {
+		t.Fatalf("fastcp-send: %s", sent)
+	}
+	select {
+	case result := <-received:
+		return result
+	case <-time.After(30 * time.Second):
+		t.Fatal("fastcp-recv did not finish")
+	}
+	return ""
+}
+
+func TestFastcpSend_ParallelStreams(t *testing.T) {
+	src, dst := t.TempDir(), t.TempDir()
+	files := make(map[string][]byte)
+	var total int64
+	for i := 0; i < 50; i++ {
+		name := filepath.Join(fmt.Sprintf("dir%d", i%5), fmt.Sprintf("file%02d.txt", i))
+		data := bytes.Repeat([]byte(fmt.Sprintf("file %d line\n", i)), i*37)
+		if err := os.MkdirAll(filepath.Join(src, filepath.Dir(name)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(src, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		files[name] = data
+		total += int64(len(data))
+	}
+
+	result := fastcpLoopback(t, src, dst, "--streams", "4")
+	if want := fmt.Sprintf("✅ Successfully received 50/50 files (%d bytes)!", total); result != want {
+		t.Errorf("fastcp-recv = %q, want %q", result, want)
+	}
+
+	for name, data := range files {
+		got, err := ioutil.ReadFile(filepath.Join(dst, name))
+		if err != nil || !bytes.Equal(got, data) {
+			t.Errorf("%s: received %d bytes that differ from the %d sent (%v)", name, len(got), len(data), err)
+		}
+	}
+}
+
+func TestFastcpSend_PreservesTimesAndModes(t *testing.T) {
+	src, dst := t.TempDir(), t.TempDir()
+	if err := os.Mkdir(filepath.Join(src, "bin"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	entries := []struct {
+		name string
+		mode os.FileMode
+		age  time.Duration
+	}{
+		{"private.txt", 0600, 48 * time.Hour},
+		{filepath.Join("bin", "tool.sh"), 0755, 30 * 24 * time.Hour},
+		{"readonly.txt", 0444, 365 * 24 * time.Hour},
+		{"bin", 0750, 72 * time.Hour},
+	}
+	for _, e := range entries[:3] {
+		if err := ioutil.WriteFile(filepath.Join(src, e.name), []byte("contents of "+e.name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, e := range entries {
+		path := filepath.Join(src, e.name)
+		modTime := time.Now().Add(-e.age)
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chmod(path, e.mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The second run updates files already there, one of them read-only
+	for run := 1; run <= 2; run++ {
+		if result := fastcpLoopback(t, src, dst); !strings.Contains(result, "Successfully received 3/3 files") {
+			t.Fatalf("run %d: fastcp-recv = %q", run, result)
+		}
+		for _, e := range entries {
+			want, err := os.Stat(filepath.Join(src, e.name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := os.Stat(filepath.Join(dst, e.name))
+			if err != nil {
+				t.Fatalf("run %d: %v", run, err)
+			}
+			if got.Mode() != want.Mode() {
+				t.Errorf("run %d: %s has mode %v, want %v", run, e.name, got.Mode(), want.Mode())
+			}
+			if diff := got.ModTime().Sub(want.ModTime()); diff < -time.Second || diff > time.Second {
+				t.Errorf("run %d: %s modified %v, want %v", run, e.name, got.ModTime(), want.ModTime())
+			}
+		}
+	}
+}
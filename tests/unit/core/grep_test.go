@@ -0,0 +1,120 @@
+package core_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"suppercommand/internal/core"
+)
+
+const grepSample = `Alpha one
+beta two
+ALPHA three
+gamma four
+alphabet five
+`
+
+// grepDir writes grepSample to sample.txt and a nested notes.md in a temp dir
+func grepDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "sample.txt"), []byte(grepSample), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "docs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "docs", "notes.md"), []byte("no match here\nalpha in notes\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func grep(args ...string) []string {
+	out := (&core.GrepCommand{}).Execute(args)
+	if out == "" {
+		return nil
+	}
+	return strings.Split(out, "\n")
+}
+
+func assertLines(t *testing.T, got []string, want ...string) {
+	t.Helper()
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got lines\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestGrep_MatchesCaseSensitively(t *testing.T) {
+	file := filepath.Join(grepDir(t), "sample.txt")
+	assertLines(t, grep("alpha", file), "alphabet five")
+	assertLines(t, grep("^[bg]", file), "beta two", "gamma four")
+	if got := grep("delta", file); got != nil {
+		t.Errorf("no line should match, got %q", got)
+	}
+}
+
+func TestGrep_IgnoreCase(t *testing.T) {
+	file := filepath.Join(grepDir(t), "sample.txt")
+	assertLines(t, grep("-i", "alpha", file), "Alpha one", "ALPHA three", "alphabet five")
+}
+
+func TestGrep_LineNumbers(t *testing.T) {
+	file := filepath.Join(grepDir(t), "sample.txt")
+	assertLines(t, grep("-n", "-i", "alpha", file), "1:Alpha one", "3:ALPHA three", "5:alphabet five")
+	// Flags combine and may follow the operands
+	assertLines(t, grep("two", file, "-ni"), "2:beta two")
+}
+
+func TestGrep_InvertMatch(t *testing.T) {
+	file := filepath.Join(grepDir(t), "sample.txt")
+	assertLines(t, grep("-v", "-i", "alpha", file), "beta two", "gamma four")
+	assertLines(t, grep("-vn", "a", file), "3:ALPHA three")
+}
+
+func TestGrep_Recursive(t *testing.T) {
+	dir := grepDir(t)
+	assertLines(t, grep("-r", "-n", "alpha", dir),
+		filepath.Join(dir, "docs", "notes.md")+":2:alpha in notes",
+		filepath.Join(dir, "sample.txt")+":5:alphabet five")
+
+	// Without -r a directory is reported rather than searched
+	got := grep("alpha", dir)
+	if len(got) != 1 || !strings.Contains(got[0], "is a directory") {
+		t.Errorf("grep of a directory = %q", got)
+	}
+}
+
+func TestGrep_Glob(t *testing.T) {
+	dir := grepDir(t)
+	if err := ioutil.WriteFile(filepath.Join(dir, "other.txt"), []byte("gamma again\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	assertLines(t, grep("gamma", filepath.Join(dir, "*.txt")),
+		filepath.Join(dir, "other.txt")+":gamma again",
+		filepath.Join(dir, "sample.txt")+":gamma four")
+}
+
+func TestGrep_LongLines(t *testing.T) {
+	// A line past bufio.Scanner's default limit is still read
+	line := strings.Repeat("x", 200*1024) + "needle"
+	file := filepath.Join(t.TempDir(), "long.txt")
+	if err := ioutil.WriteFile(file, []byte("short\n"+line+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	assertLines(t, grep("-n", "needle", file), "2:"+line)
+}
+
+func TestGrep_Usage(t *testing.T) {
+	for _, args := range [][]string{nil, {"pattern"}, {"-x", "pattern", "file"}} {
+		if out := (&core.GrepCommand{}).Execute(args); !strings.Contains(out, "Usage: grep") {
+			t.Errorf("grep %q = %q, want usage", args, out)
+		}
+	}
+	if out := (&core.GrepCommand{}).Execute([]string{"(", "file"}); !strings.Contains(out, "invalid pattern") {
+		t.Errorf("an invalid pattern should be reported, got %q", out)
+	}
+}
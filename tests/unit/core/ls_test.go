@@ -0,0 +1,109 @@
+package core_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"suppercommand/internal/core"
+)
+
+// lsDir holds a 1536-byte report.txt modified at a known time, a dotfile and
+// a subdirectory
+func lsDir(t *testing.T) (string, time.Time) {
+	t.Helper()
+	dir := t.TempDir()
+	report := filepath.Join(dir, "report.txt")
+	if err := ioutil.WriteFile(report, make([]byte, 1536), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(report, 0640); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Date(2023, 6, 15, 14, 30, 0, 0, time.Local)
+	if err := os.Chtimes(report, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, ".hidden"), []byte("secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return dir, modTime
+}
+
+// lsLine is the line of out naming name
+func lsLine(t *testing.T, out, name string) string {
+	t.Helper()
+	for _, line := range strings.Split(out, "\n") {
+		if strings.HasSuffix(line, name) {
+			return line
+		}
+	}
+	t.Fatalf("no line for %s in:\n%s", name, out)
+	return ""
+}
+
+func TestLs_HidesDotfilesUnlessAll(t *testing.T) {
+	dir, _ := lsDir(t)
+	ls := &core.LsCommand{}
+	if out := ls.Execute([]string{dir}); out != "report.txt\nsub/\n" {
+		t.Errorf("ls = %q", out)
+	}
+	if out := ls.Execute([]string{"-a", dir}); out != ".hidden\nreport.txt\nsub/\n" {
+		t.Errorf("ls -a = %q", out)
+	}
+}
+
+func TestLs_LongListing(t *testing.T) {
+	dir, modTime := lsDir(t)
+	out := (&core.LsCommand{}).Execute([]string{"-l", dir})
+	if strings.Contains(out, ".hidden") {
+		t.Errorf("ls -l should hide dotfiles:\n%s", out)
+	}
+
+	fields := strings.Fields(lsLine(t, out, "report.txt"))
+	want := []string{"-rw-r-----", "1536", modTime.Format("2006-01-02"), modTime.Format("15:04"), "report.txt"}
+	if runtime.GOOS == "windows" {
+		want[0] = "-rw-rw-rw-"
+	}
+	if strings.Join(fields, " ") != strings.Join(want, " ") {
+		t.Errorf("report.txt columns = %q, want %q", fields, want)
+	}
+	if sub := lsLine(t, out, "sub/"); !strings.HasPrefix(sub, "d") {
+		t.Errorf("sub should be listed as a directory: %q", sub)
+	}
+
+	// Sizes are right-aligned, so the columns after them line up
+	report, sub := lsLine(t, out, "report.txt"), lsLine(t, out, "sub/")
+	if strings.Index(report, "report.txt") != strings.Index(sub, "sub/") {
+		t.Errorf("names don't line up:\n%s\n%s", report, sub)
+	}
+}
+
+func TestLs_HumanReadableSizes(t *testing.T) {
+	dir, _ := lsDir(t)
+	out := (&core.LsCommand{}).Execute([]string{"-lah", dir})
+	if line := lsLine(t, out, "report.txt"); !strings.Contains(line, " 1.5 KB ") {
+		t.Errorf("ls -lh should show 1.5 KB: %q", line)
+	}
+	if line := lsLine(t, out, ".hidden"); !strings.Contains(line, " 6 B ") {
+		t.Errorf("ls -lah should show .hidden at 6 B: %q", line)
+	}
+}
+
+func TestLs_SingleFileAndBadOption(t *testing.T) {
+	dir, _ := lsDir(t)
+	ls := &core.LsCommand{}
+	if out := ls.Execute([]string{filepath.Join(dir, ".hidden")}); out != ".hidden\n" {
+		t.Errorf("ls of a file = %q", out)
+	}
+	if out := ls.Execute([]string{"-x", dir}); !strings.Contains(out, "Usage: ls") {
+		t.Errorf("ls -x = %q, want usage", out)
+	}
+}
@@ -0,0 +1,270 @@
+package core_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/core"
+
+	"github.com/fatih/color"
+)
+
+const ssOutput = `Netid State  Recv-Q Send-Q Local Address:Port  Peer Address:Port Process
+tcp   LISTEN 0      128          0.0.0.0:22         0.0.0.0:*     users:(("sshd",pid=812,fd=3))
+tcp   ESTAB  0      0        192.168.1.5:22     192.168.1.9:50112 users:(("sshd",pid=4242,fd=4))
+udp   UNCONN 0      0          127.0.0.1:323        0.0.0.0:*
+tcp   TIME-WAIT 0   0        192.168.1.5:41000 93.184.216.34:443
+`
+
+const unixNetstatOutput = `Active Internet connections (servers and established)
+Proto Recv-Q Send-Q Local Address           Foreign Address         State       PID/Program name
+tcp        0      0 0.0.0.0:22              0.0.0.0:*               LISTEN      812/sshd
+tcp        0      0 192.168.1.5:22          192.168.1.9:50112       ESTABLISHED 4242/sshd: admin
+tcp6       0      0 :::80                   :::*                    LISTEN      -
+udp        0      0 127.0.0.1:323           0.0.0.0:*                           600/chronyd
+`
+
+const windowsNetstatOutput = "\r\nActive Connections\r\n\r\n" +
+	"  Proto  Local Address          Foreign Address        State           PID\r\n" +
+	"  TCP    0.0.0.0:135            0.0.0.0:0              LISTENING       1040\r\n" +
+	"  TCP    10.0.0.4:49702         52.96.1.2:443          ESTABLISHED     6112\r\n" +
+	"  UDP    0.0.0.0:5353           *:*                                    2280\r\n"
+
+func init() {
+	color.NoColor = true
+}
+
+// unixNetstat returns a netstat command reading ss output, or netstat -tunap
+// output when ss isn't installed
+func unixNetstat(t *testing.T, withSS bool) (*core.NetstatCommand, *commands.MockRunner) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("netstat runs netstat -ano on Windows")
+	}
+	runner := &commands.MockRunner{Installed: map[string]bool{"ss": withSS}}
+	runner.On("ss -tunap", ssOutput).On("netstat -tunap", unixNetstatOutput)
+	return &core.NetstatCommand{Runner: runner}, runner
+}
+
+func netstatJSON(t *testing.T, cmd *core.NetstatCommand, args ...string) []core.NetstatEntry {
+	t.Helper()
+	out := cmd.Execute(append(args, "--json"))
+	var entries []core.NetstatEntry
+	if err := json.Unmarshal([]byte(out), &entries); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	return entries
+}
+
+func TestNetstat_ParsesSS(t *testing.T) {
+	cmd, runner := unixNetstat(t, true)
+	entries := netstatJSON(t, cmd)
+	if calls := runner.Calls(); len(calls) != 1 || calls[0] != "ss -tunap" {
+		t.Fatalf("netstat should read ss when it is installed, ran %v", calls)
+	}
+
+	want := []core.NetstatEntry{
+		{Proto: "tcp", Local: "0.0.0.0:22", Remote: "0.0.0.0:*", State: "LISTEN", PID: "812", Program: "sshd"},
+		{Proto: "tcp", Local: "192.168.1.5:22", Remote: "192.168.1.9:50112", State: "ESTABLISHED", PID: "4242", Program: "sshd"},
+		{Proto: "udp", Local: "127.0.0.1:323", Remote: "0.0.0.0:*", State: "UNCONN"},
+		{Proto: "tcp", Local: "192.168.1.5:41000", Remote: "93.184.216.34:443", State: "TIME_WAIT"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("expected %d connections, got %+v", len(want), entries)
+	}
+	for i, e := range entries {
+		e.RawLine = ""
+		if e != want[i] {
+			t.Errorf("connection %d is %+v, want %+v", i, e, want[i])
+		}
+	}
+}
+
+func TestNetstat_ParsesNetstat(t *testing.T) {
+	cmd, runner := unixNetstat(t, false)
+	entries := netstatJSON(t, cmd)
+	if calls := runner.Calls(); len(calls) != 1 || calls[0] != "netstat -tunap" {
+		t.Fatalf("netstat should fall back to netstat -tunap, ran %v", calls)
+	}
+
+	want := []core.NetstatEntry{
+		{Proto: "tcp", Local: "0.0.0.0:22", Remote: "0.0.0.0:*", State: "LISTEN", PID: "812", Program: "sshd"},
+		{Proto: "tcp", Local: "192.168.1.5:22", Remote: "192.168.1.9:50112", State: "ESTABLISHED", PID: "4242", Program: "sshd: admin"},
+		{Proto: "tcp6", Local: ":::80", Remote: ":::*", State: "LISTEN"},
+		{Proto: "udp", Local: "127.0.0.1:323", Remote: "0.0.0.0:*", PID: "600", Program: "chronyd"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("expected %d connections, got %+v", len(want), entries)
+	}
+	for i, e := range entries {
+		e.RawLine = ""
+		if e != want[i] {
+			t.Errorf("connection %d is %+v, want %+v", i, e, want[i])
+		}
+	}
+}
+
+func TestNetstat_ParsesWindowsNetstat(t *testing.T) {
+	if runtime.GOOS != "windows" {
+		t.Skip("netstat -ano is only run on Windows")
+	}
+	runner := commands.NewMockRunner().On("netstat -ano", windowsNetstatOutput)
+	entries := netstatJSON(t, &core.NetstatCommand{Runner: runner})
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 connections, got %+v", entries)
+	}
+	if udp := entries[2]; udp.Proto != "UDP" || udp.State != "" || udp.PID != "2280" {
+		t.Errorf("a UDP socket has no state, only a PID: %+v", udp)
+	}
+}
+
+func TestNetstat_Filters(t *testing.T) {
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{[]string{":22"}, 2},
+		{[]string{":2"}, 0},
+		{[]string{"-udp"}, 1},
+		{[]string{"-tcp"}, 3},
+		{[]string{"-state", "established"}, 1},
+		{[]string{"-state", "listen", ":22"}, 1},
+		{[]string{"-p", "812"}, 1},
+		{[]string{"-p", "81"}, 0},
+	}
+	for _, tt := range tests {
+		cmd, _ := unixNetstat(t, true)
+		if got := netstatJSON(t, cmd, tt.args...); len(got) != tt.want {
+			t.Errorf("netstat %s: expected %d connections, got %+v", strings.Join(tt.args, " "), tt.want, got)
+		}
+	}
+}
+
+func TestNetstat_Table(t *testing.T) {
+	cmd, _ := unixNetstat(t, true)
+	out := cmd.Execute([]string{"--sort", "pid", "--desc"})
+
+	lines := strings.Split(out, "\n")
+	if !strings.HasPrefix(lines[0], "Proto") || !strings.Contains(lines[0], "PID/Program") {
+		t.Fatalf("the table should start with its header:\n%s", out)
+	}
+	if !strings.Contains(lines[1], "4242/sshd") || !strings.Contains(lines[2], "812/sshd") {
+		t.Errorf("rows should be sorted by PID, highest first:\n%s", out)
+	}
+	if strings.Index(lines[1], "192.168.1.9:50112") != strings.Index(lines[0], "Foreign Address") {
+		t.Errorf("columns should line up:\n%s", out)
+	}
+	if !strings.Contains(out, "Total: 4 | TCP: 3 | UDP: 1 | ESTABLISHED: 1 | LISTENING: 1") {
+		t.Errorf("the summary should count the connections:\n%s", out)
+	}
+}
+
+func TestNetstat_CSV(t *testing.T) {
+	cmd, _ := unixNetstat(t, true)
+	out := cmd.Execute([]string{"--csv", "-udp"})
+	want := "proto,local,remote,state,pid\nudp,127.0.0.1:323,0.0.0.0:*,UNCONN,"
+	if out != want {
+		t.Errorf("netstat --csv -udp printed\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestNetstat_ToolFails(t *testing.T) {
+	cmd, runner := unixNetstat(t, true)
+	runner.Fail("ss -tunap", errors.New("exit status 1"))
+	if out := cmd.Execute(nil); out != "netstat failed: ss: exit status 1" {
+		t.Errorf("a failing tool should be reported: %q", out)
+	}
+}
+
+// liveFrames runs netstat's live filter over the filters typed, returning the
+// dashboard drawn for each, the unfiltered one first
+func liveFrames(t *testing.T, args []string, typed ...string) []string {
+	t.Helper()
+	cmd, _ := unixNetstat(t, true)
+	var screen bytes.Buffer
+	cmd.Output = &screen
+	cmd.Input = func(prefix string) string {
+		if len(typed) == 0 {
+			return ""
+		}
+		next := typed[0]
+		typed = typed[1:]
+		return next
+	}
+	cmd.Execute(args)
+	return strings.Split(screen.String(), "\033[H\033[2J")
+}
+
+func TestNetstat_LiveFilter(t *testing.T) {
+	frames := liveFrames(t, nil, ":22", "/:(22|323)/", "/^udp/", "/[/", "/^TCP/")
+	// Plain text matches the line, a regex each column; :port patterns are
+	// anchored so 41000 and 50112 don't count, and "[" falls back to text
+	for i, want := range []string{"Total: 4 ", "Total: 2 ", "Total: 3 ", "Total: 1 ", "Total: 0 ", "Total: 0 "} {
+		if i >= len(frames) || !strings.Contains(frames[i], want) {
+			t.Errorf("frame %d should show %q", i, want)
+		}
+	}
+
+	frames = liveFrames(t, []string{"--regex", "--ignore-case"}, "^TCP", ":4")
+	if len(frames) != 3 || !strings.Contains(frames[1], "Total: 3 ") || !strings.Contains(frames[2], "Total: 0 ") {
+		t.Errorf("--regex --ignore-case should match ^TCP case-insensitively and anchor :4:\n%s", strings.Join(frames, "\n---\n"))
+	}
+}
+
+func TestNetstat_LiveFilterHighlightsRegexMatches(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = false
+	t.Cleanup(func() { color.NoColor = noColor })
+
+	frames := liveFrames(t, nil, "/:(22|323)/")
+	highlighted := color.New(color.BgYellow, color.FgBlack, color.Bold).Sprint(":323")
+	if len(frames) != 2 || !strings.Contains(frames[1], highlighted) {
+		t.Errorf("the regex match should be highlighted:\n%q", frames[len(frames)-1])
+	}
+}
+
+func TestLegacyNetworkCommands(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the Windows tools are covered by the command lines below only on Unix")
+	}
+	tests := []struct {
+		name      string
+		cmd       func(commands.CommandRunner) core.Command
+		installed []string
+		ran       string
+		output    string
+	}{
+		{"ipconfig with ifconfig", func(r commands.CommandRunner) core.Command { return &core.IpconfigCommand{Runner: r} },
+			[]string{"ifconfig"}, "ifconfig", "eth0: flags=4163<UP,BROADCAST,RUNNING,MULTICAST>  mtu 1500\n        inet 192.168.1.5  netmask 255.255.255.0\n"},
+		{"ipconfig with ip", func(r commands.CommandRunner) core.Command { return &core.IpconfigCommand{Runner: r} },
+			nil, "ip addr", "2: eth0: <BROADCAST,MULTICAST,UP,LOWER_UP> mtu 1500\n    inet 192.168.1.5/24 brd 192.168.1.255 scope global eth0\n"},
+		{"arp with ip", func(r commands.CommandRunner) core.Command { return &core.ArpCommand{Runner: r} },
+			[]string{"ip"}, "ip neigh", "192.168.1.1 dev eth0 lladdr 00:11:22:33:44:55 REACHABLE\n"},
+		{"arp without ip", func(r commands.CommandRunner) core.Command { return &core.ArpCommand{Runner: r} },
+			nil, "arp -a", "gateway (192.168.1.1) at 00:11:22:33:44:55 [ether] on eth0\n"},
+		{"route with ip", func(r commands.CommandRunner) core.Command { return &core.RouteCommand{Runner: r} },
+			[]string{"ip"}, "ip route", "default via 192.168.1.1 dev eth0\n192.168.1.0/24 dev eth0 proto kernel scope link src 192.168.1.5\n"},
+		{"route without ip", func(r commands.CommandRunner) core.Command { return &core.RouteCommand{Runner: r} },
+			nil, "netstat -rn", "Kernel IP routing table\nDestination     Gateway         Genmask\n0.0.0.0         192.168.1.1     0.0.0.0\n"},
+	}
+	for _, tt := range tests {
+		runner := &commands.MockRunner{Installed: map[string]bool{}}
+		runner.On(tt.ran, tt.output)
+		for _, tool := range tt.installed {
+			runner.Installed[tool] = true
+		}
+		out := tt.cmd(runner).Execute(nil)
+		if calls := runner.Calls(); len(calls) != 1 || calls[0] != tt.ran {
+			t.Errorf("%s: expected %q to run, ran %v", tt.name, tt.ran, calls)
+			continue
+		}
+		if want := strings.TrimRight(tt.output, "\n"); out != want {
+			t.Errorf("%s: the output should be returned as is:\n%q\nwant\n%q", tt.name, out, want)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package core_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"suppercommand/internal/core"
+)
+
+func TestRmdir_EmptyDirectory(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "empty")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if out := (&core.RmdirCommand{}).Execute([]string{dir}); out != "" {
+		t.Fatalf("rmdir of an empty directory = %q", out)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Error("the empty directory should be gone")
+	}
+}
+
+func TestRmdir_NonEmptyDirectory(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "full")
+	file := filepath.Join(dir, "nested", "file.txt")
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(file, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	rmdir := &core.RmdirCommand{}
+
+	if out := rmdir.Execute([]string{dir}); !strings.Contains(out, "directory not empty") {
+		t.Fatalf("rmdir of a non-empty directory should fail with 'directory not empty', got %q", out)
+	}
+	if data, err := ioutil.ReadFile(file); err != nil || string(data) != "data" {
+		t.Fatalf("contents were touched: %q, %v", data, err)
+	}
+
+	if out := rmdir.Execute([]string{"-rf", dir}); out != "" {
+		t.Fatalf("rmdir -rf = %q", out)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Error("rmdir -rf should remove the tree")
+	}
+}
+
+func TestRmdir_Errors(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file.txt")
+	if err := ioutil.WriteFile(file, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	rmdir := &core.RmdirCommand{}
+
+	if out := rmdir.Execute([]string{file}); !strings.Contains(out, "not a directory") {
+		t.Errorf("rmdir of a file should fail with 'not a directory', got %q", out)
+	}
+	for _, args := range [][]string{nil, {"-r"}, {"-x", file}} {
+		if out := rmdir.Execute(args); !strings.Contains(out, "Usage: rmdir") {
+			t.Errorf("rmdir %q should print usage, got %q", args, out)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package core_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/core"
+)
+
+func TestWriteSystemInfoHTML(t *testing.T) {
+	info := goodSystem()
+	info.OS.Hostname = "<script>alert(1)</script>"
+	path := filepath.Join(t.TempDir(), "report.html")
+	if err := core.WriteSystemInfoHTML(path, info); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := string(data)
+
+	for _, want := range []string{
+		`<div class="host">&lt;script&gt;alert(1)&lt;/script&gt;</div>`,
+		`<div class="timestamp">Generated: 2026-10-01 09:00:00</div>`,
+		`id="os"`, `id="hardware"`, `id="network"`, `id="software"`,
+		`<td>eth0</td><td>10.0.0.5</td><td>aa:bb:cc:00:00:01</td>`,
+		"10.0.0.2, 1.1.1.1",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report should contain %q", want)
+		}
+	}
+	if strings.Contains(report, "<script>") {
+		t.Error("the host name should be escaped")
+	}
+}
+
+func TestSysInfo_HTMLReport(t *testing.T) {
+	dir := t.TempDir()
+	sysinfo := &core.SysInfoCommand{Runner: commands.NewMockRunner()}
+
+	for _, name := range []string{"report", "named.html"} {
+		path := filepath.Join(dir, name)
+		want := strings.TrimSuffix(path, ".html") + ".html"
+		result := sysinfo.Execute([]string{"--html", path})
+		if !strings.HasSuffix(result, "written to: "+want) {
+			t.Errorf("sysinfo --html %s = %s, want the report at %s", name, result, want)
+		}
+		data, err := ioutil.ReadFile(want)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), "<h1>System Report</h1>") {
+			t.Errorf("%s is not a report:\n%s", want, data)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "named.html.html")); !os.IsNotExist(err) {
+		t.Error("a name ending in .html should be kept as it is")
+	}
+
+	for _, args := range [][]string{{"--html"}, {"--html", "--json"}} {
+		if result := sysinfo.Execute(args); !strings.HasPrefix(result, "Usage: sysinfo") {
+			t.Errorf("sysinfo %q = %s, want the usage", args, result)
+		}
+	}
+	if entries, _ := ioutil.ReadDir(dir); len(entries) != 2 {
+		t.Errorf("a bare --html should write nothing, found %d files", len(entries))
+	}
+}
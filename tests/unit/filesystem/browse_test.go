@@ -0,0 +1,120 @@
+package filesystem_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"suppercommand/internal/commands/filesystem"
+
+	"github.com/c-bata/go-prompt"
+)
+
+func press(browser *filesystem.FileBrowser, keys ...string) {
+	for _, key := range keys {
+		switch key {
+		case "up":
+			browser.Press(prompt.Up, nil)
+		case "down":
+			browser.Press(prompt.Down, nil)
+		case "enter":
+			browser.Press(prompt.ControlM, []byte{0xd})
+		case "left":
+			browser.Press(prompt.Left, nil)
+		default:
+			browser.Press(prompt.NotDefined, []byte(key))
+		}
+	}
+}
+
+func TestFileBrowser_NavigateAndMark(t *testing.T) {
+	work, _ := journalFixture(t)
+	os.Mkdir(filepath.Join(work, "photos"), 0755)
+	writeFile(t, filepath.Join(work, "photos", "b.jpg"), "b")
+	writeFile(t, filepath.Join(work, "photos", "a.jpg"), "a")
+	writeFile(t, filepath.Join(work, "notes.txt"), "n")
+
+	browser, err := filesystem.NewFileBrowser(work)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Rows are .., then directories, then files
+	press(browser, "down", "enter")
+	if browser.Dir() != filepath.Join(work, "photos") {
+		t.Fatalf("enter should open photos, showing %s", browser.Dir())
+	}
+	press(browser, "down", " ", " ", "d")
+	want := []string{filepath.Join(work, "photos", "a.jpg"), filepath.Join(work, "photos", "b.jpg")}
+	if !browser.Done() || !reflect.DeepEqual(browser.Selected(), want) {
+		t.Errorf("selected %q, want %q", browser.Selected(), want)
+	}
+
+	// Going up keeps the cursor on the directory just left, and enter on a file picks it
+	browser, _ = filesystem.NewFileBrowser(filepath.Join(work, "photos"))
+	press(browser, "left")
+	if browser.Dir() != work {
+		t.Fatalf("left should go up to %s, showing %s", work, browser.Dir())
+	}
+	press(browser, "down", "enter")
+	if want := []string{filepath.Join(work, "notes.txt")}; !browser.Done() || !reflect.DeepEqual(browser.Selected(), want) {
+		t.Errorf("selected %q, want %q", browser.Selected(), want)
+	}
+
+	browser, _ = filesystem.NewFileBrowser(work)
+	press(browser, "q")
+	if !browser.Cancelled() || len(browser.Selected()) != 0 {
+		t.Error("q should cancel without a selection")
+	}
+}
+
+func TestFileBrowser_PermissionDenied(t *testing.T) {
+	if runtime.GOOS == "windows" || os.Geteuid() == 0 {
+		t.Skip("needs a non-root user on a system with unix permissions")
+	}
+	work, _ := journalFixture(t)
+	locked := filepath.Join(work, "locked")
+	os.Mkdir(locked, 0755)
+	os.Chmod(locked, 0)
+	defer os.Chmod(locked, 0755)
+
+	browser, _ := filesystem.NewFileBrowser(work)
+	press(browser, "down", "enter")
+	if browser.Dir() != work {
+		t.Errorf("an unreadable directory should not be entered, showing %s", browser.Dir())
+	}
+	if screen := strings.Join(browser.Render(24, 80), "\n"); !strings.Contains(screen, "permission denied") {
+		t.Errorf("the screen should explain the failure:\n%s", screen)
+	}
+}
+
+func TestSubstitutePaths(t *testing.T) {
+	paths := []string{"a.txt", "b.txt"}
+	tests := []struct {
+		words []string
+		want  []string
+	}{
+		{[]string{"cp", "{}", "/mnt/usb"}, []string{"cp", "a.txt", "b.txt", "/mnt/usb"}},
+		{[]string{"rm"}, []string{"rm", "a.txt", "b.txt"}},
+	}
+	for _, test := range tests {
+		if got := filesystem.SubstitutePaths(test.words, paths); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("SubstitutePaths(%q) = %q, want %q", test.words, got, test.want)
+		}
+	}
+}
+
+func TestFileBrowser_VanishedDirectory(t *testing.T) {
+	work, _ := journalFixture(t)
+	gone := filepath.Join(work, "gone")
+	os.Mkdir(gone, 0755)
+
+	browser, _ := filesystem.NewFileBrowser(work)
+	os.Remove(gone)
+	press(browser, "down", "enter")
+	if browser.Dir() != work || browser.Done() {
+		t.Errorf("a directory that can't be listed should leave the browser where it was, showing %s", browser.Dir())
+	}
+}
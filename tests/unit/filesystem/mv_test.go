@@ -0,0 +1,83 @@
+package filesystem_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"suppercommand/internal/commands/filesystem"
+)
+
+func TestMv_MultipleSourcesIntoDirectory(t *testing.T) {
+	work, journal := journalFixture(t)
+	dir := filepath.Join(work, "dest")
+	os.Mkdir(dir, 0755)
+	a, b := filepath.Join(work, "a.txt"), filepath.Join(work, "b.txt")
+	writeFile(t, a, "a")
+	writeFile(t, b, "b")
+	mv := filesystem.NewMvCommandWithJournal(journal)
+
+	if result := run(t, mv, a, b, dir); result.ExitCode != 0 {
+		t.Fatalf("mv into a directory failed: %v", result.Error)
+	}
+	if readFile(t, filepath.Join(dir, "a.txt")) != "a" || readFile(t, filepath.Join(dir, "b.txt")) != "b" {
+		t.Fatal("sources should end up inside the directory")
+	}
+
+	// Both moves were one command, so one undo puts both back
+	run(t, filesystem.NewUndoCommandWithJournal(journal))
+	if readFile(t, a) != "a" || readFile(t, b) != "b" {
+		t.Error("undo should move every source back")
+	}
+
+	result := run(t, mv, a, b, filepath.Join(work, "missing"))
+	if result.ExitCode != 1 || result.Error == nil || !strings.Contains(result.Error.Error(), "not a directory") {
+		t.Errorf("several sources need a directory target, got %v", result.Error)
+	}
+}
+
+func TestMv_CrossDevice(t *testing.T) {
+	// /dev/shm is a separate tmpfs on most Linux systems
+	src, err := ioutil.TempDir("/dev/shm", "mv-test")
+	if err != nil {
+		t.Skip("no /dev/shm to move from")
+	}
+	defer os.RemoveAll(src)
+	work, journal := journalFixture(t)
+	probe := filepath.Join(src, "probe")
+	writeFile(t, probe, "")
+	if err := os.Rename(probe, filepath.Join(work, "probe")); !errors.Is(err, syscall.EXDEV) {
+		t.Skip("/dev/shm is on the same filesystem")
+	}
+
+	tree := filepath.Join(src, "tree")
+	os.Mkdir(tree, 0750)
+	script := filepath.Join(tree, "run.sh")
+	writeFile(t, script, "#!/bin/sh\n")
+	os.Chmod(script, 0754)
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	os.Chtimes(script, mtime, mtime)
+	os.Chtimes(tree, mtime, mtime)
+
+	dest := filepath.Join(work, "tree")
+	if result := run(t, filesystem.NewMvCommandWithJournal(journal), tree, dest); result.ExitCode != 0 {
+		t.Fatalf("cross-device mv failed: %v", result.Error)
+	}
+	if _, err := os.Stat(tree); !os.IsNotExist(err) {
+		t.Error("the source should be deleted after copying")
+	}
+	for path, mode := range map[string]os.FileMode{dest: 0750, filepath.Join(dest, "run.sh"): 0754} {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != mode || !info.ModTime().Equal(mtime) {
+			t.Errorf("%s: mode %v and time %v, want %v and %v", path, info.Mode().Perm(), info.ModTime(), mode, mtime)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package filesystem_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"suppercommand/internal/commands/filesystem"
+)
+
+func TestRmdir_EmptyDirectory(t *testing.T) {
+	work, journal := journalFixture(t)
+	dir := filepath.Join(work, "empty")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if result := run(t, filesystem.NewRmdirCommandWithJournal(journal), dir); result.ExitCode != 0 {
+		t.Fatalf("rmdir of an empty directory failed: %v", result.Error)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Error("empty directory should be removed")
+	}
+}
+
+func TestRmdir_NonEmptyDirectory(t *testing.T) {
+	work, journal := journalFixture(t)
+	dir := filepath.Join(work, "full")
+	if err := os.MkdirAll(filepath.Join(dir, "nested"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, "nested", "file.txt"), "data")
+	rmdir := filesystem.NewRmdirCommandWithJournal(journal)
+
+	result := run(t, rmdir, dir)
+	if result.ExitCode == 0 || result.Error == nil || !strings.Contains(result.Error.Error(), "directory not empty") {
+		t.Fatalf("rmdir of a non-empty directory should fail with 'directory not empty', got %v", result.Error)
+	}
+	if got := readFile(t, filepath.Join(dir, "nested", "file.txt")); got != "data" {
+		t.Errorf("contents were touched: %q", got)
+	}
+
+	if result := run(t, rmdir, "--recursive", "--force", dir); result.ExitCode != 0 {
+		t.Fatalf("rmdir -r -f failed: %v", result.Error)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Error("rmdir -r -f should remove the tree")
+	}
+}
+
+func TestRmdir_Errors(t *testing.T) {
+	work, journal := journalFixture(t)
+	file := filepath.Join(work, "file.txt")
+	writeFile(t, file, "x")
+	rmdir := filesystem.NewRmdirCommandWithJournal(journal)
+
+	if result := run(t, rmdir, file); result.ExitCode == 0 || !strings.Contains(result.Error.Error(), "not a directory") {
+		t.Errorf("rmdir of a file should fail with 'not a directory', got %v", result.Error)
+	}
+	for _, args := range [][]string{{}, {"-r"}, {"--bogus", work}} {
+		if result := run(t, rmdir, args...); result.ExitCode != 1 || !strings.HasPrefix(result.Output, "Usage:") {
+			t.Errorf("rmdir %q should be a usage error, got %+v", args, result)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package filesystem_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/commands/filesystem"
+)
+
+// journalFixture returns a working directory and a journal kept outside it
+func journalFixture(t *testing.T) (string, *filesystem.Journal) {
+	root, err := ioutil.TempDir("", "undo-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(root) })
+	work := filepath.Join(root, "work")
+	if err := os.Mkdir(work, 0755); err != nil {
+		t.Fatal(err)
+	}
+	return work, filesystem.NewJournal(filepath.Join(root, "state"))
+}
+
+func run(t *testing.T, cmd commands.Command, args ...string) *commands.Result {
+	t.Helper()
+	result, err := cmd.Execute(context.Background(), commands.ParseArguments(args))
+	if err != nil {
+		t.Fatalf("%s %q: %v", cmd.Name(), args, err)
+	}
+	return result
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestUndo_Rm(t *testing.T) {
+	work, journal := journalFixture(t)
+	file := filepath.Join(work, "notes.txt")
+	tree := filepath.Join(work, "build")
+	writeFile(t, file, "keep me")
+	if err := os.MkdirAll(filepath.Join(tree, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(tree, "sub", "out.bin"), "artifact")
+
+	if result := run(t, filesystem.NewRmCommandWithJournal(journal), "-r", file, tree); result.ExitCode != 0 {
+		t.Fatalf("rm failed: %v", result.Error)
+	}
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Fatal("rm should have removed the file")
+	}
+
+	undo := filesystem.NewUndoCommandWithJournal(journal)
+	if result := run(t, undo); result.ExitCode != 0 {
+		t.Fatalf("undo failed: %v", result.Error)
+	}
+	if got := readFile(t, file); got != "keep me" {
+		t.Errorf("restored file = %q", got)
+	}
+	if got := readFile(t, filepath.Join(tree, "sub", "out.bin")); got != "artifact" {
+		t.Errorf("restored tree file = %q", got)
+	}
+
+	if entries, _ := journal.Entries(); len(entries) != 0 {
+		t.Errorf("undone operation should leave the journal, got %+v", entries)
+	}
+	if result := run(t, undo); result.ExitCode != 0 || result.Output == "" {
+		t.Errorf("undo with an empty journal should report nothing to undo, got %+v", result)
+	}
+}
+
+func TestUndo_MvRestoresOverwrittenFile(t *testing.T) {
+	work, journal := journalFixture(t)
+	source := filepath.Join(work, "new.conf")
+	dest := filepath.Join(work, "app.conf")
+	writeFile(t, source, "new")
+	writeFile(t, dest, "old")
+
+	if result := run(t, filesystem.NewMvCommandWithJournal(journal), source, dest); result.ExitCode != 0 {
+		t.Fatalf("mv failed: %v", result.Error)
+	}
+	if got := readFile(t, dest); got != "new" {
+		t.Fatalf("mv result = %q", got)
+	}
+
+	if result := run(t, filesystem.NewUndoCommandWithJournal(journal)); result.ExitCode != 0 {
+		t.Fatalf("undo failed: %v", result.Error)
+	}
+	if readFile(t, source) != "new" || readFile(t, dest) != "old" {
+		t.Errorf("undo should move the file back and restore the replaced one")
+	}
+}
+
+func TestUndo_RefusesToOverwrite(t *testing.T) {
+	work, journal := journalFixture(t)
+	file := filepath.Join(work, "a.txt")
+	writeFile(t, file, "first")
+	run(t, filesystem.NewRmCommandWithJournal(journal), file)
+	writeFile(t, file, "second")
+
+	undo := filesystem.NewUndoCommandWithJournal(journal)
+	if result := run(t, undo); result.ExitCode == 0 {
+		t.Fatal("undo should not replace a file created since")
+	}
+	if got := readFile(t, file); got != "second" {
+		t.Errorf("file was overwritten with %q", got)
+	}
+
+	// Once the obstacle is gone the operation can still be undone
+	os.Remove(file)
+	if result := run(t, undo); result.ExitCode != 0 || readFile(t, file) != "first" {
+		t.Errorf("retried undo failed: %v", result.Error)
+	}
+}
+
+func TestRmdir_Journal(t *testing.T) {
+	work, journal := journalFixture(t)
+	empty := filepath.Join(work, "empty")
+	full := filepath.Join(work, "full")
+	os.Mkdir(empty, 0755)
+	os.Mkdir(full, 0755)
+	writeFile(t, filepath.Join(full, "data"), "x")
+
+	rmdir := filesystem.NewRmdirCommandWithJournal(journal)
+	if result := run(t, rmdir, full); result.ExitCode == 0 {
+		t.Error("rmdir should refuse a non-empty directory without -r")
+	}
+	if result := run(t, rmdir, empty); result.ExitCode != 0 {
+		t.Fatalf("rmdir failed: %v", result.Error)
+	}
+	if result := run(t, rmdir, "-rf", full); result.ExitCode != 0 {
+		t.Fatalf("rmdir -r failed: %v", result.Error)
+	}
+
+	undo := filesystem.NewUndoCommandWithJournal(journal)
+	run(t, undo)
+	if readFile(t, filepath.Join(full, "data")) != "x" {
+		t.Error("undo should restore the tree removed by rmdir -r")
+	}
+	run(t, undo)
+	if info, err := os.Stat(empty); err != nil || !info.IsDir() {
+		t.Errorf("undo should recreate the empty directory: %v", err)
+	}
+}
@@ -0,0 +1,131 @@
+package filesystem_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/commands/filesystem"
+	"suppercommand/internal/config"
+	"suppercommand/internal/monitoring"
+)
+
+func watchFixture(t *testing.T) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "watchdir-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+// nextEvent waits for the watcher to report an event on path
+func nextEvent(t *testing.T, watcher *filesystem.TreeWatcher, path string) filesystem.WatchEvent {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event := <-watcher.Events:
+			if event.Path == path {
+				return event
+			}
+		case err := <-watcher.Errors:
+			t.Fatalf("watch error: %v", err)
+		case <-timeout:
+			t.Fatalf("no event for %s", path)
+		}
+	}
+}
+
+func TestTreeWatcher_Recursive(t *testing.T) {
+	dir := watchFixture(t)
+	if err := os.Mkdir(filepath.Join(dir, "existing"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	watcher, err := filesystem.NewTreeWatcher(dir, true, []string{"*.tmp"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+	if watcher.Watched() != 2 {
+		t.Errorf("watching %d directories, want 2", watcher.Watched())
+	}
+
+	file := filepath.Join(dir, "existing", "a.txt")
+	writeFile(t, file, "one")
+	if event := nextEvent(t, watcher, file); event.Op != filesystem.WatchCreate {
+		t.Errorf("got %s for a new file, want create", event.Op)
+	}
+
+	// A directory created while watching is watched too
+	sub := filepath.Join(dir, "new")
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	nextEvent(t, watcher, sub)
+	writeFile(t, filepath.Join(sub, "skip.tmp"), "ignored")
+	nested := filepath.Join(sub, "b.txt")
+	writeFile(t, nested, "two")
+	if event := nextEvent(t, watcher, nested); event.Op != filesystem.WatchCreate {
+		t.Errorf("got %s in a new directory, want create", event.Op)
+	}
+
+	if err := os.Remove(file); err != nil {
+		t.Fatal(err)
+	}
+	if event := nextEvent(t, watcher, file); event.Op != filesystem.WatchRemove {
+		t.Errorf("got %s for a removed file, want remove", event.Op)
+	}
+}
+
+func TestWatchdir_OnChange(t *testing.T) {
+	dir := watchFixture(t)
+	registry := commands.NewRegistry(monitoring.NewLogger(config.MonitoringConfig{}))
+	if err := registry.Register(filesystem.NewEchoCommand()); err != nil {
+		t.Fatal(err)
+	}
+	watchdir := filesystem.NewWatchdirCommand(registry)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan *commands.Result)
+	go func() {
+		result, _ := watchdir.Execute(ctx, commands.ParseArguments([]string{dir, "--on-change", "echo synced", "--debounce", "100ms"}))
+		done <- result
+	}()
+
+	// A burst of changes runs the command once
+	time.Sleep(200 * time.Millisecond)
+	for _, name := range []string{"a", "b", "c"} {
+		writeFile(t, filepath.Join(dir, name), name)
+	}
+	time.Sleep(500 * time.Millisecond)
+	cancel()
+
+	result := <-done
+	if result.ExitCode != 0 || !strings.Contains(result.Output, "1 runs of echo") {
+		t.Errorf("want one debounced run, got %q", result.Output)
+	}
+}
+
+func TestWatchdir_Usage(t *testing.T) {
+	dir := watchFixture(t)
+	registry := commands.NewRegistry(monitoring.NewLogger(config.MonitoringConfig{}))
+	watchdir := filesystem.NewWatchdirCommand(registry)
+
+	for _, args := range [][]string{
+		{dir, dir},
+		{dir, "--debounce", "soon"},
+		{dir, "--on-change", "nosuchcommand"},
+		{filepath.Join(dir, "missing")},
+	} {
+		if result := run(t, watchdir, args...); result.ExitCode == 0 || result.Error == nil {
+			t.Errorf("watchdir %q should fail, got %+v", args, result)
+		}
+	}
+}
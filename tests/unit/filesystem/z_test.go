@@ -0,0 +1,131 @@
+package filesystem_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"suppercommand/internal/commands/filesystem"
+)
+
+// jumpFixture returns a tree of directories and a database kept outside it,
+// restoring the working directory when the test ends
+func jumpFixture(t *testing.T) (string, *filesystem.JumpDB) {
+	t.Helper()
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{"work/projects/billing-api", "work/projects/billing-web", "notes/api-docs"} {
+		if err := os.MkdirAll(filepath.Join(root, filepath.FromSlash(dir)), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	return root, filesystem.NewJumpDB(filepath.Join(root, "state", "jumps.json"))
+}
+
+func cwd(t *testing.T) string {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wd, _ = filepath.EvalSymlinks(wd)
+	return wd
+}
+
+func TestJumpEntry_ScoreFavorsRecentVisits(t *testing.T) {
+	now := time.Now()
+	entry := filesystem.JumpEntry{Rank: 8}
+	for age, want := range map[time.Duration]float64{
+		time.Minute:         32,
+		3 * time.Hour:       16,
+		3 * 24 * time.Hour:  4,
+		30 * 24 * time.Hour: 2,
+	} {
+		entry.LastVisit = now.Add(-age)
+		if got := entry.Score(now); got != want {
+			t.Errorf("score %v after %v, want %v", got, age, want)
+		}
+	}
+}
+
+func TestZ_JumpsToBestMatch(t *testing.T) {
+	root, jumps := jumpFixture(t)
+	cd := filesystem.NewCdCommandWithJumps(jumps)
+	api := filepath.Join(root, "work", "projects", "billing-api")
+	web := filepath.Join(root, "work", "projects", "billing-web")
+	docs := filepath.Join(root, "notes", "api-docs")
+	for _, dir := range []string{web, api, docs, api, api, web} {
+		if result := run(t, cd, dir); result.ExitCode != 0 {
+			t.Fatalf("cd %s failed: %v", dir, result.Error)
+		}
+	}
+
+	z := filesystem.NewZCommandWithJumps(jumps)
+	if result := run(t, z, "billing"); result.ExitCode != 0 || cwd(t) != api {
+		t.Errorf("z billing should enter the most visited match %s, in %s (%v)", api, cwd(t), result.Error)
+	}
+	if result := run(t, z, "proj", "WEB"); result.ExitCode != 0 || cwd(t) != web {
+		t.Errorf("z proj WEB should enter %s, in %s (%v)", web, cwd(t), result.Error)
+	}
+	if result := run(t, z, "web", "proj"); result.ExitCode == 0 {
+		t.Error("parts must match in order")
+	}
+
+	result := run(t, z, "-l", "api")
+	lines := strings.Split(strings.TrimSpace(result.Output), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], docs) || !strings.HasSuffix(lines[1], api) {
+		t.Errorf("z -l should list the matches, best last:\n%s", result.Output)
+	}
+	if got := z.CompleteArguments([]string{"api"}); !reflect.DeepEqual(got, []string{api, docs}) {
+		t.Errorf("completions = %v", got)
+	}
+
+	// A directory that's gone is forgotten rather than entered
+	if err := os.RemoveAll(api); err != nil {
+		t.Fatal(err)
+	}
+	if result := run(t, z, "billing"); result.ExitCode != 0 || cwd(t) != web {
+		t.Errorf("z should skip a removed directory, in %s (%v)", cwd(t), result.Error)
+	}
+	entries, err := jumps.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if entry.Path == api {
+			t.Errorf("%s should have been forgotten", api)
+		}
+	}
+}
+
+func TestCd_SuggestsDirectories(t *testing.T) {
+	root, jumps := jumpFixture(t)
+	cd := filesystem.NewCdCommandWithJumps(jumps)
+
+	result := run(t, cd, filepath.Join(root, "work", "projetcs"))
+	if result.ExitCode == 0 || result.Error == nil || !strings.Contains(result.Error.Error(), "did you mean '"+filepath.Join(root, "work", "projects")+"'") {
+		t.Errorf("a typo should suggest the sibling, got %v", result.Error)
+	}
+
+	docs := filepath.Join(root, "notes", "api-docs")
+	run(t, cd, docs)
+	result = run(t, cd, filepath.Join(root, "elsewhere", "api-docs"))
+	if result.Error == nil || !strings.Contains(result.Error.Error(), "did you mean '"+docs+"'") {
+		t.Errorf("a missing directory should suggest a visited one, got %v", result.Error)
+	}
+
+	result = run(t, cd, filepath.Join(root, "nothing-like-it"))
+	if result.Error == nil || strings.Contains(result.Error.Error(), "did you mean") {
+		t.Errorf("no suggestion expected, got %v", result.Error)
+	}
+}
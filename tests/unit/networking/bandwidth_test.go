@@ -0,0 +1,59 @@
+package networking_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/commands/networking"
+)
+
+func TestBandwidth_ParallelStreams(t *testing.T) {
+	port := freePort(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	serverDone := make(chan *commands.Result, 1)
+	go func() {
+		result, _ := networking.NewBandwidthCommand().Execute(ctx, commands.ParseArguments([]string{
+			"--server", "--port", fmt.Sprintf("%d", port), "--once",
+		}))
+		serverDone <- result
+	}()
+	waitForPort(t, port)
+
+	result, err := networking.NewBandwidthCommand().Execute(ctx, commands.ParseArguments([]string{
+		"--client", fmt.Sprintf("127.0.0.1:%d", port), "--duration", "300ms", "--parallel", "3",
+	}))
+	if err != nil || result.ExitCode != 0 {
+		t.Fatalf("client failed: err=%v output=%s", err, result.Output)
+	}
+	for _, want := range []string{"[  0]", "[  2]", "Total:", "Mbps"} {
+		if !strings.Contains(result.Output, want) {
+			t.Errorf("client output missing %q:\n%s", want, result.Output)
+		}
+	}
+
+	select {
+	case server := <-serverDone:
+		if server.ExitCode != 0 || !strings.Contains(server.Output, "3 streams") {
+			t.Errorf("server did not report the session:\n%s", server.Output)
+		}
+	case <-ctx.Done():
+		t.Fatal("server did not stop after the first session with --once")
+	}
+}
+
+func TestBandwidth_RequiresMode(t *testing.T) {
+	result, err := networking.NewBandwidthCommand().Execute(context.Background(), commands.ParseArguments(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ExitCode == 0 || !strings.Contains(result.Output, "Usage") {
+		t.Errorf("expected usage error, got exit %d:\n%s", result.ExitCode, result.Output)
+	}
+}
@@ -0,0 +1,81 @@
+package networking_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/commands/networking"
+)
+
+func TestCheckCapabilities(t *testing.T) {
+	capabilities := networking.CheckCapabilities()
+	features := []string{networking.CapabilityCapture, networking.CapabilitySend, networking.CapabilityTools, networking.CapabilityTrace}
+	if len(capabilities) != len(features) {
+		t.Fatalf("expected %d features, got %+v", len(features), capabilities)
+	}
+	for i, capability := range capabilities {
+		if capability.Feature != features[i] {
+			t.Errorf("feature %d is %q, want %q", i, capability.Feature, features[i])
+		}
+		if len(capability.Commands) == 0 || capability.Detail == "" {
+			t.Errorf("%s should name its commands and what was found: %+v", capability.Feature, capability)
+		}
+		err := networking.RequireCapability(capability.Feature)
+		if capability.Available {
+			if err != nil || capability.Fix != "" {
+				t.Errorf("%s is available, yet %v / fix %q", capability.Feature, err, capability.Fix)
+			}
+			continue
+		}
+		if capability.Fix == "" {
+			t.Errorf("%s is unavailable without a fix", capability.Feature)
+		}
+		if _, ok := err.(*networking.CapabilityError); !ok || !strings.Contains(err.Error(), capability.Fix) {
+			t.Errorf("%s: the error should carry the fix, got %v", capability.Feature, err)
+		}
+	}
+}
+
+func TestCapabilitiesCommand(t *testing.T) {
+	cmd := networking.NewCapabilitiesCommand()
+	result, err := cmd.Execute(context.Background(), commands.ParseArguments([]string{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ExitCode != 0 || !strings.Contains(result.Output, "CAPABILITY CHECK") || !strings.Contains(result.Output, "replay --send") {
+		t.Errorf("unexpected report:\n%s", result.Output)
+	}
+
+	result, err = cmd.Execute(context.Background(), commands.ParseArguments([]string{"--json"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report struct {
+		Platform     string                  `json:"platform"`
+		Capabilities []networking.Capability `json:"capabilities"`
+	}
+	if err := json.Unmarshal([]byte(result.Output), &report); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, result.Output)
+	}
+	if report.Platform == "" || len(report.Capabilities) != 4 {
+		t.Errorf("unexpected JSON report: %s", result.Output)
+	}
+}
+
+func TestPing_MissingToolExplainsFix(t *testing.T) {
+	path := os.Getenv("PATH")
+	os.Setenv("PATH", "")
+	defer os.Setenv("PATH", path)
+
+	result, _ := networking.NewPingCommand().Execute(context.Background(), commands.ParseArguments([]string{"127.0.0.1", "-c", "1"}))
+	if result == nil || result.Error == nil {
+		t.Fatal("ping without a ping tool should fail")
+	}
+	if !strings.Contains(result.Error.Error(), "to fix it, ") {
+		t.Errorf("the error should say how to get the tool: %v", result.Error)
+	}
+}
@@ -0,0 +1,142 @@
+package networking_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/commands/networking"
+)
+
+// capstatsJSON is the part of capstats --json the tests look at
+type capstatsJSON struct {
+	Files []struct {
+		File      string  `json:"file"`
+		Packets   int     `json:"packets"`
+		Bytes     int64   `json:"bytes"`
+		Seconds   float64 `json:"duration_seconds"`
+		Protocols []struct {
+			Protocol string `json:"protocol"`
+			Packets  int    `json:"packets"`
+		} `json:"protocols"`
+		Talkers []struct {
+			Host    string `json:"host"`
+			Packets int    `json:"packets"`
+		} `json:"top_talkers"`
+		Conversations []struct {
+			Destination string `json:"destination"`
+			Port        int    `json:"port"`
+		} `json:"conversations"`
+		Error string `json:"error"`
+	} `json:"files"`
+	Combined *struct {
+		Packets int `json:"packets"`
+	} `json:"combined"`
+}
+
+func runCapstats(t *testing.T, args ...string) *commands.Result {
+	result, err := networking.NewCapstatsCommand().Execute(context.Background(), commands.ParseArguments(args))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestCapstats_JSON(t *testing.T) {
+	capture, cleanup := writeCapture(t)
+	defer cleanup()
+
+	result := runCapstats(t, capture, "--json")
+	if result.ExitCode != 0 {
+		t.Fatalf("capstats failed: %s", result.Output)
+	}
+	var report capstatsJSON
+	if err := json.Unmarshal([]byte(result.Output), &report); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, result.Output)
+	}
+	if len(report.Files) != 1 || report.Combined != nil {
+		t.Fatalf("one file should give one summary and no combined one: %s", result.Output)
+	}
+	file := report.Files[0]
+	if file.Packets != 3 || file.Bytes == 0 {
+		t.Errorf("expected 3 packets with bytes, got %d packets, %d bytes", file.Packets, file.Bytes)
+	}
+	if file.Seconds < 0.199 || file.Seconds > 0.201 {
+		t.Errorf("the packets span 200ms, got %vs", file.Seconds)
+	}
+	protocols := map[string]int{}
+	for _, share := range file.Protocols {
+		protocols[share.Protocol] = share.Packets
+	}
+	if protocols["HTTP"] != 1 || protocols["DNS"] != 1 || protocols["ICMP"] != 1 {
+		t.Errorf("unexpected protocol breakdown %v", protocols)
+	}
+	if len(file.Talkers) == 0 || file.Talkers[0].Host != "192.168.1.10" || file.Talkers[0].Packets != 3 {
+		t.Errorf("the host in every packet should top the talkers: %+v", file.Talkers)
+	}
+	if len(file.Conversations) != 3 {
+		t.Errorf("expected 3 conversations, got %+v", file.Conversations)
+	}
+}
+
+func TestCapstats_ComparesFiles(t *testing.T) {
+	first, cleanup := writeCapture(t)
+	defer cleanup()
+	second, cleanup := writeCapture(t)
+	defer cleanup()
+
+	result := runCapstats(t, first, second, "--top", "1")
+	if result.ExitCode != 0 {
+		t.Fatalf("capstats failed: %s", result.Output)
+	}
+	for _, want := range []string{"Capture ", "Top protocol", "2 captures", "📦 Packets:     6", "PROTOCOLS", "TOP TALKERS", "TRAFFIC FLOWS", "more flows"} {
+		if !strings.Contains(result.Output, want) {
+			t.Errorf("output should contain %q:\n%s", want, result.Output)
+		}
+	}
+
+	result = runCapstats(t, first, second, "--json")
+	var report capstatsJSON
+	if err := json.Unmarshal([]byte(result.Output), &report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Files) != 2 || report.Combined == nil || report.Combined.Packets != 6 {
+		t.Errorf("two files should be summarized apart and together: %s", result.Output)
+	}
+}
+
+func TestCapstats_TruncatedCapture(t *testing.T) {
+	capture, cleanup := writeCapture(t)
+	defer cleanup()
+	data, err := ioutil.ReadFile(capture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(capture, data[:len(data)-10], 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := runCapstats(t, capture, "--json")
+	if result.ExitCode == 0 {
+		t.Error("a truncated capture should fail")
+	}
+	var report capstatsJSON
+	if err := json.Unmarshal([]byte(result.Output), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Files[0].Packets != 2 || !strings.Contains(report.Files[0].Error, "truncated") {
+		t.Errorf("the packets before the cut should be counted and the cut reported: %+v", report.Files[0])
+	}
+}
+
+func TestCapstats_NeedsFile(t *testing.T) {
+	if result := runCapstats(t); result.Error == nil {
+		t.Error("capstats without a file should be a usage error")
+	}
+	if result := runCapstats(t, "/nonexistent/capture.pcap"); result.ExitCode == 0 {
+		t.Error("a missing file should fail")
+	}
+}
@@ -0,0 +1,139 @@
+package networking_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/commands/networking"
+	"suppercommand/internal/security"
+)
+
+// fastcpKey runs fastcp-key with --output-format json and returns what it printed
+func fastcpKey(t *testing.T, args ...string) map[string]interface{} {
+	t.Helper()
+	ctx := commands.WithOutputOptions(context.Background(), commands.OutputOptions{Format: commands.FormatJSON})
+	result, err := networking.NewFastcpKeyCommand().Execute(ctx, commands.ParseArguments(args))
+	if err != nil || result.ExitCode != 0 {
+		t.Fatalf("fastcp-key %v failed: %+v, %v", args, result, err)
+	}
+	var key map[string]interface{}
+	if err := json.Unmarshal([]byte(result.Output), &key); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, result.Output)
+	}
+	return key
+}
+
+func TestFastcpKey_Characters(t *testing.T) {
+	key := fastcpKey(t)
+	if !regexp.MustCompile(`^[A-HJ-NP-Za-km-z2-9]{32}$`).MatchString(key["key"].(string)) {
+		t.Errorf("the default key should be 32 unambiguous characters, got %q", key["key"])
+	}
+	if key["kind"] != "characters" || key["bits"].(float64) != 186 {
+		t.Errorf("32 characters from 57 should be 186 bits, got %v", key)
+	}
+	if other := fastcpKey(t)["key"]; other == key["key"] {
+		t.Error("two keys should not be the same")
+	}
+	if key = fastcpKey(t, "--length", "16"); len(key["key"].(string)) != 16 {
+		t.Errorf("--length 16 made %q", key["key"])
+	}
+}
+
+func TestFastcpKey_Words(t *testing.T) {
+	key := fastcpKey(t, "--words", "8")
+	words := strings.Split(key["key"].(string), "-")
+	if len(words) != 8 || key["kind"] != "words" || key["bits"].(float64) != 80 {
+		t.Fatalf("8 words from 1024 should be 80 bits, got %v", key)
+	}
+	for _, word := range words {
+		if !regexp.MustCompile(`^[a-z]{2,8}$`).MatchString(word) {
+			t.Errorf("passphrase words should be short lowercase words, got %q", word)
+		}
+	}
+
+	key = fastcpKey(t, "--words", "6", "--separator", " ")
+	if len(strings.Fields(key["key"].(string))) != 6 {
+		t.Errorf("--separator should join the words, got %q", key["key"])
+	}
+
+	result, _ := networking.NewFastcpKeyCommand().Execute(context.Background(), commands.ParseArguments([]string{"--words", "6"}))
+	if !strings.Contains(result.Output, "60 bits") || !strings.Contains(result.Output, "Under 80 bits") {
+		t.Errorf("a weaker passphrase should come with a warning:\n%s", result.Output)
+	}
+}
+
+func TestFastcpKey_UsageErrors(t *testing.T) {
+	for _, args := range [][]string{
+		{"--length", "8"},
+		{"--words", "3"},
+		{"--words", "8", "--length", "20"},
+		{"--force"},
+		{"extra"},
+	} {
+		result, _ := networking.NewFastcpKeyCommand().Execute(context.Background(), commands.ParseArguments(args))
+		if result.ExitCode != 1 || result.Error == nil {
+			t.Errorf("%v should be a usage error, got %+v", args, result)
+		}
+	}
+}
+
+func TestFastcpKey_Save(t *testing.T) {
+	home, err := ioutil.TempDir("", "supershell-home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+	oldHome, oldProfile, oldPassword := os.Getenv("HOME"), os.Getenv("USERPROFILE"), os.Getenv(security.MasterPasswordEnv)
+	os.Setenv("HOME", home)
+	os.Setenv("USERPROFILE", home)
+	os.Setenv(security.MasterPasswordEnv, "master")
+	defer func() {
+		os.Setenv("HOME", oldHome)
+		os.Setenv("USERPROFILE", oldProfile)
+		os.Setenv(security.MasterPasswordEnv, oldPassword)
+	}()
+
+	key := fastcpKey(t, "--save", "laptop")
+	if key["saved_as"] != "laptop" {
+		t.Fatalf("the key should be reported as saved, got %v", key)
+	}
+	cred, err := security.LoadCredential("laptop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cred.Type != security.CredentialTypeKey || cred.Secret() != key["key"] {
+		t.Errorf("the stored credential should be a key holding the generated key, got %+v", cred)
+	}
+	if _, err := os.Stat(filepath.Join(home, ".supershell", "credentials.json")); err != nil {
+		t.Errorf("the key should be in the default store: %v", err)
+	}
+
+	// An existing credential is only replaced with --force
+	result, _ := networking.NewFastcpKeyCommand().Execute(context.Background(), commands.ParseArguments([]string{"--save", "laptop"}))
+	if result.ExitCode != 1 || !strings.Contains(result.Error.Error(), "already exists") {
+		t.Errorf("saving over a credential should fail without --force, got %+v", result)
+	}
+	replaced := fastcpKey(t, "--save", "laptop", "--force")
+	if cred, _ = security.LoadCredential("laptop"); cred.Secret() != replaced["key"] {
+		t.Error("--force should replace the stored key")
+	}
+}
+
+func TestFastcpKey_QR(t *testing.T) {
+	quiet := commands.WithOutputOptions(context.Background(), commands.OutputOptions{Quiet: true, Format: commands.FormatText})
+	result, err := networking.NewFastcpKeyCommand().Execute(quiet, commands.ParseArguments([]string{"--words", "6", "--qr"}))
+	if err != nil || result.ExitCode != 0 {
+		t.Fatalf("fastcp-key --qr failed: %+v, %v", result, err)
+	}
+	lines := strings.Split(result.Output, "\n")
+	if strings.Count(lines[0], "-") != 5 || !strings.Contains(result.Output, "█") {
+		t.Errorf("--quiet --qr should print the key and then its code:\n%s", result.Output)
+	}
+}
@@ -0,0 +1,1182 @@
+package networking_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/commands/networking"
+	"suppercommand/internal/config"
+	"suppercommand/internal/monitoring"
+)
+
+// freePort reserves an unused local TCP port
+func freePort(t *testing.T) int {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to reserve port: %v", err)
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+// waitForPort blocks until something accepts connections on port
+func waitForPort(t *testing.T, port int) {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		conn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+		if err == nil {
+			conn.Close()
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("receiver never listened on port %d", port)
+}
+
+// tempDir creates a temporary directory removed by the returned cleanup
+func tempDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "supershell-fastcp")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+func TestFastcp_ServeHandlesConcurrentSenders(t *testing.T) {
+	root, cleanup := tempDir(t)
+	defer cleanup()
+
+	destination := filepath.Join(root, "received")
+	port := freePort(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	recvDone := make(chan *commands.Result, 1)
+	go func() {
+		result, _ := networking.NewFastcpRecvCommand().Execute(ctx, commands.ParseArguments([]string{
+			destination, "-p", fmt.Sprintf("%d", port), "--serve", "--max-conns", "2",
+		}))
+		recvDone <- result
+	}()
+	waitForPort(t, port)
+
+	// Two senders with different content and an identically named nested file
+	contents := map[string][]byte{
+		"alpha": bytes.Repeat([]byte("A"), 300*1024),
+		"beta":  bytes.Repeat([]byte("B"), 200*1024+17),
+	}
+	for name, data := range contents {
+		dir := filepath.Join(root, name, "sub")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, "data.bin"), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for name := range contents {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			result, err := networking.NewFastcpSendCommand().Execute(context.Background(), commands.ParseArguments([]string{
+				filepath.Join(root, name), "127.0.0.1", "-p", fmt.Sprintf("%d", port),
+			}))
+			if err != nil || result.ExitCode != 0 {
+				t.Errorf("sender %s failed: err=%v output=%s", name, err, result.Output)
+			}
+		}(name)
+	}
+	wg.Wait()
+
+	for name, want := range contents {
+		got, err := ioutil.ReadFile(filepath.Join(destination, name, "sub", "data.bin"))
+		if err != nil {
+			t.Fatalf("missing file from sender %s: %v", name, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("file from sender %s corrupted: got %d bytes, want %d", name, len(got), len(want))
+		}
+	}
+
+	leftovers, _ := filepath.Glob(filepath.Join(destination, ".fastcp-*.journal"))
+	if len(leftovers) != 0 {
+		t.Errorf("journals left behind after successful transfers: %v", leftovers)
+	}
+
+	cancel()
+	select {
+	case result := <-recvDone:
+		if !strings.Contains(result.Output, "Transfers: 2 (0 failed)") {
+			t.Errorf("unexpected receiver summary:\n%s", result.Output)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("receiver did not stop after cancellation")
+	}
+}
+
+func TestFastcp_SingleTransferRejectsTraversal(t *testing.T) {
+	root, cleanup := tempDir(t)
+	defer cleanup()
+
+	port := freePort(t)
+	recvDone := make(chan *commands.Result, 1)
+	go func() {
+		result, _ := networking.NewFastcpRecvCommand().Execute(context.Background(), commands.ParseArguments([]string{
+			root, "-p", fmt.Sprintf("%d", port), "--auto-accept",
+		}))
+		recvDone <- result
+	}()
+	waitForPort(t, port)
+
+	conn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	fmt.Fprintf(conn, "{\"version\":1,\"transfer_id\":\"x\",\"files\":[{\"path\":\"../evil\",\"size\":1}],\"total_size\":1}\n")
+
+	select {
+	case result := <-recvDone:
+		if result.ExitCode == 0 {
+			t.Errorf("receiver accepted a path traversal:\n%s", result.Output)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("receiver did not finish")
+	}
+
+	if _, err := os.Stat(filepath.Join(filepath.Dir(root), "evil")); err == nil {
+		t.Error("file written outside destination")
+	}
+}
+
+func TestFastcpSend_DryRunDoesNotConnect(t *testing.T) {
+	root, cleanup := tempDir(t)
+	defer cleanup()
+
+	if err := ioutil.WriteFile(filepath.Join(root, "report.txt"), []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing listens on this port, so a real send would fail
+	port := freePort(t)
+	result, err := networking.NewFastcpSendCommand().Execute(context.Background(), commands.ParseArguments([]string{
+		root, "127.0.0.1", "-p", fmt.Sprintf("%d", port), "--dry-run",
+	}))
+	if err != nil || result.ExitCode != 0 {
+		t.Fatalf("dry run failed: err=%v output=%s", err, result.Output)
+	}
+	if !strings.Contains(result.Output, "report.txt") || !strings.Contains(result.Output, "DRY RUN") {
+		t.Errorf("dry run output does not list files:\n%s", result.Output)
+	}
+}
+
+func TestFastcpSend_FlagErrors(t *testing.T) {
+	send := networking.NewFastcpSendCommand()
+	run := func(args ...string) *commands.Result {
+		result, err := send.Execute(context.Background(), commands.ParseArguments(args))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	if result := run(".", "127.0.0.1", "--encrpyt"); result.ExitCode != 1 || result.Error == nil || !strings.Contains(result.Error.Error(), "unknown flag --encrpyt") {
+		t.Errorf("misspelled flags should be rejected, got exit %d: %v", result.ExitCode, result.Error)
+	}
+	if result := run(".", "127.0.0.1", "-p", "http"); result.ExitCode != 1 || result.Error == nil {
+		t.Errorf("a non-numeric port should be rejected, got exit %d", result.ExitCode)
+	}
+	if result := run("--help"); result.ExitCode != 0 || !strings.Contains(result.Output, "--exclude-from <file>") {
+		t.Errorf("--help should list the flags, got exit %d:\n%s", result.ExitCode, result.Output)
+	}
+}
+
+func TestFastcpSend_DryRunFilters(t *testing.T) {
+	root, cleanup := tempDir(t)
+	defer cleanup()
+
+	project := filepath.Join(root, "project")
+	for name, content := range map[string]string{
+		"src/main.go":             "package main",
+		"src/main_test.go":        "package main",
+		"src/vendor/lib.go":       "package lib",
+		"node_modules/pkg/x.js":   "x",
+		".git/HEAD":               "ref",
+		"build.log":               "noise",
+		"logs/keep.log":           "keep",
+		"docs/node_modules/a.txt": "nested",
+	} {
+		path := filepath.Join(project, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ignore := filepath.Join(root, "ignore")
+	if err := ioutil.WriteFile(ignore, []byte("# build output\nnode_modules/\n*.log\n!keep.log\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dryRun := func(extra ...string) string {
+		raw := append([]string{project, "127.0.0.1", "-p", fmt.Sprintf("%d", freePort(t)), "--dry-run"}, extra...)
+		result, err := networking.NewFastcpSendCommand().Execute(context.Background(), commands.ParseArguments(raw))
+		if err != nil || result.ExitCode != 0 {
+			t.Fatalf("dry run failed: err=%v output=%s", err, result.Output)
+		}
+		return result.Output
+	}
+
+	output := dryRun("--exclude-from", ignore, "--exclude", ".git")
+	for _, want := range []string{"project/src/main.go", "project/src/vendor/lib.go", "project/logs/keep.log"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %s to be sent:\n%s", want, output)
+		}
+	}
+	for _, unwanted := range []string{"x.js", "HEAD", "build.log", "a.txt"} {
+		if strings.Contains(output, unwanted) {
+			t.Errorf("expected %s to be excluded:\n%s", unwanted, output)
+		}
+	}
+	if !strings.Contains(output, "Excluded:    1 files, 3 directories") {
+		t.Errorf("missing exclusion summary:\n%s", output)
+	}
+
+	// Includes narrow the selection and paths are relative to the source root
+	output = dryRun("--include", "*.go", "--exclude=*_test.go", "--exclude", "src/vendor/")
+	if !strings.Contains(output, "project/src/main.go") || strings.Contains(output, "main_test.go") ||
+		strings.Contains(output, "lib.go") || strings.Contains(output, "keep.log") {
+		t.Errorf("unexpected selection for --include/--exclude:\n%s", output)
+	}
+}
+
+func TestFastcp_SymlinksSentAsLinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("creating symlinks needs extra privileges on windows")
+	}
+	root, cleanup := tempDir(t)
+	defer cleanup()
+
+	source := filepath.Join(root, "project")
+	if err := os.MkdirAll(source, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(source, "real.txt"), []byte("content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for link, target := range map[string]string{"alias": "real.txt", "loop": ".", "outside": "/etc/hosts"} {
+		if err := os.Symlink(target, filepath.Join(source, link)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Following links must terminate despite the loop and send the alias as a file
+	port := freePort(t)
+	result, err := networking.NewFastcpSendCommand().Execute(context.Background(), commands.ParseArguments([]string{
+		source, "127.0.0.1", "-p", fmt.Sprintf("%d", port), "--dry-run", "--follow-symlinks",
+	}))
+	if err != nil || result.ExitCode != 0 {
+		t.Fatalf("dry run failed: err=%v output=%s", err, result.Output)
+	}
+	if !strings.Contains(result.Output, "link loops") || strings.Contains(result.Output, " -> ") {
+		t.Errorf("unexpected --follow-symlinks selection:\n%s", result.Output)
+	}
+
+	destination := filepath.Join(root, "received")
+	recvDone := make(chan *commands.Result, 1)
+	go func() {
+		result, _ := networking.NewFastcpRecvCommand().Execute(context.Background(), commands.ParseArguments([]string{
+			destination, "-p", fmt.Sprintf("%d", port), "--auto-accept",
+		}))
+		recvDone <- result
+	}()
+	waitForPort(t, port)
+
+	result, err = networking.NewFastcpSendCommand().Execute(context.Background(), commands.ParseArguments([]string{
+		source, "127.0.0.1", "-p", fmt.Sprintf("%d", port),
+	}))
+	if err != nil || result.ExitCode != 0 {
+		t.Fatalf("send failed: err=%v output=%s", err, result.Output)
+	}
+	if !strings.Contains(result.Output, "3 sent as links") {
+		t.Errorf("sender did not report links:\n%s", result.Output)
+	}
+
+	recvResult := <-recvDone
+	if target, err := os.Readlink(filepath.Join(destination, "project", "alias")); err != nil || target != "real.txt" {
+		t.Errorf("alias not recreated as a link: %q, %v", target, err)
+	}
+	if target, err := os.Readlink(filepath.Join(destination, "project", "loop")); err != nil || target != "." {
+		t.Errorf("loop not recreated as a link: %q, %v", target, err)
+	}
+	if _, err := os.Lstat(filepath.Join(destination, "project", "outside")); err == nil {
+		t.Error("absolute link recreated")
+	}
+	if !strings.Contains(recvResult.Output, "Skipped link project/outside") {
+		t.Errorf("receiver did not report the skipped link:\n%s", recvResult.Output)
+	}
+}
+
+func TestFastcp_LinkChainCannotEscape(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("creating symlinks needs extra privileges on windows")
+	}
+	root, cleanup := tempDir(t)
+	defer cleanup()
+
+	destination := filepath.Join(root, "received")
+	port := freePort(t)
+	recvDone := make(chan *commands.Result, 1)
+	go func() {
+		result, _ := networking.NewFastcpRecvCommand().Execute(context.Background(), commands.ParseArguments([]string{
+			destination, "-p", fmt.Sprintf("%d", port), "--auto-accept",
+		}))
+		recvDone <- result
+	}()
+	waitForPort(t, port)
+
+	conn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// "a" points at its own directory, so "a/a/b" really lives beside it and "../.."
+	// from there leaves the destination even though it looks safe lexically
+	fmt.Fprintf(conn, "{\"version\":1,\"transfer_id\":\"x\",\"files\":[{\"path\":\"a\",\"size\":0,\"link\":\".\"},{\"path\":\"a/a/b\",\"size\":0,\"link\":\"../..\"}]}\n")
+	fmt.Fprintf(conn, "{\"checksum\":\"\"}\n{\"checksum\":\"\"}\n")
+
+	select {
+	case <-recvDone:
+	case <-time.After(5 * time.Second):
+		t.Fatal("receiver did not finish")
+	}
+
+	if _, err := os.Lstat(filepath.Join(destination, "b")); err == nil {
+		t.Error("escaping link created")
+	}
+	if target, err := os.Readlink(filepath.Join(destination, "a")); err != nil || target != "." {
+		t.Errorf("safe link not created: %q, %v", target, err)
+	}
+}
+
+func TestFastcp_StatsReport(t *testing.T) {
+	root, cleanup := tempDir(t)
+	defer cleanup()
+
+	source := filepath.Join(root, "payload.bin")
+	if err := ioutil.WriteFile(source, bytes.Repeat([]byte("x"), 4096), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	port := freePort(t)
+	recvStats := filepath.Join(root, "recv.json")
+	sendStats := filepath.Join(root, "send.json")
+
+	recvDone := make(chan *commands.Result, 1)
+	go func() {
+		result, _ := networking.NewFastcpRecvCommand().Execute(context.Background(), commands.ParseArguments([]string{
+			"--stats", recvStats, filepath.Join(root, "out"), "-p", fmt.Sprintf("%d", port), "--auto-accept",
+		}))
+		recvDone <- result
+	}()
+	waitForPort(t, port)
+
+	result, err := networking.NewFastcpSendCommand().Execute(context.Background(), commands.ParseArguments([]string{
+		source, "127.0.0.1", "-p", fmt.Sprintf("%d", port), "--stats", sendStats,
+	}))
+	if err != nil || result.ExitCode != 0 {
+		t.Fatalf("send failed: err=%v output=%s", err, result.Output)
+	}
+	if recv := <-recvDone; recv.ExitCode != 0 {
+		t.Fatalf("receive failed:\n%s", recv.Output)
+	}
+
+	for path, wantSent := range map[string]bool{sendStats: true, recvStats: false} {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatalf("stats file %s not written: %v", path, err)
+		}
+
+		var stats networking.TransferStats
+		if err := json.Unmarshal(data, &stats); err != nil {
+			t.Fatalf("invalid stats JSON in %s: %v", path, err)
+		}
+
+		moved := stats.BytesReceived
+		if wantSent {
+			moved = stats.BytesSent
+		}
+		if !stats.Success || stats.FilesTransferred != 1 || moved != 4096 {
+			t.Errorf("%s: unexpected stats %+v", path, stats)
+		}
+		if len(stats.Files) != 1 || stats.Files[0].Path != "payload.bin" || stats.Files[0].Checksum == "" {
+			t.Errorf("%s: unexpected per-file results %+v", path, stats.Files)
+		}
+	}
+}
+
+func TestFastcp_StructuredReport(t *testing.T) {
+	root, cleanup := tempDir(t)
+	defer cleanup()
+
+	source := filepath.Join(root, "payload.bin")
+	if err := ioutil.WriteFile(source, bytes.Repeat([]byte("x"), 2048), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The global output options are resolved by the registry
+	registry := commands.NewRegistry(monitoring.NewLogger(config.MonitoringConfig{}))
+	registry.Register(networking.NewFastcpSendCommand())
+	registry.Register(networking.NewFastcpRecvCommand())
+
+	portNumber := freePort(t)
+	port := fmt.Sprintf("%d", portNumber)
+	recvDone := make(chan *commands.Result, 1)
+	go func() {
+		result, _ := registry.Execute(context.Background(), "fastcp-recv", commands.ParseArguments([]string{
+			filepath.Join(root, "out"), "-p", port, "--auto-accept", "--output-format", "csv",
+		}))
+		recvDone <- result
+	}()
+	waitForPort(t, portNumber)
+
+	result, err := registry.Execute(context.Background(), "fastcp-send", commands.ParseArguments([]string{
+		source, "127.0.0.1", "-p", port, "--quiet",
+	}))
+	if err != nil || result.ExitCode != 0 {
+		t.Fatalf("send failed: err=%v output=%s", err, result.Output)
+	}
+	if !strings.HasPrefix(result.Output, "1 files, 2.0 KB in ") || strings.Count(result.Output, "\n") != 1 {
+		t.Errorf("quiet output should be one summary line, got %q", result.Output)
+	}
+
+	recv := <-recvDone
+	if recv.ExitCode != 0 || !strings.HasPrefix(recv.Output, "path,bytes,checksum,status,error\npayload.bin,2048,") {
+		t.Errorf("unexpected CSV report:\n%s", recv.Output)
+	}
+
+	// A failure still produces a JSON report naming the cause
+	result, _ = registry.Execute(context.Background(), "fastcp-send", commands.ParseArguments([]string{
+		filepath.Join(root, "missing"), "127.0.0.1", "-p", port, "--output-format", "json",
+	}))
+	var stats networking.TransferStats
+	if err := json.Unmarshal([]byte(result.Output), &stats); err != nil || stats.Success || stats.Error == "" {
+		t.Errorf("failed send should report JSON with an error, got %v:\n%s", err, result.Output)
+	}
+}
+
+func TestFastcp_DeltaSkipsUnchangedFiles(t *testing.T) {
+	root, cleanup := tempDir(t)
+	defer cleanup()
+
+	source := filepath.Join(root, "project")
+	if err := os.MkdirAll(source, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range map[string]string{"same.txt": "unchanged", "edited.txt": "before"} {
+		if err := ioutil.WriteFile(filepath.Join(source, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	destination := filepath.Join(root, "out")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	port := freePort(t)
+	go networking.NewFastcpRecvCommand().Execute(ctx, commands.ParseArguments([]string{
+		destination, "-p", fmt.Sprintf("%d", port), "--serve",
+	}))
+	waitForPort(t, port)
+
+	send := func() *networking.TransferStats {
+		statsPath := filepath.Join(root, "send.json")
+		result, err := networking.NewFastcpSendCommand().Execute(context.Background(), commands.ParseArguments([]string{
+			source, "127.0.0.1", "-p", fmt.Sprintf("%d", port), "--delta", "--stats", statsPath,
+		}))
+		if err != nil || result.ExitCode != 0 {
+			t.Fatalf("send failed: err=%v output=%s", err, result.Output)
+		}
+		data, err := ioutil.ReadFile(statsPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var stats networking.TransferStats
+		if err := json.Unmarshal(data, &stats); err != nil {
+			t.Fatal(err)
+		}
+		return &stats
+	}
+
+	if stats := send(); stats.FilesTransferred != 2 || stats.FilesUnchanged != 0 {
+		t.Fatalf("first delta send should transfer everything, got %+v", stats)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(source, "edited.txt"), []byte("after"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stats := send()
+	if stats.FilesTransferred != 1 || stats.FilesUnchanged != 1 || stats.BytesSent != int64(len("after")) {
+		t.Errorf("second delta send should only transfer the edited file, got %+v", stats)
+	}
+	for _, file := range stats.Files {
+		if want := map[string]string{"project/same.txt": "unchanged", "project/edited.txt": "ok"}[file.Path]; file.Status != want {
+			t.Errorf("%s: status %q, want %q", file.Path, file.Status, want)
+		}
+	}
+
+	got, err := ioutil.ReadFile(filepath.Join(destination, "project", "edited.txt"))
+	if err != nil || string(got) != "after" {
+		t.Errorf("edited file not updated: %q, %v", got, err)
+	}
+}
+
+func TestFastcp_ArchiveKeepsModesAndTimes(t *testing.T) {
+	root, cleanup := tempDir(t)
+	defer cleanup()
+
+	source := filepath.Join(root, "project")
+	modTime := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	for i := 0; i < 200; i++ {
+		path := filepath.Join(source, fmt.Sprintf("dir%d", i%5), fmt.Sprintf("file%03d.txt", i))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(fmt.Sprintf("content %d", i)), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	script := filepath.Join(source, "run.sh")
+	if err := ioutil.WriteFile(script, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	os.Chmod(script, 0750)
+	if err := os.Chtimes(script, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	sent := 201
+	if runtime.GOOS != "windows" {
+		if err := os.Symlink("run.sh", filepath.Join(source, "alias")); err != nil {
+			t.Fatal(err)
+		}
+		sent++
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for _, extra := range [][]string{nil, {"--compress"}} {
+		destination := filepath.Join(root, fmt.Sprintf("out%d", len(extra)))
+		port := freePort(t)
+		go networking.NewFastcpRecvCommand().Execute(ctx, commands.ParseArguments([]string{
+			destination, "-p", fmt.Sprintf("%d", port), "--serve",
+		}))
+		waitForPort(t, port)
+
+		statsPath := filepath.Join(root, "send.json")
+		args := append([]string{source, "127.0.0.1", "-p", fmt.Sprintf("%d", port), "--archive", "--stats", statsPath}, extra...)
+		result, err := networking.NewFastcpSendCommand().Execute(context.Background(), commands.ParseArguments(args))
+		if err != nil || result.ExitCode != 0 {
+			t.Fatalf("archive send %v failed: err=%v output=%s", extra, err, result.Output)
+		}
+		data, err := ioutil.ReadFile(statsPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var stats networking.TransferStats
+		if err := json.Unmarshal(data, &stats); err != nil {
+			t.Fatal(err)
+		}
+		if stats.FilesTransferred != sent {
+			t.Errorf("archive send %v should transfer every file, got %+v", extra, stats.FilesTransferred)
+		}
+
+		got, err := ioutil.ReadFile(filepath.Join(destination, "project", "dir3", "file123.txt"))
+		if err != nil || string(got) != "content 123" {
+			t.Errorf("archive send %v: file not extracted: %q, %v", extra, got, err)
+		}
+		info, err := os.Stat(filepath.Join(destination, "project", "run.sh"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !info.ModTime().Equal(modTime) {
+			t.Errorf("archive send %v: modification time %v, want %v", extra, info.ModTime(), modTime)
+		}
+		if runtime.GOOS != "windows" && info.Mode().Perm() != 0750 {
+			t.Errorf("archive send %v: mode %v, want 0750", extra, info.Mode().Perm())
+		}
+		if runtime.GOOS != "windows" {
+			if target, err := os.Readlink(filepath.Join(destination, "project", "alias")); err != nil || target != "run.sh" {
+				t.Errorf("archive send %v: link not recreated: %q, %v", extra, target, err)
+			}
+		}
+	}
+
+	result, err := networking.NewFastcpSendCommand().Execute(context.Background(), commands.ParseArguments([]string{
+		source, "127.0.0.1", "--archive", "--delta",
+	}))
+	if err != nil || result.ExitCode != 1 || result.Error == nil || !strings.Contains(result.Error.Error(), "--archive") {
+		t.Errorf("--archive with --delta should be rejected, got %+v", result)
+	}
+}
+
+func TestFastcp_DedupReusesBlocksAcrossFilesAndTransfers(t *testing.T) {
+	root, cleanup := tempDir(t)
+	defer cleanup()
+	// The receiver keeps its blocks under the home directory
+	oldHome, oldProfile := os.Getenv("HOME"), os.Getenv("USERPROFILE")
+	os.Setenv("HOME", root)
+	os.Setenv("USERPROFILE", root)
+	defer func() {
+		os.Setenv("HOME", oldHome)
+		os.Setenv("USERPROFILE", oldProfile)
+	}()
+
+	const blockSize = 1024 * 1024
+	first := bytes.Repeat([]byte("first block "), blockSize/12+1)[:blockSize]
+	second := bytes.Repeat([]byte("second block "), blockSize/13+1)[:blockSize]
+	join := func(parts ...[]byte) []byte { return bytes.Join(parts, nil) }
+
+	send := func(source, destination string) networking.TransferStats {
+		t.Helper()
+		port := freePort(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go networking.NewFastcpRecvCommand().Execute(ctx, commands.ParseArguments([]string{
+			destination, "-p", fmt.Sprintf("%d", port), "--serve",
+		}))
+		waitForPort(t, port)
+
+		statsPath := filepath.Join(root, "send.json")
+		result, err := networking.NewFastcpSendCommand().Execute(context.Background(), commands.ParseArguments([]string{
+			source, "127.0.0.1", "-p", fmt.Sprintf("%d", port), "--dedup", "--stats", statsPath,
+		}))
+		if err != nil || result.ExitCode != 0 {
+			t.Fatalf("dedup send of %s failed: err=%v output=%s", source, err, result.Output)
+		}
+		data, err := ioutil.ReadFile(statsPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var stats networking.TransferStats
+		if err := json.Unmarshal(data, &stats); err != nil {
+			t.Fatal(err)
+		}
+		return stats
+	}
+
+	// The second file only repeats blocks of the first
+	source := filepath.Join(root, "project")
+	contents := map[string][]byte{
+		"a.bin": join(first, second, []byte("tail")),
+		"b.bin": join(second, first),
+	}
+	for name, data := range contents {
+		if err := os.MkdirAll(source, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(source, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stats := send(source, filepath.Join(root, "out1"))
+	if stats.FilesTransferred != 2 || stats.BlocksDeduplicated != 2 || stats.BytesDeduplicated != 2*blockSize {
+		t.Errorf("repeated blocks should be sent once, got %d files, %d blocks, %d bytes deduplicated",
+			stats.FilesTransferred, stats.BlocksDeduplicated, stats.BytesDeduplicated)
+	}
+	for name, data := range contents {
+		got, err := ioutil.ReadFile(filepath.Join(root, "out1", "project", name))
+		if err != nil || !bytes.Equal(got, data) {
+			t.Errorf("%s not reassembled: %d bytes, %v", name, len(got), err)
+		}
+	}
+
+	// A new file sent anywhere reuses the blocks the receiver kept
+	other := filepath.Join(root, "other.bin")
+	data := join(first, []byte("new"))
+	if err := ioutil.WriteFile(other, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	stats = send(other, filepath.Join(root, "out2"))
+	if stats.BlocksDeduplicated != 1 || stats.BytesDeduplicated != blockSize {
+		t.Errorf("a held block should not be sent again, got %d blocks, %d bytes deduplicated", stats.BlocksDeduplicated, stats.BytesDeduplicated)
+	}
+	if got, err := ioutil.ReadFile(filepath.Join(root, "out2", "other.bin")); err != nil || !bytes.Equal(got, data) {
+		t.Errorf("other.bin not reassembled: %d bytes, %v", len(got), err)
+	}
+
+	result, err := networking.NewFastcpSendCommand().Execute(context.Background(), commands.ParseArguments([]string{
+		source, "127.0.0.1", "--archive", "--dedup",
+	}))
+	if err != nil || result.ExitCode != 1 || result.Error == nil || !strings.Contains(result.Error.Error(), "--dedup") {
+		t.Errorf("--archive with --dedup should be rejected, got %+v", result)
+	}
+}
+
+func TestFastcp_FlattenRenamesCollisions(t *testing.T) {
+	root, cleanup := tempDir(t)
+	defer cleanup()
+
+	source := filepath.Join(root, "project")
+	files := map[string]string{"a/notes.txt": "from a", "b/notes.txt": "from b", "notes-1.txt": "top level"}
+	for name, data := range files {
+		path := filepath.Join(source, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	destination := filepath.Join(root, "out")
+	statsPath := filepath.Join(root, "recv.json")
+	port := freePort(t)
+	recvDone := make(chan *commands.Result, 1)
+	go func() {
+		result, _ := networking.NewFastcpRecvCommand().Execute(context.Background(), commands.ParseArguments([]string{
+			destination, "-p", fmt.Sprintf("%d", port), "--auto-accept", "--dst-structure", "flatten", "--stats", statsPath,
+		}))
+		recvDone <- result
+	}()
+	waitForPort(t, port)
+
+	result, err := networking.NewFastcpSendCommand().Execute(context.Background(), commands.ParseArguments([]string{
+		source, "127.0.0.1", "-p", fmt.Sprintf("%d", port),
+	}))
+	if err != nil || result.ExitCode != 0 {
+		t.Fatalf("send failed: err=%v output=%s", err, result.Output)
+	}
+	recv := <-recvDone
+	if recv.ExitCode != 0 {
+		t.Fatalf("receive failed:\n%s", recv.Output)
+	}
+
+	// notes-1.txt arrives with the transfer, so the second notes.txt skips to -2
+	for name, want := range map[string]string{"notes.txt": "from a", "notes-2.txt": "from b", "notes-1.txt": "top level"} {
+		got, err := ioutil.ReadFile(filepath.Join(destination, name))
+		if err != nil || string(got) != want {
+			t.Errorf("%s: got %q, %v, want %q", name, got, err, want)
+		}
+	}
+	if entries, _ := ioutil.ReadDir(destination); len(entries) != 3 {
+		t.Errorf("destination should hold only the three files, got %d entries", len(entries))
+	}
+	if !strings.Contains(recv.Output, "project/b/notes.txt → notes-2.txt") {
+		t.Errorf("summary should report the renamed collision:\n%s", recv.Output)
+	}
+
+	data, err := ioutil.ReadFile(statsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stats networking.TransferStats
+	if err := json.Unmarshal(data, &stats); err != nil {
+		t.Fatal(err)
+	}
+	if stats.FilesRenamed != 1 {
+		t.Errorf("expected 1 renamed file, got %+v", stats)
+	}
+	for _, file := range stats.Files {
+		want := map[string]string{"project/b/notes.txt": "notes-2.txt"}[file.Path]
+		if file.SavedAs != want {
+			t.Errorf("%s: saved as %q, want %q", file.Path, file.SavedAs, want)
+		}
+	}
+}
+
+func TestFastcpRecv_DstStructureValidated(t *testing.T) {
+	result, _ := networking.NewFastcpRecvCommand().Execute(context.Background(), commands.ParseArguments([]string{
+		"--dst-structure", "tree",
+	}))
+	if result.ExitCode != 1 || result.Error == nil || !strings.Contains(result.Error.Error(), "preserve or flatten") {
+		t.Errorf("expected a usage error, got %+v", result)
+	}
+}
+
+func TestFastcp_TwoWayConflicts(t *testing.T) {
+	root, cleanup := tempDir(t)
+	defer cleanup()
+
+	// Keep the sync state out of the real home directory
+	home := os.Getenv("HOME")
+	os.Setenv("HOME", filepath.Join(root, "home"))
+	defer os.Setenv("HOME", home)
+
+	source := filepath.Join(root, "a", "project")
+	destination := filepath.Join(root, "b")
+	received := filepath.Join(destination, "project")
+	if err := os.MkdirAll(source, 0755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(path, data string) {
+		if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	read := func(path string) string {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	write(filepath.Join(source, "a.txt"), "a1")
+	write(filepath.Join(source, "b.txt"), "b1")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	port := freePort(t)
+	go networking.NewFastcpRecvCommand().Execute(ctx, commands.ParseArguments([]string{
+		destination, "-p", fmt.Sprintf("%d", port), "--serve",
+	}))
+	waitForPort(t, port)
+
+	send := func(strategy string) map[string]string {
+		statsPath := filepath.Join(root, "send.json")
+		result, err := networking.NewFastcpSendCommand().Execute(context.Background(), commands.ParseArguments([]string{
+			source, "127.0.0.1", "-p", fmt.Sprintf("%d", port), "--conflict", strategy, "--stats", statsPath,
+		}))
+		if err != nil || result.ExitCode != 0 {
+			t.Fatalf("send --conflict %s failed: err=%v output=%s", strategy, err, result.Output)
+		}
+		data, err := ioutil.ReadFile(statsPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var stats networking.TransferStats
+		if err := json.Unmarshal(data, &stats); err != nil {
+			t.Fatal(err)
+		}
+		statuses := make(map[string]string)
+		for _, file := range stats.Files {
+			statuses[strings.TrimPrefix(file.Path, "project/")] = file.Status
+		}
+		return statuses
+	}
+	expect := func(got map[string]string, want map[string]string) {
+		t.Helper()
+		for name, status := range want {
+			if got[name] != status {
+				t.Errorf("%s: status %q, want %q (all: %v)", name, got[name], status, got)
+			}
+		}
+	}
+
+	expect(send("skip"), map[string]string{"a.txt": "ok", "b.txt": "ok"})
+
+	// a.txt changes only on the receiver, b.txt on both ends
+	write(filepath.Join(received, "a.txt"), "a2 receiver")
+	write(filepath.Join(received, "b.txt"), "b2 receiver")
+	write(filepath.Join(source, "b.txt"), "b2 sender")
+
+	expect(send("skip"), map[string]string{"a.txt": "kept", "b.txt": "conflict"})
+	if read(filepath.Join(received, "a.txt")) != "a2 receiver" || read(filepath.Join(received, "b.txt")) != "b2 receiver" {
+		t.Fatal("skip must not overwrite files changed on the receiver")
+	}
+
+	expect(send("rename"), map[string]string{"b.txt": "renamed"})
+	copies, _ := filepath.Glob(filepath.Join(received, "b.conflict-*.txt"))
+	if len(copies) != 1 || read(copies[0]) != "b2 sender" || read(filepath.Join(received, "b.txt")) != "b2 receiver" {
+		t.Fatalf("rename should keep both versions, got copies %v", copies)
+	}
+
+	// The receiver's copy is older than the sender's
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(filepath.Join(received, "b.txt"), past, past); err != nil {
+		t.Fatal(err)
+	}
+	expect(send("newer"), map[string]string{"a.txt": "kept", "b.txt": "replaced"})
+	if read(filepath.Join(received, "b.txt")) != "b2 sender" {
+		t.Error("newer should replace the older receiver copy")
+	}
+
+	// Once both ends agree nothing is reported any more
+	expect(send("skip"), map[string]string{"b.txt": "unchanged"})
+}
+
+func TestFastcpSend_SyncSendsChanges(t *testing.T) {
+	root, cleanup := tempDir(t)
+	defer cleanup()
+
+	source := filepath.Join(root, "project")
+	if err := os.MkdirAll(source, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(source, "main.go"), []byte("package main"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	destination := filepath.Join(root, "out")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	port := freePort(t)
+	go networking.NewFastcpRecvCommand().Execute(ctx, commands.ParseArguments([]string{
+		destination, "-p", fmt.Sprintf("%d", port), "--serve",
+	}))
+	waitForPort(t, port)
+
+	syncDone := make(chan *commands.Result, 1)
+	go func() {
+		result, _ := networking.NewFastcpSendCommand().Execute(ctx, commands.ParseArguments([]string{
+			source, "127.0.0.1", "-p", fmt.Sprintf("%d", port), "--sync", "--debounce", "50ms",
+		}))
+		syncDone <- result
+	}()
+
+	waitForContent := func(path, want string) {
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			if got, err := ioutil.ReadFile(path); err == nil && string(got) == want {
+				return
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		t.Fatalf("%s never received %q", path, want)
+	}
+	waitForContent(filepath.Join(destination, "project", "main.go"), "package main")
+
+	// Both an edit and a file in a new directory reach the receiver
+	if err := ioutil.WriteFile(filepath.Join(source, "main.go"), []byte("package main // edited"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(source, "pkg"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(source, "pkg", "util.go"), []byte("package pkg"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	waitForContent(filepath.Join(destination, "project", "main.go"), "package main // edited")
+	waitForContent(filepath.Join(destination, "project", "pkg", "util.go"), "package pkg")
+
+	cancel()
+	select {
+	case result := <-syncDone:
+		if result.ExitCode != 0 || !strings.Contains(result.Output, "Synced "+source) {
+			t.Errorf("unexpected sync summary (exit %d):\n%s", result.ExitCode, result.Output)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("sync did not stop after cancellation")
+	}
+}
+
+func TestFastcpSend_SyncNeedsDirectory(t *testing.T) {
+	root, cleanup := tempDir(t)
+	defer cleanup()
+
+	source := filepath.Join(root, "payload.bin")
+	if err := ioutil.WriteFile(source, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result, _ := networking.NewFastcpSendCommand().Execute(context.Background(), commands.ParseArguments([]string{
+		source, "127.0.0.1", "--sync",
+	}))
+	if result.ExitCode == 0 || !strings.Contains(result.Output, "--sync needs a directory") {
+		t.Errorf("--sync on a file should fail, got:\n%s", result.Output)
+	}
+}
+
+func TestFastcpBackup_ResumesFromManifest(t *testing.T) {
+	root, cleanup := tempDir(t)
+	defer cleanup()
+
+	// Keep the manifest out of the real home directory
+	home := os.Getenv("HOME")
+	os.Setenv("HOME", filepath.Join(root, "home"))
+	defer os.Setenv("HOME", home)
+
+	source := filepath.Join(root, "data")
+	if err := os.MkdirAll(source, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		if err := ioutil.WriteFile(filepath.Join(source, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	backup := func() *commands.Result {
+		result, err := networking.NewFastcpBackupCommand().Execute(context.Background(), commands.ParseArguments([]string{
+			source, "bucket", "--retries", "0",
+		}))
+		if err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		return result
+	}
+
+	first := backup()
+	if first.ExitCode != 0 || !strings.Contains(first.Output, "Succeeded:  3") {
+		t.Fatalf("first backup should upload everything:\n%s", first.Output)
+	}
+
+	manifests, _ := filepath.Glob(filepath.Join(root, "home", ".supershell", "backups", "*.json"))
+	if len(manifests) != 1 {
+		t.Fatalf("expected one backup manifest, found %v", manifests)
+	}
+
+	// Pretend the backup stopped before b.txt made it to the bucket
+	var manifest map[string]interface{}
+	data, err := ioutil.ReadFile(manifests[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	manifest["complete"] = false
+	manifest["objects"].(map[string]interface{})["data/b.txt"].(map[string]interface{})["uploaded"] = false
+	data, _ = json.Marshal(manifest)
+	if err := ioutil.WriteFile(manifests[0], data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	second := backup()
+	if second.ExitCode != 0 {
+		t.Fatalf("resumed backup failed:\n%s", second.Output)
+	}
+	for _, want := range []string{"Resuming backup " + manifest["backup_id"].(string), "Succeeded:  1", "Skipped:    2"} {
+		if !strings.Contains(second.Output, want) {
+			t.Errorf("expected %q in resumed backup output:\n%s", want, second.Output)
+		}
+	}
+}
+
+func TestFastcpBackup_ResumesFromProgressJournal(t *testing.T) {
+	root, cleanup := tempDir(t)
+	defer cleanup()
+
+	home := os.Getenv("HOME")
+	os.Setenv("HOME", filepath.Join(root, "home"))
+	defer os.Setenv("HOME", home)
+
+	source := filepath.Join(root, "data")
+	if err := os.MkdirAll(source, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		if err := ioutil.WriteFile(filepath.Join(source, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A backup killed after a.txt, before any manifest was saved, and in the
+	// middle of writing its next line
+	sum := sha256.Sum256([]byte("a.txt"))
+	journal := filepath.Join(root, "home", ".supershell", "fastcp", "bucket-backup_1.progress.json")
+	if err := os.MkdirAll(filepath.Dir(journal), 0700); err != nil {
+		t.Fatal(err)
+	}
+	lines := fmt.Sprintf(`{"backup_id":"backup_1","bucket":"bucket","source":%q,"started_at":"2026-01-02T03:04:05Z"}
+{"path":"data/a.txt","size":5,"checksum":"%s"}
+{"path":"data/b.t`, source, hex.EncodeToString(sum[:]))
+	if err := ioutil.WriteFile(journal, []byte(lines), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := networking.NewFastcpBackupCommand().Execute(context.Background(), commands.ParseArguments([]string{
+		source, "bucket", "--retries", "0",
+	}))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if result.ExitCode != 0 {
+		t.Fatalf("resumed backup failed:\n%s", result.Output)
+	}
+	for _, want := range []string{"Resuming backup backup_1", "Files completed before the interruption (1)", "data/a.txt", "Succeeded:  2", "Skipped:    1"} {
+		if !strings.Contains(result.Output, want) {
+			t.Errorf("expected %q in resumed backup output:\n%s", want, result.Output)
+		}
+	}
+	if _, err := os.Stat(journal); !os.IsNotExist(err) {
+		t.Errorf("the journal should be removed once the backup completes, stat error = %v", err)
+	}
+}
+
+func TestFastcpVerify_DetectsDamagedBackup(t *testing.T) {
+	root, cleanup := tempDir(t)
+	defer cleanup()
+
+	home := os.Getenv("HOME")
+	os.Setenv("HOME", filepath.Join(root, "home"))
+	defer os.Setenv("HOME", home)
+
+	source := filepath.Join(root, "data")
+	if err := os.MkdirAll(source, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		if err := ioutil.WriteFile(filepath.Join(source, name), []byte("contents of "+name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bucketDir := filepath.Join(root, "bucket")
+	bucket := "file://" + bucketDir
+	result, err := networking.NewFastcpBackupCommand().Execute(context.Background(), commands.ParseArguments([]string{source, bucket}))
+	if err != nil || result.ExitCode != 0 {
+		t.Fatalf("backup failed: err=%v output=%s", err, result.Output)
+	}
+
+	backups, _ := ioutil.ReadDir(bucketDir)
+	if len(backups) != 1 {
+		t.Fatalf("expected one backup in the bucket, found %d", len(backups))
+	}
+	backupID := backups[0].Name()
+	objects := filepath.Join(bucketDir, backupID, "data")
+	if data, err := ioutil.ReadFile(filepath.Join(objects, "b.txt")); err != nil || string(data) != "contents of b.txt" {
+		t.Fatalf("object not uploaded to the bucket: %q, %v", data, err)
+	}
+
+	verify := func(extra ...string) *commands.Result {
+		result, err := networking.NewFastcpVerifyCommand().Execute(context.Background(),
+			commands.ParseArguments(append([]string{bucket, backupID}, extra...)))
+		if err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		return result
+	}
+
+	if result := verify("--deep"); result.ExitCode != 0 || !strings.Contains(result.Output, "VERIFICATION PASSED") {
+		t.Fatalf("an intact backup should verify:\n%s", result.Output)
+	}
+
+	// Same size, different content: only a deep check can tell
+	if err := ioutil.WriteFile(filepath.Join(objects, "a.txt"), []byte("CONTENTS OF a.txt"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if result := verify(); result.ExitCode != 0 {
+		t.Errorf("a quick check only compares sizes:\n%s", result.Output)
+	}
+
+	os.Remove(filepath.Join(objects, "c.txt"))
+	ioutil.WriteFile(filepath.Join(objects, "stray.bin"), []byte("?"), 0644)
+
+	result = verify("--deep")
+	if result.ExitCode == 0 {
+		t.Fatalf("a damaged backup should fail verification:\n%s", result.Output)
+	}
+	for _, want := range []string{"Corrupted:  " + backupID + "/data/a.txt", "Missing:    " + backupID + "/data/c.txt", "Extra:      " + backupID + "/data/stray.bin", "Verified:     1"} {
+		if !strings.Contains(result.Output, want) {
+			t.Errorf("expected %q in verify output:\n%s", want, result.Output)
+		}
+	}
+}
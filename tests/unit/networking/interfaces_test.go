@@ -0,0 +1,134 @@
+package networking_test
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/commands/networking"
+)
+
+// someInterface returns an interface of this machine, preferring one with an
+// address
+func someInterface(t *testing.T) networking.NetInterface {
+	interfaces, err := networking.ListInterfaces()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(interfaces) == 0 {
+		t.Skip("no network interfaces here")
+	}
+	for _, iface := range interfaces {
+		if len(iface.Addresses) > 0 {
+			return iface
+		}
+	}
+	return interfaces[0]
+}
+
+func TestListInterfaces(t *testing.T) {
+	interfaces, err := networking.ListInterfaces()
+	if err != nil {
+		t.Fatal(err)
+	}
+	system, err := net.Interfaces()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(interfaces) != len(system) {
+		t.Fatalf("listed %d interfaces, the system has %d", len(interfaces), len(system))
+	}
+	for i, iface := range interfaces {
+		if i > 0 && interfaces[i-1].Index >= iface.Index {
+			t.Errorf("interfaces should be ordered by index: %d before %d", interfaces[i-1].Index, iface.Index)
+		}
+		if iface.Loopback && iface.Description != "Loopback" {
+			t.Errorf("%s is loopback but described as %q", iface.Name, iface.Description)
+		}
+	}
+}
+
+func TestResolveInterface(t *testing.T) {
+	want := someInterface(t)
+
+	tokens := []string{want.Name, strings.ToUpper(want.Name), strconv.Itoa(want.Index)}
+	if len(want.Addresses) > 0 {
+		ip, _, err := net.ParseCIDR(want.Addresses[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		tokens = append(tokens, ip.String())
+	}
+	for _, token := range tokens {
+		got, err := networking.ResolveInterface(token)
+		if err != nil {
+			t.Errorf("%q: %v", token, err)
+			continue
+		}
+		if got.Name != want.Name {
+			t.Errorf("%q resolved to %s, want %s", token, got.Name, want.Name)
+		}
+	}
+
+	for _, token := range []string{"no-such-interface0", "99999", "203.0.113.254"} {
+		_, err := networking.ResolveInterface(token)
+		if err == nil {
+			t.Errorf("%q should not resolve", token)
+			continue
+		}
+		if !strings.Contains(err.Error(), "available: ") || !strings.Contains(err.Error(), want.Name) {
+			t.Errorf("%q: the error should list the interfaces there are: %v", token, err)
+		}
+	}
+}
+
+func TestInterfacesCommand(t *testing.T) {
+	want := someInterface(t)
+	cmd := networking.NewInterfacesCommand()
+
+	result, err := cmd.Execute(context.Background(), commands.ParseArguments([]string{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ExitCode != 0 || !strings.Contains(result.Output, "NETWORK INTERFACES") || !strings.Contains(result.Output, want.Name) {
+		t.Errorf("the table should list %s:\n%s", want.Name, result.Output)
+	}
+
+	result, err = cmd.Execute(context.Background(), commands.ParseArguments([]string{strconv.Itoa(want.Index), "--json"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var listed []networking.NetInterface
+	if err := json.Unmarshal([]byte(result.Output), &listed); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, result.Output)
+	}
+	if len(listed) != 1 || listed[0].Name != want.Name || listed[0].MTU != want.MTU {
+		t.Errorf("interfaces %d should show only %s: %+v", want.Index, want.Name, listed)
+	}
+
+	result, err = cmd.Execute(context.Background(), commands.ParseArguments([]string{"no-such-interface0"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ExitCode == 0 || result.Error == nil {
+		t.Error("an unknown interface should fail")
+	}
+}
+
+func TestSniff_ResolvesInterface(t *testing.T) {
+	want := someInterface(t)
+
+	result := runSniff(t, "-i", strconv.Itoa(want.Index), "-c", "1")
+	if result.ExitCode != 0 || !strings.Contains(result.Output, "Interface:   "+want.Name) {
+		t.Errorf("sniff -i %d should capture on %s:\n%s", want.Index, want.Name, result.Output)
+	}
+
+	result = runSniff(t, "no-such-interface0", "-c", "1")
+	if result.ExitCode == 0 || result.Error == nil || !strings.Contains(result.Error.Error(), "available: ") {
+		t.Errorf("an unknown interface should fail before capturing: %v\n%s", result.Error, result.Output)
+	}
+}
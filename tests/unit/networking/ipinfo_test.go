@@ -0,0 +1,116 @@
+package networking_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/commands/networking"
+	"suppercommand/internal/config"
+)
+
+// runIpinfo executes ipinfo with --json and decodes the single result
+func runIpinfo(t *testing.T, cfg config.IPInfoConfig, args ...string) networking.IPInfo {
+	result, err := networking.NewIpinfoCommand(cfg).Execute(context.Background(), commands.ParseArguments(append(args, "--json")))
+	if err != nil {
+		t.Fatalf("ipinfo failed: %v", err)
+	}
+
+	var info networking.IPInfo
+	if err := json.Unmarshal([]byte(result.Output), &info); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, result.Output)
+	}
+	return info
+}
+
+func TestIpinfo_APILookupIsCached(t *testing.T) {
+	root, cleanup := tempDir(t)
+	defer cleanup()
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"ip":"203.0.113.7","hostname":"host.example","city":"Sydney","region":"NSW","country":"AU","org":"AS64500 Example Networks"}`)
+	}))
+	defer server.Close()
+
+	cfg := config.IPInfoConfig{
+		Endpoint:  server.URL + "/{ip}/json",
+		APIKey:    "secret",
+		CacheFile: filepath.Join(root, "ipinfo.json"),
+		CacheTTL:  time.Hour,
+	}
+
+	info := runIpinfo(t, cfg, "203.0.113.7")
+	if info.Source != "api" || info.Country != "AU" || info.City != "Sydney" {
+		t.Errorf("unexpected lookup result %+v", info)
+	}
+	if info.ASN != "AS64500" || info.Organization != "Example Networks" {
+		t.Errorf("ASN not split from org: %+v", info)
+	}
+
+	cached := runIpinfo(t, cfg, "203.0.113.7")
+	if !cached.Cached || cached.City != "Sydney" {
+		t.Errorf("second lookup not served from cache: %+v", cached)
+	}
+	if requests != 1 {
+		t.Errorf("expected 1 API request, got %d", requests)
+	}
+}
+
+func TestIpinfo_DatabaseLookup(t *testing.T) {
+	root, cleanup := tempDir(t)
+	defer cleanup()
+
+	database := filepath.Join(root, "networks.csv")
+	rows := "network,country,city,asn,organization\n" +
+		"198.51.100.0/24,US,Chicago,64501,Wide Net\n" +
+		"198.51.100.128/25,US,Denver,64502,\"Narrow Net, Inc\"\n"
+	if err := ioutil.WriteFile(database, []byte(rows), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	info := runIpinfo(t, config.IPInfoConfig{Database: database}, "198.51.100.200")
+	if info.Source != "database" || info.City != "Denver" || info.ASN != "AS64502" {
+		t.Errorf("most specific network not chosen: %+v", info)
+	}
+	if info.Organization != "Narrow Net, Inc" {
+		t.Errorf("organization with comma not preserved: %q", info.Organization)
+	}
+}
+
+func TestIpinfo_OfflineFallsBack(t *testing.T) {
+	port := freePort(t)
+	cfg := config.IPInfoConfig{
+		Endpoint: fmt.Sprintf("http://127.0.0.1:%d/{ip}", port),
+		Timeout:  time.Second,
+	}
+
+	result, err := networking.NewIpinfoCommand(cfg).Execute(context.Background(), commands.ParseArguments([]string{"203.0.113.9"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ExitCode != 0 {
+		t.Errorf("offline lookup should not fail:\n%s", result.Output)
+	}
+	if !strings.Contains(result.Output, "reverse-dns") || !strings.Contains(result.Output, "unavailable") {
+		t.Errorf("expected reverse DNS fallback with a warning:\n%s", result.Output)
+	}
+
+	private := runIpinfo(t, cfg, "10.1.2.3")
+	if private.Scope != "private" || len(private.Warnings) != 0 {
+		t.Errorf("private address should skip the API: %+v", private)
+	}
+}
@@ -0,0 +1,64 @@
+package networking_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/commands/networking"
+)
+
+func TestResolveTarget_Literals(t *testing.T) {
+	tests := []struct {
+		host             string
+		forceV4, forceV6 bool
+		want             string
+		err              string
+	}{
+		{host: "192.0.2.10", want: "192.0.2.10"},
+		{host: "192.0.2.10", forceV4: true, want: "192.0.2.10"},
+		{host: "2001:db8::10", want: "2001:db8::10"},
+		{host: "2001:db8::10", forceV6: true, want: "2001:db8::10"},
+		{host: "192.0.2.10", forceV6: true, err: "192.0.2.10 has no IPv6 address"},
+		{host: "2001:db8::10", forceV4: true, err: "2001:db8::10 has no IPv4 address"},
+	}
+	for _, tt := range tests {
+		ip, err := networking.ResolveTarget(context.Background(), tt.host, tt.forceV4, tt.forceV6)
+		switch {
+		case tt.err != "":
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("ResolveTarget(%s, -4=%v, -6=%v) = %v, %v; want error %q", tt.host, tt.forceV4, tt.forceV6, ip, err, tt.err)
+			}
+		case err != nil || ip.String() != tt.want:
+			t.Errorf("ResolveTarget(%s, -4=%v, -6=%v) = %v, %v; want %s", tt.host, tt.forceV4, tt.forceV6, ip, err, tt.want)
+		}
+	}
+}
+
+func TestMtu_Errors(t *testing.T) {
+	cmd := networking.NewMtuCommand()
+
+	result, err := cmd.Execute(context.Background(), commands.ParseArguments([]string{"-i", "no-such-interface0"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ExitCode != 1 || !strings.Contains(result.Output, `no-such-interface0`) || !strings.Contains(result.Output, "available: ") {
+		t.Errorf("an unknown interface should fail listing the ones there are, got %d %q", result.ExitCode, result.Output)
+	}
+
+	result, _ = cmd.Execute(context.Background(), commands.ParseArguments([]string{"192.0.2.10", "-6"}))
+	if result.ExitCode != 1 || !strings.Contains(result.Output, "has no IPv6 address") {
+		t.Errorf("an IPv4 target with -6 should fail, got %d %q", result.ExitCode, result.Output)
+	}
+
+	for _, args := range [][]string{{"192.0.2.10", "--max"}, {"192.0.2.10", "--max", "big"}, {"192.0.2.10", "--max", "0"}, {"192.0.2.10", "--max", "-1500"}} {
+		result, err := cmd.Execute(context.Background(), commands.ParseArguments(args))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.ExitCode != 1 || result.Error == nil || !strings.HasPrefix(result.Output, "Usage: mtu") {
+			t.Errorf("%q: expected a usage error, got %d %q %v", args, result.ExitCode, result.Output, result.Error)
+		}
+	}
+}
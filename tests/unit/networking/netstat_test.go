@@ -0,0 +1,483 @@
+package networking_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/commands/networking"
+)
+
+// quiet is the context of a command run with --quiet
+var quiet = commands.WithOutputOptions(context.Background(), commands.OutputOptions{Quiet: true, Format: commands.FormatText})
+
+func TestNetstat_QuietRunsNetstat(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("netstat -o is used for processes on Windows")
+	}
+	const output = "Proto Recv-Q Send-Q Local Address Foreign Address State\ntcp 0 0 0.0.0.0:22 0.0.0.0:* LISTEN\n"
+	mock := commands.NewMockRunner().On("netstat -a -n -p", output)
+	cmd := networking.NewNetstatCommand()
+	cmd.SetRunner(mock)
+
+	result, err := cmd.Execute(quiet, commands.ParseArguments([]string{"-a", "-n", "-p"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ExitCode != 0 || result.Output != output {
+		t.Errorf("--quiet should print netstat's output as is, got %d %q", result.ExitCode, result.Output)
+	}
+	if calls := mock.Calls(); len(calls) != 1 {
+		t.Errorf("expected netstat to run once, ran %v", calls)
+	}
+}
+
+func TestNetstat_QuietReportsFailure(t *testing.T) {
+	mock := commands.NewMockRunner().Fail("netstat -an", errors.New("exit status 1"))
+	cmd := networking.NewNetstatCommand()
+	cmd.SetRunner(mock)
+
+	result, err := cmd.Execute(quiet, commands.ParseArguments(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ExitCode == 0 || result.Error == nil || result.Error.Error() != "netstat: exit status 1" {
+		t.Errorf("a failing netstat should fail the command, got %d %v", result.ExitCode, result.Error)
+	}
+}
+
+func TestNetstat_DiagnosticsStayOffStdout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("netstat -o is used for processes on Windows")
+	}
+	mock := commands.NewMockRunner().On("netstat -a -n -p", "tcp 0 0 0.0.0.0:22 0.0.0.0:* LISTEN\n")
+	cmd := networking.NewNetstatCommand()
+	cmd.SetRunner(mock)
+
+	var live bytes.Buffer
+	result, err := cmd.Execute(commands.WithOutputWriter(context.Background(), &live), commands.ParseArguments([]string{"-a", "-n", "-p"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ExitCode != 0 || !strings.Contains(result.Output, "0.0.0.0:22") {
+		t.Fatalf("expected the listing, got %d %q", result.ExitCode, result.Output)
+	}
+	if strings.Contains(result.Output, "Completed in") || !strings.Contains(result.Stderr, "Completed in") {
+		t.Errorf("the timing note belongs on stderr, got output %q and stderr %q", result.Output, result.Stderr)
+	}
+	if strings.Contains(result.Output, "Gathering") || !strings.Contains(live.String(), "Gathering network information") {
+		t.Errorf("the spinner should go to the progress writer, got %q", live.String())
+	}
+
+	mock = commands.NewMockRunner().Fail("netstat -a -n -p", errors.New("exit status 1"))
+	cmd.SetRunner(mock)
+	result, _ = cmd.Execute(commands.WithOutputWriter(context.Background(), &live), commands.ParseArguments([]string{"-a", "-n", "-p"}))
+	if result.ExitCode == 0 || result.Output != "" || result.Error == nil || !strings.Contains(result.Error.Error(), "failed to execute netstat") {
+		t.Errorf("a failure should only be reported through the error, got %q %v", result.Output, result.Error)
+	}
+}
+
+func TestNetstat_WatchNewReportsChanges(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("connections are read from ss on Linux")
+	}
+	const header = "State Recv-Q Send-Q Local Address:Port Peer Address:Port\n"
+	const ssh = "ESTAB 0 0 10.0.0.5:22 10.0.0.9:50522\n"
+	const web = "ESTAB 0 0 10.0.0.5:51234 93.184.216.34:443\n"
+	const dns = "ESTAB 0 0 10.0.0.5:40000 198.51.100.53:53\n"
+	watch := func(samples ...string) *networking.NetstatCommand {
+		cmd := networking.NewNetstatCommand()
+		cmd.SetRunner(&sequenceRunner{
+			MockRunner: commands.NewMockRunner(),
+			outputs:    map[string][]string{"ss -tin": samples},
+			runs:       map[string]int{},
+		})
+		return cmd
+	}
+
+	var out bytes.Buffer
+	ctx := commands.WithErrorWriter(commands.WithOutputWriter(context.Background(), &out), ioutil.Discard)
+	cmd := watch(header+ssh, header+ssh+web+dns, header+web)
+	args := []string{"--watch-new", "--closed", "--remote", "93.184.216.0/24:443", "--interval", "0.01", "--count", "3"}
+	result, err := cmd.Execute(ctx, commands.ParseArguments(args))
+	if err != nil || result.ExitCode != 0 {
+		t.Fatalf("netstat --watch-new failed: %v %+v", err, result)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 1 || !strings.Contains(lines[0], "+ 10.0.0.5:51234") || !strings.Contains(lines[0], "93.184.216.34:443") {
+		t.Errorf("expected only the new HTTPS connection, got %q", out.String())
+	}
+	if !strings.Contains(result.Stderr, "3 samples, baseline 1): 1 new, 0 closed") {
+		t.Errorf("unexpected summary %q", result.Stderr)
+	}
+
+	out.Reset()
+	cmd = watch(header+ssh+web, header+web+dns)
+	args = []string{"--watch-new", "--closed", "--interval", "0.01", "--count", "2"}
+	if result, _ = cmd.Execute(ctx, commands.ParseArguments(args)); result.ExitCode != 0 {
+		t.Fatalf("netstat --watch-new failed: %+v", result)
+	}
+	want := []string{"+ 10.0.0.5:40000", "- 10.0.0.5:22"}
+	lines = strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != len(want) {
+		t.Fatalf("expected %d changes, got %q", len(want), out.String())
+	}
+	for i, change := range want {
+		if !strings.Contains(lines[i], change) {
+			t.Errorf("line %d = %q, want %q", i, lines[i], change)
+		}
+	}
+
+	for _, args := range [][]string{{"--closed"}, {"--watch-new", "--live"}, {"--watch-new", "--remote", ":"}} {
+		if result, _ := cmd.Execute(ctx, commands.ParseArguments(args)); result.ExitCode != 1 || result.Error == nil {
+			t.Errorf("%q: expected a usage error, got %+v", args, result)
+		}
+	}
+}
+
+func TestParseSSConnections(t *testing.T) {
+	const output = "State  Recv-Q Send-Q Local Address:Port  Peer Address:Port Process\n" +
+		"ESTAB  0      0      10.0.0.5:22         10.0.0.9:50522\n" +
+		"\t cubic wscale:7,7 rto:204 rtt:0.5/0.25 bytes_sent:4096 bytes_acked:4097 bytes_received:2048 segs_out:10\n" +
+		"TIME-WAIT 0   0      10.0.0.5:51234      93.184.216.34:443\n" +
+		"\t cubic rto:200 bytes_acked:700 bytes_received:300\n" +
+		"CLOSE-WAIT 1  0      [::1]:8080          [::1]:40000\n"
+
+	want := []networking.ConnSample{
+		{Local: "10.0.0.5:22", Remote: "10.0.0.9:50522", State: "ESTABLISHED", Sent: 4096, Received: 2048, HasBytes: true},
+		{Local: "10.0.0.5:51234", Remote: "93.184.216.34:443", State: "TIME_WAIT", Sent: 700, Received: 300, HasBytes: true},
+		{Local: "[::1]:8080", Remote: "[::1]:40000", State: "CLOSE_WAIT"},
+	}
+	if got := networking.ParseSSConnections(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseSSConnections =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestParseProcConnections(t *testing.T) {
+	const tcp = "  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode\n" +
+		"   0: 0100007F:0CEA 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 12345 1 0000000000000000 100 0 0 10 0\n" +
+		"   1: 0500000A:0016 0900000A:C5BA 01 00000000:00000000 02:000A7B4C 00000000     0        0 23456 4 0000000000000000 20 4 30 10 -1\n" +
+		"   2: 0500000A:C822 22D8B85D:01BB 06 00000000:00000000 03:00000F1A 00000000     0        0 0 3 0000000000000000\n"
+	const tcp6 = "  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode\n" +
+		"   0: 00000000000000000000000001000000:1F90 00000000000000000000000001000000:9C40 01 00000000:00000000 00:00000000 00000000  1000        0 34567 1\n" +
+		"   1: B80D0120000000000000000001000000:01BB B80D0120000000000000000002000000:D431 08 00000000:00000000 00:00000000 00000000  1000        0 45678 1\n" +
+		"   2: 0000000000000000FFFF00000500000A:0016 0000000000000000FFFF00000900000A:C5BB 01 00000000:00000000 00:00000000 00000000     0        0 56789 1\n"
+
+	tests := []struct {
+		name  string
+		table string
+		want  []string
+	}{
+		{"tcp", tcp, []string{
+			"10.0.0.5:22 10.0.0.9:50618 ESTABLISHED",
+			"10.0.0.5:51234 93.184.216.34:443 TIME_WAIT",
+		}},
+		{"tcp6", tcp6, []string{
+			"[::1]:8080 [::1]:40000 ESTABLISHED",
+			"[2001:db8::1]:443 [2001:db8::2]:54321 CLOSE_WAIT",
+			"10.0.0.5:22 10.0.0.9:50619 ESTABLISHED",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, connection := range networking.ParseProcConnections(tt.table) {
+				got = append(got, connection.Local+" "+connection.Remote+" "+connection.State)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseProcConnections = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeProcAddress(t *testing.T) {
+	tests := []struct {
+		field string
+		want  string
+		ok    bool
+	}{
+		{"0100007F:0050", "127.0.0.1:80", true},
+		{"00000000:0000", "0.0.0.0:0", true},
+		{"00000000000000000000000001000000:0016", "[::1]:22", true},
+		{"B80D0120000000000000000001000000:01BB", "[2001:db8::1]:443", true},
+		{"0100007F", "", false},
+		{"0100007F:XYZ", "", false},
+		{"01007F:0050", "", false},
+		{"GG00007F:0050", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := networking.DecodeProcAddress(tt.field)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("DecodeProcAddress(%q) = %q, %v; want %q, %v", tt.field, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseNetstatConnections(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []networking.ConnSample
+	}{
+		{"windows", "Active Connections\n\n  Proto  Local Address          Foreign Address        State\n" +
+			"  TCP    0.0.0.0:135            0.0.0.0:0              LISTENING\n" +
+			"  TCP    10.0.0.5:51234         93.184.216.34:443      ESTABLISHED\n" +
+			"  UDP    0.0.0.0:5353           *:*\n",
+			[]networking.ConnSample{{Local: "10.0.0.5:51234", Remote: "93.184.216.34:443", State: "ESTABLISHED"}}},
+		{"macos", "Active Internet connections (including servers)\n" +
+			"Proto Recv-Q Send-Q  Local Address          Foreign Address        (state)\n" +
+			"tcp4       0      0  10.0.0.5.51234         93.184.216.34.443      ESTABLISHED\n" +
+			"tcp46      0      0  *.22                   *.*                    LISTEN\n" +
+			"udp4       0      0  *.5353                 *.*\n",
+			[]networking.ConnSample{{Local: "10.0.0.5.51234", Remote: "93.184.216.34.443", State: "ESTABLISHED"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := networking.ParseNetstatConnections(tt.output); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseNetstatConnections = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConnectionRates(t *testing.T) {
+	sample := func(local string, sent, received int64) networking.ConnSample {
+		return networking.ConnSample{Local: local, Remote: "10.0.0.9:443", State: "ESTABLISHED", Sent: sent, Received: received, HasBytes: true}
+	}
+	busy, reset, quiet := sample("10.0.0.5:1000", 1000, 500), sample("10.0.0.5:2000", 5000, 5000), sample("10.0.0.5:3000", 100, 100)
+	previous := map[string]networking.ConnSample{busy.Key(): busy, reset.Key(): reset, quiet.Key(): quiet}
+
+	current := []networking.ConnSample{
+		sample("10.0.0.5:4000", 9000, 9000),              // not seen before
+		sample("10.0.0.5:3000", 100, 100),                // idle
+		sample("10.0.0.5:2000", 100, 9000),               // the send counter was reset
+		sample("10.0.0.5:1000", 3000, 1500),              // 2000 up and 1000 down in 2s
+		{Local: "10.0.0.5:5000", Remote: "10.0.0.9:443"}, // no counters
+	}
+	type rate struct {
+		local      string
+		send, recv float64
+		known      bool
+	}
+	want := []rate{
+		{"10.0.0.5:1000", 1000, 500, true},
+		{"10.0.0.5:2000", 0, 0, false},
+		{"10.0.0.5:3000", 0, 0, true},
+		{"10.0.0.5:4000", 0, 0, false},
+		{"10.0.0.5:5000", 0, 0, false},
+	}
+	var got []rate
+	for _, r := range networking.ConnectionRates(previous, current, 2*time.Second) {
+		got = append(got, rate{r.Local, r.SendRate, r.RecvRate, r.Known})
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ConnectionRates =\n%+v\nwant\n%+v", got, want)
+	}
+
+	for _, r := range networking.ConnectionRates(previous, current, 0) {
+		if r.Known {
+			t.Errorf("%s: no rate can be known without elapsed time", r.Local)
+		}
+	}
+}
+
+func TestNetstat_LiveRendersFrames(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("connections are read from ss on Linux")
+	}
+	const header = "State Recv-Q Send-Q Local Address:Port Peer Address:Port\n"
+	const ssh = "ESTAB 0 0 10.0.0.5:22 10.0.0.9:50522\n"
+	const web = "ESTAB 0 0 10.0.0.5:51234 93.184.216.34:443\n"
+	cmd := networking.NewNetstatCommand()
+	cmd.SetRunner(&sequenceRunner{
+		MockRunner: commands.NewMockRunner(),
+		outputs: map[string][]string{"ss -tin": {
+			header + ssh + "\t cubic bytes_sent:1000 bytes_received:1000\n",
+			header + ssh + "\t cubic bytes_sent:900000 bytes_received:1000\n" + web + "\t cubic bytes_sent:10 bytes_received:10\n",
+		}},
+		runs: map[string]int{},
+	})
+
+	var out bytes.Buffer
+	ctx := commands.WithOutputWriter(context.Background(), &out)
+	result, err := cmd.Execute(ctx, commands.ParseArguments([]string{"--live", "--interval", "0.01", "--count", "2"}))
+	if err != nil || result.ExitCode != 0 {
+		t.Fatalf("netstat --live failed: %v %+v", err, result)
+	}
+	if result.Output != "" || !strings.Contains(result.Stderr, "(2 samples)") {
+		t.Errorf("frames belong on the output writer and the summary on stderr, got %q and %q", result.Output, result.Stderr)
+	}
+
+	frames := strings.Split(out.String(), "\033[H\033[2J")
+	if len(frames) != 3 || frames[0] != "" {
+		t.Fatalf("expected two frames, got %q", out.String())
+	}
+	first, second := frames[1], frames[2]
+	for _, want := range []string{"LIVE CONNECTIONS", "sample 1", "10.0.0.9:50522", "—", "📊 1 connections"} {
+		if !strings.Contains(first, want) {
+			t.Errorf("first frame should contain %q:\n%s", want, first)
+		}
+	}
+	for _, want := range []string{"sample 2", "📊 2 connections", "93.184.216.34:443"} {
+		if !strings.Contains(second, want) {
+			t.Errorf("second frame should contain %q:\n%s", want, second)
+		}
+	}
+	lines := strings.Split(second, "\n")
+	var sshRow, webRow string
+	for _, line := range lines {
+		switch {
+		case strings.Contains(line, "10.0.0.9:50522"):
+			sshRow = line
+		case strings.Contains(line, "93.184.216.34:443"):
+			webRow = line
+		}
+	}
+	if !strings.Contains(sshRow, "/s") || !strings.Contains(webRow, "—") {
+		t.Errorf("the busy connection should have a rate and the new one none:\n%s", second)
+	}
+	if strings.Index(second, sshRow) > strings.Index(second, webRow) {
+		t.Errorf("the busiest connection should come first:\n%s", second)
+	}
+	if strings.Contains(out.String(), "Press Enter to stop") {
+		t.Errorf("a counted run has no stop key:\n%s", out.String())
+	}
+
+	out.Reset()
+	cmd.SetRunner(commands.NewMockRunner().On("ss -tin", header+ssh+web))
+	if result, _ = cmd.Execute(ctx, commands.ParseArguments([]string{"--live", "--count", "1", "--filter", "93.184"})); result.ExitCode != 0 {
+		t.Fatalf("netstat --live --filter failed: %+v", result)
+	}
+	if frame := out.String(); strings.Contains(frame, "10.0.0.9:50522") || !strings.Contains(frame, "Filter: ") || !strings.Contains(frame, "(1 of 2)") {
+		t.Errorf("the filter should leave only the web connection:\n%s", frame)
+	}
+}
+
+func TestParseSSProcess(t *testing.T) {
+	tests := []struct {
+		line string
+		name string
+		pid  int
+	}{
+		{`ESTAB 0 0 10.0.0.5:22 10.0.0.9:50522 users:(("sshd",pid=812,fd=3))`, "sshd", 812},
+		{`ESTAB 0 0 10.0.0.5:80 10.0.0.9:50600 users:(("nginx",pid=100,fd=6),("nginx",pid=101,fd=6))`, "nginx", 100},
+		{`ESTAB 0 0 10.0.0.5:22 10.0.0.9:50522`, "", 0},
+		{`ESTAB 0 0 10.0.0.5:22 10.0.0.9:50522 users:(("broken`, "", 0},
+	}
+	for _, tt := range tests {
+		if name, pid := networking.ParseSSProcess(tt.line); name != tt.name || pid != tt.pid {
+			t.Errorf("ParseSSProcess(%q) = %q, %d; want %q, %d", tt.line, name, pid, tt.name, tt.pid)
+		}
+	}
+
+	const output = "State Recv-Q Send-Q Local Address:Port Peer Address:Port Process\n" +
+		`ESTAB 0      0      10.0.0.5:22        10.0.0.9:50522    users:(("sshd",pid=812,fd=3))` + "\n"
+	if got := networking.ParseSSConnections(output); len(got) != 1 || got[0].Process != "sshd" || got[0].PID != 812 {
+		t.Errorf("ss -tinp should carry the process, got %+v", got)
+	}
+}
+
+func TestParseConnections_Owners(t *testing.T) {
+	const tcp = "  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode\n" +
+		"   1: 0500000A:0016 0900000A:C5BA 01 00000000:00000000 02:000A7B4C 00000000     0        0 23456 4 0000000000000000 20 4 30 10 -1\n"
+	if got := networking.ParseProcConnections(tcp); len(got) != 1 || got[0].Inode != "23456" {
+		t.Errorf("the inode column should be kept to find the owner, got %+v", got)
+	}
+
+	const ano = "  Proto  Local Address          Foreign Address        State           PID\n" +
+		"  TCP    0.0.0.0:135            0.0.0.0:0              LISTENING       1044\n" +
+		"  TCP    10.0.0.5:51234         93.184.216.34:443      ESTABLISHED     1234\n"
+	want := []networking.ConnSample{{Local: "10.0.0.5:51234", Remote: "93.184.216.34:443", State: "ESTABLISHED", PID: 1234}}
+	if got := networking.ParseNetstatConnections(ano); !reflect.DeepEqual(got, want) {
+		t.Errorf("netstat -ano = %+v, want %+v", got, want)
+	}
+}
+
+func TestConnSample_Labels(t *testing.T) {
+	processes := []struct {
+		sample networking.ConnSample
+		want   string
+	}{
+		{networking.ConnSample{}, "-"},
+		{networking.ConnSample{PID: 42}, "42"},
+		{networking.ConnSample{PID: 42, Process: "sshd"}, "sshd(42)"},
+	}
+	for _, tt := range processes {
+		if got := tt.sample.ProcessLabel(); got != tt.want {
+			t.Errorf("ProcessLabel(%+v) = %q, want %q", tt.sample, got, tt.want)
+		}
+	}
+
+	remotes := []struct {
+		sample networking.ConnSample
+		want   string
+	}{
+		{networking.ConnSample{Remote: "93.184.216.34:443"}, "93.184.216.34:443"},
+		{networking.ConnSample{Remote: "93.184.216.34:443", RemoteHost: "example.com"}, "example.com:443"},
+		{networking.ConnSample{Remote: "[2001:db8::1]:443", RemoteHost: "example.com"}, "example.com:443"},
+		{networking.ConnSample{Remote: "93.184.216.34.443", RemoteHost: "example.com"}, "example.com"},
+	}
+	for _, tt := range remotes {
+		if got := tt.sample.RemoteLabel(); got != tt.want {
+			t.Errorf("RemoteLabel(%+v) = %q, want %q", tt.sample, got, tt.want)
+		}
+	}
+
+	columns := []struct {
+		value string
+		width int
+		want  string
+	}{
+		{"10.0.0.5:22", 28, "10.0.0.5:22"},
+		{"0123456789", 10, "0123456789"},
+		{"[2001:db8:85a3::8a2e:370:7334]:443", 20, "...a2e:370:7334]:443"},
+	}
+	for _, tt := range columns {
+		got := networking.FitColumn(tt.value, tt.width)
+		if got != tt.want || len(got) > tt.width {
+			t.Errorf("FitColumn(%q, %d) = %q, want %q", tt.value, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestConnResolver_CachesProcessNames(t *testing.T) {
+	resolver := networking.NewConnResolver(false, commands.NewMockRunner())
+	loads := 0
+	resolver.SetProcessTable(func(ctx context.Context) map[int]string {
+		loads++
+		return map[int]string{42: "sshd", 77: "nginx"}
+	})
+	names := func(connections []networking.ConnSample) []string {
+		var got []string
+		for _, connection := range connections {
+			got = append(got, connection.Process)
+		}
+		return got
+	}
+
+	first := []networking.ConnSample{{PID: 42}, {PID: 77}, {PID: 5, Process: "known"}, {}}
+	resolver.Resolve(context.Background(), first)
+	if got, want := names(first), []string{"sshd", "nginx", "known", ""}; !reflect.DeepEqual(got, want) || loads != 1 {
+		t.Fatalf("first sample resolved %q after %d loads, want %q after 1", got, loads, want)
+	}
+
+	cached := []networking.ConnSample{{PID: 77}, {PID: 42}}
+	resolver.Resolve(context.Background(), cached)
+	if got, want := names(cached), []string{"nginx", "sshd"}; !reflect.DeepEqual(got, want) || loads != 1 {
+		t.Errorf("cached PIDs resolved %q after %d loads, want %q without reloading", got, loads, want)
+	}
+
+	unknown := []networking.ConnSample{{PID: 1000}, {PID: 1001}, {PID: 42}}
+	resolver.Resolve(context.Background(), unknown)
+	if got, want := names(unknown), []string{"", "", "sshd"}; !reflect.DeepEqual(got, want) || loads != 2 {
+		t.Errorf("unknown PIDs resolved %q after %d loads, want %q after one reload", got, loads, want)
+	}
+}
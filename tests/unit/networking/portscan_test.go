@@ -0,0 +1,40 @@
+package networking_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/commands/networking"
+	"suppercommand/internal/config"
+	"suppercommand/internal/monitoring"
+)
+
+func TestPortscan_OutputFormats(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	registry := commands.NewRegistry(monitoring.NewLogger(config.MonitoringConfig{}))
+	registry.Register(networking.NewPortscanCommand())
+	scan := func(extra ...string) *commands.Result {
+		args := append([]string{"-p", fmt.Sprintf("%d", port), "127.0.0.1"}, extra...)
+		result, err := registry.Execute(context.Background(), "portscan", commands.ParseArguments(args))
+		if err != nil || result.ExitCode != 0 {
+			t.Fatalf("portscan %q failed: %v\n%s", args, err, result.Output)
+		}
+		return result
+	}
+
+	if got, want := scan("--output-format", "csv").Output, fmt.Sprintf("port,protocol,service\n%d,tcp,unknown\n", port); got != want {
+		t.Errorf("CSV output = %q, want %q", got, want)
+	}
+	if got, want := scan("--quiet").Output, fmt.Sprintf("%d/tcp unknown\n", port); got != want {
+		t.Errorf("quiet output = %q, want %q", got, want)
+	}
+}
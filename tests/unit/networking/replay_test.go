@@ -0,0 +1,218 @@
+package networking_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/commands/networking"
+	"suppercommand/internal/security"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+)
+
+// testFrame builds an Ethernet/IPv4 frame around the given transport layers
+func testFrame(t *testing.T, src, dst string, transport ...gopacket.SerializableLayer) []byte {
+	ip := &layers.IPv4{Version: 4, TTL: 64, SrcIP: net.ParseIP(src), DstIP: net.ParseIP(dst)}
+	all := []gopacket.SerializableLayer{
+		&layers.Ethernet{SrcMAC: net.HardwareAddr{2, 0, 0, 0, 0, 1}, DstMAC: net.HardwareAddr{2, 0, 0, 0, 0, 2}, EthernetType: layers.EthernetTypeIPv4},
+		ip,
+	}
+	for _, layer := range transport {
+		switch l := layer.(type) {
+		case *layers.TCP:
+			ip.Protocol = layers.IPProtocolTCP
+			l.SetNetworkLayerForChecksum(ip)
+		case *layers.UDP:
+			ip.Protocol = layers.IPProtocolUDP
+			l.SetNetworkLayerForChecksum(ip)
+		case *layers.ICMPv4:
+			ip.Protocol = layers.IPProtocolICMPv4
+		}
+	}
+	all = append(all, transport...)
+
+	buffer := gopacket.NewSerializeBuffer()
+	if err := gopacket.SerializeLayers(buffer, gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}, all...); err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
+
+// writeCapture writes a pcap file of an HTTP request, a DNS query and a ping,
+// 100ms apart
+func writeCapture(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "supershell-replay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	frames := [][]byte{
+		testFrame(t, "192.168.1.10", "93.184.216.34",
+			&layers.TCP{SrcPort: 51000, DstPort: 80, PSH: true, ACK: true, Window: 1024},
+			gopacket.Payload("GET /index.html HTTP/1.1\r\nHost: example.com\r\n\r\n")),
+		testFrame(t, "192.168.1.10", "8.8.8.8",
+			&layers.UDP{SrcPort: 53000, DstPort: 53},
+			&layers.DNS{ID: 1, RD: true, Questions: []layers.DNSQuestion{{Name: []byte("example.com"), Type: layers.DNSTypeA, Class: layers.DNSClassIN}}}),
+		testFrame(t, "192.168.1.10", "1.1.1.1",
+			&layers.ICMPv4{TypeCode: layers.CreateICMPv4TypeCode(layers.ICMPv4TypeEchoRequest, 0), Id: 1, Seq: 1}),
+	}
+
+	path := filepath.Join(dir, "capture.pcap")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer := pcapgo.NewWriter(file)
+	if err := writer.WriteFileHeader(65535, layers.LinkTypeEthernet); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i, frame := range frames {
+		info := gopacket.CaptureInfo{Timestamp: start.Add(time.Duration(i) * 100 * time.Millisecond), CaptureLength: len(frame), Length: len(frame)}
+		if err := writer.WritePacket(info, frame); err != nil {
+			t.Fatal(err)
+		}
+	}
+	file.Close()
+	return path, func() { os.RemoveAll(dir) }
+}
+
+func runReplay(t *testing.T, args ...string) *commands.Result {
+	result, err := networking.NewReplayCommand().Execute(context.Background(), commands.ParseArguments(args))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestReplay_ShowsPacketsLikeSniff(t *testing.T) {
+	capture, cleanup := writeCapture(t)
+	defer cleanup()
+
+	result := runReplay(t, capture)
+	if result.ExitCode != 0 {
+		t.Fatalf("replay failed: %s", result.Output)
+	}
+	for _, want := range []string{"CAPTURED PACKETS", "GET /index.html HTTP/1.1", "A example.com", "EchoRequest", "Total packets:     3"} {
+		if !strings.Contains(result.Output, want) {
+			t.Errorf("output should contain %q:\n%s", want, result.Output)
+		}
+	}
+}
+
+func TestReplay_Filters(t *testing.T) {
+	capture, cleanup := writeCapture(t)
+	defer cleanup()
+
+	result := runReplay(t, capture, "-f", "udp port 53")
+	if !strings.Contains(result.Output, "Total packets:     1") || !strings.Contains(result.Output, "2 of 3 packets didn't match") {
+		t.Errorf("only the DNS query should match:\n%s", result.Output)
+	}
+	result = runReplay(t, capture, "--proto", "http")
+	if !strings.Contains(result.Output, "Total packets:     1") || !strings.Contains(result.Output, "GET /index.html") {
+		t.Errorf("only the HTTP request should match:\n%s", result.Output)
+	}
+	if result := runReplay(t, capture, "-f", "port"); result.ExitCode == 0 || !strings.Contains(result.Output, "'port' needs a number") {
+		t.Errorf("a bad filter should be explained:\n%s", result.Output)
+	}
+}
+
+func TestReplay_TruncatedCapture(t *testing.T) {
+	capture, cleanup := writeCapture(t)
+	defer cleanup()
+
+	data, err := ioutil.ReadFile(capture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(capture, data[:len(data)-10], 0644); err != nil {
+		t.Fatal(err)
+	}
+	result := runReplay(t, capture)
+	if result.ExitCode == 0 {
+		t.Fatal("a truncated capture should fail")
+	}
+	if !strings.Contains(result.Output, "truncated: packet 3 is cut off") {
+		t.Errorf("the error should say where the capture ends:\n%s", result.Output)
+	}
+	if !strings.Contains(result.Output, "Total packets:     2") {
+		t.Errorf("the packets before the cut should still be shown:\n%s", result.Output)
+	}
+}
+
+func TestReplay_NotACapture(t *testing.T) {
+	dir, err := ioutil.TempDir("", "supershell-replay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for name, content := range map[string]string{"empty.pcap": "", "notes.pcap": "these are not packets"} {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		result := runReplay(t, path)
+		if result.ExitCode == 0 || result.Error == nil {
+			t.Errorf("%s should not replay", name)
+			continue
+		}
+		if msg := result.Error.Error(); !strings.Contains(msg, "empty") && !strings.Contains(msg, "not a pcap or pcapng file") {
+			t.Errorf("%s: unclear error %q", name, msg)
+		}
+	}
+}
+
+func TestReplay_SendNeedsInterfaceAndElevation(t *testing.T) {
+	replay := networking.NewReplayCommand()
+	if replay.NeedsElevation(commands.ParseArguments([]string{"capture.pcap"})) {
+		t.Error("showing a capture should not need elevation")
+	}
+	if !replay.NeedsElevation(commands.ParseArguments([]string{"capture.pcap", "eth0", "--send"})) {
+		t.Error("--send should need elevation")
+	}
+	if result := runReplay(t, "capture.pcap", "--send"); result.Error == nil {
+		t.Error("--send without an interface should be a usage error")
+	}
+	if result := runReplay(t, "capture.pcap", "--fast"); result.Error == nil {
+		t.Error("--fast without --send should be a usage error")
+	}
+}
+
+func TestReplay_SendKeepsTiming(t *testing.T) {
+	if runtime.GOOS != "linux" || !security.IsElevated() {
+		t.Skip("sending needs root on Linux")
+	}
+	capture, cleanup := writeCapture(t)
+	defer cleanup()
+
+	start := time.Now()
+	result := runReplay(t, capture, "lo", "--send")
+	if result.ExitCode != 0 {
+		if strings.Contains(result.Output, "cannot send") || strings.Contains(result.Output, "unavailable") {
+			t.Skipf("no packet socket here: %s", result.Output)
+		}
+		t.Fatalf("send failed: %s", result.Output)
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("the packets were captured 200ms apart but sent in %v", elapsed)
+	}
+	if !strings.Contains(result.Output, "Sent 3 packets") {
+		t.Errorf("unexpected report:\n%s", result.Output)
+	}
+
+	start = time.Now()
+	result = runReplay(t, capture, "lo", "--send", "--fast")
+	if result.ExitCode != 0 || time.Since(start) > 150*time.Millisecond {
+		t.Errorf("--fast should send right away, took %v:\n%s", time.Since(start), result.Output)
+	}
+}
@@ -0,0 +1,378 @@
+package networking_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/commands/networking"
+
+	"github.com/google/gopacket/pcapgo"
+)
+
+// flowRows returns the rows of the flow table in a sniff --summary result
+func flowRows(output string) [][]string {
+	var rows [][]string
+	for _, line := range strings.Split(output, "\n") {
+		if strings.Contains(line, "→") {
+			rows = append(rows, strings.Fields(line))
+		}
+	}
+	return rows
+}
+
+func TestSniff_SummaryShowsFlows(t *testing.T) {
+	result, err := networking.NewSniffCommand().Execute(context.Background(), commands.ParseArguments([]string{"lo", "--summary", "--top", "0", "-c", "25"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ExitCode != 0 {
+		t.Fatalf("sniff failed: %s", result.Output)
+	}
+	if strings.Contains(result.Output, "CAPTURED PACKETS") {
+		t.Errorf("--summary should not list packets:\n%s", result.Output)
+	}
+	if !strings.Contains(result.Output, "Interface:   lo") {
+		t.Errorf("the interface can be given without -i:\n%s", result.Output)
+	}
+
+	rows := flowRows(result.Output)
+	if len(rows) == 0 {
+		t.Fatalf("no flows in:\n%s", result.Output)
+	}
+	packets := 0
+	for _, row := range rows {
+		count, err := strconv.Atoi(row[2])
+		if err != nil {
+			t.Fatalf("bad flow row %q", row)
+		}
+		packets += count
+	}
+	if packets != 25 {
+		t.Errorf("flows should add up to the 25 captured packets, got %d", packets)
+	}
+}
+
+func TestSniff_SummaryTop(t *testing.T) {
+	result, err := networking.NewSniffCommand().Execute(context.Background(), commands.ParseArguments([]string{"--summary", "--top", "2", "-c", "25"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows := flowRows(result.Output); len(rows) != 2 {
+		t.Errorf("--top 2 should list 2 flows, got %d:\n%s", len(rows), result.Output)
+	}
+	if !strings.Contains(result.Output, "more flows") {
+		t.Errorf("hidden flows should be mentioned:\n%s", result.Output)
+	}
+}
+
+func TestCompileSniffFilter(t *testing.T) {
+	tests := []struct {
+		name string
+		opts networking.SniffOptions
+		want string
+	}{
+		{"nothing", networking.SniffOptions{}, ""},
+		{"host", networking.SniffOptions{Host: "10.0.0.5"}, "host 10.0.0.5"},
+		{"port", networking.SniffOptions{Port: "53"}, "port 53"},
+		{"transport", networking.SniffOptions{Protocol: "UDP"}, "udp"},
+		{"application", networking.SniffOptions{Protocol: "https"}, "tcp port 443"},
+		{"directions", networking.SniffOptions{SourceIP: "10.0.0.1", DestIP: "8.8.8.8"}, "src host 10.0.0.1 and dst host 8.8.8.8"},
+		{"combined", networking.SniffOptions{Host: "10.0.0.5", Port: "22", Protocol: "tcp"}, "host 10.0.0.5 and port 22 and tcp"},
+		{"raw", networking.SniffOptions{Filter: "tcp port 80 or 443"}, "tcp port 80 or 443"},
+		{"raw with options", networking.SniffOptions{Host: "10.0.0.5", Filter: "port 80 or port 443"}, "host 10.0.0.5 and (port 80 or port 443)"},
+	}
+	for _, test := range tests {
+		got, err := networking.CompileSniffFilter(test.opts)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestCompileSniffFilter_FriendlyErrors(t *testing.T) {
+	tests := []struct {
+		opts networking.SniffOptions
+		hint string
+	}{
+		{networking.SniffOptions{Filter: "tcp.port == 80"}, "Wireshark display filter"},
+		{networking.SniffOptions{Filter: "port"}, "'port' needs a number, e.g. 'port 443'"},
+		{networking.SniffOptions{Filter: "port 8000-8100"}, "use 'portrange 8000-8100'"},
+		{networking.SniffOptions{Filter: "portrange 90-80"}, "write it as 80-90"},
+		{networking.SniffOptions{Filter: "port 70000"}, "out of range"},
+		{networking.SniffOptions{Filter: "https"}, "match its port instead: 'tcp port 443'"},
+		{networking.SniffOptions{Filter: "tcp and"}, "'and' needs an expression after it"},
+		{networking.SniffOptions{Filter: "(tcp or udp"}, "without a matching ')'"},
+		{networking.SniffOptions{Filter: "tcp udp"}, "expected 'and' or 'or' before 'udp'"},
+		{networking.SniffOptions{Filter: "net 10.0.0.0/33"}, "write it like '10.0.0.0/8'"},
+		{networking.SniffOptions{Filter: "192.168.1.1"}, "'host 192.168.1.1'"},
+		{networking.SniffOptions{Filter: "frobnicate"}, "unknown keyword 'frobnicate'"},
+		{networking.SniffOptions{Port: "web"}, "not a port number"},
+		{networking.SniffOptions{Protocol: "gopher"}, "unknown protocol 'gopher'"},
+	}
+	for _, test := range tests {
+		_, err := networking.CompileSniffFilter(test.opts)
+		if err == nil {
+			t.Errorf("%+v should not compile", test.opts)
+			continue
+		}
+		if !strings.Contains(err.Error(), test.hint) {
+			t.Errorf("%+v: error %q should mention %q", test.opts, err, test.hint)
+		}
+	}
+}
+
+func TestSniff_InvalidFilterFailsBeforeCapture(t *testing.T) {
+	result, err := networking.NewSniffCommand().Execute(context.Background(), commands.ParseArguments([]string{"-f", "ip.addr == 10.0.0.1"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ExitCode == 0 || result.Error == nil {
+		t.Fatal("an invalid filter should fail")
+	}
+	if strings.Contains(result.Output, "Initializing") {
+		t.Errorf("the capture should not start:\n%s", result.Output)
+	}
+	if !strings.Contains(result.Output, "Capture filter examples") {
+		t.Errorf("the error should show examples:\n%s", result.Output)
+	}
+}
+
+func TestSniff_RawFilterApplies(t *testing.T) {
+	result, err := networking.NewSniffCommand().Execute(context.Background(), commands.ParseArguments([]string{"--summary", "--top", "0", "-c", "10", "-f", "udp"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(result.Output, "BPF filter:  udp") {
+		t.Errorf("the compiled filter should be shown:\n%s", result.Output)
+	}
+	for _, row := range flowRows(result.Output) {
+		if row[1] != "UDP" && row[1] != "DNS" {
+			t.Errorf("udp filter let through %q", row)
+		}
+	}
+}
+
+// savedPackets checks a capture file sniff saved is a whole pcap file and
+// returns how many packets it holds
+func savedPackets(t *testing.T, path string) int {
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	reader, err := pcapgo.NewReader(file)
+	if err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+	packets := 0
+	for {
+		_, _, err := reader.ReadPacketData()
+		if err == io.EOF {
+			return packets
+		}
+		if err != nil {
+			t.Fatalf("%s: packet %d: %v", path, packets+1, err)
+		}
+		packets++
+	}
+}
+
+func runSniff(t *testing.T, args ...string) *commands.Result {
+	result, err := networking.NewSniffCommand().Execute(context.Background(), commands.ParseArguments(args))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestSniff_SavesCapture(t *testing.T) {
+	dir, err := ioutil.TempDir("", "supershell-sniff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "capture.pcap")
+	result := runSniff(t, "-c", "5", "--save", path)
+	if result.ExitCode != 0 {
+		t.Fatalf("sniff failed: %s", result.Output)
+	}
+	if packets := savedPackets(t, path); packets != 5 {
+		t.Errorf("expected the 5 captured packets in %s, got %d", path, packets)
+	}
+
+	// What sniff saves, replay reads back
+	replayed, err := networking.NewReplayCommand().Execute(context.Background(), commands.ParseArguments([]string{path}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed.ExitCode != 0 || !strings.Contains(replayed.Output, "Total packets:     5") {
+		t.Errorf("the saved capture should replay:\n%s", replayed.Output)
+	}
+}
+
+func TestSniff_RotatesBySize(t *testing.T) {
+	dir, err := ioutil.TempDir("", "supershell-sniff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	result := runSniff(t, "-c", "30", "--save", filepath.Join(dir, "capture.pcap"), "--rotate-size", "4K", "--keep", "2")
+	if result.ExitCode != 0 {
+		t.Fatalf("sniff failed: %s", result.Output)
+	}
+	rotations := strings.Count(result.Output, "🔄 Rotated capture-")
+	if rotations < 2 {
+		t.Fatalf("30 packets of up to 1.5 KB should rotate a 4 KB file more than once:\n%s", result.Output)
+	}
+	if !strings.Contains(result.Output, "Removed capture-001.pcap, keeping the last 2 files") {
+		t.Errorf("the oldest file should be removed:\n%s", result.Output)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("--keep 2 should leave 2 files, found %v", files)
+	}
+	for _, file := range files {
+		if !strings.HasPrefix(filepath.Base(file), "capture-") {
+			t.Errorf("rotated files should be numbered, found %s", file)
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if packets := savedPackets(t, file); packets > 1 && info.Size() > 4096 {
+			t.Errorf("%s holds %d packets in %d bytes, over the limit", file, packets, info.Size())
+		}
+	}
+}
+
+func TestSniff_RotatesByTime(t *testing.T) {
+	dir, err := ioutil.TempDir("", "supershell-sniff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	result := runSniff(t, "-c", "10", "--save", filepath.Join(dir, "capture.pcap"), "--rotate-time", "300ms")
+	if result.ExitCode != 0 {
+		t.Fatalf("sniff failed: %s", result.Output)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "capture-*.pcap"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) < 2 {
+		t.Fatalf("10 packets over more than a second should span several 300ms files, found %v:\n%s", files, result.Output)
+	}
+	packets := 0
+	for _, file := range files {
+		packets += savedPackets(t, file)
+	}
+	if packets != 10 {
+		t.Errorf("the files should hold all 10 packets between them, got %d", packets)
+	}
+}
+
+func TestSniff_RotationNeedsSave(t *testing.T) {
+	for _, args := range [][]string{
+		{"--rotate-size", "1M"},
+		{"--save", "capture.pcap", "--keep", "3"},
+		{"--save", "capture.pcap", "--rotate-size", "lots"},
+		{"--save", "capture.pcap", "--rotate-time", "soon"},
+	} {
+		if result := runSniff(t, args...); result.Error == nil {
+			t.Errorf("%v should be a usage error", args)
+		}
+	}
+}
+
+// jsonLines decodes each line of sniff --jsonl output
+func jsonLines(t *testing.T, data string) []map[string]interface{} {
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSuffix(data, "\n"), "\n") {
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("line %q is not JSON: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestSniff_JSONLinesToStdout(t *testing.T) {
+	var out bytes.Buffer
+	ctx := commands.WithOutputWriter(context.Background(), &out)
+	result, err := networking.NewSniffCommand().Execute(ctx, commands.ParseArguments([]string{"-c", "6", "--jsonl"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ExitCode != 0 || result.Output != "" {
+		t.Fatalf("--jsonl should leave stdout to the packets, got exit %d and:\n%s", result.ExitCode, result.Output)
+	}
+
+	records := jsonLines(t, out.String())
+	if len(records) != 6 {
+		t.Fatalf("expected a line per captured packet, got %d:\n%s", len(records), out.String())
+	}
+	for _, record := range records {
+		for _, field := range []string{"timestamp", "proto", "src", "dst", "length"} {
+			if _, ok := record[field]; !ok {
+				t.Errorf("record %v has no %s", record, field)
+			}
+		}
+		if _, err := time.Parse(time.RFC3339Nano, record["timestamp"].(string)); err != nil {
+			t.Errorf("timestamp: %v", err)
+		}
+		if proto := record["proto"]; proto != "ICMP" && proto != "ARP" && record["dst_port"] == nil {
+			t.Errorf("a %v record should have ports: %v", proto, record)
+		}
+	}
+}
+
+func TestSniff_JSONLinesToFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "supershell-sniff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "packets.jsonl")
+	for run := 0; run < 2; run++ {
+		result := runSniff(t, "-c", "3", "--jsonl-file", path, "--summary")
+		if result.ExitCode != 0 || !strings.Contains(result.Output, "Streamed:          3 packets to "+path) {
+			t.Fatalf("sniff failed:\n%s", result.Output)
+		}
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if records := jsonLines(t, string(data)); len(records) != 6 {
+		t.Errorf("a second capture should append to the file, found %d records", len(records))
+	}
+}
+
+func TestSniff_JSONLinesOnStdoutRejectsSummary(t *testing.T) {
+	if result := runSniff(t, "--jsonl", "--summary"); result.Error == nil {
+		t.Error("--summary and --jsonl on stdout should be a usage error")
+	}
+}
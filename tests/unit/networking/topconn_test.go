@@ -0,0 +1,180 @@
+package networking_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/commands/networking"
+)
+
+// ssSamples are two readings of ss -tinp a moment apart. curl moves 2 MB in,
+// firefox is idle on one connection and busy on another, and the loopback
+// traffic of postgres is left out.
+var ssSamples = []string{`State Recv-Q Send-Q Local Address:Port Peer Address:Port Process
+ESTAB 0      0      192.168.1.5:41000  93.184.216.34:443 users:(("curl",pid=900,fd=5))
+	 cubic wscale:7,7 bytes_sent:1000 bytes_acked:1000 bytes_received:5000000
+ESTAB 0      0      192.168.1.5:41010  142.250.1.1:443   users:(("firefox",pid=300,fd=40))
+	 cubic bytes_sent:200 bytes_received:300
+ESTAB 0      0      192.168.1.5:41020  142.250.1.2:443   users:(("firefox",pid=300,fd=41))
+	 cubic bytes_sent:10 bytes_received:10
+ESTAB 0      0      127.0.0.1:5432     127.0.0.1:50000   users:(("postgres",pid=77,fd=9))
+	 cubic bytes_sent:10 bytes_received:10
+`, `State Recv-Q Send-Q Local Address:Port Peer Address:Port Process
+ESTAB 0      0      192.168.1.5:41000  93.184.216.34:443 users:(("curl",pid=900,fd=5))
+	 cubic wscale:7,7 bytes_sent:3000 bytes_acked:3000 bytes_received:7000000
+ESTAB 0      0      192.168.1.5:41010  142.250.1.1:443   users:(("firefox",pid=300,fd=40))
+	 cubic bytes_sent:200 bytes_received:300
+ESTAB 0      0      192.168.1.5:41020  142.250.1.2:443   users:(("firefox",pid=300,fd=41))
+	 cubic bytes_sent:5010 bytes_received:1010
+ESTAB 0      0      127.0.0.1:5432     127.0.0.1:50000   users:(("postgres",pid=77,fd=9))
+	 cubic bytes_sent:900000 bytes_received:900000
+ESTAB 0      0      192.168.1.5:41030  10.0.0.9:22
+	 cubic bytes_sent:400 bytes_received:400
+`}
+
+// sequenceRunner answers each run of a tool with the next of its outputs,
+// repeating the last one
+type sequenceRunner struct {
+	*commands.MockRunner
+	outputs map[string][]string
+
+	mu   sync.Mutex
+	runs map[string]int
+}
+
+func (s *sequenceRunner) Run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	line := strings.Join(append([]string{name}, args...), " ")
+	s.mu.Lock()
+	outputs, ok := s.outputs[line]
+	run := s.runs[line]
+	s.runs[line]++
+	s.mu.Unlock()
+	if !ok {
+		return s.MockRunner.Run(ctx, name, args...)
+	}
+	if run >= len(outputs) {
+		run = len(outputs) - 1
+	}
+	return []byte(outputs[run]), nil
+}
+
+// topConnections returns a top-connections command reading ssSamples
+func topConnections(t *testing.T) *networking.TopConnectionsCommand {
+	t.Helper()
+	if runtime.GOOS != "linux" {
+		t.Skip("byte counters are read from ss on Linux")
+	}
+	cmd := networking.NewTopConnectionsCommand()
+	cmd.SetRunner(&sequenceRunner{
+		MockRunner: commands.NewMockRunner(),
+		outputs:    map[string][]string{"ss -tinp": ssSamples},
+		runs:       map[string]int{},
+	})
+	return cmd
+}
+
+type processTraffic struct {
+	PID           int     `json:"pid"`
+	Process       string  `json:"process"`
+	Connections   int     `json:"connections"`
+	BytesSent     int64   `json:"bytes_sent"`
+	BytesReceived int64   `json:"bytes_received"`
+	SendRate      float64 `json:"send_rate"`
+	RecvRate      float64 `json:"recv_rate"`
+}
+
+func topJSON(t *testing.T, cmd *networking.TopConnectionsCommand, args ...string) []processTraffic {
+	t.Helper()
+	ctx := commands.WithOutputOptions(context.Background(), commands.OutputOptions{Format: commands.FormatJSON})
+	result, err := cmd.Execute(ctx, commands.ParseArguments(append([]string{"--interval", "0.05"}, args...)))
+	if err != nil || result.ExitCode != 0 {
+		t.Fatalf("top-connections failed: %+v, %v", result, err)
+	}
+	var processes []processTraffic
+	if err := json.Unmarshal([]byte(result.Output), &processes); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, result.Output)
+	}
+	return processes
+}
+
+func TestTopConnections_AttributesTraffic(t *testing.T) {
+	processes := topJSON(t, topConnections(t))
+
+	want := []processTraffic{
+		{PID: 900, Process: "curl", Connections: 1, BytesSent: 2000, BytesReceived: 2000000},
+		{PID: 300, Process: "firefox", Connections: 2, BytesSent: 5000, BytesReceived: 1000},
+		// A connection first seen in the second sample has no rate yet
+		{PID: 0, Connections: 1},
+	}
+	if len(processes) != len(want) {
+		t.Fatalf("expected %d processes without loopback traffic, got %+v", len(want), processes)
+	}
+	for i, process := range processes {
+		if process.SendRate <= 0 && process.BytesSent > 0 {
+			t.Errorf("%s should have a send rate: %+v", process.Process, process)
+		}
+		process.SendRate, process.RecvRate = 0, 0
+		if process != want[i] {
+			t.Errorf("process %d is %+v, want %+v", i, process, want[i])
+		}
+	}
+}
+
+func TestTopConnections_Filter(t *testing.T) {
+	processes := topJSON(t, topConnections(t), "--filter", "FIRE")
+	if len(processes) != 1 || processes[0].PID != 300 {
+		t.Errorf("--filter should match process names ignoring case, got %+v", processes)
+	}
+	if processes = topJSON(t, topConnections(t), "--filter", "nothing"); len(processes) != 0 {
+		t.Errorf("a filter matching nothing should list no processes, got %+v", processes)
+	}
+}
+
+func TestTopConnections_Live(t *testing.T) {
+	cmd := topConnections(t)
+	var out strings.Builder
+	ctx := commands.WithOutputWriter(context.Background(), &out)
+
+	result, err := cmd.Execute(ctx, commands.ParseArguments([]string{"--interval", "0.05", "--count", "1", "--limit", "1"}))
+	if err != nil || result.ExitCode != 0 {
+		t.Fatalf("top-connections failed: %+v, %v", result, err)
+	}
+	frame := out.String()
+	if !strings.Contains(frame, "curl(900)") || strings.Contains(frame, "firefox(300)") {
+		t.Errorf("--limit 1 should show only the busiest process:\n%s", frame)
+	}
+	if !strings.Contains(frame, "... and 2 more") || !strings.Contains(frame, "3 processes · 4 connections") {
+		t.Errorf("the frame should count what it left out:\n%s", frame)
+	}
+	if strings.Contains(frame, "Press Enter") {
+		t.Errorf("a run with --count doesn't wait for Enter:\n%s", frame)
+	}
+}
+
+func TestTopConnections_Errors(t *testing.T) {
+	cmd := networking.NewTopConnectionsCommand()
+	for _, args := range [][]string{{"--interval", "0"}, {"--count", "0"}, {"--limit", "-1"}} {
+		result, _ := cmd.Execute(context.Background(), commands.ParseArguments(args))
+		if result.ExitCode != 1 || result.Error == nil {
+			t.Errorf("%v should be a usage error, got %+v", args, result)
+		}
+	}
+}
+
+func TestTopConnections_ToolFails(t *testing.T) {
+	if runtime.GOOS == "linux" {
+		t.Skip("Linux falls back to /proc without ss")
+	}
+	cmd := networking.NewTopConnectionsCommand()
+	cmd.SetRunner(commands.NewMockRunner().Fail("netstat -ano", errors.New("exit status 1")).Fail("netstat -an", errors.New("exit status 1")))
+	result, _ := cmd.Execute(context.Background(), commands.ParseArguments([]string{"--count", "1"}))
+	if result.ExitCode != 1 || result.Error == nil {
+		t.Errorf("a failing netstat should fail the command, got %+v", result)
+	}
+}
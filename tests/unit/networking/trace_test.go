@@ -0,0 +1,191 @@
+package networking_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/commands/networking"
+)
+
+func TestTraceParser_Strace(t *testing.T) {
+	tests := []struct {
+		line string
+		want networking.TraceEvent
+	}{
+		{
+			`4711  10:42:01.123456 openat(AT_FDCWD</home/me>, "notes.txt", O_RDONLY) = 3</home/me/notes.txt>`,
+			networking.TraceEvent{Time: "10:42:01.123456", PID: 4711, Kind: networking.TraceOpen, Call: "openat", Target: "/home/me/notes.txt", Result: "3</home/me/notes.txt>"},
+		},
+		{
+			`[pid  4712] 10:42:01.200000 openat(AT_FDCWD</home/me>, "missing.conf", O_RDONLY) = -1 ENOENT (No such file or directory)`,
+			networking.TraceEvent{Time: "10:42:01.200000", PID: 4712, Kind: networking.TraceOpen, Call: "openat", Target: "/home/me/missing.conf", Result: "-1 ENOENT (No such file or directory)", Error: "ENOENT"},
+		},
+		{
+			`4711  10:42:02.000000 connect(5<TCP:[1234]>, {sa_family=AF_INET, sin_port=htons(443), sin_addr=inet_addr("93.184.216.34")}, 16) = -1 EINPROGRESS (Operation now in progress)`,
+			networking.TraceEvent{Time: "10:42:02.000000", PID: 4711, Kind: networking.TraceConnect, Call: "connect", Target: "93.184.216.34:443", Result: "-1 EINPROGRESS (Operation now in progress)"},
+		},
+		{
+			`4711  10:42:03.000000 write(1</dev/pts/0>, "hello\n", 6) = 6`,
+			networking.TraceEvent{Time: "10:42:03.000000", PID: 4711, Kind: networking.TraceWrite, Call: "write", Target: "/dev/pts/0", Result: "6", Bytes: 6},
+		},
+		{
+			`4711  10:42:04.000000 +++ exited with 2 +++`,
+			networking.TraceEvent{Time: "10:42:04.000000", PID: 4711, Kind: networking.TraceExit, Call: "exit", Result: "2"},
+		},
+	}
+	parser := networking.NewTraceParser(false)
+	for _, test := range tests {
+		got, ok := parser.Parse(test.line)
+		if !ok || got != test.want {
+			t.Errorf("Parse(%q) = %+v, %v, want %+v", test.line, got, ok, test.want)
+		}
+	}
+
+	for _, line := range []string{
+		`4711  10:42:05.000000 mmap(NULL, 8192, PROT_READ, MAP_PRIVATE, 3, 0) = 0x7f0000000000`,
+		`4711  10:42:05.000000 --- SIGCHLD {si_signo=SIGCHLD} ---`,
+		`strace: Process 4711 attached`,
+	} {
+		if event, ok := parser.Parse(line); ok {
+			t.Errorf("Parse(%q) should not be an event, got %+v", line, event)
+		}
+	}
+}
+
+func TestTraceParser_JoinsSplitCalls(t *testing.T) {
+	parser := networking.NewTraceParser(false)
+	if _, ok := parser.Parse(`[pid 12] 10:00:00.000001 unlink("/tmp/lock" <unfinished ...>`); ok {
+		t.Fatal("the first half of a split call is not an event yet")
+	}
+	if _, ok := parser.Parse(`[pid 13] 10:00:00.000002 <... unlink resumed>) = 0`); ok {
+		t.Error("a call is only resumed by its own process")
+	}
+	event, ok := parser.Parse(`[pid 12] 10:00:00.000003 <... unlink resumed>) = -1 EACCES (Permission denied)`)
+	if !ok || event.Kind != networking.TraceDelete || event.Target != "/tmp/lock" || event.Error != "EACCES" {
+		t.Errorf("unexpected resumed event %+v, %v", event, ok)
+	}
+}
+
+func TestTraceParser_Ltrace(t *testing.T) {
+	parser := networking.NewTraceParser(true)
+	event, ok := parser.Parse(`321 10:00:00.500000 fopen("/etc/app.conf", "r") = 0`)
+	if !ok || event.Kind != networking.TraceOpen || event.Target != "/etc/app.conf" || !event.Failed() {
+		t.Errorf("a null fopen should be a failed open, got %+v, %v", event, ok)
+	}
+	event, ok = parser.Parse(`321 10:00:01.000000 +++ exited (status 1) +++`)
+	if !ok || event.Kind != networking.TraceExit || event.Result != "1" {
+		t.Errorf("unexpected ltrace exit %+v, %v", event, ok)
+	}
+}
+
+func TestParseHandleList(t *testing.T) {
+	output := "\r\nnotepad.exe pid: 4242 DESKTOP\\me\r\n" +
+		"   40: File  (RW-)   C:\\Windows\\System32\r\n" +
+		"   44: Key           HKLM\\SOFTWARE\r\n" +
+		"  1A8: File  (R-D)   C:\\Users\\me\\notes.txt\r\n" +
+		"  1B0: File  (RW-)   C:\\Windows\\System32\r\n"
+	got := networking.ParseHandleList(output)
+	want := []string{`C:\Windows\System32`, `C:\Users\me\notes.txt`}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("ParseHandleList() = %q, want %q", got, want)
+	}
+}
+
+// fakeStrace puts a strace on PATH that writes lines as its events and exits
+func fakeStrace(t *testing.T, lines ...string) {
+	t.Helper()
+	if runtime.GOOS != "linux" {
+		t.Skip("strace is only used on Linux")
+	}
+	if data, err := ioutil.ReadFile("/proc/sys/kernel/yama/ptrace_scope"); err == nil && strings.TrimSpace(string(data)) == "3" {
+		t.Skip("ptrace is disabled on this system")
+	}
+	dir := t.TempDir()
+	events := filepath.Join(dir, "events")
+	if err := ioutil.WriteFile(events, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	script := "#!/bin/sh\ncat '" + events + "' >&3\necho 'program output'\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "strace"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := os.Getenv("PATH")
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+path)
+	t.Cleanup(func() { os.Setenv("PATH", path) })
+}
+
+var traceLines = []string{
+	`100 10:00:00.000001 openat(AT_FDCWD</srv>, "app.conf", O_RDONLY) = 3</srv/app.conf>`,
+	`100 10:00:00.000002 openat(AT_FDCWD</srv>, "local.conf", O_RDONLY) = -1 ENOENT (No such file or directory)`,
+	`100 10:00:00.000003 read(3</srv/app.conf>, "x", 4096) = 120`,
+	`100 10:00:00.000004 connect(4<TCP:[99]>, {sa_family=AF_INET, sin_port=htons(5432), sin_addr=inet_addr("10.0.0.5")}, 16) = 0`,
+	`100 10:00:00.000005 +++ exited with 3 +++`,
+}
+
+func runTrace(t *testing.T, args ...string) (*commands.Result, string) {
+	t.Helper()
+	var out strings.Builder
+	ctx := commands.WithOutputWriter(context.Background(), &out)
+	result, err := networking.NewTraceCommand().Execute(ctx, commands.ParseArguments(args))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result, out.String()
+}
+
+func TestTrace_StreamsFilteredEvents(t *testing.T) {
+	fakeStrace(t, traceLines...)
+	result, out := runTrace(t, "myapp", "--config", "x")
+	if result.ExitCode != 3 {
+		t.Errorf("trace should exit with the program's code, got %d (%v)", result.ExitCode, result.Error)
+	}
+	for _, want := range []string{"/srv/app.conf", "/srv/local.conf", "ENOENT", "10.0.0.5:5432", "program output"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output should contain %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "read") {
+		t.Errorf("reads aren't shown by default:\n%s", out)
+	}
+	if !strings.Contains(result.Stderr, "Traced 3 events") || !strings.Contains(result.Stderr, "1 failed") {
+		t.Errorf("unexpected summary %q", result.Stderr)
+	}
+
+	_, out = runTrace(t, "--failed", "--json", "myapp")
+	var events []networking.TraceEvent
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		var event networking.TraceEvent
+		if json.Unmarshal([]byte(line), &event) == nil {
+			events = append(events, event)
+		}
+	}
+	if len(events) != 2 || events[0].Target != "/srv/local.conf" || events[1].Kind != networking.TraceExit {
+		t.Errorf("--failed should keep the failed open and the exit, got %+v", events)
+	}
+
+	_, out = runTrace(t, "-e", "io", "--path", "*.conf", "myapp")
+	if !strings.Contains(out, "read") || strings.Contains(out, "10.0.0.5") || strings.Contains(out, "local.conf") {
+		t.Errorf("-e io --path should show only the read:\n%s", out)
+	}
+}
+
+func TestTrace_RejectsBadArguments(t *testing.T) {
+	for _, args := range [][]string{
+		{},
+		{"-e", "sockets", "myapp"},
+		{"-p", "12", "myapp"},
+		{"-c", "-1", "myapp"},
+	} {
+		result, _ := runTrace(t, args...)
+		if result.ExitCode == 0 || !strings.HasPrefix(result.Output, "Usage:") {
+			t.Errorf("trace %v should be a usage error, got %+v", args, result)
+		}
+	}
+}
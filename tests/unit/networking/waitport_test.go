@@ -0,0 +1,95 @@
+package networking_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/commands/networking"
+)
+
+// closedAddress returns a local address nothing listens on
+func closedAddress(t *testing.T) string {
+	return fmt.Sprintf("127.0.0.1:%d", freePort(t))
+}
+
+func waitPort(ctx context.Context, args ...string) *commands.Result {
+	result, _ := networking.NewWaitPortCommand().Execute(ctx, commands.ParseArguments(args))
+	return result
+}
+
+func TestWaitPort_OpensLater(t *testing.T) {
+	address := closedAddress(t)
+	go func() {
+		time.Sleep(150 * time.Millisecond)
+		listener, err := net.Listen("tcp", address)
+		if err != nil {
+			return
+		}
+		time.Sleep(2 * time.Second)
+		listener.Close()
+	}()
+
+	result := waitPort(context.Background(), address, "--timeout", "3s", "--interval", "50ms")
+	if result.ExitCode != 0 || !strings.Contains(result.Output, address+" is open") {
+		t.Errorf("expected the port to open, got %+v", result)
+	}
+}
+
+func TestWaitPort_TimesOut(t *testing.T) {
+	address := closedAddress(t)
+	start := time.Now()
+	result := waitPort(context.Background(), address, "--timeout", "300ms", "--interval", "50ms")
+	if result.ExitCode != 1 || result.Error == nil || !strings.Contains(result.Error.Error(), "still not open") {
+		t.Errorf("expected a timeout, got %+v", result)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("timeout of 300ms took %v", elapsed)
+	}
+
+	// --invert succeeds at once on a closed port, and times out on an open one
+	if result := waitPort(context.Background(), address, "--invert", "--timeout", "1s"); result.ExitCode != 0 {
+		t.Errorf("expected --invert to see the port closed, got %+v", result)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	if result := waitPort(context.Background(), listener.Addr().String(), "--invert", "--timeout", "200ms", "-i", "50ms"); result.ExitCode != 1 {
+		t.Errorf("expected --invert to time out on an open port, got %+v", result)
+	}
+}
+
+func TestWaitPort_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	result := waitPort(ctx, closedAddress(t), "--timeout", "0", "--interval", "10s")
+	if result.ExitCode != 1 || result.Error == nil || !strings.Contains(result.Error.Error(), "interrupted") {
+		t.Errorf("expected an interrupted wait, got %+v", result)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("cancelled wait took %v", elapsed)
+	}
+}
+
+func TestWaitPort_RejectsBadArguments(t *testing.T) {
+	for _, args := range [][]string{
+		{},
+		{"localhost"},
+		{"localhost:99999"},
+		{"localhost:80", "--timeout", "soon"},
+		{"localhost:80", "--interval", "0s"},
+	} {
+		result := waitPort(context.Background(), args...)
+		if result.ExitCode != 1 || !strings.HasPrefix(result.Output, "Usage: ") {
+			t.Errorf("%q: expected a usage error, got %+v", args, result)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package security_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"suppercommand/internal/security"
+)
+
+// tempStorePath returns a credential store path inside a fresh temporary directory
+func tempStorePath(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "supershell-cred")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	return filepath.Join(dir, "credentials.json"), func() { os.RemoveAll(dir) }
+}
+
+func TestCredentialStore_RoundTrip(t *testing.T) {
+	path, cleanup := tempStorePath(t)
+	defer cleanup()
+
+	store, err := security.OpenCredentialStore(path, "master")
+	if err != nil {
+		t.Fatalf("OpenCredentialStore() error = %v", err)
+	}
+
+	cred := &security.Credential{
+		Name: "mybackup",
+		Type: security.CredentialTypeAWS,
+		Fields: map[string]string{
+			"access_key": "AKIAEXAMPLE",
+			"secret_key": "super-secret-value",
+			"region":     "us-east-1",
+		},
+	}
+	if err := store.Add(cred); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	// Secrets must not be stored in clear text
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read store file: %v", err)
+	}
+	if strings.Contains(string(raw), "super-secret-value") || strings.Contains(string(raw), "AKIAEXAMPLE") {
+		t.Error("credential store contains plaintext secrets")
+	}
+
+	reopened, err := security.OpenCredentialStore(path, "master")
+	if err != nil {
+		t.Fatalf("reopen error = %v", err)
+	}
+
+	got, err := reopened.Get("mybackup")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.Type != security.CredentialTypeAWS {
+		t.Errorf("Type = %q, want %q", got.Type, security.CredentialTypeAWS)
+	}
+	if got.Get("secret_key") != "super-secret-value" {
+		t.Errorf("secret_key = %q, want %q", got.Get("secret_key"), "super-secret-value")
+	}
+	if got.Secret() != "super-secret-value" {
+		t.Errorf("Secret() = %q, want %q", got.Secret(), "super-secret-value")
+	}
+}
+
+func TestCredentialStore_WrongPassword(t *testing.T) {
+	path, cleanup := tempStorePath(t)
+	defer cleanup()
+
+	store, err := security.OpenCredentialStore(path, "correct")
+	if err != nil {
+		t.Fatalf("OpenCredentialStore() error = %v", err)
+	}
+	if err := store.Add(&security.Credential{Name: "k", Type: security.CredentialTypeKey, Fields: map[string]string{"secret": "s"}}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	if _, err := security.OpenCredentialStore(path, "wrong"); err == nil {
+		t.Error("OpenCredentialStore() with wrong password should fail")
+	}
+	if _, err := security.OpenCredentialStore(path, ""); err == nil {
+		t.Error("OpenCredentialStore() with empty password should fail")
+	}
+}
+
+func TestCredentialStore_ListAndRemove(t *testing.T) {
+	path, cleanup := tempStorePath(t)
+	defer cleanup()
+
+	store, err := security.OpenCredentialStore(path, "master")
+	if err != nil {
+		t.Fatalf("OpenCredentialStore() error = %v", err)
+	}
+
+	for _, name := range []string{"zeta", "alpha"} {
+		if err := store.Add(&security.Credential{Name: name, Type: security.CredentialTypeKey, Fields: map[string]string{"secret": name}}); err != nil {
+			t.Fatalf("Add(%s) error = %v", name, err)
+		}
+	}
+
+	infos := store.List()
+	if len(infos) != 2 || infos[0].Name != "alpha" || infos[1].Name != "zeta" {
+		t.Fatalf("List() = %+v, want alpha and zeta in order", infos)
+	}
+
+	if err := store.Remove("alpha"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if err := store.Remove("alpha"); err == nil {
+		t.Error("Remove() of missing credential should fail")
+	}
+	if _, err := store.Get("alpha"); err == nil {
+		t.Error("Get() of removed credential should fail")
+	}
+	if len(store.List()) != 1 {
+		t.Errorf("List() length = %d, want 1", len(store.List()))
+	}
+}
+
+func TestCredentialStore_SwappedEntriesFail(t *testing.T) {
+	path, cleanup := tempStorePath(t)
+	defer cleanup()
+
+	store, err := security.OpenCredentialStore(path, "master")
+	if err != nil {
+		t.Fatalf("OpenCredentialStore() error = %v", err)
+	}
+	for _, name := range []string{"staging", "production"} {
+		if err := store.Add(&security.Credential{Name: name, Type: security.CredentialTypeKey, Fields: map[string]string{"secret": name}}); err != nil {
+			t.Fatalf("Add(%s) error = %v", name, err)
+		}
+	}
+
+	// Swapping the encrypted blobs in the file must not hand out the
+	// production secret under the staging name
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var file map[string]interface{}
+	if err := json.Unmarshal(raw, &file); err != nil {
+		t.Fatal(err)
+	}
+	entries := file["entries"].(map[string]interface{})
+	staging, production := entries["staging"].(map[string]interface{}), entries["production"].(map[string]interface{})
+	staging["ciphertext"], production["ciphertext"] = production["ciphertext"], staging["ciphertext"]
+	if raw, err = json.Marshal(file); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, raw, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err = security.OpenCredentialStore(path, "master")
+	if err != nil {
+		t.Fatalf("OpenCredentialStore() error = %v", err)
+	}
+	if cred, err := store.Get("staging"); err == nil {
+		t.Errorf("Get(staging) = %v, want a decryption error", cred.Fields)
+	}
+}
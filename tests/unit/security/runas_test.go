@@ -0,0 +1,82 @@
+package security_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"suppercommand/internal/security"
+)
+
+func TestParseAccount(t *testing.T) {
+	tests := map[string]security.Account{
+		"alice":          {User: "alice"},
+		`CORP\svc-sql`:   {User: "svc-sql", Domain: "CORP"},
+		"svc@corp.local": {User: "svc", Domain: "corp.local"},
+		`.\admin`:        {User: "admin", Domain: "."},
+	}
+	for name, want := range tests {
+		if got := security.ParseAccount(name); got != want {
+			t.Errorf("ParseAccount(%q) = %+v, want %+v", name, got, want)
+		}
+	}
+}
+
+// fakeSudo puts a sudo on PATH that runs script instead of switching user
+func fakeSudo(t *testing.T, script string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("sudo is not used on Windows")
+	}
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "sudo"), []byte("#!/bin/sh\n"+script+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := os.Getenv("PATH")
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+path)
+	t.Cleanup(func() { os.Setenv("PATH", path) })
+}
+
+func TestRunAs_SudoStreamsAndExits(t *testing.T) {
+	fakeSudo(t, `echo "as $2: $6"; echo warning >&2; exit 4`)
+	var stdout, stderr strings.Builder
+	code, err := security.RunAs(context.Background(), security.Account{User: "alice"}, "sysinfo", strings.NewReader(""), &stdout, &stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code != 4 {
+		t.Errorf("exit code = %d, want 4", code)
+	}
+	if stdout.String() != "as alice: sysinfo\n" || stderr.String() != "warning\n" {
+		t.Errorf("unexpected output %q, %q", stdout.String(), stderr.String())
+	}
+}
+
+func TestRunAs_SudoRefusals(t *testing.T) {
+	tests := map[string]string{
+		"sudo: 3 incorrect password attempts":     "authentication failed",
+		"sudo: unknown user nobody2":              "unknown user",
+		"sudo: alice is not in the sudoers file.": "does not allow",
+	}
+	for complaint, want := range tests {
+		fakeSudo(t, "echo '"+complaint+"' >&2; exit 1")
+		var stderr strings.Builder
+		_, err := security.RunAs(context.Background(), security.Account{User: "bob"}, "sysinfo", strings.NewReader(""), ioutil.Discard, &stderr)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%q should be reported as %q, got %v", complaint, want, err)
+		}
+		if !strings.Contains(stderr.String(), complaint) {
+			t.Errorf("sudo's message should still be shown, got %q", stderr.String())
+		}
+	}
+
+	// A command failing on its own is not a refusal
+	fakeSudo(t, "echo 'something went wrong' >&2; exit 1")
+	if code, err := security.RunAs(context.Background(), security.Account{User: "bob"}, "sysinfo", strings.NewReader(""), ioutil.Discard, ioutil.Discard); err != nil || code != 1 {
+		t.Errorf("a failing command should only set the exit code, got %d, %v", code, err)
+	}
+}
@@ -0,0 +1,65 @@
+package security_test
+
+import (
+	"strings"
+	"testing"
+
+	"suppercommand/internal/security"
+)
+
+func TestScriptGuard_MatchesGuardedCommands(t *testing.T) {
+	guard := security.NewScriptGuard(security.ScriptGuardBlock, []string{"rm", "Firewall Disable", "  "})
+
+	tests := []struct {
+		name    string
+		args    []string
+		blocked bool
+	}{
+		{"rm", []string{"-rf", "build"}, true},
+		{"RM", nil, true},
+		{"rmdir", []string{"build"}, false},
+		{"firewall", []string{"disable"}, true},
+		{"firewall", []string{"--json", "disable"}, true},
+		{"firewall", []string{"status"}, false},
+		{"firewall", nil, false},
+		{"echo", []string{"rm"}, false},
+	}
+	for _, tt := range tests {
+		err := guard.Check(tt.name, tt.args)
+		if (err != nil) != tt.blocked {
+			t.Errorf("Check(%q, %q) = %v, want blocked %v", tt.name, tt.args, err, tt.blocked)
+		}
+	}
+	want := "rm -rf build|RM|firewall disable|firewall --json disable"
+	if got := strings.Join(guard.Blocked(), "|"); got != want {
+		t.Errorf("Blocked() = %q, want %q", got, want)
+	}
+}
+
+func TestScriptGuard_Modes(t *testing.T) {
+	guard := security.NewScriptGuard(security.ScriptGuardRun, []string{"rm"})
+	if err := guard.Check("rm", []string{"x"}); err != nil || len(guard.Blocked()) != 0 {
+		t.Errorf("run mode should let guarded commands through, got %v", err)
+	}
+
+	var prompts []string
+	answer := true
+	guard = security.NewScriptGuard(security.ScriptGuardConfirm, []string{"rm"})
+	guard.SetConfirm(func(prompt string) bool {
+		prompts = append(prompts, prompt)
+		return answer
+	})
+	if err := guard.Check("rm", []string{"a"}); err != nil {
+		t.Errorf("a confirmed command should run, got %v", err)
+	}
+	answer = false
+	if err := guard.Check("rm", []string{"b"}); err == nil || !strings.Contains(err.Error(), "not confirmed") {
+		t.Errorf("a declined command should be blocked, got %v", err)
+	}
+	if err := guard.Check("ls", nil); err != nil || len(prompts) != 2 {
+		t.Errorf("only guarded commands should be confirmed, asked %q", prompts)
+	}
+	if got := guard.Blocked(); len(got) != 1 || got[0] != "rm b" {
+		t.Errorf("Blocked() = %q", got)
+	}
+}
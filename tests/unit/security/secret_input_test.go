@@ -0,0 +1,41 @@
+package security_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"suppercommand/internal/security"
+)
+
+func TestStopOnEnter_ClosesWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stop := security.StopOnEnter(ctx)
+	cancel()
+	select {
+	case <-stop:
+	case <-time.After(time.Second):
+		t.Fatal("the stop channel should close when the context ends")
+	}
+}
+
+func TestCancelOnEnter_FollowsParent(t *testing.T) {
+	parent, cancelParent := context.WithCancel(context.Background())
+	ctx, cancel := security.CancelOnEnter(parent)
+	defer cancel()
+	if ctx.Err() != nil {
+		t.Fatalf("the run should not start cancelled: %v", ctx.Err())
+	}
+	cancelParent()
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("the run should end with its parent")
+	}
+
+	ctx, cancel = security.CancelOnEnter(context.Background())
+	cancel()
+	if ctx.Err() == nil {
+		t.Error("cancel should end the run")
+	}
+}
@@ -0,0 +1,176 @@
+package eventlog_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"suppercommand/internal/managers/server"
+	"suppercommand/internal/types"
+)
+
+func TestServerEventLog(t *testing.T) {
+	manager := server.NewMockServerManager()
+	ctx := context.Background()
+
+	entries, err := manager.GetEventLog(ctx, &types.EventLogQuery{Log: "System", Count: 2})
+	if err != nil {
+		t.Fatalf("Failed to read event log: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(entries))
+	}
+
+	entries, err = manager.GetEventLog(ctx, &types.EventLogQuery{MaxLevel: types.EventLevelError})
+	if err != nil {
+		t.Fatalf("Failed to read event log: %v", err)
+	}
+	if len(entries) == 0 {
+		t.Fatal("Expected error events")
+	}
+	for _, entry := range entries {
+		if entry.Level > types.EventLevelError {
+			t.Errorf("Event %d has level %s, want error or above", entry.EventID, entry.Level)
+		}
+		if entry.Log != "System" {
+			t.Errorf("Event log should default to System, got %s", entry.Log)
+		}
+	}
+}
+
+func TestParseEventLevel(t *testing.T) {
+	tests := map[string]types.EventLevel{
+		"critical":    types.EventLevelCritical,
+		"Error":       types.EventLevelError,
+		"warn":        types.EventLevelWarning,
+		"information": types.EventLevelInformation,
+		"info":        types.EventLevelInformation,
+		"verbose":     types.EventLevelVerbose,
+	}
+	for name, want := range tests {
+		if got, err := types.ParseEventLevel(name); err != nil || got != want {
+			t.Errorf("ParseEventLevel(%q) = %v, %v, want %v", name, got, err, want)
+		}
+	}
+	if _, err := types.ParseEventLevel("loud"); err == nil {
+		t.Error("ParseEventLevel(\"loud\") should fail")
+	}
+}
+
+func TestParseJournalEvent(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		ok     bool
+		want   types.EventLogEntry
+		micros int64
+	}{
+		{
+			name:   "error from a unit",
+			line:   `{"__REALTIME_TIMESTAMP":"1700000000123456","PRIORITY":"3","SYSLOG_IDENTIFIER":"sshd","_SYSTEMD_UNIT":"ssh.service","MESSAGE":"Connection reset"}`,
+			ok:     true,
+			want:   types.EventLogEntry{Level: types.EventLevelError, Source: "sshd", Message: "Connection reset", Log: "System"},
+			micros: 1700000000123456,
+		},
+		{
+			name: "emergency folds into critical",
+			line: `{"PRIORITY":"0","_COMM":"systemd","MESSAGE":"Freezing execution"}`,
+			ok:   true,
+			want: types.EventLogEntry{Level: types.EventLevelCritical, Source: "systemd", Message: "Freezing execution", Log: "System"},
+		},
+		{
+			name: "warning",
+			line: `{"PRIORITY":"4","_SYSTEMD_UNIT":"cron.service","MESSAGE":"clock skew"}`,
+			ok:   true,
+			want: types.EventLogEntry{Level: types.EventLevelWarning, Source: "cron.service", Message: "clock skew", Log: "System"},
+		},
+		{
+			name: "debug is verbose",
+			line: `{"PRIORITY":"7","SYSLOG_IDENTIFIER":"dhclient","MESSAGE":"renewing lease"}`,
+			ok:   true,
+			want: types.EventLogEntry{Level: types.EventLevelVerbose, Source: "dhclient", Message: "renewing lease", Log: "System"},
+		},
+		{
+			name: "missing priority is information",
+			line: `{"SYSLOG_IDENTIFIER":"myapp","MESSAGE":"started"}`,
+			ok:   true,
+			want: types.EventLogEntry{Level: types.EventLevelInformation, Source: "myapp", Message: "started", Log: "System"},
+		},
+		{
+			name: "no source is the kernel",
+			line: `{"PRIORITY":"6","MESSAGE":"eth0: link up"}`,
+			ok:   true,
+			want: types.EventLogEntry{Level: types.EventLevelInformation, Source: "kernel", Message: "eth0: link up", Log: "System"},
+		},
+		{
+			name: "message that isn't UTF-8 is an array of bytes",
+			line: `{"PRIORITY":"6","SYSLOG_IDENTIFIER":"app","MESSAGE":[104,105,255]}`,
+			ok:   true,
+			want: types.EventLogEntry{Level: types.EventLevelInformation, Source: "app", Message: "hi\xff", Log: "System"},
+		},
+		{name: "malformed JSON", line: `{"PRIORITY":"3","MESSAGE":"cut off`},
+		{name: "blank line", line: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, ok := server.ParseJournalEvent(tt.line, "System")
+			if ok != tt.ok {
+				t.Fatalf("ParseJournalEvent() ok = %v, want %v", ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+			want := tt.want
+			if tt.micros != 0 {
+				want.Time = time.Unix(0, tt.micros*int64(time.Microsecond))
+			}
+			if !entry.Time.Equal(want.Time) {
+				t.Errorf("Time = %v, want %v", entry.Time, want.Time)
+			}
+			entry.Time, want.Time = time.Time{}, time.Time{}
+			if *entry != want {
+				t.Errorf("ParseJournalEvent() = %+v, want %+v", *entry, want)
+			}
+		})
+	}
+}
+
+func TestParseWinEvents(t *testing.T) {
+	output := []byte(`[
+		{"TimeCreated":"2024-03-02T10:00:00.5000000Z","Level":2,"ProviderName":"Service Control Manager","Id":7031,"Message":"The Print Spooler service terminated unexpectedly.\r\n"},
+		{"TimeCreated":"2024-03-02T09:59:00.0000000Z","Level":0,"ProviderName":"EventLog","Id":6005,"Message":"The Event log service was started."},
+		{"TimeCreated":"2024-03-02T09:58:00.0000000Z","Level":1,"ProviderName":"Kernel-Power","Id":41,"Message":"The system has rebooted."}
+	]`)
+
+	entries, err := server.ParseWinEvents(output, "System")
+	if err != nil {
+		t.Fatalf("ParseWinEvents() error = %v", err)
+	}
+	want := []types.EventLogEntry{
+		{Time: time.Date(2024, 3, 2, 10, 0, 0, 500000000, time.UTC), Level: types.EventLevelError, Source: "Service Control Manager", EventID: 7031, Message: "The Print Spooler service terminated unexpectedly.", Log: "System"},
+		{Time: time.Date(2024, 3, 2, 9, 59, 0, 0, time.UTC), Level: types.EventLevelInformation, Source: "EventLog", EventID: 6005, Message: "The Event log service was started.", Log: "System"},
+		{Time: time.Date(2024, 3, 2, 9, 58, 0, 0, time.UTC), Level: types.EventLevelCritical, Source: "Kernel-Power", EventID: 41, Message: "The system has rebooted.", Log: "System"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d events, want %d", len(entries), len(want))
+	}
+	for i, entry := range entries {
+		if !entry.Time.Equal(want[i].Time) {
+			t.Errorf("event %d: Time = %v, want %v", i, entry.Time, want[i].Time)
+		}
+		got := *entry
+		got.Time, want[i].Time = time.Time{}, time.Time{}
+		if got != want[i] {
+			t.Errorf("event %d = %+v, want %+v", i, got, want[i])
+		}
+	}
+
+	// No matching events is an empty array
+	if entries, err := server.ParseWinEvents([]byte("[]"), "Application"); err != nil || len(entries) != 0 {
+		t.Errorf("ParseWinEvents([]) = %v, %v, want no events", entries, err)
+	}
+	if _, err := server.ParseWinEvents([]byte("Get-WinEvent : The specified channel could not be found"), "Bogus"); err == nil {
+		t.Error("ParseWinEvents() should fail on output that isn't JSON")
+	}
+}
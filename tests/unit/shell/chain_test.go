@@ -0,0 +1,223 @@
+package shell_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/config"
+	"suppercommand/internal/monitoring"
+	"suppercommand/internal/shell"
+	pkgerrors "suppercommand/pkg/errors"
+
+	"github.com/fatih/color"
+)
+
+// statusCommand prints its label and exits with the given code:
+// "status <code> <label>"
+type statusCommand struct {
+	*commands.BaseCommand
+}
+
+func (s *statusCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	if len(args.Raw) != 2 {
+		return nil, errors.New("usage: " + s.Usage())
+	}
+	code, _ := strconv.Atoi(args.Raw[0])
+	return &commands.Result{Output: args.Raw[1] + "\n", ExitCode: code}, nil
+}
+
+func newExecutor(t *testing.T) (*shell.Executor, *commands.JobTable, func()) {
+	t.Helper()
+	// Commands are tracked in the history file in the home directory
+	home, err := ioutil.TempDir("", "supershell-home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldHome, oldProfile := os.Getenv("HOME"), os.Getenv("USERPROFILE")
+	os.Setenv("HOME", home)
+	os.Setenv("USERPROFILE", home)
+
+	logger := monitoring.NewLogger(config.MonitoringConfig{})
+	registry := commands.NewRegistry(logger)
+	status := &statusCommand{commands.NewBaseCommand("status", "Exit with a code", "status <code> <label>", nil, false)}
+	if err := registry.Register(status); err != nil {
+		t.Fatal(err)
+	}
+	jobs := commands.NewJobTable()
+	executor := shell.NewExecutor(config.ShellConfig{}, registry, monitoring.NewMonitor(config.MonitoringConfig{}, logger), logger, nil, jobs)
+	return executor, jobs, func() {
+		os.Setenv("HOME", oldHome)
+		os.Setenv("USERPROFILE", oldProfile)
+		os.RemoveAll(home)
+	}
+}
+
+func TestExecutor_Chains(t *testing.T) {
+	executor, _, cleanup := newExecutor(t)
+	defer cleanup()
+
+	tests := []struct {
+		input    string
+		ran      []string
+		exitCode int
+	}{
+		{"status 0 a && status 0 b", []string{"a", "b"}, 0},
+		{"status 1 a && status 0 b", []string{"a"}, 1},
+		{"status 0 a || status 0 b", []string{"a"}, 0},
+		{"status 1 a || status 0 b", []string{"a", "b"}, 0},
+		{"status 1 a ; status 2 b", []string{"a", "b"}, 2},
+		// && and || bind equally from the left: (a && b) || c
+		{"status 1 a && status 0 b || status 0 c", []string{"a", "c"}, 0},
+		{"status 0 a && status 3 b || status 0 c", []string{"a", "b", "c"}, 0},
+		// (a || b) && c, where a succeeding short-circuits b but not c
+		{"status 0 a || status 0 b && status 0 c", []string{"a", "c"}, 0},
+		{"status 1 a || status 1 b && status 0 c", []string{"a", "b"}, 1},
+		// ; starts over whatever came before
+		{"status 1 a && status 0 b ; status 0 c || status 0 d", []string{"a", "c"}, 0},
+		{`status 0 "x && y" && status 0 'p; q'`, []string{"x && y", "p; q"}, 0},
+	}
+	for _, tt := range tests {
+		result, err := executor.Execute(context.Background(), tt.input)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.input, err)
+		}
+		ran := strings.Split(strings.TrimSuffix(result.Output, "\n"), "\n")
+		if strings.Join(ran, ",") != strings.Join(tt.ran, ",") || result.ExitCode != tt.exitCode {
+			t.Errorf("%s: ran %q with exit code %d, want %q with %d", tt.input, ran, result.ExitCode, tt.ran, tt.exitCode)
+		}
+	}
+}
+
+func TestExecutor_ChainErrors(t *testing.T) {
+	executor, _, cleanup := newExecutor(t)
+	defer cleanup()
+	noColor := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = noColor }()
+
+	result, err := executor.Execute(context.Background(), "status 0 a &&")
+	if err != nil || result.ExitCode != 1 || result.Error == nil || !strings.Contains(result.Error.Error(), "&&") {
+		t.Errorf("a dangling && should be a syntax error, got %+v, %v", result, err)
+	}
+
+	// A command failing mid-chain is reported where it ran
+	result, err = executor.Execute(context.Background(), "status 0 a ; status ; status 0 b")
+	if err != nil || result.ExitCode != 0 || result.Error != nil {
+		t.Fatalf("the chain should carry on after a failed command, got %+v, %v", result, err)
+	}
+	if want := "a\n❌ usage: status <code> <label>\nb\n"; result.Output != want {
+		t.Errorf("chain output %q, want %q", result.Output, want)
+	}
+	result, err = executor.Execute(context.Background(), "status 0 a && status")
+	if err != nil || result.ExitCode != 1 || result.Error == nil || result.Output != "a\n" {
+		t.Errorf("the last command's error should be the chain's, got %+v, %v", result, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+	result, _ = executor.Execute(ctx, "status 0 a ; status 0 b")
+	if result.ExitCode == 0 || strings.Contains(result.Output, "a") {
+		t.Errorf("an interrupted chain should stop, got %+v", result)
+	}
+}
+
+func TestExecutor_BackgroundJobs(t *testing.T) {
+	executor, jobs, cleanup := newExecutor(t)
+	defer cleanup()
+	noColor := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = noColor }()
+
+	// The && list before & runs as one job, and the chain carries on at once
+	result, err := executor.Execute(context.Background(), "status 0 a && status 1 b & status 0 c")
+	if err != nil || result.ExitCode != 0 || result.Error != nil {
+		t.Fatalf("starting a job should succeed, got %+v, %v", result, err)
+	}
+	if want := "[1] started: status 0 a && status 1 b\nc\n"; result.Output != want {
+		t.Errorf("chain output %q, want %q", result.Output, want)
+	}
+
+	job, ok := jobs.Get(1)
+	if !ok {
+		t.Fatal("the job should be in the table")
+	}
+	select {
+	case <-job.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("the job didn't end")
+	}
+	if job.Output() != "a\nb\n" || job.ExitCode() != 1 || job.State() != commands.JobFailed {
+		t.Errorf("job ended %s with exit code %d and output %q", job.State(), job.ExitCode(), job.Output())
+	}
+
+	// A trailing & leaves nothing to run in the foreground
+	result, err = executor.Execute(context.Background(), "status 0 d &")
+	if err != nil || result.ExitCode != 0 || result.Output != "[2] started: status 0 d\n" {
+		t.Errorf("a trailing & should only start the job, got %+v, %v", result, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := executor.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs.List()) != 2 {
+		t.Errorf("both jobs should still be listed, have %d", len(jobs.List()))
+	}
+}
+
+func TestExecutor_UnknownCommandIsNotFound(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("relies on bash reporting unknown commands")
+	}
+	executor, _, cleanup := newExecutor(t)
+	defer cleanup()
+
+	result, err := executor.Execute(context.Background(), "supershell-no-such-command --flag")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ExitCode != 127 || result.Output != "" {
+		t.Errorf("an unknown command should exit 127 with no output, got %+v", result)
+	}
+	var notFound *pkgerrors.SuperShellError
+	if !errors.As(result.Error, &notFound) || notFound.Type != pkgerrors.ErrorTypeNotFound || notFound.Context["command"] != "supershell-no-such-command" {
+		t.Errorf("expected a not found error naming the command, got %v", result.Error)
+	}
+
+	// A command that exists and fails keeps its own exit code and output
+	result, err = executor.Execute(context.Background(), `sh -c "echo ran; exit 3"`)
+	if err != nil || result.ExitCode != 3 || result.Error != nil || !strings.Contains(result.Output, "ran") {
+		t.Errorf("a failing external command should keep its status, got %+v, %v", result, err)
+	}
+}
+
+func TestExecutor_SeparatesStderr(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh redirection")
+	}
+	executor, _, cleanup := newExecutor(t)
+	defer cleanup()
+
+	result, err := executor.Execute(context.Background(), `sh -c "echo data; echo oops >&2"`)
+	if err != nil || result.ExitCode != 0 {
+		t.Fatalf("unexpected failure %+v, %v", result, err)
+	}
+	if result.Output != "data\n" || result.Stderr != "oops\n" {
+		t.Errorf("expected data on stdout and oops on stderr, got %q and %q", result.Output, result.Stderr)
+	}
+
+	// A chain keeps the diagnostics of every command apart too
+	result, _ = executor.Execute(context.Background(), `sh -c "echo one >&2" ; status 0 a ; sh -c "echo two >&2"`)
+	if result.Output != "a\n" || result.Stderr != "one\ntwo\n" {
+		t.Errorf("chain output %q and stderr %q", result.Output, result.Stderr)
+	}
+}
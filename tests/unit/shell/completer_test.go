@@ -0,0 +1,39 @@
+package shell_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/commands/filesystem"
+	"suppercommand/internal/config"
+	"suppercommand/internal/monitoring"
+	"suppercommand/internal/shell"
+)
+
+func TestCompleter_RankedSuggestionsReplaceTheWord(t *testing.T) {
+	root := t.TempDir()
+	target := filepath.Join(root, "work", "projects")
+	if err := os.MkdirAll(target, 0755); err != nil {
+		t.Fatal(err)
+	}
+	jumps := filesystem.NewJumpDB(filepath.Join(root, "jumps.json"))
+	if err := jumps.Visit(target); err != nil {
+		t.Fatal(err)
+	}
+
+	registry := commands.NewRegistry(monitoring.NewLogger(config.MonitoringConfig{}))
+	registry.Register(filesystem.NewZCommandWithJumps(jumps))
+	registry.Register(filesystem.NewUndoCommandWithJournal(filesystem.NewJournal(root)))
+	completer := shell.NewCompleter(registry, monitoring.NewLogger(config.MonitoringConfig{}))
+
+	completions := completer.GetCompletions("z proj", len("z proj"))
+	if len(completions) != 1 || completions[0].Text != target {
+		t.Errorf("z proj should complete to %s, got %+v", target, completions)
+	}
+	// Other commands' suggestions still have to start with the word
+	if completions := completer.GetCompletions("undo cl", len("undo cl")); len(completions) != 1 || completions[0].Text != "clear" {
+		t.Errorf("undo cl should complete to clear, got %+v", completions)
+	}
+}
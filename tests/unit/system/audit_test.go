@@ -0,0 +1,58 @@
+package system_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/commands/system"
+	"suppercommand/internal/config"
+	"suppercommand/internal/security"
+)
+
+func TestAuditCommand(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit-command")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	audit := security.NewAuditLog(config.AuditConfig{Enabled: true, File: filepath.Join(dir, "audit.log")})
+	t.Cleanup(func() { audit.Close() })
+	audit.Record("ls -la", dir, 0, 0)
+	audit.Record("cat missing.txt", dir, 1, 0)
+	audit.Record("ls /tmp", dir, 0, 0)
+	cmd := system.NewAuditCommand(audit)
+
+	run := func(args ...string) *commands.Result {
+		t.Helper()
+		result, err := cmd.Execute(context.Background(), commands.ParseArguments(args))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	if output := run().Output; !strings.Contains(output, "ls -la") || !strings.Contains(output, "cat missing.txt") {
+		t.Errorf("audit should list the recorded commands:\n%s", output)
+	}
+	if output := run("--failed").Output; strings.Contains(output, "ls") || !strings.Contains(output, "cat missing.txt") {
+		t.Errorf("--failed should only list failures:\n%s", output)
+	}
+	if output := run("-n", "1", "--grep", "LS").Output; strings.Contains(output, "ls -la") || !strings.Contains(output, "ls /tmp") {
+		t.Errorf("-n 1 --grep LS should list only the last ls:\n%s", output)
+	}
+	if output := run("--json", "-n", "0").Output; strings.Count(output, "\n") != 3 || !strings.Contains(output, `"exit_code":1`) {
+		t.Errorf("--json should print one entry per line:\n%s", output)
+	}
+	if output := run("status").Output; !strings.Contains(output, "on") || !strings.Contains(output, "audit.log") {
+		t.Errorf("status should show recording and the file:\n%s", output)
+	}
+	if result := run("purge"); result.ExitCode != 1 || result.Error == nil {
+		t.Errorf("unknown subcommand should be a usage error, got %+v", result)
+	}
+}
@@ -0,0 +1,107 @@
+package system_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"suppercommand/internal/commands/system"
+)
+
+// writeSupply creates a fake sysfs power supply directory
+func writeSupply(t *testing.T, attributes map[string]string) string {
+	dir, err := ioutil.TempDir("", "BAT0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, value := range attributes {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(value+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestReadPowerSupplyEnergy(t *testing.T) {
+	dir := writeSupply(t, map[string]string{
+		"status":             "Discharging",
+		"capacity":           "40",
+		"energy_now":         "20000000",
+		"energy_full":        "50000000",
+		"energy_full_design": "62500000",
+		"power_now":          "10000000",
+		"manufacturer":       "ACME",
+		"model_name":         "5B10W",
+		"cycle_count":        "312",
+	})
+	defer os.RemoveAll(dir)
+
+	battery := system.ReadPowerSupply(dir)
+	if battery.Percent != 40 || battery.State != system.BatteryDischarging {
+		t.Errorf("battery = %+v", battery)
+	}
+	if battery.MinutesRemaining != 120 {
+		t.Errorf("MinutesRemaining = %d, want 120", battery.MinutesRemaining)
+	}
+	if battery.Health != 80 || battery.CycleCount != 312 || battery.Vendor != "ACME" {
+		t.Errorf("battery = %+v", battery)
+	}
+}
+
+func TestReadPowerSupplyCharge(t *testing.T) {
+	// Charge-based drivers without a capacity file, reporting a negative current
+	dir := writeSupply(t, map[string]string{
+		"status":      "Charging",
+		"charge_now":  "1500000",
+		"charge_full": "3000000",
+		"current_now": "-1000000",
+	})
+	defer os.RemoveAll(dir)
+
+	battery := system.ReadPowerSupply(dir)
+	if battery.Percent != 50 || battery.State != system.BatteryCharging {
+		t.Errorf("battery = %+v", battery)
+	}
+	if battery.MinutesRemaining != 90 {
+		t.Errorf("MinutesRemaining = %d, want 90", battery.MinutesRemaining)
+	}
+	if battery.Health != 0 {
+		t.Errorf("Health = %v, want 0 without a design capacity", battery.Health)
+	}
+}
+
+func TestParseWin32Battery(t *testing.T) {
+	data := []byte(`[
+		{"Name":"DELL 7FHHV","DeviceID":"1234","EstimatedChargeRemaining":64,"EstimatedRunTime":185,"BatteryStatus":1},
+		{"Name":"Secondary","DeviceID":"5678","EstimatedChargeRemaining":100,"EstimatedRunTime":71582788,"BatteryStatus":2}
+	]`)
+
+	batteries, err := system.ParseWin32Battery(data)
+	if err != nil {
+		t.Fatalf("ParseWin32Battery() error = %v", err)
+	}
+	if len(batteries) != 2 {
+		t.Fatalf("len(batteries) = %d, want 2", len(batteries))
+	}
+	if batteries[0].Percent != 64 || batteries[0].State != system.BatteryDischarging || batteries[0].MinutesRemaining != 185 {
+		t.Errorf("batteries[0] = %+v", batteries[0])
+	}
+	if batteries[1].State != system.BatteryNotCharging || batteries[1].MinutesRemaining != 0 {
+		t.Errorf("batteries[1] = %+v", batteries[1])
+	}
+
+	if _, err := system.ParseWin32Battery([]byte("not json")); err == nil {
+		t.Error("ParseWin32Battery() should fail on invalid JSON")
+	}
+}
+
+func TestParsePowerScheme(t *testing.T) {
+	output := "Power Scheme GUID: 381b4222-f694-41f0-9685-ff5bb260df2e  (Balanced)\r\n"
+	if got := system.ParsePowerScheme(output); got != "Balanced" {
+		t.Errorf("ParsePowerScheme() = %q, want \"Balanced\"", got)
+	}
+	if got := system.ParsePowerScheme("garbage"); got != "" {
+		t.Errorf("ParsePowerScheme(garbage) = %q, want \"\"", got)
+	}
+}
@@ -0,0 +1,56 @@
+package system_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/commands/filesystem"
+	"suppercommand/internal/commands/system"
+	"suppercommand/internal/config"
+	"suppercommand/internal/monitoring"
+)
+
+func TestSummarizeDurations(t *testing.T) {
+	stats := system.SummarizeDurations([]time.Duration{
+		4 * time.Millisecond, 2 * time.Millisecond, 6 * time.Millisecond, 8 * time.Millisecond,
+	})
+
+	if stats.Min != 2*time.Millisecond || stats.Max != 8*time.Millisecond {
+		t.Errorf("unexpected min/max: %v/%v", stats.Min, stats.Max)
+	}
+	if stats.Mean != 5*time.Millisecond || stats.Median != 5*time.Millisecond {
+		t.Errorf("unexpected mean/median: %v/%v", stats.Mean, stats.Median)
+	}
+	// Sample standard deviation of 2, 4, 6, 8 is sqrt(20/3) ≈ 2.582ms
+	if stats.StdDev.Round(time.Microsecond) != 2582*time.Microsecond {
+		t.Errorf("unexpected stddev: %v", stats.StdDev)
+	}
+
+	if single := system.SummarizeDurations([]time.Duration{time.Second}); single.StdDev != 0 || single.Median != time.Second {
+		t.Errorf("unexpected single-run stats %+v", single)
+	}
+}
+
+func TestBenchmark_SuppressesOutput(t *testing.T) {
+	registry := commands.NewRegistry(monitoring.NewLogger(config.MonitoringConfig{}))
+	if err := registry.Register(filesystem.NewEchoCommand()); err != nil {
+		t.Fatal(err)
+	}
+	benchmark := system.NewBenchmarkCommand(registry)
+
+	result, err := benchmark.Execute(context.Background(), commands.ParseArguments([]string{"--runs", "3", "echo", "marker-text"}))
+	if err != nil || result.ExitCode != 0 {
+		t.Fatalf("benchmark failed: err=%v output=%s", err, result.Output)
+	}
+	if strings.Count(result.Output, "marker-text") != 1 || strings.Count(result.Output, "Run ") != 3 {
+		t.Errorf("expected 3 runs without command output:\n%s", result.Output)
+	}
+
+	result, _ = benchmark.Execute(context.Background(), commands.ParseArguments([]string{"--runs", "2", "--show-output", "--compare", "echo marker-text", "echo other"}))
+	if result.ExitCode != 0 || !strings.Contains(result.Output, "\nmarker-text\n") || !strings.Contains(result.Output, "COMPARISON") {
+		t.Errorf("expected compared runs with output:\n%s", result.Output)
+	}
+}
@@ -0,0 +1,162 @@
+package system_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/commands/system"
+	"suppercommand/internal/security"
+)
+
+// withHome points the home directory at a fresh temporary directory
+func withHome(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "supershell-home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldHome, oldProfile := os.Getenv("HOME"), os.Getenv("USERPROFILE")
+	os.Setenv("HOME", dir)
+	os.Setenv("USERPROFILE", dir)
+	return dir, func() {
+		os.Setenv("HOME", oldHome)
+		os.Setenv("USERPROFILE", oldProfile)
+		os.RemoveAll(dir)
+	}
+}
+
+func writeBookmarks(t *testing.T, home string, names ...string) {
+	var bookmarks []system.Bookmark
+	for _, name := range names {
+		bookmarks = append(bookmarks, system.Bookmark{Name: name, Command: "echo " + name})
+	}
+	data, _ := json.Marshal(bookmarks)
+	if err := ioutil.WriteFile(filepath.Join(home, ".supershell_bookmarks.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func runConfig(t *testing.T, scheduler *system.Scheduler, args ...string) *commands.Result {
+	result, err := system.NewConfigCommand(scheduler).Execute(context.Background(), commands.ParseArguments(args))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestConfig_ExportImportMerge(t *testing.T) {
+	oldPassword := os.Getenv(security.MasterPasswordEnv)
+	os.Setenv(security.MasterPasswordEnv, "correct horse")
+	defer os.Setenv(security.MasterPasswordEnv, oldPassword)
+
+	bundleDir, err := ioutil.TempDir("", "supershell-bundle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(bundleDir)
+	bundle := filepath.Join(bundleDir, "bundle.json")
+
+	// Source machine
+	source, cleanup := withHome(t)
+	writeBookmarks(t, source, "deploy", "backup")
+	if err := system.SaveFavorites(filepath.Join(source, ".supershell", "favorites.json"), []system.Favorite{{Label: "dns", Command: "sniff --port 53"}}); err != nil {
+		t.Fatal(err)
+	}
+	scheduler := system.NewScheduler(nil)
+	if _, err := scheduler.AddTask("0 2 * * *", "fastcp-backup ./data bucket"); err != nil {
+		t.Fatal(err)
+	}
+	store, err := security.OpenCredentialStore(security.DefaultCredentialStorePath(), "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Add(&security.Credential{Name: "s3", Type: security.CredentialTypeKey, Fields: map[string]string{"key": "top-secret"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if result := runConfig(t, scheduler, "export", bundle); result.ExitCode != 0 {
+		cleanup()
+		t.Fatalf("export failed:\n%s", result.Output)
+	}
+	cleanup()
+
+	data, err := ioutil.ReadFile(bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "top-secret") {
+		t.Fatal("bundle contains a raw secret")
+	}
+
+	// Target machine with one overlapping bookmark, a different credential and the same task
+	target, cleanup := withHome(t)
+	defer cleanup()
+	writeBookmarks(t, target, "deploy", "local")
+	scheduler = system.NewScheduler(nil)
+	scheduler.AddTask("0 2 * * *", "fastcp-backup ./data bucket")
+	store, err = security.OpenCredentialStore(security.DefaultCredentialStorePath(), "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(&security.Credential{Name: "ssh", Type: security.CredentialTypeSSH, Fields: map[string]string{"password": "x"}})
+
+	result := runConfig(t, scheduler, "import", bundle)
+	if result.ExitCode != 0 {
+		t.Fatalf("import failed:\n%s", result.Output)
+	}
+
+	data, _ = ioutil.ReadFile(filepath.Join(target, ".supershell_bookmarks.json"))
+	var bookmarks []system.Bookmark
+	json.Unmarshal(data, &bookmarks)
+	if len(bookmarks) != 3 {
+		t.Errorf("expected 3 merged bookmarks, got %+v", bookmarks)
+	}
+
+	if favorites, _ := system.LoadFavorites(filepath.Join(target, ".supershell", "favorites.json")); len(favorites) != 1 || favorites[0].Label != "dns" {
+		t.Errorf("favorites not imported: %+v", favorites)
+	}
+
+	if tasks, _ := scheduler.LoadTasks(); len(tasks) != 1 {
+		t.Errorf("duplicate schedule imported: %+v", tasks)
+	}
+
+	store, err = security.OpenCredentialStore(security.DefaultCredentialStorePath(), "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cred, err := store.Get("s3"); err != nil || cred.Secret() != "top-secret" {
+		t.Errorf("credential not merged: %v", err)
+	}
+	if _, err := store.Get("ssh"); err != nil {
+		t.Errorf("local credential lost: %v", err)
+	}
+
+	// --replace drops local-only bookmarks
+	if result := runConfig(t, scheduler, "import", bundle, "--replace"); result.ExitCode != 0 {
+		t.Fatalf("replace failed:\n%s", result.Output)
+	}
+	data, _ = ioutil.ReadFile(filepath.Join(target, ".supershell_bookmarks.json"))
+	bookmarks = nil
+	json.Unmarshal(data, &bookmarks)
+	if len(bookmarks) != 2 {
+		t.Errorf("expected bundle bookmarks only after --replace, got %+v", bookmarks)
+	}
+}
+
+func TestConfig_ImportRejectsNewerVersion(t *testing.T) {
+	_, cleanup := withHome(t)
+	defer cleanup()
+
+	file := filepath.Join(os.Getenv("HOME"), "bundle.json")
+	ioutil.WriteFile(file, []byte(`{"format":"supershell-config","version":99}`), 0644)
+
+	result := runConfig(t, nil, "import", file)
+	if result.ExitCode == 0 || !strings.Contains(result.Output, "not supported") {
+		t.Errorf("expected version error:\n%s", result.Output)
+	}
+}
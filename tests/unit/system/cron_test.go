@@ -0,0 +1,85 @@
+package system_test
+
+import (
+	"testing"
+	"time"
+
+	"suppercommand/internal/commands/system"
+)
+
+func TestParseCronSchedule_Invalid(t *testing.T) {
+	invalid := []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+	}
+
+	for _, expr := range invalid {
+		if _, err := system.ParseCronSchedule(expr); err == nil {
+			t.Errorf("ParseCronSchedule(%q) should fail", expr)
+		}
+	}
+}
+
+func TestCronSchedule_Matches(t *testing.T) {
+	tests := []struct {
+		expr string
+		time time.Time
+		want bool
+	}{
+		{"0 2 * * *", time.Date(2024, 3, 10, 2, 0, 0, 0, time.UTC), true},
+		{"0 2 * * *", time.Date(2024, 3, 10, 2, 1, 0, 0, time.UTC), false},
+		{"*/15 * * * *", time.Date(2024, 3, 10, 9, 45, 30, 0, time.UTC), true},
+		{"*/15 * * * *", time.Date(2024, 3, 10, 9, 50, 0, 0, time.UTC), false},
+		{"0 9 * * 1-5", time.Date(2024, 3, 11, 9, 0, 0, 0, time.UTC), true},  // Monday
+		{"0 9 * * 1-5", time.Date(2024, 3, 10, 9, 0, 0, 0, time.UTC), false}, // Sunday
+		{"0 0 * * 7", time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), true},    // 7 is Sunday
+		{"0 0 1 * 1", time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC), true},    // dom OR dow
+		{"@daily", time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), true},
+		{"30 8 1,15 * *", time.Date(2024, 3, 15, 8, 30, 0, 0, time.UTC), true},
+	}
+
+	for _, tt := range tests {
+		schedule, err := system.ParseCronSchedule(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseCronSchedule(%q) error = %v", tt.expr, err)
+		}
+		if got := schedule.Matches(tt.time); got != tt.want {
+			t.Errorf("%q.Matches(%v) = %v, want %v", tt.expr, tt.time, got, tt.want)
+		}
+	}
+}
+
+func TestCronSchedule_Next(t *testing.T) {
+	from := time.Date(2024, 3, 10, 2, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"0 2 * * *", time.Date(2024, 3, 11, 2, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 3, 10, 2, 15, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		schedule, err := system.ParseCronSchedule(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseCronSchedule(%q) error = %v", tt.expr, err)
+		}
+		if got := schedule.Next(from); !got.Equal(tt.want) {
+			t.Errorf("%q.Next() = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+
+	never, _ := system.ParseCronSchedule("0 0 31 2 *")
+	if got := never.Next(from); !got.IsZero() {
+		t.Errorf("Next() for impossible date = %v, want zero time", got)
+	}
+}
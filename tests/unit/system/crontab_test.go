@@ -0,0 +1,111 @@
+package system_test
+
+import (
+	"testing"
+	"time"
+
+	"suppercommand/internal/commands/system"
+)
+
+func TestParseCrontab(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	data := "SHELL=/bin/sh\n" +
+		"# m h dom mon dow user command\n" +
+		"17 * * * * root cd / && run-parts --report /etc/cron.hourly\n" +
+		"@reboot root /usr/local/bin/warmup.sh\n" +
+		"0 3 * * 0 backup /opt/backup/run --full\n" +
+		"bad line\n"
+
+	tasks := system.ParseCrontab(data, "/etc/crontab", "", now)
+	if len(tasks) != 3 {
+		t.Fatalf("len(tasks) = %d, want 3", len(tasks))
+	}
+
+	hourly := tasks[0]
+	if hourly.ID != "/etc/crontab:3" || hourly.User != "root" || hourly.Name != "run-parts" {
+		t.Errorf("tasks[0] = %+v", hourly)
+	}
+	if hourly.Command != "cd / && run-parts --report /etc/cron.hourly" {
+		t.Errorf("tasks[0].Command = %q", hourly.Command)
+	}
+	if want := time.Date(2024, 3, 10, 12, 17, 0, 0, time.UTC); !hourly.NextRun.Equal(want) {
+		t.Errorf("tasks[0].NextRun = %v, want %v", hourly.NextRun, want)
+	}
+
+	if tasks[1].Schedule != "@reboot" || tasks[1].Status != "at boot" || !tasks[1].NextRun.IsZero() {
+		t.Errorf("tasks[1] = %+v", tasks[1])
+	}
+	if tasks[2].User != "backup" || tasks[2].Name != "run" || tasks[2].Schedule != "0 3 * * 0" {
+		t.Errorf("tasks[2] = %+v", tasks[2])
+	}
+
+	// A user's crontab has no user column
+	tasks = system.ParseCrontab("*/5 * * * * /home/alice/bin/sync\n", "/var/spool/cron/crontabs/alice", "alice", now)
+	if len(tasks) != 1 || tasks[0].User != "alice" || tasks[0].Command != "/home/alice/bin/sync" {
+		t.Fatalf("tasks = %+v", tasks)
+	}
+}
+
+func TestParseTimerList(t *testing.T) {
+	output := "Mon 2024-03-11 00:00:00 UTC 11h left Sun 2024-03-10 00:00:01 UTC 12h ago logrotate.timer logrotate.service\n" +
+		"n/a                         n/a      Sun 2024-03-10 09:00:00 UTC 3h ago  fstrim.timer    fstrim.service\n" +
+		"n/a n/a n/a n/a e2scrub_all.timer e2scrub_all.service\n"
+
+	tasks := system.ParseTimerList(output, time.UTC)
+	if len(tasks) != 3 {
+		t.Fatalf("len(tasks) = %d, want 3", len(tasks))
+	}
+
+	if tasks[0].ID != "logrotate.timer" || tasks[0].Name != "logrotate" || tasks[0].Command != "logrotate.service" {
+		t.Errorf("tasks[0] = %+v", tasks[0])
+	}
+	if !tasks[0].NextRun.Equal(time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)) ||
+		!tasks[0].LastRun.Equal(time.Date(2024, 3, 10, 0, 0, 1, 0, time.UTC)) {
+		t.Errorf("tasks[0] next = %v last = %v", tasks[0].NextRun, tasks[0].LastRun)
+	}
+
+	if !tasks[1].NextRun.IsZero() || tasks[1].LastRun.IsZero() || tasks[1].Status != "inactive" {
+		t.Errorf("tasks[1] = %+v", tasks[1])
+	}
+	if !tasks[2].LastRun.IsZero() || tasks[2].Command != "e2scrub_all.service" {
+		t.Errorf("tasks[2] = %+v", tasks[2])
+	}
+}
+
+func TestParseSchtasksCSV(t *testing.T) {
+	header := `"HostName","TaskName","Next Run Time","Status","Logon Mode","Last Run Time","Last Result","Author",` +
+		`"Task To Run","Start In","Comment","Scheduled Task State","Idle Time","Power Management","Run As User",` +
+		`"Delete Task If Not Rescheduled","Stop Task If Runs X Hours and X Mins","Schedule","Schedule Type"` + "\r\n"
+	output := header +
+		`"WS01","\Backup","3/11/2024 2:00:00 AM","Ready","Interactive/Background","3/10/2024 2:00:00 AM","0","admin",` +
+		`"C:\Tools\backup.exe /quiet","N/A","","Enabled","Disabled","","SYSTEM","Disabled","72:00:00","","Daily "` + "\r\n" +
+		`"WS01","\Backup","3/11/2024 2:00:00 AM","Ready","Interactive/Background","3/10/2024 2:00:00 AM","0","admin",` +
+		`"C:\Tools\backup.exe /quiet","N/A","","Enabled","Disabled","","SYSTEM","Disabled","72:00:00","","At logon time"` + "\r\n" +
+		header +
+		`"WS01","\Microsoft\Windows\Defrag\ScheduledDefrag","N/A","Disabled","Interactive/Background","11/30/1999 12:00:00 AM","267011","Microsoft",` +
+		`"%windir%\system32\defrag.exe -c","N/A","","Disabled","","","SYSTEM","Disabled","72:00:00","","Weekly"` + "\r\n"
+
+	tasks, err := system.ParseSchtasksCSV(output)
+	if err != nil {
+		t.Fatalf("ParseSchtasksCSV() error = %v", err)
+	}
+	if len(tasks) != 2 {
+		t.Fatalf("len(tasks) = %d, want 2", len(tasks))
+	}
+
+	backup := tasks[0]
+	if backup.ID != `\Backup` || backup.Name != "Backup" || backup.User != "SYSTEM" || backup.Status != "Ready" {
+		t.Errorf("tasks[0] = %+v", backup)
+	}
+	if backup.Command != `C:\Tools\backup.exe /quiet` || backup.Schedule != "Daily, At logon time" {
+		t.Errorf("tasks[0] command = %q schedule = %q", backup.Command, backup.Schedule)
+	}
+	if want := time.Date(2024, 3, 11, 2, 0, 0, 0, time.Local); !backup.NextRun.Equal(want) {
+		t.Errorf("tasks[0].NextRun = %v, want %v", backup.NextRun, want)
+	}
+
+	defrag := tasks[1]
+	if defrag.Name != "ScheduledDefrag" || defrag.Status != "Disabled" || !defrag.NextRun.IsZero() || !defrag.LastRun.IsZero() {
+		t.Errorf("tasks[1] = %+v", defrag)
+	}
+}
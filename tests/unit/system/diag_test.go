@@ -0,0 +1,42 @@
+package system_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/commands/system"
+)
+
+func TestDiagTerminal(t *testing.T) {
+	diag := system.NewDiagCommand()
+
+	result, err := diag.Execute(context.Background(), commands.ParseArguments([]string{"terminal"}))
+	if err != nil || result.ExitCode != 0 {
+		t.Fatalf("diag terminal failed: %v %+v", err, result)
+	}
+	for _, want := range []string{"Terminal:", "Colors:", "UTF-8:", "Window size:", "RENDERING TEST", "Box drawing:", "DEFAULTS"} {
+		if !strings.Contains(result.Output, want) {
+			t.Errorf("expected %q in:\n%s", want, result.Output)
+		}
+	}
+
+	ctx := commands.WithOutputOptions(context.Background(), commands.OutputOptions{Format: commands.FormatJSON})
+	result, _ = diag.Execute(ctx, commands.ParseArguments([]string{"terminal"}))
+	var report map[string]interface{}
+	if err := json.Unmarshal([]byte(result.Output), &report); err != nil {
+		t.Fatalf("expected JSON, got %q: %v", result.Output, err)
+	}
+	if _, ok := report["colors"].(string); !ok || report["utf8"] == nil {
+		t.Errorf("unexpected report %v", report)
+	}
+
+	for _, raw := range [][]string{{}, {"network"}, {"terminal", "extra"}} {
+		result, _ := diag.Execute(context.Background(), commands.ParseArguments(raw))
+		if result.ExitCode != 1 || !strings.HasPrefix(result.Output, "Usage: ") {
+			t.Errorf("%q: expected a usage error, got %d %q", raw, result.ExitCode, result.Output)
+		}
+	}
+}
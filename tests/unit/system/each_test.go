@@ -0,0 +1,159 @@
+package system_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/commands/system"
+	"suppercommand/internal/config"
+	"suppercommand/internal/monitoring"
+)
+
+// recordCommand prints its arguments, failing for the argument "bad", and
+// records how many runs overlapped: "record <args...>"
+type recordCommand struct {
+	*commands.BaseCommand
+	mu      sync.Mutex
+	runs    []string
+	running int
+	overlap int
+}
+
+func (r *recordCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	r.mu.Lock()
+	r.runs = append(r.runs, strings.Join(args.Raw, "|"))
+	r.running++
+	if r.running > r.overlap {
+		r.overlap = r.running
+	}
+	r.mu.Unlock()
+
+	time.Sleep(20 * time.Millisecond)
+	r.mu.Lock()
+	r.running--
+	r.mu.Unlock()
+
+	for _, arg := range args.Raw {
+		if arg == "bad" {
+			return &commands.Result{Output: "failed " + arg + "\n", ExitCode: 3}, nil
+		}
+	}
+	return &commands.Result{Output: strings.Join(args.Raw, " ") + "\n"}, nil
+}
+
+func newEach(t *testing.T, input string) (*system.EachCommand, *recordCommand, context.Context) {
+	t.Helper()
+	registry := commands.NewRegistry(monitoring.NewLogger(config.MonitoringConfig{}))
+	record := &recordCommand{BaseCommand: commands.NewBaseCommand("record", "Record runs", "record <args...>", nil, false)}
+	if err := registry.Register(record); err != nil {
+		t.Fatal(err)
+	}
+	ctx := commands.WithInputReader(context.Background(), strings.NewReader(input))
+	ctx = commands.WithErrorWriter(ctx, ioutil.Discard)
+	return system.NewEachCommand(registry), record, ctx
+}
+
+func TestExpandItems(t *testing.T) {
+	tests := []struct {
+		words []string
+		items []string
+		want  string
+	}{
+		{[]string{"cat", "{}"}, []string{"my file.log"}, "cat|my file.log"},
+		{[]string{"cp", "{}", "{}.bak"}, []string{"a"}, "cp|a|a.bak"},
+		{[]string{"cat", "-n"}, []string{"a", "b"}, "cat|-n|a|b"},
+		{[]string{"echo", "[{}]"}, []string{"a", "b"}, "echo|[a b]"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(system.ExpandItems(tt.words, tt.items), "|"); got != tt.want {
+			t.Errorf("ExpandItems(%q, %q) = %q, want %q", tt.words, tt.items, got, tt.want)
+		}
+	}
+}
+
+func TestReadItems(t *testing.T) {
+	items, err := system.ReadItems(strings.NewReader("a.log\r\n\n  \nb c.log\n"), "")
+	if err != nil || strings.Join(items, "|") != "a.log|b c.log" {
+		t.Errorf("lines = %q, %v", items, err)
+	}
+	items, _ = system.ReadItems(strings.NewReader("web01, web02,,db01\n"), ",")
+	if strings.Join(items, "|") != "web01|web02|db01" {
+		t.Errorf("split items = %q", items)
+	}
+}
+
+func TestEach_RunsInInputOrder(t *testing.T) {
+	each, record, ctx := newEach(t, "one\ntwo\nthree\nfour\nfive\n")
+	result, err := each.Execute(ctx, commands.ParseArguments([]string{"--parallel", "3", "record", "-v", "{}"}))
+	if err != nil || result.ExitCode != 0 {
+		t.Fatalf("each failed: %v %+v", err, result)
+	}
+	if result.Output != "-v one\n-v two\n-v three\n-v four\n-v five\n" {
+		t.Errorf("unexpected output %q", result.Output)
+	}
+	if record.overlap < 2 || record.overlap > 3 {
+		t.Errorf("expected up to 3 runs at a time, saw %d", record.overlap)
+	}
+	if !strings.Contains(result.Stderr, "5 runs succeeded") {
+		t.Errorf("unexpected summary %q", result.Stderr)
+	}
+}
+
+func TestEach_BatchesAndFailures(t *testing.T) {
+	each, record, ctx := newEach(t, "a\nb\nbad\nc\nd\n")
+	result, _ := each.Execute(ctx, commands.ParseArguments([]string{"--batch", "2", "record"}))
+	if strings.Join(record.runs, ";") != "a|b;bad|c;d" {
+		t.Errorf("unexpected runs %q", record.runs)
+	}
+	if result.ExitCode != 1 || result.Error == nil || !strings.Contains(result.Stderr, "record bad c: exit code 3") {
+		t.Errorf("expected the failed batch to be reported, got %+v", result)
+	}
+
+	each, record, ctx = newEach(t, "a\nbad\nc\nd\n")
+	result, _ = each.Execute(ctx, commands.ParseArguments([]string{"--fail-fast", "record", "{}"}))
+	if len(record.runs) != 2 || !strings.Contains(result.Stderr, "2 not started") {
+		t.Errorf("expected --fail-fast to stop after bad, ran %q: %q", record.runs, result.Stderr)
+	}
+}
+
+func TestEach_ReadsFile(t *testing.T) {
+	file, err := ioutil.TempFile("", "each-items")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	file.WriteString("x,y")
+	file.Close()
+
+	each, record, ctx := newEach(t, "")
+	result, _ := each.Execute(ctx, commands.ParseArguments([]string{"-f", file.Name(), "-s", ",", "record item={}"}))
+	if result.ExitCode != 0 || strings.Join(record.runs, ";") != "item=x;item=y" {
+		t.Errorf("unexpected runs %q: %+v", record.runs, result)
+	}
+}
+
+func TestEach_RejectsBadArguments(t *testing.T) {
+	for _, args := range [][]string{
+		{},
+		{"--parallel", "0", "record"},
+		{"--batch", "0", "record"},
+	} {
+		each, _, ctx := newEach(t, "a\n")
+		result, _ := each.Execute(ctx, commands.ParseArguments(args))
+		if result.ExitCode != 1 || !strings.HasPrefix(result.Output, "Usage: ") {
+			t.Errorf("%q: expected a usage error, got %+v", args, result)
+		}
+	}
+
+	each, _, ctx := newEach(t, "a\n")
+	result, _ := each.Execute(ctx, commands.ParseArguments([]string{"nosuchcommand", "{}"}))
+	if result.ExitCode != 1 || result.Error == nil || !strings.Contains(result.Error.Error(), "unknown command") {
+		t.Errorf("expected an unknown command error, got %+v", result)
+	}
+}
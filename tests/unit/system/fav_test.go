@@ -0,0 +1,113 @@
+package system_test
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/commands/filesystem"
+	"suppercommand/internal/commands/system"
+	"suppercommand/internal/config"
+	"suppercommand/internal/monitoring"
+)
+
+func TestValidFavoriteLabel(t *testing.T) {
+	for label, want := range map[string]bool{
+		"nightly-backup": true,
+		"sniff.dns_53":   true,
+		"2fa":            true,
+		"42":             false,
+		"":               false,
+		"-flag":          false,
+		"two words":      false,
+	} {
+		if got := system.ValidFavoriteLabel(label); got != want {
+			t.Errorf("ValidFavoriteLabel(%q) = %v, want %v", label, got, want)
+		}
+	}
+}
+
+func TestJoinCommandWords(t *testing.T) {
+	if got := system.JoinCommandWords([]string{"sniff -i eth0 port 53"}); got != "sniff -i eth0 port 53" {
+		t.Errorf("single word should be kept as a line, got %q", got)
+	}
+	line := system.JoinCommandWords([]string{"fastcp-backup", "C:\\My Files", "--exclude", "*.tmp"})
+	want := []string{"fastcp-backup", "C:\\My Files", "--exclude", "*.tmp"}
+	if words := commands.SplitCommandLine(line); !reflect.DeepEqual(words, want) {
+		t.Errorf("JoinCommandWords() = %q, which splits to %q", line, words)
+	}
+}
+
+func TestFindFavorite(t *testing.T) {
+	favorites := []system.Favorite{{Label: "a"}, {Label: "b"}}
+	cases := map[string]int{"1": 0, "2": 1, "b": 1, "3": -1, "0": -1, "c": -1}
+	for selector, want := range cases {
+		if got := system.FindFavorite(favorites, selector); got != want {
+			t.Errorf("FindFavorite(%q) = %d, want %d", selector, got, want)
+		}
+	}
+}
+
+func TestFavCommand(t *testing.T) {
+	home, cleanup := withHome(t)
+	defer cleanup()
+
+	registry := commands.NewRegistry(monitoring.NewLogger(config.MonitoringConfig{}))
+	if err := registry.Register(filesystem.NewEchoCommand()); err != nil {
+		t.Fatal(err)
+	}
+	fav := system.NewFavCommand(registry)
+	run := func(args ...string) *commands.Result {
+		result, err := fav.Execute(context.Background(), commands.ParseArguments(args))
+		if err != nil {
+			t.Fatalf("fav %v: %v", args, err)
+		}
+		return result
+	}
+
+	if result := run("add", "greet", "echo", "hello world"); result.ExitCode != 0 {
+		t.Fatalf("add failed: %s", result.Output)
+	}
+	if result := run("add", "greet", "echo", "again"); result.ExitCode == 0 {
+		t.Error("adding a duplicate label should fail")
+	}
+	if result := run("add", "loop", "fav", "run", "1"); result.ExitCode == 0 {
+		t.Error("a favorite running fav should be rejected")
+	}
+	run("add", "bye", "echo goodbye")
+
+	list := run().Output
+	if !strings.Contains(list, "1.") || !strings.Contains(list, `echo "hello world"`) || !strings.Contains(list, "2.") {
+		t.Errorf("unexpected menu:\n%s", list)
+	}
+
+	result := run("run", "1")
+	if result.ExitCode != 0 || !strings.Contains(result.Output, "hello world") {
+		t.Errorf("run 1: exit %d, output:\n%s", result.ExitCode, result.Output)
+	}
+	if result := run("run", "9"); result.ExitCode == 0 {
+		t.Error("running a missing favorite should fail")
+	}
+
+	if result := run("edit", "bye", "--label", "farewell", "echo", "see you"); result.ExitCode != 0 {
+		t.Fatalf("edit failed: %s", result.Output)
+	}
+	if got := fav.CompleteArguments([]string{"run", ""}); !reflect.DeepEqual(got, []string{"greet", "farewell"}) {
+		t.Errorf("label completions = %v", got)
+	}
+	if result := run("run", "farewell"); !strings.Contains(result.Output, "see you") {
+		t.Errorf("edited favorite ran:\n%s", result.Output)
+	}
+
+	run("remove", "1")
+	favorites, err := system.LoadFavorites(filepath.Join(home, ".supershell", "favorites.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(favorites) != 1 || favorites[0].Label != "farewell" || favorites[0].UseCount != 1 {
+		t.Errorf("unexpected saved favorites %+v", favorites)
+	}
+}
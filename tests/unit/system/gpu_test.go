@@ -0,0 +1,89 @@
+package system_test
+
+import (
+	"testing"
+
+	"suppercommand/internal/commands/system"
+)
+
+func TestParseLspciGPUs(t *testing.T) {
+	output := `0000:00:02.0 "VGA compatible controller" "Intel Corporation" "CoffeeLake-S GT2 [UHD Graphics 630]" -r02 "Dell" "Device 0869"
+0000:00:14.0 "USB controller" "Intel Corporation" "Cannon Lake PCH USB 3.1 xHCI Host Controller" -r10 "Dell" "Device 0869"
+0000:01:00.0 "3D controller" "NVIDIA Corporation" "TU117M [GeForce GTX 1650 Mobile / Max-Q]" -ra1 "Dell" "Device 0869"
+0000:03:00.0 "Display controller" "Advanced Micro Devices, Inc. [AMD/ATI]" "Navi 21" -rc1 "" ""
+`
+	gpus := system.ParseLspciGPUs(output)
+	if len(gpus) != 3 {
+		t.Fatalf("len(gpus) = %d, want 3", len(gpus))
+	}
+
+	if gpus[0].Name != "Intel UHD Graphics 630" || gpus[0].Vendor != "Intel" || gpus[0].BusID != "0000:00:02.0" {
+		t.Errorf("gpus[0] = %+v", gpus[0])
+	}
+	if gpus[1].Name != "NVIDIA GeForce GTX 1650 Mobile / Max-Q" || gpus[1].Vendor != "NVIDIA" {
+		t.Errorf("gpus[1] = %+v", gpus[1])
+	}
+	// Without a marketing name the chip name is used
+	if gpus[2].Name != "AMD Navi 21" || gpus[2].Vendor != "AMD" {
+		t.Errorf("gpus[2] = %+v", gpus[2])
+	}
+}
+
+func TestParseNvidiaSmiAndMerge(t *testing.T) {
+	output := "00000000:01:00.0, NVIDIA GeForce GTX 1650, 4096, 512, 535.54.03, 7\n" +
+		"00000000:02:00.0, NVIDIA A100, 40960, 0, 535.54.03, [N/A]\n"
+
+	nvidia := system.ParseNvidiaSmi(output)
+	if len(nvidia) != 2 {
+		t.Fatalf("len(nvidia) = %d, want 2", len(nvidia))
+	}
+	if nvidia[0].VRAM != 4096<<20 || nvidia[0].VRAMUsed != 512<<20 || nvidia[0].Version != "535.54.03" {
+		t.Errorf("nvidia[0] = %+v", nvidia[0])
+	}
+	if nvidia[0].Utilization == nil || *nvidia[0].Utilization != 7 {
+		t.Errorf("nvidia[0].Utilization = %v, want 7", nvidia[0].Utilization)
+	}
+	if nvidia[1].Utilization != nil {
+		t.Errorf("nvidia[1].Utilization = %v, want nil for [N/A]", *nvidia[1].Utilization)
+	}
+
+	gpus := []system.GPUInfo{
+		{Name: "Intel UHD Graphics 630", Vendor: "Intel", BusID: "0000:00:02.0", Driver: "i915"},
+		{Name: "NVIDIA GeForce GTX 1650 Mobile / Max-Q", Vendor: "NVIDIA", BusID: "0000:01:00.0", Driver: "nvidia"},
+	}
+	merged := system.MergeNvidiaGPUs(gpus, nvidia)
+	if len(merged) != 3 {
+		t.Fatalf("len(merged) = %d, want 3", len(merged))
+	}
+	if merged[0].VRAM != 0 || merged[0].Driver != "i915" {
+		t.Errorf("merged[0] = %+v, want the Intel GPU untouched", merged[0])
+	}
+	if merged[1].Name != "NVIDIA GeForce GTX 1650" || merged[1].VRAM != 4096<<20 || merged[1].Driver != "nvidia" {
+		t.Errorf("merged[1] = %+v", merged[1])
+	}
+	if merged[2].Name != "NVIDIA A100" {
+		t.Errorf("merged[2] = %+v, want the unmatched GPU appended", merged[2])
+	}
+}
+
+func TestParseWin32VideoControllers(t *testing.T) {
+	data := []byte(`[
+		{"Name":"Intel(R) UHD Graphics 630","AdapterCompatibility":"Intel Corporation","AdapterRAM":1073741824,"MemorySize":0,"DriverVersion":"31.0.101.2111"},
+		{"Name":"NVIDIA GeForce RTX 3080","AdapterCompatibility":"NVIDIA","AdapterRAM":4293918720,"MemorySize":10737418240,"DriverVersion":"31.0.15.3623"}
+	]`)
+
+	gpus, err := system.ParseWin32VideoControllers(data)
+	if err != nil {
+		t.Fatalf("ParseWin32VideoControllers() error = %v", err)
+	}
+	if len(gpus) != 2 {
+		t.Fatalf("len(gpus) = %d, want 2", len(gpus))
+	}
+	if gpus[0].Vendor != "Intel" || gpus[0].VRAM != 1<<30 {
+		t.Errorf("gpus[0] = %+v", gpus[0])
+	}
+	// AdapterRAM is capped at 4 GB, so the registry size wins
+	if gpus[1].Vendor != "NVIDIA" || gpus[1].VRAM != 10<<30 || gpus[1].Version != "31.0.15.3623" {
+		t.Errorf("gpus[1] = %+v", gpus[1])
+	}
+}
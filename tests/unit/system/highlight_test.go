@@ -0,0 +1,89 @@
+package system_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/commands/system"
+
+	"github.com/fatih/color"
+)
+
+// withColor turns colors on for the length of a test
+func withColor(t *testing.T) {
+	t.Helper()
+	noColor := color.NoColor
+	color.NoColor = false
+	t.Cleanup(func() { color.NoColor = noColor })
+}
+
+func TestHighlighter_ColorsMatches(t *testing.T) {
+	withColor(t)
+	red, yellow := color.New(color.FgRed), color.New(color.FgYellow, color.Bold)
+
+	h, err := system.NewHighlighter([]string{"ERROR:red", `\d+:\d+:yellow+bold`, "error code:cyan"}, false, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := "12:30 ERROR failed"
+	if got, want := h.Line(line), yellow.Sprint("12:30")+" "+red.Sprint("ERROR")+" failed"; got != want {
+		t.Errorf("Line(%q) = %q, want %q", line, got, want)
+	}
+	if got := h.Line("all fine"); got != "all fine" {
+		t.Errorf("a line without matches should be unchanged, got %q", got)
+	}
+
+	// Case-insensitive plain text, with the earlier pattern winning an overlap
+	h, _ = system.NewHighlighter([]string{"a.b:red", "B.C:green"}, true, true, false)
+	if got, want := h.Line("xA.B.Cx"), "x"+red.Sprint("A.B")+color.New(color.FgGreen).Sprint(".C")+"x"; got != want {
+		t.Errorf("Line() = %q, want %q", got, want)
+	}
+
+	h, _ = system.NewHighlighter([]string{"warn:yellow", "http://example.com"}, false, false, true)
+	if got, want := h.Line("a warn line"), color.New(color.FgYellow).Sprint("a warn line"); got != want {
+		t.Errorf("--line should color the whole line, got %q", got)
+	}
+	if got, want := h.Line("see http://example.com"), color.New(color.BgYellow, color.FgBlack).Sprint("see http://example.com"); got != want {
+		t.Errorf("a spec ending in a non-color keeps its colon, got %q want %q", got, want)
+	}
+
+	for _, bad := range [][]string{{"(:red"}, {""}} {
+		if _, err := system.NewHighlighter(bad, false, false, false); err == nil {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestHighlight_PassesInputThrough(t *testing.T) {
+	input := "INFO started\r\nERROR broke\nno newline"
+	run := func() string {
+		var out bytes.Buffer
+		ctx := commands.WithInputReader(commands.WithOutputWriter(context.Background(), &out), strings.NewReader(input))
+		result, err := system.NewHighlightCommand().Execute(ctx, commands.ParseArguments([]string{"-i", "error:red"}))
+		if err != nil || result.ExitCode != 0 {
+			t.Fatalf("highlight failed: %v %+v", err, result)
+		}
+		return out.String()
+	}
+
+	noColor := color.NoColor
+	color.NoColor = true
+	got := run()
+	color.NoColor = noColor
+	if got != input {
+		t.Errorf("with colors off the input should pass through unchanged, got %q", got)
+	}
+
+	withColor(t)
+	if got, want := run(), "INFO started\r\n"+color.New(color.FgRed).Sprint("ERROR")+" broke\nno newline"; got != want {
+		t.Errorf("highlight printed %q, want %q", got, want)
+	}
+
+	result, _ := system.NewHighlightCommand().Execute(context.Background(), commands.ParseArguments(nil))
+	if result.ExitCode != 1 || !strings.HasPrefix(result.Output, "Usage: ") {
+		t.Errorf("expected a usage error without patterns, got %+v", result)
+	}
+}
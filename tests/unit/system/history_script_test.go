@@ -0,0 +1,134 @@
+package system_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/commands/system"
+)
+
+func scriptHistory() []system.HistoryEntry {
+	base := time.Date(2024, 3, 1, 9, 0, 0, 0, time.Local)
+	runs := []struct {
+		command  string
+		exitCode int
+	}{
+		{"cd /srv/app", 0},
+		{"ls -la", 0},
+		{"ping -c 3 db01", 1},
+		{"history", 0},
+		{"ping -c 3 db01.internal", 0},
+		{"netstat -an", 0},
+	}
+	var entries []system.HistoryEntry
+	for i, c := range runs {
+		entries = append(entries, system.HistoryEntry{
+			ID:        100 + i,
+			Command:   c.command,
+			ExitCode:  c.exitCode,
+			Timestamp: base.Add(time.Duration(i) * time.Minute),
+			Directory: "/srv/app",
+		})
+	}
+	return entries
+}
+
+func TestSelectHistory(t *testing.T) {
+	entries := scriptHistory()
+
+	selected := system.SelectHistory(entries, system.HistoryScriptOptions{From: 101, To: 104})
+	var got []string
+	for _, entry := range selected {
+		got = append(got, entry.Command)
+	}
+	// history itself is never exported
+	if strings.Join(got, ";") != "ls -la;ping -c 3 db01;ping -c 3 db01.internal" {
+		t.Errorf("unexpected range selection %q", got)
+	}
+
+	selected = system.SelectHistory(entries, system.HistoryScriptOptions{Last: 2, SkipFailed: true})
+	if len(selected) != 2 || selected[0].ID != 104 || selected[1].ID != 105 {
+		t.Errorf("unexpected last selection %+v", selected)
+	}
+	for _, entry := range system.SelectHistory(entries, system.HistoryScriptOptions{SkipFailed: true}) {
+		if entry.ExitCode != 0 {
+			t.Errorf("--skip-failed kept %q", entry.Command)
+		}
+	}
+}
+
+func TestHistoryScript(t *testing.T) {
+	entries := system.SelectHistory(scriptHistory(), system.HistoryScriptOptions{})
+	script := system.HistoryScript(entries, system.HistoryScriptOptions{}, time.Date(2024, 3, 2, 10, 0, 0, 0, time.Local))
+
+	for _, want := range []string{
+		"# Generated 2024-03-02 10:00:00 by history export\n",
+		"(entries 100-105)",
+		"# Recorded in /srv/app\n",
+		"# The next command failed with exit code 1\nping -c 3 db01\n",
+		"\nnetstat -an\n",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script is missing %q:\n%s", want, script)
+		}
+	}
+	// Every line is a comment, blank, or one of the commands
+	recorded := map[string]bool{}
+	for _, entry := range entries {
+		recorded[entry.Command] = true
+	}
+	for _, line := range strings.Split(strings.TrimSpace(script), "\n") {
+		if line != "" && !strings.HasPrefix(line, "#") && !recorded[line] {
+			t.Errorf("unexpected script line %q", line)
+		}
+	}
+}
+
+func TestHistoryExport_WritesScript(t *testing.T) {
+	home, cleanup := withHome(t)
+	defer cleanup()
+	data, _ := json.Marshal(scriptHistory())
+	if err := ioutil.WriteFile(filepath.Join(home, ".supershell_history.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	history := system.NewSmartHistoryCommand(nil)
+	file := filepath.Join(home, "scripts", "setup.ss")
+	result, err := history.Execute(context.Background(), commands.ParseArguments([]string{"export", "--from", "101", "--to", "103", "--skip-failed", file}))
+	if err != nil || result.ExitCode != 0 {
+		t.Fatalf("export failed: %v %+v", err, result)
+	}
+	script, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(string(script), "\nls -la\n") || strings.Contains(string(script), "db01") {
+		t.Errorf("unexpected script:\n%s", script)
+	}
+
+	// An existing script is only replaced with --force
+	result, _ = history.Execute(context.Background(), commands.ParseArguments([]string{"export", file}))
+	if result.ExitCode == 0 || !strings.Contains(result.Output, "--force") {
+		t.Errorf("expected export to refuse to overwrite, got %+v", result)
+	}
+
+	for _, args := range [][]string{
+		{"export", "--from", "x", file},
+		{"export", "--from", "120", "--to", "110", file},
+		{"export", "--last", "5"},
+		{"export", "--to", "0", file},
+		{"export", "--bogus", file},
+		{"export", file, "extra.ss"},
+	} {
+		result, _ := history.Execute(context.Background(), commands.ParseArguments(args))
+		if result.ExitCode != 1 || !strings.HasPrefix(result.Output, "Usage: ") {
+			t.Errorf("%v: expected a usage error, got %+v", args, result)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package system_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"suppercommand/internal/commands/system"
+)
+
+func TestAnalyzeHistory(t *testing.T) {
+	base := time.Date(2024, 3, 1, 9, 0, 0, 0, time.Local)
+	entries := []system.HistoryEntry{
+		// Older entries without session IDs, split by an idle gap
+		{Command: "ls -la", Timestamp: base, Category: "filesystem"},
+		{Command: "ping 8.8.8.8", Timestamp: base.Add(time.Minute), ExitCode: 1, Category: "network"},
+		{Command: "ls", Timestamp: base.Add(2 * time.Hour), Category: "filesystem"},
+		// Tracked sessions
+		{Command: "ls", Timestamp: base.Add(3 * time.Hour), Session: "a"},
+		{Command: "netstat", Timestamp: base.Add(3*time.Hour + time.Minute), Session: "a"},
+		{Command: "ls", Timestamp: base.Add(3*time.Hour + 2*time.Minute), Session: "b"},
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := system.AnalyzeHistory(bytes.NewReader(data), 2)
+	if err != nil {
+		t.Fatalf("AnalyzeHistory() error = %v", err)
+	}
+
+	if stats.TotalCommands != 6 {
+		t.Errorf("TotalCommands = %d, want 6", stats.TotalCommands)
+	}
+	if stats.Sessions != 4 {
+		t.Errorf("Sessions = %d, want 4", stats.Sessions)
+	}
+	if stats.CommandsPerSession != 1.5 {
+		t.Errorf("CommandsPerSession = %v, want 1.5", stats.CommandsPerSession)
+	}
+	if len(stats.TopCommands) != 2 || stats.TopCommands[0].Command != "ls" || stats.TopCommands[0].Count != 4 {
+		t.Errorf("unexpected top commands %+v", stats.TopCommands)
+	}
+	if stats.BusiestHour != 12 || stats.HourlyUsage[9] != 2 {
+		t.Errorf("unexpected hourly usage: busiest %d, %v", stats.BusiestHour, stats.HourlyUsage)
+	}
+	if stats.Categories["filesystem"] != 2 {
+		t.Errorf("unexpected categories %v", stats.Categories)
+	}
+}
+
+func TestAnalyzeHistory_Invalid(t *testing.T) {
+	stats, err := system.AnalyzeHistory(strings.NewReader(""), 5)
+	if err != nil || stats.TotalCommands != 0 {
+		t.Errorf("empty history should give empty stats, got %+v, %v", stats, err)
+	}
+
+	if _, err := system.AnalyzeHistory(strings.NewReader(`{"command":"ls"}`), 5); err == nil {
+		t.Error("expected an error for a history file that is not an array")
+	}
+}
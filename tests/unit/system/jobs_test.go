@@ -0,0 +1,118 @@
+package system_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/commands/system"
+
+	"github.com/fatih/color"
+)
+
+// startServer starts a job that prints a line and then runs until it is killed
+func startServer(jobs *commands.JobTable, line string) *commands.Job {
+	return jobs.Start(context.Background(), line, func(ctx context.Context, output io.Writer) (int, error) {
+		io.WriteString(output, "listening\n")
+		<-ctx.Done()
+		return 1, ctx.Err()
+	})
+}
+
+func TestJobsCommand(t *testing.T) {
+	color.NoColor = true
+	jobs := commands.NewJobTable()
+	jobsCmd := system.NewJobsCommand(jobs)
+
+	result, err := jobsCmd.Execute(context.Background(), commands.ParseArguments(nil))
+	if err != nil || !strings.Contains(result.Output, "No background jobs") {
+		t.Fatalf("an empty table should say so, got %+v, %v", result, err)
+	}
+
+	server := startServer(jobs, "fastcp-recv key")
+	defer server.Kill()
+	result, _ = jobsCmd.Execute(context.Background(), commands.ParseArguments(nil))
+	if !strings.Contains(result.Output, "[1] running") || !strings.Contains(result.Output, "fastcp-recv key") {
+		t.Errorf("jobs should list the running job:\n%s", result.Output)
+	}
+
+	result, _ = jobsCmd.Execute(context.Background(), commands.ParseArguments([]string{"--json"}))
+	var listed []map[string]interface{}
+	if err := json.Unmarshal([]byte(result.Output), &listed); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, result.Output)
+	}
+	if len(listed) != 1 || listed[0]["command"] != "fastcp-recv key" || listed[0]["state"] != "running" {
+		t.Errorf("jobs --json = %v", listed)
+	}
+}
+
+func TestFgCommand(t *testing.T) {
+	color.NoColor = true
+	jobs := commands.NewJobTable()
+	fg := system.NewFgCommand(jobs)
+
+	result, _ := fg.Execute(context.Background(), commands.ParseArguments(nil))
+	if result.ExitCode != 1 || result.Error == nil {
+		t.Errorf("fg without jobs should fail, got %+v", result)
+	}
+
+	release := make(chan struct{})
+	jobs.Start(context.Background(), "build", func(ctx context.Context, output io.Writer) (int, error) {
+		io.WriteString(output, "compiling\n")
+		<-release
+		io.WriteString(output, "linked\n")
+		return 3, nil
+	})
+
+	// Interrupting fg leaves the job running
+	var out bytes.Buffer
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	result, _ = fg.Execute(commands.WithOutputWriter(ctx, &out), commands.ParseArguments([]string{"%1"}))
+	if result.ExitCode != 0 || !strings.Contains(result.Output, "still running") {
+		t.Errorf("an interrupted fg should leave the job, got %+v", result)
+	}
+	if out.String() != "[1] build\ncompiling\n" {
+		t.Errorf("fg should stream the output so far, got %q", out.String())
+	}
+
+	// Following it to the end collects it with its exit code
+	close(release)
+	out.Reset()
+	result, _ = fg.Execute(commands.WithOutputWriter(context.Background(), &out), commands.ParseArguments(nil))
+	if result.ExitCode != 3 || out.String() != "[1] build\ncompiling\nlinked\n" {
+		t.Errorf("fg should wait for the job, got exit code %d and %q", result.ExitCode, out.String())
+	}
+	if len(jobs.List()) != 0 {
+		t.Errorf("a job brought to the end with fg should be removed")
+	}
+}
+
+func TestKillCommand(t *testing.T) {
+	color.NoColor = true
+	jobs := commands.NewJobTable()
+	kill := system.NewKillCommand(jobs)
+	server := startServer(jobs, "fastcp-recv key")
+
+	result, err := kill.Execute(context.Background(), commands.ParseArguments([]string{"%1"}))
+	if err != nil || result.ExitCode != 0 || !strings.Contains(result.Output, "[1] stopped: fastcp-recv key") {
+		t.Fatalf("kill %%1 should stop the job, got %+v, %v", result, err)
+	}
+	if server.State() != commands.JobKilled || len(jobs.List()) != 0 {
+		t.Errorf("the job should be killed and forgotten, is %s", server.State())
+	}
+
+	result, _ = kill.Execute(context.Background(), commands.ParseArguments([]string{"%7"}))
+	if result.ExitCode != 1 || result.Error == nil || !strings.Contains(result.Error.Error(), "no job %7") {
+		t.Errorf("an unknown job should be reported, got %+v", result)
+	}
+	result, _ = kill.Execute(context.Background(), commands.ParseArguments(nil))
+	if result.ExitCode != 1 || result.Error == nil {
+		t.Errorf("kill needs a target, got %+v", result)
+	}
+}
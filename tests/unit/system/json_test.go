@@ -0,0 +1,145 @@
+package system_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/commands/system"
+)
+
+func TestJSONDocument_KeepsLayout(t *testing.T) {
+	input := "{\n    \"zeta\": 1.50,\n    \"alpha\": {\n        \"url\": \"a<b>&c\"\n    },\n    \"list\": [],\n    \"none\": null\n}\n"
+	doc, err := system.ParseJSONDocument([]byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(doc.Bytes()); got != input {
+		t.Errorf("round trip changed the document:\n%s\nwant:\n%s", got, input)
+	}
+
+	compact, err := system.ParseJSONDocument([]byte(`{"b":1,"a":[true,"x"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(compact.Bytes()); got != "{\"b\":1,\"a\":[true,\"x\"]}\n" {
+		t.Errorf("compact document came out as %q", got)
+	}
+
+	for _, bad := range []string{"", "{", `{"a":1} {"b":2}`, `{"a":}`} {
+		if _, err := system.ParseJSONDocument([]byte(bad)); err == nil {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestJSONDocument_Merge(t *testing.T) {
+	base, _ := system.ParseJSONDocument([]byte(`{"name":"app","prompt":{"theme":"plain","color":true},"ports":[80,443],"debug":true}`))
+	override, _ := system.ParseJSONDocument([]byte(`{"prompt":{"theme":"powerline"},"ports":[8080],"debug":null,"extra":{"a":null,"b":2}}`))
+	base.Merge(override)
+
+	want := `{"name":"app","prompt":{"theme":"powerline","color":true},"ports":[8080],"extra":{"b":2}}` + "\n"
+	if got := string(base.Bytes()); got != want {
+		t.Errorf("Merge() = %s, want %s", got, want)
+	}
+}
+
+func TestJSONDocument_GetAndSet(t *testing.T) {
+	doc, _ := system.ParseJSONDocument([]byte(`{"servers":[{"host":"web01"}],"prompt":"x"}`))
+
+	if value, err := doc.Get(".servers[0].host"); err != nil || string(value.Bytes()) != "\"web01\"\n" {
+		t.Errorf("Get() = %v, %v", value, err)
+	}
+	for _, path := range []string{"servers.1", "missing", "prompt.theme", ""} {
+		if _, err := doc.Get(path); err == nil {
+			t.Errorf("Get(%q) should fail", path)
+		}
+	}
+
+	steps := []struct {
+		path  string
+		value *system.JSONDocument
+	}{
+		{"servers[0].port", system.JSONValue("8080", false)},
+		{"servers.1", system.JSONValue(`{"host":"db01"}`, false)},
+		{"settings.theme.name", system.JSONValue("powerline", false)},
+		{"settings.version", system.JSONValue("2", true)},
+	}
+	for _, step := range steps {
+		if err := doc.Set(step.path, step.value); err != nil {
+			t.Fatalf("Set(%q) error = %v", step.path, err)
+		}
+	}
+	want := `{"servers":[{"host":"web01","port":8080},{"host":"db01"}],"prompt":"x","settings":{"theme":{"name":"powerline"},"version":"2"}}` + "\n"
+	if got := string(doc.Bytes()); got != want {
+		t.Errorf("after Set() = %s, want %s", got, want)
+	}
+
+	if err := doc.Set("prompt.theme", system.JSONValue("dark", false)); err == nil {
+		t.Error("Set() should refuse to add a key to a string")
+	}
+	if err := doc.Set("servers.5", system.JSONValue("1", false)); err == nil {
+		t.Error("Set() should refuse an index past the end of an array")
+	}
+}
+
+func TestJSONCommand_EditsFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "supershell-json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "config.json")
+	original := "{\n\t\"prompt\": {\n\t\t\"theme\": \"plain\"\n\t},\n\t\"size\": 10\n}\n"
+	if err := ioutil.WriteFile(file, []byte(original), 0600); err != nil {
+		t.Fatal(err)
+	}
+	override := filepath.Join(dir, "override.json")
+	ioutil.WriteFile(override, []byte(`{"size": 20}`), 0644)
+
+	run := func(args ...string) *commands.Result {
+		result, _ := system.NewJSONCommand().Execute(context.Background(), commands.ParseArguments(args))
+		return result
+	}
+
+	if result := run("set", file, "prompt.theme", "powerline"); result.ExitCode != 0 {
+		t.Fatalf("json set failed: %+v", result)
+	}
+	data, _ := ioutil.ReadFile(file)
+	if want := strings.Replace(original, "plain", "powerline", 1); string(data) != want {
+		t.Errorf("json set wrote:\n%s\nwant:\n%s", data, want)
+	}
+	if info, _ := os.Stat(file); info.Mode().Perm() != 0600 {
+		t.Errorf("json set changed the file mode to %v", info.Mode().Perm())
+	}
+
+	if result := run("get", file, "prompt.theme"); result.Output != "powerline\n" {
+		t.Errorf("json get printed %q", result.Output)
+	}
+	if result := run("merge", file, override); !strings.Contains(result.Output, "\t\"size\": 20\n") {
+		t.Errorf("json merge printed:\n%s", result.Output)
+	}
+
+	merged := filepath.Join(dir, "merged.json")
+	if result := run("merge", file, override, "-o", merged); result.ExitCode != 0 {
+		t.Fatalf("json merge -o failed: %+v", result)
+	}
+	if data, _ := ioutil.ReadFile(merged); !strings.Contains(string(data), "\"size\": 20") {
+		t.Errorf("json merge -o wrote:\n%s", data)
+	}
+
+	ioutil.WriteFile(override, []byte(`{"size": `), 0644)
+	if result := run("merge", file, override); result.ExitCode != 1 || result.Error == nil || !strings.Contains(result.Error.Error(), "invalid JSON") {
+		t.Errorf("expected invalid JSON to be reported, got %+v", result)
+	}
+	for _, args := range [][]string{{}, {"merge", file}, {"get", file}, {"set", file, "a"}, {"patch"}} {
+		if result := run(args...); result.ExitCode != 1 || !strings.HasPrefix(result.Output, "Usage: ") {
+			t.Errorf("%q: expected a usage error, got %+v", args, result)
+		}
+	}
+}
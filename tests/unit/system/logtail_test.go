@@ -0,0 +1,177 @@
+package system_test
+
+import (
+	"testing"
+	"time"
+
+	"suppercommand/internal/commands/system"
+)
+
+func TestParseJournalEntry(t *testing.T) {
+	line := `{"__CURSOR":"s=1;i=2","__REALTIME_TIMESTAMP":"1710061201000000","PRIORITY":"3",` +
+		`"_HOSTNAME":"web1","_PID":"812","SYSLOG_IDENTIFIER":"sshd","_SYSTEMD_UNIT":"ssh.service",` +
+		`"MESSAGE":[102,97,105,108,101,100]}`
+
+	entry, err := system.ParseJournalEntry(line)
+	if err != nil {
+		t.Fatalf("ParseJournalEntry() error = %v", err)
+	}
+
+	if !entry.Time.Equal(time.Unix(1710061201, 0)) {
+		t.Errorf("Time = %v, want %v", entry.Time, time.Unix(1710061201, 0))
+	}
+	if entry.Priority != system.PriorityError || entry.Level != "error" {
+		t.Errorf("level = %d %q, want 3 \"error\"", entry.Priority, entry.Level)
+	}
+	if entry.Source != "sshd" || entry.PID != 812 || entry.Host != "web1" || entry.Log != "ssh.service" {
+		t.Errorf("entry = %+v", entry)
+	}
+	// Messages that aren't valid UTF-8 are exported as byte arrays
+	if entry.Message != "failed" {
+		t.Errorf("Message = %q, want \"failed\"", entry.Message)
+	}
+
+	if _, err := system.ParseJournalEntry("not json"); err == nil {
+		t.Error("ParseJournalEntry() should fail on invalid JSON")
+	}
+}
+
+func TestParseSyslogLine(t *testing.T) {
+	now := time.Date(2024, 3, 12, 0, 0, 0, 0, time.UTC)
+
+	entry, ok := system.ParseSyslogLine("Mar 10 09:00:01 web1 sshd[812]: error: connection reset", now)
+	if !ok {
+		t.Fatal("ParseSyslogLine() did not match a classic syslog line")
+	}
+	if want := time.Date(2024, 3, 10, 9, 0, 1, 0, time.UTC); !entry.Time.Equal(want) {
+		t.Errorf("Time = %v, want %v", entry.Time, want)
+	}
+	if entry.Host != "web1" || entry.Source != "sshd" || entry.PID != 812 {
+		t.Errorf("entry = %+v", entry)
+	}
+	if entry.Message != "error: connection reset" || entry.Priority != system.PriorityError {
+		t.Errorf("message = %q priority = %d", entry.Message, entry.Priority)
+	}
+
+	// A December entry read in January belongs to the previous year
+	entry, ok = system.ParseSyslogLine("Dec 31 23:59:59 web1 kernel: clock tick", time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC))
+	if !ok || entry.Time.Year() != 2023 {
+		t.Errorf("year = %d, want 2023", entry.Time.Year())
+	}
+	if entry.PID != 0 || entry.Priority != system.PriorityInfo {
+		t.Errorf("entry = %+v", entry)
+	}
+
+	entry, ok = system.ParseSyslogLine("2024-03-10T09:00:03.5+01:00 web1 cron[99]: WARNING low disk", now)
+	if !ok {
+		t.Fatal("ParseSyslogLine() did not match an RFC 3339 syslog line")
+	}
+	if want := time.Date(2024, 3, 10, 8, 0, 3, 500000000, time.UTC); !entry.Time.Equal(want) {
+		t.Errorf("Time = %v, want %v", entry.Time, want)
+	}
+	if entry.Priority != system.PriorityWarning {
+		t.Errorf("Priority = %d, want %d", entry.Priority, system.PriorityWarning)
+	}
+
+	if _, ok := system.ParseSyslogLine("  continuation of a stack trace", now); ok {
+		t.Error("ParseSyslogLine() matched a continuation line")
+	}
+}
+
+func TestParseEventLogText(t *testing.T) {
+	text := "Event[0]:\r\n" +
+		"  Log Name: System\r\n" +
+		"  Source: Service Control Manager\r\n" +
+		"  Date: 2024-03-10T09:00:01.123\r\n" +
+		"  Event ID: 7000\r\n" +
+		"  Level: Error\r\n" +
+		"  Computer: WS01\r\n" +
+		"  Description: \r\n" +
+		"The Spooler service failed to start.\r\n" +
+		"Access is denied.\r\n" +
+		"\r\n" +
+		"Event[1]:\r\n" +
+		"  Log Name: Application\r\n" +
+		"  Source: MsiInstaller\r\n" +
+		"  Date: 2024-03-10T09:05:00.000\r\n" +
+		"  Level: Information\r\n" +
+		"  Description: Product installed.\r\n"
+
+	entries := system.ParseEventLogText(text)
+	if len(entries) != 2 {
+		t.Fatalf("len(entries) = %d, want 2", len(entries))
+	}
+
+	first := entries[0]
+	if first.Log != "System" || first.Source != "Service Control Manager" || first.Host != "WS01" {
+		t.Errorf("entries[0] = %+v", first)
+	}
+	if first.Priority != system.PriorityError || first.Level != "error" {
+		t.Errorf("entries[0] level = %d %q", first.Priority, first.Level)
+	}
+	if want := time.Date(2024, 3, 10, 9, 0, 1, 123000000, time.Local); !first.Time.Equal(want) {
+		t.Errorf("entries[0].Time = %v, want %v", first.Time, want)
+	}
+	if first.Message != "The Spooler service failed to start.\nAccess is denied." {
+		t.Errorf("entries[0].Message = %q", first.Message)
+	}
+
+	if entries[1].Priority != system.PriorityInfo || entries[1].Message != "Product installed." {
+		t.Errorf("entries[1] = %+v", entries[1])
+	}
+}
+
+func TestParseLogSince(t *testing.T) {
+	now := time.Date(2024, 3, 10, 15, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"30m", now.Add(-30 * time.Minute)},
+		{"2h", now.Add(-2 * time.Hour)},
+		{"7d", now.AddDate(0, 0, -7)},
+		{"today", time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)},
+		{"yesterday", time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC)},
+		{"09:15", time.Date(2024, 3, 10, 9, 15, 0, 0, time.UTC)},
+		{"2024-03-01", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"2024-03-01 08:00", time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)},
+		{"2024-03-01T08:00:00Z", time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		got, err := system.ParseLogSince(tt.value, now)
+		if err != nil {
+			t.Errorf("ParseLogSince(%q) error = %v", tt.value, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseLogSince(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+
+	for _, value := range []string{"", "soon", "-5m", "0d", "2024-13-01"} {
+		if _, err := system.ParseLogSince(value, now); err == nil {
+			t.Errorf("ParseLogSince(%q) should fail", value)
+		}
+	}
+}
+
+func TestParseLogLevel(t *testing.T) {
+	tests := map[string]int{
+		"error":   system.PriorityError,
+		"ERR":     system.PriorityError,
+		"warning": system.PriorityWarning,
+		"warn":    system.PriorityWarning,
+		"crit":    system.PriorityCritical,
+		"debug":   system.PriorityDebug,
+	}
+	for name, want := range tests {
+		if got, err := system.ParseLogLevel(name); err != nil || got != want {
+			t.Errorf("ParseLogLevel(%q) = %d, %v, want %d", name, got, err, want)
+		}
+	}
+	if _, err := system.ParseLogLevel("loud"); err == nil {
+		t.Error("ParseLogLevel(\"loud\") should fail")
+	}
+}
@@ -0,0 +1,75 @@
+package system_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/commands/filesystem"
+	"suppercommand/internal/commands/networking"
+	"suppercommand/internal/commands/system"
+	"suppercommand/internal/config"
+	"suppercommand/internal/monitoring"
+
+	"github.com/fatih/color"
+)
+
+func lookupRegistry(t *testing.T) *commands.Registry {
+	t.Helper()
+	registry := commands.NewRegistry(monitoring.NewLogger(config.MonitoringConfig{}))
+	for _, cmd := range []commands.Command{
+		networking.NewPortscanCommand(),
+		networking.NewNetstatCommand(),
+		networking.NewPingCommand(),
+		filesystem.NewEchoCommand(),
+	} {
+		if err := registry.Register(cmd); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return registry
+}
+
+func runLookup(t *testing.T, args ...string) string {
+	t.Helper()
+	noColor := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = noColor }()
+
+	lookup := system.NewLookupCommand(lookupRegistry(t))
+	result, err := lookup.Execute(context.Background(), commands.ParseArguments(args))
+	if err != nil || result.ExitCode != 0 {
+		t.Fatalf("lookup %v: %v, %+v", args, err, result)
+	}
+	return result.Output
+}
+
+func TestLookup_RanksNameOverDescription(t *testing.T) {
+	out := runLookup(t, "port")
+
+	portscan := strings.Index(out, "📝 portscan")
+	netstat := strings.Index(out, "📄 netstat")
+	if portscan < 0 || netstat < 0 {
+		t.Fatalf("lookup port should find portscan by name and netstat by its help:\n%s", out)
+	}
+	if portscan > netstat {
+		t.Errorf("name matches should come before description matches:\n%s", out)
+	}
+	if strings.Contains(out, " echo ") {
+		t.Errorf("echo has nothing to do with ports:\n%s", out)
+	}
+	if !strings.Contains(out, "Usage: portscan") || !strings.Contains(out, "Usage: netstat") {
+		t.Errorf("every match should show its usage:\n%s", out)
+	}
+	if strings.Contains(out, "Example:") {
+		t.Errorf("examples should only be shown with --examples:\n%s", out)
+	}
+}
+
+func TestLookup_Examples(t *testing.T) {
+	out := runLookup(t, "port", "--examples")
+	if !strings.Contains(out, "Example: portscan ") {
+		t.Errorf("--examples should show the first example of portscan:\n%s", out)
+	}
+}
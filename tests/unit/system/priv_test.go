@@ -0,0 +1,99 @@
+package system_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/commands/filesystem"
+	"suppercommand/internal/commands/system"
+	"suppercommand/internal/config"
+	"suppercommand/internal/monitoring"
+)
+
+// privFixture returns a registry with echo, winupdate and user, and a priv
+// command whose elevation records the lines it is asked to run
+func privFixture(t *testing.T, elevated bool, method error) (*system.PrivCommand, *[]string) {
+	t.Helper()
+	registry := commands.NewRegistry(monitoring.NewLogger(config.MonitoringConfig{}))
+	for _, cmd := range []commands.Command{filesystem.NewEchoCommand(), system.NewWinUpdateCommand(), system.NewUserCommand()} {
+		if err := registry.Register(cmd); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var ran []string
+	elevation := commands.Elevation{
+		IsElevated: func() bool { return elevated },
+		Method:     func() (string, error) { return "sudo", method },
+		Confirm:    func(string) bool { return false },
+		Run: func(ctx context.Context, line string) (int, error) {
+			ran = append(ran, line)
+			return 0, nil
+		},
+	}
+	registry.SetElevation(elevation)
+	return system.NewPrivCommandWithElevation(registry, elevation), &ran
+}
+
+func runPriv(t *testing.T, priv *system.PrivCommand, args ...string) *commands.Result {
+	t.Helper()
+	result, err := priv.Execute(context.Background(), commands.ParseArguments(args))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestPrivStatus(t *testing.T) {
+	priv, _ := privFixture(t, false, nil)
+	result := runPriv(t, priv)
+	for _, want := range []string{"Standard user", "available through sudo", "user (some actions)", "winupdate (some actions)", "priv elevate"} {
+		if !strings.Contains(result.Output, want) {
+			t.Errorf("status should mention %q:\n%s", want, result.Output)
+		}
+	}
+	if strings.Contains(result.Output, "echo") {
+		t.Error("commands that don't need elevation should not be listed")
+	}
+
+	priv, _ = privFixture(t, true, errors.New("neither sudo nor pkexec is installed"))
+	result = runPriv(t, priv, "status")
+	if !strings.Contains(result.Output, "Elevated") || !strings.Contains(result.Output, "unavailable") {
+		t.Errorf("elevated status without a method:\n%s", result.Output)
+	}
+}
+
+func TestPrivElevate(t *testing.T) {
+	priv, ran := privFixture(t, false, nil)
+
+	if result := runPriv(t, priv, "elevate", "winupdate", "install", "--auto"); result.ExitCode != 0 {
+		t.Fatalf("elevate failed: %v", result.Error)
+	}
+	runPriv(t, priv, "elevate", "echo", "two words")
+	runPriv(t, priv, "elevate")
+	want := []string{"winupdate install --auto", `echo "two words"`, ""}
+	if strings.Join(*ran, "|") != strings.Join(want, "|") {
+		t.Errorf("ran %q, want %q", *ran, want)
+	}
+
+	if result := runPriv(t, priv, "elevate", "nosuchcommand"); result.ExitCode == 0 || len(*ran) != 3 {
+		t.Error("an unknown command should fail without elevating")
+	}
+	if result := runPriv(t, priv, "promote"); result.ExitCode != 1 || result.Error == nil {
+		t.Errorf("unknown subcommand should be a usage error, got %+v", result)
+	}
+}
+
+func TestPrivElevateWhenElevated(t *testing.T) {
+	priv, ran := privFixture(t, true, nil)
+	result := runPriv(t, priv, "elevate", "echo hello")
+	if len(*ran) != 0 {
+		t.Errorf("an elevated shell should run the command itself, ran %q", *ran)
+	}
+	if !strings.Contains(result.Output, "hello") {
+		t.Errorf("output = %q, want the echoed text", result.Output)
+	}
+}
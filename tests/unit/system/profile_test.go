@@ -0,0 +1,194 @@
+package system_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/commands/system"
+)
+
+func runProfile(t *testing.T, profile *system.ProfileCommand, args ...string) *commands.Result {
+	result, err := profile.Execute(context.Background(), commands.ParseArguments(args))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+// inDir changes to a fresh directory, returning it and a cleanup restoring the
+// working directory
+func inDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "supershell-profile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Compare against the resolved path, as /tmp may be a symlink
+	dir, _ = filepath.EvalSymlinks(dir)
+	oldDir, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	return dir, func() {
+		os.Chdir(oldDir)
+		os.RemoveAll(dir)
+	}
+}
+
+func TestProfile_SaveLoadRoundTrip(t *testing.T) {
+	home, cleanup := withHome(t)
+	defer cleanup()
+	defer os.Unsetenv("PROFILE_TEST_STAGE")
+	defer os.Unsetenv("PROFILE_TEST_EXTRA")
+
+	favorites := filepath.Join(home, ".supershell", "favorites.json")
+	if err := system.SaveFavorites(favorites, []system.Favorite{{Label: "logs", Command: "logtail -f"}}); err != nil {
+		t.Fatal(err)
+	}
+	writeBookmarks(t, home, "deploy")
+
+	profile := system.NewProfileCommand()
+	project, restore := inDir(t)
+	defer restore()
+	os.Setenv("PROFILE_TEST_STAGE", "staging")
+
+	result := runProfile(t, profile, "save", "work", "--env", "PROFILE_TEST_EXTRA=1")
+	if result.ExitCode != 0 {
+		t.Fatalf("save failed: %s", result.Output)
+	}
+	if !strings.Contains(result.Output, "PROFILE_TEST_STAGE=staging") || !strings.Contains(result.Output, "PROFILE_TEST_EXTRA=1") {
+		t.Errorf("save should list the environment changes:\n%s", result.Output)
+	}
+
+	// Lose the context, then get it back
+	os.Unsetenv("PROFILE_TEST_STAGE")
+	if err := system.SaveFavorites(favorites, nil); err != nil {
+		t.Fatal(err)
+	}
+	elsewhere, restoreElsewhere := inDir(t)
+	defer restoreElsewhere()
+
+	result = runProfile(t, profile, "load", "work")
+	if result.ExitCode != 0 {
+		t.Fatalf("load failed: %s", result.Output)
+	}
+	if dir, _ := os.Getwd(); dir != project {
+		t.Errorf("load should change to %s, now in %s (was %s)", project, dir, elsewhere)
+	}
+	if os.Getenv("PROFILE_TEST_STAGE") != "staging" || os.Getenv("PROFILE_TEST_EXTRA") != "1" {
+		t.Errorf("load should restore the environment, got STAGE=%q EXTRA=%q", os.Getenv("PROFILE_TEST_STAGE"), os.Getenv("PROFILE_TEST_EXTRA"))
+	}
+	restored, err := system.LoadFavorites(favorites)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(restored) != 1 || restored[0].Label != "logs" {
+		t.Errorf("load should restore favorites, got %+v", restored)
+	}
+}
+
+func TestProfile_SwitchingUndoesEnvironment(t *testing.T) {
+	_, cleanup := withHome(t)
+	defer cleanup()
+	defer os.Unsetenv("PROFILE_TEST_A")
+	defer os.Unsetenv("PROFILE_TEST_B")
+
+	profile := system.NewProfileCommand()
+	_, restore := inDir(t)
+	defer restore()
+
+	if result := runProfile(t, profile, "save", "a", "--env", "PROFILE_TEST_A=on"); result.ExitCode != 0 {
+		t.Fatalf("save failed: %s", result.Output)
+	}
+	if result := runProfile(t, profile, "save", "b", "--env", "PROFILE_TEST_B=on"); result.ExitCode != 0 {
+		t.Fatalf("save failed: %s", result.Output)
+	}
+
+	runProfile(t, profile, "load", "a")
+	if os.Getenv("PROFILE_TEST_A") != "on" {
+		t.Fatal("loading a should set PROFILE_TEST_A")
+	}
+	runProfile(t, profile, "load", "b")
+	if _, set := os.LookupEnv("PROFILE_TEST_A"); set {
+		t.Error("switching to b should undo what a set")
+	}
+	if os.Getenv("PROFILE_TEST_B") != "on" {
+		t.Error("loading b should set PROFILE_TEST_B")
+	}
+
+	list := runProfile(t, profile, "list").Output
+	for _, line := range strings.Split(list, "\n") {
+		if strings.Contains(line, "▶ ") && !strings.Contains(line, "▶ b  ") {
+			t.Errorf("list should mark only b as active:\n%s", list)
+		}
+	}
+	if !strings.Contains(list, "▶ b  ") {
+		t.Errorf("list should mark b as active:\n%s", list)
+	}
+}
+
+func TestProfile_DeleteAndErrors(t *testing.T) {
+	_, cleanup := withHome(t)
+	defer cleanup()
+
+	profile := system.NewProfileCommand()
+	_, restore := inDir(t)
+	defer restore()
+
+	runProfile(t, profile, "save", "tmp")
+	if result := runProfile(t, profile, "delete", "tmp"); result.ExitCode != 0 {
+		t.Fatalf("delete failed: %s", result.Output)
+	}
+	if list := runProfile(t, profile, "list").Output; strings.Contains(list, "tmp") {
+		t.Errorf("deleted profile still listed:\n%s", list)
+	}
+	if result := runProfile(t, profile, "load", "tmp"); result.ExitCode == 0 {
+		t.Error("loading a missing profile should fail")
+	}
+	if result := runProfile(t, profile, "save", "bad name"); result.ExitCode == 0 {
+		t.Error("a name with spaces should be rejected")
+	}
+	if result := runProfile(t, profile, "save", "x", "--env", "NOEQUALS"); result.ExitCode == 0 {
+		t.Error("--env without = should be rejected")
+	}
+	if result := runProfile(t, profile, "load", "x", "--env", "A=1"); result.Error == nil {
+		t.Error("--env outside save should be a usage error")
+	}
+}
+
+func TestConfig_BundleCarriesProfiles(t *testing.T) {
+	bundleDir, err := ioutil.TempDir("", "supershell-bundle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(bundleDir)
+	bundle := filepath.Join(bundleDir, "bundle.json")
+
+	source, cleanup := withHome(t)
+	if err := system.SaveProfiles(filepath.Join(source, ".supershell", "profiles.json"), []system.Profile{{Name: "work", Dir: source}}); err != nil {
+		t.Fatal(err)
+	}
+	result := runConfig(t, nil, "export", bundle)
+	cleanup()
+	if result.ExitCode != 0 {
+		t.Fatalf("export failed: %s", result.Output)
+	}
+
+	target, cleanup := withHome(t)
+	defer cleanup()
+	result = runConfig(t, nil, "import", bundle)
+	if result.ExitCode != 0 {
+		t.Fatalf("import failed: %s", result.Output)
+	}
+	profiles, err := system.LoadProfiles(filepath.Join(target, ".supershell", "profiles.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profiles) != 1 || profiles[0].Name != "work" {
+		t.Errorf("import should bring the profile along, got %+v", profiles)
+	}
+}
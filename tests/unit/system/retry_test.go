@@ -0,0 +1,117 @@
+package system_test
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/commands/system"
+	"suppercommand/internal/config"
+	"suppercommand/internal/monitoring"
+)
+
+// flakyCommand fails until it has run the number of times given as its first
+// argument: "flaky <runs> <flags...>"
+type flakyCommand struct {
+	*commands.BaseCommand
+	runs []string
+}
+
+func (f *flakyCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	f.runs = append(f.runs, strings.Join(args.Raw, " "))
+	needed, _ := strconv.Atoi(args.Raw[0])
+	if len(f.runs) < needed {
+		return &commands.Result{Output: "not yet\n", ExitCode: 2}, nil
+	}
+	return &commands.Result{Output: "up\n", ExitCode: 0}, nil
+}
+
+func newRetry(t *testing.T) (*system.RetryCommand, *flakyCommand) {
+	t.Helper()
+	registry := commands.NewRegistry(monitoring.NewLogger(config.MonitoringConfig{}))
+	flaky := &flakyCommand{BaseCommand: commands.NewBaseCommand("flaky", "Fail a few times", "flaky <runs>", nil, false)}
+	if err := registry.Register(flaky); err != nil {
+		t.Fatal(err)
+	}
+	return system.NewRetryCommand(registry), flaky
+}
+
+func TestRetry_RunsUntilSuccess(t *testing.T) {
+	retry, flaky := newRetry(t)
+	var progress bytes.Buffer
+	ctx := commands.WithErrorWriter(context.Background(), &progress)
+
+	result, err := retry.Execute(ctx, commands.ParseArguments([]string{"--attempts", "5", "--delay", "1ms", "flaky", "3", "--verbose"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ExitCode != 0 || result.Output != "up\n" {
+		t.Fatalf("expected the successful attempt's result, got %d %q", result.ExitCode, result.Output)
+	}
+	// The retried command's own flags reach it untouched
+	if len(flaky.runs) != 3 || flaky.runs[0] != "3 --verbose" {
+		t.Errorf("expected 3 runs of '3 --verbose', got %q", flaky.runs)
+	}
+	if strings.Count(progress.String(), "retrying in") != 2 || !strings.Contains(result.Stderr, "succeeded on attempt 3 of 5") {
+		t.Errorf("unexpected report %q / %q", progress.String(), result.Stderr)
+	}
+}
+
+func TestRetry_GivesUp(t *testing.T) {
+	retry, flaky := newRetry(t)
+
+	result, _ := retry.Execute(context.Background(), commands.ParseArguments([]string{"-n", "2", "-d", "1ms", "--backoff", "2", "flaky 9"}))
+	if result.ExitCode != 2 || result.Output != "not yet\n" || len(flaky.runs) != 2 {
+		t.Errorf("expected the last failure after 2 runs, got %d %q after %d", result.ExitCode, result.Output, len(flaky.runs))
+	}
+	if !strings.Contains(result.Stderr, "still failing after 2 attempts, exit code 2") {
+		t.Errorf("unexpected outcome %q", result.Stderr)
+	}
+
+	// --until-failure stops at the first failing run instead
+	retry, flaky = newRetry(t)
+	result, _ = retry.Execute(context.Background(), commands.ParseArguments([]string{"--until-failure", "-d", "0s", "flaky", "0"}))
+	if result.ExitCode != 0 || len(flaky.runs) != 3 || !strings.Contains(result.Stderr, "never failed in 3 attempts") {
+		t.Errorf("expected 3 successful runs, got %d after %d: %q", result.ExitCode, len(flaky.runs), result.Stderr)
+	}
+}
+
+func TestRetry_StopsWaitingWhenCancelled(t *testing.T) {
+	retry, flaky := newRetry(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	result, _ := retry.Execute(ctx, commands.ParseArguments([]string{"--attempts", "5", "--delay", "1m", "flaky", "9"}))
+	if time.Since(start) > 5*time.Second {
+		t.Fatalf("retry kept waiting after cancellation")
+	}
+	if result.ExitCode == 0 || result.Error == nil || len(flaky.runs) != 1 || !strings.Contains(result.Stderr, "interrupted after 1 of 5 attempts") {
+		t.Errorf("expected an interrupted failure after one run, got %d %v after %d: %q", result.ExitCode, result.Error, len(flaky.runs), result.Stderr)
+	}
+}
+
+func TestRetry_RejectsBadOptions(t *testing.T) {
+	retry, _ := newRetry(t)
+	for _, raw := range [][]string{
+		{},
+		{"--attempts", "0", "flaky", "1"},
+		{"--delay", "soon", "flaky", "1"},
+		{"--backoff", "0.5", "flaky", "1"},
+		{"--until-success", "--until-failure", "flaky", "1"},
+	} {
+		result, _ := retry.Execute(context.Background(), commands.ParseArguments(raw))
+		if result.ExitCode != 1 || !strings.HasPrefix(result.Output, "Usage: ") {
+			t.Errorf("%q: expected a usage error, got %d %q", raw, result.ExitCode, result.Output)
+		}
+	}
+
+	result, _ := retry.Execute(context.Background(), commands.ParseArguments([]string{"no-such-command"}))
+	if result.ExitCode == 0 || result.Error == nil || !strings.Contains(result.Error.Error(), "unknown command 'no-such-command'") {
+		t.Errorf("expected an unknown command error, got %d %v", result.ExitCode, result.Error)
+	}
+}
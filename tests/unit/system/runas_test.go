@@ -0,0 +1,133 @@
+package system_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/commands/filesystem"
+	"suppercommand/internal/commands/system"
+	"suppercommand/internal/config"
+	"suppercommand/internal/monitoring"
+	"suppercommand/internal/security"
+)
+
+// runAsFixture returns a runas command with echo registered whose user switch
+// records the account and line it runs, and exits with code
+func runAsFixture(t *testing.T, needsPassword bool, code int) (*system.RunAsCommand, *[]string) {
+	t.Helper()
+	registry := commands.NewRegistry(monitoring.NewLogger(config.MonitoringConfig{}))
+	if err := registry.Register(filesystem.NewEchoCommand()); err != nil {
+		t.Fatal(err)
+	}
+
+	var ran []string
+	switcher := system.UserSwitch{
+		NeedsPassword: needsPassword,
+		Method:        func() (string, error) { return "test", nil },
+		ReadPassword:  func(string) (string, error) { return "typed", nil },
+		LoadCredential: func(name string) (*security.Credential, error) {
+			switch name {
+			case "svc":
+				return &security.Credential{Name: name, Fields: map[string]string{"username": "svc", "password": "stored"}}, nil
+			case "nopass":
+				return &security.Credential{Name: name, Fields: map[string]string{"username": "svc"}}, nil
+			}
+			return nil, fmt.Errorf("credential '%s' not found", name)
+		},
+		Run: func(ctx context.Context, account security.Account, line string, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+			ran = append(ran, fmt.Sprintf("%s|%s|%s", account, account.Password, line))
+			fmt.Fprintln(stdout, "output as", account.User)
+			return code, nil
+		},
+	}
+	return system.NewRunAsCommandWithSwitch(registry, switcher), &ran
+}
+
+func runRunAs(t *testing.T, runas *system.RunAsCommand, args ...string) (*commands.Result, string) {
+	t.Helper()
+	var out strings.Builder
+	ctx := commands.WithOutputWriter(context.Background(), &out)
+	result, err := runas.Execute(ctx, commands.ParseArguments(args))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result, out.String()
+}
+
+func TestRunAs_RunsLineAsUser(t *testing.T) {
+	runas, ran := runAsFixture(t, false, 3)
+	result, out := runRunAs(t, runas, "alice", "echo", "-n", "hello world")
+	if result.ExitCode != 3 || result.Error != nil {
+		t.Fatalf("runas should exit with the command's code, got %d (%v)", result.ExitCode, result.Error)
+	}
+	if len(*ran) != 1 || (*ran)[0] != `alice||echo -n "hello world"` {
+		t.Errorf("unexpected run: %v", *ran)
+	}
+	if !strings.Contains(out, "output as alice") {
+		t.Errorf("the output should be streamed, got %q", out)
+	}
+}
+
+func TestRunAs_Passwords(t *testing.T) {
+	runas, ran := runAsFixture(t, true, 0)
+	if result, _ := runRunAs(t, runas, `CORP\svc`, "echo", "hi"); result.ExitCode != 0 {
+		t.Fatalf("runas failed: %v", result.Error)
+	}
+	if result, _ := runRunAs(t, runas, "--cred", "svc", "svc@corp", "echo", "hi"); result.ExitCode != 0 {
+		t.Fatalf("runas --cred failed: %v", result.Error)
+	}
+	want := []string{`CORP\svc|typed|echo hi`, `corp\svc|stored|echo hi`}
+	if strings.Join(*ran, "\n") != strings.Join(want, "\n") {
+		t.Errorf("runs = %v, want %v", *ran, want)
+	}
+
+	for _, args := range [][]string{
+		{"--cred", "nopass", "svc", "echo"},
+		{"--cred", "svc", "bob", "echo"},
+		{"--cred", "missing", "svc", "echo"},
+	} {
+		result, _ := runRunAs(t, runas, args...)
+		if result.ExitCode == 0 || result.Error == nil {
+			t.Errorf("runas %v should fail", args)
+		}
+	}
+	if len(*ran) != 2 {
+		t.Errorf("nothing should run without a usable password, ran %v", *ran)
+	}
+}
+
+func TestRunAs_RejectsBadArguments(t *testing.T) {
+	runas, ran := runAsFixture(t, false, 0)
+	for _, args := range [][]string{
+		{},
+		{"alice"},
+		{"alice", "nosuchcommand"},
+		{"--cred", "svc", "alice", "echo"},
+	} {
+		result, _ := runRunAs(t, runas, args...)
+		if result.ExitCode == 0 {
+			t.Errorf("runas %v should fail", args)
+		}
+	}
+	if len(*ran) != 0 {
+		t.Errorf("nothing should run, ran %v", *ran)
+	}
+}
+
+func TestRunAs_ReportsSwitchFailure(t *testing.T) {
+	registry := commands.NewRegistry(monitoring.NewLogger(config.MonitoringConfig{}))
+	registry.Register(filesystem.NewEchoCommand())
+	failing := system.UserSwitch{
+		Method: func() (string, error) { return "", errors.New("sudo is not installed") },
+	}
+	runas := system.NewRunAsCommandWithSwitch(registry, failing)
+	result, _ := runRunAs(t, runas, "alice", "echo", "hi")
+	if result.ExitCode == 0 || result.Error == nil || !strings.Contains(result.Error.Error(), "sudo is not installed") {
+		t.Errorf("a missing switch should be reported, got %v", result.Error)
+	}
+}
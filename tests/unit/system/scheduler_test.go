@@ -0,0 +1,66 @@
+package system_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/commands/system"
+	"suppercommand/internal/config"
+	"suppercommand/internal/monitoring"
+)
+
+// progressCommand draws a progress line as it runs before returning its
+// result: "progress"
+type progressCommand struct {
+	*commands.BaseCommand
+}
+
+func (p *progressCommand) Execute(ctx context.Context, args *commands.Arguments) (*commands.Result, error) {
+	progress := commands.ProgressWriter(ctx)
+	for percent := 0; percent <= 100; percent += 50 {
+		fmt.Fprintf(progress, "\r\x1b[KCopying %d%%", percent)
+	}
+	fmt.Fprintln(progress)
+	fmt.Fprintln(commands.OutputWriter(ctx), "copied 3 files")
+	return &commands.Result{Output: "done\n"}, nil
+}
+
+func TestScheduler_RunTaskLogsPrintedOutput(t *testing.T) {
+	_, cleanup := withHome(t)
+	defer cleanup()
+
+	registry := commands.NewRegistry(monitoring.NewLogger(config.MonitoringConfig{}))
+	if err := registry.Register(&progressCommand{commands.NewBaseCommand("progress", "Draw progress", "progress", nil, false)}); err != nil {
+		t.Fatal(err)
+	}
+	scheduler := system.NewScheduler(registry)
+	task, err := scheduler.AddTask("0 2 * * *", "progress")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := scheduler.RunTask(context.Background(), task.ID)
+	if err != nil || result.ExitCode != 0 {
+		t.Fatalf("RunTask() = %+v, %v", result, err)
+	}
+
+	data, err := ioutil.ReadFile(scheduler.LogFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := string(data)
+	for _, want := range []string{"  Copying 100%\n", "  copied 3 files\n", "  done\n"} {
+		if !strings.Contains(log, want) {
+			t.Errorf("log should contain %q:\n%s", want, log)
+		}
+	}
+	for _, unwanted := range []string{"\r", "\x1b", "Copying 50%"} {
+		if strings.Contains(log, unwanted) {
+			t.Errorf("log should not contain %q:\n%q", unwanted, log)
+		}
+	}
+}
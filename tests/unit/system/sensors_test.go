@@ -0,0 +1,124 @@
+package system_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"suppercommand/internal/commands/system"
+)
+
+// writeSysfs creates files under root, creating their directories as needed
+func writeSysfs(t *testing.T, root string, files map[string]string) {
+	for name, value := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(value+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestReadHwmon(t *testing.T) {
+	root, err := ioutil.TempDir("", "hwmon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	writeSysfs(t, root, map[string]string{
+		"hwmon0/name":         "coretemp",
+		"hwmon0/temp1_input":  "52000",
+		"hwmon0/temp1_label":  "Package id 0",
+		"hwmon0/temp1_max":    "84000",
+		"hwmon0/temp1_crit":   "100000",
+		"hwmon0/temp10_input": "48000",
+		"hwmon0/temp2_input":  "50500",
+		"hwmon0/fan1_input":   "1850",
+		"hwmon0/fan1_min":     "600",
+		"hwmon1/name":         "nvme",
+		"hwmon1/temp1_input":  "38850",
+		"hwmon1/temp1_crit":   "0",
+		"hwmon2/name":         "nvme",
+		"hwmon2/temp1_input":  "41000",
+	})
+
+	readings := system.ReadHwmon(root)
+	if len(readings) != 6 {
+		t.Fatalf("len(readings) = %d, want 6: %+v", len(readings), readings)
+	}
+
+	pkg := readings[0]
+	if pkg.Chip != "coretemp" || pkg.Label != "Package id 0" || pkg.Kind != system.SensorTemperature {
+		t.Errorf("readings[0] = %+v", pkg)
+	}
+	if pkg.Value != 52 || pkg.High != 84 || pkg.Critical != 100 {
+		t.Errorf("readings[0] value = %v high = %v crit = %v", pkg.Value, pkg.High, pkg.Critical)
+	}
+	// Inputs are ordered numerically, not by file name
+	if readings[1].Label != "temp2" || readings[2].Label != "temp10" {
+		t.Errorf("labels = %q, %q, want temp2, temp10", readings[1].Label, readings[2].Label)
+	}
+	if fan := readings[3]; fan.Kind != system.SensorFan || fan.Value != 1850 || fan.Min != 600 || fan.Unit != "RPM" {
+		t.Errorf("readings[3] = %+v", fan)
+	}
+
+	if readings[4].Critical != 0 {
+		t.Errorf("a zero threshold should be dropped, got %v", readings[4].Critical)
+	}
+	if readings[4].Chip != "nvme" || readings[5].Chip != "nvme #2" {
+		t.Errorf("chips = %q, %q, want nvme, nvme #2", readings[4].Chip, readings[5].Chip)
+	}
+
+	if system.ReadHwmon(filepath.Join(root, "missing")) != nil {
+		t.Error("ReadHwmon() of a missing directory should return nothing")
+	}
+}
+
+func TestReadThermalZones(t *testing.T) {
+	root, err := ioutil.TempDir("", "thermal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	writeSysfs(t, root, map[string]string{
+		"thermal_zone0/type":              "acpitz",
+		"thermal_zone0/temp":              "45000",
+		"thermal_zone0/trip_point_0_type": "critical",
+		"thermal_zone0/trip_point_0_temp": "105000",
+		"thermal_zone0/trip_point_1_type": "hot",
+		"thermal_zone0/trip_point_1_temp": "95000",
+		"cooling_device0/type":            "Processor",
+	})
+
+	readings := system.ReadThermalZones(root)
+	if len(readings) != 1 {
+		t.Fatalf("len(readings) = %d, want 1", len(readings))
+	}
+	zone := readings[0]
+	if zone.Label != "acpitz" || zone.Value != 45 || zone.Critical != 105 || zone.High != 95 {
+		t.Errorf("readings[0] = %+v", zone)
+	}
+}
+
+func TestParseWindowsThermal(t *testing.T) {
+	data := []byte(`[
+		{"Name":"\\_TZ.THRM","Kelvin":323.15,"CriticalKelvin":373.15},
+		{"Name":"\\_TZ.TZ01","Kelvin":0,"CriticalKelvin":0}
+	]`)
+
+	readings, err := system.ParseWindowsThermal(data)
+	if err != nil {
+		t.Fatalf("ParseWindowsThermal() error = %v", err)
+	}
+	if len(readings) != 1 {
+		t.Fatalf("len(readings) = %d, want 1", len(readings))
+	}
+	if readings[0].Label != "THRM" || int(readings[0].Value+0.5) != 50 || int(readings[0].Critical+0.5) != 100 {
+		t.Errorf("readings[0] = %+v", readings[0])
+	}
+}
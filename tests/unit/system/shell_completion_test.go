@@ -0,0 +1,81 @@
+package system_test
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/commands/system"
+	"suppercommand/internal/config"
+	"suppercommand/internal/monitoring"
+)
+
+func completionRegistry(t *testing.T) *commands.Registry {
+	registry := commands.NewRegistry(monitoring.NewLogger(config.MonitoringConfig{}))
+	for _, cmd := range []commands.Command{system.NewBatteryCommand(), system.NewTemplateCommand(), system.NewFavCommand(registry)} {
+		if err := registry.Register(cmd); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return registry
+}
+
+func TestRegistryDescribe(t *testing.T) {
+	infos := completionRegistry(t).Describe()
+	if len(infos) != 3 || infos[0].Name != "battery" || infos[1].Name != "fav" || infos[2].Name != "template" {
+		t.Fatalf("Describe() should list commands by name, got %+v", infos)
+	}
+	if !reflect.DeepEqual(infos[0].Arguments, []string{"--json"}) {
+		t.Errorf("battery arguments = %v", infos[0].Arguments)
+	}
+	// Subcommands from the command itself come first, then flags from the usage
+	if args := infos[2].Arguments; len(args) < 3 || args[0] != "list" || args[1] != "new" || !strings.Contains(strings.Join(args, " "), "--force") {
+		t.Errorf("template arguments = %v", args)
+	}
+}
+
+func TestCompletionScript(t *testing.T) {
+	infos := completionRegistry(t).Describe()
+	for _, shell := range system.CompletionShells {
+		script, err := system.CompletionScript(shell, infos)
+		if err != nil {
+			t.Fatalf("%s: %v", shell, err)
+		}
+		if !strings.Contains(script, "battery") || !strings.Contains(script, "--json") {
+			t.Errorf("%s script is missing commands or flags:\n%s", shell, script)
+		}
+	}
+	if _, err := system.CompletionScript("tcsh", infos); err == nil {
+		t.Error("unsupported shells should fail")
+	}
+
+	bash, _ := system.CompletionScript("bash", infos)
+	if !strings.Contains(bash, "'battery') words='--json' ;;") || !strings.Contains(bash, "complete -o default -F _supershell supershell") {
+		t.Errorf("unexpected bash script:\n%s", bash)
+	}
+}
+
+func TestInstallCompletion(t *testing.T) {
+	home, cleanup := withHome(t)
+	defer cleanup()
+
+	path, err := system.CompletionInstallPath("fish", home)
+	if err != nil || path != filepath.Join(home, ".config", "fish", "completions", "supershell.fish") {
+		t.Errorf("CompletionInstallPath(fish) = %q, %v", path, err)
+	}
+
+	cmd := system.NewInstallCompletionCommand(completionRegistry(t))
+	target := filepath.Join(home, "completions", "supershell.bash")
+	result, err := cmd.Execute(context.Background(), commands.ParseArguments([]string{"bash", "--path", target}))
+	if err != nil || result.ExitCode != 0 {
+		t.Fatalf("install-completion failed: %v\n%s", err, result.Output)
+	}
+	data, err := ioutil.ReadFile(target)
+	if err != nil || !strings.Contains(string(data), "_supershell()") {
+		t.Errorf("script not written: %v", err)
+	}
+}
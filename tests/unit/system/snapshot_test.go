@@ -0,0 +1,148 @@
+package system_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/commands/system"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	from := &system.SystemSnapshot{
+		Name:     "before",
+		TakenAt:  time.Date(2024, 3, 10, 9, 0, 0, 0, time.UTC),
+		Packages: map[string]string{"curl": "7.88", "nginx": "1.22", "telnet": "0.17"},
+		Services: []string{"cron.service", "nginx.service"},
+		Ports:    []string{"tcp 0.0.0.0:22", "tcp 0.0.0.0:80"},
+		Network:  []string{"dns 8.8.8.8", "gateway 10.0.0.1"},
+	}
+	to := &system.SystemSnapshot{
+		Name:     "after",
+		TakenAt:  time.Date(2024, 3, 11, 9, 0, 0, 0, time.UTC),
+		Packages: map[string]string{"curl": "8.5", "nginx": "1.22", "redis": "7.2"},
+		Services: []string{"cron.service", "nginx.service", "redis.service"},
+		Ports:    []string{"tcp 0.0.0.0:22", "tcp 0.0.0.0:80", "tcp 127.0.0.1:6379"},
+		Network:  []string{"dns 1.1.1.1", "gateway 10.0.0.1"},
+	}
+
+	diff := system.DiffSnapshots(from, to)
+	if diff.From != "before" || diff.To != "after" {
+		t.Errorf("From, To = %q, %q", diff.From, diff.To)
+	}
+
+	sections := make(map[string]system.SectionDiff)
+	for _, section := range diff.Sections {
+		sections[section.Section] = section
+	}
+
+	packages := sections[system.SectionPackages]
+	if !reflect.DeepEqual(packages.Added, []string{"redis 7.2"}) {
+		t.Errorf("packages added = %v", packages.Added)
+	}
+	if !reflect.DeepEqual(packages.Removed, []string{"telnet 0.17"}) {
+		t.Errorf("packages removed = %v", packages.Removed)
+	}
+	if !reflect.DeepEqual(packages.Changed, []string{"curl: 7.88 → 8.5"}) {
+		t.Errorf("packages changed = %v", packages.Changed)
+	}
+
+	if services := sections[system.SectionServices]; !reflect.DeepEqual(services.Added, []string{"redis.service"}) || len(services.Removed) != 0 {
+		t.Errorf("services = %+v", services)
+	}
+	if ports := sections[system.SectionPorts]; !reflect.DeepEqual(ports.Added, []string{"tcp 127.0.0.1:6379"}) {
+		t.Errorf("ports = %+v", ports)
+	}
+	network := sections[system.SectionNetwork]
+	if !reflect.DeepEqual(network.Added, []string{"dns 1.1.1.1"}) || !reflect.DeepEqual(network.Removed, []string{"dns 8.8.8.8"}) {
+		t.Errorf("network = %+v", network)
+	}
+
+	if got := diff.Changes(); got != 7 {
+		t.Errorf("Changes() = %d, want 7", got)
+	}
+}
+
+func TestDiffSnapshots_SkipsUnavailableSections(t *testing.T) {
+	from := &system.SystemSnapshot{Services: []string{"sshd"}, Ports: []string{"tcp 0.0.0.0:22"}}
+	to := &system.SystemSnapshot{Ports: []string{"tcp 0.0.0.0:22"}, Unavailable: []string{system.SectionServices}}
+
+	diff := system.DiffSnapshots(from, to)
+	for _, section := range diff.Sections {
+		if section.Section == system.SectionServices {
+			if !section.Skipped || len(section.Removed) != 0 {
+				t.Errorf("services = %+v, want skipped without removals", section)
+			}
+		}
+	}
+	if diff.Changes() != 0 {
+		t.Errorf("Changes() = %d, want 0", diff.Changes())
+	}
+}
+
+func TestSaveLoadSnapshot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "nested", "base.json")
+	snapshot := &system.SystemSnapshot{
+		Name:     "base",
+		Host:     "web1",
+		TakenAt:  time.Date(2024, 3, 10, 9, 0, 0, 0, time.UTC),
+		Packages: map[string]string{"curl": "8.5"},
+		Ports:    []string{"tcp 0.0.0.0:22"},
+	}
+	if err := system.SaveSnapshot(snapshot, path); err != nil {
+		t.Fatalf("SaveSnapshot() error = %v", err)
+	}
+
+	loaded, err := system.LoadSnapshot(path)
+	if err != nil {
+		t.Fatalf("LoadSnapshot() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded, snapshot) {
+		t.Errorf("LoadSnapshot() = %+v, want %+v", loaded, snapshot)
+	}
+
+	if err := ioutil.WriteFile(path, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := system.LoadSnapshot(path); err == nil {
+		t.Error("LoadSnapshot() should fail on a corrupt file")
+	}
+}
+
+func TestTakeSnapshot(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("reads dpkg-query, systemctl and ss output")
+	}
+	mock := commands.NewMockRunner().
+		On("dpkg-query -W -f ${Package}\t${Version}\t${Status}\n",
+			"curl\t7.88\tinstall ok installed\ntelnet\t0.17\tdeinstall ok config-files\n").
+		On("systemctl list-units --type=service --state=running --no-legend --plain",
+			"nginx.service loaded active running nginx\ncron.service loaded active running cron\n")
+
+	snapshot := system.TakeSnapshot(context.Background(), mock, "test")
+	if !reflect.DeepEqual(snapshot.Packages, map[string]string{"curl": "7.88"}) {
+		t.Errorf("packages = %v", snapshot.Packages)
+	}
+	if !reflect.DeepEqual(snapshot.Services, []string{"cron.service", "nginx.service"}) {
+		t.Errorf("services = %v", snapshot.Services)
+	}
+	// Neither ss nor netstat has output, so the ports can't be read
+	if !reflect.DeepEqual(snapshot.Unavailable, []string{system.SectionPorts}) {
+		t.Errorf("unavailable = %v", snapshot.Unavailable)
+	}
+	if !mock.Ran("ss -tuln") || !mock.Ran("netstat -an") {
+		t.Errorf("the ports should be read with ss, then netstat; ran %v", mock.Calls())
+	}
+}
@@ -0,0 +1,129 @@
+package system_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/commands/system"
+	"suppercommand/internal/config"
+	"suppercommand/internal/monitoring"
+)
+
+// newSource returns a source command whose registry has record, each, and
+// rm and firewall commands recording what they were asked to do
+func newSource(t *testing.T, policy config.ScriptsConfig) (*system.SourceCommand, map[string]*recordCommand) {
+	t.Helper()
+	registry := commands.NewRegistry(monitoring.NewLogger(config.MonitoringConfig{}))
+	records := make(map[string]*recordCommand)
+	for _, name := range []string{"record", "rm", "firewall"} {
+		records[name] = &recordCommand{BaseCommand: commands.NewBaseCommand(name, "Record runs", name+" <args...>", nil, false)}
+		if err := registry.Register(records[name]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := registry.Register(system.NewEachCommand(registry)); err != nil {
+		t.Fatal(err)
+	}
+	source := system.NewSourceCommand(registry, policy)
+	if err := registry.Register(source); err != nil {
+		t.Fatal(err)
+	}
+	return source, records
+}
+
+func writeScript(t *testing.T, content string) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "supershell-source")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	path := filepath.Join(dir, "script.ss")
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSource_RunsEveryLine(t *testing.T) {
+	source, records := newSource(t, config.ScriptsConfig{Guard: "run", GuardedCommands: []string{"rm"}})
+	script := writeScript(t, "# Clean up\nrecord one\n\nrecord bad && record skipped\nrecord bad || record rescued\nrm -rf build\nnosuchcommand\n")
+
+	var out bytes.Buffer
+	ctx := commands.WithErrorWriter(commands.WithOutputWriter(context.Background(), &out), ioutil.Discard)
+	result, err := source.Execute(ctx, commands.ParseArguments([]string{script}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(records["record"].runs, ";"); got != "one;bad;bad;rescued" {
+		t.Errorf("unexpected runs %q", got)
+	}
+	if len(records["rm"].runs) != 1 {
+		t.Errorf("rm should run when the guard is off, ran %q", records["rm"].runs)
+	}
+	if !strings.Contains(out.String(), "one\n") || !strings.Contains(out.String(), "rescued\n") {
+		t.Errorf("unexpected output %q", out.String())
+	}
+	if result.ExitCode != 1 || result.Error == nil || !strings.Contains(result.Error.Error(), "2 of 5 lines failed") {
+		t.Errorf("expected the failed lines to fail source, got %+v", result)
+	}
+}
+
+func TestSource_SafeBlocksGuardedCommands(t *testing.T) {
+	source, records := newSource(t, config.ScriptsConfig{Guard: "run", GuardedCommands: []string{"rm", "firewall disable"}})
+	script := writeScript(t, "record start\nrm -rf /srv/data\nfirewall status\nfirewall disable\nrecord end\n")
+
+	var diagnostics bytes.Buffer
+	ctx := commands.WithErrorWriter(commands.WithOutputWriter(context.Background(), ioutil.Discard), &diagnostics)
+	ctx = commands.WithInputReader(ctx, strings.NewReader("old.log\nnew.log\n"))
+	result, _ := source.Execute(ctx, commands.ParseArguments([]string{"--safe", script}))
+
+	if len(records["rm"].runs) != 0 || strings.Join(records["firewall"].runs, ";") != "status" {
+		t.Errorf("guarded commands ran: rm %q, firewall %q", records["rm"].runs, records["firewall"].runs)
+	}
+	if strings.Join(records["record"].runs, ";") != "start;end" {
+		t.Errorf("the rest of the script should run, got %q", records["record"].runs)
+	}
+	if result.ExitCode != 1 || result.Error == nil || !strings.Contains(result.Error.Error(), "2 guarded commands blocked") {
+		t.Errorf("expected source to fail with the blocked commands, got %+v", result)
+	}
+	for _, want := range []string{"line 2: rm -rf /srv/data", "line 4: firewall disable"} {
+		if !strings.Contains(result.Stderr, want) {
+			t.Errorf("expected %q in the report, got %q", want, result.Stderr)
+		}
+	}
+	if !strings.Contains(diagnostics.String(), "script.ss:2:") {
+		t.Errorf("expected each block to be reported as it happens, got %q", diagnostics.String())
+	}
+
+	// Commands reached through each are guarded too
+	script = writeScript(t, "each rm {}\n")
+	result, _ = source.Execute(ctx, commands.ParseArguments([]string{"--safe", script}))
+	if len(records["rm"].runs) != 0 || !strings.Contains(result.Stderr, "line 1: rm old.log") || !strings.Contains(result.Stderr, "line 1: rm new.log") {
+		t.Errorf("rm run by each should be blocked, ran %q: %q", records["rm"].runs, result.Stderr)
+	}
+}
+
+func TestSource_PolicyAndArguments(t *testing.T) {
+	source, records := newSource(t, config.ScriptsConfig{Guard: "block", GuardedCommands: []string{"rm"}})
+	script := writeScript(t, "rm x\n")
+	ctx := commands.WithErrorWriter(commands.WithOutputWriter(context.Background(), ioutil.Discard), ioutil.Discard)
+	if result, _ := source.Execute(ctx, commands.ParseArguments([]string{script})); result.ExitCode != 1 || len(records["rm"].runs) != 0 {
+		t.Errorf("guard: block should apply without --safe, got %+v", result)
+	}
+
+	for _, args := range [][]string{{}, {script, script}, {"--safe", "--confirm", script}} {
+		if result, _ := source.Execute(ctx, commands.ParseArguments(args)); result.ExitCode != 1 || !strings.HasPrefix(result.Output, "Usage: ") {
+			t.Errorf("%q: expected a usage error, got %+v", args, result)
+		}
+	}
+	if result, _ := source.Execute(ctx, commands.ParseArguments([]string{"/nonexistent/script.ss"})); result.ExitCode != 1 || result.Error == nil {
+		t.Errorf("expected a missing script to fail, got %+v", result)
+	}
+}
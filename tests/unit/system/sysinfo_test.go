@@ -0,0 +1,42 @@
+package system_test
+
+import (
+	"context"
+	"encoding/json"
+	"runtime"
+	"strings"
+	"testing"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/commands/system"
+	"suppercommand/internal/config"
+	"suppercommand/internal/monitoring"
+)
+
+func TestSysInfoOutputFormats(t *testing.T) {
+	registry := commands.NewRegistry(monitoring.NewLogger(config.MonitoringConfig{}))
+	registry.Register(system.NewSysInfoCommand())
+	run := func(args ...string) string {
+		result, err := registry.Execute(context.Background(), "sysinfo", commands.ParseArguments(args))
+		if err != nil || result.ExitCode != 0 {
+			t.Fatalf("sysinfo %q failed: %v", args, err)
+		}
+		return result.Output
+	}
+
+	csv := run("--output-format", "csv")
+	if !strings.HasPrefix(csv, "field,value\nos,"+runtime.GOOS+"\n") {
+		t.Errorf("unexpected CSV:\n%s", csv)
+	}
+	if quiet := run("--quiet"); !strings.HasPrefix(quiet, "os: "+runtime.GOOS+"\n") || strings.Contains(quiet, "═") {
+		t.Errorf("quiet output should be plain fields:\n%s", quiet)
+	}
+
+	// --json is still accepted as a shorthand
+	for _, args := range [][]string{{"--output-format", "json"}, {"--json"}} {
+		var report map[string]interface{}
+		if err := json.Unmarshal([]byte(run(args...)), &report); err != nil || report["os"] != runtime.GOOS {
+			t.Errorf("sysinfo %q: invalid JSON report: %v", args, err)
+		}
+	}
+}
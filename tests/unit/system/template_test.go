@@ -0,0 +1,101 @@
+package system_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"suppercommand/internal/commands"
+	"suppercommand/internal/commands/system"
+	"suppercommand/internal/config"
+)
+
+func TestConfigTemplateMatchesDefaults(t *testing.T) {
+	template, ok := system.FindTemplate("config")
+	if !ok {
+		t.Fatal("config template missing")
+	}
+	dir, err := ioutil.TempDir("", "template")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "supershell.yaml")
+	if err := ioutil.WriteFile(path, []byte(template.Content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := config.NewLoader().Load(path)
+	if err != nil {
+		t.Fatalf("config template doesn't load: %v", err)
+	}
+
+	// The template documents the defaults, so loading it must change nothing
+	defaults := config.NewLoader().LoadWithDefaults()
+	if !reflect.DeepEqual(loaded.Shell, defaults.Shell) || !reflect.DeepEqual(loaded.Monitoring, defaults.Monitoring) ||
+		!reflect.DeepEqual(loaded.Networking, defaults.Networking) || loaded.Commands.Timeout != defaults.Commands.Timeout ||
+		loaded.Intelligence.ResponseTimeout != defaults.Intelligence.ResponseTimeout ||
+		loaded.Security.MaxInputLength != defaults.Security.MaxInputLength ||
+		!reflect.DeepEqual(loaded.Security.Scripts, defaults.Security.Scripts) {
+		t.Errorf("config template differs from the defaults:\n got %+v\nwant %+v", loaded, defaults)
+	}
+}
+
+func TestTemplatePlaceholders(t *testing.T) {
+	template, _ := system.FindTemplate("backup")
+	want := []string{"<SOURCE_DIR>", "<BUCKET>", "<CRED_NAME>", "<BACKUP_ID>"}
+	if got := system.TemplatePlaceholders(template.Content); !reflect.DeepEqual(got, want) {
+		t.Errorf("TemplatePlaceholders() = %v, want %v", got, want)
+	}
+	if got := system.TemplatePlaceholders("ping <host> and {ip}"); got != nil {
+		t.Errorf("lower-case words shouldn't count as placeholders: %v", got)
+	}
+}
+
+func TestTemplateNew(t *testing.T) {
+	dir, err := ioutil.TempDir("", "template")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cmd := system.NewTemplateCommand()
+	run := func(args ...string) *commands.Result {
+		result, err := cmd.Execute(context.Background(), commands.ParseArguments(args))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	file := filepath.Join(dir, "scripts", "nightly.ss")
+	result := run("new", "backup", file)
+	if result.ExitCode != 0 || !strings.Contains(result.Output, "<BUCKET>") {
+		t.Fatalf("new backup: exit %d\n%s", result.ExitCode, result.Output)
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil || !strings.Contains(string(data), "fastcp-backup <SOURCE_DIR>") {
+		t.Fatalf("template not written: %v", err)
+	}
+
+	if result := run("new", "script", file); result.ExitCode == 0 {
+		t.Error("an existing file should not be overwritten without --force")
+	}
+	if result := run("new", "script", file, "--force"); result.ExitCode != 0 {
+		t.Errorf("--force failed:\n%s", result.Output)
+	}
+	if data, _ := ioutil.ReadFile(file); !strings.Contains(string(data), "automation script") {
+		t.Error("--force did not replace the file")
+	}
+
+	if result := run("new", "nope"); result.ExitCode == 0 || result.Error == nil {
+		t.Error("unknown templates should be a usage error")
+	}
+	if result := run("list"); !strings.Contains(result.Output, "supershell.yaml") {
+		t.Errorf("list should show default file names:\n%s", result.Output)
+	}
+}
@@ -0,0 +1,82 @@
+package system_test
+
+import (
+	"testing"
+	"time"
+
+	"suppercommand/internal/commands/system"
+)
+
+func TestParsePasswd(t *testing.T) {
+	data := "root:x:0:0:root:/root:/bin/bash\n" +
+		"daemon:x:1:1:daemon:/usr/sbin:/usr/sbin/nologin\n" +
+		"# comment:x:2:2::/:/bin/sh\n" +
+		"alice:x:1000:1000:Alice Smith,,,:/home/alice:/bin/zsh\n" +
+		"nobody:x:65534:65534:nobody:/nonexistent:/usr/sbin/nologin\n" +
+		"broken:x:notanumber:0::/:/bin/sh\n"
+
+	users := system.ParsePasswd(data)
+	if len(users) != 4 {
+		t.Fatalf("len(users) = %d, want 4", len(users))
+	}
+
+	root, daemon, alice, nobody := users[0], users[1], users[2], users[3]
+	if root.System || !root.Enabled || root.Shell != "/bin/bash" {
+		t.Errorf("root = %+v", root)
+	}
+	if !daemon.System || daemon.Enabled {
+		t.Errorf("daemon = %+v, want a disabled system account", daemon)
+	}
+	if alice.System || alice.UID != 1000 || alice.FullName != "Alice Smith" || alice.Home != "/home/alice" {
+		t.Errorf("alice = %+v", alice)
+	}
+	if !nobody.System {
+		t.Errorf("nobody = %+v, want a system account", nobody)
+	}
+}
+
+func TestShadowDisabled(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	data := "root:$6$hash:19700:0:99999:7:::\n" +
+		"locked:!$6$hash:19700:0:99999:7:::\n" +
+		"expired:$6$hash:19700:0:99999:7::1:\n" +
+		"later:$6$hash:19700:0:99999:7::30000:\n"
+
+	disabled := system.ShadowDisabled(data, now)
+	for name, want := range map[string]bool{"root": false, "locked": true, "expired": true, "later": false} {
+		if disabled[name] != want {
+			t.Errorf("disabled[%q] = %v, want %v", name, disabled[name], want)
+		}
+	}
+}
+
+func TestParseLastlog(t *testing.T) {
+	output := "Username         Port     From             Latest\n" +
+		"root             pts/0    10.0.0.5         Mon Mar 11 09:00:01 +0000 2024\n" +
+		"daemon                                     **Never logged in**\n" +
+		"alice            tty1                      Sat Mar  2 18:30:00 +0100 2024\n"
+
+	logons := system.ParseLastlog(output)
+	if len(logons) != 2 {
+		t.Fatalf("len(logons) = %d, want 2", len(logons))
+	}
+	if want := time.Date(2024, 3, 11, 9, 0, 1, 0, time.UTC); !logons["root"].Equal(want) {
+		t.Errorf("root = %v, want %v", logons["root"], want)
+	}
+	if want := time.Date(2024, 3, 2, 17, 30, 0, 0, time.UTC); !logons["alice"].Equal(want) {
+		t.Errorf("alice = %v, want %v", logons["alice"], want)
+	}
+}
+
+func TestValidUserName(t *testing.T) {
+	for _, name := range []string{"alice", "svc_backup", "john.doe", "Guest User", "HOST$"} {
+		if !system.ValidUserName(name) {
+			t.Errorf("ValidUserName(%q) = false, want true", name)
+		}
+	}
+	for _, name := range []string{"", "-rf", "a:b", "bob'; Remove-Item", "trailing ", "a/b"} {
+		if system.ValidUserName(name) {
+			t.Errorf("ValidUserName(%q) = true, want false", name)
+		}
+	}
+}